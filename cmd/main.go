@@ -1,6 +1,14 @@
 /**
  *  Main entry point for the DailyVerse application. This file sets up the HTTP server,
- *  initializes services, repositories, and handlers, and defines routes for various endpoints.
+ *  initializes services, repositories, and handlers, and serves them on the routes defined in
+ *  internal/server, with the OpenAPI document of those routes at /api/openapi.json.
+ *  On SIGINT or SIGTERM the server stops accepting requests, lets in-flight requests finish
+ *  for up to 20 seconds, and then closes the Firestore client, if one was created. Each request is given 10 seconds.
+ *  The configuration is loaded from the environment once, before anything else is started, and
+ *  the application exits with a list of the missing variables if it is incomplete.
+ *  Prometheus metrics about requests, Firestore and external API calls, and rate limiting are
+ *  served at /metrics. With STORAGE_BACKEND=memory, all data is kept in memory and no Firestore
+ *  client is created, unless RATE_LIMIT_STORE=firestore keeps the rate limit buckets there.
  *
  *  @file      main.go
  *  @project   DailyVerse
@@ -16,130 +24,326 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/repositories/memory"
+	"syscall"
 	"time"
 
-	"github.com/gorilla/mux"
+	"cloud.google.com/go/firestore"
 	"github.com/joho/godotenv"
-	"github.com/rs/cors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"golang.org/x/time/rate"
+	"proh2052-group6/internal/apidoc"
+	"proh2052-group6/internal/config"
 	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/metrics"
 	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/server"
 	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/httpclient"
+	"proh2052-group6/pkg/utils"
+)
+
+const (
+	requestTimeout  = 10 * time.Second // Maximum time a single request may take.
+	shutdownTimeout = 20 * time.Second // Maximum time in-flight requests get to finish on shutdown.
+	emailQueueSize  = 100              // Emails waiting for delivery before senders have to wait.
 )
 
 func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run starts the application and blocks until it is interrupted by SIGINT or SIGTERM,
+// returning once the server has shut down and the Firestore client, if any, is closed.
+func run() error {
 	// Load environment variables from a .env file
 	if err := godotenv.Load(); err != nil {
 		log.Print("No .env file found")
 	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	jwtManager := utils.NewJWTManager(cfg.JWTSecret, cfg.JWTOldSecrets, cfg.JWTExpiry, cfg.JWTIssuer)
 
-	// Create a context for service initialization
-	ctx := context.Background()
-
-	// Initialize Firestore client for database access
-	dbClient, err := services.NewFirestoreClient(ctx)
+	// Build the OpenAPI document served at /api/openapi.json
+	apiSpec, err := apidoc.JSON()
 	if err != nil {
-		log.Fatalf("Failed to initialize Firestore: %v", err)
+		return err
+	}
+
+	// Register the application's metrics alongside the Go runtime and process metrics
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	appMetrics := metrics.New(registry)
+
+	// Create a context for service initialization that is cancelled on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Initialize the Firestore client for database access, unless nothing is kept in Firestore
+	var dbClient *firestore.Client
+	if cfg.UsesFirestore() {
+		if dbClient, err = services.NewFirestoreClient(ctx, cfg.FirestoreProjectID); err != nil {
+			return fmt.Errorf("Failed to initialize Firestore: %w", err)
+		}
+		defer dbClient.Close() // Ensure Firestore client is closed after the server has shut down
 	}
-	defer dbClient.Close() // Ensure Firestore client is closed when the application exits
 
-	// Initialize repositories for data access
-	userRepository := repositories.NewFirestoreUserRepository(dbClient)
-	friendRepository := repositories.NewFirestoreFriendRepository(dbClient)
-	eventRepository := repositories.NewFirestoreEventRepository(dbClient)
-	journalRepository := repositories.NewFirestoreJournalRepository(dbClient)
+	// Data is kept in Firestore, or in memory for local development.
+	var (
+		userStore             repositories.UserRepository
+		friendStore           repositories.FriendRepository
+		eventStore            repositories.EventRepository
+		journalStore          repositories.JournalRepository
+		invitationStore       repositories.InvitationRepository
+		notificationStore     repositories.NotificationRepository
+		idempotencyStore      repositories.IdempotencyRepository
+		shareStore            repositories.ShareRepository
+		favoriteStore         repositories.FavoriteRepository
+		promptStore           repositories.PromptRepository
+		auditStore            repositories.AuditRepository
+		friendInvitationStore repositories.FriendInvitationRepository
+		pendingDeliveryStore  repositories.PendingDeliveryRepository
+	)
+	if cfg.StorageBackend == config.StorageBackendMemory {
+		memoryUsers, memoryEvents, memoryJournals := memory.NewUserRepository(), memory.NewEventRepository(), memory.NewJournalRepository()
+		memoryUsers.Events, memoryUsers.Journals = memoryEvents, memoryJournals // Moved along with a user's email.
+		userStore, friendStore, eventStore, journalStore = memoryUsers, memory.NewFriendRepository(), memoryEvents, memoryJournals
+		invitationStore, notificationStore, idempotencyStore = memory.NewInvitationRepository(), memory.NewNotificationRepository(), memory.NewIdempotencyRepository()
+		shareStore, favoriteStore, promptStore = memory.NewShareRepository(), memory.NewFavoriteRepository(), memory.NewPromptRepository()
+		auditStore, friendInvitationStore, pendingDeliveryStore = memory.NewAuditRepository(), memory.NewFriendInvitationRepository(), memory.NewPendingDeliveryRepository()
+		log.Println("Keeping all data in memory; it is lost on restart")
+	} else {
+		userStore, friendStore = repositories.NewFirestoreUserRepository(dbClient), repositories.NewFirestoreFriendRepository(dbClient)
+		eventStore, journalStore = repositories.NewFirestoreEventRepository(dbClient), repositories.NewFirestoreJournalRepository(dbClient)
+		invitationStore = repositories.NewFirestoreInvitationRepository(dbClient)
+		notificationStore = repositories.NewFirestoreNotificationRepository(dbClient)
+		idempotencyStore = repositories.NewFirestoreIdempotencyRepository(dbClient)
+		shareStore = repositories.NewFirestoreShareRepository(dbClient)
+		favoriteStore = repositories.NewFirestoreFavoriteRepository(dbClient)
+		promptStore = repositories.NewFirestorePromptRepository(dbClient)
+		auditStore = repositories.NewFirestoreAuditRepository(dbClient)
+		friendInvitationStore = repositories.NewFirestoreFriendInvitationRepository(dbClient)
+		pendingDeliveryStore = repositories.NewFirestorePendingDeliveryRepository(dbClient)
+	}
+
+	// Initialize repositories for data access, timing every database call
+	userRepository := repositories.NewTimedUserRepository(userStore, appMetrics)
+	friendRepository := repositories.NewTimedFriendRepository(friendStore, appMetrics)
+	eventRepository := repositories.NewTimedEventRepository(eventStore, appMetrics)
+	journalRepository := repositories.NewTimedJournalRepository(journalStore, appMetrics)
+	invitationRepository := repositories.NewTimedInvitationRepository(invitationStore, appMetrics)
+	notificationRepository := repositories.NewTimedNotificationRepository(notificationStore, appMetrics)
+	idempotencyRepository := repositories.NewTimedIdempotencyRepository(idempotencyStore, appMetrics)
+	shareRepository := repositories.NewTimedShareRepository(shareStore, appMetrics)
+	favoriteRepository := repositories.NewTimedFavoriteRepository(favoriteStore, appMetrics)
+	promptRepository := repositories.NewTimedPromptRepository(promptStore, appMetrics)
+	auditRepository := repositories.NewTimedAuditRepository(auditStore, appMetrics)
+	friendInvitationRepository := repositories.NewTimedFriendInvitationRepository(friendInvitationStore, appMetrics)
+	pendingDeliveryRepository := repositories.NewTimedPendingDeliveryRepository(pendingDeliveryStore, appMetrics)
 
 	// Initialize services for business logic
-	emailService := services.NewSMTPEmailService()
-	userService := services.NewUserService(userRepository, emailService)
-	eventService := services.NewEventService(eventRepository)
-	friendService := services.NewFriendService(userRepository, friendRepository)
-	journalService := services.NewJournalService(journalRepository)
-	newsService := services.NewNewsService(userRepository)
-	profileService := services.NewProfileService(userRepository)
+	// Emails such as OTPs are queued and sent in the background, so requests do not wait for SMTP.
+	emailTransport := services.NewSMTPTransport(cfg.SMTP)
+	emailQueue := services.NewEmailQueue(emailTransport, emailQueueSize)
+	emailQueue.Start()
+	emailService := services.NewSMTPEmailService(emailTransport, emailQueue)
+	// Notification emails are held back during each user's quiet hours and sent once they end.
+	notificationGate := services.NewNotificationGate(pendingDeliveryRepository, emailService)
+	var storageService services.StorageServiceInterface // Profile pictures and journal attachments; uploads are disabled without a bucket.
+	if cfg.GCSBucket != "" {
+		if storageService, err = services.NewGCSStorageService(ctx, cfg.GCSBucket); err != nil {
+			return fmt.Errorf("Failed to initialize Cloud Storage: %w", err)
+		}
+	} else {
+		log.Print("GCS_BUCKET not set, profile picture and journal attachment uploads are disabled")
+	}
+	var journalCipher *services.JournalCipher // Journal content is stored in plaintext without a master key.
+	if cfg.EncryptionMasterKey != nil {
+		if journalCipher, err = services.NewJournalCipher(cfg.EncryptionMasterKey); err != nil {
+			return fmt.Errorf("Failed to initialize journal encryption: %w", err)
+		}
+	} else {
+		log.Print("ENCRYPTION_MASTER_KEY not set, journal entries are stored in plaintext")
+	}
+	auditService := services.NewAuditService(auditRepository)
+	statsService := services.NewStatsService(eventRepository, journalRepository, friendRepository)
+	userService := services.NewUserService(userRepository, emailService, friendRepository, jwtManager)
+	userService.(*services.UserService).Audit = auditService
+	userService.(*services.UserService).FriendInvitationRepo = friendInvitationRepository
+	if cfg.OTPLength > 0 {
+		userService.(*services.UserService).OTP.Length = cfg.OTPLength
+	}
+	if cfg.OTPTTL > 0 {
+		userService.(*services.UserService).OTP.TTL = cfg.OTPTTL
+	}
+	notificationHub := services.NewNotificationHub()
+	notificationService := services.NewNotificationService(notificationRepository, notificationHub)
+	// Outbound clients time out and retry idempotent calls; every attempt is recorded in the metrics.
+	outboundClient := func(upstream string) *http.Client {
+		clientConfig := httpclient.DefaultConfig()
+		clientConfig.Transport = appMetrics.Transport(upstream, httpclient.NewTransport(clientConfig))
+		return httpclient.New(clientConfig)
+	}
+	userAgent := "DailyVerse/1.0 (" + cfg.SMTP.User + ")" // Nominatim and Open-Meteo ask clients to include a contact address.
+	geocoder := services.NewNominatimGeocoder(userAgent)
+	geocoder.(*services.NominatimGeocoder).HTTPClient = outboundClient("geocoding")
+	eventService := services.NewEventService(eventRepository, invitationRepository, userRepository, friendRepository, notificationService, idempotencyRepository, geocoder)
+	eventService.(*services.EventService).ShareRepo = shareRepository
+	eventService.(*services.EventService).AppURL = cfg.AppURL
+	friendService := services.NewFriendService(userRepository, friendRepository, emailService, notificationService)
+	friendService.(*services.FriendService).InvitationRepo = friendInvitationRepository
+	friendService.(*services.FriendService).AppURL = cfg.AppURL
+	friendService.(*services.FriendService).Gate = notificationGate
+	promptService := services.NewPromptService(promptRepository, userRepository)
+	journalService := services.NewJournalService(journalRepository, userRepository)
+	journalService.(*services.JournalService).Storage = storageService
+	journalService.(*services.JournalService).Cipher = journalCipher
+	journalService.(*services.JournalService).Prompts = promptService.(*services.PromptService)
+	newsService := services.NewNewsService(userRepository, cfg)
+	newsService.(*services.NewsService).HTTPClient = outboundClient("news")
+	profileService := services.NewProfileService(userRepository, friendRepository, invitationRepository, emailService, storageService, jwtManager)
+	profileService.(*services.ProfileService).Audit = auditService
+	profileService.(*services.ProfileService).ShareRepo = shareRepository
+	if cfg.OTPLength > 0 {
+		profileService.(*services.ProfileService).OTP.Length = cfg.OTPLength
+	}
+	if cfg.OTPTTL > 0 {
+		profileService.(*services.ProfileService).OTP.TTL = cfg.OTPTTL
+	}
 	cityService := services.NewCityService()
+	cityService.(*services.CityService).HTTPClient = outboundClient("cities")
+	userService.(*services.UserService).Cities = cityService
+	weatherService := services.NewWeatherService(userAgent)
+	weatherService.(*services.WeatherService).HTTPClient = outboundClient("weather")
+	holidayService := services.NewHolidayService(userAgent)
+	holidayService.(*services.HolidayService).HTTPClient = outboundClient("holidays")
+	services.SetCountryHTTPClient(outboundClient("countries"))
+	quoteService := services.NewQuoteService(favoriteRepository)
+	countryService := services.NewCountryService(services.CountrySourceLocal)
 	timetableService := services.NewTimetableService(eventRepository)
+	timetableService.(*services.TimetableService).UserRepo = userRepository
+	adminService := services.NewAdminService(userRepository, auditService)
+	adminService.(*services.AdminService).FriendRepo = friendRepository
+	adminService.(*services.AdminService).InvitationRepo = invitationRepository
+	adminService.(*services.AdminService).ShareRepo = shareRepository
+	exportService := services.NewExportService(userRepository, eventRepository, journalRepository, friendRepository)
+	exportService.(*services.ExportService).JournalCipher = journalCipher
+	reminderService := services.NewReminderService(eventRepository, emailService)
+	reminderService.(*services.ReminderService).UserRepo = userRepository
+	reminderService.(*services.ReminderService).Gate = notificationGate
+	digestService := services.NewDigestService(userRepository, eventRepository, journalRepository, emailService)
+	digestService.(*services.DigestService).Gate = notificationGate
+	if cfg.DigestInterval > 0 {
+		digestService.(*services.DigestService).Interval = cfg.DigestInterval
+	}
+	journalReminderService := services.NewJournalReminderService(userRepository, journalRepository, emailService)
+	journalReminderService.(*services.JournalReminderService).Gate = notificationGate
+	healthCheckers := map[string]services.HealthChecker{
+		"smtp": services.SkippedHealthChecker(), // Probing SMTP would mean sending an email.
+	}
+	if dbClient != nil {
+		healthCheckers["firestore"] = services.FirestoreHealthChecker(dbClient)
+	}
+	healthService := services.NewHealthService(healthCheckers)
+
+	// Start the background schedulers that email event reminders, weekly digests, journal reminders
+	// and the notifications held back during quiet hours
+	go reminderService.Start(ctx)
+	go friendService.(*services.FriendService).StartExpirySweep(ctx)
+	go journalService.(*services.JournalService).StartTrashPurge(ctx)
+	go digestService.Start(ctx)
+	go journalReminderService.Start(ctx)
+	go notificationGate.Start(ctx)
+
+	// Rate limit buckets are kept in memory, or in Firestore so limits hold across restarts and instances.
+	var limiterStore middleware.LimiterStore
+	var persistentLimiterStore *middleware.PersistentLimiterStore
+	if cfg.RateLimitStore == config.RateLimitStoreFirestore {
+		persistentLimiterStore = middleware.NewPersistentLimiterStore(
+			repositories.NewTimedRateLimitRepository(repositories.NewFirestoreRateLimitRepository(dbClient), appMetrics))
+		go persistentLimiterStore.StartFlushing(ctx, cfg.RateLimitFlushInterval)
+		limiterStore = persistentLimiterStore
+	} else {
+		memoryLimiterStore := middleware.NewMemoryLimiterStore()
+		go memoryLimiterStore.StartPruning(ctx)
+		limiterStore = memoryLimiterStore
+	}
+	limiters := middleware.NewRateLimiters(limiterStore)
 
 	// Initialize HTTP handlers
-	userHandler := handlers.NewUserHandler(userService)
-	eventHandler := handlers.NewEventHandler(eventService)
-	friendHandler := handlers.NewFriendHandler(friendService)
-	journalHandler := handlers.NewJournalHandler(journalService)
-	newsHandler := handlers.NewNewsHandler(newsService)
-	profileHandler := handlers.NewProfileHandler(profileService)
-	countryHandler := handlers.NewCountryHandler()
-	cityHandler := handlers.NewCityHandler(cityService, userService)
-	timetableHandler := handlers.NewTimetableHandler(timetableService)
-
-	// Set up the HTTP router
-	router := mux.NewRouter()
+	routeHandlers := server.Handlers{
+		User:         handlers.NewUserHandler(userService),
+		Event:        handlers.NewEventHandler(eventService),
+		Friend:       handlers.NewFriendHandler(friendService),
+		Journal:      handlers.NewJournalHandler(journalService),
+		Prompt:       handlers.NewPromptHandler(promptService),
+		News:         handlers.NewNewsHandler(newsService),
+		Weather:      handlers.NewWeatherHandler(weatherService, userService, geocoder),
+		Holiday:      handlers.NewHolidayHandler(holidayService, userService),
+		Quote:        handlers.NewQuoteHandler(quoteService, userService),
+		Profile:      handlers.NewProfileHandler(profileService),
+		Country:      handlers.NewCountryHandler(countryService),
+		City:         handlers.NewCityHandler(cityService, userService),
+		Timetable:    handlers.NewTimetableHandler(timetableService),
+		Health:       handlers.NewHealthHandler(healthService),
+		Notification: handlers.NewNotificationHandler(notificationService, notificationHub, cfg.AllowedOrigins),
+		Export:       handlers.NewExportHandler(exportService),
+		Digest:       handlers.NewDigestHandler(digestService),
+		Audit:        handlers.NewAuditHandler(auditService),
+		Stats:        handlers.NewStatsHandler(statsService),
+		Admin:        handlers.NewAdminHandler(adminService),
+		Docs:         handlers.NewDocsHandler(apiSpec),
+		Metrics:      metrics.Handler(registry, cfg.MetricsToken),
+	}
+
+	// JWT authentication for protected routes; tokens are revoked when the password changes and
+	// rejected for disabled accounts. The administrator routes also require the admin role.
+	// The unauthenticated user routes are rate limited with separate per-IP buckets, and data
+	// exports and email invitations per user, since an export reads everything stored about a user and an
+	// invitation emails someone without an account. Rejections are counted per limiter, and the limiter
+	// names also key their buckets in the shared store.
+	// POST and PUT bodies must be JSON and are limited in size so a large body cannot exhaust memory.
+	// Authenticated requests are answered in the language of the user's country unless the client chose one.
+	jwtAuth := middleware.NewJwtAuthMiddleware(userRepository, jwtManager)
+	webSocketAuth := middleware.NewWebSocketAuthMiddleware(userRepository, jwtManager) // Also accepts ?token= for browsers.
+	userLocale := middleware.NewUserLocale(userRepository, services.UserLocale)
+	routeMiddleware := server.Middleware{
+		JWTAuth:       func(next http.HandlerFunc) http.HandlerFunc { return jwtAuth(userLocale(next)) },
+		WebSocketAuth: func(next http.HandlerFunc) http.HandlerFunc { return webSocketAuth(userLocale(next)) },
+		AdminOnly:     middleware.NewAdminOnlyMiddleware(userRepository),
+		SignupLimit:   appMetrics.CountRejections("signup", limiters.PerIP("signup", rate.Every(time.Hour/5), 5)),        // 5 signups per hour.
+		LoginLimit:    appMetrics.CountRejections("login", limiters.PerIP("login", rate.Every(time.Minute), 10)),         // 10 attempts, then 1 per minute.
+		OTPLimit:      appMetrics.CountRejections("otp", limiters.PerIP("otp", rate.Every(10*time.Minute/3), 10)),        // 10 attempts, then 3 per 10 minutes.
+		ExportLimit:   appMetrics.CountRejections("export", limiters.PerUser("export", rate.Every(12*time.Hour), 2)),     // 2 exports per day.
+		InviteLimit:   appMetrics.CountRejections("invite", limiters.PerUser("invite", rate.Every(24*time.Hour/10), 10)), // 10 invitations per day.
+		Instrument:    appMetrics.Instrument,
+		JSONBody:      middleware.NewJSONBody(cfg.MaxBodySize),
+		ImportBody:    middleware.NewJSONBody(cfg.MaxImportBodySize), // ICS timetables are larger than other bodies.
+	}
 
 	// Define API routes
-	// User routes
-	router.Handle("/api/signup", middleware.RateLimitMiddleware(http.HandlerFunc(userHandler.Signup))).Methods("POST")
-	router.Handle("/api/login", middleware.RateLimitMiddleware(http.HandlerFunc(userHandler.Login))).Methods("POST")
-	router.Handle("/api/resend-otp", middleware.RateLimitMiddleware(http.HandlerFunc(userHandler.ResendOTP))).Methods("POST")
-	router.HandleFunc("/api/verify-email", userHandler.VerifyEmail).Methods("POST")
-	router.HandleFunc("/api/forgot-password", userHandler.ForgotPassword).Methods("POST")
-	router.HandleFunc("/api/reset-password", userHandler.ResetPassword).Methods("POST")
-	router.Handle("/api/me", middleware.JwtAuthMiddleware(userHandler.GetUserInfo)).Methods("GET")
-
-	// Event routes
-	router.Handle("/api/events/create", middleware.JwtAuthMiddleware(eventHandler.CreateEvent)).Methods("POST")
-	router.Handle("/api/events/get", middleware.JwtAuthMiddleware(eventHandler.GetEvent)).Methods("GET")
-	router.Handle("/api/events/update", middleware.JwtAuthMiddleware(eventHandler.UpdateEvent)).Methods("PUT")
-	router.Handle("/api/events/delete", middleware.JwtAuthMiddleware(eventHandler.DeleteEvent)).Methods("DELETE")
-	router.Handle("/api/events/all", middleware.JwtAuthMiddleware(eventHandler.GetAllEvents)).Methods("GET")
-
-	// Friend routes
-	router.Handle("/api/friends/add", middleware.JwtAuthMiddleware(friendHandler.SendFriendRequest)).Methods("POST")
-	router.Handle("/api/friends/accept", middleware.JwtAuthMiddleware(friendHandler.AcceptFriendRequest)).Methods("POST")
-	router.Handle("/api/friends/list", middleware.JwtAuthMiddleware(friendHandler.GetFriendsList)).Methods("GET")
-	router.Handle("/api/friends/delete", middleware.JwtAuthMiddleware(friendHandler.RemoveFriend)).Methods("DELETE")
-	router.Handle("/api/friends/requests", middleware.JwtAuthMiddleware(friendHandler.GetPendingFriendRequests)).Methods("GET")
-	router.Handle("/api/friends/decline", middleware.JwtAuthMiddleware(friendHandler.DeclineFriendRequest)).Methods("POST")
-	router.Handle("/api/friends/cancel", middleware.JwtAuthMiddleware(friendHandler.CancelFriendRequest)).Methods("POST")
-
-	// User search
-	router.Handle("/api/users/search", middleware.JwtAuthMiddleware(userHandler.SearchUsersByUsername)).Methods("GET")
-
-	// Profile routes
-	router.Handle("/api/profile", middleware.JwtAuthMiddleware(profileHandler.ProfileHandler)).Methods("GET", "PUT")
-
-	// Country and city routes
-	router.HandleFunc("/api/countries", countryHandler.GetCountries).Methods("GET")
-	router.HandleFunc("/api/cities", cityHandler.GetCities).Methods("GET")
-
-	// News route
-	router.Handle("/api/news", middleware.JwtAuthMiddleware(newsHandler.FetchNews)).Methods("GET")
-
-	// Journal routes
-	router.Handle("/api/journal/save", middleware.JwtAuthMiddleware(journalHandler.CreateJournal)).Methods("POST")
-	router.Handle("/api/journal", middleware.JwtAuthMiddleware(journalHandler.GetJournal)).Methods("GET")
-	router.Handle("/api/journal/update", middleware.JwtAuthMiddleware(journalHandler.UpdateJournal)).Methods("PUT")
-	router.Handle("/api/journal/delete", middleware.JwtAuthMiddleware(journalHandler.DeleteJournal)).Methods("DELETE")
-	router.Handle("/api/journals", middleware.JwtAuthMiddleware(journalHandler.GetAllJournals)).Methods("GET")
-
-	// Timetable route
-	router.Handle("/api/import-ntnu-timetable", middleware.JwtAuthMiddleware(timetableHandler.ImportTimetable)).Methods("POST")
-
-	// Apply CORS middleware
-	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"}, // Allow all origins for development (adjust in production)
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
-		AllowedHeaders:   []string{"Authorization", "Content-Type"},
-		AllowCredentials: true,
-	})
+	router := server.NewAPIRouter(routeHandlers, routeMiddleware, cfg.EnableAdminRoutes, cfg.Debug())
 
 	// Configure and start the HTTP server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080" // Default port
-	}
-	handler := c.Handler(router)
+	port := cfg.Port
+
+	// Health probes and metrics are served outside the CORS, JWT and timeout middleware; every other path goes to the router.
+	// The WebSocket endpoint is also outside the timeout middleware, since its connections are long-lived.
+	// The locale middleware stores the language the client asked for, from ?lang= or Accept-Language.
+	// Panic recovery wraps everything, so a panicking handler fails only its own request.
+	handler := middleware.NewRecover()(server.NewRootRouter(routeHandlers, routeMiddleware, middleware.NewCORS(cfg.AllowedOrigins)(middleware.NewLocale()(middleware.NewRequestTimeout(requestTimeout)(router)))))
 	srv := &http.Server{
 		Handler:      handler,
 		Addr:         ":" + port,
@@ -147,8 +351,30 @@ func main() {
 		ReadTimeout:  15 * time.Second,
 	}
 
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return fmt.Errorf("Server failed to start: %w", err)
+	}
+
 	log.Printf("Server running on port %s", port)
-	if err := srv.ListenAndServe(); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	serveErr := server.Serve(ctx, srv, listener, shutdownTimeout)
+
+	// Deliver the emails queued by the last requests before exiting.
+	drainCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := emailQueue.Shutdown(drainCtx); err != nil {
+		log.Printf("Email queue did not drain: %v", err)
+	}
+	// Save the buckets changed since the last flush, so a deploy does not reset them.
+	if persistentLimiterStore != nil {
+		if err := persistentLimiterStore.Flush(drainCtx); err != nil {
+			log.Printf("Failed to save rate limit buckets: %v", err)
+		}
+	}
+
+	if serveErr != nil {
+		return serveErr
 	}
+	log.Print("Server stopped")
+	return nil
 }
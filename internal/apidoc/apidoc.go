@@ -0,0 +1,343 @@
+/**
+ *  Package apidoc builds the OpenAPI 3 document of the HTTP API at startup. Every route is described
+ *  by an Operation in routes.go, and the request and response schemas are generated from the Go types
+ *  the handlers decode and encode, so the documentation cannot drift from the bodies actually sent.
+ *
+ *  @file       apidoc.go
+ *  @package    apidoc
+ *
+ *  @struct     Operation
+ *  @struct     Parameter
+ *
+ *  @methods
+ *  - Build() - Builds the OpenAPI document from the described operations.
+ *  - JSON()  - Builds the OpenAPI document and encodes it as JSON.
+ *
+ *  @behaviors
+ *  - Routes require a bearer JWT unless their Operation is marked Public.
+ *  - Every named request and response type becomes a schema under components/schemas, named after the Go type.
+ *    Fields tagged `apidoc:"-"`, which requests accept but ignore, are left out.
+ *  - Error statuses are documented with the body of utils.WriteJSONError, and routes whose services validate
+ *    their input also document the field-level 400 of utils.WriteJSONValidationError. Protected routes
+ *    always document 401, and routes with a JSON body 400, 413 and 415.
+ *  - Conditional GET operations document the If-None-Match header, the ETag response header and the
+ *    304 answer, and are also described as a HEAD operation returning the same headers.
+ *  - Build validates the document, so a broken description fails at startup instead of in the browser.
+ *
+ *  @dependencies
+ *  - github.com/getkin/kin-openapi: OpenAPI 3 document model, validation and schema generation.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package apidoc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+
+	"proh2052-group6/internal/handlers"
+)
+
+const (
+	// Title and Version are reported in the document's info object.
+	Title   = "DailyVerse API"
+	Version = "1.0.0"
+
+	// bearerAuth is the name of the JWT security scheme.
+	bearerAuth = "bearerAuth"
+)
+
+// Operation describes one route of the API.
+type Operation struct {
+	Method      string      // HTTP method, e.g. http.MethodGet.
+	Path        string      // Path as registered with the router.
+	Tag         string      // Group the operation is listed under, e.g. "events".
+	Summary     string      // One-line description.
+	Public      bool        // Served without a JWT.
+	Parameters  []Parameter // Query and header parameters.
+	Request     interface{} // Value of the JSON request body's type; nil if the route takes no JSON body.
+	RequestForm string      // Name of the file field of a multipart/form-data body, instead of Request.
+	RequestType string      // Content type of a non-JSON body accepted besides Request, such as text/csv.
+	Response    interface{} // Value of the JSON response body's type; nil if ResponseType is set.
+	// ResponseType is the content type of a non-JSON response, such as a file download.
+	ResponseType string
+	Status       int   // Status code of a successful response; 200 if zero.
+	Errors       []int // Error status codes answered with handlers.ErrorResponse.
+	PlainErrors  bool  // Errors are answered with a plain text message instead of JSON.
+	Validated    bool  // The service validates the input, so a 400 may list the invalid fields.
+	// Conditional marks GET routes that set an ETag, answer a matching If-None-Match with 304 and
+	// are also served for HEAD.
+	Conditional bool
+}
+
+// Parameter describes a query, header or path parameter.
+type Parameter struct {
+	In          string // "query", "header" or "path".
+	Name        string
+	Type        string // Schema type: "string", "integer" or "boolean".
+	Required    bool
+	Description string
+}
+
+// query returns an optional string query parameter.
+func query(name, description string) Parameter {
+	return Parameter{In: openapi3.ParameterInQuery, Name: name, Type: openapi3.TypeString, Description: description}
+}
+
+// requiredQuery returns a required string query parameter.
+func requiredQuery(name, description string) Parameter {
+	return Parameter{In: openapi3.ParameterInQuery, Name: name, Type: openapi3.TypeString, Required: true, Description: description}
+}
+
+// pathParam returns a string path parameter, which is always required.
+func pathParam(name, description string) Parameter {
+	return Parameter{In: openapi3.ParameterInPath, Name: name, Type: openapi3.TypeString, Required: true, Description: description}
+}
+
+// typedQuery returns an optional query parameter of the given schema type.
+func typedQuery(name, typ, description string) Parameter {
+	return Parameter{In: openapi3.ParameterInQuery, Name: name, Type: typ, Description: description}
+}
+
+// Build builds and validates the OpenAPI document describing every operation in routes.go.
+func Build() (*openapi3.T, error) {
+	b := &builder{
+		schemas: openapi3.Schemas{},
+		types:   map[string]reflect.Type{},
+	}
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       Title,
+			Version:     Version,
+			Description: "HTTP API of DailyVerse. Errors are returned as {\"message\": \"...\"} with a matching status code.",
+		},
+		Paths: openapi3.Paths{},
+		Components: &openapi3.Components{
+			Schemas: b.schemas,
+			SecuritySchemes: openapi3.SecuritySchemes{
+				bearerAuth: &openapi3.SecuritySchemeRef{Value: openapi3.NewJWTSecurityScheme()},
+			},
+		},
+		Security: *openapi3.NewSecurityRequirements().With(openapi3.NewSecurityRequirement().Authenticate(bearerAuth)),
+	}
+
+	for _, op := range operations {
+		operation, err := b.operation(op)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to describe %s %s: %w", op.Method, op.Path, err)
+		}
+		doc.AddOperation(op.Path, op.Method, operation)
+
+		if op.Conditional {
+			head := op
+			head.Method = http.MethodHead
+			head.Summary = op.Summary + " Only the headers are returned."
+			head.Response = nil
+			operation, err := b.operation(head)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to describe %s %s: %w", head.Method, head.Path, err)
+			}
+			doc.AddOperation(head.Path, head.Method, operation)
+		}
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("Invalid OpenAPI document: %w", err)
+	}
+	return doc, nil
+}
+
+// JSON builds the OpenAPI document and encodes it as JSON.
+func JSON() ([]byte, error) {
+	doc, err := Build()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// builder generates the schemas of the document, adding each named type to the components once.
+type builder struct {
+	schemas openapi3.Schemas
+	types   map[string]reflect.Type // Go type of each schema in schemas, to detect name clashes.
+}
+
+// operation describes op as an OpenAPI operation.
+func (b *builder) operation(op Operation) (*openapi3.Operation, error) {
+	operation := &openapi3.Operation{
+		Summary:   op.Summary,
+		Tags:      []string{op.Tag},
+		Responses: openapi3.Responses{}, // Without the empty default response openapi3.NewResponses adds.
+	}
+	if op.Public {
+		operation.Security = openapi3.NewSecurityRequirements() // Overrides the document's bearer requirement.
+	}
+
+	for _, param := range op.Parameters {
+		parameter := &openapi3.Parameter{
+			In:          param.In,
+			Name:        param.Name,
+			Required:    param.Required,
+			Description: param.Description,
+			Schema:      openapi3.NewSchemaRef("", &openapi3.Schema{Type: param.Type}),
+		}
+		operation.AddParameter(parameter)
+	}
+	if op.Conditional {
+		operation.AddParameter(&openapi3.Parameter{
+			In:          openapi3.ParameterInHeader,
+			Name:        "If-None-Match",
+			Description: "ETag of a previous response; answered with 304 Not Modified if it is still current.",
+			Schema:      openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+		})
+	}
+
+	switch {
+	case op.Request != nil:
+		schema, err := b.schemaRef(op.Request)
+		if err != nil {
+			return nil, err
+		}
+		body := openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(schema)
+		if op.RequestType != "" {
+			body.Content[op.RequestType] = openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema())
+		}
+		operation.RequestBody = &openapi3.RequestBodyRef{Value: body}
+	case op.RequestForm != "":
+		form := openapi3.NewObjectSchema().
+			WithProperty(op.RequestForm, openapi3.NewStringSchema().WithFormat("binary"))
+		form.Required = []string{op.RequestForm}
+		operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithRequired(true).
+				WithContent(openapi3.NewContentWithSchema(form, []string{"multipart/form-data"})),
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := openapi3.NewResponse().WithDescription(http.StatusText(status))
+	switch {
+	case op.Response != nil:
+		schema, err := b.schemaRef(op.Response)
+		if err != nil {
+			return nil, err
+		}
+		success.WithJSONSchemaRef(schema)
+	case op.ResponseType != "":
+		success.WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema().WithFormat("binary"), []string{op.ResponseType}))
+	}
+	if op.Conditional {
+		success.Headers = openapi3.Headers{"ETag": &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+			Description: "Version of the response, to send as If-None-Match.",
+			Schema:      openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+		}}}}
+		operation.AddResponse(http.StatusNotModified, openapi3.NewResponse().WithDescription(http.StatusText(http.StatusNotModified)))
+	}
+	operation.AddResponse(status, success)
+
+	errorSchema, err := b.schemaRef(handlers.ErrorResponse{})
+	if err != nil {
+		return nil, err
+	}
+	validationSchema, err := b.schemaRef(handlers.ValidationErrorResponse{})
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range errorStatuses(op) {
+		response := openapi3.NewResponse().WithDescription(http.StatusText(code))
+		switch {
+		case op.PlainErrors:
+			response.WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema(), []string{"text/plain"}))
+		case code == http.StatusBadRequest && op.Validated:
+			response.WithJSONSchema(&openapi3.Schema{OneOf: openapi3.SchemaRefs{errorSchema, validationSchema}})
+		default:
+			response.WithJSONSchemaRef(errorSchema)
+		}
+		operation.AddResponse(code, response)
+	}
+
+	return operation, nil
+}
+
+// errorStatuses returns the sorted error status codes of op, including 401 for protected routes and
+// 400, 413 and 415 for routes with a JSON body.
+func errorStatuses(op Operation) []int {
+	codes := append([]int(nil), op.Errors...)
+	if !op.Public {
+		codes = append(codes, http.StatusUnauthorized)
+	}
+	if op.Request != nil {
+		codes = append(codes, http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType)
+	}
+	if op.Validated {
+		codes = append(codes, http.StatusBadRequest)
+	}
+	sort.Ints(codes)
+
+	unique := codes[:0]
+	for i, code := range codes {
+		if i == 0 || code != codes[i-1] {
+			unique = append(unique, code)
+		}
+	}
+	return unique
+}
+
+// schemaRef returns the schema of value's type. Named types are added to the components and
+// referenced; slices of named types become arrays of references.
+func (b *builder) schemaRef(value interface{}) (*openapi3.SchemaRef, error) {
+	t := reflect.TypeOf(value)
+	if t.Kind() == reflect.Slice && t.Elem().Name() != "" {
+		items, err := b.schemaRef(reflect.Zero(t.Elem()).Interface())
+		if err != nil {
+			return nil, err
+		}
+		return openapi3.NewSchemaRef("", &openapi3.Schema{Type: openapi3.TypeArray, Items: items}), nil
+	}
+
+	name := t.Name()
+	if name == "" {
+		return generateSchema(value)
+	}
+	if existing, ok := b.types[name]; ok {
+		if existing != t {
+			return nil, fmt.Errorf("Schema name %q is used by both %v and %v", name, existing, t)
+		}
+		return openapi3.NewSchemaRef("#/components/schemas/"+name, b.schemas[name].Value), nil
+	}
+
+	schema, err := generateSchema(value)
+	if err != nil {
+		return nil, err
+	}
+	b.schemas[name] = openapi3.NewSchemaRef("", schema.Value)
+	b.types[name] = t
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, schema.Value), nil
+}
+
+// generateSchema generates the schema of value's type from its exported fields, named by their JSON
+// tags, leaving out the fields tagged `apidoc:"-"`.
+func generateSchema(value interface{}) (*openapi3.SchemaRef, error) {
+	return openapi3gen.NewSchemaRefForValue(value, nil, openapi3gen.UseAllExportedFields(),
+		openapi3gen.SchemaCustomizer(func(name string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+			if tag.Get("apidoc") == "-" {
+				return &openapi3gen.ExcludeSchemaSentinel{}
+			}
+			return nil
+		}))
+}
@@ -0,0 +1,773 @@
+/**
+ *  Description of every route served by the router in internal/server. A route registered there
+ *  without an Operation here fails the route coverage test, so new endpoints cannot go undocumented.
+ *
+ *  @file       routes.go
+ *  @package    apidoc
+ *
+ *  @behaviors
+ *  - Operations are listed in the order the routes are registered, grouped by tag.
+ *  - The error statuses are the ones the handlers write; 401 is added for every protected route.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+)
+
+const (
+	badRequest   = http.StatusBadRequest
+	gone         = http.StatusGone
+	forbidden    = http.StatusForbidden
+	notFound     = http.StatusNotFound
+	conflict     = http.StatusConflict
+	tooMany      = http.StatusTooManyRequests
+	internal     = http.StatusInternalServerError
+	unavailable  = http.StatusServiceUnavailable
+	integerParam = openapi3.TypeInteger
+	booleanParam = openapi3.TypeBoolean
+)
+
+// Parameters shared by several routes.
+var (
+	eventIDParam = requiredQuery("eventID", "ID of the event.")
+	scopeParams  = []Parameter{
+		query("scope", `"series" (default) for the entire event, or "occurrence" for the occurrence on date.`),
+		query("date", "Date of the occurrence (YYYY-MM-DD); required with scope=occurrence."),
+	}
+	journalIDParam = requiredQuery("journalID", "ID of the journal.")
+	rangeParams    = []Parameter{
+		query("from", "Inclusive start date (YYYY-MM-DD)."),
+		query("to", "Inclusive end date (YYYY-MM-DD)."),
+	}
+)
+
+// operations describes every route of the API.
+var operations = []Operation{
+	// User routes
+	{
+		Method: http.MethodPost, Path: "/api/signup", Tag: "users", Public: true,
+		Summary: "Register a new user and email them an OTP to verify the address. An account of the email left unverified for 7 days is replaced.",
+		Request: handlers.SignupRequest{}, Response: handlers.SignupResponse{},
+		Errors: []int{badRequest, conflict, tooMany, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/login", Tag: "users", Public: true,
+		Summary: "Log in with email and password.",
+		Request: models.LoginRequest{}, Response: handlers.TokenResponse{},
+		Errors: []int{badRequest, http.StatusUnauthorized, forbidden, http.StatusGone, http.StatusLocked, tooMany, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/resend-otp", Tag: "users", Public: true,
+		Summary: "Send a new email verification OTP. Accounts not verified within 7 days must sign up again.",
+		Request: handlers.EmailRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, conflict, http.StatusGone, tooMany, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/verify-email", Tag: "users", Public: true,
+		Summary: "Verify an email address with its OTP and log in.",
+		Request: handlers.VerifyEmailRequest{}, Response: handlers.TokenMessageResponse{},
+		Errors: []int{badRequest, http.StatusGone, tooMany, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/forgot-password", Tag: "users", Public: true,
+		Summary: "Email an OTP for resetting the password.",
+		Request: handlers.EmailRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, tooMany, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/reset-password", Tag: "users", Public: true,
+		Summary: "Reset the password with an OTP, revoking all existing tokens.",
+		Request: handlers.ResetPasswordRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, http.StatusGone, tooMany, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/me", Tag: "users",
+		Summary:  "Get the authenticated user.",
+		Response: models.UserProfile{},
+		Errors:   []int{notFound, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/me/security-log", Tag: "users",
+		Summary:  "List the latest 50 security-sensitive actions on the user's account, such as logins and password changes, newest first.",
+		Response: []models.AuditEntry{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/me/stats", Tag: "users",
+		Summary:  "Count the user's events, journals and friends, capped at 1000, and the days this month with a journal. Cached for 10 minutes.",
+		Response: models.UserStats{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/me/export", Tag: "users",
+		Summary:      "Download everything stored about the user as a ZIP archive.",
+		ResponseType: "application/zip",
+		Errors:       []int{notFound, tooMany, internal, unavailable},
+	},
+
+	// Event routes
+	{
+		Method: http.MethodPost, Path: "/api/events/create", Tag: "events",
+		Summary: "Create an event. Retries with the same Idempotency-Key return the original event.",
+		Parameters: []Parameter{{
+			In: openapi3.ParameterInHeader, Name: "Idempotency-Key", Type: openapi3.TypeString,
+			Description: "Client-generated key that makes retrying the request safe.",
+		}},
+		Request: handlers.EventRequest{}, Response: handlers.EventSavedResponse{},
+		Errors:    []int{conflict, http.StatusUnprocessableEntity, notFound, internal, unavailable},
+		Validated: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/events/get", Tag: "events",
+		Summary:    "Get one of the user's events.",
+		Parameters: []Parameter{eventIDParam},
+		Response:   models.Event{},
+		Errors:     []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/events/update", Tag: "events",
+		Summary:    "Update an event, or a single occurrence of a recurring event.",
+		Parameters: []Parameter{eventIDParam, scopeParams[0], scopeParams[1]},
+		Request:    handlers.EventRequest{}, Response: handlers.EventSavedResponse{},
+		Errors:    []int{notFound, conflict, internal, unavailable},
+		Validated: true,
+	},
+	{
+		Method: http.MethodPatch, Path: "/api/events/update", Tag: "events",
+		Summary:    "Update only the given fields of an event or recurring series; omitted fields are kept.",
+		Parameters: []Parameter{eventIDParam},
+		Request:    models.Event{}, Response: models.Event{},
+		Errors:    []int{notFound, conflict, internal, unavailable},
+		Validated: true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/events/delete", Tag: "events",
+		Summary:    "Delete an event, or a single occurrence of a recurring event.",
+		Parameters: []Parameter{eventIDParam, scopeParams[0], scopeParams[1]},
+		Response:   handlers.MessageResponse{},
+		Errors:     []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/events/all", Tag: "events",
+		Summary: "List the user's events. Without parameters the response is an array of all events; with any of them it is a page.",
+		Parameters: []Parameter{
+			rangeParams[0], rangeParams[1],
+			typedQuery("limit", integerParam, "Maximum number of events per page."),
+			query("pageToken", "nextPageToken of the previous page."),
+			query("tag", "Only events carrying this tag."),
+			typedQuery("includeCancelled", booleanParam, "Also list cancelled events."),
+		},
+		Response:    models.EventPage{},
+		Errors:      []int{badRequest, internal, unavailable},
+		Conditional: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/events/invite", Tag: "events",
+		Summary: "Invite a friend to one of the user's events.",
+		Request: handlers.InviteToEventRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, conflict, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/events/rsvp", Tag: "events",
+		Summary: "Accept or decline an event invitation.",
+		Request: handlers.RespondToInvitationRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/events/invitations", Tag: "events",
+		Summary:  "List the user's event invitations.",
+		Response: []models.EventInvitation{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/events/tags", Tag: "events",
+		Summary:  "List the tags on the user's events with the number of events carrying each.",
+		Response: []models.TagCount{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/events/nearby", Tag: "events",
+		Summary: "List the user's events within a radius of a position, nearest first.",
+		Parameters: []Parameter{
+			{In: openapi3.ParameterInQuery, Name: "lat", Type: openapi3.TypeNumber, Required: true, Description: "Latitude in degrees."},
+			{In: openapi3.ParameterInQuery, Name: "lng", Type: openapi3.TypeNumber, Required: true, Description: "Longitude in degrees."},
+			typedQuery("radiusKm", openapi3.TypeNumber, "Search radius in kilometres, at most 500; 10 by default."),
+		},
+		Response: []models.NearbyEvent{},
+		Errors:   []int{badRequest, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/events/search", Tag: "events",
+		Summary: "Search the user's events by title, description and street address, ordered by date and start time.",
+		Parameters: []Parameter{
+			requiredQuery("q", "Text to find, ignoring case."),
+			requiredQuery("from", "Inclusive start date (YYYY-MM-DD)."),
+			requiredQuery("to", "Inclusive end date (YYYY-MM-DD), at most one year after from."),
+		},
+		Response: []models.EventSearchResult{},
+		Errors:   []int{badRequest, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/events/bulk-create", Tag: "events",
+		Summary: "Create up to 100 events. Each event is created or rejected on its own; the response lists both.",
+		Request: []handlers.EventRequest{}, Response: models.BulkEventResult{},
+		Errors: []int{badRequest, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/events/bulk-delete", Tag: "events",
+		Summary: "Delete up to 100 of the user's events. IDs of events the user does not own are listed as failed.",
+		Request: handlers.BulkDeleteEventsRequest{}, Response: models.BulkEventResult{},
+		Errors: []int{badRequest, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/events/duplicate", Tag: "events",
+		Summary: "Copy one of the user's events to a date, and with count above 1 to the following weeks. Copies are one-off events with new IDs.",
+		Request: handlers.DuplicateEventRequest{}, Response: handlers.DuplicateEventResponse{},
+		Errors: []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/events/cancel", Tag: "events",
+		Summary:    "Cancel one of the user's events. The event is kept with status cancelled and invitees who accepted are notified.",
+		Parameters: []Parameter{eventIDParam},
+		Response:   handlers.MessageResponse{},
+		Errors:     []int{badRequest, notFound, conflict, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/events/month", Tag: "events",
+		Summary:    "Summarize the user's events per day of the Monday-to-Sunday weeks a month is shown in.",
+		Parameters: []Parameter{requiredQuery("month", "The month (YYYY-MM).")},
+		Response:   models.EventMonth{},
+		Errors:     []int{badRequest, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/events/feed", Tag: "events",
+		Summary:  "List the public events of all of the user's friends in the next 14 days, ordered by date and start time.",
+		Response: []models.FriendEvent{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/events/share", Tag: "events",
+		Summary:    "Create a read-only link to an event for people without an account. Earlier links to the event stop working.",
+		Parameters: []Parameter{eventIDParam},
+		Response:   models.EventShareLink{},
+		Errors:     []int{badRequest, notFound, conflict, internal, unavailable},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/events/share", Tag: "events",
+		Summary:    "Revoke the read-only links to an event.",
+		Parameters: []Parameter{eventIDParam},
+		Response:   handlers.MessageResponse{},
+		Errors:     []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/shared/events/{token}", Tag: "events", Public: true,
+		Summary:    "Read a shared event, without the owner's email or other account data.",
+		Parameters: []Parameter{pathParam("token", "Token of the shared event link.")},
+		Response:   models.SharedEvent{},
+		Errors:     []int{notFound, gone, internal, unavailable},
+	},
+
+	// Friend routes
+	{
+		Method: http.MethodPost, Path: "/api/friends/add", Tag: "friends",
+		Summary: "Send a friend request, or accept the other user's pending request.",
+		Request: handlers.UsernameOrEmailRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, tooMany, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/friends/accept", Tag: "friends",
+		Summary: "Accept a friend request.",
+		Request: handlers.UsernameOrEmailRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/friends/list", Tag: "friends",
+		Summary:  "List the user's friends.",
+		Response: []models.UserSummary{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/friends/delete", Tag: "friends",
+		Summary: "Remove a friend.",
+		Request: handlers.UsernameRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/friends/requests", Tag: "friends",
+		Summary:  "List the friend requests sent to the user.",
+		Response: []models.UserSummary{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/friends/decline", Tag: "friends",
+		Summary: "Decline a friend request.",
+		Request: handlers.UsernameOrEmailRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/friends/cancel", Tag: "friends",
+		Summary: "Cancel a friend request the user sent.",
+		Request: handlers.UsernameRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/friends/block", Tag: "friends",
+		Summary: "Block a user, ending any friendship or pending request with them.",
+		Request: handlers.UsernameOrEmailRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/friends/unblock", Tag: "friends",
+		Summary: "Unblock a user.",
+		Request: handlers.UsernameOrEmailRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/friends/blocked", Tag: "friends",
+		Summary:  "List the users the user has blocked.",
+		Response: []models.UserSummary{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/friends/suggestions", Tag: "friends",
+		Summary:    "Suggest friends of the user's friends, with the number of mutual friends.",
+		Parameters: []Parameter{typedQuery("limit", integerParam, "Maximum number of suggestions.")},
+		Response:   []models.UserSummary{},
+		Errors:     []int{badRequest, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/friends/mutual", Tag: "friends",
+		Summary:    "List the friends the user has in common with another user.",
+		Parameters: []Parameter{requiredQuery("username", "Username or email of the other user.")},
+		Response:   []models.UserSummary{},
+		Errors:     []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/friends/events", Tag: "friends",
+		Summary: "List the public events of a friend, ordered by date and start time. Private and cancelled events are never listed.",
+		Parameters: []Parameter{
+			requiredQuery("username", "Username of the friend."),
+			query("from", "First date (YYYY-MM-DD); defaults to today."),
+			query("to", "Last date (YYYY-MM-DD), at most one year after from; defaults to the 14th day from from."),
+		},
+		Response: []models.FriendEvent{},
+		Errors:   []int{badRequest, forbidden, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/friends/status", Tag: "friends",
+		Summary: "Look up the relationship with up to 50 users by email: none, friends, pending_incoming, pending_outgoing or blocked.",
+		Request: handlers.FriendStatusRequest{}, Response: map[string]string{},
+		Errors: []int{badRequest, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/friends/invite", Tag: "friends",
+		Summary: "Send a friend request to an email address, or invite it to sign up if it has no account. Limited to 10 per day.",
+		Request: handlers.EmailRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, conflict, tooMany, unavailable},
+	},
+
+	// Notification routes
+	{
+		Method: http.MethodGet, Path: "/api/notifications", Tag: "notifications",
+		Summary: "List the user's notifications, newest first.",
+		Parameters: []Parameter{
+			typedQuery("unread", booleanParam, "Only unread notifications."),
+			typedQuery("limit", integerParam, "Maximum number of notifications."),
+		},
+		Response: []models.Notification{},
+		Errors:   []int{badRequest, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/notifications/read", Tag: "notifications",
+		Summary: "Mark one notification, or all of them, as read.",
+		Request: handlers.MarkReadRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/ws", Tag: "notifications",
+		Summary:    "Open a WebSocket that receives new notifications as JSON. Browsers may pass the JWT as ?token=.",
+		Parameters: []Parameter{query("token", "JWT, for clients that cannot set the Authorization header.")},
+		Status:     http.StatusSwitchingProtocols,
+	},
+
+	// User search
+	{
+		Method: http.MethodGet, Path: "/api/users/search", Tag: "users",
+		Summary: "Search users by username, first name or last name prefix.",
+		Parameters: []Parameter{
+			requiredQuery("query", "Prefix to search for."),
+			typedQuery("excludeBlocked", booleanParam, "Hide users the user has blocked."),
+			typedQuery("excludeRelated", booleanParam, "Hide friends, users with a pending request in either direction and blocked users."),
+			typedQuery("limit", integerParam, "Maximum number of results; 20 by default and at most 50."),
+			typedQuery("offset", integerParam, "Number of results to skip."),
+		},
+		Response: []handlers.UserSearchResult{},
+		Errors:   []int{badRequest, notFound, unavailable},
+	},
+
+	// Profile routes
+	{
+		Method: http.MethodGet, Path: "/api/profile", Tag: "profile",
+		Summary:  "Get the user's profile.",
+		Response: models.UserProfile{},
+		Errors:   []int{notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/profile", Tag: "profile",
+		Summary: "Update the fields present in the body. CurrentPassword is always required.",
+		Request: handlers.UpdateProfileRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, notFound, conflict, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/profile/change-email", Tag: "profile",
+		Summary: "Start changing the email address by sending an OTP to the new address.",
+		Request: handlers.ChangeEmailRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, conflict, tooMany, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/profile/confirm-email", Tag: "profile",
+		Summary: "Confirm the new email address with its OTP. Returns a token for the new address.",
+		Request: handlers.ConfirmEmailRequest{}, Response: handlers.TokenMessageResponse{},
+		Errors: []int{badRequest, conflict, tooMany, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/profile/avatar", Tag: "profile",
+		Summary:     "Upload a JPEG, PNG or WebP profile picture.",
+		RequestForm: "avatar", Response: handlers.AvatarResponse{},
+		Errors: []int{badRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, internal, unavailable},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/profile/avatar", Tag: "profile",
+		Summary:  "Remove the profile picture.",
+		Response: handlers.MessageResponse{},
+		Errors:   []int{notFound, internal, unavailable},
+	},
+
+	// Country and city routes
+	{
+		Method: http.MethodGet, Path: "/api/countries", Tag: "locations", Public: true,
+		Summary:    "List countries, optionally filtered by name.",
+		Parameters: []Parameter{query("search", "Only countries whose name contains this text.")},
+		Response:   []services.Country{},
+		Errors:     []int{internal},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/cities", Tag: "locations", Public: true,
+		Summary:     "List the cities of a country. Errors are plain text.",
+		Parameters:  []Parameter{requiredQuery("country", "Name of the country.")},
+		Response:    handlers.CitiesResponse{},
+		PlainErrors: true,
+		Errors:      []int{badRequest, internal, http.StatusGatewayTimeout},
+	},
+
+	// News route
+	{
+		Method: http.MethodGet, Path: "/api/news", Tag: "news",
+		Summary: "Get a page of news for the user's country, or for a search.",
+		Parameters: []Parameter{
+			query("mode", `"country" (default) or "search".`),
+			query("country", "Country code; the user's country by default."),
+			query("language", "One of the country's language codes; its primary language by default."),
+			query("q", "Search query, for mode=search."),
+			query("page", "nextPage of the previous page."),
+			typedQuery("refresh", booleanParam, "Bypass the cache."),
+		},
+		Response: services.NewsPage{},
+		Errors:   []int{tooMany, internal, http.StatusBadGateway},
+	},
+
+	// Weather route
+	{
+		Method: http.MethodGet, Path: "/api/weather", Tag: "weather",
+		Summary:    "Get the weather forecast in the user's city.",
+		Parameters: []Parameter{query("date", "Date of the forecast (YYYY-MM-DD); today by default.")},
+		Response:   handlers.WeatherResponse{},
+		Errors:     []int{badRequest, notFound, internal, unavailable, http.StatusBadGateway},
+	},
+
+	// Holiday route
+	{
+		Method: http.MethodGet, Path: "/api/holidays", Tag: "holidays",
+		Summary:    "List the public holidays of the user's country, ordered by date.",
+		Parameters: []Parameter{typedQuery("year", integerParam, "Year of the holidays, at most 10 years from now; the current year by default.")},
+		Response:   []services.Holiday{},
+		Errors:     []int{badRequest, notFound, internal, unavailable, http.StatusBadGateway},
+	},
+
+	// Daily verse routes
+	{
+		Method: http.MethodGet, Path: "/api/daily-verse", Tag: "quotes",
+		Summary: "Get the quote of the day, the same for every user with the same language.",
+		Parameters: []Parameter{
+			query("date", "Date of the quote (YYYY-MM-DD); today by default."),
+			query("language", "Two-letter language code; the language of the user's country by default."),
+		},
+		Response: models.Quote{},
+		Errors:   []int{badRequest, notFound, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/daily-verse/favorite", Tag: "quotes",
+		Summary: "Save a quote to the user's favourites.",
+		Request: handlers.FavoriteQuoteRequest{}, Response: models.FavoriteQuote{},
+		Errors: []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/daily-verse/favorites", Tag: "quotes",
+		Summary:  "List the user's favourite quotes, most recently saved first.",
+		Response: []models.FavoriteQuote{},
+		Errors:   []int{internal, unavailable},
+	},
+
+	// Journal routes
+	{
+		Method: http.MethodPost, Path: "/api/journal/save", Tag: "journals",
+		Summary:    "Create a journal. With upsert=true an existing journal for the same date is overwritten.",
+		Parameters: []Parameter{typedQuery("upsert", booleanParam, "Overwrite the journal for the same date.")},
+		Request:    handlers.JournalRequest{}, Response: handlers.JournalSavedResponse{},
+		Errors:    []int{conflict, internal, unavailable},
+		Validated: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/journal", Tag: "journals",
+		Summary:    "Get one of the user's journals.",
+		Parameters: []Parameter{journalIDParam},
+		Response:   models.Journal{},
+		Errors:     []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/journal/update", Tag: "journals",
+		Summary:    "Update a journal.",
+		Parameters: []Parameter{journalIDParam},
+		Request:    handlers.JournalRequest{}, Response: handlers.MessageResponse{},
+		Errors:    []int{notFound, conflict, internal, unavailable},
+		Validated: true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/journal/delete", Tag: "journals",
+		Summary:    "Move a journal to the trash.",
+		Parameters: []Parameter{journalIDParam},
+		Response:   handlers.MessageResponse{},
+		Errors:     []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/journal/trash", Tag: "journals",
+		Summary:  "List the user's journals deleted in the last 30 days, most recently deleted first.",
+		Response: []models.Journal{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/journal/restore", Tag: "journals",
+		Summary:    "Restore a journal from the trash.",
+		Parameters: []Parameter{journalIDParam},
+		Response:   models.Journal{},
+		Errors:     []int{badRequest, notFound, conflict, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/journal/attachments", Tag: "journals",
+		Summary:     "Attach a JPEG, PNG or WebP image of at most 5 MB to a journal. A journal has at most 10 attachments.",
+		Parameters:  []Parameter{journalIDParam},
+		RequestForm: "attachment", Response: handlers.AttachmentResponse{},
+		Errors: []int{badRequest, notFound, conflict, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/journal/attachments", Tag: "journals",
+		Summary:      "Download an image attached to a journal. Attachments are only served to their owner.",
+		Parameters:   []Parameter{journalIDParam, requiredQuery("attachmentID", "ID of the attachment.")},
+		ResponseType: "image/*",
+		Errors:       []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/journal/attachments", Tag: "journals",
+		Summary:    "Remove an image from a journal.",
+		Parameters: []Parameter{journalIDParam, requiredQuery("attachmentID", "ID of the attachment.")},
+		Response:   handlers.MessageResponse{},
+		Errors:     []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/journal/prompt", Tag: "journals",
+		Summary:  "Get the user's writing prompt of the day. Save a journal with its ID as promptId to record that the entry answers it.",
+		Response: models.JournalPrompt{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/journal/prompt/skip", Tag: "journals",
+		Summary:  "Skip the user's writing prompt and get the next one of their sequence.",
+		Response: models.JournalPrompt{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/journals", Tag: "journals",
+		Summary:     "List the user's journals.",
+		Response:    []models.Journal{},
+		Errors:      []int{internal, unavailable},
+		Conditional: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/journals/search", Tag: "journals",
+		Summary: "Search the user's journals, newest first.",
+		Parameters: []Parameter{
+			query("q", "Text the content must contain, ignoring case."),
+			rangeParams[0], rangeParams[1],
+			typedQuery("limit", integerParam, "Maximum number of journals."),
+		},
+		Response: []models.Journal{},
+		Errors:   []int{badRequest, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/journals/export", Tag: "journals",
+		Summary:      "Download the user's journals as JSON or Markdown.",
+		Parameters:   []Parameter{query("format", `"json" (default) or "markdown".`), rangeParams[0], rangeParams[1]},
+		ResponseType: "application/octet-stream",
+		Errors:       []int{badRequest, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/journals/import", Tag: "journals",
+		Summary:    "Import at most 1000 journals from a JSON array or a CSV file with date and content columns. Rows for a date that already has a journal are skipped, or appended to it with merge.",
+		Parameters: []Parameter{query("merge", `"true" to append rows to the journal of their date instead of skipping them.`)},
+		Request:    []handlers.ImportJournalEntry{}, RequestType: "text/csv", Response: models.JournalImportResult{},
+		Errors: []int{badRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/journals/stats", Tag: "journals",
+		Summary:    "Get the entry count, moods and streaks of the user's journals in a month.",
+		Parameters: []Parameter{query("month", "Month (YYYY-MM); the current month by default.")},
+		Response:   models.JournalStats{},
+		Errors:     []int{badRequest, internal, unavailable},
+	},
+
+	// Timetable routes
+	{
+		Method: http.MethodPost, Path: "/api/import-ntnu-timetable", Tag: "events",
+		Summary: "Import the events of an ICS timetable, or validate it with dryRun.",
+		Request: handlers.ImportTimetableRequest{}, Response: models.ImportResult{},
+		Errors: []int{badRequest, internal},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/import-ntnu-timetable", Tag: "events",
+		Summary:    "Undo an import by deleting the events it created. Events created manually are never deleted.",
+		Parameters: []Parameter{requiredQuery("batchID", "batchID of the import result.")},
+		Response:   handlers.UndoImportResponse{},
+		Errors:     []int{badRequest, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/import-ntnu-timetable/batches", Tag: "events",
+		Summary:  "List the user's imports that can be undone, most recent first.",
+		Response: []models.ImportBatch{},
+		Errors:   []int{internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/events/export.ics", Tag: "events",
+		Summary:      "Download the user's events as an iCalendar file.",
+		Parameters:   rangeParams,
+		ResponseType: "text/calendar",
+		Errors:       []int{badRequest, internal},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/events/feed-token", Tag: "events",
+		Summary:  "Create the URL of a calendar feed to subscribe to, replacing the old one, which stops working.",
+		Response: models.CalendarFeed{},
+		Errors:   []int{notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/events/feed-token", Tag: "events",
+		Summary:  "Get the URL of the user's calendar feed.",
+		Response: models.CalendarFeed{},
+		Errors:   []int{notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/events/feed-token", Tag: "events",
+		Summary:  "Turn the calendar feed off, so its URL stops working.",
+		Response: handlers.MessageResponse{},
+		Errors:   []int{notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/feeds/{token}.ics", Tag: "events", Public: true,
+		Summary:      "Subscribe to the events from a month ago to a year ahead as an iCalendar feed, without the user's email.",
+		Parameters:   []Parameter{pathParam("token", "Token of the calendar feed.")},
+		ResponseType: "text/calendar",
+		Errors:       []int{notFound, internal, unavailable},
+	},
+
+	// Administrator routes
+	{
+		Method: http.MethodGet, Path: "/api/admin/users", Tag: "admin",
+		Summary: "Find accounts by email, or by username or name prefix. Administrators only.",
+		Parameters: []Parameter{
+			query("query", "An email, or a username or name prefix; every account without one."),
+			typedQuery("limit", integerParam, "Maximum number of accounts, 20 by default and at most 50."),
+			typedQuery("offset", integerParam, "Number of accounts to skip."),
+		},
+		Response: []models.AdminUserSummary{},
+		Errors:   []int{badRequest, forbidden, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/admin/users/verify", Tag: "admin",
+		Summary: "Mark an account verified, for users whose OTP email bounced. Administrators only.",
+		Request: handlers.EmailRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, forbidden, notFound, conflict, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/admin/users/disable", Tag: "admin",
+		Summary: "Disable an account, or re-enable it with disabled false. Disabled accounts cannot log in and their tokens are rejected. Administrators only.",
+		Request: handlers.DisableUserRequest{}, Response: handlers.MessageResponse{},
+		Errors: []int{badRequest, forbidden, notFound, internal, unavailable},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/admin/users/normalize-emails", Tag: "admin",
+		Summary:  "Move every account created under a mixed-case or padded email to the lowercased email, reporting those whose lowercased email is taken. Administrators only.",
+		Response: models.EmailNormalizationReport{},
+		Errors:   []int{forbidden, internal, unavailable},
+	},
+
+	// Development routes
+	{
+		Method: http.MethodPost, Path: "/api/admin/digest/run", Tag: "admin",
+		Summary:  "Send the weekly digest to every opted-in user now. Administrators only, and only served with ENABLE_ADMIN_ROUTES=true.",
+		Response: handlers.DigestRunResponse{},
+		Errors:   []int{forbidden, internal, unavailable},
+	},
+
+	// Documentation routes
+	{
+		Method: http.MethodGet, Path: "/api/openapi.json", Tag: "docs", Public: true,
+		Summary:      "Get this OpenAPI document.",
+		ResponseType: "application/json",
+	},
+	{
+		Method: http.MethodGet, Path: "/api/docs", Tag: "docs", Public: true,
+		Summary:      "Browse this document with Swagger UI.",
+		ResponseType: "text/html",
+	},
+
+	// Health probes
+	{
+		Method: http.MethodGet, Path: "/healthz", Tag: "health", Public: true,
+		Summary:  "Liveness probe.",
+		Response: handlers.HealthResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/readyz", Tag: "health", Public: true,
+		Summary:  "Readiness probe with the status of each dependency, answered with 503 if any of them failed.",
+		Response: map[string]string{},
+	},
+	{
+		Method: http.MethodGet, Path: "/metrics", Tag: "health", Public: true,
+		Summary:      "Prometheus metrics. If METRICS_TOKEN is set, it must be sent as a bearer token instead of a JWT.",
+		ResponseType: "text/plain",
+		Errors:       []int{http.StatusUnauthorized},
+	},
+}
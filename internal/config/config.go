@@ -3,6 +3,60 @@
  *  used in the DailyVerse application. This file centralizes configuration
  *  settings for better maintainability and scalability.
  *
+ *  @struct   Config
+ *  @struct   SMTPConfig
+ *
+ *  @methods
+ *  - Load()          - Reads and validates the settings from the environment.
+ *  - UsesFirestore() - Reports whether anything is kept in Firestore, so a project ID is needed.
+ *  - Debug()         - Reports whether debug logging is enabled.
+ *
+ *  @behaviors
+ *  - The environment is read once, by main at startup; the settings are then passed to the
+ *    constructors that need them, so nothing depends on the environment at package init time.
+ *  - Load reports every missing required variable and every invalid value in a single error,
+ *    so a misconfigured deployment can be fixed in one go.
+ *  - Empty variables count as missing.
+ *
+ *  @environment_variables
+ *  - JWT_SECRET_KEY (required): Secret key used for signing JWT tokens and hashing OTPs.
+ *  - JWT_OLD_SECRET_KEYS: Comma-separated previous secret keys whose tokens are still accepted while
+ *    the secret is rotated.
+ *  - JWT_EXPIRY: How long a JWT token is valid, e.g. "12h"; 24 hours by default.
+ *  - JWT_ISSUER: Issuer claim set on and required of JWT tokens, "dailyverse" by default.
+ *  - NEWS_API_KEY (required): API key for the news API.
+ *  - FIRESTORE_PROJECT_ID (required unless STORAGE_BACKEND is "memory" and RATE_LIMIT_STORE is not
+ *    "firestore"): Google Cloud project of the Firestore database. GOOGLE_CLOUD_PROJECT is used when it is not set.
+ *  - SMTP_HOST, SMTP_PORT, EMAIL_USER, EMAIL_PASS (required): SMTP server and sending account.
+ *  - PORT: Port the HTTP server listens on, 8080 by default.
+ *  - GCS_BUCKET: Cloud Storage bucket for profile pictures and journal attachments; uploads are disabled
+ *    without it. The bucket must not allow public reads and must use fine-grained access control: profile
+ *    pictures are made public one by one, while attachments are only served through the API.
+ *  - DIGEST_INTERVAL: How often the weekly digest scheduler runs, e.g. "1h".
+ *  - ENABLE_ADMIN_ROUTES: "true" serves the development routes under /api/admin.
+ *  - METRICS_TOKEN: Bearer token required to scrape /metrics; the metrics are public without it.
+ *  - MAX_BODY_SIZE: Largest JSON request body in bytes, 1 MB by default.
+ *  - MAX_IMPORT_BODY_SIZE: Largest timetable import body in bytes, 10 MB by default, since ICS files can be big.
+ *  - ALLOWED_ORIGINS: Comma-separated origins browsers may call the API from, e.g.
+ *    "https://dailyverse.app,https://www.dailyverse.app". Without it only the local development
+ *    servers in DefaultAllowedOrigins are allowed. Each origin is a scheme and host with an optional
+ *    port; "*" is rejected, since the API allows credentials.
+ *  - APP_URL: Origin of the web app, used for links in emails such as friend invitations;
+ *    DefaultAppURL by default.
+ *  - RATE_LIMIT_STORE: Where rate limit buckets are kept: "memory" (the default), which forgets them on
+ *    restart, or "firestore", which persists them so limits hold across restarts and instances.
+ *  - RATE_LIMIT_FLUSH_INTERVAL: How often changed buckets are saved to Firestore, 30 seconds by default.
+ *  - STORAGE_BACKEND: Where the app's data is kept: "firestore" (the default) or "memory", which forgets
+ *    it on restart and is meant for local development. The memory backend needs no Firestore project.
+ *  - ENCRYPTION_MASTER_KEY: Base64-encoded 32-byte key journal entries are encrypted with at rest;
+ *    journals are stored in plaintext without it. Its value is never included in errors.
+ *  - OTP_LENGTH: Number of digits of the OTPs sent for email verification, password resets and email
+ *    changes, between MinOTPLength and MaxOTPLength; 6 by default.
+ *  - OTP_TTL: How long those OTPs stay valid, e.g. "10m"; 5 minutes by default, and 10 minutes for
+ *    email changes.
+ *  - LOG_LEVEL: "info" (the default) or "debug", which also logs requests to unknown /api paths,
+ *    to catch typos in the web app.
+ *
  *  @file      config.go
  *  @project   DailyVerse
  *  @framework Go HTTP Server
@@ -16,6 +70,16 @@
 
 package config
 
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
 var (
 	// CountriesAPIURL defines the endpoint for retrieving country data.
 	CountriesAPIURL = "https://restcountries.com/v3.1/all"
@@ -23,3 +87,279 @@ var (
 	// CitiesAPIURL defines the endpoint for retrieving cities based on countries.
 	CitiesAPIURL = "https://countriesnow.space/api/v0.1/countries/cities"
 )
+
+// DefaultPort is the port the HTTP server listens on when PORT is not set.
+const DefaultPort = "8080"
+
+// Default request body limits, used when MAX_BODY_SIZE and MAX_IMPORT_BODY_SIZE are not set.
+const (
+	DefaultMaxBodySize       = 1 << 20
+	DefaultMaxImportBodySize = 10 << 20
+)
+
+// DefaultAllowedOrigins are the origins of the local development servers, allowed when
+// ALLOWED_ORIGINS is not set.
+var DefaultAllowedOrigins = []string{
+	"http://localhost:3000",
+	"http://localhost:5173",
+	"http://localhost:8080",
+}
+
+// DefaultAppURL is the origin of the web app when APP_URL is not set: the local development server.
+const DefaultAppURL = "http://localhost:5173"
+
+// Bounds of OTP_LENGTH: shorter OTPs are too easy to guess, longer ones too hard to type.
+const (
+	MinOTPLength = 4
+	MaxOTPLength = 10
+)
+
+// Stores rate limit buckets can be kept in, set by RATE_LIMIT_STORE.
+const (
+	RateLimitStoreMemory    = "memory"
+	RateLimitStoreFirestore = "firestore"
+)
+
+// Levels of logging, set by LOG_LEVEL.
+const (
+	LogLevelInfo  = "info"
+	LogLevelDebug = "debug"
+)
+
+// Backends the app's data can be kept in, set by STORAGE_BACKEND.
+const (
+	StorageBackendFirestore = "firestore"
+	StorageBackendMemory    = "memory"
+)
+
+// DefaultRateLimitFlushInterval is how often rate limit buckets are saved when RATE_LIMIT_FLUSH_INTERVAL is not set.
+const DefaultRateLimitFlushInterval = 30 * time.Second
+
+// Config holds the settings read from the environment at startup.
+type Config struct {
+	Port               string        // Port the HTTP server listens on.
+	JWTSecret          string        // Secret for signing JWT tokens and hashing OTPs.
+	JWTOldSecrets      []string      // Previous secrets whose tokens and OTPs are still accepted.
+	JWTExpiry          time.Duration // Lifetime of JWT tokens; 0 keeps utils.DefaultJWTExpiry.
+	JWTIssuer          string        // Issuer of JWT tokens; empty keeps utils.DefaultJWTIssuer.
+	NewsAPIKey         string        // API key for the news API.
+	FirestoreProjectID string        // Google Cloud project of the Firestore database.
+	SMTP               SMTPConfig    // SMTP server used to send emails.
+	GCSBucket          string        // Bucket for profile pictures and attachments; empty disables uploads.
+	DigestInterval     time.Duration // How often the digest scheduler runs; 0 keeps its default.
+	EnableAdminRoutes  bool          // Whether the development routes are served.
+	MetricsToken       string        // Bearer token required by /metrics; empty leaves it unprotected.
+	MaxBodySize        int64         // Largest JSON request body in bytes.
+	MaxImportBodySize  int64         // Largest timetable import request body in bytes.
+	AllowedOrigins     []string      // Origins allowed to make cross-origin requests.
+	AppURL             string        // Origin of the web app, for links in emails.
+	RateLimitStore     string        // RateLimitStoreMemory or RateLimitStoreFirestore.
+	// How often rate limit buckets are saved to Firestore.
+	RateLimitFlushInterval time.Duration
+	StorageBackend         string // StorageBackendFirestore or StorageBackendMemory.
+	// Master key journal entries are encrypted with; nil stores them in plaintext.
+	EncryptionMasterKey []byte
+	OTPLength           int           // Digits of emailed OTPs; 0 keeps services.DefaultOTPLength.
+	OTPTTL              time.Duration // Lifetime of emailed OTPs; 0 keeps services.OTPValidity.
+	LogLevel            string        // LogLevelInfo or LogLevelDebug.
+}
+
+// SMTPConfig holds the SMTP server and the account emails are sent from.
+type SMTPConfig struct {
+	Host     string // SMTP server hostname.
+	Port     int    // SMTP server port number.
+	User     string // Email address used for sending and for authentication.
+	Password string // Password or app-specific password for User.
+}
+
+// UsesFirestore reports whether the configuration keeps anything in Firestore: the storage backend
+// or the rate limit buckets.
+func (c *Config) UsesFirestore() bool {
+	return c.StorageBackend == StorageBackendFirestore || c.RateLimitStore == RateLimitStoreFirestore
+}
+
+// Debug reports whether LOG_LEVEL asks for debug logging.
+func (c *Config) Debug() bool {
+	return c.LogLevel == LogLevelDebug
+}
+
+// Load reads the configuration from the environment. If required variables are missing or values
+// are invalid, the returned error lists all of them.
+func Load() (*Config, error) {
+	var missing, invalid []string
+	required := func(name string) string {
+		value := os.Getenv(name)
+		if value == "" {
+			missing = append(missing, name)
+		}
+		return value
+	}
+
+	cfg := &Config{
+		Port:              os.Getenv("PORT"),
+		JWTSecret:         required("JWT_SECRET_KEY"),
+		JWTIssuer:         os.Getenv("JWT_ISSUER"),
+		NewsAPIKey:        required("NEWS_API_KEY"),
+		GCSBucket:         os.Getenv("GCS_BUCKET"),
+		EnableAdminRoutes: os.Getenv("ENABLE_ADMIN_ROUTES") == "true",
+		MetricsToken:      os.Getenv("METRICS_TOKEN"),
+		MaxBodySize:       DefaultMaxBodySize,
+		MaxImportBodySize: DefaultMaxImportBodySize,
+	}
+	if cfg.Port == "" {
+		cfg.Port = DefaultPort
+	}
+
+	for _, secret := range strings.Split(os.Getenv("JWT_OLD_SECRET_KEYS"), ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			cfg.JWTOldSecrets = append(cfg.JWTOldSecrets, secret)
+		}
+	}
+	if expiry := os.Getenv("JWT_EXPIRY"); expiry != "" {
+		parsed, err := time.ParseDuration(expiry)
+		if err != nil || parsed <= 0 {
+			invalid = append(invalid, fmt.Sprintf("JWT_EXPIRY %q", expiry))
+		}
+		cfg.JWTExpiry = parsed
+	}
+
+	cfg.FirestoreProjectID = os.Getenv("FIRESTORE_PROJECT_ID")
+	if cfg.FirestoreProjectID == "" {
+		cfg.FirestoreProjectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+
+	cfg.SMTP.Host = required("SMTP_HOST")
+	if port := required("SMTP_PORT"); port != "" {
+		parsed, err := strconv.Atoi(port)
+		if err != nil || parsed <= 0 {
+			invalid = append(invalid, fmt.Sprintf("SMTP_PORT %q", port))
+		}
+		cfg.SMTP.Port = parsed
+	}
+	cfg.SMTP.User = required("EMAIL_USER")
+	cfg.SMTP.Password = required("EMAIL_PASS")
+
+	if interval := os.Getenv("DIGEST_INTERVAL"); interval != "" {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil || parsed <= 0 {
+			invalid = append(invalid, fmt.Sprintf("DIGEST_INTERVAL %q", interval))
+		}
+		cfg.DigestInterval = parsed
+	}
+
+	byteSize := func(name string, target *int64) {
+		if size := os.Getenv(name); size != "" {
+			parsed, err := strconv.ParseInt(size, 10, 64)
+			if err != nil || parsed <= 0 {
+				invalid = append(invalid, fmt.Sprintf("%s %q", name, size))
+			}
+			*target = parsed
+		}
+	}
+	byteSize("MAX_BODY_SIZE", &cfg.MaxBodySize)
+	byteSize("MAX_IMPORT_BODY_SIZE", &cfg.MaxImportBodySize)
+
+	cfg.AllowedOrigins = DefaultAllowedOrigins
+	if origins := os.Getenv("ALLOWED_ORIGINS"); origins != "" {
+		cfg.AllowedOrigins = nil
+		for _, origin := range strings.Split(origins, ",") {
+			origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+			if origin == "" {
+				continue
+			}
+			if !isOrigin(origin) {
+				invalid = append(invalid, fmt.Sprintf("ALLOWED_ORIGINS entry %q", origin))
+				continue
+			}
+			cfg.AllowedOrigins = append(cfg.AllowedOrigins, origin)
+		}
+		if len(cfg.AllowedOrigins) == 0 && len(invalid) == 0 {
+			invalid = append(invalid, fmt.Sprintf("ALLOWED_ORIGINS %q", origins))
+		}
+	}
+
+	cfg.AppURL = DefaultAppURL
+	if appURL := os.Getenv("APP_URL"); appURL != "" {
+		cfg.AppURL = strings.TrimSuffix(appURL, "/")
+		if !isOrigin(cfg.AppURL) {
+			invalid = append(invalid, fmt.Sprintf("APP_URL %q", appURL))
+		}
+	}
+
+	cfg.RateLimitStore = RateLimitStoreMemory
+	if store := os.Getenv("RATE_LIMIT_STORE"); store != "" {
+		cfg.RateLimitStore = store
+		if store != RateLimitStoreMemory && store != RateLimitStoreFirestore {
+			invalid = append(invalid, fmt.Sprintf("RATE_LIMIT_STORE %q", store))
+		}
+	}
+	cfg.StorageBackend = StorageBackendFirestore
+	if backend := os.Getenv("STORAGE_BACKEND"); backend != "" {
+		cfg.StorageBackend = backend
+		if backend != StorageBackendFirestore && backend != StorageBackendMemory {
+			invalid = append(invalid, fmt.Sprintf("STORAGE_BACKEND %q", backend))
+		}
+	}
+	if cfg.FirestoreProjectID == "" && cfg.UsesFirestore() {
+		missing = append(missing, "FIRESTORE_PROJECT_ID")
+	}
+	cfg.RateLimitFlushInterval = DefaultRateLimitFlushInterval
+	if interval := os.Getenv("RATE_LIMIT_FLUSH_INTERVAL"); interval != "" {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil || parsed <= 0 {
+			invalid = append(invalid, fmt.Sprintf("RATE_LIMIT_FLUSH_INTERVAL %q", interval))
+		}
+		cfg.RateLimitFlushInterval = parsed
+	}
+	if key := os.Getenv("ENCRYPTION_MASTER_KEY"); key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(decoded) != 32 {
+			invalid = append(invalid, "ENCRYPTION_MASTER_KEY (must be base64 of 32 bytes)")
+		}
+		cfg.EncryptionMasterKey = decoded
+	}
+	if length := os.Getenv("OTP_LENGTH"); length != "" {
+		parsed, err := strconv.Atoi(length)
+		if err != nil || parsed < MinOTPLength || parsed > MaxOTPLength {
+			invalid = append(invalid, fmt.Sprintf("OTP_LENGTH %q", length))
+		}
+		cfg.OTPLength = parsed
+	}
+	if ttl := os.Getenv("OTP_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil || parsed <= 0 {
+			invalid = append(invalid, fmt.Sprintf("OTP_TTL %q", ttl))
+		}
+		cfg.OTPTTL = parsed
+	}
+	cfg.LogLevel = LogLevelInfo
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		cfg.LogLevel = level
+		if level != LogLevelInfo && level != LogLevelDebug {
+			invalid = append(invalid, fmt.Sprintf("LOG_LEVEL %q", level))
+		}
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing required environment variables: "+strings.Join(missing, ", "))
+	}
+	if len(invalid) > 0 {
+		problems = append(problems, "invalid values: "+strings.Join(invalid, ", "))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("Invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return cfg, nil
+}
+
+// isOrigin reports whether s is an origin as browsers send it: an http or https scheme and a host,
+// with an optional port and nothing else.
+func isOrigin(s string) bool {
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" &&
+		parsed.Path == "" && parsed.RawQuery == "" && parsed.Fragment == "" && parsed.User == nil
+}
@@ -0,0 +1,196 @@
+/**
+ *  AdminHandler serves the moderation endpoints available to administrators.
+ *
+ *  @struct   AdminHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewAdminHandler(as)   - Initializes a new AdminHandler with the required AdminService.
+ *  - SearchUsers(w, r)     - Handles GET requests to find accounts.
+ *  - VerifyUser(w, r)      - Handles POST requests to mark an account verified.
+ *  - DisableUser(w, r)     - Handles POST requests to disable or re-enable an account.
+ *  - NormalizeEmails(w, r) - Handles POST requests to move accounts to their normalized email.
+ *
+ *  @endpoints
+ *  - /api/admin/users
+ *    - HTTP Method: GET
+ *    - Query Parameters: `query` (optional) - An email, or a username or name prefix; every account
+ *      without one. `limit` (optional, 20 by default and at most 50) and `offset` page the results.
+ *    - Returns e.g. `[{ "email": "jane@example.com", "username": "jane", "role": "user", "isVerified": false, "disabled": false, ... }]`.
+ *
+ *  - /api/admin/users/verify
+ *    - HTTP Method: POST
+ *    - Body: `{ "email": "string" }`
+ *    - Marks the account verified, for users whose OTP email bounced. Returns 409 if it already is.
+ *
+ *  - /api/admin/users/disable
+ *    - HTTP Method: POST
+ *    - Body: `{ "email": "string", "disabled": true }` - `disabled` is true by default; false re-enables the account.
+ *    - A disabled account cannot log in and its tokens are rejected. Returns 400 for the caller's own account.
+ *
+ *  - /api/admin/users/normalize-emails
+ *    - HTTP Method: POST
+ *    - Moves every account created under a mixed-case or padded email to the trimmed, lowercased email.
+ *    - Returns e.g. `{ "migrated": ["Jane@Example.com"], "conflicts": ["JOHN@example.com"], "failed": [] }`;
+ *      conflicts are accounts whose normalized email belongs to another account and are left alone.
+ *
+ *  @behaviors
+ *  - The routes are wrapped in the JWT and AdminOnly middleware, so other users get 403 Forbidden.
+ *  - Unknown accounts are answered with 404 and an unreachable database with 503.
+ *
+ *  @dependencies
+ *  - services.AdminServiceInterface: Performs the administrator actions.
+ *  - utils.WriteJSON, utils.WriteJSONError: Utility functions for JSON responses.
+ *
+ *  @file      admin_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/i18n"
+	"proh2052-group6/pkg/utils"
+)
+
+// AdminHandler manages HTTP requests for administrator actions.
+type AdminHandler struct {
+	AdminService services.AdminServiceInterface // Service performing the administrator actions.
+}
+
+// NewAdminHandler initializes an AdminHandler with the given AdminService.
+func NewAdminHandler(as services.AdminServiceInterface) *AdminHandler {
+	return &AdminHandler{AdminService: as}
+}
+
+// SearchUsers handles GET requests to find accounts by email, or by username or name prefix.
+// Query Parameters: query (string, optional), limit (int, optional), offset (int, optional).
+func (ah *AdminHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	adminEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 0
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			utils.WriteJSONError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	offset := 0
+	if offsetParam := r.URL.Query().Get("offset"); offsetParam != "" {
+		var err error
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			utils.WriteJSONError(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	users, err := ah.AdminService.SearchUsers(r.Context(), adminEmail, r.URL.Query().Get("query"), limit, offset)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	utils.WriteJSON(w, users)
+}
+
+// VerifyUser handles POST requests to mark an account verified without an OTP.
+// Body: { "email": "string" }.
+func (ah *AdminHandler) VerifyUser(w http.ResponseWriter, r *http.Request) {
+	adminEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData EmailRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+	if requestData.Email == "" {
+		utils.WriteJSONError(w, "Email is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := ah.AdminService.VerifyUser(withClientInfo(r), adminEmail, requestData.Email); err != nil {
+		utils.WriteJSONError(w, err.Error(), adminErrorStatus(err))
+		return
+	}
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "admin.user_verified")})
+}
+
+// DisableUser handles POST requests to disable or re-enable an account.
+// Body: { "email": "string", "disabled": bool (optional, true by default) }.
+func (ah *AdminHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	adminEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData DisableUserRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+	if requestData.Email == "" {
+		utils.WriteJSONError(w, "Email is required", http.StatusBadRequest)
+		return
+	}
+	disabled := requestData.Disabled == nil || *requestData.Disabled
+
+	if err := ah.AdminService.SetUserDisabled(withClientInfo(r), adminEmail, requestData.Email, disabled); err != nil {
+		utils.WriteJSONError(w, err.Error(), adminErrorStatus(err))
+		return
+	}
+	if disabled {
+		utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "admin.user_disabled")})
+	} else {
+		utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "admin.user_enabled")})
+	}
+}
+
+// NormalizeEmails handles POST requests to move the accounts stored under an email that is not
+// normalized to the normalized email, and reports what happened to each.
+func (ah *AdminHandler) NormalizeEmails(w http.ResponseWriter, r *http.Request) {
+	adminEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	report, err := ah.AdminService.NormalizeUserEmails(withClientInfo(r), adminEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), adminErrorStatus(err))
+		return
+	}
+	utils.WriteJSON(w, report)
+}
+
+// adminErrorStatus maps an error from the AdminService to an HTTP status code.
+func adminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrDisableSelf):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrAlreadyVerified):
+		return http.StatusConflict
+	default:
+		return repositoryErrorStatus(err, http.StatusInternalServerError)
+	}
+}
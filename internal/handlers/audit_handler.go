@@ -0,0 +1,81 @@
+/**
+ *  AuditHandler serves the audit log of the authenticated user, so they can see when their account
+ *  was logged into and when its password was reset or changed.
+ *
+ *  @struct   AuditHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewAuditHandler(as)       - Initializes a new AuditHandler with the required AuditService.
+ *  - GetSecurityLog(w, r)      - Retrieves the latest entries of the user's audit log.
+ *  - withClientInfo(r)         - Returns the request's context carrying its client IP and User-Agent.
+ *
+ *  @endpoint
+ *  - /api/me/security-log
+ *    - Method: GET
+ *    - Response: `[{ "action": "login_succeeded", "ip": "string", "userAgent": "string", "timestamp": "..." }]`,
+ *      the latest 50 entries, newest first
+ *
+ *  @behaviors
+ *  - Users can only read their own audit log.
+ *  - The handlers whose actions are audited pass the client's IP, as determined by middleware.ClientIP,
+ *    and User-Agent to the services through withClientInfo.
+ *  - Returns 503 Service Unavailable when the database cannot be reached.
+ *
+ *  @dependencies
+ *  - AuditServiceInterface: Reads the audit log.
+ *  - utils.WriteJSON, utils.WriteJSONError: Utility functions for JSON responses.
+ *
+ *  @file      audit_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/utils"
+)
+
+// AuditHandler manages HTTP requests for the user's audit log.
+type AuditHandler struct {
+	AuditService services.AuditServiceInterface // Service reading the audit log.
+}
+
+// NewAuditHandler initializes an AuditHandler with the given AuditService.
+func NewAuditHandler(as services.AuditServiceInterface) *AuditHandler {
+	return &AuditHandler{AuditService: as}
+}
+
+// GetSecurityLog handles GET requests for the latest entries of the authenticated user's audit log.
+// Endpoint: /api/me/security-log
+func (ah *AuditHandler) GetSecurityLog(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	entries, err := ah.AuditService.ListEntries(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	utils.WriteJSON(w, entries)
+}
+
+// withClientInfo returns the context of r carrying the client's IP address and User-Agent, for the
+// services to record in the audit log.
+func withClientInfo(r *http.Request) context.Context {
+	return services.WithClientInfo(r.Context(), middleware.ClientIP(r), r.UserAgent())
+}
@@ -21,7 +21,8 @@
  *
  *  @behaviors
  *  - Returns a 400 Bad Request error if the 'country' parameter is missing.
- *  - Returns a 500 Internal Server Error if an error occurs while fetching cities.
+ *  - Returns a 504 Gateway Timeout if the external cities API does not respond in time.
+ *  - Returns a 500 Internal Server Error if another error occurs while fetching cities.
  *  - On success, returns a JSON object with a `data` field containing the list of cities.
  *
  *  @example
@@ -56,6 +57,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"proh2052-group6/internal/services"
@@ -90,7 +92,12 @@ func (ch *CityHandler) GetCities(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch the list of cities for the given country.
-	cities, err := ch.CityService.GetCitiesByCountry(country)
+	cities, err := ch.CityService.GetCitiesByCountry(r.Context(), country)
+	if errors.Is(err, services.ErrCityLookupTimeout) {
+		// Return 504 Gateway Timeout if the external API is too slow.
+		http.Error(w, "Timed out fetching cities", http.StatusGatewayTimeout)
+		return
+	}
 	if err != nil {
 		// Return 500 Internal Server Error if fetching cities fails.
 		http.Error(w, "Error fetching cities", http.StatusInternalServerError)
@@ -98,9 +105,7 @@ func (ch *CityHandler) GetCities(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Wrap the fetched cities in a JSON response with a 'data' field.
-	response := map[string]interface{}{
-		"data": cities,
-	}
+	response := CitiesResponse{Data: cities}
 
 	// Write the JSON response.
 	utils.WriteJSON(w, response)
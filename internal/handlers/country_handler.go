@@ -7,16 +7,17 @@
  *  @inherits None
  *
  *  @methods
- *  - NewCountryHandler()         - Initializes a new CountryHandler instance.
+ *  - NewCountryHandler(cs)       - Initializes a new CountryHandler with the required CountryService.
  *  - GetCountries(w, r)          - Handles GET requests to fetch a list of countries based on a search query.
  *
  *  @endpoint
  *  - /api/countries
  *    - HTTP Method: GET
- *    - Query Parameter: `search` (optional) - A substring to filter country names (minimum length: 3 characters).
+ *    - Query Parameter: `search` (optional) - A substring to filter country names (minimum length: 3 characters),
+ *      or an exact two-letter country code.
  *
  *  @behaviors
- *  - Returns an empty list if the search query is less than 3 characters.
+ *  - Returns an empty list if the search query is less than 3 characters and not a country code.
  *  - Returns a 500 Internal Server Error if there is an issue fetching countries.
  *  - On success, returns a JSON array of countries matching the search query.
  *
@@ -32,7 +33,7 @@
  *  ```
  *
  *  @dependencies
- *  - services.GetCountries: Fetches country data filtered by the search query.
+ *  - CountryServiceInterface: Searches countries by name or code.
  *
  *  @file      country_handler.go
  *  @project   DailyVerse
@@ -49,35 +50,28 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strings"
 
 	"proh2052-group6/internal/services"
 )
 
 // CountryHandler struct for handling country-related requests.
-type CountryHandler struct{}
+type CountryHandler struct {
+	CountryService services.CountryServiceInterface // Service for searching countries.
+}
 
-// NewCountryHandler initializes a new CountryHandler instance.
-func NewCountryHandler() *CountryHandler {
-	return &CountryHandler{}
+// NewCountryHandler initializes a CountryHandler with the given CountryService.
+func NewCountryHandler(cs services.CountryServiceInterface) *CountryHandler {
+	return &CountryHandler{CountryService: cs}
 }
 
 // GetCountries handles GET requests to fetch a list of countries based on a search query.
 // Endpoint: /api/countries
 // Query Parameter:
-//   - search (string, optional): Substring to filter country names. Minimum length is 3 characters.
+//   - search (string, optional): Substring to filter country names. Minimum length is 3 characters,
+//     unless it is a two-letter country code.
 func (ch *CountryHandler) GetCountries(w http.ResponseWriter, r *http.Request) {
-	// Extract and sanitize the search query from the URL.
-	searchQuery := strings.ToLower(r.URL.Query().Get("search"))
-
-	// Return an empty list if the search query is too short.
-	if len(searchQuery) < 3 {
-		json.NewEncoder(w).Encode([]services.Country{})
-		return
-	}
-
-	// Fetch the list of countries matching the search query.
-	countries, err := services.GetCountries(searchQuery)
+	// Fetch the list of countries matching the search query. Queries that are too short match nothing.
+	countries, err := ch.CountryService.SearchCountries(r.Context(), r.URL.Query().Get("search"))
 	if err != nil {
 		// Return a 500 error if there is an issue fetching countries.
 		http.Error(w, "Error fetching countries", http.StatusInternalServerError)
@@ -0,0 +1,62 @@
+/**
+ *  Shared decoding of JSON request bodies, so every handler answers a malformed, misspelled or
+ *  oversized body the same way.
+ *
+ *  @file      decode.go
+ *  @package   handlers
+ *
+ *  @methods
+ *  - decodeJSON(w, r, dst) - Decodes a request DTO, rejecting fields it does not have.
+ *
+ *  @behaviors
+ *  - A body larger than the limit of middleware.NewJSONBody is answered with a 413.
+ *  - An unknown field in a request DTO is answered with a 400 naming the field, so a typo such as
+ *    "usrname" is reported instead of silently ignored.
+ *  - Any other undecodable body is answered with a 400 "Invalid request body".
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/pkg/utils"
+)
+
+// decodeJSON decodes the request body into dst, rejecting fields dst does not have. If decoding
+// fails it writes the error response and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	return decodeBody(w, decoder, dst)
+}
+
+// decodeBody decodes one JSON value into dst and answers a failure with 413 or 400.
+func decodeBody(w http.ResponseWriter, decoder *json.Decoder, dst interface{}) bool {
+	err := decoder.Decode(dst)
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		utils.WriteJSONError(w, middleware.ErrRequestBodyTooLarge.Error(), http.StatusRequestEntityTooLarge)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for unknown fields; the field name is quoted in the message.
+		utils.WriteJSONError(w, "Unknown field "+strings.TrimPrefix(err.Error(), "json: unknown field "), http.StatusBadRequest)
+	default:
+		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	}
+	return false
+}
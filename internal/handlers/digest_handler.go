@@ -0,0 +1,72 @@
+/**
+ *  DigestHandler lets developers trigger the weekly digest by hand instead of waiting for Monday.
+ *
+ *  @struct   DigestHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewDigestHandler(ds)  - Initializes a new DigestHandler with the required DigestService.
+ *  - RunDigests(w, r)      - Sends the weekly digest to every subscriber now.
+ *
+ *  @endpoint
+ *  - /api/admin/digest/run
+ *    - Method: POST
+ *    - Response: `{ "sent": 3 }`, the number of digests sent.
+ *
+ *  @behaviors
+ *  - The route is only registered when ENABLE_ADMIN_ROUTES is "true", for development, and only
+ *    administrators may call it there.
+ *  - Digests go only to users with the digest enabled, and manual runs do not stop Monday's digest.
+ *
+ *  @dependencies
+ *  - DigestServiceInterface: Sends the digests.
+ *  - utils.WriteJSON, utils.WriteJSONError: Utility functions for JSON responses.
+ *
+ *  @file      digest_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/utils"
+)
+
+// DigestHandler manages HTTP requests for manual weekly digest runs.
+type DigestHandler struct {
+	DigestService services.DigestServiceInterface // Service sending the digests.
+}
+
+// NewDigestHandler initializes a DigestHandler with the given DigestService.
+func NewDigestHandler(ds services.DigestServiceInterface) *DigestHandler {
+	return &DigestHandler{DigestService: ds}
+}
+
+// RunDigests handles POST requests to send the weekly digest to every subscriber now.
+// Endpoint: /api/admin/digest/run
+func (dh *DigestHandler) RunDigests(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	log.Printf("Weekly digest run triggered by %s", userEmail)
+	sent, err := dh.DigestService.SendAllDigests(r.Context())
+	if err != nil {
+		utils.WriteJSONError(w, "Failed to send weekly digests", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSON(w, DigestRunResponse{Sent: sent})
+}
@@ -0,0 +1,77 @@
+/**
+ *  DocsHandler serves the OpenAPI document of the API and a Swagger UI page that renders it,
+ *  so frontend developers can look up the request and response shapes of every endpoint.
+ *
+ *  @struct   DocsHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewDocsHandler(spec)  - Initializes a new DocsHandler with the OpenAPI document as JSON.
+ *  - GetSpec(w, r)         - Handles GET /api/openapi.json.
+ *  - GetDocs(w, r)         - Handles GET /api/docs.
+ *
+ *  @endpoints
+ *  - /api/openapi.json (GET)
+ *    - Behavior: Returns the OpenAPI 3 document built by the apidoc package at startup.
+ *  - /api/docs (GET)
+ *    - Behavior: Returns an HTML page that loads Swagger UI from a CDN and points it at /api/openapi.json.
+ *
+ *  @behaviors
+ *  - Both endpoints are public, so the documentation can be read before logging in; "Authorize" in
+ *    Swagger UI takes a JWT for trying out the protected routes.
+ *
+ *  @file      docs_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"net/http"
+)
+
+// swaggerUIPage renders the document at /api/openapi.json with Swagger UI.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>DailyVerse API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// DocsHandler serves the API documentation.
+type DocsHandler struct {
+	Spec []byte // The OpenAPI document, encoded as JSON.
+}
+
+// NewDocsHandler initializes a DocsHandler that serves the given OpenAPI document.
+func NewDocsHandler(spec []byte) *DocsHandler {
+	return &DocsHandler{Spec: spec}
+}
+
+// GetSpec handles GET requests to /api/openapi.json.
+func (dh *DocsHandler) GetSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(dh.Spec)
+}
+
+// GetDocs handles GET requests to /api/docs.
+func (dh *DocsHandler) GetDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerUIPage))
+}
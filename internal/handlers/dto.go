@@ -0,0 +1,416 @@
+/**
+ *  Request and response bodies of the HTTP API. Handlers decode and encode these types, and the
+ *  apidoc package generates the OpenAPI schemas from them, so the documentation follows the code.
+ *  Request bodies are decoded strictly into these types, never into the models, so a field added to
+ *  a model, such as a role or token version, cannot be set by clients until a request type lists it.
+ *
+ *  @file      dto.go
+ *  @package   handlers
+ *
+ *  @behaviors
+ *  - JSON field names are part of the API; renaming a field is a breaking change for the frontend.
+ *  - UpdateProfileRequest uses capitalised field names, as the profile update always has. GET /api/me and
+ *    GET /api/profile both return models.UserProfile.
+ *  - UpdateProfileRequest has pointer fields, since fields left out of the request must not be changed.
+ *  - EventRequest and JournalRequest also accept the fields the API returns with an event or journal,
+ *    such as reminderSent or attachments, so a client can send back what it received. Those fields are
+ *    ignored; they are set by the server only.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"time"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+)
+
+// ErrorResponse is the body of every error written with utils.WriteJSONError.
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is the body of a 400 written with utils.WriteJSONValidationError.
+type ValidationErrorResponse struct {
+	Message string            `json:"message"` // Always "Invalid input".
+	Errors  map[string]string `json:"errors"`  // Error message per invalid field, keyed by the field's JSON name.
+}
+
+// MessageResponse is the body of a successful request that returns no data.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// SignupResponse is the body of a successful signup.
+type SignupResponse struct {
+	Message  string            `json:"message"`
+	Warnings map[string]string `json:"warnings,omitempty"` // Doubtful fields that were accepted, e.g. {"city": "..."}.
+}
+
+// TokenResponse is the body of a successful login.
+type TokenResponse struct {
+	Token string `json:"token"` // JWT to send as "Authorization: Bearer <token>".
+}
+
+// TokenMessageResponse is the body of a request that issues a new JWT, such as a verified email.
+type TokenMessageResponse struct {
+	Message string `json:"message"`
+	Token   string `json:"token"`
+}
+
+// SignupRequest is the body of POST /api/signup.
+type SignupRequest struct {
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Username  string `json:"username"`
+	Country   string `json:"country"`
+	City      string `json:"city"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+}
+
+// User returns the account to sign up. Fields such as IsVerified and the OTP are set by the service.
+func (req SignupRequest) User() models.User {
+	return models.User{
+		Email:     req.Email,
+		Password:  req.Password,
+		Username:  req.Username,
+		Country:   req.Country,
+		City:      req.City,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	}
+}
+
+// EventRequest is the body of POST /api/events/create, PUT /api/events/update, and each element of
+// POST /api/events/bulk-create.
+type EventRequest struct {
+	EventID string `json:"eventID,omitempty"` // Ignored on create; must be the updated event's ID on update.
+	Email   string `json:"email,omitempty"`   // Ignored on create; must be the user's email on update.
+
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	EventTypeID string `json:"eventTypeID"`      // "public" or "private".
+	Status      string `json:"status,omitempty"` // "tentative", "confirmed" or "cancelled"; "confirmed" by default.
+
+	Date      string     `json:"date"`                // YYYY-MM-DD, in the user's time zone.
+	Time      string     `json:"time,omitempty"`      // Legacy display time.
+	StartTime string     `json:"startTime,omitempty"` // HH:MM.
+	EndTime   string     `json:"endTime,omitempty"`   // HH:MM.
+	StartAt   *time.Time `json:"startAt,omitempty"`   // Start timestamp, used when date and times are left out.
+	EndAt     *time.Time `json:"endAt,omitempty"`     // End timestamp, used when date and times are left out.
+	AllDay    bool       `json:"allDay"`
+
+	StreetAddress string   `json:"streetAddress"`
+	PostalNumber  string   `json:"postalNumber"`
+	Latitude      *float64 `json:"latitude,omitempty"`  // Coordinates of the address; geocoded when both are left out.
+	Longitude     *float64 `json:"longitude,omitempty"` // Coordinates of the address; geocoded when both are left out.
+
+	ReminderMinutesBefore int                `json:"reminderMinutesBefore,omitempty"`
+	Recurrence            *models.Recurrence `json:"recurrence,omitempty"`
+	Tags                  []string           `json:"tags,omitempty"`
+	Color                 string             `json:"color,omitempty"`
+
+	eventServerFields
+}
+
+// eventServerFields are the fields of models.Event set by the server. They are accepted in requests
+// so a client can send back an event as it received it, and are ignored and left out of the API docs.
+type eventServerFields struct {
+	TimeZone       interface{} `json:"timeZone,omitempty" apidoc:"-"`
+	ReminderSent   interface{} `json:"reminderSent,omitempty" apidoc:"-"`
+	ExternalID     interface{} `json:"externalID,omitempty" apidoc:"-"`
+	ImportBatchID  interface{} `json:"importBatchID,omitempty" apidoc:"-"`
+	ImportedAt     interface{} `json:"importedAt,omitempty" apidoc:"-"`
+	ExceptionDates interface{} `json:"exceptionDates,omitempty" apidoc:"-"`
+	SeriesID       interface{} `json:"seriesID,omitempty" apidoc:"-"`
+	CancelledAt    interface{} `json:"cancelledAt,omitempty" apidoc:"-"`
+}
+
+// Event returns the event of userEmail described by the request, without the fields set by the server.
+func (req EventRequest) Event(userEmail string) models.Event {
+	event := models.Event{
+		Email:                 userEmail,
+		Title:                 req.Title,
+		Description:           req.Description,
+		EventTypeID:           req.EventTypeID,
+		Status:                req.Status,
+		Date:                  req.Date,
+		Time:                  req.Time,
+		StartTime:             req.StartTime,
+		EndTime:               req.EndTime,
+		EndAt:                 req.EndAt,
+		AllDay:                req.AllDay,
+		StreetAddress:         req.StreetAddress,
+		PostalNumber:          req.PostalNumber,
+		Latitude:              req.Latitude,
+		Longitude:             req.Longitude,
+		ReminderMinutesBefore: req.ReminderMinutesBefore,
+		Recurrence:            req.Recurrence,
+		Tags:                  req.Tags,
+		Color:                 req.Color,
+	}
+	if req.StartAt != nil {
+		event.StartAt = *req.StartAt
+	}
+	return event
+}
+
+// JournalRequest is the body of POST /api/journal/save and PUT /api/journal/update.
+type JournalRequest struct {
+	JournalID string `json:"journalID,omitempty"` // Ignored; the journal is identified by the journalID parameter.
+	Email     string `json:"email,omitempty"`     // Ignored; journals belong to the authenticated user.
+
+	Date     string   `json:"date"` // YYYY-MM-DD.
+	Content  string   `json:"content"`
+	Mood     string   `json:"mood,omitempty"`     // One of "great", "good", "neutral", "bad" or "awful"; empty if not logged.
+	Tags     []string `json:"tags,omitempty"`     // Lowercase labels, validated like event tags.
+	PromptID string   `json:"promptId,omitempty"` // ID of the prompt the entry was written for; ignored on update.
+
+	journalServerFields
+}
+
+// journalServerFields are the fields of models.Journal set by the server. They are accepted in
+// requests so a client can send back a journal as it received it, and are ignored and left out of
+// the API docs.
+type journalServerFields struct {
+	Attachments interface{} `json:"attachments,omitempty" apidoc:"-"`
+	DeletedAt   interface{} `json:"deletedAt,omitempty" apidoc:"-"`
+}
+
+// Journal returns the journal of userEmail described by the request, without the fields set by the server.
+func (req JournalRequest) Journal(userEmail string) models.Journal {
+	return models.Journal{
+		Email:    userEmail,
+		Date:     req.Date,
+		Content:  req.Content,
+		Mood:     req.Mood,
+		Tags:     req.Tags,
+		PromptID: req.PromptID,
+	}
+}
+
+// EmailRequest is the body of POST /api/resend-otp, POST /api/forgot-password, POST /api/friends/invite
+// and POST /api/admin/users/verify.
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+// DisableUserRequest is the body of POST /api/admin/users/disable. A missing Disabled disables the account.
+type DisableUserRequest struct {
+	Email    string `json:"email"`
+	Disabled *bool  `json:"disabled,omitempty"`
+}
+
+// FriendStatusRequest is the body of POST /api/friends/status.
+type FriendStatusRequest struct {
+	Emails []string `json:"emails"`
+}
+
+// VerifyEmailRequest is the body of POST /api/verify-email.
+type VerifyEmailRequest struct {
+	Email string `json:"email"`
+	OTP   string `json:"otp"`
+}
+
+// ResetPasswordRequest is the body of POST /api/reset-password.
+type ResetPasswordRequest struct {
+	Email       string `json:"email"`
+	OTP         string `json:"otp"`
+	NewPassword string `json:"newPassword"`
+}
+
+// UserSearchResult is one result of GET /api/users/search.
+type UserSearchResult struct {
+	Username         string `json:"username"`
+	Email            string `json:"email"`
+	FirstName        string `json:"firstName"`
+	LastName         string `json:"lastName"`
+	ImageURL         string `json:"imageUrl"`
+	FriendshipStatus string `json:"friendshipStatus"` // "none", "pending_outgoing", "pending_incoming" or "friends".
+}
+
+// EventSavedResponse is the body of a created event, or of an updated occurrence, which is saved as a new event.
+type EventSavedResponse struct {
+	Message  string `json:"message"`
+	EventID  string `json:"eventID"`
+	Geocoded *bool  `json:"geocoded,omitempty"` // Whether the event's address was found on the map; omitted without an address.
+}
+
+// DuplicateEventRequest is the body of POST /api/events/duplicate.
+type DuplicateEventRequest struct {
+	EventID string `json:"eventID"`
+	Date    string `json:"date"`            // Date of the first copy, YYYY-MM-DD.
+	Count   int    `json:"count,omitempty"` // Number of copies a week apart, at most services.MaxEventCopies; 1 by default.
+}
+
+// DuplicateEventResponse is the body of the copies created by POST /api/events/duplicate.
+type DuplicateEventResponse struct {
+	Message  string   `json:"message"`
+	EventIDs []string `json:"eventIDs"` // IDs of the copies in date order.
+}
+
+// InviteToEventRequest is the body of POST /api/events/invite.
+type InviteToEventRequest struct {
+	EventID  string `json:"eventID"`
+	Username string `json:"username"` // Username or email of the friend to invite.
+}
+
+// RespondToInvitationRequest is the body of POST /api/events/rsvp.
+type RespondToInvitationRequest struct {
+	EventID  string `json:"eventID"`
+	Response string `json:"response"` // "accept" or "decline".
+}
+
+// BulkDeleteEventsRequest is the body of POST /api/events/bulk-delete.
+type BulkDeleteEventsRequest struct {
+	EventIDs []string `json:"eventIDs"` // At most services.MaxBulkEvents IDs.
+}
+
+// UsernameOrEmailRequest is the body of the friend routes that look the other user up by username or email.
+type UsernameOrEmailRequest struct {
+	UsernameOrEmail string `json:"usernameOrEmail"`
+}
+
+// UsernameRequest is the body of DELETE /api/friends/delete and POST /api/friends/cancel.
+type UsernameRequest struct {
+	Username string `json:"username"`
+}
+
+// MarkReadRequest is the body of POST /api/notifications/read. Either ID or All must be set.
+type MarkReadRequest struct {
+	ID  string `json:"id"`
+	All bool   `json:"all"`
+}
+
+// UpdateProfileRequest is the body of PUT /api/profile. Only the fields present are changed.
+type UpdateProfileRequest struct {
+	CurrentPassword      string  // Required to change anything.
+	NewPassword          *string `json:",omitempty"` // Changing the password revokes all existing tokens.
+	Username             *string `json:",omitempty"`
+	FirstName            *string `json:",omitempty"`
+	LastName             *string `json:",omitempty"`
+	Country              *string `json:",omitempty"`
+	City                 *string `json:",omitempty"`
+	NotificationsEnabled *bool   `json:",omitempty"`
+	DigestEnabled        *bool   `json:",omitempty"`
+	ReminderEnabled      *bool   `json:",omitempty"` // Daily journal reminder; needs a ReminderTime.
+	ReminderTime         *string `json:",omitempty"` // HH:MM in the user's time zone.
+	QuietStart           *string `json:",omitempty"` // Start of the quiet hours, HH:MM in the user's time zone; set with QuietEnd.
+	QuietEnd             *string `json:",omitempty"` // End of the quiet hours; before QuietStart to wrap past midnight.
+	TimeZone             *string `json:",omitempty"` // IANA time zone such as "Europe/Oslo"; empty to use the country's.
+}
+
+// Updates returns the fields of the request that are present, keyed by their names in models.User,
+// as ProfileService.UpdateProfile takes them.
+func (req UpdateProfileRequest) Updates() map[string]interface{} {
+	updates := map[string]interface{}{"CurrentPassword": req.CurrentPassword}
+	for field, value := range map[string]*string{
+		"NewPassword":  req.NewPassword,
+		"Username":     req.Username,
+		"FirstName":    req.FirstName,
+		"LastName":     req.LastName,
+		"Country":      req.Country,
+		"City":         req.City,
+		"ReminderTime": req.ReminderTime,
+		"QuietStart":   req.QuietStart,
+		"QuietEnd":     req.QuietEnd,
+		"TimeZone":     req.TimeZone,
+	} {
+		if value != nil {
+			updates[field] = *value
+		}
+	}
+	for field, value := range map[string]*bool{
+		"NotificationsEnabled": req.NotificationsEnabled,
+		"DigestEnabled":        req.DigestEnabled,
+		"ReminderEnabled":      req.ReminderEnabled,
+	} {
+		if value != nil {
+			updates[field] = *value
+		}
+	}
+	return updates
+}
+
+// ChangeEmailRequest is the body of POST /api/profile/change-email.
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"newEmail"`
+	CurrentPassword string `json:"currentPassword"`
+}
+
+// ConfirmEmailRequest is the body of POST /api/profile/confirm-email.
+type ConfirmEmailRequest struct {
+	OTP string `json:"otp"`
+}
+
+// AvatarResponse is the body of a successful POST /api/profile/avatar.
+type AvatarResponse struct {
+	Message  string `json:"message"`
+	ImageURL string `json:"imageUrl"`
+}
+
+// JournalSavedResponse is the body of POST /api/journal/save.
+type JournalSavedResponse struct {
+	Message   string `json:"message"`
+	JournalID string `json:"journalID"`
+}
+
+// AttachmentResponse is the body of a successful POST /api/journal/attachments.
+type AttachmentResponse struct {
+	Message    string            `json:"message"`
+	Attachment models.Attachment `json:"attachment"`
+}
+
+// ImportTimetableRequest is the body of POST /api/import-ntnu-timetable.
+type ImportTimetableRequest struct {
+	ICSContent string `json:"icsContent"` // The ICS content of the timetable to import.
+	DryRun     bool   `json:"dryRun"`     // If true, validate the ICS content without saving events.
+}
+
+// ImportJournalEntry is an element of the JSON array of POST /api/journals/import. A CSV body has
+// the same columns.
+type ImportJournalEntry struct {
+	Date    string `json:"date"` // YYYY-MM-DD.
+	Content string `json:"content"`
+}
+
+// UndoImportResponse is the body of DELETE /api/import-ntnu-timetable.
+type UndoImportResponse struct {
+	Message string `json:"message"`
+	Deleted int    `json:"deleted"` // Number of imported events deleted.
+}
+
+// CitiesResponse is the body of GET /api/cities.
+type CitiesResponse struct {
+	Data []string `json:"data"`
+}
+
+// WeatherResponse is the body of GET /api/weather.
+type WeatherResponse struct {
+	City    string `json:"city"`
+	Country string `json:"country"`
+	services.Forecast
+}
+
+// FavoriteQuoteRequest is the body of POST /api/daily-verse/favorite.
+type FavoriteQuoteRequest struct {
+	QuoteID string `json:"quoteId"` // ID of the quote to save, as returned by GET /api/daily-verse.
+}
+
+// DigestRunResponse is the body of POST /api/admin/digest/run.
+type DigestRunResponse struct {
+	Sent int `json:"sent"` // Number of digests sent.
+}
+
+// HealthResponse is the body of GET /healthz.
+type HealthResponse struct {
+	Status string `json:"status"` // Always "ok".
+}
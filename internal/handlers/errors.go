@@ -0,0 +1,38 @@
+/**
+ *  Shared mapping of repository errors to HTTP status codes, so every handler answers a missing
+ *  document and an unreachable database the same way.
+ *
+ *  @file      errors.go
+ *  @package   handlers
+ *
+ *  @methods
+ *  - repositoryErrorStatus(err, fallback) - Returns 404 for ErrNotFound, 503 for ErrUnavailable and fallback otherwise.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"proh2052-group6/internal/repositories"
+)
+
+// repositoryErrorStatus maps an error that no handler-specific case matched to an HTTP status code:
+// 404 if a document was not found, 503 if the database is unavailable and fallback otherwise.
+func repositoryErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repositories.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return fallback
+	}
+}
@@ -10,31 +10,143 @@
  *  - NewEventHandler(es)         - Initializes a new EventHandler with the required EventService.
  *  - CreateEvent(w, r)           - Handles event creation requests.
  *  - GetEvent(w, r)              - Fetches a single event by its ID.
- *  - UpdateEvent(w, r)           - Updates an existing event.
+ *  - UpdateEvent(w, r)           - Replaces an existing event.
+ *  - PatchEvent(w, r)            - Updates only the given fields of an existing event.
  *  - DeleteEvent(w, r)           - Deletes an event by its ID.
- *  - GetAllEvents(w, r)          - Retrieves all events for the authenticated user.
+ *  - GetAllEvents(w, r)          - Retrieves the authenticated user's events, optionally paginated.
+ *  - InviteToEvent(w, r)         - Invites a friend to an event.
+ *  - RespondToInvitation(w, r)   - Accepts or declines an event invitation.
+ *  - GetInvitations(w, r)        - Retrieves the authenticated user's event invitations.
+ *  - GetEventTags(w, r)          - Retrieves the tags used on the authenticated user's events, with counts.
+ *  - GetNearbyEvents(w, r)       - Retrieves the authenticated user's events near a position.
+ *  - SearchEvents(w, r)          - Searches the authenticated user's events by title, description and address.
+ *  - BulkCreateEvents(w, r)      - Creates up to 100 events at once.
+ *  - BulkDeleteEvents(w, r)      - Deletes up to 100 events at once.
+ *  - CancelEvent(w, r)           - Cancels an event, keeping it for history.
+ *  - GetEventMonth(w, r)         - Summarizes the authenticated user's events per day of a month.
+ *  - ShareEvent(w, r)            - Creates a read-only link to an event for people without an account.
+ *  - RevokeEventShare(w, r)      - Deletes the read-only links to an event.
+ *  - GetSharedEvent(w, r)        - Returns the read-only view of a shared event, without authentication.
+ *  - GetFriendEvents(w, r)       - Retrieves the public events of one of the authenticated user's friends.
+ *  - GetFriendsEventFeed(w, r)   - Retrieves the upcoming public events of all of the authenticated user's friends.
+ *  - DuplicateEvent(w, r)        - Copies an event to another date, or to several dates a week apart.
  *
  *  @endpoint
  *  - /api/events/create
  *    - Method: POST
- *    - Body: Event object
+ *    - Headers: Idempotency-Key (string, optional)
+ *    - Body: EventRequest; fields the server sets, such as reminderSent, are ignored and unknown fields rejected
+ *    - Response: `{ "message": "string", "eventID": "string", "geocoded": bool }`; geocoded is only
+ *      present for events with an address and is false if the address could not be found on the map
  *  - /api/events/get
  *    - Method: GET
  *    - Query Parameter: eventID (string, required)
  *  - /api/events/update
  *    - Method: PUT
+ *    - Query Parameters: eventID (string, required), scope ("series" | "occurrence", default "series"),
+ *      date (YYYY-MM-DD, required for scope=occurrence)
+ *    - Body: EventRequest replacing the stored event; its email and eventID may be left out
+ *  - /api/events/update
+ *    - Method: PATCH
  *    - Query Parameter: eventID (string, required)
- *    - Body: Updated Event object
+ *    - Body: object with only the Event fields to change; null resets a field
+ *    - Response: the updated Event
  *  - /api/events/delete
  *    - Method: DELETE
- *    - Query Parameter: eventID (string, required)
+ *    - Query Parameters: eventID (string, required), scope ("series" | "occurrence", default "series"),
+ *      date (YYYY-MM-DD, required for scope=occurrence)
  *  - /api/events/all
+ *    - Method: GET, HEAD
+ *    - Query Parameters: from, to (YYYY-MM-DD), limit (int), pageToken (string), tag (string),
+ *      includeCancelled (bool, default false), all optional
+ *    - Response: `{ "items": [...], "nextPageToken": "string" }`, or a bare array when no parameters are given
+ *    - Conditional: the response has an ETag; a request whose If-None-Match matches it gets 304 Not Modified
+ *  - /api/events/invite
+ *    - Method: POST
+ *    - Body: `{ "eventID": "string", "username": "string" }`
+ *  - /api/events/rsvp
+ *    - Method: POST
+ *    - Body: `{ "eventID": "string", "response": "accept" | "decline" }`
+ *  - /api/events/invitations
+ *    - Method: GET
+ *  - /api/events/tags
+ *    - Method: GET
+ *    - Response: `[{ "tag": "string", "count": int }]`, most used first
+ *  - /api/events/nearby
+ *    - Method: GET
+ *    - Query Parameters: lat, lng (degrees, required), radiusKm (default 10, at most 500)
+ *    - Response: events with coordinates within the radius and their `distanceKm`, nearest first
+ *  - /api/events/search
+ *    - Method: GET
+ *    - Query Parameters: q (required), from, to (YYYY-MM-DD, required, at most one year apart)
+ *    - Response: matching events ordered by date and start time, each with its `matchedField`
+ *  - /api/events/bulk-create
+ *    - Method: POST
+ *    - Body: array of at most 100 EventRequest objects
+ *    - Response: `{ "succeeded": ["eventID"], "failed": [{ "index": int, "error": "string" }] }`
+ *  - /api/events/bulk-delete
+ *    - Method: POST
+ *    - Body: `{ "eventIDs": ["string"] }`, at most 100 IDs
+ *    - Response: `{ "succeeded": ["eventID"], "failed": [{ "index": int, "eventID": "string", "error": "string" }] }`
+ *  - /api/events/duplicate
+ *    - Method: POST
+ *    - Body: `{ "eventID": "string", "date": "YYYY-MM-DD", "count": int }`; count defaults to 1 and is at most 30
+ *    - Response: `{ "message": "string", "eventIDs": ["string"] }`, the copies on date and the following weeks
+ *  - /api/events/cancel
+ *    - Method: POST
+ *    - Query Parameter: eventID (string, required)
+ *  - /api/events/month
+ *    - Method: GET
+ *    - Query Parameter: month (YYYY-MM, required)
+ *    - Response: `{ "month", "from", "to", "weeks", "days": [{ "date", "count", "firstTitle", "colors" }] }`,
+ *      covering the Monday-to-Sunday weeks of the month and listing only days with events
+ *  - /api/events/share
+ *    - Method: POST
+ *    - Query Parameter: eventID (string, required)
+ *    - Response: `{ "token": "string", "url": "string", "expiresAt": "timestamp" }`; earlier links stop working
+ *  - /api/events/share
+ *    - Method: DELETE
+ *    - Query Parameter: eventID (string, required)
+ *  - /api/shared/events/{token}
+ *    - Method: GET, without authentication
+ *    - Response: the title, description, date, times, time zone, address and status of the event
+ *  - /api/friends/events
+ *    - Method: GET
+ *    - Query Parameters: username (string, required), from, to (YYYY-MM-DD, optional, default the 14
+ *      days starting today, at most one year apart)
+ *    - Response: the friend's public events as `[{ "eventID", "username", "title", "date", ... }]`,
+ *      ordered by date and start time, in the user's time zone
+ *  - /api/events/feed
  *    - Method: GET
+ *    - Response: the public events of all friends in the next 14 days, in the same form and order
  *
  *  @behaviors
- *  - Returns 400 Bad Request for missing or invalid inputs.
- *  - Returns 404 Not Found for non-existent event IDs.
- *  - Returns 500 Internal Server Error for service-layer failures.
+ *  - Returns 400 Bad Request for missing or invalid inputs, including invalid recurrence rules.
+ *    Invalid fields such as an empty title are listed per field:
+ *    `{ "message": "Invalid input", "errors": { "title": "required" } }`.
+ *  - Events may carry a `recurrence` rule; with both from and to, /api/events/all lists each occurrence.
+ *  - Events may be sent with `date`, `startTime` and `endTime` in the user's time zone, or with the
+ *    `startAt` and `endAt` timestamps instead. Events are returned with both, the strings in the
+ *    user's current time zone (`timeZone`). A time skipped by a daylight saving time change returns 400.
+ *  - Events may have a `color` ("#RRGGBB" or a palette name such as "blue") and be `allDay`, in which
+ *    case they must not have a startTime or endTime.
+ *  - Events have a `status` of "tentative" or "confirmed" (the default). Cancelled events keep their
+ *    data with status "cancelled" and a `cancelledAt` time; changing them returns 409 Conflict.
+ *  - scope=occurrence changes or deletes only the occurrence on `date`; otherwise the whole series is affected.
+ *  - A create request with an Idempotency-Key that was already used for the same request returns the
+ *    original event ID with an `Idempotent-Replayed: true` header instead of creating a duplicate.
+ *    Reusing the key for a different request returns 422, and retrying before the first request has
+ *    finished returns 409.
+ *  - Returns 404 Not Found for non-existent event IDs and for events owned by someone else.
+ *  - Shared links return 404 Not Found when unknown, revoked or when the event was deleted, and
+ *    410 Gone once expired. Sharing a cancelled event returns 409.
+ *  - Updates that change an event's email or eventID, and patches with unknown fields, return 400.
+ *  - Friends' events never include private or cancelled events. Asking for the events of a user who
+ *    is not an accepted friend returns 403 Forbidden, and of an unknown username 404.
+ *  - Bulk requests succeed or fail per event and respond with 200 OK and the outcome of each;
+ *    they return 400 only when the body is invalid, empty or longer than 100 events.
+ *  - Returns 503 Service Unavailable when the database cannot be reached.
+ *  - Returns 500 Internal Server Error for other service-layer failures.
  *  - On success, responds with appropriate HTTP status codes and data.
  *
  *  @dependencies
@@ -55,9 +167,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/gorilla/mux"
+
+	"proh2052-group6/internal/middleware"
 	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/i18n"
 	"proh2052-group6/pkg/models"
 	"proh2052-group6/pkg/utils"
 )
@@ -73,42 +191,72 @@ func NewEventHandler(es services.EventServiceInterface) *EventHandler {
 }
 
 // CreateEvent handles POST requests to create a new event.
-// Body: JSON-encoded Event object.
+// Body: JSON-encoded EventRequest. Header: Idempotency-Key (optional), to make retries safe.
 func (eh *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
-	var event models.Event
-	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	// Attach user email from context to the event.
-	userEmail := r.Context().Value("userEmail").(string)
-	event.Email = userEmail
+	var requestData EventRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+	event := requestData.Event(userEmail)
 
-	if err := eh.EventService.CreateEvent(r.Context(), &event); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+	replayed, err := eh.EventService.CreateEventIdempotent(r.Context(), &event, r.Header.Get("Idempotency-Key"))
+	if err != nil {
+		writeServiceError(w, err, createEventErrorStatus(err))
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{
-		"message": "Event created successfully",
-		"eventID": event.EventID,
-	})
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	response := EventSavedResponse{
+		Message: i18n.T(r.Context(), "event.created"),
+		EventID: event.EventID,
+	}
+	if event.StreetAddress != "" || event.PostalNumber != "" {
+		geocoded := event.Latitude != nil && event.Longitude != nil
+		response.Geocoded = &geocoded
+	}
+	utils.WriteJSON(w, response)
+}
+
+// createEventErrorStatus maps errors from creating an event, including Idempotency-Key errors, to HTTP status codes.
+func createEventErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidIdempotencyKey):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrIdempotencyRequestInProgress):
+		return http.StatusConflict
+	default:
+		return eventErrorStatus(err)
+	}
 }
 
 // GetEvent handles GET requests to fetch a specific event by its ID.
 // Query Parameter: eventID (string, required).
 func (eh *EventHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	eventID := r.URL.Query().Get("eventID")
 	if eventID == "" {
 		utils.WriteJSONError(w, "Missing eventID parameter", http.StatusBadRequest)
 		return
 	}
 
-	userEmail := r.Context().Value("userEmail").(string)
 	event, err := eh.EventService.GetEvent(r.Context(), userEmail, eventID)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		writeServiceError(w, err, eventErrorStatus(err))
 		return
 	}
 
@@ -116,60 +264,642 @@ func (eh *EventHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
 }
 
 // UpdateEvent handles PUT requests to update an existing event.
-// Query Parameter: eventID (string, required).
-// Body: JSON-encoded Event object with updated details.
+// Query Parameters: eventID (string, required), scope ("series" or "occurrence"), date (required for occurrences).
+// Body: JSON-encoded EventRequest with updated details.
 func (eh *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	eventID := r.URL.Query().Get("eventID")
 	if eventID == "" {
 		utils.WriteJSONError(w, "Missing eventID parameter", http.StatusBadRequest)
 		return
 	}
 
-	var event models.Event
-	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	occurrenceDate, ok := occurrenceScope(w, r)
+	if !ok {
 		return
 	}
 
-	// Attach user email and event ID to the event.
-	userEmail := r.Context().Value("userEmail").(string)
-	event.Email = userEmail
+	var requestData EventRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+	if (requestData.Email != "" && requestData.Email != userEmail) || (requestData.EventID != "" && requestData.EventID != eventID) {
+		utils.WriteJSONError(w, services.ErrImmutableEventField.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event := requestData.Event(userEmail)
 	event.EventID = eventID
 
+	if occurrenceDate != "" {
+		if err := eh.EventService.UpdateOccurrence(r.Context(), &event, occurrenceDate); err != nil {
+			writeServiceError(w, err, eventErrorStatus(err))
+			return
+		}
+		utils.WriteJSON(w, EventSavedResponse{
+			Message: i18n.T(r.Context(), "event.updated"),
+			EventID: event.EventID,
+		})
+		return
+	}
+
 	if err := eh.EventService.UpdateEvent(r.Context(), &event); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, eventErrorStatus(err))
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Event updated successfully"})
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "event.updated")})
+}
+
+// PatchEvent handles PATCH requests to update only the fields of an event present in the body.
+// Query Parameter: eventID (string, required). Body: JSON object with the Event fields to change.
+func (eh *EventHandler) PatchEvent(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	eventID := r.URL.Query().Get("eventID")
+	if eventID == "" {
+		utils.WriteJSONError(w, "Missing eventID parameter", http.StatusBadRequest)
+		return
+	}
+	if scope := r.URL.Query().Get("scope"); scope != "" && scope != "series" {
+		utils.WriteJSONError(w, "Use PUT to change a single occurrence", http.StatusBadRequest)
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if !decodeJSON(w, r, &patch) {
+		return
+	}
+	if patch == nil {
+		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	event, err := eh.EventService.PatchEvent(r.Context(), userEmail, eventID, patch)
+	if err != nil {
+		writeServiceError(w, err, eventErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, event)
 }
 
 // DeleteEvent handles DELETE requests to remove an event by its ID.
-// Query Parameter: eventID (string, required).
+// Query Parameters: eventID (string, required), scope ("series" or "occurrence"), date (required for occurrences).
 func (eh *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	eventID := r.URL.Query().Get("eventID")
 	if eventID == "" {
 		utils.WriteJSONError(w, "Missing eventID parameter", http.StatusBadRequest)
 		return
 	}
 
-	userEmail := r.Context().Value("userEmail").(string)
+	occurrenceDate, ok := occurrenceScope(w, r)
+	if !ok {
+		return
+	}
 
-	if err := eh.EventService.DeleteEvent(r.Context(), userEmail, eventID); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+	var err error
+	if occurrenceDate != "" {
+		err = eh.EventService.DeleteOccurrence(r.Context(), userEmail, eventID, occurrenceDate)
+	} else {
+		err = eh.EventService.DeleteEvent(r.Context(), userEmail, eventID)
+	}
+	if err != nil {
+		writeServiceError(w, err, eventErrorStatus(err))
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Event deleted successfully"})
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "event.deleted")})
+}
+
+// occurrenceScope reads the scope and date query parameters of an update or delete request.
+// It returns the occurrence date for scope=occurrence, or an empty string for the entire series.
+// On invalid parameters it writes a 400 response and returns false.
+func occurrenceScope(w http.ResponseWriter, r *http.Request) (string, bool) {
+	switch r.URL.Query().Get("scope") {
+	case "", "series":
+		return "", true
+	case "occurrence":
+		date := r.URL.Query().Get("date")
+		if date == "" {
+			utils.WriteJSONError(w, "Missing date parameter", http.StatusBadRequest)
+			return "", false
+		}
+		return date, true
+	default:
+		utils.WriteJSONError(w, "scope must be 'series' or 'occurrence'", http.StatusBadRequest)
+		return "", false
+	}
 }
 
-// GetAllEvents handles GET requests to fetch all events for the authenticated user.
+// eventErrorStatus maps an error from the EventService to an HTTP status code.
+func eventErrorStatus(err error) int {
+	if errors.Is(err, services.ErrNonexistentLocalTime) || errors.Is(err, services.ErrEventSpansDays) ||
+		errors.Is(err, services.ErrInvalidEventStatus) || errors.Is(err, services.ErrImmutableEventField) ||
+		errors.Is(err, services.ErrUnknownEventField) || errors.Is(err, services.ErrInvalidEventField) ||
+		errors.Is(err, services.ErrInvalidEventColor) || errors.Is(err, services.ErrAllDayEventTimes) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, services.ErrEventCancelled) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, services.ErrEventShareNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, services.ErrEventShareExpired) {
+		return http.StatusGone
+	}
+	if errors.Is(err, services.ErrEventSharingDisabled) {
+		return http.StatusServiceUnavailable
+	}
+	if errors.Is(err, services.ErrNotFriends) {
+		return http.StatusForbidden
+	}
+	switch err.Error() {
+	case "Recurrence frequency must be 'daily' or 'weekly'",
+		"Recurrence interval must be a positive number",
+		"Recurrence cannot have both until and count",
+		"Recurrence count must be a positive number",
+		"Recurrence until date must not be before the event date",
+		"Invalid start time format. Please use HH:MM.",
+		"Invalid event type",
+		"An event can have at most 10 tags",
+		"Tags must be between 1 and 30 characters",
+		"Duplicate tags are not allowed",
+		"Event is not recurring",
+		"Date is not an occurrence of this event",
+		"Date range must not exceed one year",
+		"to must not be before today":
+		return http.StatusBadRequest
+	case "Event not found", "Unauthorized to access this event", "User not found":
+		return http.StatusNotFound
+	default:
+		return repositoryErrorStatus(err, http.StatusInternalServerError)
+	}
+}
+
+// GetAllEvents handles GET requests to fetch the events of the authenticated user.
+// Query Parameters: from, to (YYYY-MM-DD), limit (int), pageToken (string), tag (string) and
+// includeCancelled (bool), all optional.
+// Without any of these parameters the response is a bare array of all events, for backward compatibility.
 func (eh *EventHandler) GetAllEvents(w http.ResponseWriter, r *http.Request) {
-	userEmail := r.Context().Value("userEmail").(string)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	query := models.EventQuery{
+		From:      params.Get("from"),
+		To:        params.Get("to"),
+		PageToken: params.Get("pageToken"),
+		Tag:       params.Get("tag"),
+	}
+	if limit := params.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			utils.WriteJSONError(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		query.Limit = parsed
+	}
+	if includeCancelled := params.Get("includeCancelled"); includeCancelled != "" {
+		parsed, err := strconv.ParseBool(includeCancelled)
+		if err != nil {
+			utils.WriteJSONError(w, "Invalid includeCancelled parameter", http.StatusBadRequest)
+			return
+		}
+		query.IncludeCancelled = parsed
+	}
+
+	page, err := eh.EventService.GetAllEvents(r.Context(), userEmail, query)
+	if err != nil {
+		switch err.Error() {
+		case "limit must be a positive number", "Invalid page token":
+			utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeServiceError(w, err, repositoryErrorStatus(err, http.StatusInternalServerError))
+		}
+		return
+	}
+
+	if query == (models.EventQuery{}) {
+		utils.WriteJSONWithETag(w, r, page.Items)
+		return
+	}
+
+	utils.WriteJSONWithETag(w, r, page)
+}
+
+// InviteToEvent handles POST requests to invite a friend to one of the user's events.
+// Body: { "eventID": "string", "username": "string" }.
+func (eh *EventHandler) InviteToEvent(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData InviteToEventRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if requestData.EventID == "" || requestData.Username == "" {
+		utils.WriteJSONError(w, "eventID and username are required", http.StatusBadRequest)
+		return
+	}
+
+	err := eh.EventService.InviteToEvent(r.Context(), userEmail, requestData.EventID, requestData.Username)
+	if err != nil {
+		switch err.Error() {
+		case "Event not found", "User not found":
+			utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		case services.ErrEventCancelled.Error():
+			utils.WriteJSONError(w, err.Error(), http.StatusConflict)
+		default:
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusBadRequest))
+		}
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "event.invitation_sent")})
+}
+
+// RespondToInvitation handles POST requests to accept or decline an event invitation.
+// Body: { "eventID": "string", "response": "accept" | "decline" }.
+func (eh *EventHandler) RespondToInvitation(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData RespondToInvitationRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if requestData.EventID == "" {
+		utils.WriteJSONError(w, "Missing eventID parameter", http.StatusBadRequest)
+		return
+	}
+
+	err := eh.EventService.RespondToInvitation(r.Context(), userEmail, requestData.EventID, requestData.Response)
+	if err != nil {
+		switch err.Error() {
+		case "Invitation not found":
+			utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		default:
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusBadRequest))
+		}
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "event.invitation_response_saved")})
+}
+
+// GetInvitations handles GET requests to fetch all event invitations for the authenticated user.
+func (eh *EventHandler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	invitations, err := eh.EventService.GetInvitations(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, invitations)
+}
+
+// GetEventTags handles GET requests to list the tags on the authenticated user's events with their counts.
+func (eh *EventHandler) GetEventTags(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tags, err := eh.EventService.GetEventTags(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, tags)
+}
+
+// defaultNearbyRadiusKm is the search radius of /api/events/nearby when radiusKm is not given.
+const defaultNearbyRadiusKm = 10.0
+
+// GetNearbyEvents handles GET requests to list the authenticated user's events near a position.
+// Query Parameters: lat, lng (degrees, required), radiusKm (optional).
+func (eh *EventHandler) GetNearbyEvents(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	if params.Get("lat") == "" || params.Get("lng") == "" {
+		utils.WriteJSONError(w, "lat and lng are required", http.StatusBadRequest)
+		return
+	}
+	latitude, latErr := strconv.ParseFloat(params.Get("lat"), 64)
+	longitude, lngErr := strconv.ParseFloat(params.Get("lng"), 64)
+	radiusKm := defaultNearbyRadiusKm
+	var radiusErr error
+	if radius := params.Get("radiusKm"); radius != "" {
+		radiusKm, radiusErr = strconv.ParseFloat(radius, 64)
+	}
+	if latErr != nil || lngErr != nil || radiusErr != nil {
+		utils.WriteJSONError(w, "lat, lng and radiusKm must be numbers", http.StatusBadRequest)
+		return
+	}
+
+	events, err := eh.EventService.GetNearbyEvents(r.Context(), userEmail, latitude, longitude, radiusKm)
+	if err != nil {
+		switch err.Error() {
+		case "lat must be between -90 and 90", "lng must be between -180 and 180",
+			"radiusKm must be greater than 0 and at most 500":
+			utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+		default:
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		}
+		return
+	}
+
+	utils.WriteJSON(w, events)
+}
+
+// SearchEvents handles GET requests to search the authenticated user's events.
+// Query Parameters: q, the text to find in the title, description or street address, and from
+// and to (YYYY-MM-DD), the inclusive window to search, at most one year long.
+func (eh *EventHandler) SearchEvents(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	events, err := eh.EventService.SearchEvents(r.Context(), userEmail, params.Get("q"), params.Get("from"), params.Get("to"))
+	if err != nil {
+		switch err.Error() {
+		case "q is required", "from and to are required", "Search range must not exceed one year":
+			utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeServiceError(w, err, repositoryErrorStatus(err, http.StatusInternalServerError))
+		}
+		return
+	}
+
+	utils.WriteJSON(w, events)
+}
+
+// BulkCreateEvents handles POST requests to create up to services.MaxBulkEvents events at once.
+// Body: JSON array of EventRequest objects. Each event is validated and created on its own.
+func (eh *EventHandler) BulkCreateEvents(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData []EventRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+	events := make([]models.Event, len(requestData))
+	for i, request := range requestData {
+		events[i] = request.Event(userEmail)
+	}
+
+	result, err := eh.EventService.BulkCreateEvents(r.Context(), userEmail, events)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), bulkEventErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, result)
+}
+
+// BulkDeleteEvents handles POST requests to delete up to services.MaxBulkEvents events at once.
+// Body: { "eventIDs": ["string"] }. IDs of events the user does not own are reported as failed.
+func (eh *EventHandler) BulkDeleteEvents(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData BulkDeleteEventsRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	result, err := eh.EventService.BulkDeleteEvents(r.Context(), userEmail, requestData.EventIDs)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), bulkEventErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, result)
+}
+
+// bulkEventErrorStatus maps an error that failed a whole bulk request to an HTTP status code.
+func bulkEventErrorStatus(err error) int {
+	if errors.Is(err, services.ErrNoBulkEvents) || errors.Is(err, services.ErrTooManyBulkEvents) {
+		return http.StatusBadRequest
+	}
+	return repositoryErrorStatus(err, http.StatusInternalServerError)
+}
+
+// DuplicateEvent handles POST requests to copy one of the user's events to other dates.
+// Body: { "eventID": "string", "date": "YYYY-MM-DD", "count": int }. The copies are a week apart.
+func (eh *EventHandler) DuplicateEvent(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData DuplicateEventRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+	if requestData.EventID == "" {
+		utils.WriteJSONError(w, "Missing eventID", http.StatusBadRequest)
+		return
+	}
+
+	eventIDs, err := eh.EventService.DuplicateEvent(r.Context(), userEmail, requestData.EventID, requestData.Date, requestData.Count)
+	if err != nil {
+		writeServiceError(w, err, eventErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, DuplicateEventResponse{Message: i18n.T(r.Context(), "event.duplicated"), EventIDs: eventIDs})
+}
+
+// CancelEvent handles POST requests to cancel one of the user's events.
+// Query Parameter: eventID (string). The event is kept with status "cancelled".
+func (eh *EventHandler) CancelEvent(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	eventID := r.URL.Query().Get("eventID")
+	if eventID == "" {
+		utils.WriteJSONError(w, "Missing eventID parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := eh.EventService.CancelEvent(r.Context(), userEmail, eventID); err != nil {
+		writeServiceError(w, err, eventErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "event.cancelled")})
+}
+
+// GetEventMonth handles GET requests to summarize the authenticated user's events per day of a month.
+// Query Parameter: month (YYYY-MM, required). The summary covers the whole weeks the month is shown in.
+func (eh *EventHandler) GetEventMonth(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	month, err := eh.EventService.GetEventMonth(r.Context(), userEmail, r.URL.Query().Get("month"))
+	if err != nil {
+		writeServiceError(w, err, repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, month)
+}
+
+// ShareEvent handles POST requests to create a read-only link to one of the user's events.
+// Query Parameter: eventID (string). Earlier links to the event stop working.
+func (eh *EventHandler) ShareEvent(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	eventID := r.URL.Query().Get("eventID")
+	if eventID == "" {
+		utils.WriteJSONError(w, "Missing eventID parameter", http.StatusBadRequest)
+		return
+	}
+
+	link, err := eh.EventService.ShareEvent(r.Context(), userEmail, eventID)
+	if err != nil {
+		writeServiceError(w, err, eventErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, link)
+}
+
+// RevokeEventShare handles DELETE requests to delete the read-only links to one of the user's events.
+// Query Parameter: eventID (string).
+func (eh *EventHandler) RevokeEventShare(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	eventID := r.URL.Query().Get("eventID")
+	if eventID == "" {
+		utils.WriteJSONError(w, "Missing eventID parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := eh.EventService.RevokeEventShare(r.Context(), userEmail, eventID); err != nil {
+		writeServiceError(w, err, eventErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "event.link_revoked")})
+}
+
+// GetSharedEvent handles GET requests for the read-only view of a shared event. It is served without
+// authentication; the token in the path is the only credential.
+func (eh *EventHandler) GetSharedEvent(w http.ResponseWriter, r *http.Request) {
+	event, err := eh.EventService.GetSharedEvent(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		writeServiceError(w, err, eventErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, event)
+}
+
+// GetFriendEvents handles GET requests for the public events of one of the user's friends.
+// Query Parameters: username (string), from and to (YYYY-MM-DD, optional).
+func (eh *EventHandler) GetFriendEvents(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	username := params.Get("username")
+	if username == "" {
+		utils.WriteJSONError(w, "Missing username parameter", http.StatusBadRequest)
+		return
+	}
+
+	events, err := eh.EventService.GetFriendEvents(r.Context(), userEmail, username, params.Get("from"), params.Get("to"))
+	if err != nil {
+		writeServiceError(w, err, eventErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, events)
+}
+
+// GetFriendsEventFeed handles GET requests for the public events of all of the user's friends in the
+// next services.FriendFeedDays days.
+func (eh *EventHandler) GetFriendsEventFeed(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
-	events, err := eh.EventService.GetAllEvents(r.Context(), userEmail)
+	events, err := eh.EventService.GetFriendsEventFeed(r.Context(), userEmail)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, eventErrorStatus(err))
 		return
 	}
 
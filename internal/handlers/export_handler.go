@@ -0,0 +1,80 @@
+/**
+ *  ExportHandler handles requests for a user's data export, a ZIP archive of everything
+ *  stored about the authenticated user.
+ *
+ *  @struct   ExportHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewExportHandler(es)  - Initializes a new ExportHandler with the required ExportService.
+ *  - ExportData(w, r)      - Streams the authenticated user's data export.
+ *
+ *  @endpoint
+ *  - /api/me/export
+ *    - Method: GET
+ *    - Response: `dailyverse-export.zip` with profile.json, events.json, journals.json and friends.json
+ *
+ *  @behaviors
+ *  - The archive is streamed to the client while it is assembled, never buffered in full.
+ *  - Errors before the download starts are answered with a JSON error, 503 if the database cannot be
+ *    reached; later errors are logged and end the download, leaving an incomplete archive.
+ *  - The route is rate limited per user, since an export reads all of the user's data.
+ *
+ *  @dependencies
+ *  - ExportServiceInterface: Assembles the export.
+ *  - utils.WriteJSONError: Utility function for JSON error responses.
+ *
+ *  @file      export_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/utils"
+)
+
+// ExportHandler manages HTTP requests for data exports.
+type ExportHandler struct {
+	ExportService services.ExportServiceInterface // Service assembling the export.
+}
+
+// NewExportHandler initializes an ExportHandler with the given ExportService.
+func NewExportHandler(es services.ExportServiceInterface) *ExportHandler {
+	return &ExportHandler{ExportService: es}
+}
+
+// ExportData handles GET requests to download a ZIP archive of the authenticated user's data.
+// Endpoint: /api/me/export
+func (eh *ExportHandler) ExportData(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	out := &exportWriter{ResponseWriter: w, contentType: "application/zip", filename: "dailyverse-export.zip"}
+	if err := eh.ExportService.ExportUserData(r.Context(), userEmail, out); err != nil {
+		// Once the download has started, the status can no longer be changed.
+		if out.started {
+			log.Printf("Failed to export data for %s: %v", userEmail, err)
+			return
+		}
+		if err.Error() == "User not found" {
+			utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+	}
+}
@@ -15,12 +15,21 @@
  *  - GetPendingFriendRequests(w, r)    - Handles GET requests to fetch pending friend requests for a user.
  *  - DeclineFriendRequest(w, r)        - Handles POST requests to decline a friend request.
  *  - CancelFriendRequest(w, r)         - Handles DELETE requests to cancel a sent friend request.
+ *  - BlockUser(w, r)                   - Handles POST requests to block a user.
+ *  - UnblockUser(w, r)                 - Handles POST requests to unblock a user.
+ *  - GetBlockedUsers(w, r)             - Handles GET requests to fetch the users blocked by the user.
+ *  - GetFriendSuggestions(w, r)        - Handles GET requests to suggest friends of friends.
+ *  - GetMutualFriends(w, r)            - Handles GET requests to fetch the friends shared with another user.
+ *  - InviteFriend(w, r)                - Handles POST requests to befriend or invite someone by email.
+ *  - GetRelationshipStatuses(w, r)     - Handles POST requests to look up the relationship with several users.
  *
  *  @endpoints
  *  - /api/friends/send
  *    - HTTP Method: POST
  *    - Body: `{ "usernameOrEmail": "string" }`
- *    - Sends a friend request to the specified user by username or email.
+ *    - Sends a friend request to the specified user by username or email. If that user already
+ *      sent a request, it is accepted and the message is "Friend request accepted".
+ *    - Returns 429 if the user declined a request from the sender less than a day ago.
  *
  *  - /api/friends/accept
  *    - HTTP Method: POST
@@ -29,31 +38,72 @@
  *
  *  - /api/friends/list
  *    - HTTP Method: GET
- *    - Fetches the list of friends for the authenticated user.
+ *    - Fetches summaries of the authenticated user's friends, with the date each friendship began.
  *
  *  - /api/friends/remove
  *    - HTTP Method: DELETE
  *    - Body: `{ "username": "string" }`
- *    - Removes the specified user from the authenticated user's friend list.
+ *    - Removes the specified user from the authenticated user's friend list; returns 404 if they are not friends.
  *
  *  - /api/friends/pending
  *    - HTTP Method: GET
- *    - Fetches the pending friend requests for the authenticated user.
+ *    - Fetches the pending friend requests for the authenticated user. Requests expire after 30 days.
  *
  *  - /api/friends/decline
  *    - HTTP Method: POST
  *    - Body: `{ "usernameOrEmail": "string" }`
- *    - Declines a friend request from the specified user by username or email.
+ *    - Declines a friend request from the specified user by username or email; returns 404 if
+ *      there is no pending request from them.
  *
  *  - /api/friends/cancel
  *    - HTTP Method: DELETE
  *    - Body: `{ "username": "string" }`
  *    - Cancels a sent friend request to the specified user.
  *
+ *  - /api/friends/block
+ *    - HTTP Method: POST
+ *    - Body: `{ "usernameOrEmail": "string" }`
+ *    - Blocks the specified user and removes any pending request or friendship with them.
+ *
+ *  - /api/friends/unblock
+ *    - HTTP Method: POST
+ *    - Body: `{ "usernameOrEmail": "string" }`
+ *    - Unblocks the specified user.
+ *
+ *  - /api/friends/blocked
+ *    - HTTP Method: GET
+ *    - Fetches the users blocked by the authenticated user.
+ *
+ *  - /api/friends/suggestions
+ *    - HTTP Method: GET
+ *    - Query Parameter: `limit` (optional) - Maximum number of suggestions, 10 by default and at most 50.
+ *    - Suggests friends of friends ranked by mutual friends, e.g. `[{ "username": "jane_doe", ..., "mutualFriends": 3 }]`.
+ *
+ *  - /api/friends/mutual
+ *    - HTTP Method: GET
+ *    - Query Parameter: `username` (required) - The username or email of the other user.
+ *    - Fetches the friends the authenticated user has in common with the other user.
+ *
+ *  - /api/friends/invite
+ *    - HTTP Method: POST
+ *    - Body: `{ "email": "string" }`
+ *    - Sends a friend request like /api/friends/add if the email is registered. Otherwise emails the
+ *      address an invitation to sign up, and the message is "Invitation sent"; the friend request is
+ *      created when they sign up.
+ *    - Returns 409 if the user already invited the address, and 429 after 10 invitations in a day.
+ *
+ *  - /api/friends/status
+ *    - HTTP Method: POST
+ *    - Body: `{ "emails": ["a@example.com", "b@example.com"] }` - At most 50 emails.
+ *    - Returns the relationship with each user, e.g. `{ "a@example.com": "friends", "b@example.com": "none" }`:
+ *      "none", "friends", "pending_incoming", "pending_outgoing" or "blocked". Unknown emails are "none".
+ *    - Returns 400 if an entry is not an email address or there are more than 50.
+ *
  *  @behaviors
  *  - Validates request payloads and responds with appropriate error messages for invalid inputs.
  *  - Ensures user authentication via `userEmail` in the request context.
- *  - Returns meaningful status codes based on the success or failure of operations.
+ *  - Returns meaningful status codes based on the success or failure of operations, and 503 Service
+ *    Unavailable when the database cannot be reached.
  *
  *  @example
  *  ```
@@ -66,8 +116,8 @@
  *  GET /api/friends/list
  *  Response:
  *  [
- *      { "username": "john_doe", "email": "john.doe@example.com" },
- *      { "username": "jane_doe", "email": "jane.doe@example.com" }
+ *      { "username": "john_doe", "email": "john.doe@example.com", "country": "Norway", "city": "Oslo", "friendsSince": "2024-11-01T12:00:00Z" },
+ *      { "username": "jane_doe", "email": "jane.doe@example.com", "country": "Norway", "city": "Bergen" }
  *  ]
  *  ```
  *
@@ -88,10 +138,13 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
+	"proh2052-group6/internal/middleware"
 	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/i18n"
 	"proh2052-group6/pkg/utils"
 )
 
@@ -107,11 +160,14 @@ func NewFriendHandler(fs services.FriendServiceInterface) *FriendHandler {
 
 // SendFriendRequest handles POST requests to send a friend request to a user.
 func (fh *FriendHandler) SendFriendRequest(w http.ResponseWriter, r *http.Request) {
-	var requestData struct {
-		UsernameOrEmail string `json:"usernameOrEmail"`
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+
+	var requestData UsernameOrEmailRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
 
@@ -120,39 +176,81 @@ func (fh *FriendHandler) SendFriendRequest(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	userEmail, ok := r.Context().Value("userEmail").(string)
+	accepted, err := fh.FriendService.SendFriendRequest(r.Context(), userEmail, requestData.UsernameOrEmail)
+	if errors.Is(err, services.ErrFriendRequestCooldown) {
+		utils.WriteJSONError(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	// The other user had already sent a request, which was accepted instead.
+	if accepted {
+		utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.request_accepted")})
+		return
+	}
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.request_sent")})
+}
+
+// InviteFriend handles POST requests to send a friend request to an email address, inviting its
+// owner to sign up if they have no account yet.
+func (fh *FriendHandler) InviteFriend(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
 	if !ok {
 		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	err := fh.FriendService.SendFriendRequest(r.Context(), userEmail, requestData.UsernameOrEmail)
-	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+	var requestData EmailRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if requestData.Email == "" {
+		utils.WriteJSONError(w, "Email is required", http.StatusBadRequest)
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Friend request sent"})
+	outcome, err := fh.FriendService.InviteFriend(r.Context(), userEmail, requestData.Email)
+	switch {
+	case errors.Is(err, services.ErrFriendRequestCooldown):
+		utils.WriteJSONError(w, err.Error(), http.StatusTooManyRequests)
+		return
+	case errors.Is(err, services.ErrFriendInvitationExists):
+		utils.WriteJSONError(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	switch outcome {
+	case services.InviteRequestAccepted:
+		utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.request_accepted")})
+	case services.InviteInvitationSent:
+		utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.invitation_sent")})
+	default:
+		utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.request_sent")})
+	}
 }
 
 // AcceptFriendRequest handles POST requests to accept a friend request.
 func (fh *FriendHandler) AcceptFriendRequest(w http.ResponseWriter, r *http.Request) {
-	var requestData struct {
-		UsernameOrEmail string `json:"usernameOrEmail"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	if requestData.UsernameOrEmail == "" {
-		utils.WriteJSONError(w, "Username or Email is required", http.StatusBadRequest)
+	var requestData UsernameOrEmailRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
 
-	userEmail, ok := r.Context().Value("userEmail").(string)
-	if !ok {
-		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+	if requestData.UsernameOrEmail == "" {
+		utils.WriteJSONError(w, "Username or Email is required", http.StatusBadRequest)
 		return
 	}
 
@@ -162,21 +260,25 @@ func (fh *FriendHandler) AcceptFriendRequest(w http.ResponseWriter, r *http.Requ
 		case "User not found", "Friend request not found":
 			utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
 		default:
-			utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusBadRequest))
 		}
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Friend request accepted"})
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.request_accepted")})
 }
 
 // GetFriendsList handles GET requests to fetch the authenticated user's friends list.
 func (fh *FriendHandler) GetFriendsList(w http.ResponseWriter, r *http.Request) {
-	userEmail := r.Context().Value("userEmail").(string)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
 	friends, err := fh.FriendService.GetFriendsList(r.Context(), userEmail)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -185,31 +287,41 @@ func (fh *FriendHandler) GetFriendsList(w http.ResponseWriter, r *http.Request)
 
 // RemoveFriend handles DELETE requests to remove a friend from the user's friend list.
 func (fh *FriendHandler) RemoveFriend(w http.ResponseWriter, r *http.Request) {
-	var requestData struct {
-		Username string `json:"username"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	userEmail := r.Context().Value("userEmail").(string)
+	var requestData UsernameRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
 
 	if err := fh.FriendService.RemoveFriend(r.Context(), userEmail, requestData.Username); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		switch err.Error() {
+		case "User not found", "Friend not found":
+			utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		default:
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		}
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Friend removed"})
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.removed")})
 }
 
 // GetPendingFriendRequests handles GET requests to fetch pending friend requests for the user.
 func (fh *FriendHandler) GetPendingFriendRequests(w http.ResponseWriter, r *http.Request) {
-	userEmail := r.Context().Value("userEmail").(string)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
 	requests, err := fh.FriendService.GetPendingFriendRequests(r.Context(), userEmail)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -218,11 +330,14 @@ func (fh *FriendHandler) GetPendingFriendRequests(w http.ResponseWriter, r *http
 
 // DeclineFriendRequest handles POST requests to decline a friend request.
 func (fh *FriendHandler) DeclineFriendRequest(w http.ResponseWriter, r *http.Request) {
-	var requestData struct {
-		UsernameOrEmail string `json:"usernameOrEmail"`
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+
+	var requestData UsernameOrEmailRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
 
@@ -231,42 +346,204 @@ func (fh *FriendHandler) DeclineFriendRequest(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	userEmail, ok := r.Context().Value("userEmail").(string)
+	err := fh.FriendService.DeclineFriendRequest(r.Context(), userEmail, requestData.UsernameOrEmail)
+	if err != nil {
+		switch err.Error() {
+		case "User not found", "Friend request not found":
+			utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		default:
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusBadRequest))
+		}
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.request_declined")})
+}
+
+// CancelFriendRequest handles DELETE requests to cancel a sent friend request.
+func (fh *FriendHandler) CancelFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
 	if !ok {
 		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	err := fh.FriendService.DeclineFriendRequest(r.Context(), userEmail, requestData.UsernameOrEmail)
+	var requestData UsernameRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := fh.FriendService.CancelFriendRequest(r.Context(), userEmail, requestData.Username); err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.request_canceled")})
+}
+
+// BlockUser handles POST requests to block a user.
+func (fh *FriendHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData UsernameOrEmailRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if requestData.UsernameOrEmail == "" {
+		utils.WriteJSONError(w, "Username or Email is required", http.StatusBadRequest)
+		return
+	}
+
+	err := fh.FriendService.BlockUser(r.Context(), userEmail, requestData.UsernameOrEmail)
 	if err != nil {
 		switch err.Error() {
-		case "User not found", "Friend request not found":
+		case "User not found":
 			utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
 		default:
-			utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusBadRequest))
 		}
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Friend request declined"})
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.user_blocked")})
 }
 
-// CancelFriendRequest handles DELETE requests to cancel a sent friend request.
-func (fh *FriendHandler) CancelFriendRequest(w http.ResponseWriter, r *http.Request) {
-	var requestData struct {
-		Username string `json:"username"`
+// UnblockUser handles POST requests to unblock a user.
+func (fh *FriendHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+
+	var requestData UsernameOrEmailRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
 
-	userEmail := r.Context().Value("userEmail").(string)
+	if requestData.UsernameOrEmail == "" {
+		utils.WriteJSONError(w, "Username or Email is required", http.StatusBadRequest)
+		return
+	}
 
-	if err := fh.FriendService.CancelFriendRequest(r.Context(), userEmail, requestData.Username); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+	err := fh.FriendService.UnblockUser(r.Context(), userEmail, requestData.UsernameOrEmail)
+	if err != nil {
+		switch err.Error() {
+		case "User not found", "User is not blocked":
+			utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		default:
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusBadRequest))
+		}
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "friend.user_unblocked")})
+}
+
+// GetBlockedUsers handles GET requests to fetch the users blocked by the authenticated user.
+func (fh *FriendHandler) GetBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	blockedUsers, err := fh.FriendService.GetBlockedUsers(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, blockedUsers)
+}
+
+// GetFriendSuggestions handles GET requests to suggest friends of the user's friends.
+// Query Parameter:
+//   - limit (int, optional): Maximum number of suggestions.
+func (fh *FriendHandler) GetFriendSuggestions(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 0
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			utils.WriteJSONError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	suggestions, err := fh.FriendService.ComputeSuggestions(r.Context(), userEmail, limit)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, suggestions)
+}
+
+// GetMutualFriends handles GET requests to fetch the friends the user has in common with another user.
+// Query Parameter:
+//   - username (string, required): The username or email of the other user.
+func (fh *FriendHandler) GetMutualFriends(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		utils.WriteJSONError(w, "Username is required", http.StatusBadRequest)
+		return
+	}
+
+	mutualFriends, err := fh.FriendService.GetMutualFriends(r.Context(), userEmail, username)
+	if err != nil {
+		switch err.Error() {
+		case "User not found":
+			utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		case "You cannot view mutual friends with yourself":
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusBadRequest))
+		default:
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		}
+		return
+	}
+
+	utils.WriteJSON(w, mutualFriends)
+}
+
+// GetRelationshipStatuses handles POST requests to look up the user's relationship with several users
+// at once, e.g. to label user search results.
+// Request Body:
+//   - emails ([]string, required): The emails to look up, at most 50.
+func (fh *FriendHandler) GetRelationshipStatuses(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData FriendStatusRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	statuses, err := fh.FriendService.GetRelationshipStatuses(r.Context(), userEmail, requestData.Emails)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Friend request canceled"})
+	utils.WriteJSON(w, statuses)
 }
@@ -0,0 +1,70 @@
+/**
+ *  HealthHandler serves the liveness and readiness probes used by load balancers and orchestrators.
+ *  Both endpoints are public and are registered outside the CORS and JWT middleware.
+ *
+ *  @struct   HealthHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewHealthHandler(hs)   - Initializes a new HealthHandler with the required HealthService.
+ *  - Liveness(w, r)         - Handles GET /healthz; always 200 while the process is running.
+ *  - Readiness(w, r)        - Handles GET /readyz; 200 if all dependencies are usable, otherwise 503.
+ *
+ *  @endpoints
+ *  - /healthz (GET)
+ *    - Behavior: Returns {"status":"ok"} without touching any dependency.
+ *  - /readyz (GET)
+ *    - Behavior: Returns the status of each dependency, e.g. {"firestore":"ok","smtp":"skipped"}.
+ *
+ *  @dependencies
+ *  - HealthServiceInterface: Runs the dependency checks.
+ *  - utils.WriteJSON: Utility function to write JSON responses.
+ *
+ *  @file      health_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/utils"
+)
+
+// HealthHandler handles liveness and readiness probes.
+type HealthHandler struct {
+	HealthService services.HealthServiceInterface // Service that checks the dependencies.
+}
+
+// NewHealthHandler initializes a HealthHandler with the given HealthService.
+func NewHealthHandler(hs services.HealthServiceInterface) *HealthHandler {
+	return &HealthHandler{HealthService: hs}
+}
+
+// Liveness handles GET requests to /healthz. It reports that the process is up.
+func (hh *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, HealthResponse{Status: "ok"})
+}
+
+// Readiness handles GET requests to /readyz. It reports the status of each dependency,
+// with 503 Service Unavailable if any of them failed its check.
+func (hh *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	statuses, ready := hh.HealthService.CheckReadiness(r.Context())
+	if ready {
+		utils.WriteJSON(w, statuses)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(statuses)
+}
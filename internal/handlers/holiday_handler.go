@@ -0,0 +1,134 @@
+/**
+ *  HolidayHandler handles HTTP requests for the public holidays of the authenticated user's country,
+ *  which the calendar shows alongside the user's events.
+ *
+ *  @struct   HolidayHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewHolidayHandler(hs, us) - Initializes a new HolidayHandler with the required services.
+ *  - GetHolidays(w, r)         - Handles GET requests for the holidays of the user's country.
+ *
+ *  @endpoint
+ *  - /api/holidays
+ *    - HTTP Method: GET
+ *    - Query Parameters:
+ *      - year (integer, optional): Year of the holidays; the current year by default.
+ *
+ *  @behaviors
+ *  - Returns a 400 Bad Request if the year is not a number or more than 10 years from the current
+ *    year, or if the user has not set a known country in their profile.
+ *  - Returns a 502 Bad Gateway if the holidays API fails, except for Norway, whose holidays are
+ *    embedded as a fallback.
+ *  - On success, responds with the holidays ordered by date.
+ *
+ *  @example
+ *  ```
+ *  GET /api/holidays?year=2025
+ *
+ *  Response:
+ *  [
+ *      { "date": "2025-05-17", "localName": "Syttende mai", "name": "Constitution Day" }
+ *  ]
+ *  ```
+ *
+ *  @dependencies
+ *  - HolidayServiceInterface: Fetches the holidays of a country.
+ *  - UserServiceInterface: Provides the user's country.
+ *  - utils.WriteJSON, utils.WriteJSONError: Utility functions for JSON responses.
+ *
+ *  @file      holiday_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/utils"
+)
+
+// HolidayHandler manages HTTP requests for public holidays.
+type HolidayHandler struct {
+	HolidayService services.HolidayServiceInterface // Service for fetching holidays.
+	UserService    services.UserServiceInterface    // Service for the user's country.
+}
+
+// NewHolidayHandler initializes a HolidayHandler with the given services.
+func NewHolidayHandler(hs services.HolidayServiceInterface, us services.UserServiceInterface) *HolidayHandler {
+	return &HolidayHandler{HolidayService: hs, UserService: us}
+}
+
+// GetHolidays handles GET requests for the public holidays of the authenticated user's country.
+// Query Parameters:
+//   - year (integer, optional): Year of the holidays; the current year by default.
+func (hh *HolidayHandler) GetHolidays(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	year := time.Now().Year()
+	if rawYear := r.URL.Query().Get("year"); rawYear != "" {
+		parsed, err := strconv.Atoi(rawYear)
+		if err != nil {
+			utils.WriteJSONError(w, "year must be a number", http.StatusBadRequest)
+			return
+		}
+		year = parsed
+	}
+
+	userInfo, err := hh.UserService.GetUserInfo(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusNotFound))
+		return
+	}
+	country := strings.TrimSpace(userInfo.Country)
+	if country == "" {
+		utils.WriteJSONError(w, "Country not found in user profile", http.StatusBadRequest)
+		return
+	}
+	countryCode, _, err := services.GetCountryLanguages(country)
+	if err != nil {
+		utils.WriteJSONError(w, "The country in your profile is not supported", http.StatusBadRequest)
+		return
+	}
+
+	holidays, err := hh.HolidayService.GetHolidays(r.Context(), countryCode, year)
+	if err != nil {
+		writeHolidayError(w, err)
+		return
+	}
+	utils.WriteJSON(w, holidays)
+}
+
+// writeHolidayError maps invalid input to 400, holidays API failures to 502 and other errors to 500.
+func writeHolidayError(w http.ResponseWriter, err error) {
+	var apiErr *services.HolidayAPIError
+	switch {
+	case errors.As(err, &apiErr):
+		log.Printf("Holiday API request failed: %v", apiErr)
+		utils.WriteJSONError(w, "The holiday provider is unavailable, please try again later", http.StatusBadGateway)
+	case errors.Is(err, services.ErrHolidayYearOutOfRange):
+		utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrUnknownCountryCode):
+		utils.WriteJSONError(w, "The country in your profile is not supported", http.StatusBadRequest)
+	default:
+		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
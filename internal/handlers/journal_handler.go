@@ -14,14 +14,25 @@
  *  - CreateJournal(w, r)                  - Handles POST requests to create a new journal.
  *  - GetJournal(w, r)                     - Handles GET requests to fetch a specific journal by its ID.
  *  - UpdateJournal(w, r)                  - Handles PUT requests to update an existing journal by its ID.
- *  - DeleteJournal(w, r)                  - Handles DELETE requests to delete a specific journal by its ID.
+ *  - DeleteJournal(w, r)                  - Handles DELETE requests to move a specific journal to the trash.
+ *  - GetJournalTrash(w, r)                - Handles GET requests to list the logged-in user's deleted journals.
+ *  - RestoreJournal(w, r)                 - Handles POST requests to restore a journal from the trash.
  *  - GetAllJournals(w, r)                 - Handles GET requests to fetch all journals for the logged-in user.
+ *  - SearchJournals(w, r)                 - Handles GET requests to search the logged-in user's journals.
+ *  - ExportJournals(w, r)                 - Handles GET requests to download the logged-in user's journals.
+ *  - ImportJournals(w, r)                 - Handles POST requests to import journals from a JSON or CSV file.
+ *  - GetJournalStats(w, r)                - Handles GET requests for the logged-in user's monthly journal statistics.
+ *  - UploadAttachment(w, r)               - Handles POST requests to attach an image to a journal.
+ *  - GetAttachment(w, r)                  - Handles GET requests to download an image attached to a journal.
+ *  - DeleteAttachment(w, r)               - Handles DELETE requests to remove an image from a journal.
  *
  *  @endpoints
  *  - /api/journals (POST)
  *    - HTTP Method: POST
- *    - Request Body: JSON object representing a journal.
- *    - Behavior: Creates a new journal for the authenticated user.
+ *    - Query Parameter: `upsert` (optional) - When "true", replaces the existing journal for the same date.
+ *    - Request Body: JournalRequest, with an optional `promptId` naming the writing prompt from
+ *      /api/journal/prompt it answers. Unknown fields are rejected.
+ *    - Behavior: Creates a new journal for the authenticated user; only one journal is allowed per date.
  *
  *  - /api/journals/{journalID} (GET)
  *    - HTTP Method: GET
@@ -31,23 +42,81 @@
  *  - /api/journals/{journalID} (PUT)
  *    - HTTP Method: PUT
  *    - Query Parameter: `journalID` (required) - The ID of the journal to update.
- *    - Request Body: JSON object representing updated journal data.
+ *    - Request Body: JournalRequest with the updated journal data.
  *    - Behavior: Updates the specified journal for the authenticated user.
  *
  *  - /api/journals/{journalID} (DELETE)
  *    - HTTP Method: DELETE
  *    - Query Parameter: `journalID` (required) - The ID of the journal to delete.
- *    - Behavior: Deletes the specified journal for the authenticated user.
+ *    - Behavior: Moves the specified journal of the authenticated user to the trash.
+ *
+ *  - /api/journal/trash (GET)
+ *    - HTTP Method: GET
+ *    - Behavior: Fetches the authenticated user's journals deleted in the last 30 days, most recently deleted first.
+ *
+ *  - /api/journal/restore (POST)
+ *    - HTTP Method: POST
+ *    - Query Parameter: `journalID` (required) - The ID of the journal to restore.
+ *    - Behavior: Moves the journal out of the trash and returns it. Fails with 409 Conflict if the journal
+ *      is not in the trash or another journal has been written on its date.
  *
  *  - /api/journals (GET)
+ *    - HTTP Method: GET, HEAD
+ *    - Behavior: Fetches all journals for the authenticated user. The response has an ETag; a request
+ *      whose If-None-Match matches it gets 304 Not Modified without a body.
+ *
+ *  - /api/journals/search (GET)
+ *    - HTTP Method: GET
+ *    - Query Parameters: `q` (content substring), `from` and `to` (YYYY-MM-DD), `limit` (int), all optional.
+ *    - Behavior: Fetches matching journals for the authenticated user, newest first.
+ *
+ *  - /api/journals/export (GET)
+ *    - HTTP Method: GET
+ *    - Query Parameters: `format` ("json" or "markdown", default "json"), `from` and `to` (YYYY-MM-DD), optional.
+ *    - Behavior: Streams the authenticated user's journals, oldest first, as a downloadable
+ *      journals.json (JSON array) or journals.md (one dated section per entry).
+ *
+ *  - /api/journals/import (POST)
+ *    - HTTP Method: POST
+ *    - Query Parameter: `merge` (optional) - When "true", rows for a date that already has an entry are
+ *      appended to it instead of skipped.
+ *    - Request Body: A JSON array of {date, content} objects (application/json), or a CSV file with date
+ *      and content columns and a header row (text/csv). At most 1000 entries and 20 MB.
+ *    - Behavior: Validates every row like a new journal and imports the valid ones. Returns the number of
+ *      imported, merged, skipped and failed rows and the outcome of each. Returns 400 for an unreadable
+ *      file or one with more than 1000 entries, 413 for larger files and 415 for other content types.
+ *
+ *  - /api/journals/stats (GET)
  *    - HTTP Method: GET
- *    - Behavior: Fetches all journals for the authenticated user.
+ *    - Query Parameter: `month` (YYYY-MM, optional) - Defaults to the current month.
+ *    - Behavior: Returns the month's entry count, mood distribution and current and longest writing streak.
+ *
+ *  - /api/journal/attachments (POST)
+ *    - HTTP Method: POST
+ *    - Query Parameter: `journalID` (required) - The ID of the journal to attach the image to.
+ *    - Request Body: multipart/form-data with a JPEG, PNG or WebP image of at most 5 MB in the "attachment" field.
+ *    - Behavior: Stores the image and returns its metadata. Returns 413 for larger images, 415 for other
+ *      files and 409 when the journal already has 10 attachments.
+ *
+ *  - /api/journal/attachments (GET)
+ *    - HTTP Method: GET
+ *    - Query Parameters: `journalID` and `attachmentID` (required); the `url` of an attachment already has them.
+ *    - Behavior: Returns the image of one of the authenticated user's journals. The images are not
+ *      publicly readable in storage, so this is the only way to fetch them.
+ *
+ *  - /api/journal/attachments (DELETE)
+ *    - HTTP Method: DELETE
+ *    - Query Parameters: `journalID` and `attachmentID` (required).
+ *    - Behavior: Removes the image from the journal and from storage.
  *
  *  @behaviors
  *  - Validates the presence of required parameters (e.g., `journalID`) and request body fields.
- *  - Returns a 400 Bad Request error if parameters or body content are invalid or missing.
+ *  - Returns a 400 Bad Request error if parameters or body content are invalid or missing, including
+ *    an unknown mood, invalid tags or an unknown promptId. Empty or overly long content is reported per field:
+ *    `{ "message": "Invalid input", "errors": { "content": "required" } }`.
  *  - Returns a 404 Not Found error if the specified journal does not exist.
- *  - Returns a 500 Internal Server Error if an error occurs during processing.
+ *  - Returns a 503 Service Unavailable error if the database cannot be reached.
+ *  - Returns a 500 Internal Server Error if any other error occurs during processing.
  *  - On success, returns a JSON object containing the journal data or a success message.
  *
  *  @examples
@@ -97,11 +166,17 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
 	"net/http"
+	"strconv"
 
+	"proh2052-group6/internal/middleware"
 	"proh2052-group6/internal/services"
-	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/i18n"
 	"proh2052-group6/pkg/utils"
 )
 
@@ -116,41 +191,63 @@ func NewJournalHandler(js services.JournalServiceInterface) *JournalHandler {
 }
 
 // CreateJournal handles POST requests to create a new journal.
-// Endpoint: /api/journals
+// Endpoint: /api/journal/save[?upsert=true]
 func (jh *JournalHandler) CreateJournal(w http.ResponseWriter, r *http.Request) {
-	var journal models.Journal
-	if err := json.NewDecoder(r.Body).Decode(&journal); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData JournalRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
+	journal := requestData.Journal(userEmail)
+
+	// With ?upsert=true an existing journal for the same date is overwritten instead of rejected.
+	if r.URL.Query().Get("upsert") == "true" {
+		if err := jh.JournalService.UpsertJournal(r.Context(), &journal); err != nil {
+			writeServiceError(w, err, journalErrorStatus(err))
+			return
+		}
 
-	userEmail := r.Context().Value("userEmail").(string)
-	journal.Email = userEmail
+		utils.WriteJSON(w, JournalSavedResponse{
+			Message:   "Journal saved successfully",
+			JournalID: journal.JournalID,
+		})
+		return
+	}
 
 	if err := jh.JournalService.CreateJournal(r.Context(), &journal); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, journalErrorStatus(err))
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{
-		"message":   "Journal created successfully",
-		"journalID": journal.JournalID,
+	utils.WriteJSON(w, JournalSavedResponse{
+		Message:   "Journal created successfully",
+		JournalID: journal.JournalID,
 	})
 }
 
 // GetJournal handles GET requests to retrieve a specific journal by ID.
 // Endpoint: /api/journals/{journalID}
 func (jh *JournalHandler) GetJournal(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	journalID := r.URL.Query().Get("journalID")
 	if journalID == "" {
 		utils.WriteJSONError(w, "Missing journalID parameter", http.StatusBadRequest)
 		return
 	}
 
-	userEmail := r.Context().Value("userEmail").(string)
 	journal, err := jh.JournalService.GetJournal(r.Context(), userEmail, journalID)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		writeServiceError(w, err, journalErrorStatus(err))
 		return
 	}
 
@@ -160,59 +257,400 @@ func (jh *JournalHandler) GetJournal(w http.ResponseWriter, r *http.Request) {
 // UpdateJournal handles PUT requests to update an existing journal by ID.
 // Endpoint: /api/journals/{journalID}
 func (jh *JournalHandler) UpdateJournal(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	journalID := r.URL.Query().Get("journalID")
 	if journalID == "" {
 		utils.WriteJSONError(w, "Missing journalID parameter", http.StatusBadRequest)
 		return
 	}
 
-	var journal models.Journal
-	if err := json.NewDecoder(r.Body).Decode(&journal); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	var requestData JournalRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
-
-	userEmail := r.Context().Value("userEmail").(string)
-	journal.Email = userEmail
+	journal := requestData.Journal(userEmail)
 	journal.JournalID = journalID
 
 	if err := jh.JournalService.UpdateJournal(r.Context(), &journal); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, journalErrorStatus(err))
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Journal updated successfully"})
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "journal.updated")})
 }
 
 // DeleteJournal handles DELETE requests to delete a specific journal by ID.
 // Endpoint: /api/journals/{journalID}
 func (jh *JournalHandler) DeleteJournal(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	journalID := r.URL.Query().Get("journalID")
 	if journalID == "" {
 		utils.WriteJSONError(w, "Missing journalID parameter", http.StatusBadRequest)
 		return
 	}
 
-	userEmail := r.Context().Value("userEmail").(string)
-
 	if err := jh.JournalService.DeleteJournal(r.Context(), userEmail, journalID); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "journal.deleted")})
+}
+
+// GetJournalTrash handles GET requests to list the logged-in user's journals in the trash.
+// Endpoint: /api/journal/trash
+func (jh *JournalHandler) GetJournalTrash(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Journal deleted successfully"})
+	journals, err := jh.JournalService.GetJournalTrash(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, journals)
+}
+
+// RestoreJournal handles POST requests to move a journal out of the trash.
+// Endpoint: /api/journal/restore?journalID=...
+func (jh *JournalHandler) RestoreJournal(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	journalID := r.URL.Query().Get("journalID")
+	if journalID == "" {
+		utils.WriteJSONError(w, "Missing journalID parameter", http.StatusBadRequest)
+		return
+	}
+
+	journal, err := jh.JournalService.RestoreJournal(r.Context(), userEmail, journalID)
+	if err != nil {
+		writeServiceError(w, err, journalErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, journal)
 }
 
 // GetAllJournals handles GET requests to fetch all journals for the logged-in user.
 // Endpoint: /api/journals
 func (jh *JournalHandler) GetAllJournals(w http.ResponseWriter, r *http.Request) {
-	userEmail := r.Context().Value("userEmail").(string)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
 	journals, err := jh.JournalService.GetAllJournals(r.Context(), userEmail)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSONWithETag(w, r, journals)
+}
+
+// SearchJournals handles GET requests to search the logged-in user's journals by content and date.
+// Endpoint: /api/journals/search?q=...&from=YYYY-MM-DD&to=YYYY-MM-DD&limit=N
+func (jh *JournalHandler) SearchJournals(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+
+	limit := 0
+	if rawLimit := params.Get("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 {
+			utils.WriteJSONError(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	journals, err := jh.JournalService.SearchJournals(r.Context(), userEmail, params.Get("q"), params.Get("from"), params.Get("to"), limit)
+	if err != nil {
+		switch err.Error() {
+		case "limit must be a positive number":
+			utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeServiceError(w, err, repositoryErrorStatus(err, http.StatusInternalServerError))
+		}
 		return
 	}
 
 	utils.WriteJSON(w, journals)
 }
+
+// GetJournalStats handles GET requests for the logged-in user's journal statistics for a month.
+// Endpoint: /api/journals/stats?month=YYYY-MM
+func (jh *JournalHandler) GetJournalStats(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	stats, err := jh.JournalService.GetJournalStats(r.Context(), userEmail, r.URL.Query().Get("month"))
+	if err != nil {
+		writeServiceError(w, err, journalErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, stats)
+}
+
+// attachmentFormOverhead is the room left for multipart headers on top of the attachment itself.
+const attachmentFormOverhead = 64 << 10
+
+// UploadAttachment handles POST requests to attach an image to one of the logged-in user's journals.
+// Endpoint: /api/journal/attachments?journalID=...
+// Body: multipart/form-data with the image in the "attachment" field.
+func (jh *JournalHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	journalID := r.URL.Query().Get("journalID")
+	if journalID == "" {
+		utils.WriteJSONError(w, "Missing journalID parameter", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxAttachmentSize+attachmentFormOverhead)
+	file, _, err := r.FormFile("attachment")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.WriteJSONError(w, services.ErrAttachmentTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		utils.WriteJSONError(w, "Attachment is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	// Read one byte past the limit so the service can tell that the image is too large.
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAttachmentSize+1))
+	if err != nil {
+		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	attachment, err := jh.JournalService.AddAttachment(r.Context(), userEmail, journalID, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAttachmentTooLarge):
+			utils.WriteJSONError(w, err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, services.ErrUnsupportedAttachmentType):
+			utils.WriteJSONError(w, err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, services.ErrTooManyAttachments):
+			utils.WriteJSONError(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, services.ErrAttachmentUploadsDisabled):
+			utils.WriteJSONError(w, err.Error(), http.StatusServiceUnavailable)
+		default:
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		}
+		return
+	}
+
+	utils.WriteJSON(w, AttachmentResponse{Message: i18n.T(r.Context(), "journal.attachment_added"), Attachment: *attachment})
+}
+
+// GetAttachment handles GET requests to download an image attached to one of the logged-in user's journals.
+// Endpoint: /api/journal/attachments?journalID=...&attachmentID=...
+func (jh *JournalHandler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	journalID, attachmentID := params.Get("journalID"), params.Get("attachmentID")
+	if journalID == "" || attachmentID == "" {
+		utils.WriteJSONError(w, "Missing journalID or attachmentID parameter", http.StatusBadRequest)
+		return
+	}
+
+	attachment, data, err := jh.JournalService.GetAttachment(r.Context(), userEmail, journalID, attachmentID)
+	if err != nil {
+		if errors.Is(err, services.ErrAttachmentUploadsDisabled) {
+			utils.WriteJSONError(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	// The image is private to the user, so shared caches must not keep it.
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write(data)
+}
+
+// DeleteAttachment handles DELETE requests to remove an image from one of the logged-in user's journals.
+// Endpoint: /api/journal/attachments?journalID=...&attachmentID=...
+func (jh *JournalHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	journalID, attachmentID := params.Get("journalID"), params.Get("attachmentID")
+	if journalID == "" || attachmentID == "" {
+		utils.WriteJSONError(w, "Missing journalID or attachmentID parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := jh.JournalService.DeleteAttachment(r.Context(), userEmail, journalID, attachmentID); err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "journal.attachment_deleted")})
+}
+
+// journalErrorStatus maps an error from the JournalService to an HTTP status code.
+func journalErrorStatus(err error) int {
+	switch err.Error() {
+	case "Invalid month format. Please use YYYY-MM.",
+		"Mood must be one of great, good, neutral, bad or awful",
+		"A journal entry can have at most 10 tags",
+		"Tags must be between 1 and 30 characters",
+		"Duplicate tags are not allowed",
+		services.ErrUnknownPrompt.Error():
+		return http.StatusBadRequest
+	case "A journal already exists for this date",
+		"Journal is not in the trash":
+		return http.StatusConflict
+	default:
+		return repositoryErrorStatus(err, http.StatusInternalServerError)
+	}
+}
+
+// ExportJournals handles GET requests to download the logged-in user's journals.
+// Endpoint: /api/journals/export?format=json|markdown&from=YYYY-MM-DD&to=YYYY-MM-DD
+func (jh *JournalHandler) ExportJournals(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	format := params.Get("format")
+	if format == "" {
+		format = services.JournalExportJSON
+	}
+
+	contentType, filename := "application/json", "journals.json"
+	if format == services.JournalExportMarkdown {
+		contentType, filename = "text/markdown; charset=utf-8", "journals.md"
+	}
+	out := &exportWriter{ResponseWriter: w, contentType: contentType, filename: filename}
+
+	err := jh.JournalService.ExportJournals(r.Context(), userEmail, format, params.Get("from"), params.Get("to"), out)
+	if err != nil {
+		// Once the download has started, the status can no longer be changed.
+		if out.started {
+			log.Printf("Failed to export journals for %s: %v", userEmail, err)
+			return
+		}
+		switch err.Error() {
+		case "format must be 'json' or 'markdown'":
+			utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeServiceError(w, err, repositoryErrorStatus(err, http.StatusInternalServerError))
+		}
+	}
+}
+
+// ImportJournals handles POST requests to import the logged-in user's journals from a JSON or CSV file.
+// Endpoint: /api/journals/import[?merge=true]
+// Body: A JSON array of {date, content} objects, or a CSV file with date and content columns.
+func (jh *JournalHandler) ImportJournals(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var format string
+	switch mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType {
+	case "application/json":
+		format = services.JournalImportJSON
+	case "text/csv":
+		format = services.JournalImportCSV
+	default:
+		utils.WriteJSONError(w, "Content-Type must be application/json or text/csv", http.StatusUnsupportedMediaType)
+		return
+	}
+	if r.ContentLength > services.MaxJournalImportSize {
+		utils.WriteJSONError(w, services.ErrImportTooLarge.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxJournalImportSize)
+
+	result, err := jh.JournalService.ImportJournals(r.Context(), userEmail, format, r.Body, r.URL.Query().Get("merge") == "true")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			utils.WriteJSONError(w, services.ErrImportTooLarge.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, services.ErrInvalidImportFile), errors.Is(err, services.ErrTooManyImportEntries):
+			utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeServiceError(w, err, repositoryErrorStatus(err, http.StatusInternalServerError))
+		}
+		return
+	}
+
+	utils.WriteJSON(w, result)
+}
+
+// exportWriter sets the download headers on the first write, so errors raised before
+// any output is produced can still be answered with a JSON error.
+type exportWriter struct {
+	http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+// Write sends the download headers before the first chunk of the export.
+func (ew *exportWriter) Write(p []byte) (int, error) {
+	if !ew.started {
+		ew.started = true
+		ew.Header().Set("Content-Type", ew.contentType)
+		ew.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", ew.filename))
+		ew.WriteHeader(http.StatusOK)
+	}
+	return ew.ResponseWriter.Write(p)
+}
@@ -15,30 +15,34 @@
  *    - Query Parameters:
  *      - mode (string, optional): Filter for news type or category.
  *      - country (string, optional): Filter for news by country.
+ *      - language (string, optional): One of the country's language codes; its primary language by default.
  *      - q (string, optional): Search query for filtering news articles.
+ *      - page (string, optional): nextPage token from a previous response.
+ *      - refresh (bool, optional): "true" bypasses the cached response.
  *
  *  @behaviors
  *  - Retrieves news articles using filters provided as query parameters.
- *  - Returns a 500 Internal Server Error for service-layer failures.
- *  - On success, responds with a JSON array of news articles.
+ *  - Serves cached responses unless refresh=true is given.
+ *  - Returns a 429 Too Many Requests if the news API's quota is exhausted.
+ *  - Returns a 502 Bad Gateway if the news API fails or returns malformed data.
+ *  - Returns a 500 Internal Server Error for other service-layer failures.
+ *  - On success, responds with the articles and the token for the next page.
  *
  *  @example
  *  ```
  *  GET /api/news?mode=technology&country=US&q=AI
  *
  *  Response:
- *  [
- *      {
- *          "title": "Advances in AI",
- *          "source": "TechDaily",
- *          "url": "https://example.com/ai-news"
- *      },
- *      {
- *          "title": "AI in 2024",
- *          "source": "FutureTrends",
- *          "url": "https://example.com/ai-2024"
- *      }
- *  ]
+ *  {
+ *      "items": [
+ *          {
+ *              "title": "Advances in AI",
+ *              "source": "TechDaily",
+ *              "url": "https://example.com/ai-news"
+ *          }
+ *      ],
+ *      "nextPage": "1699999999123456789"
+ *  }
  *  ```
  *
  *  @dependencies
@@ -58,8 +62,11 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 
+	"proh2052-group6/internal/middleware"
 	"proh2052-group6/internal/services"
 	"proh2052-group6/pkg/utils"
 )
@@ -78,24 +85,48 @@ func NewNewsHandler(ns services.NewsServiceInterface) *NewsHandler {
 // Query Parameters:
 //   - mode (string, optional): Filter for news type or category.
 //   - country (string, optional): Filter for news by country.
+//   - language (string, optional): One of the country's language codes; its primary language by default.
 //   - q (string, optional): Search query for filtering news articles.
+//   - page (string, optional): nextPage token from a previous response.
+//   - refresh (bool, optional): "true" bypasses the cached response.
 func (nh *NewsHandler) FetchNews(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	// Extract query parameters.
 	mode := r.URL.Query().Get("mode")
 	country := r.URL.Query().Get("country")
+	language := r.URL.Query().Get("language")
 	query := r.URL.Query().Get("q")
-
-	// Retrieve user email from the request context.
-	userEmail := r.Context().Value("userEmail").(string)
+	page := r.URL.Query().Get("page")
+	refresh := r.URL.Query().Get("refresh") == "true"
 
 	// Fetch news articles using the NewsService.
-	news, err := nh.NewsService.FetchNews(r.Context(), userEmail, mode, country, query)
+	news, err := nh.NewsService.FetchNews(r.Context(), userEmail, mode, country, language, query, page, refresh)
 	if err != nil {
-		// Return a 500 Internal Server Error if the news fetching fails.
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		writeNewsError(w, err)
 		return
 	}
 
 	// Write the fetched news as a JSON response.
 	utils.WriteJSON(w, news)
 }
+
+// writeNewsError maps news API failures to 429 or 502 and other errors to 500.
+func writeNewsError(w http.ResponseWriter, err error) {
+	var apiErr *services.NewsAPIError
+	if !errors.As(err, &apiErr) {
+		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("News API request failed: %v", apiErr)
+	if apiErr.RateLimited() {
+		utils.WriteJSONError(w, "The news provider's rate limit was reached, please try again later", http.StatusTooManyRequests)
+		return
+	}
+	utils.WriteJSONError(w, "The news provider is unavailable, please try again later", http.StatusBadGateway)
+}
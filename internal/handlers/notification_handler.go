@@ -0,0 +1,226 @@
+/**
+ *  NotificationHandler serves the user's notification inbox and upgrades authenticated requests
+ *  to WebSocket connections that push new notifications, such as friend requests and event invitations.
+ *
+ *  @struct   NotificationHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewNotificationHandler(ns, hub, allowedOrigins) - Initializes a new NotificationHandler with the NotificationService and hub.
+ *  - ListNotifications(w, r)         - Handles GET requests to list the user's notifications.
+ *  - MarkRead(w, r)                  - Handles POST requests to mark one or all notifications as read.
+ *  - ServeWS(w, r)                   - Handles GET /api/ws by upgrading to a WebSocket connection.
+ *
+ *  @endpoints
+ *  - /api/notifications (GET)
+ *    - Query Parameters: `unread=true` to list only unread notifications; `limit` (default 20, max 100).
+ *    - Behavior: Returns the user's notifications, newest first.
+ *  - /api/notifications/read (POST)
+ *    - Body: `{ "id": "string" }` to mark one notification, or `{ "all": true }` to mark all of them.
+ *    - Behavior: Returns 404 if the notification does not exist.
+ *  - /api/ws (GET)
+ *    - Authorization: `Authorization: Bearer <token>` header or `?token=<token>` query parameter.
+ *    - Behavior: Sends each notification as a JSON text message, e.g.
+ *      {"id":"...","type":"friend_request","message":"alice sent you a friend request","payload":{"username":"alice"},"createdAt":"...","read":false}
+ *
+ *  @behaviors
+ *  - The connection is registered with the hub until the client disconnects or stops answering pings.
+ *  - Messages sent by the client are read and discarded; the connection is push-only.
+ *  - Browsers may only connect from the origins the CORS middleware allows (ALLOWED_ORIGINS); other
+ *    handshakes are rejected with 403. Clients that send no Origin header, which are not browsers, may connect.
+ *  - The inbox endpoints answer 503 when the database cannot be reached.
+ *
+ *  @dependencies
+ *  - NotificationServiceInterface: Reads and updates the user's notification inbox.
+ *  - NotificationHubInterface: Delivers the notifications published by the services.
+ *  - github.com/gorilla/websocket: Implements the WebSocket protocol.
+ *
+ *  @file      notification_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/i18n"
+	"proh2052-group6/pkg/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second    // Time allowed to write a message to the client.
+	wsPongWait   = 60 * time.Second    // Time allowed to read the next pong from the client.
+	wsPingPeriod = wsPongWait * 9 / 10 // How often pings are sent; must be less than wsPongWait.
+)
+
+// NotificationHandler serves the notification inbox and pushes notifications over WebSockets.
+type NotificationHandler struct {
+	NotificationService services.NotificationServiceInterface // Service for the notification inbox.
+	Hub                 services.NotificationHubInterface     // Hub the services publish notifications to.
+	upgrader            websocket.Upgrader
+}
+
+// NewNotificationHandler initializes a NotificationHandler with the given NotificationService and hub,
+// accepting WebSocket connections from browsers on allowedOrigins, the origins the CORS middleware allows.
+func NewNotificationHandler(ns services.NotificationServiceInterface, hub services.NotificationHubInterface, allowedOrigins []string) *NotificationHandler {
+	return &NotificationHandler{
+		NotificationService: ns,
+		Hub:                 hub,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || middleware.OriginAllowed(allowedOrigins, origin)
+			},
+		},
+	}
+}
+
+// ListNotifications handles GET requests to list the user's notifications, newest first.
+// Query Parameters:
+//   - unread (bool, optional): If true, only unread notifications are listed.
+//   - limit (int, optional): The maximum number of notifications to return.
+func (nh *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	unreadOnly := false
+	if unreadParam := r.URL.Query().Get("unread"); unreadParam != "" {
+		var err error
+		unreadOnly, err = strconv.ParseBool(unreadParam)
+		if err != nil {
+			utils.WriteJSONError(w, "Invalid unread parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := 0
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			utils.WriteJSONError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	notifications, err := nh.NotificationService.ListNotifications(r.Context(), userEmail, unreadOnly, limit)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, notifications)
+}
+
+// MarkRead handles POST requests to mark a single notification, or all of them, as read.
+func (nh *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData MarkReadRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if requestData.All {
+		if err := nh.NotificationService.MarkAllRead(r.Context(), userEmail); err != nil {
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+			return
+		}
+		utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "notification.all_read")})
+		return
+	}
+
+	if requestData.ID == "" {
+		utils.WriteJSONError(w, "Notification ID or all is required", http.StatusBadRequest)
+		return
+	}
+	if err := nh.NotificationService.MarkRead(r.Context(), userEmail, requestData.ID); err != nil {
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "notification.read")})
+}
+
+// ServeWS handles GET requests to /api/ws. It upgrades the connection to a WebSocket
+// and writes the user's notifications to it until the client disconnects.
+func (nh *NotificationHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Upgrade writes an HTTP error response itself if the handshake fails.
+	conn, err := nh.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	subscription := nh.Hub.Subscribe(userEmail)
+	defer nh.Hub.Unsubscribe(subscription)
+
+	// Read in the background to process pongs and notice when the client goes away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case notification, ok := <-subscription.Notifications:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(notification); err != nil {
+				log.Printf("Failed to push notification to %s: %v", userEmail, err)
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
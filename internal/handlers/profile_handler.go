@@ -10,6 +10,10 @@
  *  - ProfileHandler(w, r)            - Routes HTTP requests based on the HTTP method.
  *  - GetProfile(w, r)                - Handles GET requests to fetch the authenticated user's profile.
  *  - UpdateProfile(w, r)             - Handles PUT requests to update the authenticated user's profile.
+ *  - ChangeEmail(w, r)               - Handles POST requests to start changing the user's email.
+ *  - ConfirmEmail(w, r)              - Handles POST requests to confirm an email change with an OTP.
+ *  - UploadAvatar(w, r)              - Handles POST requests to upload a profile picture.
+ *  - DeleteAvatar(w, r)              - Handles DELETE requests to remove the profile picture.
  *
  *  @endpoints
  *  - /api/profile
@@ -18,11 +22,35 @@
  *    - HTTP Method: PUT
  *      - Body: `{ "field1": "value1", "field2": "value2", ... }`
  *      - Updates the profile information of the authenticated user with the provided data.
+ *  - /api/profile/change-email
+ *    - HTTP Method: POST
+ *      - Body: `{ "newEmail": "string", "currentPassword": "string" }`
+ *      - Sends an OTP to the new email address.
+ *  - /api/profile/confirm-email
+ *    - HTTP Method: POST
+ *      - Body: `{ "otp": "string" }`
+ *      - Moves the account to the new email and returns a token for it: `{ "message": "...", "token": "..." }`.
+ *  - /api/profile/avatar
+ *    - HTTP Method: POST
+ *      - Body: multipart/form-data with the image in the `avatar` field (PNG or JPEG, at most 2 MB).
+ *      - Replaces the profile picture and returns its URL: `{ "message": "...", "imageUrl": "..." }`.
+ *    - HTTP Method: DELETE
+ *      - Removes the profile picture.
  *
  *  @behaviors
  *  - Ensures user authentication by retrieving `userEmail` from the request context.
  *  - Returns meaningful status codes based on the success or failure of operations.
+ *  - Returns 409 Conflict when the requested username or email is already taken.
+ *  - Returns 400 Bad Request for a TimeZone that is not an IANA time zone name, a ReminderTime that is
+ *    not HH:MM, a journal reminder enabled without a time, or quiet hours that are not two different
+ *    HH:MM times set together. An unknown country is reported per
+ *    field, e.g. {"errors": {"country": "is not a known country; did you mean Norway?"}}.
+ *  - Returns 401 for a wrong current password when changing the email, and 429 once the email
+ *    change OTP has been invalidated after too many wrong attempts.
  *  - Validates request payloads for PUT requests.
+ *  - Returns 413 for a profile picture over 2 MB, 415 for one that is not a PNG or JPEG image, and
+ *    503 when uploads are not configured.
+ *  - Returns 404 when the user's account no longer exists and 503 when the database cannot be reached.
  *
  *  @example
  *  ```
@@ -60,13 +88,19 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
+	"proh2052-group6/internal/middleware"
 	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/i18n"
 	"proh2052-group6/pkg/utils"
 )
 
+// avatarFormOverhead is the room left for multipart headers on top of the profile picture itself.
+const avatarFormOverhead = 64 << 10
+
 // ProfileHandler struct for handling profile-related requests.
 type ProfileHandler struct {
 	ProfileService services.ProfileServiceInterface
@@ -94,11 +128,15 @@ func (ph *ProfileHandler) ProfileHandler(w http.ResponseWriter, r *http.Request)
 
 // GetProfile handles GET requests to fetch the authenticated user's profile.
 func (ph *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
-	userEmail := r.Context().Value("userEmail").(string)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
 	profileData, err := ph.ProfileService.GetProfile(r.Context(), userEmail)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -107,18 +145,159 @@ func (ph *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 
 // UpdateProfile handles PUT requests to update the authenticated user's profile.
 func (ph *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
-	userEmail := r.Context().Value("userEmail").(string)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData UpdateProfileRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := ph.ProfileService.UpdateProfile(withClientInfo(r), userEmail, requestData.Updates()); err != nil {
+		if errors.Is(err, services.ErrUsernameTaken) {
+			utils.WriteJSONError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, services.ErrInvalidTimeZone) || errors.Is(err, services.ErrInvalidReminderTime) || errors.Is(err, services.ErrReminderTimeRequired) ||
+			errors.Is(err, services.ErrInvalidQuietHours) || errors.Is(err, services.ErrQuietHoursIncomplete) {
+			utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeServiceError(w, err, repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "profile.updated")})
+}
+
+// ChangeEmail handles POST requests to start changing the authenticated user's email.
+// Body: { "newEmail": "string", "currentPassword": "string" }.
+func (ph *ProfileHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData ChangeEmailRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := ph.ProfileService.RequestEmailChange(r.Context(), userEmail, requestData.NewEmail, requestData.CurrentPassword); err != nil {
+		utils.WriteJSONError(w, err.Error(), emailChangeErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "profile.email_otp_sent")})
+}
+
+// ConfirmEmail handles POST requests to complete an email change with the OTP sent to the new address.
+// Body: { "otp": "string" }. The response contains a token for the new email.
+func (ph *ProfileHandler) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var requestData ConfirmEmailRequest
+	if !decodeJSON(w, r, &requestData) {
+		return
+	}
 
-	var updatedData map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&updatedData); err != nil {
+	token, err := ph.ProfileService.ConfirmEmailChange(r.Context(), userEmail, requestData.OTP)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), emailChangeErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, TokenMessageResponse{Message: i18n.T(r.Context(), "profile.email_changed"), Token: token})
+}
+
+// UploadAvatar handles POST requests to replace the authenticated user's profile picture.
+// Body: multipart/form-data with the image in the "avatar" field.
+func (ph *ProfileHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxAvatarSize+avatarFormOverhead)
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.WriteJSONError(w, services.ErrAvatarTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		utils.WriteJSONError(w, "Profile picture is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	// Read one byte past the limit so the service can tell that the picture is too large.
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAvatarSize+1))
+	if err != nil {
 		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if err := ph.ProfileService.UpdateProfile(r.Context(), userEmail, updatedData); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+	imageURL, err := ph.ProfileService.UpdateAvatar(r.Context(), userEmail, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAvatarTooLarge):
+			utils.WriteJSONError(w, err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, services.ErrUnsupportedAvatarType):
+			utils.WriteJSONError(w, err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, services.ErrAvatarUploadsDisabled):
+			utils.WriteJSONError(w, err.Error(), http.StatusServiceUnavailable)
+		default:
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		}
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Successfully updated profile"})
+	utils.WriteJSON(w, AvatarResponse{Message: i18n.T(r.Context(), "profile.avatar_updated"), ImageURL: imageURL})
+}
+
+// DeleteAvatar handles DELETE requests to remove the authenticated user's profile picture.
+func (ph *ProfileHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := ph.ProfileService.DeleteAvatar(r.Context(), userEmail); err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "profile.avatar_removed")})
+}
+
+// emailChangeErrorStatus maps an error from the email change flow to an HTTP status code.
+func emailChangeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrEmailTaken):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrTooManyOTPAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, services.ErrInvalidEmail), errors.Is(err, services.ErrSameEmail),
+		errors.Is(err, services.ErrNoPendingEmailChange), errors.Is(err, services.ErrInvalidOTP),
+		errors.Is(err, services.ErrOTPExpired):
+		return http.StatusBadRequest
+	}
+
+	switch err.Error() {
+	case "Invalid current password":
+		return http.StatusUnauthorized
+	default:
+		return repositoryErrorStatus(err, http.StatusInternalServerError)
+	}
 }
@@ -0,0 +1,90 @@
+/**
+ *  PromptHandler handles HTTP requests for the journal writing prompt of the day, which helps users
+ *  who do not know what to write.
+ *
+ *  @struct   PromptHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewPromptHandler(ps) - Initializes a new PromptHandler with the required PromptService.
+ *  - GetPrompt(w, r)      - Handles GET requests for the user's prompt of the day.
+ *  - SkipPrompt(w, r)     - Handles POST requests to skip to the user's next prompt.
+ *
+ *  @endpoint
+ *  - /api/journal/prompt
+ *    - HTTP Method: GET
+ *    - Response: `{ "id": "prompt-007", "text": "string" }`
+ *  - /api/journal/prompt/skip
+ *    - HTTP Method: POST
+ *    - Response: the next prompt, in the same form
+ *
+ *  @behaviors
+ *  - The prompt changes every day and is the same all day until the user skips it.
+ *  - Saving a journal with the prompt's ID as `promptId` records which prompt the entry answers.
+ *  - Returns a 503 Service Unavailable while the database is unavailable.
+ *
+ *  @dependencies
+ *  - PromptServiceInterface: Picks the prompts and records skips.
+ *  - utils.WriteJSON, utils.WriteJSONError: Utility functions for JSON responses.
+ *
+ *  @file      prompt_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/utils"
+)
+
+// PromptHandler manages HTTP requests for journal writing prompts.
+type PromptHandler struct {
+	PromptService services.PromptServiceInterface // Service for the prompt of the day.
+}
+
+// NewPromptHandler initializes a PromptHandler with the given PromptService.
+func NewPromptHandler(ps services.PromptServiceInterface) *PromptHandler {
+	return &PromptHandler{PromptService: ps}
+}
+
+// GetPrompt handles GET requests for the user's journal prompt of the day.
+func (ph *PromptHandler) GetPrompt(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	prompt, err := ph.PromptService.GetPrompt(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	utils.WriteJSON(w, prompt)
+}
+
+// SkipPrompt handles POST requests to skip the user's prompt and returns the next one.
+func (ph *PromptHandler) SkipPrompt(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	prompt, err := ph.PromptService.SkipPrompt(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	utils.WriteJSON(w, prompt)
+}
@@ -0,0 +1,166 @@
+/**
+ *  QuoteHandler handles HTTP requests for the daily verse, the quote of the day the app is named
+ *  after, and for the quotes users save to their favourites.
+ *
+ *  @struct   QuoteHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewQuoteHandler(qs, us) - Initializes a new QuoteHandler with the required services.
+ *  - GetDailyVerse(w, r)     - Handles GET requests for the quote of the day.
+ *  - SaveFavorite(w, r)      - Handles POST requests to save a quote to the user's favourites.
+ *  - GetFavorites(w, r)      - Handles GET requests for the user's favourite quotes.
+ *
+ *  @endpoint
+ *  - /api/daily-verse
+ *    - HTTP Method: GET
+ *    - Query Parameters:
+ *      - date (string, optional): Date of the quote (YYYY-MM-DD); today by default.
+ *      - language (string, optional): Two-letter language code; the language of the user's country by default.
+ *  - /api/daily-verse/favorite
+ *    - HTTP Method: POST
+ *    - Request Body: {"quoteId": "en-042"}
+ *  - /api/daily-verse/favorites
+ *    - HTTP Method: GET
+ *
+ *  @behaviors
+ *  - Users whose country has no quotes in its language, or who have not set a country, get the
+ *    English quote of the day.
+ *  - Returns a 400 Bad Request for an invalid date or a missing quoteId.
+ *  - Returns a 404 Not Found if the quote to save does not exist.
+ *  - Returns a 503 Service Unavailable while the database is unavailable.
+ *
+ *  @example
+ *  ```
+ *  GET /api/daily-verse
+ *
+ *  Response:
+ *  {
+ *      "id": "no-003",
+ *      "language": "no",
+ *      "text": "Etter regn kommer solskinn.",
+ *      "author": "Norsk ordtak",
+ *      "tags": ["hope"]
+ *  }
+ *  ```
+ *
+ *  @dependencies
+ *  - QuoteServiceInterface: Picks the daily verse and stores favourites.
+ *  - UserServiceInterface: Provides the user's country, from which the language is derived.
+ *  - utils.WriteJSON, utils.WriteJSONError: Utility functions for JSON responses.
+ *
+ *  @file      quote_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/utils"
+)
+
+// QuoteHandler manages HTTP requests for the daily verse and favourite quotes.
+type QuoteHandler struct {
+	QuoteService services.QuoteServiceInterface // Service for the daily verse and favourites.
+	UserService  services.UserServiceInterface  // Service for the user's country.
+}
+
+// NewQuoteHandler initializes a QuoteHandler with the given services.
+func NewQuoteHandler(qs services.QuoteServiceInterface, us services.UserServiceInterface) *QuoteHandler {
+	return &QuoteHandler{QuoteService: qs, UserService: us}
+}
+
+// GetDailyVerse handles GET requests for the quote of the day.
+// Query Parameters:
+//   - date (string, optional): Date of the quote (YYYY-MM-DD); today by default.
+//   - language (string, optional): Two-letter language code; the language of the user's country by default.
+func (qh *QuoteHandler) GetDailyVerse(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+
+	language := r.URL.Query().Get("language")
+	if language == "" {
+		userInfo, err := qh.UserService.GetUserInfo(r.Context(), userEmail)
+		if err != nil {
+			utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusNotFound))
+			return
+		}
+		// Users without a known country get the default language.
+		if _, languageCode, err := services.GetCountryAndLanguageCode(strings.TrimSpace(userInfo.Country)); err == nil {
+			language = languageCode
+		}
+	}
+
+	quote, err := qh.QuoteService.GetDailyQuote(r.Context(), date, language)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	utils.WriteJSON(w, quote)
+}
+
+// SaveFavorite handles POST requests to save a quote to the user's favourites.
+func (qh *QuoteHandler) SaveFavorite(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var request FavoriteQuoteRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	if strings.TrimSpace(request.QuoteID) == "" {
+		utils.WriteJSONError(w, "quoteId is required", http.StatusBadRequest)
+		return
+	}
+
+	favorite, err := qh.QuoteService.SaveFavorite(r.Context(), userEmail, strings.TrimSpace(request.QuoteID))
+	if errors.Is(err, services.ErrQuoteNotFound) {
+		utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	utils.WriteJSON(w, favorite)
+}
+
+// GetFavorites handles GET requests for the user's favourite quotes, most recently saved first.
+func (qh *QuoteHandler) GetFavorites(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	favorites, err := qh.QuoteService.GetFavorites(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	utils.WriteJSON(w, favorites)
+}
@@ -0,0 +1,70 @@
+/**
+ *  StatsHandler serves the activity statistics of the authenticated user, shown in the
+ *  "your activity" widget of the dashboard.
+ *
+ *  @struct   StatsHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewStatsHandler(ss)   - Initializes a new StatsHandler with the required StatsService.
+ *  - GetUserStats(w, r)    - Retrieves the user's activity statistics.
+ *
+ *  @endpoint
+ *  - /api/me/stats
+ *    - Method: GET
+ *    - Response: `{ "events": 12, "journals": 30, "friends": 4, "activeDays": 9 }`
+ *
+ *  @behaviors
+ *  - Statistics may be up to 10 minutes old, since the service caches them per user.
+ *  - Returns 503 Service Unavailable when the database cannot be reached.
+ *
+ *  @dependencies
+ *  - StatsServiceInterface: Computes the statistics.
+ *  - utils.WriteJSON, utils.WriteJSONError: Utility functions for JSON responses.
+ *
+ *  @file      stats_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/utils"
+)
+
+// StatsHandler manages HTTP requests for the user's activity statistics.
+type StatsHandler struct {
+	StatsService services.StatsServiceInterface // Service computing the statistics.
+}
+
+// NewStatsHandler initializes a StatsHandler with the given StatsService.
+func NewStatsHandler(ss services.StatsServiceInterface) *StatsHandler {
+	return &StatsHandler{StatsService: ss}
+}
+
+// GetUserStats handles GET requests for the authenticated user's activity statistics.
+// Endpoint: /api/me/stats
+func (sh *StatsHandler) GetUserStats(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	stats, err := sh.StatsService.GetUserStats(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	utils.WriteJSON(w, stats)
+}
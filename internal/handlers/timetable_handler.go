@@ -1,6 +1,7 @@
 /**
  *  TimetableHandler is responsible for handling HTTP requests related to timetable operations,
- *  including importing timetables from ICS content. This handler integrates with the
+ *  including importing timetables from ICS content and exporting the user's events as ICS.
+ *  This handler integrates with the
  *  TimetableService to provide the necessary functionality.
  *
  *  @struct   TimetableHandler
@@ -12,31 +13,69 @@
  *  @methods
  *  - NewTimetableHandler(ts)               - Initializes a new TimetableHandler with the required service.
  *  - ImportTimetable(w, r)                 - Handles POST requests to import timetables from ICS content.
+ *  - ExportTimetable(w, r)                 - Handles GET requests to download the user's events as an ICS file.
+ *  - UndoImport(w, r)                      - Handles DELETE requests to remove the events created by an import.
+ *  - GetImportBatches(w, r)                - Handles GET requests for the imports that can be undone.
+ *  - CreateFeedToken(w, r)                 - Handles POST requests to create or rotate the calendar feed token.
+ *  - GetFeedToken(w, r)                    - Handles GET requests for the calendar feed URL.
+ *  - RevokeFeedToken(w, r)                 - Handles DELETE requests to turn the calendar feed off.
+ *  - GetFeed(w, r)                         - Serves a calendar feed as ICS, without authentication.
  *
  *  @endpoints
  *  - /api/timetables/import (POST)
  *    - HTTP Method: POST
- *    - Request Body: JSON object containing ICS content.
+ *    - Request Body: JSON object containing ICS content and an optional `dryRun` flag.
  *    - Behavior: Imports a timetable for the authenticated user based on the provided ICS content.
+ *      With `dryRun` set, the ICS is parsed and validated but nothing is saved, allowing a preview.
+ *  - /api/events/export.ics (GET)
+ *    - Query Parameters: from, to (YYYY-MM-DD), both optional.
+ *    - Behavior: Streams the authenticated user's events as an iCalendar file (text/calendar) that can be
+ *      imported into Google Calendar or Outlook.
+ *  - /api/import-ntnu-timetable (DELETE)
+ *    - Query Parameters: batchID (string, required), the batchID of an import result.
+ *    - Behavior: Deletes the events the import created. Events created manually are never deleted.
+ *  - /api/import-ntnu-timetable/batches (GET)
+ *    - Behavior: Lists the user's imports with their time and remaining number of events, most recent first.
+ *  - /api/events/feed-token (POST, GET, DELETE)
+ *    - Behavior: POST creates a new feed token, so the URL with the old one stops working; GET returns the
+ *      feed's token and URL, or 404 if there is none; DELETE turns the feed off.
+ *  - /api/feeds/{token}.ics (GET)
+ *    - Behavior: Serves the events from a month ago to a year ahead as an iCalendar feed that calendar apps
+ *      can subscribe to. It needs no login, since the token is the credential, and answers 404 for unknown
+ *      or revoked tokens. Responses may be cached for FeedMaxAge.
  *
  *  @behaviors
  *  - Validates the presence of required parameters (e.g., `icsContent`) and request body fields.
- *  - Returns a 400 Bad Request error if parameters or body content are invalid or missing.
+ *  - Returns a 400 Bad Request error if parameters or body content are invalid or missing,
+ *    or if the ICS content cannot be parsed.
  *  - Returns a 401 Unauthorized error if the user is not authenticated.
+ *  - Returns a 404 Not Found error when undoing an import that has no events left, and for unknown feeds.
+ *  - Returns a 503 Service Unavailable error while the database is unavailable.
  *  - Returns a 500 Internal Server Error if an error occurs during processing.
- *  - On success, returns a JSON object containing a success message.
+ *  - On success, returns the import result with imported, existing, skipped and failed counts and per-event reasons.
+ *    Events already present from an earlier import are counted as existing rather than duplicated.
  *
  *  @examples
  *  Import Timetable:
  *  ```
  *  POST /api/timetables/import
  *  Body: {
- *      "icsContent": "BEGIN:VCALENDAR\nVERSION:2.0\n..."
+ *      "icsContent": "BEGIN:VCALENDAR\nVERSION:2.0\n...",
+ *      "dryRun": false
  *  }
  *
  *  Response:
  *  {
- *      "message": "Timetable imported successfully"
+ *      "dryRun": false,
+ *      "imported": 1,
+ *      "existing": 0,
+ *      "skipped": 1,
+ *      "failed": 0,
+ *      "batchID": "5f2b9c0e4a7d1e3b8c6a9f01",
+ *      "events": [
+ *          { "title": "IDATG2204 Lecture", "date": "2024-01-15", "startTime": "09:15", "endTime": "11:00", "status": "imported" },
+ *          { "title": "IDATG2204 Lab", "status": "skipped", "reason": "Invalid start time: unsupported value \"TBA\"" }
+ *      ]
  *  }
  *  ```
  *
@@ -58,13 +97,25 @@
 package handlers
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
+
+	"proh2052-group6/internal/middleware"
 	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/i18n"
+	"proh2052-group6/pkg/models"
 	"proh2052-group6/pkg/utils"
 )
 
+// FeedMaxAge is how long calendar apps and proxies may cache a calendar feed.
+const FeedMaxAge = 15 * time.Minute
+
 // TimetableHandler struct handles requests related to timetable operations.
 type TimetableHandler struct {
 	TimetableService services.TimetableServiceInterface // Service for managing timetable-related logic.
@@ -78,13 +129,16 @@ func NewTimetableHandler(ts services.TimetableServiceInterface) *TimetableHandle
 // ImportTimetable handles POST requests to import a timetable using ICS content.
 // Endpoint: /api/timetables/import
 func (th *TimetableHandler) ImportTimetable(w http.ResponseWriter, r *http.Request) {
-	var requestData struct {
-		ICSContent string `json:"icsContent"` // The ICS content of the timetable to import.
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
 
+	var requestData ImportTimetableRequest
+
 	// Decode the request body into the requestData struct.
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
 
@@ -94,22 +148,177 @@ func (th *TimetableHandler) ImportTimetable(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Retrieve the authenticated user's email from the request context.
-	userEmail, ok := r.Context().Value("userEmail").(string)
+	// Attempt to import the timetable using the service.
+	result, err := th.TimetableService.ImportTimetable(r.Context(), userEmail, requestData.ICSContent, requestData.DryRun)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "Failed to parse ICS content") {
+			utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with the per-event results of the import.
+	utils.WriteJSON(w, result)
+}
+
+// ExportTimetable handles GET requests to download the user's events as an ICS file.
+// Endpoint: /api/events/export.ics
+// Query Parameters: from, to (YYYY-MM-DD), both optional.
+func (th *TimetableHandler) ExportTimetable(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
 	if !ok {
 		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	// Attempt to import the timetable using the service.
-	err := th.TimetableService.ImportTimetable(r.Context(), userEmail, requestData.ICSContent)
+	// Build the calendar before writing, so validation errors can still be sent as JSON.
+	var calendar bytes.Buffer
+	err := th.TimetableService.ExportTimetable(r.Context(), userEmail, r.URL.Query().Get("from"), r.URL.Query().Get("to"), &calendar)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="dailyverse-events.ics"`)
+	calendar.WriteTo(w)
+}
+
+// UndoImport handles DELETE requests to remove the events created by an import.
+// Endpoint: /api/import-ntnu-timetable
+// Query Parameters: batchID (string, required).
+func (th *TimetableHandler) UndoImport(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	batchID := r.URL.Query().Get("batchID")
+	if batchID == "" {
+		utils.WriteJSONError(w, "Missing batchID parameter", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := th.TimetableService.UndoImport(r.Context(), userEmail, batchID)
+	if errors.Is(err, services.ErrImportBatchNotFound) {
+		utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.WriteJSON(w, UndoImportResponse{Message: i18n.T(r.Context(), "timetable.import_undone"), Deleted: deleted})
+}
+
+// GetImportBatches handles GET requests for the user's imports that can be undone.
+// Endpoint: /api/import-ntnu-timetable/batches
+func (th *TimetableHandler) GetImportBatches(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	batches, err := th.TimetableService.GetImportBatches(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
-	// Respond with a success message.
-	utils.WriteJSON(w, map[string]string{
-		"message": "Timetable imported successfully",
-	})
+	utils.WriteJSON(w, batches)
+}
+
+// CreateFeedToken handles POST requests to create a calendar feed, or to replace its token so the
+// old URL stops working.
+// Endpoint: /api/events/feed-token
+func (th *TimetableHandler) CreateFeedToken(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := th.TimetableService.CreateFeedToken(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), feedErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, models.CalendarFeed{Token: token, URL: feedURL(r, token)})
+}
+
+// GetFeedToken handles GET requests for the token and URL of the user's calendar feed.
+// Endpoint: /api/events/feed-token
+func (th *TimetableHandler) GetFeedToken(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := th.TimetableService.GetFeedToken(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), feedErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, models.CalendarFeed{Token: token, URL: feedURL(r, token)})
+}
+
+// RevokeFeedToken handles DELETE requests to turn the user's calendar feed off.
+// Endpoint: /api/events/feed-token
+func (th *TimetableHandler) RevokeFeedToken(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := th.TimetableService.RevokeFeedToken(r.Context(), userEmail); err != nil {
+		utils.WriteJSONError(w, err.Error(), feedErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "timetable.feed_revoked")})
+}
+
+// GetFeed serves the calendar feed with the token in the path as ICS. It is served without
+// authentication; the token is the only credential.
+// Endpoint: /api/feeds/{token}.ics
+func (th *TimetableHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	var calendar bytes.Buffer
+	if err := th.TimetableService.ExportFeed(r.Context(), mux.Vars(r)["token"], &calendar); err != nil {
+		utils.WriteJSONError(w, err.Error(), feedErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(FeedMaxAge.Seconds())))
+	calendar.WriteTo(w)
+}
+
+// feedErrorStatus maps the errors of the calendar feed methods to HTTP status codes.
+func feedErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrFeedNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrFeedsDisabled):
+		return http.StatusServiceUnavailable
+	default:
+		return repositoryErrorStatus(err, http.StatusInternalServerError)
+	}
+}
+
+// feedURL returns the absolute URL of the calendar feed with the token, on the host the request was sent to.
+func feedURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/api/feeds/" + token + ".ics"
 }
@@ -15,7 +15,7 @@
  *  - ForgotPassword(w, r)                - Initiates a password reset by sending an OTP to the user's email.
  *  - ResetPassword(w, r)                 - Resets the user's password using an OTP.
  *  - GetUserInfo(w, r)                   - Fetches the authenticated user's information.
- *  - SearchUsersByUsername(w, r)         - Searches for users by username.
+ *  - SearchUsersByUsername(w, r)         - Searches for users by username, first name or last name.
  *
  *  @endpoint
  *  - /api/signup                         - POST request to register a new user.
@@ -25,12 +25,29 @@
  *  - /api/forgot-password                - POST request to initiate a password reset.
  *  - /api/reset-password                 - POST request to reset a user's password.
  *  - /api/me                             - GET request to fetch the authenticated user's information.
- *  - /api/users/search                   - GET request to search for users by username.
+ *  - /api/users/search                   - GET request to search for users by username, first name or last name prefix
+ *                                          (`excludeBlocked=true` hides blocked users; `excludeRelated=true` also hides friends
+ *                                          and pending requests; `limit`, 20 by default, and `offset` page the results).
  *
  *  @behaviors
  *  - Validates incoming request data and handles errors appropriately.
  *  - Communicates with the UserService to perform user-related operations.
  *  - Returns JSON responses with appropriate HTTP status codes.
+ *  - Signup, Login, ResendOTP and ForgotPassword map service errors with errors.Is: missing fields and weak
+ *    passwords to 400, invalid credentials to 401, unverified emails and disabled accounts to 403, unknown emails to 404,
+ *    taken emails or usernames and already verified emails to 409, accounts not verified in time to 410,
+ *    locked accounts to 423 and too many OTP attempts to 429. An unreachable database is answered with 503; other errors are logged and
+ *    answered with a generic 500. An unknown country is answered with 400 and the field errors, e.g.
+ *    {"errors": {"country": "is not a known country; did you mean Norway?"}}.
+ *  - Signup accepts a city missing from the country's city list, which is incomplete, but reports it
+ *    in `warnings`, e.g. {"city": "is not in our list of cities in Norway; check the spelling"}.
+ *  - Each search result includes `friendshipStatus`: "none", "pending_outgoing", "pending_incoming" or "friends".
+ *  - VerifyEmail and ResetPassword return 429 once an OTP has been invalidated after too many wrong attempts,
+ *    and 410 for an account not verified within 7 days, which must sign up again.
+ *  - Success messages and the messages of the service's errors are translated into the request's locale
+ *    (see middleware.NewLocale); wrapped errors keep their English text.
+ *  - Login, VerifyEmail, ForgotPassword and ResetPassword pass the client's IP and User-Agent to the
+ *    service for the audit log.
  *
  *  @example
  *  ```
@@ -63,12 +80,18 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
 
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
 	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/i18n"
 	"proh2052-group6/pkg/models"
 	"proh2052-group6/pkg/utils"
+	"proh2052-group6/pkg/validate"
 )
 
 // UserHandler handles user-related HTTP requests.
@@ -83,141 +106,236 @@ func NewUserHandler(us services.UserServiceInterface) *UserHandler {
 
 // Signup handles POST requests for user registration.
 func (uh *UserHandler) Signup(w http.ResponseWriter, r *http.Request) {
-	var user models.User
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	var requestData SignupRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
 
+	user := requestData.User()
 	if err := uh.UserService.Signup(r.Context(), &user); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		writeUserError(w, r, err)
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Signup successful. Please verify your email."})
+	response := SignupResponse{Message: i18n.T(r.Context(), "user.signup_success")}
+	if user.CityUnlisted {
+		response.Warnings = map[string]string{"city": i18n.T(r.Context(), "user.city_unlisted", user.Country)}
+	}
+	utils.WriteJSON(w, response)
 }
 
 // Login handles POST requests for user login.
 func (uh *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var loginData models.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&loginData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &loginData) {
 		return
 	}
 
-	token, err := uh.UserService.Login(r.Context(), &loginData)
+	token, err := uh.UserService.Login(withClientInfo(r), &loginData)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusUnauthorized)
+		writeUserError(w, r, err)
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"token": token})
+	utils.WriteJSON(w, TokenResponse{Token: token})
 }
 
 // ResendOTP handles POST requests to resend an OTP for email verification.
 func (uh *UserHandler) ResendOTP(w http.ResponseWriter, r *http.Request) {
-	var requestData struct {
-		Email string `json:"email"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	var requestData EmailRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
 
 	if err := uh.UserService.ResendOTP(r.Context(), requestData.Email); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		writeUserError(w, r, err)
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "A new OTP has been sent to your email address."})
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "user.otp_resent")})
 }
 
 // VerifyEmail handles POST requests to verify a user's email using an OTP.
 func (uh *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
-	var requestData struct {
-		Email string `json:"email"`
-		OTP   string `json:"otp"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	var requestData VerifyEmailRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
 
-	token, err := uh.UserService.VerifyEmail(r.Context(), requestData.Email, requestData.OTP)
+	token, err := uh.UserService.VerifyEmail(withClientInfo(r), requestData.Email, requestData.OTP)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+		utils.WriteJSONError(w, userErrorMessage(r, err), otpErrorStatus(err))
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Email verified successfully", "token": token})
+	utils.WriteJSON(w, TokenMessageResponse{Message: i18n.T(r.Context(), "user.email_verified"), Token: token})
 }
 
 // ForgotPassword handles POST requests to initiate a password reset.
 func (uh *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
-	var requestData struct {
-		Email string `json:"email"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	var requestData EmailRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
 
-	if err := uh.UserService.ForgotPassword(r.Context(), requestData.Email); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+	if err := uh.UserService.ForgotPassword(withClientInfo(r), requestData.Email); err != nil {
+		writeUserError(w, r, err)
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "If the email exists, an OTP has been sent."})
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "user.reset_requested")})
 }
 
 // ResetPassword handles POST requests to reset a user's password using an OTP.
 func (uh *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
-	var requestData struct {
-		Email       string `json:"email"`
-		OTP         string `json:"otp"`
-		NewPassword string `json:"newPassword"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.WriteJSONError(w, "Invalid request body", http.StatusBadRequest)
+	var requestData ResetPasswordRequest
+	if !decodeJSON(w, r, &requestData) {
 		return
 	}
 
-	if err := uh.UserService.ResetPassword(r.Context(), requestData.Email, requestData.OTP, requestData.NewPassword); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+	if err := uh.UserService.ResetPassword(withClientInfo(r), requestData.Email, requestData.OTP, requestData.NewPassword); err != nil {
+		utils.WriteJSONError(w, userErrorMessage(r, err), otpErrorStatus(err))
 		return
 	}
 
-	utils.WriteJSON(w, map[string]string{"message": "Password has been reset successfully."})
+	utils.WriteJSON(w, MessageResponse{Message: i18n.T(r.Context(), "user.password_reset")})
 }
 
 // GetUserInfo handles GET requests to fetch the authenticated user's information.
 func (uh *UserHandler) GetUserInfo(w http.ResponseWriter, r *http.Request) {
-	userEmail := r.Context().Value("userEmail").(string)
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
 	userInfo, err := uh.UserService.GetUserInfo(r.Context(), userEmail)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusUnauthorized)
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusUnauthorized))
 		return
 	}
 
 	utils.WriteJSON(w, userInfo)
 }
 
-// SearchUsersByUsername handles GET requests to search for users by username.
+// SearchUsersByUsername handles GET requests to search for users by username, first name or last name.
+// Query Parameters: query (string, required), excludeBlocked ("true" to hide blocked users, optional),
+// excludeRelated ("true" to hide friends, pending requests in either direction and blocked users, optional),
+// limit (int, optional, 20 by default and at most 50), offset (int, optional).
 func (uh *UserHandler) SearchUsersByUsername(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	query := r.URL.Query().Get("query")
 	if query == "" {
 		utils.WriteJSONError(w, "Missing search query", http.StatusBadRequest)
 		return
 	}
 
-	userEmail := r.Context().Value("userEmail").(string)
+	excludeBlocked := r.URL.Query().Get("excludeBlocked") == "true"
+	excludeRelated := r.URL.Query().Get("excludeRelated") == "true"
 
-	results, err := uh.UserService.SearchUsersByUsername(r.Context(), userEmail, query)
+	limit := 0
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			utils.WriteJSONError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	offset := 0
+	if offsetParam := r.URL.Query().Get("offset"); offsetParam != "" {
+		var err error
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			utils.WriteJSONError(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	results, err := uh.UserService.SearchUsersByUsername(r.Context(), userEmail, query, excludeBlocked, excludeRelated, limit, offset)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusNotFound))
 		return
 	}
 
 	utils.WriteJSON(w, results)
 }
+
+// writeUserError writes the response for an error from the UserService in the locale of r. Expected
+// errors are mapped to their status code; any other error is logged and answered with a generic
+// message, so internal details are not sent to the client.
+func writeUserError(w http.ResponseWriter, r *http.Request, err error) {
+	if fieldErrors, ok := validate.AsErrors(err); ok {
+		utils.WriteJSONValidationError(w, fieldErrors)
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrMissingFields), errors.Is(err, services.ErrWeakPassword):
+		utils.WriteJSONError(w, userErrorMessage(r, err), http.StatusBadRequest)
+	case errors.Is(err, services.ErrInvalidCredentials):
+		utils.WriteJSONError(w, userErrorMessage(r, err), http.StatusUnauthorized)
+	case errors.Is(err, services.ErrNotVerified), errors.Is(err, services.ErrAccountDisabled):
+		utils.WriteJSONError(w, userErrorMessage(r, err), http.StatusForbidden)
+	case errors.Is(err, services.ErrEmailNotRegistered):
+		utils.WriteJSONError(w, userErrorMessage(r, err), http.StatusNotFound)
+	case errors.Is(err, services.ErrEmailTaken), errors.Is(err, services.ErrUsernameTaken), errors.Is(err, services.ErrAlreadyVerified):
+		utils.WriteJSONError(w, userErrorMessage(r, err), http.StatusConflict)
+	case errors.Is(err, services.ErrAccountLocked):
+		utils.WriteJSONError(w, userErrorMessage(r, err), http.StatusLocked)
+	case errors.Is(err, services.ErrTooManyOTPAttempts):
+		utils.WriteJSONError(w, userErrorMessage(r, err), http.StatusTooManyRequests)
+	case errors.Is(err, services.ErrAccountExpired):
+		utils.WriteJSONError(w, userErrorMessage(r, err), http.StatusGone)
+	case errors.Is(err, repositories.ErrUnavailable):
+		log.Printf("User request failed: %v", err)
+		utils.WriteJSONError(w, i18n.T(r.Context(), "error.service_unavailable"), http.StatusServiceUnavailable)
+	default:
+		log.Printf("User request failed: %v", err)
+		utils.WriteJSONError(w, i18n.T(r.Context(), "error.internal"), http.StatusInternalServerError)
+	}
+}
+
+// userErrorKeys maps the errors of the UserService to the keys of their translated messages.
+var userErrorKeys = map[error]string{
+	services.ErrMissingFields:      "error.missing_fields",
+	services.ErrEmailTaken:         "error.email_taken",
+	services.ErrUsernameTaken:      "error.username_taken",
+	services.ErrWeakPassword:       "error.weak_password",
+	services.ErrInvalidCredentials: "error.invalid_credentials",
+	services.ErrNotVerified:        "error.not_verified",
+	services.ErrEmailNotRegistered: "error.email_not_registered",
+	services.ErrAlreadyVerified:    "error.already_verified",
+	services.ErrAccountLocked:      "error.account_locked",
+	services.ErrTooManyOTPAttempts: "error.too_many_otp_attempts",
+	services.ErrAccountDisabled:    "error.account_disabled",
+	services.ErrAccountExpired:     "error.account_expired",
+	services.ErrInvalidOTP:         "error.invalid_otp",
+	services.ErrOTPExpired:         "error.otp_expired",
+}
+
+// userErrorMessage returns the message of an error from the UserService in the locale of r. Only the
+// errors themselves are translated; a wrapped error keeps its English text.
+func userErrorMessage(r *http.Request, err error) string {
+	if key, ok := userErrorKeys[err]; ok {
+		return i18n.T(r.Context(), key)
+	}
+	return err.Error()
+}
+
+// otpErrorStatus maps an error from an OTP check to an HTTP status code. An OTP invalidated after
+// too many wrong attempts is 429, an expired account 410 and an unreachable database 503; any other
+// error is treated as a bad submission.
+func otpErrorStatus(err error) int {
+	if errors.Is(err, services.ErrTooManyOTPAttempts) {
+		return http.StatusTooManyRequests
+	}
+	if errors.Is(err, services.ErrAccountExpired) {
+		return http.StatusGone
+	}
+	return repositoryErrorStatus(err, http.StatusBadRequest)
+}
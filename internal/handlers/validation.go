@@ -0,0 +1,34 @@
+/**
+ *  Shared error handling for handlers whose services validate user input with the validate package.
+ *
+ *  @file      validation.go
+ *  @package   handlers
+ *
+ *  @methods
+ *  - writeServiceError(w, err, code) - Writes a field-level 400 for validation errors, otherwise a JSON error with code.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"proh2052-group6/pkg/utils"
+	"proh2052-group6/pkg/validate"
+)
+
+// writeServiceError answers a validation error from a service with a 400 listing the invalid fields,
+// and any other error with its message and the given status code.
+func writeServiceError(w http.ResponseWriter, err error, code int) {
+	if fieldErrors, ok := validate.AsErrors(err); ok {
+		utils.WriteJSONValidationError(w, fieldErrors)
+		return
+	}
+	utils.WriteJSONError(w, err.Error(), code)
+}
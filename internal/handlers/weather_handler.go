@@ -0,0 +1,142 @@
+/**
+ *  WeatherHandler handles HTTP requests for the weather forecast in the authenticated user's city,
+ *  shown on the dashboard next to the day's agenda. The user's city and country are looked up on
+ *  the map with the GeocodingService and the forecast is fetched with the WeatherService.
+ *
+ *  @struct   WeatherHandler
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewWeatherHandler(ws, us, geocoder) - Initializes a new WeatherHandler with the required services.
+ *  - GetWeather(w, r)                    - Handles GET requests for the forecast in the user's city.
+ *
+ *  @endpoint
+ *  - /api/weather
+ *    - HTTP Method: GET
+ *    - Query Parameters:
+ *      - date (string, optional): Date of the forecast (YYYY-MM-DD); today by default.
+ *
+ *  @behaviors
+ *  - Returns a 400 Bad Request if the date is invalid or out of the forecast range, or if the
+ *    user has not set a city in their profile.
+ *  - Returns a 404 Not Found if the user's city cannot be found on the map.
+ *  - Returns a 502 Bad Gateway if the geocoding or weather API fails.
+ *  - On success, responds with the city and its temperature, precipitation and summary.
+ *
+ *  @example
+ *  ```
+ *  GET /api/weather?date=2024-05-01
+ *
+ *  Response:
+ *  {
+ *      "city": "Oslo",
+ *      "country": "Norway",
+ *      "date": "2024-05-01",
+ *      "temperatureMax": 14.2,
+ *      "temperatureMin": 5.1,
+ *      "precipitationMm": 0.4,
+ *      "summary": "Light rain"
+ *  }
+ *  ```
+ *
+ *  @dependencies
+ *  - WeatherServiceInterface: Fetches the forecast for a position.
+ *  - UserServiceInterface: Provides the user's city and country.
+ *  - GeocodingService: Looks up the coordinates of the user's city.
+ *  - utils.WriteJSON, utils.WriteJSONError: Utility functions for JSON responses.
+ *
+ *  @file      weather_handler.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/utils"
+)
+
+// WeatherHandler manages HTTP requests for weather forecasts.
+type WeatherHandler struct {
+	WeatherService services.WeatherServiceInterface // Service for fetching forecasts.
+	UserService    services.UserServiceInterface    // Service for the user's city and country.
+	Geocoder       services.GeocodingService        // Service for the coordinates of the user's city.
+}
+
+// NewWeatherHandler initializes a WeatherHandler with the given services.
+func NewWeatherHandler(ws services.WeatherServiceInterface, us services.UserServiceInterface, geocoder services.GeocodingService) *WeatherHandler {
+	return &WeatherHandler{WeatherService: ws, UserService: us, Geocoder: geocoder}
+}
+
+// GetWeather handles GET requests for the forecast in the authenticated user's city.
+// Query Parameters:
+//   - date (string, optional): Date of the forecast (YYYY-MM-DD); today by default.
+func (wh *WeatherHandler) GetWeather(w http.ResponseWriter, r *http.Request) {
+	userEmail, ok := middleware.UserEmailFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+
+	userInfo, err := wh.UserService.GetUserInfo(r.Context(), userEmail)
+	if err != nil {
+		utils.WriteJSONError(w, err.Error(), repositoryErrorStatus(err, http.StatusNotFound))
+		return
+	}
+	city, country := strings.TrimSpace(userInfo.City), strings.TrimSpace(userInfo.Country)
+	if city == "" {
+		utils.WriteJSONError(w, "City not found in user profile", http.StatusBadRequest)
+		return
+	}
+
+	coordinates, err := wh.Geocoder.Geocode(r.Context(), strings.Join([]string{city, country}, ", "))
+	if errors.Is(err, services.ErrAddressNotFound) {
+		utils.WriteJSONError(w, "The city in your profile could not be found on the map", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to geocode the city of %s: %v", userEmail, err)
+		utils.WriteJSONError(w, "The map provider is unavailable, please try again later", http.StatusBadGateway)
+		return
+	}
+
+	forecast, err := wh.WeatherService.GetForecast(r.Context(), coordinates.Latitude, coordinates.Longitude, date)
+	if err != nil {
+		writeWeatherError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, WeatherResponse{City: city, Country: country, Forecast: *forecast})
+}
+
+// writeWeatherError maps invalid dates to 400, weather API failures to 502 and other errors to 500.
+func writeWeatherError(w http.ResponseWriter, err error) {
+	var apiErr *services.WeatherAPIError
+	switch {
+	case errors.As(err, &apiErr):
+		log.Printf("Weather API request failed: %v", apiErr)
+		utils.WriteJSONError(w, "The weather provider is unavailable, please try again later", http.StatusBadGateway)
+	case errors.Is(err, services.ErrForecastDateOutOfRange), err.Error() == "date must be in the format YYYY-MM-DD":
+		utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+	default:
+		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
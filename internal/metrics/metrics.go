@@ -0,0 +1,258 @@
+/**
+ *  Package metrics collects Prometheus metrics about the requests the server answers, the calls it
+ *  makes to the news API, the cities API and Firestore, and the requests its rate limiters reject.
+ *  The metrics are registered with a registry passed in by the caller, so tests can gather them
+ *  from their own registry.
+ *
+ *  @struct     Metrics
+ *
+ *  @methods
+ *  - New(registerer)                - Creates the metrics and registers them.
+ *  - Handler(gatherer, token)       - Serves the gathered metrics, optionally only to a bearer token.
+ *  - Instrument(next)               - Middleware recording the count and duration of requests per route template.
+ *  - Transport(upstream, next)      - Wraps an http.RoundTripper to record the latency of outbound calls.
+ *  - ObserveOperation(...)          - Records a Firestore call; implements repositories.OperationObserver.
+ *  - CountRejections(limiter, next) - Wraps a rate limiting middleware to count the requests it rejects.
+ *
+ *  @metrics
+ *  - dailyverse_http_requests_total{route, method, status}
+ *  - dailyverse_http_request_duration_seconds{route, method, status}
+ *  - dailyverse_upstream_request_duration_seconds{upstream, operation, outcome}
+ *  - dailyverse_rate_limit_rejections_total{limiter}
+ *
+ *  @behaviors
+ *  - Requests are labelled with the route template, e.g. /api/events/get, not the requested path, so
+ *    query strings and IDs do not create new series. Instrument must therefore run after the router
+ *    has matched the route, i.e. be added with Router.Use or wrap a route's handler.
+ *  - Outbound HTTP calls are timed until the response headers arrive; their outcome is the status
+ *    class ("2xx", "4xx", ...) or "error" if no response was received.
+ *  - Firestore calls are labelled with the repository method; their outcome is "ok", "not_found" or "error".
+ *
+ *  @example
+ *  ```
+ *  registry := prometheus.NewRegistry()
+ *  appMetrics := metrics.New(registry)
+ *  router.Use(appMetrics.Instrument)
+ *  http.Handle("/metrics", metrics.Handler(registry, ""))
+ *  ```
+ *
+ *  @dependencies
+ *  - github.com/prometheus/client_golang: Metric types, registry and the exposition handler.
+ *
+ *  @file      metrics.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/utils"
+)
+
+// namespace prefixes the name of every metric.
+const namespace = "dailyverse"
+
+// UpstreamFirestore is the upstream label of Firestore calls.
+const UpstreamFirestore = "firestore"
+
+// Metrics holds the collectors the application records its metrics in.
+type Metrics struct {
+	requests            *prometheus.CounterVec
+	requestDuration     *prometheus.HistogramVec
+	upstreamDuration    *prometheus.HistogramVec
+	rateLimitRejections *prometheus.CounterVec
+}
+
+// New creates the application's metrics and registers them with registerer.
+// It panics if they are already registered, like prometheus.MustRegister.
+func New(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests answered, by route template, method and status code.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to answer HTTP requests, by route template, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "Latency of calls to external APIs and Firestore, by upstream, operation and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"upstream", "operation", "outcome"}),
+		rateLimitRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limit_rejections_total",
+			Help:      "Requests rejected by a rate limiter, by limiter.",
+		}, []string{"limiter"}),
+	}
+	registerer.MustRegister(m.requests, m.requestDuration, m.upstreamDuration, m.rateLimitRejections)
+	return m
+}
+
+// Handler serves the metrics gathered by gatherer in the Prometheus text format. If token is not
+// empty, scrapes must send it as "Authorization: Bearer <token>"; other requests get a 401.
+func Handler(gatherer prometheus.Gatherer, token string) http.Handler {
+	metricsHandler := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+	if token == "" {
+		return metricsHandler
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			utils.WriteJSONError(w, "Invalid or missing metrics token", http.StatusUnauthorized)
+			return
+		}
+		metricsHandler.ServeHTTP(w, r)
+	})
+}
+
+// Instrument is a middleware that records the count and duration of requests by the template
+// of the route the router matched, the method and the status code.
+func (m *Metrics) Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		status := strconv.Itoa(recorder.status)
+		m.requests.WithLabelValues(route, r.Method, status).Inc()
+		m.requestDuration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the status code before writing it.
+func (sr *statusRecorder) WriteHeader(status int) {
+	if !sr.wroteHeader {
+		sr.status, sr.wroteHeader = status, true
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes the body, which implies a 200 if no status was written.
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	sr.wroteHeader = true
+	return sr.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered data to the client, for streamed responses such as exports.
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, as WebSocket upgrades do.
+func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := sr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("The response writer does not support hijacking")
+	}
+	sr.status, sr.wroteHeader = http.StatusSwitchingProtocols, true
+	return hijacker.Hijack()
+}
+
+// Transport wraps next, or http.DefaultTransport if next is nil, so the latency of every request
+// it sends is recorded under the given upstream name, e.g. "news".
+func (m *Metrics) Transport(upstream string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &instrumentedTransport{metrics: m, upstream: upstream, next: next}
+}
+
+// instrumentedTransport records the latency of the requests sent through next.
+type instrumentedTransport struct {
+	metrics  *Metrics
+	upstream string
+	next     http.RoundTripper
+}
+
+// RoundTrip sends req and records how long it took until the response headers arrived.
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	outcome := "error"
+	if err == nil {
+		outcome = fmt.Sprintf("%dxx", resp.StatusCode/100)
+	}
+	t.metrics.upstreamDuration.WithLabelValues(t.upstream, req.Method, outcome).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// ObserveOperation records the duration of a Firestore call made through a timed repository.
+func (m *Metrics) ObserveOperation(repository, operation string, duration time.Duration, err error) {
+	outcome := "ok"
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		outcome = "not_found"
+	case err != nil:
+		outcome = "error"
+	}
+	m.upstreamDuration.WithLabelValues(UpstreamFirestore, repository+"."+operation, outcome).Observe(duration.Seconds())
+}
+
+// admittedKey is the context key of the flag set when a rate limiter lets a request through.
+type admittedKey struct{}
+
+// CountRejections wraps the rate limiting middleware limit so every request it rejects is counted
+// under the given limiter name. A request counts as rejected if limit answers it without calling
+// the next handler.
+func (m *Metrics) CountRejections(limiter string, limit func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	rejections := m.rateLimitRejections.WithLabelValues(limiter)
+	return func(next http.Handler) http.Handler {
+		limited := limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if admitted, ok := r.Context().Value(admittedKey{}).(*bool); ok {
+				*admitted = true
+			}
+			next.ServeHTTP(w, r)
+		}))
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			admitted := false
+			limited.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), admittedKey{}, &admitted)))
+			if !admitted {
+				rejections.Inc()
+			}
+		})
+	}
+}
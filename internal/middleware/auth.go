@@ -1,34 +1,50 @@
 /**
- *  JwtAuthMiddleware is a middleware function that validates JWT tokens for secure API endpoints.
+ *  NewJwtAuthMiddleware creates a middleware that validates JWT tokens for secure API endpoints.
  *  It ensures that only authenticated users can access protected resources by verifying the token
  *  provided in the "Authorization" header of incoming HTTP requests.
  *
- *  @middleware JwtAuthMiddleware
+ *  @middleware NewJwtAuthMiddleware(userRepo, jwtManager)
+ *  @middleware NewWebSocketAuthMiddleware(userRepo, jwtManager)
+ *  @middleware NewAdminOnlyMiddleware(userRepo)
  *
  *  @behaviors
  *  - Verifies the presence and format of the Authorization header.
- *  - Parses and validates the JWT token using the secret key.
+ *  - Parses and validates the JWT token with the JWTManager holding the secret keys, checking its
+ *    signature, expiry, issuer and audience.
+ *  - Rejects tokens whose tokenVersion claim is older than the user's stored TokenVersion, so
+ *    a password reset or change revokes every token issued before it.
  *  - Extracts the user's email from the token claims and attaches it to the request context.
- *  - Returns a 401 Unauthorized status for invalid or missing tokens.
+ *  - Returns a 401 Unauthorized status with a JSON body for invalid or missing tokens.
+ *  - Answers 503 Service Unavailable if the user could not be looked up, e.g. during a database
+ *    outage, so clients do not take a valid token for a revoked one and log the user out.
+ *  - Rejects the tokens of disabled accounts with 403 Forbidden, so disabling an account also ends
+ *    its sessions.
+ *  - NewAdminOnlyMiddleware runs after the JWT middleware and lets only users with the admin role
+ *    through; everyone else gets 403 Forbidden.
+ *  - Stores the email under a typed context key; handlers read it with UserEmailFromContext.
+ *  - NewWebSocketAuthMiddleware also accepts the token in a "token" query parameter, since
+ *    browsers cannot set headers on WebSocket connections.
  *
  *  @dependencies
- *  - jwt-go: Library for working with JSON Web Tokens.
- *  - models.Claims: Struct defining the claims within the JWT token.
+ *  - utils.JWTManager: Validates tokens with the configured secret key.
+ *  - repositories.UserRepository: Looks up the user's current TokenVersion, role and disabled flag.
  *  - utils: Utility package for writing JSON responses and errors.
- *  - os.Getenv("JWT_SECRET_KEY"): Environment variable storing the JWT secret key.
  *
  *  @example
  *  ```
+ *  jwtAuth := middleware.NewJwtAuthMiddleware(userRepository, utils.NewJWTManager(cfg.JWTSecret, cfg.JWTOldSecrets, cfg.JWTExpiry, cfg.JWTIssuer))
+ *  router.Handle("/api/me", jwtAuth(userHandler.GetUserInfo))
+ *
  *  Authorization: Bearer <valid_jwt_token>
  *
  *  Valid Request:
  *  - Header: Authorization: Bearer <jwt_token>
- *  - Claims: { "email": "user@example.com", ... }
+ *  - Claims: { "email": "user@example.com", "tokenVersion": 0, ... }
  *  - Next handler receives the user's email in the request context.
  *
  *  Invalid Request:
  *  - Header: Authorization: Bearer <invalid_jwt_token>
- *  - Response: { "error": "Invalid or expired token" }
+ *  - Response: { "message": "Invalid or expired token" }
  *  ```
  *
  *  @file      auth.go
@@ -45,53 +61,126 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"os"
 	"strings"
 
+	"proh2052-group6/internal/repositories"
 	"proh2052-group6/pkg/models"
 	"proh2052-group6/pkg/utils"
-
-	"github.com/dgrijalva/jwt-go"
 )
 
-// jwtSecretKey holds the JWT secret key from the environment variable.
-var jwtSecretKey = os.Getenv("JWT_SECRET_KEY")
+// ContextKey is the type of the keys this package stores in a request context.
+// A dedicated type prevents collisions with keys defined by other packages.
+type ContextKey string
+
+// UserEmailKey is the context key under which the authenticated user's email is stored.
+const UserEmailKey ContextKey = "userEmail"
+
+// WithUserEmail returns a copy of ctx carrying the authenticated user's email.
+func WithUserEmail(ctx context.Context, email string) context.Context {
+	return context.WithValue(ctx, UserEmailKey, email)
+}
+
+// UserEmailFromContext returns the authenticated user's email stored in ctx.
+// The boolean is false if no non-empty email was set.
+func UserEmailFromContext(ctx context.Context) (string, bool) {
+	email, ok := ctx.Value(UserEmailKey).(string)
+	return email, ok && email != ""
+}
 
-// JwtAuthMiddleware is a middleware for validating JWT tokens in incoming requests.
-// It ensures that only authenticated users can access the next handler.
-func JwtAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+// NewJwtAuthMiddleware creates a middleware for validating JWT tokens in incoming requests.
+// It ensures that only authenticated users whose token has not been revoked can access the next handler.
+func NewJwtAuthMiddleware(userRepo repositories.UserRepository, jwtManager *utils.JWTManager) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return jwtAuth(userRepo, jwtManager, next, false)
+	}
+}
+
+// NewWebSocketAuthMiddleware creates a middleware like NewJwtAuthMiddleware that also accepts
+// the token in the "token" query parameter when there is no Authorization header.
+func NewWebSocketAuthMiddleware(userRepo repositories.UserRepository, jwtManager *utils.JWTManager) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return jwtAuth(userRepo, jwtManager, next, true)
+	}
+}
+
+// jwtAuth wraps next with the token checks of the middleware created by NewJwtAuthMiddleware.
+// If allowQueryToken is true, a request without an Authorization header may pass the token as ?token=.
+func jwtAuth(userRepo repositories.UserRepository, jwtManager *utils.JWTManager, next http.HandlerFunc, allowQueryToken bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Extract the Authorization header from the incoming request.
 		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
+		queryToken := ""
+		if allowQueryToken {
+			queryToken = r.URL.Query().Get("token")
+		}
+		if authHeader == "" && queryToken == "" {
 			utils.WriteJSONError(w, "Authorization token is missing", http.StatusUnauthorized)
 			return
 		}
 
-		// Ensure the token format is "Bearer <token>".
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			utils.WriteJSONError(w, "Authorization token format must be 'Bearer <token>'", http.StatusUnauthorized)
-			return
+		tokenString := queryToken
+		if authHeader != "" {
+			// Ensure the token format is "Bearer <token>".
+			parts := strings.SplitN(authHeader, " ", 2)
+			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+				utils.WriteJSONError(w, "Authorization token format must be 'Bearer <token>'", http.StatusUnauthorized)
+				return
+			}
+			tokenString = parts[1]
 		}
 
-		tokenString := parts[1]
-		claims := &models.Claims{}
-
-		// Parse and validate the JWT token using the secret key.
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtSecretKey), nil
-		})
+		// Parse and validate the JWT token using the secret keys, rejecting invalid or expired tokens.
+		claims, err := jwtManager.ParseAndValidate(tokenString)
+		if err != nil {
+			utils.WriteJSONError(w, "Invalid or expired token", http.StatusUnauthorized)
+			return
+		}
 
-		// Handle invalid or expired tokens.
-		if err != nil || !token.Valid {
+		// Reject tokens issued before the user's password was last reset or changed. A lookup that
+		// failed for another reason than a missing user says nothing about the token.
+		user, err := userRepo.GetUserByEmail(r.Context(), claims.Email)
+		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			utils.WriteJSONError(w, "Service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil || user == nil || claims.TokenVersion < user.TokenVersion {
 			utils.WriteJSONError(w, "Invalid or expired token", http.StatusUnauthorized)
 			return
 		}
+		if user.Disabled {
+			utils.WriteJSONError(w, "Account disabled", http.StatusForbidden)
+			return
+		}
+
+		// Attach the user's email to the request context
+		next.ServeHTTP(w, r.WithContext(WithUserEmail(r.Context(), claims.Email)))
+	}
+}
 
-		// Attach the user's email to the request context.
-		ctx := context.WithValue(r.Context(), "userEmail", claims.Email)
-		next.ServeHTTP(w, r.WithContext(ctx))
+// NewAdminOnlyMiddleware creates a middleware that lets only administrators through. It must wrap
+// handlers behind the JWT middleware, which puts the user's email in the request context.
+func NewAdminOnlyMiddleware(userRepo repositories.UserRepository) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			userEmail, ok := UserEmailFromContext(r.Context())
+			if !ok {
+				utils.WriteJSONError(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			user, err := userRepo.GetUserByEmail(r.Context(), userEmail)
+			if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+				utils.WriteJSONError(w, "Failed to check permissions", http.StatusServiceUnavailable)
+				return
+			}
+			if err != nil || user == nil || user.Role != models.RoleAdmin {
+				utils.WriteJSONError(w, "Admin access required", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
 	}
 }
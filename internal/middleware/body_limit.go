@@ -0,0 +1,74 @@
+/**
+ *  JSONBody is a middleware that only lets JSON request bodies of a bounded size through to the
+ *  handlers, so a client cannot make the server buffer an arbitrarily large body.
+ *
+ *  @middleware NewJSONBody
+ *
+ *  @behaviors
+ *  - Only POST, PUT and PATCH requests with a body are checked; other requests pass unchanged.
+ *  - A body without an application/json Content-Type is rejected with a 415 JSON error.
+ *  - A body whose Content-Length exceeds the limit is rejected with a 413 JSON error. Other bodies
+ *    are wrapped in http.MaxBytesReader, so reading past the limit fails with *http.MaxBytesError,
+ *    which the handlers answer with a 413.
+ *
+ *  @example
+ *  ```
+ *  jsonBody := middleware.NewJSONBody(1 << 20)
+ *  router.Handle("/api/journal/save", jsonBody(handler)).Methods("POST")
+ *  ```
+ *
+ *  @file      body_limit.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package middleware
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"proh2052-group6/pkg/utils"
+)
+
+// ErrRequestBodyTooLarge is answered with a 413 for bodies over the limit.
+var ErrRequestBodyTooLarge = errors.New("Request body too large")
+
+// NewJSONBody creates a middleware that requires the bodies of POST, PUT and PATCH requests to be
+// JSON of at most maxBytes bytes.
+func NewJSONBody(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+			// Requests without a body, such as a POST that only triggers an action, need no Content-Type.
+			if r.ContentLength == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != "application/json" {
+				utils.WriteJSONError(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+				return
+			}
+			if r.ContentLength > maxBytes {
+				utils.WriteJSONError(w, ErrRequestBodyTooLarge.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
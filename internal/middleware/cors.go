@@ -0,0 +1,71 @@
+/**
+ *  CORS is a middleware that lets browsers call the API from the web app's origins, answering
+ *  preflight requests and adding the CORS headers to the responses of allowed origins.
+ *
+ *  @middleware NewCORS
+ *  @methods
+ *  - OriginAllowed(allowedOrigins, origin) - Reports whether the CORS middleware allows an origin.
+ *
+ *  @behaviors
+ *  - Only origins in the allow-list get CORS headers; requests from other origins are still served,
+ *    but without the headers, so browsers do not let the page read the response.
+ *  - Credentials are allowed, so the allow-list must name origins explicitly rather than use "*".
+ *  - Preflight OPTIONS requests are answered by the middleware without reaching the routes, and may be
+ *    cached by the browser for CORSMaxAge.
+ *  - GET, HEAD, POST, PUT, PATCH, DELETE and OPTIONS are allowed, with the Authorization, Content-Type,
+ *    Idempotency-Key and If-None-Match request headers; the Idempotent-Replayed and ETag response
+ *    headers are exposed.
+ *  - Origins are compared ignoring case, like the CORS middleware does, so OriginAllowed can check the
+ *    origin of requests it does not see, such as WebSocket handshakes.
+ *
+ *  @example
+ *  ```
+ *  handler := middleware.NewCORS(cfg.AllowedOrigins)(router)
+ *  ```
+ *
+ *  @file      cors.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/cors"
+)
+
+// CORSMaxAge is how long browsers may cache the answer to a preflight request.
+const CORSMaxAge = 10 * time.Minute
+
+// NewCORS creates a middleware that allows cross-origin requests from allowedOrigins.
+func NewCORS(allowedOrigins []string) func(http.Handler) http.Handler {
+	c := cors.New(cors.Options{
+		AllowedOrigins:   allowedOrigins,
+		AllowedMethods:   []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "Idempotency-Key", "If-None-Match"},
+		ExposedHeaders:   []string{"Idempotent-Replayed", "ETag"},
+		AllowCredentials: true,
+		MaxAge:           int(CORSMaxAge.Seconds()),
+	})
+	return c.Handler
+}
+
+// OriginAllowed reports whether origin is in allowedOrigins, ignoring case, i.e. whether NewCORS(allowedOrigins)
+// lets browsers on origin read the API's responses.
+func OriginAllowed(allowedOrigins []string, origin string) bool {
+	for _, allowed := range allowedOrigins {
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+/**
+ *  Limiter implements the token bucket algorithm behind the rate limiting middleware, independent of
+ *  HTTP and of where the buckets are kept, so its refill behaviour can be tested with a fake clock.
+ *
+ *  @file       limiter.go
+ *  @package    middleware
+ *
+ *  @interface LimiterStore
+ *  - Get(key)         - Returns the bucket kept under a key, if there is one.
+ *  - Set(key, bucket) - Keeps a bucket under a key.
+ *
+ *  @struct   Limiter
+ *  - Limit (rate.Limit)      - Tokens added to each bucket per second.
+ *  - Burst (int)             - Size of each bucket.
+ *  - Store (LimiterStore)    - Where the buckets are kept.
+ *  - Now (func() time.Time)  - Clock used to refill the buckets; replaceable in tests.
+ *
+ *  @methods
+ *  - NewLimiter(limit, burst, store)  - Creates a Limiter keeping its buckets in store.
+ *  - Reserve(key)                     - Takes a token from a client's bucket.
+ *  - NewMemoryLimiterStore()          - Creates a LimiterStore that keeps buckets in memory.
+ *  - StartPruning(ctx)                - Forgets full buckets every cleanupInterval until the context is cancelled.
+ *  - Prune(now)                       - Forgets the buckets that are full at a time.
+ *
+ *  @behaviors
+ *  - A client without a bucket has a full one, so a bucket can be forgotten once it has refilled
+ *    completely, at its FullAt time. Buckets are never forgotten earlier, so pruning cannot reset a
+ *    client's quota.
+ *  - Each Reserve refills the bucket for the time since the client was last seen, up to Burst, then
+ *    takes a token if there is one. A rejected request takes nothing and is told how long to wait.
+ *  - Reserve reads and writes a bucket under a lock of its key, so concurrent requests of a client
+ *    cannot take the same token, while other clients are not held up when a store loads a bucket from
+ *    the database. Limiters sharing a store must use different keys.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"proh2052-group6/pkg/models"
+)
+
+// LimiterStore keeps the token buckets of rate limiters by key.
+type LimiterStore interface {
+	// Get returns the bucket kept under key, or false if there is none.
+	Get(key string) (models.RateLimitBucket, bool)
+	// Set keeps bucket under key, replacing the bucket kept before.
+	Set(key string, bucket models.RateLimitBucket)
+}
+
+// Limiter gives each client a token bucket of Burst tokens that refills at Limit tokens per second.
+type Limiter struct {
+	Limit rate.Limit       // Tokens added per second; rate.Inf allows every request.
+	Burst int              // Maximum number of tokens, and so of requests in quick succession.
+	Store LimiterStore     // Where the buckets are kept.
+	Now   func() time.Time // Clock used to refill the buckets; replaceable in tests.
+	mutex sync.Mutex       // Guards keys.
+	keys  map[string]*keyLock
+}
+
+// keyLock makes reading and writing the bucket of one key atomic.
+type keyLock struct {
+	sync.Mutex
+	users int // Reserve calls holding or waiting for the lock; it is forgotten when none are left.
+}
+
+// NewLimiter creates a Limiter with limit tokens per second and buckets of burst tokens, kept in store.
+func NewLimiter(limit rate.Limit, burst int, store LimiterStore) *Limiter {
+	return &Limiter{Limit: limit, Burst: burst, Store: store, Now: time.Now}
+}
+
+// Reserve takes a token from the bucket of key. If none is available, it returns false and how long
+// the client has to wait for the next token.
+func (l *Limiter) Reserve(key string) (time.Duration, bool) {
+	if l.Limit == rate.Inf {
+		return 0, true
+	}
+	if l.Burst <= 0 {
+		// No request is ever allowed.
+		return cleanupInterval, false
+	}
+
+	unlock := l.lockKey(key)
+	defer unlock()
+
+	now := l.Now()
+	tokens := float64(l.Burst)
+	if bucket, ok := l.Store.Get(key); ok {
+		tokens = bucket.Tokens
+		if elapsed := now.Sub(bucket.LastSeen); elapsed > 0 {
+			tokens = math.Min(tokens+elapsed.Seconds()*float64(l.Limit), float64(l.Burst))
+		}
+	}
+
+	var delay time.Duration
+	if tokens >= 1 {
+		tokens--
+	} else {
+		delay = l.refillTime(1 - tokens)
+	}
+	l.Store.Set(key, models.RateLimitBucket{
+		Key:      key,
+		Tokens:   tokens,
+		LastSeen: now,
+		FullAt:   now.Add(l.refillTime(float64(l.Burst) - tokens)),
+	})
+	return delay, delay == 0
+}
+
+// lockKey locks the bucket of key and returns the function that unlocks it. The Limiter's own lock
+// is only held to find the key's lock, never while a bucket is loaded.
+func (l *Limiter) lockKey(key string) func() {
+	l.mutex.Lock()
+	if l.keys == nil {
+		l.keys = make(map[string]*keyLock)
+	}
+	lock, ok := l.keys[key]
+	if !ok {
+		lock = &keyLock{}
+		l.keys[key] = lock
+	}
+	lock.users++
+	l.mutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		if lock.users--; lock.users == 0 {
+			delete(l.keys, key)
+		}
+	}
+}
+
+// refillTime returns how long it takes to add tokens to a bucket. A limit that never refills is
+// treated like the old in-memory buckets, which were forgotten after cleanupInterval.
+func (l *Limiter) refillTime(tokens float64) time.Duration {
+	if l.Limit <= 0 {
+		return cleanupInterval
+	}
+	return time.Duration(math.Ceil(tokens / float64(l.Limit) * float64(time.Second)))
+}
+
+// MemoryLimiterStore keeps token buckets in memory. Its buckets are lost when the server restarts.
+type MemoryLimiterStore struct {
+	mutex   sync.Mutex
+	buckets map[string]models.RateLimitBucket
+}
+
+// NewMemoryLimiterStore creates an empty MemoryLimiterStore.
+func NewMemoryLimiterStore() *MemoryLimiterStore {
+	return &MemoryLimiterStore{buckets: make(map[string]models.RateLimitBucket)}
+}
+
+// Get returns the bucket kept under key, or false if there is none.
+func (s *MemoryLimiterStore) Get(key string) (models.RateLimitBucket, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	bucket, ok := s.buckets[key]
+	return bucket, ok
+}
+
+// Set keeps bucket under key.
+func (s *MemoryLimiterStore) Set(key string, bucket models.RateLimitBucket) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.buckets[key] = bucket
+}
+
+// Prune forgets the buckets that are full at now and returns how many it forgot.
+func (s *MemoryLimiterStore) Prune(now time.Time) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	pruned := 0
+	for key, bucket := range s.buckets {
+		if !bucket.FullAt.After(now) {
+			delete(s.buckets, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// StartPruning forgets full buckets every cleanupInterval until the context is cancelled.
+func (s *MemoryLimiterStore) StartPruning(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.Prune(now)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+/**
+ *  PersistentLimiterStore keeps token buckets in memory and persists them in a RateLimitRepository,
+ *  so rate limits hold across restarts and deploys.
+ *
+ *  @file       limiter_store.go
+ *  @package    middleware
+ *
+ *  @struct   PersistentLimiterStore
+ *  @inherits LimiterStore
+ *  - Repo (repositories.RateLimitRepository) - Where the buckets are persisted.
+ *  - Timeout (time.Duration)                 - How long loading a bucket may take.
+ *  - Now (func() time.Time)                  - Clock used to prune full buckets; replaceable in tests.
+ *
+ *  @methods
+ *  - NewPersistentLimiterStore(repo)   - Creates a PersistentLimiterStore over a repository.
+ *  - Get(key)                          - Returns a bucket, loading it from the repository the first time.
+ *  - Set(key, bucket)                  - Keeps a bucket and marks it to be flushed.
+ *  - Flush(ctx)                        - Saves the changed buckets and forgets the full ones.
+ *  - StartFlushing(ctx, interval)      - Flushes every interval until the context is cancelled.
+ *  - RunFlush(ctx, ticks)              - Flushes on every tick until the context is cancelled.
+ *
+ *  @behaviors
+ *  - Buckets are loaded lazily: the first Get of a key reads the repository, later ones the memory.
+ *  - Set only changes the memory; changed buckets are saved together by Flush, so a request costs at
+ *    most one read and no write. Buckets changed since the last flush are lost if the process dies.
+ *  - The store fails open: a bucket that cannot be loaded in Timeout counts as full, and buckets that
+ *    cannot be saved are kept and saved by the next flush. Rate limiting never makes a request fail.
+ *  - After saving, buckets that have refilled are forgotten, since a missing bucket is a full one.
+ *
+ *  @dependencies
+ *  - repositories.RateLimitRepository: Persists the buckets.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// defaultLimiterLoadTimeout bounds how long a request waits for its bucket to be loaded.
+const defaultLimiterLoadTimeout = 2 * time.Second
+
+// PersistentLimiterStore keeps token buckets in memory and persists them in a repository.
+type PersistentLimiterStore struct {
+	Repo    repositories.RateLimitRepository // Where the buckets are persisted.
+	Timeout time.Duration                    // How long loading a bucket may take.
+	Now     func() time.Time                 // Clock used to prune full buckets.
+	mutex   sync.Mutex                       // Guards buckets and dirty.
+	buckets map[string]models.RateLimitBucket
+	dirty   map[string]bool // Keys changed since the last flush.
+}
+
+// NewPersistentLimiterStore creates an empty PersistentLimiterStore over repo.
+func NewPersistentLimiterStore(repo repositories.RateLimitRepository) *PersistentLimiterStore {
+	return &PersistentLimiterStore{
+		Repo:    repo,
+		Timeout: defaultLimiterLoadTimeout,
+		Now:     time.Now,
+		buckets: make(map[string]models.RateLimitBucket),
+		dirty:   make(map[string]bool),
+	}
+}
+
+// Get returns the bucket kept under key, loading it from the repository if it is not in memory.
+func (s *PersistentLimiterStore) Get(key string) (models.RateLimitBucket, bool) {
+	s.mutex.Lock()
+	bucket, ok := s.buckets[key]
+	s.mutex.Unlock()
+	if ok {
+		return bucket, true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	loaded, err := s.Repo.GetRateLimitBucket(ctx, key)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			log.Printf("Failed to load rate limit bucket: %v", err)
+		}
+		return models.RateLimitBucket{}, false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if bucket, ok := s.buckets[key]; ok {
+		// The bucket was set while it was loading; the newer bucket wins.
+		return bucket, true
+	}
+	s.buckets[key] = *loaded
+	return *loaded, true
+}
+
+// Set keeps bucket under key and marks it to be saved by the next flush.
+func (s *PersistentLimiterStore) Set(key string, bucket models.RateLimitBucket) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.buckets[key] = bucket
+	s.dirty[key] = true
+}
+
+// Flush saves the buckets changed since the last flush, then forgets the buckets that have refilled.
+// Buckets that could not be saved are saved by the next flush.
+func (s *PersistentLimiterStore) Flush(ctx context.Context) error {
+	s.mutex.Lock()
+	changed := make([]models.RateLimitBucket, 0, len(s.dirty))
+	for key := range s.dirty {
+		changed = append(changed, s.buckets[key])
+	}
+	s.dirty = make(map[string]bool)
+	s.mutex.Unlock()
+
+	var err error
+	if len(changed) > 0 {
+		err = s.Repo.SaveRateLimitBuckets(ctx, changed)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err != nil {
+		for _, bucket := range changed {
+			s.dirty[bucket.Key] = true
+		}
+	}
+	now := s.Now()
+	for key, bucket := range s.buckets {
+		if !s.dirty[key] && !bucket.FullAt.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+	return err
+}
+
+// StartFlushing flushes the store every interval until the context is cancelled.
+func (s *PersistentLimiterStore) StartFlushing(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	s.RunFlush(ctx, ticker.C)
+}
+
+// RunFlush flushes the store on every tick until the context is cancelled.
+func (s *PersistentLimiterStore) RunFlush(ctx context.Context, ticks <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+			if err := s.Flush(ctx); err != nil {
+				log.Printf("Failed to save rate limit buckets: %v", err)
+			}
+		}
+	}
+}
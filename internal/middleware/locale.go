@@ -0,0 +1,80 @@
+/**
+ *  Locale is a middleware that resolves the language to answer a request in and stores it in the
+ *  request context, where handlers and services translate their messages with i18n.T.
+ *
+ *  @middleware NewLocale
+ *  @middleware NewUserLocale(userRepo, localeOf)
+ *
+ *  @behaviors
+ *  - A supported "lang" query parameter wins, e.g. ?lang=nb, so a link can pick the language.
+ *  - Otherwise the most preferred supported language of the Accept-Language header is used.
+ *  - Otherwise no locale is stored: NewUserLocale, which runs after the JWT middleware, then stores the
+ *    locale of the authenticated user, e.g. the language of their country, and requests without a
+ *    user are answered in English.
+ *  - NewUserLocale only looks the user up when the client chose no locale, and a failed lookup only
+ *    costs the user their language.
+ *  - Responses carry "Vary: Accept-Language", since their messages depend on it.
+ *
+ *  @example
+ *  ```
+ *  handler := middleware.NewLocale()(router)
+ *  router.Handle("/api/me", jwtAuth(middleware.NewUserLocale(userRepo, services.UserLocale)(userHandler.GetUserInfo)))
+ *
+ *  Accept-Language: nb-NO,nb;q=0.9,en;q=0.5
+ *  Response: { "message": "Venneforespørsel sendt" }
+ *  ```
+ *
+ *  @file      locale.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package middleware
+
+import (
+	"net/http"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/i18n"
+	"proh2052-group6/pkg/models"
+)
+
+// NewLocale creates a middleware that stores the locale the client asked for in the request context.
+func NewLocale() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Language")
+			locale, ok := i18n.Match(r.URL.Query().Get("lang"))
+			if !ok {
+				locale, ok = i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+			}
+			if ok {
+				r = r.WithContext(i18n.WithLocale(r.Context(), locale))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NewUserLocale creates a middleware that stores the locale localeOf returns for the authenticated user
+// in the request context, unless the client chose one. It must wrap handlers after the JWT middleware,
+// which stores the user's email.
+func NewUserLocale(userRepo repositories.UserRepository, localeOf func(*models.User) string) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := i18n.FromContext(r.Context()); !ok {
+				if email, ok := UserEmailFromContext(r.Context()); ok {
+					if user, err := userRepo.GetUserByEmail(r.Context(), email); err == nil && user != nil {
+						r = r.WithContext(i18n.WithLocale(r.Context(), localeOf(user)))
+					}
+				}
+			}
+			next.ServeHTTP(w, r)
+		}
+	}
+}
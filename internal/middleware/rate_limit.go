@@ -1,32 +1,35 @@
 /**
- *  RateLimitMiddleware provides middleware to limit the number of requests per client IP.
- *  This implementation uses a token bucket algorithm provided by the `golang.org/x/time/rate`
- *  package to enforce rate limits and maintain fairness among clients.
+ *  RateLimiter provides middleware to limit the number of requests per client IP, or per
+ *  authenticated user.
+ *  The token buckets are counted by a Limiter (see limiter.go) and kept in a LimiterStore, in memory
+ *  or, through RateLimiters, in a store shared by all limiters that can persist across restarts.
  *
  *  @file       rate_limit.go
  *  @package    middleware
  *
- *  @properties
- *  - clients (map[string]*client) - A map storing rate limiters for each client IP.
- *  - mutex (sync.Mutex)           - A mutex to ensure thread-safe access to the clients map.
- *  - rateLimit (rate.Limit)       - The rate of requests allowed per time period.
- *  - burst (int)                  - The maximum burst size of requests allowed.
- *  - cleanupInterval (time.Duration) - The interval to clean up inactive clients.
- *
- *  @struct   client
- *  - limiter (*rate.Limiter) - A token bucket rate limiter for the client.
- *  - lastSeen (time.Time)    - The last time this client was active.
+ *  @struct   RateLimiters
+ *  - Store (LimiterStore) - Where the buckets of all limiters it creates are kept.
  *
  *  @methods
- *  - RateLimitMiddleware(next)       - Middleware to enforce rate limiting on requests.
- *  - getIP(r)                        - Extracts the client's IP address from the HTTP request.
- *  - cleanupClients()                - Periodically removes inactive clients from the map.
+ *  - NewRateLimiter(limit, burst)          - Creates a rate limiting middleware with its own client buckets.
+ *  - NewUserRateLimiter(limit, burst)      - Creates a rate limiting middleware with buckets per authenticated user.
+ *  - NewRateLimiters(store)                - Creates RateLimiters keeping their buckets in store.
+ *  - (RateLimiters) PerIP(name, limit, burst)   - Creates a named rate limiting middleware per client IP.
+ *  - (RateLimiters) PerUser(name, limit, burst) - Creates a named rate limiting middleware per authenticated user.
+ *  - ClientIP(r)                           - Extracts the client's IP address from the HTTP request.
+ *  - userKey(r)                            - Identifies a request by its user, or else by its client IP.
  *
  *  @behavior
- *  - Enforces a maximum of 5 requests per hour per client IP.
- *  - Allows bursts of up to 5 requests within the defined time period.
- *  - Returns a 429 Too Many Requests error if the client exceeds the rate limit.
- *  - Automatically cleans up clients that have been inactive for a specified duration.
+ *  - Each limiter created by NewRateLimiter has its own buckets, so routes do not share a limit.
+ *    Limiters created by RateLimiters share a store, and their buckets are keyed by the limiter's
+ *    name, so the names must be unique.
+ *  - Identifies clients by the first X-Forwarded-For entry, or by the host part of RemoteAddr.
+ *  - NewUserRateLimiter identifies clients by the email set by the JWT middleware, so it must run
+ *    after it; requests without a user fall back to the client IP.
+ *  - Returns a 429 Too Many Requests JSON error with a Retry-After header (in seconds)
+ *    if the client exceeds the rate limit.
+ *  - Limiters created by NewRateLimiter forget a client's bucket once it has refilled; the store of
+ *    RateLimiters is pruned or flushed by its owner.
  *
  *  @example
  *  ```
@@ -36,14 +39,14 @@
  *          w.Write([]byte("Hello, world!"))
  *      })
  *
- *      handler := middleware.RateLimitMiddleware(mux)
- *      http.ListenAndServe(":8080", handler)
+ *      // 5 requests per hour, all of which may be used at once.
+ *      limit := middleware.NewRateLimiter(rate.Every(time.Hour/5), 5)
+ *      http.ListenAndServe(":8080", limit(mux))
  *  }
  *  ```
  *
  *  @dependencies
- *  - "golang.org/x/time/rate": Provides token bucket rate limiting.
- *  - sync.Mutex: Ensures thread-safe access to shared resources.
+ *  - "golang.org/x/time/rate": Provides the rate.Limit type limits are expressed in.
  *
  *  @authors
  *      - Aayush
@@ -55,78 +58,106 @@
 package middleware
 
 import (
-	"golang.org/x/time/rate"
+	"context"
+	"fmt"
+	"math"
+	"net"
 	"net/http"
-	"sync"
+	"strings"
 	"time"
+
+	"golang.org/x/time/rate"
+	"proh2052-group6/pkg/utils"
 )
 
-// client represents a single client's rate limiter and last activity.
-type client struct {
-	limiter  *rate.Limiter // Rate limiter for the client.
-	lastSeen time.Time     // Timestamp of the client's last request.
+// cleanupInterval is how often full buckets are removed from memory, and how long a client is
+// rejected by a limiter whose burst is zero.
+const cleanupInterval = time.Minute * 10
+
+// NewRateLimiter creates a middleware that allows each client IP `limit` requests per second
+// with bursts of up to `burst` requests. Each call creates independent buckets, so a separate
+// limiter should be created for each group of routes that should be limited separately.
+func NewRateLimiter(limit rate.Limit, burst int) func(http.Handler) http.Handler {
+	return newRateLimiter(NewLimiter(limit, burst, newPrunedMemoryStore()), ClientIP)
 }
 
-var (
-	clients         = make(map[string]*client)  // Map of client IPs to rate limiters.
-	mutex           sync.Mutex                  // Mutex for thread-safe map access.
-	rateLimit       = rate.Every(time.Hour / 5) // 5 requests per hour.
-	burst           = 5                         // Burst size: maximum number of requests in quick succession.
-	cleanupInterval = time.Minute * 10          // Interval to clean up inactive clients.
-)
+// NewUserRateLimiter creates a middleware like NewRateLimiter whose buckets belong to the
+// authenticated user instead of the client IP. It must be applied inside the JWT middleware.
+func NewUserRateLimiter(limit rate.Limit, burst int) func(http.Handler) http.Handler {
+	return newRateLimiter(NewLimiter(limit, burst, newPrunedMemoryStore()), userKey)
+}
 
-// RateLimitMiddleware limits the number of requests per client.
-func RateLimitMiddleware(next http.Handler) http.Handler {
-	// Start the client cleanup goroutine.
-	go cleanupClients()
-
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Extract the client's IP address.
-		ip := getIP(r)
-
-		mutex.Lock()
-		// Retrieve or initialize the client's rate limiter.
-		c, exists := clients[ip]
-		if !exists {
-			limiter := rate.NewLimiter(rateLimit, burst)
-			clients[ip] = &client{limiter: limiter, lastSeen: time.Now()}
-			c = clients[ip]
-		}
-		// Update the client's last seen timestamp.
-		c.lastSeen = time.Now()
-		mutex.Unlock()
-
-		// Enforce the rate limit.
-		if !c.limiter.Allow() {
-			http.Error(w, "Too many requests. Please try again later.", http.StatusTooManyRequests)
-			return
-		}
+// RateLimiters creates rate limiting middleware whose buckets are kept in one store.
+type RateLimiters struct {
+	Store LimiterStore // Where the buckets of all limiters are kept.
+}
 
-		// Proceed to the next handler.
-		next.ServeHTTP(w, r)
+// NewRateLimiters creates RateLimiters keeping their buckets in store.
+func NewRateLimiters(store LimiterStore) *RateLimiters {
+	return &RateLimiters{Store: store}
+}
+
+// PerIP creates a middleware like NewRateLimiter whose buckets are kept in the shared store under name.
+func (rls *RateLimiters) PerIP(name string, limit rate.Limit, burst int) func(http.Handler) http.Handler {
+	return newRateLimiter(NewLimiter(limit, burst, rls.Store), func(r *http.Request) string {
+		return name + ":" + ClientIP(r)
 	})
 }
 
-// getIP extracts the client's real IP address from the request headers or RemoteAddr.
-func getIP(r *http.Request) string {
-	xff := r.Header.Get("X-Forwarded-For")
-	if xff != "" {
-		// X-Forwarded-For can contain multiple IPs; use the first IP.
-		return xff
-	}
-	return r.RemoteAddr
+// PerUser creates a middleware like NewUserRateLimiter whose buckets are kept in the shared store under name.
+func (rls *RateLimiters) PerUser(name string, limit rate.Limit, burst int) func(http.Handler) http.Handler {
+	return newRateLimiter(NewLimiter(limit, burst, rls.Store), func(r *http.Request) string {
+		return name + ":" + userKey(r)
+	})
+}
+
+// newPrunedMemoryStore creates a memory store that forgets full buckets for as long as the process runs.
+func newPrunedMemoryStore() *MemoryLimiterStore {
+	store := NewMemoryLimiterStore()
+	go store.StartPruning(context.Background())
+	return store
 }
 
-// cleanupClients periodically removes inactive clients from the map.
-func cleanupClients() {
-	for {
-		time.Sleep(cleanupInterval)
-		mutex.Lock()
-		for ip, c := range clients {
-			if time.Since(c.lastSeen) > cleanupInterval {
-				delete(clients, ip)
+// newRateLimiter creates a rate limiting middleware that counts requests against key(r).
+func newRateLimiter(limiter *Limiter, key func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Enforce the rate limit.
+			if delay, ok := limiter.Reserve(key(r)); !ok {
+				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(delay.Seconds()))))
+				utils.WriteJSONError(w, "Too many requests. Please try again later.", http.StatusTooManyRequests)
+				return
 			}
+
+			// Proceed to the next handler.
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// userKey identifies a request by the authenticated user, or by the client IP if there is none.
+func userKey(r *http.Request) string {
+	if email, ok := UserEmailFromContext(r.Context()); ok {
+		return "user:" + email
+	}
+	return ClientIP(r)
+}
+
+// ClientIP extracts the client's real IP address from the request headers or RemoteAddr.
+// Only the first X-Forwarded-For entry is used, and the port is stripped from RemoteAddr.
+func ClientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		// X-Forwarded-For can contain multiple IPs; the first one is the original client.
+		first, _, _ := strings.Cut(xff, ",")
+		if ip := strings.TrimSpace(first); ip != "" {
+			return ip
 		}
-		mutex.Unlock()
 	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		// RemoteAddr has no port.
+		return r.RemoteAddr
+	}
+	return host
 }
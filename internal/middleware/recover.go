@@ -0,0 +1,122 @@
+/**
+ *  Recover is a middleware that turns a panicking handler into a 500 response for that request alone,
+ *  instead of a crash of the process that drops every other request in flight on the instance.
+ *
+ *  @middleware NewRecover
+ *
+ *  @behaviors
+ *  - Recovers panics of the wrapped handler and logs them with their stack trace and the request ID.
+ *  - The request ID is taken from the X-Request-ID header, or generated if there is none, and is
+ *    echoed in the X-Request-ID response header so a client can quote it when reporting an error.
+ *  - If the handler had not written its response yet, a 500 JSON error is written. Once the headers
+ *    were sent the status can no longer change, so the panic is only logged.
+ *  - http.ErrAbortHandler is re-panicked, so net/http still aborts the response silently.
+ *
+ *  @example
+ *  ```
+ *  handler := middleware.NewRecover()(router)
+ *  ```
+ *
+ *  @file      recover.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package middleware
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+
+	"proh2052-group6/pkg/utils"
+)
+
+// RequestIDHeader carries the ID a request is logged under.
+const RequestIDHeader = "X-Request-ID"
+
+// NewRecover creates a middleware that recovers panics of the handlers behind it.
+func NewRecover() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				requestID = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			recorder := &headerRecorder{ResponseWriter: w}
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				log.Printf("Panic serving %s %s (request %s): %v\n%s", r.Method, r.URL.Path, requestID, err, debug.Stack())
+				if !recorder.wroteHeader {
+					utils.WriteJSONError(w, "Internal server error", http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// newRequestID returns a random 16-byte hex ID.
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
+
+// headerRecorder remembers whether the response headers were sent.
+type headerRecorder struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// WriteHeader records that the headers were sent before writing them.
+func (hr *headerRecorder) WriteHeader(status int) {
+	hr.wroteHeader = true
+	hr.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes the body, which sends the headers if they were not sent yet.
+func (hr *headerRecorder) Write(b []byte) (int, error) {
+	hr.wroteHeader = true
+	return hr.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered data to the client, for streamed responses such as exports.
+func (hr *headerRecorder) Flush() {
+	if flusher, ok := hr.ResponseWriter.(http.Flusher); ok {
+		hr.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, as WebSocket upgrades do.
+func (hr *headerRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := hr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("The response writer does not support hijacking")
+	}
+	hr.wroteHeader = true
+	return hijacker.Hijack()
+}
@@ -0,0 +1,46 @@
+/**
+ *  RequestTimeout is a middleware that bounds how long a request may take by attaching a
+ *  deadline to its context. Handlers pass r.Context() on to services and repositories, so slow
+ *  Firestore or external API calls are cancelled instead of holding a worker indefinitely.
+ *
+ *  @middleware NewRequestTimeout
+ *
+ *  @behaviors
+ *  - Derives a context from the request context that is cancelled after the timeout,
+ *    or earlier if the client disconnects.
+ *  - Does not write a response itself; handlers report the cancelled call as an error.
+ *
+ *  @example
+ *  ```
+ *  handler := middleware.NewRequestTimeout(10 * time.Second)(router)
+ *  ```
+ *
+ *  @file      timeout.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// NewRequestTimeout creates a middleware that cancels each request's context after timeout.
+func NewRequestTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+/**
+ *  AuditRepository defines the interface for storing the audit log of each account: the
+ *  security-sensitive actions taken on it, such as logins and password changes.
+ *
+ *  @interface AuditRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - CreateAuditEntry(ctx, entry)             - Stores an entry in the audit log of entry.Email.
+ *  - ListAuditEntries(ctx, userEmail, limit)  - Retrieves the latest entries of a user's audit log, newest first.
+ *
+ *  @dependencies
+ *  - models.AuditEntry: Defines the structure of an audit log entry.
+ *  - context.Context: Manages request-scoped values, deadlines, and cancellations.
+ *
+ *  @file      audit_repository.go
+ *  @project   DailyVerse
+ *  @framework Go Interface for Repository Pattern
+ *  @purpose   Database operations abstraction for the account audit log.
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"proh2052-group6/pkg/models"
+)
+
+// AuditRepository defines the interface for audit log data operations.
+type AuditRepository interface {
+	// CreateAuditEntry stores an entry in the audit log of entry.Email.
+	CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+
+	// ListAuditEntries retrieves up to limit of the latest entries of a user's audit log, newest first.
+	ListAuditEntries(ctx context.Context, userEmail string, limit int) ([]models.AuditEntry, error)
+}
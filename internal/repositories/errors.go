@@ -0,0 +1,62 @@
+/**
+ *  Repository errors let callers tell a missing document apart from a database that could not be
+ *  reached, independently of the database behind a repository.
+ *
+ *  @file       errors.go
+ *  @package    repositories
+ *
+ *  @errors
+ *  - ErrNotFound    - The requested document does not exist.
+ *  - ErrUnavailable - The database could not be reached or did not answer in time.
+ *
+ *  @methods
+ *  - firestoreError(msg, err) - Translates a Firestore error into the errors above, prefixed with msg.
+ *
+ *  @behaviors
+ *  - gRPC NotFound becomes ErrNotFound; Unavailable and DeadlineExceeded, including an expired context,
+ *    become ErrUnavailable. Any other error is wrapped with %w, so it can still be inspected.
+ *  - Callers check the errors with errors.Is, since repositories wrap them with a description of
+ *    the failed operation.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotFound is returned, wrapped, when the requested document does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrUnavailable is returned, wrapped, when the database could not be reached or timed out.
+	ErrUnavailable = errors.New("database unavailable")
+)
+
+// firestoreError translates an error returned by Firestore into ErrNotFound or ErrUnavailable,
+// prefixed with msg. Other errors are wrapped unchanged, and nil is returned as nil.
+func firestoreError(msg string, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("%s: %w", msg, ErrNotFound)
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return fmt.Errorf("%s: %w", msg, ErrUnavailable)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s: %w", msg, ErrUnavailable)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
@@ -10,8 +10,18 @@
  *  - CreateEvent(ctx, event)                - Creates a new event in the database.
  *  - GetEvent(ctx, userEmail, eventID)      - Retrieves a specific event by its ID and the user's email.
  *  - UpdateEvent(ctx, event)                - Updates an existing event in the database.
+ *  - UpdateEventFields(ctx, userEmail, eventID, updates) - Updates only the given fields of an existing event.
  *  - DeleteEvent(ctx, userEmail, eventID)   - Deletes an event by its ID and the user's email.
- *  - GetAllEvents(ctx, userEmail)           - Fetches all events associated with a specific user.
+ *  - GetAllEvents(ctx, userEmail, query)    - Fetches a page of a user's events, optionally filtered by date.
+ *  - GetEventsBetween(ctx, start, end)      - Fetches events of all users starting within a time range.
+ *  - GetEventByExternalID(ctx, userEmail, externalID) - Fetches a user's event imported from an external calendar.
+ *  - GetRecurringEvents(ctx, userEmail)     - Fetches all of a user's recurring events, regardless of date.
+ *  - GetEvents(ctx, userEmail, eventIDs)    - Retrieves several of a user's events in one round trip.
+ *  - CreateEvents(ctx, events)              - Creates several events at once, reporting an error per event.
+ *  - DeleteEvents(ctx, userEmail, eventIDs) - Deletes several of a user's events at once, reporting an error per event.
+ *  - DeleteEventsByBatch(ctx, userEmail, batchID) - Deletes the events a timetable import created.
+ *  - GetImportBatches(ctx, userEmail)       - Summarises the timetable imports whose events are still stored.
+ *  - CountEvents(ctx, userEmail, limit)     - Counts a user's events, up to a limit.
  *
  *  @dependencies
  *  - models.Event: Defines the structure of an event object.
@@ -32,6 +42,7 @@ package repositories
 import (
 	"context"
 	"proh2052-group6/pkg/models"
+	"time"
 )
 
 // EventRepository defines the interface for event-related data operations.
@@ -40,14 +51,52 @@ type EventRepository interface {
 	CreateEvent(ctx context.Context, event *models.Event) error
 
 	// GetEvent retrieves a specific event by its ID and the associated user's email.
+	// It returns ErrNotFound if the user has no such event.
 	GetEvent(ctx context.Context, userEmail, eventID string) (*models.Event, error)
 
 	// UpdateEvent updates an existing event in the database.
 	UpdateEvent(ctx context.Context, event *models.Event) error
 
+	// UpdateEventFields sets the given fields, keyed by their Go field names, of an existing event and
+	// leaves the other fields unchanged. It returns ErrNotFound if the user has no such event.
+	UpdateEventFields(ctx context.Context, userEmail, eventID string, updates map[string]interface{}) error
+
 	// DeleteEvent removes an event from the database by its ID and the user's email.
 	DeleteEvent(ctx context.Context, userEmail, eventID string) error
 
-	// GetAllEvents fetches all events associated with a specific user's email.
-	GetAllEvents(ctx context.Context, userEmail string) ([]models.Event, error)
+	// GetAllEvents fetches a page of events associated with a specific user's email, ordered by date.
+	// A zero EventQuery returns all of the user's events.
+	GetAllEvents(ctx context.Context, userEmail string, query models.EventQuery) (*models.EventPage, error)
+
+	// GetEventsBetween fetches events of all users whose StartAt lies within [start, end].
+	GetEventsBetween(ctx context.Context, start, end time.Time) ([]models.Event, error)
+
+	// GetEventByExternalID retrieves the user's event with the given external (ICS) UID, or nil if there is none.
+	GetEventByExternalID(ctx context.Context, userEmail, externalID string) (*models.Event, error)
+
+	// GetRecurringEvents fetches every event of the user that has a recurrence rule.
+	GetRecurringEvents(ctx context.Context, userEmail string) ([]models.Event, error)
+
+	// GetEvents retrieves the user's events with the given IDs. The result has an entry per ID,
+	// which is nil if the user has no such event.
+	GetEvents(ctx context.Context, userEmail string, eventIDs []string) ([]*models.Event, error)
+
+	// CreateEvents inserts several events, assigning each its EventID. The writes are not atomic:
+	// the result has an error per event, which is nil for events that were created.
+	CreateEvents(ctx context.Context, events []*models.Event) []error
+
+	// DeleteEvents removes several of the user's events by ID. The writes are not atomic:
+	// the result has an error per ID, which is nil for events that were deleted.
+	DeleteEvents(ctx context.Context, userEmail string, eventIDs []string) []error
+
+	// DeleteEventsByBatch removes every event of the user with the given ImportBatchID and
+	// returns how many were deleted.
+	DeleteEventsByBatch(ctx context.Context, userEmail, batchID string) (int, error)
+
+	// GetImportBatches summarises the user's events by ImportBatchID, most recent import first.
+	// Events without an ImportBatchID are not included.
+	GetImportBatches(ctx context.Context, userEmail string) ([]models.ImportBatch, error)
+
+	// CountEvents counts the user's events, stopping at limit. A limit of 0 counts every event.
+	CountEvents(ctx context.Context, userEmail string, limit int) (int, error)
 }
@@ -0,0 +1,42 @@
+/**
+ *  FavoriteRepository defines the interface for storing the quotes a user has saved from the
+ *  daily verse, so they can read them again later.
+ *
+ *  @interface FavoriteRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - SaveFavorite(ctx, favorite)   - Stores a favourite quote for a user.
+ *  - GetFavorites(ctx, userEmail)  - Retrieves a user's favourite quotes, most recently saved first.
+ *
+ *  @dependencies
+ *  - models.FavoriteQuote: Defines the structure of a saved quote.
+ *  - context.Context: Manages request-scoped values, deadlines, and cancellations.
+ *
+ *  @file      favorite_repository.go
+ *  @project   DailyVerse
+ *  @framework Go Interface for Repository Pattern
+ *  @purpose   Database operations abstraction for favourite quotes.
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"proh2052-group6/pkg/models"
+)
+
+// FavoriteRepository defines the interface for favourite quote data operations.
+type FavoriteRepository interface {
+	// SaveFavorite stores a favourite quote for favorite.Email. Saving a quote the user has already
+	// saved replaces the earlier favourite, so a quote is never listed twice.
+	SaveFavorite(ctx context.Context, favorite *models.FavoriteQuote) error
+
+	// GetFavorites retrieves a user's favourite quotes, most recently saved first.
+	GetFavorites(ctx context.Context, userEmail string) ([]models.FavoriteQuote, error)
+}
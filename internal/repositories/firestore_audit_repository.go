@@ -0,0 +1,99 @@
+/**
+ *  FirestoreAuditRepository implements the AuditRepository interface, storing each user's audit
+ *  log in the `audit` subcollection of their user document.
+ *
+ *  @struct   FirestoreAuditRepository
+ *  @inherits AuditRepository
+ *
+ *  @methods
+ *  - NewFirestoreAuditRepository(client)      - Creates a new FirestoreAuditRepository instance.
+ *  - CreateAuditEntry(ctx, entry)             - Adds an entry to the user's audit log.
+ *  - ListAuditEntries(ctx, userEmail, limit)  - Retrieves the latest entries of a user's audit log, newest first.
+ *
+ *  @behaviors
+ *  - Entries are stored at users/{email}/audit/{id}, so they move with the user on an email change.
+ *  - Entries are never updated; each action adds a new document.
+ *
+ *  @dependencies
+ *  - cloud.google.com/go/firestore: Provides the Firestore client for database operations.
+ *  - google.golang.org/api/iterator: Handles Firestore document iteration.
+ *  - models.AuditEntry: Defines the structure of an audit log entry.
+ *
+ *  @file      firestore_audit_repository.go
+ *  @project   DailyVerse
+ *  @framework Go with Firestore integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"proh2052-group6/pkg/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// FirestoreAuditRepository provides Firestore-based implementation of AuditRepository.
+type FirestoreAuditRepository struct {
+	Client *firestore.Client // Firestore client for database operations.
+}
+
+// NewFirestoreAuditRepository initializes a new FirestoreAuditRepository instance.
+func NewFirestoreAuditRepository(client *firestore.Client) AuditRepository {
+	return &FirestoreAuditRepository{Client: client}
+}
+
+// audit returns the audit subcollection of a user.
+func (ar *FirestoreAuditRepository) audit(ctx context.Context, userEmail string) (*firestore.CollectionRef, error) {
+	user, err := userDoc(ctx, ar.Client, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	return user.Collection("audit"), nil
+}
+
+// CreateAuditEntry adds an entry to the user's audit log.
+func (ar *FirestoreAuditRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	audit, err := ar.audit(ctx, entry.Email)
+	if err != nil {
+		return firestoreError("Failed to create audit entry", err)
+	}
+	if _, err := audit.NewDoc().Create(ctx, entry); err != nil {
+		return firestoreError("Failed to create audit entry", err)
+	}
+	return nil
+}
+
+// ListAuditEntries retrieves up to limit of the latest entries of a user's audit log, newest first.
+func (ar *FirestoreAuditRepository) ListAuditEntries(ctx context.Context, userEmail string, limit int) ([]models.AuditEntry, error) {
+	audit, err := ar.audit(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve audit entries", err)
+	}
+	iter := audit.OrderBy("Timestamp", firestore.Desc).Limit(limit).Documents(ctx)
+	defer iter.Stop()
+
+	entries := []models.AuditEntry{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to retrieve audit entries", err)
+		}
+		var entry models.AuditEntry
+		if err := doc.DataTo(&entry); err != nil {
+			return nil, fmt.Errorf("Failed to parse audit entry data: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,52 @@
+/**
+ *  Firestore count helpers count the documents matching a query. The Firestore client in use
+ *  predates aggregation queries, so the documents are iterated instead, reading as little of each
+ *  as possible and stopping at a cap.
+ *
+ *  @file       firestore_count.go
+ *  @package    repositories
+ *
+ *  @methods
+ *  - countDocuments(ctx, query, limit, msg, keep) - Counts the documents matching a query, up to limit.
+ *
+ *  @behaviors
+ *  - Only the fields selected by the query are read; a query without a selection reads whole documents.
+ *  - A limit of 0 counts every matching document.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// countDocuments counts the documents matching query for which keep returns true, or all of them if
+// keep is nil, stopping once limit is reached. Errors are reported with msg.
+func countDocuments(ctx context.Context, query firestore.Query, limit int, msg string, keep func(*firestore.DocumentSnapshot) bool) (int, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	count := 0
+	for limit <= 0 || count < limit {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, firestoreError(msg, err)
+		}
+		if keep == nil || keep(doc) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -10,12 +10,30 @@
  *  - CreateEvent(ctx, event)             - Creates a new event for a user in Firestore.
  *  - GetEvent(ctx, userEmail, eventID)   - Fetches a specific event for a user by its ID.
  *  - UpdateEvent(ctx, event)             - Updates an existing event in Firestore.
+ *  - UpdateEventFields(ctx, userEmail, eventID, updates) - Updates only the given fields of an existing event.
  *  - DeleteEvent(ctx, userEmail, eventID)- Deletes a specific event for a user by its ID.
- *  - GetAllEvents(ctx, userEmail)        - Retrieves all events for a user from Firestore.
+ *  - GetAllEvents(ctx, userEmail, query) - Retrieves a page of a user's events from Firestore.
+ *  - GetEventsBetween(ctx, start, end)   - Retrieves events of all users starting within a time range.
+ *  - GetEventByExternalID(ctx, userEmail, externalID) - Retrieves a user's event by its external (ICS) UID.
+ *  - GetRecurringEvents(ctx, userEmail)  - Retrieves all of a user's recurring events.
+ *  - GetEvents(ctx, userEmail, eventIDs) - Retrieves several of a user's events with a single GetAll call.
+ *  - CreateEvents(ctx, events)           - Creates several events with a BulkWriter.
+ *  - DeleteEvents(ctx, userEmail, eventIDs) - Deletes several of a user's events with a BulkWriter.
+ *  - DeleteEventsByBatch(ctx, userEmail, batchID) - Deletes the events a timetable import created.
+ *  - GetImportBatches(ctx, userEmail)    - Summarises the timetable imports whose events are still stored.
+ *  - CountEvents(ctx, userEmail, limit)  - Counts a user's events without reading their fields.
  *
  *  @behaviors
- *  - Uses Firestore's hierarchical document structure to store user-specific events under `users/{userEmail}/events/{eventID}`.
- *  - Handles error scenarios and returns meaningful messages on failure.
+ *  - Uses Firestore's hierarchical document structure to store user-specific events under `users/{userEmail}/events/{eventID}`,
+ *    where the user document is resolved with userDoc so escaped and legacy user IDs both work.
+ *  - Pages through a user's events ordered by Date and document ID; the page token is the last event's ID.
+ *  - Filters events by tag with an array-contains query.
+ *  - A recurring event is stored once; its Date is the first occurrence.
+ *  - Imported events carry the ID of their import batch, so an import can be listed and undone.
+ *  - Bulk writes are sent in parallel batches by a BulkWriter instead of one round trip per event.
+ *    They are not atomic, so every event gets its own result.
+ *  - Handles error scenarios and returns meaningful messages on failure. Missing events are reported
+ *    as ErrNotFound and unreachable databases as ErrUnavailable.
  *  - Ensures seamless conversion between Firestore documents and the `models.Event` struct.
  *
  *  @dependencies
@@ -33,8 +51,10 @@
  *  }
  *  err := repository.CreateEvent(ctx, event)
  *
- *  // Fetch all events for a user
- *  events, err := repository.GetAllEvents(ctx, "user@example.com")
+ *  // Fetch the first 20 events in December for a user
+ *  page, err := repository.GetAllEvents(ctx, "user@example.com", models.EventQuery{
+ *      From: "2024-12-01", To: "2024-12-31", Limit: 20,
+ *  })
  *  ```
  *
  *  @file      firestore_event_repository.go
@@ -53,9 +73,13 @@ import (
 	"context"
 	"fmt"
 	"proh2052-group6/pkg/models"
+	"sort"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // FirestoreEventRepository implements the EventRepository interface for Firestore.
@@ -68,19 +92,31 @@ func NewFirestoreEventRepository(client *firestore.Client) EventRepository {
 	return &FirestoreEventRepository{Client: client}
 }
 
+// events returns the events subcollection of the user with userEmail.
+func (er *FirestoreEventRepository) events(ctx context.Context, userEmail string) (*firestore.CollectionRef, error) {
+	user, err := userDoc(ctx, er.Client, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	return user.Collection("events"), nil
+}
+
 // CreateEvent creates a new event for a user in Firestore.
 func (er *FirestoreEventRepository) CreateEvent(ctx context.Context, event *models.Event) error {
-	userEventsCollection := er.Client.Collection("users").Doc(event.Email).Collection("events")
+	userEventsCollection, err := er.events(ctx, event.Email)
+	if err != nil {
+		return firestoreError("Failed to create event", err)
+	}
 	docRef, _, err := userEventsCollection.Add(ctx, event)
 	if err != nil {
-		return fmt.Errorf("Failed to create event: %v", err)
+		return firestoreError("Failed to create event", err)
 	}
 
 	// Assign the generated EventID back to the event object and update the Firestore document.
 	event.EventID = docRef.ID
 	_, err = docRef.Set(ctx, event)
 	if err != nil {
-		return fmt.Errorf("Failed to update event with EventID: %v", err)
+		return firestoreError("Failed to update event with EventID", err)
 	}
 
 	return nil
@@ -88,10 +124,16 @@ func (er *FirestoreEventRepository) CreateEvent(ctx context.Context, event *mode
 
 // GetEvent retrieves a specific event for a user by its ID.
 func (er *FirestoreEventRepository) GetEvent(ctx context.Context, userEmail, eventID string) (*models.Event, error) {
-	docRef := er.Client.Collection("users").Doc(userEmail).Collection("events").Doc(eventID)
-	doc, err := docRef.Get(ctx)
+	userEventsCollection, err := er.events(ctx, userEmail)
 	if err != nil {
-		return nil, fmt.Errorf("Event not found: %v", err)
+		return nil, firestoreError("Failed to get event", err)
+	}
+	doc, err := userEventsCollection.Doc(eventID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("Event %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, firestoreError("Failed to get event", err)
 	}
 
 	var event models.Event
@@ -105,38 +147,92 @@ func (er *FirestoreEventRepository) GetEvent(ctx context.Context, userEmail, eve
 
 // UpdateEvent updates an existing event in Firestore.
 func (er *FirestoreEventRepository) UpdateEvent(ctx context.Context, event *models.Event) error {
-	docRef := er.Client.Collection("users").Doc(event.Email).Collection("events").Doc(event.EventID)
-	_, err := docRef.Set(ctx, event)
+	userEventsCollection, err := er.events(ctx, event.Email)
 	if err != nil {
-		return fmt.Errorf("Failed to update event: %v", err)
+		return firestoreError("Failed to update event", err)
+	}
+	_, err = userEventsCollection.Doc(event.EventID).Set(ctx, event)
+	if err != nil {
+		return firestoreError("Failed to update event", err)
+	}
+	return nil
+}
+
+// UpdateEventFields updates only the given fields of an existing event in Firestore. Unlike
+// UpdateEvent it fails instead of creating the event if it does not exist.
+func (er *FirestoreEventRepository) UpdateEventFields(ctx context.Context, userEmail, eventID string, updates map[string]interface{}) error {
+	userEventsCollection, err := er.events(ctx, userEmail)
+	if err != nil {
+		return firestoreError("Failed to update event", err)
+	}
+	_, err = userEventsCollection.Doc(eventID).Update(ctx, fieldUpdates(updates))
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("Event %w", ErrNotFound)
+	}
+	if err != nil {
+		return firestoreError("Failed to update event", err)
 	}
 	return nil
 }
 
 // DeleteEvent deletes a specific event for a user by its ID.
 func (er *FirestoreEventRepository) DeleteEvent(ctx context.Context, userEmail, eventID string) error {
-	docRef := er.Client.Collection("users").Doc(userEmail).Collection("events").Doc(eventID)
-	_, err := docRef.Delete(ctx)
+	userEventsCollection, err := er.events(ctx, userEmail)
+	if err != nil {
+		return firestoreError("Failed to delete event", err)
+	}
+	_, err = userEventsCollection.Doc(eventID).Delete(ctx)
 	if err != nil {
-		return fmt.Errorf("Failed to delete event: %v", err)
+		return firestoreError("Failed to delete event", err)
 	}
 	return nil
 }
 
-// GetAllEvents retrieves all events for a user from Firestore.
-func (er *FirestoreEventRepository) GetAllEvents(ctx context.Context, userEmail string) ([]models.Event, error) {
-	var events []models.Event
+// GetAllEvents retrieves a page of a user's events from Firestore, ordered by date.
+// The date range is applied in the query and the page token is the ID of the last event on the previous page.
+func (er *FirestoreEventRepository) GetAllEvents(ctx context.Context, userEmail string, query models.EventQuery) (*models.EventPage, error) {
+	eventsCollection, err := er.events(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to fetch user's events", err)
+	}
 
-	iter := er.Client.Collection("users").Doc(userEmail).Collection("events").Documents(ctx)
+	q := eventsCollection.OrderBy("Date", firestore.Asc).OrderBy(firestore.DocumentID, firestore.Asc)
+	if query.From != "" {
+		q = q.Where("Date", ">=", query.From)
+	}
+	if query.To != "" {
+		q = q.Where("Date", "<=", query.To)
+	}
+	if query.Tag != "" {
+		// Combined with the Date ordering this requires a composite index on (Tags, Date).
+		q = q.Where("Tags", "array-contains", query.Tag)
+	}
+	if query.PageToken != "" {
+		cursor, err := eventsCollection.Doc(query.PageToken).Get(ctx)
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("Invalid page token")
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to fetch user's events", err)
+		}
+		q = q.StartAfter(cursor)
+	}
+	if query.Limit > 0 {
+		// Fetch one extra event to find out whether another page exists.
+		q = q.Limit(query.Limit + 1)
+	}
+
+	iter := q.Documents(ctx)
 	defer iter.Stop()
 
+	page := &models.EventPage{Items: []models.Event{}}
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("Failed to fetch user's events: %v", err)
+			return nil, firestoreError("Failed to fetch user's events", err)
 		}
 
 		var event models.Event
@@ -146,9 +242,311 @@ func (er *FirestoreEventRepository) GetAllEvents(ctx context.Context, userEmail
 		}
 
 		// Assign the Firestore document ID to the EventID field.
+		event.EventID = doc.Ref.ID
+		page.Items = append(page.Items, event)
+	}
+
+	if query.Limit > 0 && len(page.Items) > query.Limit {
+		page.Items = page.Items[:query.Limit]
+		page.NextPageToken = page.Items[query.Limit-1].EventID
+	}
+
+	return page, nil
+}
+
+// GetEventsBetween retrieves events of all users whose StartAt lies within [start, end].
+// It uses a collection group query across every user's "events" subcollection.
+func (er *FirestoreEventRepository) GetEventsBetween(ctx context.Context, start, end time.Time) ([]models.Event, error) {
+	var events []models.Event
+
+	iter := er.Client.CollectionGroup("events").
+		Where("StartAt", ">=", start).
+		Where("StartAt", "<=", end).
+		Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to fetch upcoming events", err)
+		}
+
+		var event models.Event
+		if err := doc.DataTo(&event); err != nil {
+			return nil, fmt.Errorf("Error parsing event data: %v", err)
+		}
+
 		event.EventID = doc.Ref.ID
 		events = append(events, event)
 	}
 
 	return events, nil
 }
+
+// GetEventByExternalID retrieves the user's event with the given external ID, or nil if there is none.
+func (er *FirestoreEventRepository) GetEventByExternalID(ctx context.Context, userEmail, externalID string) (*models.Event, error) {
+	userEventsCollection, err := er.events(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve event", err)
+	}
+	iter := userEventsCollection.
+		Where("ExternalID", "==", externalID).
+		Limit(1).
+		Documents(ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve event", err)
+	}
+
+	var event models.Event
+	if err := doc.DataTo(&event); err != nil {
+		return nil, fmt.Errorf("Error parsing event data: %v", err)
+	}
+
+	event.EventID = doc.Ref.ID
+	return &event, nil
+}
+
+// GetRecurringEvents retrieves every event of the user that has a recurrence rule.
+func (er *FirestoreEventRepository) GetRecurringEvents(ctx context.Context, userEmail string) ([]models.Event, error) {
+	userEventsCollection, err := er.events(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve recurring events", err)
+	}
+	iter := userEventsCollection.
+		Where("Recurrence.Frequency", "in", []string{"daily", "weekly"}).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var events []models.Event
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to retrieve recurring events", err)
+		}
+
+		var event models.Event
+		if err := doc.DataTo(&event); err != nil {
+			return nil, fmt.Errorf("Error parsing event data: %v", err)
+		}
+		event.EventID = doc.Ref.ID
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// GetEvents retrieves the user's events with the given IDs in a single round trip.
+// Events that do not exist are returned as nil.
+func (er *FirestoreEventRepository) GetEvents(ctx context.Context, userEmail string, eventIDs []string) ([]*models.Event, error) {
+	events := make([]*models.Event, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return events, nil
+	}
+
+	userEventsCollection, err := er.events(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to get events", err)
+	}
+	docRefs := make([]*firestore.DocumentRef, len(eventIDs))
+	for i, eventID := range eventIDs {
+		docRefs[i] = userEventsCollection.Doc(eventID)
+	}
+
+	docs, err := er.Client.GetAll(ctx, docRefs)
+	if err != nil {
+		return nil, firestoreError("Failed to get events", err)
+	}
+	for i, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+		var event models.Event
+		if err := doc.DataTo(&event); err != nil {
+			return nil, fmt.Errorf("Error parsing event data: %v", err)
+		}
+		event.EventID = doc.Ref.ID
+		events[i] = &event
+	}
+	return events, nil
+}
+
+// CreateEvents creates several events with a BulkWriter, assigning each its generated EventID.
+// Events that could not be created get an error and an empty EventID.
+func (er *FirestoreEventRepository) CreateEvents(ctx context.Context, events []*models.Event) []error {
+	errs := make([]error, len(events))
+	if len(events) == 0 {
+		return errs
+	}
+
+	// Resolve each user's events subcollection once; an import usually has a single owner.
+	collections := make(map[string]*firestore.CollectionRef)
+	bulkWriter := er.Client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, len(events))
+	for i, event := range events {
+		userEventsCollection, ok := collections[event.Email]
+		if !ok {
+			userEventsCollection, errs[i] = er.events(ctx, event.Email)
+			if errs[i] != nil {
+				continue
+			}
+			collections[event.Email] = userEventsCollection
+		}
+		// Generate the ID up front, so the document is written once with its EventID.
+		docRef := userEventsCollection.NewDoc()
+		event.EventID = docRef.ID
+		jobs[i], errs[i] = bulkWriter.Create(docRef, event)
+	}
+	bulkWriter.End()
+
+	for i, job := range jobs {
+		if job != nil {
+			_, errs[i] = job.Results()
+		}
+		if errs[i] != nil {
+			errs[i] = firestoreError("Failed to create event", errs[i])
+			events[i].EventID = ""
+		}
+	}
+	return errs
+}
+
+// DeleteEvents deletes several of the user's events with a BulkWriter.
+func (er *FirestoreEventRepository) DeleteEvents(ctx context.Context, userEmail string, eventIDs []string) []error {
+	errs := make([]error, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return errs
+	}
+
+	userEventsCollection, err := er.events(ctx, userEmail)
+	if err != nil {
+		for i := range errs {
+			errs[i] = firestoreError("Failed to delete event", err)
+		}
+		return errs
+	}
+	bulkWriter := er.Client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, len(eventIDs))
+	for i, eventID := range eventIDs {
+		jobs[i], errs[i] = bulkWriter.Delete(userEventsCollection.Doc(eventID))
+	}
+	bulkWriter.End()
+
+	for i, job := range jobs {
+		if job != nil {
+			_, errs[i] = job.Results()
+		}
+		if errs[i] != nil {
+			errs[i] = firestoreError("Failed to delete event", errs[i])
+		}
+	}
+	return errs
+}
+
+// DeleteEventsByBatch deletes every event of the user with the given ImportBatchID with a
+// BulkWriter and returns how many were deleted.
+func (er *FirestoreEventRepository) DeleteEventsByBatch(ctx context.Context, userEmail, batchID string) (int, error) {
+	userEventsCollection, err := er.events(ctx, userEmail)
+	if err != nil {
+		return 0, firestoreError("Failed to retrieve imported events", err)
+	}
+	iter := userEventsCollection.
+		Where("ImportBatchID", "==", batchID).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var eventIDs []string
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, firestoreError("Failed to retrieve imported events", err)
+		}
+		eventIDs = append(eventIDs, doc.Ref.ID)
+	}
+
+	deleted := 0
+	var firstErr error
+	for _, err := range er.DeleteEvents(ctx, userEmail, eventIDs) {
+		if err == nil {
+			deleted++
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return deleted, firstErr
+}
+
+// GetImportBatches summarises the user's imported events by ImportBatchID, most recent import first.
+func (er *FirestoreEventRepository) GetImportBatches(ctx context.Context, userEmail string) ([]models.ImportBatch, error) {
+	userEventsCollection, err := er.events(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve imported events", err)
+	}
+	iter := userEventsCollection.
+		Where("ImportBatchID", ">", "").
+		Documents(ctx)
+	defer iter.Stop()
+
+	var events []models.Event
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to retrieve imported events", err)
+		}
+
+		var event models.Event
+		if err := doc.DataTo(&event); err != nil {
+			return nil, fmt.Errorf("Error parsing event data: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	return summarizeImportBatches(events), nil
+}
+
+// summarizeImportBatches counts imported events per ImportBatchID, most recent import first.
+func summarizeImportBatches(events []models.Event) []models.ImportBatch {
+	batches := []models.ImportBatch{}
+	indexes := make(map[string]int)
+	for _, event := range events {
+		i, exists := indexes[event.ImportBatchID]
+		if !exists {
+			i = len(batches)
+			indexes[event.ImportBatchID] = i
+			batches = append(batches, models.ImportBatch{BatchID: event.ImportBatchID})
+			if event.ImportedAt != nil {
+				batches[i].ImportedAt = *event.ImportedAt
+			}
+		}
+		batches[i].EventCount++
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].ImportedAt.After(batches[j].ImportedAt) })
+	return batches
+}
+
+// CountEvents counts the user's events, stopping at limit, reading only their document names.
+func (er *FirestoreEventRepository) CountEvents(ctx context.Context, userEmail string, limit int) (int, error) {
+	userEventsCollection, err := er.events(ctx, userEmail)
+	if err != nil {
+		return 0, firestoreError("Failed to count events", err)
+	}
+	query := userEventsCollection.Select()
+	return countDocuments(ctx, query, limit, "Failed to count events", nil)
+}
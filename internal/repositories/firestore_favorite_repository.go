@@ -0,0 +1,99 @@
+/**
+ *  FirestoreFavoriteRepository implements the FavoriteRepository interface, storing each user's
+ *  favourite quotes in the `favorites` subcollection of their user document.
+ *
+ *  @struct   FirestoreFavoriteRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewFirestoreFavoriteRepository(client) - Creates a new FirestoreFavoriteRepository instance.
+ *  - SaveFavorite(ctx, favorite)            - Stores a favourite quote in the user's collection.
+ *  - GetFavorites(ctx, userEmail)           - Retrieves a user's favourite quotes, most recently saved first.
+ *
+ *  @behaviors
+ *  - Favourites are stored at users/{email}/favorites/{quoteID}, so saving a quote twice keeps one
+ *    document, and they move with the user on an email change.
+ *
+ *  @dependencies
+ *  - cloud.google.com/go/firestore: Provides the Firestore client for database operations.
+ *  - google.golang.org/api/iterator: Handles Firestore document iteration.
+ *  - models.FavoriteQuote: Defines the structure of a saved quote.
+ *
+ *  @file      firestore_favorite_repository.go
+ *  @project   DailyVerse
+ *  @framework Go with Firestore integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"proh2052-group6/pkg/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// FirestoreFavoriteRepository provides Firestore-based implementation of FavoriteRepository.
+type FirestoreFavoriteRepository struct {
+	Client *firestore.Client // Firestore client for database operations.
+}
+
+// NewFirestoreFavoriteRepository initializes a new FirestoreFavoriteRepository instance.
+func NewFirestoreFavoriteRepository(client *firestore.Client) FavoriteRepository {
+	return &FirestoreFavoriteRepository{Client: client}
+}
+
+// favorites returns the favorites subcollection of a user.
+func (fr *FirestoreFavoriteRepository) favorites(ctx context.Context, userEmail string) (*firestore.CollectionRef, error) {
+	user, err := userDoc(ctx, fr.Client, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	return user.Collection("favorites"), nil
+}
+
+// SaveFavorite stores a favourite quote under its quote ID in the user's collection.
+func (fr *FirestoreFavoriteRepository) SaveFavorite(ctx context.Context, favorite *models.FavoriteQuote) error {
+	favorites, err := fr.favorites(ctx, favorite.Email)
+	if err != nil {
+		return firestoreError("Failed to save favorite", err)
+	}
+	if _, err := favorites.Doc(favorite.ID).Set(ctx, favorite); err != nil {
+		return firestoreError("Failed to save favorite", err)
+	}
+	return nil
+}
+
+// GetFavorites retrieves a user's favourite quotes, most recently saved first.
+func (fr *FirestoreFavoriteRepository) GetFavorites(ctx context.Context, userEmail string) ([]models.FavoriteQuote, error) {
+	collection, err := fr.favorites(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve favorites", err)
+	}
+	iter := collection.OrderBy("SavedAt", firestore.Desc).Documents(ctx)
+	defer iter.Stop()
+
+	favorites := []models.FavoriteQuote{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to retrieve favorites", err)
+		}
+		var favorite models.FavoriteQuote
+		if err := doc.DataTo(&favorite); err != nil {
+			return nil, fmt.Errorf("Failed to parse favorite data: %v", err)
+		}
+		favorites = append(favorites, favorite)
+	}
+	return favorites, nil
+}
@@ -0,0 +1,123 @@
+/**
+ *  FirestoreFriendInvitationRepository implements the FriendInvitationRepository interface, storing
+ *  invitations in the `friendInvitations` collection.
+ *
+ *  @struct   FirestoreFriendInvitationRepository
+ *  @inherits FriendInvitationRepository
+ *
+ *  @methods
+ *  - NewFirestoreFriendInvitationRepository(client)                     - Creates a new FirestoreFriendInvitationRepository instance.
+ *  - CreateFriendInvitation(ctx, invitation)                            - Stores a new invitation.
+ *  - GetFriendInvitation(ctx, inviteeEmail, inviterEmail)               - Retrieves the invitation from inviter to invitee.
+ *  - GetPendingFriendInvitations(ctx, inviteeEmail)                     - Retrieves the invitations to an address not yet consumed.
+ *  - ConsumeFriendInvitation(ctx, inviteeEmail, inviterEmail, consumedAt) - Marks an invitation as consumed.
+ *
+ *  @behaviors
+ *  - Documents are keyed by CompositeID(inviteeEmail, inviterEmail), so an inviter has at most one
+ *    invitation per address. Invitations stored under the legacy raw ID are still found. The collection is separate from `invitations`, which holds event invitations.
+ *  - Consumed invitations are kept, so an inviter is not told to invite someone who has already joined.
+ *
+ *  @dependencies
+ *  - cloud.google.com/go/firestore: Provides the Firestore client for database operations.
+ *  - google.golang.org/api/iterator: Handles Firestore document iteration.
+ *  - models.FriendInvitation: Defines the structure of an invitation.
+ *
+ *  @file      firestore_friend_invitation_repository.go
+ *  @project   DailyVerse
+ *  @framework Go with Firestore integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"proh2052-group6/pkg/models"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// FirestoreFriendInvitationRepository provides Firestore-based implementation of FriendInvitationRepository.
+type FirestoreFriendInvitationRepository struct {
+	Client *firestore.Client // Firestore client for database operations.
+}
+
+// NewFirestoreFriendInvitationRepository initializes a new FirestoreFriendInvitationRepository instance.
+func NewFirestoreFriendInvitationRepository(client *firestore.Client) FriendInvitationRepository {
+	return &FirestoreFriendInvitationRepository{Client: client}
+}
+
+// CreateFriendInvitation stores a new invitation, replacing an earlier one from the same inviter.
+func (fr *FirestoreFriendInvitationRepository) CreateFriendInvitation(ctx context.Context, invitation *models.FriendInvitation) error {
+	docRef, err := resolveDoc(ctx, fr.Client.Collection("friendInvitations"), invitation.InviteeEmail, invitation.InviterEmail)
+	if err != nil {
+		return firestoreError("Failed to create friend invitation", err)
+	}
+	if _, err := docRef.Set(ctx, invitation); err != nil {
+		return firestoreError("Failed to create friend invitation", err)
+	}
+	return nil
+}
+
+// GetFriendInvitation retrieves the invitation from inviterEmail to inviteeEmail.
+func (fr *FirestoreFriendInvitationRepository) GetFriendInvitation(ctx context.Context, inviteeEmail, inviterEmail string) (*models.FriendInvitation, error) {
+	doc, err := getDoc(ctx, fr.Client.Collection("friendInvitations"), inviteeEmail, inviterEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve friend invitation", err)
+	}
+	var invitation models.FriendInvitation
+	if err := doc.DataTo(&invitation); err != nil {
+		return nil, fmt.Errorf("Failed to parse friend invitation data: %v", err)
+	}
+	return &invitation, nil
+}
+
+// GetPendingFriendInvitations retrieves the invitations to inviteeEmail that have not been consumed, oldest first.
+func (fr *FirestoreFriendInvitationRepository) GetPendingFriendInvitations(ctx context.Context, inviteeEmail string) ([]models.FriendInvitation, error) {
+	iter := fr.Client.Collection("friendInvitations").Where("InviteeEmail", "==", inviteeEmail).Documents(ctx)
+	defer iter.Stop()
+
+	invitations := []models.FriendInvitation{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to retrieve friend invitations", err)
+		}
+		var invitation models.FriendInvitation
+		if err := doc.DataTo(&invitation); err != nil {
+			return nil, fmt.Errorf("Failed to parse friend invitation data: %v", err)
+		}
+		if invitation.ConsumedAt == nil {
+			invitations = append(invitations, invitation)
+		}
+	}
+	// Sorted here rather than in the query, so it needs no composite index.
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.Before(invitations[j].CreatedAt)
+	})
+	return invitations, nil
+}
+
+// ConsumeFriendInvitation marks the invitation from inviterEmail to inviteeEmail as consumed at consumedAt.
+func (fr *FirestoreFriendInvitationRepository) ConsumeFriendInvitation(ctx context.Context, inviteeEmail, inviterEmail string, consumedAt time.Time) error {
+	docRef, err := resolveDoc(ctx, fr.Client.Collection("friendInvitations"), inviteeEmail, inviterEmail)
+	if err != nil {
+		return firestoreError("Failed to consume friend invitation", err)
+	}
+	_, err = docRef.Update(ctx, []firestore.Update{{Path: "ConsumedAt", Value: consumedAt}})
+	if err != nil {
+		return firestoreError("Failed to consume friend invitation", err)
+	}
+	return nil
+}
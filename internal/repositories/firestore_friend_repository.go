@@ -14,15 +14,32 @@
  *  - CreateFriendRequest(ctx, friend)                        - Creates a friend request document in Firestore.
  *  - GetFriendRequest(ctx, senderEmail, recipientEmail)      - Retrieves a specific friend request document.
  *  - UpdateFriendRequest(ctx, senderEmail, recipientEmail)   - Updates fields in an existing friend request document.
+ *  - AcceptFriendRequest(ctx, senderEmail, recipientEmail)   - Accepts a pending friend request in a transaction.
  *  - DeleteFriendRequest(ctx, senderEmail, recipientEmail)   - Deletes a specific friend request document.
  *  - GetFriends(ctx, userEmail)                              - Retrieves all friends for a user with an "accepted" status.
  *  - GetPendingFriendRequests(ctx, userEmail)                - Retrieves all pending friend requests for a user.
+ *  - GetSentFriendRequests(ctx, userEmail)                   - Retrieves all pending friend requests sent by a user.
+ *  - GetFriendsOfUsers(ctx, userEmails)                      - Retrieves the accepted friendships of many users with batched "in" queries.
+ *  - CreateBlock(ctx, block)                                 - Creates a block document in Firestore.
+ *  - GetBlock(ctx, blockerEmail, blockedEmail)               - Retrieves a specific block document.
+ *  - DeleteBlock(ctx, blockerEmail, blockedEmail)            - Deletes a specific block document.
+ *  - GetBlockedUsers(ctx, blockerEmail)                      - Retrieves all blocks created by a user.
+ *  - MigrateFriendEmail(ctx, oldEmail, newEmail)             - Re-keys friend requests and blocks after an email change.
+ *  - DeleteStaleFriendRequests(ctx, pendingBefore, declinedBefore) - Deletes expired pending requests and old declined ones.
+ *  - CountFriends(ctx, userEmail, limit)      - Counts a user's accepted friendships without reading their fields.
  *
  *  @behaviors
- *  - Ensures friend request documents are uniquely identified using a composite key: `<senderEmail>_<recipientEmail>`.
+ *  - Ensures friend request documents are uniquely identified using a composite key: `<senderEmail>_<recipientEmail>`,
+ *    built by CompositeID from the escaped emails so an underscore in an email cannot make it ambiguous.
+ *    Requests and blocks stored under the raw emails before are still read, updated and deleted there.
  *  - Allows querying both sent and received friend requests by filtering on `Email` or `FriendEmail` fields.
- *  - Supports updating only specific fields in friend request documents using Firestore's `MergeAll` option.
- *  - Handles Firestore errors gracefully, returning `nil` for `NotFound` errors in `GetFriendRequest`.
+ *  - Updates only specific fields in friend request documents, failing for requests that do not exist.
+ *  - Accepts friend requests in a transaction that re-reads the request, so a request deleted concurrently
+ *    is not recreated by the update.
+ *  - Stale requests are deleted only if unchanged since they were read, so a request sent again meanwhile is kept.
+ *  - Stores blocks in a separate `blocks` collection keyed by `<blockerEmail>_<blockedEmail>`, escaped the same way.
+ *  - Handles Firestore errors gracefully, returning `nil` for `NotFound` errors in `GetFriendRequest` and `GetBlock`.
+ *    Other failures are translated into ErrNotFound and ErrUnavailable.
  *
  *  @examples
  *  Create a Friend Request:
@@ -62,9 +79,12 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"proh2052-group6/pkg/models"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
@@ -82,20 +102,22 @@ func NewFirestoreFriendRepository(client *firestore.Client) FriendRepository {
 
 // CreateFriendRequest creates a new friend request document in Firestore.
 func (fr *FirestoreFriendRepository) CreateFriendRequest(ctx context.Context, friend *models.Friend) error {
-	docID := friend.Email + "_" + friend.FriendEmail
-	_, err := fr.Client.Collection("friends").Doc(docID).Set(ctx, friend)
-	return err
+	docRef, err := resolveDoc(ctx, fr.Client.Collection("friends"), friend.Email, friend.FriendEmail)
+	if err != nil {
+		return firestoreError("Failed to create friend request", err)
+	}
+	_, err = docRef.Set(ctx, friend)
+	return firestoreError("Failed to create friend request", err)
 }
 
 // GetFriendRequest retrieves a specific friend request document by sender and recipient emails.
 func (fr *FirestoreFriendRepository) GetFriendRequest(ctx context.Context, senderEmail, recipientEmail string) (*models.Friend, error) {
-	docID := senderEmail + "_" + recipientEmail
-	doc, err := fr.Client.Collection("friends").Doc(docID).Get(ctx)
+	doc, err := getDoc(ctx, fr.Client.Collection("friends"), senderEmail, recipientEmail)
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
 			return nil, nil // Return nil if document not found.
 		}
-		return nil, err
+		return nil, firestoreError("Failed to get friend request", err)
 	}
 	var friend models.Friend
 	if err := doc.DataTo(&friend); err != nil {
@@ -105,17 +127,61 @@ func (fr *FirestoreFriendRepository) GetFriendRequest(ctx context.Context, sende
 }
 
 // UpdateFriendRequest updates specific fields in an existing friend request document.
+// It fails rather than creating a request that does not exist.
 func (fr *FirestoreFriendRepository) UpdateFriendRequest(ctx context.Context, senderEmail, recipientEmail string, updates map[string]interface{}) error {
-	docID := senderEmail + "_" + recipientEmail
-	_, err := fr.Client.Collection("friends").Doc(docID).Set(ctx, updates, firestore.MergeAll)
-	return err
+	if len(updates) == 0 {
+		return nil
+	}
+	docRef, err := resolveDoc(ctx, fr.Client.Collection("friends"), senderEmail, recipientEmail)
+	if err != nil {
+		return firestoreError("Failed to update friend request", err)
+	}
+	_, err = docRef.Update(ctx, fieldUpdates(updates))
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("friend request %w", ErrNotFound)
+	}
+	return firestoreError("Failed to update friend request", err)
+}
+
+// AcceptFriendRequest sets the status of a pending friend request to "accepted" in a transaction.
+// It returns ErrFriendRequestNotPending if the request was deleted or is no longer pending.
+func (fr *FirestoreFriendRepository) AcceptFriendRequest(ctx context.Context, senderEmail, recipientEmail string) error {
+	docRef, err := resolveDoc(ctx, fr.Client.Collection("friends"), senderEmail, recipientEmail)
+	if err != nil {
+		return firestoreError("Failed to accept friend request", err)
+	}
+	err = fr.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrFriendRequestNotPending
+			}
+			return err
+		}
+		var friend models.Friend
+		if err := doc.DataTo(&friend); err != nil {
+			return err
+		}
+		if friend.Status != "pending" {
+			return ErrFriendRequestNotPending
+		}
+		// Update, unlike Set with MergeAll, fails rather than creating a missing document.
+		return tx.Update(docRef, []firestore.Update{{Path: "Status", Value: "accepted"}})
+	})
+	if errors.Is(err, ErrFriendRequestNotPending) {
+		return ErrFriendRequestNotPending
+	}
+	return firestoreError("Failed to accept friend request", err)
 }
 
 // DeleteFriendRequest deletes a specific friend request document from Firestore.
 func (fr *FirestoreFriendRepository) DeleteFriendRequest(ctx context.Context, senderEmail, recipientEmail string) error {
-	docID := senderEmail + "_" + recipientEmail
-	_, err := fr.Client.Collection("friends").Doc(docID).Delete(ctx)
-	return err
+	docRef, err := resolveDoc(ctx, fr.Client.Collection("friends"), senderEmail, recipientEmail)
+	if err != nil {
+		return firestoreError("Failed to delete friend request", err)
+	}
+	_, err = docRef.Delete(ctx)
+	return firestoreError("Failed to delete friend request", err)
 }
 
 // GetFriends retrieves all accepted friends for a user.
@@ -131,7 +197,7 @@ func (fr *FirestoreFriendRepository) GetFriends(ctx context.Context, userEmail s
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, firestoreError("Failed to fetch friends", err)
 		}
 		var friend models.Friend
 		if err := doc.DataTo(&friend); err != nil {
@@ -149,7 +215,7 @@ func (fr *FirestoreFriendRepository) GetFriends(ctx context.Context, userEmail s
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, firestoreError("Failed to fetch friends", err)
 		}
 		var friend models.Friend
 		if err := doc.DataTo(&friend); err != nil {
@@ -174,7 +240,7 @@ func (fr *FirestoreFriendRepository) GetPendingFriendRequests(ctx context.Contex
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, firestoreError("Failed to fetch pending friend requests", err)
 		}
 
 		var friend models.Friend
@@ -187,3 +253,231 @@ func (fr *FirestoreFriendRepository) GetPendingFriendRequests(ctx context.Contex
 
 	return friends, nil
 }
+
+// GetSentFriendRequests fetches all pending friend requests sent by a user.
+func (fr *FirestoreFriendRepository) GetSentFriendRequests(ctx context.Context, userEmail string) ([]models.Friend, error) {
+	var friends []models.Friend
+
+	iter := fr.Client.Collection("friends").Where("Email", "==", userEmail).Where("Status", "==", "pending").Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to fetch sent friend requests", err)
+		}
+		var friend models.Friend
+		if err := doc.DataTo(&friend); err != nil {
+			continue
+		}
+		friends = append(friends, friend)
+	}
+
+	return friends, nil
+}
+
+// firestoreInLimit is the maximum number of values in a Firestore "in" filter.
+const firestoreInLimit = 10
+
+// GetFriendsOfUsers fetches the accepted friendships of any of the given users. Users are queried
+// in batches of firestoreInLimit, as sender and as recipient, so n users take 2*ceil(n/10) queries.
+func (fr *FirestoreFriendRepository) GetFriendsOfUsers(ctx context.Context, userEmails []string) ([]models.Friend, error) {
+	var friends []models.Friend
+	seen := make(map[string]bool)
+
+	for start := 0; start < len(userEmails); start += firestoreInLimit {
+		end := start + firestoreInLimit
+		if end > len(userEmails) {
+			end = len(userEmails)
+		}
+		batch := userEmails[start:end]
+
+		for _, field := range []string{"Email", "FriendEmail"} {
+			iter := fr.Client.Collection("friends").Where(field, "in", batch).Where("Status", "==", "accepted").Documents(ctx)
+			for {
+				doc, err := iter.Next()
+				if err == iterator.Done {
+					break
+				}
+				if err != nil {
+					iter.Stop()
+					return nil, firestoreError("Failed to fetch friends", err)
+				}
+
+				// A friendship between two of the users matches both queries.
+				if seen[doc.Ref.ID] {
+					continue
+				}
+				seen[doc.Ref.ID] = true
+
+				var friend models.Friend
+				if err := doc.DataTo(&friend); err != nil {
+					continue
+				}
+				friends = append(friends, friend)
+			}
+			iter.Stop()
+		}
+	}
+
+	return friends, nil
+}
+
+// CreateBlock creates a block document in Firestore.
+func (fr *FirestoreFriendRepository) CreateBlock(ctx context.Context, block *models.Block) error {
+	docRef, err := resolveDoc(ctx, fr.Client.Collection("blocks"), block.BlockerEmail, block.BlockedEmail)
+	if err != nil {
+		return firestoreError("Failed to create block", err)
+	}
+	_, err = docRef.Set(ctx, block)
+	return firestoreError("Failed to create block", err)
+}
+
+// GetBlock retrieves a specific block document by blocker and blocked emails.
+func (fr *FirestoreFriendRepository) GetBlock(ctx context.Context, blockerEmail, blockedEmail string) (*models.Block, error) {
+	doc, err := getDoc(ctx, fr.Client.Collection("blocks"), blockerEmail, blockedEmail)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil // Return nil if document not found.
+		}
+		return nil, firestoreError("Failed to get block", err)
+	}
+	var block models.Block
+	if err := doc.DataTo(&block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// DeleteBlock deletes a specific block document from Firestore.
+func (fr *FirestoreFriendRepository) DeleteBlock(ctx context.Context, blockerEmail, blockedEmail string) error {
+	docRef, err := resolveDoc(ctx, fr.Client.Collection("blocks"), blockerEmail, blockedEmail)
+	if err != nil {
+		return firestoreError("Failed to delete block", err)
+	}
+	_, err = docRef.Delete(ctx)
+	return firestoreError("Failed to delete block", err)
+}
+
+// GetBlockedUsers fetches all blocks created by a user.
+func (fr *FirestoreFriendRepository) GetBlockedUsers(ctx context.Context, blockerEmail string) ([]models.Block, error) {
+	var blocks []models.Block
+
+	iter := fr.Client.Collection("blocks").Where("BlockerEmail", "==", blockerEmail).Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to fetch blocked users", err)
+		}
+		var block models.Block
+		if err := doc.DataTo(&block); err != nil {
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// MigrateFriendEmail replaces oldEmail with newEmail in every friend request and block involving it.
+// The documents are moved to new IDs, since their IDs are composed of both emails.
+func (fr *FirestoreFriendRepository) MigrateFriendEmail(ctx context.Context, oldEmail, newEmail string) error {
+	migrations := []struct {
+		collection    string
+		first, second string // Fields whose values make up the document ID.
+	}{
+		{"friends", "Email", "FriendEmail"},
+		{"blocks", "BlockerEmail", "BlockedEmail"},
+	}
+
+	for _, migration := range migrations {
+		collection := fr.Client.Collection(migration.collection)
+		for _, field := range []string{migration.first, migration.second} {
+			err := rewriteDocuments(ctx, fr.Client, collection.Where(field, "==", oldEmail), func(doc *firestore.DocumentSnapshot, data map[string]interface{}) *firestore.DocumentRef {
+				data[field] = newEmail
+				return collection.Doc(CompositeID(fmt.Sprint(data[migration.first]), fmt.Sprint(data[migration.second])))
+			})
+			if err != nil {
+				return firestoreError(fmt.Sprintf("Failed to migrate %s", migration.collection), err)
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteStaleFriendRequests deletes the pending requests created before pendingBefore and the
+// declined requests declined before declinedBefore, and returns how many were deleted.
+func (fr *FirestoreFriendRepository) DeleteStaleFriendRequests(ctx context.Context, pendingBefore, declinedBefore time.Time) (int, error) {
+	friends := fr.Client.Collection("friends")
+	queries := []firestore.Query{
+		friends.Where("Status", "==", "pending").Where("CreatedAt", "<", pendingBefore),
+		friends.Where("Status", "==", "declined").Where("DeclinedAt", "<", declinedBefore),
+	}
+
+	var stale []*firestore.DocumentSnapshot
+	for _, query := range queries {
+		iter := query.Documents(ctx)
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				iter.Stop()
+				return 0, firestoreError("Failed to retrieve stale friend requests", err)
+			}
+			var friend models.Friend
+			if err := doc.DataTo(&friend); err != nil {
+				iter.Stop()
+				return 0, fmt.Errorf("Failed to parse friend request data: %w", err)
+			}
+			// Requests sent before CreatedAt was recorded have no age to expire by.
+			if friend.Status == "pending" && friend.CreatedAt.IsZero() {
+				continue
+			}
+			stale = append(stale, doc)
+		}
+		iter.Stop()
+	}
+
+	deleted := 0
+	for _, doc := range stale {
+		_, err := doc.Ref.Delete(ctx, firestore.LastUpdateTime(doc.UpdateTime))
+		if status.Code(err) == codes.FailedPrecondition || status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return deleted, firestoreError("Failed to delete stale friend request", err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// CountFriends counts the user's accepted friendships in both directions, stopping at limit,
+// reading only their document names.
+func (fr *FirestoreFriendRepository) CountFriends(ctx context.Context, userEmail string, limit int) (int, error) {
+	total := 0
+	for _, field := range []string{"Email", "FriendEmail"} {
+		remaining := 0
+		if limit > 0 {
+			remaining = limit - total
+			if remaining <= 0 {
+				break
+			}
+		}
+		query := fr.Client.Collection("friends").Where(field, "==", userEmail).Where("Status", "==", "accepted").Select()
+		count, err := countDocuments(ctx, query, remaining, "Failed to count friends", nil)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
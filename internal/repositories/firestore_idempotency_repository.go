@@ -0,0 +1,148 @@
+/**
+ *  FirestoreIdempotencyRepository implements the IdempotencyRepository interface, storing each
+ *  user's idempotency keys in the `idempotencyKeys` subcollection of their user document.
+ *
+ *  @struct   FirestoreIdempotencyRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewFirestoreIdempotencyRepository(client) - Creates a new FirestoreIdempotencyRepository instance.
+ *  - CreateRecord(ctx, record)                 - Stores a record in a transaction unless a live one exists.
+ *  - GetRecord(ctx, userEmail, key)            - Retrieves the record of a user's key.
+ *  - CompleteRecord(ctx, record)               - Stores the status, event ID and response of a record.
+ *  - DeleteRecord(ctx, userEmail, key)         - Removes the record of a user's key.
+ *
+ *  @behaviors
+ *  - Records are stored at users/{email}/idempotencyKeys/{sha256(key)}, since keys may contain
+ *    characters that are not allowed in document IDs.
+ *  - Expired records are replaced when the key is used again; a TTL policy on ExpiresAt can be
+ *    configured to remove the ones that are never reused. Pending records whose lease expired before
+ *    an event was created for them are replaced too.
+ *
+ *  @dependencies
+ *  - cloud.google.com/go/firestore: Provides the Firestore client for database operations.
+ *  - models.IdempotencyRecord: Defines the structure of an idempotency record.
+ *
+ *  @file      firestore_idempotency_repository.go
+ *  @project   DailyVerse
+ *  @framework Go with Firestore integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"proh2052-group6/pkg/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FirestoreIdempotencyRepository provides Firestore-based implementation of IdempotencyRepository.
+type FirestoreIdempotencyRepository struct {
+	Client *firestore.Client // Firestore client for database operations.
+}
+
+// NewFirestoreIdempotencyRepository initializes a new FirestoreIdempotencyRepository instance.
+func NewFirestoreIdempotencyRepository(client *firestore.Client) IdempotencyRepository {
+	return &FirestoreIdempotencyRepository{Client: client}
+}
+
+// record returns the document of a user's idempotency key.
+func (ir *FirestoreIdempotencyRepository) record(ctx context.Context, userEmail, key string) (*firestore.DocumentRef, error) {
+	user, err := userDoc(ctx, ir.Client, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(key))
+	return user.Collection("idempotencyKeys").Doc(hex.EncodeToString(hash[:])), nil
+}
+
+// CreateRecord stores record unless the user has a record for the key that IdempotencyRecordReplaceable keeps.
+func (ir *FirestoreIdempotencyRepository) CreateRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	docRef, err := ir.record(ctx, record.Email, record.Key)
+	if err != nil {
+		return firestoreError("Failed to create idempotency record", err)
+	}
+	err = ir.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err == nil {
+			var existing models.IdempotencyRecord
+			if err := doc.DataTo(&existing); err != nil {
+				return fmt.Errorf("Failed to parse idempotency record: %v", err)
+			}
+			if !IdempotencyRecordReplaceable(&existing, record.CreatedAt) {
+				return ErrIdempotencyKeyExists
+			}
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		return tx.Set(docRef, record)
+	})
+	if errors.Is(err, ErrIdempotencyKeyExists) {
+		return ErrIdempotencyKeyExists
+	}
+	if err != nil {
+		return firestoreError("Failed to create idempotency record", err)
+	}
+	return nil
+}
+
+// GetRecord retrieves the record of a user's key.
+func (ir *FirestoreIdempotencyRepository) GetRecord(ctx context.Context, userEmail, key string) (*models.IdempotencyRecord, error) {
+	docRef, err := ir.record(ctx, userEmail, key)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve idempotency record", err)
+	}
+	doc, err := docRef.Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve idempotency record", err)
+	}
+	var record models.IdempotencyRecord
+	if err := doc.DataTo(&record); err != nil {
+		return nil, fmt.Errorf("Failed to parse idempotency record: %v", err)
+	}
+	return &record, nil
+}
+
+// CompleteRecord stores the status, event ID and response of record.
+func (ir *FirestoreIdempotencyRepository) CompleteRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	docRef, err := ir.record(ctx, record.Email, record.Key)
+	if err != nil {
+		return firestoreError("Failed to complete idempotency record", err)
+	}
+	_, err = docRef.Update(ctx, []firestore.Update{
+		{Path: "Status", Value: record.Status},
+		{Path: "EventID", Value: record.EventID},
+		{Path: "Response", Value: record.Response},
+	})
+	if err != nil {
+		return firestoreError("Failed to complete idempotency record", err)
+	}
+	return nil
+}
+
+// DeleteRecord removes the record of a user's key.
+func (ir *FirestoreIdempotencyRepository) DeleteRecord(ctx context.Context, userEmail, key string) error {
+	docRef, err := ir.record(ctx, userEmail, key)
+	if err != nil {
+		return firestoreError("Failed to delete idempotency record", err)
+	}
+	if _, err := docRef.Delete(ctx); err != nil {
+		return firestoreError("Failed to delete idempotency record", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/**
+ *  FirestoreInvitationRepository provides methods to interact with the Firestore database for
+ *  event invitation operations. Invitations are stored in a top-level `invitations` collection
+ *  with document IDs of the form `{eventID}_{inviteeEmail}`.
+ *
+ *  @struct   FirestoreInvitationRepository
+ *  @inherits InvitationRepository
+ *
+ *  @methods
+ *  - NewFirestoreInvitationRepository(client)              - Initializes a new repository with a Firestore client.
+ *  - CreateInvitation(ctx, invitation)                     - Creates a new invitation document.
+ *  - GetInvitation(ctx, eventID, inviteeEmail)             - Fetches an invitation; returns nil if it does not exist.
+ *  - UpdateInvitation(ctx, eventID, inviteeEmail, updates) - Merges the given fields into an invitation.
+ *  - GetInvitationsForUser(ctx, inviteeEmail)              - Retrieves all invitations received by a user.
+ *  - GetInvitationsForEvent(ctx, ownerEmail, eventID)      - Retrieves all invitations sent for an event.
+ *  - MigrateInvitationEmail(ctx, oldEmail, newEmail)       - Rewrites the owner and invitee emails after an email change.
+ *
+ *  @dependencies
+ *  - cloud.google.com/go/firestore: Firestore client for database operations.
+ *  - google.golang.org/api/iterator: Iterator for traversing Firestore query results.
+ *  - models.EventInvitation: Struct representing invitation data.
+ *
+ *  @file      firestore_invitation_repository.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server & Firestore API
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"proh2052-group6/pkg/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// FirestoreInvitationRepository manages event invitation operations in Firestore.
+type FirestoreInvitationRepository struct {
+	Client *firestore.Client // Firestore client instance.
+}
+
+// NewFirestoreInvitationRepository initializes a new FirestoreInvitationRepository.
+func NewFirestoreInvitationRepository(client *firestore.Client) InvitationRepository {
+	return &FirestoreInvitationRepository{Client: client}
+}
+
+// CreateInvitation creates a new invitation document in Firestore.
+func (ir *FirestoreInvitationRepository) CreateInvitation(ctx context.Context, invitation *models.EventInvitation) error {
+	docID := invitation.EventID + "_" + invitation.InviteeEmail
+	_, err := ir.Client.Collection("invitations").Doc(docID).Set(ctx, invitation)
+	return firestoreError("Failed to create invitation", err)
+}
+
+// GetInvitation retrieves the invitation of a user to an event.
+func (ir *FirestoreInvitationRepository) GetInvitation(ctx context.Context, eventID, inviteeEmail string) (*models.EventInvitation, error) {
+	docID := eventID + "_" + inviteeEmail
+	doc, err := ir.Client.Collection("invitations").Doc(docID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil // Return nil if document not found.
+		}
+		return nil, firestoreError("Failed to get invitation", err)
+	}
+	var invitation models.EventInvitation
+	if err := doc.DataTo(&invitation); err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// UpdateInvitation updates specific fields in an existing invitation document.
+func (ir *FirestoreInvitationRepository) UpdateInvitation(ctx context.Context, eventID, inviteeEmail string, updates map[string]interface{}) error {
+	docID := eventID + "_" + inviteeEmail
+	_, err := ir.Client.Collection("invitations").Doc(docID).Set(ctx, updates, firestore.MergeAll)
+	return firestoreError("Failed to update invitation", err)
+}
+
+// GetInvitationsForUser retrieves all invitations received by a user.
+func (ir *FirestoreInvitationRepository) GetInvitationsForUser(ctx context.Context, inviteeEmail string) ([]models.EventInvitation, error) {
+	return ir.queryInvitations(ctx, ir.Client.Collection("invitations").Where("InviteeEmail", "==", inviteeEmail))
+}
+
+// GetInvitationsForEvent retrieves all invitations sent by ownerEmail for one of their events.
+// Event IDs are only unique per owner, so both are matched.
+func (ir *FirestoreInvitationRepository) GetInvitationsForEvent(ctx context.Context, ownerEmail, eventID string) ([]models.EventInvitation, error) {
+	return ir.queryInvitations(ctx, ir.Client.Collection("invitations").
+		Where("OwnerEmail", "==", ownerEmail).
+		Where("EventID", "==", eventID))
+}
+
+// queryInvitations returns the invitations matched by query, skipping documents that cannot be decoded.
+func (ir *FirestoreInvitationRepository) queryInvitations(ctx context.Context, query firestore.Query) ([]models.EventInvitation, error) {
+	var invitations []models.EventInvitation
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to fetch invitations", err)
+		}
+		var invitation models.EventInvitation
+		if err := doc.DataTo(&invitation); err != nil {
+			continue
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, nil
+}
+
+// MigrateInvitationEmail replaces oldEmail with newEmail as the owner or invitee of every invitation.
+// Invitations received by the user are moved to new IDs, since their IDs contain the invitee's email.
+func (ir *FirestoreInvitationRepository) MigrateInvitationEmail(ctx context.Context, oldEmail, newEmail string) error {
+	invitations := ir.Client.Collection("invitations")
+
+	err := rewriteDocuments(ctx, ir.Client, invitations.Where("OwnerEmail", "==", oldEmail), func(doc *firestore.DocumentSnapshot, data map[string]interface{}) *firestore.DocumentRef {
+		data["OwnerEmail"] = newEmail
+		return doc.Ref
+	})
+	if err != nil {
+		return firestoreError("Failed to migrate invitations", err)
+	}
+
+	err = rewriteDocuments(ctx, ir.Client, invitations.Where("InviteeEmail", "==", oldEmail), func(doc *firestore.DocumentSnapshot, data map[string]interface{}) *firestore.DocumentRef {
+		data["InviteeEmail"] = newEmail
+		return invitations.Doc(fmt.Sprint(data["EventID"]) + "_" + newEmail)
+	})
+	if err != nil {
+		return firestoreError("Failed to migrate invitations", err)
+	}
+	return nil
+}
@@ -11,9 +11,24 @@
  *  - NewFirestoreJournalRepository(client)          - Creates a new FirestoreJournalRepository instance.
  *  - CreateJournal(ctx, journal)                   - Adds a new journal to the user's collection.
  *  - GetJournal(ctx, userEmail, journalID)         - Retrieves a specific journal by its ID.
+ *  - GetJournalByDate(ctx, userEmail, date)        - Retrieves a user's journal for a specific date.
  *  - UpdateJournal(ctx, journal)                   - Updates an existing journal in Firestore.
  *  - DeleteJournal(ctx, userEmail, journalID)      - Deletes a journal by its ID.
  *  - GetAllJournals(ctx, userEmail)                - Retrieves all journals for a specific user.
+ *  - SearchJournals(ctx, userEmail, query, from, to, limit) - Searches a user's journals by content within a date range.
+ *  - StreamJournals(ctx, userEmail, from, to, fn)   - Iterates over a user's journals in date order.
+ *  - GetDeletedJournals(ctx, userEmail)            - Retrieves a user's journals in the trash.
+ *  - PurgeDeletedJournals(ctx, deletedBefore)      - Permanently deletes and returns journals trashed before a time.
+ *  - CountJournals(ctx, userEmail, limit)          - Counts a user's journals outside the trash.
+ *  - SaveJournals(ctx, journals)                   - Creates or replaces several journals with a BulkWriter.
+ *
+ *  @behaviors
+ *  - Missing journals are reported as ErrNotFound and unreachable databases as ErrUnavailable.
+ *  - Journals with a DeletedAt time are in the trash and are skipped while iterating over queries,
+ *    since Firestore cannot filter on a field being null without an extra index.
+ *  - Purging runs across every user's journals and keeps a journal restored since it was read.
+ *  - Bulk writes are sent in parallel batches by a BulkWriter. They are not atomic, so every journal
+ *    gets its own result.
  *
  *  @dependencies
  *  - cloud.google.com/go/firestore: Provides the Firestore client for database operations.
@@ -36,9 +51,13 @@ import (
 	"context"
 	"fmt"
 	"proh2052-group6/pkg/models"
+	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // FirestoreJournalRepository provides Firestore-based implementation of JournalRepository.
@@ -51,21 +70,33 @@ func NewFirestoreJournalRepository(client *firestore.Client) JournalRepository {
 	return &FirestoreJournalRepository{Client: client}
 }
 
+// journals returns the journals subcollection of the user with userEmail.
+func (jr *FirestoreJournalRepository) journals(ctx context.Context, userEmail string) (*firestore.CollectionRef, error) {
+	user, err := userDoc(ctx, jr.Client, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	return user.Collection("journals"), nil
+}
+
 // CreateJournal adds a new journal to the user's Firestore collection.
 func (jr *FirestoreJournalRepository) CreateJournal(ctx context.Context, journal *models.Journal) error {
-	userDocRef := jr.Client.Collection("users").Doc(journal.Email).Collection("journals")
+	userDocRef, err := jr.journals(ctx, journal.Email)
+	if err != nil {
+		return firestoreError("Failed to create journal", err)
+	}
 
 	// Add journal data to Firestore.
 	docRef, _, err := userDocRef.Add(ctx, journal)
 	if err != nil {
-		return fmt.Errorf("Failed to create journal: %v", err)
+		return firestoreError("Failed to create journal", err)
 	}
 
 	// Update the journal with its generated ID.
 	journal.JournalID = docRef.ID
 	_, err = docRef.Set(ctx, journal)
 	if err != nil {
-		return fmt.Errorf("Failed to update journal with JournalID: %v", err)
+		return firestoreError("Failed to update journal with JournalID", err)
 	}
 
 	return nil
@@ -73,10 +104,16 @@ func (jr *FirestoreJournalRepository) CreateJournal(ctx context.Context, journal
 
 // GetJournal retrieves a specific journal by its ID from Firestore.
 func (jr *FirestoreJournalRepository) GetJournal(ctx context.Context, userEmail, journalID string) (*models.Journal, error) {
-	docRef := jr.Client.Collection("users").Doc(userEmail).Collection("journals").Doc(journalID)
-	doc, err := docRef.Get(ctx)
+	userDocRef, err := jr.journals(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to get journal", err)
+	}
+	doc, err := userDocRef.Doc(journalID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("Journal %w", ErrNotFound)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("Journal not found: %v", err)
+		return nil, firestoreError("Failed to get journal", err)
 	}
 
 	// Map Firestore data to a Journal model.
@@ -89,29 +126,74 @@ func (jr *FirestoreJournalRepository) GetJournal(ctx context.Context, userEmail,
 	return &journal, nil
 }
 
+// GetJournalByDate retrieves the user's journal for the given date, or nil if there is none.
+func (jr *FirestoreJournalRepository) GetJournalByDate(ctx context.Context, userEmail, date string) (*models.Journal, error) {
+	userDocRef, err := jr.journals(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve journal", err)
+	}
+	iter := userDocRef.
+		Where("Date", "==", date).
+		Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to retrieve journal", err)
+		}
+
+		var journal models.Journal
+		err = doc.DataTo(&journal)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse journal data: %v", err)
+		}
+
+		// A journal in the trash does not occupy its date.
+		if journal.DeletedAt != nil {
+			continue
+		}
+
+		journal.JournalID = doc.Ref.ID
+		return &journal, nil
+	}
+}
+
 // UpdateJournal updates an existing journal in the Firestore collection.
 func (jr *FirestoreJournalRepository) UpdateJournal(ctx context.Context, journal *models.Journal) error {
-	docRef := jr.Client.Collection("users").Doc(journal.Email).Collection("journals").Doc(journal.JournalID)
-	_, err := docRef.Set(ctx, journal)
+	userDocRef, err := jr.journals(ctx, journal.Email)
 	if err != nil {
-		return fmt.Errorf("Failed to update journal: %v", err)
+		return firestoreError("Failed to update journal", err)
+	}
+	_, err = userDocRef.Doc(journal.JournalID).Set(ctx, journal)
+	if err != nil {
+		return firestoreError("Failed to update journal", err)
 	}
 	return nil
 }
 
 // DeleteJournal removes a journal from Firestore by its ID.
 func (jr *FirestoreJournalRepository) DeleteJournal(ctx context.Context, userEmail, journalID string) error {
-	docRef := jr.Client.Collection("users").Doc(userEmail).Collection("journals").Doc(journalID)
-	_, err := docRef.Delete(ctx)
+	userDocRef, err := jr.journals(ctx, userEmail)
+	if err != nil {
+		return firestoreError("Failed to delete journal", err)
+	}
+	_, err = userDocRef.Doc(journalID).Delete(ctx)
 	if err != nil {
-		return fmt.Errorf("Failed to delete journal: %v", err)
+		return firestoreError("Failed to delete journal", err)
 	}
 	return nil
 }
 
 // GetAllJournals retrieves all journals for a specific user from Firestore.
 func (jr *FirestoreJournalRepository) GetAllJournals(ctx context.Context, userEmail string) ([]models.Journal, error) {
-	userDocRef := jr.Client.Collection("users").Doc(userEmail).Collection("journals")
+	userDocRef, err := jr.journals(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve journals", err)
+	}
 	iter := userDocRef.Documents(ctx)
 
 	var journals []models.Journal
@@ -123,7 +205,7 @@ func (jr *FirestoreJournalRepository) GetAllJournals(ctx context.Context, userEm
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("Failed to retrieve journals: %v", err)
+			return nil, firestoreError("Failed to retrieve journals", err)
 		}
 
 		var journal models.Journal
@@ -131,6 +213,9 @@ func (jr *FirestoreJournalRepository) GetAllJournals(ctx context.Context, userEm
 		if err != nil {
 			return nil, fmt.Errorf("Failed to parse journal data: %v", err)
 		}
+		if journal.DeletedAt != nil {
+			continue
+		}
 
 		// Include the document ID in the journal.
 		journal.JournalID = doc.Ref.ID
@@ -139,3 +224,230 @@ func (jr *FirestoreJournalRepository) GetAllJournals(ctx context.Context, userEm
 
 	return journals, nil
 }
+
+// SearchJournals retrieves a user's journals within a date range, newest first, keeping only those
+// whose content contains query. Firestore has no substring search, so the date window is queried
+// and the text match is applied while iterating over the results.
+func (jr *FirestoreJournalRepository) SearchJournals(ctx context.Context, userEmail, query, from, to string, limit int) ([]models.Journal, error) {
+	userDocRef, err := jr.journals(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to search journals", err)
+	}
+	q := userDocRef.OrderBy("Date", firestore.Desc)
+	if from != "" {
+		q = q.Where("Date", ">=", from)
+	}
+	if to != "" {
+		q = q.Where("Date", "<=", to)
+	}
+
+	iter := q.Documents(ctx)
+	defer iter.Stop()
+
+	needle := strings.ToLower(query)
+	journals := []models.Journal{}
+
+	for limit <= 0 || len(journals) < limit {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to search journals", err)
+		}
+
+		var journal models.Journal
+		err = doc.DataTo(&journal)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse journal data: %v", err)
+		}
+
+		if journal.DeletedAt != nil {
+			continue
+		}
+		if needle != "" && !strings.Contains(strings.ToLower(journal.Content), needle) {
+			continue
+		}
+
+		// Include the document ID in the journal.
+		journal.JournalID = doc.Ref.ID
+		journals = append(journals, journal)
+	}
+
+	return journals, nil
+}
+
+// StreamJournals iterates over a user's journals within a date range, oldest first,
+// calling fn for each document as it is read from Firestore.
+func (jr *FirestoreJournalRepository) StreamJournals(ctx context.Context, userEmail, from, to string, fn func(models.Journal) error) error {
+	userDocRef, err := jr.journals(ctx, userEmail)
+	if err != nil {
+		return firestoreError("Failed to retrieve journals", err)
+	}
+	q := userDocRef.OrderBy("Date", firestore.Asc)
+	if from != "" {
+		q = q.Where("Date", ">=", from)
+	}
+	if to != "" {
+		q = q.Where("Date", "<=", to)
+	}
+
+	iter := q.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return firestoreError("Failed to retrieve journals", err)
+		}
+
+		var journal models.Journal
+		if err := doc.DataTo(&journal); err != nil {
+			return fmt.Errorf("Failed to parse journal data: %v", err)
+		}
+		if journal.DeletedAt != nil {
+			continue
+		}
+		journal.JournalID = doc.Ref.ID
+
+		if err := fn(journal); err != nil {
+			return err
+		}
+	}
+}
+
+// GetDeletedJournals retrieves a user's journals in the trash, most recently deleted first.
+func (jr *FirestoreJournalRepository) GetDeletedJournals(ctx context.Context, userEmail string) ([]models.Journal, error) {
+	userDocRef, err := jr.journals(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve deleted journals", err)
+	}
+	iter := userDocRef.
+		Where("DeletedAt", ">", time.Time{}).
+		OrderBy("DeletedAt", firestore.Desc).
+		Documents(ctx)
+	defer iter.Stop()
+
+	journals := []models.Journal{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to retrieve deleted journals", err)
+		}
+
+		var journal models.Journal
+		if err := doc.DataTo(&journal); err != nil {
+			return nil, fmt.Errorf("Failed to parse journal data: %v", err)
+		}
+		journal.JournalID = doc.Ref.ID
+		journals = append(journals, journal)
+	}
+
+	return journals, nil
+}
+
+// PurgeDeletedJournals permanently deletes the journals of all users that were moved to the trash
+// before deletedBefore, and returns the deleted journals.
+func (jr *FirestoreJournalRepository) PurgeDeletedJournals(ctx context.Context, deletedBefore time.Time) ([]models.Journal, error) {
+	iter := jr.Client.CollectionGroup("journals").Where("DeletedAt", "<", deletedBefore).Documents(ctx)
+	defer iter.Stop()
+
+	var expired []*firestore.DocumentSnapshot
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to retrieve deleted journals", err)
+		}
+		expired = append(expired, doc)
+	}
+
+	deleted := []models.Journal{}
+	for _, doc := range expired {
+		var journal models.Journal
+		if err := doc.DataTo(&journal); err != nil {
+			return deleted, fmt.Errorf("Failed to parse journal data: %v", err)
+		}
+		journal.JournalID = doc.Ref.ID
+
+		_, err := doc.Ref.Delete(ctx, firestore.LastUpdateTime(doc.UpdateTime))
+		if status.Code(err) == codes.FailedPrecondition || status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return deleted, firestoreError("Failed to purge deleted journal", err)
+		}
+		deleted = append(deleted, journal)
+	}
+	return deleted, nil
+}
+
+// CountJournals counts the user's journals outside the trash, stopping at limit. Only the DeletedAt
+// field of each journal is read.
+func (jr *FirestoreJournalRepository) CountJournals(ctx context.Context, userEmail string, limit int) (int, error) {
+	userDocRef, err := jr.journals(ctx, userEmail)
+	if err != nil {
+		return 0, firestoreError("Failed to count journals", err)
+	}
+	query := userDocRef.Select("DeletedAt")
+	return countDocuments(ctx, query, limit, "Failed to count journals", func(doc *firestore.DocumentSnapshot) bool {
+		deletedAt, err := doc.DataAt("DeletedAt")
+		return err != nil || deletedAt == nil
+	})
+}
+
+// SaveJournals creates or replaces several journals with a BulkWriter. Journals without a JournalID
+// are created with a generated one; those that could not be created get an error and an empty JournalID.
+func (jr *FirestoreJournalRepository) SaveJournals(ctx context.Context, journals []*models.Journal) []error {
+	errs := make([]error, len(journals))
+	if len(journals) == 0 {
+		return errs
+	}
+
+	// Resolve each user's journals subcollection once; an import has a single owner.
+	collections := make(map[string]*firestore.CollectionRef)
+	bulkWriter := jr.Client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, len(journals))
+	created := make([]bool, len(journals))
+	for i, journal := range journals {
+		userJournals, ok := collections[journal.Email]
+		if !ok {
+			userJournals, errs[i] = jr.journals(ctx, journal.Email)
+			if errs[i] != nil {
+				continue
+			}
+			collections[journal.Email] = userJournals
+		}
+		if journal.JournalID == "" {
+			// Generate the ID up front, so the document is written once with its JournalID.
+			docRef := userJournals.NewDoc()
+			journal.JournalID = docRef.ID
+			created[i] = true
+			jobs[i], errs[i] = bulkWriter.Create(docRef, journal)
+		} else {
+			jobs[i], errs[i] = bulkWriter.Set(userJournals.Doc(journal.JournalID), journal)
+		}
+	}
+	bulkWriter.End()
+
+	for i, job := range jobs {
+		if job != nil {
+			_, errs[i] = job.Results()
+		}
+		if errs[i] != nil {
+			errs[i] = firestoreError("Failed to save journal", errs[i])
+			if created[i] {
+				journals[i].JournalID = ""
+			}
+		}
+	}
+	return errs
+}
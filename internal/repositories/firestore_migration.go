@@ -0,0 +1,79 @@
+/**
+ *  Firestore migration helpers rewrite documents that are keyed by, or refer to, a user's email,
+ *  so that the email address of an account can be changed.
+ *
+ *  @file       firestore_migration.go
+ *  @package    repositories
+ *
+ *  @methods
+ *  - rewriteDocuments(ctx, client, query, rewrite) - Moves or updates every document matching a query in batches.
+ *
+ *  @behaviors
+ *  - Writes are committed in batches of at most maxBatchWrites, below Firestore's limit of 500 per batch.
+ *  - A document whose new reference differs from its current one is written to the new reference
+ *    and deleted at the old one in the same batch.
+ *  - An interrupted migration can be run again, since rewritten documents no longer match the query.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// maxBatchWrites is the number of writes committed per batch, leaving headroom below Firestore's limit.
+const maxBatchWrites = 400
+
+// rewriteFunc updates the data of a document in place and returns the reference it should be stored at.
+type rewriteFunc func(doc *firestore.DocumentSnapshot, data map[string]interface{}) *firestore.DocumentRef
+
+// rewriteDocuments applies rewrite to every document matching query and stores the result,
+// deleting the original document if rewrite moved it to another reference.
+func rewriteDocuments(ctx context.Context, client *firestore.Client, query firestore.Query, rewrite rewriteFunc) error {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	batch := client.Batch()
+	writes := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		data := doc.Data()
+		target := rewrite(doc, data)
+		batch.Set(target, data)
+		writes++
+		if target.Path != doc.Ref.Path {
+			batch.Delete(doc.Ref)
+			writes++
+		}
+
+		if writes >= maxBatchWrites {
+			if _, err := batch.Commit(ctx); err != nil {
+				return err
+			}
+			batch = client.Batch()
+			writes = 0
+		}
+	}
+
+	if writes == 0 {
+		return nil
+	}
+	_, err := batch.Commit(ctx)
+	return err
+}
@@ -0,0 +1,143 @@
+/**
+ *  FirestoreNotificationRepository implements the NotificationRepository interface, storing
+ *  each user's notifications in the `notifications` subcollection of their user document.
+ *
+ *  @struct   FirestoreNotificationRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewFirestoreNotificationRepository(client)            - Creates a new FirestoreNotificationRepository instance.
+ *  - CreateNotification(ctx, notification)                 - Adds a notification to the user's collection.
+ *  - ListNotifications(ctx, userEmail, unreadOnly, limit)  - Retrieves a user's notifications, newest first.
+ *  - MarkRead(ctx, userEmail, notificationID)              - Marks a single notification as read.
+ *  - MarkAllRead(ctx, userEmail)                           - Marks all unread notifications as read in batches.
+ *
+ *  @behaviors
+ *  - Notifications are stored at users/{email}/notifications/{id}, so they move with the user on an email change.
+ *  - Listing unread notifications needs a composite index on Read and CreatedAt (descending).
+ *
+ *  @dependencies
+ *  - cloud.google.com/go/firestore: Provides the Firestore client for database operations.
+ *  - google.golang.org/api/iterator: Handles Firestore document iteration.
+ *  - models.Notification: Defines the structure of a notification.
+ *
+ *  @file      firestore_notification_repository.go
+ *  @project   DailyVerse
+ *  @framework Go with Firestore integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"proh2052-group6/pkg/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FirestoreNotificationRepository provides Firestore-based implementation of NotificationRepository.
+type FirestoreNotificationRepository struct {
+	Client *firestore.Client // Firestore client for database operations.
+}
+
+// NewFirestoreNotificationRepository initializes a new FirestoreNotificationRepository instance.
+func NewFirestoreNotificationRepository(client *firestore.Client) NotificationRepository {
+	return &FirestoreNotificationRepository{Client: client}
+}
+
+// notifications returns the notifications subcollection of a user.
+func (nr *FirestoreNotificationRepository) notifications(ctx context.Context, userEmail string) (*firestore.CollectionRef, error) {
+	user, err := userDoc(ctx, nr.Client, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	return user.Collection("notifications"), nil
+}
+
+// CreateNotification adds a notification to the user's collection and sets its ID.
+func (nr *FirestoreNotificationRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	notifications, err := nr.notifications(ctx, notification.Email)
+	if err != nil {
+		return firestoreError("Failed to create notification", err)
+	}
+	docRef := notifications.NewDoc()
+	notification.ID = docRef.ID
+	if _, err := docRef.Create(ctx, notification); err != nil {
+		return firestoreError("Failed to create notification", err)
+	}
+	return nil
+}
+
+// ListNotifications retrieves up to limit of a user's notifications, newest first.
+func (nr *FirestoreNotificationRepository) ListNotifications(ctx context.Context, userEmail string, unreadOnly bool, limit int) ([]models.Notification, error) {
+	collection, err := nr.notifications(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve notifications", err)
+	}
+	query := collection.Query
+	if unreadOnly {
+		query = query.Where("Read", "==", false)
+	}
+	iter := query.OrderBy("CreatedAt", firestore.Desc).Limit(limit).Documents(ctx)
+	defer iter.Stop()
+
+	notifications := []models.Notification{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to retrieve notifications", err)
+		}
+		var notification models.Notification
+		if err := doc.DataTo(&notification); err != nil {
+			return nil, fmt.Errorf("Failed to parse notification data: %v", err)
+		}
+		notification.ID = doc.Ref.ID
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+// MarkRead marks a single notification as read.
+func (nr *FirestoreNotificationRepository) MarkRead(ctx context.Context, userEmail, notificationID string) error {
+	notifications, err := nr.notifications(ctx, userEmail)
+	if err != nil {
+		return firestoreError("Failed to mark notification as read", err)
+	}
+	_, err = notifications.Doc(notificationID).Update(ctx, []firestore.Update{{Path: "Read", Value: true}})
+	if status.Code(err) == codes.NotFound {
+		return ErrNotificationNotFound
+	}
+	if err != nil {
+		return firestoreError("Failed to mark notification as read", err)
+	}
+	return nil
+}
+
+// MarkAllRead marks all unread notifications of a user as read.
+func (nr *FirestoreNotificationRepository) MarkAllRead(ctx context.Context, userEmail string) error {
+	notifications, err := nr.notifications(ctx, userEmail)
+	if err != nil {
+		return firestoreError("Failed to mark notifications as read", err)
+	}
+	unread := notifications.Where("Read", "==", false)
+	err = rewriteDocuments(ctx, nr.Client, unread, func(doc *firestore.DocumentSnapshot, data map[string]interface{}) *firestore.DocumentRef {
+		data["Read"] = true
+		return doc.Ref
+	})
+	if err != nil {
+		return firestoreError("Failed to mark notifications as read", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/**
+ *  FirestorePendingDeliveryRepository implements the PendingDeliveryRepository interface, storing
+ *  the emails held back during quiet hours in the `pendingDeliveries` collection.
+ *
+ *  @struct   FirestorePendingDeliveryRepository
+ *  @inherits PendingDeliveryRepository
+ *
+ *  @methods
+ *  - NewFirestorePendingDeliveryRepository(client) - Creates a new FirestorePendingDeliveryRepository instance.
+ *  - CreatePendingDelivery(ctx, delivery)          - Adds a held back email with a generated ID.
+ *  - GetDuePendingDeliveries(ctx, now, limit)      - Retrieves the emails due for delivery, oldest first.
+ *  - DeletePendingDelivery(ctx, deliveryID)        - Deletes an email once it has been delivered.
+ *
+ *  @behaviors
+ *  - The collection is top-level rather than per user, so the due emails of all users are found with
+ *    one query on DeliverAt, which needs no composite index.
+ *
+ *  @dependencies
+ *  - cloud.google.com/go/firestore: Provides the Firestore client for database operations.
+ *  - google.golang.org/api/iterator: Handles Firestore document iteration.
+ *  - models.PendingDelivery: Defines the structure of a held back email.
+ *
+ *  @file      firestore_pending_delivery_repository.go
+ *  @project   DailyVerse
+ *  @framework Go with Firestore integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"proh2052-group6/pkg/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// FirestorePendingDeliveryRepository provides Firestore-based implementation of PendingDeliveryRepository.
+type FirestorePendingDeliveryRepository struct {
+	Client *firestore.Client // Firestore client for database operations.
+}
+
+// NewFirestorePendingDeliveryRepository initializes a new FirestorePendingDeliveryRepository instance.
+func NewFirestorePendingDeliveryRepository(client *firestore.Client) PendingDeliveryRepository {
+	return &FirestorePendingDeliveryRepository{Client: client}
+}
+
+// CreatePendingDelivery adds a held back email to the collection and sets its ID.
+func (pr *FirestorePendingDeliveryRepository) CreatePendingDelivery(ctx context.Context, delivery *models.PendingDelivery) error {
+	docRef := pr.Client.Collection("pendingDeliveries").NewDoc()
+	delivery.ID = docRef.ID
+	if _, err := docRef.Create(ctx, delivery); err != nil {
+		return firestoreError("Failed to store pending delivery", err)
+	}
+	return nil
+}
+
+// GetDuePendingDeliveries retrieves up to limit emails whose DeliverAt is not after now, oldest first.
+func (pr *FirestorePendingDeliveryRepository) GetDuePendingDeliveries(ctx context.Context, now time.Time, limit int) ([]models.PendingDelivery, error) {
+	iter := pr.Client.Collection("pendingDeliveries").
+		Where("DeliverAt", "<=", now).
+		OrderBy("DeliverAt", firestore.Asc).
+		Limit(limit).
+		Documents(ctx)
+	defer iter.Stop()
+
+	deliveries := []models.PendingDelivery{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to retrieve pending deliveries", err)
+		}
+		var delivery models.PendingDelivery
+		if err := doc.DataTo(&delivery); err != nil {
+			return nil, fmt.Errorf("Failed to parse pending delivery data: %v", err)
+		}
+		delivery.ID = doc.Ref.ID
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// DeletePendingDelivery deletes an email. Deleting an email that does not exist succeeds.
+func (pr *FirestorePendingDeliveryRepository) DeletePendingDelivery(ctx context.Context, deliveryID string) error {
+	if _, err := pr.Client.Collection("pendingDeliveries").Doc(deliveryID).Delete(ctx); err != nil {
+		return firestoreError("Failed to delete pending delivery", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+/**
+ *  FirestorePromptRepository implements the PromptRepository interface, storing each user's journal
+ *  prompt offset in a document of the `settings` subcollection of their user document.
+ *
+ *  @struct   FirestorePromptRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewFirestorePromptRepository(client)       - Creates a new FirestorePromptRepository instance.
+ *  - GetPromptOffset(ctx, userEmail)            - Retrieves a user's prompt offset, 0 if none is stored.
+ *  - IncrementPromptOffset(ctx, userEmail, now) - Adds one to a user's prompt offset in a transaction.
+ *
+ *  @behaviors
+ *  - The offset is stored at users/{email}/settings/journalPrompt, so it moves with the user on an
+ *    email change, and is created by the first skip.
+ *
+ *  @dependencies
+ *  - cloud.google.com/go/firestore: Provides the Firestore client for database operations.
+ *  - models.PromptOffset: Defines the structure of a user's prompt offset.
+ *
+ *  @file      firestore_prompt_repository.go
+ *  @project   DailyVerse
+ *  @framework Go with Firestore integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"proh2052-group6/pkg/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FirestorePromptRepository provides Firestore-based implementation of PromptRepository.
+type FirestorePromptRepository struct {
+	Client *firestore.Client // Firestore client for database operations.
+}
+
+// NewFirestorePromptRepository initializes a new FirestorePromptRepository instance.
+func NewFirestorePromptRepository(client *firestore.Client) PromptRepository {
+	return &FirestorePromptRepository{Client: client}
+}
+
+// promptOffset returns the document of a user's prompt offset.
+func (pr *FirestorePromptRepository) promptOffset(ctx context.Context, userEmail string) (*firestore.DocumentRef, error) {
+	user, err := userDoc(ctx, pr.Client, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	return user.Collection("settings").Doc("journalPrompt"), nil
+}
+
+// GetPromptOffset retrieves a user's prompt offset, or an offset of 0 if the user never skipped a prompt.
+func (pr *FirestorePromptRepository) GetPromptOffset(ctx context.Context, userEmail string) (*models.PromptOffset, error) {
+	docRef, err := pr.promptOffset(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve prompt offset", err)
+	}
+	doc, err := docRef.Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &models.PromptOffset{Email: userEmail}, nil
+	}
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve prompt offset", err)
+	}
+	var offset models.PromptOffset
+	if err := doc.DataTo(&offset); err != nil {
+		return nil, fmt.Errorf("Failed to parse prompt offset: %v", err)
+	}
+	offset.Email = userEmail
+	return &offset, nil
+}
+
+// IncrementPromptOffset adds one to a user's prompt offset in a transaction and returns the new offset.
+func (pr *FirestorePromptRepository) IncrementPromptOffset(ctx context.Context, userEmail string, now time.Time) (*models.PromptOffset, error) {
+	docRef, err := pr.promptOffset(ctx, userEmail)
+	if err != nil {
+		return nil, firestoreError("Failed to update prompt offset", err)
+	}
+	offset := &models.PromptOffset{Email: userEmail}
+	err = pr.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		offset.Offset = 0
+		doc, err := tx.Get(docRef)
+		if err == nil {
+			if err := doc.DataTo(offset); err != nil {
+				return fmt.Errorf("Failed to parse prompt offset: %v", err)
+			}
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		offset.Offset++
+		offset.UpdatedAt = now
+		return tx.Set(docRef, offset)
+	})
+	if err != nil {
+		return nil, firestoreError("Failed to update prompt offset", err)
+	}
+	offset.Email = userEmail
+	return offset, nil
+}
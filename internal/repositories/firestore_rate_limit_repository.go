@@ -0,0 +1,98 @@
+/**
+ *  FirestoreRateLimitRepository implements the RateLimitRepository interface, storing token buckets
+ *  in the `rateLimits` collection.
+ *
+ *  @struct   FirestoreRateLimitRepository
+ *  @inherits RateLimitRepository
+ *
+ *  @methods
+ *  - NewFirestoreRateLimitRepository(client) - Creates a new FirestoreRateLimitRepository instance.
+ *  - GetRateLimitBucket(ctx, key)            - Retrieves the bucket of a limiter's client.
+ *  - SaveRateLimitBuckets(ctx, buckets)      - Stores several buckets with a BulkWriter.
+ *
+ *  @behaviors
+ *  - Documents are keyed by the SHA-256 hash of the bucket key, since keys contain client IPs and
+ *    emails, which may hold characters document IDs cannot. The key itself is stored in the document.
+ *  - Buckets are not deleted here: a Firestore TTL policy on FullAt removes them once they have
+ *    refilled, and a bucket read after FullAt is full anyway.
+ *
+ *  @dependencies
+ *  - cloud.google.com/go/firestore: Provides the Firestore client for database operations.
+ *  - models.RateLimitBucket: Defines the structure of a token bucket.
+ *
+ *  @file      firestore_rate_limit_repository.go
+ *  @project   DailyVerse
+ *  @framework Go with Firestore integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"proh2052-group6/pkg/models"
+
+	"cloud.google.com/go/firestore"
+)
+
+// FirestoreRateLimitRepository provides Firestore-based implementation of RateLimitRepository.
+type FirestoreRateLimitRepository struct {
+	Client *firestore.Client // Firestore client for database operations.
+}
+
+// NewFirestoreRateLimitRepository initializes a new FirestoreRateLimitRepository instance.
+func NewFirestoreRateLimitRepository(client *firestore.Client) RateLimitRepository {
+	return &FirestoreRateLimitRepository{Client: client}
+}
+
+// bucket returns the document of the bucket stored under key.
+func (rr *FirestoreRateLimitRepository) bucket(key string) *firestore.DocumentRef {
+	hash := sha256.Sum256([]byte(key))
+	return rr.Client.Collection("rateLimits").Doc(hex.EncodeToString(hash[:]))
+}
+
+// GetRateLimitBucket retrieves the bucket stored under key.
+func (rr *FirestoreRateLimitRepository) GetRateLimitBucket(ctx context.Context, key string) (*models.RateLimitBucket, error) {
+	doc, err := rr.bucket(key).Get(ctx)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve rate limit bucket", err)
+	}
+	var bucket models.RateLimitBucket
+	if err := doc.DataTo(&bucket); err != nil {
+		return nil, fmt.Errorf("Failed to parse rate limit bucket data: %v", err)
+	}
+	return &bucket, nil
+}
+
+// SaveRateLimitBuckets stores buckets under their keys with a BulkWriter, replacing the buckets stored before.
+func (rr *FirestoreRateLimitRepository) SaveRateLimitBuckets(ctx context.Context, buckets []models.RateLimitBucket) error {
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	bulkWriter := rr.Client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, len(buckets))
+	errs := make([]error, len(buckets))
+	for i, bucket := range buckets {
+		jobs[i], errs[i] = bulkWriter.Set(rr.bucket(bucket.Key), bucket)
+	}
+	bulkWriter.End()
+
+	for i, job := range jobs {
+		if job != nil {
+			_, errs[i] = job.Results()
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return firestoreError("Failed to save rate limit buckets", err)
+	}
+	return nil
+}
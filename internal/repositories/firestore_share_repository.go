@@ -0,0 +1,138 @@
+/**
+ *  FirestoreShareRepository implements the ShareRepository interface, storing shared event links in
+ *  the `eventShares` collection.
+ *
+ *  @struct   FirestoreShareRepository
+ *  @inherits ShareRepository
+ *
+ *  @methods
+ *  - NewFirestoreShareRepository(client)     - Creates a new FirestoreShareRepository instance.
+ *  - CreateEventShare(ctx, token, share)     - Stores a link under its token.
+ *  - GetEventShare(ctx, token)               - Retrieves the link with a token.
+ *  - DeleteEventShares(ctx, email, eventID)  - Deletes every link to an event.
+ *  - MigrateShareEmail(ctx, oldEmail, newEmail) - Rewrites the owner of links after an email change.
+ *
+ *  @behaviors
+ *  - Documents are keyed by the SHA-256 hash of the token and the token itself is not stored, so
+ *    reading the database does not reveal working links.
+ *  - Expired links are not deleted here: a Firestore TTL policy on ExpiresAt removes them, and the
+ *    service rejects them until then.
+ *
+ *  @dependencies
+ *  - cloud.google.com/go/firestore: Provides the Firestore client for database operations.
+ *  - google.golang.org/api/iterator: Handles Firestore document iteration.
+ *  - models.EventShare: Defines the structure of a link.
+ *
+ *  @file      firestore_share_repository.go
+ *  @project   DailyVerse
+ *  @framework Go with Firestore integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"proh2052-group6/pkg/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// FirestoreShareRepository provides Firestore-based implementation of ShareRepository.
+type FirestoreShareRepository struct {
+	Client *firestore.Client // Firestore client for database operations.
+}
+
+// NewFirestoreShareRepository initializes a new FirestoreShareRepository instance.
+func NewFirestoreShareRepository(client *firestore.Client) ShareRepository {
+	return &FirestoreShareRepository{Client: client}
+}
+
+// share returns the document of the link with token.
+func (sr *FirestoreShareRepository) share(token string) *firestore.DocumentRef {
+	hash := sha256.Sum256([]byte(token))
+	return sr.Client.Collection("eventShares").Doc(hex.EncodeToString(hash[:]))
+}
+
+// CreateEventShare stores share as the link with token.
+func (sr *FirestoreShareRepository) CreateEventShare(ctx context.Context, token string, share *models.EventShare) error {
+	if _, err := sr.share(token).Create(ctx, share); err != nil {
+		return firestoreError("Failed to create event share", err)
+	}
+	return nil
+}
+
+// GetEventShare retrieves the link with token.
+func (sr *FirestoreShareRepository) GetEventShare(ctx context.Context, token string) (*models.EventShare, error) {
+	doc, err := sr.share(token).Get(ctx)
+	if err != nil {
+		return nil, firestoreError("Failed to retrieve event share", err)
+	}
+	var share models.EventShare
+	if err := doc.DataTo(&share); err != nil {
+		return nil, fmt.Errorf("Failed to parse event share data: %v", err)
+	}
+	return &share, nil
+}
+
+// DeleteEventShares deletes every link to the event eventID of email with a BulkWriter.
+func (sr *FirestoreShareRepository) DeleteEventShares(ctx context.Context, email, eventID string) error {
+	iter := sr.Client.Collection("eventShares").Where("Email", "==", email).Where("EventID", "==", eventID).Documents(ctx)
+	defer iter.Stop()
+
+	var refs []*firestore.DocumentRef
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return firestoreError("Failed to retrieve event shares", err)
+		}
+		refs = append(refs, doc.Ref)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	bulkWriter := sr.Client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, len(refs))
+	errs := make([]error, len(refs))
+	for i, ref := range refs {
+		jobs[i], errs[i] = bulkWriter.Delete(ref)
+	}
+	bulkWriter.End()
+
+	for i, job := range jobs {
+		if job != nil {
+			_, errs[i] = job.Results()
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return firestoreError("Failed to delete event shares", err)
+	}
+	return nil
+}
+
+// MigrateShareEmail replaces oldEmail with newEmail as the owner of every link. The links keep
+// their IDs, which are derived from the token alone.
+func (sr *FirestoreShareRepository) MigrateShareEmail(ctx context.Context, oldEmail, newEmail string) error {
+	shares := sr.Client.Collection("eventShares")
+	err := rewriteDocuments(ctx, sr.Client, shares.Where("Email", "==", oldEmail), func(doc *firestore.DocumentSnapshot, data map[string]interface{}) *firestore.DocumentRef {
+		data["Email"] = newEmail
+		return doc.Ref
+	})
+	if err != nil {
+		return firestoreError("Failed to migrate event shares", err)
+	}
+	return nil
+}
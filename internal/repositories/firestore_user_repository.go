@@ -9,13 +9,37 @@
  *  - NewFirestoreUserRepository(client)    - Initializes a new FirestoreUserRepository with a Firestore client.
  *  - GetUserByEmail(ctx, email)            - Fetches a user by their email address.
  *  - GetUserByUsername(ctx, username)      - Fetches a user by their username.
+ *  - GetUserByFeedToken(ctx, token)        - Fetches the user whose calendar feed has the token.
  *  - CreateUser(ctx, user)                 - Creates a new user in Firestore.
  *  - UpdateUser(ctx, email, updates)       - Updates a user's details in Firestore.
- *  - SearchUsersByUsername(ctx, query)     - Searches users by a username substring query.
+ *  - IncrementUserField(ctx, email, field) - Adds one to a counter of a user in a transaction.
+ *  - ReplaceUser(ctx, user)                - Overwrites an existing user document.
+ *  - SearchUsers(ctx, query, limit)        - Searches users by username, first name or last name prefix.
+ *  - MigrateUserEmail(ctx, oldEmail, newEmail) - Moves a user document and its subcollections to a new email.
+ *  - GetDigestSubscribers(ctx)             - Fetches the users who enabled the weekly digest.
+ *  - GetJournalReminderSubscribers(ctx)    - Fetches the users who enabled the daily journal reminder.
+ *  - GetUnnormalizedUserEmails(ctx)        - Fetches the emails of users stored under a mixed-case or padded email.
  *
  *  @behaviors
- *  - Uses Firestore's document-based structure to store and query user data under `users/{email}`.
- *  - Supports case-insensitive username search with prefix matching using Firestore queries.
+ *  - Uses Firestore's document-based structure to store and query user data under `users/{email}`,
+ *    with the email escaped by EncodeID. Users stored under their raw email before are still found there.
+ *  - Supports case-insensitive prefix search on UsernameLower, FirstNameLower and LastNameLower,
+ *    with one range query per field whose results are merged. Users stored before FirstNameLower and
+ *    LastNameLower existed are found by username only, until their names are updated.
+ *  - Calendar feeds are looked up with an equality query on FeedToken, served by Firestore's automatic
+ *    single-field index; FeedToken must not be exempted from indexing.
+ *  - Changing a user's email re-keys `users/{email}` and its `events` and `journals` subcollections;
+ *    the new user document is created in a transaction so an existing account is never overwritten.
+ *    The copy is marked with MovedFrom until the old document is deleted, so a move that failed
+ *    halfway can be run again: it overwrites its own copy, moves the remaining subcollections and
+ *    clears the mark, while an account registered under the new email is still never overwritten.
+ *  - Firestore cannot query for emails that are not normalized, so GetUnnormalizedUserEmails reads the
+ *    Email field of every user. It is meant for one-off migrations, not for requests.
+ *  - UpdateUser updates existing users only; it returns "user not found" instead of creating a document.
+ *    ReplaceUser likewise checks in a transaction that the document exists before overwriting it.
+ *  - IncrementUserField reads and writes the counter in a transaction, so concurrent failed logins and
+ *    OTP attempts each see their own count.
+ *  - Missing users are reported as ErrNotFound and unreachable databases as ErrUnavailable.
  *  - Handles error scenarios and returns meaningful messages for failed operations.
  *
  *  @dependencies
@@ -53,10 +77,13 @@ import (
 	"context"
 	"fmt"
 	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
 	"strings"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // FirestoreUserRepository implements the UserRepository interface for Firestore.
@@ -71,9 +98,9 @@ func NewFirestoreUserRepository(client *firestore.Client) UserRepository {
 
 // GetUserByEmail retrieves a user by their email address.
 func (ur *FirestoreUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	doc, err := ur.Client.Collection("users").Doc(email).Get(ctx)
+	doc, err := getDoc(ctx, ur.Client.Collection("users"), email)
 	if err != nil {
-		return nil, err
+		return nil, firestoreError("Failed to get user", err)
 	}
 	var user models.User
 	if err := doc.DataTo(&user); err != nil {
@@ -89,11 +116,34 @@ func (ur *FirestoreUserRepository) GetUserByUsername(ctx context.Context, userna
 
 	doc, err := iter.Next()
 	if err == iterator.Done {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("user %w", ErrNotFound)
 	}
 	if err != nil {
+		return nil, firestoreError("Failed to get user", err)
+	}
+
+	var user models.User
+	if err := doc.DataTo(&user); err != nil {
 		return nil, err
 	}
+	return &user, nil
+}
+
+// GetUserByFeedToken retrieves the user whose calendar feed has the token.
+func (ur *FirestoreUserRepository) GetUserByFeedToken(ctx context.Context, token string) (*models.User, error) {
+	if token == "" {
+		return nil, fmt.Errorf("user %w", ErrNotFound) // Users without a feed have an empty FeedToken.
+	}
+	iter := ur.Client.Collection("users").Where("FeedToken", "==", token).Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, firestoreError("Failed to get user", err)
+	}
 
 	var user models.User
 	if err := doc.DataTo(&user); err != nil {
@@ -104,22 +154,229 @@ func (ur *FirestoreUserRepository) GetUserByUsername(ctx context.Context, userna
 
 // CreateUser creates a new user in Firestore.
 func (ur *FirestoreUserRepository) CreateUser(ctx context.Context, user *models.User) error {
-	_, err := ur.Client.Collection("users").Doc(user.Email).Set(ctx, user)
-	return err
+	if _, err := ur.Client.Collection("users").Doc(EncodeID(user.Email)).Set(ctx, user); err != nil {
+		return firestoreError("Failed to create user", err)
+	}
+	return nil
+}
+
+// ReplaceUser overwrites an existing user document with user, dropping every field user does not have.
+func (ur *FirestoreUserRepository) ReplaceUser(ctx context.Context, user *models.User) error {
+	ref, err := userDoc(ctx, ur.Client, user.Email)
+	if err != nil {
+		return firestoreError("Failed to replace user", err)
+	}
+	err = ur.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(ref); err != nil {
+			return err
+		}
+		return tx.Set(ref, user)
+	})
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return firestoreError("Failed to replace user", err)
+	}
+	return nil
 }
 
 // UpdateUser updates a user's details in Firestore with the provided key-value pairs.
+// Unlike Set with MergeAll, it fails rather than creating a user that does not exist.
 func (ur *FirestoreUserRepository) UpdateUser(ctx context.Context, email string, updates map[string]interface{}) error {
-	_, err := ur.Client.Collection("users").Doc(email).Set(ctx, updates, firestore.MergeAll)
-	return err
+	if len(updates) == 0 {
+		return nil
+	}
+	ref, err := userDoc(ctx, ur.Client, email)
+	if err != nil {
+		return firestoreError("Failed to update user", err)
+	}
+	_, err = ref.Update(ctx, fieldUpdates(updates))
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return firestoreError("Failed to update user", err)
+	}
+	return nil
+}
+
+// IncrementUserField adds one to the integer field of a user in a transaction and returns the new value.
+func (ur *FirestoreUserRepository) IncrementUserField(ctx context.Context, email, field string) (int, error) {
+	ref, err := userDoc(ctx, ur.Client, email)
+	if err != nil {
+		return 0, firestoreError("Failed to update user", err)
+	}
+	var count int64
+	err = ur.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		count = 0
+		if value, err := doc.DataAt(field); err == nil {
+			if stored, ok := value.(int64); ok {
+				count = stored
+			}
+		}
+		count++
+		return tx.Update(ref, []firestore.Update{{FieldPath: firestore.FieldPath{field}, Value: count}})
+	})
+	if status.Code(err) == codes.NotFound {
+		return 0, fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return 0, firestoreError("Failed to update user", err)
+	}
+	return int(count), nil
+}
+
+// fieldUpdates converts a map of top-level fields to Firestore updates. Keys are used as single
+// field names, so they are never split at dots.
+func fieldUpdates(updates map[string]interface{}) []firestore.Update {
+	fields := make([]firestore.Update, 0, len(updates))
+	for field, value := range updates {
+		fields = append(fields, firestore.Update{FieldPath: firestore.FieldPath{field}, Value: value})
+	}
+	return fields
+}
+
+// userSearchFields are the lowercase fields matched by SearchUsers, in the order their matches are returned.
+var userSearchFields = []string{"UsernameLower", "FirstNameLower", "LastNameLower"}
+
+// SearchUsers searches for users whose username, first name or last name starts with the given query
+// (case-insensitive), returning up to limit matches per field with each user once.
+func (ur *FirestoreUserRepository) SearchUsers(ctx context.Context, query string, limit int) ([]*models.User, error) {
+	prefix := strings.ToLower(query)
+	seen := make(map[string]bool)
+	var users []*models.User
+	for _, field := range userSearchFields {
+		iter := ur.Client.Collection("users").
+			Where(field, ">=", prefix).
+			Where(field, "<=", prefix+"\uf8ff").
+			OrderBy(field, firestore.Asc).
+			Limit(limit).
+			Documents(ctx)
+
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				iter.Stop()
+				return nil, firestoreError("Failed to search users", err)
+			}
+
+			var user models.User
+			if err := doc.DataTo(&user); err != nil || seen[user.Email] {
+				continue
+			}
+			seen[user.Email] = true
+			users = append(users, &user)
+		}
+		iter.Stop()
+	}
+
+	return users, nil
+}
+
+// migratedUserSubcollections are the subcollections moved along with a user document when their email changes.
+var migratedUserSubcollections = []string{"events", "journals", "notifications", "audit", "settings"}
+
+// MigrateUserEmail moves the user document from oldEmail to newEmail, together with its events,
+// journals, notifications, audit log and settings, and sets the Email field of every moved document to newEmail.
+// A move that failed before is resumed.
+func (ur *FirestoreUserRepository) MigrateUserEmail(ctx context.Context, oldEmail, newEmail string) error {
+	oldRef, err := userDoc(ctx, ur.Client, oldEmail)
+	if err != nil {
+		return firestoreError("Failed to move user to new email", err)
+	}
+	newRef := ur.Client.Collection("users").Doc(EncodeID(newEmail))
+
+	// Create fails if the document exists, so a concurrent signup with newEmail is never overwritten.
+	// Only a copy left by an earlier move from oldEmail is replaced.
+	err = ur.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(oldRef)
+		if err != nil {
+			return err
+		}
+		existing, err := tx.Get(newRef)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		data := doc.Data()
+		data["Email"] = newEmail
+		data["MovedFrom"] = oldEmail
+		if err == nil {
+			if movedFrom, _ := existing.Data()["MovedFrom"].(string); movedFrom != oldEmail {
+				return status.Errorf(codes.AlreadyExists, "user %s already exists", newEmail)
+			}
+			return tx.Set(newRef, data)
+		}
+		return tx.Create(newRef, data)
+	})
+	if err != nil {
+		return firestoreError("Failed to move user to new email", err)
+	}
+
+	for _, name := range migratedUserSubcollections {
+		target := newRef.Collection(name)
+		err := rewriteDocuments(ctx, ur.Client, oldRef.Collection(name).Query, func(doc *firestore.DocumentSnapshot, data map[string]interface{}) *firestore.DocumentRef {
+			data["Email"] = newEmail
+			return target.Doc(doc.Ref.ID)
+		})
+		if err != nil {
+			return firestoreError(fmt.Sprintf("Failed to move %s to new email", name), err)
+		}
+	}
+
+	if _, err := oldRef.Delete(ctx); err != nil {
+		return firestoreError("Failed to delete user with old email", err)
+	}
+	if _, err := newRef.Update(ctx, []firestore.Update{{Path: "MovedFrom", Value: firestore.Delete}}); err != nil {
+		return firestoreError("Failed to finish moving user to new email", err)
+	}
+	return nil
+}
+
+// GetDigestSubscribers fetches all users with DigestEnabled set.
+func (ur *FirestoreUserRepository) GetDigestSubscribers(ctx context.Context) ([]*models.User, error) {
+	return ur.usersWith(ctx, "DigestEnabled", "Failed to fetch digest subscribers")
 }
 
-// SearchUsersByUsername searches for users with a username matching the given query (prefix match, case-insensitive).
-func (ur *FirestoreUserRepository) SearchUsersByUsername(ctx context.Context, query string) ([]*models.User, error) {
-	iter := ur.Client.Collection("users").
-		Where("UsernameLower", ">=", strings.ToLower(query)).
-		Where("UsernameLower", "<=", strings.ToLower(query)+"\uf8ff").
-		Documents(ctx)
+// GetJournalReminderSubscribers fetches all users with ReminderEnabled set.
+func (ur *FirestoreUserRepository) GetJournalReminderSubscribers(ctx context.Context) ([]*models.User, error) {
+	return ur.usersWith(ctx, "ReminderEnabled", "Failed to fetch journal reminder subscribers")
+}
+
+// GetUnnormalizedUserEmails fetches the emails of all users whose Email is not in the form
+// utils.NormalizeEmail gives it, reading only the Email field of each user.
+func (ur *FirestoreUserRepository) GetUnnormalizedUserEmails(ctx context.Context) ([]string, error) {
+	iter := ur.Client.Collection("users").Select("Email").Documents(ctx)
+	defer iter.Stop()
+
+	var emails []string
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, firestoreError("Failed to fetch user emails", err)
+		}
+
+		email, _ := doc.Data()["Email"].(string)
+		if email != "" && email != utils.NormalizeEmail(email) {
+			emails = append(emails, email)
+		}
+	}
+	return emails, nil
+}
+
+// usersWith fetches all users with the boolean field set, failing with message.
+func (ur *FirestoreUserRepository) usersWith(ctx context.Context, field, message string) ([]*models.User, error) {
+	iter := ur.Client.Collection("users").Where(field, "==", true).Documents(ctx)
 	defer iter.Stop()
 
 	var users []*models.User
@@ -129,7 +386,7 @@ func (ur *FirestoreUserRepository) SearchUsersByUsername(ctx context.Context, qu
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, firestoreError(message, err)
 		}
 
 		var user models.User
@@ -138,6 +395,5 @@ func (ur *FirestoreUserRepository) SearchUsersByUsername(ctx context.Context, qu
 		}
 		users = append(users, &user)
 	}
-
 	return users, nil
 }
@@ -0,0 +1,53 @@
+/**
+ *  FriendInvitationRepository defines the interface for storing invitations to join DailyVerse,
+ *  sent by a user to the email address of a friend who has no account yet.
+ *
+ *  @interface FriendInvitationRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - CreateFriendInvitation(ctx, invitation)                            - Stores a new invitation.
+ *  - GetFriendInvitation(ctx, inviteeEmail, inviterEmail)               - Retrieves the invitation from inviter to invitee.
+ *  - GetPendingFriendInvitations(ctx, inviteeEmail)                     - Retrieves the invitations to an address not yet consumed.
+ *  - ConsumeFriendInvitation(ctx, inviteeEmail, inviterEmail, consumedAt) - Marks an invitation as consumed.
+ *
+ *  @errors
+ *  - ErrNotFound: Returned, wrapped, by GetFriendInvitation and ConsumeFriendInvitation when there is no such invitation.
+ *
+ *  @dependencies
+ *  - models.FriendInvitation: Defines the structure of an invitation.
+ *  - context.Context: Manages request-scoped values, deadlines, and cancellations.
+ *
+ *  @file      friend_invitation_repository.go
+ *  @project   DailyVerse
+ *  @framework Go Interface for Repository Pattern
+ *  @purpose   Database operations abstraction for invitations to join as a friend.
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"proh2052-group6/pkg/models"
+	"time"
+)
+
+// FriendInvitationRepository defines the interface for friend invitation data operations.
+type FriendInvitationRepository interface {
+	// CreateFriendInvitation stores a new invitation, replacing an earlier one from the same inviter.
+	CreateFriendInvitation(ctx context.Context, invitation *models.FriendInvitation) error
+
+	// GetFriendInvitation retrieves the invitation from inviterEmail to inviteeEmail.
+	GetFriendInvitation(ctx context.Context, inviteeEmail, inviterEmail string) (*models.FriendInvitation, error)
+
+	// GetPendingFriendInvitations retrieves the invitations to inviteeEmail that have not been consumed, oldest first.
+	GetPendingFriendInvitations(ctx context.Context, inviteeEmail string) ([]models.FriendInvitation, error)
+
+	// ConsumeFriendInvitation marks the invitation from inviterEmail to inviteeEmail as consumed at consumedAt.
+	ConsumeFriendInvitation(ctx context.Context, inviteeEmail, inviterEmail string, consumedAt time.Time) error
+}
@@ -10,13 +10,26 @@
  *  - CreateFriendRequest(ctx, friend)                   - Creates a new friend request.
  *  - GetFriendRequest(ctx, senderEmail, recipientEmail) - Retrieves a specific friend request.
  *  - UpdateFriendRequest(ctx, senderEmail, recipientEmail, updates) - Updates fields of an existing friend request.
+ *  - AcceptFriendRequest(ctx, senderEmail, recipientEmail) - Atomically accepts a pending friend request.
  *  - DeleteFriendRequest(ctx, senderEmail, recipientEmail) - Deletes a specific friend request.
  *  - GetFriends(ctx, userEmail)                         - Fetches all friends for a user with the "accepted" status.
  *  - GetPendingFriendRequests(ctx, userEmail)           - Fetches all pending friend requests for a user.
+ *  - GetSentFriendRequests(ctx, userEmail)              - Fetches all pending friend requests sent by a user.
+ *  - GetFriendsOfUsers(ctx, userEmails)                 - Fetches all accepted friendships of any of the given users.
+ *  - CreateBlock(ctx, block)                            - Records that a user has blocked another user.
+ *  - GetBlock(ctx, blockerEmail, blockedEmail)          - Retrieves a specific block, or nil.
+ *  - DeleteBlock(ctx, blockerEmail, blockedEmail)       - Removes a block.
+ *  - GetBlockedUsers(ctx, blockerEmail)                 - Fetches all blocks created by a user.
+ *  - MigrateFriendEmail(ctx, oldEmail, newEmail)        - Rewrites friend requests and blocks after an email change.
+ *  - DeleteStaleFriendRequests(ctx, pendingBefore, declinedBefore) - Deletes expired pending requests and old declined ones.
+ *  - CountFriends(ctx, userEmail, limit)      - Counts a user's accepted friendships, up to a limit.
  *
  *  @behavior
  *  - Provides a contract for repository implementations to ensure consistency.
- *  - Focuses on operations for friend requests and relationships.
+ *  - Focuses on operations for friend requests and relationships, including blocks between users.
+ *  - Declined requests are kept with the "declined" status and are not listed as friends, pending or sent requests.
+ *  - AcceptFriendRequest returns ErrFriendRequestNotPending when the request was removed or already answered,
+ *    so a request cancelled concurrently is never turned into a friendship.
  *
  *  @example
  *  ```
@@ -48,9 +61,14 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"proh2052-group6/pkg/models"
+	"time"
 )
 
+// ErrFriendRequestNotPending is returned by AcceptFriendRequest when the request does not exist or is not pending.
+var ErrFriendRequestNotPending = errors.New("friend request not found or not pending")
+
 // FriendRepository defines the interface for friend-related operations.
 type FriendRepository interface {
 	// CreateFriendRequest creates a new friend request.
@@ -60,8 +78,13 @@ type FriendRepository interface {
 	GetFriendRequest(ctx context.Context, senderEmail, recipientEmail string) (*models.Friend, error)
 
 	// UpdateFriendRequest updates specific fields in an existing friend request.
+	// It returns ErrNotFound if the request does not exist.
 	UpdateFriendRequest(ctx context.Context, senderEmail, recipientEmail string, updates map[string]interface{}) error
 
+	// AcceptFriendRequest marks a pending friend request as accepted, re-reading it in the same transaction.
+	// It returns ErrFriendRequestNotPending if the request no longer exists or is not pending.
+	AcceptFriendRequest(ctx context.Context, senderEmail, recipientEmail string) error
+
 	// DeleteFriendRequest deletes a specific friend request.
 	DeleteFriendRequest(ctx context.Context, senderEmail, recipientEmail string) error
 
@@ -70,4 +93,36 @@ type FriendRepository interface {
 
 	// GetPendingFriendRequests retrieves all pending friend requests for a user.
 	GetPendingFriendRequests(ctx context.Context, userEmail string) ([]models.Friend, error)
+
+	// GetSentFriendRequests retrieves all pending friend requests sent by a user.
+	GetSentFriendRequests(ctx context.Context, userEmail string) ([]models.Friend, error)
+
+	// GetFriendsOfUsers retrieves the accepted friendships involving any of the given users,
+	// each friendship once, in a bounded number of queries.
+	GetFriendsOfUsers(ctx context.Context, userEmails []string) ([]models.Friend, error)
+
+	// CreateBlock records that one user has blocked another.
+	CreateBlock(ctx context.Context, block *models.Block) error
+
+	// GetBlock retrieves the block of blockedEmail by blockerEmail, or nil if there is none.
+	GetBlock(ctx context.Context, blockerEmail, blockedEmail string) (*models.Block, error)
+
+	// DeleteBlock removes the block of blockedEmail by blockerEmail.
+	DeleteBlock(ctx context.Context, blockerEmail, blockedEmail string) error
+
+	// GetBlockedUsers retrieves all blocks created by a user.
+	GetBlockedUsers(ctx context.Context, blockerEmail string) ([]models.Block, error)
+
+	// MigrateFriendEmail replaces oldEmail with newEmail in every friend request and block involving it.
+	// Running it again after a failure finishes the migration.
+	MigrateFriendEmail(ctx context.Context, oldEmail, newEmail string) error
+
+	// DeleteStaleFriendRequests deletes the pending requests created before pendingBefore and the
+	// declined requests declined before declinedBefore, and returns how many were deleted. Requests
+	// without a CreatedAt are never deleted as expired.
+	DeleteStaleFriendRequests(ctx context.Context, pendingBefore, declinedBefore time.Time) (int, error)
+
+	// CountFriends counts the user's accepted friendships, stopping at limit. A limit of 0 counts
+	// every friendship.
+	CountFriends(ctx context.Context, userEmail string, limit int) (int, error)
 }
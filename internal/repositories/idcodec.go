@@ -0,0 +1,166 @@
+/**
+ *  ID codec turns emails into Firestore document IDs. Users are stored at users/{email}, and friend
+ *  requests and blocks under the IDs of both emails joined with "_", so the emails are escaped:
+ *  "john_doe@example.com" would make `<sender>_<recipient>` ambiguous, and an email with "/" in a
+ *  quoted local part would not even be a valid document path.
+ *
+ *  @file       idcodec.go
+ *  @package    repositories
+ *
+ *  @methods
+ *  - EncodeID(key)                           - Escapes a key for use as a document ID or part of one.
+ *  - DecodeID(id)                            - Reverses EncodeID.
+ *  - CompositeID(parts...)                   - Joins escaped keys with "_".
+ *  - SplitCompositeID(id)                    - Splits a composite ID and decodes its parts.
+ *  - getDoc(ctx, collection, parts...)       - Reads a document, falling back to its legacy raw ID.
+ *  - resolveDoc(ctx, collection, parts...)   - Returns the reference a document is stored at.
+ *  - userDoc(ctx, client, email)             - Returns the user document of email, the parent of their subcollections.
+ *
+ *  @behaviors
+ *  - Every byte except ASCII letters, digits and ".@+-" is percent-encoded with upper-case hex, so
+ *    "_" never occurs inside an escaped key and "/" never occurs in an ID.
+ *  - Most emails contain no escaped character and keep the ID they always had. Documents of the others
+ *    were stored under the raw email before escaping was introduced: they are read from the escaped ID
+ *    first and from the legacy raw ID if there is none, and keep being written where they were found.
+ *    New documents are always created under the escaped ID.
+ *  - Keys whose legacy ID was never a valid document path, such as those containing "/", have no fallback.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// compositeIDSeparator joins the escaped parts of a composite ID; EncodeID always escapes it.
+const compositeIDSeparator = "_"
+
+// upperHex are the digits of percent-encoded bytes.
+const upperHex = "0123456789ABCDEF"
+
+// isIDSafe reports whether EncodeID keeps b as it is.
+func isIDSafe(b byte) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' ||
+		b == '.' || b == '@' || b == '+' || b == '-'
+}
+
+// EncodeID escapes key for use as a Firestore document ID, or as one part of a composite ID.
+func EncodeID(key string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(key); i++ {
+		b := key[i]
+		if isIDSafe(b) {
+			encoded.WriteByte(b)
+			continue
+		}
+		encoded.WriteByte('%')
+		encoded.WriteByte(upperHex[b>>4])
+		encoded.WriteByte(upperHex[b&0x0F])
+	}
+	return encoded.String()
+}
+
+// DecodeID reverses EncodeID. It fails for IDs EncodeID cannot have produced.
+func DecodeID(id string) (string, error) {
+	var decoded strings.Builder
+	for i := 0; i < len(id); i++ {
+		b := id[i]
+		if isIDSafe(b) {
+			decoded.WriteByte(b)
+			continue
+		}
+		if b != '%' || i+2 >= len(id) {
+			return "", fmt.Errorf("Invalid document ID %q", id)
+		}
+		high, low := strings.IndexByte(upperHex, id[i+1]), strings.IndexByte(upperHex, id[i+2])
+		if high < 0 || low < 0 {
+			return "", fmt.Errorf("Invalid document ID %q", id)
+		}
+		decoded.WriteByte(byte(high<<4 | low))
+		i += 2
+	}
+	return decoded.String(), nil
+}
+
+// CompositeID returns the ID of a document keyed by several values, such as a friend request by
+// its sender and recipient.
+func CompositeID(parts ...string) string {
+	encoded := make([]string, len(parts))
+	for i, part := range parts {
+		encoded[i] = EncodeID(part)
+	}
+	return strings.Join(encoded, compositeIDSeparator)
+}
+
+// SplitCompositeID returns the values a CompositeID was made of.
+func SplitCompositeID(id string) ([]string, error) {
+	encoded := strings.Split(id, compositeIDSeparator)
+	parts := make([]string, len(encoded))
+	for i, part := range encoded {
+		decoded, err := DecodeID(part)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = decoded
+	}
+	return parts, nil
+}
+
+// legacyID returns the ID the document of parts was stored under before IDs were escaped, and
+// whether it differs from CompositeID and is a valid document ID at all.
+func legacyID(parts ...string) (string, bool) {
+	id := strings.Join(parts, compositeIDSeparator)
+	valid := id != "" && !strings.Contains(id, "/") && id != "." && id != ".." &&
+		!(strings.HasPrefix(id, "__") && strings.HasSuffix(id, "__"))
+	return id, valid && id != CompositeID(parts...)
+}
+
+// getDoc reads the document of parts in collection from its escaped ID, or from its legacy raw ID if
+// only that exists. Missing documents are reported with the NotFound error of the escaped ID.
+func getDoc(ctx context.Context, collection *firestore.CollectionRef, parts ...string) (*firestore.DocumentSnapshot, error) {
+	doc, err := collection.Doc(CompositeID(parts...)).Get(ctx)
+	legacy, hasLegacy := legacyID(parts...)
+	if status.Code(err) != codes.NotFound || !hasLegacy {
+		return doc, err
+	}
+	legacyDoc, legacyErr := collection.Doc(legacy).Get(ctx)
+	if status.Code(legacyErr) == codes.NotFound {
+		return doc, err
+	}
+	return legacyDoc, legacyErr
+}
+
+// resolveDoc returns the reference the document of parts in collection is stored at: its legacy raw
+// ID if only that exists, otherwise its escaped ID.
+func resolveDoc(ctx context.Context, collection *firestore.CollectionRef, parts ...string) (*firestore.DocumentRef, error) {
+	ref := collection.Doc(CompositeID(parts...))
+	if _, hasLegacy := legacyID(parts...); !hasLegacy {
+		return ref, nil
+	}
+	doc, err := getDoc(ctx, collection, parts...)
+	if status.Code(err) == codes.NotFound {
+		return ref, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.Ref, nil
+}
+
+// userDoc returns the document of the user with email. Their events, journals and other
+// subcollections live under it, including for users created before IDs were escaped.
+func userDoc(ctx context.Context, client *firestore.Client, email string) (*firestore.DocumentRef, error) {
+	return resolveDoc(ctx, client.Collection("users"), email)
+}
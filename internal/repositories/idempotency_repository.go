@@ -0,0 +1,72 @@
+/**
+ *  IdempotencyRepository defines the interface for storing the Idempotency-Key records that make
+ *  retried event creations return the original event instead of creating a duplicate.
+ *
+ *  @interface IdempotencyRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - CreateRecord(ctx, record)          - Stores a record unless the user has a live record for the key.
+ *  - GetRecord(ctx, userEmail, key)     - Retrieves the record of a user's key.
+ *  - CompleteRecord(ctx, record)        - Stores the outcome of the request a record was created for.
+ *  - DeleteRecord(ctx, userEmail, key)  - Removes a record so the key can be used again.
+ *  - IdempotencyRecordReplaceable(existing, now) - Reports whether CreateRecord may replace a record.
+ *
+ *  @dependencies
+ *  - models.IdempotencyRecord: Defines the structure of an idempotency record.
+ *  - context.Context: Manages request-scoped values, deadlines, and cancellations.
+ *
+ *  @file      idempotency_repository.go
+ *  @project   DailyVerse
+ *  @framework Go Interface for Repository Pattern
+ *  @purpose   Database operations abstraction for idempotency keys.
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"proh2052-group6/pkg/models"
+	"time"
+)
+
+var (
+	// ErrIdempotencyKeyExists is returned by CreateRecord when the user already has a live record for the key.
+	ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+	// ErrIdempotencyRecordNotFound is returned by GetRecord when the user has no record for the key.
+	ErrIdempotencyRecordNotFound = fmt.Errorf("idempotency record %w", ErrNotFound)
+)
+
+// IdempotencyRecordReplaceable reports whether CreateRecord may replace existing with a record created at now:
+// existing has expired, or its lease expired before an event was created for it.
+func IdempotencyRecordReplaceable(existing *models.IdempotencyRecord, now time.Time) bool {
+	if !existing.ExpiresAt.After(now) {
+		return true
+	}
+	return existing.EventID == "" && !existing.LeaseExpiresAt.After(now)
+}
+
+// IdempotencyRepository defines the interface for idempotency key data operations.
+type IdempotencyRepository interface {
+	// CreateRecord stores record for record.Email and record.Key. The check and the write are atomic,
+	// so of several concurrent requests with the same key only one succeeds; the others get
+	// ErrIdempotencyKeyExists. A record that expired before record.CreatedAt is replaced, and so is a
+	// record without an EventID whose lease expired before then.
+	CreateRecord(ctx context.Context, record *models.IdempotencyRecord) error
+
+	// GetRecord retrieves the record of a user's key. It returns ErrIdempotencyRecordNotFound if there is none.
+	GetRecord(ctx context.Context, userEmail, key string) (*models.IdempotencyRecord, error)
+
+	// CompleteRecord stores the status, event ID and response of record.
+	CompleteRecord(ctx context.Context, record *models.IdempotencyRecord) error
+
+	// DeleteRecord removes the record of a user's key. Deleting a missing record is not an error.
+	DeleteRecord(ctx context.Context, userEmail, key string) error
+}
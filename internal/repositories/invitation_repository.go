@@ -0,0 +1,58 @@
+/**
+ *  InvitationRepository defines the interface for data access operations related to event invitations.
+ *  It abstracts the database layer, allowing the application to manage which friends are invited to
+ *  an event and how they responded, without being tied to a specific database implementation.
+ *
+ *  @interface InvitationRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - CreateInvitation(ctx, invitation)                   - Creates a new invitation in the database.
+ *  - GetInvitation(ctx, eventID, inviteeEmail)           - Retrieves the invitation of a user to an event.
+ *  - UpdateInvitation(ctx, eventID, inviteeEmail, updates) - Updates fields of an existing invitation.
+ *  - GetInvitationsForUser(ctx, inviteeEmail)            - Fetches all invitations received by a user.
+ *  - GetInvitationsForEvent(ctx, ownerEmail, eventID)    - Fetches all invitations sent for an event.
+ *  - MigrateInvitationEmail(ctx, oldEmail, newEmail)     - Rewrites invitations after a user's email change.
+ *
+ *  @dependencies
+ *  - models.EventInvitation: Defines the structure of an invitation object.
+ *  - context.Context: Used for managing request-scoped values, deadlines, and cancellation signals.
+ *
+ *  @file      invitation_repository.go
+ *  @project   DailyVerse
+ *  @framework Go Interface for Repository Pattern
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"proh2052-group6/pkg/models"
+)
+
+// InvitationRepository defines the interface for event invitation data operations.
+type InvitationRepository interface {
+	// CreateInvitation inserts a new invitation into the database.
+	CreateInvitation(ctx context.Context, invitation *models.EventInvitation) error
+
+	// GetInvitation retrieves the invitation of a user to an event. Returns nil if none exists.
+	GetInvitation(ctx context.Context, eventID, inviteeEmail string) (*models.EventInvitation, error)
+
+	// UpdateInvitation updates specific fields in an existing invitation.
+	UpdateInvitation(ctx context.Context, eventID, inviteeEmail string, updates map[string]interface{}) error
+
+	// GetInvitationsForUser fetches all invitations received by a user.
+	GetInvitationsForUser(ctx context.Context, inviteeEmail string) ([]models.EventInvitation, error)
+
+	// GetInvitationsForEvent fetches all invitations sent by ownerEmail for one of their events.
+	GetInvitationsForEvent(ctx context.Context, ownerEmail, eventID string) ([]models.EventInvitation, error)
+
+	// MigrateInvitationEmail replaces oldEmail with newEmail as the owner or invitee of every invitation.
+	// Running it again after a failure finishes the migration.
+	MigrateInvitationEmail(ctx context.Context, oldEmail, newEmail string) error
+}
@@ -9,9 +9,20 @@
  *  @methods
  *  - CreateJournal(ctx, journal)                - Adds a new journal entry to the database.
  *  - GetJournal(ctx, userEmail, journalID)      - Retrieves a specific journal entry by its ID and user email.
+ *  - GetJournalByDate(ctx, userEmail, date)     - Retrieves a user's journal entry for a specific date, or nil.
  *  - UpdateJournal(ctx, journal)                - Updates an existing journal entry in the database.
  *  - DeleteJournal(ctx, userEmail, journalID)   - Deletes a journal entry by its ID and user email.
  *  - GetAllJournals(ctx, userEmail)             - Retrieves all journal entries associated with a specific user.
+ *  - SearchJournals(ctx, userEmail, query, from, to, limit) - Searches a user's journal entries by content and date.
+ *  - StreamJournals(ctx, userEmail, from, to, fn) - Calls fn for each of a user's journal entries in date order.
+ *  - GetDeletedJournals(ctx, userEmail)         - Retrieves a user's journal entries in the trash.
+ *  - PurgeDeletedJournals(ctx, deletedBefore)   - Permanently deletes and returns the entries of all users trashed before a time.
+ *  - CountJournals(ctx, userEmail, limit)       - Counts a user's journal entries outside the trash, up to a limit.
+ *  - SaveJournals(ctx, journals)                - Creates or replaces several journal entries at once, reporting an error per entry.
+ *
+ *  @behaviors
+ *  - Entries in the trash have a DeletedAt time. GetJournal returns them, but the other queries of
+ *    entries leave them out.
  *
  *  @dependencies
  *  - models.Journal: Defines the structure of a journal object.
@@ -33,6 +44,7 @@ package repositories
 import (
 	"context"
 	"proh2052-group6/pkg/models"
+	"time"
 )
 
 // JournalRepository defines the interface for journal-related data operations.
@@ -40,15 +52,46 @@ type JournalRepository interface {
 	// CreateJournal inserts a new journal entry into the database.
 	CreateJournal(ctx context.Context, journal *models.Journal) error
 
-	// GetJournal retrieves a specific journal entry by its ID and associated user email.
+	// GetJournal retrieves a specific journal entry by its ID and associated user email, including
+	// an entry in the trash. It returns ErrNotFound if the user has no such journal.
 	GetJournal(ctx context.Context, userEmail, journalID string) (*models.Journal, error)
 
+	// GetJournalByDate retrieves a user's journal entry for the given date (YYYY-MM-DD).
+	// Returns nil without an error if the user has no journal for that date outside the trash.
+	GetJournalByDate(ctx context.Context, userEmail, date string) (*models.Journal, error)
+
 	// UpdateJournal modifies an existing journal entry in the database.
 	UpdateJournal(ctx context.Context, journal *models.Journal) error
 
-	// DeleteJournal removes a journal entry from the database by its ID and associated user email.
+	// DeleteJournal permanently removes a journal entry from the database by its ID and associated user email.
 	DeleteJournal(ctx context.Context, userEmail, journalID string) error
 
-	// GetAllJournals fetches all journal entries linked to a specific user's email.
+	// GetAllJournals fetches all journal entries linked to a specific user's email, except those in the trash.
 	GetAllJournals(ctx context.Context, userEmail string) ([]models.Journal, error)
+
+	// SearchJournals fetches a user's journal entries whose Content contains query (case-insensitive)
+	// and whose Date lies within [from, to], sorted by date descending. Empty query, from or to
+	// disable that filter, and a limit of 0 returns every match. Entries in the trash are left out.
+	SearchJournals(ctx context.Context, userEmail, query, from, to string, limit int) ([]models.Journal, error)
+
+	// StreamJournals calls fn for each of a user's journal entries whose Date lies within [from, to],
+	// oldest first, without loading them all into memory. Empty from or to disable that bound.
+	// Iteration stops at the first error returned by fn, which is returned. Entries in the trash are left out.
+	StreamJournals(ctx context.Context, userEmail, from, to string, fn func(models.Journal) error) error
+
+	// GetDeletedJournals fetches a user's journal entries in the trash, most recently deleted first.
+	GetDeletedJournals(ctx context.Context, userEmail string) ([]models.Journal, error)
+
+	// PurgeDeletedJournals permanently deletes the journal entries of every user that were moved to
+	// the trash before deletedBefore, and returns the deleted entries, so their attachments can be removed.
+	PurgeDeletedJournals(ctx context.Context, deletedBefore time.Time) ([]models.Journal, error)
+
+	// CountJournals counts the user's journal entries outside the trash, stopping at limit.
+	// A limit of 0 counts every entry.
+	CountJournals(ctx context.Context, userEmail string, limit int) (int, error)
+
+	// SaveJournals stores several journal entries: those without a JournalID are created and assigned
+	// one, the others replace the stored entry with their ID. The writes are not atomic: the result
+	// has an error per entry, which is nil for entries that were stored.
+	SaveJournals(ctx context.Context, journals []*models.Journal) []error
 }
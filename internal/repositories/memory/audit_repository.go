@@ -0,0 +1,89 @@
+/**
+ *  AuditRepository is an in-memory implementation of repositories.AuditRepository, used when
+ *  STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       audit_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Entries (map[string][]models.AuditEntry) - Audit logs keyed by user email.
+ *  - Err (error)                              - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewAuditRepository()                    - Creates an empty AuditRepository.
+ *  - CreateAuditEntry(ctx, entry)            - Stores an audit entry.
+ *  - ListAuditEntries(ctx, userEmail, limit) - Lists a user's latest audit entries, newest first.
+ *  - Actions(userEmail)                      - Returns the recorded actions of a user, oldest first.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, since audit entries may be written from several goroutines.
+ *    Entries is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"proh2052-group6/pkg/models"
+)
+
+// AuditRepository keeps account audit logs in memory.
+type AuditRepository struct {
+	mu      sync.RWMutex
+	Entries map[string][]models.AuditEntry // Audit logs keyed by user email.
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewAuditRepository creates an empty AuditRepository.
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{Entries: make(map[string][]models.AuditEntry)}
+}
+
+// CreateAuditEntry stores an entry in the audit log of entry.Email.
+func (ar *AuditRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	if ar.Err != nil {
+		return ar.Err
+	}
+	ar.Entries[entry.Email] = append(ar.Entries[entry.Email], *entry)
+	return nil
+}
+
+// ListAuditEntries lists up to limit of a user's latest audit entries, newest first.
+func (ar *AuditRepository) ListAuditEntries(ctx context.Context, userEmail string, limit int) ([]models.AuditEntry, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+	if ar.Err != nil {
+		return nil, ar.Err
+	}
+	entries := append([]models.AuditEntry{}, ar.Entries[userEmail]...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// Actions returns the actions in the audit log of userEmail, in the order they were recorded.
+func (ar *AuditRepository) Actions(userEmail string) []string {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+	actions := []string{}
+	for _, entry := range ar.Entries[userEmail] {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
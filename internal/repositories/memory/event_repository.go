@@ -0,0 +1,387 @@
+/**
+ *  EventRepository is an in-memory implementation of repositories.EventRepository, used when
+ *  STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       event_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Events (map[string]*models.Event) - The events of all users keyed by EventID.
+ *  - Err (error)                       - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewEventRepository()                          - Creates an empty EventRepository.
+ *  - CreateEvent(ctx, event)                       - Stores an event and assigns an EventID.
+ *  - GetEvent(ctx, userEmail, eventID)             - Retrieves an event by ID for a user.
+ *  - UpdateEvent(ctx, event)                       - Replaces an event.
+ *  - UpdateEventFields(ctx, userEmail, eventID, updates) - Updates only some fields of an event.
+ *  - DeleteEvent(ctx, userEmail, eventID)          - Deletes an event.
+ *  - GetAllEvents(ctx, userEmail, query)           - Retrieves a page of events for a user.
+ *  - GetEventsBetween(ctx, start, end)             - Retrieves events of all users within a time range.
+ *  - GetEventByExternalID(ctx, userEmail, externalID) - Retrieves a user's event by external ID.
+ *  - GetRecurringEvents(ctx, userEmail)            - Retrieves all of a user's recurring events.
+ *  - GetEvents(ctx, userEmail, eventIDs)           - Retrieves several of a user's events at once.
+ *  - CreateEvents(ctx, events)                     - Stores several events.
+ *  - DeleteEvents(ctx, userEmail, eventIDs)        - Deletes several events.
+ *  - DeleteEventsByBatch(ctx, userEmail, batchID)  - Deletes the events created by a timetable import.
+ *  - GetImportBatches(ctx, userEmail)              - Summarises a user's imported events by batch.
+ *  - CountEvents(ctx, userEmail, limit)            - Counts a user's events up to a limit.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, so the repository can be shared by concurrent requests.
+ *    Events is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *  - Events are stored and returned as copies, so callers cannot change them without UpdateEvent.
+ *  - Like the users/{email}/events subcollection in Firestore, an event belongs to the user in its
+ *    Email field: other users cannot read, update or delete it, and deleting an event the user does
+ *    not have succeeds without deleting anything. EventIDs are unique across users.
+ *  - Event listings are ordered by Date and EventID and paged like Firestore, using the last EventID
+ *    as page token. Tag filters are applied like Firestore's array-contains.
+ *  - Missing events are reported with repositories.ErrNotFound.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// EventRepository keeps the events of all users in memory.
+type EventRepository struct {
+	mu     sync.RWMutex
+	Events map[string]*models.Event // Events keyed by EventID.
+	nextID int                      // Counter used to generate EventIDs.
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewEventRepository creates an empty EventRepository.
+func NewEventRepository() *EventRepository {
+	return &EventRepository{Events: make(map[string]*models.Event)}
+}
+
+// userEvent returns the stored event with eventID if it belongs to userEmail, or nil.
+func (er *EventRepository) userEvent(userEmail, eventID string) *models.Event {
+	if event, exists := er.Events[eventID]; exists && event.Email == userEmail {
+		return event
+	}
+	return nil
+}
+
+// CreateEvent stores a copy of an event, assigning a generated EventID to it.
+func (er *EventRepository) CreateEvent(ctx context.Context, event *models.Event) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	if er.Err != nil {
+		return er.Err
+	}
+	er.nextID++
+	event.EventID = fmt.Sprintf("event%d", er.nextID)
+	stored := *event
+	er.Events[event.EventID] = &stored
+	return nil
+}
+
+// GetEvent retrieves a copy of a user's event by ID.
+func (er *EventRepository) GetEvent(ctx context.Context, userEmail, eventID string) (*models.Event, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	if er.Err != nil {
+		return nil, er.Err
+	}
+	event := er.userEvent(userEmail, eventID)
+	if event == nil {
+		return nil, fmt.Errorf("Event %w", repositories.ErrNotFound)
+	}
+	found := *event
+	return &found, nil
+}
+
+// UpdateEvent replaces an event with a copy of event. Like Firestore's Set, the event is stored
+// even if it did not exist, but an event of another user is never overwritten.
+func (er *EventRepository) UpdateEvent(ctx context.Context, event *models.Event) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	if er.Err != nil {
+		return er.Err
+	}
+	if existing, exists := er.Events[event.EventID]; exists && existing.Email != event.Email {
+		return fmt.Errorf("Event %w", repositories.ErrNotFound)
+	}
+	stored := *event
+	er.Events[event.EventID] = &stored
+	return nil
+}
+
+// UpdateEventFields updates the fields of a user's event, keyed by their Go field names.
+// A nil value clears a field.
+func (er *EventRepository) UpdateEventFields(ctx context.Context, userEmail, eventID string, updates map[string]interface{}) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	if er.Err != nil {
+		return er.Err
+	}
+	event := er.userEvent(userEmail, eventID)
+	if event == nil {
+		return fmt.Errorf("Event %w", repositories.ErrNotFound)
+	}
+	stored := *event
+	if err := setFields(&stored, updates); err != nil {
+		return fmt.Errorf("Failed to update event: %w", err)
+	}
+	er.Events[eventID] = &stored
+	return nil
+}
+
+// DeleteEvent deletes a user's event by ID. Deleting an event the user does not have succeeds.
+func (er *EventRepository) DeleteEvent(ctx context.Context, userEmail, eventID string) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	if er.Err != nil {
+		return er.Err
+	}
+	if er.userEvent(userEmail, eventID) != nil {
+		delete(er.Events, eventID)
+	}
+	return nil
+}
+
+// GetAllEvents retrieves a page of a user's events, ordered by Date and EventID.
+func (er *EventRepository) GetAllEvents(ctx context.Context, userEmail string, query models.EventQuery) (*models.EventPage, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	if er.Err != nil {
+		return nil, er.Err
+	}
+	var events []models.Event
+	for _, event := range er.Events {
+		if event.Email == userEmail {
+			events = append(events, *event)
+		}
+	}
+	return paginateEvents(events, query)
+}
+
+// GetEventsBetween retrieves the events of all users whose StartAt lies within [start, end].
+func (er *EventRepository) GetEventsBetween(ctx context.Context, start, end time.Time) ([]models.Event, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	if er.Err != nil {
+		return nil, er.Err
+	}
+	var events []models.Event
+	for _, event := range er.Events {
+		if !event.StartAt.Before(start) && !event.StartAt.After(end) {
+			events = append(events, *event)
+		}
+	}
+	return events, nil
+}
+
+// GetEventByExternalID retrieves a user's event by its external ID, returning nil if there is none.
+func (er *EventRepository) GetEventByExternalID(ctx context.Context, userEmail, externalID string) (*models.Event, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	if er.Err != nil {
+		return nil, er.Err
+	}
+	for _, event := range er.Events {
+		if event.Email == userEmail && event.ExternalID == externalID {
+			found := *event
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetRecurringEvents retrieves every event of a user that has a recurrence rule.
+func (er *EventRepository) GetRecurringEvents(ctx context.Context, userEmail string) ([]models.Event, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	if er.Err != nil {
+		return nil, er.Err
+	}
+	var events []models.Event
+	for _, event := range er.Events {
+		if event.Email == userEmail && event.Recurrence != nil {
+			events = append(events, *event)
+		}
+	}
+	return events, nil
+}
+
+// paginateEvents filters events by the query's date range and tag and returns the page following its page token.
+func paginateEvents(events []models.Event, query models.EventQuery) (*models.EventPage, error) {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Date != events[j].Date {
+			return events[i].Date < events[j].Date
+		}
+		return events[i].EventID < events[j].EventID
+	})
+
+	var filtered []models.Event
+	for _, event := range events {
+		if (query.From != "" && event.Date < query.From) || (query.To != "" && event.Date > query.To) {
+			continue
+		}
+		if query.Tag != "" && !containsTag(event.Tags, query.Tag) {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+
+	start := 0
+	if query.PageToken != "" {
+		start = -1
+		for i, event := range filtered {
+			if event.EventID == query.PageToken {
+				start = i + 1
+				break
+			}
+		}
+		if start == -1 {
+			return nil, fmt.Errorf("Invalid page token")
+		}
+	}
+
+	page := &models.EventPage{Items: []models.Event{}}
+	end := len(filtered)
+	if query.Limit > 0 && start+query.Limit < end {
+		end = start + query.Limit
+		page.NextPageToken = filtered[end-1].EventID
+	}
+	page.Items = append(page.Items, filtered[start:end]...)
+	return page, nil
+}
+
+// containsTag reports whether tags contains tag.
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEvents retrieves copies of several of a user's events, returning nil for those the user does not have.
+func (er *EventRepository) GetEvents(ctx context.Context, userEmail string, eventIDs []string) ([]*models.Event, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	if er.Err != nil {
+		return nil, er.Err
+	}
+	events := make([]*models.Event, len(eventIDs))
+	for i, eventID := range eventIDs {
+		if event := er.userEvent(userEmail, eventID); event != nil {
+			found := *event
+			events[i] = &found
+		}
+	}
+	return events, nil
+}
+
+// CreateEvents stores several events, returning an error per event.
+func (er *EventRepository) CreateEvents(ctx context.Context, events []*models.Event) []error {
+	errs := make([]error, len(events))
+	for i, event := range events {
+		errs[i] = er.CreateEvent(ctx, event)
+	}
+	return errs
+}
+
+// DeleteEvents deletes several of a user's events, returning an error per event.
+func (er *EventRepository) DeleteEvents(ctx context.Context, userEmail string, eventIDs []string) []error {
+	errs := make([]error, len(eventIDs))
+	for i, eventID := range eventIDs {
+		errs[i] = er.DeleteEvent(ctx, userEmail, eventID)
+	}
+	return errs
+}
+
+// DeleteEventsByBatch deletes every event of a user with the given ImportBatchID.
+func (er *EventRepository) DeleteEventsByBatch(ctx context.Context, userEmail, batchID string) (int, error) {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	if er.Err != nil {
+		return 0, er.Err
+	}
+	deleted := 0
+	for eventID, event := range er.Events {
+		if event.Email == userEmail && event.ImportBatchID == batchID {
+			delete(er.Events, eventID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// GetImportBatches summarises a user's imported events by ImportBatchID, most recent first.
+func (er *EventRepository) GetImportBatches(ctx context.Context, userEmail string) ([]models.ImportBatch, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	if er.Err != nil {
+		return nil, er.Err
+	}
+	counts := make(map[string]*models.ImportBatch)
+	for _, event := range er.Events {
+		if event.Email != userEmail || event.ImportBatchID == "" {
+			continue
+		}
+		if counts[event.ImportBatchID] == nil {
+			counts[event.ImportBatchID] = &models.ImportBatch{BatchID: event.ImportBatchID}
+			if event.ImportedAt != nil {
+				counts[event.ImportBatchID].ImportedAt = *event.ImportedAt
+			}
+		}
+		counts[event.ImportBatchID].EventCount++
+	}
+
+	batches := []models.ImportBatch{}
+	for _, batch := range counts {
+		batches = append(batches, *batch)
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].ImportedAt.After(batches[j].ImportedAt) })
+	return batches, nil
+}
+
+// CountEvents counts a user's events, stopping at limit.
+func (er *EventRepository) CountEvents(ctx context.Context, userEmail string, limit int) (int, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	if er.Err != nil {
+		return 0, er.Err
+	}
+	count := 0
+	for _, event := range er.Events {
+		if event.Email == userEmail {
+			count++
+		}
+	}
+	return capCount(count, limit), nil
+}
+
+// migrateEmail moves the events of oldEmail to newEmail, for UserRepository.MigrateUserEmail.
+func (er *EventRepository) migrateEmail(oldEmail, newEmail string) {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	for eventID, event := range er.Events {
+		if event.Email == oldEmail {
+			moved := *event
+			moved.Email = newEmail
+			er.Events[eventID] = &moved
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/**
+ *  FavoriteRepository is an in-memory implementation of repositories.FavoriteRepository, used when
+ *  STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       favorite_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Favorites (map[string]map[string]models.FavoriteQuote) - Favourites keyed by user email and quote ID.
+ *  - Err (error)                                            - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewFavoriteRepository()        - Creates an empty FavoriteRepository.
+ *  - SaveFavorite(ctx, favorite)    - Stores a favourite under its quote ID.
+ *  - GetFavorites(ctx, userEmail)   - Lists a user's favourites, most recently saved first.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, so the repository can be shared by concurrent requests.
+ *    Favorites is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *  - Favourites are kept per user email and quote ID, like the users/{email}/favorites subcollection,
+ *    so saving a quote again replaces the earlier favourite.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"proh2052-group6/pkg/models"
+)
+
+// FavoriteRepository keeps favourite quotes in memory.
+type FavoriteRepository struct {
+	mu        sync.RWMutex
+	Favorites map[string]map[string]models.FavoriteQuote // Favourites keyed by user email and quote ID.
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewFavoriteRepository creates an empty FavoriteRepository.
+func NewFavoriteRepository() *FavoriteRepository {
+	return &FavoriteRepository{Favorites: make(map[string]map[string]models.FavoriteQuote)}
+}
+
+// SaveFavorite stores a favourite under its quote ID, replacing an earlier favourite of the same quote.
+func (fr *FavoriteRepository) SaveFavorite(ctx context.Context, favorite *models.FavoriteQuote) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return fr.Err
+	}
+	if fr.Favorites[favorite.Email] == nil {
+		fr.Favorites[favorite.Email] = make(map[string]models.FavoriteQuote)
+	}
+	fr.Favorites[favorite.Email][favorite.ID] = *favorite
+	return nil
+}
+
+// GetFavorites lists a user's favourites, most recently saved first.
+func (fr *FavoriteRepository) GetFavorites(ctx context.Context, userEmail string) ([]models.FavoriteQuote, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	if fr.Err != nil {
+		return nil, fr.Err
+	}
+	favorites := []models.FavoriteQuote{}
+	for _, favorite := range fr.Favorites[userEmail] {
+		favorites = append(favorites, favorite)
+	}
+	sort.Slice(favorites, func(i, j int) bool {
+		return favorites[i].SavedAt.After(favorites[j].SavedAt)
+	})
+	return favorites, nil
+}
@@ -0,0 +1,72 @@
+/**
+ *  Helpers shared by the in-memory repositories for applying partial updates and capping counts
+ *  the way the Firestore repositories do.
+ *
+ *  @file       fields.go
+ *  @package    memory
+ *
+ *  @methods
+ *  - setFields(target, updates) - Sets the fields of the struct target points to, keyed by their Go field names.
+ *  - capCount(count, limit)     - Caps a count at a positive limit, like the Firestore count queries.
+ *
+ *  @behaviors
+ *  - Updates are keyed by Go field name, like the field paths the Firestore repositories update.
+ *  - A nil value clears the field, as it does in Firestore. A value for a pointer field is stored
+ *    behind a new pointer, so {"NotificationsEnabled": true} sets a *bool.
+ *  - Unknown fields and values of the wrong type are rejected before anything is changed.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// setFields sets the fields of the struct target points to from updates, keyed by Go field name.
+// Nothing is changed if any update names an unknown field or has a value of the wrong type.
+func setFields(target interface{}, updates map[string]interface{}) error {
+	object := reflect.ValueOf(target).Elem()
+	values := make(map[string]reflect.Value, len(updates))
+	for name, value := range updates {
+		field := object.FieldByName(name)
+		if !field.IsValid() || !field.CanSet() {
+			return fmt.Errorf("Unknown field %s", name)
+		}
+		if value == nil {
+			values[name] = reflect.Zero(field.Type())
+			continue
+		}
+
+		newValue := reflect.ValueOf(value)
+		switch {
+		case newValue.Type().AssignableTo(field.Type()):
+		case field.Kind() == reflect.Ptr && newValue.Type().AssignableTo(field.Type().Elem()):
+			pointer := reflect.New(field.Type().Elem())
+			pointer.Elem().Set(newValue)
+			newValue = pointer
+		default:
+			return fmt.Errorf("Invalid value for field %s: %T", name, value)
+		}
+		values[name] = newValue
+	}
+
+	for name, value := range values {
+		object.FieldByName(name).Set(value)
+	}
+	return nil
+}
+
+// capCount returns count, or limit if it is positive and count exceeds it, like the Firestore count queries.
+func capCount(count, limit int) int {
+	if limit > 0 && count > limit {
+		return limit
+	}
+	return count
+}
@@ -0,0 +1,117 @@
+/**
+ *  FriendInvitationRepository is an in-memory implementation of repositories.FriendInvitationRepository,
+ *  used when STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       friend_invitation_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Invitations (map[string]*models.FriendInvitation) - Invitations keyed by CompositeID(invitee, inviter).
+ *  - Err (error)                                       - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewFriendInvitationRepository()                                      - Creates an empty FriendInvitationRepository.
+ *  - CreateFriendInvitation(ctx, invitation)                              - Stores an invitation.
+ *  - GetFriendInvitation(ctx, inviteeEmail, inviterEmail)                 - Retrieves an invitation.
+ *  - GetPendingFriendInvitations(ctx, inviteeEmail)                       - Retrieves the unconsumed invitations to an address.
+ *  - ConsumeFriendInvitation(ctx, inviteeEmail, inviterEmail, consumedAt) - Marks an invitation as consumed.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, so the repository can be shared by concurrent requests.
+ *    Invitations is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *  - Invitations are stored and returned as copies, like documents read from Firestore.
+ *  - Missing invitations are reported with repositories.ErrNotFound.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// FriendInvitationRepository keeps invitations to join as a friend in memory.
+type FriendInvitationRepository struct {
+	mu          sync.RWMutex
+	Invitations map[string]*models.FriendInvitation // Invitations keyed by CompositeID(invitee, inviter).
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewFriendInvitationRepository creates an empty FriendInvitationRepository.
+func NewFriendInvitationRepository() *FriendInvitationRepository {
+	return &FriendInvitationRepository{Invitations: make(map[string]*models.FriendInvitation)}
+}
+
+// CreateFriendInvitation stores a copy of an invitation, replacing an earlier one from the same inviter.
+func (fr *FriendInvitationRepository) CreateFriendInvitation(ctx context.Context, invitation *models.FriendInvitation) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return fr.Err
+	}
+	stored := *invitation
+	fr.Invitations[repositories.CompositeID(invitation.InviteeEmail, invitation.InviterEmail)] = &stored
+	return nil
+}
+
+// GetFriendInvitation retrieves a copy of the invitation from inviterEmail to inviteeEmail.
+func (fr *FriendInvitationRepository) GetFriendInvitation(ctx context.Context, inviteeEmail, inviterEmail string) (*models.FriendInvitation, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	if fr.Err != nil {
+		return nil, fr.Err
+	}
+	invitation, ok := fr.Invitations[repositories.CompositeID(inviteeEmail, inviterEmail)]
+	if !ok {
+		return nil, fmt.Errorf("friend invitation %w", repositories.ErrNotFound)
+	}
+	copied := *invitation
+	return &copied, nil
+}
+
+// GetPendingFriendInvitations retrieves copies of the unconsumed invitations to inviteeEmail, oldest first.
+func (fr *FriendInvitationRepository) GetPendingFriendInvitations(ctx context.Context, inviteeEmail string) ([]models.FriendInvitation, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	if fr.Err != nil {
+		return nil, fr.Err
+	}
+	invitations := []models.FriendInvitation{}
+	for _, invitation := range fr.Invitations {
+		if invitation.InviteeEmail == inviteeEmail && invitation.ConsumedAt == nil {
+			invitations = append(invitations, *invitation)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.Before(invitations[j].CreatedAt)
+	})
+	return invitations, nil
+}
+
+// ConsumeFriendInvitation marks the invitation from inviterEmail to inviteeEmail as consumed.
+func (fr *FriendInvitationRepository) ConsumeFriendInvitation(ctx context.Context, inviteeEmail, inviterEmail string, consumedAt time.Time) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return fr.Err
+	}
+	invitation, ok := fr.Invitations[repositories.CompositeID(inviteeEmail, inviterEmail)]
+	if !ok {
+		return fmt.Errorf("friend invitation %w", repositories.ErrNotFound)
+	}
+	invitation.ConsumedAt = &consumedAt
+	return nil
+}
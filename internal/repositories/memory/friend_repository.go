@@ -0,0 +1,310 @@
+/**
+ *  FriendRepository is an in-memory implementation of repositories.FriendRepository, used when
+ *  STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       friend_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Friends (map[string]*models.Friend) - Friend requests keyed by CompositeID(sender, recipient).
+ *  - Blocks (map[string]*models.Block)   - Blocks keyed by CompositeID(blocker, blocked).
+ *  - Err (error)                         - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewFriendRepository()                                         - Creates an empty FriendRepository.
+ *  - CreateFriendRequest(ctx, friend)                              - Stores a friend request.
+ *  - GetFriendRequest(ctx, senderEmail, recipientEmail)            - Retrieves a friend request, or nil.
+ *  - UpdateFriendRequest(ctx, senderEmail, recipientEmail, updates) - Updates fields of a friend request.
+ *  - AcceptFriendRequest(ctx, senderEmail, recipientEmail)          - Accepts a pending friend request.
+ *  - DeleteFriendRequest(ctx, senderEmail, recipientEmail)          - Deletes a friend request.
+ *  - GetFriends(ctx, userEmail)                                    - Retrieves a user's accepted friendships.
+ *  - GetPendingFriendRequests(ctx, userEmail)                      - Retrieves the pending requests sent to a user.
+ *  - GetSentFriendRequests(ctx, userEmail)                         - Retrieves the pending requests sent by a user.
+ *  - GetFriendsOfUsers(ctx, userEmails)                            - Retrieves the accepted friendships of many users.
+ *  - CreateBlock, GetBlock, DeleteBlock, GetBlockedUsers           - Manage blocks between users.
+ *  - MigrateFriendEmail(ctx, oldEmail, newEmail)                   - Re-keys requests and blocks after an email change.
+ *  - DeleteStaleFriendRequests(ctx, pendingBefore, declinedBefore) - Deletes expired and old declined requests.
+ *  - CountFriends(ctx, userEmail, limit)                           - Counts a user's accepted friendships up to a limit.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, so the repository can be shared by concurrent requests.
+ *    Friends and Blocks are exported so tests can seed and inspect them; that direct access is not synchronized.
+ *  - Requests and blocks are stored and returned as copies, like documents read from Firestore.
+ *  - Like Firestore, GetFriendRequest and GetBlock return nil for a missing document, and
+ *    UpdateFriendRequest reports it with repositories.ErrNotFound rather than creating it.
+ *  - AcceptFriendRequest checks and updates the request under the lock, so like the Firestore
+ *    transaction it returns repositories.ErrFriendRequestNotPending for a missing or answered request.
+ *  - Friendships are filtered by Status: "accepted" for friends, "pending" for open requests.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// FriendRepository keeps friend requests and blocks in memory.
+type FriendRepository struct {
+	mu      sync.RWMutex
+	Friends map[string]*models.Friend // Friend requests keyed by CompositeID(sender, recipient).
+	Blocks  map[string]*models.Block  // Blocks keyed by CompositeID(blocker, blocked).
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewFriendRepository creates an empty FriendRepository.
+func NewFriendRepository() *FriendRepository {
+	return &FriendRepository{Friends: make(map[string]*models.Friend), Blocks: make(map[string]*models.Block)}
+}
+
+// CreateFriendRequest stores a copy of a friend request, replacing any request between the same users.
+func (fr *FriendRepository) CreateFriendRequest(ctx context.Context, friend *models.Friend) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return fr.Err
+	}
+	stored := *friend
+	fr.Friends[repositories.CompositeID(friend.Email, friend.FriendEmail)] = &stored
+	return nil
+}
+
+// GetFriendRequest retrieves a copy of the friend request from senderEmail to recipientEmail,
+// returning nil if there is none.
+func (fr *FriendRepository) GetFriendRequest(ctx context.Context, senderEmail, recipientEmail string) (*models.Friend, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	if fr.Err != nil {
+		return nil, fr.Err
+	}
+	friend, exists := fr.Friends[repositories.CompositeID(senderEmail, recipientEmail)]
+	if !exists {
+		return nil, nil
+	}
+	found := *friend
+	return &found, nil
+}
+
+// UpdateFriendRequest updates the fields of a friend request, keyed by their Go field names.
+// It fails rather than creating a request that does not exist.
+func (fr *FriendRepository) UpdateFriendRequest(ctx context.Context, senderEmail, recipientEmail string, updates map[string]interface{}) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return fr.Err
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	friend, exists := fr.Friends[repositories.CompositeID(senderEmail, recipientEmail)]
+	if !exists {
+		return fmt.Errorf("friend request %w", repositories.ErrNotFound)
+	}
+	updated := *friend
+	if err := setFields(&updated, updates); err != nil {
+		return fmt.Errorf("Failed to update friend request: %w", err)
+	}
+	*friend = updated
+	return nil
+}
+
+// AcceptFriendRequest accepts the friend request from senderEmail to recipientEmail if it is still pending.
+func (fr *FriendRepository) AcceptFriendRequest(ctx context.Context, senderEmail, recipientEmail string) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return fr.Err
+	}
+	friend, exists := fr.Friends[repositories.CompositeID(senderEmail, recipientEmail)]
+	if !exists || friend.Status != "pending" {
+		return repositories.ErrFriendRequestNotPending
+	}
+	friend.Status = "accepted"
+	return nil
+}
+
+// DeleteFriendRequest deletes the friend request from senderEmail to recipientEmail, if there is one.
+func (fr *FriendRepository) DeleteFriendRequest(ctx context.Context, senderEmail, recipientEmail string) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return fr.Err
+	}
+	delete(fr.Friends, repositories.CompositeID(senderEmail, recipientEmail))
+	return nil
+}
+
+// GetFriends retrieves the accepted friendships sent or received by a user.
+func (fr *FriendRepository) GetFriends(ctx context.Context, userEmail string) ([]models.Friend, error) {
+	return fr.friendsWhere(func(friend *models.Friend) bool {
+		return (friend.Email == userEmail || friend.FriendEmail == userEmail) && friend.Status == "accepted"
+	})
+}
+
+// GetPendingFriendRequests retrieves the pending friend requests sent to a user.
+func (fr *FriendRepository) GetPendingFriendRequests(ctx context.Context, userEmail string) ([]models.Friend, error) {
+	return fr.friendsWhere(func(friend *models.Friend) bool {
+		return friend.FriendEmail == userEmail && friend.Status == "pending"
+	})
+}
+
+// GetSentFriendRequests retrieves the pending friend requests sent by a user.
+func (fr *FriendRepository) GetSentFriendRequests(ctx context.Context, userEmail string) ([]models.Friend, error) {
+	return fr.friendsWhere(func(friend *models.Friend) bool {
+		return friend.Email == userEmail && friend.Status == "pending"
+	})
+}
+
+// GetFriendsOfUsers retrieves the accepted friendships involving any of the given users.
+func (fr *FriendRepository) GetFriendsOfUsers(ctx context.Context, userEmails []string) ([]models.Friend, error) {
+	users := make(map[string]bool, len(userEmails))
+	for _, email := range userEmails {
+		users[email] = true
+	}
+	return fr.friendsWhere(func(friend *models.Friend) bool {
+		return (users[friend.Email] || users[friend.FriendEmail]) && friend.Status == "accepted"
+	})
+}
+
+// friendsWhere retrieves copies of the friend requests for which match returns true.
+func (fr *FriendRepository) friendsWhere(match func(friend *models.Friend) bool) ([]models.Friend, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	if fr.Err != nil {
+		return nil, fr.Err
+	}
+	var friends []models.Friend
+	for _, friend := range fr.Friends {
+		if match(friend) {
+			friends = append(friends, *friend)
+		}
+	}
+	return friends, nil
+}
+
+// CreateBlock records that a user has blocked another user.
+func (fr *FriendRepository) CreateBlock(ctx context.Context, block *models.Block) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return fr.Err
+	}
+	stored := *block
+	fr.Blocks[repositories.CompositeID(block.BlockerEmail, block.BlockedEmail)] = &stored
+	return nil
+}
+
+// GetBlock retrieves a copy of a block, returning nil if there is none.
+func (fr *FriendRepository) GetBlock(ctx context.Context, blockerEmail, blockedEmail string) (*models.Block, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	if fr.Err != nil {
+		return nil, fr.Err
+	}
+	block, exists := fr.Blocks[repositories.CompositeID(blockerEmail, blockedEmail)]
+	if !exists {
+		return nil, nil
+	}
+	found := *block
+	return &found, nil
+}
+
+// DeleteBlock removes a block, if there is one.
+func (fr *FriendRepository) DeleteBlock(ctx context.Context, blockerEmail, blockedEmail string) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return fr.Err
+	}
+	delete(fr.Blocks, repositories.CompositeID(blockerEmail, blockedEmail))
+	return nil
+}
+
+// GetBlockedUsers retrieves all blocks created by a user.
+func (fr *FriendRepository) GetBlockedUsers(ctx context.Context, blockerEmail string) ([]models.Block, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	if fr.Err != nil {
+		return nil, fr.Err
+	}
+	var blocks []models.Block
+	for _, block := range fr.Blocks {
+		if block.BlockerEmail == blockerEmail {
+			blocks = append(blocks, *block)
+		}
+	}
+	return blocks, nil
+}
+
+// MigrateFriendEmail replaces oldEmail with newEmail in every friend request and block,
+// re-keying them like the Firestore implementation.
+func (fr *FriendRepository) MigrateFriendEmail(ctx context.Context, oldEmail, newEmail string) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return fr.Err
+	}
+	replace := func(email string) string {
+		if email == oldEmail {
+			return newEmail
+		}
+		return email
+	}
+
+	friends := make(map[string]*models.Friend, len(fr.Friends))
+	for _, friend := range fr.Friends {
+		friend.Email, friend.FriendEmail = replace(friend.Email), replace(friend.FriendEmail)
+		friends[repositories.CompositeID(friend.Email, friend.FriendEmail)] = friend
+	}
+	fr.Friends = friends
+
+	blocks := make(map[string]*models.Block, len(fr.Blocks))
+	for _, block := range fr.Blocks {
+		block.BlockerEmail, block.BlockedEmail = replace(block.BlockerEmail), replace(block.BlockedEmail)
+		blocks[repositories.CompositeID(block.BlockerEmail, block.BlockedEmail)] = block
+	}
+	fr.Blocks = blocks
+	return nil
+}
+
+// DeleteStaleFriendRequests deletes the pending requests created before pendingBefore and the
+// declined requests declined before declinedBefore, returning how many were deleted.
+// Requests without a CreatedAt are kept.
+func (fr *FriendRepository) DeleteStaleFriendRequests(ctx context.Context, pendingBefore, declinedBefore time.Time) (int, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.Err != nil {
+		return 0, fr.Err
+	}
+	deleted := 0
+	for docID, friend := range fr.Friends {
+		expired := friend.Status == "pending" && !friend.CreatedAt.IsZero() && friend.CreatedAt.Before(pendingBefore)
+		declined := friend.Status == "declined" && friend.DeclinedAt != nil && friend.DeclinedAt.Before(declinedBefore)
+		if expired || declined {
+			delete(fr.Friends, docID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// CountFriends counts a user's accepted friendships, stopping at limit.
+func (fr *FriendRepository) CountFriends(ctx context.Context, userEmail string, limit int) (int, error) {
+	friends, err := fr.GetFriends(ctx, userEmail)
+	if err != nil {
+		return 0, err
+	}
+	return capCount(len(friends), limit), nil
+}
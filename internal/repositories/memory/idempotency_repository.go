@@ -0,0 +1,113 @@
+/**
+ *  IdempotencyRepository is an in-memory implementation of repositories.IdempotencyRepository, used
+ *  when STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       idempotency_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Records (map[string]*models.IdempotencyRecord) - Records keyed by "{email}_{key}".
+ *  - Err (error)                                    - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewIdempotencyRepository()         - Creates an empty IdempotencyRepository.
+ *  - CreateRecord(ctx, record)          - Stores a record unless a live one exists.
+ *  - GetRecord(ctx, userEmail, key)     - Retrieves the record of a user's key.
+ *  - CompleteRecord(ctx, record)        - Stores the outcome of a request.
+ *  - DeleteRecord(ctx, userEmail, key)  - Removes the record of a user's key.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.Mutex, so concurrent CreateRecord calls behave like the Firestore transaction.
+ *  - GetRecord returns repositories.ErrIdempotencyRecordNotFound for an unknown key, like Firestore.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// IdempotencyRepository keeps idempotency records in memory.
+type IdempotencyRepository struct {
+	mu      sync.Mutex
+	Records map[string]*models.IdempotencyRecord // Records keyed by "{email}_{key}".
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewIdempotencyRepository creates an empty IdempotencyRepository.
+func NewIdempotencyRepository() *IdempotencyRepository {
+	return &IdempotencyRepository{Records: make(map[string]*models.IdempotencyRecord)}
+}
+
+// idempotencyRecordKey returns the key of the record of a user's idempotency key.
+func idempotencyRecordKey(userEmail, key string) string {
+	return userEmail + "_" + key
+}
+
+// CreateRecord stores a copy of a record unless the user has a record for the key that
+// repositories.IdempotencyRecordReplaceable keeps.
+func (ir *IdempotencyRepository) CreateRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if ir.Err != nil {
+		return ir.Err
+	}
+	if existing, exists := ir.Records[idempotencyRecordKey(record.Email, record.Key)]; exists && !repositories.IdempotencyRecordReplaceable(existing, record.CreatedAt) {
+		return repositories.ErrIdempotencyKeyExists
+	}
+	stored := *record
+	ir.Records[idempotencyRecordKey(record.Email, record.Key)] = &stored
+	return nil
+}
+
+// GetRecord retrieves a copy of the record of a user's key.
+func (ir *IdempotencyRepository) GetRecord(ctx context.Context, userEmail, key string) (*models.IdempotencyRecord, error) {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if ir.Err != nil {
+		return nil, ir.Err
+	}
+	record, exists := ir.Records[idempotencyRecordKey(userEmail, key)]
+	if !exists {
+		return nil, repositories.ErrIdempotencyRecordNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+// CompleteRecord stores the status, event ID and response of a record.
+func (ir *IdempotencyRepository) CompleteRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if ir.Err != nil {
+		return ir.Err
+	}
+	if stored, exists := ir.Records[idempotencyRecordKey(record.Email, record.Key)]; exists {
+		stored.Status = record.Status
+		stored.EventID = record.EventID
+		stored.Response = record.Response
+	}
+	return nil
+}
+
+// DeleteRecord removes the record of a user's key.
+func (ir *IdempotencyRepository) DeleteRecord(ctx context.Context, userEmail, key string) error {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if ir.Err != nil {
+		return ir.Err
+	}
+	delete(ir.Records, idempotencyRecordKey(userEmail, key))
+	return nil
+}
@@ -0,0 +1,163 @@
+/**
+ *  InvitationRepository is an in-memory implementation of repositories.InvitationRepository, used when
+ *  STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       invitation_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Invitations (map[string]*models.EventInvitation) - Invitations keyed by "{eventID}_{inviteeEmail}".
+ *  - Err (error)                                      - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewInvitationRepository()                             - Creates an empty InvitationRepository.
+ *  - CreateInvitation(ctx, invitation)                     - Stores an invitation.
+ *  - GetInvitation(ctx, eventID, inviteeEmail)             - Retrieves an invitation, or nil.
+ *  - UpdateInvitation(ctx, eventID, inviteeEmail, updates) - Updates fields of an invitation.
+ *  - GetInvitationsForUser(ctx, inviteeEmail)              - Retrieves the invitations received by a user.
+ *  - GetInvitationsForEvent(ctx, ownerEmail, eventID)      - Retrieves the invitations sent for an event.
+ *  - MigrateInvitationEmail(ctx, oldEmail, newEmail)       - Re-keys invitations after an email change.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, so the repository can be shared by concurrent requests.
+ *    Invitations is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *  - Invitations are stored and returned as copies, like documents read from Firestore.
+ *  - Like Firestore, GetInvitation returns nil for a missing invitation, and UpdateInvitation
+ *    reports it with repositories.ErrNotFound rather than creating it.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// InvitationRepository keeps event invitations in memory.
+type InvitationRepository struct {
+	mu          sync.RWMutex
+	Invitations map[string]*models.EventInvitation // Invitations keyed by "{eventID}_{inviteeEmail}".
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewInvitationRepository creates an empty InvitationRepository.
+func NewInvitationRepository() *InvitationRepository {
+	return &InvitationRepository{Invitations: make(map[string]*models.EventInvitation)}
+}
+
+// invitationKey returns the key of the invitation of inviteeEmail to eventID.
+func invitationKey(eventID, inviteeEmail string) string {
+	return eventID + "_" + inviteeEmail
+}
+
+// CreateInvitation stores a copy of an invitation, replacing any invitation of the same invitee to the event.
+func (ir *InvitationRepository) CreateInvitation(ctx context.Context, invitation *models.EventInvitation) error {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if ir.Err != nil {
+		return ir.Err
+	}
+	stored := *invitation
+	ir.Invitations[invitationKey(invitation.EventID, invitation.InviteeEmail)] = &stored
+	return nil
+}
+
+// GetInvitation retrieves a copy of the invitation of inviteeEmail to eventID, returning nil if there is none.
+func (ir *InvitationRepository) GetInvitation(ctx context.Context, eventID, inviteeEmail string) (*models.EventInvitation, error) {
+	ir.mu.RLock()
+	defer ir.mu.RUnlock()
+	if ir.Err != nil {
+		return nil, ir.Err
+	}
+	invitation, exists := ir.Invitations[invitationKey(eventID, inviteeEmail)]
+	if !exists {
+		return nil, nil
+	}
+	found := *invitation
+	return &found, nil
+}
+
+// UpdateInvitation updates the fields of an invitation, keyed by their Go field names.
+// It fails rather than creating an invitation that does not exist.
+func (ir *InvitationRepository) UpdateInvitation(ctx context.Context, eventID, inviteeEmail string, updates map[string]interface{}) error {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if ir.Err != nil {
+		return ir.Err
+	}
+	invitation, exists := ir.Invitations[invitationKey(eventID, inviteeEmail)]
+	if !exists {
+		return fmt.Errorf("invitation %w", repositories.ErrNotFound)
+	}
+	updated := *invitation
+	if err := setFields(&updated, updates); err != nil {
+		return fmt.Errorf("Failed to update invitation: %w", err)
+	}
+	*invitation = updated
+	return nil
+}
+
+// GetInvitationsForUser retrieves copies of all invitations received by inviteeEmail.
+func (ir *InvitationRepository) GetInvitationsForUser(ctx context.Context, inviteeEmail string) ([]models.EventInvitation, error) {
+	ir.mu.RLock()
+	defer ir.mu.RUnlock()
+	if ir.Err != nil {
+		return nil, ir.Err
+	}
+	var invitations []models.EventInvitation
+	for _, invitation := range ir.Invitations {
+		if invitation.InviteeEmail == inviteeEmail {
+			invitations = append(invitations, *invitation)
+		}
+	}
+	return invitations, nil
+}
+
+// GetInvitationsForEvent retrieves copies of all invitations sent by ownerEmail for one of their events.
+func (ir *InvitationRepository) GetInvitationsForEvent(ctx context.Context, ownerEmail, eventID string) ([]models.EventInvitation, error) {
+	ir.mu.RLock()
+	defer ir.mu.RUnlock()
+	if ir.Err != nil {
+		return nil, ir.Err
+	}
+	var invitations []models.EventInvitation
+	for _, invitation := range ir.Invitations {
+		if invitation.OwnerEmail == ownerEmail && invitation.EventID == eventID {
+			invitations = append(invitations, *invitation)
+		}
+	}
+	return invitations, nil
+}
+
+// MigrateInvitationEmail replaces oldEmail with newEmail as the owner or invitee of every invitation,
+// re-keying the invitations received under oldEmail.
+func (ir *InvitationRepository) MigrateInvitationEmail(ctx context.Context, oldEmail, newEmail string) error {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if ir.Err != nil {
+		return ir.Err
+	}
+	migrated := make(map[string]*models.EventInvitation, len(ir.Invitations))
+	for _, invitation := range ir.Invitations {
+		if invitation.OwnerEmail == oldEmail {
+			invitation.OwnerEmail = newEmail
+		}
+		if invitation.InviteeEmail == oldEmail {
+			invitation.InviteeEmail = newEmail
+		}
+		migrated[invitationKey(invitation.EventID, invitation.InviteeEmail)] = invitation
+	}
+	ir.Invitations = migrated
+	return nil
+}
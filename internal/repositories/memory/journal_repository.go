@@ -0,0 +1,299 @@
+/**
+ *  JournalRepository is an in-memory implementation of repositories.JournalRepository, used when
+ *  STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       journal_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Journals (map[string]*models.Journal) - The journals of all users keyed by JournalID.
+ *  - Err (error)                           - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewJournalRepository()                                - Creates an empty JournalRepository.
+ *  - CreateJournal(ctx, journal)                           - Stores a journal and assigns a JournalID.
+ *  - GetJournal(ctx, userEmail, journalID)                 - Retrieves a journal by ID for a user.
+ *  - GetJournalByDate(ctx, userEmail, date)                - Retrieves a user's journal for a date.
+ *  - UpdateJournal(ctx, journal)                           - Replaces a journal.
+ *  - DeleteJournal(ctx, userEmail, journalID)              - Deletes a journal.
+ *  - GetAllJournals(ctx, userEmail)                        - Retrieves all journals of a user.
+ *  - SearchJournals(ctx, userEmail, query, from, to, limit) - Searches a user's journals by content and date.
+ *  - StreamJournals(ctx, userEmail, from, to, fn)          - Iterates over a user's journals in date order.
+ *  - GetDeletedJournals(ctx, userEmail)                    - Retrieves a user's journals in the trash.
+ *  - PurgeDeletedJournals(ctx, deletedBefore)              - Purges and returns the journals trashed before a time.
+ *  - CountJournals(ctx, userEmail, limit)                  - Counts a user's journals outside the trash.
+ *  - SaveJournals(ctx, journals)                           - Creates or replaces several journals.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, so the repository can be shared by concurrent requests.
+ *    Journals is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *  - Journals are stored and returned as copies, so callers cannot change them without UpdateJournal.
+ *  - Like the users/{email}/journals subcollection in Firestore, a journal belongs to the user in its
+ *    Email field, and deleting a journal the user does not have succeeds without deleting anything.
+ *  - Journals with a DeletedAt time are in the trash: only GetJournal, GetDeletedJournals and
+ *    PurgeDeletedJournals return them, like the Firestore repository.
+ *  - StreamJournals calls fn without holding the lock, so fn may use the repository.
+ *  - Missing journals are reported with repositories.ErrNotFound.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// JournalRepository keeps the journals of all users in memory.
+type JournalRepository struct {
+	mu       sync.RWMutex
+	Journals map[string]*models.Journal // Journals keyed by JournalID.
+	nextID   int                        // Counter used to generate JournalIDs.
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewJournalRepository creates an empty JournalRepository.
+func NewJournalRepository() *JournalRepository {
+	return &JournalRepository{Journals: make(map[string]*models.Journal)}
+}
+
+// CreateJournal stores a copy of a journal, assigning a generated JournalID to it.
+func (jr *JournalRepository) CreateJournal(ctx context.Context, journal *models.Journal) error {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	if jr.Err != nil {
+		return jr.Err
+	}
+	jr.nextID++
+	journal.JournalID = fmt.Sprintf("journal%d", jr.nextID)
+	stored := *journal
+	jr.Journals[journal.JournalID] = &stored
+	return nil
+}
+
+// GetJournal retrieves a copy of a user's journal by ID, also if it is in the trash.
+func (jr *JournalRepository) GetJournal(ctx context.Context, userEmail, journalID string) (*models.Journal, error) {
+	jr.mu.RLock()
+	defer jr.mu.RUnlock()
+	if jr.Err != nil {
+		return nil, jr.Err
+	}
+	journal, exists := jr.Journals[journalID]
+	if !exists || journal.Email != userEmail {
+		return nil, fmt.Errorf("Journal %w", repositories.ErrNotFound)
+	}
+	found := *journal
+	return &found, nil
+}
+
+// GetJournalByDate retrieves a user's journal for a date, returning nil if there is none.
+func (jr *JournalRepository) GetJournalByDate(ctx context.Context, userEmail, date string) (*models.Journal, error) {
+	jr.mu.RLock()
+	defer jr.mu.RUnlock()
+	if jr.Err != nil {
+		return nil, jr.Err
+	}
+	for _, journal := range jr.Journals {
+		if journal.Email == userEmail && journal.Date == date && journal.DeletedAt == nil {
+			found := *journal
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateJournal replaces a journal with a copy of journal. Like Firestore's Set, the journal is
+// stored even if it did not exist, but a journal of another user is never overwritten.
+func (jr *JournalRepository) UpdateJournal(ctx context.Context, journal *models.Journal) error {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	if jr.Err != nil {
+		return jr.Err
+	}
+	if existing, exists := jr.Journals[journal.JournalID]; exists && existing.Email != journal.Email {
+		return fmt.Errorf("Journal %w", repositories.ErrNotFound)
+	}
+	stored := *journal
+	jr.Journals[journal.JournalID] = &stored
+	return nil
+}
+
+// DeleteJournal deletes a user's journal by ID. Deleting a journal the user does not have succeeds.
+func (jr *JournalRepository) DeleteJournal(ctx context.Context, userEmail, journalID string) error {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	if jr.Err != nil {
+		return jr.Err
+	}
+	if journal, exists := jr.Journals[journalID]; exists && journal.Email == userEmail {
+		delete(jr.Journals, journalID)
+	}
+	return nil
+}
+
+// GetAllJournals retrieves a user's journals outside the trash, ordered by ID like Firestore.
+func (jr *JournalRepository) GetAllJournals(ctx context.Context, userEmail string) ([]models.Journal, error) {
+	jr.mu.RLock()
+	defer jr.mu.RUnlock()
+	if jr.Err != nil {
+		return nil, jr.Err
+	}
+	var journals []models.Journal
+	for _, journal := range jr.Journals {
+		if journal.Email == userEmail && journal.DeletedAt == nil {
+			journals = append(journals, *journal)
+		}
+	}
+	sort.Slice(journals, func(i, j int) bool { return journals[i].JournalID < journals[j].JournalID })
+	return journals, nil
+}
+
+// SearchJournals searches a user's journals outside the trash for query, ignoring case, within the
+// date range [from, to], newest first. Empty bounds are open, and a limit of 0 returns every match.
+func (jr *JournalRepository) SearchJournals(ctx context.Context, userEmail, query, from, to string, limit int) ([]models.Journal, error) {
+	jr.mu.RLock()
+	defer jr.mu.RUnlock()
+	if jr.Err != nil {
+		return nil, jr.Err
+	}
+	return jr.search(userEmail, query, from, to, limit), nil
+}
+
+// StreamJournals calls fn with each of a user's journals within the date range [from, to], oldest first.
+func (jr *JournalRepository) StreamJournals(ctx context.Context, userEmail, from, to string, fn func(models.Journal) error) error {
+	jr.mu.RLock()
+	if jr.Err != nil {
+		jr.mu.RUnlock()
+		return jr.Err
+	}
+	journals := jr.search(userEmail, "", from, to, 0)
+	jr.mu.RUnlock()
+
+	for i := len(journals) - 1; i >= 0; i-- {
+		if err := fn(journals[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// search returns the journals matched by SearchJournals. The caller holds the lock.
+func (jr *JournalRepository) search(userEmail, query, from, to string, limit int) []models.Journal {
+	needle := strings.ToLower(query)
+	journals := []models.Journal{}
+	for _, journal := range jr.Journals {
+		if journal.Email != userEmail || journal.DeletedAt != nil {
+			continue
+		}
+		if (from != "" && journal.Date < from) || (to != "" && journal.Date > to) {
+			continue
+		}
+		if needle != "" && !strings.Contains(strings.ToLower(journal.Content), needle) {
+			continue
+		}
+		journals = append(journals, *journal)
+	}
+
+	sort.Slice(journals, func(i, j int) bool {
+		if journals[i].Date != journals[j].Date {
+			return journals[i].Date > journals[j].Date
+		}
+		return journals[i].JournalID < journals[j].JournalID
+	})
+	if limit > 0 && len(journals) > limit {
+		journals = journals[:limit]
+	}
+	return journals
+}
+
+// GetDeletedJournals retrieves a user's journals in the trash, most recently deleted first.
+func (jr *JournalRepository) GetDeletedJournals(ctx context.Context, userEmail string) ([]models.Journal, error) {
+	jr.mu.RLock()
+	defer jr.mu.RUnlock()
+	if jr.Err != nil {
+		return nil, jr.Err
+	}
+	journals := []models.Journal{}
+	for _, journal := range jr.Journals {
+		if journal.Email == userEmail && journal.DeletedAt != nil {
+			journals = append(journals, *journal)
+		}
+	}
+	sort.Slice(journals, func(i, j int) bool {
+		return journals[i].DeletedAt.After(*journals[j].DeletedAt)
+	})
+	return journals, nil
+}
+
+// PurgeDeletedJournals permanently deletes the journals of all users trashed before deletedBefore,
+// returning them.
+func (jr *JournalRepository) PurgeDeletedJournals(ctx context.Context, deletedBefore time.Time) ([]models.Journal, error) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	if jr.Err != nil {
+		return nil, jr.Err
+	}
+	deleted := []models.Journal{}
+	for journalID, journal := range jr.Journals {
+		if journal.DeletedAt != nil && journal.DeletedAt.Before(deletedBefore) {
+			delete(jr.Journals, journalID)
+			deleted = append(deleted, *journal)
+		}
+	}
+	return deleted, nil
+}
+
+// CountJournals counts a user's journals outside the trash, stopping at limit.
+func (jr *JournalRepository) CountJournals(ctx context.Context, userEmail string, limit int) (int, error) {
+	jr.mu.RLock()
+	defer jr.mu.RUnlock()
+	if jr.Err != nil {
+		return 0, jr.Err
+	}
+	count := 0
+	for _, journal := range jr.Journals {
+		if journal.Email == userEmail && journal.DeletedAt == nil {
+			count++
+		}
+	}
+	return capCount(count, limit), nil
+}
+
+// migrateEmail moves the journals of oldEmail to newEmail, for UserRepository.MigrateUserEmail.
+func (jr *JournalRepository) migrateEmail(oldEmail, newEmail string) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	for journalID, journal := range jr.Journals {
+		if journal.Email == oldEmail {
+			moved := *journal
+			moved.Email = newEmail
+			jr.Journals[journalID] = &moved
+		}
+	}
+}
+
+// SaveJournals creates the journals without a JournalID and replaces the others, returning an error per journal.
+func (jr *JournalRepository) SaveJournals(ctx context.Context, journals []*models.Journal) []error {
+	errs := make([]error, len(journals))
+	for i, journal := range journals {
+		if journal.JournalID == "" {
+			errs[i] = jr.CreateJournal(ctx, journal)
+		} else {
+			errs[i] = jr.UpdateJournal(ctx, journal)
+		}
+	}
+	return errs
+}
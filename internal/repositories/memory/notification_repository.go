@@ -0,0 +1,122 @@
+/**
+ *  NotificationRepository is an in-memory implementation of repositories.NotificationRepository, used
+ *  when STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       notification_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Notifications (map[string][]*models.Notification) - Notifications keyed by user email.
+ *  - Err (error)                                       - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewNotificationRepository()                          - Creates an empty NotificationRepository.
+ *  - CreateNotification(ctx, notification)                - Stores a notification with a generated ID.
+ *  - ListNotifications(ctx, userEmail, unreadOnly, limit) - Lists a user's notifications, newest first.
+ *  - MarkRead(ctx, userEmail, notificationID)             - Marks a notification as read.
+ *  - MarkAllRead(ctx, userEmail)                          - Marks all of a user's notifications as read.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, so the repository can be shared by concurrent requests.
+ *    Notifications is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *  - Notifications are kept per user email, like the users/{email}/notifications subcollection.
+ *  - MarkRead returns repositories.ErrNotificationNotFound for an unknown ID, like Firestore.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// NotificationRepository keeps notification inboxes in memory.
+type NotificationRepository struct {
+	mu            sync.RWMutex
+	Notifications map[string][]*models.Notification // Notifications keyed by user email.
+	nextID        int                               // Counter used to generate IDs.
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewNotificationRepository creates an empty NotificationRepository.
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{Notifications: make(map[string][]*models.Notification)}
+}
+
+// CreateNotification stores a copy of a notification and sets its generated ID.
+func (nr *NotificationRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	if nr.Err != nil {
+		return nr.Err
+	}
+	nr.nextID++
+	notification.ID = fmt.Sprintf("notification%d", nr.nextID)
+	stored := *notification
+	nr.Notifications[notification.Email] = append(nr.Notifications[notification.Email], &stored)
+	return nil
+}
+
+// ListNotifications lists up to limit of a user's notifications, newest first.
+func (nr *NotificationRepository) ListNotifications(ctx context.Context, userEmail string, unreadOnly bool, limit int) ([]models.Notification, error) {
+	nr.mu.RLock()
+	defer nr.mu.RUnlock()
+	if nr.Err != nil {
+		return nil, nr.Err
+	}
+	notifications := []models.Notification{}
+	for _, notification := range nr.Notifications[userEmail] {
+		if unreadOnly && notification.Read {
+			continue
+		}
+		notifications = append(notifications, *notification)
+	}
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+// MarkRead marks a single notification of a user as read.
+func (nr *NotificationRepository) MarkRead(ctx context.Context, userEmail, notificationID string) error {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	if nr.Err != nil {
+		return nr.Err
+	}
+	for _, notification := range nr.Notifications[userEmail] {
+		if notification.ID == notificationID {
+			notification.Read = true
+			return nil
+		}
+	}
+	return repositories.ErrNotificationNotFound
+}
+
+// MarkAllRead marks all of a user's notifications as read.
+func (nr *NotificationRepository) MarkAllRead(ctx context.Context, userEmail string) error {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	if nr.Err != nil {
+		return nr.Err
+	}
+	for _, notification := range nr.Notifications[userEmail] {
+		notification.Read = true
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/**
+ *  PendingDeliveryRepository is an in-memory implementation of repositories.PendingDeliveryRepository,
+ *  used when STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       pending_delivery_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Deliveries (map[string]*models.PendingDelivery) - Held back emails keyed by generated ID.
+ *  - Err (error)                                     - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewPendingDeliveryRepository()           - Creates an empty PendingDeliveryRepository.
+ *  - CreatePendingDelivery(ctx, delivery)     - Stores a held back email with a generated ID.
+ *  - GetDuePendingDeliveries(ctx, now, limit) - Retrieves the emails due for delivery, oldest first.
+ *  - DeletePendingDelivery(ctx, deliveryID)   - Deletes a delivered email.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, so the repository can be shared by the scheduler and requests.
+ *    Deliveries is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"proh2052-group6/pkg/models"
+)
+
+// PendingDeliveryRepository keeps emails held back by quiet hours in memory.
+type PendingDeliveryRepository struct {
+	mu         sync.RWMutex
+	Deliveries map[string]*models.PendingDelivery // Held back emails keyed by generated ID.
+	nextID     int                                // Counter used to generate IDs.
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewPendingDeliveryRepository creates an empty PendingDeliveryRepository.
+func NewPendingDeliveryRepository() *PendingDeliveryRepository {
+	return &PendingDeliveryRepository{Deliveries: make(map[string]*models.PendingDelivery)}
+}
+
+// CreatePendingDelivery stores a copy of a held back email and sets its generated ID.
+func (pr *PendingDeliveryRepository) CreatePendingDelivery(ctx context.Context, delivery *models.PendingDelivery) error {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if pr.Err != nil {
+		return pr.Err
+	}
+	pr.nextID++
+	delivery.ID = fmt.Sprintf("delivery%d", pr.nextID)
+	stored := *delivery
+	pr.Deliveries[delivery.ID] = &stored
+	return nil
+}
+
+// GetDuePendingDeliveries retrieves up to limit emails whose DeliverAt is not after now, oldest first.
+func (pr *PendingDeliveryRepository) GetDuePendingDeliveries(ctx context.Context, now time.Time, limit int) ([]models.PendingDelivery, error) {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	if pr.Err != nil {
+		return nil, pr.Err
+	}
+	deliveries := []models.PendingDelivery{}
+	for _, delivery := range pr.Deliveries {
+		if !delivery.DeliverAt.After(now) {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].DeliverAt.Equal(deliveries[j].DeliverAt) {
+			return deliveries[i].DeliverAt.Before(deliveries[j].DeliverAt)
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// DeletePendingDelivery deletes a delivered email.
+func (pr *PendingDeliveryRepository) DeletePendingDelivery(ctx context.Context, deliveryID string) error {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if pr.Err != nil {
+		return pr.Err
+	}
+	delete(pr.Deliveries, deliveryID)
+	return nil
+}
@@ -0,0 +1,76 @@
+/**
+ *  PromptRepository is an in-memory implementation of repositories.PromptRepository, used when
+ *  STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       prompt_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Offsets (map[string]models.PromptOffset) - Prompt offsets keyed by user email.
+ *  - Err (error)                              - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewPromptRepository()                      - Creates an empty PromptRepository.
+ *  - GetPromptOffset(ctx, userEmail)            - Retrieves a user's prompt offset, 0 if none is stored.
+ *  - IncrementPromptOffset(ctx, userEmail, now) - Adds one to a user's prompt offset.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.Mutex, so increments are atomic like the Firestore transaction.
+ *    Offsets is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"proh2052-group6/pkg/models"
+)
+
+// PromptRepository keeps journal prompt offsets in memory.
+type PromptRepository struct {
+	mu      sync.Mutex
+	Offsets map[string]models.PromptOffset // Prompt offsets keyed by user email.
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewPromptRepository creates an empty PromptRepository.
+func NewPromptRepository() *PromptRepository {
+	return &PromptRepository{Offsets: make(map[string]models.PromptOffset)}
+}
+
+// GetPromptOffset retrieves a user's prompt offset, or an offset of 0 if none is stored.
+func (pr *PromptRepository) GetPromptOffset(ctx context.Context, userEmail string) (*models.PromptOffset, error) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if pr.Err != nil {
+		return nil, pr.Err
+	}
+	offset := pr.Offsets[userEmail]
+	offset.Email = userEmail
+	return &offset, nil
+}
+
+// IncrementPromptOffset adds one to a user's prompt offset and returns the new offset.
+func (pr *PromptRepository) IncrementPromptOffset(ctx context.Context, userEmail string, now time.Time) (*models.PromptOffset, error) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if pr.Err != nil {
+		return nil, pr.Err
+	}
+	offset := pr.Offsets[userEmail]
+	offset.Email = userEmail
+	offset.Offset++
+	offset.UpdatedAt = now
+	pr.Offsets[userEmail] = offset
+	return &offset, nil
+}
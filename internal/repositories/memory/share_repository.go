@@ -0,0 +1,113 @@
+/**
+ *  ShareRepository is an in-memory implementation of repositories.ShareRepository, used when
+ *  STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       share_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Shares (map[string]models.EventShare) - Shared event links keyed by token.
+ *  - Err (error)                           - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewShareRepository()                       - Creates an empty ShareRepository.
+ *  - CreateEventShare(ctx, token, share)        - Stores a link under its token.
+ *  - GetEventShare(ctx, token)                  - Retrieves the link with a token.
+ *  - DeleteEventShares(ctx, email, eventID)     - Deletes every link to an event.
+ *  - MigrateShareEmail(ctx, oldEmail, newEmail) - Rewrites the owner of links after an email change.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, so the repository can be shared by concurrent requests.
+ *    Shares is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *  - Like the Firestore create, CreateEventShare fails if the token is taken.
+ *  - Missing links are reported with repositories.ErrNotFound.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// ShareRepository keeps shared event links in memory.
+type ShareRepository struct {
+	mu     sync.RWMutex
+	Shares map[string]models.EventShare // Shared event links keyed by token.
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewShareRepository creates an empty ShareRepository.
+func NewShareRepository() *ShareRepository {
+	return &ShareRepository{Shares: make(map[string]models.EventShare)}
+}
+
+// CreateEventShare stores share as the link with token, failing if the token is taken.
+func (sr *ShareRepository) CreateEventShare(ctx context.Context, token string, share *models.EventShare) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.Err != nil {
+		return sr.Err
+	}
+	if _, exists := sr.Shares[token]; exists {
+		return fmt.Errorf("Failed to create event share: token already exists")
+	}
+	sr.Shares[token] = *share
+	return nil
+}
+
+// GetEventShare retrieves the link with token.
+func (sr *ShareRepository) GetEventShare(ctx context.Context, token string) (*models.EventShare, error) {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	if sr.Err != nil {
+		return nil, sr.Err
+	}
+	share, exists := sr.Shares[token]
+	if !exists {
+		return nil, fmt.Errorf("event share %w", repositories.ErrNotFound)
+	}
+	return &share, nil
+}
+
+// DeleteEventShares deletes every link to the event eventID of email.
+func (sr *ShareRepository) DeleteEventShares(ctx context.Context, email, eventID string) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.Err != nil {
+		return sr.Err
+	}
+	for token, share := range sr.Shares {
+		if share.Email == email && share.EventID == eventID {
+			delete(sr.Shares, token)
+		}
+	}
+	return nil
+}
+
+// MigrateShareEmail replaces oldEmail with newEmail as the owner of every link.
+func (sr *ShareRepository) MigrateShareEmail(ctx context.Context, oldEmail, newEmail string) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.Err != nil {
+		return sr.Err
+	}
+	for token, share := range sr.Shares {
+		if share.Email == oldEmail {
+			share.Email = newEmail
+			sr.Shares[token] = share
+		}
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+/**
+ *  UserRepository is an in-memory implementation of repositories.UserRepository, used when
+ *  STORAGE_BACKEND is "memory" and by the tests.
+ *
+ *  @file       user_repository.go
+ *  @package    memory
+ *
+ *  @properties
+ *  - Users (map[string]*models.User)   - The users keyed by email.
+ *  - Events (*EventRepository)         - Events moved along with a user by MigrateUserEmail, if set.
+ *  - Journals (*JournalRepository)     - Journals moved along with a user by MigrateUserEmail, if set.
+ *  - Err (error)                       - When set, every method fails with it.
+ *
+ *  @methods
+ *  - NewUserRepository()                              - Creates an empty UserRepository.
+ *  - GetUserByEmail(ctx, email)                       - Retrieves a user by email.
+ *  - GetUserByUsername(ctx, username)                 - Retrieves a user by username, ignoring case.
+ *  - GetUserByFeedToken(ctx, token)                   - Retrieves a user by calendar feed token.
+ *  - CreateUser(ctx, user)                            - Stores a new user.
+ *  - UpdateUser(ctx, email, updates)                  - Updates fields of a user, keyed by their Go field names.
+ *  - IncrementUserField(ctx, email, field)            - Adds one to an integer field of a user.
+ *  - ReplaceUser(ctx, user)                           - Overwrites an existing user.
+ *  - SearchUsers(ctx, query, limit)                   - Searches users by username, first or last name prefix.
+ *  - MigrateUserEmail(ctx, oldEmail, newEmail)        - Moves a user and their events and journals to a new email.
+ *  - GetDigestSubscribers(ctx)                        - Retrieves the users who enabled the weekly digest.
+ *  - GetJournalReminderSubscribers(ctx)               - Retrieves the users who enabled the daily journal reminder.
+ *  - GetUnnormalizedUserEmails(ctx)                   - Retrieves the emails of users stored under a mixed-case or padded email.
+ *
+ *  @behaviors
+ *  - Every method holds a sync.RWMutex, so the repository can be shared by concurrent requests.
+ *    Users is exported so tests can seed and inspect it; that direct access is not synchronized.
+ *  - Users are stored and returned as copies, like documents read from Firestore, so changing a
+ *    returned user does not change the repository.
+ *  - Usernames are matched through UsernameLower, or Username for users stored without it.
+ *  - Like Firestore, SearchUsers returns up to limit matches per field in field order, username
+ *    matches first, with each user once.
+ *  - Like the users/{email}/events and journals subcollections in Firestore, a user's events and
+ *    journals move with them when their email changes. Moves cannot fail halfway here, but like in
+ *    Firestore a user under the new email with MovedFrom set to the old one is replaced by the move.
+ *  - Missing users are reported with repositories.ErrNotFound. Unlike Firestore, CreateUser refuses
+ *    to overwrite an existing user.
+ *
+ *  @example
+ *  ```
+ *  userRepository := memory.NewUserRepository()
+ *  err := userRepository.CreateUser(ctx, &models.User{Email: "user@example.com", Username: "testuser"})
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+)
+
+// UserRepository keeps users in memory.
+type UserRepository struct {
+	mu    sync.RWMutex
+	Users map[string]*models.User // Users keyed by email.
+
+	Events   *EventRepository   // Events moved along with a user by MigrateUserEmail, if set.
+	Journals *JournalRepository // Journals moved along with a user by MigrateUserEmail, if set.
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewUserRepository creates an empty UserRepository.
+func NewUserRepository() *UserRepository {
+	return &UserRepository{Users: make(map[string]*models.User)}
+}
+
+// copyUser returns a copy of user.
+func copyUser(user *models.User) *models.User {
+	found := *user
+	return &found
+}
+
+// usernameLower returns the lowercase username of user, also for users stored without UsernameLower.
+func usernameLower(user *models.User) string {
+	if user.UsernameLower != "" {
+		return user.UsernameLower
+	}
+	return strings.ToLower(user.Username)
+}
+
+// GetUserByEmail retrieves a user by email.
+func (ur *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+	if ur.Err != nil {
+		return nil, ur.Err
+	}
+	if user, exists := ur.Users[email]; exists {
+		return copyUser(user), nil
+	}
+	return nil, fmt.Errorf("user %w", repositories.ErrNotFound)
+}
+
+// GetUserByUsername retrieves a user by username, ignoring case.
+func (ur *UserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+	if ur.Err != nil {
+		return nil, ur.Err
+	}
+	for _, user := range ur.Users {
+		if usernameLower(user) == strings.ToLower(username) {
+			return copyUser(user), nil
+		}
+	}
+	return nil, fmt.Errorf("user %w", repositories.ErrNotFound)
+}
+
+// GetUserByFeedToken retrieves the user whose calendar feed has the token.
+func (ur *UserRepository) GetUserByFeedToken(ctx context.Context, token string) (*models.User, error) {
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+	if ur.Err != nil {
+		return nil, ur.Err
+	}
+	for _, user := range ur.Users {
+		if token != "" && user.FeedToken == token {
+			return copyUser(user), nil
+		}
+	}
+	return nil, fmt.Errorf("user %w", repositories.ErrNotFound)
+}
+
+// CreateUser stores a copy of a new user, failing if a user with the email exists.
+func (ur *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	if ur.Err != nil {
+		return ur.Err
+	}
+	if _, exists := ur.Users[user.Email]; exists {
+		return fmt.Errorf("user already exists")
+	}
+	ur.Users[user.Email] = copyUser(user)
+	return nil
+}
+
+// ReplaceUser overwrites an existing user with a copy of user.
+func (ur *UserRepository) ReplaceUser(ctx context.Context, user *models.User) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	if ur.Err != nil {
+		return ur.Err
+	}
+	if _, exists := ur.Users[user.Email]; !exists {
+		return fmt.Errorf("user %w", repositories.ErrNotFound)
+	}
+	ur.Users[user.Email] = copyUser(user)
+	return nil
+}
+
+// UpdateUser updates the fields of a user, keyed by their Go field names. A nil value clears a field.
+func (ur *UserRepository) UpdateUser(ctx context.Context, email string, updates map[string]interface{}) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	if ur.Err != nil {
+		return ur.Err
+	}
+	user, exists := ur.Users[email]
+	if !exists {
+		return fmt.Errorf("user %w", repositories.ErrNotFound)
+	}
+	updated := copyUser(user)
+	if err := setFields(updated, updates); err != nil {
+		return fmt.Errorf("Failed to update user: %w", err)
+	}
+	*user = *updated
+	return nil
+}
+
+// IncrementUserField adds one to the integer field of a user, keyed by its Go field name, and returns the new value.
+func (ur *UserRepository) IncrementUserField(ctx context.Context, email, field string) (int, error) {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	if ur.Err != nil {
+		return 0, ur.Err
+	}
+	user, exists := ur.Users[email]
+	if !exists {
+		return 0, fmt.Errorf("user %w", repositories.ErrNotFound)
+	}
+	counter := reflect.ValueOf(user).Elem().FieldByName(field)
+	if counter.Kind() != reflect.Int {
+		return 0, fmt.Errorf("Failed to update user: %s is not a counter", field)
+	}
+	counter.SetInt(counter.Int() + 1)
+	return int(counter.Int()), nil
+}
+
+// SearchUsers searches for users whose username, first name or last name starts with query, ignoring case.
+// It returns up to limit matches per field, username matches first, with each user once.
+func (ur *UserRepository) SearchUsers(ctx context.Context, query string, limit int) ([]*models.User, error) {
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+	if ur.Err != nil {
+		return nil, ur.Err
+	}
+	prefix := strings.ToLower(query)
+	fields := []func(user *models.User) string{
+		usernameLower,
+		func(user *models.User) string { return user.FirstNameLower },
+		func(user *models.User) string { return user.LastNameLower },
+	}
+
+	seen := make(map[string]bool)
+	var users []*models.User
+	for _, field := range fields {
+		var matches []*models.User
+		for _, user := range ur.Users {
+			if value := field(user); value != "" && strings.HasPrefix(value, prefix) {
+				matches = append(matches, user)
+			}
+		}
+		sort.Slice(matches, func(i, j int) bool {
+			if field(matches[i]) != field(matches[j]) {
+				return field(matches[i]) < field(matches[j])
+			}
+			return matches[i].Email < matches[j].Email
+		})
+		if len(matches) > limit {
+			matches = matches[:limit]
+		}
+		for _, user := range matches {
+			if !seen[user.Email] {
+				seen[user.Email] = true
+				users = append(users, copyUser(user))
+			}
+		}
+	}
+	return users, nil
+}
+
+// MigrateUserEmail moves a user from oldEmail to newEmail, together with their events and journals
+// in Events and Journals, failing if a user with newEmail exists.
+func (ur *UserRepository) MigrateUserEmail(ctx context.Context, oldEmail, newEmail string) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	if ur.Err != nil {
+		return ur.Err
+	}
+	user, exists := ur.Users[oldEmail]
+	if !exists {
+		return fmt.Errorf("user %w", repositories.ErrNotFound)
+	}
+	if existing, exists := ur.Users[newEmail]; exists && existing.MovedFrom != oldEmail {
+		return fmt.Errorf("user already exists")
+	}
+
+	if ur.Events != nil {
+		ur.Events.migrateEmail(oldEmail, newEmail)
+	}
+	if ur.Journals != nil {
+		ur.Journals.migrateEmail(oldEmail, newEmail)
+	}
+	delete(ur.Users, oldEmail)
+	user.Email, user.MovedFrom = newEmail, ""
+	ur.Users[newEmail] = user
+	return nil
+}
+
+// GetDigestSubscribers retrieves the users with DigestEnabled set, ordered by email.
+func (ur *UserRepository) GetDigestSubscribers(ctx context.Context) ([]*models.User, error) {
+	return ur.usersWith(func(user *models.User) bool { return user.DigestEnabled })
+}
+
+// GetJournalReminderSubscribers retrieves the users with ReminderEnabled set, ordered by email.
+func (ur *UserRepository) GetJournalReminderSubscribers(ctx context.Context) ([]*models.User, error) {
+	return ur.usersWith(func(user *models.User) bool { return user.ReminderEnabled })
+}
+
+// GetUnnormalizedUserEmails retrieves the emails that utils.NormalizeEmail would change, in order.
+func (ur *UserRepository) GetUnnormalizedUserEmails(ctx context.Context) ([]string, error) {
+	users, err := ur.usersWith(func(user *models.User) bool { return user.Email != utils.NormalizeEmail(user.Email) })
+	if err != nil {
+		return nil, err
+	}
+	emails := make([]string, len(users))
+	for i, user := range users {
+		emails[i] = user.Email
+	}
+	return emails, nil
+}
+
+// usersWith retrieves the users for which enabled returns true, ordered by email.
+func (ur *UserRepository) usersWith(enabled func(user *models.User) bool) ([]*models.User, error) {
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+	if ur.Err != nil {
+		return nil, ur.Err
+	}
+	var users []*models.User
+	for _, user := range ur.Users {
+		if enabled(user) {
+			users = append(users, copyUser(user))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+	return users, nil
+}
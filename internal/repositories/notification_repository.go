@@ -0,0 +1,54 @@
+/**
+ *  NotificationRepository defines the interface for storing the notifications in a user's inbox,
+ *  such as incoming friend requests, so users can see what they missed while offline.
+ *
+ *  @interface NotificationRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - CreateNotification(ctx, notification)        - Stores a notification and sets its ID.
+ *  - ListNotifications(ctx, userEmail, unreadOnly, limit) - Retrieves a user's notifications, newest first.
+ *  - MarkRead(ctx, userEmail, notificationID)     - Marks a single notification as read.
+ *  - MarkAllRead(ctx, userEmail)                  - Marks every notification of a user as read.
+ *
+ *  @dependencies
+ *  - models.Notification: Defines the structure of a notification.
+ *  - context.Context: Manages request-scoped values, deadlines, and cancellations.
+ *
+ *  @file      notification_repository.go
+ *  @project   DailyVerse
+ *  @framework Go Interface for Repository Pattern
+ *  @purpose   Database operations abstraction for notifications.
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"proh2052-group6/pkg/models"
+)
+
+// ErrNotificationNotFound is returned by MarkRead when the user has no notification with the given ID.
+var ErrNotificationNotFound = fmt.Errorf("notification %w", ErrNotFound)
+
+// NotificationRepository defines the interface for notification-related data operations.
+type NotificationRepository interface {
+	// CreateNotification stores a notification for notification.Email and sets notification.ID.
+	CreateNotification(ctx context.Context, notification *models.Notification) error
+
+	// ListNotifications retrieves up to limit of a user's notifications, newest first.
+	// If unreadOnly is true, notifications that have been read are left out.
+	ListNotifications(ctx context.Context, userEmail string, unreadOnly bool, limit int) ([]models.Notification, error)
+
+	// MarkRead marks a notification as read. It returns ErrNotificationNotFound if it does not exist.
+	MarkRead(ctx context.Context, userEmail, notificationID string) error
+
+	// MarkAllRead marks every unread notification of a user as read.
+	MarkAllRead(ctx context.Context, userEmail string) error
+}
@@ -0,0 +1,46 @@
+/**
+ *  PendingDeliveryRepository defines the interface for storing the notification emails held back
+ *  during their recipients' quiet hours, until they can be sent.
+ *
+ *  @interface PendingDeliveryRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - CreatePendingDelivery(ctx, delivery)        - Stores a held back email and sets its ID.
+ *  - GetDuePendingDeliveries(ctx, now, limit)    - Retrieves the emails due for delivery, oldest first.
+ *  - DeletePendingDelivery(ctx, deliveryID)      - Deletes an email once it has been delivered.
+ *
+ *  @dependencies
+ *  - models.PendingDelivery: Defines the structure of a held back email.
+ *  - context.Context: Manages request-scoped values, deadlines, and cancellations.
+ *
+ *  @file      pending_delivery_repository.go
+ *  @project   DailyVerse
+ *  @framework Go Interface for Repository Pattern
+ *  @purpose   Database operations abstraction for emails held back during quiet hours.
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"proh2052-group6/pkg/models"
+	"time"
+)
+
+// PendingDeliveryRepository defines the interface for pending delivery data operations.
+type PendingDeliveryRepository interface {
+	// CreatePendingDelivery stores an email to send at delivery.DeliverAt and sets delivery.ID.
+	CreatePendingDelivery(ctx context.Context, delivery *models.PendingDelivery) error
+
+	// GetDuePendingDeliveries retrieves up to limit emails whose DeliverAt is not after now, oldest first.
+	GetDuePendingDeliveries(ctx context.Context, now time.Time, limit int) ([]models.PendingDelivery, error)
+
+	// DeletePendingDelivery deletes an email. Deleting an email that does not exist succeeds.
+	DeletePendingDelivery(ctx context.Context, deliveryID string) error
+}
@@ -0,0 +1,43 @@
+/**
+ *  PromptRepository defines the interface for storing how many journal prompts each user has
+ *  skipped, which moves them along the daily rotation of prompts.
+ *
+ *  @interface PromptRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - GetPromptOffset(ctx, userEmail)            - Retrieves a user's prompt offset.
+ *  - IncrementPromptOffset(ctx, userEmail, now) - Adds one to a user's prompt offset and returns it.
+ *
+ *  @dependencies
+ *  - models.PromptOffset: Defines the structure of a user's prompt offset.
+ *  - context.Context: Manages request-scoped values, deadlines, and cancellations.
+ *
+ *  @file      prompt_repository.go
+ *  @project   DailyVerse
+ *  @framework Go Interface for Repository Pattern
+ *  @purpose   Database operations abstraction for journal prompt offsets.
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"proh2052-group6/pkg/models"
+	"time"
+)
+
+// PromptRepository defines the interface for journal prompt offset operations.
+type PromptRepository interface {
+	// GetPromptOffset retrieves a user's prompt offset. Users who never skipped a prompt have an offset of 0.
+	GetPromptOffset(ctx context.Context, userEmail string) (*models.PromptOffset, error)
+
+	// IncrementPromptOffset adds one to a user's prompt offset, recording now as the time of the
+	// skip, and returns the new offset. Concurrent increments are all counted.
+	IncrementPromptOffset(ctx context.Context, userEmail string, now time.Time) (*models.PromptOffset, error)
+}
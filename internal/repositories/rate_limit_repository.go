@@ -0,0 +1,44 @@
+/**
+ *  RateLimitRepository defines the interface for persisting the token buckets of the rate limiters,
+ *  so the limits survive a restart of the server.
+ *
+ *  @interface RateLimitRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - GetRateLimitBucket(ctx, key)        - Retrieves the bucket of a limiter's client.
+ *  - SaveRateLimitBuckets(ctx, buckets)  - Stores several buckets, replacing earlier versions.
+ *
+ *  @errors
+ *  - ErrNotFound: Returned, wrapped, by GetRateLimitBucket when no bucket is stored under the key.
+ *
+ *  @dependencies
+ *  - models.RateLimitBucket: Defines the structure of a token bucket.
+ *  - context.Context: Manages request-scoped values, deadlines, and cancellations.
+ *
+ *  @file      rate_limit_repository.go
+ *  @project   DailyVerse
+ *  @framework Go Interface for Repository Pattern
+ *  @purpose   Database operations abstraction for rate limit state.
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"proh2052-group6/pkg/models"
+)
+
+// RateLimitRepository defines the interface for rate limit bucket data operations.
+type RateLimitRepository interface {
+	// GetRateLimitBucket retrieves the bucket stored under key.
+	GetRateLimitBucket(ctx context.Context, key string) (*models.RateLimitBucket, error)
+
+	// SaveRateLimitBuckets stores buckets under their keys, replacing the buckets stored before.
+	SaveRateLimitBuckets(ctx context.Context, buckets []models.RateLimitBucket) error
+}
@@ -0,0 +1,53 @@
+/**
+ *  ShareRepository defines the interface for storing read-only links to events, which let people
+ *  without an account see a single event.
+ *
+ *  @interface ShareRepository
+ *  @inherits None
+ *
+ *  @methods
+ *  - CreateEventShare(ctx, token, share)     - Stores a link under its token.
+ *  - GetEventShare(ctx, token)               - Retrieves the link with a token.
+ *  - DeleteEventShares(ctx, email, eventID)  - Deletes every link to an event.
+ *  - MigrateShareEmail(ctx, oldEmail, newEmail) - Rewrites the owner of links after an email change.
+ *
+ *  @errors
+ *  - ErrNotFound: Returned, wrapped, by GetEventShare when no link has the token.
+ *
+ *  @dependencies
+ *  - models.EventShare: Defines the structure of a link.
+ *  - context.Context: Manages request-scoped values, deadlines, and cancellations.
+ *
+ *  @file      share_repository.go
+ *  @project   DailyVerse
+ *  @framework Go Interface for Repository Pattern
+ *  @purpose   Database operations abstraction for shared event links.
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"proh2052-group6/pkg/models"
+)
+
+// ShareRepository defines the interface for shared event link data operations.
+type ShareRepository interface {
+	// CreateEventShare stores share as the link with token.
+	CreateEventShare(ctx context.Context, token string, share *models.EventShare) error
+
+	// GetEventShare retrieves the link with token.
+	GetEventShare(ctx context.Context, token string) (*models.EventShare, error)
+
+	// DeleteEventShares deletes every link to the event eventID of email.
+	DeleteEventShares(ctx context.Context, email, eventID string) error
+
+	// MigrateShareEmail replaces oldEmail with newEmail as the owner of every link. Running it again
+	// after a failure finishes the migration.
+	MigrateShareEmail(ctx context.Context, oldEmail, newEmail string) error
+}
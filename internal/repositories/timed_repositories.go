@@ -0,0 +1,669 @@
+/**
+ *  Timed repositories wrap the repository interfaces and report how long every call took and
+ *  whether it failed, so the latency of the database can be monitored without each
+ *  implementation measuring itself.
+ *
+ *  @interface OperationObserver
+ *
+ *  @methods
+ *  - NewTimedUserRepository(repo, observer)         - Wraps a UserRepository.
+ *  - NewTimedEventRepository(repo, observer)        - Wraps an EventRepository.
+ *  - NewTimedJournalRepository(repo, observer)      - Wraps a JournalRepository.
+ *  - NewTimedFriendRepository(repo, observer)       - Wraps a FriendRepository.
+ *  - NewTimedInvitationRepository(repo, observer)   - Wraps an InvitationRepository.
+ *  - NewTimedNotificationRepository(repo, observer) - Wraps a NotificationRepository.
+ *  - NewTimedIdempotencyRepository(repo, observer)  - Wraps an IdempotencyRepository.
+ *  - NewTimedFavoriteRepository(repo, observer)     - Wraps a FavoriteRepository.
+ *  - NewTimedAuditRepository(repo, observer)        - Wraps an AuditRepository.
+ *  - NewTimedFriendInvitationRepository(repo, observer) - Wraps a FriendInvitationRepository.
+ *  - NewTimedRateLimitRepository(repo, observer)    - Wraps a RateLimitRepository.
+ *  - NewTimedShareRepository(repo, observer)        - Wraps a ShareRepository.
+ *  - NewTimedPromptRepository(repo, observer)       - Wraps a PromptRepository.
+ *  - NewTimedPendingDeliveryRepository(repo, observer) - Wraps a PendingDeliveryRepository.
+ *
+ *  @behaviors
+ *  - Calls are passed through unchanged; the observer is told the repository and method name,
+ *    the duration and the returned error after each call.
+ *  - StreamJournals is timed until the stream ends, including the time spent in the callback.
+ *  - Bulk writes that report an error per item are observed with the errors joined.
+ *
+ *  @example
+ *  ```
+ *  userRepository := repositories.NewTimedUserRepository(repositories.NewFirestoreUserRepository(dbClient), appMetrics)
+ *  ```
+ *
+ *  @file      timed_repositories.go
+ *  @project   DailyVerse
+ *  @framework Database Agnostic (e.g., Firestore, SQL, etc.)
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"proh2052-group6/pkg/models"
+)
+
+// OperationObserver is told the duration and outcome of every call to a timed repository.
+type OperationObserver interface {
+	// ObserveOperation records a call to the named method of the named repository interface,
+	// e.g. "UserRepository" and "GetUserByEmail". err is nil if the call succeeded.
+	ObserveOperation(repository, operation string, duration time.Duration, err error)
+}
+
+// observe reports a call that started at start and returned *err. It is deferred by every
+// timed method, so the error is read after the call has returned.
+func observe(observer OperationObserver, repository, operation string, start time.Time, err *error) {
+	observer.ObserveOperation(repository, operation, time.Since(start), *err)
+}
+
+// timedUserRepository reports the duration of every UserRepository call to an OperationObserver.
+type timedUserRepository struct {
+	repo     UserRepository
+	observer OperationObserver
+}
+
+// NewTimedUserRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedUserRepository(repo UserRepository, observer OperationObserver) UserRepository {
+	return &timedUserRepository{repo: repo, observer: observer}
+}
+
+func (r *timedUserRepository) GetUserByEmail(ctx context.Context, email string) (_ *models.User, err error) {
+	defer observe(r.observer, "UserRepository", "GetUserByEmail", time.Now(), &err)
+	return r.repo.GetUserByEmail(ctx, email)
+}
+
+func (r *timedUserRepository) GetUserByUsername(ctx context.Context, username string) (_ *models.User, err error) {
+	defer observe(r.observer, "UserRepository", "GetUserByUsername", time.Now(), &err)
+	return r.repo.GetUserByUsername(ctx, username)
+}
+
+func (r *timedUserRepository) GetUserByFeedToken(ctx context.Context, token string) (_ *models.User, err error) {
+	defer observe(r.observer, "UserRepository", "GetUserByFeedToken", time.Now(), &err)
+	return r.repo.GetUserByFeedToken(ctx, token)
+}
+
+func (r *timedUserRepository) CreateUser(ctx context.Context, user *models.User) (err error) {
+	defer observe(r.observer, "UserRepository", "CreateUser", time.Now(), &err)
+	return r.repo.CreateUser(ctx, user)
+}
+
+func (r *timedUserRepository) ReplaceUser(ctx context.Context, user *models.User) (err error) {
+	defer observe(r.observer, "UserRepository", "ReplaceUser", time.Now(), &err)
+	return r.repo.ReplaceUser(ctx, user)
+}
+
+func (r *timedUserRepository) UpdateUser(ctx context.Context, email string, updates map[string]interface{}) (err error) {
+	defer observe(r.observer, "UserRepository", "UpdateUser", time.Now(), &err)
+	return r.repo.UpdateUser(ctx, email, updates)
+}
+
+func (r *timedUserRepository) IncrementUserField(ctx context.Context, email, field string) (_ int, err error) {
+	defer observe(r.observer, "UserRepository", "IncrementUserField", time.Now(), &err)
+	return r.repo.IncrementUserField(ctx, email, field)
+}
+
+func (r *timedUserRepository) SearchUsers(ctx context.Context, query string, limit int) (_ []*models.User, err error) {
+	defer observe(r.observer, "UserRepository", "SearchUsers", time.Now(), &err)
+	return r.repo.SearchUsers(ctx, query, limit)
+}
+
+func (r *timedUserRepository) MigrateUserEmail(ctx context.Context, oldEmail, newEmail string) (err error) {
+	defer observe(r.observer, "UserRepository", "MigrateUserEmail", time.Now(), &err)
+	return r.repo.MigrateUserEmail(ctx, oldEmail, newEmail)
+}
+
+func (r *timedUserRepository) GetDigestSubscribers(ctx context.Context) (_ []*models.User, err error) {
+	defer observe(r.observer, "UserRepository", "GetDigestSubscribers", time.Now(), &err)
+	return r.repo.GetDigestSubscribers(ctx)
+}
+
+func (r *timedUserRepository) GetJournalReminderSubscribers(ctx context.Context) (_ []*models.User, err error) {
+	defer observe(r.observer, "UserRepository", "GetJournalReminderSubscribers", time.Now(), &err)
+	return r.repo.GetJournalReminderSubscribers(ctx)
+}
+
+func (r *timedUserRepository) GetUnnormalizedUserEmails(ctx context.Context) (_ []string, err error) {
+	defer observe(r.observer, "UserRepository", "GetUnnormalizedUserEmails", time.Now(), &err)
+	return r.repo.GetUnnormalizedUserEmails(ctx)
+}
+
+// timedEventRepository reports the duration of every EventRepository call to an OperationObserver.
+type timedEventRepository struct {
+	repo     EventRepository
+	observer OperationObserver
+}
+
+// NewTimedEventRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedEventRepository(repo EventRepository, observer OperationObserver) EventRepository {
+	return &timedEventRepository{repo: repo, observer: observer}
+}
+
+func (r *timedEventRepository) CreateEvent(ctx context.Context, event *models.Event) (err error) {
+	defer observe(r.observer, "EventRepository", "CreateEvent", time.Now(), &err)
+	return r.repo.CreateEvent(ctx, event)
+}
+
+func (r *timedEventRepository) GetEvent(ctx context.Context, userEmail, eventID string) (_ *models.Event, err error) {
+	defer observe(r.observer, "EventRepository", "GetEvent", time.Now(), &err)
+	return r.repo.GetEvent(ctx, userEmail, eventID)
+}
+
+func (r *timedEventRepository) UpdateEvent(ctx context.Context, event *models.Event) (err error) {
+	defer observe(r.observer, "EventRepository", "UpdateEvent", time.Now(), &err)
+	return r.repo.UpdateEvent(ctx, event)
+}
+
+func (r *timedEventRepository) UpdateEventFields(ctx context.Context, userEmail, eventID string, updates map[string]interface{}) (err error) {
+	defer observe(r.observer, "EventRepository", "UpdateEventFields", time.Now(), &err)
+	return r.repo.UpdateEventFields(ctx, userEmail, eventID, updates)
+}
+
+func (r *timedEventRepository) DeleteEvent(ctx context.Context, userEmail, eventID string) (err error) {
+	defer observe(r.observer, "EventRepository", "DeleteEvent", time.Now(), &err)
+	return r.repo.DeleteEvent(ctx, userEmail, eventID)
+}
+
+func (r *timedEventRepository) GetAllEvents(ctx context.Context, userEmail string, query models.EventQuery) (_ *models.EventPage, err error) {
+	defer observe(r.observer, "EventRepository", "GetAllEvents", time.Now(), &err)
+	return r.repo.GetAllEvents(ctx, userEmail, query)
+}
+
+func (r *timedEventRepository) GetEventsBetween(ctx context.Context, start, end time.Time) (_ []models.Event, err error) {
+	defer observe(r.observer, "EventRepository", "GetEventsBetween", time.Now(), &err)
+	return r.repo.GetEventsBetween(ctx, start, end)
+}
+
+func (r *timedEventRepository) GetEventByExternalID(ctx context.Context, userEmail, externalID string) (_ *models.Event, err error) {
+	defer observe(r.observer, "EventRepository", "GetEventByExternalID", time.Now(), &err)
+	return r.repo.GetEventByExternalID(ctx, userEmail, externalID)
+}
+
+func (r *timedEventRepository) GetRecurringEvents(ctx context.Context, userEmail string) (_ []models.Event, err error) {
+	defer observe(r.observer, "EventRepository", "GetRecurringEvents", time.Now(), &err)
+	return r.repo.GetRecurringEvents(ctx, userEmail)
+}
+
+func (r *timedEventRepository) GetEvents(ctx context.Context, userEmail string, eventIDs []string) (_ []*models.Event, err error) {
+	defer observe(r.observer, "EventRepository", "GetEvents", time.Now(), &err)
+	return r.repo.GetEvents(ctx, userEmail, eventIDs)
+}
+
+func (r *timedEventRepository) CreateEvents(ctx context.Context, events []*models.Event) []error {
+	var err error
+	defer observe(r.observer, "EventRepository", "CreateEvents", time.Now(), &err)
+	errs := r.repo.CreateEvents(ctx, events)
+	err = errors.Join(errs...)
+	return errs
+}
+
+func (r *timedEventRepository) DeleteEvents(ctx context.Context, userEmail string, eventIDs []string) []error {
+	var err error
+	defer observe(r.observer, "EventRepository", "DeleteEvents", time.Now(), &err)
+	errs := r.repo.DeleteEvents(ctx, userEmail, eventIDs)
+	err = errors.Join(errs...)
+	return errs
+}
+
+func (r *timedEventRepository) DeleteEventsByBatch(ctx context.Context, userEmail, batchID string) (_ int, err error) {
+	defer observe(r.observer, "EventRepository", "DeleteEventsByBatch", time.Now(), &err)
+	return r.repo.DeleteEventsByBatch(ctx, userEmail, batchID)
+}
+
+func (r *timedEventRepository) GetImportBatches(ctx context.Context, userEmail string) (_ []models.ImportBatch, err error) {
+	defer observe(r.observer, "EventRepository", "GetImportBatches", time.Now(), &err)
+	return r.repo.GetImportBatches(ctx, userEmail)
+}
+
+func (r *timedEventRepository) CountEvents(ctx context.Context, userEmail string, limit int) (_ int, err error) {
+	defer observe(r.observer, "EventRepository", "CountEvents", time.Now(), &err)
+	return r.repo.CountEvents(ctx, userEmail, limit)
+}
+
+// timedJournalRepository reports the duration of every JournalRepository call to an OperationObserver.
+type timedJournalRepository struct {
+	repo     JournalRepository
+	observer OperationObserver
+}
+
+// NewTimedJournalRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedJournalRepository(repo JournalRepository, observer OperationObserver) JournalRepository {
+	return &timedJournalRepository{repo: repo, observer: observer}
+}
+
+func (r *timedJournalRepository) CreateJournal(ctx context.Context, journal *models.Journal) (err error) {
+	defer observe(r.observer, "JournalRepository", "CreateJournal", time.Now(), &err)
+	return r.repo.CreateJournal(ctx, journal)
+}
+
+func (r *timedJournalRepository) GetJournal(ctx context.Context, userEmail, journalID string) (_ *models.Journal, err error) {
+	defer observe(r.observer, "JournalRepository", "GetJournal", time.Now(), &err)
+	return r.repo.GetJournal(ctx, userEmail, journalID)
+}
+
+func (r *timedJournalRepository) GetJournalByDate(ctx context.Context, userEmail, date string) (_ *models.Journal, err error) {
+	defer observe(r.observer, "JournalRepository", "GetJournalByDate", time.Now(), &err)
+	return r.repo.GetJournalByDate(ctx, userEmail, date)
+}
+
+func (r *timedJournalRepository) UpdateJournal(ctx context.Context, journal *models.Journal) (err error) {
+	defer observe(r.observer, "JournalRepository", "UpdateJournal", time.Now(), &err)
+	return r.repo.UpdateJournal(ctx, journal)
+}
+
+func (r *timedJournalRepository) DeleteJournal(ctx context.Context, userEmail, journalID string) (err error) {
+	defer observe(r.observer, "JournalRepository", "DeleteJournal", time.Now(), &err)
+	return r.repo.DeleteJournal(ctx, userEmail, journalID)
+}
+
+func (r *timedJournalRepository) GetAllJournals(ctx context.Context, userEmail string) (_ []models.Journal, err error) {
+	defer observe(r.observer, "JournalRepository", "GetAllJournals", time.Now(), &err)
+	return r.repo.GetAllJournals(ctx, userEmail)
+}
+
+func (r *timedJournalRepository) SearchJournals(ctx context.Context, userEmail, query, from, to string, limit int) (_ []models.Journal, err error) {
+	defer observe(r.observer, "JournalRepository", "SearchJournals", time.Now(), &err)
+	return r.repo.SearchJournals(ctx, userEmail, query, from, to, limit)
+}
+
+func (r *timedJournalRepository) StreamJournals(ctx context.Context, userEmail, from, to string, fn func(models.Journal) error) (err error) {
+	defer observe(r.observer, "JournalRepository", "StreamJournals", time.Now(), &err)
+	return r.repo.StreamJournals(ctx, userEmail, from, to, fn)
+}
+
+func (r *timedJournalRepository) GetDeletedJournals(ctx context.Context, userEmail string) (_ []models.Journal, err error) {
+	defer observe(r.observer, "JournalRepository", "GetDeletedJournals", time.Now(), &err)
+	return r.repo.GetDeletedJournals(ctx, userEmail)
+}
+
+func (r *timedJournalRepository) PurgeDeletedJournals(ctx context.Context, deletedBefore time.Time) (_ []models.Journal, err error) {
+	defer observe(r.observer, "JournalRepository", "PurgeDeletedJournals", time.Now(), &err)
+	return r.repo.PurgeDeletedJournals(ctx, deletedBefore)
+}
+
+func (r *timedJournalRepository) CountJournals(ctx context.Context, userEmail string, limit int) (_ int, err error) {
+	defer observe(r.observer, "JournalRepository", "CountJournals", time.Now(), &err)
+	return r.repo.CountJournals(ctx, userEmail, limit)
+}
+
+func (r *timedJournalRepository) SaveJournals(ctx context.Context, journals []*models.Journal) []error {
+	var err error
+	defer observe(r.observer, "JournalRepository", "SaveJournals", time.Now(), &err)
+	errs := r.repo.SaveJournals(ctx, journals)
+	err = errors.Join(errs...)
+	return errs
+}
+
+// timedFriendRepository reports the duration of every FriendRepository call to an OperationObserver.
+type timedFriendRepository struct {
+	repo     FriendRepository
+	observer OperationObserver
+}
+
+// NewTimedFriendRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedFriendRepository(repo FriendRepository, observer OperationObserver) FriendRepository {
+	return &timedFriendRepository{repo: repo, observer: observer}
+}
+
+func (r *timedFriendRepository) CreateFriendRequest(ctx context.Context, friend *models.Friend) (err error) {
+	defer observe(r.observer, "FriendRepository", "CreateFriendRequest", time.Now(), &err)
+	return r.repo.CreateFriendRequest(ctx, friend)
+}
+
+func (r *timedFriendRepository) GetFriendRequest(ctx context.Context, senderEmail, recipientEmail string) (_ *models.Friend, err error) {
+	defer observe(r.observer, "FriendRepository", "GetFriendRequest", time.Now(), &err)
+	return r.repo.GetFriendRequest(ctx, senderEmail, recipientEmail)
+}
+
+func (r *timedFriendRepository) UpdateFriendRequest(ctx context.Context, senderEmail, recipientEmail string, updates map[string]interface{}) (err error) {
+	defer observe(r.observer, "FriendRepository", "UpdateFriendRequest", time.Now(), &err)
+	return r.repo.UpdateFriendRequest(ctx, senderEmail, recipientEmail, updates)
+}
+
+func (r *timedFriendRepository) AcceptFriendRequest(ctx context.Context, senderEmail, recipientEmail string) (err error) {
+	defer observe(r.observer, "FriendRepository", "AcceptFriendRequest", time.Now(), &err)
+	return r.repo.AcceptFriendRequest(ctx, senderEmail, recipientEmail)
+}
+
+func (r *timedFriendRepository) DeleteFriendRequest(ctx context.Context, senderEmail, recipientEmail string) (err error) {
+	defer observe(r.observer, "FriendRepository", "DeleteFriendRequest", time.Now(), &err)
+	return r.repo.DeleteFriendRequest(ctx, senderEmail, recipientEmail)
+}
+
+func (r *timedFriendRepository) GetFriends(ctx context.Context, userEmail string) (_ []models.Friend, err error) {
+	defer observe(r.observer, "FriendRepository", "GetFriends", time.Now(), &err)
+	return r.repo.GetFriends(ctx, userEmail)
+}
+
+func (r *timedFriendRepository) GetPendingFriendRequests(ctx context.Context, userEmail string) (_ []models.Friend, err error) {
+	defer observe(r.observer, "FriendRepository", "GetPendingFriendRequests", time.Now(), &err)
+	return r.repo.GetPendingFriendRequests(ctx, userEmail)
+}
+
+func (r *timedFriendRepository) GetSentFriendRequests(ctx context.Context, userEmail string) (_ []models.Friend, err error) {
+	defer observe(r.observer, "FriendRepository", "GetSentFriendRequests", time.Now(), &err)
+	return r.repo.GetSentFriendRequests(ctx, userEmail)
+}
+
+func (r *timedFriendRepository) GetFriendsOfUsers(ctx context.Context, userEmails []string) (_ []models.Friend, err error) {
+	defer observe(r.observer, "FriendRepository", "GetFriendsOfUsers", time.Now(), &err)
+	return r.repo.GetFriendsOfUsers(ctx, userEmails)
+}
+
+func (r *timedFriendRepository) CreateBlock(ctx context.Context, block *models.Block) (err error) {
+	defer observe(r.observer, "FriendRepository", "CreateBlock", time.Now(), &err)
+	return r.repo.CreateBlock(ctx, block)
+}
+
+func (r *timedFriendRepository) GetBlock(ctx context.Context, blockerEmail, blockedEmail string) (_ *models.Block, err error) {
+	defer observe(r.observer, "FriendRepository", "GetBlock", time.Now(), &err)
+	return r.repo.GetBlock(ctx, blockerEmail, blockedEmail)
+}
+
+func (r *timedFriendRepository) DeleteBlock(ctx context.Context, blockerEmail, blockedEmail string) (err error) {
+	defer observe(r.observer, "FriendRepository", "DeleteBlock", time.Now(), &err)
+	return r.repo.DeleteBlock(ctx, blockerEmail, blockedEmail)
+}
+
+func (r *timedFriendRepository) GetBlockedUsers(ctx context.Context, blockerEmail string) (_ []models.Block, err error) {
+	defer observe(r.observer, "FriendRepository", "GetBlockedUsers", time.Now(), &err)
+	return r.repo.GetBlockedUsers(ctx, blockerEmail)
+}
+
+func (r *timedFriendRepository) MigrateFriendEmail(ctx context.Context, oldEmail, newEmail string) (err error) {
+	defer observe(r.observer, "FriendRepository", "MigrateFriendEmail", time.Now(), &err)
+	return r.repo.MigrateFriendEmail(ctx, oldEmail, newEmail)
+}
+
+func (r *timedFriendRepository) DeleteStaleFriendRequests(ctx context.Context, pendingBefore, declinedBefore time.Time) (_ int, err error) {
+	defer observe(r.observer, "FriendRepository", "DeleteStaleFriendRequests", time.Now(), &err)
+	return r.repo.DeleteStaleFriendRequests(ctx, pendingBefore, declinedBefore)
+}
+
+func (r *timedFriendRepository) CountFriends(ctx context.Context, userEmail string, limit int) (_ int, err error) {
+	defer observe(r.observer, "FriendRepository", "CountFriends", time.Now(), &err)
+	return r.repo.CountFriends(ctx, userEmail, limit)
+}
+
+// timedInvitationRepository reports the duration of every InvitationRepository call to an OperationObserver.
+type timedInvitationRepository struct {
+	repo     InvitationRepository
+	observer OperationObserver
+}
+
+// NewTimedInvitationRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedInvitationRepository(repo InvitationRepository, observer OperationObserver) InvitationRepository {
+	return &timedInvitationRepository{repo: repo, observer: observer}
+}
+
+func (r *timedInvitationRepository) CreateInvitation(ctx context.Context, invitation *models.EventInvitation) (err error) {
+	defer observe(r.observer, "InvitationRepository", "CreateInvitation", time.Now(), &err)
+	return r.repo.CreateInvitation(ctx, invitation)
+}
+
+func (r *timedInvitationRepository) GetInvitation(ctx context.Context, eventID, inviteeEmail string) (_ *models.EventInvitation, err error) {
+	defer observe(r.observer, "InvitationRepository", "GetInvitation", time.Now(), &err)
+	return r.repo.GetInvitation(ctx, eventID, inviteeEmail)
+}
+
+func (r *timedInvitationRepository) UpdateInvitation(ctx context.Context, eventID, inviteeEmail string, updates map[string]interface{}) (err error) {
+	defer observe(r.observer, "InvitationRepository", "UpdateInvitation", time.Now(), &err)
+	return r.repo.UpdateInvitation(ctx, eventID, inviteeEmail, updates)
+}
+
+func (r *timedInvitationRepository) GetInvitationsForUser(ctx context.Context, inviteeEmail string) (_ []models.EventInvitation, err error) {
+	defer observe(r.observer, "InvitationRepository", "GetInvitationsForUser", time.Now(), &err)
+	return r.repo.GetInvitationsForUser(ctx, inviteeEmail)
+}
+
+func (r *timedInvitationRepository) GetInvitationsForEvent(ctx context.Context, ownerEmail, eventID string) (_ []models.EventInvitation, err error) {
+	defer observe(r.observer, "InvitationRepository", "GetInvitationsForEvent", time.Now(), &err)
+	return r.repo.GetInvitationsForEvent(ctx, ownerEmail, eventID)
+}
+
+func (r *timedInvitationRepository) MigrateInvitationEmail(ctx context.Context, oldEmail, newEmail string) (err error) {
+	defer observe(r.observer, "InvitationRepository", "MigrateInvitationEmail", time.Now(), &err)
+	return r.repo.MigrateInvitationEmail(ctx, oldEmail, newEmail)
+}
+
+// timedNotificationRepository reports the duration of every NotificationRepository call to an OperationObserver.
+type timedNotificationRepository struct {
+	repo     NotificationRepository
+	observer OperationObserver
+}
+
+// NewTimedNotificationRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedNotificationRepository(repo NotificationRepository, observer OperationObserver) NotificationRepository {
+	return &timedNotificationRepository{repo: repo, observer: observer}
+}
+
+func (r *timedNotificationRepository) CreateNotification(ctx context.Context, notification *models.Notification) (err error) {
+	defer observe(r.observer, "NotificationRepository", "CreateNotification", time.Now(), &err)
+	return r.repo.CreateNotification(ctx, notification)
+}
+
+func (r *timedNotificationRepository) ListNotifications(ctx context.Context, userEmail string, unreadOnly bool, limit int) (_ []models.Notification, err error) {
+	defer observe(r.observer, "NotificationRepository", "ListNotifications", time.Now(), &err)
+	return r.repo.ListNotifications(ctx, userEmail, unreadOnly, limit)
+}
+
+func (r *timedNotificationRepository) MarkRead(ctx context.Context, userEmail, notificationID string) (err error) {
+	defer observe(r.observer, "NotificationRepository", "MarkRead", time.Now(), &err)
+	return r.repo.MarkRead(ctx, userEmail, notificationID)
+}
+
+func (r *timedNotificationRepository) MarkAllRead(ctx context.Context, userEmail string) (err error) {
+	defer observe(r.observer, "NotificationRepository", "MarkAllRead", time.Now(), &err)
+	return r.repo.MarkAllRead(ctx, userEmail)
+}
+
+// timedIdempotencyRepository reports the duration of every IdempotencyRepository call to an OperationObserver.
+type timedIdempotencyRepository struct {
+	repo     IdempotencyRepository
+	observer OperationObserver
+}
+
+// NewTimedIdempotencyRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedIdempotencyRepository(repo IdempotencyRepository, observer OperationObserver) IdempotencyRepository {
+	return &timedIdempotencyRepository{repo: repo, observer: observer}
+}
+
+func (r *timedIdempotencyRepository) CreateRecord(ctx context.Context, record *models.IdempotencyRecord) (err error) {
+	defer observe(r.observer, "IdempotencyRepository", "CreateRecord", time.Now(), &err)
+	return r.repo.CreateRecord(ctx, record)
+}
+
+func (r *timedIdempotencyRepository) GetRecord(ctx context.Context, userEmail, key string) (_ *models.IdempotencyRecord, err error) {
+	defer observe(r.observer, "IdempotencyRepository", "GetRecord", time.Now(), &err)
+	return r.repo.GetRecord(ctx, userEmail, key)
+}
+
+func (r *timedIdempotencyRepository) CompleteRecord(ctx context.Context, record *models.IdempotencyRecord) (err error) {
+	defer observe(r.observer, "IdempotencyRepository", "CompleteRecord", time.Now(), &err)
+	return r.repo.CompleteRecord(ctx, record)
+}
+
+func (r *timedIdempotencyRepository) DeleteRecord(ctx context.Context, userEmail, key string) (err error) {
+	defer observe(r.observer, "IdempotencyRepository", "DeleteRecord", time.Now(), &err)
+	return r.repo.DeleteRecord(ctx, userEmail, key)
+}
+
+// timedFavoriteRepository reports the duration of every FavoriteRepository call to an OperationObserver.
+type timedFavoriteRepository struct {
+	repo     FavoriteRepository
+	observer OperationObserver
+}
+
+// NewTimedFavoriteRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedFavoriteRepository(repo FavoriteRepository, observer OperationObserver) FavoriteRepository {
+	return &timedFavoriteRepository{repo: repo, observer: observer}
+}
+
+func (r *timedFavoriteRepository) SaveFavorite(ctx context.Context, favorite *models.FavoriteQuote) (err error) {
+	defer observe(r.observer, "FavoriteRepository", "SaveFavorite", time.Now(), &err)
+	return r.repo.SaveFavorite(ctx, favorite)
+}
+
+func (r *timedFavoriteRepository) GetFavorites(ctx context.Context, userEmail string) (_ []models.FavoriteQuote, err error) {
+	defer observe(r.observer, "FavoriteRepository", "GetFavorites", time.Now(), &err)
+	return r.repo.GetFavorites(ctx, userEmail)
+}
+
+// timedAuditRepository reports the duration of every AuditRepository call to an OperationObserver.
+type timedAuditRepository struct {
+	repo     AuditRepository
+	observer OperationObserver
+}
+
+// NewTimedAuditRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedAuditRepository(repo AuditRepository, observer OperationObserver) AuditRepository {
+	return &timedAuditRepository{repo: repo, observer: observer}
+}
+
+func (r *timedAuditRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) (err error) {
+	defer observe(r.observer, "AuditRepository", "CreateAuditEntry", time.Now(), &err)
+	return r.repo.CreateAuditEntry(ctx, entry)
+}
+
+func (r *timedAuditRepository) ListAuditEntries(ctx context.Context, userEmail string, limit int) (_ []models.AuditEntry, err error) {
+	defer observe(r.observer, "AuditRepository", "ListAuditEntries", time.Now(), &err)
+	return r.repo.ListAuditEntries(ctx, userEmail, limit)
+}
+
+// timedFriendInvitationRepository reports the duration of every FriendInvitationRepository call to an OperationObserver.
+type timedFriendInvitationRepository struct {
+	repo     FriendInvitationRepository
+	observer OperationObserver
+}
+
+// NewTimedFriendInvitationRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedFriendInvitationRepository(repo FriendInvitationRepository, observer OperationObserver) FriendInvitationRepository {
+	return &timedFriendInvitationRepository{repo: repo, observer: observer}
+}
+
+func (r *timedFriendInvitationRepository) CreateFriendInvitation(ctx context.Context, invitation *models.FriendInvitation) (err error) {
+	defer observe(r.observer, "FriendInvitationRepository", "CreateFriendInvitation", time.Now(), &err)
+	return r.repo.CreateFriendInvitation(ctx, invitation)
+}
+
+func (r *timedFriendInvitationRepository) GetFriendInvitation(ctx context.Context, inviteeEmail, inviterEmail string) (_ *models.FriendInvitation, err error) {
+	defer observe(r.observer, "FriendInvitationRepository", "GetFriendInvitation", time.Now(), &err)
+	return r.repo.GetFriendInvitation(ctx, inviteeEmail, inviterEmail)
+}
+
+func (r *timedFriendInvitationRepository) GetPendingFriendInvitations(ctx context.Context, inviteeEmail string) (_ []models.FriendInvitation, err error) {
+	defer observe(r.observer, "FriendInvitationRepository", "GetPendingFriendInvitations", time.Now(), &err)
+	return r.repo.GetPendingFriendInvitations(ctx, inviteeEmail)
+}
+
+func (r *timedFriendInvitationRepository) ConsumeFriendInvitation(ctx context.Context, inviteeEmail, inviterEmail string, consumedAt time.Time) (err error) {
+	defer observe(r.observer, "FriendInvitationRepository", "ConsumeFriendInvitation", time.Now(), &err)
+	return r.repo.ConsumeFriendInvitation(ctx, inviteeEmail, inviterEmail, consumedAt)
+}
+
+// timedRateLimitRepository reports the duration of every RateLimitRepository call to an OperationObserver.
+type timedRateLimitRepository struct {
+	repo     RateLimitRepository
+	observer OperationObserver
+}
+
+// NewTimedRateLimitRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedRateLimitRepository(repo RateLimitRepository, observer OperationObserver) RateLimitRepository {
+	return &timedRateLimitRepository{repo: repo, observer: observer}
+}
+
+func (r *timedRateLimitRepository) GetRateLimitBucket(ctx context.Context, key string) (_ *models.RateLimitBucket, err error) {
+	defer observe(r.observer, "RateLimitRepository", "GetRateLimitBucket", time.Now(), &err)
+	return r.repo.GetRateLimitBucket(ctx, key)
+}
+
+func (r *timedRateLimitRepository) SaveRateLimitBuckets(ctx context.Context, buckets []models.RateLimitBucket) (err error) {
+	defer observe(r.observer, "RateLimitRepository", "SaveRateLimitBuckets", time.Now(), &err)
+	return r.repo.SaveRateLimitBuckets(ctx, buckets)
+}
+
+// timedShareRepository reports the duration of every ShareRepository call to an OperationObserver.
+type timedShareRepository struct {
+	repo     ShareRepository
+	observer OperationObserver
+}
+
+// NewTimedShareRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedShareRepository(repo ShareRepository, observer OperationObserver) ShareRepository {
+	return &timedShareRepository{repo: repo, observer: observer}
+}
+
+func (r *timedShareRepository) CreateEventShare(ctx context.Context, token string, share *models.EventShare) (err error) {
+	defer observe(r.observer, "ShareRepository", "CreateEventShare", time.Now(), &err)
+	return r.repo.CreateEventShare(ctx, token, share)
+}
+
+func (r *timedShareRepository) GetEventShare(ctx context.Context, token string) (_ *models.EventShare, err error) {
+	defer observe(r.observer, "ShareRepository", "GetEventShare", time.Now(), &err)
+	return r.repo.GetEventShare(ctx, token)
+}
+
+func (r *timedShareRepository) DeleteEventShares(ctx context.Context, email, eventID string) (err error) {
+	defer observe(r.observer, "ShareRepository", "DeleteEventShares", time.Now(), &err)
+	return r.repo.DeleteEventShares(ctx, email, eventID)
+}
+
+func (r *timedShareRepository) MigrateShareEmail(ctx context.Context, oldEmail, newEmail string) (err error) {
+	defer observe(r.observer, "ShareRepository", "MigrateShareEmail", time.Now(), &err)
+	return r.repo.MigrateShareEmail(ctx, oldEmail, newEmail)
+}
+
+// timedPromptRepository reports the duration of every PromptRepository call to an OperationObserver.
+type timedPromptRepository struct {
+	repo     PromptRepository
+	observer OperationObserver
+}
+
+// NewTimedPromptRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedPromptRepository(repo PromptRepository, observer OperationObserver) PromptRepository {
+	return &timedPromptRepository{repo: repo, observer: observer}
+}
+
+func (r *timedPromptRepository) GetPromptOffset(ctx context.Context, userEmail string) (_ *models.PromptOffset, err error) {
+	defer observe(r.observer, "PromptRepository", "GetPromptOffset", time.Now(), &err)
+	return r.repo.GetPromptOffset(ctx, userEmail)
+}
+
+func (r *timedPromptRepository) IncrementPromptOffset(ctx context.Context, userEmail string, now time.Time) (_ *models.PromptOffset, err error) {
+	defer observe(r.observer, "PromptRepository", "IncrementPromptOffset", time.Now(), &err)
+	return r.repo.IncrementPromptOffset(ctx, userEmail, now)
+}
+
+// timedPendingDeliveryRepository reports the duration of every PendingDeliveryRepository call to an OperationObserver.
+type timedPendingDeliveryRepository struct {
+	repo     PendingDeliveryRepository
+	observer OperationObserver
+}
+
+// NewTimedPendingDeliveryRepository wraps repo so the duration and outcome of every call is reported to observer.
+func NewTimedPendingDeliveryRepository(repo PendingDeliveryRepository, observer OperationObserver) PendingDeliveryRepository {
+	return &timedPendingDeliveryRepository{repo: repo, observer: observer}
+}
+
+func (r *timedPendingDeliveryRepository) CreatePendingDelivery(ctx context.Context, delivery *models.PendingDelivery) (err error) {
+	defer observe(r.observer, "PendingDeliveryRepository", "CreatePendingDelivery", time.Now(), &err)
+	return r.repo.CreatePendingDelivery(ctx, delivery)
+}
+
+func (r *timedPendingDeliveryRepository) GetDuePendingDeliveries(ctx context.Context, now time.Time, limit int) (_ []models.PendingDelivery, err error) {
+	defer observe(r.observer, "PendingDeliveryRepository", "GetDuePendingDeliveries", time.Now(), &err)
+	return r.repo.GetDuePendingDeliveries(ctx, now, limit)
+}
+
+func (r *timedPendingDeliveryRepository) DeletePendingDelivery(ctx context.Context, deliveryID string) (err error) {
+	defer observe(r.observer, "PendingDeliveryRepository", "DeletePendingDelivery", time.Now(), &err)
+	return r.repo.DeletePendingDelivery(ctx, deliveryID)
+}
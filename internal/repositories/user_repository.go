@@ -7,11 +7,19 @@
  *  @methods
  *  - GetUserByEmail(ctx, email)                 - Retrieves a user by their email address.
  *  - GetUserByUsername(ctx, username)           - Retrieves a user by their username.
+ *  - GetUserByFeedToken(ctx, token)             - Retrieves the user whose calendar feed has the token.
  *  - CreateUser(ctx, user)                      - Creates a new user in the database.
  *  - UpdateUser(ctx, email, updates)            - Updates a user's data in the database.
- *  - SearchUsersByUsername(ctx, query)          - Searches for users by a username substring (prefix match, case-insensitive).
+ *  - IncrementUserField(ctx, email, field)      - Atomically adds one to a counter of a user and returns the new value.
+ *  - ReplaceUser(ctx, user)                     - Overwrites every field of an existing user.
+ *  - SearchUsers(ctx, query, limit)             - Searches for users by username, first name or last name prefix (case-insensitive).
+ *  - MigrateUserEmail(ctx, oldEmail, newEmail)  - Moves a user and the data stored under them to a new email.
+ *  - GetDigestSubscribers(ctx)                  - Retrieves the users who enabled the weekly digest.
+ *  - GetJournalReminderSubscribers(ctx)         - Retrieves the users who enabled the daily journal reminder.
+ *  - GetUnnormalizedUserEmails(ctx)             - Retrieves the emails of users stored under a mixed-case or padded email.
  *
  *  @behaviors
+ *  - Failures are reported with ErrNotFound and ErrUnavailable, whatever the database.
  *  - Allows extensibility for implementing user management across different database systems.
  *  - Standardizes operations for retrieving and updating user-related data.
  *
@@ -46,19 +54,48 @@ import (
 
 // UserRepository defines the interface for user-related data operations.
 type UserRepository interface {
-	// GetUserByEmail retrieves a user by their email address.
+	// GetUserByEmail retrieves a user by their email address. It returns ErrNotFound if there is none.
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 
-	// GetUserByUsername retrieves a user by their username.
+	// GetUserByUsername retrieves a user by their username. It returns ErrNotFound if there is none.
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
 
+	// GetUserByFeedToken retrieves the user whose FeedToken is token. It returns ErrNotFound if there is none.
+	GetUserByFeedToken(ctx context.Context, token string) (*models.User, error)
+
 	// CreateUser creates a new user in the database.
 	CreateUser(ctx context.Context, user *models.User) error
 
 	// UpdateUser updates a user's data in the database with the provided key-value pairs.
+	// It returns ErrNotFound if the user does not exist.
 	UpdateUser(ctx context.Context, email string, updates map[string]interface{}) error
 
-	// SearchUsersByUsername searches for users whose usernames match the given query.
-	// The search supports prefix matching and is case-insensitive.
-	SearchUsersByUsername(ctx context.Context, query string) ([]*models.User, error)
+	// IncrementUserField atomically adds one to the integer field of the user stored under email, keyed
+	// by its Go field name, and returns the new value, so concurrent increments are never lost.
+	// It returns ErrNotFound if the user does not exist.
+	IncrementUserField(ctx context.Context, email, field string) (int, error)
+
+	// ReplaceUser overwrites the user stored under user.Email with user, so no field of the stored user
+	// is kept. It returns ErrNotFound if the user does not exist.
+	ReplaceUser(ctx context.Context, user *models.User) error
+
+	// SearchUsers searches for users whose username, first name or last name starts with the given query,
+	// ignoring case. It returns up to limit matches per field, each user once: username matches first,
+	// then first name matches, then last name matches, each ordered by the matched field.
+	SearchUsers(ctx context.Context, query string, limit int) ([]*models.User, error)
+
+	// MigrateUserEmail moves the user stored under oldEmail, and the events, journals, notifications,
+	// audit log and settings stored under them, to newEmail. It fails if a user with newEmail already
+	// exists, unless that user is the copy of an earlier move from oldEmail (MovedFrom), which is resumed.
+	MigrateUserEmail(ctx context.Context, oldEmail, newEmail string) error
+
+	// GetDigestSubscribers retrieves all users with DigestEnabled set.
+	GetDigestSubscribers(ctx context.Context) ([]*models.User, error)
+
+	// GetJournalReminderSubscribers retrieves all users with ReminderEnabled set.
+	GetJournalReminderSubscribers(ctx context.Context) ([]*models.User, error)
+
+	// GetUnnormalizedUserEmails retrieves the emails of the users stored under an email that
+	// utils.NormalizeEmail would change, i.e. accounts created before emails were normalized.
+	GetUnnormalizedUserEmails(ctx context.Context) ([]string, error)
 }
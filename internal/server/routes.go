@@ -0,0 +1,289 @@
+/**
+ *  Routes registers every endpoint of the application with its handler and middleware. The routes are
+ *  kept out of main so tests can walk them, e.g. to check that each one is described in the OpenAPI document.
+ *
+ *  @struct   Handlers
+ *  @struct   Middleware
+ *
+ *  @methods
+ *  - NewAPIRouter(h, m, enableAdminRoutes, debug) - Registers the /api routes.
+ *  - NewRootRouter(h, m, api)                     - Registers the routes served outside the API middleware and sends every other path to api.
+ *
+ *  @behaviors
+ *  - Protected routes are wrapped in Middleware.JWTAuth; the unauthenticated user routes are rate limited per IP.
+ *    Shared events are read without a JWT, since their unguessable token is the credential.
+ *  - POST and PUT routes are wrapped in Middleware.JSONBody, or Middleware.ImportBody for the timetable
+ *    import, which require bodies to be JSON and limit their size. The avatar and journal attachment
+ *    uploads take a multipart form instead, and the journal import JSON or CSV, and limit their size themselves.
+ *  - The administrator routes under /api/admin are wrapped in Middleware.AdminOnly after the JWT check.
+ *    The development routes among them are only registered when enableAdminRoutes is true.
+ *  - The health probes and the WebSocket endpoint are served by the root router, outside the CORS and
+ *    timeout middleware that main wraps the API router in, since WebSocket connections are long-lived.
+ *  - /metrics is also served by the root router, outside the JWT middleware; Handlers.Metrics does its
+ *    own token check if one is configured.
+ *  - Every route except the WebSocket endpoint is wrapped in Middleware.Instrument, which records
+ *    request metrics by route template. WebSocket connections stay open for the whole session, so
+ *    their duration says nothing about latency.
+ *  - Unknown paths are answered with a JSON 404 and known paths requested with the wrong method with a
+ *    JSON 405 listing the route's methods in the Allow header, in the same envelope as other errors.
+ *    Both pass through the CORS middleware like any API response, so browsers can read them. When debug
+ *    is true, unknown paths under /api/ are logged with a debug tag, to catch typos in the web app.
+ *
+ *  @file      routes.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package server
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/pkg/utils"
+)
+
+// Handlers holds the HTTP handlers the routes are served by.
+type Handlers struct {
+	User         *handlers.UserHandler
+	Event        *handlers.EventHandler
+	Friend       *handlers.FriendHandler
+	Journal      *handlers.JournalHandler
+	Prompt       *handlers.PromptHandler
+	News         *handlers.NewsHandler
+	Weather      *handlers.WeatherHandler
+	Holiday      *handlers.HolidayHandler
+	Quote        *handlers.QuoteHandler
+	Profile      *handlers.ProfileHandler
+	Country      *handlers.CountryHandler
+	City         *handlers.CityHandler
+	Timetable    *handlers.TimetableHandler
+	Health       *handlers.HealthHandler
+	Notification *handlers.NotificationHandler
+	Export       *handlers.ExportHandler
+	Digest       *handlers.DigestHandler
+	Audit        *handlers.AuditHandler
+	Stats        *handlers.StatsHandler
+	Admin        *handlers.AdminHandler
+	Docs         *handlers.DocsHandler
+	Metrics      http.Handler // Serves the Prometheus metrics.
+}
+
+// Middleware holds the authentication and rate limiting middleware the routes are wrapped in.
+type Middleware struct {
+	JWTAuth       func(http.HandlerFunc) http.HandlerFunc // Requires a valid JWT.
+	WebSocketAuth func(http.HandlerFunc) http.HandlerFunc // Requires a valid JWT, also accepted as ?token=.
+	AdminOnly     func(http.HandlerFunc) http.HandlerFunc // Requires the admin role; runs after JWTAuth.
+	SignupLimit   func(http.Handler) http.Handler         // Limits signups per IP.
+	LoginLimit    func(http.Handler) http.Handler         // Limits login attempts per IP.
+	OTPLimit      func(http.Handler) http.Handler         // Limits OTP requests and submissions per IP.
+	ExportLimit   func(http.Handler) http.Handler         // Limits data exports per user.
+	InviteLimit   func(http.Handler) http.Handler         // Limits friend invitations by email per user.
+	Instrument    func(http.Handler) http.Handler         // Records request metrics; runs after the route is matched.
+	JSONBody      func(http.Handler) http.Handler         // Requires a JSON body of limited size.
+	ImportBody    func(http.Handler) http.Handler         // Requires a JSON body, with a larger limit for timetable imports.
+}
+
+// NewAPIRouter returns a router serving the /api routes. debug logs requests to unknown /api paths.
+func NewAPIRouter(h Handlers, m Middleware, enableAdminRoutes, debug bool) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(m.Instrument)
+	jwtAuth := m.JWTAuth
+	jsonBody := m.JSONBody
+
+	// User routes
+	router.Handle("/api/signup", jsonBody(m.SignupLimit(http.HandlerFunc(h.User.Signup)))).Methods("POST")
+	router.Handle("/api/login", jsonBody(m.LoginLimit(http.HandlerFunc(h.User.Login)))).Methods("POST")
+	router.Handle("/api/resend-otp", jsonBody(m.OTPLimit(http.HandlerFunc(h.User.ResendOTP)))).Methods("POST")
+	router.Handle("/api/verify-email", jsonBody(m.OTPLimit(http.HandlerFunc(h.User.VerifyEmail)))).Methods("POST")
+	router.Handle("/api/forgot-password", jsonBody(m.OTPLimit(http.HandlerFunc(h.User.ForgotPassword)))).Methods("POST")
+	router.Handle("/api/reset-password", jsonBody(m.OTPLimit(http.HandlerFunc(h.User.ResetPassword)))).Methods("POST")
+	router.Handle("/api/me", jwtAuth(h.User.GetUserInfo)).Methods("GET")
+	router.Handle("/api/me/security-log", jwtAuth(h.Audit.GetSecurityLog)).Methods("GET")
+	router.Handle("/api/me/stats", jwtAuth(h.Stats.GetUserStats)).Methods("GET")
+	router.Handle("/api/me/export", jwtAuth(m.ExportLimit(http.HandlerFunc(h.Export.ExportData)).ServeHTTP)).Methods("GET")
+
+	// Event routes
+	router.Handle("/api/events/create", jsonBody(jwtAuth(h.Event.CreateEvent))).Methods("POST")
+	router.Handle("/api/events/get", jwtAuth(h.Event.GetEvent)).Methods("GET")
+	router.Handle("/api/events/update", jsonBody(jwtAuth(h.Event.UpdateEvent))).Methods("PUT")
+	router.Handle("/api/events/update", jsonBody(jwtAuth(h.Event.PatchEvent))).Methods("PATCH")
+	router.Handle("/api/events/delete", jwtAuth(h.Event.DeleteEvent)).Methods("DELETE")
+	router.Handle("/api/events/all", jwtAuth(h.Event.GetAllEvents)).Methods("GET", "HEAD")
+	router.Handle("/api/events/invite", jsonBody(jwtAuth(h.Event.InviteToEvent))).Methods("POST")
+	router.Handle("/api/events/rsvp", jsonBody(jwtAuth(h.Event.RespondToInvitation))).Methods("POST")
+	router.Handle("/api/events/invitations", jwtAuth(h.Event.GetInvitations)).Methods("GET")
+	router.Handle("/api/events/tags", jwtAuth(h.Event.GetEventTags)).Methods("GET")
+	router.Handle("/api/events/nearby", jwtAuth(h.Event.GetNearbyEvents)).Methods("GET")
+	router.Handle("/api/events/search", jwtAuth(h.Event.SearchEvents)).Methods("GET")
+	router.Handle("/api/events/bulk-create", jsonBody(jwtAuth(h.Event.BulkCreateEvents))).Methods("POST")
+	router.Handle("/api/events/bulk-delete", jsonBody(jwtAuth(h.Event.BulkDeleteEvents))).Methods("POST")
+	router.Handle("/api/events/duplicate", jsonBody(jwtAuth(h.Event.DuplicateEvent))).Methods("POST")
+	router.Handle("/api/events/cancel", jsonBody(jwtAuth(h.Event.CancelEvent))).Methods("POST")
+	router.Handle("/api/events/month", jwtAuth(h.Event.GetEventMonth)).Methods("GET")
+	router.Handle("/api/events/feed", jwtAuth(h.Event.GetFriendsEventFeed)).Methods("GET")
+	router.Handle("/api/events/share", jwtAuth(h.Event.ShareEvent)).Methods("POST")
+	router.Handle("/api/events/share", jwtAuth(h.Event.RevokeEventShare)).Methods("DELETE")
+	router.HandleFunc("/api/shared/events/{token}", h.Event.GetSharedEvent).Methods("GET") // The token is the credential.
+	router.HandleFunc("/api/feeds/{token}.ics", h.Timetable.GetFeed).Methods("GET")        // The token is the credential.
+
+	// Friend routes
+	router.Handle("/api/friends/add", jsonBody(jwtAuth(h.Friend.SendFriendRequest))).Methods("POST")
+	router.Handle("/api/friends/accept", jsonBody(jwtAuth(h.Friend.AcceptFriendRequest))).Methods("POST")
+	router.Handle("/api/friends/list", jwtAuth(h.Friend.GetFriendsList)).Methods("GET")
+	router.Handle("/api/friends/delete", jwtAuth(h.Friend.RemoveFriend)).Methods("DELETE")
+	router.Handle("/api/friends/requests", jwtAuth(h.Friend.GetPendingFriendRequests)).Methods("GET")
+	router.Handle("/api/friends/decline", jsonBody(jwtAuth(h.Friend.DeclineFriendRequest))).Methods("POST")
+	router.Handle("/api/friends/cancel", jsonBody(jwtAuth(h.Friend.CancelFriendRequest))).Methods("POST")
+	router.Handle("/api/friends/block", jsonBody(jwtAuth(h.Friend.BlockUser))).Methods("POST")
+	router.Handle("/api/friends/unblock", jsonBody(jwtAuth(h.Friend.UnblockUser))).Methods("POST")
+	router.Handle("/api/friends/blocked", jwtAuth(h.Friend.GetBlockedUsers)).Methods("GET")
+	router.Handle("/api/friends/suggestions", jwtAuth(h.Friend.GetFriendSuggestions)).Methods("GET")
+	router.Handle("/api/friends/mutual", jwtAuth(h.Friend.GetMutualFriends)).Methods("GET")
+	router.Handle("/api/friends/events", jwtAuth(h.Event.GetFriendEvents)).Methods("GET")
+	router.Handle("/api/friends/status", jsonBody(jwtAuth(h.Friend.GetRelationshipStatuses))).Methods("POST")
+	router.Handle("/api/friends/invite", jsonBody(jwtAuth(m.InviteLimit(http.HandlerFunc(h.Friend.InviteFriend)).ServeHTTP))).Methods("POST")
+
+	// Notification routes
+	router.Handle("/api/notifications", jwtAuth(h.Notification.ListNotifications)).Methods("GET")
+	router.Handle("/api/notifications/read", jsonBody(jwtAuth(h.Notification.MarkRead))).Methods("POST")
+
+	// User search
+	router.Handle("/api/users/search", jwtAuth(h.User.SearchUsersByUsername)).Methods("GET")
+
+	// Profile routes
+	router.Handle("/api/profile", jsonBody(jwtAuth(h.Profile.ProfileHandler))).Methods("GET", "PUT")
+	router.Handle("/api/profile/change-email", jsonBody(m.OTPLimit(jwtAuth(h.Profile.ChangeEmail)))).Methods("POST")
+	router.Handle("/api/profile/confirm-email", jsonBody(m.OTPLimit(jwtAuth(h.Profile.ConfirmEmail)))).Methods("POST")
+	router.Handle("/api/profile/avatar", jwtAuth(h.Profile.UploadAvatar)).Methods("POST")
+	router.Handle("/api/profile/avatar", jwtAuth(h.Profile.DeleteAvatar)).Methods("DELETE")
+
+	// Country and city routes
+	router.HandleFunc("/api/countries", h.Country.GetCountries).Methods("GET")
+	router.HandleFunc("/api/cities", h.City.GetCities).Methods("GET")
+
+	// News route
+	router.Handle("/api/news", jwtAuth(h.News.FetchNews)).Methods("GET")
+
+	// Weather route
+	router.Handle("/api/weather", jwtAuth(h.Weather.GetWeather)).Methods("GET")
+
+	// Holiday route
+	router.Handle("/api/holidays", jwtAuth(h.Holiday.GetHolidays)).Methods("GET")
+
+	// Daily verse routes
+	router.Handle("/api/daily-verse", jwtAuth(h.Quote.GetDailyVerse)).Methods("GET")
+	router.Handle("/api/daily-verse/favorite", jsonBody(jwtAuth(h.Quote.SaveFavorite))).Methods("POST")
+	router.Handle("/api/daily-verse/favorites", jwtAuth(h.Quote.GetFavorites)).Methods("GET")
+
+	// Journal routes
+	router.Handle("/api/journal/save", jsonBody(jwtAuth(h.Journal.CreateJournal))).Methods("POST")
+	router.Handle("/api/journal", jwtAuth(h.Journal.GetJournal)).Methods("GET")
+	router.Handle("/api/journal/update", jsonBody(jwtAuth(h.Journal.UpdateJournal))).Methods("PUT")
+	router.Handle("/api/journal/delete", jwtAuth(h.Journal.DeleteJournal)).Methods("DELETE")
+	router.Handle("/api/journal/trash", jwtAuth(h.Journal.GetJournalTrash)).Methods("GET")
+	router.Handle("/api/journal/restore", jwtAuth(h.Journal.RestoreJournal)).Methods("POST")
+	router.Handle("/api/journal/attachments", jwtAuth(h.Journal.UploadAttachment)).Methods("POST")
+	router.Handle("/api/journal/attachments", jwtAuth(h.Journal.GetAttachment)).Methods("GET")
+	router.Handle("/api/journal/attachments", jwtAuth(h.Journal.DeleteAttachment)).Methods("DELETE")
+	router.Handle("/api/journal/prompt", jwtAuth(h.Prompt.GetPrompt)).Methods("GET")
+	router.Handle("/api/journal/prompt/skip", jwtAuth(h.Prompt.SkipPrompt)).Methods("POST")
+	router.Handle("/api/journals", jwtAuth(h.Journal.GetAllJournals)).Methods("GET", "HEAD")
+	router.Handle("/api/journals/search", jwtAuth(h.Journal.SearchJournals)).Methods("GET")
+	router.Handle("/api/journals/export", jwtAuth(h.Journal.ExportJournals)).Methods("GET")
+	router.Handle("/api/journals/import", jwtAuth(h.Journal.ImportJournals)).Methods("POST")
+	router.Handle("/api/journals/stats", jwtAuth(h.Journal.GetJournalStats)).Methods("GET")
+
+	// Timetable route
+	router.Handle("/api/import-ntnu-timetable", m.ImportBody(jwtAuth(h.Timetable.ImportTimetable))).Methods("POST")
+	router.Handle("/api/import-ntnu-timetable", jwtAuth(h.Timetable.UndoImport)).Methods("DELETE")
+	router.Handle("/api/import-ntnu-timetable/batches", jwtAuth(h.Timetable.GetImportBatches)).Methods("GET")
+	router.Handle("/api/events/export.ics", jwtAuth(h.Timetable.ExportTimetable)).Methods("GET")
+	router.Handle("/api/events/feed-token", jwtAuth(h.Timetable.CreateFeedToken)).Methods("POST")
+	router.Handle("/api/events/feed-token", jwtAuth(h.Timetable.GetFeedToken)).Methods("GET")
+	router.Handle("/api/events/feed-token", jwtAuth(h.Timetable.RevokeFeedToken)).Methods("DELETE")
+
+	// Administrator routes
+	adminOnly := func(next http.HandlerFunc) http.HandlerFunc { return jwtAuth(m.AdminOnly(next)) }
+	router.Handle("/api/admin/users", adminOnly(h.Admin.SearchUsers)).Methods("GET")
+	router.Handle("/api/admin/users/verify", jsonBody(adminOnly(h.Admin.VerifyUser))).Methods("POST")
+	router.Handle("/api/admin/users/disable", jsonBody(adminOnly(h.Admin.DisableUser))).Methods("POST")
+	router.Handle("/api/admin/users/normalize-emails", adminOnly(h.Admin.NormalizeEmails)).Methods("POST")
+
+	// Development routes
+	if enableAdminRoutes {
+		router.Handle("/api/admin/digest/run", jsonBody(adminOnly(h.Digest.RunDigests))).Methods("POST")
+	}
+
+	// API documentation
+	router.HandleFunc("/api/openapi.json", h.Docs.GetSpec).Methods("GET")
+	router.HandleFunc("/api/docs", h.Docs.GetDocs).Methods("GET")
+
+	router.NotFoundHandler = m.Instrument(notFound(debug))
+	router.MethodNotAllowedHandler = m.Instrument(methodNotAllowed(router))
+	return router
+}
+
+// notFound answers requests to unknown paths with a JSON 404, logging those under /api/ when debug is true.
+func notFound(debug bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if debug && strings.HasPrefix(r.URL.Path, "/api/") {
+			log.Printf("debug unmatched_route method=%s path=%q", r.Method, r.URL.Path)
+		}
+		utils.WriteJSONError(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// methodNotAllowed answers requests to a path of router with a method it is not served with by a
+// JSON 405, listing the methods it is served with in the Allow header.
+func methodNotAllowed(router *mux.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(allowedMethods(router, r), ", "))
+		utils.WriteJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// allowedMethods returns the methods the routes of router matching the path of r are served with, sorted.
+func allowedMethods(router *mux.Router, r *http.Request) []string {
+	seen := make(map[string]bool)
+	var methods []string
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		routeMethods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range routeMethods {
+			probe := *r
+			probe.Method = method
+			if !seen[method] && route.Match(&probe, &mux.RouteMatch{}) {
+				seen[method] = true
+				methods = append(methods, method)
+			}
+		}
+		return nil
+	})
+	sort.Strings(methods)
+	return methods
+}
+
+// NewRootRouter returns a router serving the health probes, the metrics and the WebSocket endpoint,
+// which sends every other path to api.
+func NewRootRouter(h Handlers, m Middleware, api http.Handler) *mux.Router {
+	router := mux.NewRouter()
+	router.Handle("/healthz", m.Instrument(http.HandlerFunc(h.Health.Liveness))).Methods("GET")
+	router.Handle("/readyz", m.Instrument(http.HandlerFunc(h.Health.Readiness))).Methods("GET")
+	router.Handle("/metrics", m.Instrument(h.Metrics)).Methods("GET")
+	router.Handle("/api/ws", m.WebSocketAuth(h.Notification.ServeWS)).Methods("GET")
+	router.PathPrefix("/").Handler(api)
+	return router
+}
@@ -0,0 +1,71 @@
+/**
+ *  Server runs the HTTP server until its context is cancelled, then shuts it down gracefully
+ *  so in-flight requests can complete before the application releases its resources.
+ *
+ *  @methods
+ *  - Serve(ctx, srv, listener, shutdownTimeout) - Serves requests until ctx is done, then shuts down.
+ *
+ *  @behaviors
+ *  - Stops accepting new connections once ctx is cancelled (e.g. on SIGINT or SIGTERM).
+ *  - Waits up to shutdownTimeout for in-flight requests before closing remaining connections.
+ *  - Returns nil after a graceful shutdown, or the error that stopped the server.
+ *
+ *  @example
+ *  ```
+ *  ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+ *  defer stop()
+ *
+ *  listener, err := net.Listen("tcp", ":8080")
+ *  ...
+ *  err = server.Serve(ctx, &http.Server{Handler: router}, listener, 20*time.Second)
+ *  ```
+ *
+ *  @file      server.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Server
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Serve serves requests on the listener until ctx is done, then shuts the server down, giving
+// in-flight requests up to shutdownTimeout to complete.
+func Serve(ctx context.Context, srv *http.Server, listener net.Listener, shutdownTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		// The server stopped on its own, e.g. because the listener failed.
+		return fmt.Errorf("Server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		// Requests still running after the timeout are cut off.
+		srv.Close()
+		return fmt.Errorf("Server shutdown failed: %w", err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("Server failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+/**
+ *  AdminService provides the moderation actions available to administrators: finding any account,
+ *  verifying an email by hand, disabling or re-enabling an account, and moving accounts created before
+ *  emails were normalized to their normalized email.
+ *
+ *  @file       admin_service.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - AdminServiceInterface: Defines the contract for administrator actions.
+ *
+ *  @methods
+ *  - NewAdminService(userRepo, audit)                          - Initializes a new AdminService.
+ *  - SearchUsers(ctx, adminEmail, query, limit, offset)        - Finds accounts by email, or by username or name prefix.
+ *  - VerifyUser(ctx, adminEmail, email)                        - Marks an account verified without an OTP.
+ *  - SetUserDisabled(ctx, adminEmail, email, disabled)         - Disables or re-enables an account.
+ *  - NormalizeUserEmails(ctx, adminEmail)                      - Moves mixed-case or padded accounts to their normalized email.
+ *
+ *  @behaviors
+ *  - Only administrators reach these methods: the routes are wrapped in the AdminOnly middleware.
+ *    Administrators are made by setting Role to "admin" on their user document in the database.
+ *  - A query that is an email address finds that account exactly; any other query is matched as a
+ *    username, first name or last name prefix like the user search, but over every account.
+ *  - VerifyUser is for users whose OTP email bounced. It clears the pending OTP.
+ *  - A disabled account cannot log in, and the tokens it was issued are rejected, so its sessions end
+ *    at once. Administrators cannot disable their own account.
+ *  - Emails are normalized with utils.NormalizeEmail before accounts are looked up.
+ *  - NormalizeUserEmails is a one-time migration for accounts created before emails were normalized,
+ *    which can no longer be found by their email. Each is moved, with its data, friends,
+ *    invitations and shared event links, like an email change; the owner must log in again, since their tokens name the old
+ *    email. Accounts whose normalized email is already taken are reported as conflicts and left for an
+ *    administrator to resolve. Running it again only picks up accounts still stored under their old email,
+ *    which includes those that failed: the account is moved after its friends, invitations and shared
+ *    event links, so a failed move is resumed on the next run instead of leaving them behind.
+ *  - Every action is logged with the administrator and the account it was taken on. Verifying,
+ *    disabling and re-enabling are also recorded in the audit log of that account.
+ *
+ *  @errors
+ *  - ErrDisableSelf: An administrator tried to disable their own account.
+ *  - ErrAlreadyVerified: VerifyUser was called for a verified account.
+ *  - "User not found" wrapping repositories.ErrNotFound: No account has the email.
+ *
+ *  @dependencies
+ *  - repositories.UserRepository: Reads and updates the accounts.
+ *  - repositories.FriendRepository: Friend requests and blocks moved by NormalizeUserEmails; may be nil.
+ *  - repositories.InvitationRepository: Invitations moved by NormalizeUserEmails; may be nil.
+ *  - repositories.ShareRepository: Shared event links moved by NormalizeUserEmails; may be nil.
+ *  - AuditServiceInterface: Records the actions in the audit log of the account; may be nil.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+)
+
+// ErrDisableSelf is returned when an administrator tries to disable their own account.
+var ErrDisableSelf = errors.New("You cannot disable your own account")
+
+// AdminServiceInterface defines the actions available to administrators.
+type AdminServiceInterface interface {
+	SearchUsers(ctx context.Context, adminEmail, query string, limit, offset int) ([]models.AdminUserSummary, error)
+	VerifyUser(ctx context.Context, adminEmail, email string) error
+	SetUserDisabled(ctx context.Context, adminEmail, email string, disabled bool) error
+	NormalizeUserEmails(ctx context.Context, adminEmail string) (*models.EmailNormalizationReport, error)
+}
+
+// AdminService implements AdminServiceInterface.
+type AdminService struct {
+	UserRepo       repositories.UserRepository       // Repository for the accounts.
+	FriendRepo     repositories.FriendRepository     // Friend requests moved by NormalizeUserEmails; may be nil.
+	InvitationRepo repositories.InvitationRepository // Invitations moved by NormalizeUserEmails; may be nil.
+	ShareRepo      repositories.ShareRepository      // Shared event links moved by NormalizeUserEmails; may be nil.
+	Audit          AuditServiceInterface             // Records actions in the audit log of the account; may be nil.
+	Now            func() time.Time                  // Clock used to report lockouts; replaceable in tests.
+}
+
+// NewAdminService initializes a new AdminService.
+func NewAdminService(userRepo repositories.UserRepository, audit AuditServiceInterface) AdminServiceInterface {
+	return &AdminService{
+		UserRepo: userRepo,
+		Audit:    audit,
+		Now:      time.Now,
+	}
+}
+
+// SearchUsers returns the page of limit accounts starting at offset that match query: the account
+// with that email if query is an email address, otherwise the accounts whose username, first name or
+// last name starts with query. A limit outside 1..MaxUserSearchLimit is clamped.
+func (as *AdminService) SearchUsers(ctx context.Context, adminEmail, query string, limit, offset int) ([]models.AdminUserSummary, error) {
+	if limit <= 0 {
+		limit = DefaultUserSearchLimit
+	} else if limit > MaxUserSearchLimit {
+		limit = MaxUserSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	query = strings.TrimSpace(query)
+	logAdminAction(adminEmail, "search_users", query)
+
+	var matches []*models.User
+	if email := utils.NormalizeEmail(query); utils.IsValidEmail(email) {
+		user, err := as.UserRepo.GetUserByEmail(ctx, email)
+		if isRepositoryFailure(err) {
+			return nil, fmt.Errorf("Failed to search users: %w", err)
+		}
+		if err == nil && user != nil {
+			matches = append(matches, user)
+		}
+	} else {
+		// The first offset+limit merged matches are in their final order; see SearchUsersByUsername.
+		users, err := as.UserRepo.SearchUsers(ctx, query, offset+limit)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to search users: %w", err)
+		}
+		matches = users
+	}
+
+	results := []models.AdminUserSummary{}
+	for i := offset; i < len(matches) && i < offset+limit; i++ {
+		results = append(results, as.summarize(matches[i]))
+	}
+	return results, nil
+}
+
+// summarize describes user to an administrator.
+func (as *AdminService) summarize(user *models.User) models.AdminUserSummary {
+	role := user.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+	summary := models.AdminUserSummary{
+		Email:      user.Email,
+		Username:   user.Username,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Country:    user.Country,
+		City:       user.City,
+		Role:       role,
+		IsVerified: user.IsVerified,
+		Disabled:   user.Disabled,
+	}
+	if as.Now().Before(user.LockedUntil) {
+		lockedUntil := user.LockedUntil
+		summary.LockedUntil = &lockedUntil
+	}
+	return summary
+}
+
+// VerifyUser marks the account of email verified, for users who cannot receive the OTP email.
+func (as *AdminService) VerifyUser(ctx context.Context, adminEmail, email string) error {
+	user, err := as.findUser(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user.IsVerified {
+		return ErrAlreadyVerified
+	}
+
+	updates := map[string]interface{}{"IsVerified": true, "OTP": "", "OTPAttempts": 0}
+	if err := as.UserRepo.UpdateUser(ctx, user.Email, updates); err != nil {
+		return fmt.Errorf("Failed to verify user: %w", err)
+	}
+	logAdminAction(adminEmail, AuditEmailVerifiedByAdmin, user.Email)
+	recordAudit(ctx, as.Audit, user.Email, AuditEmailVerifiedByAdmin)
+	return nil
+}
+
+// SetUserDisabled disables the account of email, or re-enables it if disabled is false.
+func (as *AdminService) SetUserDisabled(ctx context.Context, adminEmail, email string, disabled bool) error {
+	if disabled && strings.EqualFold(email, adminEmail) {
+		return ErrDisableSelf
+	}
+	user, err := as.findUser(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if err := as.UserRepo.UpdateUser(ctx, user.Email, map[string]interface{}{"Disabled": disabled}); err != nil {
+		return fmt.Errorf("Failed to update user: %w", err)
+	}
+	action := AuditAccountEnabled
+	if disabled {
+		action = AuditAccountDisabled
+	}
+	logAdminAction(adminEmail, action, user.Email)
+	recordAudit(ctx, as.Audit, user.Email, action)
+	return nil
+}
+
+// NormalizeUserEmails moves every account stored under an email that is not normalized to the
+// normalized email, reporting the accounts moved, those whose normalized email is taken, and those that failed.
+func (as *AdminService) NormalizeUserEmails(ctx context.Context, adminEmail string) (*models.EmailNormalizationReport, error) {
+	emails, err := as.UserRepo.GetUnnormalizedUserEmails(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find users to migrate: %w", err)
+	}
+
+	report := &models.EmailNormalizationReport{Migrated: []string{}, Conflicts: []string{}, Failed: []string{}}
+	for _, email := range emails {
+		normalized := utils.NormalizeEmail(email)
+		existing, err := as.UserRepo.GetUserByEmail(ctx, normalized)
+		if isRepositoryFailure(err) {
+			log.Printf("Failed to check %s before migrating %s: %v", normalized, email, err)
+			report.Failed = append(report.Failed, email)
+			continue
+		}
+		// A copy left under the normalized email by a run that failed halfway is not a conflict.
+		if err == nil && existing != nil && existing.MovedFrom != email {
+			report.Conflicts = append(report.Conflicts, email)
+			continue
+		}
+
+		if err := migrateAccountEmail(ctx, as.UserRepo, as.FriendRepo, as.InvitationRepo, as.ShareRepo, email, normalized); err != nil {
+			log.Printf("Failed to migrate %s to %s: %v", email, normalized, err)
+			report.Failed = append(report.Failed, email)
+			continue
+		}
+		logAdminAction(adminEmail, "email_normalized", email)
+		report.Migrated = append(report.Migrated, email)
+	}
+	return report, nil
+}
+
+// findUser returns the account of email, or an error wrapping repositories.ErrNotFound if there is none.
+func (as *AdminService) findUser(ctx context.Context, email string) (*models.User, error) {
+	user, err := as.UserRepo.GetUserByEmail(ctx, utils.NormalizeEmail(email))
+	if isRepositoryFailure(err) {
+		return nil, err
+	}
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("User %w", repositories.ErrNotFound)
+	}
+	return user, nil
+}
+
+// logAdminAction writes an administrator action to the server log as key=value pairs.
+func logAdminAction(adminEmail, action, target string) {
+	log.Printf("admin_action action=%s admin=%q target=%q", action, adminEmail, target)
+}
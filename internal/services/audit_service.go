@@ -0,0 +1,133 @@
+/**
+ *  AuditService keeps the audit log of each account: the security-sensitive actions taken on it,
+ *  such as logins, password resets and password changes, with the IP address and User-Agent of the
+ *  request, so users and support can tell when and from where they happened.
+ *
+ *  @file       audit_service.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - AuditServiceInterface: Defines the contract for recording and reading audit entries.
+ *
+ *  @methods
+ *  - NewAuditService(auditRepo)              - Initializes a new AuditService.
+ *  - Record(ctx, userEmail, action)          - Adds an entry to a user's audit log.
+ *  - ListEntries(ctx, userEmail)             - Retrieves the latest MaxAuditEntries entries of a user's audit log.
+ *  - WithClientInfo(ctx, ip, userAgent)      - Attaches the client of a request to a context for Record.
+ *
+ *  @behaviors
+ *  - Record is best-effort and never fails the action it records: failures are logged.
+ *  - Record writes with a context detached from the request's cancellation, so an entry is kept when
+ *    the client disconnects, and it may be called from a goroutine after the request has finished.
+ *  - The IP address and User-Agent are read from the context; handlers attach them with WithClientInfo.
+ *  - Entries are listed newest first.
+ *
+ *  @dependencies
+ *  - repositories.AuditRepository: Stores the audit log.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditLoginSucceeded         = "login_succeeded"
+	AuditLoginFailed            = "login_failed"
+	AuditPasswordResetRequested = "password_reset_requested"
+	AuditPasswordResetCompleted = "password_reset_completed"
+	AuditEmailVerified          = "email_verified"
+	AuditPasswordChanged        = "password_changed"
+	AuditEmailVerifiedByAdmin   = "email_verified_by_admin"
+	AuditAccountDisabled        = "account_disabled"
+	AuditAccountEnabled         = "account_enabled"
+)
+
+// MaxAuditEntries is the number of entries returned by ListEntries.
+const MaxAuditEntries = 50
+
+// auditWriteTimeout bounds a write to the audit log, which no longer ends with the request.
+const auditWriteTimeout = 10 * time.Second
+
+// AuditServiceInterface defines methods for recording and reading the audit log of an account.
+type AuditServiceInterface interface {
+	Record(ctx context.Context, userEmail, action string)
+	ListEntries(ctx context.Context, userEmail string) ([]models.AuditEntry, error)
+}
+
+// AuditService implements AuditServiceInterface.
+type AuditService struct {
+	AuditRepo repositories.AuditRepository // Repository for the users' audit logs.
+	Now       func() time.Time             // Clock used for Timestamp; replaceable in tests.
+}
+
+// NewAuditService initializes a new AuditService.
+func NewAuditService(auditRepo repositories.AuditRepository) AuditServiceInterface {
+	return &AuditService{
+		AuditRepo: auditRepo,
+		Now:       time.Now,
+	}
+}
+
+// clientInfoKey is the context key for the client of the request being audited.
+type clientInfoKey struct{}
+
+// clientInfo identifies the client that made a request.
+type clientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// WithClientInfo returns a copy of ctx carrying the IP address and User-Agent of the client, which
+// Record stores with each entry.
+func WithClientInfo(ctx context.Context, ip, userAgent string) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, clientInfo{IP: ip, UserAgent: userAgent})
+}
+
+// Record adds an entry for action to the audit log of userEmail. A failure is logged, not returned.
+func (as *AuditService) Record(ctx context.Context, userEmail, action string) {
+	client, _ := ctx.Value(clientInfoKey{}).(clientInfo)
+	entry := &models.AuditEntry{
+		Email:     userEmail,
+		Action:    action,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Timestamp: as.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+	if err := as.AuditRepo.CreateAuditEntry(ctx, entry); err != nil {
+		log.Printf("Failed to record %s in the audit log of %s: %v", action, userEmail, err)
+	}
+}
+
+// ListEntries retrieves the latest MaxAuditEntries entries of a user's audit log, newest first.
+func (as *AuditService) ListEntries(ctx context.Context, userEmail string) ([]models.AuditEntry, error) {
+	entries, err := as.AuditRepo.ListAuditEntries(ctx, userEmail, MaxAuditEntries)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve security log: %w", err)
+	}
+	return entries, nil
+}
+
+// recordAudit records an action through audit, which may be nil when the audit log is not in use.
+func recordAudit(ctx context.Context, audit AuditServiceInterface, userEmail, action string) {
+	if audit != nil {
+		audit.Record(ctx, userEmail, action)
+	}
+}
@@ -10,21 +10,23 @@
  *
  *  @methods
  *  - NewCityService()                                - Initializes a new instance of CityService.
- *  - GetCitiesByCountry(country) ([]string, error)   - Fetches a list of cities for the specified country.
+ *  - GetCitiesByCountry(ctx, country) ([]string, error) - Fetches a list of cities for the specified country.
  *
  *  @dependencies
  *  - config.CitiesAPIURL: Configuration value containing the external API endpoint.
- *  - http.Client: Used for making HTTP requests.
+ *  - httpclient.New: Builds the HTTP client, with timeouts and retries of idempotent requests.
  *
  *  @behaviors
  *  - Sends a POST request to the external API with the country name as the request payload.
  *  - Parses the JSON response and returns the list of cities on success.
  *  - Handles errors gracefully, including API errors, decoding errors, and connection issues.
+ *  - Caches city lists in memory per country (case-insensitive) for CacheTTL, 24 hours by default.
+ *  - The request is cancelled along with ctx; a timeout is reported as ErrCityLookupTimeout.
  *
  *  @example
  *  ```
  *  cityService := NewCityService()
- *  cities, err := cityService.GetCitiesByCountry("Norway")
+ *  cities, err := cityService.GetCitiesByCountry(ctx, "Norway")
  *  if err != nil {
  *      log.Fatal("Failed to fetch cities:", err)
  *  }
@@ -36,6 +38,7 @@
  *  - Returns an error if the HTTP request fails.
  *  - Returns an error if the API response indicates a failure.
  *  - Returns an error if the JSON response cannot be decoded.
+ *  - Returns ErrCityLookupTimeout if the external API does not respond in time.
  *
  *  @authors
  *      - Aayush
@@ -48,43 +51,85 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
+
 	"proh2052-group6/internal/config"
+	"proh2052-group6/pkg/httpclient"
 )
 
+// DefaultCityCacheTTL is how long city lists are cached by default. They rarely change.
+const DefaultCityCacheTTL = 24 * time.Hour
+
+// ErrCityLookupTimeout is returned when the external cities API does not respond in time.
+var ErrCityLookupTimeout = errors.New("Timed out fetching cities")
+
 // CityServiceInterface defines the methods for CityService.
 type CityServiceInterface interface {
 	// GetCitiesByCountry fetches cities for a given country.
-	GetCitiesByCountry(country string) ([]string, error)
+	GetCitiesByCountry(ctx context.Context, country string) ([]string, error)
 }
 
 // CityService implements CityServiceInterface.
 type CityService struct {
-	HTTPClient   *http.Client // HTTP client for making API requests.
-	CitiesAPIURL string       // URL of the external cities API.
+	HTTPClient   *http.Client     // HTTP client for making API requests.
+	CitiesAPIURL string           // URL of the external cities API.
+	CacheTTL     time.Duration    // How long city lists are cached; 0 disables caching.
+	Now          func() time.Time // Clock used for cache expiry; defaults to time.Now.
+
+	mutex sync.Mutex
+	cache map[string]cityCacheEntry
+}
+
+// cityCacheEntry holds the cities of a country until they expire.
+type cityCacheEntry struct {
+	cities    []string
+	expiresAt time.Time
 }
 
 // NewCityService initializes a new CityService.
 func NewCityService() CityServiceInterface {
 	return &CityService{
-		HTTPClient:   http.DefaultClient,
+		HTTPClient:   httpclient.New(httpclient.DefaultConfig()),
 		CitiesAPIURL: config.CitiesAPIURL,
+		CacheTTL:     DefaultCityCacheTTL,
+		Now:          time.Now,
 	}
 }
 
-// GetCitiesByCountry fetches cities for a given country by calling an external API.
-func (cs *CityService) GetCitiesByCountry(country string) ([]string, error) {
+// GetCitiesByCountry fetches cities for a given country, from the cache if possible,
+// otherwise by calling an external API.
+func (cs *CityService) GetCitiesByCountry(ctx context.Context, country string) ([]string, error) {
+	key := strings.ToLower(strings.TrimSpace(country))
+	if cities, ok := cs.cachedCities(key); ok {
+		return cities, nil
+	}
+
 	// Create the request body for the external API.
-	requestBody, err := json.Marshal(map[string]string{"country": country})
+	requestBody, err := json.Marshal(map[string]string{"country": strings.TrimSpace(country)})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request body: %v", err)
 	}
 
-	// Make a POST request to the external API.
-	resp, err := cs.HTTPClient.Post(cs.CitiesAPIURL, "application/json", bytes.NewBuffer(requestBody))
+	// Make a POST request to the external API, cancelled along with the caller's request.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cs.CitiesAPIURL, bytes.NewBuffer(requestBody))
 	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := cs.HTTPClient.Do(req)
+	if err != nil {
+		if isTimeout(ctx, err) {
+			return nil, fmt.Errorf("%w: %v", ErrCityLookupTimeout, err)
+		}
 		return nil, fmt.Errorf("error fetching cities: %v", err)
 	}
 	defer resp.Body.Close()
@@ -97,6 +142,9 @@ func (cs *CityService) GetCitiesByCountry(country string) ([]string, error) {
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&cityResponse); err != nil {
+		if isTimeout(ctx, err) {
+			return nil, fmt.Errorf("%w: %v", ErrCityLookupTimeout, err)
+		}
 		return nil, fmt.Errorf("error decoding cities response: %v", err)
 	}
 
@@ -105,6 +153,60 @@ func (cs *CityService) GetCitiesByCountry(country string) ([]string, error) {
 		return nil, fmt.Errorf("error fetching cities: %s", cityResponse.Msg)
 	}
 
-	// Return the list of cities on success.
+	// Cache and return the list of cities on success.
+	cs.cacheCities(key, cityResponse.Data)
 	return cityResponse.Data, nil
 }
+
+// cachedCities returns the unexpired cities cached for the normalized country key.
+func (cs *CityService) cachedCities(key string) ([]string, bool) {
+	if cs.CacheTTL <= 0 {
+		return nil, false
+	}
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	entry, ok := cs.cache[key]
+	if !ok || !cs.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.cities, true
+}
+
+// cacheCities stores the cities for the normalized country key and evicts expired entries.
+func (cs *CityService) cacheCities(key string, cities []string) {
+	if cs.CacheTTL <= 0 {
+		return
+	}
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	if cs.cache == nil {
+		cs.cache = make(map[string]cityCacheEntry)
+	}
+
+	now := cs.now()
+	for k, entry := range cs.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(cs.cache, k)
+		}
+	}
+	cs.cache[key] = cityCacheEntry{cities: cities, expiresAt: now.Add(cs.CacheTTL)}
+}
+
+// now returns the current time from the service's clock.
+func (cs *CityService) now() time.Time {
+	if cs.Now == nil {
+		return time.Now()
+	}
+	return cs.Now()
+}
+
+// isTimeout reports whether err was caused by ctx's deadline or a network timeout.
+func isTimeout(ctx context.Context, err error) bool {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -6,9 +6,21 @@
  *  @map       CountryLanguageMap
  *  @methods
  *  - GetCountryAndLanguageCode(countryName)  - Retrieves the country code and primary language code for a given country.
+ *  - GetCountryLanguages(country)            - Retrieves the country code and all language codes for a country name, alias or code.
+ *  - LookupByCode(code)                      - Retrieves the country name and language codes for an ISO country code.
+ *  - CountryLocale(country)                  - Retrieves the supported i18n locale of a country's languages.
+ *  - UserLocale(user)                        - Retrieves the locale to write to a user in, from their country.
+ *  - WithUserLocale(ctx, user)               - Stores the user's locale in ctx unless the request chose one.
+ *
+ *  @behaviors
+ *  - Each country lists its languages with the primary one first, e.g. Belgium has "nl", "fr" and "de".
+ *  - Names match case-insensitively and common aliases such as "USA", "UK" and "Korea, Republic of" are accepted.
+ *  - Countries can also be given by ISO code, as stored by the country picker.
+ *  - Users are written to in the first language of their country that has an i18n catalog, e.g.
+ *    Norwegian for Norway, and in English otherwise.
  *
  *  @dependencies
- *  - strings.Title: Used to normalize country names for case-insensitive matching.
+ *  - strings.ToLower, strings.Fields: Used to normalize country names for case-insensitive matching.
  *  - fmt.Errorf: Provides formatted error messages for unmatched countries.
  *
  *  @file      country_language.go
@@ -25,224 +37,363 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"strings"
+
+	"proh2052-group6/pkg/i18n"
+	"proh2052-group6/pkg/models"
 )
 
-// CountryLanguageMap maps country names to their two-letter ISO country codes and primary two-letter language codes.
+// CountryLanguageMap maps country names to their two-letter ISO country codes and two-letter language codes.
 var CountryLanguageMap = map[string]struct {
-	CountryCode  string
-	LanguageCode string
+	CountryCode string
+	Languages   []string // Primary language first.
 }{
-	"Afghanistan":                      {"AF", "fa"},
-	"Albania":                          {"AL", "sq"},
-	"Algeria":                          {"DZ", "ar"},
-	"Andorra":                          {"AD", "ca"},
-	"Angola":                           {"AO", "pt"},
-	"Argentina":                        {"AR", "es"},
-	"Armenia":                          {"AM", "hy"},
-	"Australia":                        {"AU", "en"},
-	"Austria":                          {"AT", "de"},
-	"Azerbaijan":                       {"AZ", "az"},
-	"Bahamas":                          {"BS", "en"},
-	"Bahrain":                          {"BH", "ar"},
-	"Bangladesh":                       {"BD", "bn"},
-	"Belarus":                          {"BY", "be"},
-	"Belgium":                          {"BE", "nl"}, // Also "fr" and "de"
-	"Belize":                           {"BZ", "en"},
-	"Benin":                            {"BJ", "fr"},
-	"Bhutan":                           {"BT", "dz"},
-	"Bolivia":                          {"BO", "es"},
-	"Bosnia and Herzegovina":           {"BA", "bs"},
-	"Botswana":                         {"BW", "en"},
-	"Brazil":                           {"BR", "pt"},
-	"Brunei":                           {"BN", "ms"},
-	"Bulgaria":                         {"BG", "bg"},
-	"Burkina Faso":                     {"BF", "fr"},
-	"Burundi":                          {"BI", "fr"},
-	"Cambodia":                         {"KH", "km"},
-	"Cameroon":                         {"CM", "fr"},
-	"Canada":                           {"CA", "en"}, // Also "fr"
-	"Cape Verde":                       {"CV", "pt"},
-	"Central African Republic":         {"CF", "fr"},
-	"Chad":                             {"TD", "fr"},
-	"Chile":                            {"CL", "es"},
-	"China":                            {"CN", "zh"},
-	"Colombia":                         {"CO", "es"},
-	"Comoros":                          {"KM", "ar"},
-	"Congo (Congo-Brazzaville)":        {"CG", "fr"},
-	"Congo (Democratic Republic)":      {"CD", "fr"},
-	"Costa Rica":                       {"CR", "es"},
-	"Croatia":                          {"HR", "hr"},
-	"Cuba":                             {"CU", "es"},
-	"Cyprus":                           {"CY", "el"},
-	"Czech Republic":                   {"CZ", "cs"},
-	"Denmark":                          {"DK", "da"},
-	"Djibouti":                         {"DJ", "fr"},
-	"Dominica":                         {"DM", "en"},
-	"Dominican Republic":               {"DO", "es"},
-	"Ecuador":                          {"EC", "es"},
-	"Egypt":                            {"EG", "ar"},
-	"El Salvador":                      {"SV", "es"},
-	"Equatorial Guinea":                {"GQ", "es"}, // Also "fr" and "pt"
-	"Eritrea":                          {"ER", "ti"},
-	"Estonia":                          {"EE", "et"},
-	"Eswatini":                         {"SZ", "en"}, // Also "ss"
-	"Ethiopia":                         {"ET", "am"},
-	"Fiji":                             {"FJ", "en"},
-	"Finland":                          {"FI", "fi"},
-	"France":                           {"FR", "fr"},
-	"Gabon":                            {"GA", "fr"},
-	"Gambia":                           {"GM", "en"},
-	"Georgia":                          {"GE", "ka"},
-	"Germany":                          {"DE", "de"},
-	"Ghana":                            {"GH", "en"},
-	"Greece":                           {"GR", "el"},
-	"Grenada":                          {"GD", "en"},
-	"Guatemala":                        {"GT", "es"},
-	"Guinea":                           {"GN", "fr"},
-	"Guinea-Bissau":                    {"GW", "pt"},
-	"Guyana":                           {"GY", "en"},
-	"Haiti":                            {"HT", "fr"}, // Also "ht"
-	"Honduras":                         {"HN", "es"},
-	"Hungary":                          {"HU", "hu"},
-	"Iceland":                          {"IS", "is"},
-	"India":                            {"IN", "hi"}, // Also "en"
-	"Indonesia":                        {"ID", "id"},
-	"Iran":                             {"IR", "fa"},
-	"Iraq":                             {"IQ", "ar"},
-	"Ireland":                          {"IE", "en"},
-	"Italy":                            {"IT", "it"},
-	"Jamaica":                          {"JM", "en"},
-	"Japan":                            {"JP", "ja"},
-	"Jordan":                           {"JO", "ar"},
-	"Kazakhstan":                       {"KZ", "kk"},
-	"Kenya":                            {"KE", "en"}, // Also "sw"
-	"Kiribati":                         {"KI", "en"},
-	"Kuwait":                           {"KW", "ar"},
-	"Kyrgyzstan":                       {"KG", "ky"},
-	"Laos":                             {"LA", "lo"},
-	"Latvia":                           {"LV", "lv"},
-	"Lebanon":                          {"LB", "ar"},
-	"Lesotho":                          {"LS", "en"},
-	"Liberia":                          {"LR", "en"},
-	"Libya":                            {"LY", "ar"},
-	"Liechtenstein":                    {"LI", "de"},
-	"Lithuania":                        {"LT", "lt"},
-	"Luxembourg":                       {"LU", "fr"}, // Also "de" and "lb"
-	"Madagascar":                       {"MG", "fr"},
-	"Malawi":                           {"MW", "en"},
-	"Malaysia":                         {"MY", "ms"},
-	"Maldives":                         {"MV", "dv"},
-	"Mali":                             {"ML", "fr"},
-	"Malta":                            {"MT", "mt"},
-	"Marshall Islands":                 {"MH", "en"},
-	"Mauritania":                       {"MR", "ar"},
-	"Mauritius":                        {"MU", "en"},
-	"Mexico":                           {"MX", "es"},
-	"Micronesia":                       {"FM", "en"},
-	"Moldova":                          {"MD", "ro"},
-	"Monaco":                           {"MC", "fr"},
-	"Mongolia":                         {"MN", "mn"},
-	"Montenegro":                       {"ME", "sr"},
-	"Morocco":                          {"MA", "ar"},
-	"Mozambique":                       {"MZ", "pt"},
-	"Myanmar":                          {"MM", "my"},
-	"Namibia":                          {"NA", "en"},
-	"Nauru":                            {"NR", "en"},
-	"Nepal":                            {"NP", "ne"},
-	"Netherlands":                      {"NL", "nl"},
-	"New Zealand":                      {"NZ", "en"},
-	"Nicaragua":                        {"NI", "es"},
-	"Niger":                            {"NE", "fr"},
-	"Nigeria":                          {"NG", "en"},
-	"North Korea":                      {"KP", "ko"},
-	"North Macedonia":                  {"MK", "mk"},
-	"Norway":                           {"NO", "no"},
-	"Oman":                             {"OM", "ar"},
-	"Pakistan":                         {"PK", "ur"},
-	"Palau":                            {"PW", "en"},
-	"Palestine":                        {"PS", "ar"},
-	"Panama":                           {"PA", "es"},
-	"Papua New Guinea":                 {"PG", "en"},
-	"Paraguay":                         {"PY", "es"},
-	"Peru":                             {"PE", "es"},
-	"Philippines":                      {"PH", "en"},
-	"Poland":                           {"PL", "pl"},
-	"Portugal":                         {"PT", "pt"},
-	"Qatar":                            {"QA", "ar"},
-	"Romania":                          {"RO", "ro"},
-	"Russia":                           {"RU", "ru"},
-	"Rwanda":                           {"RW", "rw"},
-	"Saint Kitts and Nevis":            {"KN", "en"},
-	"Saint Lucia":                      {"LC", "en"},
-	"Saint Vincent and the Grenadines": {"VC", "en"},
-	"Samoa":                            {"WS", "sm"},
-	"San Marino":                       {"SM", "it"},
-	"Saudi Arabia":                     {"SA", "ar"},
-	"Senegal":                          {"SN", "fr"},
-	"Serbia":                           {"RS", "sr"},
-	"Seychelles":                       {"SC", "fr"},
-	"Sierra Leone":                     {"SL", "en"},
-	"Singapore":                        {"SG", "en"},
-	"Slovakia":                         {"SK", "sk"},
-	"Slovenia":                         {"SI", "sl"},
-	"Solomon Islands":                  {"SB", "en"},
-	"Somalia":                          {"SO", "so"},
-	"South Africa":                     {"ZA", "en"}, // Also "af"
-	"South Korea":                      {"KR", "ko"},
-	"South Sudan":                      {"SS", "en"},
-	"Spain":                            {"ES", "es"},
-	"Sri Lanka":                        {"LK", "si"},
-	"Sudan":                            {"SD", "ar"},
-	"Suriname":                         {"SR", "nl"},
-	"Sweden":                           {"SE", "sv"},
-	"Switzerland":                      {"CH", "de"}, // Also "fr", "it", "rm"
-	"Syria":                            {"SY", "ar"},
-	"Taiwan":                           {"TW", "zh"},
-	"Tajikistan":                       {"TJ", "tg"},
-	"Tanzania":                         {"TZ", "sw"},
-	"Thailand":                         {"TH", "th"},
-	"Togo":                             {"TG", "fr"},
-	"Tonga":                            {"TO", "to"},
-	"Trinidad and Tobago":              {"TT", "en"},
-	"Tunisia":                          {"TN", "ar"},
-	"Turkey":                           {"TR", "tr"},
-	"Turkmenistan":                     {"TM", "tk"},
-	"Tuvalu":                           {"TV", "en"},
-	"Uganda":                           {"UG", "en"},
-	"Ukraine":                          {"UA", "uk"},
-	"United Arab Emirates":             {"AE", "ar"},
-	"United Kingdom":                   {"GB", "en"},
-	"United States":                    {"US", "en"},
-	"Uruguay":                          {"UY", "es"},
-	"Uzbekistan":                       {"UZ", "uz"},
-	"Vanuatu":                          {"VU", "en"},
-	"Vatican City":                     {"VA", "it"},
-	"Venezuela":                        {"VE", "es"},
-	"Vietnam":                          {"VN", "vi"},
-	"Yemen":                            {"YE", "ar"},
-	"Zambia":                           {"ZM", "en"},
-	"Zimbabwe":                         {"ZW", "en"},
+	"Afghanistan":                      {"AF", []string{"fa"}},
+	"Albania":                          {"AL", []string{"sq"}},
+	"Algeria":                          {"DZ", []string{"ar"}},
+	"Andorra":                          {"AD", []string{"ca"}},
+	"Angola":                           {"AO", []string{"pt"}},
+	"Argentina":                        {"AR", []string{"es"}},
+	"Armenia":                          {"AM", []string{"hy"}},
+	"Australia":                        {"AU", []string{"en"}},
+	"Austria":                          {"AT", []string{"de"}},
+	"Azerbaijan":                       {"AZ", []string{"az"}},
+	"Bahamas":                          {"BS", []string{"en"}},
+	"Bahrain":                          {"BH", []string{"ar"}},
+	"Bangladesh":                       {"BD", []string{"bn"}},
+	"Belarus":                          {"BY", []string{"be"}},
+	"Belgium":                          {"BE", []string{"nl", "fr", "de"}},
+	"Belize":                           {"BZ", []string{"en"}},
+	"Benin":                            {"BJ", []string{"fr"}},
+	"Bhutan":                           {"BT", []string{"dz"}},
+	"Bolivia":                          {"BO", []string{"es"}},
+	"Bosnia and Herzegovina":           {"BA", []string{"bs"}},
+	"Botswana":                         {"BW", []string{"en"}},
+	"Brazil":                           {"BR", []string{"pt"}},
+	"Brunei":                           {"BN", []string{"ms"}},
+	"Bulgaria":                         {"BG", []string{"bg"}},
+	"Burkina Faso":                     {"BF", []string{"fr"}},
+	"Burundi":                          {"BI", []string{"fr"}},
+	"Cambodia":                         {"KH", []string{"km"}},
+	"Cameroon":                         {"CM", []string{"fr"}},
+	"Canada":                           {"CA", []string{"en", "fr"}},
+	"Cape Verde":                       {"CV", []string{"pt"}},
+	"Central African Republic":         {"CF", []string{"fr"}},
+	"Chad":                             {"TD", []string{"fr"}},
+	"Chile":                            {"CL", []string{"es"}},
+	"China":                            {"CN", []string{"zh"}},
+	"Colombia":                         {"CO", []string{"es"}},
+	"Comoros":                          {"KM", []string{"ar"}},
+	"Congo (Congo-Brazzaville)":        {"CG", []string{"fr"}},
+	"Congo (Democratic Republic)":      {"CD", []string{"fr"}},
+	"Costa Rica":                       {"CR", []string{"es"}},
+	"Croatia":                          {"HR", []string{"hr"}},
+	"Cuba":                             {"CU", []string{"es"}},
+	"Cyprus":                           {"CY", []string{"el", "tr"}},
+	"Czech Republic":                   {"CZ", []string{"cs"}},
+	"Denmark":                          {"DK", []string{"da"}},
+	"Djibouti":                         {"DJ", []string{"fr"}},
+	"Dominica":                         {"DM", []string{"en"}},
+	"Dominican Republic":               {"DO", []string{"es"}},
+	"Ecuador":                          {"EC", []string{"es"}},
+	"Egypt":                            {"EG", []string{"ar"}},
+	"El Salvador":                      {"SV", []string{"es"}},
+	"Equatorial Guinea":                {"GQ", []string{"es", "fr", "pt"}},
+	"Eritrea":                          {"ER", []string{"ti"}},
+	"Estonia":                          {"EE", []string{"et"}},
+	"Eswatini":                         {"SZ", []string{"en", "ss"}},
+	"Ethiopia":                         {"ET", []string{"am"}},
+	"Fiji":                             {"FJ", []string{"en"}},
+	"Finland":                          {"FI", []string{"fi", "sv"}},
+	"France":                           {"FR", []string{"fr"}},
+	"Gabon":                            {"GA", []string{"fr"}},
+	"Gambia":                           {"GM", []string{"en"}},
+	"Georgia":                          {"GE", []string{"ka"}},
+	"Germany":                          {"DE", []string{"de"}},
+	"Ghana":                            {"GH", []string{"en"}},
+	"Greece":                           {"GR", []string{"el"}},
+	"Grenada":                          {"GD", []string{"en"}},
+	"Guatemala":                        {"GT", []string{"es"}},
+	"Guinea":                           {"GN", []string{"fr"}},
+	"Guinea-Bissau":                    {"GW", []string{"pt"}},
+	"Guyana":                           {"GY", []string{"en"}},
+	"Haiti":                            {"HT", []string{"fr", "ht"}},
+	"Honduras":                         {"HN", []string{"es"}},
+	"Hungary":                          {"HU", []string{"hu"}},
+	"Iceland":                          {"IS", []string{"is"}},
+	"India":                            {"IN", []string{"hi", "en"}},
+	"Indonesia":                        {"ID", []string{"id"}},
+	"Iran":                             {"IR", []string{"fa"}},
+	"Iraq":                             {"IQ", []string{"ar"}},
+	"Ireland":                          {"IE", []string{"en", "ga"}},
+	"Italy":                            {"IT", []string{"it"}},
+	"Jamaica":                          {"JM", []string{"en"}},
+	"Japan":                            {"JP", []string{"ja"}},
+	"Jordan":                           {"JO", []string{"ar"}},
+	"Kazakhstan":                       {"KZ", []string{"kk"}},
+	"Kenya":                            {"KE", []string{"en", "sw"}},
+	"Kiribati":                         {"KI", []string{"en"}},
+	"Kuwait":                           {"KW", []string{"ar"}},
+	"Kyrgyzstan":                       {"KG", []string{"ky"}},
+	"Laos":                             {"LA", []string{"lo"}},
+	"Latvia":                           {"LV", []string{"lv"}},
+	"Lebanon":                          {"LB", []string{"ar"}},
+	"Lesotho":                          {"LS", []string{"en"}},
+	"Liberia":                          {"LR", []string{"en"}},
+	"Libya":                            {"LY", []string{"ar"}},
+	"Liechtenstein":                    {"LI", []string{"de"}},
+	"Lithuania":                        {"LT", []string{"lt"}},
+	"Luxembourg":                       {"LU", []string{"fr", "de", "lb"}},
+	"Madagascar":                       {"MG", []string{"fr"}},
+	"Malawi":                           {"MW", []string{"en"}},
+	"Malaysia":                         {"MY", []string{"ms"}},
+	"Maldives":                         {"MV", []string{"dv"}},
+	"Mali":                             {"ML", []string{"fr"}},
+	"Malta":                            {"MT", []string{"mt", "en"}},
+	"Marshall Islands":                 {"MH", []string{"en"}},
+	"Mauritania":                       {"MR", []string{"ar"}},
+	"Mauritius":                        {"MU", []string{"en"}},
+	"Mexico":                           {"MX", []string{"es"}},
+	"Micronesia":                       {"FM", []string{"en"}},
+	"Moldova":                          {"MD", []string{"ro"}},
+	"Monaco":                           {"MC", []string{"fr"}},
+	"Mongolia":                         {"MN", []string{"mn"}},
+	"Montenegro":                       {"ME", []string{"sr"}},
+	"Morocco":                          {"MA", []string{"ar"}},
+	"Mozambique":                       {"MZ", []string{"pt"}},
+	"Myanmar":                          {"MM", []string{"my"}},
+	"Namibia":                          {"NA", []string{"en"}},
+	"Nauru":                            {"NR", []string{"en"}},
+	"Nepal":                            {"NP", []string{"ne"}},
+	"Netherlands":                      {"NL", []string{"nl"}},
+	"New Zealand":                      {"NZ", []string{"en"}},
+	"Nicaragua":                        {"NI", []string{"es"}},
+	"Niger":                            {"NE", []string{"fr"}},
+	"Nigeria":                          {"NG", []string{"en"}},
+	"North Korea":                      {"KP", []string{"ko"}},
+	"North Macedonia":                  {"MK", []string{"mk"}},
+	"Norway":                           {"NO", []string{"no"}},
+	"Oman":                             {"OM", []string{"ar"}},
+	"Pakistan":                         {"PK", []string{"ur"}},
+	"Palau":                            {"PW", []string{"en"}},
+	"Palestine":                        {"PS", []string{"ar"}},
+	"Panama":                           {"PA", []string{"es"}},
+	"Papua New Guinea":                 {"PG", []string{"en"}},
+	"Paraguay":                         {"PY", []string{"es"}},
+	"Peru":                             {"PE", []string{"es"}},
+	"Philippines":                      {"PH", []string{"en"}},
+	"Poland":                           {"PL", []string{"pl"}},
+	"Portugal":                         {"PT", []string{"pt"}},
+	"Qatar":                            {"QA", []string{"ar"}},
+	"Romania":                          {"RO", []string{"ro"}},
+	"Russia":                           {"RU", []string{"ru"}},
+	"Rwanda":                           {"RW", []string{"rw"}},
+	"Saint Kitts and Nevis":            {"KN", []string{"en"}},
+	"Saint Lucia":                      {"LC", []string{"en"}},
+	"Saint Vincent and the Grenadines": {"VC", []string{"en"}},
+	"Samoa":                            {"WS", []string{"sm"}},
+	"San Marino":                       {"SM", []string{"it"}},
+	"Saudi Arabia":                     {"SA", []string{"ar"}},
+	"Senegal":                          {"SN", []string{"fr"}},
+	"Serbia":                           {"RS", []string{"sr"}},
+	"Seychelles":                       {"SC", []string{"fr"}},
+	"Sierra Leone":                     {"SL", []string{"en"}},
+	"Singapore":                        {"SG", []string{"en", "ms", "zh", "ta"}},
+	"Slovakia":                         {"SK", []string{"sk"}},
+	"Slovenia":                         {"SI", []string{"sl"}},
+	"Solomon Islands":                  {"SB", []string{"en"}},
+	"Somalia":                          {"SO", []string{"so"}},
+	"South Africa":                     {"ZA", []string{"en", "af"}},
+	"South Korea":                      {"KR", []string{"ko"}},
+	"South Sudan":                      {"SS", []string{"en"}},
+	"Spain":                            {"ES", []string{"es"}},
+	"Sri Lanka":                        {"LK", []string{"si"}},
+	"Sudan":                            {"SD", []string{"ar"}},
+	"Suriname":                         {"SR", []string{"nl"}},
+	"Sweden":                           {"SE", []string{"sv"}},
+	"Switzerland":                      {"CH", []string{"de", "fr", "it", "rm"}},
+	"Syria":                            {"SY", []string{"ar"}},
+	"Taiwan":                           {"TW", []string{"zh"}},
+	"Tajikistan":                       {"TJ", []string{"tg"}},
+	"Tanzania":                         {"TZ", []string{"sw"}},
+	"Thailand":                         {"TH", []string{"th"}},
+	"Togo":                             {"TG", []string{"fr"}},
+	"Tonga":                            {"TO", []string{"to"}},
+	"Trinidad and Tobago":              {"TT", []string{"en"}},
+	"Tunisia":                          {"TN", []string{"ar"}},
+	"Turkey":                           {"TR", []string{"tr"}},
+	"Turkmenistan":                     {"TM", []string{"tk"}},
+	"Tuvalu":                           {"TV", []string{"en"}},
+	"Uganda":                           {"UG", []string{"en"}},
+	"Ukraine":                          {"UA", []string{"uk"}},
+	"United Arab Emirates":             {"AE", []string{"ar"}},
+	"United Kingdom":                   {"GB", []string{"en"}},
+	"United States":                    {"US", []string{"en"}},
+	"Uruguay":                          {"UY", []string{"es"}},
+	"Uzbekistan":                       {"UZ", []string{"uz"}},
+	"Vanuatu":                          {"VU", []string{"en"}},
+	"Vatican City":                     {"VA", []string{"it"}},
+	"Venezuela":                        {"VE", []string{"es"}},
+	"Vietnam":                          {"VN", []string{"vi"}},
+	"Yemen":                            {"YE", []string{"ar"}},
+	"Zambia":                           {"ZM", []string{"en"}},
+	"Zimbabwe":                         {"ZW", []string{"en"}},
+}
+
+// countryAliases maps common alternative country names, normalized, to their names in CountryLanguageMap.
+var countryAliases = map[string]string{
+	"usa":                                    "United States",
+	"us":                                     "United States",
+	"united states of america":               "United States",
+	"america":                                "United States",
+	"uk":                                     "United Kingdom",
+	"great britain":                          "United Kingdom",
+	"britain":                                "United Kingdom",
+	"korea":                                  "South Korea",
+	"korea, republic of":                     "South Korea",
+	"republic of korea":                      "South Korea",
+	"korea, democratic people's republic of": "North Korea",
+	"uae":                                    "United Arab Emirates",
+	"russian federation":                     "Russia",
+	"czechia":                                "Czech Republic",
+	"the netherlands":                        "Netherlands",
+	"holland":                                "Netherlands",
+	"türkiye":                                "Turkey",
+	"turkiye":                                "Turkey",
+	"viet nam":                               "Vietnam",
+	"swaziland":                              "Eswatini",
+	"macedonia":                              "North Macedonia",
+	"burma":                                  "Myanmar",
+	"drc":                                    "Congo (Democratic Republic)",
+	"democratic republic of the congo":       "Congo (Democratic Republic)",
+	"republic of the congo":                  "Congo (Congo-Brazzaville)",
+	"holy see":                               "Vatican City",
+}
+
+// countryLanguageIndex maps normalized country names and aliases to their CountryLanguageMap entries.
+var countryLanguageIndex = buildCountryLanguageIndex()
+
+// countryCodeIndex maps upper-case ISO country codes to their CountryLanguageMap entries.
+var countryCodeIndex = buildCountryCodeIndex()
+
+// buildCountryLanguageIndex indexes CountryLanguageMap by normalized country name and alias.
+func buildCountryLanguageIndex() map[string]string {
+	index := make(map[string]string, len(CountryLanguageMap)+len(countryAliases))
+	for alias, name := range countryAliases {
+		index[alias] = name
+	}
+	for name := range CountryLanguageMap {
+		index[normalizeCountryName(name)] = name
+	}
+	return index
+}
+
+// buildCountryCodeIndex indexes CountryLanguageMap by ISO country code.
+func buildCountryCodeIndex() map[string]string {
+	index := make(map[string]string, len(CountryLanguageMap))
+	for name, entry := range CountryLanguageMap {
+		index[entry.CountryCode] = name
+	}
+	return index
+}
+
+// normalizeCountryName lowercases a country name and collapses its whitespace,
+// so "united  States" and "United States" compare equal.
+func normalizeCountryName(countryName string) string {
+	return strings.Join(strings.Fields(strings.ToLower(countryName)), " ")
+}
+
+// LookupByCode retrieves the name and language codes of the country with the given ISO code.
+// Parameters:
+//   - code (string): The two-letter ISO country code (case-insensitive), e.g. "be".
+//
+// Returns:
+//   - string: The country name, e.g. "Belgium".
+//   - []string: Lower-case language codes, primary first, e.g. ["nl", "fr", "de"].
+//   - error: Returns an error if no country has the code.
+func LookupByCode(code string) (string, []string, error) {
+	name, exists := countryCodeIndex[strings.ToUpper(strings.TrimSpace(code))]
+	if !exists {
+		return "", nil, fmt.Errorf("country code not found in map: %s", code)
+	}
+	return name, countryLanguages(name), nil
 }
 
-// GetCountryAndLanguageCode retrieves the country code and primary language code for a given country name.
+// GetCountryLanguages retrieves the country code and language codes for a country given by name,
+// common alias or ISO code.
 // Parameters:
-//   - countryName (string): The name of the country (case-insensitive).
+//   - country (string): The country (case-insensitive), e.g. "Switzerland", "USA" or "ch".
+//
+// Returns:
+//   - string: Lower-case ISO country code, e.g. "ch".
+//   - []string: Lower-case language codes, primary first, e.g. ["de", "fr", "it", "rm"].
+//   - error: Returns an error if the country is not found in the map.
+func GetCountryLanguages(country string) (string, []string, error) {
+	name, exists := countryLanguageIndex[normalizeCountryName(country)]
+	if !exists {
+		var err error
+		if name, _, err = LookupByCode(country); err != nil {
+			return "", nil, fmt.Errorf("country not found in map: %s", country)
+		}
+	}
+	return strings.ToLower(CountryLanguageMap[name].CountryCode), countryLanguages(name), nil
+}
+
+// countryLanguages returns a lower-case copy of the language codes of the named country.
+func countryLanguages(name string) []string {
+	languages := make([]string, len(CountryLanguageMap[name].Languages))
+	for i, language := range CountryLanguageMap[name].Languages {
+		languages[i] = strings.ToLower(language)
+	}
+	return languages
+}
+
+// GetCountryAndLanguageCode retrieves the country code and primary language code for a country
+// given by name, common alias or ISO code.
+// Parameters:
+//   - countryName (string): The name of the country (case-insensitive), e.g. "united states" or "Bosnia and Herzegovina".
 //
 // Returns:
 //   - string: ISO country code (e.g., "US" for the United States).
 //   - string: Primary language code (e.g., "en" for English).
 //   - error: Returns an error if the country is not found in the map.
 func GetCountryAndLanguageCode(countryName string) (string, string, error) {
-	// Normalize the country name for case-insensitive matching.
-	normalizedCountryName := strings.Title(strings.ToLower(countryName))
+	countryCode, languages, err := GetCountryLanguages(countryName)
+	if err != nil {
+		return "", "", err
+	}
+	return countryCode, languages[0], nil
+}
 
-	// Retrieve the country and language codes from the map.
-	if entry, exists := CountryLanguageMap[normalizedCountryName]; exists {
-		return strings.ToLower(entry.CountryCode), strings.ToLower(entry.LanguageCode), nil
+// CountryLocale returns the supported i18n locale of a country given by name, alias or ISO code: the
+// first of its languages there is a catalog for, e.g. "nb" for Norway. The boolean is false if the
+// country is unknown or none of its languages is supported.
+func CountryLocale(country string) (string, bool) {
+	_, languages, err := GetCountryLanguages(country)
+	if err != nil {
+		return "", false
 	}
+	return i18n.MatchLanguages(languages)
+}
 
-	// Return an error if the country is not found.
-	return "", "", fmt.Errorf("country not found in map: %s", countryName)
+// UserLocale returns the locale to write to user in: the language of their country if it is
+// supported, or i18n.DefaultLocale. A nil user gets the default.
+func UserLocale(user *models.User) string {
+	if user != nil {
+		if locale, ok := CountryLocale(user.Country); ok {
+			return locale
+		}
+	}
+	return i18n.DefaultLocale
+}
+
+// WithUserLocale returns ctx carrying the locale to answer user in. A locale the client chose for the
+// request is kept; otherwise the user's UserLocale is stored.
+func WithUserLocale(ctx context.Context, user *models.User) context.Context {
+	if _, ok := i18n.FromContext(ctx); ok {
+		return ctx
+	}
+	return i18n.WithLocale(ctx, UserLocale(user))
 }
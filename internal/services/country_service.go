@@ -1,36 +1,40 @@
 /**
- *  CountryService provides functionality to retrieve country data from an external API
- *  and filter the results based on a search query. This service integrates with a RESTful
- *  countries API to fetch information about all countries.
+ *  CountryService searches countries by name or code for the signup form's country picker.
+ *  By default it serves the bundled CountryLanguageMap, so it works without network access;
+ *  the RESTful countries API can be opted into instead when constructing the service.
  *
- *  @struct   Country
- *  @inherits None
+ *  @interface CountryServiceInterface
+ *  @struct   CountryService
  *
  *  @methods
- *  - SetCountryHTTPClient(client)       - Sets a custom HTTP client for API requests (useful for testing).
- *  - SetCountriesAPIURL(url)            - Sets the API endpoint for fetching country data.
- *  - GetCountries(searchQuery)          - Fetches and filters country data based on the search query.
+ *  - NewCountryService(source)             - Initializes a CountryService reading from CountrySourceLocal or CountrySourceExternal.
+ *  - SearchCountries(ctx, searchQuery)     - Returns the countries matching the search query.
+ *  - SetCountryHTTPClient(client)          - Sets a custom HTTP client for API requests (useful for testing).
+ *  - SetCountriesAPIURL(url)               - Sets the API endpoint for fetching country data.
  *
  *  @behaviors
- *  - Retrieves data from the countries API, defined in `config.CountriesAPIURL`.
- *  - Filters countries by name, matching the search query with a case-insensitive prefix.
+ *  - The local country list is built from CountryLanguageMap once, sorted by name.
+ *  - In external mode, retrieves data from the countries API, defined in `config.CountriesAPIURL`.
+ *  - Matches names case-insensitively by prefix, then by substring, and requires at least 3 characters.
+ *  - A two-letter search matches the country with that ISO code exactly, e.g. "NO" returns Norway.
  *  - Ensures graceful handling of errors during API calls or JSON decoding.
  *
  *  @dependencies
+ *  - CountryLanguageMap: Local list of country names and codes.
  *  - config.CountriesAPIURL: Configuration variable for the countries API endpoint.
- *  - http.Client: HTTP client used for API requests.
+ *  - httpclient.New: Builds the HTTP client used for API requests, with timeouts and retries.
  *  - json: Used for decoding JSON responses from the API.
  *
  *  @example
  *  ```
- *  // Fetch countries starting with "nor"
- *  SetCountriesAPIURL("https://restcountries.com/v3.1/all")
- *  countries, err := GetCountries("nor")
+ *  countryService := services.NewCountryService(services.CountrySourceLocal)
+ *  countries, err := countryService.SearchCountries(ctx, "nor")
  *
  *  Response:
  *  [
- *      { "name": "Norway", "code": "NO" },
- *      { "name": "Northern Ireland", "code": "GB" }
+ *      { "name": "North Korea", "code": "KP" },
+ *      { "name": "North Macedonia", "code": "MK" },
+ *      { "name": "Norway", "code": "NO" }
  *  ]
  *  ```
  *
@@ -42,10 +46,13 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"proh2052-group6/internal/config"
+	"proh2052-group6/pkg/httpclient"
+	"sort"
 	"strings"
 )
 
@@ -55,8 +62,45 @@ type Country struct {
 	Code string `json:"code"`
 }
 
+// CountrySource selects where CountryService reads its country list from.
+type CountrySource int
+
+const (
+	// CountrySourceLocal serves countries from CountryLanguageMap, without network access.
+	CountrySourceLocal CountrySource = iota
+	// CountrySourceExternal fetches countries from the external API on every search.
+	CountrySourceExternal
+)
+
+// minCountrySearchLength is the shortest search matched against country names.
+const minCountrySearchLength = 3
+
+// CountryServiceInterface defines the contract for searching countries.
+type CountryServiceInterface interface {
+	// SearchCountries returns the countries matching the search query.
+	SearchCountries(ctx context.Context, searchQuery string) ([]Country, error)
+}
+
+// CountryService implements CountryServiceInterface.
+type CountryService struct {
+	Source    CountrySource // Where the country list is read from.
+	countries []Country     // Local country list, sorted by name.
+}
+
+// NewCountryService initializes a CountryService reading from the given source.
+// The local country list is built once, here.
+func NewCountryService(source CountrySource) CountryServiceInterface {
+	countries := make([]Country, 0, len(CountryLanguageMap))
+	for name, entry := range CountryLanguageMap {
+		countries = append(countries, Country{Name: name, Code: entry.CountryCode})
+	}
+	sort.Slice(countries, func(i, j int) bool { return countries[i].Name < countries[j].Name })
+
+	return &CountryService{Source: source, countries: countries}
+}
+
 var (
-	countryHTTPClient = http.DefaultClient // Default HTTP client for making API calls.
+	countryHTTPClient = httpclient.New(httpclient.DefaultConfig()) // HTTP client for making API calls, with timeouts and retries.
 )
 
 // SetCountryHTTPClient allows setting a custom HTTP client for testing or customization.
@@ -69,11 +113,61 @@ func SetCountriesAPIURL(url string) {
 	config.CountriesAPIURL = url
 }
 
-// GetCountries fetches and filters country data based on a search query.
-// Returns a list of countries whose names start with the given query.
-func GetCountries(searchQuery string) ([]Country, error) {
-	// Fetch data from the countries API.
-	resp, err := countryHTTPClient.Get(config.CountriesAPIURL)
+// SearchCountries returns the countries whose names start with or contain the search query,
+// prefix matches first, or the country whose two-letter code equals it. Matching is case-insensitive.
+// Queries that are neither a two-letter code nor at least three characters long match nothing.
+func (cs *CountryService) SearchCountries(ctx context.Context, searchQuery string) ([]Country, error) {
+	searchQuery = strings.ToLower(strings.TrimSpace(searchQuery))
+	if len(searchQuery) != 2 && len(searchQuery) < minCountrySearchLength {
+		return []Country{}, nil
+	}
+
+	countries := cs.countries
+	if cs.Source == CountrySourceExternal {
+		var err error
+		countries, err = fetchCountries(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filterCountries(countries, searchQuery), nil
+}
+
+// filterCountries matches an exact two-letter code, or names by prefix and then by substring.
+// The search query must already be lowercase.
+func filterCountries(countries []Country, searchQuery string) []Country {
+	if len(searchQuery) == 2 {
+		for _, country := range countries {
+			if strings.EqualFold(country.Code, searchQuery) {
+				return []Country{country}
+			}
+		}
+		return []Country{}
+	}
+
+	prefixMatches := []Country{}
+	var substringMatches []Country
+	for _, country := range countries {
+		countryName := strings.ToLower(country.Name)
+		switch {
+		case strings.HasPrefix(countryName, searchQuery):
+			prefixMatches = append(prefixMatches, country)
+		case strings.Contains(countryName, searchQuery):
+			substringMatches = append(substringMatches, country)
+		}
+	}
+	return append(prefixMatches, substringMatches...)
+}
+
+// fetchCountries fetches all countries from the external countries API.
+func fetchCountries(ctx context.Context) ([]Country, error) {
+	// Fetch data from the countries API, cancelled along with the caller's request.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, config.CountriesAPIURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching countries: %v", err)
+	}
+	resp, err := countryHTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("Error fetching countries: %v", err)
 	}
@@ -91,17 +185,9 @@ func GetCountries(searchQuery string) ([]Country, error) {
 		return nil, fmt.Errorf("Error decoding response: %v", err)
 	}
 
-	// Filter countries by the search query (case-insensitive prefix match).
-	var countries []Country
+	countries := make([]Country, 0, len(countriesData))
 	for _, country := range countriesData {
-		countryName := strings.ToLower(country.Name.Common)
-		if strings.HasPrefix(countryName, searchQuery) {
-			countries = append(countries, Country{
-				Name: country.Name.Common,
-				Code: country.CCA2,
-			})
-		}
+		countries = append(countries, Country{Name: country.Name.Common, Code: country.CCA2})
 	}
-
 	return countries, nil
 }
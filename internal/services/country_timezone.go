@@ -0,0 +1,285 @@
+/**
+ *  CountryTimezoneMap maps the countries of CountryLanguageMap to an IANA time zone, so times that
+ *  matter to a user, such as when their weekly digest is sent or the times of their events, follow
+ *  their local clock.
+ *
+ *  @map       CountryTimezoneMap
+ *  @methods
+ *  - LocationForCountry(countryName)  - Returns the time zone of a country, or UTC for unknown countries.
+ *  - LocationForUser(user)            - Returns the user's time zone setting, or else the time zone of their country.
+ *  - LoadTimeZone(name)               - Loads an IANA time zone name, returning ErrInvalidTimeZone if it is unknown.
+ *
+ *  @behaviors
+ *  - Countries spanning several time zones map to the zone of their capital or largest city; users
+ *    elsewhere in those countries can set their own zone in their profile.
+ *  - The time zone database is embedded with time/tzdata, so zones load on hosts without one.
+ *
+ *  @file      country_timezone.go
+ *  @project   DailyVerse
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	_ "time/tzdata" // Embeds the time zone database for hosts without one.
+
+	"proh2052-group6/pkg/models"
+)
+
+// ErrInvalidTimeZone is returned for a time zone that is not an IANA time zone name.
+var ErrInvalidTimeZone = errors.New("Invalid time zone")
+
+// CountryTimezoneMap maps country names to IANA time zone names.
+var CountryTimezoneMap = map[string]string{
+	"Afghanistan":                      "Asia/Kabul",
+	"Albania":                          "Europe/Tirane",
+	"Algeria":                          "Africa/Algiers",
+	"Andorra":                          "Europe/Andorra",
+	"Angola":                           "Africa/Luanda",
+	"Argentina":                        "America/Argentina/Buenos_Aires",
+	"Armenia":                          "Asia/Yerevan",
+	"Australia":                        "Australia/Sydney",
+	"Austria":                          "Europe/Vienna",
+	"Azerbaijan":                       "Asia/Baku",
+	"Bahamas":                          "America/Nassau",
+	"Bahrain":                          "Asia/Bahrain",
+	"Bangladesh":                       "Asia/Dhaka",
+	"Belarus":                          "Europe/Minsk",
+	"Belgium":                          "Europe/Brussels",
+	"Belize":                           "America/Belize",
+	"Benin":                            "Africa/Porto-Novo",
+	"Bhutan":                           "Asia/Thimphu",
+	"Bolivia":                          "America/La_Paz",
+	"Bosnia and Herzegovina":           "Europe/Sarajevo",
+	"Botswana":                         "Africa/Gaborone",
+	"Brazil":                           "America/Sao_Paulo",
+	"Brunei":                           "Asia/Brunei",
+	"Bulgaria":                         "Europe/Sofia",
+	"Burkina Faso":                     "Africa/Ouagadougou",
+	"Burundi":                          "Africa/Bujumbura",
+	"Cambodia":                         "Asia/Phnom_Penh",
+	"Cameroon":                         "Africa/Douala",
+	"Canada":                           "America/Toronto",
+	"Cape Verde":                       "Atlantic/Cape_Verde",
+	"Central African Republic":         "Africa/Bangui",
+	"Chad":                             "Africa/Ndjamena",
+	"Chile":                            "America/Santiago",
+	"China":                            "Asia/Shanghai",
+	"Colombia":                         "America/Bogota",
+	"Comoros":                          "Indian/Comoro",
+	"Congo (Congo-Brazzaville)":        "Africa/Brazzaville",
+	"Congo (Democratic Republic)":      "Africa/Kinshasa",
+	"Costa Rica":                       "America/Costa_Rica",
+	"Croatia":                          "Europe/Zagreb",
+	"Cuba":                             "America/Havana",
+	"Cyprus":                           "Asia/Nicosia",
+	"Czech Republic":                   "Europe/Prague",
+	"Denmark":                          "Europe/Copenhagen",
+	"Djibouti":                         "Africa/Djibouti",
+	"Dominica":                         "America/Dominica",
+	"Dominican Republic":               "America/Santo_Domingo",
+	"Ecuador":                          "America/Guayaquil",
+	"Egypt":                            "Africa/Cairo",
+	"El Salvador":                      "America/El_Salvador",
+	"Equatorial Guinea":                "Africa/Malabo",
+	"Eritrea":                          "Africa/Asmara",
+	"Estonia":                          "Europe/Tallinn",
+	"Eswatini":                         "Africa/Mbabane",
+	"Ethiopia":                         "Africa/Addis_Ababa",
+	"Fiji":                             "Pacific/Fiji",
+	"Finland":                          "Europe/Helsinki",
+	"France":                           "Europe/Paris",
+	"Gabon":                            "Africa/Libreville",
+	"Gambia":                           "Africa/Banjul",
+	"Georgia":                          "Asia/Tbilisi",
+	"Germany":                          "Europe/Berlin",
+	"Ghana":                            "Africa/Accra",
+	"Greece":                           "Europe/Athens",
+	"Grenada":                          "America/Grenada",
+	"Guatemala":                        "America/Guatemala",
+	"Guinea":                           "Africa/Conakry",
+	"Guinea-Bissau":                    "Africa/Bissau",
+	"Guyana":                           "America/Guyana",
+	"Haiti":                            "America/Port-au-Prince",
+	"Honduras":                         "America/Tegucigalpa",
+	"Hungary":                          "Europe/Budapest",
+	"Iceland":                          "Atlantic/Reykjavik",
+	"India":                            "Asia/Kolkata",
+	"Indonesia":                        "Asia/Jakarta",
+	"Iran":                             "Asia/Tehran",
+	"Iraq":                             "Asia/Baghdad",
+	"Ireland":                          "Europe/Dublin",
+	"Italy":                            "Europe/Rome",
+	"Jamaica":                          "America/Jamaica",
+	"Japan":                            "Asia/Tokyo",
+	"Jordan":                           "Asia/Amman",
+	"Kazakhstan":                       "Asia/Almaty",
+	"Kenya":                            "Africa/Nairobi",
+	"Kiribati":                         "Pacific/Tarawa",
+	"Kuwait":                           "Asia/Kuwait",
+	"Kyrgyzstan":                       "Asia/Bishkek",
+	"Laos":                             "Asia/Vientiane",
+	"Latvia":                           "Europe/Riga",
+	"Lebanon":                          "Asia/Beirut",
+	"Lesotho":                          "Africa/Maseru",
+	"Liberia":                          "Africa/Monrovia",
+	"Libya":                            "Africa/Tripoli",
+	"Liechtenstein":                    "Europe/Vaduz",
+	"Lithuania":                        "Europe/Vilnius",
+	"Luxembourg":                       "Europe/Luxembourg",
+	"Madagascar":                       "Indian/Antananarivo",
+	"Malawi":                           "Africa/Blantyre",
+	"Malaysia":                         "Asia/Kuala_Lumpur",
+	"Maldives":                         "Indian/Maldives",
+	"Mali":                             "Africa/Bamako",
+	"Malta":                            "Europe/Malta",
+	"Marshall Islands":                 "Pacific/Majuro",
+	"Mauritania":                       "Africa/Nouakchott",
+	"Mauritius":                        "Indian/Mauritius",
+	"Mexico":                           "America/Mexico_City",
+	"Micronesia":                       "Pacific/Pohnpei",
+	"Moldova":                          "Europe/Chisinau",
+	"Monaco":                           "Europe/Monaco",
+	"Mongolia":                         "Asia/Ulaanbaatar",
+	"Montenegro":                       "Europe/Podgorica",
+	"Morocco":                          "Africa/Casablanca",
+	"Mozambique":                       "Africa/Maputo",
+	"Myanmar":                          "Asia/Yangon",
+	"Namibia":                          "Africa/Windhoek",
+	"Nauru":                            "Pacific/Nauru",
+	"Nepal":                            "Asia/Kathmandu",
+	"Netherlands":                      "Europe/Amsterdam",
+	"New Zealand":                      "Pacific/Auckland",
+	"Nicaragua":                        "America/Managua",
+	"Niger":                            "Africa/Niamey",
+	"Nigeria":                          "Africa/Lagos",
+	"North Korea":                      "Asia/Pyongyang",
+	"North Macedonia":                  "Europe/Skopje",
+	"Norway":                           "Europe/Oslo",
+	"Oman":                             "Asia/Muscat",
+	"Pakistan":                         "Asia/Karachi",
+	"Palau":                            "Pacific/Palau",
+	"Palestine":                        "Asia/Gaza",
+	"Panama":                           "America/Panama",
+	"Papua New Guinea":                 "Pacific/Port_Moresby",
+	"Paraguay":                         "America/Asuncion",
+	"Peru":                             "America/Lima",
+	"Philippines":                      "Asia/Manila",
+	"Poland":                           "Europe/Warsaw",
+	"Portugal":                         "Europe/Lisbon",
+	"Qatar":                            "Asia/Qatar",
+	"Romania":                          "Europe/Bucharest",
+	"Russia":                           "Europe/Moscow",
+	"Rwanda":                           "Africa/Kigali",
+	"Saint Kitts and Nevis":            "America/St_Kitts",
+	"Saint Lucia":                      "America/St_Lucia",
+	"Saint Vincent and the Grenadines": "America/St_Vincent",
+	"Samoa":                            "Pacific/Apia",
+	"San Marino":                       "Europe/San_Marino",
+	"Saudi Arabia":                     "Asia/Riyadh",
+	"Senegal":                          "Africa/Dakar",
+	"Serbia":                           "Europe/Belgrade",
+	"Seychelles":                       "Indian/Mahe",
+	"Sierra Leone":                     "Africa/Freetown",
+	"Singapore":                        "Asia/Singapore",
+	"Slovakia":                         "Europe/Bratislava",
+	"Slovenia":                         "Europe/Ljubljana",
+	"Solomon Islands":                  "Pacific/Guadalcanal",
+	"Somalia":                          "Africa/Mogadishu",
+	"South Africa":                     "Africa/Johannesburg",
+	"South Korea":                      "Asia/Seoul",
+	"South Sudan":                      "Africa/Juba",
+	"Spain":                            "Europe/Madrid",
+	"Sri Lanka":                        "Asia/Colombo",
+	"Sudan":                            "Africa/Khartoum",
+	"Suriname":                         "America/Paramaribo",
+	"Sweden":                           "Europe/Stockholm",
+	"Switzerland":                      "Europe/Zurich",
+	"Syria":                            "Asia/Damascus",
+	"Taiwan":                           "Asia/Taipei",
+	"Tajikistan":                       "Asia/Dushanbe",
+	"Tanzania":                         "Africa/Dar_es_Salaam",
+	"Thailand":                         "Asia/Bangkok",
+	"Togo":                             "Africa/Lome",
+	"Tonga":                            "Pacific/Tongatapu",
+	"Trinidad and Tobago":              "America/Port_of_Spain",
+	"Tunisia":                          "Africa/Tunis",
+	"Turkey":                           "Europe/Istanbul",
+	"Turkmenistan":                     "Asia/Ashgabat",
+	"Tuvalu":                           "Pacific/Funafuti",
+	"Uganda":                           "Africa/Kampala",
+	"Ukraine":                          "Europe/Kyiv",
+	"United Arab Emirates":             "Asia/Dubai",
+	"United Kingdom":                   "Europe/London",
+	"United States":                    "America/New_York",
+	"Uruguay":                          "America/Montevideo",
+	"Uzbekistan":                       "Asia/Tashkent",
+	"Vanuatu":                          "Pacific/Efate",
+	"Vatican City":                     "Europe/Vatican",
+	"Venezuela":                        "America/Caracas",
+	"Vietnam":                          "Asia/Ho_Chi_Minh",
+	"Yemen":                            "Asia/Aden",
+	"Zambia":                           "Africa/Lusaka",
+	"Zimbabwe":                         "Africa/Harare",
+}
+
+// LocationForCountry returns the time zone of the named country, matching the name case-insensitively.
+// Unknown countries, and zones that fail to load, fall back to UTC.
+func LocationForCountry(countryName string) *time.Location {
+	location, ok := countryLocation(countryName)
+	if !ok {
+		return time.UTC
+	}
+	return location
+}
+
+// LocationForUser returns the user's time zone: their TimeZone setting if it is valid, otherwise the
+// time zone of their country. ok is false, and the location UTC, if neither is known.
+func LocationForUser(user *models.User) (location *time.Location, ok bool) {
+	if user == nil {
+		return time.UTC, false
+	}
+	if location, err := LoadTimeZone(user.TimeZone); err == nil {
+		return location, true
+	}
+	if location, ok := countryLocation(user.Country); ok {
+		return location, true
+	}
+	return time.UTC, false
+}
+
+// LoadTimeZone loads an IANA time zone name such as "Europe/Oslo". The server's own zone, "Local",
+// is not accepted, since it differs between hosts.
+func LoadTimeZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return nil, ErrInvalidTimeZone
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+	return location, nil
+}
+
+// countryLocation returns the time zone of the named country, and false for unknown countries and
+// zones that fail to load.
+func countryLocation(countryName string) (*time.Location, bool) {
+	name, exists := countryLanguageIndex[normalizeCountryName(countryName)]
+	if !exists {
+		return nil, false
+	}
+	location, err := time.LoadLocation(CountryTimezoneMap[name])
+	if err != nil {
+		return nil, false
+	}
+	return location, true
+}
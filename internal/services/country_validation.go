@@ -0,0 +1,138 @@
+/**
+ *  Country and city validation for signup and profile updates, so a typo such as "Norwayy" is caught
+ *  before it breaks features that depend on the country, such as the local news.
+ *
+ *  @file       country_validation.go
+ *  @package    services
+ *
+ *  @methods
+ *  - NormalizeCountry(country)                - Returns the name of a country in CountryLanguageMap, or an error with suggestions.
+ *  - SuggestCountries(country, limit)         - Returns the country names closest to a misspelled one.
+ *  - NormalizeCity(ctx, cities, country, city) - Returns the listed spelling of a city and whether it is listed.
+ *  - levenshtein(a, b)                        - Computes the edit distance between two strings.
+ *
+ *  @behaviors
+ *  - Countries are accepted by name, common alias or ISO code, case-insensitively, and stored under
+ *    their name in CountryLanguageMap, e.g. "usa" and "US" become "United States".
+ *  - An unknown country is reported as a validate.Errors for "country", naming up to
+ *    MaxCountrySuggestions countries within a few typos of it.
+ *  - The city list of CityService is incomplete, so a city missing from it is kept and only flagged.
+ *    If the list cannot be fetched, the city is kept as entered and not flagged.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"proh2052-group6/pkg/validate"
+)
+
+// MaxCountrySuggestions is the largest number of countries suggested for an unknown country.
+const MaxCountrySuggestions = 3
+
+// NormalizeCountry returns the name in CountryLanguageMap of a country given by name, alias or ISO
+// code. An unknown country is reported as a validate.Errors for "country" with the closest matches.
+func NormalizeCountry(country string) (string, error) {
+	if strings.TrimSpace(country) == "" {
+		return "", validate.Errors{"country": "required"}
+	}
+	if name, ok := countryLanguageIndex[normalizeCountryName(country)]; ok {
+		return name, nil
+	}
+	if name, ok := countryCodeIndex[strings.ToUpper(strings.TrimSpace(country))]; ok {
+		return name, nil
+	}
+
+	message := "is not a known country"
+	if suggestions := SuggestCountries(country, MaxCountrySuggestions); len(suggestions) > 0 {
+		message += "; did you mean " + strings.Join(suggestions, ", ") + "?"
+	}
+	return "", validate.Errors{"country": message}
+}
+
+// SuggestCountries returns up to limit country names within a few typos of country, closest first.
+// Aliases are matched too, but suggested under their country's name.
+func SuggestCountries(country string, limit int) []string {
+	input := normalizeCountryName(country)
+	// Allow one typo per three letters, and at least two, so short names are not matched by anything.
+	maxDistance := utf8.RuneCountInString(input) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	distances := make(map[string]int)
+	for candidate, name := range countryLanguageIndex {
+		distance := levenshtein(input, candidate)
+		if best, seen := distances[name]; distance <= maxDistance && (!seen || distance < best) {
+			distances[name] = distance
+		}
+	}
+
+	names := make([]string, 0, len(distances))
+	for name := range distances {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if distances[names[i]] != distances[names[j]] {
+			return distances[names[i]] < distances[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > limit {
+		names = names[:limit]
+	}
+	return names
+}
+
+// NormalizeCity looks city up in the city list of country. It returns the city as spelled in the list
+// and true if it is listed, or the trimmed city and false if it is not. Without a list, because cities
+// is nil or the lookup fails, the city is returned as entered and reported as listed.
+func NormalizeCity(ctx context.Context, cities CityServiceInterface, country, city string) (string, bool) {
+	city = strings.TrimSpace(city)
+	if cities == nil {
+		return city, true
+	}
+	listed, err := cities.GetCitiesByCountry(ctx, country)
+	if err != nil || len(listed) == 0 {
+		return city, true
+	}
+	for _, candidate := range listed {
+		if strings.EqualFold(strings.TrimSpace(candidate), city) {
+			return strings.TrimSpace(candidate), true
+		}
+	}
+	return city, false
+}
+
+// levenshtein returns the number of single-character insertions, deletions and substitutions
+// needed to turn a into b.
+func levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
@@ -1,25 +1,34 @@
 /**
- *  Provides a utility function to initialize a Firestore client for database operations.
+ *  Provides utility functions to initialize a Firestore client for database operations and to
+ *  check that the database can be reached.
  *
  *  @file       db.go
  *  @package    services
  *
  *  @functions
- *  - NewFirestoreClient(ctx) - Creates and returns a new Firestore client for the specified context.
+ *  - NewFirestoreClient(ctx, projectID) - Creates a Firestore client for a project and checks that it can be reached.
+ *  - NewFirestoreClientForTesting(ctx, projectID) - Creates a client for a project on the Firestore emulator.
+ *  - PingFirestore(ctx, client)   - Reads a single document to check that the database can be reached.
  *
  *  @dependencies
  *  - "cloud.google.com/go/firestore": Provides Firestore client capabilities.
- *  - Google Cloud Project: The project must be configured and accessible for Firestore operations.
+ *  - Google Cloud Project: The project, config.Config.FirestoreProjectID, must be accessible for Firestore operations.
  *
  *  @behaviors
- *  - Establishes a connection to the Firestore database using the provided context.
+ *  - The Google SDK only connects on the first query, so NewFirestoreClient pings the database
+ *    within FirestoreConnectTimeout. Wrong credentials or an unreachable project then fail at startup
+ *    instead of failing every request.
+ *  - Connection errors name the project ID and the credential source: the emulator, the file in
+ *    GOOGLE_APPLICATION_CREDENTIALS or the application default credentials.
+ *  - The readiness probe of the HealthService uses the same PingFirestore check.
  *  - Logs a success message upon successful connection.
- *  - Returns an error if the client initialization fails.
+ *  - The testing client connects without credentials, so GOOGLE_APPLICATION_CREDENTIALS is not needed,
+ *    and refuses to start unless FIRESTORE_EMULATOR_HOST is set, so tests never reach a real database.
  *
  *  @example
  *  ```
  *  ctx := context.Background()
- *  client, err := NewFirestoreClient(ctx)
+ *  client, err := NewFirestoreClient(ctx, cfg.FirestoreProjectID)
  *  if err != nil {
  *      log.Fatalf("Failed to connect to Firestore: %v", err)
  *  }
@@ -27,7 +36,8 @@
  *  ```
  *
  *  @errors
- *  - Returns an error if the Firestore client cannot be created.
+ *  - ErrFirestoreProjectIDMissing: No project ID was configured.
+ *  - Returns a descriptive error if the Firestore client cannot be created or the database cannot be reached.
  *
  *  @authors
  *      - Aayush
@@ -40,18 +50,78 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"time"
 
 	"cloud.google.com/go/firestore"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// NewFirestoreClient creates and returns a new Firestore client.
-// It takes a context as an argument, which is used to manage the lifecycle of the client connection.
-func NewFirestoreClient(ctx context.Context) (*firestore.Client, error) {
-	client, err := firestore.NewClient(ctx, "prog2052-project") // Replace "prog2052-project" with your actual Google Cloud Project ID.
+// FirestoreConnectTimeout bounds the read NewFirestoreClient makes to check the connection.
+const FirestoreConnectTimeout = 5 * time.Second
+
+// ErrFirestoreProjectIDMissing is returned by NewFirestoreClient when no project ID is configured.
+var ErrFirestoreProjectIDMissing = errors.New("Firestore project ID is not set; set FIRESTORE_PROJECT_ID to the Google Cloud project of the database")
+
+// ErrFirestoreEmulatorNotConfigured is returned by NewFirestoreClientForTesting when FIRESTORE_EMULATOR_HOST is not set.
+var ErrFirestoreEmulatorNotConfigured = errors.New("FIRESTORE_EMULATOR_HOST is not set")
+
+// NewFirestoreClient creates a Firestore client for projectID and checks that the database can be
+// reached within FirestoreConnectTimeout. The context manages the lifecycle of the client connection.
+func NewFirestoreClient(ctx context.Context, projectID string) (*firestore.Client, error) {
+	if projectID == "" {
+		return nil, ErrFirestoreProjectIDMissing
+	}
+	source := firestoreCredentialSource()
+
+	client, err := firestore.NewClient(ctx, projectID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("Failed to create a Firestore client for project %q using %s: %w", projectID, source, err)
 	}
-	log.Println("Connected to Firestore successfully.") // Log successful connection.
+
+	pingCtx, cancel := context.WithTimeout(ctx, FirestoreConnectTimeout)
+	defer cancel()
+	if err := PingFirestore(pingCtx, client); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("Failed to reach Firestore project %q using %s: %w", projectID, source, err)
+	}
+
+	log.Printf("Connected to Firestore project %q using %s.", projectID, source) // Log successful connection.
 	return client, nil
 }
+
+// PingFirestore reads a single document to check that the database can be reached. A missing
+// document still proves the database is reachable, so only other errors are returned.
+func PingFirestore(ctx context.Context, client *firestore.Client) error {
+	_, err := client.Collection("health").Doc("readiness").Get(ctx)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+	return nil
+}
+
+// firestoreCredentialSource describes where the Firestore client takes its credentials from,
+// in the order the Google SDK looks for them.
+func firestoreCredentialSource() string {
+	if host := os.Getenv("FIRESTORE_EMULATOR_HOST"); host != "" {
+		return fmt.Sprintf("the emulator at %s", host)
+	}
+	if file := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); file != "" {
+		return fmt.Sprintf("the credentials file %s", file)
+	}
+	return "the application default credentials"
+}
+
+// NewFirestoreClientForTesting creates a Firestore client for projectID on the emulator at FIRESTORE_EMULATOR_HOST.
+// Every project on the emulator has its own data, so tests can isolate themselves by using different project IDs.
+func NewFirestoreClientForTesting(ctx context.Context, projectID string) (*firestore.Client, error) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		return nil, ErrFirestoreEmulatorNotConfigured
+	}
+	return firestore.NewClient(ctx, projectID, option.WithoutAuthentication())
+}
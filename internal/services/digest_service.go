@@ -0,0 +1,272 @@
+/**
+ *  DigestService emails users who opted in a weekly digest on Monday morning: their events for the
+ *  coming week and how many of the last seven days they wrote a journal entry.
+ *
+ *  @file       digest_service.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - DigestServiceInterface - Defines the contract for the weekly digest scheduler.
+ *
+ *  @methods
+ *  - NewDigestService(userRepo, eventRepo, journalRepo, emailService) - Creates a new DigestService with default settings.
+ *  - Start(ctx)                   - Runs the scheduler on a real ticker until ctx is cancelled.
+ *  - Run(ctx, ticks)              - Runs the scheduler on the given tick channel until ctx is cancelled.
+ *  - SendDueDigests(ctx)          - Sends the digests that are due at the current time.
+ *  - SendAllDigests(ctx)          - Sends a digest to every subscriber immediately, for manual runs.
+ *  - DigestSendTime(now, location) - Returns the Monday 08:00 in location of the week containing now.
+ *
+ *  @dependencies
+ *  - repositories.UserRepository: Provides GetDigestSubscribers and records which week was sent.
+ *  - repositories.EventRepository: Provides the events of the coming week.
+ *  - repositories.JournalRepository: Provides the journal entries of the last week.
+ *  - EmailServiceInterface, EmailTemplateRenderer: Render and send the digest emails.
+ *  - NotificationGate: Holds digests back during quiet hours; may be nil.
+ *
+ *  @behaviors
+ *  - Only users with DigestEnabled receive a digest, and only once their email is verified.
+ *  - A digest is due at 08:00 on Monday in the user's time zone, or that of their country (UTC for
+ *    unknown countries). A digest missed while the server was down is still sent within CatchUp of that time.
+ *  - DigestSentFor records the Monday each digest was sent for, so a week is never sent twice.
+ *  - Cancelled events are left out of the digest.
+ *  - The digests due on a run are sent together with SendBulk, so a run uses one SMTP session, and
+ *    only the digests that were delivered are recorded as sent.
+ *  - Manual runs ignore the schedule and do not record the week, so Monday's digest is still sent.
+ *  - With a Gate, a digest due during the user's quiet hours is held back until they end, and the
+ *    week is recorded as sent.
+ *  - The clock (`Now`) and the tick source are injectable to allow testing without sleeping.
+ *
+ *  @example
+ *  ```
+ *  digestService := NewDigestService(userRepo, eventRepo, journalRepo, emailService)
+ *  go digestService.Start(ctx)
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// Default settings used by NewDigestService.
+const (
+	DefaultDigestInterval = 15 * time.Minute
+	DefaultDigestCatchUp  = 12 * time.Hour
+)
+
+// digestSendHour is the local hour on Monday at which the weekly digest is sent.
+const digestSendHour = 8
+
+// DigestServiceInterface defines the operations of the weekly digest scheduler.
+type DigestServiceInterface interface {
+	// Start runs the scheduler on a real ticker until the context is cancelled.
+	Start(ctx context.Context)
+
+	// Run runs the scheduler, checking for due digests on every tick, until the context is cancelled.
+	Run(ctx context.Context, ticks <-chan time.Time)
+
+	// SendDueDigests sends the digests due at the current time and returns how many were sent.
+	SendDueDigests(ctx context.Context) (int, error)
+
+	// SendAllDigests sends a digest to every subscriber now, regardless of the schedule, and returns how many were sent.
+	SendAllDigests(ctx context.Context) (int, error)
+}
+
+// Digest is the content of a user's weekly digest email.
+type Digest struct {
+	Username     string         // Name the digest greets the user with.
+	From         string         // First day covered by Events (YYYY-MM-DD).
+	To           string         // Last day covered by Events (YYYY-MM-DD).
+	Events       []models.Event // The user's events from From to To, in chronological order.
+	JournalCount int            // Days with a journal entry in the seven days before From.
+}
+
+// DigestService implements DigestServiceInterface.
+type DigestService struct {
+	UserRepo    repositories.UserRepository    // Repository used to find subscribers and record sent digests.
+	EventRepo   repositories.EventRepository   // Repository used to query the coming week's events.
+	JournalRepo repositories.JournalRepository // Repository used to count the last week's journal entries.
+	Email       EmailServiceInterface          // Email service for sending digests.
+	Templates   *EmailTemplateRenderer         // Renders the digest emails.
+	Gate        *NotificationGate              // Holds digests back during quiet hours; may be nil.
+	Interval    time.Duration                  // How often the scheduler checks for due digests.
+	CatchUp     time.Duration                  // How long after Monday 08:00 a missed digest is still sent.
+	Now         func() time.Time               // Clock used by the scheduler; replaceable in tests.
+}
+
+// NewDigestService initializes a DigestService that checks every 15 minutes for due digests.
+func NewDigestService(userRepo repositories.UserRepository, eventRepo repositories.EventRepository, journalRepo repositories.JournalRepository, emailService EmailServiceInterface) DigestServiceInterface {
+	return &DigestService{
+		UserRepo:    userRepo,
+		EventRepo:   eventRepo,
+		JournalRepo: journalRepo,
+		Email:       emailService,
+		Templates:   NewEmailTemplateRenderer(),
+		Interval:    DefaultDigestInterval,
+		CatchUp:     DefaultDigestCatchUp,
+		Now:         time.Now,
+	}
+}
+
+// Start runs the scheduler on a time.Ticker until the context is cancelled.
+func (ds *DigestService) Start(ctx context.Context) {
+	ticker := time.NewTicker(ds.Interval)
+	defer ticker.Stop()
+	ds.Run(ctx, ticker.C)
+}
+
+// Run checks for due digests on every tick until the context is cancelled.
+func (ds *DigestService) Run(ctx context.Context, ticks <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+			if _, err := ds.SendDueDigests(ctx); err != nil {
+				log.Printf("Failed to send weekly digests: %v", err)
+			}
+		}
+	}
+}
+
+// SendDueDigests emails every subscriber whose Monday 08:00 has been reached, unless this week's
+// digest was already sent or more than CatchUp has passed since.
+func (ds *DigestService) SendDueDigests(ctx context.Context) (int, error) {
+	return ds.sendDigests(ctx, false)
+}
+
+// SendAllDigests emails every subscriber a digest now, without recording the week as sent.
+func (ds *DigestService) SendAllDigests(ctx context.Context) (int, error) {
+	return ds.sendDigests(ctx, true)
+}
+
+// sendDigests sends the digests of all subscribers, either only the due ones or, with force, all of them.
+func (ds *DigestService) sendDigests(ctx context.Context, force bool) (int, error) {
+	users, err := ds.UserRepo.GetDigestSubscribers(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := ds.Now()
+	var messages []EmailMessage
+	var weeks []string // Week of each message, recorded once it is sent.
+	for _, user := range users {
+		if !user.DigestEnabled || !user.IsVerified {
+			continue
+		}
+
+		location, _ := LocationForUser(user)
+		sendAt := DigestSendTime(now, location)
+		week := sendAt.Format("2006-01-02")
+		if !force && (now.Before(sendAt) || !now.Before(sendAt.Add(ds.CatchUp)) || user.DigestSentFor == week) {
+			continue
+		}
+
+		msg, err := ds.renderDigest(ctx, user, now.In(location))
+		if err != nil {
+			log.Printf("Failed to prepare weekly digest for %s: %v", user.Email, err)
+			continue
+		}
+		held, err := ds.Gate.Hold(ctx, user, msg, time.Time{})
+		if err != nil {
+			log.Printf("Failed to hold back weekly digest for %s: %v", user.Email, err)
+			continue
+		}
+		if held {
+			if !force {
+				ds.markSent(ctx, user.Email, week)
+			}
+			continue
+		}
+		messages = append(messages, msg)
+		weeks = append(weeks, week)
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	result, _ := ds.Email.SendBulk(ctx, messages)
+	sent := 0
+	for i, recipient := range result.Recipients {
+		if recipient.Err != nil {
+			log.Printf("Failed to send weekly digest to %s: %v", recipient.To, recipient.Err)
+			continue
+		}
+		sent++
+
+		if !force {
+			ds.markSent(ctx, recipient.To, weeks[i])
+		}
+	}
+
+	return sent, nil
+}
+
+// markSent records week as the Monday userEmail was last sent a digest for, so it is not sent again.
+func (ds *DigestService) markSent(ctx context.Context, userEmail, week string) {
+	if err := ds.UserRepo.UpdateUser(ctx, userEmail, map[string]interface{}{"DigestSentFor": week}); err != nil {
+		log.Printf("Failed to mark weekly digest as sent for %s: %v", userEmail, err)
+	}
+}
+
+// renderDigest gathers the digest of user for the week starting on the local date of now and renders
+// the email to send them.
+func (ds *DigestService) renderDigest(ctx context.Context, user *models.User, now time.Time) (EmailMessage, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	digest := &Digest{
+		Username: user.Username,
+		From:     today.Format("2006-01-02"),
+		To:       today.AddDate(0, 0, 6).Format("2006-01-02"),
+	}
+
+	page, err := ds.EventRepo.GetAllEvents(ctx, user.Email, models.EventQuery{From: digest.From, To: digest.To})
+	if err != nil {
+		return EmailMessage{}, err
+	}
+	digest.Events = filterCancelled(page.Items, false)
+	sort.SliceStable(digest.Events, func(i, j int) bool {
+		if digest.Events[i].Date != digest.Events[j].Date {
+			return digest.Events[i].Date < digest.Events[j].Date
+		}
+		return digest.Events[i].StartTime < digest.Events[j].StartTime
+	})
+
+	written := make(map[string]bool)
+	err = ds.JournalRepo.StreamJournals(ctx, user.Email, today.AddDate(0, 0, -7).Format("2006-01-02"), today.AddDate(0, 0, -1).Format("2006-01-02"),
+		func(journal models.Journal) error {
+			written[journal.Date] = true
+			return nil
+		})
+	if err != nil {
+		return EmailMessage{}, err
+	}
+	digest.JournalCount = len(written)
+
+	msg, err := ds.Templates.WeeklyDigest(UserLocale(user), digest)
+	if err != nil {
+		return EmailMessage{}, err
+	}
+	msg.To = user.Email
+	return msg, nil
+}
+
+// DigestSendTime returns 08:00 on the Monday of the week containing now, in location. Weeks start
+// on Monday at midnight, so early on a Monday the result is later the same day.
+func DigestSendTime(now time.Time, location *time.Location) time.Time {
+	local := now.In(location)
+	daysSinceMonday := (int(local.Weekday()) + 6) % 7
+	return time.Date(local.Year(), local.Month(), local.Day()-daysSinceMonday, digestSendHour, 0, 0, 0, location)
+}
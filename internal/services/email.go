@@ -1,31 +1,55 @@
 /**
  *  Email Service provides functionality to send emails using the SMTP protocol.
- *  It leverages environment variables for configuration, ensuring secure and flexible setup.
+ *  The SMTP server and sending account come from config.Config, loaded once at startup.
+ *  Emails can be sent synchronously, or handed to an EmailQueue that delivers them in the
+ *  background so requests do not wait for a slow SMTP server.
  *
  *  @interface EmailServiceInterface
+ *  @interface EmailTransport
+ *  @interface BatchEmailTransport
+ *  @struct   BulkResult
  *  @struct   SMTPEmailService
+ *  @struct   SMTPTransport
  *  @methods
- *  - NewSMTPEmailService()         - Initializes a new SMTPEmailService instance with environment configurations.
- *  - SendEmail(toEmail, subject, body) - Sends an email to the specified recipient.
+ *  - NewSMTPTransport(cfg)                    - Initializes an SMTPTransport for the configured SMTP server.
+ *  - Send(ctx, toEmail, msg)                  - Delivers a composed message over SMTP, honouring ctx.
+ *  - SendBatch(ctx, emails)                   - Delivers many composed messages over one SMTP connection.
+ *  - NewSMTPEmailService(transport, queue)    - Initializes a new SMTPEmailService instance.
+ *  - SendEmail(toEmail, subject, body)        - Sends an email to the specified recipient and waits for delivery.
+ *  - SendEmailAsync(ctx, toEmail, subject, body) - Queues an email for background delivery.
+ *  - SendMultipartEmail(toEmail, msg)         - Sends a rendered email with HTML and plaintext parts.
+ *  - SendMultipartEmailAsync(ctx, toEmail, msg) - Queues a rendered email for background delivery.
+ *  - SendBulk(ctx, messages)                  - Sends rendered emails to many recipients and reports each outcome.
+ *
+ *  @behaviors
+ *  - SendEmailAsync only waits for room in the queue, and returns ctx's error if ctx ends first.
+ *    The email is delivered after the request has finished, so ctx does not limit the delivery.
+ *  - Without a queue, SendEmailAsync sends synchronously.
+ *  - Multipart emails are multipart/alternative MIME messages with a quoted-printable plaintext part
+ *    followed by the HTML part, so mail clients show the HTML version when they can.
+ *  - Subjects are encoded as MIME encoded-words when they contain non-ASCII or control characters,
+ *    so a subject can never add headers to the message.
+ *  - SendBulk delivers all messages in one SMTP session when the transport supports batches. A
+ *    recipient the server rejects fails alone; the session is reset and the next message is sent.
+ *    Only when the connection itself fails are the remaining messages failed with its error.
  *
  *  @dependencies
  *  - net/smtp: Provides the SMTP client for sending emails.
- *  - os.Getenv: Fetches configuration values from environment variables.
- *  - strconv.Atoi: Converts port string to an integer.
+ *  - config.SMTPConfig: The SMTP server and sending account.
  *
  *  @file      email.go
  *  @project   DailyVerse
  *  @purpose   Utility service for email communication in the application.
  *  @framework Go Standard Library with SMTP Integration
- *  @environment_variables
- *  - SMTP_HOST: The hostname of the SMTP server (e.g., smtp.gmail.com).
- *  - SMTP_PORT: The port number of the SMTP server (e.g., 587).
- *  - EMAIL_USER: The email address used to send emails.
- *  - EMAIL_PASS: The password or app-specific password for the sending email account.
  *
  *  @example
  *  ```
- *  emailService := NewSMTPEmailService()
+ *  transport := NewSMTPTransport(cfg.SMTP)
+ *  queue := NewEmailQueue(transport, 100)
+ *  queue.Start()
+ *  defer queue.Shutdown(shutdownCtx)
+ *
+ *  emailService := NewSMTPEmailService(transport, queue)
  *  err := emailService.SendEmail("recipient@example.com", "Welcome to DailyVerse", "Thank you for joining!")
  *  if err != nil {
  *      log.Fatalf("Failed to send email: %v", err)
@@ -42,44 +66,233 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
 	"net/smtp"
-	"os"
-	"strconv"
+	"net/textproto"
+
+	"proh2052-group6/internal/config"
 )
 
 // EmailServiceInterface defines the contract for email services.
 type EmailServiceInterface interface {
 	// SendEmail sends an email with the specified subject and body to the recipient.
 	SendEmail(toEmail, subject, body string) error
+
+	// SendEmailAsync queues an email for background delivery. It returns once the email is queued,
+	// or with an error if ctx ends first or the queue is shut down.
+	SendEmailAsync(ctx context.Context, toEmail, subject, body string) error
+
+	// SendMultipartEmail sends a rendered email with HTML and plaintext bodies to the recipient.
+	SendMultipartEmail(toEmail string, msg EmailMessage) error
+
+	// SendMultipartEmailAsync queues a rendered email for background delivery, like SendEmailAsync.
+	SendMultipartEmailAsync(ctx context.Context, toEmail string, msg EmailMessage) error
+
+	// SendBulk sends rendered emails, each to its To address, and waits until they have been
+	// delivered. The result reports each recipient; the error is set if any of them failed.
+	SendBulk(ctx context.Context, messages []EmailMessage) (*BulkResult, error)
 }
 
-// SMTPEmailService implements EmailServiceInterface using the SMTP protocol.
-type SMTPEmailService struct {
+// BulkResult reports the outcome of each message of a SendBulk call.
+type BulkResult struct {
+	Recipients []BulkRecipientResult // One per message, in the order they were given.
+}
+
+// BulkRecipientResult is the outcome of one message of a SendBulk call.
+type BulkRecipientResult struct {
+	To  string // Recipient of the message.
+	Err error  // Why the message was not delivered; nil if it was.
+}
+
+// Sent returns the number of delivered messages.
+func (br *BulkResult) Sent() int {
+	sent := 0
+	for _, recipient := range br.Recipients {
+		if recipient.Err == nil {
+			sent++
+		}
+	}
+	return sent
+}
+
+// Failed returns the outcomes of the messages that were not delivered.
+func (br *BulkResult) Failed() []BulkRecipientResult {
+	var failed []BulkRecipientResult
+	for _, recipient := range br.Recipients {
+		if recipient.Err != nil {
+			failed = append(failed, recipient)
+		}
+	}
+	return failed
+}
+
+// EmailTransport delivers composed email messages.
+type EmailTransport interface {
+	// Send delivers msg to toEmail, giving up when ctx is done.
+	Send(ctx context.Context, toEmail string, msg []byte) error
+}
+
+// ComposedEmail is a composed message and its recipient.
+type ComposedEmail struct {
+	To  string // Recipient's email address.
+	Msg []byte // Composed message, with headers.
+}
+
+// BatchEmailTransport is an EmailTransport that can deliver many messages in one session.
+type BatchEmailTransport interface {
+	EmailTransport
+
+	// SendBatch delivers emails, giving up when ctx is done, and returns the error of each email
+	// in order, nil for the delivered ones.
+	SendBatch(ctx context.Context, emails []ComposedEmail) []error
+}
+
+// SMTPTransport implements EmailTransport using an SMTP server.
+type SMTPTransport struct {
 	Auth smtp.Auth // Authentication credentials for the SMTP server.
 	Host string    // SMTP server hostname.
 	Port int       // SMTP server port number.
 	From string    // Sender's email address.
 }
 
-// NewSMTPEmailService initializes an SMTPEmailService using environment variables for configuration.
-// Required environment variables:
-// - SMTP_HOST: SMTP server hostname.
-// - SMTP_PORT: SMTP server port.
-// - EMAIL_USER: Email address used for sending.
-// - EMAIL_PASS: Password for the email address.
-func NewSMTPEmailService() EmailServiceInterface {
-	port, _ := strconv.Atoi(os.Getenv("SMTP_PORT")) // Convert port to integer.
-	auth := smtp.PlainAuth("", os.Getenv("EMAIL_USER"), os.Getenv("EMAIL_PASS"), os.Getenv("SMTP_HOST"))
-	return &SMTPEmailService{
-		Auth: auth,
-		Host: os.Getenv("SMTP_HOST"),
-		Port: port,
-		From: os.Getenv("EMAIL_USER"),
+// NewSMTPTransport initializes an SMTPTransport for the SMTP server in cfg, sending from cfg.User.
+func NewSMTPTransport(cfg config.SMTPConfig) *SMTPTransport {
+	return &SMTPTransport{
+		Auth: smtp.PlainAuth("", cfg.User, cfg.Password, cfg.Host),
+		Host: cfg.Host,
+		Port: cfg.Port,
+		From: cfg.User,
+	}
+}
+
+// Send delivers msg to toEmail like smtp.SendMail, but aborts the connection when ctx is done.
+func (st *SMTPTransport) Send(ctx context.Context, toEmail string, msg []byte) error {
+	client, closeSession, err := st.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeSession()
+
+	if err := st.deliver(client, toEmail, msg); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// SendBatch delivers emails over a single connection, one mail transaction each. When the server
+// rejects an email, the transaction is reset and the next email is sent; when the connection fails,
+// the remaining emails fail with the same error.
+func (st *SMTPTransport) SendBatch(ctx context.Context, emails []ComposedEmail) []error {
+	errs := make([]error, len(emails))
+	client, closeSession, err := st.connect(ctx)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	defer closeSession()
+
+	for i, email := range emails {
+		err := st.deliver(client, email.To, email.Msg)
+		if err == nil {
+			continue
+		}
+		errs[i] = err
+
+		// Only a reply from the server leaves the session usable.
+		var reply *textproto.Error
+		if !errors.As(err, &reply) || client.Reset() != nil {
+			for j := i + 1; j < len(emails); j++ {
+				errs[j] = err
+			}
+			return errs
+		}
 	}
+	client.Quit()
+	return errs
 }
 
-// SendEmail sends an email using the SMTP server.
+// connect opens an authenticated SMTP session that is aborted when ctx is done. The returned function
+// closes the session.
+func (st *SMTPTransport) connect(ctx context.Context) (*smtp.Client, func(), error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", st.Host, st.Port))
+	if err != nil {
+		return nil, nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Unblock reads and writes when ctx is cancelled without a deadline.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	client, err := smtp.NewClient(conn, st.Host)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, nil, err
+	}
+	closeSession := func() {
+		stop()
+		client.Close()
+		conn.Close()
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: st.Host}); err != nil {
+			closeSession()
+			return nil, nil, err
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok && st.Auth != nil {
+		if err := client.Auth(st.Auth); err != nil {
+			closeSession()
+			return nil, nil, err
+		}
+	}
+	return client, closeSession, nil
+}
+
+// deliver sends msg to toEmail in one mail transaction of client.
+func (st *SMTPTransport) deliver(client *smtp.Client, toEmail string, msg []byte) error {
+	if err := client.Mail(st.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(toEmail); err != nil {
+		return err
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(msg); err != nil {
+		return err
+	}
+	return data.Close()
+}
+
+// SMTPEmailService implements EmailServiceInterface on top of an EmailTransport.
+type SMTPEmailService struct {
+	Transport EmailTransport // Delivers emails sent with SendEmail.
+	Queue     *EmailQueue    // Delivers emails sent with SendEmailAsync; nil sends them synchronously.
+}
+
+// NewSMTPEmailService initializes an SMTPEmailService sending through transport, and queueing
+// asynchronous emails on queue if it is not nil.
+func NewSMTPEmailService(transport EmailTransport, queue *EmailQueue) EmailServiceInterface {
+	return &SMTPEmailService{Transport: transport, Queue: queue}
+}
+
+// SendEmail sends an email and waits until it has been delivered.
 // Parameters:
 // - toEmail (string): Recipient's email address.
 // - subject (string): Email subject.
@@ -87,15 +300,116 @@ func NewSMTPEmailService() EmailServiceInterface {
 // Returns:
 // - error: Returns an error if the email cannot be sent.
 func (es *SMTPEmailService) SendEmail(toEmail, subject, body string) error {
-	// Construct the SMTP server address.
-	addr := fmt.Sprintf("%s:%d", es.Host, es.Port)
+	return es.Transport.Send(context.Background(), toEmail, composeEmail(toEmail, subject, body))
+}
+
+// SendEmailAsync queues an email for background delivery, waiting for room in the queue until ctx is done.
+func (es *SMTPEmailService) SendEmailAsync(ctx context.Context, toEmail, subject, body string) error {
+	if es.Queue == nil {
+		return es.SendEmail(toEmail, subject, body)
+	}
+	return es.Queue.Enqueue(ctx, toEmail, composeEmail(toEmail, subject, body))
+}
 
-	// Create the email message.
-	msg := []byte("To: " + toEmail + "\r\n" +
+// SendMultipartEmail sends a rendered email and waits until it has been delivered.
+func (es *SMTPEmailService) SendMultipartEmail(toEmail string, msg EmailMessage) error {
+	data, err := composeMultipartEmail(toEmail, msg)
+	if err != nil {
+		return err
+	}
+	return es.Transport.Send(context.Background(), toEmail, data)
+}
+
+// SendMultipartEmailAsync queues a rendered email for background delivery, waiting for room in the queue until ctx is done.
+func (es *SMTPEmailService) SendMultipartEmailAsync(ctx context.Context, toEmail string, msg EmailMessage) error {
+	if es.Queue == nil {
+		return es.SendMultipartEmail(toEmail, msg)
+	}
+	data, err := composeMultipartEmail(toEmail, msg)
+	if err != nil {
+		return err
+	}
+	return es.Queue.Enqueue(ctx, toEmail, data)
+}
+
+// SendBulk sends rendered emails, each to its To address, in one session when the transport supports
+// batches and one by one otherwise, and waits until they have been delivered.
+func (es *SMTPEmailService) SendBulk(ctx context.Context, messages []EmailMessage) (*BulkResult, error) {
+	result := &BulkResult{Recipients: make([]BulkRecipientResult, len(messages))}
+	var emails []ComposedEmail
+	var indexes []int // Index in messages of each email.
+	for i, msg := range messages {
+		result.Recipients[i].To = msg.To
+		data, err := composeMultipartEmail(msg.To, msg)
+		if err != nil {
+			result.Recipients[i].Err = err
+			continue
+		}
+		emails = append(emails, ComposedEmail{To: msg.To, Msg: data})
+		indexes = append(indexes, i)
+	}
+
+	var errs []error
+	if batch, ok := es.Transport.(BatchEmailTransport); ok {
+		errs = batch.SendBatch(ctx, emails)
+	} else {
+		errs = make([]error, len(emails))
+		for i, email := range emails {
+			errs[i] = es.Transport.Send(ctx, email.To, email.Msg)
+		}
+	}
+	for i, err := range errs {
+		result.Recipients[indexes[i]].Err = err
+	}
+
+	if failed := len(result.Failed()); failed > 0 {
+		return result, fmt.Errorf("Failed to send %d of %d emails", failed, len(messages))
+	}
+	return result, nil
+}
+
+// composeEmail creates the message for an email.
+func composeEmail(toEmail, subject, body string) []byte {
+	return []byte("To: " + toEmail + "\r\n" +
 		"Subject: " + subject + "\r\n" +
 		"\r\n" +
 		body + "\r\n")
+}
+
+// composeMultipartEmail creates a multipart/alternative message with the plaintext and HTML bodies of msg.
+func composeMultipartEmail(toEmail string, msg EmailMessage) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	parts := []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	}
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
 
-	// Send the email using the configured SMTP server.
-	return smtp.SendMail(addr, es.Auth, es.From, []string{toEmail}, msg)
+	var message bytes.Buffer
+	message.WriteString("To: " + toEmail + "\r\n")
+	message.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: multipart/alternative; boundary=\"" + writer.Boundary() + "\"\r\n")
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
 }
@@ -0,0 +1,165 @@
+/**
+ *  EmailQueue delivers emails in the background, so requests that send an email, such as signup,
+ *  do not wait for the SMTP server, and transient SMTP failures are retried instead of failing
+ *  the request.
+ *
+ *  @struct   EmailQueue
+ *  @methods
+ *  - NewEmailQueue(transport, size)          - Initializes a queue holding up to size emails, with default retries.
+ *  - Start()                                 - Starts the workers delivering queued emails.
+ *  - Enqueue(ctx, toEmail, msg)              - Queues a composed email, waiting for room until ctx is done.
+ *  - Shutdown(ctx)                           - Stops accepting emails and waits for the queue to drain.
+ *
+ *  @behaviors
+ *  - Each email is attempted up to MaxAttempts times, waiting Backoff before the first retry and
+ *    twice as long before each further retry. Emails that still fail are logged and dropped.
+ *  - Each attempt is limited to SendTimeout.
+ *  - Shutdown delivers the emails already queued. If ctx ends first, pending retries are abandoned
+ *    and Shutdown returns ctx's error.
+ *
+ *  @dependencies
+ *  - EmailTransport: Delivers the emails.
+ *
+ *  @file      email_queue.go
+ *  @project   DailyVerse
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// Default delivery settings used by NewEmailQueue.
+const (
+	DefaultEmailMaxAttempts = 3
+	DefaultEmailBackoff     = 2 * time.Second
+	DefaultEmailSendTimeout = 30 * time.Second
+	emailQueueWorkers       = 2 // Emails delivered concurrently.
+)
+
+// ErrEmailQueueClosed is returned by Enqueue after Shutdown.
+var ErrEmailQueueClosed = errors.New("Email queue is shut down")
+
+// queuedEmail is a composed email waiting for delivery.
+type queuedEmail struct {
+	to  string
+	msg []byte
+}
+
+// EmailQueue delivers queued emails with retries on a fixed number of worker goroutines.
+type EmailQueue struct {
+	Transport   EmailTransport // Delivers the emails.
+	MaxAttempts int            // Attempts per email, including the first.
+	Backoff     time.Duration  // Delay before the first retry; doubled before each further retry.
+	SendTimeout time.Duration  // Time limit of a single attempt.
+
+	emails  chan queuedEmail
+	mutex   sync.RWMutex  // Guards closed, so no email is sent on the closed channel.
+	closed  bool          // Whether Shutdown has been called.
+	abort   chan struct{} // Closed when Shutdown gives up waiting, to cut retries short.
+	aborted sync.Once
+	workers sync.WaitGroup
+}
+
+// NewEmailQueue initializes an EmailQueue holding up to size emails. Settings may be changed
+// until Start is called.
+func NewEmailQueue(transport EmailTransport, size int) *EmailQueue {
+	return &EmailQueue{
+		Transport:   transport,
+		MaxAttempts: DefaultEmailMaxAttempts,
+		Backoff:     DefaultEmailBackoff,
+		SendTimeout: DefaultEmailSendTimeout,
+		emails:      make(chan queuedEmail, size),
+		abort:       make(chan struct{}),
+	}
+}
+
+// Start starts the workers delivering queued emails.
+func (eq *EmailQueue) Start() {
+	for i := 0; i < emailQueueWorkers; i++ {
+		eq.workers.Add(1)
+		go func() {
+			defer eq.workers.Done()
+			for email := range eq.emails {
+				eq.deliver(email)
+			}
+		}()
+	}
+}
+
+// Enqueue queues msg for delivery to toEmail. It waits for room in the queue until ctx is done.
+func (eq *EmailQueue) Enqueue(ctx context.Context, toEmail string, msg []byte) error {
+	eq.mutex.RLock()
+	defer eq.mutex.RUnlock()
+	if eq.closed {
+		return ErrEmailQueueClosed
+	}
+
+	select {
+	case eq.emails <- queuedEmail{to: toEmail, msg: msg}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops accepting emails and waits until the queued emails have been delivered,
+// or until ctx is done.
+func (eq *EmailQueue) Shutdown(ctx context.Context) error {
+	eq.mutex.Lock()
+	if !eq.closed {
+		eq.closed = true
+		close(eq.emails)
+	}
+	eq.mutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		eq.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		eq.aborted.Do(func() { close(eq.abort) })
+		return ctx.Err()
+	}
+}
+
+// deliver sends an email, retrying with exponential backoff.
+func (eq *EmailQueue) deliver(email queuedEmail) {
+	backoff := eq.Backoff
+	var err error
+	for attempt := 1; attempt <= eq.MaxAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), eq.SendTimeout)
+		err = eq.Transport.Send(ctx, email.to, email.msg)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt == eq.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-eq.abort:
+			log.Printf("Dropped email to %s on shutdown after %d attempts: %v", email.to, attempt, err)
+			return
+		}
+	}
+	log.Printf("Failed to send email to %s after %d attempts: %v", email.to, eq.MaxAttempts, err)
+}
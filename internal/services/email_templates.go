@@ -0,0 +1,151 @@
+/**
+ *  EmailTemplateRenderer renders the emails sent by DailyVerse from templates embedded in the binary.
+ *  Every email has an HTML body, rendered with html/template, and a plaintext alternative, rendered
+ *  with text/template, which SendMultipartEmail combines into a multipart/alternative message.
+ *
+ *  @struct   EmailTemplateRenderer
+ *  @struct   EmailMessage
+ *  @methods
+ *  - NewEmailTemplateRenderer()                  - Parses the embedded email templates.
+ *  - VerificationOTP(locale, otp, validFor)      - Renders the email verification code email.
+ *  - PasswordResetOTP(locale, otp, validFor)     - Renders the password reset code email.
+ *  - FriendRequest(locale, username)             - Renders the notification for a new friend request.
+ *  - FriendAccepted(locale, username)            - Renders the notification for an accepted friend request.
+ *  - FriendInvitation(locale, username, signupURL) - Renders the invitation to join sent to someone without an account.
+ *  - EventReminder(locale, event)                - Renders the reminder for an upcoming event.
+ *  - WeeklyDigest(locale, digest)                - Renders the weekly digest of upcoming events and journaling.
+ *  - JournalReminder(locale, username)           - Renders the daily reminder to write in the journal.
+ *
+ *  @behaviors
+ *  - Templates live in templates/email: {name}.html and {name}.txt for every email, and layout.html
+ *    with the header and footer shared by the HTML bodies.
+ *  - Values such as usernames and event titles are escaped in the HTML body, so they cannot inject markup.
+ *  - Subjects and texts come from the i18n catalogs in the given locale; the templates look texts up
+ *    with {{t .Locale "key" args...}}. Missing translations fall back to English.
+ *
+ *  @file      email_templates.go
+ *  @project   DailyVerse
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+
+	"proh2052-group6/pkg/i18n"
+	"proh2052-group6/pkg/models"
+)
+
+//go:embed templates/email
+var emailTemplateFS embed.FS
+
+// EmailMessage is a rendered email with an HTML body and a plaintext alternative.
+type EmailMessage struct {
+	To      string // Recipient, for SendBulk; the other send methods take it as an argument.
+	Subject string // Email subject.
+	Text    string // Plaintext body.
+	HTML    string // HTML body.
+}
+
+// EmailTemplateRenderer renders emails from the embedded templates.
+type EmailTemplateRenderer struct {
+	html *htmltemplate.Template // HTML bodies and the shared layout.
+	text *texttemplate.Template // Plaintext bodies.
+}
+
+// NewEmailTemplateRenderer parses the embedded email templates. It panics if they are invalid,
+// since they are part of the binary.
+func NewEmailTemplateRenderer() *EmailTemplateRenderer {
+	return &EmailTemplateRenderer{
+		html: htmltemplate.Must(htmltemplate.New("email").Funcs(htmltemplate.FuncMap{"t": i18n.Translate}).ParseFS(emailTemplateFS, "templates/email/*.html")),
+		text: texttemplate.Must(texttemplate.New("email").Funcs(texttemplate.FuncMap{"t": i18n.Translate}).ParseFS(emailTemplateFS, "templates/email/*.txt")),
+	}
+}
+
+// VerificationOTP renders the email containing the code that verifies a new account.
+func (er *EmailTemplateRenderer) VerificationOTP(locale, otp string, validFor time.Duration) (EmailMessage, error) {
+	return er.render(locale, "verification_otp", i18n.Translate(locale, "email.verification.subject"), map[string]interface{}{
+		"OTP":          otp,
+		"ValidMinutes": int(validFor.Minutes()),
+	})
+}
+
+// PasswordResetOTP renders the email containing the code that resets a password.
+func (er *EmailTemplateRenderer) PasswordResetOTP(locale, otp string, validFor time.Duration) (EmailMessage, error) {
+	return er.render(locale, "password_reset_otp", i18n.Translate(locale, "email.password_reset.subject"), map[string]interface{}{
+		"OTP":          otp,
+		"ValidMinutes": int(validFor.Minutes()),
+	})
+}
+
+// FriendRequest renders the email telling a user that username sent them a friend request.
+func (er *EmailTemplateRenderer) FriendRequest(locale, username string) (EmailMessage, error) {
+	return er.render(locale, "friend_request", i18n.Translate(locale, "email.friend_request.subject"), map[string]interface{}{
+		"Username": username,
+	})
+}
+
+// FriendAccepted renders the email telling a user that username accepted their friend request.
+func (er *EmailTemplateRenderer) FriendAccepted(locale, username string) (EmailMessage, error) {
+	return er.render(locale, "friend_accepted", i18n.Translate(locale, "email.friend_accepted.subject"), map[string]interface{}{
+		"Username": username,
+	})
+}
+
+// FriendInvitation renders the email inviting someone without an account to sign up and become
+// username's friend.
+func (er *EmailTemplateRenderer) FriendInvitation(locale, username, signupURL string) (EmailMessage, error) {
+	return er.render(locale, "friend_invitation", i18n.Translate(locale, "email.friend_invitation.subject", username), map[string]interface{}{
+		"Username":  username,
+		"SignupURL": signupURL,
+	})
+}
+
+// EventReminder renders the reminder for an upcoming event.
+func (er *EmailTemplateRenderer) EventReminder(locale string, event *models.Event) (EmailMessage, error) {
+	return er.render(locale, "event_reminder", i18n.Translate(locale, "email.event_reminder.subject", event.Title), map[string]interface{}{
+		"Title":     event.Title,
+		"Date":      event.Date,
+		"StartTime": event.StartTime,
+	})
+}
+
+// WeeklyDigest renders the weekly digest email.
+func (er *EmailTemplateRenderer) WeeklyDigest(locale string, digest *Digest) (EmailMessage, error) {
+	return er.render(locale, "weekly_digest", i18n.Translate(locale, "email.weekly_digest.subject"), map[string]interface{}{
+		"Digest": digest,
+	})
+}
+
+// JournalReminder renders the daily reminder to write in the journal.
+func (er *EmailTemplateRenderer) JournalReminder(locale, username string) (EmailMessage, error) {
+	return er.render(locale, "journal_reminder", i18n.Translate(locale, "email.journal_reminder.subject"), map[string]interface{}{
+		"Username": username,
+	})
+}
+
+// render executes the HTML and plaintext templates called name with data in locale. The subject is
+// added to data as Subject, for the title of the HTML layout, and the locale as Locale.
+func (er *EmailTemplateRenderer) render(locale, name, subject string, data map[string]interface{}) (EmailMessage, error) {
+	data["Subject"] = subject
+	data["Locale"] = locale
+
+	var html, text bytes.Buffer
+	if err := er.html.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return EmailMessage{}, fmt.Errorf("Failed to render %s email: %w", name, err)
+	}
+	if err := er.text.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return EmailMessage{}, fmt.Errorf("Failed to render %s email: %w", name, err)
+	}
+	return EmailMessage{Subject: subject, Text: text.String(), HTML: html.String()}, nil
+}
@@ -0,0 +1,30 @@
+/**
+ *  Shared handling of repository errors in services. Lookups that report any error as a missing
+ *  document must still pass database failures on, so handlers can answer them with 503 or 500.
+ *
+ *  @file       errors.go
+ *  @package    services
+ *
+ *  @methods
+ *  - isRepositoryFailure(err) - Reports whether err is a repository failure rather than a missing document.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"errors"
+
+	"proh2052-group6/internal/repositories"
+)
+
+// isRepositoryFailure reports whether err is an error other than repositories.ErrNotFound, which
+// callers must return as it is instead of reporting the document as missing.
+func isRepositoryFailure(err error) bool {
+	return err != nil && !errors.Is(err, repositories.ErrNotFound)
+}
@@ -0,0 +1,205 @@
+/**
+ *  Bulk event operations create or delete up to MaxBulkEvents events in one request, with a
+ *  single round trip to the repository for the writes instead of one per event.
+ *
+ *  @file       event_bulk.go
+ *  @package    services
+ *
+ *  @methods
+ *  - BulkCreateEvents(ctx, userEmail, events)   - Validates and creates events, reporting the outcome per event.
+ *  - BulkDeleteEvents(ctx, userEmail, eventIDs) - Deletes the user's events, reporting the outcome per event.
+ *
+ *  @behaviors
+ *  - Every event succeeds or fails on its own: invalid events, IDs the user does not own and
+ *    failed writes are listed under failed, and the other events are still processed.
+ *  - Events are always created for, and deleted from, the authenticated user; an ID of someone
+ *    else's event is reported as "Event not found", like a single delete.
+ *  - Deleting a recurring series also deletes its individually changed occurrences.
+ *  - Addresses are geocoded within bulkGeocodeTimeout for the whole request; events left over
+ *    are created without coordinates.
+ *
+ *  @errors
+ *  - ErrNoBulkEvents: The request contains no events.
+ *  - ErrTooManyBulkEvents: The request contains more than MaxBulkEvents events.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"proh2052-group6/pkg/models"
+)
+
+// MaxBulkEvents is the largest number of events a bulk create or delete accepts.
+const MaxBulkEvents = 100
+
+// bulkGeocodeTimeout is the longest a bulk create spends geocoding the addresses of its events.
+const bulkGeocodeTimeout = 10 * time.Second
+
+var (
+	// ErrNoBulkEvents is returned for bulk requests without events.
+	ErrNoBulkEvents = errors.New("At least one event is required")
+	// ErrTooManyBulkEvents is returned for bulk requests with more than MaxBulkEvents events.
+	ErrTooManyBulkEvents = fmt.Errorf("At most %d events can be processed at once", MaxBulkEvents)
+)
+
+// checkBulkSize returns an error if a bulk request of count events is empty or too large.
+func checkBulkSize(count int) error {
+	if count == 0 {
+		return ErrNoBulkEvents
+	}
+	if count > MaxBulkEvents {
+		return ErrTooManyBulkEvents
+	}
+	return nil
+}
+
+// BulkCreateEvents validates the events and creates the valid ones for the user. The IDs of the
+// created events are listed in request order; invalid events and failed writes are listed with
+// their index in the request.
+func (es *EventService) BulkCreateEvents(ctx context.Context, userEmail string, events []models.Event) (*models.BulkEventResult, error) {
+	if err := checkBulkSize(len(events)); err != nil {
+		return nil, err
+	}
+
+	loc, err := es.userLocation(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BulkEventResult{Succeeded: []string{}, Failed: []models.BulkEventFailure{}}
+	geocodeCtx, cancel := context.WithTimeout(ctx, bulkGeocodeTimeout)
+	defer cancel()
+
+	var valid []*models.Event
+	var indexes []int
+	for i := range events {
+		event := events[i]
+		event.Email = userEmail
+		event.EventID = ""
+		if err := es.prepareNewEvent(&event, loc); err != nil {
+			result.Failed = append(result.Failed, models.BulkEventFailure{Index: i, Error: err.Error()})
+			continue
+		}
+		if geocodeCtx.Err() == nil {
+			es.geocodeEvent(geocodeCtx, &event)
+		}
+		valid = append(valid, &event)
+		indexes = append(indexes, i)
+	}
+
+	for i, err := range es.EventRepo.CreateEvents(ctx, valid) {
+		if err != nil {
+			result.Failed = append(result.Failed, models.BulkEventFailure{Index: indexes[i], Error: err.Error()})
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, valid[i].EventID)
+	}
+	sortBulkFailures(result.Failed)
+	return result, nil
+}
+
+// BulkDeleteEvents deletes the user's events with the given IDs. IDs that are empty, repeated or
+// not among the user's events are listed as failed without deleting anything for them.
+func (es *EventService) BulkDeleteEvents(ctx context.Context, userEmail string, eventIDs []string) (*models.BulkEventResult, error) {
+	if err := checkBulkSize(len(eventIDs)); err != nil {
+		return nil, err
+	}
+
+	result := &models.BulkEventResult{Succeeded: []string{}, Failed: []models.BulkEventFailure{}}
+	fail := func(index int, eventID, message string) {
+		result.Failed = append(result.Failed, models.BulkEventFailure{Index: index, EventID: eventID, Error: message})
+	}
+
+	// Look up every requested event at once; the lookup only finds the user's own events.
+	seen := make(map[string]bool, len(eventIDs))
+	var lookupIDs []string
+	var lookupIndexes []int
+	for i, eventID := range eventIDs {
+		eventID = strings.TrimSpace(eventID)
+		switch {
+		case eventID == "":
+			fail(i, eventID, "Missing eventID")
+		case seen[eventID]:
+			fail(i, eventID, "Duplicate eventID")
+		default:
+			seen[eventID] = true
+			lookupIDs = append(lookupIDs, eventID)
+			lookupIndexes = append(lookupIndexes, i)
+		}
+	}
+	found, err := es.EventRepo.GetEvents(ctx, userEmail, lookupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleteIDs []string
+	var deleteIndexes []int
+	series := make(map[string]bool)
+	for i, event := range found {
+		if event == nil || event.Email != userEmail {
+			fail(lookupIndexes[i], lookupIDs[i], "Event not found")
+			continue
+		}
+		deleteIDs = append(deleteIDs, lookupIDs[i])
+		deleteIndexes = append(deleteIndexes, lookupIndexes[i])
+		if event.Recurrence != nil {
+			series[lookupIDs[i]] = true
+		}
+	}
+
+	// Changed occurrences of a deleted series are deleted with it and reported as part of the series.
+	occurrenceSeries := make(map[string]string)
+	if len(series) > 0 {
+		page, err := es.EventRepo.GetAllEvents(ctx, userEmail, models.EventQuery{})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to delete changed occurrences of the series: %w", err)
+		}
+		for _, other := range page.Items {
+			if series[other.SeriesID] && !seen[other.EventID] {
+				occurrenceSeries[other.EventID] = other.SeriesID
+			}
+		}
+	}
+	requested := len(deleteIDs)
+	for eventID := range occurrenceSeries {
+		deleteIDs = append(deleteIDs, eventID)
+	}
+
+	errs := es.EventRepo.DeleteEvents(ctx, userEmail, deleteIDs)
+	seriesErrors := make(map[string]error)
+	for i := requested; i < len(deleteIDs); i++ {
+		if errs[i] != nil {
+			seriesErrors[occurrenceSeries[deleteIDs[i]]] = errs[i]
+		}
+	}
+	for i := 0; i < requested; i++ {
+		switch {
+		case errs[i] != nil:
+			fail(deleteIndexes[i], deleteIDs[i], errs[i].Error())
+		case seriesErrors[deleteIDs[i]] != nil:
+			fail(deleteIndexes[i], deleteIDs[i], fmt.Sprintf("Failed to delete changed occurrences of the series: %v", seriesErrors[deleteIDs[i]]))
+		default:
+			result.Succeeded = append(result.Succeeded, deleteIDs[i])
+		}
+	}
+	sortBulkFailures(result.Failed)
+	return result, nil
+}
+
+// sortBulkFailures orders failures by their index in the request.
+func sortBulkFailures(failures []models.BulkEventFailure) {
+	sort.SliceStable(failures, func(i, j int) bool { return failures[i].Index < failures[j].Index })
+}
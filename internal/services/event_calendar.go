@@ -0,0 +1,151 @@
+/**
+ *  Event calendar metadata. Events carry a color and an all-day flag for the calendar views, and the
+ *  month view is summarized per day, so the month grid does not have to fetch every event in full.
+ *
+ *  @file       event_calendar.go
+ *  @package    services
+ *
+ *  @methods
+ *  - GetEventMonth(ctx, userEmail, month)  - Summarizes the user's events per day of a month's grid.
+ *  - normalizeCalendarFields(event)       - Validates the color and all-day flag of a new or updated event.
+ *  - monthGrid(month)                     - Returns the first and last day of the weeks a month is shown in.
+ *  - summarizeEventDays(events)           - Summarizes events per day.
+ *  - hasColor(colors, color)              - Reports whether a list of colors contains a color.
+ *
+ *  @behaviors
+ *  - A color is "#RRGGBB" or a name from eventColorPalette. Colors are stored lowercase, so "#FF8800"
+ *    and "#ff8800" are the same color; an empty color is the default color of the client.
+ *  - All-day events have a date but no start or end time, and are exported to ICS with a DATE-valued
+ *    DTSTART. Events without a start time were already shown as all-day events; AllDay makes it explicit.
+ *  - The month grid runs from the Monday of the week of the 1st to the Sunday of the week of the last
+ *    day, 4 to 6 weeks. Days are counted as listed by GetAllEvents: own events, accepted invitations
+ *    and occurrences of recurring events, in the user's time zone, without cancelled events.
+ *  - The first event of a day is its first all-day event, or else the event that starts first.
+ *
+ *  @errors
+ *  - ErrInvalidEventColor: The color is neither "#RRGGBB" nor a name from the palette.
+ *  - ErrAllDayEventTimes: An all-day event has a start or end time.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"proh2052-group6/pkg/dates"
+	"proh2052-group6/pkg/models"
+)
+
+var (
+	// ErrInvalidEventColor is returned for an event color that is neither a hex color nor in the palette.
+	ErrInvalidEventColor = errors.New("Color must be #RRGGBB or one of red, orange, yellow, green, teal, blue, purple, pink, gray")
+	// ErrAllDayEventTimes is returned for an all-day event with a start or end time.
+	ErrAllDayEventTimes = errors.New("All-day events cannot have a start or end time")
+)
+
+// eventColorPalette are the named colors an event can have besides hex colors.
+var eventColorPalette = map[string]bool{
+	"red": true, "orange": true, "yellow": true, "green": true, "teal": true,
+	"blue": true, "purple": true, "pink": true, "gray": true,
+}
+
+// GetEventMonth summarizes the user's events on each day of the grid month (YYYY-MM) is shown in.
+func (es *EventService) GetEventMonth(ctx context.Context, userEmail, month string) (*models.EventMonth, error) {
+	first, err := dates.ParseMonth("month", month)
+	if err != nil {
+		return nil, err
+	}
+	from, to := monthGrid(first)
+
+	page, err := es.getEventsInWindow(ctx, userEmail, models.EventQuery{From: from.Format(dates.Layout), To: to.Format(dates.Layout)})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.EventMonth{
+		Month: first.Format(dates.MonthLayout),
+		From:  from.Format(dates.Layout),
+		To:    to.Format(dates.Layout),
+		Weeks: int(to.Sub(from).Hours()/24+1) / 7,
+		Days:  []models.EventDaySummary{},
+	}
+	for _, day := range summarizeEventDays(page.Items) {
+		// Events shown in the user's time zone may move just outside the grid.
+		if day.Date >= summary.From && day.Date <= summary.To {
+			summary.Days = append(summary.Days, day)
+		}
+	}
+	return summary, nil
+}
+
+// normalizeCalendarFields lowercases and validates the color of an event and checks that an all-day
+// event has no start or end time.
+func normalizeCalendarFields(event *models.Event) error {
+	event.Color = strings.ToLower(strings.TrimSpace(event.Color))
+	if event.Color != "" && !eventColorPalette[event.Color] && !isHexColor(event.Color) {
+		return ErrInvalidEventColor
+	}
+	if event.AllDay && (event.StartTime != "" || event.EndTime != "") {
+		return ErrAllDayEventTimes
+	}
+	return nil
+}
+
+// isHexColor reports whether color is "#" followed by six lowercase hex digits.
+func isHexColor(color string) bool {
+	return len(color) == 7 && color[0] == '#' && strings.Trim(color[1:], "0123456789abcdef") == ""
+}
+
+// monthGrid returns the first and last day of the weeks, Monday to Sunday, the month starting on
+// first is shown in.
+func monthGrid(first time.Time) (from, to time.Time) {
+	last := first.AddDate(0, 1, -1)
+	from = first.AddDate(0, 0, -(int(first.Weekday())+6)%7)
+	to = last.AddDate(0, 0, (7-int(last.Weekday()))%7)
+	return from, to
+}
+
+// summarizeEventDays summarizes events per day, in date order.
+func summarizeEventDays(events []models.Event) []models.EventDaySummary {
+	sorted := append([]models.Event(nil), events...)
+	// All-day events and events without a start time sort first, since their StartTime is empty.
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Date != sorted[j].Date {
+			return sorted[i].Date < sorted[j].Date
+		}
+		return sorted[i].StartTime < sorted[j].StartTime
+	})
+
+	var days []models.EventDaySummary
+	for _, event := range sorted {
+		if len(days) == 0 || days[len(days)-1].Date != event.Date {
+			days = append(days, models.EventDaySummary{Date: event.Date, FirstTitle: event.Title})
+		}
+		day := &days[len(days)-1]
+		day.Count++
+		if event.Color != "" && !hasColor(day.Colors, event.Color) {
+			day.Colors = append(day.Colors, event.Color)
+		}
+	}
+	return days
+}
+
+// hasColor reports whether colors contains color.
+func hasColor(colors []string, color string) bool {
+	for _, c := range colors {
+		if c == color {
+			return true
+		}
+	}
+	return false
+}
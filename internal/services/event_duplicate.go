@@ -0,0 +1,117 @@
+/**
+ *  Event duplication copies one of a user's events to another date, or to several dates a week
+ *  apart, so a similar event does not have to be entered again.
+ *
+ *  @file       event_duplicate.go
+ *  @package    services
+ *
+ *  @methods
+ *  - DuplicateEvent(ctx, userEmail, eventID, date, count) - Copies an event to date and the weeks after it.
+ *
+ *  @behaviors
+ *  - The first copy is on date and every further copy a week after the previous one, at the same
+ *    times in the user's time zone. A count of 0 makes one copy.
+ *  - Copies keep the title, description, address, coordinates, type, status, tags, color and
+ *    reminder offset of the event. They get their own EventIDs and are one-off events: the
+ *    recurrence, exceptions, series, import batch, sent reminder and cancellation are not copied.
+ *    A cancelled event is copied as confirmed.
+ *  - Every copy is validated like a new event before any is created, so an invalid date creates nothing.
+ *    If storing a copy fails, the copies already stored are deleted again.
+ *  - Only the user's own events can be copied; other events are reported as "Event not found".
+ *
+ *  @errors
+ *  - validate.Errors for "count" when count is negative or above MaxEventCopies.
+ *  - *dates.Error for "date" when a copy's date is invalid or more than dates.MaxEventYears away.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"proh2052-group6/pkg/dates"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/validate"
+)
+
+// MaxEventCopies is the largest number of copies a single duplicate request creates.
+const MaxEventCopies = 30
+
+// DuplicateEvent copies the user's event with eventID to date and, for a count above 1, to the
+// count-1 following weeks. It returns the IDs of the copies in date order.
+func (es *EventService) DuplicateEvent(ctx context.Context, userEmail, eventID, date string, count int) ([]string, error) {
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 || count > MaxEventCopies {
+		return nil, validate.Errors{"count": fmt.Sprintf("must be between 1 and %d", MaxEventCopies)}
+	}
+
+	source, err := es.ownedEvent(ctx, userEmail, eventID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := es.userLocation(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	first, err := dates.ParseEventDate("date", date, dates.Today(es.Now(), loc))
+	if err != nil {
+		return nil, err
+	}
+	renderEventTimes(source, loc)
+
+	copies := make([]*models.Event, count)
+	for i := range copies {
+		event := eventCopy(*source)
+		event.Date = first.AddDate(0, 0, 7*i).Format(dates.Layout)
+		if err := es.prepareNewEvent(&event, loc); err != nil {
+			return nil, err
+		}
+		copies[i] = &event
+	}
+
+	eventIDs := make([]string, 0, count)
+	var createErr error
+	for i, err := range es.EventRepo.CreateEvents(ctx, copies) {
+		if err != nil {
+			createErr = err
+			continue
+		}
+		eventIDs = append(eventIDs, copies[i].EventID)
+	}
+	if createErr != nil {
+		for i, err := range es.EventRepo.DeleteEvents(ctx, userEmail, eventIDs) {
+			if err != nil {
+				log.Printf("Failed to delete copy %s of event %s: %v", eventIDs[i], eventID, err)
+			}
+		}
+		return nil, fmt.Errorf("Failed to duplicate event: %w", createErr)
+	}
+	return eventIDs, nil
+}
+
+// eventCopy returns a one-off copy of event without its ID, timestamps and per-instance fields,
+// ready to be given a date and prepared as a new event.
+func eventCopy(event models.Event) models.Event {
+	event.EventID = ""
+	event.StartAt, event.EndAt = time.Time{}, nil
+	event.Recurrence, event.ExceptionDates, event.SeriesID = nil, nil, ""
+	event.ReminderSent = false
+	event.ImportBatchID, event.ImportedAt, event.ExternalID = "", nil, ""
+	if isCancelled(event) {
+		event.Status = EventStatusConfirmed
+	}
+	event.CancelledAt = nil
+	event.Tags = append([]string(nil), event.Tags...)
+	return event
+}
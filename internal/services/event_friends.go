@@ -0,0 +1,267 @@
+/**
+ *  Friend events let users see the public events of their accepted friends, either of one friend or
+ *  as a feed of the upcoming public events of all of them.
+ *
+ *  @file       event_friends.go
+ *  @package    services
+ *
+ *  @methods
+ *  - GetPublicEventsForUser(ctx, ownerEmail, from, to) - Lists a user's public events between two dates, without authorization.
+ *  - GetFriendEvents(ctx, userEmail, username, from, to) - Lists the public events of one of the user's friends.
+ *  - GetFriendsEventFeed(ctx, userEmail)               - Lists the public events of all of the user's friends in the next FriendFeedDays days.
+ *  - friendEventView(event, username)                  - Builds the view of an event shown to friends.
+ *  - isPublic(event)                                   - Reports whether friends of the owner may see an event.
+ *
+ *  @behaviors
+ *  - Only events with the "public" event type are listed. Private events, cancelled events and the
+ *    events the owner was invited to by others are never shown to friends.
+ *  - Only accepted friends, in either direction of the request, may see a user's public events;
+ *    anyone else gets ErrNotFriends, even if the user has no public events.
+ *  - Recurring events are expanded into their occurrences within the window. Events are shown as
+ *    models.FriendEvent, in the time zone of the viewer, without the owner's email or account data.
+ *  - from and to default to the FriendFeedDays days starting today in the viewer's time zone, and may
+ *    be at most one year apart.
+ *  - The feed reads the events of at most friendFeedConcurrency friends at a time, and fails if the
+ *    events of any friend cannot be read, rather than returning a feed with gaps.
+ *
+ *  @errors
+ *  - ErrNotFriends: The user and the owner of the events are not accepted friends.
+ *  - "User not found": No user has the username.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"proh2052-group6/pkg/dates"
+	"proh2052-group6/pkg/models"
+)
+
+// FriendFeedDays is how many days, starting today, the feed of friends' events covers.
+const FriendFeedDays = 14
+
+// friendFeedConcurrency is how many friends' events the feed reads at the same time.
+const friendFeedConcurrency = 8
+
+// ErrNotFriends is returned when a user asks for the events of someone who is not an accepted friend.
+var ErrNotFriends = errors.New("You can only see the events of your friends")
+
+// GetPublicEventsForUser returns the public events of ownerEmail between from and to (YYYY-MM-DD,
+// inclusive), with the occurrences of recurring events, in their stored time zone. It does not check
+// who may see them; callers must.
+func (es *EventService) GetPublicEventsForUser(ctx context.Context, ownerEmail, from, to string) ([]models.Event, error) {
+	var events []models.Event
+
+	stored, err := es.EventRepo.GetAllEvents(ctx, ownerEmail, models.EventQuery{From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range filterCancelled(stored.Items, false) {
+		// Series are fetched separately, since their first occurrence may lie before the window.
+		if event.Recurrence == nil && isPublic(event) {
+			events = append(events, event)
+		}
+	}
+
+	series, err := es.EventRepo.GetRecurringEvents(ctx, ownerEmail)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range filterCancelled(series, false) {
+		if isPublic(event) {
+			events = append(events, expandEvent(event, from, to)...)
+		}
+	}
+	return events, nil
+}
+
+// GetFriendEvents returns the public events between from and to of the friend of userEmail with the
+// given username, in the time zone of userEmail and ordered by date and start time.
+func (es *EventService) GetFriendEvents(ctx context.Context, userEmail, username, from, to string) ([]models.FriendEvent, error) {
+	owner, err := es.UserRepo.GetUserByUsername(ctx, strings.TrimSpace(username))
+	if isRepositoryFailure(err) {
+		return nil, err
+	}
+	if err != nil || owner == nil {
+		return nil, fmt.Errorf("User not found")
+	}
+	if owner.Email == userEmail {
+		return nil, ErrNotFriends
+	}
+	friends, err := es.areFriends(ctx, userEmail, owner.Email)
+	if err != nil {
+		return nil, err
+	}
+	if !friends {
+		return nil, ErrNotFriends
+	}
+
+	from, to, err = es.friendEventWindow(ctx, userEmail, from, to)
+	if err != nil {
+		return nil, err
+	}
+	events, err := es.GetPublicEventsForUser(ctx, owner.Email, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return es.friendEventViews(ctx, userEmail, events, map[string]string{owner.Email: owner.Username})
+}
+
+// GetFriendsEventFeed returns the public events of all friends of userEmail in the FriendFeedDays
+// days starting today, in the time zone of userEmail and ordered by date and start time.
+func (es *EventService) GetFriendsEventFeed(ctx context.Context, userEmail string) ([]models.FriendEvent, error) {
+	from, to, err := es.friendEventWindow(ctx, userEmail, "", "")
+	if err != nil {
+		return nil, err
+	}
+	friendships, err := es.FriendRepo.GetFriends(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+		events    []models.Event
+		usernames = make(map[string]string)
+		firstErr  error
+	)
+	slots := make(chan struct{}, friendFeedConcurrency)
+	seen := make(map[string]bool)
+	for _, friendship := range friendships {
+		friendEmail := friendship.FriendEmail
+		if friendEmail == userEmail {
+			friendEmail = friendship.Email
+		}
+		if seen[friendEmail] {
+			continue
+		}
+		seen[friendEmail] = true
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			username, friendEvents, err := es.friendFeedEvents(ctx, friendEmail, from, to)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			events = append(events, friendEvents...)
+			usernames[friendEmail] = username
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return es.friendEventViews(ctx, userEmail, events, usernames)
+}
+
+// friendFeedEvents returns the username and public events between from and to of the friend with
+// friendEmail. Friends whose account no longer exists have no events.
+func (es *EventService) friendFeedEvents(ctx context.Context, friendEmail, from, to string) (string, []models.Event, error) {
+	friend, err := es.UserRepo.GetUserByEmail(ctx, friendEmail)
+	if isRepositoryFailure(err) {
+		return "", nil, err
+	}
+	if err != nil || friend == nil {
+		return "", nil, nil
+	}
+	events, err := es.GetPublicEventsForUser(ctx, friendEmail, from, to)
+	if err != nil {
+		return "", nil, err
+	}
+	return friend.Username, events, nil
+}
+
+// friendEventWindow validates the from and to dates of a listing of friends' events, defaulting to
+// the FriendFeedDays days starting today in the time zone of userEmail.
+func (es *EventService) friendEventWindow(ctx context.Context, userEmail, from, to string) (string, string, error) {
+	fromDate, toDate, err := dates.ParseRange(from, to)
+	if err != nil {
+		return "", "", err
+	}
+	if from == "" {
+		loc, err := es.userLocation(ctx, userEmail)
+		if err != nil {
+			return "", "", err
+		}
+		fromDate = dates.Today(es.Now(), loc)
+		if to != "" && toDate.Before(fromDate) {
+			return "", "", fmt.Errorf("to must not be before today")
+		}
+	}
+	if to == "" {
+		toDate = fromDate.AddDate(0, 0, FriendFeedDays-1)
+	}
+	if toDate.After(fromDate.AddDate(1, 0, 0)) {
+		return "", "", fmt.Errorf("Date range must not exceed one year")
+	}
+	return fromDate.Format("2006-01-02"), toDate.Format("2006-01-02"), nil
+}
+
+// friendEventViews renders events in the time zone of userEmail and returns their views for friends
+// ordered by date and start time, naming each owner with the username in usernames.
+func (es *EventService) friendEventViews(ctx context.Context, userEmail string, events []models.Event, usernames map[string]string) ([]models.FriendEvent, error) {
+	if err := es.renderEventsFor(ctx, userEmail, events); err != nil {
+		return nil, err
+	}
+	views := make([]models.FriendEvent, 0, len(events))
+	for _, event := range events {
+		views = append(views, friendEventView(event, usernames[event.Email]))
+	}
+	sort.SliceStable(views, func(i, j int) bool {
+		if views[i].Date != views[j].Date {
+			return views[i].Date < views[j].Date
+		}
+		if views[i].StartTime != views[j].StartTime {
+			return views[i].StartTime < views[j].StartTime
+		}
+		return views[i].EventID < views[j].EventID
+	})
+	return views, nil
+}
+
+// friendEventView returns the fields of event that friends may see, owned by username.
+func friendEventView(event models.Event, username string) models.FriendEvent {
+	return models.FriendEvent{
+		EventID:       event.EventID,
+		Username:      username,
+		Title:         event.Title,
+		Description:   event.Description,
+		Date:          event.Date,
+		StartTime:     event.StartTime,
+		EndTime:       event.EndTime,
+		StartAt:       event.StartAt,
+		EndAt:         event.EndAt,
+		TimeZone:      event.TimeZone,
+		AllDay:        event.AllDay,
+		StreetAddress: event.StreetAddress,
+		PostalNumber:  event.PostalNumber,
+		Status:        event.Status,
+	}
+}
+
+// isPublic reports whether friends of the owner may see event.
+func isPublic(event models.Event) bool {
+	return strings.EqualFold(event.EventTypeID, "public")
+}
@@ -0,0 +1,173 @@
+/**
+ *  Idempotent event creation lets clients safely retry a create request: the client sends the
+ *  same Idempotency-Key with each attempt, and attempts after the first return the event that
+ *  was originally created instead of inserting a duplicate.
+ *
+ *  @file       event_idempotency.go
+ *  @package    services
+ *
+ *  @methods
+ *  - CreateEventIdempotent(ctx, event, key) - Creates an event once per key, replaying the original on retries.
+ *  - idempotencyRequestHash(event)          - Hashes a create request to detect a key reused for another request.
+ *
+ *  @behaviors
+ *  - Keys are scoped to the user and expire after idempotencyKeyTTL.
+ *  - The key is claimed with a create-if-absent write before the event is created, so of several
+ *    concurrent requests with the same key only one creates an event.
+ *  - Reusing a key for a different request returns ErrIdempotencyKeyReused; retrying while the first
+ *    request is still being processed returns ErrIdempotencyRequestInProgress.
+ *  - If creating the event fails, the key is released so the request can be retried.
+ *  - A pending record holds the key for idempotencyLeaseTTL. If its request dies before creating the
+ *    event, a retry after the lease takes the key over and creates the event.
+ *  - The event ID is stored on the record as soon as the event exists. If completing the record then
+ *    fails, a retry finishes the record from the stored event and replays it instead of waiting for
+ *    the key to expire.
+ *  - Without a key, or without an IdempotencyRepo, events are created as usual.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+const (
+	idempotencyKeyTTL          = 24 * time.Hour // How long a key is remembered after its first use.
+	idempotencyLeaseTTL        = time.Minute    // How long a pending key is held, well beyond the request timeout.
+	maxIdempotencyKeyLength    = 255            // Maximum length of an Idempotency-Key in bytes.
+	idempotencyStatusPending   = "pending"
+	idempotencyStatusCompleted = "completed"
+)
+
+var (
+	// ErrInvalidIdempotencyKey is returned for an Idempotency-Key that is too long.
+	ErrInvalidIdempotencyKey = fmt.Errorf("Idempotency key must be at most %d characters", maxIdempotencyKeyLength)
+	// ErrIdempotencyKeyReused is returned when a key is sent again with a different request.
+	ErrIdempotencyKeyReused = errors.New("Idempotency key was already used for a different request")
+	// ErrIdempotencyRequestInProgress is returned when a key is sent again before the first request has finished.
+	ErrIdempotencyRequestInProgress = errors.New("A request with this idempotency key is still being processed")
+)
+
+// CreateEventIdempotent creates event like CreateEvent, at most once per user and key. If the key was
+// already used for the same request, event is replaced by the originally created event and replayed
+// is true. An empty key creates the event without idempotency.
+func (es *EventService) CreateEventIdempotent(ctx context.Context, event *models.Event, key string) (replayed bool, err error) {
+	if key == "" || es.IdempotencyRepo == nil {
+		return false, es.CreateEvent(ctx, event)
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return false, ErrInvalidIdempotencyKey
+	}
+
+	requestHash, err := idempotencyRequestHash(event)
+	if err != nil {
+		return false, err
+	}
+	now := es.Now()
+	record := &models.IdempotencyRecord{
+		Key:         key,
+		Email:       event.Email,
+		RequestHash: requestHash,
+		Status:      idempotencyStatusPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(idempotencyKeyTTL),
+
+		LeaseExpiresAt: now.Add(idempotencyLeaseTTL),
+	}
+
+	err = es.IdempotencyRepo.CreateRecord(ctx, record)
+	if errors.Is(err, repositories.ErrIdempotencyKeyExists) {
+		return es.replayEvent(ctx, event, key, requestHash)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := es.CreateEvent(ctx, event); err != nil {
+		// Release the key so the client can retry with the same key.
+		es.IdempotencyRepo.DeleteRecord(ctx, event.Email, key)
+		return false, err
+	}
+
+	// Store the event ID first, so the key can no longer be taken over and a retry can finish the record
+	// if completing it fails.
+	record.EventID = event.EventID
+	if err := es.IdempotencyRepo.CompleteRecord(ctx, record); err != nil {
+		return false, err
+	}
+	return false, es.completeIdempotencyRecord(ctx, record, event)
+}
+
+// completeIdempotencyRecord stores event as the response of record, so retries replay it.
+func (es *EventService) completeIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord, event *models.Event) error {
+	response, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	record.Status = idempotencyStatusCompleted
+	record.EventID = event.EventID
+	record.Response = response
+	return es.IdempotencyRepo.CompleteRecord(ctx, record)
+}
+
+// replayEvent replaces event with the event originally created for a user's key.
+func (es *EventService) replayEvent(ctx context.Context, event *models.Event, key, requestHash string) (bool, error) {
+	record, err := es.IdempotencyRepo.GetRecord(ctx, event.Email, key)
+	if errors.Is(err, repositories.ErrIdempotencyRecordNotFound) {
+		// The first request failed and released the key after we tried to claim it.
+		return false, ErrIdempotencyRequestInProgress
+	}
+	if err != nil {
+		return false, err
+	}
+	if record.RequestHash != requestHash {
+		return false, ErrIdempotencyKeyReused
+	}
+	if record.Status != idempotencyStatusCompleted {
+		if record.EventID == "" {
+			return false, ErrIdempotencyRequestInProgress
+		}
+		// The event was created but the record not completed; finish it from the stored event.
+		created, err := es.EventRepo.GetEvent(ctx, event.Email, record.EventID)
+		if err != nil {
+			return false, fmt.Errorf("Failed to retrieve created event: %w", err)
+		}
+		if err := es.completeIdempotencyRecord(ctx, record, created); err != nil {
+			return false, err
+		}
+		*event = *created
+		return true, nil
+	}
+
+	var original models.Event
+	if err := json.Unmarshal(record.Response, &original); err != nil {
+		return false, fmt.Errorf("Failed to parse stored event: %v", err)
+	}
+	*event = original
+	return true, nil
+}
+
+// idempotencyRequestHash returns the SHA-256 of an event as submitted, before it is normalised.
+func idempotencyRequestHash(event *models.Event) (string, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(body)
+	return hex.EncodeToString(hash[:]), nil
+}
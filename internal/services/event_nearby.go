@@ -0,0 +1,121 @@
+/**
+ *  Event locations give events with an address map coordinates, looked up with a GeocodingService,
+ *  and find the events closest to a position.
+ *
+ *  @file       event_nearby.go
+ *  @package    services
+ *
+ *  @methods
+ *  - GetNearbyEvents(ctx, userEmail, latitude, longitude, radiusKm) - Lists a user's events within a radius, nearest first.
+ *  - HaversineKm(lat1, lng1, lat2, lng2)                           - Returns the great-circle distance between two positions.
+ *  - geocodeEvent(ctx, event)                                      - Fills in the coordinates of an event from its address.
+ *
+ *  @behaviors
+ *  - Events are geocoded on create and update when they have an address but no coordinates.
+ *    Geocoding failures are logged and leave the coordinates empty; they never fail the request.
+ *  - Coordinates sent by the client are kept as they are.
+ *  - Nearby events are the user's own events and accepted invitations with coordinates; a
+ *    recurring series is listed once.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"proh2052-group6/pkg/models"
+)
+
+const (
+	earthRadiusKm     = 6371.0          // Mean radius of the earth.
+	maxNearbyRadiusKm = 500.0           // Largest radius events can be searched within.
+	geocodeTimeout    = 3 * time.Second // Longest a request waits for its event to be geocoded.
+)
+
+// HaversineKm returns the great-circle distance in kilometres between two positions given in degrees.
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// GetNearbyEvents returns the user's events within radiusKm of the given position, nearest first.
+func (es *EventService) GetNearbyEvents(ctx context.Context, userEmail string, latitude, longitude, radiusKm float64) ([]models.NearbyEvent, error) {
+	if latitude < -90 || latitude > 90 {
+		return nil, fmt.Errorf("lat must be between -90 and 90")
+	}
+	if longitude < -180 || longitude > 180 {
+		return nil, fmt.Errorf("lng must be between -180 and 180")
+	}
+	if radiusKm <= 0 || radiusKm > maxNearbyRadiusKm {
+		return nil, fmt.Errorf("radiusKm must be greater than 0 and at most 500")
+	}
+
+	page, err := es.GetAllEvents(ctx, userEmail, models.EventQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	nearby := []models.NearbyEvent{}
+	for _, event := range page.Items {
+		if event.Latitude == nil || event.Longitude == nil {
+			continue
+		}
+		distance := HaversineKm(latitude, longitude, *event.Latitude, *event.Longitude)
+		if distance <= radiusKm {
+			nearby = append(nearby, models.NearbyEvent{Event: event, DistanceKm: distance})
+		}
+	}
+	sort.SliceStable(nearby, func(i, j int) bool { return nearby[i].DistanceKm < nearby[j].DistanceKm })
+	return nearby, nil
+}
+
+// geocodeEvent fills in the coordinates of an event that has an address but no coordinates.
+func (es *EventService) geocodeEvent(ctx context.Context, event *models.Event) {
+	if es.Geocoder == nil || event.Latitude != nil || event.Longitude != nil {
+		return
+	}
+	address := eventAddress(event)
+	if address == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, geocodeTimeout)
+	defer cancel()
+	coordinates, err := es.Geocoder.Geocode(ctx, address)
+	if err != nil {
+		if !errors.Is(err, ErrAddressNotFound) {
+			log.Printf("Failed to geocode the address of an event of %s: %v", event.Email, err)
+		}
+		return
+	}
+	event.Latitude, event.Longitude = &coordinates.Latitude, &coordinates.Longitude
+}
+
+// eventAddress joins the street address and postal number of an event.
+func eventAddress(event *models.Event) string {
+	var parts []string
+	for _, part := range []string{event.StreetAddress, event.PostalNumber} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,205 @@
+/**
+ *  Event patches update only the fields of an event that a request sends, so a client that does not
+ *  echo every field back cannot wipe the others, and only the fields that changed are written.
+ *
+ *  @file       event_patch.go
+ *  @package    services
+ *
+ *  @methods
+ *  - PatchEvent(ctx, userEmail, eventID, patch) - Applies the given fields to an existing event.
+ *  - applyEventPatch(event, patch)              - Overlays a patch on an event.
+ *  - changedEventFields(old, new)               - Lists the fields that differ between two versions of an event.
+ *
+ *  @behaviors
+ *  - A patch is keyed by the JSON field names of models.Event; null resets a field.
+ *  - The patched event is validated and normalized like a full update, and is returned as saved.
+ *  - email and eventID cannot be changed, but may be sent with their current values. Fields set by
+ *    the server, such as reminderSent and seriesID, are ignored as they are on full updates.
+ *  - Dates and times are read in the user's time zone. The stored times are converted to it first,
+ *    so patching the title does not move an event created in another time zone.
+ *  - A patch with startAt or endAt but no date or times sets the times from the timestamps.
+ *  - Changing the address without sending coordinates clears the old ones, so the new address is geocoded.
+ *
+ *  @errors
+ *  - ErrImmutableEventField: The patch changes email or eventID.
+ *  - ErrUnknownEventField: The patch has a field events do not have.
+ *  - ErrInvalidEventField: A field of the patch has a value of the wrong type.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"proh2052-group6/pkg/models"
+)
+
+var (
+	// ErrImmutableEventField is returned for an update that changes the owner or ID of an event.
+	ErrImmutableEventField = errors.New("Event email and ID cannot be changed")
+	// ErrUnknownEventField is returned for a patch with a field events do not have.
+	ErrUnknownEventField = errors.New("Unknown field")
+	// ErrInvalidEventField is returned for a patch with a value of the wrong type.
+	ErrInvalidEventField = errors.New("Invalid value for field")
+)
+
+// eventFields are the fields of models.Event by JSON name.
+var eventFields = jsonFields(reflect.TypeOf(models.Event{}))
+
+// serverManagedEventFields are the JSON fields of an event that clients cannot set.
+var serverManagedEventFields = map[string]bool{
+	"reminderSent":   true,
+	"timeZone":       true,
+	"externalID":     true,
+	"importBatchID":  true,
+	"importedAt":     true,
+	"exceptionDates": true,
+	"seriesID":       true,
+	"cancelledAt":    true,
+}
+
+// jsonFields maps the JSON names of the fields of struct type t to the fields.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = field
+		}
+	}
+	return fields
+}
+
+// PatchEvent applies patch, the JSON fields to change, to the event eventID of userEmail and saves
+// the fields that changed. It returns the updated event.
+func (es *EventService) PatchEvent(ctx context.Context, userEmail, eventID string, patch map[string]json.RawMessage) (*models.Event, error) {
+	loc, err := es.userLocation(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := es.ownedEvent(ctx, userEmail, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := *existing
+	renderEventTimes(&current, loc)
+	event, err := applyEventPatch(current, patch)
+	if err != nil {
+		return nil, err
+	}
+	if err := es.prepareUpdate(ctx, event, existing, loc); err != nil {
+		return nil, err
+	}
+
+	if updates := changedEventFields(existing, event); len(updates) > 0 {
+		if err := es.EventRepo.UpdateEventFields(ctx, userEmail, eventID, updates); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+// applyEventPatch returns a copy of event with the fields in patch replaced.
+func applyEventPatch(event models.Event, patch map[string]json.RawMessage) (*models.Event, error) {
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to encode event: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, fmt.Errorf("Failed to encode event: %w", err)
+	}
+
+	for name, value := range patch {
+		field, known := eventFields[name]
+		if !known {
+			return nil, fmt.Errorf("%w %q", ErrUnknownEventField, name)
+		}
+		decoded := reflect.New(field.Type)
+		if err := json.Unmarshal(value, decoded.Interface()); err != nil {
+			return nil, fmt.Errorf("%w %q", ErrInvalidEventField, name)
+		}
+		if name == "email" || name == "eventID" {
+			if decoded.Elem().String() != reflect.ValueOf(event).FieldByName(field.Name).String() {
+				return nil, ErrImmutableEventField
+			}
+			continue
+		}
+		if serverManagedEventFields[name] {
+			continue
+		}
+		fields[name] = value
+	}
+
+	// Timestamps without a date or times replace the stored date and times, as on create.
+	_, hasDate := patch["date"]
+	_, hasStartTime := patch["startTime"]
+	_, hasEndTime := patch["endTime"]
+	_, hasStartAt := patch["startAt"]
+	_, hasEndAt := patch["endAt"]
+	if (hasStartAt || hasEndAt) && !hasDate && !hasStartTime && !hasEndTime {
+		delete(fields, "date")
+		delete(fields, "startTime")
+		delete(fields, "endTime")
+	}
+
+	encoded, err = json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to encode event: %w", err)
+	}
+	var patched models.Event
+	if err := json.Unmarshal(encoded, &patched); err != nil {
+		return nil, fmt.Errorf("Failed to encode event: %w", err)
+	}
+
+	// Coordinates of the old address do not belong to a new one.
+	_, hasLatitude := patch["latitude"]
+	_, hasLongitude := patch["longitude"]
+	if eventAddress(&patched) != eventAddress(&event) && !hasLatitude && !hasLongitude {
+		patched.Latitude, patched.Longitude = nil, nil
+	}
+	return &patched, nil
+}
+
+// changedEventFields returns the fields of updated that differ from old, keyed by Go field name,
+// which is also their name in the database.
+func changedEventFields(old, updated *models.Event) map[string]interface{} {
+	oldValue, newValue := reflect.ValueOf(old).Elem(), reflect.ValueOf(updated).Elem()
+	updates := make(map[string]interface{})
+	for i := 0; i < newValue.NumField(); i++ {
+		if !sameFieldValue(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			updates[newValue.Type().Field(i).Name] = newValue.Field(i).Interface()
+		}
+	}
+	return updates
+}
+
+// sameFieldValue reports whether two values of an event field are equal. Times are compared as
+// instants, since stored times may come back in another location.
+func sameFieldValue(a, b interface{}) bool {
+	switch a := a.(type) {
+	case time.Time:
+		return a.Equal(b.(time.Time))
+	case *time.Time:
+		b := b.(*time.Time)
+		if a == nil || b == nil {
+			return a == b
+		}
+		return a.Equal(*b)
+	}
+	return reflect.DeepEqual(a, b)
+}
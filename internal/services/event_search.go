@@ -0,0 +1,90 @@
+/**
+ *  Event search finds a user's events whose title, description or address contains a query.
+ *
+ *  @file       event_search.go
+ *  @package    services
+ *
+ *  @methods
+ *  - SearchEvents(ctx, userEmail, query, from, to) - Lists the user's events between two dates matching a query.
+ *  - matchEventField(event, query)                 - Names the first field of an event containing a query.
+ *
+ *  @behaviors
+ *  - Firestore cannot match substrings, so the events of the window are fetched with the date range
+ *    query and filtered here. The window is therefore limited to one year.
+ *  - Matching is case-insensitive. Own events, accepted invitations and occurrences of recurring
+ *    events are searched, as listed by GetAllEvents; cancelled events are left out.
+ *  - Results are ordered by date, then start time, and name the field that matched, checking the
+ *    title first, then the description, then the street address.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"proh2052-group6/pkg/dates"
+	"proh2052-group6/pkg/models"
+)
+
+// SearchEvents returns the user's events between from and to (YYYY-MM-DD, inclusive) whose title,
+// description or street address contains query, ordered by date and start time.
+func (es *EventService) SearchEvents(ctx context.Context, userEmail, query, from, to string) ([]models.EventSearchResult, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, fmt.Errorf("q is required")
+	}
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("from and to are required")
+	}
+	fromDate, toDate, err := dates.ParseRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if toDate.After(fromDate.AddDate(1, 0, 0)) {
+		return nil, fmt.Errorf("Search range must not exceed one year")
+	}
+
+	page, err := es.getEventsInWindow(ctx, userEmail, models.EventQuery{From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+
+	results := []models.EventSearchResult{}
+	for _, event := range page.Items {
+		if field := matchEventField(event, query); field != "" {
+			results = append(results, models.EventSearchResult{Event: event, MatchedField: field})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Date != results[j].Date {
+			return results[i].Date < results[j].Date
+		}
+		return results[i].StartTime < results[j].StartTime
+	})
+	return results, nil
+}
+
+// matchEventField returns the JSON name of the first searchable field of event containing query,
+// which must be lower case, or "" if none does.
+func matchEventField(event models.Event, query string) string {
+	fields := []struct{ name, value string }{
+		{"title", event.Title},
+		{"description", event.Description},
+		{"streetAddress", event.StreetAddress},
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field.value), query) {
+			return field.name
+		}
+	}
+	return ""
+}
@@ -5,29 +5,84 @@
  *  @interface EventServiceInterface
  *  @methods
  *  - CreateEvent(ctx, event)                  - Creates a new event with validation.
+ *  - CreateEventIdempotent(ctx, event, key)   - Creates an event once per Idempotency-Key, replaying it on retries.
  *  - GetEvent(ctx, userEmail, eventID)        - Retrieves a specific event by its ID.
- *  - UpdateEvent(ctx, event)                  - Updates an existing event.
- *  - DeleteEvent(ctx, userEmail, eventID)     - Deletes a specific event by its ID.
- *  - GetAllEvents(ctx, userEmail)             - Retrieves all events for a given user.
+ *  - UpdateEvent(ctx, event)                  - Replaces an existing event, or an entire recurring series.
+ *  - PatchEvent(ctx, userEmail, eventID, patch) - Updates only the given fields of an existing event.
+ *  - UpdateOccurrence(ctx, event, date)       - Replaces a single occurrence of a recurring event.
+ *  - DeleteEvent(ctx, userEmail, eventID)     - Deletes a specific event, or an entire recurring series.
+ *  - DeleteOccurrence(ctx, userEmail, eventID, date) - Removes a single occurrence of a recurring event.
+ *  - GetAllEvents(ctx, userEmail, query)      - Retrieves a page of events for a given user.
+ *  - InviteToEvent(ctx, ownerEmail, eventID, identifier) - Invites a friend to an event.
+ *  - RespondToInvitation(ctx, userEmail, eventID, response) - Accepts or declines an invitation.
+ *  - GetInvitations(ctx, userEmail)           - Retrieves all invitations received by a user.
+ *  - GetEventTags(ctx, userEmail)             - Counts the tags used on a user's events.
+ *  - GetNearbyEvents(ctx, userEmail, lat, lng, radiusKm) - Lists a user's events within a radius, nearest first.
+ *  - SearchEvents(ctx, userEmail, query, from, to)       - Lists a user's events within a year matching a query.
+ *  - BulkCreateEvents(ctx, userEmail, events) - Creates up to 100 events, reporting the outcome per event.
+ *  - BulkDeleteEvents(ctx, userEmail, eventIDs) - Deletes up to 100 events, reporting the outcome per event.
+ *  - CancelEvent(ctx, userEmail, eventID)    - Cancels an event, keeping it for history.
+ *  - GetEventMonth(ctx, userEmail, month)     - Summarizes a user's events per day of a month's calendar grid.
+ *  - ShareEvent(ctx, userEmail, eventID)      - Creates a read-only link to an event.
+ *  - RevokeEventShare(ctx, userEmail, eventID) - Deletes the read-only links to an event.
+ *  - GetSharedEvent(ctx, token)               - Returns the read-only view of a shared event.
+ *  - GetFriendEvents(ctx, userEmail, username, from, to) - Lists the public events of a friend.
+ *  - GetFriendsEventFeed(ctx, userEmail)      - Lists the upcoming public events of all of a user's friends.
+ *  - DuplicateEvent(ctx, userEmail, eventID, date, count) - Copies an event to a date and the following weeks.
  *
  *  @struct   EventService
  *  @inherits EventServiceInterface
  *
  *  @methods
- *  - NewEventService(eventRepo)              - Initializes a new EventService with the given repository.
+ *  - NewEventService(eventRepo, invitationRepo, userRepo, friendRepo, notificationService, idempotencyRepo, geocoder) - Initializes a new EventService.
  *  - CreateEvent(ctx, event)                 - Implements event creation logic.
  *  - GetEvent(ctx, userEmail, eventID)       - Implements event retrieval logic.
  *  - UpdateEvent(ctx, event)                 - Implements event update logic.
  *  - DeleteEvent(ctx, userEmail, eventID)    - Implements event deletion logic.
- *  - GetAllEvents(ctx, userEmail)            - Implements logic to retrieve all events for a user.
+ *  - GetAllEvents(ctx, userEmail, query)     - Implements logic to retrieve owned and accepted events for a user.
+ *  - GetEventTags(ctx, userEmail)            - Implements logic to count the tags on a user's own events.
  *
  *  @behaviors
- *  - Validates event data (e.g., EventTypeID, Date format) before creating an event.
- *  - Ensures only authorized users can access or modify their events.
- *  - Handles errors gracefully and returns meaningful messages on failure.
+ *  - Validates event data (e.g., EventTypeID, Date format) before creating an event. The date must be
+ *    within dates.MaxEventYears of today in the user's time zone; invalid dates and date ranges are
+ *    rejected with a *dates.Error naming the field or query parameter.
+ *  - Validates the title, description, times and postal number with validate.Event on create and update.
+ *  - Tags are lowercased and validated on create and update; listings can be filtered by a single tag.
+ *  - Ensures only authorized users can access or modify their events; updating or deleting an event
+ *    of another user, or one that does not exist, returns "Event not found" instead of succeeding.
+ *  - Partial updates only write the fields that changed; see event_patch.go.
+ *  - Validates the date range of event listings and caps the page size at maxEventPageSize.
+ *  - Validates recurrence rules; a recurring series is stored once and expanded into occurrences
+ *    when events are listed with both a from and a to date.
+ *  - Changing or deleting one occurrence records its date as an exception on the series; a changed
+ *    occurrence is stored as a separate event linked to the series through SeriesID.
+ *  - Only accepted friends can be invited to an event; repeated invites are idempotent.
+ *  - New invitations are stored in the invitee's notification inbox and pushed to their open WebSocket connections.
+ *  - Create requests with an Idempotency-Key are processed once per user and key; see event_idempotency.go.
+ *  - Bulk creates and deletes validate and authorize every event on its own; see event_bulk.go.
+ *  - The import batch of an event is set by timetable imports only; clients cannot set or change it.
+ *  - Event times are read in the user's time zone and stored with StartAt, EndAt and TimeZone; events
+ *    are returned in the time zone of the user viewing them. See event_timezone.go.
+ *  - Events are tentative, confirmed or cancelled. Cancelled events are kept but cannot be changed, and
+ *    are left out of listings unless IncludeCancelled is set; see event_status.go.
+ *  - Events may have a calendar color and be all-day events without times; see event_calendar.go.
+ *  - Events with an address but no coordinates are geocoded on create and update; a failed lookup
+ *    leaves the coordinates empty instead of failing the request. See event_nearby.go.
+ *  - Events can be shared with people without an account through an expiring read-only link; see event_share.go.
+ *  - Accepted friends can see a user's public events, but never private ones; see event_friends.go.
+ *  - Events can be copied to other dates, up to MaxEventCopies weeks at once; see event_duplicate.go.
+ *  - Handles errors gracefully and returns meaningful messages on failure. Repository failures are
+ *    returned wrapped, so repositories.ErrUnavailable is never reported as a missing event or user.
  *
  *  @dependencies
  *  - repositories.EventRepository: Repository for interacting with event data in the database.
+ *  - repositories.InvitationRepository: Repository for event invitations and RSVP status.
+ *  - repositories.UserRepository: Resolves invitees by username or email.
+ *  - repositories.FriendRepository: Verifies that invitees are accepted friends.
+ *  - NotificationServiceInterface: Stores invitation notifications and pushes them to connected clients.
+ *  - repositories.IdempotencyRepository: Remembers the events created for Idempotency-Keys.
+ *  - GeocodingService: Looks up the coordinates of event addresses.
+ *  - repositories.ShareRepository: Stores the read-only links to events.
  *  - models.Event: Struct representing the event entity.
  *
  *  @example
@@ -54,50 +109,146 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/dates"
 	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+	"proh2052-group6/pkg/validate"
 )
 
+// maxEventPageSize is the largest number of events returned in a single page.
+const maxEventPageSize = 500
+
 // EventServiceInterface defines methods for managing events.
 type EventServiceInterface interface {
 	CreateEvent(ctx context.Context, event *models.Event) error
+	CreateEventIdempotent(ctx context.Context, event *models.Event, key string) (bool, error)
 	GetEvent(ctx context.Context, userEmail, eventID string) (*models.Event, error)
 	UpdateEvent(ctx context.Context, event *models.Event) error
+	PatchEvent(ctx context.Context, userEmail, eventID string, patch map[string]json.RawMessage) (*models.Event, error)
+	UpdateOccurrence(ctx context.Context, event *models.Event, occurrenceDate string) error
 	DeleteEvent(ctx context.Context, userEmail, eventID string) error
-	GetAllEvents(ctx context.Context, userEmail string) ([]models.Event, error)
+	DeleteOccurrence(ctx context.Context, userEmail, eventID, occurrenceDate string) error
+	GetAllEvents(ctx context.Context, userEmail string, query models.EventQuery) (*models.EventPage, error)
+	InviteToEvent(ctx context.Context, ownerEmail, eventID, identifier string) error
+	RespondToInvitation(ctx context.Context, userEmail, eventID, response string) error
+	GetInvitations(ctx context.Context, userEmail string) ([]models.EventInvitation, error)
+	GetEventTags(ctx context.Context, userEmail string) ([]models.TagCount, error)
+	GetNearbyEvents(ctx context.Context, userEmail string, latitude, longitude, radiusKm float64) ([]models.NearbyEvent, error)
+	SearchEvents(ctx context.Context, userEmail, query, from, to string) ([]models.EventSearchResult, error)
+	BulkCreateEvents(ctx context.Context, userEmail string, events []models.Event) (*models.BulkEventResult, error)
+	BulkDeleteEvents(ctx context.Context, userEmail string, eventIDs []string) (*models.BulkEventResult, error)
+	CancelEvent(ctx context.Context, userEmail, eventID string) error
+	GetEventMonth(ctx context.Context, userEmail, month string) (*models.EventMonth, error)
+	ShareEvent(ctx context.Context, userEmail, eventID string) (*models.EventShareLink, error)
+	RevokeEventShare(ctx context.Context, userEmail, eventID string) error
+	GetSharedEvent(ctx context.Context, token string) (*models.SharedEvent, error)
+	GetFriendEvents(ctx context.Context, userEmail, username, from, to string) ([]models.FriendEvent, error)
+	GetFriendsEventFeed(ctx context.Context, userEmail string) ([]models.FriendEvent, error)
+	DuplicateEvent(ctx context.Context, userEmail, eventID, date string, count int) ([]string, error)
 }
 
 // EventService provides implementations for EventServiceInterface.
 type EventService struct {
-	EventRepo repositories.EventRepository
+	EventRepo       repositories.EventRepository
+	InvitationRepo  repositories.InvitationRepository  // Repository for event invitations.
+	UserRepo        repositories.UserRepository        // Repository for resolving invitees.
+	FriendRepo      repositories.FriendRepository      // Repository for verifying friendships.
+	Notifications   NotificationServiceInterface       // Inbox and push notifications for invitees; may be nil.
+	IdempotencyRepo repositories.IdempotencyRepository // Idempotency-Key records for create requests; may be nil.
+	Geocoder        GeocodingService                   // Looks up the coordinates of event addresses; may be nil.
+	ShareRepo       repositories.ShareRepository       // Read-only links to events; sharing is disabled if nil.
+	AppURL          string                             // Origin of the web app, for the URL of shared events.
+	Now             func() time.Time                   // Clock used for idempotency key expiry and cancellations; replaceable in tests.
 }
 
-// NewEventService initializes a new EventService with the given EventRepository.
-func NewEventService(eventRepo repositories.EventRepository) EventServiceInterface {
-	return &EventService{EventRepo: eventRepo}
+// NewEventService initializes a new EventService with the given repositories.
+func NewEventService(eventRepo repositories.EventRepository, invitationRepo repositories.InvitationRepository, userRepo repositories.UserRepository, friendRepo repositories.FriendRepository, notificationService NotificationServiceInterface, idempotencyRepo repositories.IdempotencyRepository, geocoder GeocodingService) EventServiceInterface {
+	return &EventService{
+		EventRepo:       eventRepo,
+		InvitationRepo:  invitationRepo,
+		UserRepo:        userRepo,
+		FriendRepo:      friendRepo,
+		Notifications:   notificationService,
+		IdempotencyRepo: idempotencyRepo,
+		Geocoder:        geocoder,
+		Now:             time.Now,
+	}
 }
 
 // CreateEvent validates and creates a new event.
 func (es *EventService) CreateEvent(ctx context.Context, event *models.Event) error {
+	loc, err := es.userLocation(ctx, event.Email)
+	if err != nil {
+		return err
+	}
+	if err := es.prepareNewEvent(event, loc); err != nil {
+		return err
+	}
+
+	es.geocodeEvent(ctx, event)
+
+	// Delegate to repository
+	return es.EventRepo.CreateEvent(ctx, event)
+}
+
+// prepareNewEvent validates a new event and normalizes its type, status, date, recurrence, tags and
+// timestamps, reading its date and times in loc, the time zone of the user.
+func (es *EventService) prepareNewEvent(event *models.Event, loc *time.Location) error {
+	if err := fillEventClock(event, loc); err != nil {
+		return err
+	}
+	if err := validate.Event(event); err != nil {
+		return err
+	}
+	if err := normalizeCalendarFields(event); err != nil {
+		return err
+	}
+
 	// Validate EventTypeID
 	event.EventTypeID = strings.ToLower(event.EventTypeID)
 	if event.EventTypeID != "public" && event.EventTypeID != "private" {
 		return fmt.Errorf("Invalid event type")
 	}
 
+	if err := normalizeEventStatus(event, ""); err != nil {
+		return err
+	}
+
 	// Parse and format the date
-	eventDate, err := time.Parse("2006-01-02", event.Date)
+	eventDate, err := dates.ParseEventDate("date", event.Date, dates.Today(es.Now(), loc))
 	if err != nil {
-		return fmt.Errorf("Invalid date format. Please use YYYY-MM-DD.")
+		return err
 	}
-	event.Date = eventDate.Format("2006-01-02")
+	event.Date = eventDate.Format(dates.Layout)
 
-	// Delegate to repository
-	return es.EventRepo.CreateEvent(ctx, event)
+	// Validate the recurrence rule of a series; a new series has no exceptions yet.
+	event.ExceptionDates = nil
+	if event.Recurrence != nil {
+		if err := normalizeRecurrence(event); err != nil {
+			return err
+		}
+	}
+
+	if err := normalizeTags(event); err != nil {
+		return err
+	}
+
+	// Derive the timestamps used for reminders and for showing the event in other time zones.
+	if err := setEventTimestamps(event, loc); err != nil {
+		return err
+	}
+	event.ReminderSent = false
+
+	// Only timetable imports create events belonging to an import batch or calendar.
+	event.ImportBatchID, event.ImportedAt, event.ExternalID = "", nil, ""
+	return nil
 }
 
 // GetEvent retrieves a specific event by its ID and ensures the user is authorized to access it.
@@ -111,20 +262,501 @@ func (es *EventService) GetEvent(ctx context.Context, userEmail, eventID string)
 		return nil, fmt.Errorf("Unauthorized to access this event")
 	}
 
+	loc, err := es.userLocation(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	renderEventTimes(event, loc)
 	return event, nil
 }
 
-// UpdateEvent updates an existing event in the repository.
+// UpdateEvent replaces an existing event of event.Email with event. For a recurring event this
+// updates the entire series, keeping the occurrences that were removed or changed individually.
+// The reminder is re-armed only when the start time or reminder offset changes.
 func (es *EventService) UpdateEvent(ctx context.Context, event *models.Event) error {
+	loc, err := es.userLocation(ctx, event.Email)
+	if err != nil {
+		return err
+	}
+	existing, err := es.ownedEvent(ctx, event.Email, event.EventID)
+	if err != nil {
+		return err
+	}
+	if err := es.prepareUpdate(ctx, event, existing, loc); err != nil {
+		return err
+	}
 	return es.EventRepo.UpdateEvent(ctx, event)
 }
 
-// DeleteEvent deletes a specific event by its ID for a user.
+// ownedEvent retrieves the event of userEmail that is about to be changed or deleted. A missing event, including
+// an event of another user, is reported as "Event not found".
+func (es *EventService) ownedEvent(ctx context.Context, userEmail, eventID string) (*models.Event, error) {
+	existing, err := es.EventRepo.GetEvent(ctx, userEmail, eventID)
+	if isRepositoryFailure(err) {
+		return nil, err
+	}
+	if err != nil || existing == nil || existing.Email != userEmail {
+		return nil, fmt.Errorf("Event not found")
+	}
+	return existing, nil
+}
+
+// prepareUpdate validates and normalizes event, the new version of existing, with times read in loc.
+// Fields managed by the server, such as the import batch and exceptions of a series, are carried
+// over from existing.
+func (es *EventService) prepareUpdate(ctx context.Context, event, existing *models.Event, loc *time.Location) error {
+	if isCancelled(*existing) {
+		return ErrEventCancelled
+	}
+	if err := fillEventClock(event, loc); err != nil {
+		return err
+	}
+	if err := validate.Event(event); err != nil {
+		return err
+	}
+	if err := normalizeCalendarFields(event); err != nil {
+		return err
+	}
+	if _, err := dates.ParseEventDate("date", event.Date, dates.Today(es.Now(), loc)); err != nil {
+		return err
+	}
+
+	if err := setEventTimestamps(event, loc); err != nil {
+		return err
+	}
+	event.ReminderSent = false
+
+	event.ExceptionDates = nil
+	if event.Recurrence != nil {
+		if err := normalizeRecurrence(event); err != nil {
+			return err
+		}
+	}
+
+	if err := normalizeTags(event); err != nil {
+		return err
+	}
+
+	event.ImportBatchID, event.ImportedAt, event.ExternalID = existing.ImportBatchID, existing.ImportedAt, existing.ExternalID
+	if existing.StartAt.Equal(event.StartAt) && existing.ReminderMinutesBefore == event.ReminderMinutesBefore {
+		event.ReminderSent = existing.ReminderSent
+	}
+	if event.Recurrence != nil {
+		event.ExceptionDates = existing.ExceptionDates
+	}
+	event.SeriesID = existing.SeriesID
+	if err := normalizeEventStatus(event, existing.Status); err != nil {
+		return err
+	}
+
+	es.geocodeEvent(ctx, event)
+	return nil
+}
+
+// UpdateOccurrence replaces the occurrence of the recurring event event.EventID on occurrenceDate
+// with the details in event. The replacement is created as a separate event linked to the series,
+// and its EventID is written back to event.
+func (es *EventService) UpdateOccurrence(ctx context.Context, event *models.Event, occurrenceDate string) error {
+	series, err := es.findOccurrence(ctx, event.Email, event.EventID, occurrenceDate)
+	if err != nil {
+		return err
+	}
+
+	replacement := *event
+	replacement.EventID = ""
+	replacement.Recurrence = nil
+	replacement.SeriesID = series.EventID
+	if replacement.Date == "" {
+		replacement.Date = occurrenceDate
+	}
+	if replacement.EventTypeID == "" {
+		replacement.EventTypeID = series.EventTypeID
+	}
+	if err := es.CreateEvent(ctx, &replacement); err != nil {
+		return err
+	}
+
+	series.ExceptionDates = append(series.ExceptionDates, occurrenceDate)
+	if err := es.EventRepo.UpdateEvent(ctx, series); err != nil {
+		return fmt.Errorf("Failed to update event: %w", err)
+	}
+
+	event.EventID = replacement.EventID
+	return nil
+}
+
+// DeleteEvent deletes a specific event by its ID for a user. Deleting a recurring event
+// deletes the entire series, including occurrences that were changed individually.
 func (es *EventService) DeleteEvent(ctx context.Context, userEmail, eventID string) error {
-	return es.EventRepo.DeleteEvent(ctx, userEmail, eventID)
+	event, err := es.ownedEvent(ctx, userEmail, eventID)
+	if err != nil {
+		return err
+	}
+	if err := es.EventRepo.DeleteEvent(ctx, userEmail, eventID); err != nil {
+		return err
+	}
+	if event.Recurrence == nil {
+		return nil
+	}
+
+	page, err := es.EventRepo.GetAllEvents(ctx, userEmail, models.EventQuery{})
+	if err != nil {
+		return fmt.Errorf("Failed to delete changed occurrences of the series: %w", err)
+	}
+	for _, other := range page.Items {
+		if other.SeriesID != eventID {
+			continue
+		}
+		if err := es.EventRepo.DeleteEvent(ctx, userEmail, other.EventID); err != nil {
+			return fmt.Errorf("Failed to delete changed occurrences of the series: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteOccurrence removes the occurrence of a recurring event on occurrenceDate from the series.
+func (es *EventService) DeleteOccurrence(ctx context.Context, userEmail, eventID, occurrenceDate string) error {
+	series, err := es.findOccurrence(ctx, userEmail, eventID, occurrenceDate)
+	if err != nil {
+		return err
+	}
+
+	series.ExceptionDates = append(series.ExceptionDates, occurrenceDate)
+	if err := es.EventRepo.UpdateEvent(ctx, series); err != nil {
+		return fmt.Errorf("Failed to delete event: %w", err)
+	}
+	return nil
+}
+
+// findOccurrence retrieves the recurring event eventID and verifies that occurrenceDate is one of its occurrences.
+func (es *EventService) findOccurrence(ctx context.Context, userEmail, eventID, occurrenceDate string) (*models.Event, error) {
+	if _, err := dates.Parse("date", occurrenceDate); err != nil {
+		return nil, err
+	}
+
+	series, err := es.EventRepo.GetEvent(ctx, userEmail, eventID)
+	if isRepositoryFailure(err) {
+		return nil, err
+	}
+	if err != nil || series == nil {
+		return nil, fmt.Errorf("Event not found")
+	}
+	if series.Recurrence == nil {
+		return nil, fmt.Errorf("Event is not recurring")
+	}
+	if isCancelled(*series) {
+		return nil, ErrEventCancelled
+	}
+	if !isOccurrence(series, occurrenceDate) {
+		return nil, fmt.Errorf("Date is not an occurrence of this event")
+	}
+	return series, nil
+}
+
+// GetAllEvents retrieves a page of events owned by a user. Events the user accepted an invitation to
+// are added to the first page, filtered by the same date range and tag. If both from and to are given,
+// recurring events are expanded into their occurrences within that window.
+func (es *EventService) GetAllEvents(ctx context.Context, userEmail string, query models.EventQuery) (*models.EventPage, error) {
+	if _, _, err := dates.ParseRange(query.From, query.To); err != nil {
+		return nil, err
+	}
+	if query.Limit < 0 {
+		return nil, fmt.Errorf("limit must be a positive number")
+	}
+	if query.Limit > maxEventPageSize {
+		query.Limit = maxEventPageSize
+	}
+	query.Tag = strings.ToLower(strings.TrimSpace(query.Tag))
+
+	// Recurring events can only be expanded within a bounded window.
+	if query.From != "" && query.To != "" {
+		return es.getEventsInWindow(ctx, userEmail, query)
+	}
+
+	page, err := es.EventRepo.GetAllEvents(ctx, userEmail, query)
+	if err != nil {
+		return nil, err
+	}
+	// Firestore cannot exclude a status without also excluding events stored without one, so
+	// cancelled events are left out here, which may shorten the page.
+	page.Items = filterCancelled(page.Items, query.IncludeCancelled)
+
+	if query.PageToken != "" {
+		if err := es.renderEventsFor(ctx, userEmail, page.Items); err != nil {
+			return nil, err
+		}
+		return page, nil
+	}
+
+	invitations, err := es.InvitationRepo.GetInvitationsForUser(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch event invitations: %w", err)
+	}
+
+	for _, invitation := range invitations {
+		if invitation.Status != "accepted" {
+			continue
+		}
+
+		// Skip invitations whose event has since been deleted.
+		event, err := es.EventRepo.GetEvent(ctx, invitation.OwnerEmail, invitation.EventID)
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
+		if err != nil || event == nil || (isCancelled(*event) && !query.IncludeCancelled) {
+			continue
+		}
+		if (query.From != "" && event.Date < query.From) || (query.To != "" && event.Date > query.To) {
+			continue
+		}
+		if !hasTag(*event, query.Tag) {
+			continue
+		}
+		page.Items = append(page.Items, *event)
+	}
+
+	if err := es.renderEventsFor(ctx, userEmail, page.Items); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// getEventsInWindow retrieves a page of the user's own and accepted events between query.From and
+// query.To, with recurring events expanded into their occurrences. The merged list is ordered by
+// Date and EventID and paged in memory; the page token is the date and ID of the last item.
+func (es *EventService) getEventsInWindow(ctx context.Context, userEmail string, query models.EventQuery) (*models.EventPage, error) {
+	var events []models.Event
+
+	stored, err := es.EventRepo.GetAllEvents(ctx, userEmail, models.EventQuery{From: query.From, To: query.To, Tag: query.Tag})
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range filterCancelled(stored.Items, query.IncludeCancelled) {
+		// Series are fetched separately, since their first occurrence may lie before the window.
+		if event.Recurrence == nil {
+			events = append(events, event)
+		}
+	}
+
+	series, err := es.EventRepo.GetRecurringEvents(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range filterCancelled(series, query.IncludeCancelled) {
+		if hasTag(event, query.Tag) {
+			events = append(events, expandEvent(event, query.From, query.To)...)
+		}
+	}
+
+	invitations, err := es.InvitationRepo.GetInvitationsForUser(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch event invitations: %w", err)
+	}
+	for _, invitation := range invitations {
+		if invitation.Status != "accepted" {
+			continue
+		}
+		event, err := es.EventRepo.GetEvent(ctx, invitation.OwnerEmail, invitation.EventID)
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
+		if err != nil || event == nil || !hasTag(*event, query.Tag) || (isCancelled(*event) && !query.IncludeCancelled) {
+			continue
+		}
+		if event.Recurrence != nil {
+			events = append(events, expandEvent(*event, query.From, query.To)...)
+		} else if event.Date >= query.From && event.Date <= query.To {
+			events = append(events, *event)
+		}
+	}
+
+	// Order by the dates the user sees, which may differ from the stored dates of events in other time zones.
+	if err := es.renderEventsFor(ctx, userEmail, events); err != nil {
+		return nil, err
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Date != events[j].Date {
+			return events[i].Date < events[j].Date
+		}
+		return events[i].EventID < events[j].EventID
+	})
+
+	start := 0
+	if query.PageToken != "" {
+		date, eventID, found := strings.Cut(query.PageToken, "/")
+		if !found {
+			return nil, fmt.Errorf("Invalid page token")
+		}
+		start = sort.Search(len(events), func(i int) bool {
+			return events[i].Date > date || (events[i].Date == date && events[i].EventID > eventID)
+		})
+	}
+
+	page := &models.EventPage{Items: []models.Event{}}
+	end := len(events)
+	if query.Limit > 0 && start+query.Limit < end {
+		end = start + query.Limit
+		page.NextPageToken = events[end-1].Date + "/" + events[end-1].EventID
+	}
+	page.Items = append(page.Items, events[start:end]...)
+	return page, nil
+}
+
+// InviteToEvent invites a friend, identified by username or email, to an event owned by ownerEmail.
+// Inviting someone who is already invited is a no-op.
+func (es *EventService) InviteToEvent(ctx context.Context, ownerEmail, eventID, identifier string) error {
+	event, err := es.EventRepo.GetEvent(ctx, ownerEmail, eventID)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err != nil || event == nil {
+		return fmt.Errorf("Event not found")
+	}
+	if isCancelled(*event) {
+		return ErrEventCancelled
+	}
+
+	// Determine if identifier is an email.
+	var invitee *models.User
+	if utils.IsValidEmail(identifier) {
+		invitee, err = es.UserRepo.GetUserByEmail(ctx, identifier)
+	} else {
+		invitee, err = es.UserRepo.GetUserByUsername(ctx, identifier)
+	}
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err != nil || invitee == nil {
+		return fmt.Errorf("User not found")
+	}
+
+	if invitee.Email == ownerEmail {
+		return fmt.Errorf("You cannot invite yourself to your own event")
+	}
+
+	friends, err := es.areFriends(ctx, ownerEmail, invitee.Email)
+	if err != nil {
+		return err
+	}
+	if !friends {
+		return fmt.Errorf("You can only invite friends to an event")
+	}
+
+	existing, err := es.InvitationRepo.GetInvitation(ctx, eventID, invitee.Email)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	invitation := &models.EventInvitation{
+		EventID:      eventID,
+		OwnerEmail:   ownerEmail,
+		InviteeEmail: invitee.Email,
+		Status:       "pending",
+	}
+	if err := es.InvitationRepo.CreateInvitation(ctx, invitation); err != nil {
+		return fmt.Errorf("Failed to send invitation: %w", err)
+	}
+
+	owner := es.displayName(ctx, ownerEmail)
+	sendNotification(ctx, es.Notifications, invitee.Email, models.Notification{
+		Type:    NotificationEventInvitation,
+		Message: fmt.Sprintf("%s invited you to %s", owner, event.Title),
+		Payload: map[string]string{"eventID": eventID, "username": owner},
+	})
+
+	return nil
 }
 
-// GetAllEvents retrieves all events for a specific user from the repository.
-func (es *EventService) GetAllEvents(ctx context.Context, userEmail string) ([]models.Event, error) {
-	return es.EventRepo.GetAllEvents(ctx, userEmail)
+// RespondToInvitation accepts or declines an invitation to an event.
+// response must be either "accept" or "decline".
+func (es *EventService) RespondToInvitation(ctx context.Context, userEmail, eventID, response string) error {
+	var status string
+	switch strings.ToLower(response) {
+	case "accept":
+		status = "accepted"
+	case "decline":
+		status = "declined"
+	default:
+		return fmt.Errorf("Response must be 'accept' or 'decline'")
+	}
+
+	invitation, err := es.InvitationRepo.GetInvitation(ctx, eventID, userEmail)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err != nil || invitation == nil {
+		return fmt.Errorf("Invitation not found")
+	}
+
+	updates := map[string]interface{}{
+		"Status": status,
+	}
+	if err := es.InvitationRepo.UpdateInvitation(ctx, eventID, userEmail, updates); err != nil {
+		return fmt.Errorf("Failed to respond to invitation: %w", err)
+	}
+
+	return nil
+}
+
+// GetInvitations retrieves all invitations received by a user, including the event details.
+func (es *EventService) GetInvitations(ctx context.Context, userEmail string) ([]models.EventInvitation, error) {
+	invitations, err := es.InvitationRepo.GetInvitationsForUser(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch event invitations: %w", err)
+	}
+
+	var results []models.EventInvitation
+	for _, invitation := range invitations {
+		event, err := es.EventRepo.GetEvent(ctx, invitation.OwnerEmail, invitation.EventID)
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
+		if err != nil || event == nil {
+			continue
+		}
+		invitation.Event = event
+		results = append(results, invitation)
+	}
+
+	return results, nil
+}
+
+// GetEventTags counts how many of the user's own events carry each tag, most used first.
+// A recurring series counts once, however many occurrences it has.
+func (es *EventService) GetEventTags(ctx context.Context, userEmail string) ([]models.TagCount, error) {
+	page, err := es.EventRepo.GetAllEvents(ctx, userEmail, models.EventQuery{})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch event tags: %w", err)
+	}
+	return countTags(page.Items), nil
+}
+
+// areFriends reports whether two users have an accepted friendship in either direction.
+func (es *EventService) areFriends(ctx context.Context, userEmail, otherEmail string) (bool, error) {
+	for _, pair := range [][2]string{{userEmail, otherEmail}, {otherEmail, userEmail}} {
+		friendship, err := es.FriendRepo.GetFriendRequest(ctx, pair[0], pair[1])
+		if isRepositoryFailure(err) {
+			return false, err
+		}
+		if err == nil && friendship != nil && friendship.Status == "accepted" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseEventStart combines an event's date (YYYY-MM-DD) and optional start time (HH:MM)
+// into a single timestamp in loc. Events without a start time begin at midnight.
+func parseEventStart(date, startTime string, loc *time.Location) (time.Time, error) {
+	if _, err := dates.Parse("date", date); err != nil {
+		return time.Time{}, err
+	}
+	startAt, ok := localTime(date, startTime, loc)
+	if !ok {
+		return time.Time{}, fmt.Errorf("Invalid start time format. Please use HH:MM.")
+	}
+	return startAt, nil
 }
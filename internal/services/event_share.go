@@ -0,0 +1,161 @@
+/**
+ *  Shared event links let the owner of an event show it to people without an account, such as the
+ *  guests of a party. Anyone with the link can read a subset of the event, but not change it.
+ *
+ *  @file       event_share.go
+ *  @package    services
+ *
+ *  @methods
+ *  - ShareEvent(ctx, userEmail, eventID)        - Creates a link to one of the user's events, replacing earlier links.
+ *  - RevokeEventShare(ctx, userEmail, eventID)  - Deletes the links to one of the user's events.
+ *  - GetSharedEvent(ctx, token)                 - Returns the read-only view of the event a link points to.
+ *  - newShareToken()                            - Generates an unguessable token.
+ *
+ *  @behaviors
+ *  - Tokens are 32 random bytes, hex encoded. An event has at most one working link: sharing it
+ *    again replaces the link, so the old one stops working.
+ *  - Links expire EventShareLifetime after they are created. Deleting the event makes its links
+ *    unknown; cancelling it keeps them working, so guests see that the event was cancelled.
+ *  - The shared view is a models.SharedEvent with the times in the owner's time zone. It never
+ *    contains the owner's email, invitees, reminders or other account data.
+ *  - The URL points to the shared event page of the web app at AppURL, which reads the event from
+ *    GET /api/shared/events/{token}.
+ *
+ *  @errors
+ *  - ErrEventSharingDisabled: No ShareRepo is configured.
+ *  - ErrEventShareNotFound: No link has the token, or its event was deleted.
+ *  - ErrEventShareExpired: The link has expired.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"proh2052-group6/pkg/models"
+)
+
+// EventShareLifetime is how long a shared event link works.
+const EventShareLifetime = 30 * 24 * time.Hour
+
+var (
+	// ErrEventSharingDisabled is returned when events are shared without a ShareRepo.
+	ErrEventSharingDisabled = errors.New("Event sharing is not available")
+	// ErrEventShareNotFound is returned for an unknown link or a link to a deleted event.
+	ErrEventShareNotFound = errors.New("Shared event not found")
+	// ErrEventShareExpired is returned for a link that has expired.
+	ErrEventShareExpired = errors.New("This link has expired")
+)
+
+// ShareEvent creates a read-only link to the user's event eventID. Earlier links to the event stop working.
+func (es *EventService) ShareEvent(ctx context.Context, userEmail, eventID string) (*models.EventShareLink, error) {
+	if es.ShareRepo == nil {
+		return nil, ErrEventSharingDisabled
+	}
+	event, err := es.ownedEvent(ctx, userEmail, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if isCancelled(*event) {
+		return nil, ErrEventCancelled
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := es.ShareRepo.DeleteEventShares(ctx, userEmail, eventID); err != nil {
+		return nil, err
+	}
+	now := es.Now()
+	share := &models.EventShare{
+		Email:     userEmail,
+		EventID:   eventID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(EventShareLifetime),
+	}
+	if err := es.ShareRepo.CreateEventShare(ctx, token, share); err != nil {
+		return nil, err
+	}
+	return &models.EventShareLink{
+		Token:     token,
+		URL:       es.AppURL + "/shared/events/" + token,
+		ExpiresAt: share.ExpiresAt,
+	}, nil
+}
+
+// RevokeEventShare deletes the links to the user's event eventID, so they stop working.
+func (es *EventService) RevokeEventShare(ctx context.Context, userEmail, eventID string) error {
+	if es.ShareRepo == nil {
+		return ErrEventSharingDisabled
+	}
+	if _, err := es.ownedEvent(ctx, userEmail, eventID); err != nil {
+		return err
+	}
+	return es.ShareRepo.DeleteEventShares(ctx, userEmail, eventID)
+}
+
+// GetSharedEvent returns the read-only view of the event the link with token points to.
+func (es *EventService) GetSharedEvent(ctx context.Context, token string) (*models.SharedEvent, error) {
+	if es.ShareRepo == nil {
+		return nil, ErrEventSharingDisabled
+	}
+	share, err := es.ShareRepo.GetEventShare(ctx, token)
+	if isRepositoryFailure(err) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, ErrEventShareNotFound
+	}
+	if !es.Now().Before(share.ExpiresAt) {
+		return nil, ErrEventShareExpired
+	}
+
+	event, err := es.EventRepo.GetEvent(ctx, share.Email, share.EventID)
+	if isRepositoryFailure(err) {
+		return nil, err
+	}
+	if err != nil || event == nil || event.Email != share.Email {
+		return nil, ErrEventShareNotFound
+	}
+	loc, err := es.userLocation(ctx, share.Email)
+	if err != nil {
+		return nil, err
+	}
+	renderEventTimes(event, loc)
+
+	return &models.SharedEvent{
+		Title:         event.Title,
+		Description:   event.Description,
+		Date:          event.Date,
+		StartTime:     event.StartTime,
+		EndTime:       event.EndTime,
+		StartAt:       event.StartAt,
+		EndAt:         event.EndAt,
+		TimeZone:      event.TimeZone,
+		AllDay:        event.AllDay,
+		StreetAddress: event.StreetAddress,
+		PostalNumber:  event.PostalNumber,
+		Status:        event.Status,
+	}, nil
+}
+
+// newShareToken generates a random hex token of 32 bytes.
+func newShareToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("Failed to generate share token: %v", err)
+	}
+	return hex.EncodeToString(token), nil
+}
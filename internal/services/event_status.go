@@ -0,0 +1,170 @@
+/**
+ *  Event statuses. An event is tentative or confirmed while it is planned, and cancelled events are
+ *  kept for history instead of being deleted.
+ *
+ *  @file       event_status.go
+ *  @package    services
+ *
+ *  @methods
+ *  - CancelEvent(ctx, userEmail, eventID)  - Cancels an event and notifies the invitees who accepted it.
+ *  - normalizeEventStatus(event, current)  - Validates the status of a new or updated event.
+ *  - isCancelled(event)                    - Reports whether an event is cancelled.
+ *  - filterCancelled(events, include)      - Leaves cancelled events out of a listing unless they are asked for.
+ *
+ *  @behaviors
+ *  - New events are confirmed unless they are created as tentative. An update without a status keeps
+ *    the stored one; events stored before statuses were validated are treated as confirmed.
+ *  - Events are only cancelled by CancelEvent, which records CancelledAt. A cancelled event is final:
+ *    it can be deleted, but not updated, cancelled again or have its occurrences changed.
+ *  - Cancelling a recurring event also cancels the occurrences that were changed individually.
+ *  - Invitees who accepted a cancelled event get a notification; pending and declined invitees do not.
+ *    A failure to notify is logged and does not undo the cancellation.
+ *  - Cancelled events are left out of event listings, reminders and digests unless they are asked for.
+ *
+ *  @errors
+ *  - ErrInvalidEventStatus: A status other than tentative or confirmed was given to create or update an event.
+ *  - ErrEventCancelled: The event is cancelled and cannot be changed.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"proh2052-group6/pkg/models"
+)
+
+// Statuses of an event.
+const (
+	EventStatusTentative = "tentative"
+	EventStatusConfirmed = "confirmed"
+	EventStatusCancelled = "cancelled"
+)
+
+var (
+	// ErrInvalidEventStatus is returned for a status other than tentative or confirmed on create or update.
+	ErrInvalidEventStatus = errors.New("Status must be 'tentative' or 'confirmed'")
+	// ErrEventCancelled is returned for changes to a cancelled event.
+	ErrEventCancelled = errors.New("Cancelled events cannot be changed")
+)
+
+// normalizeEventStatus lowercases and validates the status of a new or updated event. An empty status
+// keeps current, the status of the stored event, or is confirmed for new events and for stored events
+// without a valid status.
+func normalizeEventStatus(event *models.Event, current string) error {
+	status := strings.ToLower(strings.TrimSpace(event.Status))
+	if status == "" && (current == EventStatusTentative || current == EventStatusConfirmed) {
+		status = current
+	}
+	if status == "" {
+		status = EventStatusConfirmed
+	}
+	if status != EventStatusTentative && status != EventStatusConfirmed {
+		return ErrInvalidEventStatus
+	}
+
+	event.Status = status
+	event.CancelledAt = nil
+	return nil
+}
+
+// isCancelled reports whether an event is cancelled.
+func isCancelled(event models.Event) bool {
+	return event.Status == EventStatusCancelled
+}
+
+// filterCancelled returns events without the cancelled ones, or events unchanged if include is set.
+func filterCancelled(events []models.Event, include bool) []models.Event {
+	if include {
+		return events
+	}
+	kept := events[:0]
+	for _, event := range events {
+		if !isCancelled(event) {
+			kept = append(kept, event)
+		}
+	}
+	return kept
+}
+
+// CancelEvent marks one of the user's events as cancelled, keeping it for history, and notifies the
+// invitees who accepted it. Cancelling a recurring event cancels the entire series.
+func (es *EventService) CancelEvent(ctx context.Context, userEmail, eventID string) error {
+	event, err := es.EventRepo.GetEvent(ctx, userEmail, eventID)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err != nil || event == nil || event.Email != userEmail {
+		return fmt.Errorf("Event not found")
+	}
+	if isCancelled(*event) {
+		return ErrEventCancelled
+	}
+
+	if err := es.cancel(ctx, event); err != nil {
+		return err
+	}
+	if event.Recurrence == nil {
+		return nil
+	}
+
+	page, err := es.EventRepo.GetAllEvents(ctx, userEmail, models.EventQuery{})
+	if err != nil {
+		return fmt.Errorf("Failed to cancel changed occurrences of the series: %w", err)
+	}
+	for i := range page.Items {
+		if page.Items[i].SeriesID != eventID || isCancelled(page.Items[i]) {
+			continue
+		}
+		if err := es.cancel(ctx, &page.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancel stores an event as cancelled now and notifies its accepted invitees.
+func (es *EventService) cancel(ctx context.Context, event *models.Event) error {
+	cancelledAt := es.Now()
+	event.Status = EventStatusCancelled
+	event.CancelledAt = &cancelledAt
+	if err := es.EventRepo.UpdateEvent(ctx, event); err != nil {
+		return fmt.Errorf("Failed to cancel event: %w", err)
+	}
+
+	invitations, err := es.InvitationRepo.GetInvitationsForEvent(ctx, event.Email, event.EventID)
+	if err != nil {
+		log.Printf("Failed to notify invitees of cancelled event %s: %v", event.EventID, err)
+		return nil
+	}
+	owner := es.displayName(ctx, event.Email)
+	for _, invitation := range invitations {
+		if invitation.Status != "accepted" {
+			continue
+		}
+		sendNotification(ctx, es.Notifications, invitation.InviteeEmail, models.Notification{
+			Type:    NotificationEventCancelled,
+			Message: fmt.Sprintf("%s cancelled %s", owner, event.Title),
+			Payload: map[string]string{"eventID": event.EventID, "username": owner},
+		})
+	}
+	return nil
+}
+
+// displayName returns the username of a user to show in notifications, or their email if they have none.
+func (es *EventService) displayName(ctx context.Context, userEmail string) string {
+	if user, err := es.UserRepo.GetUserByEmail(ctx, userEmail); err == nil && user != nil && user.Username != "" {
+		return user.Username
+	}
+	return userEmail
+}
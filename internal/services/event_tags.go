@@ -0,0 +1,111 @@
+/**
+ *  Event tag helpers validate the free-form tags users attach to events (e.g. "work", "gym")
+ *  and count how often each tag is used, so clients can render a list of tag filters.
+ *  Journal entries use the same tag rules.
+ *
+ *  @file       event_tags.go
+ *  @package    services
+ *
+ *  @methods
+ *  - normalizeTags(event)          - Trims, lowercases and validates an event's tags.
+ *  - normalizeTagList(tags, tooMany) - Trims, lowercases and validates a list of tags.
+ *  - hasTag(event, tag)            - Reports whether an event carries a tag.
+ *  - countTags(events)             - Counts the events carrying each tag, most used first.
+ *
+ *  @behaviors
+ *  - Tags are stored lowercase, so "Work" and "work" are the same tag.
+ *  - An event has at most maxTagsPerEvent tags of 1 to maxTagLength characters, without duplicates.
+ *  - An empty tag filter matches every event.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"proh2052-group6/pkg/models"
+)
+
+const (
+	maxTagsPerEvent = 10 // Maximum number of tags on a single event.
+	maxTagLength    = 30 // Maximum length of a tag in characters.
+)
+
+// normalizeTags trims and lowercases the tags of an event and validates their number, length and uniqueness.
+func normalizeTags(event *models.Event) error {
+	tags, err := normalizeTagList(event.Tags, "An event can have at most 10 tags")
+	if err != nil {
+		return err
+	}
+	event.Tags = tags
+	return nil
+}
+
+// normalizeTagList trims and lowercases tags in place and validates their number, length and uniqueness.
+// tooMany is the error message used when there are more than maxTagsPerEvent tags. Returns nil for no tags.
+func normalizeTagList(tags []string, tooMany string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	if len(tags) > maxTagsPerEvent {
+		return nil, fmt.Errorf("%s", tooMany)
+	}
+
+	seen := make(map[string]bool, len(tags))
+	for i, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("Tags must be between 1 and 30 characters")
+		}
+		if seen[tag] {
+			return nil, fmt.Errorf("Duplicate tags are not allowed")
+		}
+		seen[tag] = true
+		tags[i] = tag
+	}
+	return tags, nil
+}
+
+// hasTag reports whether event carries tag. An empty tag matches every event.
+func hasTag(event models.Event, tag string) bool {
+	if tag == "" {
+		return true
+	}
+	for _, t := range event.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// countTags counts the events carrying each tag, ordered by count (highest first) and then by tag.
+func countTags(events []models.Event) []models.TagCount {
+	counts := make(map[string]int)
+	for _, event := range events {
+		for _, tag := range event.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]models.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, models.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags
+}
@@ -0,0 +1,199 @@
+/**
+ *  Event time zones. Events are stored with full StartAt and EndAt timestamps and the IANA time zone
+ *  their Date, StartTime and EndTime strings are written in, next to the strings themselves, which
+ *  older clients still read and write.
+ *
+ *  @file       event_timezone.go
+ *  @package    services
+ *
+ *  @methods
+ *  - userLocation(ctx, userEmail)   - Returns the time zone events of the user are entered and shown in.
+ *  - fillEventClock(event, loc)     - Derives Date, StartTime and EndTime from StartAt and EndAt if no date is given.
+ *  - setEventTimestamps(event, loc) - Sets StartAt, EndAt and TimeZone from the strings, read in loc.
+ *  - renderEventTimes(event, loc)   - Rewrites the strings of a stored event in the viewer's time zone.
+ *
+ *  @behaviors
+ *  - A user's time zone is their profile setting, or else the time zone of their country. Users with
+ *    neither use the server's zone, and their events are stored without a TimeZone, as before.
+ *  - Incoming events may give either Date and StartTime or the StartAt (and EndAt) timestamps; the
+ *    strings win if both are given, and are read in the user's time zone.
+ *  - A start or end time skipped by a daylight saving time change is rejected with
+ *    ErrNonexistentLocalTime. A time that occurs twice, when the clocks go back, is the first one.
+ *  - Events stored before time zones were recorded (an empty TimeZone) and all-day events are shown
+ *    as they were entered. Other events are shown in the zone of the user viewing them; an end time
+ *    before the start time then means the event ends the next day.
+ *  - Occurrences of a recurring event keep their local time across daylight saving time changes in
+ *    the event's zone; their dates, exceptions and until date are in that zone too.
+ *
+ *  @errors
+ *  - ErrNonexistentLocalTime: The start or end time does not exist on the event's date in the user's zone.
+ *  - ErrEventSpansDays: StartAt and EndAt fall on different days in the user's zone.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"proh2052-group6/pkg/models"
+)
+
+var (
+	// ErrNonexistentLocalTime is returned for an event time skipped by a daylight saving time change.
+	ErrNonexistentLocalTime = errors.New("The time does not exist because of a daylight saving time change")
+	// ErrEventSpansDays is returned for events given as timestamps that end on a later day than they start.
+	ErrEventSpansDays = errors.New("endAt must be on the same day as startAt")
+)
+
+// userLocation returns the time zone the user enters and views events in. Users without a time zone
+// or a known country, and users that cannot be found, get the server's zone.
+func (es *EventService) userLocation(ctx context.Context, userEmail string) (*time.Location, error) {
+	if es.UserRepo == nil {
+		return time.Local, nil
+	}
+	user, err := es.UserRepo.GetUserByEmail(ctx, userEmail)
+	if isRepositoryFailure(err) {
+		return nil, err
+	}
+	if err != nil {
+		return time.Local, nil
+	}
+	if location, ok := LocationForUser(user); ok {
+		return location, nil
+	}
+	return time.Local, nil
+}
+
+// zoneName returns the name stored as the TimeZone of events in loc, which is empty for the server's zone.
+func zoneName(loc *time.Location) string {
+	if loc == time.Local {
+		return ""
+	}
+	return loc.String()
+}
+
+// eventLocation returns the time zone the strings of a stored event are in, which is the server's
+// zone for events stored without one.
+func eventLocation(event *models.Event) *time.Location {
+	if location, err := LoadTimeZone(event.TimeZone); err == nil {
+		return location
+	}
+	return time.Local
+}
+
+// fillEventClock sets Date, StartTime and EndTime of an event given only as timestamps to the
+// StartAt and EndAt times in loc. Events with a date are left as they are.
+func fillEventClock(event *models.Event, loc *time.Location) error {
+	if event.Date != "" || event.StartAt.IsZero() {
+		return nil
+	}
+
+	start := event.StartAt.In(loc)
+	event.Date = start.Format("2006-01-02")
+	event.StartTime = start.Format("15:04")
+	event.EndTime = ""
+	if event.EndAt != nil {
+		end := event.EndAt.In(loc)
+		if end.Format("2006-01-02") != event.Date {
+			return ErrEventSpansDays
+		}
+		event.EndTime = end.Format("15:04")
+	}
+	return nil
+}
+
+// setEventTimestamps sets StartAt and EndAt from the date and times of an event, read in loc, and
+// records loc as the event's TimeZone.
+func setEventTimestamps(event *models.Event, loc *time.Location) error {
+	startAt, err := parseEventStart(event.Date, event.StartTime, loc)
+	if err != nil {
+		return err
+	}
+	if !localTimeExists(startAt, event.StartTime, loc) {
+		return fmt.Errorf("%w: %s %s in %s", ErrNonexistentLocalTime, event.Date, event.StartTime, loc)
+	}
+
+	var endAt *time.Time
+	if event.EndTime != "" {
+		end, ok := localTime(event.Date, event.EndTime, loc)
+		if !ok {
+			return fmt.Errorf("Invalid end time format. Please use HH:MM.")
+		}
+		if !localTimeExists(end, event.EndTime, loc) {
+			return fmt.Errorf("%w: %s %s in %s", ErrNonexistentLocalTime, event.Date, event.EndTime, loc)
+		}
+		endAt = &end
+	}
+
+	event.StartAt = startAt
+	event.EndAt = endAt
+	event.TimeZone = zoneName(loc)
+	return nil
+}
+
+// localTime returns the time of day clock (HH:MM, or midnight if empty) on date (YYYY-MM-DD) in loc.
+// A time skipped by a daylight saving time change is moved forward by the length of the gap, and a
+// time that occurs twice is the first of the two.
+func localTime(date, clock string, loc *time.Location) (time.Time, bool) {
+	wall := date + " " + clock
+	if clock == "" {
+		wall += "00:00"
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04", wall, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// time.ParseInLocation may pick either instant of an ambiguous time; transitions move the
+	// clocks by an hour almost everywhere, and by half an hour in a few zones.
+	for _, shift := range []time.Duration{time.Hour, 30 * time.Minute} {
+		if earlier := t.Add(-shift); earlier.Format("2006-01-02 15:04") == wall {
+			return earlier, true
+		}
+	}
+	return t, true
+}
+
+// localTimeExists reports whether t, parsed from the time of day clock, shows that time in loc.
+// It is false for a time skipped by a daylight saving time change.
+func localTimeExists(t time.Time, clock string, loc *time.Location) bool {
+	return clock == "" || t.In(loc).Format("15:04") == clock
+}
+
+// renderEventTimes rewrites the date and times of a stored event in loc, the time zone of the user
+// viewing it, and sets its TimeZone to loc. Events stored without a time zone and all-day events
+// are left as they are.
+func renderEventTimes(event *models.Event, loc *time.Location) {
+	if event.TimeZone == "" || event.StartTime == "" || event.StartAt.IsZero() {
+		return
+	}
+
+	start := event.StartAt.In(loc)
+	event.Date = start.Format("2006-01-02")
+	event.StartTime = start.Format("15:04")
+	if event.EndAt != nil {
+		event.EndTime = event.EndAt.In(loc).Format("15:04")
+	}
+	event.TimeZone = zoneName(loc)
+}
+
+// renderEventsFor rewrites the date and times of the events in the time zone of the user viewing them.
+func (es *EventService) renderEventsFor(ctx context.Context, userEmail string, events []models.Event) error {
+	loc, err := es.userLocation(ctx, userEmail)
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		renderEventTimes(&events[i], loc)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+/**
+ *  ExportService assembles a copy of everything stored about a user, so users can download their
+ *  data (a "takeout"). It reads from the existing repositories and writes a ZIP archive.
+ *
+ *  @interface ExportServiceInterface
+ *  @struct   ExportService
+ *  @methods
+ *  - NewExportService(userRepo, eventRepo, journalRepo, friendRepo) - Initializes a new ExportService.
+ *  - ExportUserData(ctx, userEmail, w)                             - Writes a ZIP archive of a user's data to w.
+ *
+ *  @behaviors
+ *  - The archive contains profile.json, events.json, journals.json and friends.json.
+ *  - The profile leaves out the password hash, OTP hashes and other security fields.
+ *  - Events are read a page at a time and journals are streamed, and each entry is written to the
+ *    archive as soon as it is read, so large accounts are never held in memory.
+ *  - The user is looked up before anything is written, so an unknown user produces no output.
+ *  - Encrypted journal entries are exported decrypted, using JournalCipher.
+ *
+ *  @dependencies
+ *  - repositories.UserRepository: Provides the profile.
+ *  - repositories.EventRepository: Provides the user's events.
+ *  - repositories.JournalRepository: Streams the user's journal entries.
+ *  - repositories.FriendRepository: Provides friendships, pending requests and blocks.
+ *  - JournalCipher: Decrypts encrypted journal entries; may be nil if journals are stored in plaintext.
+ *  - archive/zip: Writes the archive.
+ *
+ *  @file      export_service.go
+ *  @project   DailyVerse
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// exportEventPageSize is the number of events read per page while exporting.
+const exportEventPageSize = 200
+
+// ExportServiceInterface defines the contract for exporting a user's data.
+type ExportServiceInterface interface {
+	// ExportUserData writes a ZIP archive of everything stored about a user to w.
+	ExportUserData(ctx context.Context, userEmail string, w io.Writer) error
+}
+
+// ExportService implements ExportServiceInterface.
+type ExportService struct {
+	UserRepo    repositories.UserRepository    // Repository for the profile.
+	EventRepo   repositories.EventRepository   // Repository for the user's events.
+	JournalRepo repositories.JournalRepository // Repository for the user's journal entries.
+	FriendRepo  repositories.FriendRepository  // Repository for friendships and blocks.
+
+	JournalCipher *JournalCipher // Decrypts encrypted journal entries; nil if none are encrypted.
+}
+
+// NewExportService initializes a new ExportService with the given repositories.
+func NewExportService(userRepo repositories.UserRepository, eventRepo repositories.EventRepository, journalRepo repositories.JournalRepository, friendRepo repositories.FriendRepository) ExportServiceInterface {
+	return &ExportService{
+		UserRepo:    userRepo,
+		EventRepo:   eventRepo,
+		JournalRepo: journalRepo,
+		FriendRepo:  friendRepo,
+	}
+}
+
+// exportedFriends is the content of friends.json.
+type exportedFriends struct {
+	Friends          []models.Friend `json:"friends"`
+	IncomingRequests []models.Friend `json:"incomingRequests"`
+	OutgoingRequests []models.Friend `json:"outgoingRequests"`
+	Blocked          []models.Block  `json:"blocked"`
+}
+
+// ExportUserData writes a ZIP archive with profile.json, events.json, journals.json and friends.json to w.
+func (es *ExportService) ExportUserData(ctx context.Context, userEmail string, w io.Writer) error {
+	user, err := es.UserRepo.GetUserByEmail(ctx, userEmail)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err != nil || user == nil {
+		return fmt.Errorf("User not found")
+	}
+
+	archive := zip.NewWriter(w)
+	// The password, OTPs and other security fields are tagged json:"-", so they are left out of the profile.
+	if err := es.writeJSONFile(archive, "profile.json", user); err != nil {
+		return err
+	}
+	if err := es.writeEvents(ctx, archive, userEmail); err != nil {
+		return err
+	}
+	if err := es.writeJournals(ctx, archive, userEmail); err != nil {
+		return err
+	}
+	friends, err := es.collectFriends(ctx, userEmail)
+	if err != nil {
+		return err
+	}
+	if err := es.writeJSONFile(archive, "friends.json", friends); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// writeJSONFile adds a file holding value as indented JSON to the archive.
+func (es *ExportService) writeJSONFile(archive *zip.Writer, name string, value interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// writeEvents adds events.json to the archive, reading the user's events a page at a time.
+func (es *ExportService) writeEvents(ctx context.Context, archive *zip.Writer, userEmail string) error {
+	file, err := archive.Create("events.json")
+	if err != nil {
+		return err
+	}
+	array := newJSONArrayWriter(file)
+	query := models.EventQuery{Limit: exportEventPageSize}
+	for {
+		page, err := es.EventRepo.GetAllEvents(ctx, userEmail, query)
+		if err != nil {
+			return fmt.Errorf("Failed to export events: %w", err)
+		}
+		for _, event := range page.Items {
+			if err := array.add(event); err != nil {
+				return err
+			}
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		query.PageToken = page.NextPageToken
+	}
+	return array.close()
+}
+
+// writeJournals adds journals.json to the archive, streaming the user's journal entries oldest first.
+func (es *ExportService) writeJournals(ctx context.Context, archive *zip.Writer, userEmail string) error {
+	file, err := archive.Create("journals.json")
+	if err != nil {
+		return err
+	}
+	array := newJSONArrayWriter(file)
+	err = es.JournalRepo.StreamJournals(ctx, userEmail, "", "", func(journal models.Journal) error {
+		if err := es.JournalCipher.Open(&journal); err != nil {
+			return err
+		}
+		return array.add(journal)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to export journals: %w", err)
+	}
+	return array.close()
+}
+
+// collectFriends gathers the user's friendships, pending requests in both directions and blocks.
+func (es *ExportService) collectFriends(ctx context.Context, userEmail string) (*exportedFriends, error) {
+	friends := &exportedFriends{}
+	var err error
+	if friends.Friends, err = es.FriendRepo.GetFriends(ctx, userEmail); err != nil {
+		return nil, fmt.Errorf("Failed to export friends: %w", err)
+	}
+	if friends.IncomingRequests, err = es.FriendRepo.GetPendingFriendRequests(ctx, userEmail); err != nil {
+		return nil, fmt.Errorf("Failed to export friends: %w", err)
+	}
+	if friends.OutgoingRequests, err = es.FriendRepo.GetSentFriendRequests(ctx, userEmail); err != nil {
+		return nil, fmt.Errorf("Failed to export friends: %w", err)
+	}
+	if friends.Blocked, err = es.FriendRepo.GetBlockedUsers(ctx, userEmail); err != nil {
+		return nil, fmt.Errorf("Failed to export friends: %w", err)
+	}
+
+	// Write empty lists as [] rather than null.
+	for _, list := range []*[]models.Friend{&friends.Friends, &friends.IncomingRequests, &friends.OutgoingRequests} {
+		if *list == nil {
+			*list = []models.Friend{}
+		}
+	}
+	if friends.Blocked == nil {
+		friends.Blocked = []models.Block{}
+	}
+	return friends, nil
+}
+
+// jsonArrayWriter writes a JSON array one element at a time.
+type jsonArrayWriter struct {
+	w     io.Writer
+	count int
+}
+
+// newJSONArrayWriter starts a JSON array on w.
+func newJSONArrayWriter(w io.Writer) *jsonArrayWriter {
+	return &jsonArrayWriter{w: w}
+}
+
+// add writes the next element of the array.
+func (aw *jsonArrayWriter) add(value interface{}) error {
+	element, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	separator := ",\n  "
+	if aw.count == 0 {
+		separator = "[\n  "
+	}
+	aw.count++
+	if _, err := io.WriteString(aw.w, separator); err != nil {
+		return err
+	}
+	_, err = aw.w.Write(element)
+	return err
+}
+
+// close ends the array.
+func (aw *jsonArrayWriter) close() error {
+	end := "\n]\n"
+	if aw.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(aw.w, end)
+	return err
+}
@@ -9,23 +9,36 @@
  *  - FriendServiceInterface: Defines the contract for friend-related operations.
  *
  *  @methods
- *  - NewFriendService(userRepo, friendRepo): Initializes a new FriendService instance.
- *  - SendFriendRequest(ctx, userEmail, username): Sends a friend request to another user.
+ *  - NewFriendService(userRepo, friendRepo, emailService, notificationService): Initializes a new FriendService instance.
+ *  - SendFriendRequest(ctx, userEmail, username): Sends a friend request, or accepts the one the other user already sent.
  *  - AcceptFriendRequest(ctx, userEmail, username): Accepts a received friend request.
- *  - GetFriendsList(ctx, userEmail): Retrieves the list of friends for a user.
+ *  - GetFriendsList(ctx, userEmail): Retrieves summaries of a user's friends and when each friendship began.
  *  - RemoveFriend(ctx, userEmail, username): Removes a friendship.
  *  - GetPendingFriendRequests(ctx, userEmail): Retrieves pending friend requests for a user.
  *  - DeclineFriendRequest(ctx, userEmail, username): Declines a received friend request.
  *  - CancelFriendRequest(ctx, userEmail, username): Cancels a sent friend request.
+ *  - BlockUser(ctx, userEmail, identifier): Blocks a user and removes any request or friendship with them.
+ *  - UnblockUser(ctx, userEmail, identifier): Removes a block.
+ *  - GetBlockedUsers(ctx, userEmail): Retrieves the users blocked by a user.
+ *  - ComputeSuggestions(ctx, userEmail, limit): Suggests friends of friends, ranked by mutual friends.
+ *  - GetMutualFriends(ctx, userEmail, identifier): Retrieves the friends a user has in common with another user.
+ *  - GetRelationshipStatuses(ctx, userEmail, emails): Reports the user's relationship with each of up to 50 users.
+ *  - InviteFriend(ctx, userEmail, email): Sends a friend request, or invites the address to sign up if it has no account.
+ *  - PurgeExpiredFriendRequests(ctx): Deletes expired pending requests and declined requests past their cooldown.
+ *  - StartExpirySweep(ctx) / RunExpirySweep(ctx, ticks): Purge stale friend requests periodically until the context is cancelled.
  *
  *  @dependencies
  *  - repositories.UserRepository: Manages user-related data.
  *  - repositories.FriendRepository: Manages friend-related data.
+ *  - EmailServiceInterface: Sends friend request notification emails.
+ *  - NotificationServiceInterface: Stores friend request notifications and pushes them to connected clients.
+ *  - repositories.FriendInvitationRepository: Stores invitations to addresses without an account; may be nil.
+ *  - NotificationGate: Holds notification emails back during quiet hours; may be nil.
  *  - utils.IsValidEmail: Utility function to validate email addresses.
  *
  *  @example
  *  ```
- *  friendService := NewFriendService(userRepo, friendRepo)
+ *  friendService := NewFriendService(userRepo, friendRepo, emailService, notificationService)
  *  err := friendService.SendFriendRequest(ctx, "user@example.com", "friend@example.com")
  *  if err != nil {
  *      log.Println("Failed to send friend request:", err)
@@ -35,11 +48,40 @@
  *  @behaviors
  *  - Validates input, ensuring users cannot send friend requests to themselves.
  *  - Prevents duplicate friend requests or relationships.
- *  - Supports friend operations by username or email.
+ *  - Sending a request to a user who already sent one accepts theirs instead of creating a second request.
+ *  - Cleans up legacy request pairs in both directions: a pending request is dropped once the
+ *    reverse request is accepted, and is hidden from the pending list if the users are already friends.
+ *  - Accepts only requests that are still pending, atomically, so a concurrent cancel or decline wins.
+ *  - Removes only accepted friendships, stored in either direction; removing someone who is not a
+ *    friend returns "Friend not found" instead of succeeding silently.
+ *  - Rejects friend requests between users when either has blocked the other.
+ *  - Pending requests expire after FriendRequestExpiry: they are no longer listed or accepted, can be
+ *    sent again, and are deleted by the expiry sweep. Requests without a CreatedAt never expire.
+ *  - Declining keeps the request as "declined" so its sender cannot send another one for
+ *    FriendRequestDeclineCooldown. Cancelling a declined request leaves it in place.
+ *  - Supports friend operations by username or email. Emails are matched however they are cased
+ *    (see utils.NormalizeEmail).
  *  - Fetches user summaries for pending requests, excluding sensitive information.
+ *  - Suggestions exclude existing friends, pending requests in either direction and blocked users.
+ *  - Relationship statuses are classified in memory from the user's friendships, requests and blocks,
+ *    loaded once per call however many emails are asked about. Only blocks made by the user are
+ *    reported, so a user cannot find out who blocked them; unknown emails are reported as "none".
+ *    Friends of friends are loaded with batched repository queries rather than one query per friend.
+ *  - Emails the recipient of a new friend request and the sender of an accepted one, rendered from
+ *    the email templates, unless they turned notifications off. Email failures are logged and never fail the operation.
+ *  - With a Gate, those emails are held back during the recipient's quiet hours and sent when they end.
+ *  - Also stores these notifications in the recipient's inbox and pushes them to their open WebSocket connections.
+ *  - InviteFriend behaves exactly like SendFriendRequest for a registered address. Otherwise it stores an
+ *    invitation, keyed by the normalized address, and emails a link to the signup page at AppURL. UserService
+ *    turns the invitation into a pending friend request when the address signs up.
  *
  *  @errors
  *  - Returns errors for invalid inputs, non-existent users, or database operation failures.
+ *  - ErrFriendRequestCooldown: The recipient declined a request from the sender less than a day ago.
+ *  - ErrInvalidEmail: InviteFriend or GetRelationshipStatuses was given something other than an email address.
+ *  - ErrTooManyStatusEmails: GetRelationshipStatuses was asked about more than MaxRelationshipStatusEmails users.
+ *  - ErrFriendInvitationExists: The user already invited the address and it has not signed up yet.
+ *  - Database failures are returned wrapped, so repositories.ErrUnavailable is never reported as a missing user.
  *
  *  @authors
  *      - Aayush
@@ -52,78 +94,195 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net/url"
 	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/i18n"
 	"proh2052-group6/pkg/models"
 	"proh2052-group6/pkg/utils"
+	"sort"
+	"time"
+)
+
+// Limits on the number of friend suggestions returned by ComputeSuggestions.
+const (
+	DefaultSuggestionLimit = 10
+	MaxSuggestionLimit     = 50
+)
+
+const (
+	// FriendRequestExpiry is how long a friend request stays pending before it expires.
+	FriendRequestExpiry = 30 * 24 * time.Hour
+	// FriendRequestDeclineCooldown is how long a sender must wait after a decline to send another request.
+	FriendRequestDeclineCooldown = 24 * time.Hour
+)
+
+// ErrFriendRequestCooldown is returned when the recipient recently declined a request from the sender.
+var ErrFriendRequestCooldown = errors.New("Request recently declined, try again later")
+
+// MaxRelationshipStatusEmails is the most users GetRelationshipStatuses reports on in one call.
+const MaxRelationshipStatusEmails = 50
+
+// ErrTooManyStatusEmails is returned when more than MaxRelationshipStatusEmails statuses are asked for at once.
+var ErrTooManyStatusEmails = fmt.Errorf("At most %d emails can be looked up at once", MaxRelationshipStatusEmails)
+
+// ErrFriendInvitationExists is returned when the user already has a pending invitation to the address.
+var ErrFriendInvitationExists = errors.New("You have already invited this email address")
+
+// Outcomes of InviteFriend.
+const (
+	InviteRequestSent     = "request_sent"     // The address is registered and was sent a friend request.
+	InviteRequestAccepted = "request_accepted" // The address is registered and had already sent a request, which was accepted.
+	InviteInvitationSent  = "invitation_sent"  // The address has no account and was emailed an invitation to sign up.
 )
 
 // FriendServiceInterface defines methods for friend-related operations.
 type FriendServiceInterface interface {
-	SendFriendRequest(ctx context.Context, userEmail, username string) error
+	SendFriendRequest(ctx context.Context, userEmail, username string) (accepted bool, err error)
 	AcceptFriendRequest(ctx context.Context, userEmail, username string) error
-	GetFriendsList(ctx context.Context, userEmail string) ([]models.User, error)
+	GetFriendsList(ctx context.Context, userEmail string) ([]models.UserSummary, error)
 	RemoveFriend(ctx context.Context, userEmail, username string) error
 	GetPendingFriendRequests(ctx context.Context, userEmail string) ([]models.UserSummary, error)
 	DeclineFriendRequest(ctx context.Context, userEmail, username string) error
 	CancelFriendRequest(ctx context.Context, userEmail, username string) error
+	BlockUser(ctx context.Context, userEmail, identifier string) error
+	UnblockUser(ctx context.Context, userEmail, identifier string) error
+	GetBlockedUsers(ctx context.Context, userEmail string) ([]models.UserSummary, error)
+	ComputeSuggestions(ctx context.Context, userEmail string, limit int) ([]models.UserSummary, error)
+	GetMutualFriends(ctx context.Context, userEmail, identifier string) ([]models.UserSummary, error)
+	InviteFriend(ctx context.Context, userEmail, email string) (outcome string, err error)
+	GetRelationshipStatuses(ctx context.Context, userEmail string, emails []string) (map[string]string, error)
 }
 
 // FriendService implements FriendServiceInterface.
 type FriendService struct {
-	UserRepo   repositories.UserRepository   // Repository for user data.
-	FriendRepo repositories.FriendRepository // Repository for friend data.
+	UserRepo       repositories.UserRepository             // Repository for user data.
+	FriendRepo     repositories.FriendRepository           // Repository for friend data.
+	Email          EmailServiceInterface                   // Email service for friend request notifications.
+	Notifications  NotificationServiceInterface            // Inbox and push notifications; may be nil.
+	Templates      *EmailTemplateRenderer                  // Renders the notification emails.
+	InvitationRepo repositories.FriendInvitationRepository // Invitations to addresses without an account; nil disables them.
+	AppURL         string                                  // Origin of the web app, for the signup link in invitations.
+	Gate           *NotificationGate                       // Holds notification emails back during quiet hours; may be nil.
+	SweepInterval  time.Duration                           // How often the expiry sweep purges stale requests.
+	Now            func() time.Time                        // Clock used for expiry and cooldowns; replaceable in tests.
 }
 
 // NewFriendService initializes a new FriendService.
-func NewFriendService(userRepo repositories.UserRepository, friendRepo repositories.FriendRepository) FriendServiceInterface {
+func NewFriendService(userRepo repositories.UserRepository, friendRepo repositories.FriendRepository, emailService EmailServiceInterface, notificationService NotificationServiceInterface) FriendServiceInterface {
 	return &FriendService{
-		UserRepo:   userRepo,
-		FriendRepo: friendRepo,
+		UserRepo:      userRepo,
+		FriendRepo:    friendRepo,
+		Email:         emailService,
+		Notifications: notificationService,
+		Templates:     NewEmailTemplateRenderer(),
+		SweepInterval: time.Hour,
+		Now:           time.Now,
 	}
 }
 
-// SendFriendRequest sends a friend request to another user.
-func (fs *FriendService) SendFriendRequest(ctx context.Context, userEmail, identifier string) error {
+// isExpired reports whether request is a pending request older than FriendRequestExpiry.
+func (fs *FriendService) isExpired(request *models.Friend) bool {
+	return request.Status == "pending" && !request.CreatedAt.IsZero() && fs.Now().Sub(request.CreatedAt) > FriendRequestExpiry
+}
+
+// SendFriendRequest sends a friend request to another user. If that user already sent a pending
+// request to userEmail, it is accepted instead and accepted is true.
+func (fs *FriendService) SendFriendRequest(ctx context.Context, userEmail, identifier string) (bool, error) {
 	var friendUser *models.User
 	var err error
 
 	// Determine if identifier is an email.
-	if utils.IsValidEmail(identifier) {
-		friendUser, err = fs.UserRepo.GetUserByEmail(ctx, identifier)
+	if email := utils.NormalizeEmail(identifier); utils.IsValidEmail(email) {
+		friendUser, err = fs.UserRepo.GetUserByEmail(ctx, email)
 	} else {
 		friendUser, err = fs.UserRepo.GetUserByUsername(ctx, identifier)
 	}
 
+	if isRepositoryFailure(err) {
+		return false, err
+	}
 	if err != nil || friendUser == nil {
-		return fmt.Errorf("User not found")
+		return false, fmt.Errorf("User not found")
 	}
 
 	friendEmail := friendUser.Email
 
 	// Prevent sending a friend request to self.
 	if userEmail == friendEmail {
-		return fmt.Errorf("You cannot send a friend request to yourself")
+		return false, fmt.Errorf("You cannot send a friend request to yourself")
+	}
+
+	// Reject requests between users where either has blocked the other.
+	blocked, err := isBlockedEitherWay(ctx, fs.FriendRepo, userEmail, friendEmail)
+	if err != nil {
+		return false, fmt.Errorf("Failed to send friend request: %w", err)
+	}
+	if blocked {
+		return false, fmt.Errorf("You cannot send a friend request to this user")
 	}
 
 	// Check for existing friend requests or relationships.
 	existingRequest, err := fs.FriendRepo.GetFriendRequest(ctx, userEmail, friendEmail)
-	if err == nil && existingRequest != nil {
-		return fmt.Errorf("Friend request already exists or you are already friends")
+	if isRepositoryFailure(err) {
+		return false, err
+	}
+	if err != nil {
+		existingRequest = nil // Not found.
+	}
+	if existingRequest != nil && existingRequest.Status != "declined" && !fs.isExpired(existingRequest) {
+		return false, fmt.Errorf("Friend request already exists or you are already friends")
 	}
 
-	// Create a new friend request with "pending" status.
+	// If the other user already sent a request, accept it rather than creating a crossing request.
+	reverseRequest, err := fs.FriendRepo.GetFriendRequest(ctx, friendEmail, userEmail)
+	if isRepositoryFailure(err) {
+		return false, err
+	}
+	if err == nil && reverseRequest != nil && reverseRequest.Status != "declined" && !fs.isExpired(reverseRequest) {
+		if reverseRequest.Status != "pending" {
+			return false, fmt.Errorf("Friend request already exists or you are already friends")
+		}
+		err = fs.FriendRepo.AcceptFriendRequest(ctx, friendEmail, userEmail)
+		if err == nil {
+			fs.notifyAccepted(ctx, friendUser, userEmail)
+			return true, nil
+		}
+		// The request was cancelled or declined in the meantime, so a new one is sent below.
+		if !errors.Is(err, repositories.ErrFriendRequestNotPending) {
+			return false, fmt.Errorf("Failed to send friend request: %w", err)
+		}
+	}
+
+	// A declined request blocks new ones until the cooldown has passed; after that it is replaced.
+	if existingRequest != nil && existingRequest.Status == "declined" && existingRequest.DeclinedAt != nil &&
+		fs.Now().Sub(*existingRequest.DeclinedAt) < FriendRequestDeclineCooldown {
+		return false, ErrFriendRequestCooldown
+	}
+
+	// Create a new friend request with "pending" status, replacing an expired or declined one.
 	friendRequest := &models.Friend{
 		Email:       userEmail,
 		FriendEmail: friendEmail,
 		Status:      "pending",
+		CreatedAt:   fs.Now(),
 	}
 	err = fs.FriendRepo.CreateFriendRequest(ctx, friendRequest)
 	if err != nil {
-		return fmt.Errorf("Failed to send friend request")
+		return false, fmt.Errorf("Failed to send friend request: %w", err)
 	}
 
-	return nil
+	requester := fs.displayName(ctx, userEmail)
+	fs.notify(ctx, friendUser, func(locale string) (EmailMessage, error) { return fs.Templates.FriendRequest(locale, requester) })
+	sendNotification(ctx, fs.Notifications, friendEmail, models.Notification{
+		Type:    NotificationFriendRequest,
+		Message: fmt.Sprintf("%s sent you a friend request", requester),
+		Payload: map[string]string{"username": requester},
+	})
+
+	return false, nil
 }
 
 // AcceptFriendRequest accepts a pending friend request.
@@ -133,40 +292,60 @@ func (fs *FriendService) AcceptFriendRequest(ctx context.Context, userEmail, ide
 
 	// Get the sender user by username or email.
 	senderUser, err = fs.UserRepo.GetUserByUsername(ctx, identifier)
+	if isRepositoryFailure(err) {
+		return err
+	}
 	if err != nil || senderUser == nil {
 		senderUser, err = fs.UserRepo.GetUserByEmail(ctx, identifier)
+		if isRepositoryFailure(err) {
+			return err
+		}
 		if err != nil || senderUser == nil {
 			return fmt.Errorf("User not found")
 		}
 	}
 	senderEmail := senderUser.Email
 
-	// Find the friend request sent by senderEmail to userEmail.
-	existingRequest, err := fs.FriendRepo.GetFriendRequest(ctx, senderEmail, userEmail)
-	if err != nil || existingRequest == nil {
+	request, err := fs.FriendRepo.GetFriendRequest(ctx, senderEmail, userEmail)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err == nil && request != nil && fs.isExpired(request) {
 		return fmt.Errorf("Friend request not found")
 	}
 
-	// Update the status of the request to "accepted".
-	updates := map[string]interface{}{
-		"Status": "accepted",
+	// Accept the request sent by senderEmail to userEmail. The repository re-reads it in a transaction,
+	// so a request cancelled or declined in the meantime is not turned into a friendship.
+	err = fs.FriendRepo.AcceptFriendRequest(ctx, senderEmail, userEmail)
+	if errors.Is(err, repositories.ErrFriendRequestNotPending) {
+		return fmt.Errorf("Friend request not found")
 	}
-	err = fs.FriendRepo.UpdateFriendRequest(ctx, senderEmail, userEmail, updates)
 	if err != nil {
-		return fmt.Errorf("Failed to accept friend request")
+		return fmt.Errorf("Failed to accept friend request: %w", err)
 	}
 
+	// Drop a legacy request in the other direction so it does not stay pending forever.
+	reverseRequest, err := fs.FriendRepo.GetFriendRequest(ctx, userEmail, senderEmail)
+	if err == nil && reverseRequest != nil && reverseRequest.Status == "pending" {
+		if err := fs.FriendRepo.DeleteFriendRequest(ctx, userEmail, senderEmail); err != nil {
+			log.Printf("Failed to remove duplicate friend request from %s to %s: %v", userEmail, senderEmail, err)
+		}
+	}
+
+	fs.notifyAccepted(ctx, senderUser, userEmail)
+
 	return nil
 }
 
-// GetFriendsList retrieves the list of friends for a user.
-func (fs *FriendService) GetFriendsList(ctx context.Context, userEmail string) ([]models.User, error) {
-	var friends []models.User
+// GetFriendsList retrieves summaries of a user's friends. Only public profile fields are returned,
+// along with the date the friendship began.
+func (fs *FriendService) GetFriendsList(ctx context.Context, userEmail string) ([]models.UserSummary, error) {
+	friends := []models.UserSummary{}
 
 	// Fetch all accepted friend relationships.
 	friendRelations, err := fs.FriendRepo.GetFriends(ctx, userEmail)
 	if err != nil {
-		return nil, fmt.Errorf("Error fetching friends list")
+		return nil, fmt.Errorf("Error fetching friends list: %w", err)
 	}
 
 	for _, friendRelation := range friendRelations {
@@ -179,31 +358,64 @@ func (fs *FriendService) GetFriendsList(ctx context.Context, userEmail string) (
 
 		// Fetch user details of the friend.
 		friendUser, err := fs.UserRepo.GetUserByEmail(ctx, friendEmail)
-		if err != nil {
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
+		if err != nil || friendUser == nil {
 			continue
 		}
 
-		friends = append(friends, *friendUser)
+		summary := models.UserSummary{
+			Username: friendUser.Username,
+			Email:    friendUser.Email,
+			Country:  friendUser.Country,
+			City:     friendUser.City,
+			ImageURL: friendUser.ImageURL,
+		}
+		if !friendRelation.CreatedAt.IsZero() {
+			friendsSince := friendRelation.CreatedAt
+			summary.FriendsSince = &friendsSince
+		}
+		friends = append(friends, summary)
 	}
 
 	return friends, nil
 }
 
-// RemoveFriend removes a friendship.
+// RemoveFriend removes a friendship, whichever of the two users sent the original request.
+// It returns "Friend not found" if the users are not friends; pending requests are cancelled
+// or declined instead.
 func (fs *FriendService) RemoveFriend(ctx context.Context, userEmail, username string) error {
 	// Retrieve the friend's email.
 	friendUser, err := fs.UserRepo.GetUserByUsername(ctx, username)
-	if err != nil {
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err != nil || friendUser == nil {
 		return fmt.Errorf("User not found")
 	}
 	friendEmail := friendUser.Email
 
-	// Remove the friendship in both directions.
-	err1 := fs.FriendRepo.DeleteFriendRequest(ctx, userEmail, friendEmail)
-	err2 := fs.FriendRepo.DeleteFriendRequest(ctx, friendEmail, userEmail)
+	friends := false
+	for _, pair := range [][2]string{{userEmail, friendEmail}, {friendEmail, userEmail}} {
+		friendship, err := fs.FriendRepo.GetFriendRequest(ctx, pair[0], pair[1])
+		if isRepositoryFailure(err) {
+			return err
+		}
+		if err == nil && friendship != nil && friendship.Status == "accepted" {
+			friends = true
+		}
+	}
+	if !friends {
+		return fmt.Errorf("Friend not found")
+	}
 
-	if err1 != nil && err2 != nil {
-		return fmt.Errorf("Failed to remove friend")
+	// Remove the friendship in both directions, which also clears legacy duplicate pairs.
+	// Deleting a direction that does not exist succeeds, so any error is a real failure.
+	for _, pair := range [][2]string{{userEmail, friendEmail}, {friendEmail, userEmail}} {
+		if err := fs.FriendRepo.DeleteFriendRequest(ctx, pair[0], pair[1]); err != nil {
+			return fmt.Errorf("Failed to remove friend: %w", err)
+		}
 	}
 
 	return nil
@@ -219,9 +431,27 @@ func (fs *FriendService) GetPendingFriendRequests(ctx context.Context, userEmail
 	var pendingRequests []models.UserSummary
 	for _, fr := range friendRequests {
 		senderEmail := fr.Email
+		if fs.isExpired(&fr) {
+			continue
+		}
+
+		// A legacy request from a user who is already a friend is stale; remove it instead of listing it.
+		reverseRequest, err := fs.FriendRepo.GetFriendRequest(ctx, userEmail, senderEmail)
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
+		if err == nil && reverseRequest != nil && reverseRequest.Status == "accepted" {
+			if err := fs.FriendRepo.DeleteFriendRequest(ctx, senderEmail, userEmail); err != nil {
+				log.Printf("Failed to remove stale friend request from %s to %s: %v", senderEmail, userEmail, err)
+			}
+			continue
+		}
 
 		// Fetch user details of the sender.
 		user, err := fs.UserRepo.GetUserByEmail(ctx, senderEmail)
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
 		if err != nil {
 			continue
 		}
@@ -232,6 +462,7 @@ func (fs *FriendService) GetPendingFriendRequests(ctx context.Context, userEmail
 			Email:    user.Email,
 			Country:  user.Country,
 			City:     user.City,
+			ImageURL: user.ImageURL,
 		}
 
 		pendingRequests = append(pendingRequests, userSummary)
@@ -240,18 +471,32 @@ func (fs *FriendService) GetPendingFriendRequests(ctx context.Context, userEmail
 	return pendingRequests, nil
 }
 
-// DeclineFriendRequest declines a received friend request.
+// DeclineFriendRequest declines a received friend request. The request is kept as "declined"
+// so that its sender cannot send another one until the cooldown has passed.
 func (fs *FriendService) DeclineFriendRequest(ctx context.Context, userEmail, username string) error {
 	senderUser, err := fs.UserRepo.GetUserByUsername(ctx, username)
+	if isRepositoryFailure(err) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("User not found")
 	}
 	senderEmail := senderUser.Email
 
-	// Delete the friend request.
-	err = fs.FriendRepo.DeleteFriendRequest(ctx, senderEmail, userEmail)
+	request, err := fs.FriendRepo.GetFriendRequest(ctx, senderEmail, userEmail)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err != nil || request == nil || request.Status != "pending" || fs.isExpired(request) {
+		return fmt.Errorf("Friend request not found")
+	}
+
+	err = fs.FriendRepo.UpdateFriendRequest(ctx, senderEmail, userEmail, map[string]interface{}{
+		"Status":     "declined",
+		"DeclinedAt": fs.Now(),
+	})
 	if err != nil {
-		return fmt.Errorf("Failed to decline friend request")
+		return fmt.Errorf("Failed to decline friend request: %w", err)
 	}
 
 	return nil
@@ -260,16 +505,541 @@ func (fs *FriendService) DeclineFriendRequest(ctx context.Context, userEmail, us
 // CancelFriendRequest cancels a sent friend request.
 func (fs *FriendService) CancelFriendRequest(ctx context.Context, userEmail, username string) error {
 	recipientUser, err := fs.UserRepo.GetUserByUsername(ctx, username)
+	if isRepositoryFailure(err) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("User not found")
 	}
 	recipientEmail := recipientUser.Email
 
+	// A declined request looks cancelled to its sender but stays until its cooldown has passed.
+	request, err := fs.FriendRepo.GetFriendRequest(ctx, userEmail, recipientEmail)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err == nil && request != nil && request.Status == "declined" {
+		return nil
+	}
+
 	// Delete the friend request.
 	err = fs.FriendRepo.DeleteFriendRequest(ctx, userEmail, recipientEmail)
 	if err != nil {
-		return fmt.Errorf("Failed to cancel friend request")
+		return fmt.Errorf("Failed to cancel friend request: %w", err)
+	}
+
+	return nil
+}
+
+// BlockUser blocks a user, identified by username or email, and removes any pending
+// request or friendship between the two users. Blocking an already blocked user is a no-op.
+func (fs *FriendService) BlockUser(ctx context.Context, userEmail, identifier string) error {
+	blockedUser, err := fs.findUser(ctx, identifier)
+	if err != nil {
+		return err
+	}
+	blockedEmail := blockedUser.Email
+
+	if userEmail == blockedEmail {
+		return fmt.Errorf("You cannot block yourself")
+	}
+
+	existingBlock, err := fs.FriendRepo.GetBlock(ctx, userEmail, blockedEmail)
+	if err != nil {
+		return err
+	}
+	if existingBlock != nil {
+		return nil
+	}
+
+	block := &models.Block{
+		BlockerEmail: userEmail,
+		BlockedEmail: blockedEmail,
+		CreatedAt:    fs.Now(),
+	}
+	if err := fs.FriendRepo.CreateBlock(ctx, block); err != nil {
+		return fmt.Errorf("Failed to block user: %w", err)
+	}
+
+	// Remove requests and friendships in both directions.
+	fs.FriendRepo.DeleteFriendRequest(ctx, userEmail, blockedEmail)
+	fs.FriendRepo.DeleteFriendRequest(ctx, blockedEmail, userEmail)
+
+	return nil
+}
+
+// UnblockUser removes a block on a user, identified by username or email.
+func (fs *FriendService) UnblockUser(ctx context.Context, userEmail, identifier string) error {
+	blockedUser, err := fs.findUser(ctx, identifier)
+	if err != nil {
+		return err
+	}
+
+	existingBlock, err := fs.FriendRepo.GetBlock(ctx, userEmail, blockedUser.Email)
+	if err != nil {
+		return err
+	}
+	if existingBlock == nil {
+		return fmt.Errorf("User is not blocked")
+	}
+
+	if err := fs.FriendRepo.DeleteBlock(ctx, userEmail, blockedUser.Email); err != nil {
+		return fmt.Errorf("Failed to unblock user: %w", err)
+	}
+
+	return nil
+}
+
+// GetBlockedUsers retrieves summaries of the users blocked by a user.
+func (fs *FriendService) GetBlockedUsers(ctx context.Context, userEmail string) ([]models.UserSummary, error) {
+	blocks, err := fs.FriendRepo.GetBlockedUsers(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching blocked users: %w", err)
+	}
+
+	blockedUsers := []models.UserSummary{}
+	for _, block := range blocks {
+		user, err := fs.UserRepo.GetUserByEmail(ctx, block.BlockedEmail)
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
+		if err != nil || user == nil {
+			continue
+		}
+
+		blockedUsers = append(blockedUsers, models.UserSummary{
+			Username: user.Username,
+			Email:    user.Email,
+			Country:  user.Country,
+			City:     user.City,
+			ImageURL: user.ImageURL,
+		})
+	}
+
+	return blockedUsers, nil
+}
+
+// ComputeSuggestions suggests up to limit friends of the user's friends, ranked by the number of
+// mutual friends. Existing friends, pending requests in either direction and users blocked in either
+// direction are excluded. A limit outside 1..MaxSuggestionLimit is clamped.
+func (fs *FriendService) ComputeSuggestions(ctx context.Context, userEmail string, limit int) ([]models.UserSummary, error) {
+	if limit <= 0 {
+		limit = DefaultSuggestionLimit
+	} else if limit > MaxSuggestionLimit {
+		limit = MaxSuggestionLimit
+	}
+
+	friendRelations, err := fs.FriendRepo.GetFriends(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching friend suggestions: %w", err)
+	}
+	suggestions := []models.UserSummary{}
+	if len(friendRelations) == 0 {
+		return suggestions, nil
+	}
+
+	// Users who cannot be suggested: the user, their friends, pending requests and blocks.
+	excluded := map[string]bool{userEmail: true}
+	var friendEmails []string
+	for _, relation := range friendRelations {
+		friendEmail := otherEmail(relation, userEmail)
+		if !excluded[friendEmail] {
+			excluded[friendEmail] = true
+			friendEmails = append(friendEmails, friendEmail)
+		}
+	}
+	if err := fs.excludePendingAndBlocked(ctx, userEmail, excluded); err != nil {
+		return nil, fmt.Errorf("Error fetching friend suggestions: %w", err)
+	}
+
+	// Count, for every friend of a friend, the distinct friends they have in common with the user.
+	friendsOfFriends, err := fs.FriendRepo.GetFriendsOfUsers(ctx, friendEmails)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching friend suggestions: %w", err)
+	}
+	isFriend := make(map[string]bool, len(friendEmails))
+	for _, email := range friendEmails {
+		isFriend[email] = true
+	}
+	mutualFriends := make(map[string]map[string]bool)
+	for _, relation := range friendsOfFriends {
+		for _, pair := range [][2]string{{relation.Email, relation.FriendEmail}, {relation.FriendEmail, relation.Email}} {
+			friend, candidate := pair[0], pair[1]
+			if !isFriend[friend] || excluded[candidate] {
+				continue
+			}
+			if mutualFriends[candidate] == nil {
+				mutualFriends[candidate] = make(map[string]bool)
+			}
+			mutualFriends[candidate][friend] = true
+		}
+	}
+
+	// Rank by mutual friends, then by email so the order is stable.
+	candidates := make([]string, 0, len(mutualFriends))
+	for candidate := range mutualFriends {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		ci, cj := len(mutualFriends[candidates[i]]), len(mutualFriends[candidates[j]])
+		if ci != cj {
+			return ci > cj
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	// Hydrate the top candidates, skipping anyone who has blocked the user.
+	for _, candidate := range candidates {
+		if len(suggestions) == limit {
+			break
+		}
+		block, err := fs.FriendRepo.GetBlock(ctx, candidate, userEmail)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching friend suggestions: %w", err)
+		}
+		if block != nil {
+			continue
+		}
+		user, err := fs.UserRepo.GetUserByEmail(ctx, candidate)
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
+		if err != nil || user == nil {
+			continue
+		}
+		suggestions = append(suggestions, models.UserSummary{
+			Username:      user.Username,
+			Email:         user.Email,
+			Country:       user.Country,
+			City:          user.City,
+			ImageURL:      user.ImageURL,
+			MutualFriends: len(mutualFriends[candidate]),
+		})
+	}
+
+	return suggestions, nil
+}
+
+// excludePendingAndBlocked adds to excluded everyone with a pending request to or from the user,
+// and everyone the user has blocked.
+func (fs *FriendService) excludePendingAndBlocked(ctx context.Context, userEmail string, excluded map[string]bool) error {
+	received, err := fs.FriendRepo.GetPendingFriendRequests(ctx, userEmail)
+	if err != nil {
+		return err
+	}
+	sent, err := fs.FriendRepo.GetSentFriendRequests(ctx, userEmail)
+	if err != nil {
+		return err
+	}
+	for _, request := range append(received, sent...) {
+		excluded[otherEmail(request, userEmail)] = true
 	}
 
+	blocks, err := fs.FriendRepo.GetBlockedUsers(ctx, userEmail)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		excluded[block.BlockedEmail] = true
+	}
 	return nil
 }
+
+// GetMutualFriends retrieves summaries of the friends the user has in common with the user
+// identified by username or email, sorted by username.
+func (fs *FriendService) GetMutualFriends(ctx context.Context, userEmail, identifier string) ([]models.UserSummary, error) {
+	otherUser, err := fs.findUser(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+	if otherUser.Email == userEmail {
+		return nil, fmt.Errorf("You cannot view mutual friends with yourself")
+	}
+
+	// Blocked users are treated as unknown, so blocking cannot be detected through this endpoint.
+	blocked, err := isBlockedEitherWay(ctx, fs.FriendRepo, userEmail, otherUser.Email)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching mutual friends: %w", err)
+	}
+	if blocked {
+		return nil, fmt.Errorf("User not found")
+	}
+
+	// Load the friendships of both users in one batched lookup.
+	relations, err := fs.FriendRepo.GetFriendsOfUsers(ctx, []string{userEmail, otherUser.Email})
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching mutual friends: %w", err)
+	}
+	friendsOf := map[string]map[string]bool{userEmail: {}, otherUser.Email: {}}
+	for _, relation := range relations {
+		if friends, ok := friendsOf[relation.Email]; ok {
+			friends[relation.FriendEmail] = true
+		}
+		if friends, ok := friendsOf[relation.FriendEmail]; ok {
+			friends[relation.Email] = true
+		}
+	}
+
+	mutualFriends := []models.UserSummary{}
+	for email := range friendsOf[userEmail] {
+		if !friendsOf[otherUser.Email][email] {
+			continue
+		}
+		user, err := fs.UserRepo.GetUserByEmail(ctx, email)
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
+		if err != nil || user == nil {
+			continue
+		}
+		mutualFriends = append(mutualFriends, models.UserSummary{
+			Username: user.Username,
+			Email:    user.Email,
+			Country:  user.Country,
+			City:     user.City,
+			ImageURL: user.ImageURL,
+		})
+	}
+	sort.Slice(mutualFriends, func(i, j int) bool { return mutualFriends[i].Username < mutualFriends[j].Username })
+
+	return mutualFriends, nil
+}
+
+// GetRelationshipStatuses reports the user's relationship with each of emails: FriendshipNone,
+// FriendshipFriends, FriendshipPendingIncoming, FriendshipPendingOutgoing or FriendshipBlocked.
+// Every entry must be an email address, and at most MaxRelationshipStatusEmails can be given.
+func (fs *FriendService) GetRelationshipStatuses(ctx context.Context, userEmail string, emails []string) (map[string]string, error) {
+	if len(emails) > MaxRelationshipStatusEmails {
+		return nil, ErrTooManyStatusEmails
+	}
+	for _, email := range emails {
+		if !utils.IsValidEmail(utils.NormalizeEmail(email)) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidEmail, email)
+		}
+	}
+
+	statuses := make(map[string]string, len(emails))
+	asked := make(map[string][]string, len(emails)) // Normalized email to the forms it was given in.
+	for _, email := range emails {
+		statuses[email] = FriendshipNone
+		normalized := utils.NormalizeEmail(email)
+		asked[normalized] = append(asked[normalized], email)
+	}
+	if len(emails) == 0 {
+		return statuses, nil
+	}
+
+	// Later classifications win: a friendship hides a leftover request, and a block hides both.
+	sent, err := fs.FriendRepo.GetSentFriendRequests(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching relationship statuses: %w", err)
+	}
+	received, err := fs.FriendRepo.GetPendingFriendRequests(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching relationship statuses: %w", err)
+	}
+	friends, err := fs.FriendRepo.GetFriends(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching relationship statuses: %w", err)
+	}
+	blocks, err := fs.FriendRepo.GetBlockedUsers(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching relationship statuses: %w", err)
+	}
+
+	classify := func(email, status string) {
+		if email == userEmail {
+			return
+		}
+		for _, given := range asked[utils.NormalizeEmail(email)] {
+			statuses[given] = status
+		}
+	}
+	for _, request := range sent {
+		if !fs.isExpired(&request) {
+			classify(request.FriendEmail, FriendshipPendingOutgoing)
+		}
+	}
+	for _, request := range received {
+		if !fs.isExpired(&request) {
+			classify(request.Email, FriendshipPendingIncoming)
+		}
+	}
+	for _, friend := range friends {
+		classify(otherEmail(friend, userEmail), FriendshipFriends)
+	}
+	for _, block := range blocks {
+		classify(block.BlockedEmail, FriendshipBlocked)
+	}
+	return statuses, nil
+}
+
+// otherEmail returns the email of the user on the other side of a friendship or request from userEmail.
+func otherEmail(relation models.Friend, userEmail string) string {
+	if relation.Email == userEmail {
+		return relation.FriendEmail
+	}
+	return relation.Email
+}
+
+// InviteFriend sends a friend request to the user registered with email, exactly like SendFriendRequest.
+// If nobody is registered with it, the address is instead emailed an invitation to sign up, which
+// becomes a friend request from userEmail once they do.
+func (fs *FriendService) InviteFriend(ctx context.Context, userEmail, email string) (string, error) {
+	email = utils.NormalizeEmail(email)
+	if !utils.IsValidEmail(email) {
+		return "", ErrInvalidEmail
+	}
+
+	_, err := fs.UserRepo.GetUserByEmail(ctx, email)
+	if isRepositoryFailure(err) {
+		return "", err
+	}
+	if err == nil {
+		accepted, err := fs.SendFriendRequest(ctx, userEmail, email)
+		if err != nil {
+			return "", err
+		}
+		if accepted {
+			return InviteRequestAccepted, nil
+		}
+		return InviteRequestSent, nil
+	}
+
+	if fs.InvitationRepo == nil {
+		return "", fmt.Errorf("User not found")
+	}
+	existing, err := fs.InvitationRepo.GetFriendInvitation(ctx, email, userEmail)
+	if isRepositoryFailure(err) {
+		return "", fmt.Errorf("Failed to send invitation: %w", err)
+	}
+	if err == nil && existing.ConsumedAt == nil {
+		return "", ErrFriendInvitationExists
+	}
+
+	invitation := &models.FriendInvitation{
+		InviterEmail: userEmail,
+		InviteeEmail: email,
+		CreatedAt:    fs.Now(),
+	}
+	if err := fs.InvitationRepo.CreateFriendInvitation(ctx, invitation); err != nil {
+		return "", fmt.Errorf("Failed to send invitation: %w", err)
+	}
+
+	if fs.Email != nil {
+		signupURL := fs.AppURL + "/signup?email=" + url.QueryEscape(email)
+		// The invitee has no account yet, so they are written to in the inviter's language.
+		msg, err := fs.Templates.FriendInvitation(i18n.Locale(ctx), fs.displayName(ctx, userEmail), signupURL)
+		if err == nil {
+			err = fs.Email.SendMultipartEmailAsync(ctx, email, msg)
+		}
+		if err != nil {
+			log.Printf("Failed to send friend invitation email to %s: %v", email, err)
+		}
+	}
+	return InviteInvitationSent, nil
+}
+
+// findUser resolves a user by email or username.
+func (fs *FriendService) findUser(ctx context.Context, identifier string) (*models.User, error) {
+	var user *models.User
+	var err error
+	if email := utils.NormalizeEmail(identifier); utils.IsValidEmail(email) {
+		user, err = fs.UserRepo.GetUserByEmail(ctx, email)
+	} else {
+		user, err = fs.UserRepo.GetUserByUsername(ctx, identifier)
+	}
+	if isRepositoryFailure(err) {
+		return nil, err
+	}
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("User not found")
+	}
+	return user, nil
+}
+
+// isBlockedEitherWay reports whether either of the two users has blocked the other.
+func isBlockedEitherWay(ctx context.Context, friendRepo repositories.FriendRepository, userEmail, otherEmail string) (bool, error) {
+	for _, pair := range [][2]string{{userEmail, otherEmail}, {otherEmail, userEmail}} {
+		block, err := friendRepo.GetBlock(ctx, pair[0], pair[1])
+		if err != nil {
+			return false, err
+		}
+		if block != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// displayName returns the username of the user with the given email, falling back to the email itself.
+func (fs *FriendService) displayName(ctx context.Context, email string) string {
+	user, err := fs.UserRepo.GetUserByEmail(ctx, email)
+	if err != nil || user == nil || user.Username == "" {
+		return email
+	}
+	return user.Username
+}
+
+// notifyAccepted tells the sender of a friend request that accepterEmail accepted it.
+func (fs *FriendService) notifyAccepted(ctx context.Context, sender *models.User, accepterEmail string) {
+	accepter := fs.displayName(ctx, accepterEmail)
+	fs.notify(ctx, sender, func(locale string) (EmailMessage, error) { return fs.Templates.FriendAccepted(locale, accepter) })
+	sendNotification(ctx, fs.Notifications, sender.Email, models.Notification{
+		Type:    NotificationFriendAccepted,
+		Message: fmt.Sprintf("%s accepted your friend request", accepter),
+		Payload: map[string]string{"username": accepter},
+	})
+}
+
+// notify emails a user the message rendered by render in their UserLocale, unless they disabled
+// notifications, holding it back during their quiet hours. Failures are only logged, since a friend operation must not fail
+// because a notification could not be delivered.
+func (fs *FriendService) notify(ctx context.Context, recipient *models.User, render func(locale string) (EmailMessage, error)) {
+	if fs.Email == nil || recipient.NotificationsEnabled != nil && !*recipient.NotificationsEnabled {
+		return
+	}
+	msg, err := render(UserLocale(recipient))
+	if err != nil {
+		log.Printf("Failed to render notification email to %s: %v", recipient.Email, err)
+		return
+	}
+	held, err := fs.Gate.Hold(ctx, recipient, msg, time.Time{})
+	if err != nil {
+		log.Printf("Failed to hold back notification email to %s: %v", recipient.Email, err)
+	}
+	if held {
+		return
+	}
+	if err := fs.Email.SendMultipartEmail(recipient.Email, msg); err != nil {
+		log.Printf("Failed to send notification email to %s: %v", recipient.Email, err)
+	}
+}
+
+// PurgeExpiredFriendRequests deletes the pending requests that have expired and the declined
+// requests whose cooldown has passed, and returns how many were deleted.
+func (fs *FriendService) PurgeExpiredFriendRequests(ctx context.Context) (int, error) {
+	now := fs.Now()
+	return fs.FriendRepo.DeleteStaleFriendRequests(ctx, now.Add(-FriendRequestExpiry), now.Add(-FriendRequestDeclineCooldown))
+}
+
+// StartExpirySweep purges stale friend requests every SweepInterval until the context is cancelled.
+func (fs *FriendService) StartExpirySweep(ctx context.Context) {
+	ticker := time.NewTicker(fs.SweepInterval)
+	defer ticker.Stop()
+	fs.RunExpirySweep(ctx, ticker.C)
+}
+
+// RunExpirySweep purges stale friend requests on every tick until the context is cancelled.
+func (fs *FriendService) RunExpirySweep(ctx context.Context, ticks <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+			if _, err := fs.PurgeExpiredFriendRequests(ctx); err != nil {
+				log.Printf("Failed to purge expired friend requests: %v", err)
+			}
+		}
+	}
+}
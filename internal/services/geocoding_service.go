@@ -0,0 +1,236 @@
+/**
+ *  GeocodingService looks up the coordinates of a free-text address, so events can be shown on a
+ *  map and searched by distance. NominatimGeocoder implements it with the OpenStreetMap Nominatim
+ *  search API.
+ *
+ *  @interface GeocodingService
+ *  @struct    NominatimGeocoder
+ *
+ *  @methods
+ *  - NewNominatimGeocoder(userAgent) - Initializes a geocoder for the public Nominatim server.
+ *  - Geocode(ctx, address)           - Returns the coordinates of the best match for an address.
+ *  - NormalizeAddress(address)       - Normalizes an address for use as a cache key.
+ *
+ *  @behaviors
+ *  - Results are cached in memory by normalized address for CacheTTL, 30 days by default, including
+ *    addresses that were not found, so the same address is looked up at most once per period.
+ *  - Requests are spaced at least a second apart, and identify the application with UserAgent,
+ *    as the Nominatim usage policy requires.
+ *  - Returns ErrAddressNotFound when there is no match.
+ *
+ *  @dependencies
+ *  - nominatim.openstreetmap.org: External geocoding API.
+ *  - golang.org/x/time/rate: Spaces out requests to the API.
+ *  - httpclient.New: Builds the HTTP client, with timeouts and retries.
+ *
+ *  @example
+ *  ```
+ *  geocoder := NewNominatimGeocoder("DailyVerse/1.0 (admin@example.com)")
+ *  coordinates, err := geocoder.Geocode(ctx, "Kongens gate 1, 7011")
+ *  ```
+ *
+ *  @file      geocoding_service.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Client with JSON Integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"proh2052-group6/pkg/httpclient"
+)
+
+// DefaultGeocodeCacheTTL is how long geocoding results are cached by default. Addresses rarely move.
+const DefaultGeocodeCacheTTL = 30 * 24 * time.Hour
+
+// nominatimSearchURL is the search endpoint of the public Nominatim server.
+const nominatimSearchURL = "https://nominatim.openstreetmap.org/search"
+
+// ErrAddressNotFound is returned when the geocoding API has no match for an address.
+var ErrAddressNotFound = errors.New("Address not found")
+
+// Coordinates is a position on the earth in degrees.
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// GeocodingService defines the contract for looking up the coordinates of an address.
+type GeocodingService interface {
+	// Geocode returns the coordinates of the best match for address, or ErrAddressNotFound.
+	Geocode(ctx context.Context, address string) (*Coordinates, error)
+}
+
+// NominatimGeocoder implements GeocodingService with the Nominatim search API.
+type NominatimGeocoder struct {
+	HTTPClient *http.Client     // HTTP client for making API requests.
+	SearchURL  string           // URL of the Nominatim search endpoint.
+	UserAgent  string           // Identifies the application to Nominatim.
+	Limiter    *rate.Limiter    // Spaces out requests; nil sends them without delay.
+	CacheTTL   time.Duration    // How long results are cached; 0 disables caching.
+	Now        func() time.Time // Clock used for cache expiry; defaults to time.Now.
+
+	mutex sync.Mutex
+	cache map[string]geocodeCacheEntry
+}
+
+// geocodeCacheEntry holds the result for an address until it expires.
+type geocodeCacheEntry struct {
+	coordinates *Coordinates // nil if the address was not found.
+	expiresAt   time.Time
+}
+
+// NewNominatimGeocoder initializes a NominatimGeocoder for the public Nominatim server, which
+// allows one request per second from clients identified by userAgent.
+func NewNominatimGeocoder(userAgent string) GeocodingService {
+	return &NominatimGeocoder{
+		HTTPClient: httpclient.New(httpclient.DefaultConfig()),
+		SearchURL:  nominatimSearchURL,
+		UserAgent:  userAgent,
+		Limiter:    rate.NewLimiter(rate.Every(time.Second), 1),
+		CacheTTL:   DefaultGeocodeCacheTTL,
+		Now:        time.Now,
+	}
+}
+
+// NormalizeAddress lowercases an address and collapses its whitespace and commas, so differently
+// typed versions of the same address share a cache entry.
+func NormalizeAddress(address string) string {
+	var parts []string
+	for _, part := range strings.Split(address, ",") {
+		if part = strings.Join(strings.Fields(part), " "); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.ToLower(strings.Join(parts, ", "))
+}
+
+// Geocode returns the coordinates of the best match for address, from the cache if possible,
+// otherwise by calling the Nominatim API.
+func (g *NominatimGeocoder) Geocode(ctx context.Context, address string) (*Coordinates, error) {
+	key := NormalizeAddress(address)
+	if key == "" {
+		return nil, ErrAddressNotFound
+	}
+	if entry, ok := g.cached(key); ok {
+		return entry.result()
+	}
+
+	if g.Limiter != nil {
+		if err := g.Limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("Geocoding was cancelled: %w", err)
+		}
+	}
+
+	params := url.Values{}
+	params.Set("q", key)
+	params.Set("format", "jsonv2")
+	params.Set("limit", "1")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.SearchURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create geocoding request: %v", err)
+	}
+	req.Header.Set("User-Agent", g.UserAgent)
+
+	resp, err := g.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to reach the geocoding API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Geocoding API responded with status %d", resp.StatusCode)
+	}
+
+	// Nominatim returns the coordinates as strings.
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("Failed to parse geocoding response: %v", err)
+	}
+
+	entry := geocodeCacheEntry{}
+	if len(results) > 0 {
+		latitude, latErr := strconv.ParseFloat(results[0].Lat, 64)
+		longitude, lonErr := strconv.ParseFloat(results[0].Lon, 64)
+		if latErr != nil || lonErr != nil {
+			return nil, fmt.Errorf("Failed to parse geocoding response: invalid coordinates")
+		}
+		entry.coordinates = &Coordinates{Latitude: latitude, Longitude: longitude}
+	}
+	g.store(key, entry)
+	return entry.result()
+}
+
+// result returns a copy of the cached coordinates, or ErrAddressNotFound for a cached miss.
+func (entry geocodeCacheEntry) result() (*Coordinates, error) {
+	if entry.coordinates == nil {
+		return nil, ErrAddressNotFound
+	}
+	coordinates := *entry.coordinates
+	return &coordinates, nil
+}
+
+// cached returns the unexpired cache entry for the normalized address key.
+func (g *NominatimGeocoder) cached(key string) (geocodeCacheEntry, bool) {
+	if g.CacheTTL <= 0 {
+		return geocodeCacheEntry{}, false
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	entry, ok := g.cache[key]
+	if !ok || !g.now().Before(entry.expiresAt) {
+		return geocodeCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// store caches the result for the normalized address key and evicts expired entries.
+func (g *NominatimGeocoder) store(key string, entry geocodeCacheEntry) {
+	if g.CacheTTL <= 0 {
+		return
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.cache == nil {
+		g.cache = make(map[string]geocodeCacheEntry)
+	}
+
+	now := g.now()
+	for k, existing := range g.cache {
+		if !now.Before(existing.expiresAt) {
+			delete(g.cache, k)
+		}
+	}
+	entry.expiresAt = now.Add(g.CacheTTL)
+	g.cache[key] = entry
+}
+
+// now returns the current time from the geocoder's clock.
+func (g *NominatimGeocoder) now() time.Time {
+	if g.Now == nil {
+		return time.Now()
+	}
+	return g.Now()
+}
@@ -0,0 +1,120 @@
+/**
+ *  HealthService checks whether the application's dependencies are reachable, for use by
+ *  load balancer and orchestrator readiness probes. Each dependency is checked by an injectable
+ *  HealthChecker, so tests can simulate failures without real clients.
+ *
+ *  @interface HealthServiceInterface
+ *  @struct   HealthService
+ *
+ *  @methods
+ *  - CheckReadiness(ctx)              - Runs every checker and reports the status of each dependency.
+ *  - FirestoreHealthChecker(client)   - Creates a checker that pings Firestore like NewFirestoreClient does.
+ *  - SkippedHealthChecker()           - Creates a checker for dependencies that are not probed.
+ *
+ *  @behaviors
+ *  - Reports each dependency as "ok", "skipped" or "error".
+ *  - The application is ready only if no check reports "error".
+ *  - Every check runs with the service's Timeout, so a hanging dependency cannot block the probe.
+ *  - Failure details are logged rather than returned, so they are not exposed to callers.
+ *
+ *  @example
+ *  ```
+ *  healthService := services.NewHealthService(map[string]services.HealthChecker{
+ *      "firestore": services.FirestoreHealthChecker(dbClient),
+ *      "smtp":      services.SkippedHealthChecker(),
+ *  })
+ *  statuses, ready := healthService.CheckReadiness(ctx) // {"firestore": "ok", "smtp": "skipped"}, true
+ *  ```
+ *
+ *  @file      health_service.go
+ *  @project   DailyVerse
+ *  @framework Go Business Logic Layer
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// Dependency statuses reported by CheckReadiness.
+const (
+	HealthStatusOK      = "ok"
+	HealthStatusSkipped = "skipped"
+	HealthStatusError   = "error"
+)
+
+// ErrHealthCheckSkipped is returned by a HealthChecker for a dependency that is not probed.
+var ErrHealthCheckSkipped = errors.New("Health check skipped")
+
+// HealthChecker checks a single dependency. It returns nil if the dependency is reachable.
+type HealthChecker func(ctx context.Context) error
+
+// HealthServiceInterface defines the contract for readiness checks.
+type HealthServiceInterface interface {
+	// CheckReadiness reports the status of each dependency and whether all of them are usable.
+	CheckReadiness(ctx context.Context) (map[string]string, bool)
+}
+
+// HealthService implements HealthServiceInterface.
+type HealthService struct {
+	Checkers map[string]HealthChecker // Checkers keyed by dependency name.
+	Timeout  time.Duration            // Maximum time a single check may take.
+}
+
+// NewHealthService initializes a HealthService with the given checkers and a 2-second timeout per check.
+func NewHealthService(checkers map[string]HealthChecker) HealthServiceInterface {
+	return &HealthService{Checkers: checkers, Timeout: 2 * time.Second}
+}
+
+// CheckReadiness runs every checker and reports the status of each dependency.
+// The application is ready if no dependency reports an error.
+func (hs *HealthService) CheckReadiness(ctx context.Context) (map[string]string, bool) {
+	statuses := make(map[string]string, len(hs.Checkers))
+	ready := true
+
+	for name, check := range hs.Checkers {
+		checkCtx, cancel := context.WithTimeout(ctx, hs.Timeout)
+		err := check(checkCtx)
+		cancel()
+
+		switch {
+		case err == nil:
+			statuses[name] = HealthStatusOK
+		case errors.Is(err, ErrHealthCheckSkipped):
+			statuses[name] = HealthStatusSkipped
+		default:
+			log.Printf("Readiness check %s failed: %v", name, err)
+			statuses[name] = HealthStatusError
+			ready = false
+		}
+	}
+
+	return statuses, ready
+}
+
+// FirestoreHealthChecker creates a checker that pings the database with PingFirestore,
+// the same check NewFirestoreClient makes at startup.
+func FirestoreHealthChecker(client *firestore.Client) HealthChecker {
+	return func(ctx context.Context) error {
+		return PingFirestore(ctx, client)
+	}
+}
+
+// SkippedHealthChecker creates a checker for a dependency that is not probed,
+// e.g. SMTP, where a real check would require sending an email.
+func SkippedHealthChecker() HealthChecker {
+	return func(ctx context.Context) error {
+		return ErrHealthCheckSkipped
+	}
+}
@@ -0,0 +1,308 @@
+/**
+ *  HolidayService fetches the public holidays of a country, so the calendar can show them next to
+ *  the user's events. It integrates with the Nager.Date public holidays API, and falls back to the
+ *  Norwegian holidays embedded in the binary when the API fails.
+ *
+ *  @interface HolidayServiceInterface
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewHolidayService(userAgent)            - Initializes a HolidayService for the public Nager.Date API.
+ *  - GetHolidays(ctx, countryCode, year)     - Returns the national holidays of a country in a year.
+ *  - NorwegianHolidays(year)                 - Returns the embedded Norwegian holidays of a year.
+ *
+ *  @behaviors
+ *  - Countries are given by the ISO 3166-1 alpha-2 codes of CountryLanguageMap, in either case.
+ *  - Only national holidays are returned; holidays of some regions only are left out.
+ *  - Holidays are cached in memory for CacheTTL, 24 hours by default, per country and year.
+ *  - Returns ErrHolidayYearOutOfRange for years more than MaxHolidayYearDistance from the current year.
+ *  - If the API fails, rejects the request or returns malformed data, the holidays of Norway come from
+ *    holidays/norway.json, fixed dates and days relative to Easter, so the fallback covers every year.
+ *    Fallback holidays are not cached, so the next request retries the API. Other countries get a
+ *    *HolidayAPIError.
+ *  - Every request identifies the application with UserAgent.
+ *
+ *  @dependencies
+ *  - date.nager.at: External public holidays API.
+ *  - httpclient.New: Builds the HTTP client, with timeouts and retries.
+ *
+ *  @example
+ *  ```
+ *  holidayService := NewHolidayService("DailyVerse/1.0 (admin@example.com)")
+ *  holidays, err := holidayService.GetHolidays(ctx, "NO", 2025)
+ *  ```
+ *
+ *  @file      holiday_service.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Client with JSON Integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"proh2052-group6/pkg/httpclient"
+)
+
+// DefaultHolidayCacheTTL is how long holidays are cached by default.
+const DefaultHolidayCacheTTL = 24 * time.Hour
+
+// MaxHolidayYearDistance is how many years before or after the current year holidays can be listed for.
+const MaxHolidayYearDistance = 10
+
+// nagerDateHolidaysURL is the public holidays endpoint of the Nager.Date API, followed by /{year}/{countryCode}.
+const nagerDateHolidaysURL = "https://date.nager.at/api/v3/PublicHolidays"
+
+// norwayCountryCode is the country whose holidays are embedded as a fallback.
+const norwayCountryCode = "NO"
+
+// Errors returned by GetHolidays for invalid input.
+var (
+	ErrHolidayYearOutOfRange = fmt.Errorf("year must be within %d years of the current year", MaxHolidayYearDistance)
+	ErrUnknownCountryCode    = errors.New("Unknown country code")
+)
+
+//go:embed holidays/norway.json
+var norwayHolidaysJSON []byte
+
+// HolidayServiceInterface defines the contract for fetching public holidays.
+type HolidayServiceInterface interface {
+	// GetHolidays returns the national holidays of the country with countryCode in year, by date.
+	GetHolidays(ctx context.Context, countryCode string, year int) ([]Holiday, error)
+}
+
+// Holiday is a public holiday.
+type Holiday struct {
+	Date      string `json:"date"`      // Date of the holiday (YYYY-MM-DD).
+	LocalName string `json:"localName"` // Name in the country's language, e.g. "Syttende mai".
+	Name      string `json:"name"`      // English name, e.g. "Constitution Day".
+}
+
+// HolidayAPIError is returned when the holidays API fails or responds with an error.
+type HolidayAPIError struct {
+	StatusCode int    // HTTP status code from the holidays API, or 0 if no response was received.
+	Message    string // Description of the failure.
+}
+
+// Error implements the error interface.
+func (e *HolidayAPIError) Error() string {
+	return fmt.Sprintf("Holiday API error: %s", e.Message)
+}
+
+// HolidayService implements the HolidayServiceInterface with the Nager.Date API.
+type HolidayService struct {
+	HTTPClient  *http.Client     // HTTP client for making API requests.
+	HolidaysURL string           // URL of the public holidays endpoint.
+	UserAgent   string           // Identifies the application to the holidays API.
+	CacheTTL    time.Duration    // How long holidays are cached; 0 disables caching.
+	Now         func() time.Time // Clock used for the year range and cache expiry; defaults to time.Now.
+
+	mutex sync.Mutex
+	cache map[holidayCacheKey]holidayCacheEntry
+}
+
+// holidayCacheKey identifies the holidays of a country in a year.
+type holidayCacheKey struct {
+	countryCode string
+	year        int
+}
+
+// holidayCacheEntry holds holidays until they expire.
+type holidayCacheEntry struct {
+	holidays  []Holiday
+	expiresAt time.Time
+}
+
+// NewHolidayService initializes a HolidayService for the public Nager.Date API, identifying the
+// application with userAgent.
+func NewHolidayService(userAgent string) HolidayServiceInterface {
+	return &HolidayService{
+		HTTPClient:  httpclient.New(httpclient.DefaultConfig()),
+		HolidaysURL: nagerDateHolidaysURL,
+		UserAgent:   userAgent,
+		CacheTTL:    DefaultHolidayCacheTTL,
+		Now:         time.Now,
+	}
+}
+
+// GetHolidays returns the national holidays of the country with countryCode in year, from the cache
+// if possible, otherwise by calling the holidays API.
+func (hs *HolidayService) GetHolidays(ctx context.Context, countryCode string, year int) ([]Holiday, error) {
+	countryCode = strings.ToUpper(strings.TrimSpace(countryCode))
+	if _, _, err := LookupByCode(countryCode); err != nil {
+		return nil, ErrUnknownCountryCode
+	}
+	if distance := year - hs.now().Year(); distance < -MaxHolidayYearDistance || distance > MaxHolidayYearDistance {
+		return nil, ErrHolidayYearOutOfRange
+	}
+
+	key := holidayCacheKey{countryCode: countryCode, year: year}
+	if holidays, ok := hs.cachedHolidays(key); ok {
+		return holidays, nil
+	}
+
+	holidays, err := hs.requestHolidays(ctx, key)
+	if err != nil {
+		if countryCode == norwayCountryCode {
+			return NorwegianHolidays(year), nil
+		}
+		return nil, err
+	}
+	hs.cacheHolidays(key, holidays)
+	return holidays, nil
+}
+
+// requestHolidays calls the holidays API for the holidays of the country and year in key.
+func (hs *HolidayService) requestHolidays(ctx context.Context, key holidayCacheKey) ([]Holiday, error) {
+	url := fmt.Sprintf("%s/%d/%s", hs.HolidaysURL, key.year, key.countryCode)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create holidays request: %v", err)
+	}
+	req.Header.Set("User-Agent", hs.UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := hs.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &HolidayAPIError{Message: "Failed to reach the holidays API"}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HolidayAPIError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("Unexpected response with status %d", resp.StatusCode)}
+	}
+
+	var result []struct {
+		Date      string `json:"date"`
+		LocalName string `json:"localName"`
+		Name      string `json:"name"`
+		Global    bool   `json:"global"` // False for holidays of some regions only.
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, &HolidayAPIError{StatusCode: resp.StatusCode, Message: "Failed to parse holiday data"}
+	}
+
+	holidays := []Holiday{}
+	for _, holiday := range result {
+		if _, err := time.Parse("2006-01-02", holiday.Date); err != nil {
+			return nil, &HolidayAPIError{StatusCode: resp.StatusCode, Message: "Failed to parse holiday data"}
+		}
+		if holiday.Global {
+			holidays = append(holidays, Holiday{Date: holiday.Date, LocalName: holiday.LocalName, Name: holiday.Name})
+		}
+	}
+	sortHolidays(holidays)
+	return holidays, nil
+}
+
+// NorwegianHolidays returns the Norwegian public holidays of year from the embedded rules, by date.
+// It panics if the embedded rules are invalid, since they are part of the binary.
+func NorwegianHolidays(year int) []Holiday {
+	var rules []struct {
+		Date         string `json:"date"`         // Month and day (MM-DD) of a holiday on a fixed date.
+		EasterOffset *int   `json:"easterOffset"` // Days after Easter Sunday of a movable holiday.
+		LocalName    string `json:"localName"`
+		Name         string `json:"name"`
+	}
+	if err := json.Unmarshal(norwayHolidaysJSON, &rules); err != nil {
+		panic(fmt.Sprintf("Invalid Norwegian holidays: %v", err))
+	}
+
+	easter := easterSunday(year)
+	holidays := make([]Holiday, 0, len(rules))
+	for _, rule := range rules {
+		var date time.Time
+		if rule.EasterOffset != nil {
+			date = easter.AddDate(0, 0, *rule.EasterOffset)
+		} else {
+			day, err := time.Parse("01-02", rule.Date)
+			if err != nil {
+				panic(fmt.Sprintf("Invalid Norwegian holiday date %q: %v", rule.Date, err))
+			}
+			date = time.Date(year, day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		holidays = append(holidays, Holiday{Date: date.Format("2006-01-02"), LocalName: rule.LocalName, Name: rule.Name})
+	}
+	sortHolidays(holidays)
+	return holidays
+}
+
+// easterSunday returns the date of Easter Sunday in year in the Gregorian calendar, computed with
+// the anonymous Gregorian algorithm.
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// sortHolidays orders holidays by date.
+func sortHolidays(holidays []Holiday) {
+	sort.SliceStable(holidays, func(i, j int) bool { return holidays[i].Date < holidays[j].Date })
+}
+
+// cachedHolidays returns a copy of the unexpired holidays cached for key.
+func (hs *HolidayService) cachedHolidays(key holidayCacheKey) ([]Holiday, bool) {
+	if hs.CacheTTL <= 0 {
+		return nil, false
+	}
+
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+	entry, ok := hs.cache[key]
+	if !ok || !hs.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return append([]Holiday{}, entry.holidays...), true
+}
+
+// cacheHolidays stores the holidays for key and evicts expired entries.
+func (hs *HolidayService) cacheHolidays(key holidayCacheKey, holidays []Holiday) {
+	if hs.CacheTTL <= 0 {
+		return
+	}
+
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+	if hs.cache == nil {
+		hs.cache = make(map[holidayCacheKey]holidayCacheEntry)
+	}
+
+	now := hs.now()
+	for k, entry := range hs.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(hs.cache, k)
+		}
+	}
+	hs.cache[key] = holidayCacheEntry{holidays: append([]Holiday{}, holidays...), expiresAt: now.Add(hs.CacheTTL)}
+}
+
+// now returns the current time from the service's clock.
+func (hs *HolidayService) now() time.Time {
+	if hs.Now == nil {
+		return time.Now()
+	}
+	return hs.Now()
+}
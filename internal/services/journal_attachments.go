@@ -0,0 +1,211 @@
+/**
+ *  Journal attachments. Images are attached to a journal entry one at a time, kept private in the
+ *  StorageService, listed on the entry with their metadata, and downloaded through the API.
+ *
+ *  @file       journal_attachments.go
+ *  @package    services
+ *
+ *  @methods
+ *  - AddAttachment(ctx, userEmail, journalID, data)              - Stores an image and attaches it to an entry.
+ *  - GetAttachment(ctx, userEmail, journalID, attachmentID)      - Reads an image attached to an entry.
+ *  - DeleteAttachment(ctx, userEmail, journalID, attachmentID)   - Removes an image from an entry and from storage.
+ *  - AttachmentURL(journalID, attachmentID)                      - Returns the API path an image is downloaded from.
+ *  - linkAttachments(journal)                                    - Points the attachments of a read entry at AttachmentURL.
+ *  - deleteAttachmentObjects(ctx, journal)                       - Removes the stored images of a purged entry.
+ *
+ *  @behaviors
+ *  - The type of an image is sniffed from its content, whatever the upload claims; only JPEG, PNG and
+ *    WebP images of at most MaxAttachmentSize bytes are accepted, and at most MaxJournalAttachments per entry.
+ *  - Entries are looked up under the user's own email, so an entry of another user, or one in the trash,
+ *    is reported as repositories.ErrNotFound.
+ *  - Attachments survive saving the entry, which cannot change them, and moving it to the trash, so a
+ *    restored entry keeps its images. They are deleted from storage when the entry is purged.
+ *  - Storage failures after the entry was saved are only logged, since the image is no longer referenced.
+ *  - Images are uploaded with StorageService.UploadPrivate and only their owner can read them, through
+ *    GetAttachment; the URL of an attachment is the authenticated API path, never a storage URL.
+ *    Attachments stored before that have their storage URL in URL; it is moved to Object when the entry is read.
+ *
+ *  @errors
+ *  - ErrAttachmentTooLarge: The image is larger than MaxAttachmentSize.
+ *  - ErrUnsupportedAttachmentType: The file is not a JPEG, PNG or WebP image.
+ *  - ErrTooManyAttachments: The entry already has MaxJournalAttachments attachments.
+ *  - ErrAttachmentUploadsDisabled: No StorageService is configured.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// Errors returned when attaching an image to a journal entry.
+var (
+	ErrAttachmentTooLarge        = errors.New("Attachment must be at most 5 MB")
+	ErrUnsupportedAttachmentType = errors.New("Attachment must be a JPEG, PNG or WebP image")
+	ErrTooManyAttachments        = errors.New("A journal entry can have at most 10 attachments")
+	ErrAttachmentUploadsDisabled = errors.New("Attachment uploads are not available")
+)
+
+// MaxAttachmentSize is the maximum size of a journal attachment in bytes.
+const MaxAttachmentSize = 5 << 20
+
+// MaxJournalAttachments is the maximum number of attachments of a journal entry.
+const MaxJournalAttachments = 10
+
+// attachmentExtensions maps the accepted attachment content types to the extension of the stored object.
+var attachmentExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// AddAttachment stores data as an image attached to the user's journal entry and returns its metadata.
+func (js *JournalService) AddAttachment(ctx context.Context, userEmail, journalID string, data []byte) (*models.Attachment, error) {
+	if js.Storage == nil {
+		return nil, ErrAttachmentUploadsDisabled
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := attachmentExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedAttachmentType
+	}
+
+	journal, err := js.GetJournal(ctx, userEmail, journalID)
+	if err != nil {
+		return nil, err
+	}
+	if len(journal.Attachments) >= MaxJournalAttachments {
+		return nil, ErrTooManyAttachments
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("Failed to upload attachment")
+	}
+	attachment := models.Attachment{ID: hex.EncodeToString(id), ContentType: contentType, Size: len(data)}
+	attachment.Object, err = js.Storage.UploadPrivate(ctx, "journals/"+attachment.ID+extension, contentType, data)
+	if err != nil {
+		log.Printf("Failed to upload attachment for journal %s of %s: %v", journalID, userEmail, err)
+		return nil, fmt.Errorf("Failed to upload attachment")
+	}
+	attachment.URL = AttachmentURL(journal.JournalID, attachment.ID)
+
+	journal.Attachments = append(journal.Attachments, attachment)
+	if err := js.updateJournal(ctx, journal); err != nil {
+		js.deleteAttachmentObject(ctx, attachment)
+		return nil, fmt.Errorf("Failed to save attachment: %w", err)
+	}
+	return &attachment, nil
+}
+
+// GetAttachment reads an image attached to the user's journal entry and returns its metadata and content.
+// It returns repositories.ErrNotFound, wrapped, if the entry, the attachment or the stored image does not exist.
+func (js *JournalService) GetAttachment(ctx context.Context, userEmail, journalID, attachmentID string) (*models.Attachment, []byte, error) {
+	if js.Storage == nil {
+		return nil, nil, ErrAttachmentUploadsDisabled
+	}
+	journal, err := js.GetJournal(ctx, userEmail, journalID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, attachment := range journal.Attachments {
+		if attachment.ID != attachmentID {
+			continue
+		}
+		data, err := js.Storage.Download(ctx, attachment.Object)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil, err
+		}
+		if err != nil {
+			log.Printf("Failed to download attachment %s of journal %s: %v", attachmentID, journalID, err)
+			return nil, nil, fmt.Errorf("Failed to download attachment")
+		}
+		return &attachment, data, nil
+	}
+	return nil, nil, fmt.Errorf("Attachment %w", repositories.ErrNotFound)
+}
+
+// DeleteAttachment removes an attachment from the user's journal entry and deletes the image from storage.
+// It returns repositories.ErrNotFound, wrapped, if the entry or the attachment does not exist.
+func (js *JournalService) DeleteAttachment(ctx context.Context, userEmail, journalID, attachmentID string) error {
+	journal, err := js.GetJournal(ctx, userEmail, journalID)
+	if err != nil {
+		return err
+	}
+
+	for i, attachment := range journal.Attachments {
+		if attachment.ID != attachmentID {
+			continue
+		}
+		journal.Attachments = append(journal.Attachments[:i:i], journal.Attachments[i+1:]...)
+		if err := js.updateJournal(ctx, journal); err != nil {
+			return fmt.Errorf("Failed to delete attachment: %w", err)
+		}
+		js.deleteAttachmentObject(ctx, attachment)
+		return nil
+	}
+	return fmt.Errorf("Attachment %w", repositories.ErrNotFound)
+}
+
+// deleteAttachmentObjects deletes the stored images of a journal entry that no longer exists.
+func (js *JournalService) deleteAttachmentObjects(ctx context.Context, journal models.Journal) {
+	for _, attachment := range journal.Attachments {
+		js.deleteAttachmentObject(ctx, attachment)
+	}
+}
+
+// deleteAttachmentObject deletes an attachment from storage. Failures are only logged, since the
+// image is no longer referenced by the entry.
+func (js *JournalService) deleteAttachmentObject(ctx context.Context, attachment models.Attachment) {
+	if js.Storage == nil {
+		return
+	}
+	objectURL := attachmentObject(attachment)
+	if err := js.Storage.Delete(ctx, objectURL); err != nil {
+		log.Printf("Failed to delete journal attachment %s: %v", objectURL, err)
+	}
+}
+
+// AttachmentURL returns the API path the image attachmentID of journalID is downloaded from.
+func AttachmentURL(journalID, attachmentID string) string {
+	return "/api/journal/attachments?" + url.Values{"journalID": {journalID}, "attachmentID": {attachmentID}}.Encode()
+}
+
+// attachmentObject returns the URL of an attachment in storage: Object, or URL for an attachment
+// stored before Object existed.
+func attachmentObject(attachment models.Attachment) string {
+	if attachment.Object != "" {
+		return attachment.Object
+	}
+	return attachment.URL
+}
+
+// linkAttachments points the URLs of the attachments of a journal entry read from the repository at
+// AttachmentURL, keeping their storage URL in Object.
+func linkAttachments(journal *models.Journal) {
+	for i := range journal.Attachments {
+		attachment := &journal.Attachments[i]
+		attachment.Object = attachmentObject(*attachment)
+		attachment.URL = AttachmentURL(journal.JournalID, attachment.ID)
+	}
+}
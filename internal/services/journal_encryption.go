@@ -0,0 +1,177 @@
+/**
+ *  Journal encryption at rest. With a master key configured, the content of journal entries is
+ *  encrypted before it reaches the repository, so it cannot be read in the database console.
+ *
+ *  @file       journal_encryption.go
+ *  @package    services
+ *
+ *  @struct   JournalCipher
+ *
+ *  @methods
+ *  - NewJournalCipher(masterKey)      - Creates a JournalCipher from a 32-byte master key.
+ *  - Seal(journal)                    - Encrypts the content of a journal entry for its user.
+ *  - Open(journal)                    - Decrypts the content of a journal entry, leaving plaintext entries as they are.
+ *  - (JournalService) sealJournal     - Stores a journal entry encrypted, leaving the caller's copy in plaintext.
+ *  - (JournalService) openJournals    - Decrypts journal entries read from the repository.
+ *
+ *  @behaviors
+ *  - Each user has their own AES-256 key, derived from the master key and their email with HKDF-SHA256.
+ *    Content is sealed with AES-GCM under a random nonce and stored as base64 of the nonce and ciphertext.
+ *  - Entries record the version of the encryption and the email their key was derived from. Version 0
+ *    marks legacy plaintext entries, which are read as they are and encrypted the next time they are
+ *    saved. An entry moved to a new email by an email change keeps being read with the key of the old
+ *    email until it is saved again.
+ *  - Without a cipher, entries are stored in plaintext; encrypted entries then cannot be read.
+ *  - An entry that cannot be decrypted, e.g. because the master key changed, fails with
+ *    ErrJournalDecryption instead of returning ciphertext as content.
+ *
+ *  @errors
+ *  - ErrJournalDecryption: The content of an entry could not be decrypted.
+ *
+ *  @dependencies
+ *  - golang.org/x/crypto/hkdf: Derives the per-user keys.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"proh2052-group6/pkg/models"
+)
+
+// Versions of journal encryption, stored in models.Journal.Encryption.
+const (
+	JournalPlaintext   = 0 // Legacy entries and entries written without a master key.
+	JournalEncryptedV1 = 1 // AES-256-GCM with a key derived by HKDF-SHA256 from the master key and the user's email.
+)
+
+// journalKeyInfo is the HKDF info prefix of the journal keys, so other keys derived from the same
+// master key can never be equal to them.
+const journalKeyInfo = "dailyverse journal v1:"
+
+// ErrJournalDecryption is returned for a journal entry whose content cannot be decrypted.
+var ErrJournalDecryption = errors.New("Journal entry could not be decrypted")
+
+// JournalCipher encrypts and decrypts the content of journal entries with per-user keys.
+type JournalCipher struct {
+	masterKey []byte
+}
+
+// NewJournalCipher creates a JournalCipher from a 32-byte master key.
+func NewJournalCipher(masterKey []byte) (*JournalCipher, error) {
+	if len(masterKey) != 32 {
+		return nil, fmt.Errorf("Journal master key must be 32 bytes, got %d", len(masterKey))
+	}
+	return &JournalCipher{masterKey: append([]byte(nil), masterKey...)}, nil
+}
+
+// aead returns the AES-GCM cipher with the key of userEmail.
+func (jc *JournalCipher) aead(userEmail string) (cipher.AEAD, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, jc.masterKey, nil, []byte(journalKeyInfo+userEmail)), key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts the content of journal with the key of journal.Email.
+func (jc *JournalCipher) Seal(journal *models.Journal) error {
+	aead, err := jc.aead(journal.Email)
+	if err != nil {
+		return fmt.Errorf("Failed to encrypt journal: %v", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("Failed to encrypt journal: %v", err)
+	}
+	journal.Content = base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(journal.Content), nil))
+	journal.Encryption = JournalEncryptedV1
+	journal.KeyEmail = journal.Email
+	return nil
+}
+
+// Open decrypts the content of journal. Plaintext entries are left as they are.
+func (jc *JournalCipher) Open(journal *models.Journal) error {
+	if journal.Encryption == JournalPlaintext {
+		return nil
+	}
+	if jc == nil || journal.Encryption != JournalEncryptedV1 {
+		return ErrJournalDecryption
+	}
+
+	keyEmail := journal.KeyEmail
+	if keyEmail == "" {
+		keyEmail = journal.Email
+	}
+	aead, err := jc.aead(keyEmail)
+	if err != nil {
+		return ErrJournalDecryption
+	}
+	sealed, err := base64.StdEncoding.DecodeString(journal.Content)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return ErrJournalDecryption
+	}
+	content, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return ErrJournalDecryption
+	}
+	journal.Content = string(content)
+	journal.Encryption = JournalPlaintext
+	journal.KeyEmail = ""
+	return nil
+}
+
+// sealJournal encrypts journal if a cipher is configured and passes it to store, e.g. the repository's
+// CreateJournal. The content is plaintext again when sealJournal returns, so callers can keep using
+// journal, including the ID store may have set.
+func (js *JournalService) sealJournal(journal *models.Journal, store func(*models.Journal) error) error {
+	content := journal.Content
+	defer func() {
+		journal.Content = content
+		journal.Encryption = JournalPlaintext
+		journal.KeyEmail = ""
+	}()
+
+	journal.Encryption = JournalPlaintext
+	journal.KeyEmail = ""
+	if js.Cipher != nil {
+		if err := js.Cipher.Seal(journal); err != nil {
+			return err
+		}
+	}
+	return store(journal)
+}
+
+// openJournals decrypts journals read from the repository in place and points their attachments at
+// the API (see linkAttachments).
+func (js *JournalService) openJournals(journals ...*models.Journal) error {
+	for _, journal := range journals {
+		if journal == nil {
+			continue
+		}
+		if err := js.Cipher.Open(journal); err != nil {
+			return err
+		}
+		linkAttachments(journal)
+	}
+	return nil
+}
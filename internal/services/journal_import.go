@@ -0,0 +1,375 @@
+/**
+ *  Journal imports. Entries written elsewhere are imported from a JSON array of {date, content}
+ *  objects or a CSV file with date and content columns, and saved with one batched repository write.
+ *
+ *  @file       journal_import.go
+ *  @package    services
+ *
+ *  @methods
+ *  - ImportJournals(ctx, userEmail, format, r, merge) - Imports journal entries and reports the outcome of every row.
+ *  - jsonImportRows(r) / csvImportRows(r)            - Read the rows of an import one at a time.
+ *  - readImportRows(next)                            - Reads every row, enforcing MaxJournalImportEntries.
+ *
+ *  @behaviors
+ *  - Files are read one row at a time, so a file over the limit is rejected once its first extra row is
+ *    read, without reading the rest. Nothing is saved if the file cannot be parsed or has too many rows.
+ *  - Each row is checked like a new entry: the date must be YYYY-MM-DD and not after today in the user's
+ *    time zone, and the content is required and limited to validate.MaxJournalContentLength characters.
+ *    Invalid rows are skipped with the reason; the other rows are still imported.
+ *  - A user has one entry per date, so a row whose date already has an entry, or appeared earlier in
+ *    the file, is skipped. With merge, its content is appended to that entry instead, after a blank
+ *    line, unless the entry already contains it or would become too long.
+ *  - CSV files need a header row naming the date and content columns, in any order and case; other
+ *    columns are ignored. JSON rows with other fields are accepted, the other fields are ignored.
+ *  - All new and merged entries are saved with one JournalRepository.SaveJournals call, encrypted if a
+ *    Cipher is configured. Entries that cannot be saved are reported as failed rows.
+ *
+ *  @errors
+ *  - ErrUnsupportedImportFormat: The format is not JournalImportJSON or JournalImportCSV.
+ *  - ErrInvalidImportFile: The file is not a JSON array or a CSV file with date and content columns.
+ *  - ErrTooManyImportEntries: The file has more than MaxJournalImportEntries rows.
+ *  - ErrImportTooLarge: The file is larger than MaxJournalImportSize; returned by the handler, which limits the body.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"proh2052-group6/pkg/dates"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/validate"
+)
+
+// Errors returned when importing journal entries.
+var (
+	ErrUnsupportedImportFormat = errors.New("Import must be JSON or CSV")
+	ErrInvalidImportFile       = errors.New("Invalid import file")
+	ErrTooManyImportEntries    = errors.New("An import can have at most 1000 entries")
+	ErrImportTooLarge          = errors.New("Import must be at most 20 MB")
+)
+
+// Supported journal import formats.
+const (
+	JournalImportJSON = "json"
+	JournalImportCSV  = "csv"
+)
+
+// MaxJournalImportEntries is the maximum number of rows of a journal import.
+const MaxJournalImportEntries = 1000
+
+// MaxJournalImportSize is the maximum size of a journal import file in bytes, enough for
+// MaxJournalImportEntries entries of ordinary length.
+const MaxJournalImportSize = 20 << 20
+
+// Outcomes of a row of a journal import.
+const (
+	importStatusImported = "imported"
+	importStatusMerged   = "merged"
+	importStatusSkipped  = "skipped"
+	importStatusFailed   = "failed"
+)
+
+// journalImportRow is a row read from an import file. Reason is set for rows that could not be decoded.
+type journalImportRow struct {
+	Date    string `json:"date"`
+	Content string `json:"content"`
+	Reason  string `json:"-"`
+}
+
+// ImportJournals reads journal entries from r in the given format and saves them for the user.
+// Rows are validated and deduplicated by date one by one, and the outcome of each is reported in the
+// result. With merge, rows for a date that already has an entry are appended to it instead of skipped.
+func (js *JournalService) ImportJournals(ctx context.Context, userEmail, format string, r io.Reader, merge bool) (*models.JournalImportResult, error) {
+	var next func() (journalImportRow, error)
+	var err error
+	switch format {
+	case JournalImportJSON:
+		next, err = jsonImportRows(r)
+	case JournalImportCSV:
+		next, err = csvImportRows(r)
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readImportRows(next)
+	if err != nil {
+		return nil, err
+	}
+
+	today, err := js.userToday(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.JournalImportResult{Rows: make([]models.JournalImportRowResult, len(rows))}
+	var from, to string
+	for i := range rows {
+		result.Rows[i] = models.JournalImportRowResult{Row: i + 1, Date: rows[i].Date}
+		if rows[i].Reason == "" {
+			rows[i].Reason = checkImportRow(&rows[i], today)
+		}
+		if rows[i].Reason != "" {
+			result.Rows[i].Status, result.Rows[i].Reason = importStatusSkipped, rows[i].Reason
+			continue
+		}
+		result.Rows[i].Date = rows[i].Date
+		if from == "" || rows[i].Date < from {
+			from = rows[i].Date
+		}
+		if rows[i].Date > to {
+			to = rows[i].Date
+		}
+	}
+	if from == "" {
+		return tallyImport(result), nil
+	}
+
+	existing, err := js.existingJournals(ctx, userEmail, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// pending holds the entry to save for each date, and sources the rows that went into it.
+	pending := make(map[string]*models.Journal)
+	sources := make(map[string][]int)
+	var batch []*models.Journal
+	for i, row := range rows {
+		if row.Reason != "" {
+			continue
+		}
+		journal, ok := pending[row.Date]
+		if !ok {
+			journal, ok = existing[row.Date]
+		}
+		outcome := &result.Rows[i]
+
+		if !ok {
+			journal = &models.Journal{Email: userEmail, Date: row.Date, Content: row.Content}
+			pending[row.Date] = journal
+			batch = append(batch, journal)
+			outcome.Status = importStatusImported
+		} else if !merge {
+			outcome.Status, outcome.Reason = importStatusSkipped, "A journal already exists for this date"
+			continue
+		} else if strings.Contains(journal.Content, row.Content) {
+			outcome.Status, outcome.Reason = importStatusSkipped, "The journal for this date already contains this content"
+			continue
+		} else {
+			merged := *journal
+			merged.Content = journal.Content + "\n\n" + row.Content
+			if err := validate.Journal(&merged); err != nil {
+				outcome.Status, outcome.Reason = importStatusSkipped, err.Error()
+				continue
+			}
+			if _, ok := pending[row.Date]; ok {
+				journal.Content = merged.Content
+			} else {
+				journal = &merged
+				pending[row.Date] = journal
+				batch = append(batch, journal)
+			}
+			outcome.Status = importStatusMerged
+		}
+		sources[row.Date] = append(sources[row.Date], i)
+	}
+
+	for i, err := range js.saveJournals(ctx, batch) {
+		if err == nil {
+			continue
+		}
+		for _, row := range sources[batch[i].Date] {
+			result.Rows[row].Status, result.Rows[row].Reason = importStatusFailed, err.Error()
+		}
+	}
+	return tallyImport(result), nil
+}
+
+// checkImportRow validates the date and content of a row like those of a new entry and normalizes
+// its date. It returns why the row is invalid, or "" if it is valid.
+func checkImportRow(row *journalImportRow, today time.Time) string {
+	date, err := dates.ParseJournalDate("date", strings.TrimSpace(row.Date), today)
+	if err != nil {
+		return err.Error()
+	}
+	row.Date = date.Format(dates.Layout)
+	if err := validate.Journal(&models.Journal{Date: row.Date, Content: row.Content}); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// existingJournals returns the user's decrypted entries between from and to, keyed by date.
+func (js *JournalService) existingJournals(ctx context.Context, userEmail, from, to string) (map[string]*models.Journal, error) {
+	existing := make(map[string]*models.Journal)
+	err := js.JournalRepo.StreamJournals(ctx, userEmail, from, to, func(journal models.Journal) error {
+		if err := js.openJournals(&journal); err != nil {
+			return err
+		}
+		existing[journal.Date] = &journal
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to check for existing journals: %w", err)
+	}
+	return existing, nil
+}
+
+// saveJournals stores the journals with one repository call, encrypted if a Cipher is configured,
+// and returns an error per journal. Created journals get their JournalID.
+func (js *JournalService) saveJournals(ctx context.Context, journals []*models.Journal) []error {
+	errs := make([]error, len(journals))
+	sealed := make([]*models.Journal, 0, len(journals))
+	positions := make([]int, 0, len(journals))
+	for i, journal := range journals {
+		stored := *journal
+		stored.Encryption = JournalPlaintext
+		stored.KeyEmail = ""
+		if js.Cipher != nil {
+			if errs[i] = js.Cipher.Seal(&stored); errs[i] != nil {
+				continue
+			}
+		}
+		sealed = append(sealed, &stored)
+		positions = append(positions, i)
+	}
+	if len(sealed) == 0 {
+		return errs
+	}
+
+	for i, err := range js.JournalRepo.SaveJournals(ctx, sealed) {
+		errs[positions[i]] = err
+		journals[positions[i]].JournalID = sealed[i].JournalID
+	}
+	return errs
+}
+
+// tallyImport counts the rows of the result by status.
+func tallyImport(result *models.JournalImportResult) *models.JournalImportResult {
+	for _, row := range result.Rows {
+		switch row.Status {
+		case importStatusImported:
+			result.Imported++
+		case importStatusMerged:
+			result.Merged++
+		case importStatusSkipped:
+			result.Skipped++
+		case importStatusFailed:
+			result.Failed++
+		}
+	}
+	return result
+}
+
+// readImportRows reads the rows returned by next until io.EOF. It fails with ErrTooManyImportEntries
+// as soon as a row past MaxJournalImportEntries is read.
+func readImportRows(next func() (journalImportRow, error)) ([]journalImportRow, error) {
+	var rows []journalImportRow
+	for {
+		row, err := next()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == MaxJournalImportEntries {
+			return nil, ErrTooManyImportEntries
+		}
+		rows = append(rows, row)
+	}
+}
+
+// jsonImportRows checks that r starts a JSON array and returns a function decoding its elements one
+// at a time. Elements that are not objects with string date and content get a Reason instead of
+// failing the import; invalid JSON does.
+func jsonImportRows(r io.Reader) (func() (journalImportRow, error), error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, fmt.Errorf("%w: expected a JSON array of {date, content} objects", ErrInvalidImportFile)
+	}
+	return func() (journalImportRow, error) {
+		if !decoder.More() {
+			if _, err := decoder.Token(); err != nil {
+				return journalImportRow{}, importReadError(err)
+			}
+			return journalImportRow{}, io.EOF
+		}
+		var row journalImportRow
+		if err := decoder.Decode(&row); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				return journalImportRow{}, importReadError(err)
+			}
+			// The decoder has read the whole element, so the next one can still be decoded.
+			return journalImportRow{Reason: "Entry must be an object with string date and content"}, nil
+		}
+		return row, nil
+	}, nil
+}
+
+// csvImportRows reads the header of the CSV file r and returns a function reading its rows one at a time.
+// Rows with too few columns get a Reason; unparsable CSV fails the import.
+func csvImportRows(r io.Reader) (func() (journalImportRow, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: expected a CSV header with date and content columns", ErrInvalidImportFile)
+	}
+	dateColumn, contentColumn := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "date":
+			dateColumn = i
+		case "content":
+			contentColumn = i
+		}
+	}
+	if dateColumn < 0 || contentColumn < 0 {
+		return nil, fmt.Errorf("%w: expected a CSV header with date and content columns", ErrInvalidImportFile)
+	}
+
+	return func() (journalImportRow, error) {
+		record, err := reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				return journalImportRow{}, io.EOF
+			}
+			return journalImportRow{}, importReadError(err)
+		}
+		if len(record) <= dateColumn || len(record) <= contentColumn {
+			return journalImportRow{Reason: "Row must have date and content columns"}, nil
+		}
+		return journalImportRow{Date: record[dateColumn], Content: record[contentColumn]}, nil
+	}, nil
+}
+
+// importReadError wraps an error reading an import file in ErrInvalidImportFile, keeping errors of
+// the underlying reader, such as a body over its size limit, so callers can still tell them apart.
+func importReadError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var parseErr *csv.ParseError
+	switch {
+	case errors.As(err, &syntaxErr), errors.As(err, &parseErr), err == io.ErrUnexpectedEOF:
+		return fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	default:
+		return err
+	}
+}
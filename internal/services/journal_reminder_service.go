@@ -0,0 +1,233 @@
+/**
+ *  JournalReminderService emails users who opted in a daily reminder to write in their journal, at the
+ *  time of day they chose, unless they have already written that day's entry.
+ *
+ *  @file       journal_reminder_service.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - JournalReminderServiceInterface - Defines the contract for the journal reminder scheduler.
+ *
+ *  @methods
+ *  - NewJournalReminderService(userRepo, journalRepo, emailService) - Creates a new JournalReminderService with default settings.
+ *  - Start(ctx)              - Runs the scheduler on a real ticker until ctx is cancelled.
+ *  - Run(ctx, ticks)         - Runs the scheduler on the given tick channel until ctx is cancelled.
+ *  - SendDueReminders(ctx)   - Sends the reminders that are due at the current time.
+ *
+ *  @dependencies
+ *  - repositories.UserRepository: Provides GetJournalReminderSubscribers and records the day each reminder was sent.
+ *  - repositories.JournalRepository: Provides GetJournalByDate, to skip users who already wrote today.
+ *  - EmailServiceInterface, EmailTemplateRenderer: Render and send the reminder emails.
+ *  - NotificationGate: Holds reminders back during quiet hours; may be nil.
+ *
+ *  @behaviors
+ *  - Only users with ReminderEnabled and a ReminderTime receive a reminder, and only once their email is verified.
+ *  - A reminder is due at ReminderTime in the user's time zone, or that of their country (UTC for
+ *    unknown countries). A reminder missed while the server was down is still sent within CatchUp of that time.
+ *  - Users with a journal entry for the local day, outside the trash, are not reminded.
+ *  - LastReminderSentDate records the local day each reminder was sent on, so a day is never sent twice.
+ *  - The reminders due on a run are sent together with SendBulk, and only the delivered ones are recorded.
+ *  - With a Gate, a reminder due during the user's quiet hours is held back until they end, or
+ *    dropped if they end on a later day. Either way the day is recorded as reminded.
+ *  - The clock (`Now`) and the tick source are injectable to allow testing without sleeping.
+ *
+ *  @errors
+ *  - ErrInvalidReminderTime: A reminder time is not HH:MM.
+ *  - ErrReminderTimeRequired: The reminder is enabled without a time.
+ *
+ *  @example
+ *  ```
+ *  journalReminderService := NewJournalReminderService(userRepo, journalRepo, emailService)
+ *  go journalReminderService.Start(ctx)
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+)
+
+// Default settings used by NewJournalReminderService.
+const (
+	DefaultJournalReminderInterval = time.Minute
+	DefaultJournalReminderCatchUp  = time.Hour
+)
+
+// Errors returned when the journal reminder settings are invalid.
+var (
+	ErrInvalidReminderTime  = errors.New("ReminderTime must be a time in HH:MM format")
+	ErrReminderTimeRequired = errors.New("A reminder time is required to enable the journal reminder")
+)
+
+// JournalReminderServiceInterface defines the operations of the journal reminder scheduler.
+type JournalReminderServiceInterface interface {
+	// Start runs the scheduler on a real ticker until the context is cancelled.
+	Start(ctx context.Context)
+
+	// Run runs the scheduler, checking for due reminders on every tick, until the context is cancelled.
+	Run(ctx context.Context, ticks <-chan time.Time)
+
+	// SendDueReminders sends the reminders due at the current time and returns how many were sent.
+	SendDueReminders(ctx context.Context) (int, error)
+}
+
+// JournalReminderService implements JournalReminderServiceInterface.
+type JournalReminderService struct {
+	UserRepo    repositories.UserRepository    // Repository used to find subscribers and record sent reminders.
+	JournalRepo repositories.JournalRepository // Repository used to check for today's entry.
+	Email       EmailServiceInterface          // Email service for sending reminders.
+	Templates   *EmailTemplateRenderer         // Renders the reminder emails.
+	Gate        *NotificationGate              // Holds reminders back during quiet hours; may be nil.
+	Interval    time.Duration                  // How often the scheduler checks for due reminders.
+	CatchUp     time.Duration                  // How long after the reminder time a missed reminder is still sent.
+	Now         func() time.Time               // Clock used by the scheduler; replaceable in tests.
+}
+
+// NewJournalReminderService initializes a JournalReminderService that checks every minute for due reminders.
+func NewJournalReminderService(userRepo repositories.UserRepository, journalRepo repositories.JournalRepository, emailService EmailServiceInterface) JournalReminderServiceInterface {
+	return &JournalReminderService{
+		UserRepo:    userRepo,
+		JournalRepo: journalRepo,
+		Email:       emailService,
+		Templates:   NewEmailTemplateRenderer(),
+		Interval:    DefaultJournalReminderInterval,
+		CatchUp:     DefaultJournalReminderCatchUp,
+		Now:         time.Now,
+	}
+}
+
+// Start runs the scheduler on a time.Ticker until the context is cancelled.
+func (jrs *JournalReminderService) Start(ctx context.Context) {
+	ticker := time.NewTicker(jrs.Interval)
+	defer ticker.Stop()
+	jrs.Run(ctx, ticker.C)
+}
+
+// Run checks for due reminders on every tick until the context is cancelled.
+func (jrs *JournalReminderService) Run(ctx context.Context, ticks <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+			if _, err := jrs.SendDueReminders(ctx); err != nil {
+				log.Printf("Failed to send journal reminders: %v", err)
+			}
+		}
+	}
+}
+
+// SendDueReminders emails every subscriber whose reminder time has been reached today, unless today's
+// reminder was already sent, more than CatchUp has passed since, or they have written today's entry.
+func (jrs *JournalReminderService) SendDueReminders(ctx context.Context) (int, error) {
+	users, err := jrs.UserRepo.GetJournalReminderSubscribers(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := jrs.Now()
+	var messages []EmailMessage
+	var days []string // Local day of each message, recorded once it is sent.
+	for _, user := range users {
+		if !user.ReminderEnabled || !user.IsVerified {
+			continue
+		}
+
+		location, _ := LocationForUser(user)
+		sendAt, err := reminderSendTime(now, location, user.ReminderTime)
+		if err != nil {
+			continue
+		}
+		today := sendAt.Format("2006-01-02")
+		if now.Before(sendAt) || !now.Before(sendAt.Add(jrs.CatchUp)) || user.LastReminderSentDate == today {
+			continue
+		}
+
+		journal, err := jrs.JournalRepo.GetJournalByDate(ctx, user.Email, today)
+		if err != nil {
+			log.Printf("Failed to check today's journal of %s: %v", user.Email, err)
+			continue
+		}
+		if journal != nil && journal.DeletedAt == nil {
+			continue
+		}
+
+		msg, err := jrs.Templates.JournalReminder(UserLocale(user), user.Username)
+		if err != nil {
+			log.Printf("Failed to prepare journal reminder for %s: %v", user.Email, err)
+			continue
+		}
+		msg.To = user.Email
+		endOfDay := time.Date(sendAt.Year(), sendAt.Month(), sendAt.Day()+1, 0, 0, 0, 0, location)
+		held, err := jrs.Gate.Hold(ctx, user, msg, endOfDay)
+		if err != nil {
+			log.Printf("Failed to hold back journal reminder for %s: %v", user.Email, err)
+			continue
+		}
+		if held {
+			jrs.markReminded(ctx, user.Email, today)
+			continue
+		}
+		messages = append(messages, msg)
+		days = append(days, today)
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	result, _ := jrs.Email.SendBulk(ctx, messages)
+	sent := 0
+	for i, recipient := range result.Recipients {
+		if recipient.Err != nil {
+			log.Printf("Failed to send journal reminder to %s: %v", recipient.To, recipient.Err)
+			continue
+		}
+		sent++
+		jrs.markReminded(ctx, recipient.To, days[i])
+	}
+
+	return sent, nil
+}
+
+// markReminded records day as the local day userEmail was last reminded on, so it is not reminded again.
+func (jrs *JournalReminderService) markReminded(ctx context.Context, userEmail, day string) {
+	if err := jrs.UserRepo.UpdateUser(ctx, userEmail, map[string]interface{}{"LastReminderSentDate": day}); err != nil {
+		log.Printf("Failed to mark journal reminder as sent for %s: %v", userEmail, err)
+	}
+}
+
+// reminderSendTime returns reminderTime (HH:MM) on the day of now in location.
+func reminderSendTime(now time.Time, location *time.Location, reminderTime string) (time.Time, error) {
+	clock, err := time.Parse("15:04", reminderTime)
+	if err != nil {
+		return time.Time{}, ErrInvalidReminderTime
+	}
+	local := now.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, location), nil
+}
+
+// normalizeReminderTime validates a reminder time and formats it as HH:MM; an empty time stays empty.
+func normalizeReminderTime(reminderTime string) (string, error) {
+	reminderTime = strings.TrimSpace(reminderTime)
+	if reminderTime == "" {
+		return "", nil
+	}
+	clock, err := time.Parse("15:04", reminderTime)
+	if err != nil {
+		return "", ErrInvalidReminderTime
+	}
+	return clock.Format("15:04"), nil
+}
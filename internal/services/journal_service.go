@@ -8,15 +8,51 @@
  *
  *  @methods
  *  - CreateJournal(ctx, journal)                - Creates a new journal entry after validation and formatting.
+ *  - UpsertJournal(ctx, journal)                - Creates a journal entry or replaces the one already written on that date.
  *  - GetJournal(ctx, userEmail, journalID)      - Retrieves a specific journal entry by user email and journal ID.
  *  - UpdateJournal(ctx, journal)                - Updates an existing journal entry.
- *  - DeleteJournal(ctx, userEmail, journalID)   - Deletes a journal entry by its ID.
+ *  - DeleteJournal(ctx, userEmail, journalID)   - Moves a journal entry to the trash.
+ *  - GetJournalTrash(ctx, userEmail)            - Lists the entries deleted within JournalTrashRetention.
+ *  - RestoreJournal(ctx, userEmail, journalID)  - Moves a journal entry out of the trash.
+ *  - PurgeDeletedJournals(ctx)                  - Permanently deletes entries kept in the trash past JournalTrashRetention.
+ *  - StartTrashPurge(ctx)                       - Purges the trash every PurgeInterval until the context is cancelled.
  *  - GetAllJournals(ctx, userEmail)             - Fetches all journal entries associated with a specific user.
+ *  - SearchJournals(ctx, userEmail, query, from, to, limit) - Searches journal entries by content and date range.
+ *  - ExportJournals(ctx, userEmail, format, from, to, w)    - Writes journal entries as JSON or Markdown.
+ *  - ImportJournals(ctx, userEmail, format, r, merge)       - Imports entries from JSON or CSV; see journal_import.go.
+ *  - GetJournalStats(ctx, userEmail, month)     - Counts a month's entries and moods and computes writing streaks.
+ *  - AddAttachment / GetAttachment / DeleteAttachment - Attach images to an entry; see journal_attachments.go.
+ *
+ *  @behaviors
+ *  - A user can have at most one journal entry per date; CreateJournal rejects duplicates.
+ *  - An entry cannot be dated after today in the user's time zone, their profile setting or else
+ *    their country's. Users whose zone is unknown may write up to the latest date it is anywhere.
+ *  - Invalid dates are rejected with a *dates.Error naming the field or query parameter.
+ *  - The content of an entry is required and limited to validate.MaxJournalContentLength characters.
+ *  - The mood of an entry is optional and must be one of JournalMoods; tags follow the event tag rules.
+ *  - New entries may name the writing prompt they answer with promptId, which must be a prompt of the
+ *    Prompts list if one is configured. The prompt of an entry is kept when it is updated.
+ *  - Writing streaks only count days within the requested month. The current streak ends today for the
+ *    current month, or on the last day of a past month, and is kept while today's entry is not written yet.
+ *  - Updating or deleting an entry the user does not have fails with repositories.ErrNotFound instead
+ *    of creating the entry or succeeding silently.
+ *  - Deleting an entry moves it to the trash, where it is hidden from every other method until it is
+ *    restored or purged JournalTrashRetention after its deletion. An entry cannot be restored onto a
+ *    date that already has an entry. Purging an entry also deletes its attachments from storage.
+ *  - Exports stream entries oldest first straight to the writer, so large journals are never held in memory.
+ *  - Markdown exports escape special characters in the content, so entries render as plain text.
+ *  - With a Cipher, content is encrypted before it is stored and decrypted when it is read; see
+ *    journal_encryption.go. Searches then read every entry in the date range and match the decrypted
+ *    content here, since the repository cannot search ciphertext.
  *
  *  @dependencies
  *  - repositories.JournalRepository: Interface for data persistence operations.
+ *  - repositories.UserRepository: Looks up the user's time zone.
+ *  - StorageServiceInterface: Stores the images attached to entries; may be nil, which disables attachments.
+ *  - JournalCipher: Encrypts the content of entries at rest; may be nil, which stores them in plaintext.
+ *  - PromptService: Knows the IDs of the writing prompts; may be nil, which accepts any prompt ID.
  *  - models.Journal: Defines the structure of a journal entry.
- *  - time.Parse: Used for validating and formatting date strings.
+ *  - dates: Parses and validates dates and date ranges.
  *
  *  @file      journal_service.go
  *  @project   DailyVerse
@@ -47,12 +83,19 @@
 package services
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"strings"
 	"time"
 
 	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/dates"
 	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/validate"
 )
 
 // JournalServiceInterface defines the contract for journal services.
@@ -60,59 +103,566 @@ type JournalServiceInterface interface {
 	// CreateJournal creates a new journal entry.
 	CreateJournal(ctx context.Context, journal *models.Journal) error
 
+	// UpsertJournal creates a journal entry, or updates the existing entry for the same date.
+	UpsertJournal(ctx context.Context, journal *models.Journal) error
+
 	// GetJournal retrieves a specific journal entry by user email and journal ID.
 	GetJournal(ctx context.Context, userEmail, journalID string) (*models.Journal, error)
 
 	// UpdateJournal updates an existing journal entry.
 	UpdateJournal(ctx context.Context, journal *models.Journal) error
 
-	// DeleteJournal deletes a journal entry by its ID and user email.
+	// DeleteJournal moves a journal entry to the trash by its ID and user email.
 	DeleteJournal(ctx context.Context, userEmail, journalID string) error
 
+	// GetJournalTrash lists a user's journal entries in the trash, most recently deleted first.
+	GetJournalTrash(ctx context.Context, userEmail string) ([]models.Journal, error)
+
+	// RestoreJournal moves a journal entry out of the trash and returns it.
+	RestoreJournal(ctx context.Context, userEmail, journalID string) (*models.Journal, error)
+
 	// GetAllJournals fetches all journal entries for a specific user.
 	GetAllJournals(ctx context.Context, userEmail string) ([]models.Journal, error)
+
+	// SearchJournals finds a user's journal entries by content and date range, newest first.
+	SearchJournals(ctx context.Context, userEmail, query, from, to string, limit int) ([]models.Journal, error)
+
+	// ExportJournals writes a user's journal entries within the optional date range to w,
+	// oldest first, as a JSON array ("json") or a Markdown document ("markdown").
+	ExportJournals(ctx context.Context, userEmail, format, from, to string, w io.Writer) error
+
+	// ImportJournals imports a user's journal entries from a JSON array ("json") or CSV file ("csv")
+	// of dates and contents, merging rows into existing entries of the same date if merge is set.
+	ImportJournals(ctx context.Context, userEmail, format string, r io.Reader, merge bool) (*models.JournalImportResult, error)
+
+	// GetJournalStats summarises a user's journal entries in a month (YYYY-MM, default the current month).
+	GetJournalStats(ctx context.Context, userEmail, month string) (*models.JournalStats, error)
+
+	// AddAttachment attaches an image to a journal entry and returns its metadata.
+	AddAttachment(ctx context.Context, userEmail, journalID string, data []byte) (*models.Attachment, error)
+
+	// GetAttachment reads an image attached to a journal entry and returns its metadata and content.
+	GetAttachment(ctx context.Context, userEmail, journalID, attachmentID string) (*models.Attachment, []byte, error)
+
+	// DeleteAttachment removes an image from a journal entry.
+	DeleteAttachment(ctx context.Context, userEmail, journalID, attachmentID string) error
 }
 
+// Journal moods, from best to worst.
+const (
+	MoodGreat   = "great"
+	MoodGood    = "good"
+	MoodNeutral = "neutral"
+	MoodBad     = "bad"
+	MoodAwful   = "awful"
+)
+
+// JournalMoods lists the moods a journal entry can be logged with.
+var JournalMoods = []string{MoodGreat, MoodGood, MoodNeutral, MoodBad, MoodAwful}
+
+// JournalTrashRetention is how long a deleted journal entry can be restored before it is purged.
+const JournalTrashRetention = 30 * 24 * time.Hour
+
 // JournalService implements JournalServiceInterface.
 type JournalService struct {
-	JournalRepo repositories.JournalRepository // Repository for journal data persistence.
+	JournalRepo   repositories.JournalRepository // Repository for journal data persistence.
+	UserRepo      repositories.UserRepository    // Repository for the user's time zone; nil treats every zone as unknown.
+	Storage       StorageServiceInterface        // Stores attached images; nil disables attachments.
+	Cipher        *JournalCipher                 // Encrypts content at rest; nil stores it in plaintext.
+	Prompts       *PromptService                 // Checks the prompt IDs of new entries; nil accepts any ID.
+	Now           func() time.Time               // Clock used for the current month, streak and trash; replaceable in tests.
+	PurgeInterval time.Duration                  // How often the trash purge deletes expired entries.
 }
 
 // NewJournalService initializes a new JournalService instance.
-func NewJournalService(journalRepo repositories.JournalRepository) JournalServiceInterface {
-	return &JournalService{JournalRepo: journalRepo}
+func NewJournalService(journalRepo repositories.JournalRepository, userRepo repositories.UserRepository) JournalServiceInterface {
+	return &JournalService{JournalRepo: journalRepo, UserRepo: userRepo, Now: time.Now, PurgeInterval: time.Hour}
 }
 
 // CreateJournal validates and creates a new journal entry.
-// Validates the date format (YYYY-MM-DD) and stores the journal in the repository.
+// Validates the date (YYYY-MM-DD, not in the future) and rejects the entry if the user already has a journal for that date.
 func (js *JournalService) CreateJournal(ctx context.Context, journal *models.Journal) error {
-	// Validate and format the journal's date.
-	journalDate, err := time.Parse("2006-01-02", journal.Date)
+	if err := validateJournal(journal); err != nil {
+		return err
+	}
+	if err := js.checkPrompt(journal); err != nil {
+		return err
+	}
+	existing, err := js.findJournalForDate(ctx, journal)
 	if err != nil {
-		return fmt.Errorf("Invalid date format. Please use YYYY-MM-DD.")
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("A journal already exists for this date")
 	}
-	journal.Date = journalDate.Format("2006-01-02")
 
 	// Delegate creation to the repository.
-	return js.JournalRepo.CreateJournal(ctx, journal)
+	return js.createJournal(ctx, journal)
+}
+
+// UpsertJournal creates a new journal entry, or overwrites the user's existing entry for the same date.
+func (js *JournalService) UpsertJournal(ctx context.Context, journal *models.Journal) error {
+	if err := validateJournal(journal); err != nil {
+		return err
+	}
+	existing, err := js.findJournalForDate(ctx, journal)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		if err := js.checkPrompt(journal); err != nil {
+			return err
+		}
+		return js.createJournal(ctx, journal)
+	}
+
+	journal.JournalID = existing.JournalID
+	journal.Attachments = existing.Attachments
+	journal.PromptID = existing.PromptID
+	return js.updateJournal(ctx, journal)
+}
+
+// checkPrompt trims the prompt ID of a new entry and checks that it names a prompt, if it has one.
+func (js *JournalService) checkPrompt(journal *models.Journal) error {
+	journal.PromptID = strings.TrimSpace(journal.PromptID)
+	if journal.PromptID != "" && js.Prompts != nil && !js.Prompts.IsPrompt(journal.PromptID) {
+		return ErrUnknownPrompt
+	}
+	return nil
+}
+
+// createJournal stores a new journal entry, encrypted if a Cipher is configured.
+func (js *JournalService) createJournal(ctx context.Context, journal *models.Journal) error {
+	return js.sealJournal(journal, func(sealed *models.Journal) error {
+		return js.JournalRepo.CreateJournal(ctx, sealed)
+	})
+}
+
+// updateJournal replaces a stored journal entry, encrypted if a Cipher is configured.
+func (js *JournalService) updateJournal(ctx context.Context, journal *models.Journal) error {
+	return js.sealJournal(journal, func(sealed *models.Journal) error {
+		return js.JournalRepo.UpdateJournal(ctx, sealed)
+	})
+}
+
+// validateJournal validates the content of a journal entry, then lowercases its mood and tags and validates them.
+func validateJournal(journal *models.Journal) error {
+	if err := validate.Journal(journal); err != nil {
+		return err
+	}
+
+	journal.Mood = strings.ToLower(strings.TrimSpace(journal.Mood))
+	if journal.Mood != "" && !isJournalMood(journal.Mood) {
+		return fmt.Errorf("Mood must be one of great, good, neutral, bad or awful")
+	}
+
+	tags, err := normalizeTagList(journal.Tags, "A journal entry can have at most 10 tags")
+	if err != nil {
+		return err
+	}
+	journal.Tags = tags
+
+	// Only DeleteJournal and RestoreJournal move entries in and out of the trash, and only
+	// AddAttachment and DeleteAttachment change the attachments.
+	journal.DeletedAt = nil
+	journal.Attachments = nil
+	return nil
+}
+
+// isJournalMood reports whether mood is one of JournalMoods.
+func isJournalMood(mood string) bool {
+	for _, m := range JournalMoods {
+		if m == mood {
+			return true
+		}
+	}
+	return false
+}
+
+// findJournalForDate validates and normalizes the journal's date, then looks up
+// the user's existing journal for that date.
+func (js *JournalService) findJournalForDate(ctx context.Context, journal *models.Journal) (*models.Journal, error) {
+	if err := js.checkJournalDate(ctx, journal); err != nil {
+		return nil, err
+	}
+
+	existing, err := js.JournalRepo.GetJournalByDate(ctx, journal.Email, journal.Date)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to check for an existing journal: %w", err)
+	}
+	return existing, nil
+}
+
+// checkJournalDate validates the journal's date against today in the user's time zone and normalizes it.
+func (js *JournalService) checkJournalDate(ctx context.Context, journal *models.Journal) error {
+	today, err := js.userToday(ctx, journal.Email)
+	if err != nil {
+		return err
+	}
+	date, err := dates.ParseJournalDate("date", journal.Date, today)
+	if err != nil {
+		return err
+	}
+	journal.Date = date.Format(dates.Layout)
+	return nil
+}
+
+// userToday returns today's date in the user's time zone. Users whose zone is unknown, and users that
+// cannot be found, get today in dates.EarliestZone, so no one is kept from writing the entry of their evening.
+func (js *JournalService) userToday(ctx context.Context, userEmail string) (time.Time, error) {
+	location := dates.EarliestZone
+	if js.UserRepo != nil {
+		user, err := js.UserRepo.GetUserByEmail(ctx, userEmail)
+		if isRepositoryFailure(err) {
+			return time.Time{}, err
+		}
+		if err == nil {
+			if userLocation, ok := LocationForUser(user); ok {
+				location = userLocation
+			}
+		}
+	}
+	return dates.Today(js.Now(), location), nil
 }
 
 // GetJournal retrieves a specific journal entry by user email and journal ID.
+// An entry in the trash is reported as repositories.ErrNotFound.
 func (js *JournalService) GetJournal(ctx context.Context, userEmail, journalID string) (*models.Journal, error) {
-	return js.JournalRepo.GetJournal(ctx, userEmail, journalID)
+	journal, err := js.JournalRepo.GetJournal(ctx, userEmail, journalID)
+	if err != nil {
+		return nil, err
+	}
+	if journal.DeletedAt != nil {
+		return nil, fmt.Errorf("Journal %w", repositories.ErrNotFound)
+	}
+	if err := js.openJournals(journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
 }
 
-// UpdateJournal updates an existing journal entry.
+// UpdateJournal validates and updates an existing journal entry of journal.Email.
+// It returns repositories.ErrNotFound, wrapped, if the user has no such entry outside the trash.
 func (js *JournalService) UpdateJournal(ctx context.Context, journal *models.Journal) error {
-	return js.JournalRepo.UpdateJournal(ctx, journal)
+	existing, err := js.GetJournal(ctx, journal.Email, journal.JournalID)
+	if err != nil {
+		return err
+	}
+	if err := validateJournal(journal); err != nil {
+		return err
+	}
+	journal.Attachments = existing.Attachments
+	journal.PromptID = existing.PromptID
+	if err := js.checkJournalDate(ctx, journal); err != nil {
+		return err
+	}
+	return js.updateJournal(ctx, journal)
 }
 
-// DeleteJournal deletes a journal entry by its ID and associated user email.
+// DeleteJournal moves a journal entry to the trash by its ID and associated user email.
+// It returns repositories.ErrNotFound, wrapped, if the user has no such entry outside the trash.
 func (js *JournalService) DeleteJournal(ctx context.Context, userEmail, journalID string) error {
-	return js.JournalRepo.DeleteJournal(ctx, userEmail, journalID)
+	journal, err := js.GetJournal(ctx, userEmail, journalID)
+	if err != nil {
+		return err
+	}
+	deletedAt := js.Now()
+	journal.DeletedAt = &deletedAt
+	return js.updateJournal(ctx, journal)
+}
+
+// GetJournalTrash lists the user's journal entries deleted within JournalTrashRetention,
+// most recently deleted first. Older entries are left for the purge.
+func (js *JournalService) GetJournalTrash(ctx context.Context, userEmail string) ([]models.Journal, error) {
+	deleted, err := js.JournalRepo.GetDeletedJournals(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve deleted journals: %w", err)
+	}
+
+	cutoff := js.Now().Add(-JournalTrashRetention)
+	trash := []models.Journal{}
+	for _, journal := range deleted {
+		if journal.DeletedAt.After(cutoff) {
+			if err := js.openJournals(&journal); err != nil {
+				return nil, err
+			}
+			trash = append(trash, journal)
+		}
+	}
+	return trash, nil
+}
+
+// RestoreJournal moves a journal entry out of the trash. It returns repositories.ErrNotFound,
+// wrapped, if the user has no such entry or it was deleted longer than JournalTrashRetention ago,
+// and fails if the entry is not in the trash or another entry was written on its date since.
+func (js *JournalService) RestoreJournal(ctx context.Context, userEmail, journalID string) (*models.Journal, error) {
+	journal, err := js.JournalRepo.GetJournal(ctx, userEmail, journalID)
+	if err != nil {
+		return nil, err
+	}
+	if journal.DeletedAt == nil {
+		return nil, fmt.Errorf("Journal is not in the trash")
+	}
+	if !journal.DeletedAt.After(js.Now().Add(-JournalTrashRetention)) {
+		return nil, fmt.Errorf("Journal %w", repositories.ErrNotFound)
+	}
+
+	existing, err := js.JournalRepo.GetJournalByDate(ctx, userEmail, journal.Date)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to check for an existing journal: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("A journal already exists for this date")
+	}
+
+	if err := js.openJournals(journal); err != nil {
+		return nil, err
+	}
+	journal.DeletedAt = nil
+	if err := js.updateJournal(ctx, journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// PurgeDeletedJournals permanently deletes the journal entries of all users that have been in the
+// trash longer than JournalTrashRetention, along with their attachments, and returns how many were deleted.
+func (js *JournalService) PurgeDeletedJournals(ctx context.Context) (int, error) {
+	purged, err := js.JournalRepo.PurgeDeletedJournals(ctx, js.Now().Add(-JournalTrashRetention))
+	for _, journal := range purged {
+		js.deleteAttachmentObjects(ctx, journal)
+	}
+	return len(purged), err
+}
+
+// StartTrashPurge purges expired journal entries every PurgeInterval until the context is cancelled.
+func (js *JournalService) StartTrashPurge(ctx context.Context) {
+	ticker := time.NewTicker(js.PurgeInterval)
+	defer ticker.Stop()
+	js.RunTrashPurge(ctx, ticker.C)
+}
+
+// RunTrashPurge purges expired journal entries on every tick until the context is cancelled.
+func (js *JournalService) RunTrashPurge(ctx context.Context, ticks <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+			if _, err := js.PurgeDeletedJournals(ctx); err != nil {
+				log.Printf("Failed to purge deleted journals: %v", err)
+			}
+		}
+	}
 }
 
 // GetAllJournals fetches all journal entries associated with a specific user.
 func (js *JournalService) GetAllJournals(ctx context.Context, userEmail string) ([]models.Journal, error) {
-	return js.JournalRepo.GetAllJournals(ctx, userEmail)
+	journals, err := js.JournalRepo.GetAllJournals(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	for i := range journals {
+		if err := js.openJournals(&journals[i]); err != nil {
+			return nil, err
+		}
+	}
+	return journals, nil
+}
+
+// SearchJournals validates the date range and limit, then searches the user's journal entries.
+// Dates must use the YYYY-MM-DD format; from and to are inclusive.
+func (js *JournalService) SearchJournals(ctx context.Context, userEmail, query, from, to string, limit int) ([]models.Journal, error) {
+	if _, _, err := dates.ParseRange(from, to); err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("limit must be a positive number")
+	}
+
+	query = strings.TrimSpace(query)
+	if js.Cipher == nil || query == "" {
+		journals, err := js.JournalRepo.SearchJournals(ctx, userEmail, query, from, to, limit)
+		if err != nil {
+			return nil, err
+		}
+		for i := range journals {
+			if err := js.openJournals(&journals[i]); err != nil {
+				return nil, err
+			}
+		}
+		return journals, nil
+	}
+
+	// Encrypted content can only be matched once it is decrypted.
+	candidates, err := js.JournalRepo.SearchJournals(ctx, userEmail, "", from, to, 0)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(query)
+	journals := []models.Journal{}
+	for _, journal := range candidates {
+		if limit > 0 && len(journals) == limit {
+			break
+		}
+		if err := js.openJournals(&journal); err != nil {
+			return nil, err
+		}
+		if strings.Contains(strings.ToLower(journal.Content), needle) {
+			journals = append(journals, journal)
+		}
+	}
+	return journals, nil
+}
+
+// GetJournalStats counts the user's journal entries and moods in month (YYYY-MM) and computes
+// their writing streaks. An empty month means the current month.
+func (js *JournalService) GetJournalStats(ctx context.Context, userEmail, month string) (*models.JournalStats, error) {
+	today := js.Now()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if month == "" {
+		month = today.Format("2006-01")
+	}
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid month format. Please use YYYY-MM.")
+	}
+	end := start.AddDate(0, 1, -1)
+
+	journals, err := js.JournalRepo.SearchJournals(ctx, userEmail, "", start.Format("2006-01-02"), end.Format("2006-01-02"), 0)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve journals: %w", err)
+	}
+
+	stats := &models.JournalStats{
+		Month:            month,
+		EntryCount:       len(journals),
+		MoodDistribution: make(map[string]int, len(JournalMoods)),
+	}
+	for _, mood := range JournalMoods {
+		stats.MoodDistribution[mood] = 0
+	}
+	written := make(map[string]bool, len(journals))
+	for _, journal := range journals {
+		written[journal.Date] = true
+		if isJournalMood(journal.Mood) {
+			stats.MoodDistribution[journal.Mood]++
+		}
+	}
+
+	// Walk the month day by day, tracking the run of consecutive days with an entry.
+	run := 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if written[day.Format("2006-01-02")] {
+			run++
+			if run > stats.LongestStreak {
+				stats.LongestStreak = run
+			}
+		} else {
+			run = 0
+		}
+	}
+
+	// The current streak ends today, or yesterday while today's entry has not been written yet.
+	streakEnd := end
+	if today.Before(end) {
+		streakEnd = today
+	}
+	if !written[streakEnd.Format("2006-01-02")] {
+		streakEnd = streakEnd.AddDate(0, 0, -1)
+	}
+	for day := streakEnd; !day.Before(start) && written[day.Format("2006-01-02")]; day = day.AddDate(0, 0, -1) {
+		stats.CurrentStreak++
+	}
+
+	return stats, nil
+}
+
+// Supported journal export formats.
+const (
+	JournalExportJSON     = "json"
+	JournalExportMarkdown = "markdown"
+)
+
+// ExportJournals validates the format and date range, then streams the user's journal entries to w.
+// Dates must use the YYYY-MM-DD format; from and to are inclusive. Nothing is written if validation fails.
+func (js *JournalService) ExportJournals(ctx context.Context, userEmail, format, from, to string, w io.Writer) error {
+	if format != JournalExportJSON && format != JournalExportMarkdown {
+		return fmt.Errorf("format must be 'json' or 'markdown'")
+	}
+	if _, _, err := dates.ParseRange(from, to); err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	var err error
+	if format == JournalExportJSON {
+		err = js.exportJSON(ctx, userEmail, from, to, out)
+	} else {
+		err = js.exportMarkdown(ctx, userEmail, from, to, out)
+	}
+	if err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// exportJSON writes the journal entries as a JSON array, one entry at a time.
+func (js *JournalService) exportJSON(ctx context.Context, userEmail, from, to string, w *bufio.Writer) error {
+	w.WriteString("[")
+	first := true
+	err := js.JournalRepo.StreamJournals(ctx, userEmail, from, to, func(journal models.Journal) error {
+		if err := js.openJournals(&journal); err != nil {
+			return err
+		}
+		entry, err := json.Marshal(journal)
+		if err != nil {
+			return err
+		}
+		if !first {
+			w.WriteString(",")
+		}
+		first = false
+		_, err = w.Write(entry)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.WriteString("]\n")
+	return err
+}
+
+// exportMarkdown writes the journal entries as a Markdown document with one section per date.
+func (js *JournalService) exportMarkdown(ctx context.Context, userEmail, from, to string, w *bufio.Writer) error {
+	w.WriteString("# Journal\n")
+	return js.JournalRepo.StreamJournals(ctx, userEmail, from, to, func(journal models.Journal) error {
+		if err := js.openJournals(&journal); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "\n## %s\n\n%s\n", journal.Date, escapeMarkdown(journal.Content))
+		return err
+	})
+}
+
+// markdownEscaper backslash-escapes characters that Markdown would otherwise treat as formatting.
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "{", "\\{", "}", "\\}",
+	"[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)", "#", "\\#", "+", "\\+",
+	"-", "\\-", "!", "\\!", "|", "\\|", "<", "\\<", ">", "\\>", "~", "\\~",
+)
+
+// escapeMarkdown escapes journal content so it renders as plain text. Line breaks are kept,
+// and a period after leading digits is escaped so a line is not read as a numbered list.
+func escapeMarkdown(content string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = markdownEscaper.Replace(line)
+		digits := len(line) - len(strings.TrimLeft(line, "0123456789"))
+		if digits > 0 && strings.HasPrefix(line[digits:], ".") {
+			line = line[:digits] + "\\" + line[digits:]
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
 }
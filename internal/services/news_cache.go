@@ -0,0 +1,129 @@
+/**
+ *  newsCache is an in-memory TTL cache for news API responses. Concurrent requests for the
+ *  same key share a single upstream call, so a burst of dashboard refreshes from one country
+ *  costs one request against the news API quota.
+ *
+ *  @methods
+ *  - get(ctx, key, refresh, fetch) - Returns the cached page for key, or calls fetch to load it.
+ *
+ *  @behaviors
+ *  - Entries expire after the cache's TTL and are evicted whenever a new upstream call starts.
+ *  - Failed fetches are not cached, so the next request retries the upstream.
+ *  - refresh skips the cached entry but still joins an upstream call that is already in flight.
+ *  - The shared upstream call runs detached from the request that started it, bounded by
+ *    newsFetchTimeout, so a client that disconnects does not fail the requests waiting with it.
+ *    Each request stops waiting when its own context is done, and the result is still cached.
+ *  - Safe for concurrent use.
+ *
+ *  @file      news_cache.go
+ *  @project   DailyVerse
+ *  @framework Go Business Logic Layer
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// newsFetchTimeout bounds a shared upstream call, which no longer ends with the request that started it.
+const newsFetchTimeout = 30 * time.Second
+
+// newsCacheKey identifies a news API request.
+type newsCacheKey struct {
+	countryCode  string
+	languageCode string
+	query        string
+	page         string
+}
+
+// newsCacheEntry holds a page of articles for a key until it expires.
+type newsCacheEntry struct {
+	page      *NewsPage
+	expiresAt time.Time
+}
+
+// newsCall is an upstream call in flight; done is closed once page and err are set.
+type newsCall struct {
+	done chan struct{}
+	page *NewsPage
+	err  error
+}
+
+// newsCache caches news API responses per key for ttl.
+type newsCache struct {
+	mutex   sync.Mutex
+	entries map[newsCacheKey]newsCacheEntry
+	calls   map[newsCacheKey]*newsCall
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// newNewsCache creates an empty cache whose entries live for ttl.
+func newNewsCache(ttl time.Duration, now func() time.Time) *newsCache {
+	return &newsCache{
+		entries: make(map[newsCacheKey]newsCacheEntry),
+		calls:   make(map[newsCacheKey]*newsCall),
+		ttl:     ttl,
+		now:     now,
+	}
+}
+
+// get returns the unexpired page cached for key. Otherwise, or if refresh is set, it starts fetch,
+// unless a call for the same key is already in flight, and waits for the result until ctx is done.
+func (c *newsCache) get(ctx context.Context, key newsCacheKey, refresh bool, fetch func(context.Context) (*NewsPage, error)) (*NewsPage, error) {
+	c.mutex.Lock()
+	now := c.now()
+	if entry, ok := c.entries[key]; ok && !refresh && now.Before(entry.expiresAt) {
+		c.mutex.Unlock()
+		return entry.page, nil
+	}
+	call, ok := c.calls[key]
+	if !ok {
+		call = &newsCall{done: make(chan struct{})}
+		c.calls[key] = call
+		c.evictExpired(now)
+		go c.fetch(context.WithoutCancel(ctx), key, call, fetch)
+	}
+	c.mutex.Unlock()
+
+	select {
+	case <-call.done:
+		return call.page, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch runs the upstream call for key within newsFetchTimeout, caches a successful result and
+// hands the result to every request waiting on call.
+func (c *newsCache) fetch(ctx context.Context, key newsCacheKey, call *newsCall, fetch func(context.Context) (*NewsPage, error)) {
+	ctx, cancel := context.WithTimeout(ctx, newsFetchTimeout)
+	defer cancel()
+	page, err := fetch(ctx)
+
+	c.mutex.Lock()
+	delete(c.calls, key)
+	call.page, call.err = page, err
+	if err == nil {
+		c.entries[key] = newsCacheEntry{page: page, expiresAt: c.now().Add(c.ttl)}
+	}
+	c.mutex.Unlock()
+	close(call.done)
+}
+
+// evictExpired removes every entry that has expired by now. The caller must hold the mutex.
+func (c *newsCache) evictExpired(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -1,25 +1,38 @@
 /**
  *  NewsService provides business logic for fetching news articles based on user-specific
  *  preferences or general search criteria. It integrates with an external news API and
- *  uses the UserRepository to fetch user details when needed.
+ *  uses the UserRepository to fetch user details when needed. Responses are cached in memory
+ *  per country, language and query to stay within the news API's rate limits.
  *
  *  @interface NewsServiceInterface
  *  @inherits None
  *
  *  @methods
- *  - FetchNews(ctx, userEmail, mode, country, query) - Fetches news articles from the news API based on the input parameters.
+ *  - FetchNews(ctx, userEmail, mode, country, language, query, page, refresh) - Fetches a page of news articles from the cache or the news API.
+ *
+ *  @behaviors
+ *  - Returns a *NewsAPIError if the news API reports an error, rate-limits the request or returns malformed data.
+ *  - Returns the API's nextPage token with the articles, so clients can request the following page.
+ *  - Cached responses are reused for CacheTTL (default 10 minutes); a CacheTTL of 0 disables caching.
+ *  - Concurrent requests for the same country, language, query and page share a single upstream call.
+ *  - refresh bypasses the cached response and replaces it with a fresh one.
+ *  - Local news is in the country's primary language unless language names another of its languages,
+ *    e.g. "fr" for Belgium. Unsupported languages fall back to the primary one.
+ *  - Countries may be given by name, common alias or ISO code.
  *
  *  @dependencies
  *  - repositories.UserRepository: Fetches user details to determine local news preferences.
+ *  - config.Config: Provides the news API key.
  *  - newsdata.io: External news API for fetching articles.
+ *  - httpclient.New: Builds the HTTP client, with timeouts and retries.
  *
  *  @example
  *  ```
  *  // Fetch general news
- *  articles, err := newsService.FetchNews(ctx, "", "general", "", "technology")
+ *  articles, err := newsService.FetchNews(ctx, "", "general", "", "", "technology", "", false)
  *
- *  // Fetch local news based on user profile
- *  articles, err := newsService.FetchNews(ctx, "user@example.com", "local", "", "")
+ *  // Fetch local news based on user profile, in French for a user in Belgium
+ *  articles, err := newsService.FetchNews(ctx, "user@example.com", "local", "", "fr", "", "", false)
  *  ```
  *
  *  @file      news_service.go
@@ -34,48 +47,90 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
+	"proh2052-group6/internal/config"
 	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/httpclient"
 )
 
 // NewsServiceInterface defines the contract for fetching news articles.
 type NewsServiceInterface interface {
-	// FetchNews retrieves news articles based on user and query parameters.
-	FetchNews(ctx context.Context, userEmail, mode, country, query string) ([]map[string]interface{}, error)
+	// FetchNews retrieves a page of news articles based on user and query parameters.
+	// If refresh is true, the cached response is bypassed.
+	FetchNews(ctx context.Context, userEmail, mode, country, language, query, page string, refresh bool) (*NewsPage, error)
+}
+
+// NewsPage is a single page of news articles.
+type NewsPage struct {
+	Items    []map[string]interface{} `json:"items"`    // Articles as returned by the news API.
+	NextPage string                   `json:"nextPage"` // Token for the following page; empty on the last page.
+}
+
+// NewsAPIError is returned when the news API fails or responds with an error.
+type NewsAPIError struct {
+	StatusCode int    // HTTP status code from the news API, or 0 if no response was received.
+	Code       string // Error code reported by the news API, e.g. "RateLimitExceeded".
+	Message    string // Error message reported by the news API.
+}
+
+// Error implements the error interface.
+func (e *NewsAPIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("News API error (%s): %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("News API error: %s", e.Message)
+}
+
+// RateLimited reports whether the news API rejected the request because the quota was exceeded.
+func (e *NewsAPIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.Code == "RateLimitExceeded"
 }
 
 // NewsService implements the NewsServiceInterface and interacts with the external news API.
 type NewsService struct {
-	UserRepo                  repositories.UserRepository          // Repository for fetching user data.
-	HTTPClient                *http.Client                         // HTTP client for making API requests.
-	NewsAPIURL                string                               // Base URL of the news API.
-	GetCountryAndLanguageCode func(string) (string, string, error) // Helper function to map country names to codes.
+	UserRepo            repositories.UserRepository            // Repository for fetching user data.
+	HTTPClient          *http.Client                           // HTTP client for making API requests.
+	NewsAPIURL          string                                 // Base URL of the news API.
+	APIKey              string                                 // Key sent with every news API request.
+	GetCountryLanguages func(string) (string, []string, error) // Helper function to map countries to their code and languages.
+	CacheTTL            time.Duration                          // How long responses are cached; 0 disables caching.
+	Now                 func() time.Time                       // Clock used for cache expiry; defaults to time.Now.
+
+	cacheOnce sync.Once
+	cache     *newsCache
 }
 
-// NewNewsService initializes a NewsService instance with default values.
-func NewNewsService(userRepo repositories.UserRepository) NewsServiceInterface {
+// DefaultNewsCacheTTL is how long news API responses are cached by default.
+const DefaultNewsCacheTTL = 10 * time.Minute
+
+// NewNewsService initializes a NewsService instance with default values, using the news API key in cfg.
+func NewNewsService(userRepo repositories.UserRepository, cfg *config.Config) NewsServiceInterface {
 	return &NewsService{
-		UserRepo:                  userRepo,
-		HTTPClient:                http.DefaultClient,
-		NewsAPIURL:                "https://newsdata.io/api/1/news",
-		GetCountryAndLanguageCode: GetCountryAndLanguageCode,
+		UserRepo:            userRepo,
+		APIKey:              cfg.NewsAPIKey,
+		HTTPClient:          httpclient.New(httpclient.DefaultConfig()),
+		NewsAPIURL:          "https://newsdata.io/api/1/news",
+		GetCountryLanguages: GetCountryLanguages,
+		CacheTTL:            DefaultNewsCacheTTL,
+		Now:                 time.Now,
 	}
 }
 
-// Global variable for the news API key, sourced from environment variables.
-var newsAPIKey = os.Getenv("NEWS_API_KEY")
-
 // FetchNews fetches news articles based on the input parameters.
 // Parameters:
 // - ctx: Request context for handling deadlines and cancellations.
 // - userEmail: The email of the user requesting news (used for local news preferences).
 // - mode: Specifies the type of news (e.g., "local").
 // - country: The country for which news is requested.
+// - language: One of the country's language codes for local news; empty for its primary language.
 // - query: Search query for filtering news articles.
-func (ns *NewsService) FetchNews(ctx context.Context, userEmail, mode, country, query string) ([]map[string]interface{}, error) {
-	var url string
-
+// - page: The nextPage token from a previous response, or empty for the first page.
+// - refresh: Bypasses the cached response for the same country, language, query and page.
+func (ns *NewsService) FetchNews(ctx context.Context, userEmail, mode, country, language, query, page string, refresh bool) (*NewsPage, error) {
 	// Handle "local" mode by fetching the user's country if not provided.
 	if mode == "local" && country == "" {
 		user, err := ns.UserRepo.GetUserByEmail(ctx, userEmail)
@@ -90,38 +145,102 @@ func (ns *NewsService) FetchNews(ctx context.Context, userEmail, mode, country,
 		}
 	}
 
-	// Construct the API URL for local or general news.
+	// Determine the country and language for local or general news.
+	key := newsCacheKey{languageCode: "en", query: query, page: page}
 	if mode == "local" && country != "" {
-		countryCode, languageCode, err := ns.GetCountryAndLanguageCode(country)
+		countryCode, languages, err := ns.GetCountryLanguages(country)
 		if err != nil {
 			return nil, fmt.Errorf("Invalid country for local news: %v", err)
 		}
-		url = fmt.Sprintf("%s?country=%s&language=%s&apikey=%s", ns.NewsAPIURL, countryCode, languageCode, newsAPIKey)
-	} else {
-		url = fmt.Sprintf("%s?language=en&apikey=%s", ns.NewsAPIURL, newsAPIKey)
+		key.countryCode, key.languageCode = countryCode, newsLanguage(languages, language)
+	}
+
+	if ns.CacheTTL <= 0 {
+		return ns.requestNews(ctx, key)
+	}
+	ns.cacheOnce.Do(func() {
+		now := ns.Now
+		if now == nil {
+			now = time.Now
+		}
+		ns.cache = newNewsCache(ns.CacheTTL, now)
+	})
+	return ns.cache.get(ctx, key, refresh, func(ctx context.Context) (*NewsPage, error) {
+		return ns.requestNews(ctx, key)
+	})
+}
+
+// newsLanguage returns language if it is one of languages, and the primary language otherwise.
+func newsLanguage(languages []string, language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	for _, supported := range languages {
+		if supported == language {
+			return language
+		}
+	}
+	if len(languages) == 0 {
+		return "en"
 	}
+	return languages[0]
+}
+
+// requestNews calls the news API for the given country, language, query and page.
+func (ns *NewsService) requestNews(ctx context.Context, key newsCacheKey) (*NewsPage, error) {
+	params := url.Values{}
+	if key.countryCode != "" {
+		params.Set("country", key.countryCode)
+	}
+	params.Set("language", key.languageCode)
+	params.Set("apikey", ns.APIKey)
 
-	// Append query parameter if a search term is provided.
-	if query != "" {
-		url += fmt.Sprintf("&q=%s", query)
+	// Append query and page parameters if provided.
+	if key.query != "" {
+		params.Set("q", key.query)
+	}
+	if key.page != "" {
+		params.Set("page", key.page)
 	}
 
-	// Send the HTTP GET request to the news API.
-	resp, err := ns.HTTPClient.Get(url)
+	// Send the HTTP GET request to the news API, cancelled along with the caller's request.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ns.NewsAPIURL+"?"+params.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to fetch news")
 	}
+	resp, err := ns.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &NewsAPIError{Message: "Failed to reach the news API"}
+	}
 	defer resp.Body.Close()
 
-	// Parse the JSON response from the news API.
+	// Parse the JSON response from the news API. On success, results is an array of articles;
+	// on failure, it is an object with the error message and code.
 	var result struct {
-		Status       string                   `json:"status"`
-		TotalResults int                      `json:"totalResults"`
-		Results      []map[string]interface{} `json:"results"`
+		Status       string          `json:"status"`
+		TotalResults int             `json:"totalResults"`
+		Results      json.RawMessage `json:"results"`
+		NextPage     string          `json:"nextPage"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("Failed to parse news data")
+		return nil, &NewsAPIError{StatusCode: resp.StatusCode, Message: "Failed to parse news data"}
+	}
+
+	if result.Status != "success" || resp.StatusCode != http.StatusOK {
+		apiErr := &NewsAPIError{StatusCode: resp.StatusCode, Message: "Unexpected response from the news API"}
+		var details struct {
+			Message string `json:"message"`
+			Code    string `json:"code"`
+		}
+		if json.Unmarshal(result.Results, &details) == nil && details.Message != "" {
+			apiErr.Code, apiErr.Message = details.Code, details.Message
+		}
+		return nil, apiErr
 	}
 
-	return result.Results, nil
+	page := &NewsPage{Items: []map[string]interface{}{}, NextPage: result.NextPage}
+	if len(result.Results) > 0 {
+		if err := json.Unmarshal(result.Results, &page.Items); err != nil {
+			return nil, &NewsAPIError{StatusCode: resp.StatusCode, Message: "Failed to parse news data"}
+		}
+	}
+	return page, nil
 }
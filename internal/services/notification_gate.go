@@ -0,0 +1,236 @@
+/**
+ *  NotificationGate keeps notification emails from reaching users during their quiet hours. Every
+ *  feature that emails notifications asks the gate first; during the recipient's quiet hours the
+ *  gate holds the email back in the pending deliveries, and its scheduler sends it once they end.
+ *
+ *  @file       notification_gate.go
+ *  @package    services
+ *
+ *  @methods
+ *  - NewNotificationGate(pendingRepo, emailService) - Creates a new NotificationGate with default settings.
+ *  - Hold(ctx, recipient, msg, expiresAt)  - Holds an email back if the recipient is in their quiet hours.
+ *  - Start(ctx)                            - Runs the scheduler on a real ticker until ctx is cancelled.
+ *  - Run(ctx, ticks)                       - Runs the scheduler on the given tick channel until ctx is cancelled.
+ *  - FlushDue(ctx)                         - Sends the held emails whose quiet hours have ended.
+ *  - QuietUntil(user, now)                 - Returns when the quiet hours the user is in at now end.
+ *  - QuietWindowEnd(now, location, start, end) - Returns when the quiet hours from start to end around now end.
+ *
+ *  @dependencies
+ *  - repositories.PendingDeliveryRepository: Stores the held emails until they are due.
+ *  - EmailServiceInterface: Sends the held emails once their quiet hours have ended.
+ *
+ *  @behaviors
+ *  - Quiet hours run from QuietStart to QuietEnd (HH:MM) in the user's time zone, or that of their
+ *    country (UTC for unknown countries). A window whose end is before its start wraps past
+ *    midnight, so 22:00 to 07:00 covers the night. The start is inside the window, the end is not.
+ *  - An email held back is stored with the end of the window as its DeliverAt. If the window ends
+ *    after the expiresAt the caller gives, e.g. an event reminder for an event that has started by
+ *    then, it is dropped instead.
+ *  - The scheduler sends the due emails together with SendBulk; emails that fail stay pending and
+ *    are retried on the next run.
+ *  - Only notifications go through the gate. OTPs, password resets and email change codes are sent
+ *    directly, since the user is waiting for them.
+ *  - A nil gate holds nothing, so features can be used without one.
+ *  - The clock (`Now`) and the tick source are injectable to allow testing without sleeping.
+ *
+ *  @errors
+ *  - ErrInvalidQuietHours: QuietStart or QuietEnd is not HH:MM, or they are the same time.
+ *  - ErrQuietHoursIncomplete: Only one of QuietStart and QuietEnd is set.
+ *
+ *  @example
+ *  ```
+ *  gate := NewNotificationGate(pendingDeliveryRepo, emailService)
+ *  friendService.(*FriendService).Gate = gate
+ *  go gate.Start(ctx)
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// Default settings used by NewNotificationGate.
+const (
+	DefaultNotificationGateInterval  = time.Minute
+	DefaultNotificationGateBatchSize = 100
+)
+
+// Errors returned when the quiet hours are invalid.
+var (
+	ErrInvalidQuietHours    = errors.New("QuietStart and QuietEnd must be two different times in HH:MM format")
+	ErrQuietHoursIncomplete = errors.New("QuietStart and QuietEnd must be set together")
+)
+
+// NotificationGate holds notification emails back during their recipients' quiet hours.
+type NotificationGate struct {
+	Pending   repositories.PendingDeliveryRepository // Repository the held emails wait in.
+	Email     EmailServiceInterface                  // Email service for sending the held emails.
+	Interval  time.Duration                          // How often the scheduler checks for due emails.
+	BatchSize int                                    // Most held emails sent on one run.
+	Now       func() time.Time                       // Clock used for quiet hours; replaceable in tests.
+}
+
+// NewNotificationGate initializes a NotificationGate that checks every minute for held emails to send.
+func NewNotificationGate(pendingRepo repositories.PendingDeliveryRepository, emailService EmailServiceInterface) *NotificationGate {
+	return &NotificationGate{
+		Pending:   pendingRepo,
+		Email:     emailService,
+		Interval:  DefaultNotificationGateInterval,
+		BatchSize: DefaultNotificationGateBatchSize,
+		Now:       time.Now,
+	}
+}
+
+// Hold reports whether msg must not be sent to recipient now. During the recipient's quiet hours it
+// stores msg to be sent when they end, or drops it if they end after expiresAt; a zero expiresAt
+// never expires. If msg could not be stored, Hold returns an error and the caller should try again later.
+func (g *NotificationGate) Hold(ctx context.Context, recipient *models.User, msg EmailMessage, expiresAt time.Time) (bool, error) {
+	if g == nil {
+		return false, nil
+	}
+	now := g.Now()
+	deliverAt, quiet := QuietUntil(recipient, now)
+	if !quiet {
+		return false, nil
+	}
+	if !expiresAt.IsZero() && deliverAt.After(expiresAt) {
+		return true, nil
+	}
+
+	delivery := &models.PendingDelivery{
+		Email:     recipient.Email,
+		Subject:   msg.Subject,
+		Text:      msg.Text,
+		HTML:      msg.HTML,
+		DeliverAt: deliverAt,
+		CreatedAt: now,
+	}
+	if err := g.Pending.CreatePendingDelivery(ctx, delivery); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// Start runs the scheduler on a time.Ticker until the context is cancelled.
+func (g *NotificationGate) Start(ctx context.Context) {
+	ticker := time.NewTicker(g.Interval)
+	defer ticker.Stop()
+	g.Run(ctx, ticker.C)
+}
+
+// Run sends the due held emails on every tick until the context is cancelled.
+func (g *NotificationGate) Run(ctx context.Context, ticks <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+			if _, err := g.FlushDue(ctx); err != nil {
+				log.Printf("Failed to send held notification emails: %v", err)
+			}
+		}
+	}
+}
+
+// FlushDue sends up to BatchSize held emails whose quiet hours have ended, deletes the delivered
+// ones and returns how many were sent.
+func (g *NotificationGate) FlushDue(ctx context.Context) (int, error) {
+	deliveries, err := g.Pending.GetDuePendingDeliveries(ctx, g.Now(), g.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	messages := make([]EmailMessage, len(deliveries))
+	for i, delivery := range deliveries {
+		messages[i] = EmailMessage{To: delivery.Email, Subject: delivery.Subject, Text: delivery.Text, HTML: delivery.HTML}
+	}
+	result, _ := g.Email.SendBulk(ctx, messages)
+	sent := 0
+	for i, recipient := range result.Recipients {
+		if recipient.Err != nil {
+			log.Printf("Failed to send held notification email to %s: %v", recipient.To, recipient.Err)
+			continue
+		}
+		sent++
+
+		if err := g.Pending.DeletePendingDelivery(ctx, deliveries[i].ID); err != nil {
+			log.Printf("Failed to delete held notification email %s: %v", deliveries[i].ID, err)
+		}
+	}
+	return sent, nil
+}
+
+// QuietUntil returns the end of the quiet hours user is in at now, in their time zone, and false if
+// they are not in their quiet hours or have none.
+func QuietUntil(user *models.User, now time.Time) (time.Time, bool) {
+	if user == nil || user.QuietStart == "" || user.QuietEnd == "" {
+		return time.Time{}, false
+	}
+	location, _ := LocationForUser(user)
+	return QuietWindowEnd(now, location, user.QuietStart, user.QuietEnd)
+}
+
+// QuietWindowEnd returns the end of the quiet hours from start to end (HH:MM in location) that
+// contain now, and false if now is outside them. An end before the start wraps past midnight.
+func QuietWindowEnd(now time.Time, location *time.Location, start, end string) (time.Time, bool) {
+	startClock, startErr := time.Parse("15:04", start)
+	endClock, endErr := time.Parse("15:04", end)
+	if startErr != nil || endErr != nil || startClock.Equal(endClock) {
+		return time.Time{}, false
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := startClock.Hour()*60 + startClock.Minute()
+	endMinute := endClock.Hour()*60 + endClock.Minute()
+	days := 0 // Days from today to the end of the window.
+	switch {
+	case startMinute < endMinute:
+		if minute < startMinute || minute >= endMinute {
+			return time.Time{}, false
+		}
+	case minute >= startMinute:
+		days = 1 // Before midnight in a window that wraps; it ends tomorrow.
+	case minute >= endMinute:
+		return time.Time{}, false
+	}
+	return time.Date(local.Year(), local.Month(), local.Day()+days, endClock.Hour(), endClock.Minute(), 0, 0, location), true
+}
+
+// normalizeQuietHours validates the quiet hours and formats them as HH:MM. Both must be set or both empty.
+func normalizeQuietHours(start, end string) (string, string, error) {
+	start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+	if start == "" && end == "" {
+		return "", "", nil
+	}
+	if start == "" || end == "" {
+		return "", "", ErrQuietHoursIncomplete
+	}
+	startClock, err := time.Parse("15:04", start)
+	if err != nil {
+		return "", "", ErrInvalidQuietHours
+	}
+	endClock, err := time.Parse("15:04", end)
+	if err != nil || endClock.Equal(startClock) {
+		return "", "", ErrInvalidQuietHours
+	}
+	return startClock.Format("15:04"), endClock.Format("15:04"), nil
+}
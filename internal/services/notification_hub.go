@@ -0,0 +1,121 @@
+/**
+ *  NotificationHub keeps track of the WebSocket connections of each user and pushes
+ *  notifications, such as new friend requests and event invitations, to them.
+ *
+ *  @file       notification_hub.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - NotificationHubInterface: Defines the contract for publishing and subscribing to notifications.
+ *
+ *  @methods
+ *  - NewNotificationHub()                   - Initializes an empty NotificationHub.
+ *  - Publish(userEmail, notification)       - Pushes a notification to every connection of a user.
+ *  - Subscribe(userEmail)                   - Registers a connection and returns its subscription.
+ *  - Unsubscribe(subscription)              - Removes a connection and closes its channel.
+ *
+ *  @behaviors
+ *  - A user can have several connections, e.g. one per browser tab; each receives every notification.
+ *  - Publish never blocks: notifications for users without connections are dropped, and so are
+ *    notifications for a connection whose buffer of notificationBufferSize messages is full.
+ *  - Safe for concurrent use.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"sync"
+	"time"
+
+	"proh2052-group6/pkg/models"
+)
+
+// notificationBufferSize is the number of notifications queued per connection before new ones are dropped.
+const notificationBufferSize = 16
+
+// Notification types pushed by the services.
+const (
+	NotificationFriendRequest   = "friend_request"
+	NotificationFriendAccepted  = "friend_accepted"
+	NotificationEventInvitation = "event_invitation"
+	NotificationEventCancelled  = "event_cancelled"
+)
+
+// NotificationHubInterface defines methods for pushing notifications to connected users.
+type NotificationHubInterface interface {
+	Publish(userEmail string, notification models.Notification)
+	Subscribe(userEmail string) *NotificationSubscription
+	Unsubscribe(subscription *NotificationSubscription)
+}
+
+// NotificationSubscription is a single connection's feed of notifications.
+type NotificationSubscription struct {
+	UserEmail     string                   // Email of the connected user.
+	Notifications chan models.Notification // Receives the user's notifications; closed by Unsubscribe.
+}
+
+// NotificationHub implements NotificationHubInterface.
+type NotificationHub struct {
+	mu            sync.RWMutex
+	subscriptions map[string]map[*NotificationSubscription]struct{} // Connections keyed by user email.
+}
+
+// NewNotificationHub initializes an empty NotificationHub.
+func NewNotificationHub() NotificationHubInterface {
+	return &NotificationHub{subscriptions: make(map[string]map[*NotificationSubscription]struct{})}
+}
+
+// Publish pushes a notification to every connection of userEmail without blocking.
+// CreatedAt is set to the current time if it is zero.
+func (nh *NotificationHub) Publish(userEmail string, notification models.Notification) {
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	nh.mu.RLock()
+	defer nh.mu.RUnlock()
+	for subscription := range nh.subscriptions[userEmail] {
+		select {
+		case subscription.Notifications <- notification:
+		default:
+			// The connection is not keeping up; drop the notification rather than block the caller.
+		}
+	}
+}
+
+// Subscribe registers a new connection for userEmail.
+func (nh *NotificationHub) Subscribe(userEmail string) *NotificationSubscription {
+	subscription := &NotificationSubscription{
+		UserEmail:     userEmail,
+		Notifications: make(chan models.Notification, notificationBufferSize),
+	}
+
+	nh.mu.Lock()
+	defer nh.mu.Unlock()
+	if nh.subscriptions[userEmail] == nil {
+		nh.subscriptions[userEmail] = make(map[*NotificationSubscription]struct{})
+	}
+	nh.subscriptions[userEmail][subscription] = struct{}{}
+	return subscription
+}
+
+// Unsubscribe removes a connection and closes its channel. Calling it more than once is a no-op.
+func (nh *NotificationHub) Unsubscribe(subscription *NotificationSubscription) {
+	nh.mu.Lock()
+	defer nh.mu.Unlock()
+	subscriptions := nh.subscriptions[subscription.UserEmail]
+	if _, ok := subscriptions[subscription]; !ok {
+		return
+	}
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(nh.subscriptions, subscription.UserEmail)
+	}
+	close(subscription.Notifications)
+}
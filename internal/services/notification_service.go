@@ -0,0 +1,140 @@
+/**
+ *  NotificationService stores notifications in each user's inbox and pushes them to the user's
+ *  open WebSocket connections, so users see them immediately when online and later when offline.
+ *
+ *  @file       notification_service.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - NotificationServiceInterface: Defines the contract for sending and reading notifications.
+ *
+ *  @methods
+ *  - NewNotificationService(notificationRepo, hub)         - Initializes a new NotificationService.
+ *  - Notify(ctx, userEmail, notification)                  - Stores a notification and pushes it to the user.
+ *  - ListNotifications(ctx, userEmail, unreadOnly, limit)  - Retrieves a user's notifications, newest first.
+ *  - MarkRead(ctx, userEmail, notificationID)              - Marks a single notification as read.
+ *  - MarkAllRead(ctx, userEmail)                           - Marks all of a user's notifications as read.
+ *
+ *  @behaviors
+ *  - Notify never fails the operation that triggered it: storage failures are logged, and the
+ *    notification is still pushed to connected clients.
+ *  - A limit outside 1..MaxNotificationLimit is clamped; 0 means DefaultNotificationLimit.
+ *
+ *  @errors
+ *  - ErrNotificationNotFound: MarkRead was given an ID the user has no notification for.
+ *
+ *  @dependencies
+ *  - repositories.NotificationRepository: Stores the notifications.
+ *  - NotificationHubInterface: Pushes notifications to connected clients; may be nil.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// Limits on the number of notifications returned by ListNotifications.
+const (
+	DefaultNotificationLimit = 20
+	MaxNotificationLimit     = 100
+)
+
+// ErrNotificationNotFound is returned when a user has no notification with the given ID.
+var ErrNotificationNotFound = errors.New("Notification not found")
+
+// NotificationServiceInterface defines methods for sending and reading notifications.
+type NotificationServiceInterface interface {
+	Notify(ctx context.Context, userEmail string, notification models.Notification)
+	ListNotifications(ctx context.Context, userEmail string, unreadOnly bool, limit int) ([]models.Notification, error)
+	MarkRead(ctx context.Context, userEmail, notificationID string) error
+	MarkAllRead(ctx context.Context, userEmail string) error
+}
+
+// NotificationService implements NotificationServiceInterface.
+type NotificationService struct {
+	NotificationRepo repositories.NotificationRepository // Repository for the users' inboxes.
+	Hub              NotificationHubInterface            // Pushes notifications to connected clients; may be nil.
+	Now              func() time.Time                    // Clock used for CreatedAt; replaceable in tests.
+}
+
+// NewNotificationService initializes a new NotificationService.
+func NewNotificationService(notificationRepo repositories.NotificationRepository, hub NotificationHubInterface) NotificationServiceInterface {
+	return &NotificationService{
+		NotificationRepo: notificationRepo,
+		Hub:              hub,
+		Now:              time.Now,
+	}
+}
+
+// Notify stores an unread notification in the user's inbox and pushes it to their open connections.
+func (ns *NotificationService) Notify(ctx context.Context, userEmail string, notification models.Notification) {
+	notification.Email = userEmail
+	notification.Read = false
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = ns.Now()
+	}
+
+	if err := ns.NotificationRepo.CreateNotification(ctx, &notification); err != nil {
+		log.Printf("Failed to store notification for %s: %v", userEmail, err)
+	}
+	if ns.Hub != nil {
+		ns.Hub.Publish(userEmail, notification)
+	}
+}
+
+// ListNotifications retrieves up to limit of a user's notifications, newest first.
+func (ns *NotificationService) ListNotifications(ctx context.Context, userEmail string, unreadOnly bool, limit int) ([]models.Notification, error) {
+	if limit <= 0 {
+		limit = DefaultNotificationLimit
+	}
+	if limit > MaxNotificationLimit {
+		limit = MaxNotificationLimit
+	}
+
+	notifications, err := ns.NotificationRepo.ListNotifications(ctx, userEmail, unreadOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+// MarkRead marks a single notification of the user as read.
+func (ns *NotificationService) MarkRead(ctx context.Context, userEmail, notificationID string) error {
+	err := ns.NotificationRepo.MarkRead(ctx, userEmail, notificationID)
+	if errors.Is(err, repositories.ErrNotificationNotFound) {
+		return ErrNotificationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to mark notification as read: %w", err)
+	}
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications as read.
+func (ns *NotificationService) MarkAllRead(ctx context.Context, userEmail string) error {
+	if err := ns.NotificationRepo.MarkAllRead(ctx, userEmail); err != nil {
+		return fmt.Errorf("Failed to mark notifications as read: %w", err)
+	}
+	return nil
+}
+
+// sendNotification notifies a user through notifications, which may be nil when notifications are not in use.
+func sendNotification(ctx context.Context, notifications NotificationServiceInterface, userEmail string, notification models.Notification) {
+	if notifications != nil {
+		notifications.Notify(ctx, userEmail, notification)
+	}
+}
@@ -0,0 +1,138 @@
+/**
+ *  OTPManager generates and checks the one-time passwords that verify email addresses and reset
+ *  passwords, and OTPDeliveryChannel sends them to the user, so UserService neither hardcodes the
+ *  length and lifetime of its codes nor how they reach the user.
+ *
+ *  @file       otp.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - OTPDeliveryChannel: Sends an OTP to a user, e.g. by email or, later, SMS.
+ *
+ *  @methods
+ *  - NewOTPManager(jwtManager)                  - Creates an OTPManager with DefaultOTPLength and OTPValidity.
+ *  - Generate()                                 - Returns a new OTP, the hash to store and when it expires.
+ *  - Validate(stored, provided, expiresAt)      - Checks a submitted OTP against the stored hash and expiry.
+ *  - NewEmailOTPDelivery(emailService, templates) - Creates the channel that emails OTPs from the templates.
+ *
+ *  @behaviors
+ *  - OTPs are random digits from crypto/rand; only their HMAC is stored, keyed by the JWT secret.
+ *  - Validate reports a wrong OTP before an expired one, so wrong guesses are counted even after expiry.
+ *  - ErrInvalidOTP and ErrOTPExpired keep the messages the API has always returned.
+ *  - The email channel writes in the locale stored in the context (see i18n.WithLocale), English by default.
+ *  - The email channel queues the message with SendMultipartEmailAsync, so delivery is retried in the
+ *    background and does not delay the request.
+ *
+ *  @dependencies
+ *  - utils.JWTManager: Hashes OTPs with the server secret and checks them against old secrets too.
+ *  - EmailServiceInterface, EmailTemplateRenderer: Send the OTP emails.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"proh2052-group6/pkg/i18n"
+	"proh2052-group6/pkg/utils"
+)
+
+// Errors returned by OTPManager.Validate.
+var (
+	ErrInvalidOTP = errors.New("Invalid OTP")
+	ErrOTPExpired = errors.New("OTP has expired")
+)
+
+// DefaultOTPLength is the number of digits of an OTP unless OTP_LENGTH configures another.
+const DefaultOTPLength = 6
+
+// OTPPurpose tells a delivery channel which message to send an OTP in.
+type OTPPurpose string
+
+// Purposes OTPs are sent for.
+const (
+	OTPPurposeVerification  OTPPurpose = "verification"
+	OTPPurposePasswordReset OTPPurpose = "password_reset"
+)
+
+// OTPManager generates OTPs and checks submitted ones.
+type OTPManager struct {
+	JWT    *utils.JWTManager // Hashes OTPs with the server secret.
+	Length int               // Digits of each OTP.
+	TTL    time.Duration     // How long an OTP stays valid.
+	Now    func() time.Time  // Clock used for expiry; replaceable in tests.
+}
+
+// NewOTPManager creates an OTPManager hashing with jwtManager, with DefaultOTPLength digits and
+// OTPValidity lifetime.
+func NewOTPManager(jwtManager *utils.JWTManager) *OTPManager {
+	return &OTPManager{
+		JWT:    jwtManager,
+		Length: DefaultOTPLength,
+		TTL:    OTPValidity,
+		Now:    time.Now,
+	}
+}
+
+// Generate returns a new OTP to send to the user, the hash to store in its place and when it expires.
+func (om *OTPManager) Generate() (otp, hash string, expiresAt time.Time) {
+	otp = utils.GenerateOTPOfLength(om.Length)
+	return otp, om.JWT.HashOTP(otp), om.Now().Add(om.TTL)
+}
+
+// Validate checks a provided OTP against the stored hash and its expiry. It returns ErrInvalidOTP
+// if they do not match or nothing is stored, and ErrOTPExpired if the OTP matches but has expired.
+func (om *OTPManager) Validate(stored, provided string, expiresAt time.Time) error {
+	if !om.JWT.CheckOTP(provided, stored) {
+		return ErrInvalidOTP
+	}
+	if om.Now().After(expiresAt) {
+		return ErrOTPExpired
+	}
+	return nil
+}
+
+// OTPDeliveryChannel sends an OTP to a user. validFor is included in the message, so the user knows
+// how long the OTP can be used.
+type OTPDeliveryChannel interface {
+	SendOTP(ctx context.Context, email, otp string, purpose OTPPurpose, validFor time.Duration) error
+}
+
+// emailOTPDelivery sends OTPs in the emails rendered from the templates.
+type emailOTPDelivery struct {
+	email     EmailServiceInterface
+	templates *EmailTemplateRenderer
+}
+
+// NewEmailOTPDelivery creates the channel that emails OTPs to the user's address.
+func NewEmailOTPDelivery(emailService EmailServiceInterface, templates *EmailTemplateRenderer) OTPDeliveryChannel {
+	return &emailOTPDelivery{email: emailService, templates: templates}
+}
+
+// SendOTP renders the email for purpose in the locale of ctx and queues it to email.
+func (ed *emailOTPDelivery) SendOTP(ctx context.Context, email, otp string, purpose OTPPurpose, validFor time.Duration) error {
+	var msg EmailMessage
+	var err error
+	locale := i18n.Locale(ctx)
+	switch purpose {
+	case OTPPurposeVerification:
+		msg, err = ed.templates.VerificationOTP(locale, otp, validFor)
+	case OTPPurposePasswordReset:
+		msg, err = ed.templates.PasswordResetOTP(locale, otp, validFor)
+	default:
+		return fmt.Errorf("Unknown OTP purpose %q", purpose)
+	}
+	if err != nil {
+		return err
+	}
+	return ed.email.SendMultipartEmailAsync(ctx, email, msg)
+}
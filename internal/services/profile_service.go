@@ -6,24 +6,67 @@
  *  @methods
  *  - GetProfile(ctx, userEmail)                 - Retrieves the profile data for the specified user.
  *  - UpdateProfile(ctx, userEmail, updatedData) - Updates the profile data for the specified user.
+ *  - RequestEmailChange(ctx, userEmail, newEmail, currentPassword) - Sends an OTP to a new email address.
+ *  - ConfirmEmailChange(ctx, userEmail, otp)    - Moves the account to the pending email and returns a new token.
+ *  - UpdateAvatar(ctx, userEmail, data)         - Stores a new profile picture and returns its URL.
+ *  - DeleteAvatar(ctx, userEmail)               - Removes the user's profile picture.
  *
  *  @struct   ProfileService
  *  @inherits ProfileServiceInterface
  *
  *  @methods
- *  - NewProfileService(userRepo)               - Creates a new ProfileService instance with a user repository.
+ *  - NewProfileService(userRepo, friendRepo, invitationRepo, emailService, storageService, jwtManager) - Creates a new ProfileService instance.
  *  - GetProfile(ctx, userEmail)                - Implementation for retrieving user profile data.
  *  - UpdateProfile(ctx, userEmail, updatedData)- Implementation for updating user profile data.
+ *  - RequestEmailChange(ctx, userEmail, newEmail, currentPassword) - Implementation for starting an email change.
+ *  - ConfirmEmailChange(ctx, userEmail, otp)   - Implementation for completing an email change.
+ *  - UpdateAvatar(ctx, userEmail, data)        - Implementation for uploading a profile picture.
+ *  - DeleteAvatar(ctx, userEmail)              - Implementation for removing a profile picture.
  *
  *  @behaviors
  *  - Ensures that user data is validated before updating the profile.
  *  - Validates the current password for sensitive updates, such as password changes.
- *  - Prevents updating protected fields like the email address.
- *  - Converts user data from struct to a map for JSON compatibility.
+ *  - Changing the password bumps the user's TokenVersion, which revokes every token issued before,
+ *    including the one used for the change. The change is recorded in the user's audit log.
+ *  - Prevents updating protected fields like the email address; the email is changed through
+ *    RequestEmailChange and ConfirmEmailChange, which verify the new address with an OTP.
+ *  - An email change moves the user, their events and journals to the new email and rewrites
+ *    friend requests, blocks, invitations and shared event links referring to the old one. The user
+ *    is moved last and the pending change is cleared under the new email once everything has moved,
+ *    so if a step fails the old email and token keep working and confirming again with the same OTP
+ *    resumes the change: every step only picks up what still refers to the old email.
+ *  - Email change OTPs stay valid for emailChangeOTPLifetime; the OTP manager uses the service's clock,
+ *    so replacing Now also moves their expiry.
+ *  - Rejects a new username already used by another user (case-insensitive) with ErrUsernameTaken,
+ *    and keeps UsernameLower in sync with the username.
+ *  - Keeps FirstNameLower and LastNameLower in sync with the first and last name, for user search.
+ *  - Exposes the NotificationsEnabled setting, which must be a boolean when updated.
+ *  - Exposes the DigestEnabled setting for the weekly digest email, which must be a boolean when updated.
+ *  - Exposes the ReminderEnabled and ReminderTime settings of the daily journal reminder. The time must
+ *    be HH:MM or empty, and the reminder can only be enabled with a time.
+ *  - Exposes the QuietStart and QuietEnd of the user's quiet hours. They are HH:MM, must differ, and
+ *    are set or cleared together.
+ *  - Rejects unknown countries with suggestions and stores the country's canonical name (see
+ *    NormalizeCountry); the city must not be empty.
+ *  - Exposes the TimeZone setting events are entered and shown in. It must be an IANA time zone name,
+ *    or empty to use the time zone of the user's country, which GetProfile reports in its place.
+ *  - Profile pictures must be PNG or JPEG images of at most MaxAvatarSize bytes; the type is detected
+ *    from the content, not the file name. ImageURL is only set through UpdateAvatar and DeleteAvatar,
+ *    and the previous picture is deleted from storage once it has been replaced or removed.
+ *  - A profile update can never change the user's role or disabled flag, which only administrators control.
+ *  - GetProfile returns the same models.UserProfile as UserService.GetUserInfo, so GET /api/profile
+ *    and GET /api/me describe the user alike.
  *
  *  @dependencies
  *  - repositories.UserRepository: Repository for interacting with the Firestore user data.
+ *  - repositories.FriendRepository, repositories.InvitationRepository: Rewritten when the email changes.
+ *  - repositories.ShareRepository: Shared event links rewritten when the email changes; may be nil.
+ *  - EmailServiceInterface: Sends the OTP for an email change.
+ *  - StorageServiceInterface: Stores profile pictures; may be nil, which disables uploads.
  *  - utils: Utility package for password hashing, validation, and security checks.
+ *  - utils.JWTManager: Issues the token for the new email.
+ *  - OTPManager: Generates and validates email change OTPs, like the OTPs of UserService.
+ *  - AuditServiceInterface: Records password changes; may be nil.
  *
  *  @example
  *  ```
@@ -48,46 +91,138 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
 
 	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/i18n"
+	"proh2052-group6/pkg/models"
 	"proh2052-group6/pkg/utils"
+	"proh2052-group6/pkg/validate"
 )
 
+// Errors returned by the email change flow.
+var (
+	ErrInvalidEmail         = errors.New("Invalid email address")
+	ErrSameEmail            = errors.New("New email must be different from the current email")
+	ErrNoPendingEmailChange = errors.New("No pending email change")
+)
+
+// Errors returned when uploading a profile picture.
+var (
+	ErrAvatarTooLarge        = errors.New("Profile picture must be at most 2 MB")
+	ErrUnsupportedAvatarType = errors.New("Profile picture must be a PNG or JPEG image")
+	ErrAvatarUploadsDisabled = errors.New("Profile picture uploads are not available")
+)
+
+// MaxAvatarSize is the maximum size of a profile picture in bytes.
+const MaxAvatarSize = 2 << 20
+
+// avatarExtensions maps the accepted profile picture content types to the extension of the stored object.
+var avatarExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+}
+
+// emailChangeOTPLifetime is how long the OTP for an email change stays valid.
+const emailChangeOTPLifetime = 10 * time.Minute
+
 // ProfileServiceInterface defines the methods for managing user profiles.
 type ProfileServiceInterface interface {
-	GetProfile(ctx context.Context, userEmail string) (map[string]interface{}, error)
+	GetProfile(ctx context.Context, userEmail string) (*models.UserProfile, error)
 	UpdateProfile(ctx context.Context, userEmail string, updatedData map[string]interface{}) error
+	RequestEmailChange(ctx context.Context, userEmail, newEmail, currentPassword string) error
+	ConfirmEmailChange(ctx context.Context, userEmail, otp string) (string, error)
+	UpdateAvatar(ctx context.Context, userEmail string, data []byte) (string, error)
+	DeleteAvatar(ctx context.Context, userEmail string) error
 }
 
 // ProfileService provides implementations for ProfileServiceInterface methods.
 type ProfileService struct {
-	UserRepo repositories.UserRepository
+	UserRepo       repositories.UserRepository
+	FriendRepo     repositories.FriendRepository     // Rewritten when the user's email changes.
+	InvitationRepo repositories.InvitationRepository // Rewritten when the user's email changes.
+	ShareRepo      repositories.ShareRepository      // Rewritten when the user's email changes; may be nil.
+	Email          EmailServiceInterface             // Sends the OTP for an email change.
+	Storage        StorageServiceInterface           // Stores profile pictures; nil disables uploads.
+	JWT            *utils.JWTManager                 // Issues the token for a changed email.
+	OTP            *OTPManager                       // Generates and validates email change OTPs.
+	Audit          AuditServiceInterface             // Records password changes; may be nil.
+
+	MaxOTPAttempts int              // Wrong submissions before an email change OTP is invalidated.
+	Now            func() time.Time // Clock used for OTP expiry; replaceable in tests.
 }
 
-// NewProfileService initializes a new ProfileService with the given UserRepository.
-func NewProfileService(userRepo repositories.UserRepository) ProfileServiceInterface {
-	return &ProfileService{UserRepo: userRepo}
+// NewProfileService initializes a new ProfileService with the given repositories, EmailService, StorageService
+// and JWTManager.
+func NewProfileService(userRepo repositories.UserRepository, friendRepo repositories.FriendRepository, invitationRepo repositories.InvitationRepository, emailService EmailServiceInterface, storageService StorageServiceInterface, jwtManager *utils.JWTManager) ProfileServiceInterface {
+	ps := &ProfileService{
+		UserRepo:       userRepo,
+		FriendRepo:     friendRepo,
+		InvitationRepo: invitationRepo,
+		Email:          emailService,
+		Storage:        storageService,
+		JWT:            jwtManager,
+		OTP:            NewOTPManager(jwtManager),
+		MaxOTPAttempts: DefaultMaxOTPAttempts,
+		Now:            time.Now,
+	}
+	ps.OTP.TTL = emailChangeOTPLifetime
+	ps.OTP.Now = func() time.Time { return ps.Now() }
+	return ps
 }
 
 // GetProfile retrieves the profile data for the specified user.
-func (ps *ProfileService) GetProfile(ctx context.Context, userEmail string) (map[string]interface{}, error) {
+func (ps *ProfileService) GetProfile(ctx context.Context, userEmail string) (*models.UserProfile, error) {
 	// Fetch user data from the repository.
 	user, err := ps.UserRepo.GetUserByEmail(ctx, userEmail)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to get profile")
+		return nil, fmt.Errorf("Failed to get profile: %w", err)
 	}
+	return newUserProfile(user), nil
+}
 
-	// Convert user struct to a map[string]interface{} for JSON compatibility.
-	profileData := map[string]interface{}{
-		"Email":    user.Email,
-		"Username": user.Username,
-		"Country":  user.Country,
-		"City":     user.City,
-		// Add other fields as required.
+// newUserProfile describes user to themselves, for GetProfile and UserService.GetUserInfo.
+func newUserProfile(user *models.User) *models.UserProfile {
+	profile := &models.UserProfile{
+		Email:      user.Email,
+		Username:   user.Username,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Country:    user.Country,
+		City:       user.City,
+		ImageURL:   user.ImageURL,
+		IsVerified: user.IsVerified,
+		// Notifications are enabled unless the user explicitly turned them off.
+		NotificationsEnabled: user.NotificationsEnabled == nil || *user.NotificationsEnabled,
+		// The weekly digest is only sent to users who opted in.
+		DigestEnabled:   user.DigestEnabled,
+		ReminderEnabled: user.ReminderEnabled,
+		ReminderTime:    user.ReminderTime,
+		QuietStart:      user.QuietStart,
+		QuietEnd:        user.QuietEnd,
+		TimeZone:        profileTimeZone(user),
+	}
+	// Accounts created before CreatedAt was recorded leave it out.
+	if !user.CreatedAt.IsZero() {
+		createdAt := user.CreatedAt
+		profile.CreatedAt = &createdAt
 	}
+	return profile
+}
 
-	return profileData, nil
+// profileTimeZone returns the name of the user's time zone, or of their country's; it is empty if neither is known.
+func profileTimeZone(user *models.User) string {
+	if location, ok := LocationForUser(user); ok {
+		return location.String()
+	}
+	return ""
 }
 
 // UpdateProfile updates the profile data for the specified user with validation.
@@ -95,7 +230,7 @@ func (ps *ProfileService) UpdateProfile(ctx context.Context, userEmail string, u
 	// Retrieve the current user data.
 	user, err := ps.UserRepo.GetUserByEmail(ctx, userEmail)
 	if err != nil {
-		return fmt.Errorf("Failed to retrieve user data")
+		return fmt.Errorf("Failed to retrieve user data: %w", err)
 	}
 	storedHashedPassword := user.Password
 
@@ -105,24 +240,395 @@ func (ps *ProfileService) UpdateProfile(ctx context.Context, userEmail string, u
 		return fmt.Errorf("Invalid current password")
 	}
 
-	// Validate and update the password if a new password is provided.
+	// Validate and update the password if a new password is provided. The token version is only
+	// ever bumped here, never set by the client.
+	delete(updatedData, "TokenVersion")
 	if newPassword, ok := updatedData["NewPassword"].(string); ok && newPassword != "" {
 		if !utils.IsValidPassword(newPassword) {
-			return fmt.Errorf("Password does not meet complexity requirements")
+			return ErrWeakPassword
+		}
+		hashedPassword, err := utils.HashPassword(newPassword)
+		if err != nil {
+			return fmt.Errorf("Failed to update profile")
+		}
+		updatedData["Password"] = hashedPassword
+		updatedData["TokenVersion"] = user.TokenVersion + 1
+	}
+
+	// Validate the username if it is being changed, and keep the lowercase copy used for lookups in sync.
+	delete(updatedData, "UsernameLower")
+	if rawUsername, ok := updatedData["Username"]; ok {
+		username, isString := rawUsername.(string)
+		username = strings.TrimSpace(username)
+		if !isString || username == "" {
+			return fmt.Errorf("Username must not be empty")
+		}
+		usernameLower := strings.ToLower(username)
+		if usernameLower != strings.ToLower(user.Username) {
+			existing, err := ps.UserRepo.GetUserByUsername(ctx, username)
+			if isRepositoryFailure(err) {
+				return err
+			}
+			if err == nil && existing != nil && existing.Email != userEmail {
+				return ErrUsernameTaken
+			}
+		}
+		updatedData["Username"] = username
+		updatedData["UsernameLower"] = usernameLower
+	}
+
+	// Keep the lowercase copies of the names used for search in sync.
+	for _, field := range []string{"FirstName", "LastName"} {
+		delete(updatedData, field+"Lower")
+		if rawName, ok := updatedData[field]; ok {
+			name, isString := rawName.(string)
+			if !isString {
+				return fmt.Errorf("%s must be a string", field)
+			}
+			name = strings.TrimSpace(name)
+			updatedData[field] = name
+			updatedData[field+"Lower"] = strings.ToLower(name)
+		}
+	}
+
+	// Validate the notification setting if provided.
+	if notificationsEnabled, ok := updatedData["NotificationsEnabled"]; ok {
+		if _, isBool := notificationsEnabled.(bool); !isBool {
+			return fmt.Errorf("NotificationsEnabled must be true or false")
+		}
+	}
+	if digestEnabled, ok := updatedData["DigestEnabled"]; ok {
+		if _, isBool := digestEnabled.(bool); !isBool {
+			return fmt.Errorf("DigestEnabled must be true or false")
+		}
+	}
+
+	// Validate the journal reminder if provided; it can only be enabled with a time to send it at.
+	reminderEnabled, reminderTime := user.ReminderEnabled, user.ReminderTime
+	if rawEnabled, ok := updatedData["ReminderEnabled"]; ok {
+		enabled, isBool := rawEnabled.(bool)
+		if !isBool {
+			return fmt.Errorf("ReminderEnabled must be true or false")
+		}
+		reminderEnabled = enabled
+	}
+	if rawTime, ok := updatedData["ReminderTime"]; ok {
+		timeString, isString := rawTime.(string)
+		if !isString {
+			return ErrInvalidReminderTime
+		}
+		normalized, err := normalizeReminderTime(timeString)
+		if err != nil {
+			return err
+		}
+		updatedData["ReminderTime"] = normalized
+		reminderTime = normalized
+	}
+	if reminderEnabled && reminderTime == "" {
+		return ErrReminderTimeRequired
+	}
+
+	// Validate the quiet hours if either end is changed; the result must still be set or cleared together.
+	_, hasQuietStart := updatedData["QuietStart"]
+	_, hasQuietEnd := updatedData["QuietEnd"]
+	if hasQuietStart || hasQuietEnd {
+		quietHours := []string{user.QuietStart, user.QuietEnd}
+		for i, field := range []string{"QuietStart", "QuietEnd"} {
+			if rawTime, ok := updatedData[field]; ok {
+				timeString, isString := rawTime.(string)
+				if !isString {
+					return ErrInvalidQuietHours
+				}
+				quietHours[i] = timeString
+			}
+		}
+		quietStart, quietEnd, err := normalizeQuietHours(quietHours[0], quietHours[1])
+		if err != nil {
+			return err
+		}
+		updatedData["QuietStart"], updatedData["QuietEnd"] = quietStart, quietEnd
+	}
+
+	// Validate the country if provided, and store its canonical name.
+	if rawCountry, ok := updatedData["Country"]; ok {
+		countryString, _ := rawCountry.(string)
+		country, err := NormalizeCountry(countryString)
+		if err != nil {
+			return err
+		}
+		updatedData["Country"] = country
+	}
+	if rawCity, ok := updatedData["City"]; ok {
+		city, isString := rawCity.(string)
+		if !isString || strings.TrimSpace(city) == "" {
+			return validate.Errors{"city": "required"}
 		}
-		updatedData["Password"] = utils.HashPassword(newPassword)
+		updatedData["City"] = strings.TrimSpace(city)
+	}
+
+	// Validate the time zone if provided; an empty time zone falls back to the user's country.
+	if rawTimeZone, ok := updatedData["TimeZone"]; ok {
+		timeZone, isString := rawTimeZone.(string)
+		if !isString {
+			return ErrInvalidTimeZone
+		}
+		timeZone = strings.TrimSpace(timeZone)
+		if timeZone != "" {
+			if _, err := LoadTimeZone(timeZone); err != nil {
+				return err
+			}
+		}
+		updatedData["TimeZone"] = timeZone
 	}
 
 	// Remove fields that should not be updated directly.
 	delete(updatedData, "CurrentPassword")
 	delete(updatedData, "NewPassword")
-	delete(updatedData, "Email") // Prevent updating the email address.
+	delete(updatedData, "Email")    // Prevent updating the email address.
+	delete(updatedData, "ImageURL") // Set through UpdateAvatar and DeleteAvatar only.
+	for _, field := range []string{"PendingEmail", "EmailChangeOTP", "EmailChangeOTPExpiresAt", "EmailChangeOTPAttempts", "DigestSentFor", "LastReminderSentDate", "FeedToken", "Role", "Disabled", "MovedFrom"} {
+		delete(updatedData, field)
+	}
 
 	// Update the user data in the repository.
 	err = ps.UserRepo.UpdateUser(ctx, userEmail, updatedData)
 	if err != nil {
-		return fmt.Errorf("Failed to update profile")
+		return fmt.Errorf("Failed to update profile: %w", err)
 	}
 
+	if _, changed := updatedData["Password"]; changed {
+		recordAudit(ctx, ps.Audit, userEmail, AuditPasswordChanged)
+	}
 	return nil
 }
+
+// RequestEmailChange starts changing the user's email to newEmail. After checking the current password
+// and that newEmail is not registered, it sends an OTP to newEmail that ConfirmEmailChange must receive.
+func (ps *ProfileService) RequestEmailChange(ctx context.Context, userEmail, newEmail, currentPassword string) error {
+	newEmail = utils.NormalizeEmail(newEmail)
+	if !utils.IsValidEmail(newEmail) {
+		return ErrInvalidEmail
+	}
+	if strings.EqualFold(newEmail, userEmail) {
+		return ErrSameEmail
+	}
+
+	user, err := ps.UserRepo.GetUserByEmail(ctx, userEmail)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve user data: %w", err)
+	}
+	if user == nil {
+		return fmt.Errorf("Failed to retrieve user data")
+	}
+	if !utils.CheckPasswordHash(currentPassword, user.Password) {
+		return fmt.Errorf("Invalid current password")
+	}
+
+	existing, err := ps.UserRepo.GetUserByEmail(ctx, newEmail)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err == nil && existing != nil {
+		return ErrEmailTaken
+	}
+
+	otp, hash, expiresAt := ps.OTP.Generate()
+	updates := map[string]interface{}{
+		"PendingEmail":            newEmail,
+		"EmailChangeOTP":          hash,
+		"EmailChangeOTPExpiresAt": expiresAt,
+		"EmailChangeOTPAttempts":  0,
+	}
+	if err := ps.UserRepo.UpdateUser(ctx, userEmail, updates); err != nil {
+		return fmt.Errorf("Failed to start email change: %w", err)
+	}
+
+	subject := i18n.T(ctx, "email.email_change.subject")
+	body := i18n.T(ctx, "email.email_change.body", otp, int(ps.OTP.TTL.Minutes()))
+	if err := ps.Email.SendEmail(newEmail, subject, body); err != nil {
+		return fmt.Errorf("Failed to send OTP email")
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange completes a pending email change if otp matches the one sent to the new address.
+// The account and everything referring to it are moved to the new email, and a token for the new
+// email is returned, since tokens for the old email no longer identify a user.
+func (ps *ProfileService) ConfirmEmailChange(ctx context.Context, userEmail, otp string) (string, error) {
+	user, err := ps.UserRepo.GetUserByEmail(ctx, userEmail)
+	if err != nil {
+		return "", fmt.Errorf("Failed to retrieve user data: %w", err)
+	}
+	if user == nil {
+		return "", fmt.Errorf("Failed to retrieve user data")
+	}
+	if user.PendingEmail == "" {
+		return "", ErrNoPendingEmailChange
+	}
+	if err := ps.checkEmailChangeOTP(ctx, user, otp); err != nil {
+		return "", err
+	}
+
+	// The new email may have been registered since the change was requested. A copy of the user left
+	// there by an earlier confirmation that failed halfway is not a conflict; the move resumes.
+	newEmail := user.PendingEmail
+	existing, err := ps.UserRepo.GetUserByEmail(ctx, newEmail)
+	if isRepositoryFailure(err) {
+		return "", err
+	}
+	if err == nil && existing != nil && existing.MovedFrom != userEmail {
+		return "", ErrEmailTaken
+	}
+
+	if err := migrateAccountEmail(ctx, ps.UserRepo, ps.FriendRepo, ps.InvitationRepo, ps.ShareRepo, userEmail, newEmail); err != nil {
+		log.Printf("Failed to change email of %s to %s: %v", userEmail, newEmail, err)
+		return "", fmt.Errorf("Failed to change email")
+	}
+
+	// The pending change is cleared only once everything has moved, so until then it can be confirmed again.
+	clearPending := map[string]interface{}{
+		"PendingEmail":            nil,
+		"EmailChangeOTP":          nil,
+		"EmailChangeOTPExpiresAt": nil,
+		"EmailChangeOTPAttempts":  0,
+	}
+	if err := ps.UserRepo.UpdateUser(ctx, newEmail, clearPending); err != nil {
+		return "", fmt.Errorf("Failed to change email: %w", err)
+	}
+
+	token, err := ps.JWT.GenerateJWT(newEmail, user.TokenVersion)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate token")
+	}
+	return token, nil
+}
+
+// migrateAccountEmail moves the account of oldEmail to newEmail: their friend requests and blocks,
+// their invitations, their shared event links, and last the user and the data stored under them, so
+// the account stays under oldEmail until everything else has moved. Every step only rewrites what
+// still refers to oldEmail, so after a failure calling it again resumes the move. A nil friendRepo,
+// invitationRepo or shareRepo is skipped.
+func migrateAccountEmail(ctx context.Context, userRepo repositories.UserRepository, friendRepo repositories.FriendRepository, invitationRepo repositories.InvitationRepository, shareRepo repositories.ShareRepository, oldEmail, newEmail string) error {
+	if friendRepo != nil {
+		if err := friendRepo.MigrateFriendEmail(ctx, oldEmail, newEmail); err != nil {
+			return fmt.Errorf("Failed to migrate friends: %w", err)
+		}
+	}
+	if invitationRepo != nil {
+		if err := invitationRepo.MigrateInvitationEmail(ctx, oldEmail, newEmail); err != nil {
+			return fmt.Errorf("Failed to migrate invitations: %w", err)
+		}
+	}
+	if shareRepo != nil {
+		if err := shareRepo.MigrateShareEmail(ctx, oldEmail, newEmail); err != nil {
+			return fmt.Errorf("Failed to migrate event shares: %w", err)
+		}
+	}
+	if err := userRepo.MigrateUserEmail(ctx, oldEmail, newEmail); err != nil {
+		return fmt.Errorf("Failed to migrate user: %w", err)
+	}
+	return nil
+}
+
+// checkEmailChangeOTP validates a submitted OTP against the user's pending email change. Every submission
+// is counted before it is checked, so at most MaxOTPAttempts are checked even when they are sent
+// concurrently; once the last one is wrong the pending change is cancelled and ErrTooManyOTPAttempts is returned.
+func (ps *ProfileService) checkEmailChangeOTP(ctx context.Context, user *models.User, otp string) error {
+	if user.EmailChangeOTPAttempts >= ps.MaxOTPAttempts {
+		return ErrTooManyOTPAttempts
+	}
+	attempts, err := ps.UserRepo.IncrementUserField(ctx, user.Email, "EmailChangeOTPAttempts")
+	if err != nil {
+		return fmt.Errorf("Failed to record OTP attempt: %w", err)
+	}
+	if attempts > ps.MaxOTPAttempts {
+		return ErrTooManyOTPAttempts
+	}
+
+	err = ps.OTP.Validate(user.EmailChangeOTP, otp, user.EmailChangeOTPExpiresAt)
+	if errors.Is(err, ErrInvalidOTP) && attempts == ps.MaxOTPAttempts {
+		updates := map[string]interface{}{"PendingEmail": nil, "EmailChangeOTP": nil, "EmailChangeOTPExpiresAt": nil}
+		if err := ps.UserRepo.UpdateUser(ctx, user.Email, updates); err != nil {
+			return fmt.Errorf("Failed to record OTP attempt: %w", err)
+		}
+		return ErrTooManyOTPAttempts
+	}
+	return err
+}
+
+// UpdateAvatar stores data as the user's profile picture, replacing and deleting the previous one,
+// and returns the URL of the new picture.
+func (ps *ProfileService) UpdateAvatar(ctx context.Context, userEmail string, data []byte) (string, error) {
+	if ps.Storage == nil {
+		return "", ErrAvatarUploadsDisabled
+	}
+	if len(data) > MaxAvatarSize {
+		return "", ErrAvatarTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := avatarExtensions[contentType]
+	if !ok {
+		return "", ErrUnsupportedAvatarType
+	}
+
+	user, err := ps.UserRepo.GetUserByEmail(ctx, userEmail)
+	if isRepositoryFailure(err) {
+		return "", err
+	}
+	if err != nil || user == nil {
+		return "", fmt.Errorf("User not found")
+	}
+	previousURL := user.ImageURL
+
+	// Every picture gets a new random name, so cached copies of the old one are never served instead.
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return "", fmt.Errorf("Failed to upload profile picture")
+	}
+	imageURL, err := ps.Storage.Upload(ctx, "avatars/"+hex.EncodeToString(name)+extension, contentType, data)
+	if err != nil {
+		log.Printf("Failed to upload profile picture for %s: %v", userEmail, err)
+		return "", fmt.Errorf("Failed to upload profile picture")
+	}
+
+	if err := ps.UserRepo.UpdateUser(ctx, userEmail, map[string]interface{}{"ImageURL": imageURL}); err != nil {
+		ps.deleteAvatarObject(ctx, imageURL)
+		return "", fmt.Errorf("Failed to update profile: %w", err)
+	}
+	ps.deleteAvatarObject(ctx, previousURL)
+
+	return imageURL, nil
+}
+
+// DeleteAvatar removes the user's profile picture. Removing a picture that is not set is not an error.
+func (ps *ProfileService) DeleteAvatar(ctx context.Context, userEmail string) error {
+	user, err := ps.UserRepo.GetUserByEmail(ctx, userEmail)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err != nil || user == nil {
+		return fmt.Errorf("User not found")
+	}
+	imageURL := user.ImageURL
+	if imageURL == "" {
+		return nil
+	}
+
+	if err := ps.UserRepo.UpdateUser(ctx, userEmail, map[string]interface{}{"ImageURL": ""}); err != nil {
+		return fmt.Errorf("Failed to update profile: %w", err)
+	}
+	ps.deleteAvatarObject(ctx, imageURL)
+	return nil
+}
+
+// deleteAvatarObject deletes a profile picture from storage. Failures are only logged, since the
+// picture is no longer referenced by the user.
+func (ps *ProfileService) deleteAvatarObject(ctx context.Context, imageURL string) {
+	if imageURL == "" || ps.Storage == nil {
+		return
+	}
+	if err := ps.Storage.Delete(ctx, imageURL); err != nil {
+		log.Printf("Failed to delete profile picture %s: %v", imageURL, err)
+	}
+}
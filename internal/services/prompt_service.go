@@ -0,0 +1,150 @@
+/**
+ *  PromptService suggests a journal writing prompt of the day to users who do not know what to
+ *  write, chosen from a list of prompts embedded in the binary, and lets them skip to the next one.
+ *
+ *  @file       prompt_service.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - PromptServiceInterface: Defines the contract for the journal prompt of the day.
+ *
+ *  @methods
+ *  - NewPromptService(promptRepo, userRepo) - Initializes a new PromptService with the embedded prompts.
+ *  - GetPrompt(ctx, userEmail)              - Returns the user's prompt of the day.
+ *  - SkipPrompt(ctx, userEmail)             - Moves the user on to the next prompt and returns it.
+ *  - IsPrompt(id)                           - Reports whether a prompt ID is in the list.
+ *
+ *  @behaviors
+ *  - Like the daily verse, the prompt of a day starts at the FNV-1a hash of the date modulo the
+ *    number of prompts, so the prompt changes every day and is the same all day.
+ *  - Each user has a prompt offset, the number of prompts they skipped, which is added to that
+ *    index. Skipping moves the user to the next prompt of the list, and keeps them ahead of the
+ *    daily rotation on later days, so a skipped prompt does not come back the next morning.
+ *  - "Today" is the date in the user's time zone, or the server's for users without one.
+ *  - Adding prompts changes which prompt is picked, so prompt IDs must never be reused: journal
+ *    entries refer to them.
+ *
+ *  @errors
+ *  - ErrUnknownPrompt: A journal entry names a prompt ID that is not in the list.
+ *
+ *  @dependencies
+ *  - prompts/prompts.json: The list of prompts, embedded with go:embed.
+ *  - repositories.PromptRepository: Stores the users' prompt offsets.
+ *  - repositories.UserRepository: Looks up the user's time zone.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// ErrUnknownPrompt is returned when a journal entry names a prompt that is not in the list.
+var ErrUnknownPrompt = errors.New("Unknown prompt")
+
+//go:embed prompts/prompts.json
+var promptsJSON []byte
+
+// PromptServiceInterface defines methods for the journal prompt of the day.
+type PromptServiceInterface interface {
+	GetPrompt(ctx context.Context, userEmail string) (*models.JournalPrompt, error)
+	SkipPrompt(ctx context.Context, userEmail string) (*models.JournalPrompt, error)
+}
+
+// PromptService implements PromptServiceInterface.
+type PromptService struct {
+	PromptRepo repositories.PromptRepository // Repository for the users' prompt offsets.
+	UserRepo   repositories.UserRepository   // Repository for the users' time zones; may be nil.
+	Now        func() time.Time              // Clock used for today's date; replaceable in tests.
+
+	prompts []models.JournalPrompt // Prompts in list order.
+	byID    map[string]bool        // IDs of the prompts.
+}
+
+// NewPromptService initializes a new PromptService with the embedded prompts. It panics if the
+// list is invalid, since it is part of the binary.
+func NewPromptService(promptRepo repositories.PromptRepository, userRepo repositories.UserRepository) PromptServiceInterface {
+	var prompts []models.JournalPrompt
+	if err := json.Unmarshal(promptsJSON, &prompts); err != nil {
+		panic(fmt.Sprintf("Invalid prompt list: %v", err))
+	}
+	if len(prompts) == 0 {
+		panic("Invalid prompt list: no prompts")
+	}
+
+	ps := &PromptService{
+		PromptRepo: promptRepo,
+		UserRepo:   userRepo,
+		Now:        time.Now,
+		prompts:    prompts,
+		byID:       make(map[string]bool, len(prompts)),
+	}
+	for _, prompt := range prompts {
+		if ps.byID[prompt.ID] {
+			panic(fmt.Sprintf("Invalid prompt list: duplicate ID %s", prompt.ID))
+		}
+		ps.byID[prompt.ID] = true
+	}
+	return ps
+}
+
+// GetPrompt returns the user's prompt of the day.
+func (ps *PromptService) GetPrompt(ctx context.Context, userEmail string) (*models.JournalPrompt, error) {
+	offset, err := ps.PromptRepo.GetPromptOffset(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	return ps.promptFor(ctx, userEmail, offset.Offset)
+}
+
+// SkipPrompt moves the user on to the next prompt of their sequence and returns it.
+func (ps *PromptService) SkipPrompt(ctx context.Context, userEmail string) (*models.JournalPrompt, error) {
+	offset, err := ps.PromptRepo.IncrementPromptOffset(ctx, userEmail, ps.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return ps.promptFor(ctx, userEmail, offset.Offset)
+}
+
+// IsPrompt reports whether id is the ID of a prompt in the list.
+func (ps *PromptService) IsPrompt(id string) bool {
+	return ps.byID[id]
+}
+
+// promptFor returns the prompt of today, in the time zone of userEmail, moved on by offset.
+func (ps *PromptService) promptFor(ctx context.Context, userEmail string, offset int) (*models.JournalPrompt, error) {
+	location := time.Local
+	if ps.UserRepo != nil {
+		user, err := ps.UserRepo.GetUserByEmail(ctx, userEmail)
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
+		if err == nil {
+			if userLocation, ok := LocationForUser(user); ok {
+				location = userLocation
+			}
+		}
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(ps.Now().In(location).Format("2006-01-02")))
+	count := uint64(len(ps.prompts))
+	index := (uint64(hash.Sum32()) + uint64(offset)%count) % count
+	prompt := ps.prompts[index]
+	return &prompt, nil
+}
@@ -0,0 +1,141 @@
+/**
+ *  QuoteService picks the daily verse, a quote of the day chosen from a curated dataset embedded
+ *  in the binary, and keeps the quotes users save to their favourites.
+ *
+ *  @file       quote_service.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - QuoteServiceInterface: Defines the contract for the daily verse and favourite quotes.
+ *
+ *  @methods
+ *  - NewQuoteService(favoriteRepo)          - Initializes a new QuoteService with the embedded dataset.
+ *  - GetDailyQuote(ctx, date, language)     - Returns the quote of the day in a language.
+ *  - SaveFavorite(ctx, userEmail, quoteID)  - Saves a quote to the user's favourites.
+ *  - GetFavorites(ctx, userEmail)           - Lists the user's favourites, most recently saved first.
+ *
+ *  @behaviors
+ *  - The quote of a day is the FNV-1a hash of the date modulo the number of quotes in the language,
+ *    so every user with the same language sees the same quote all day, without storing anything.
+ *  - Languages without quotes in the dataset get the English quote of the day.
+ *  - Adding quotes to a language changes which quote is picked on later days, so quote IDs must
+ *    never be reused: favourites refer to them.
+ *
+ *  @errors
+ *  - ErrQuoteNotFound: SaveFavorite was given an ID that is not in the dataset.
+ *
+ *  @dependencies
+ *  - quotes/quotes.json: The curated dataset, embedded with go:embed.
+ *  - repositories.FavoriteRepository: Stores the favourite quotes.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// DefaultQuoteLanguage is the language of the daily verse for languages the dataset has no quotes in.
+const DefaultQuoteLanguage = "en"
+
+// ErrQuoteNotFound is returned when a quote ID is not in the dataset.
+var ErrQuoteNotFound = errors.New("Quote not found")
+
+//go:embed quotes/quotes.json
+var quotesJSON []byte
+
+// QuoteServiceInterface defines methods for the daily verse and favourite quotes.
+type QuoteServiceInterface interface {
+	GetDailyQuote(ctx context.Context, date, language string) (*models.Quote, error)
+	SaveFavorite(ctx context.Context, userEmail, quoteID string) (*models.FavoriteQuote, error)
+	GetFavorites(ctx context.Context, userEmail string) ([]models.FavoriteQuote, error)
+}
+
+// QuoteService implements QuoteServiceInterface.
+type QuoteService struct {
+	FavoriteRepo repositories.FavoriteRepository // Repository for the users' favourite quotes.
+	Now          func() time.Time                // Clock used for the time a favourite is saved.
+
+	byLanguage map[string][]models.Quote // Quotes per language, in dataset order.
+	byID       map[string]models.Quote   // Quotes by ID.
+}
+
+// NewQuoteService initializes a new QuoteService with the embedded dataset. It panics if the
+// dataset is invalid, since it is part of the binary.
+func NewQuoteService(favoriteRepo repositories.FavoriteRepository) QuoteServiceInterface {
+	var quotes []models.Quote
+	if err := json.Unmarshal(quotesJSON, &quotes); err != nil {
+		panic(fmt.Sprintf("Invalid quote dataset: %v", err))
+	}
+
+	qs := &QuoteService{
+		FavoriteRepo: favoriteRepo,
+		Now:          time.Now,
+		byLanguage:   make(map[string][]models.Quote),
+		byID:         make(map[string]models.Quote, len(quotes)),
+	}
+	for _, quote := range quotes {
+		if _, exists := qs.byID[quote.ID]; exists {
+			panic(fmt.Sprintf("Invalid quote dataset: duplicate ID %s", quote.ID))
+		}
+		qs.byID[quote.ID] = quote
+		qs.byLanguage[quote.Language] = append(qs.byLanguage[quote.Language], quote)
+	}
+	if len(qs.byLanguage[DefaultQuoteLanguage]) == 0 {
+		panic("Invalid quote dataset: no quotes in " + DefaultQuoteLanguage)
+	}
+	return qs
+}
+
+// GetDailyQuote returns the quote of the day for date (YYYY-MM-DD) in language, a two-letter
+// language code, or in DefaultQuoteLanguage if the dataset has no quotes in language.
+func (qs *QuoteService) GetDailyQuote(ctx context.Context, date, language string) (*models.Quote, error) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, fmt.Errorf("date must be in the format YYYY-MM-DD")
+	}
+
+	quotes := qs.byLanguage[strings.ToLower(strings.TrimSpace(language))]
+	if len(quotes) == 0 {
+		quotes = qs.byLanguage[DefaultQuoteLanguage]
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(date))
+	quote := quotes[hash.Sum32()%uint32(len(quotes))]
+	return &quote, nil
+}
+
+// SaveFavorite saves the quote with the given ID to the user's favourites and returns the favourite.
+func (qs *QuoteService) SaveFavorite(ctx context.Context, userEmail, quoteID string) (*models.FavoriteQuote, error) {
+	quote, ok := qs.byID[quoteID]
+	if !ok {
+		return nil, ErrQuoteNotFound
+	}
+
+	favorite := &models.FavoriteQuote{Quote: quote, Email: userEmail, SavedAt: qs.Now().UTC()}
+	if err := qs.FavoriteRepo.SaveFavorite(ctx, favorite); err != nil {
+		return nil, err
+	}
+	return favorite, nil
+}
+
+// GetFavorites lists the user's favourite quotes, most recently saved first.
+func (qs *QuoteService) GetFavorites(ctx context.Context, userEmail string) ([]models.FavoriteQuote, error) {
+	return qs.FavoriteRepo.GetFavorites(ctx, userEmail)
+}
@@ -0,0 +1,159 @@
+/**
+ *  Recurrence helpers validate repeat rules on events and expand a recurring event into its
+ *  concrete occurrences within a date window. A series is stored as a single event; occurrences
+ *  are computed on demand and never stored.
+ *
+ *  @file       recurrence.go
+ *  @package    services
+ *
+ *  @methods
+ *  - normalizeRecurrence(event)             - Validates an event's recurrence rule and fills in defaults.
+ *  - occurrenceDates(event, from, to)       - Lists the occurrence dates of a series within [from, to].
+ *  - isOccurrence(event, date)              - Reports whether a date is a (non-removed) occurrence of a series.
+ *  - expandEvent(event, from, to)           - Returns a copy of the event for every occurrence within [from, to].
+ *
+ *  @behaviors
+ *  - Supports daily and weekly frequencies with an interval of one or more days or weeks.
+ *  - A series ends at an inclusive until date, after a number of occurrences, or never.
+ *  - Count includes occurrences that were later removed, matching iCalendar's COUNT and EXDATE.
+ *  - Dates are calendar dates, so month boundaries and daylight saving changes do not shift occurrences.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"proh2052-group6/pkg/dates"
+	"proh2052-group6/pkg/models"
+)
+
+// maxOccurrencesPerSeries bounds how many occurrences are expanded from a single series.
+const maxOccurrencesPerSeries = 5000
+
+// normalizeRecurrence validates the recurrence rule of an event, lowercases its frequency
+// and defaults its interval to 1. The event's Date must already be valid.
+func normalizeRecurrence(event *models.Event) error {
+	rule := event.Recurrence
+	rule.Frequency = strings.ToLower(rule.Frequency)
+	if rule.Frequency != "daily" && rule.Frequency != "weekly" {
+		return fmt.Errorf("Recurrence frequency must be 'daily' or 'weekly'")
+	}
+
+	if rule.Interval < 0 {
+		return fmt.Errorf("Recurrence interval must be a positive number")
+	}
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+
+	if rule.Until != "" && rule.Count != 0 {
+		return fmt.Errorf("Recurrence cannot have both until and count")
+	}
+	if rule.Count < 0 {
+		return fmt.Errorf("Recurrence count must be a positive number")
+	}
+	if rule.Until != "" {
+		until, err := dates.Parse("recurrence.until", rule.Until)
+		if err != nil {
+			return err
+		}
+		rule.Until = until.Format(dates.Layout)
+		if rule.Until < event.Date {
+			return fmt.Errorf("Recurrence until date must not be before the event date")
+		}
+	}
+
+	return nil
+}
+
+// occurrenceDates returns the dates (YYYY-MM-DD) of the series' occurrences within [from, to],
+// excluding removed occurrences. Empty bounds are not allowed; from and to must be valid dates.
+func occurrenceDates(event *models.Event, from, to string) []string {
+	start, err := time.Parse("2006-01-02", event.Date)
+	if err != nil || event.Recurrence == nil {
+		return nil
+	}
+	step := recurrenceStepDays(event.Recurrence)
+
+	// Jump straight to the first occurrence on or after from.
+	index := 0
+	if fromDate, err := time.Parse("2006-01-02", from); err == nil && fromDate.After(start) {
+		days := int(fromDate.Sub(start).Hours() / 24)
+		index = (days + step - 1) / step
+	}
+
+	var dates []string
+	for ; index < maxOccurrencesPerSeries; index++ {
+		if event.Recurrence.Count > 0 && index >= event.Recurrence.Count {
+			break
+		}
+		date := start.AddDate(0, 0, index*step).Format("2006-01-02")
+		if date > to || (event.Recurrence.Until != "" && date > event.Recurrence.Until) {
+			break
+		}
+		if !containsDate(event.ExceptionDates, date) {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// isOccurrence reports whether date is an occurrence of the recurring event that has not been removed.
+func isOccurrence(event *models.Event, date string) bool {
+	dates := occurrenceDates(event, date, date)
+	return len(dates) == 1 && dates[0] == date
+}
+
+// expandEvent returns a copy of a recurring event for each of its occurrences within [from, to].
+// Each copy keeps the series' EventID and Recurrence, with Date, StartAt and EndAt set to the
+// occurrence. Occurrences keep the series' local time in its time zone across daylight saving
+// time changes.
+func expandEvent(event models.Event, from, to string) []models.Event {
+	loc := eventLocation(&event)
+	var occurrences []models.Event
+	for _, date := range occurrenceDates(&event, from, to) {
+		occurrence := event
+		occurrence.Date = date
+		if startAt, err := parseEventStart(date, event.StartTime, loc); err == nil {
+			occurrence.StartAt = startAt
+		}
+		if event.EndAt != nil {
+			if endAt, ok := localTime(date, event.EndTime, loc); ok {
+				occurrence.EndAt = &endAt
+			}
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences
+}
+
+// recurrenceStepDays returns the number of days between two consecutive occurrences.
+func recurrenceStepDays(rule *models.Recurrence) int {
+	interval := rule.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	if rule.Frequency == "weekly" {
+		return 7 * interval
+	}
+	return interval
+}
+
+// containsDate reports whether dates contains date.
+func containsDate(dates []string, date string) bool {
+	for _, d := range dates {
+		if d == date {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+/**
+ *  ReminderService periodically scans upcoming events and emails their owners a reminder
+ *  shortly before the event starts. Each event is reminded at most once.
+ *
+ *  @file       reminder_service.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - ReminderServiceInterface - Defines the contract for the reminder scheduler.
+ *
+ *  @methods
+ *  - NewReminderService(eventRepo, emailService) - Creates a new ReminderService with default settings.
+ *  - Start(ctx)                                  - Runs the scheduler on a real ticker until ctx is cancelled.
+ *  - Run(ctx, ticks)                             - Runs the scheduler on the given tick channel until ctx is cancelled.
+ *  - SendDueReminders(ctx)                       - Sends all reminders that are due at the current time.
+ *
+ *  @dependencies
+ *  - repositories.EventRepository: Provides GetEventsBetween to find upcoming events.
+ *  - EmailServiceInterface: Sends the reminder emails.
+ *  - EmailTemplateRenderer: Renders the reminder emails.
+ *  - NotificationGate, repositories.UserRepository: Hold reminders back during the owner's quiet hours; may be nil.
+ *    The UserRepository also gives the owner's language; without it reminders are written in English.
+ *
+ *  @behaviors
+ *  - Looks ahead `Window` from the current time for events that have a reminder configured.
+ *  - Sends a reminder once `now >= StartAt - ReminderMinutesBefore`.
+ *  - Marks the event with ReminderSent so it is not reminded again.
+ *  - The reminders due on a scan are sent together with SendBulk, and only delivered ones are marked.
+ *  - Cancelled events are not reminded.
+ *  - With a Gate, a reminder due during the owner's quiet hours is held back until they end, or
+ *    dropped if the event starts before then. Either way the event is marked as reminded.
+ *  - The clock (`Now`) and the tick source are injectable to allow testing without sleeping.
+ *
+ *  @example
+ *  ```
+ *  reminderService := NewReminderService(eventRepo, emailService)
+ *  go reminderService.Start(ctx)
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// ReminderServiceInterface defines the operations of the event reminder scheduler.
+type ReminderServiceInterface interface {
+	// Start runs the scheduler on a real ticker until the context is cancelled.
+	Start(ctx context.Context)
+
+	// Run runs the scheduler, checking for due reminders on every tick, until the context is cancelled.
+	Run(ctx context.Context, ticks <-chan time.Time)
+
+	// SendDueReminders sends all reminders due at the current time and returns how many were sent.
+	SendDueReminders(ctx context.Context) (int, error)
+}
+
+// ReminderService implements ReminderServiceInterface.
+type ReminderService struct {
+	EventRepo repositories.EventRepository // Repository used to query upcoming events.
+	Email     EmailServiceInterface        // Email service for sending reminders.
+	Templates *EmailTemplateRenderer       // Renders the reminder emails.
+	UserRepo  repositories.UserRepository  // Repository used to look up the owners' language and quiet hours; needed with a Gate.
+	Gate      *NotificationGate            // Holds reminders back during quiet hours; may be nil.
+	Interval  time.Duration                // How often the scheduler checks for due reminders.
+	Window    time.Duration                // How far ahead to look for upcoming events.
+	Now       func() time.Time             // Clock used by the scheduler; replaceable in tests.
+}
+
+// NewReminderService initializes a ReminderService that checks every minute for events in the next 24 hours.
+func NewReminderService(eventRepo repositories.EventRepository, emailService EmailServiceInterface) ReminderServiceInterface {
+	return &ReminderService{
+		EventRepo: eventRepo,
+		Email:     emailService,
+		Templates: NewEmailTemplateRenderer(),
+		Interval:  time.Minute,
+		Window:    24 * time.Hour,
+		Now:       time.Now,
+	}
+}
+
+// Start runs the scheduler on a time.Ticker until the context is cancelled.
+func (rs *ReminderService) Start(ctx context.Context) {
+	ticker := time.NewTicker(rs.Interval)
+	defer ticker.Stop()
+	rs.Run(ctx, ticker.C)
+}
+
+// Run checks for due reminders on every tick until the context is cancelled.
+func (rs *ReminderService) Run(ctx context.Context, ticks <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+			if _, err := rs.SendDueReminders(ctx); err != nil {
+				log.Printf("Failed to send event reminders: %v", err)
+			}
+		}
+	}
+}
+
+// SendDueReminders emails the owners of all events whose reminder time has been reached
+// and marks those events as reminded.
+func (rs *ReminderService) SendDueReminders(ctx context.Context) (int, error) {
+	now := rs.Now()
+
+	events, err := rs.EventRepo.GetEventsBetween(ctx, now, now.Add(rs.Window))
+	if err != nil {
+		return 0, err
+	}
+
+	var messages []EmailMessage
+	var due []*models.Event                 // Event of each message.
+	owners := make(map[string]*models.User) // Owners looked up for the gate, by email.
+	for i := range events {
+		event := &events[i]
+		if event.ReminderSent || event.ReminderMinutesBefore <= 0 || isCancelled(*event) {
+			continue
+		}
+
+		remindAt := event.StartAt.Add(-time.Duration(event.ReminderMinutesBefore) * time.Minute)
+		if now.Before(remindAt) {
+			continue
+		}
+
+		owner, err := rs.owner(ctx, owners, event.Email)
+		if err != nil {
+			log.Printf("Failed to look up the owner of event %s: %v", event.EventID, err)
+			continue
+		}
+		msg, err := rs.Templates.EventReminder(UserLocale(owner), event)
+		if err != nil {
+			log.Printf("Failed to render reminder for event %s: %v", event.EventID, err)
+			continue
+		}
+		msg.To = event.Email
+		held, err := rs.hold(ctx, owner, event, msg)
+		if err != nil {
+			log.Printf("Failed to hold back reminder for event %s: %v", event.EventID, err)
+			continue
+		}
+		if held {
+			rs.markReminded(ctx, event)
+			continue
+		}
+		messages = append(messages, msg)
+		due = append(due, event)
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	result, _ := rs.Email.SendBulk(ctx, messages)
+	sent := 0
+	for i, recipient := range result.Recipients {
+		event := due[i]
+		if recipient.Err != nil {
+			log.Printf("Failed to send reminder for event %s: %v", event.EventID, recipient.Err)
+			continue
+		}
+
+		rs.markReminded(ctx, event)
+		sent++
+	}
+
+	return sent, nil
+}
+
+// owner returns the owner of the events of email, for their language and quiet hours, or nil
+// without a UserRepo. owners caches the owners looked up during a scan.
+func (rs *ReminderService) owner(ctx context.Context, owners map[string]*models.User, email string) (*models.User, error) {
+	if rs.UserRepo == nil {
+		return nil, nil
+	}
+	owner, ok := owners[email]
+	if !ok {
+		user, err := rs.UserRepo.GetUserByEmail(ctx, email)
+		if isRepositoryFailure(err) {
+			return nil, err
+		}
+		owner = user // Nil for an owner that no longer exists, who has no quiet hours.
+		owners[email] = owner
+	}
+	return owner, nil
+}
+
+// hold holds the reminder of event back if its owner is in their quiet hours, dropping it if they
+// end after the event has started.
+func (rs *ReminderService) hold(ctx context.Context, owner *models.User, event *models.Event, msg EmailMessage) (bool, error) {
+	if rs.Gate == nil || rs.UserRepo == nil {
+		return false, nil
+	}
+	return rs.Gate.Hold(ctx, owner, msg, event.StartAt)
+}
+
+// markReminded marks event with ReminderSent so it is not reminded again.
+func (rs *ReminderService) markReminded(ctx context.Context, event *models.Event) {
+	event.ReminderSent = true
+	if err := rs.EventRepo.UpdateEvent(ctx, event); err != nil {
+		log.Printf("Failed to mark reminder as sent for event %s: %v", event.EventID, err)
+	}
+}
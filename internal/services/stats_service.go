@@ -0,0 +1,168 @@
+/**
+ *  StatsService summarises a user's activity for the "your activity" widget of the dashboard:
+ *  how many events, journal entries and friends they have, and on how many days this month
+ *  they wrote in their journal.
+ *
+ *  @file       stats_service.go
+ *  @package    services
+ *
+ *  @interfaces
+ *  - StatsServiceInterface: Defines the contract for reading a user's activity statistics.
+ *
+ *  @methods
+ *  - NewStatsService(eventRepo, journalRepo, friendRepo) - Initializes a new StatsService.
+ *  - GetUserStats(ctx, userEmail)                        - Counts the user's events, journals, friends and active days.
+ *
+ *  @behaviors
+ *  - Counts stop at MaxStatsCount, so a very active account costs a bounded number of reads.
+ *  - Journal entries in the trash are not counted.
+ *  - Active days are the days of the current month, up to today, with a journal entry.
+ *  - Statistics are cached in memory per user for CacheTTL, 10 minutes by default, so refreshing the
+ *    dashboard does not repeat the reads. Failed lookups are not cached.
+ *
+ *  @dependencies
+ *  - repositories.EventRepository, JournalRepository, FriendRepository: Count the user's documents.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// DefaultStatsCacheTTL is how long a user's statistics are cached by default.
+const DefaultStatsCacheTTL = 10 * time.Minute
+
+// MaxStatsCount is the largest count reported for events, journals and friends.
+const MaxStatsCount = 1000
+
+// StatsServiceInterface defines the contract for reading a user's activity statistics.
+type StatsServiceInterface interface {
+	// GetUserStats counts the user's events, journal entries and friends, and the days of the
+	// current month with a journal entry.
+	GetUserStats(ctx context.Context, userEmail string) (*models.UserStats, error)
+}
+
+// StatsService implements StatsServiceInterface with the event, journal and friend repositories.
+type StatsService struct {
+	EventRepo   repositories.EventRepository   // Counts the user's events.
+	JournalRepo repositories.JournalRepository // Counts the user's journal entries and their dates.
+	FriendRepo  repositories.FriendRepository  // Counts the user's friends.
+	CacheTTL    time.Duration                  // How long statistics are cached; 0 disables caching.
+	Now         func() time.Time               // Clock used for the current month and cache expiry; replaceable in tests.
+
+	mutex sync.Mutex
+	cache map[string]statsCacheEntry
+}
+
+// statsCacheEntry holds a user's statistics until they expire.
+type statsCacheEntry struct {
+	stats     models.UserStats
+	expiresAt time.Time
+}
+
+// NewStatsService initializes a StatsService with the given repositories.
+func NewStatsService(eventRepo repositories.EventRepository, journalRepo repositories.JournalRepository, friendRepo repositories.FriendRepository) StatsServiceInterface {
+	return &StatsService{
+		EventRepo:   eventRepo,
+		JournalRepo: journalRepo,
+		FriendRepo:  friendRepo,
+		CacheTTL:    DefaultStatsCacheTTL,
+		Now:         time.Now,
+	}
+}
+
+// GetUserStats returns the user's statistics, from the cache if they were computed less than
+// CacheTTL ago.
+func (ss *StatsService) GetUserStats(ctx context.Context, userEmail string) (*models.UserStats, error) {
+	if stats, ok := ss.cachedStats(userEmail); ok {
+		return stats, nil
+	}
+
+	stats, err := ss.countStats(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	ss.cacheStats(userEmail, *stats)
+	return stats, nil
+}
+
+// countStats reads the user's statistics from the repositories.
+func (ss *StatsService) countStats(ctx context.Context, userEmail string) (*models.UserStats, error) {
+	var stats models.UserStats
+	var err error
+
+	if stats.Events, err = ss.EventRepo.CountEvents(ctx, userEmail, MaxStatsCount); err != nil {
+		return nil, fmt.Errorf("Failed to count events: %w", err)
+	}
+	if stats.Journals, err = ss.JournalRepo.CountJournals(ctx, userEmail, MaxStatsCount); err != nil {
+		return nil, fmt.Errorf("Failed to count journals: %w", err)
+	}
+	if stats.Friends, err = ss.FriendRepo.CountFriends(ctx, userEmail, MaxStatsCount); err != nil {
+		return nil, fmt.Errorf("Failed to count friends: %w", err)
+	}
+
+	// A user writes at most one journal entry per date, so each entry this month is an active day.
+	today := ss.Now()
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	journals, err := ss.JournalRepo.SearchJournals(ctx, userEmail, "", monthStart.Format("2006-01-02"), today.Format("2006-01-02"), 0)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve journals: %w", err)
+	}
+	days := make(map[string]bool, len(journals))
+	for _, journal := range journals {
+		days[journal.Date] = true
+	}
+	stats.ActiveDays = len(days)
+
+	return &stats, nil
+}
+
+// cachedStats returns a copy of the user's unexpired cached statistics.
+func (ss *StatsService) cachedStats(userEmail string) (*models.UserStats, bool) {
+	if ss.CacheTTL <= 0 {
+		return nil, false
+	}
+
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+	entry, ok := ss.cache[userEmail]
+	if !ok || !ss.Now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	stats := entry.stats
+	return &stats, true
+}
+
+// cacheStats stores the user's statistics and evicts expired entries.
+func (ss *StatsService) cacheStats(userEmail string, stats models.UserStats) {
+	if ss.CacheTTL <= 0 {
+		return
+	}
+
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+	if ss.cache == nil {
+		ss.cache = make(map[string]statsCacheEntry)
+	}
+
+	now := ss.Now()
+	for email, entry := range ss.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(ss.cache, email)
+		}
+	}
+	ss.cache[userEmail] = statsCacheEntry{stats: stats, expiresAt: now.Add(ss.CacheTTL)}
+}
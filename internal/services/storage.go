@@ -0,0 +1,157 @@
+/**
+ *  Storage Service stores uploaded files: profile pictures, served from public URLs, and journal
+ *  attachments, which only the server can read. The Google Cloud Storage implementation uses the Cloud Storage JSON API with the application
+ *  default credentials, like the Firestore client.
+ *
+ *  @interface StorageServiceInterface
+ *  @struct   GCSStorageService
+ *  @methods
+ *  - NewGCSStorageService(ctx, bucket)                 - Initializes a GCSStorageService for a bucket.
+ *  - Upload(ctx, objectName, contentType, data)        - Stores a publicly readable object and returns its URL.
+ *  - UploadPrivate(ctx, objectName, contentType, data) - Stores an object only the server can read and returns its URL.
+ *  - Download(ctx, objectURL)                          - Reads the object behind a URL returned by an upload.
+ *  - Delete(ctx, objectURL)                            - Deletes the object behind a URL returned by an upload.
+ *
+ *  @behaviors
+ *  - Objects are addressed by https://storage.googleapis.com/{bucket}/{objectName}. The bucket itself
+ *    stays private: Upload makes its object publicly readable with an ACL, so the bucket must use
+ *    fine-grained access control, and UploadPrivate's objects can only be read with Download.
+ *  - Download reports an object that does not exist, or a URL outside the bucket, as repositories.ErrNotFound.
+ *  - Delete ignores URLs outside the bucket and objects that no longer exist, so removing a file
+ *    twice, or a URL set before uploads were stored in the bucket, is not an error.
+ *
+ *  @file      storage.go
+ *  @project   DailyVerse
+ *  @environment_variables
+ *  - GCS_BUCKET: The Cloud Storage bucket for uploads. Uploads are disabled when it is not set.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+	storage "google.golang.org/api/storage/v1"
+
+	"proh2052-group6/internal/repositories"
+)
+
+// gcsObjectURL is the base URL of Cloud Storage objects; only public objects can be fetched from it.
+const gcsObjectURL = "https://storage.googleapis.com/"
+
+// StorageServiceInterface defines the contract for storing uploaded files.
+type StorageServiceInterface interface {
+	// Upload stores data under objectName, readable by anyone, and returns the URL the object is served from.
+	Upload(ctx context.Context, objectName, contentType string, data []byte) (string, error)
+	// UploadPrivate stores data under objectName, readable only through Download, and returns its URL.
+	UploadPrivate(ctx context.Context, objectName, contentType string, data []byte) (string, error)
+	// Download reads the object behind a URL returned by Upload or UploadPrivate.
+	Download(ctx context.Context, objectURL string) ([]byte, error)
+	// Delete removes the object behind a URL returned by Upload or UploadPrivate.
+	Delete(ctx context.Context, objectURL string) error
+}
+
+// GCSStorageService implements StorageServiceInterface with Google Cloud Storage.
+type GCSStorageService struct {
+	Service *storage.Service // Cloud Storage JSON API client.
+	Bucket  string           // Bucket the objects are stored in.
+}
+
+// NewGCSStorageService initializes a GCSStorageService storing objects in bucket.
+func NewGCSStorageService(ctx context.Context, bucket string) (StorageServiceInterface, error) {
+	if bucket == "" {
+		return nil, errors.New("Storage bucket is not configured")
+	}
+	service, err := storage.NewService(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &GCSStorageService{Service: service, Bucket: bucket}, nil
+}
+
+// Upload stores data in the bucket under objectName, readable by anyone, and returns its public URL.
+func (gs *GCSStorageService) Upload(ctx context.Context, objectName, contentType string, data []byte) (string, error) {
+	return gs.insert(ctx, objectName, contentType, "publicRead", data)
+}
+
+// UploadPrivate stores data in the bucket under objectName, readable only by the bucket owners, and
+// returns its URL, which is only useful to Download and Delete.
+func (gs *GCSStorageService) UploadPrivate(ctx context.Context, objectName, contentType string, data []byte) (string, error) {
+	return gs.insert(ctx, objectName, contentType, "private", data)
+}
+
+// insert stores data in the bucket under objectName with the predefined ACL acl and returns its URL.
+func (gs *GCSStorageService) insert(ctx context.Context, objectName, contentType, acl string, data []byte) (string, error) {
+	object := &storage.Object{Name: objectName, ContentType: contentType}
+	call := gs.Service.Objects.Insert(gs.Bucket, object).PredefinedAcl(acl).Media(bytes.NewReader(data))
+	if _, err := call.Context(ctx).Do(); err != nil {
+		return "", err
+	}
+	return gs.objectURL(objectName), nil
+}
+
+// Download reads the object behind objectURL from the bucket.
+func (gs *GCSStorageService) Download(ctx context.Context, objectURL string) ([]byte, error) {
+	objectName := gs.objectName(objectURL)
+	if objectName == "" {
+		return nil, fmt.Errorf("Stored object %w", repositories.ErrNotFound)
+	}
+
+	resp, err := gs.Service.Objects.Get(gs.Bucket, objectName).Context(ctx).Download()
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil, fmt.Errorf("Stored object %w", repositories.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Delete removes the object behind objectURL from the bucket.
+func (gs *GCSStorageService) Delete(ctx context.Context, objectURL string) error {
+	objectName := gs.objectName(objectURL)
+	if objectName == "" {
+		return nil
+	}
+
+	err := gs.Service.Objects.Delete(gs.Bucket, objectName).Context(ctx).Do()
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// objectURL returns the URL of an object in the bucket.
+func (gs *GCSStorageService) objectURL(objectName string) string {
+	return gcsObjectURL + gs.Bucket + "/" + (&url.URL{Path: objectName}).EscapedPath()
+}
+
+// objectName returns the name of the object in the bucket behind objectURL, or "" if it is not in the bucket.
+func (gs *GCSStorageService) objectName(objectURL string) string {
+	prefix := gs.objectURL("")
+	if !strings.HasPrefix(objectURL, prefix) {
+		return ""
+	}
+	objectName, err := url.PathUnescape(strings.TrimPrefix(objectURL, prefix))
+	if err != nil {
+		return ""
+	}
+	return objectName
+}
@@ -0,0 +1,127 @@
+/**
+ *  Calendar feeds let calendar apps such as Google Calendar subscribe to a user's events, instead of
+ *  importing a one-off export. The feed URL contains a secret token, since calendar apps cannot log in.
+ *
+ *  @file       timetable_feed.go
+ *  @package    services
+ *
+ *  @methods
+ *  - CreateFeedToken(ctx, userEmail)  - Gives the user a new feed token, so the old feed URL stops working.
+ *  - GetFeedToken(ctx, userEmail)     - Returns the user's feed token.
+ *  - RevokeFeedToken(ctx, userEmail)  - Turns the user's feed off.
+ *  - ExportFeed(ctx, token, w)        - Writes the events of the feed with the token as ICS.
+ *  - newFeedToken()                   - Generates an unguessable token.
+ *
+ *  @behaviors
+ *  - Tokens are 32 random bytes, hex encoded, stored as User.FeedToken. A user has at most one feed:
+ *    creating a token replaces the old one.
+ *  - The feed contains the events from FeedPastMonths before to FeedFutureYears after today, in the
+ *    user's time zone, built by ExportTimetable. Like the export, it never contains the user's email.
+ *  - Unknown and revoked tokens, and the feeds of disabled accounts, are reported as ErrFeedNotFound.
+ *
+ *  @errors
+ *  - ErrFeedsDisabled: No UserRepo is configured.
+ *  - ErrFeedNotFound: The user has no feed, or no feed has the token.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"proh2052-group6/pkg/dates"
+)
+
+// Range of the calendar feed, relative to the user's today.
+const (
+	FeedPastMonths  = 1
+	FeedFutureYears = 1
+)
+
+var (
+	// ErrFeedsDisabled is returned when calendar feeds are used without a UserRepo.
+	ErrFeedsDisabled = errors.New("Calendar feeds are not available")
+	// ErrFeedNotFound is returned for a user without a feed and for an unknown or revoked token.
+	ErrFeedNotFound = errors.New("Calendar feed not found")
+)
+
+// CreateFeedToken gives the user a new calendar feed token. The URL with the old token stops working.
+func (ts *TimetableService) CreateFeedToken(ctx context.Context, userEmail string) (string, error) {
+	if ts.UserRepo == nil {
+		return "", ErrFeedsDisabled
+	}
+	token, err := newFeedToken()
+	if err != nil {
+		return "", err
+	}
+	if err := ts.UserRepo.UpdateUser(ctx, userEmail, map[string]interface{}{"FeedToken": token}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// GetFeedToken returns the user's calendar feed token, or ErrFeedNotFound if the feed is off.
+func (ts *TimetableService) GetFeedToken(ctx context.Context, userEmail string) (string, error) {
+	if ts.UserRepo == nil {
+		return "", ErrFeedsDisabled
+	}
+	user, err := ts.UserRepo.GetUserByEmail(ctx, userEmail)
+	if err != nil {
+		return "", err
+	}
+	if user.FeedToken == "" {
+		return "", ErrFeedNotFound
+	}
+	return user.FeedToken, nil
+}
+
+// RevokeFeedToken turns the user's calendar feed off, so its URL stops working.
+func (ts *TimetableService) RevokeFeedToken(ctx context.Context, userEmail string) error {
+	if ts.UserRepo == nil {
+		return ErrFeedsDisabled
+	}
+	return ts.UserRepo.UpdateUser(ctx, userEmail, map[string]interface{}{"FeedToken": ""})
+}
+
+// ExportFeed writes the events of the calendar feed with the given token to w as an ICS calendar.
+func (ts *TimetableService) ExportFeed(ctx context.Context, token string, w io.Writer) error {
+	if ts.UserRepo == nil {
+		return ErrFeedsDisabled
+	}
+	if token == "" {
+		return ErrFeedNotFound
+	}
+	user, err := ts.UserRepo.GetUserByFeedToken(ctx, token)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err != nil || user.FeedToken != token || user.Disabled {
+		return ErrFeedNotFound
+	}
+
+	location, _ := LocationForUser(user)
+	today := ts.Now().In(location)
+	from := today.AddDate(0, -FeedPastMonths, 0).Format(dates.Layout)
+	to := today.AddDate(FeedFutureYears, 0, 0).Format(dates.Layout)
+	return ts.ExportTimetable(ctx, user.Email, from, to, w)
+}
+
+// newFeedToken generates a random hex token of 32 bytes.
+func newFeedToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("Failed to generate feed token: %v", err)
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -10,32 +10,54 @@
  *  - TimetableServiceInterface - Defines the contract for timetable-related operations.
  *
  *  @methods
- *  - NewTimetableService(eventRepo)                   - Creates a new instance of TimetableService.
- *  - ImportTimetable(ctx, userEmail, icsContent)      - Parses and imports events from ICS content.
+ *  - NewTimetableService(eventRepo)                        - Creates a new instance of TimetableService.
+ *  - ImportTimetable(ctx, userEmail, icsContent, dryRun)   - Parses and imports events from ICS content.
+ *  - ExportTimetable(ctx, userEmail, from, to, w)          - Writes the user's events as an ICS calendar.
+ *  - UndoImport(ctx, userEmail, batchID)                   - Deletes the events created by an import.
+ *  - GetImportBatches(ctx, userEmail)                      - Lists the imports that can be undone.
+ *  - The calendar feed methods are in timetable_feed.go.
  *
  *  @dependencies
  *  - EventRepository: Handles CRUD operations for events.
+ *  - UserRepository: Stores and looks up the calendar feed tokens.
  *  - "github.com/arran4/golang-ical": Provides ICS parsing capabilities.
  *  - models.Event: Represents the data structure for an event.
+ *  - models.ImportResult: Reports how many events were imported, skipped or failed, and why.
  *
  *  @behaviors
  *  - Parses ICS (iCalendar) content to extract event details such as title, description, location, and timing.
  *  - Saves each extracted event into the database using the EventRepository.
- *  - Ignores events with missing or invalid start and end times.
+ *  - Accepts DTSTART/DTEND in UTC (20240115T081500Z), TZID-qualified local time, floating local time,
+ *    date-only and RFC3339 formats. TZID parameters are honoured; times are converted to and stored in
+ *    the service's Location (Europe/Oslo by default), which is recorded as the events' TimeZone.
+ *  - Skips events with missing or invalid start and end times and reports the reason.
+ *  - Continues after an event fails to save, so one bad event does not abort the whole import.
+ *  - In a dry run, parses and validates every event without writing anything.
+ *  - Exports events with DTSTART/DTEND in the service's Location, converted from the events' own
+ *    TimeZone (or read in the service's Location for events without one), recurring events as RRULE with
+ *    EXDATE for removed occurrences, and imported events under their original UID. Each event's STATUS
+ *    is exported, so calendars remove cancelled events.
+ *  - Identifies each event by its ICS UID (or a hash of title, date and times if it has none), stored as
+ *    Event.ExternalID. Events imported before are updated in place instead of being duplicated.
+ *  - Events created by an import are stamped with a random ImportBatchID, returned as the result's
+ *    BatchID. Undoing the import deletes only those events: events created manually and events an
+ *    earlier import created, including the ones this import updated, are never touched.
  *
  *  @example
  *  Import Timetable:
  *  ```
  *  timetableService := NewTimetableService(eventRepo)
- *  err := timetableService.ImportTimetable(ctx, "user@example.com", icsContent)
+ *  result, err := timetableService.ImportTimetable(ctx, "user@example.com", icsContent, false)
  *  if err != nil {
  *      log.Fatal("Failed to import timetable:", err)
  *  }
+ *  log.Printf("Imported %d events, skipped %d, failed %d", result.Imported, result.Skipped, result.Failed)
  *  ```
  *
  *  @errors
  *  - Returns an error if the ICS content cannot be parsed.
- *  - Returns an error if saving an event to the repository fails.
+ *  - ErrImportBatchNotFound: The user has no events from the import to undo.
+ *  - Failures to save individual events are reported in the result, not as an error.
  *
  *  @authors
  *      - Aayush
@@ -48,30 +70,80 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	ics "github.com/arran4/golang-ical"
+	"io"
 	"strings"
 	"time"
 
 	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/dates"
 	"proh2052-group6/pkg/models"
 )
 
 // TimetableServiceInterface defines the operations for managing timetables.
 type TimetableServiceInterface interface {
 	// ImportTimetable parses ICS content and imports events for a specific user.
-	ImportTimetable(ctx context.Context, userEmail, icsContent string) error
+	// If dryRun is true, the events are validated but not saved.
+	ImportTimetable(ctx context.Context, userEmail, icsContent string, dryRun bool) (*models.ImportResult, error)
+
+	// ExportTimetable writes the user's events within the optional [from, to] date range to w as an ICS calendar.
+	ExportTimetable(ctx context.Context, userEmail, from, to string, w io.Writer) error
+
+	// UndoImport deletes the events created by the import with the given batch ID and returns how many were deleted.
+	UndoImport(ctx context.Context, userEmail, batchID string) (int, error)
+
+	// GetImportBatches lists the user's imports whose events are still stored, most recent first.
+	GetImportBatches(ctx context.Context, userEmail string) ([]models.ImportBatch, error)
+
+	// CreateFeedToken gives the user a new calendar feed token, replacing the old one, and returns it.
+	CreateFeedToken(ctx context.Context, userEmail string) (string, error)
+
+	// GetFeedToken returns the user's calendar feed token.
+	GetFeedToken(ctx context.Context, userEmail string) (string, error)
+
+	// RevokeFeedToken turns the user's calendar feed off.
+	RevokeFeedToken(ctx context.Context, userEmail string) error
+
+	// ExportFeed writes the events of the calendar feed with the given token to w as an ICS calendar.
+	ExportFeed(ctx context.Context, token string, w io.Writer) error
 }
 
+// ErrImportBatchNotFound is returned when undoing an import the user has no events from.
+var ErrImportBatchNotFound = errors.New("Import batch not found")
+
+// defaultTimetableLocation is the time zone NTNU timetables are shown in.
+const defaultTimetableLocation = "Europe/Oslo"
+
+// ICS timestamp layouts accepted for DTSTART and DTEND values.
+const (
+	icsTimestampUTC   = "20060102T150405Z"
+	icsTimestampLocal = "20060102T150405"
+	icsDate           = "20060102"
+)
+
 // TimetableService provides implementation of TimetableServiceInterface.
 type TimetableService struct {
 	EventRepo repositories.EventRepository // Repository for event data operations.
+	UserRepo  repositories.UserRepository  // Calendar feed tokens; feeds are disabled if nil.
+	Location  *time.Location               // Time zone used for event dates and floating ICS times.
+	Now       func() time.Time             // Clock used for the import time of batches and the feed range; replaceable in tests.
 }
 
 // NewTimetableService initializes a new instance of TimetableService.
 func NewTimetableService(eventRepo repositories.EventRepository) TimetableServiceInterface {
+	location, err := time.LoadLocation(defaultTimetableLocation)
+	if err != nil {
+		location = time.UTC
+	}
 	return &TimetableService{
 		EventRepo: eventRepo,
+		Location:  location,
+		Now:       time.Now,
 	}
 }
 
@@ -80,39 +152,52 @@ func NewTimetableService(eventRepo repositories.EventRepository) TimetableServic
 //   - ctx: The context for handling deadlines and cancellations.
 //   - userEmail: The email of the user for whom the timetable is being imported.
 //   - icsContent: The raw ICS content to be parsed.
+//   - dryRun: If true, events are parsed and validated but not saved.
 //
 // Returns:
-//   - error: Returns an error if parsing or saving fails.
-func (ts *TimetableService) ImportTimetable(ctx context.Context, userEmail, icsContent string) error {
+//   - *models.ImportResult: Counts and per-event outcomes of the import.
+//   - error: Returns an error if the ICS content cannot be parsed.
+func (ts *TimetableService) ImportTimetable(ctx context.Context, userEmail, icsContent string, dryRun bool) (*models.ImportResult, error) {
 	// Parse the ICS content.
 	cal, err := ics.ParseCalendar(strings.NewReader(icsContent))
 	if err != nil {
-		return fmt.Errorf("Failed to parse ICS content: %v", err)
+		return nil, fmt.Errorf("Failed to parse ICS content: %v", err)
 	}
 
+	result := &models.ImportResult{DryRun: dryRun, Events: []models.ImportEventResult{}}
+
+	// Every event created by this import is stamped with the batch, so the import can be undone.
+	batchID, err := newImportBatchID()
+	if err != nil {
+		return nil, err
+	}
+	importedAt := ts.Now().UTC()
+
 	// Iterate over the events in the calendar.
 	for _, event := range cal.Events() {
 		// Extract event details.
-		summary := event.GetProperty(ics.ComponentPropertySummary).Value
-		description := event.GetProperty(ics.ComponentPropertyDescription).Value
-		location := event.GetProperty(ics.ComponentPropertyLocation).Value
-
-		dtStartProp := event.GetProperty(ics.ComponentPropertyDtStart)
-		dtEndProp := event.GetProperty(ics.ComponentPropertyDtEnd)
+		summary := propertyValue(event, ics.ComponentPropertySummary)
+		description := propertyValue(event, ics.ComponentPropertyDescription)
+		location := propertyValue(event, ics.ComponentPropertyLocation)
 
-		// Skip events with missing start or end time.
-		if dtStartProp == nil || dtEndProp == nil {
-			continue
-		}
+		outcome := models.ImportEventResult{Title: summary}
 
-		// Parse start and end times.
-		dtStart, err := time.Parse(time.RFC3339, dtStartProp.Value)
+		// Parse start and end times, skipping events where either is missing or invalid.
+		dtStart, err := ts.parseEventTime(event, ics.ComponentPropertyDtStart)
 		if err != nil {
+			result.Skipped++
+			outcome.Status = "skipped"
+			outcome.Reason = fmt.Sprintf("Invalid start time: %v", err)
+			result.Events = append(result.Events, outcome)
 			continue
 		}
 
-		dtEnd, err := time.Parse(time.RFC3339, dtEndProp.Value)
+		dtEnd, err := ts.parseEventTime(event, ics.ComponentPropertyDtEnd)
 		if err != nil {
+			result.Skipped++
+			outcome.Status = "skipped"
+			outcome.Reason = fmt.Sprintf("Invalid end time: %v", err)
+			result.Events = append(result.Events, outcome)
 			continue
 		}
 
@@ -124,16 +209,346 @@ func (ts *TimetableService) ImportTimetable(ctx context.Context, userEmail, icsC
 			Date:          dtStart.Format("2006-01-02"),
 			StartTime:     dtStart.Format("15:04"),
 			EndTime:       dtEnd.Format("15:04"),
+			StartAt:       dtStart,
+			EndAt:         &dtEnd,
+			TimeZone:      zoneName(ts.Location),
 			EventTypeID:   "private",
-			Status:        "confirmed",
+			Status:        EventStatusConfirmed,
 			StreetAddress: location,
 		}
+		newEvent.ExternalID = externalEventID(event, &newEvent)
+		outcome.Date = newEvent.Date
+		outcome.StartTime = newEvent.StartTime
+		outcome.EndTime = newEvent.EndTime
+
+		// Look for the same event from an earlier import.
+		existing, err := ts.EventRepo.GetEventByExternalID(ctx, userEmail, newEvent.ExternalID)
+		if err != nil {
+			result.Failed++
+			outcome.Status = "failed"
+			outcome.Reason = fmt.Sprintf("Failed to check for existing event: %v", err)
+			result.Events = append(result.Events, outcome)
+			continue
+		}
+
+		if existing != nil {
+			// Refresh the existing event with the imported details, keeping the user's own settings.
+			if !dryRun {
+				newEvent.EventID = existing.EventID
+				newEvent.EventTypeID = existing.EventTypeID
+				newEvent.ReminderMinutesBefore = existing.ReminderMinutesBefore
+				newEvent.ReminderSent = existing.ReminderSent && existing.StartAt.Equal(newEvent.StartAt)
+				newEvent.ImportBatchID = existing.ImportBatchID
+				newEvent.ImportedAt = existing.ImportedAt
+				if existing.Status != "" {
+					// A re-import does not undo the user marking the event tentative or cancelled.
+					newEvent.Status, newEvent.CancelledAt = existing.Status, existing.CancelledAt
+				}
+				if err := ts.EventRepo.UpdateEvent(ctx, &newEvent); err != nil {
+					result.Failed++
+					outcome.Status = "failed"
+					outcome.Reason = fmt.Sprintf("Failed to update event: %v", err)
+					result.Events = append(result.Events, outcome)
+					continue
+				}
+			}
+
+			result.Existing++
+			outcome.Status = "existing"
+			result.Events = append(result.Events, outcome)
+			continue
+		}
+
+		// Save the event to the repository unless this is a dry run.
+		if !dryRun {
+			newEvent.ImportBatchID = batchID
+			newEvent.ImportedAt = &importedAt
+			if err := ts.EventRepo.CreateEvent(ctx, &newEvent); err != nil {
+				result.Failed++
+				outcome.Status = "failed"
+				outcome.Reason = fmt.Sprintf("Failed to save event: %v", err)
+				result.Events = append(result.Events, outcome)
+				continue
+			}
+		}
+
+		result.Imported++
+		outcome.Status = "imported"
+		result.Events = append(result.Events, outcome)
+	}
+
+	if !dryRun && result.Imported > 0 {
+		result.BatchID = batchID
+	}
+	return result, nil
+}
+
+// UndoImport deletes the user's events created by the import with the given batch ID.
+// Returns ErrImportBatchNotFound if there are none, e.g. because the import was already undone.
+func (ts *TimetableService) UndoImport(ctx context.Context, userEmail, batchID string) (int, error) {
+	deleted, err := ts.EventRepo.DeleteEventsByBatch(ctx, userEmail, batchID)
+	if err != nil {
+		return deleted, fmt.Errorf("Failed to undo import: %w", err)
+	}
+	if deleted == 0 {
+		return 0, ErrImportBatchNotFound
+	}
+	return deleted, nil
+}
+
+// GetImportBatches lists the user's imports whose events are still stored, most recent first.
+func (ts *TimetableService) GetImportBatches(ctx context.Context, userEmail string) ([]models.ImportBatch, error) {
+	batches, err := ts.EventRepo.GetImportBatches(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch import batches: %w", err)
+	}
+	return batches, nil
+}
+
+// newImportBatchID returns a random ID for the events created by an import.
+func newImportBatchID() (string, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("Failed to start import: %v", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// ExportTimetable writes the user's own events to w as an ICS calendar. If from or to is set,
+// only events (or recurring series with an occurrence) within that date range are included.
+// Parameters:
+//   - ctx: The context for handling deadlines and cancellations.
+//   - userEmail: The email of the user whose events are exported.
+//   - from, to: Optional inclusive date range (YYYY-MM-DD).
+//   - w: The writer the ICS content is written to.
+//
+// Returns:
+//   - error: Returns an error if the date range is invalid or the events cannot be fetched.
+func (ts *TimetableService) ExportTimetable(ctx context.Context, userEmail, from, to string, w io.Writer) error {
+	if _, _, err := dates.ParseRange(from, to); err != nil {
+		return err
+	}
+
+	page, err := ts.EventRepo.GetAllEvents(ctx, userEmail, models.EventQuery{From: from, To: to})
+	if err != nil {
+		return err
+	}
+	series, err := ts.EventRepo.GetRecurringEvents(ctx, userEmail)
+	if err != nil {
+		return err
+	}
+
+	cal := ics.NewCalendar()
+	cal.SetProductId("-//DailyVerse//Events//EN")
+	cal.SetMethod(ics.MethodPublish)
+	cal.SetXWRCalName("DailyVerse")
+	cal.SetXWRTimezone(ts.Location.String())
+
+	for i := range page.Items {
+		// Series are added below, since their first occurrence may lie outside the range.
+		if page.Items[i].Recurrence == nil {
+			ts.addExportEvent(cal, &page.Items[i])
+		}
+	}
+
+	// A series is included if any of its occurrences falls within the range.
+	upper := to
+	if upper == "" {
+		upper = "9999-12-31"
+	}
+	for i := range series {
+		if len(occurrenceDates(&series[i], from, upper)) > 0 {
+			ts.addExportEvent(cal, &series[i])
+		}
+	}
+
+	return cal.SerializeTo(w)
+}
+
+// addExportEvent adds an event to the calendar. Events with an invalid date or time are left out.
+func (ts *TimetableService) addExportEvent(cal *ics.Calendar, event *models.Event) {
+	// The strings of events stored before time zones were recorded are read in the service's Location.
+	location := ts.Location
+	if event.TimeZone != "" {
+		if loaded, err := LoadTimeZone(event.TimeZone); err == nil {
+			location = loaded
+		}
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", event.Date, location)
+	if err != nil {
+		return
+	}
+
+	uid := event.ExternalID
+	if uid == "" {
+		uid = event.EventID + "@dailyverse"
+	}
+
+	vevent := cal.AddEvent(uid)
+	vevent.SetDtStampTime(time.Now())
+	vevent.SetSummary(event.Title)
+	switch event.Status {
+	case EventStatusTentative:
+		vevent.SetStatus(ics.ObjectStatusTentative)
+	case EventStatusCancelled:
+		vevent.SetStatus(ics.ObjectStatusCancelled)
+	default:
+		vevent.SetStatus(ics.ObjectStatusConfirmed)
+	}
+	if event.Description != "" {
+		vevent.SetDescription(event.Description)
+	}
+	if event.StreetAddress != "" {
+		vevent.SetLocation(event.StreetAddress)
+	}
+
+	// All-day events, and events without a start time, are exported with DATE-valued DTSTART and DTEND.
+	if event.AllDay || event.StartTime == "" {
+		vevent.SetAllDayStartAt(date)
+		vevent.SetAllDayEndAt(date.AddDate(0, 0, 1))
+		ts.addExportRecurrence(vevent, event, date, true, location)
+		return
+	}
+
+	start, ok := localTime(event.Date, event.StartTime, location)
+	if !ok {
+		return
+	}
+	end := start
+	if event.EndTime != "" {
+		if parsed, ok := localTime(event.Date, event.EndTime, location); ok {
+			end = parsed
+		}
+		// An end time before the start time means the event ends the next day.
+		if end.Before(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+	}
+	ts.setExportTime(&vevent.ComponentBase, ics.ComponentPropertyDtStart, start)
+	ts.setExportTime(&vevent.ComponentBase, ics.ComponentPropertyDtEnd, end)
+	ts.addExportRecurrence(vevent, event, start, false, location)
+}
+
+// addExportRecurrence adds the RRULE and EXDATE properties of a recurring event.
+// start is the first occurrence, used to give UNTIL and EXDATE the same form as DTSTART, and
+// location is the time zone the event's dates are in.
+func (ts *TimetableService) addExportRecurrence(vevent *ics.VEvent, event *models.Event, start time.Time, allDay bool, location *time.Location) {
+	rule := event.Recurrence
+	if rule == nil {
+		return
+	}
+
+	parts := []string{"FREQ=" + strings.ToUpper(rule.Frequency)}
+	if rule.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", rule.Interval))
+	}
+	if rule.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", rule.Count))
+	}
+	if until, err := time.ParseInLocation("2006-01-02", rule.Until, location); err == nil {
+		if allDay {
+			parts = append(parts, "UNTIL="+until.Format(icsDate))
+		} else {
+			// UNTIL must be in UTC when DTSTART has a time zone; it is the start of the last occurrence.
+			last := time.Date(until.Year(), until.Month(), until.Day(), start.Hour(), start.Minute(), 0, 0, location)
+			parts = append(parts, "UNTIL="+last.UTC().Format(icsTimestampUTC))
+		}
+	}
+	vevent.AddRrule(strings.Join(parts, ";"))
 
-		// Save the event to the repository.
-		if err := ts.EventRepo.CreateEvent(ctx, &newEvent); err != nil {
-			return fmt.Errorf("Failed to save event: %v", err)
+	for _, exception := range event.ExceptionDates {
+		date, err := time.ParseInLocation("2006-01-02", exception, location)
+		if err != nil {
+			continue
 		}
+		if allDay {
+			vevent.AddExdate(date.Format(icsDate), ics.WithValue(string(ics.ValueDataTypeDate)))
+			continue
+		}
+		occurrence := time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), 0, 0, location)
+		vevent.AddExdate(ts.formatExportTime(occurrence), ts.exportTimeParams()...)
+	}
+}
+
+// setExportTime sets a date-time property in the service's Location.
+func (ts *TimetableService) setExportTime(component *ics.ComponentBase, property ics.ComponentProperty, t time.Time) {
+	component.SetProperty(property, ts.formatExportTime(t), ts.exportTimeParams()...)
+}
+
+// formatExportTime formats t as an ICS timestamp: in UTC if the service's Location is UTC,
+// otherwise as local time to be qualified with a TZID.
+func (ts *TimetableService) formatExportTime(t time.Time) string {
+	if ts.Location == time.UTC {
+		return t.UTC().Format(icsTimestampUTC)
+	}
+	return t.In(ts.Location).Format(icsTimestampLocal)
+}
+
+// exportTimeParams returns the TZID parameter for timestamps formatted by formatExportTime.
+func (ts *TimetableService) exportTimeParams() []ics.PropertyParameter {
+	if ts.Location == time.UTC {
+		return nil
 	}
+	return []ics.PropertyParameter{&ics.KeyValues{Key: string(ics.ParameterTzid), Value: []string{ts.Location.String()}}}
+}
 
-	return nil
+// parseEventTime parses a DTSTART or DTEND property of an ICS event and converts it to the service's Location.
+// UTC timestamps, TZID-qualified and floating local timestamps, dates and RFC3339 values are supported.
+func (ts *TimetableService) parseEventTime(event *ics.VEvent, property ics.ComponentProperty) (time.Time, error) {
+	prop := event.GetProperty(property)
+	if prop == nil || prop.Value == "" {
+		return time.Time{}, fmt.Errorf("missing %s", property)
+	}
+	value := prop.Value
+
+	// Floating times are interpreted in the service's Location unless a TZID is given.
+	location := ts.Location
+	if tzid, ok := prop.ICalParameters["TZID"]; ok && len(tzid) > 0 {
+		loaded, err := time.LoadLocation(tzid[0])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", tzid[0])
+		}
+		location = loaded
+	}
+
+	var parsed time.Time
+	var err error
+	switch {
+	case strings.HasSuffix(value, "Z") && len(value) == len(icsTimestampUTC):
+		parsed, err = time.ParseInLocation(icsTimestampUTC, value, time.UTC)
+	case len(value) == len(icsTimestampLocal):
+		parsed, err = time.ParseInLocation(icsTimestampLocal, value, location)
+	case len(value) == len(icsDate):
+		parsed, err = time.ParseInLocation(icsDate, value, location)
+	default:
+		parsed, err = time.Parse(time.RFC3339, value)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unsupported value %q", value)
+	}
+	return parsed.In(ts.Location), nil
+}
+
+// externalEventID returns a stable identifier for an imported ICS event. It is the event's UID,
+// qualified by its RECURRENCE-ID for overridden occurrences of a recurring event. Events without
+// a UID are identified by a hash of their title, date and times.
+func externalEventID(event *ics.VEvent, parsed *models.Event) string {
+	uid := propertyValue(event, ics.ComponentPropertyUniqueId)
+	if uid == "" {
+		sum := sha1.Sum([]byte(strings.Join([]string{parsed.Title, parsed.Date, parsed.StartTime, parsed.EndTime}, "|")))
+		return "ics-" + hex.EncodeToString(sum[:])
+	}
+	if recurrenceID := propertyValue(event, ics.ComponentProperty(ics.PropertyRecurrenceId)); recurrenceID != "" {
+		return uid + "/" + recurrenceID
+	}
+	return uid
+}
+
+// propertyValue returns the value of an ICS event property, or an empty string if it is not set.
+func propertyValue(event *ics.VEvent, property ics.ComponentProperty) string {
+	if prop := event.GetProperty(property); prop != nil {
+		return prop.Value
+	}
+	return ""
 }
@@ -14,16 +14,59 @@
  *  - ForgotPassword(ctx, email)             - Sends an OTP to reset the user's password.
  *  - ResetPassword(ctx, email, otp, newPwd) - Resets the user's password using an OTP.
  *  - GetUserInfo(ctx, userEmail)            - Fetches the user's profile information.
- *  - SearchUsersByUsername(ctx, userEmail, query) - Searches for users by username.
+ *  - SearchUsersByUsername(ctx, userEmail, query, excludeBlocked, excludeRelated, limit, offset) - Searches for users by username or name.
  *
  *  @dependencies
  *  - repositories.UserRepository: Repository for interacting with user data in the database.
  *  - EmailServiceInterface: Service for sending emails to users.
- *  - utils: Utility package for password hashing, OTP generation, and JWT token handling.
+ *  - repositories.FriendRepository: Used to exclude blocked and related users from search results and to report friendship statuses.
+ *  - utils: Utility package for password hashing.
+ *  - utils.JWTManager: Issues JWT tokens.
+ *  - OTPManager: Generates and validates OTPs with the configured length and lifetime.
+ *  - OTPDeliveryChannel: Sends OTPs to the user; by email unless another channel is set.
+ *  - AuditServiceInterface: Records logins, email verifications and password resets; may be nil.
+ *  - repositories.FriendInvitationRepository: Invitations to join sent to the address before it signed up; may be nil.
+ *  - CityServiceInterface: City lists used to check the city at signup; may be nil.
  *
  *  @behaviors
  *  - Ensures secure handling of user data, including password hashing and OTP validation.
  *  - Provides detailed error messages for user-related operations.
+ *  - Returns the sentinel errors below for expected failures, so callers can tell them apart with errors.Is.
+ *  - Locks an account for LockoutDuration after MaxLoginAttempts wrong passwords in a row. Wrong passwords
+ *    and OTP submissions are counted with UserRepository.IncrementUserField, so sending them concurrently
+ *    does not get past the limits.
+ *  - Login reports ErrNotVerified and ErrAccountDisabled only for the correct password, so the status of
+ *    an account is not revealed to anyone else.
+ *  - New accounts always get models.RoleUser and are enabled; roles are only changed in the database.
+ *  - Signup trims the username and checks it is unique regardless of case, like ProfileService.UpdateProfile.
+ *  - Checks each OTP at most MaxOTPAttempts times and invalidates it after the last wrong one; a new OTP
+ *    must then be requested.
+ *    VerifyEmail and ResetPassword check OTPs through the same code path.
+ *  - Records successful logins, wrong passwords and logins to a locked account, verified emails, and
+ *    requested and completed password resets in the account's audit log. Attempts on unknown emails
+ *    are not recorded, since there is no account to record them for.
+ *  - OTP emails are rendered from the email templates and queued with SendMultipartEmailAsync, so a slow
+ *    or briefly failing SMTP server does not delay or fail the request; delivery is retried in the background.
+ *  - The OTP manager uses the service's clock, so replacing Now also moves OTP expiry.
+ *  - Stores only an HMAC of each OTP; the raw OTP appears only in the email sent to the user.
+ *  - Signup turns every pending invitation to the new address into a pending friend request from the
+ *    inviter and marks it consumed. Inviters who deleted their account are skipped. Failures are only
+ *    logged, since the account has already been created.
+ *  - Emails are normalized with utils.NormalizeEmail (trimmed and lowercased) at every entry point, so an
+ *    account is found however its address is typed, and new accounts are stored under the normalized form.
+ *  - Keeps FirstNameLower and LastNameLower in sync with the names, so users can be found by name.
+ *  - Signup records when the account was created in CreatedAt; GetUserInfo leaves it out for older accounts.
+ *  - An account not verified within UnverifiedAccountExpiry of its CreatedAt has expired. Signing up again
+ *    with its email replaces it, with a fresh password and OTP, and may reuse its username. Verifying,
+ *    resending the OTP or resetting the password of an expired account fails with ErrAccountExpired and
+ *    clears its OTP, ForgotPassword sends it nothing, and Login reports ErrAccountExpired instead of
+ *    ErrNotVerified. Unverified accounts without CreatedAt were created before the field was
+ *    recorded, long enough ago to have expired, so they are treated as expired too; verified accounts
+ *    never expire.
+ *  - Signup rejects unknown countries with suggestions and stores the country's canonical name (see
+ *    NormalizeCountry). A city missing from the country's city list is kept, but flagged with CityUnlisted.
+ *  - Search results are paged with limit and offset and carry the friendship status with the requesting user:
+ *    "none", "pending_outgoing", "pending_incoming" or "friends".
  *  - Prevents unauthorized access by validating user inputs and tokens.
  *
  *  @example
@@ -54,7 +97,9 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"proh2052-group6/internal/repositories"
 	"strings"
 	"time"
@@ -63,6 +108,53 @@ import (
 	"proh2052-group6/pkg/utils"
 )
 
+// Errors returned by UserService for expected failures. Any other error is an internal failure.
+var (
+	ErrMissingFields      = errors.New("Country, City, Email, Username, and Password are required")
+	ErrEmailTaken         = errors.New("Email already registered")
+	ErrUsernameTaken      = errors.New("Username already taken")
+	ErrWeakPassword       = errors.New("Password does not meet complexity requirements")
+	ErrInvalidCredentials = errors.New("Email or password is incorrect")
+	ErrNotVerified        = errors.New("Email not verified")
+	ErrEmailNotRegistered = errors.New("Email not registered")
+	ErrAlreadyVerified    = errors.New("Email is already verified")
+	ErrAccountLocked      = errors.New("Account temporarily locked")
+	ErrTooManyOTPAttempts = errors.New("Too many invalid OTP attempts")
+	ErrAccountDisabled    = errors.New("Account disabled")
+	ErrAccountExpired     = errors.New("Account was not verified in time. Please sign up again")
+)
+
+// Default brute-force limits used by NewUserService.
+const (
+	DefaultMaxLoginAttempts = 5
+	DefaultLockoutDuration  = 15 * time.Minute
+	DefaultMaxOTPAttempts   = 5
+)
+
+// UnverifiedAccountExpiry is how long a new account can be verified. An unverified account older than
+// this no longer holds its email: signing up again with the email replaces it.
+const UnverifiedAccountExpiry = 7 * 24 * time.Hour
+
+// OTPValidity is how long an emailed OTP can be used unless OTP_TTL configures another lifetime.
+const OTPValidity = 5 * time.Minute
+
+// Limits on the number of users returned by SearchUsersByUsername.
+const (
+	DefaultUserSearchLimit = 20
+	MaxUserSearchLimit     = 50
+	maxUserSearchScan      = 1000 // Most matches per field fetched to fill a page after excluding users.
+)
+
+// Friendship statuses reported with each user search result, from the requesting user's point of view.
+// FriendshipBlocked is only reported by FriendService.GetRelationshipStatuses.
+const (
+	FriendshipNone            = "none"
+	FriendshipPendingOutgoing = "pending_outgoing"
+	FriendshipPendingIncoming = "pending_incoming"
+	FriendshipFriends         = "friends"
+	FriendshipBlocked         = "blocked"
+)
+
 // UserServiceInterface defines the contract for user management operations.
 type UserServiceInterface interface {
 	Signup(ctx context.Context, user *models.User) error
@@ -71,141 +163,330 @@ type UserServiceInterface interface {
 	VerifyEmail(ctx context.Context, email, otp string) (string, error)
 	ForgotPassword(ctx context.Context, email string) error
 	ResetPassword(ctx context.Context, email, otp, newPassword string) error
-	GetUserInfo(ctx context.Context, userEmail string) (map[string]string, error)
-	SearchUsersByUsername(ctx context.Context, userEmail, query string) ([]map[string]string, error)
+	GetUserInfo(ctx context.Context, userEmail string) (*models.UserProfile, error)
+	SearchUsersByUsername(ctx context.Context, userEmail, query string, excludeBlocked, excludeRelated bool, limit, offset int) ([]map[string]string, error)
 }
 
 // UserService implements UserServiceInterface and interacts with repositories and email services.
 type UserService struct {
-	UserRepo repositories.UserRepository // Repository for user-related database operations.
-	Email    EmailServiceInterface       // Email service for sending OTPs and notifications.
+	UserRepo   repositories.UserRepository   // Repository for user-related database operations.
+	Email      EmailServiceInterface         // Email service for sending OTPs and notifications.
+	FriendRepo repositories.FriendRepository // Repository used to look up friendships, requests and blocks between users.
+	Templates  *EmailTemplateRenderer        // Renders the OTP emails.
+	JWT        *utils.JWTManager             // Issues tokens.
+	Audit      AuditServiceInterface         // Records security-sensitive actions; may be nil.
+
+	OTP         *OTPManager        // Generates and validates OTPs.
+	OTPDelivery OTPDeliveryChannel // Sends OTPs to the user.
+
+	FriendInvitationRepo repositories.FriendInvitationRepository // Invitations converted to friend requests on signup; may be nil.
+	Cities               CityServiceInterface                    // City lists checked at signup; cities are not checked if nil.
+
+	MaxLoginAttempts int              // Wrong passwords in a row before the account is locked.
+	LockoutDuration  time.Duration    // How long a locked account stays locked.
+	MaxOTPAttempts   int              // Wrong submissions before an OTP is invalidated.
+	Now              func() time.Time // Clock used for OTP expiry and lockouts; replaceable in tests.
 }
 
-// NewUserService initializes a new UserService with a UserRepository and EmailService.
-func NewUserService(userRepo repositories.UserRepository, emailService EmailServiceInterface) UserServiceInterface {
-	return &UserService{
-		UserRepo: userRepo,
-		Email:    emailService,
-	}
+// NewUserService initializes a new UserService with a UserRepository, EmailService, FriendRepository and
+// JWTManager, using the default brute-force limits.
+func NewUserService(userRepo repositories.UserRepository, emailService EmailServiceInterface, friendRepo repositories.FriendRepository, jwtManager *utils.JWTManager) UserServiceInterface {
+	us := &UserService{
+		UserRepo:         userRepo,
+		Email:            emailService,
+		FriendRepo:       friendRepo,
+		Templates:        NewEmailTemplateRenderer(),
+		JWT:              jwtManager,
+		OTP:              NewOTPManager(jwtManager),
+		MaxLoginAttempts: DefaultMaxLoginAttempts,
+		LockoutDuration:  DefaultLockoutDuration,
+		MaxOTPAttempts:   DefaultMaxOTPAttempts,
+		Now:              time.Now,
+	}
+	us.OTP.Now = func() time.Time { return us.Now() }
+	us.OTPDelivery = NewEmailOTPDelivery(emailService, us.Templates)
+	return us
 }
 
 // Signup registers a new user with validation, OTP generation, and email verification.
 func (us *UserService) Signup(ctx context.Context, user *models.User) error {
+	// The username is trimmed like UpdateProfile does, so padding cannot get around the uniqueness check.
+	user.Username = strings.TrimSpace(user.Username)
 	if user.Country == "" || user.City == "" || user.Email == "" || user.Username == "" || user.Password == "" {
-		return fmt.Errorf("Country, City, Email, Username, and Password are required")
+		return ErrMissingFields
+	}
+
+	country, err := NormalizeCountry(user.Country)
+	if err != nil {
+		return err
 	}
+	user.Country = country
+	user.Email = utils.NormalizeEmail(user.Email)
 
 	existingUser, err := us.UserRepo.GetUserByEmail(ctx, user.Email)
-	if err == nil && existingUser != nil {
-		return fmt.Errorf("Email already registered")
+	if isRepositoryFailure(err) {
+		return err
+	}
+	// An expired unverified account is replaced instead of keeping the email taken.
+	replace := err == nil && existingUser != nil && us.isExpired(existingUser)
+	if err == nil && existingUser != nil && !replace {
+		return ErrEmailTaken
+	}
+
+	// Usernames are unique regardless of case, since lookups go through UsernameLower.
+	// The username of the account being replaced is free.
+	existingUser, err = us.UserRepo.GetUserByUsername(ctx, user.Username)
+	if isRepositoryFailure(err) {
+		return err
+	}
+	if err == nil && existingUser != nil && !(replace && existingUser.Email == user.Email) {
+		return ErrUsernameTaken
 	}
 
 	if !utils.IsValidPassword(user.Password) {
-		return fmt.Errorf("Password does not meet complexity requirements")
+		return ErrWeakPassword
 	}
 
-	user.Password = utils.HashPassword(user.Password)
+	hashedPassword, err := utils.HashPassword(user.Password)
+	if err != nil {
+		return fmt.Errorf("Failed to hash password")
+	}
+	user.Password = hashedPassword
 	user.IsVerified = false
+	user.Role = models.RoleUser
+	user.Disabled = false
+	user.CreatedAt = us.Now()
 	user.UsernameLower = strings.ToLower(user.Username)
-	user.OTP = utils.GenerateOTP()
-	user.OTPExpiresAt = time.Now().Add(5 * time.Minute)
-
-	if err := us.UserRepo.CreateUser(ctx, user); err != nil {
-		return fmt.Errorf("Failed to create user: %v", err)
+	user.FirstNameLower = strings.ToLower(user.FirstName)
+	user.LastNameLower = strings.ToLower(user.LastName)
+	city, listed := NormalizeCity(ctx, us.Cities, user.Country, user.City)
+	user.City = city
+	user.CityUnlisted = !listed
+	otp, hash, expiresAt := us.OTP.Generate()
+	user.OTP = hash
+	user.OTPExpiresAt = expiresAt
+
+	if replace {
+		if err := us.UserRepo.ReplaceUser(ctx, user); err != nil {
+			return fmt.Errorf("Failed to create user: %w", err)
+		}
+	} else if err := us.UserRepo.CreateUser(ctx, user); err != nil {
+		return fmt.Errorf("Failed to create user: %w", err)
 	}
+	us.acceptFriendInvitations(ctx, user.Email)
 
-	subject := "Your Verification Code"
-	body := fmt.Sprintf("Your OTP for email verification is: %s. It will expire in 5 minutes.", user.OTP)
-	if err := us.Email.SendEmail(user.Email, subject, body); err != nil {
-		return fmt.Errorf("Failed to send verification email: %v", err)
+	if err := us.OTPDelivery.SendOTP(WithUserLocale(ctx, user), user.Email, otp, OTPPurposeVerification, us.OTP.TTL); err != nil {
+		return fmt.Errorf("Failed to send verification email: %w", err)
 	}
 
 	return nil
 }
 
+// acceptFriendInvitations turns the pending invitations to email into pending friend requests from
+// their inviters and marks them consumed. Errors are logged, since they must not fail the signup.
+func (us *UserService) acceptFriendInvitations(ctx context.Context, email string) {
+	if us.FriendInvitationRepo == nil || us.FriendRepo == nil {
+		return
+	}
+	inviteeEmail := utils.NormalizeEmail(email)
+	invitations, err := us.FriendInvitationRepo.GetPendingFriendInvitations(ctx, inviteeEmail)
+	if err != nil {
+		log.Printf("Failed to retrieve friend invitations for %s: %v", email, err)
+		return
+	}
+	for _, invitation := range invitations {
+		inviter, err := us.UserRepo.GetUserByEmail(ctx, invitation.InviterEmail)
+		if isRepositoryFailure(err) {
+			log.Printf("Failed to retrieve inviter %s of %s: %v", invitation.InviterEmail, email, err)
+			continue // Left pending, so it is not lost.
+		}
+		if err == nil && inviter != nil {
+			request := &models.Friend{
+				Email:       invitation.InviterEmail,
+				FriendEmail: email,
+				Status:      "pending",
+				CreatedAt:   us.Now(),
+			}
+			if err := us.FriendRepo.CreateFriendRequest(ctx, request); err != nil {
+				log.Printf("Failed to create friend request from %s to %s: %v", invitation.InviterEmail, email, err)
+				continue
+			}
+		}
+		if err := us.FriendInvitationRepo.ConsumeFriendInvitation(ctx, inviteeEmail, invitation.InviterEmail, us.Now()); err != nil {
+			log.Printf("Failed to consume friend invitation from %s to %s: %v", invitation.InviterEmail, email, err)
+		}
+	}
+}
+
 // Login authenticates a user and returns a JWT token if successful.
+// Wrong passwords are counted, and the account is locked once MaxLoginAttempts is reached.
 func (us *UserService) Login(ctx context.Context, loginData *models.LoginRequest) (string, error) {
-	user, err := us.UserRepo.GetUserByEmail(ctx, loginData.Email)
+	user, err := us.UserRepo.GetUserByEmail(ctx, utils.NormalizeEmail(loginData.Email))
+	if isRepositoryFailure(err) {
+		return "", err
+	}
 	if err != nil || user == nil {
-		return "", fmt.Errorf("Email or password is incorrect")
+		return "", ErrInvalidCredentials
+	}
+
+	if us.Now().Before(user.LockedUntil) {
+		recordAudit(ctx, us.Audit, user.Email, AuditLoginFailed)
+		return "", ErrAccountLocked
 	}
 
+	if utils.IsLegacyPasswordHash(user.Password) {
+		if !utils.CheckLegacyPasswordHash(loginData.Password, user.Password) {
+			return "", us.recordFailedLogin(ctx, user)
+		}
+
+		// Transparently upgrade the legacy SHA-256 hash to bcrypt. A failed upgrade
+		// does not block the login; it is retried on the next successful login.
+		if err := us.upgradePasswordHash(ctx, user.Email, loginData.Password); err != nil {
+			log.Printf("Failed to upgrade password hash for %s: %v", user.Email, err)
+		}
+	} else if !utils.CheckPasswordHash(loginData.Password, user.Password) {
+		return "", us.recordFailedLogin(ctx, user)
+	}
+
+	// Only reveal that the email is unverified or the account disabled to someone who knows the password.
+	if user.Disabled {
+		return "", ErrAccountDisabled
+	}
+	if us.isExpired(user) {
+		return "", ErrAccountExpired
+	}
 	if !user.IsVerified {
-		return "", fmt.Errorf("Email not verified")
+		return "", ErrNotVerified
 	}
 
-	if utils.HashPassword(loginData.Password) != user.Password {
-		return "", fmt.Errorf("Email or password is incorrect")
+	if user.FailedLoginCount > 0 {
+		if err := us.UserRepo.UpdateUser(ctx, user.Email, map[string]interface{}{"FailedLoginCount": 0}); err != nil {
+			log.Printf("Failed to reset failed login count for %s: %v", user.Email, err)
+		}
 	}
 
-	token, err := utils.GenerateJWT(user.Email)
+	token, err := us.JWT.GenerateJWT(user.Email, user.TokenVersion)
 	if err != nil {
 		return "", fmt.Errorf("Failed to generate token")
 	}
 
+	recordAudit(ctx, us.Audit, user.Email, AuditLoginSucceeded)
 	return token, nil
 }
 
+// recordFailedLogin counts a wrong password and locks the account once MaxLoginAttempts is reached.
+// It returns the error for the login attempt: ErrAccountLocked if the account was just locked,
+// otherwise ErrInvalidCredentials.
+func (us *UserService) recordFailedLogin(ctx context.Context, user *models.User) error {
+	recordAudit(ctx, us.Audit, user.Email, AuditLoginFailed)
+
+	// The stored count is incremented, not the one read with the user, so concurrent wrong passwords are all counted.
+	failedLogins, err := us.UserRepo.IncrementUserField(ctx, user.Email, "FailedLoginCount")
+	if err != nil {
+		return fmt.Errorf("Failed to record login attempt: %w", err)
+	}
+	if failedLogins < us.MaxLoginAttempts {
+		return ErrInvalidCredentials
+	}
+
+	// The count starts over once the lockout has expired.
+	updates := map[string]interface{}{"FailedLoginCount": 0, "LockedUntil": us.Now().Add(us.LockoutDuration)}
+	if err := us.UserRepo.UpdateUser(ctx, user.Email, updates); err != nil {
+		return fmt.Errorf("Failed to record login attempt: %w", err)
+	}
+	return ErrAccountLocked
+}
+
+// upgradePasswordHash rewrites a user's stored legacy password hash as a bcrypt hash.
+func (us *UserService) upgradePasswordHash(ctx context.Context, email, password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("Failed to upgrade password hash")
+	}
+
+	updates := map[string]interface{}{
+		"Password": hashedPassword,
+	}
+	if err := us.UserRepo.UpdateUser(ctx, email, updates); err != nil {
+		return fmt.Errorf("Failed to upgrade password hash: %w", err)
+	}
+
+	return nil
+}
+
 // ResendOTP sends a new OTP to the user's email for verification.
 func (us *UserService) ResendOTP(ctx context.Context, email string) error {
+	email = utils.NormalizeEmail(email)
 	user, err := us.UserRepo.GetUserByEmail(ctx, email)
+	if isRepositoryFailure(err) {
+		return err
+	}
 	if err != nil || user == nil {
-		return fmt.Errorf("Email not registered")
+		return ErrEmailNotRegistered
 	}
 
 	if user.IsVerified {
-		return fmt.Errorf("Email is already verified")
+		return ErrAlreadyVerified
+	}
+	if us.isExpired(user) {
+		return us.expireOTP(ctx, user)
 	}
 
-	user.OTP = utils.GenerateOTP()
-	user.OTPExpiresAt = time.Now().Add(5 * time.Minute)
+	return us.issueOTP(WithUserLocale(ctx, user), email, OTPPurposeVerification)
+}
 
+// issueOTP replaces the user's OTP with a new one, resetting the wrong attempts, and sends it for purpose.
+func (us *UserService) issueOTP(ctx context.Context, email string, purpose OTPPurpose) error {
+	otp, hash, expiresAt := us.OTP.Generate()
 	updates := map[string]interface{}{
-		"OTP":          user.OTP,
-		"OTPExpiresAt": user.OTPExpiresAt,
+		"OTP":          hash,
+		"OTPExpiresAt": expiresAt,
+		"OTPAttempts":  0,
 	}
 	if err := us.UserRepo.UpdateUser(ctx, email, updates); err != nil {
-		return fmt.Errorf("Failed to update OTP")
+		return fmt.Errorf("Failed to update OTP: %w", err)
 	}
 
-	subject := "Your New Verification Code"
-	body := fmt.Sprintf("Your new OTP is: %s. It will expire in 5 minutes.", user.OTP)
-	if err := us.Email.SendEmail(email, subject, body); err != nil {
+	if err := us.OTPDelivery.SendOTP(ctx, email, otp, purpose, us.OTP.TTL); err != nil {
 		return fmt.Errorf("Failed to send OTP email")
 	}
-
 	return nil
 }
 
 // VerifyEmail verifies the user's email using the provided OTP and updates their status.
 func (us *UserService) VerifyEmail(ctx context.Context, email, otp string) (string, error) {
+	email = utils.NormalizeEmail(email)
 	user, err := us.UserRepo.GetUserByEmail(ctx, email)
+	if isRepositoryFailure(err) {
+		return "", err
+	}
 	if err != nil || user == nil {
 		return "", fmt.Errorf("Invalid email or OTP")
 	}
 
 	if user.IsVerified {
-		return "", fmt.Errorf("Email is already verified")
+		return "", ErrAlreadyVerified
 	}
-
-	if user.OTP != otp {
-		return "", fmt.Errorf("Invalid OTP")
+	if us.isExpired(user) {
+		return "", us.expireOTP(ctx, user)
 	}
 
-	if time.Now().After(user.OTPExpiresAt) {
-		return "", fmt.Errorf("OTP has expired")
+	if err := us.checkOTP(ctx, user, otp); err != nil {
+		return "", err
 	}
 
 	updates := map[string]interface{}{
 		"IsVerified":   true,
 		"OTP":          nil,
 		"OTPExpiresAt": nil,
+		"OTPAttempts":  0,
 	}
 	if err := us.UserRepo.UpdateUser(ctx, email, updates); err != nil {
-		return "", fmt.Errorf("Failed to update user verification status")
+		return "", fmt.Errorf("Failed to update user verification status: %w", err)
 	}
+	recordAudit(ctx, us.Audit, email, AuditEmailVerified)
 
-	token, err := utils.GenerateJWT(email)
+	token, err := us.JWT.GenerateJWT(email, user.TokenVersion)
 	if err != nil {
 		return "", fmt.Errorf("Failed to generate token")
 	}
@@ -213,106 +494,263 @@ func (us *UserService) VerifyEmail(ctx context.Context, email, otp string) (stri
 	return token, nil
 }
 
+// isExpired reports whether user is an unverified account created more than UnverifiedAccountExpiry ago.
+// Unverified accounts without CreatedAt predate the field, so they are older than that too.
+func (us *UserService) isExpired(user *models.User) bool {
+	if user.IsVerified {
+		return false
+	}
+	return user.CreatedAt.IsZero() || !us.Now().Before(user.CreatedAt.Add(UnverifiedAccountExpiry))
+}
+
+// expireOTP clears the OTP of an expired account, so it can no longer be used, and returns ErrAccountExpired.
+func (us *UserService) expireOTP(ctx context.Context, user *models.User) error {
+	if user.OTP != "" || !user.OTPExpiresAt.IsZero() {
+		updates := map[string]interface{}{"OTP": nil, "OTPExpiresAt": nil, "OTPAttempts": 0}
+		if err := us.UserRepo.UpdateUser(ctx, user.Email, updates); err != nil {
+			return fmt.Errorf("Failed to clear expired OTP: %w", err)
+		}
+	}
+	return ErrAccountExpired
+}
+
+// checkOTP validates a submitted OTP against the user's current one for both VerifyEmail and
+// ResetPassword. Every submission is counted before it is checked, so at most MaxOTPAttempts are
+// checked even when they are sent concurrently; once the last one is wrong the OTP is cleared and
+// ErrTooManyOTPAttempts is returned until a new OTP is requested.
+func (us *UserService) checkOTP(ctx context.Context, user *models.User, otp string) error {
+	if user.OTPAttempts >= us.MaxOTPAttempts {
+		return ErrTooManyOTPAttempts
+	}
+	attempts, err := us.UserRepo.IncrementUserField(ctx, user.Email, "OTPAttempts")
+	if err != nil {
+		return fmt.Errorf("Failed to record OTP attempt: %w", err)
+	}
+	if attempts > us.MaxOTPAttempts {
+		return ErrTooManyOTPAttempts
+	}
+
+	err = us.OTP.Validate(user.OTP, otp, user.OTPExpiresAt)
+	if errors.Is(err, ErrInvalidOTP) && attempts == us.MaxOTPAttempts {
+		if err := us.UserRepo.UpdateUser(ctx, user.Email, map[string]interface{}{"OTP": nil, "OTPExpiresAt": nil}); err != nil {
+			return fmt.Errorf("Failed to record OTP attempt: %w", err)
+		}
+		return ErrTooManyOTPAttempts
+	}
+	return err
+}
+
 func (us *UserService) ForgotPassword(ctx context.Context, email string) error {
+	email = utils.NormalizeEmail(email)
 	// Fetch user data
 	user, err := us.UserRepo.GetUserByEmail(ctx, email)
+	if isRepositoryFailure(err) {
+		return err
+	}
 	if err != nil || user == nil {
 		// For security, we don't reveal whether the email exists
 		return nil
 	}
-
-	// Generate OTP
-	user.OTP = utils.GenerateOTP()
-	user.OTPExpiresAt = time.Now().Add(5 * time.Minute)
-
-	// Update the user with new OTP
-	updates := map[string]interface{}{
-		"OTP":          user.OTP,
-		"OTPExpiresAt": user.OTPExpiresAt,
-	}
-	err = us.UserRepo.UpdateUser(ctx, email, updates)
-	if err != nil {
-		return fmt.Errorf("Failed to update OTP")
+	if us.isExpired(user) {
+		// Nor that the account expired; it can only be signed up again.
+		return nil
 	}
 
-	// Send OTP email
-	subject := "Password Reset Request"
-	body := fmt.Sprintf("Your OTP for password reset is: %s. It will expire in 5 minutes.", user.OTP)
-	if err := us.Email.SendEmail(email, subject, body); err != nil {
-		return fmt.Errorf("Failed to send OTP email")
+	// Only the OTP's hash is stored; the OTP itself is sent to the user.
+	if err := us.issueOTP(WithUserLocale(ctx, user), email, OTPPurposePasswordReset); err != nil {
+		return err
 	}
 
+	recordAudit(ctx, us.Audit, email, AuditPasswordResetRequested)
 	return nil
 }
 
 func (us *UserService) ResetPassword(ctx context.Context, email, otp, newPassword string) error {
+	email = utils.NormalizeEmail(email)
 	user, err := us.UserRepo.GetUserByEmail(ctx, email)
+	if isRepositoryFailure(err) {
+		return err
+	}
 	if err != nil || user == nil {
 		return fmt.Errorf("Invalid email or OTP")
 	}
-
-	if user.OTP != otp {
-		return fmt.Errorf("Invalid OTP")
+	if us.isExpired(user) {
+		return us.expireOTP(ctx, user)
 	}
 
-	if time.Now().After(user.OTPExpiresAt) {
-		return fmt.Errorf("OTP has expired")
+	if err := us.checkOTP(ctx, user, otp); err != nil {
+		return err
 	}
 
 	if !utils.IsValidPassword(newPassword) {
-		return fmt.Errorf("Password does not meet complexity requirements")
+		return ErrWeakPassword
 	}
 
-	hashedPassword := utils.HashPassword(newPassword)
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("Failed to reset password")
+	}
 
-	// Update the user's password and clear OTP
+	// Update the user's password, clear the OTP and revoke all tokens issued before the reset.
 	updates := map[string]interface{}{
 		"Password":     hashedPassword,
 		"OTP":          nil,
 		"OTPExpiresAt": nil,
+		"OTPAttempts":  0,
+		"TokenVersion": user.TokenVersion + 1,
 	}
 	err = us.UserRepo.UpdateUser(ctx, email, updates)
 	if err != nil {
-		return fmt.Errorf("Failed to reset password")
+		return fmt.Errorf("Failed to reset password: %w", err)
 	}
 
+	recordAudit(ctx, us.Audit, email, AuditPasswordResetCompleted)
 	return nil
 }
 
-func (us *UserService) GetUserInfo(ctx context.Context, userEmail string) (map[string]string, error) {
+// GetUserInfo returns the profile of the user with userEmail, the same as ProfileService.GetProfile.
+func (us *UserService) GetUserInfo(ctx context.Context, userEmail string) (*models.UserProfile, error) {
 	user, err := us.UserRepo.GetUserByEmail(ctx, userEmail)
+	if isRepositoryFailure(err) {
+		return nil, err
+	}
 	if err != nil || user == nil {
 		return nil, fmt.Errorf("User not found")
 	}
 
-	userInfo := map[string]string{
-		"email":    user.Email,
-		"username": user.Username,
-		"country":  user.Country,
-		"city":     user.City,
-	}
-
-	return userInfo, nil
+	return newUserProfile(user), nil
 }
 
-func (us *UserService) SearchUsersByUsername(ctx context.Context, userEmail, query string) ([]map[string]string, error) {
-	users, err := us.UserRepo.SearchUsersByUsername(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to search users")
+// SearchUsersByUsername searches for users whose username, first name or last name starts with query,
+// excluding the requesting user, and returns the page of limit results starting at offset. Username
+// matches come first, then first name and last name matches. Each result carries the friendship status
+// between the requesting user and the match. When excludeBlocked is true, users who blocked or were
+// blocked by the requesting user are excluded too. When excludeRelated is true, so are friends, users
+// with a pending request in either direction and blocked users, leaving only people who can be added
+// as friends. A limit outside 1..MaxUserSearchLimit is clamped.
+func (us *UserService) SearchUsersByUsername(ctx context.Context, userEmail, query string, excludeBlocked, excludeRelated bool, limit, offset int) ([]map[string]string, error) {
+	if limit <= 0 {
+		limit = DefaultUserSearchLimit
+	} else if limit > MaxUserSearchLimit {
+		limit = MaxUserSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
 	}
 
-	var results []map[string]string
-	for _, user := range users {
-		// Exclude the requesting user from the results
-		if user.Email == userEmail {
-			continue
+	statuses, err := us.friendshipStatuses(ctx, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to search users: %w", err)
+	}
+	// Blocked users have a relationship with the requesting user too.
+	excludeBlocked = excludeBlocked || excludeRelated
+
+	// The repository returns up to fetch matches per field. Only the first fetch merged users are in
+	// their final order, so fetch more until the page is filled after excluding users.
+	needed := offset + limit
+	blocked := make(map[string]bool)
+	var matches []*models.User
+	for fetch := needed + 1; ; fetch *= 2 {
+		if fetch > maxUserSearchScan {
+			fetch = maxUserSearchScan
+		}
+		users, err := us.UserRepo.SearchUsers(ctx, query, fetch)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to search users: %w", err)
+		}
+		complete := len(users) < fetch
+		if !complete {
+			users = users[:fetch]
+		}
+
+		matches = matches[:0]
+		for _, user := range users {
+			if user.Email == userEmail {
+				continue
+			}
+			if _, related := statuses[user.Email]; related && excludeRelated {
+				continue
+			}
+			if excludeBlocked {
+				isBlocked, checked := blocked[user.Email]
+				if !checked {
+					isBlocked, err = isBlockedEitherWay(ctx, us.FriendRepo, userEmail, user.Email)
+					if err != nil {
+						return nil, fmt.Errorf("Failed to search users: %w", err)
+					}
+					blocked[user.Email] = isBlocked
+				}
+				if isBlocked {
+					continue
+				}
+			}
+			matches = append(matches, user)
+		}
+
+		if len(matches) >= needed || complete || fetch == maxUserSearchScan {
+			break
 		}
+	}
 
+	results := []map[string]string{}
+	if offset >= len(matches) {
+		return results, nil
+	}
+	if needed > len(matches) {
+		needed = len(matches)
+	}
+	page := matches[offset:needed]
+
+	for _, user := range page {
+		status, ok := statuses[user.Email]
+		if !ok {
+			status = FriendshipNone
+		}
 		results = append(results, map[string]string{
-			"username": user.Username,
-			"email":    user.Email,
+			"username":         user.Username,
+			"email":            user.Email,
+			"firstName":        user.FirstName,
+			"lastName":         user.LastName,
+			"imageUrl":         user.ImageURL,
+			"friendshipStatus": status,
 		})
 	}
 
 	return results, nil
 }
+
+// friendshipStatuses returns the friendship status between userEmail and every user they are friends
+// with or have a pending request with, keyed by the other user's email.
+func (us *UserService) friendshipStatuses(ctx context.Context, userEmail string) (map[string]string, error) {
+	statuses := make(map[string]string)
+
+	sent, err := us.FriendRepo.GetSentFriendRequests(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	for _, request := range sent {
+		statuses[request.FriendEmail] = FriendshipPendingOutgoing
+	}
+
+	received, err := us.FriendRepo.GetPendingFriendRequests(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	for _, request := range received {
+		statuses[request.Email] = FriendshipPendingIncoming
+	}
+
+	friends, err := us.FriendRepo.GetFriends(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	for _, friend := range friends {
+		if friend.Email == userEmail {
+			statuses[friend.FriendEmail] = FriendshipFriends
+		} else {
+			statuses[friend.Email] = FriendshipFriends
+		}
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,293 @@
+/**
+ *  WeatherService fetches the daily weather forecast for a position, so the dashboard can show the
+ *  weather next to today's agenda. It integrates with the Open-Meteo forecast API.
+ *
+ *  @interface WeatherServiceInterface
+ *  @inherits None
+ *
+ *  @methods
+ *  - NewWeatherService(userAgent)                 - Initializes a WeatherService for the public Open-Meteo API.
+ *  - GetForecast(ctx, latitude, longitude, date) - Returns the forecast for a position on a date.
+ *  - WeatherSummary(code)                        - Describes a WMO weather code in a few words.
+ *
+ *  @behaviors
+ *  - Forecasts are cached in memory for CacheTTL, an hour by default, by date and position rounded to
+ *    0.01 degrees, so every user in the same city shares one upstream request per date.
+ *  - Failed requests are not cached, so the next request retries the upstream.
+ *  - Returns ErrForecastDateOutOfRange for dates before today or more than 15 days ahead.
+ *  - Returns a *WeatherAPIError if the API fails, rejects the request or returns malformed data.
+ *  - Every request identifies the application with UserAgent.
+ *
+ *  @dependencies
+ *  - api.open-meteo.com: External weather forecast API.
+ *  - httpclient.New: Builds the HTTP client, with timeouts and retries.
+ *
+ *  @example
+ *  ```
+ *  weatherService := NewWeatherService("DailyVerse/1.0 (admin@example.com)")
+ *  forecast, err := weatherService.GetForecast(ctx, 59.9139, 10.7522, "2024-05-01")
+ *  ```
+ *
+ *  @file      weather_service.go
+ *  @project   DailyVerse
+ *  @framework Go HTTP Client with JSON Integration
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"proh2052-group6/pkg/httpclient"
+)
+
+// DefaultWeatherCacheTTL is how long forecasts are cached by default.
+const DefaultWeatherCacheTTL = time.Hour
+
+// maxForecastDays is how many days ahead, counting today, the forecast API covers.
+const maxForecastDays = 16
+
+// openMeteoForecastURL is the forecast endpoint of the public Open-Meteo API.
+const openMeteoForecastURL = "https://api.open-meteo.com/v1/forecast"
+
+// ErrForecastDateOutOfRange is returned for dates the forecast API has no forecast for.
+var ErrForecastDateOutOfRange = errors.New("date must be between today and 15 days ahead")
+
+// WeatherServiceInterface defines the contract for fetching weather forecasts.
+type WeatherServiceInterface interface {
+	// GetForecast returns the forecast for the position on date (YYYY-MM-DD).
+	GetForecast(ctx context.Context, latitude, longitude float64, date string) (*Forecast, error)
+}
+
+// Forecast is the weather forecast for a day.
+type Forecast struct {
+	Date            string  `json:"date"`            // Date of the forecast (YYYY-MM-DD).
+	TemperatureMax  float64 `json:"temperatureMax"`  // Highest temperature in °C.
+	TemperatureMin  float64 `json:"temperatureMin"`  // Lowest temperature in °C.
+	PrecipitationMm float64 `json:"precipitationMm"` // Total precipitation in millimetres.
+	Summary         string  `json:"summary"`         // Short description, e.g. "Light rain".
+}
+
+// WeatherAPIError is returned when the weather API fails or responds with an error.
+type WeatherAPIError struct {
+	StatusCode int    // HTTP status code from the weather API, or 0 if no response was received.
+	Message    string // Error message reported by the weather API.
+}
+
+// Error implements the error interface.
+func (e *WeatherAPIError) Error() string {
+	return fmt.Sprintf("Weather API error: %s", e.Message)
+}
+
+// WeatherService implements the WeatherServiceInterface with the Open-Meteo forecast API.
+type WeatherService struct {
+	HTTPClient  *http.Client     // HTTP client for making API requests.
+	ForecastURL string           // URL of the forecast endpoint.
+	UserAgent   string           // Identifies the application to the weather API.
+	CacheTTL    time.Duration    // How long forecasts are cached; 0 disables caching.
+	Now         func() time.Time // Clock used for the date range and cache expiry; defaults to time.Now.
+
+	mutex sync.Mutex
+	cache map[weatherCacheKey]weatherCacheEntry
+}
+
+// weatherCacheKey identifies a forecast by rounded position and date.
+type weatherCacheKey struct {
+	latitude  float64
+	longitude float64
+	date      string
+}
+
+// weatherCacheEntry holds a forecast until it expires.
+type weatherCacheEntry struct {
+	forecast  Forecast
+	expiresAt time.Time
+}
+
+// NewWeatherService initializes a WeatherService for the public Open-Meteo API, identifying the
+// application with userAgent.
+func NewWeatherService(userAgent string) WeatherServiceInterface {
+	return &WeatherService{
+		HTTPClient:  httpclient.New(httpclient.DefaultConfig()),
+		ForecastURL: openMeteoForecastURL,
+		UserAgent:   userAgent,
+		CacheTTL:    DefaultWeatherCacheTTL,
+		Now:         time.Now,
+	}
+}
+
+// GetForecast returns the forecast for the position on date, from the cache if possible,
+// otherwise by calling the forecast API.
+func (ws *WeatherService) GetForecast(ctx context.Context, latitude, longitude float64, date string) (*Forecast, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("date must be in the format YYYY-MM-DD")
+	}
+	now := ws.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(today) || !day.Before(today.AddDate(0, 0, maxForecastDays)) {
+		return nil, ErrForecastDateOutOfRange
+	}
+
+	key := weatherCacheKey{latitude: roundCoordinate(latitude), longitude: roundCoordinate(longitude), date: date}
+	if forecast, ok := ws.cachedForecast(key); ok {
+		return forecast, nil
+	}
+
+	forecast, err := ws.requestForecast(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	ws.cacheForecast(key, *forecast)
+	return forecast, nil
+}
+
+// requestForecast calls the forecast API for the daily forecast of the position and date in key.
+func (ws *WeatherService) requestForecast(ctx context.Context, key weatherCacheKey) (*Forecast, error) {
+	params := url.Values{}
+	params.Set("latitude", strconv.FormatFloat(key.latitude, 'f', -1, 64))
+	params.Set("longitude", strconv.FormatFloat(key.longitude, 'f', -1, 64))
+	params.Set("daily", "temperature_2m_max,temperature_2m_min,precipitation_sum,weather_code")
+	params.Set("timezone", "auto")
+	params.Set("start_date", key.date)
+	params.Set("end_date", key.date)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ws.ForecastURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create weather request: %v", err)
+	}
+	req.Header.Set("User-Agent", ws.UserAgent)
+
+	resp, err := ws.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &WeatherAPIError{Message: "Failed to reach the weather API"}
+	}
+	defer resp.Body.Close()
+
+	// On failure, the API responds with {"error": true, "reason": "..."}.
+	var result struct {
+		Reason string `json:"reason"`
+		Daily  struct {
+			Time           []string  `json:"time"`
+			TemperatureMax []float64 `json:"temperature_2m_max"`
+			TemperatureMin []float64 `json:"temperature_2m_min"`
+			Precipitation  []float64 `json:"precipitation_sum"`
+			WeatherCode    []int     `json:"weather_code"`
+		} `json:"daily"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, &WeatherAPIError{StatusCode: resp.StatusCode, Message: "Failed to parse weather data"}
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := result.Reason
+		if message == "" {
+			message = fmt.Sprintf("Unexpected response with status %d", resp.StatusCode)
+		}
+		return nil, &WeatherAPIError{StatusCode: resp.StatusCode, Message: message}
+	}
+
+	daily := result.Daily
+	if len(daily.Time) == 0 || len(daily.TemperatureMax) == 0 || len(daily.TemperatureMin) == 0 ||
+		len(daily.Precipitation) == 0 || len(daily.WeatherCode) == 0 {
+		return nil, &WeatherAPIError{StatusCode: resp.StatusCode, Message: "Failed to parse weather data"}
+	}
+	return &Forecast{
+		Date:            daily.Time[0],
+		TemperatureMax:  daily.TemperatureMax[0],
+		TemperatureMin:  daily.TemperatureMin[0],
+		PrecipitationMm: daily.Precipitation[0],
+		Summary:         WeatherSummary(daily.WeatherCode[0]),
+	}, nil
+}
+
+// WeatherSummary describes a WMO weather interpretation code, as returned by the forecast API.
+func WeatherSummary(code int) string {
+	switch {
+	case code == 0:
+		return "Clear sky"
+	case code == 1:
+		return "Mainly clear"
+	case code == 2:
+		return "Partly cloudy"
+	case code == 3:
+		return "Overcast"
+	case code == 45 || code == 48:
+		return "Fog"
+	case code >= 51 && code <= 57:
+		return "Drizzle"
+	case code == 61 || code == 80:
+		return "Light rain"
+	case code >= 61 && code <= 67 || code == 81 || code == 82:
+		return "Rain"
+	case code >= 71 && code <= 77 || code == 85 || code == 86:
+		return "Snow"
+	case code >= 95 && code <= 99:
+		return "Thunderstorm"
+	default:
+		return "Unknown"
+	}
+}
+
+// roundCoordinate rounds a coordinate to 0.01 degrees, about a kilometre.
+func roundCoordinate(degrees float64) float64 {
+	return math.Round(degrees*100) / 100
+}
+
+// cachedForecast returns a copy of the unexpired forecast cached for key.
+func (ws *WeatherService) cachedForecast(key weatherCacheKey) (*Forecast, bool) {
+	if ws.CacheTTL <= 0 {
+		return nil, false
+	}
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	entry, ok := ws.cache[key]
+	if !ok || !ws.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	forecast := entry.forecast
+	return &forecast, true
+}
+
+// cacheForecast stores the forecast for key and evicts expired entries.
+func (ws *WeatherService) cacheForecast(key weatherCacheKey, forecast Forecast) {
+	if ws.CacheTTL <= 0 {
+		return
+	}
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	if ws.cache == nil {
+		ws.cache = make(map[weatherCacheKey]weatherCacheEntry)
+	}
+
+	now := ws.now()
+	for k, entry := range ws.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(ws.cache, k)
+		}
+	}
+	ws.cache[key] = weatherCacheEntry{forecast: forecast, expiresAt: now.Add(ws.CacheTTL)}
+}
+
+// now returns the current time from the service's clock.
+func (ws *WeatherService) now() time.Time {
+	if ws.Now == nil {
+		return time.Now()
+	}
+	return ws.Now()
+}
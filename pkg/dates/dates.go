@@ -0,0 +1,157 @@
+/**
+ *  Dates Package parses and validates the calendar dates (YYYY-MM-DD) of events and journal entries,
+ *  and of the date ranges used to list, search and export them, so every endpoint accepts the same
+ *  dates and rejects the rest with the same error.
+ *
+ *  @file      dates.go
+ *  @package   dates
+ *  @purpose   Shared parsing and validation of YYYY-MM-DD dates.
+ *
+ *  @methods
+ *  - Parse(field, value)                      - Parses a required date.
+ *  - ParseRange(from, to)                     - Parses an optional, inclusive date range.
+ *  - ParseMonth(field, value)                 - Parses a required month (YYYY-MM).
+ *  - ParseEventDate(field, value, today)      - Parses an event date within MaxEventYears of today.
+ *  - ParseJournalDate(field, value, today)    - Parses a journal date that is not after today.
+ *  - Today(now, loc)                          - Returns the date it is at now in loc.
+ *  - AsError(err)                             - Extracts the date error wrapped in err.
+ *
+ *  @behaviors
+ *  - Dates are returned as midnight UTC, so they compare and format the same on every server.
+ *  - Invalid dates are reported as *Error, naming the field or query parameter and what is wrong
+ *    with it. validate.AsErrors recognizes it, so handlers answer it with the usual 400 listing
+ *    the invalid fields.
+ *  - "Today" depends on the user's time zone: at 21:00 in Auckland it is already tomorrow in UTC.
+ *    Callers pass the user's today from Today, or from EarliestZone when the zone is unknown.
+ *
+ *  @example
+ *  ```
+ *  date, err := dates.ParseJournalDate("date", journal.Date, dates.Today(time.Now(), loc))
+ *  if err != nil {
+ *      return err // Invalid input: date must not be in the future
+ *  }
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package dates
+
+import (
+	"errors"
+	"time"
+)
+
+// Layout is the format of dates in requests, responses and stored documents.
+const Layout = "2006-01-02"
+
+// MonthLayout is the format of months in requests, e.g. for the month view of the calendar.
+const MonthLayout = "2006-01"
+
+// MaxEventYears is how many years before or after today an event may take place.
+const MaxEventYears = 10
+
+// EarliestZone is the first time zone to reach a new date, UTC+14. Its today is the latest date it
+// is anywhere, which is the limit for users whose time zone is unknown.
+var EarliestZone = time.FixedZone("UTC+14", 14*60*60)
+
+// Reasons a date is rejected.
+const (
+	ReasonFormat    = "must be a date in YYYY-MM-DD format"
+	ReasonMonth     = "must be a month in YYYY-MM format"
+	ReasonFuture    = "must not be in the future"
+	ReasonAfterTo   = "must not be after to"
+	ReasonEventYear = "must be within 10 years of today" // Keep in step with MaxEventYears.
+)
+
+// Error is a validation error for a date field or query parameter.
+type Error struct {
+	Field  string // JSON name of the field or query parameter, e.g. "date" or "from".
+	Reason string // What is wrong with the date, e.g. ReasonFormat.
+}
+
+// Error describes the invalid field like validate.Errors does.
+func (e *Error) Error() string {
+	return "Invalid input: " + e.Field + " " + e.Reason
+}
+
+// AsError returns the date error wrapped in err, if there is one.
+func AsError(err error) (*Error, bool) {
+	var dateErr *Error
+	if errors.As(err, &dateErr) {
+		return dateErr, true
+	}
+	return nil, false
+}
+
+// Parse parses value as a YYYY-MM-DD date, reporting an error for field if it is empty or malformed.
+func Parse(field, value string) (time.Time, error) {
+	date, err := time.Parse(Layout, value)
+	if err != nil {
+		return time.Time{}, &Error{Field: field, Reason: ReasonFormat}
+	}
+	return date, nil
+}
+
+// ParseRange parses the optional, inclusive range from "from" to "to". A bound that is not given is
+// returned as the zero time.
+func ParseRange(from, to string) (fromDate, toDate time.Time, err error) {
+	if from != "" {
+		if fromDate, err = Parse("from", from); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if to != "" {
+		if toDate, err = Parse("to", to); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if from != "" && to != "" && fromDate.After(toDate) {
+		return time.Time{}, time.Time{}, &Error{Field: "from", Reason: ReasonAfterTo}
+	}
+	return fromDate, toDate, nil
+}
+
+// ParseMonth parses value as a YYYY-MM month, returning its first day and reporting an error for field
+// if it is empty or malformed.
+func ParseMonth(field, value string) (time.Time, error) {
+	month, err := time.Parse(MonthLayout, value)
+	if err != nil {
+		return time.Time{}, &Error{Field: field, Reason: ReasonMonth}
+	}
+	return month, nil
+}
+
+// ParseEventDate parses the date of an event, which must be at most MaxEventYears before or after today.
+func ParseEventDate(field, value string, today time.Time) (time.Time, error) {
+	date, err := Parse(field, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date.Before(today.AddDate(-MaxEventYears, 0, 0)) || date.After(today.AddDate(MaxEventYears, 0, 0)) {
+		return time.Time{}, &Error{Field: field, Reason: ReasonEventYear}
+	}
+	return date, nil
+}
+
+// ParseJournalDate parses the date of a journal entry, which must not be after today.
+func ParseJournalDate(field, value string, today time.Time) (time.Time, error) {
+	date, err := Parse(field, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date.After(today) {
+		return time.Time{}, &Error{Field: field, Reason: ReasonFuture}
+	}
+	return date, nil
+}
+
+// Today returns the date it is at now in loc, as midnight UTC.
+func Today(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
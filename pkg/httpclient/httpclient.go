@@ -0,0 +1,146 @@
+/**
+ *  HTTPClient Package builds the HTTP clients used for every outbound call to a third-party API,
+ *  such as the news, cities, countries, geocoding and weather services, so a hung upstream cannot
+ *  hold a request's goroutine until the server's write timeout cuts the response off.
+ *
+ *  @file      httpclient.go
+ *  @package   httpclient
+ *  @purpose   Shared timeouts and retries for outbound HTTP calls.
+ *
+ *  @methods
+ *  - DefaultConfig()       - Returns the timeouts and retry policy used by the services.
+ *  - New(cfg)              - Returns an *http.Client with the timeouts and retries of cfg.
+ *  - NewTransport(cfg)     - Returns an *http.Transport with the connect, TLS and header timeouts of cfg.
+ *
+ *  @behaviors
+ *  - Connecting, the TLS handshake and waiting for the response headers each have their own timeout,
+ *    and the whole call, retries included, is bounded by Config.Timeout.
+ *  - GET and HEAD requests are retried up to Config.Retries times on network errors and 5xx
+ *    responses, waiting a jittered, doubling backoff in between. Other methods are never retried,
+ *    since the upstream may already have acted on them.
+ *  - A cancelled or expired request context stops the retries at once.
+ *  - When every attempt fails with a 5xx, the last response is returned so callers can report it.
+ *
+ *  @example
+ *  ```
+ *  cfg := httpclient.DefaultConfig()
+ *  cfg.Transport = appMetrics.Transport("news", httpclient.NewTransport(cfg))
+ *  newsService.HTTPClient = httpclient.New(cfg)
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package httpclient
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Default timeouts and retry policy of DefaultConfig.
+const (
+	DefaultDialTimeout           = 5 * time.Second
+	DefaultTLSHandshakeTimeout   = 5 * time.Second
+	DefaultResponseHeaderTimeout = 5 * time.Second
+	DefaultTimeout               = 10 * time.Second
+	DefaultRetries               = 2
+	DefaultRetryBackoff          = 100 * time.Millisecond
+)
+
+// Config holds the timeouts and retry policy of a client. Zero durations disable the timeout.
+type Config struct {
+	DialTimeout           time.Duration     // Longest wait to connect to the upstream.
+	TLSHandshakeTimeout   time.Duration     // Longest wait for the TLS handshake.
+	ResponseHeaderTimeout time.Duration     // Longest wait for the response headers after sending a request.
+	Timeout               time.Duration     // Longest time a whole call may take, retries included.
+	Retries               int               // How often a failed GET or HEAD is retried; 0 disables retries.
+	RetryBackoff          time.Duration     // Wait before the first retry; it doubles for each later one.
+	Transport             http.RoundTripper // Sends each attempt; NewTransport(cfg) if nil.
+}
+
+// DefaultConfig returns the timeouts and retry policy used for calls to third-party APIs.
+func DefaultConfig() Config {
+	return Config{
+		DialTimeout:           DefaultDialTimeout,
+		TLSHandshakeTimeout:   DefaultTLSHandshakeTimeout,
+		ResponseHeaderTimeout: DefaultResponseHeaderTimeout,
+		Timeout:               DefaultTimeout,
+		Retries:               DefaultRetries,
+		RetryBackoff:          DefaultRetryBackoff,
+	}
+}
+
+// NewTransport returns a transport like http.DefaultTransport with the connect, TLS handshake and
+// response header timeouts of cfg.
+func NewTransport(cfg Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	return transport
+}
+
+// New returns a client with the timeouts of cfg that retries failed GET and HEAD requests
+// cfg.Retries times.
+func New(cfg Config) *http.Client {
+	transport := cfg.Transport
+	if transport == nil {
+		transport = NewTransport(cfg)
+	}
+	if cfg.Retries > 0 {
+		transport = &retryTransport{next: transport, retries: cfg.Retries, backoff: cfg.RetryBackoff}
+	}
+	return &http.Client{Transport: transport, Timeout: cfg.Timeout}
+}
+
+// retryTransport resends idempotent requests that failed with a network error or a 5xx response.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+// RoundTrip sends req, retrying it if it is a GET or HEAD without a body and the attempt failed.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if (req.Method != http.MethodGet && req.Method != http.MethodHead) || (req.Body != nil && req.Body != http.NoBody) {
+		return t.next.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		retryable := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if !retryable || attempt >= t.retries || req.Context().Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			// Drain the body so the connection can be reused for the next attempt.
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(t.delay(attempt))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns a random wait between half and all of the backoff doubled attempt times.
+func (t *retryTransport) delay(attempt int) time.Duration {
+	backoff := t.backoff << attempt
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
@@ -0,0 +1,215 @@
+/**
+ *  I18n Package translates the messages DailyVerse shows its users, such as API response messages and
+ *  email subjects and bodies, from message catalogs embedded in the binary.
+ *
+ *  @file      i18n.go
+ *  @package   i18n
+ *  @purpose   Message catalogs and locale resolution for localized responses and emails.
+ *
+ *  @methods
+ *  - T(ctx, key, args...)                   - Translates a message into the locale stored in ctx.
+ *  - Translate(locale, key, args...)        - Translates a message into a locale.
+ *  - Match(language)                        - Returns the supported locale of a language tag, e.g. "nb-NO".
+ *  - ParseAcceptLanguage(header)            - Returns the preferred supported locale of an Accept-Language header.
+ *  - MatchLanguages(languages)              - Returns the first supported locale of a list of languages.
+ *  - WithLocale(ctx, locale)                - Returns a copy of ctx carrying a locale.
+ *  - FromContext(ctx)                       - Returns the locale stored in ctx, if any.
+ *  - Locale(ctx)                            - Returns the locale stored in ctx, or DefaultLocale.
+ *  - Locales()                              - Returns the supported locales.
+ *  - Keys(locale)                           - Returns the message keys of a locale's catalog.
+ *
+ *  @behaviors
+ *  - Catalogs live in locales/{locale}.json as flat objects of message keys to messages. English is the
+ *    reference catalog; every other catalog should have the same keys.
+ *  - A message missing from a catalog falls back to English, and a key missing from English too is
+ *    returned as-is, so a missing translation never hides a response.
+ *  - Messages with arguments are formatted with fmt.Sprintf, e.g. "%s sent you a friend request".
+ *  - Norwegian is served as Bokmål ("nb"): the "no" and "nn" language tags match it too.
+ *
+ *  @example
+ *  ```
+ *  ctx = i18n.WithLocale(ctx, "nb")
+ *  i18n.T(ctx, "friend.request_sent") // "Venneforespørsel sendt"
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the locale messages are translated into when no other locale is known, and the
+// catalog missing translations fall back to.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs maps each supported locale to its messages, keyed by message key.
+var catalogs = loadCatalogs()
+
+// aliases maps language codes to the supported locale they are served in.
+var aliases = map[string]string{
+	"no": "nb", // Norwegian, as stored for Norway in CountryLanguageMap.
+	"nn": "nb", // Nynorsk readers understand Bokmål.
+}
+
+// loadCatalogs parses the embedded catalogs. It panics if they are invalid, since they are part of the binary.
+func loadCatalogs() map[string]map[string]string {
+	files, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: failed to read catalogs: %v", err))
+	}
+	loaded := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := localeFS.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read %s: %v", file.Name(), err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", file.Name(), err))
+		}
+		loaded[strings.TrimSuffix(file.Name(), ".json")] = messages
+	}
+	return loaded
+}
+
+// Translate returns the message with the given key in locale, formatted with args if there are any.
+// A message missing from locale falls back to English, and a key missing from English to the key itself.
+func Translate(locale, key string, args ...interface{}) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		if message, ok = catalogs[DefaultLocale][key]; !ok {
+			message = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// T returns the message with the given key in the locale stored in ctx, formatted with args.
+func T(ctx context.Context, key string, args ...interface{}) string {
+	return Translate(Locale(ctx), key, args...)
+}
+
+// Match returns the supported locale of a language tag such as "nb", "nb-NO" or "en_US", ignoring case
+// and the region. The boolean is false if the language is not supported.
+func Match(language string) (string, bool) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	if alias, ok := aliases[language]; ok {
+		language = alias
+	}
+	_, ok := catalogs[language]
+	return language, ok && language != ""
+}
+
+// MatchLanguages returns the first supported locale of languages, e.g. the languages of a country with
+// the primary one first. The boolean is false if none is supported.
+func MatchLanguages(languages []string) (string, bool) {
+	for _, language := range languages {
+		if locale, ok := Match(language); ok {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// ParseAcceptLanguage returns the supported locale the client prefers most in an Accept-Language header,
+// e.g. "nb" for "en;q=0.5, nb-NO". Languages are ranked by their q-value, then by their position; "*"
+// and languages with q=0 are ignored. The boolean is false if no language is supported.
+func ParseAcceptLanguage(header string) (string, bool) {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		q := 1.0
+		for _, param := range fields[1:] {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				parsed = 0
+			}
+			q = parsed
+		}
+		if locale, ok := Match(fields[0]); ok && q > 0 {
+			candidates = append(candidates, candidate{locale: locale, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].locale, true
+}
+
+// contextKey is the type of the key the locale is stored under in a context.
+type contextKey struct{}
+
+// WithLocale returns a copy of ctx carrying locale.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale stored in ctx. The boolean is false if no locale was stored.
+func FromContext(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(contextKey{}).(string)
+	return locale, ok && locale != ""
+}
+
+// Locale returns the locale stored in ctx, or DefaultLocale if none was stored.
+func Locale(ctx context.Context) string {
+	if locale, ok := FromContext(ctx); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Locales returns the supported locales, sorted.
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Keys returns the message keys of locale's catalog, sorted, or nil if the locale is not supported.
+func Keys(locale string) []string {
+	messages, ok := catalogs[locale]
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(messages))
+	for key := range messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
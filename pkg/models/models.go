@@ -10,14 +10,32 @@
  *  - User: Represents a user account with details like username, email, and password.
  *  - LoginRequest: Represents the request payload for user login.
  *  - Event: Represents event details for user-created events.
- *  - Journal: Represents a daily journal entry linked to a user.
+ *  - NearbyEvent: Represents an event with its distance from a searched position.
+ *  - Recurrence: Describes how an event repeats (daily or weekly, with an interval and an end).
+ *  - EventInvitation: Represents an invitation of a friend to an event and their RSVP status.
+ *  - EventQuery: Represents date-range and pagination options for listing events.
+ *  - EventPage: Represents a single page of events and the token for the next page.
+ *  - TagCount: Represents how many of a user's events carry a tag.
+ *  - BulkEventResult: Reports which events of a bulk create or delete succeeded and which failed.
+ *  - BulkEventFailure: Describes why a single event of a bulk operation failed.
+ *  - Journal: Represents a daily journal entry linked to a user, with an optional mood and tags.
+ *  - JournalStats: Summarises a user's journal entries, moods and writing streaks in one month.
+ *  - JournalImportResult: Summarises the outcome of a journal import.
+ *  - JournalImportRowResult: Describes the outcome for a single row of a journal import.
  *  - Friend: Manages friendships or friend requests between users.
- *  - Claims: Represents JWT claims for authentication.
+ *  - Block: Records that one user has blocked another.
  *  - TimetableEvent: Represents events retrieved from the NTNU timetable API.
+ *  - ImportResult: Summarises the outcome of a timetable import.
+ *  - ImportEventResult: Describes the outcome for a single imported timetable event.
+ *  - ImportBatch: Summarises the events created by one timetable import, so the import can be undone.
  *  - UserSummary: Provides minimal user information for frontend display.
+ *  - Notification: Represents a notification stored in a user's inbox and pushed to their WebSocket connections.
+ *  - IdempotencyRecord: Records the event created for a client-supplied Idempotency-Key.
+ *  - Quote: Represents an entry of the curated dataset the daily verse is chosen from.
+ *  - FavoriteQuote: Represents a quote a user has saved to their favourites.
  *
  *  @dependencies
- *  - github.com/dgrijalva/jwt-go: For handling JWT authentication claims.
+ *  - time: For timestamps such as creation dates and OTP expiry.
  *
  *  @example
  *  ```
@@ -40,24 +58,134 @@ package models
 
 import (
 	"time"
-
-	"github.com/dgrijalva/jwt-go"
 )
 
 // User represents a user account with profile and authentication details.
 type User struct {
-	Username      string    `json:"username"`
-	UsernameLower string    `json:"usernameLower"` // Lowercase version of the username for case-insensitive operations.
-	Email         string    `json:"email"`
-	Password      string    `json:"-"` // Stored as a hashed password.
-	Country       string    `json:"country"`
-	City          string    `json:"city"`
-	ImageURL      string    `json:"imageUrl,omitempty"`
-	FirstName     string    `json:"firstName,omitempty"`
-	LastName      string    `json:"lastName,omitempty"`
-	IsVerified    bool      `json:"isVerified"`
-	OTP           string    `json:"-"` // One-Time Password for verification.
-	OTPExpiresAt  time.Time `json:"-"` // Expiration time for the OTP.
+	Username       string    `json:"username"`
+	UsernameLower  string    `json:"usernameLower"` // Lowercase version of the username for case-insensitive operations.
+	Email          string    `json:"email"`
+	Password       string    `json:"-"` // Stored as a hashed password.
+	Country        string    `json:"country"`
+	City           string    `json:"city"`
+	ImageURL       string    `json:"imageUrl,omitempty"`
+	FirstName      string    `json:"firstName,omitempty"`
+	LastName       string    `json:"lastName,omitempty"`
+	FirstNameLower string    `json:"-"` // Lowercase first name for case-insensitive search.
+	LastNameLower  string    `json:"-"` // Lowercase last name for case-insensitive search.
+	IsVerified     bool      `json:"isVerified"`
+	OTP            string    `json:"-"` // Hash of the one-time password for verification (see utils.HashOTP).
+	OTPExpiresAt   time.Time `json:"-"` // Expiration time for the OTP.
+
+	// Brute-force protection. FailedLoginCount counts wrong passwords since the last successful login,
+	// logins are refused until LockedUntil, and OTPAttempts counts wrong submissions of the current OTP.
+	FailedLoginCount int       `json:"-"`
+	LockedUntil      time.Time `json:"-"`
+	OTPAttempts      int       `json:"-"`
+
+	// NotificationsEnabled controls notification emails such as friend requests.
+	// Nil means enabled, so accounts created before the setting existed keep receiving them.
+	NotificationsEnabled *bool `json:"notificationsEnabled,omitempty"`
+
+	// DigestEnabled opts the user in to the weekly digest email. DigestSentFor is the local date
+	// (YYYY-MM-DD) of the Monday the last digest was sent for, so each week's digest is sent once.
+	DigestEnabled bool   `json:"digestEnabled"`
+	DigestSentFor string `json:"-"`
+
+	// ReminderEnabled opts the user in to a daily email reminding them to write in their journal at
+	// ReminderTime (HH:MM in their time zone). LastReminderSentDate is the local date (YYYY-MM-DD) the
+	// last reminder was sent on, so each day's reminder is sent once.
+	ReminderEnabled      bool   `json:"reminderEnabled"`
+	ReminderTime         string `json:"reminderTime,omitempty"`
+	LastReminderSentDate string `json:"-"`
+
+	// QuietStart and QuietEnd (HH:MM in the user's time zone) are the user's quiet hours, during which
+	// notification emails are held back until QuietEnd. The window may wrap past midnight, e.g. 22:00
+	// to 07:00. Both are empty when the user has no quiet hours.
+	QuietStart string `json:"quietStart,omitempty"`
+	QuietEnd   string `json:"quietEnd,omitempty"`
+
+	// TimeZone is the IANA time zone the user sees times in, e.g. "Europe/Oslo". Empty means the
+	// time zone of their country.
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// FeedToken is the secret in the URL of the user's calendar feed. Empty means the feed is off.
+	FeedToken string `json:"-"`
+
+	// TokenVersion is embedded in every JWT issued to the user and bumped whenever the password
+	// changes, so tokens issued before the change are rejected.
+	TokenVersion int `json:"-"`
+
+	// Pending email change. The change is applied once the OTP sent to PendingEmail is confirmed;
+	// it uses its own OTP fields so it cannot be mixed up with email verification or password resets.
+	// EmailChangeOTP holds only the hash of the OTP.
+	PendingEmail            string    `json:"-"`
+	EmailChangeOTP          string    `json:"-"`
+	EmailChangeOTPExpiresAt time.Time `json:"-"`
+	EmailChangeOTPAttempts  int       `json:"-"`
+
+	// MovedFrom is the email a user was copied from by a move to a new email that has not finished,
+	// e.g. because the database failed halfway. Moving the user again resumes the move, which clears it.
+	MovedFrom string `json:"-"`
+
+	// Role is RoleUser or RoleAdmin; empty means RoleUser. It is only ever set in the database, never
+	// through signup or a profile update. Disabled accounts cannot log in or use their tokens.
+	Role     string `json:"-"`
+	Disabled bool   `json:"-"`
+
+	// CityUnlisted is set by signup when City is missing from the city list of the country. The list is
+	// incomplete, so the city is kept; the flag is only reported back and never stored.
+	CityUnlisted bool `json:"-" firestore:"-"`
+
+	// CreatedAt is when the account was created. It is zero for accounts created before it was recorded.
+	CreatedAt time.Time `json:"-"`
+}
+
+// UserProfile is the authenticated user's own account, returned by both GET /api/me and GET /api/profile.
+type UserProfile struct {
+	Email                string     `json:"email"`
+	Username             string     `json:"username"`
+	FirstName            string     `json:"firstName"`
+	LastName             string     `json:"lastName"`
+	Country              string     `json:"country"`
+	City                 string     `json:"city"`
+	ImageURL             string     `json:"imageUrl"`
+	IsVerified           bool       `json:"isVerified"`
+	CreatedAt            *time.Time `json:"createdAt,omitempty"` // Left out for accounts created before it was recorded.
+	NotificationsEnabled bool       `json:"notificationsEnabled"`
+	DigestEnabled        bool       `json:"digestEnabled"`
+	ReminderEnabled      bool       `json:"reminderEnabled"`        // Whether the daily journal reminder is sent.
+	ReminderTime         string     `json:"reminderTime,omitempty"` // Time of day (HH:MM) of the journal reminder.
+	QuietStart           string     `json:"quietStart,omitempty"`   // Start (HH:MM) of the quiet hours; empty without quiet hours.
+	QuietEnd             string     `json:"quietEnd,omitempty"`     // End (HH:MM) of the quiet hours, when held emails are sent.
+	TimeZone             string     `json:"timeZone"`               // The user's IANA time zone, or that of their country; empty if neither is known.
+}
+
+// Roles a user can have.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// AdminUserSummary describes an account to administrators.
+type AdminUserSummary struct {
+	Email       string     `json:"email"`
+	Username    string     `json:"username"`
+	FirstName   string     `json:"firstName,omitempty"`
+	LastName    string     `json:"lastName,omitempty"`
+	Country     string     `json:"country"`
+	City        string     `json:"city"`
+	Role        string     `json:"role"`
+	IsVerified  bool       `json:"isVerified"`
+	Disabled    bool       `json:"disabled"`
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"` // Set while logins are locked after wrong passwords.
+}
+
+// EmailNormalizationReport lists what happened to each account stored under an email that was not normalized.
+type EmailNormalizationReport struct {
+	Migrated  []string `json:"migrated"`  // Moved to the normalized email.
+	Conflicts []string `json:"conflicts"` // Left alone, since an account with the normalized email exists.
+	Failed    []string `json:"failed"`    // Could not be moved; the server log has the reason.
 }
 
 // LoginRequest represents the payload for user login requests.
@@ -71,7 +199,7 @@ type Event struct {
 	EventID       string `json:"eventID"`
 	StreetAddress string `json:"streetAddress"`
 	PostalNumber  string `json:"postalNumber"`
-	Status        string `json:"status"`
+	Status        string `json:"status"` // "tentative", "confirmed" or "cancelled".
 	Description   string `json:"description"`
 	Time          string `json:"time"`
 	EventTypeID   string `json:"eventTypeID"`
@@ -80,6 +208,172 @@ type Event struct {
 	Title         string `json:"title"`
 	StartTime     string `json:"startTime"`
 	EndTime       string `json:"endTime"`
+
+	StartAt               time.Time  `json:"startAt"`                         // Parsed start timestamp derived from Date and StartTime.
+	EndAt                 *time.Time `json:"endAt,omitempty"`                 // Parsed end timestamp derived from Date and EndTime; nil without an end time.
+	TimeZone              string     `json:"timeZone,omitempty"`              // IANA time zone Date, StartTime and EndTime are in; empty for events stored before time zones were recorded.
+	ReminderMinutesBefore int        `json:"reminderMinutesBefore,omitempty"` // Minutes before StartAt to send a reminder; 0 disables it.
+	ReminderSent          bool       `json:"reminderSent"`                    // Whether the reminder email has already been sent.
+	ExternalID            string     `json:"externalID,omitempty"`            // Stable ID of the event in an imported calendar, e.g. the ICS UID.
+
+	ImportBatchID string     `json:"importBatchID,omitempty"` // ID of the timetable import that created the event; empty for events created manually.
+	ImportedAt    *time.Time `json:"importedAt,omitempty"`    // When the import that created the event ran.
+
+	Recurrence     *Recurrence `json:"recurrence,omitempty"`     // Repeat rule; nil for a one-off event.
+	ExceptionDates []string    `json:"exceptionDates,omitempty"` // Occurrence dates (YYYY-MM-DD) removed from or rescheduled out of the series.
+	SeriesID       string      `json:"seriesID,omitempty"`       // ID of the recurring event this event replaces one occurrence of.
+
+	Tags []string `json:"tags,omitempty"` // Lowercase labels such as "work" or "gym", used to filter events.
+
+	Color  string `json:"color,omitempty"` // Calendar color: "#RRGGBB" or a name from the palette, such as "blue"; empty for the default.
+	AllDay bool   `json:"allDay"`          // Whether the event lasts the whole day; all-day events have no start or end time.
+
+	Latitude  *float64 `json:"latitude,omitempty"`  // Latitude of the address in degrees; nil if unknown.
+	Longitude *float64 `json:"longitude,omitempty"` // Longitude of the address in degrees; nil if unknown.
+
+	CancelledAt *time.Time `json:"cancelledAt,omitempty"` // When the event was cancelled; nil unless Status is "cancelled".
+}
+
+// NearbyEvent is an event with its distance from the location events were searched around.
+type NearbyEvent struct {
+	Event
+	DistanceKm float64 `json:"distanceKm"` // Great-circle distance in kilometres.
+}
+
+// EventSearchResult is an event matching a search, with the field the query was found in.
+type EventSearchResult struct {
+	Event
+	MatchedField string `json:"matchedField"` // "title", "description" or "streetAddress".
+}
+
+// EventShare is a read-only link to an event for people without an account. It is stored under the
+// hash of its token, so only the owner who created the link knows the token.
+type EventShare struct {
+	Email     string    `json:"email"`     // Owner of the shared event.
+	EventID   string    `json:"eventID"`   // The shared event.
+	CreatedAt time.Time `json:"createdAt"` // When the link was created.
+	ExpiresAt time.Time `json:"expiresAt"` // When the link stops working.
+}
+
+// EventShareLink is returned to the owner of an event when the event is shared.
+type EventShareLink struct {
+	Token     string    `json:"token"`     // Unguessable token identifying the link.
+	URL       string    `json:"url"`       // Page of the web app showing the shared event.
+	ExpiresAt time.Time `json:"expiresAt"` // When the link stops working.
+}
+
+// CalendarFeed is the URL calendar apps subscribe to, to keep showing the user's events.
+type CalendarFeed struct {
+	Token string `json:"token"` // Secret token identifying the feed.
+	URL   string `json:"url"`   // ICS URL to paste into a calendar app.
+}
+
+// SharedEvent is the read-only view of a shared event shown to anyone with the link. It never
+// contains the owner's email or other account data.
+type SharedEvent struct {
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	Date          string     `json:"date"`
+	StartTime     string     `json:"startTime"`
+	EndTime       string     `json:"endTime"`
+	StartAt       time.Time  `json:"startAt"`
+	EndAt         *time.Time `json:"endAt,omitempty"`
+	TimeZone      string     `json:"timeZone,omitempty"` // Time zone of the owner, which the date and times are in.
+	AllDay        bool       `json:"allDay"`
+	StreetAddress string     `json:"streetAddress"`
+	PostalNumber  string     `json:"postalNumber"`
+	Status        string     `json:"status"` // "tentative", "confirmed" or "cancelled", so guests learn of cancellations.
+}
+
+// FriendEvent is the view of a public event shown to the owner's accepted friends. It names the owner
+// by username and leaves out their email, invitees, reminders, tags and other account data.
+type FriendEvent struct {
+	EventID       string     `json:"eventID"`
+	Username      string     `json:"username"` // Username of the friend who owns the event.
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	Date          string     `json:"date"`
+	StartTime     string     `json:"startTime"`
+	EndTime       string     `json:"endTime"`
+	StartAt       time.Time  `json:"startAt"`
+	EndAt         *time.Time `json:"endAt,omitempty"`
+	TimeZone      string     `json:"timeZone,omitempty"` // Time zone of the viewer, which the date and times are in.
+	AllDay        bool       `json:"allDay"`
+	StreetAddress string     `json:"streetAddress"`
+	PostalNumber  string     `json:"postalNumber"`
+	Status        string     `json:"status"`
+}
+
+// EventMonth summarizes the events of the weeks a calendar month is shown in, one entry per day with events.
+type EventMonth struct {
+	Month string            `json:"month"` // The month, YYYY-MM.
+	From  string            `json:"from"`  // First day of the grid, the Monday of the week of the 1st.
+	To    string            `json:"to"`    // Last day of the grid, the Sunday of the week of the last day.
+	Weeks int               `json:"weeks"` // Number of weeks in the grid, 4 to 6.
+	Days  []EventDaySummary `json:"days"`  // Days with events, in date order.
+}
+
+// EventDaySummary summarizes the events of one day of an EventMonth.
+type EventDaySummary struct {
+	Date       string   `json:"date"`             // YYYY-MM-DD.
+	Count      int      `json:"count"`            // Number of events on the day, counting each occurrence of a series.
+	FirstTitle string   `json:"firstTitle"`       // Title of the first event of the day, all-day events first, then by start time.
+	Colors     []string `json:"colors,omitempty"` // The distinct colors of the day's events, in the order of the events.
+}
+
+// Recurrence describes how an event repeats. Occurrences are computed when events are listed,
+// so a series is stored as a single event.
+type Recurrence struct {
+	Frequency string `json:"frequency"`       // "daily" or "weekly".
+	Interval  int    `json:"interval"`        // Repeat every Interval days or weeks; 0 is treated as 1.
+	Until     string `json:"until,omitempty"` // Last possible occurrence date (YYYY-MM-DD), inclusive.
+	Count     int    `json:"count,omitempty"` // Total number of occurrences, including removed ones.
+}
+
+// EventInvitation represents an invitation of a friend to another user's event.
+type EventInvitation struct {
+	EventID      string `json:"eventID"`
+	OwnerEmail   string `json:"ownerEmail"`                    // Email of the user who owns the event.
+	InviteeEmail string `json:"inviteeEmail"`                  // Email of the invited friend.
+	Status       string `json:"status"`                        // "pending", "accepted" or "declined".
+	Event        *Event `json:"event,omitempty" firestore:"-"` // Event details, populated when listing invitations.
+}
+
+// EventQuery holds the optional filters and pagination options for listing a user's events.
+// The zero value returns every event in a single page.
+type EventQuery struct {
+	From      string // Inclusive lower bound on Date (YYYY-MM-DD); empty for no bound.
+	To        string // Inclusive upper bound on Date (YYYY-MM-DD); empty for no bound.
+	Limit     int    // Maximum number of events per page; 0 for no limit.
+	PageToken string // Opaque cursor returned as NextPageToken by the previous page.
+	Tag       string // Only events carrying this tag; empty for all events.
+
+	IncludeCancelled bool // Also return cancelled events, which are left out by default.
+}
+
+// EventPage represents one page of events ordered by date.
+type EventPage struct {
+	Items         []Event `json:"items"`
+	NextPageToken string  `json:"nextPageToken"` // Empty when there are no more events.
+}
+
+// TagCount represents how many of a user's events carry a tag.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// BulkEventResult reports the outcome of a bulk create or delete; each event succeeds or fails on its own.
+type BulkEventResult struct {
+	Succeeded []string           `json:"succeeded"` // IDs of the created or deleted events, in request order.
+	Failed    []BulkEventFailure `json:"failed"`
+}
+
+// BulkEventFailure describes why a single event of a bulk operation failed.
+type BulkEventFailure struct {
+	Index   int    `json:"index"`             // Position of the event in the request.
+	EventID string `json:"eventID,omitempty"` // Empty for events that could not be created.
+	Error   string `json:"error"`
 }
 
 // Journal represents a daily journal entry linked to a user.
@@ -88,19 +382,94 @@ type Journal struct {
 	Date      string `json:"date"`
 	Content   string `json:"content"`
 	Email     string `json:"email"` // User's email as a foreign key.
+
+	Mood string   `json:"mood,omitempty"` // One of "great", "good", "neutral", "bad" or "awful"; empty if not logged.
+	Tags []string `json:"tags,omitempty"` // Lowercase labels, validated like event tags.
+
+	PromptID string `json:"promptId,omitempty"` // ID of the JournalPrompt the entry was written for; set on creation only.
+
+	// Attachments are the images attached to the entry, oldest first. They are only changed by
+	// uploading or deleting an attachment, never by saving the entry.
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	DeletedAt *time.Time `json:"deletedAt,omitempty"` // When the entry was moved to the trash; nil unless deleted.
+
+	// Encryption is the version of the encryption of Content at rest, 0 for plaintext, and KeyEmail
+	// the email its key was derived from. Both are only set on entries as stored, never in the API.
+	Encryption int    `json:"-"`
+	KeyEmail   string `json:"-"`
+}
+
+// Attachment is an image attached to a journal entry, kept private in the StorageService.
+type Attachment struct {
+	ID          string `json:"id"`
+	URL         string `json:"url"`         // API path the image is downloaded from with the owner's token.
+	Object      string `json:"-"`           // URL of the image in the StorageService; never exposed.
+	ContentType string `json:"contentType"` // "image/jpeg", "image/png" or "image/webp".
+	Size        int    `json:"size"`        // Size of the image in bytes.
+}
+
+// JournalStats summarises a user's journal entries in one month.
+type JournalStats struct {
+	Month            string         `json:"month"`            // YYYY-MM.
+	EntryCount       int            `json:"entryCount"`       // Number of entries written in the month.
+	MoodDistribution map[string]int `json:"moodDistribution"` // Entries per mood; entries without a mood are not counted.
+	CurrentStreak    int            `json:"currentStreak"`    // Consecutive days with an entry up to today, or the end of a past month.
+	LongestStreak    int            `json:"longestStreak"`    // Longest run of consecutive days with an entry in the month.
+}
+
+// JournalImportResult summarises the outcome of importing journal entries from a JSON or CSV file.
+type JournalImportResult struct {
+	Imported int                      `json:"imported"` // New entries saved.
+	Merged   int                      `json:"merged"`   // Rows appended to an entry of the same date.
+	Skipped  int                      `json:"skipped"`  // Rows ignored because they were invalid or their date already has an entry.
+	Failed   int                      `json:"failed"`   // Rows that could not be saved.
+	Rows     []JournalImportRowResult `json:"rows"`
+}
+
+// JournalImportRowResult describes what happened to a single row during a journal import.
+type JournalImportRowResult struct {
+	Row    int    `json:"row"`              // 1-based position of the entry in the file, not counting a CSV header.
+	Date   string `json:"date,omitempty"`   // Format: "YYYY-MM-DD".
+	Status string `json:"status"`           // "imported", "merged", "skipped" or "failed".
+	Reason string `json:"reason,omitempty"` // Why the row was skipped or failed.
+}
+
+// UserStats summarises a user's activity for the dashboard.
+type UserStats struct {
+	Events     int `json:"events"`     // Number of events the user has.
+	Journals   int `json:"journals"`   // Number of journal entries outside the trash.
+	Friends    int `json:"friends"`    // Number of accepted friendships.
+	ActiveDays int `json:"activeDays"` // Days of the current month with a journal entry.
 }
 
 // Friend manages friendships or friend requests between users.
 type Friend struct {
-	Email       string `json:"email"`       // Email of the user who sent the request.
-	FriendEmail string `json:"friendEmail"` // Email of the user who received the request.
-	Status      string `json:"status"`      // "pending" or "accepted".
+	Email       string    `json:"email"`       // Email of the user who sent the request.
+	FriendEmail string    `json:"friendEmail"` // Email of the user who received the request.
+	Status      string    `json:"status"`      // "pending", "accepted" or "declined".
+	CreatedAt   time.Time `json:"createdAt"`   // When the request was sent. Zero for requests sent before it was recorded.
+
+	// DeclinedAt is when the recipient declined the request. A declined request is kept for a while
+	// so the sender cannot send a new one straight away.
+	DeclinedAt *time.Time `json:"declinedAt,omitempty"`
+}
+
+// FriendInvitation records that InviterEmail invited someone who had no account yet to join and
+// become their friend. When InviteeEmail signs up, the invitation becomes a pending friend request.
+type FriendInvitation struct {
+	InviterEmail string     `json:"inviterEmail"`
+	InviteeEmail string     `json:"inviteeEmail"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	ConsumedAt   *time.Time `json:"consumedAt,omitempty"` // When the invitee signed up. Nil while pending.
 }
 
-// Claims represents JWT claims for authentication and user identification.
-type Claims struct {
-	Email string `json:"email"`
-	jwt.StandardClaims
+// Block records that BlockerEmail has blocked BlockedEmail. Blocked users cannot
+// exchange friend requests with the blocker.
+type Block struct {
+	BlockerEmail string    `json:"blockerEmail"`
+	BlockedEmail string    `json:"blockedEmail"`
+	CreatedAt    time.Time `json:"createdAt"`
 }
 
 // TimetableEvent represents the structure of events received from the NTNU timetable API.
@@ -113,10 +482,135 @@ type TimetableEvent struct {
 	EndTime     string `json:"endTime"`   // Format: "HH:MM".
 }
 
+// ImportResult summarises the outcome of importing an ICS timetable.
+type ImportResult struct {
+	DryRun   bool                `json:"dryRun"`            // True if the events were only validated, not saved.
+	Imported int                 `json:"imported"`          // New events saved, or that would be saved in a dry run.
+	Existing int                 `json:"existing"`          // Events already present from an earlier import; they are updated in place.
+	Skipped  int                 `json:"skipped"`           // Events ignored because they could not be parsed.
+	Failed   int                 `json:"failed"`            // Events that could not be saved.
+	BatchID  string              `json:"batchID,omitempty"` // Undoes the import with DELETE /api/import-ntnu-timetable; empty if no event was created.
+	Events   []ImportEventResult `json:"events"`
+}
+
+// ImportBatch summarises the events created by one timetable import that are still stored.
+type ImportBatch struct {
+	BatchID    string    `json:"batchID"`
+	ImportedAt time.Time `json:"importedAt"`
+	EventCount int       `json:"eventCount"`
+}
+
+// ImportEventResult describes what happened to a single event during a timetable import.
+type ImportEventResult struct {
+	Title     string `json:"title"`
+	Date      string `json:"date,omitempty"`      // Format: "YYYY-MM-DD".
+	StartTime string `json:"startTime,omitempty"` // Format: "HH:MM".
+	EndTime   string `json:"endTime,omitempty"`   // Format: "HH:MM".
+	Status    string `json:"status"`              // "imported", "existing", "skipped" or "failed".
+	Reason    string `json:"reason,omitempty"`    // Why the event was skipped or failed.
+}
+
 // UserSummary provides minimal user information for frontend display.
 type UserSummary struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Country  string `json:"country"`
 	City     string `json:"city"`
+	ImageURL string `json:"imageUrl,omitempty"` // Profile picture, if the user uploaded one.
+
+	// FriendsSince is when the friendship was established. It is only set in friends lists,
+	// and only for friendships created after the date was recorded.
+	FriendsSince *time.Time `json:"friendsSince,omitempty"`
+
+	// MutualFriends is the number of friends in common with the user. It is only set in friend suggestions.
+	MutualFriends int `json:"mutualFriends,omitempty"`
+}
+
+// Notification is stored in a user's inbox under users/{email}/notifications and pushed
+// to the user's open WebSocket connections.
+type Notification struct {
+	ID        string            `json:"id"`
+	Email     string            `json:"-"`                 // Email of the user the notification is for.
+	Type      string            `json:"type"`              // "friend_request", "friend_accepted" or "event_invitation".
+	Message   string            `json:"message"`           // Human-readable text for the client to display.
+	Payload   map[string]string `json:"payload,omitempty"` // Details such as the other user's username or the event ID.
+	CreatedAt time.Time         `json:"createdAt"`
+	Read      bool              `json:"read"`
+}
+
+// IdempotencyRecord remembers which event was created for a client-supplied Idempotency-Key,
+// so a retried create request returns the original event instead of creating a duplicate.
+type IdempotencyRecord struct {
+	Key         string    // Idempotency-Key header sent by the client.
+	Email       string    // Email of the user the key belongs to; keys are scoped per user.
+	RequestHash string    // SHA-256 of the original request, to detect a key reused for a different request.
+	Status      string    // "pending" while the event is being created, then "completed".
+	EventID     string    // ID of the created event, stored as soon as it exists.
+	Response    []byte    // The created event as JSON, once completed.
+	CreatedAt   time.Time // When the key was first seen.
+	ExpiresAt   time.Time // After this time the key can be used again.
+	// LeaseExpiresAt is when a pending record without an EventID may be taken over by another request,
+	// because the request that created it must have failed.
+	LeaseExpiresAt time.Time
+}
+
+// Quote is an entry of the curated dataset the daily verse is chosen from.
+type Quote struct {
+	ID       string   `json:"id"`             // Stable ID, e.g. "en-042"; favourites refer to it.
+	Language string   `json:"language"`       // Two-letter language code, e.g. "en" or "no".
+	Text     string   `json:"text"`           // The quote itself.
+	Author   string   `json:"author"`         // Author or source, e.g. "Seneca" or "Norsk ordtak".
+	Tags     []string `json:"tags,omitempty"` // Lowercase themes, e.g. "courage".
+}
+
+// FavoriteQuote is a quote a user has saved, stored under users/{email}/favorites with the
+// quote's ID as the document ID. The quote is copied, so favourites outlive edits to the dataset.
+type FavoriteQuote struct {
+	Quote
+	Email   string    `json:"-"` // Email of the user who saved the quote.
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// JournalPrompt is a writing prompt suggested to users who do not know what to write in their journal.
+type JournalPrompt struct {
+	ID   string `json:"id"`   // Stable ID, e.g. "prompt-007"; journal entries refer to it.
+	Text string `json:"text"` // The prompt itself.
+}
+
+// PromptOffset is how many prompts a user has skipped, stored at users/{email}/settings/journalPrompt.
+// It moves the user along the daily rotation of prompts.
+type PromptOffset struct {
+	Email     string    `json:"-"`
+	Offset    int       `json:"offset"`
+	UpdatedAt time.Time `json:"updatedAt"` // When the user last skipped a prompt; zero if never.
+}
+
+// AuditEntry records a security-sensitive action on an account, such as a login or a password
+// change, under users/{email}/audit, so users and support can see when and from where it happened.
+type AuditEntry struct {
+	Email     string    `json:"-"`         // Email of the account the action was taken on.
+	Action    string    `json:"action"`    // E.g. "login_succeeded" or "password_changed".
+	IP        string    `json:"ip"`        // Client IP of the request, if known.
+	UserAgent string    `json:"userAgent"` // User-Agent of the request, if known.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PendingDelivery is a notification email held back during the recipient's quiet hours, which is
+// sent once DeliverAt is reached.
+type PendingDelivery struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"-"`         // Email of the recipient.
+	Subject   string    `json:"subject"`   // Subject of the rendered email.
+	Text      string    `json:"-"`         // Plaintext body of the rendered email.
+	HTML      string    `json:"-"`         // HTML body of the rendered email.
+	DeliverAt time.Time `json:"deliverAt"` // End of the quiet hours the email was held back in.
+	CreatedAt time.Time `json:"createdAt"` // When the email would have been sent.
+}
+
+// RateLimitBucket is the token bucket of one client of a rate limiter.
+type RateLimitBucket struct {
+	Key      string    `json:"key"`      // Name of the limiter and the client, e.g. "signup:203.0.113.7".
+	Tokens   float64   `json:"tokens"`   // Requests the client could make at LastSeen.
+	LastSeen time.Time `json:"lastSeen"` // When the client last made a request.
+	FullAt   time.Time `json:"fullAt"`   // When the bucket has refilled completely; after that it can be forgotten.
 }
@@ -8,28 +8,51 @@
  *  @purpose   Utility functions for authentication, validation, and response handling.
  *
  *  @methods
- *  - GenerateJWT(email)                   - Generates a JWT token for the given email.
- *  - HashPassword(password)               - Hashes a password using SHA-256.
+ *  - NewJWTManager(primaryKey, oldKeys, ttl, issuer) - Creates a JWTManager that signs tokens and hashes OTPs with primaryKey.
+ *  - (JWTManager) GenerateJWT(email, tokenVersion) - Generates a JWT token for the given email and token version.
+ *  - (JWTManager) ParseAndValidate(tokenString) - Validates a token's signature, expiry, issuer and audience and returns its claims.
+ *  - HashPassword(password)               - Hashes a password using bcrypt.
+ *  - IsLegacyPasswordHash(hash)           - Detects a legacy SHA-256 password hash.
+ *  - CheckLegacyPasswordHash(password, hash) - Compares a plain password with a legacy SHA-256 hash.
  *  - IsValidPassword(password)            - Validates password complexity requirements.
- *  - GenerateOTP()                        - Generates a random 6-digit OTP.
+ *  - GenerateOTP()                        - Generates a random 6-digit OTP using crypto/rand.
+ *  - GenerateOTPOfLength(length)           - Generates a random OTP of the given number of digits.
+ *  - (JWTManager) HashOTP(otp)            - Hashes an OTP with HMAC-SHA256 keyed by the server secret.
+ *  - (JWTManager) CheckOTP(otp, hash)     - Compares an OTP with its stored hash in constant time.
  *  - WriteJSON(w, data)                   - Writes a JSON response to the HTTP response writer.
+ *  - WriteJSONWithETag(w, r, data)        - Writes a JSON response with an ETag, honouring If-None-Match and HEAD.
  *  - WriteJSONError(w, message, code)     - Writes an error message as a JSON response.
+ *  - WriteJSONValidationError(w, fieldErrors) - Writes a 400 response listing the invalid fields.
  *  - CheckPasswordHash(password, hash)    - Compares a plain password with its hashed version.
  *  - IsValidEmail(email)                  - Validates if a string is a properly formatted email.
+ *  - NormalizeEmail(email)                - Trims and lowercases an email into the form it is stored in.
  *
  *  @dependencies
  *  - golang.org/x/crypto/bcrypt: Used for secure password hashing and comparison.
- *  - github.com/dgrijalva/jwt-go: Used for generating and validating JWT tokens.
- *  - crypto/sha256: Provides hashing capabilities.
+ *  - github.com/golang-jwt/jwt/v5: Used for generating and validating JWT tokens.
+ *  - crypto/sha256: Verifies legacy password hashes created before the bcrypt migration.
+ *  - crypto/hmac: Hashes OTPs so that only their hash is stored.
+ *  - crypto/rand: Generates unpredictable OTP digits.
  *
  *  @example
  *  ```
- *  hashedPassword := HashPassword("Secure@123")
+ *  hashedPassword, err := HashPassword("Secure@123")
  *  isValid := IsValidPassword("Secure@123")
  *  ```
  *
- *  @environment_variables
- *  - JWT_SECRET_KEY: Secret key used for signing JWT tokens.
+ *  @behaviors
+ *  - Tokens are signed with HS256 and the primary key only. Tokens signed with an old key are still
+ *    accepted, so the secret can be rotated without logging everyone out: move the current secret to the
+ *    old keys, and drop it once every token it signed has expired.
+ *  - Tokens must carry the manager's issuer, the JWTAudience audience, an issue time and an expiry.
+ *    Any other signing method, including "none", is rejected.
+ *  - OTP hashes made with an old key still match, so an OTP sent just before a rotation can be used.
+ *  - The ETag of WriteJSONWithETag is a hash of the encoded response, so it changes whenever anything
+ *    in the response does, and a client that sends it back as If-None-Match gets 304 Not Modified.
+ *
+ *  @configuration
+ *  - config.Config.JWTSecret: Primary key passed to NewJWTManager for signing JWT tokens and hashing OTPs.
+ *  - config.Config.JWTOldSecrets, JWTExpiry, JWTIssuer: The old keys, token lifetime and issuer.
  *
  *  @authors
  *      - Aayush
@@ -41,58 +64,165 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"math/big"
 	"net/http"
-	"os"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 	"unicode"
 
-	"github.com/dgrijalva/jwt-go"
-	"math/rand"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Defaults used by NewJWTManager for a zero ttl or an empty issuer.
+const (
+	DefaultJWTExpiry = 24 * time.Hour
+	DefaultJWTIssuer = "dailyverse"
 )
 
-// JWT Secret Key from environment variables
-var jwtSecretKey = os.Getenv("JWT_SECRET_KEY")
+// JWTAudience is the audience of every token, the DailyVerse API.
+const JWTAudience = "dailyverse-api"
+
+// JWTManager signs and validates JWT tokens and hashes OTPs with the server secret.
+type JWTManager struct {
+	keys   [][]byte      // Primary key first, followed by the old keys still accepted.
+	ttl    time.Duration // Lifetime of the tokens it generates.
+	issuer string        // Issuer set on generated tokens and required on parsed ones.
+
+	Now func() time.Time // Clock used for issue and expiry times; replaceable in tests.
+}
+
+// NewJWTManager creates a JWTManager that signs with primaryKey, normally config.Config.JWTSecret,
+// and also accepts tokens signed with any of oldKeys. A ttl of 0 means DefaultJWTExpiry and an
+// empty issuer means DefaultJWTIssuer.
+func NewJWTManager(primaryKey string, oldKeys []string, ttl time.Duration, issuer string) *JWTManager {
+	if ttl <= 0 {
+		ttl = DefaultJWTExpiry
+	}
+	if issuer == "" {
+		issuer = DefaultJWTIssuer
+	}
+	keys := [][]byte{[]byte(primaryKey)}
+	for _, key := range oldKeys {
+		if key != "" && key != primaryKey {
+			keys = append(keys, []byte(key))
+		}
+	}
+	return &JWTManager{keys: keys, ttl: ttl, issuer: issuer, Now: time.Now}
+}
 
 // Claims defines the JWT token structure.
 type Claims struct {
-	Email string `json:"email"`
-	jwt.StandardClaims
+	Email        string `json:"email"`
+	TokenVersion int    `json:"tokenVersion"` // User's TokenVersion when the token was issued.
+	jwt.RegisteredClaims
 }
 
 // GenerateJWT generates a JWT token for a given email.
 // Parameters:
 //   - email: The email address to associate with the token.
+//   - tokenVersion: The user's current TokenVersion; the token is rejected once the version is bumped.
 //
 // Returns:
 //   - string: A signed JWT token.
 //   - error: Returns an error if token signing fails.
-func GenerateJWT(email string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+func (m *JWTManager) GenerateJWT(email string, tokenVersion int) (string, error) {
+	now := m.Now()
 	claims := &Claims{
-		Email: email,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
+		Email:        email,
+		TokenVersion: tokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Audience:  jwt.ClaimStrings{JWTAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.ttl)),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecretKey))
+	return token.SignedString(m.keys[0])
+}
+
+// ParseAndValidate validates a token signed by GenerateJWT with the primary key or an old key.
+// Parameters:
+//   - tokenString: The signed token.
+//
+// Returns:
+//   - *Claims: The claims of the token.
+//   - error: Returns an error if the signature is invalid, the token was not signed with HS256,
+//     has expired, was issued in the future, or has the wrong issuer or audience.
+func (m *JWTManager) ParseAndValidate(tokenString string) (*Claims, error) {
+	keys := make([]jwt.VerificationKey, len(m.keys))
+	for i, key := range m.keys {
+		keys[i] = key
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return jwt.VerificationKeySet{Keys: keys}, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithAudience(JWTAudience),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(m.Now),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("Invalid token")
+	}
+	return claims, nil
 }
 
-// HashPassword hashes a given password using SHA-256.
+// HashPassword hashes a given password using bcrypt.
 // Parameters:
 //   - password: The plain text password to hash.
 //
 // Returns:
-//   - string: The hashed password as a hexadecimal string.
-func HashPassword(password string) string {
-	hash := sha256.New()
-	hash.Write([]byte(password))
-	return hex.EncodeToString(hash.Sum(nil))
+//   - string: The bcrypt hash of the password.
+//   - error: Returns an error if hashing fails.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// legacyHashRegex matches the 64-character hexadecimal SHA-256 hashes used before bcrypt.
+var legacyHashRegex = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// IsLegacyPasswordHash reports whether a stored hash is a legacy SHA-256 hash.
+// Parameters:
+//   - hash: The stored password hash.
+//
+// Returns:
+//   - bool: True if the hash is a 64-character hex SHA-256 digest.
+func IsLegacyPasswordHash(hash string) bool {
+	return legacyHashRegex.MatchString(hash)
+}
+
+// CheckLegacyPasswordHash compares a plain password with a legacy SHA-256 hash.
+// Parameters:
+//   - password: The plain text password.
+//   - hash: The legacy SHA-256 hash to compare.
+//
+// Returns:
+//   - bool: True if the passwords match, false otherwise.
+func CheckLegacyPasswordHash(password, hash string) bool {
+	sum := sha256.Sum256([]byte(password))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(hash)) == 1
 }
 
 // IsValidPassword checks if a password meets complexity requirements.
@@ -127,26 +257,78 @@ func IsValidPassword(password string) bool {
 // Returns:
 //   - string: A 6-digit OTP as a string.
 func GenerateOTP() string {
-	rand.Seed(time.Now().UnixNano())
 	return randSeq(6)
 }
 
+// GenerateOTPOfLength generates a random OTP of the given number of digits.
+// Parameters:
+//   - length: The number of digits.
+//
+// Returns:
+//   - string: An OTP of length digits as a string.
+func GenerateOTPOfLength(length int) string {
+	return randSeq(length)
+}
+
 var letters = []rune("1234567890")
 
-// randSeq generates a random string of n digits.
+// randSeq generates a random string of n digits using crypto/rand.
 // Parameters:
 //   - n: The length of the random string.
 //
 // Returns:
 //   - string: A random string of digits.
 func randSeq(n int) string {
+	max := big.NewInt(int64(len(letters)))
 	b := make([]rune, n)
 	for i := range b {
-		b[i] = letters[rand.Intn(len(letters))]
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			// The system's secure random source is unavailable; an OTP must never fall back to a predictable one.
+			panic("utils: crypto/rand failed: " + err.Error())
+		}
+		b[i] = letters[idx.Int64()]
 	}
 	return string(b)
 }
 
+// HashOTP hashes an OTP with HMAC-SHA256 keyed by the primary key, so the raw OTP is never stored.
+// Parameters:
+//   - otp: The plain OTP.
+//
+// Returns:
+//   - string: The hex-encoded HMAC of the OTP.
+func (m *JWTManager) HashOTP(otp string) string {
+	return hashOTP(m.keys[0], otp)
+}
+
+// hashOTP returns the hex-encoded HMAC-SHA256 of otp keyed by key.
+func hashOTP(key []byte, otp string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(otp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckOTP compares a plain OTP with a stored OTP hash in constant time, trying the primary key
+// and then the old keys.
+// Parameters:
+//   - otp: The OTP entered by the user.
+//   - hash: The stored hash created by HashOTP.
+//
+// Returns:
+//   - bool: True if the OTP matches the hash, false otherwise or if no hash is stored.
+func (m *JWTManager) CheckOTP(otp, hash string) bool {
+	if otp == "" || hash == "" {
+		return false
+	}
+	for _, key := range m.keys {
+		if hmac.Equal([]byte(hashOTP(key, otp)), []byte(hash)) {
+			return true
+		}
+	}
+	return false
+}
+
 // WriteJSON writes a JSON response to the HTTP response writer.
 // Parameters:
 //   - w: The HTTP response writer.
@@ -156,6 +338,52 @@ func WriteJSON(w http.ResponseWriter, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
+// WriteJSONWithETag writes data as a JSON response with an ETag computed from its encoding. If the
+// request's If-None-Match lists that ETag, the response is 304 Not Modified without a body. HEAD
+// requests get the headers of the full response only.
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The request, for its method and If-None-Match header.
+//   - data: The data to encode as JSON.
+func WriteJSONWithETag(w http.ResponseWriter, r *http.Request, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		WriteJSONError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n') // As written by WriteJSON.
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	// The response depends on the user, and must be revalidated before a cached copy is used.
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagListed(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Write(body)
+}
+
+// etagListed reports whether the If-None-Match header value lists etag or is "*". The comparison is
+// weak, as If-None-Match requires, so W/ prefixes are ignored.
+func etagListed(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // WriteJSONError writes an error message as a JSON response with a specific status code.
 // Parameters:
 //   - w: The HTTP response writer.
@@ -169,6 +397,20 @@ func WriteJSONError(w http.ResponseWriter, message string, code int) {
 	})
 }
 
+// WriteJSONValidationError writes a 400 response with an error message per invalid field:
+// { "message": "Invalid input", "errors": { "title": "required" } }.
+// Parameters:
+//   - w: The HTTP response writer.
+//   - fieldErrors: The error message for each invalid field, keyed by the field's JSON name.
+func WriteJSONValidationError(w http.ResponseWriter, fieldErrors map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Invalid input",
+		"errors":  fieldErrors,
+	})
+}
+
 // CheckPasswordHash compares a plain password with a hashed password.
 // Parameters:
 //   - password: The plain text password.
@@ -191,3 +433,14 @@ func IsValidEmail(email string) bool {
 	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)*$`)
 	return emailRegex.MatchString(email)
 }
+
+// NormalizeEmail returns the form emails are stored and looked up in, so that an address
+// matches however it is typed.
+// Parameters:
+//   - email: The email address as entered.
+//
+// Returns:
+//   - string: The email without surrounding whitespace and in lowercase.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
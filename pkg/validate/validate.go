@@ -0,0 +1,164 @@
+/**
+ *  Validate Package checks user input for events and journal entries before it is stored,
+ *  so required fields are present and free text stays within sensible limits.
+ *
+ *  @file      validate.go
+ *  @package   validate
+ *  @purpose   Field-level validation of models received from clients.
+ *
+ *  @methods
+ *  - Event(event)           - Validates an event's title, description, times, postal number and coordinates.
+ *  - Journal(journal)       - Validates a journal entry's content.
+ *  - AsErrors(err)          - Extracts the field errors from an error returned by a validation or by the dates package.
+ *
+ *  @behaviors
+ *  - All invalid fields are reported at once as Errors, keyed by the field's JSON name,
+ *    e.g. {"title": "required"}, so handlers can return them to the client.
+ *  - Lengths are counted in characters, not bytes.
+ *  - Optional fields such as the start time and postal number are only checked when present.
+ *  - Coordinates are optional, but latitude and longitude must be given together.
+ *
+ *  @example
+ *  ```
+ *  if err := validate.Event(event); err != nil {
+ *      return err // validate.Errors{"title": "required"}
+ *  }
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"proh2052-group6/pkg/dates"
+	"proh2052-group6/pkg/models"
+)
+
+// Maximum field lengths in characters.
+const (
+	MaxEventTitleLength       = 200
+	MaxEventDescriptionLength = 5000
+	MaxJournalContentLength   = 20000
+)
+
+// Errors maps the JSON name of each invalid field to what is wrong with it.
+type Errors map[string]string
+
+// Error lists the invalid fields in alphabetical order.
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field + " " + e[field]
+	}
+	return "Invalid input: " + strings.Join(messages, ", ")
+}
+
+// AsErrors returns the field errors wrapped in err, if it is a validation error. An invalid date
+// from the dates package is returned as an error for its field.
+func AsErrors(err error) (Errors, bool) {
+	var fieldErrors Errors
+	if errors.As(err, &fieldErrors) {
+		return fieldErrors, true
+	}
+	if dateErr, ok := dates.AsError(err); ok {
+		return Errors{dateErr.Field: dateErr.Reason}, true
+	}
+	return nil, false
+}
+
+// Event validates the fields of an event entered by the user.
+func Event(event *models.Event) error {
+	e := Errors{}
+	e.required("title", event.Title)
+	e.maxLength("title", event.Title, MaxEventTitleLength)
+	e.maxLength("description", event.Description, MaxEventDescriptionLength)
+
+	startTime, startOK := e.timeOfDay("startTime", event.StartTime)
+	endTime, endOK := e.timeOfDay("endTime", event.EndTime)
+	if startOK && endOK && event.StartTime != "" && event.EndTime != "" && !endTime.After(startTime) {
+		e["endTime"] = "must be after startTime"
+	}
+
+	if event.PostalNumber != "" && strings.Trim(event.PostalNumber, "0123456789") != "" {
+		e["postalNumber"] = "must contain only digits"
+	}
+
+	switch {
+	case event.Latitude != nil && event.Longitude == nil:
+		e["longitude"] = "required with latitude"
+	case event.Latitude == nil && event.Longitude != nil:
+		e["latitude"] = "required with longitude"
+	}
+	e.degrees("latitude", event.Latitude, 90)
+	e.degrees("longitude", event.Longitude, 180)
+	return e.err()
+}
+
+// Journal validates the fields of a journal entry entered by the user.
+func Journal(journal *models.Journal) error {
+	e := Errors{}
+	e.required("content", journal.Content)
+	e.maxLength("content", journal.Content, MaxJournalContentLength)
+	return e.err()
+}
+
+// required records an error for field if value is empty or only whitespace.
+func (e Errors) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e[field] = "required"
+	}
+}
+
+// maxLength records an error for field if value is longer than max characters.
+func (e Errors) maxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		e[field] = fmt.Sprintf("must be at most %d characters", max)
+	}
+}
+
+// timeOfDay parses value as HH:MM, recording an error for field if it is present but malformed.
+// It reports whether value was absent or valid.
+func (e Errors) timeOfDay(field, value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, true
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		e[field] = "must be a time in HH:MM format"
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+// degrees records an error for field if value is present and outside [-max, max].
+func (e Errors) degrees(field string, value *float64, max float64) {
+	if value != nil && (*value < -max || *value > max) {
+		e[field] = fmt.Sprintf("must be between %g and %g", -max, max)
+	}
+}
+
+// err returns e as an error, or nil when no field is invalid.
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
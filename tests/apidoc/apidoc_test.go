@@ -0,0 +1,167 @@
+/**
+ *  OpenAPI Document Tests validate the document built by the apidoc package and check that it
+ *  describes every route registered by the server, so endpoints cannot be added undocumented.
+ *
+ *  @file       apidoc_test.go
+ *  @package    apidoc_test
+ *
+ *  @test_cases
+ *  - TestBuild_ValidDocument   - Tests that the served JSON loads and validates as an OpenAPI 3 document.
+ *  - TestBuild_Security        - Tests the bearer JWT scheme, public routes and the documented error bodies.
+ *  - TestBuild_CoversAllRoutes - Tests that every registered route is documented and every documented route is registered.
+ *
+ *  @dependencies
+ *  - server.NewAPIRouter, server.NewRootRouter: The routes the server registers.
+ *  - openapi3.Loader: Loads the document as a client would, resolving every reference.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package apidoc_test
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+
+	"proh2052-group6/internal/apidoc"
+	"proh2052-group6/internal/server"
+)
+
+// loadDocument builds the document and loads its JSON encoding, as Swagger UI would.
+func loadDocument(t *testing.T) *openapi3.T {
+	t.Helper()
+	spec, err := apidoc.JSON()
+	if err != nil {
+		t.Fatalf("Failed to build the OpenAPI document: %v", err)
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		t.Fatalf("Failed to load the OpenAPI document: %v", err)
+	}
+	return doc
+}
+
+func TestBuild_ValidDocument(t *testing.T) {
+	doc := loadDocument(t)
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("Expected a valid OpenAPI document, got %v", err)
+	}
+	if doc.OpenAPI != "3.0.3" || doc.Info.Title != apidoc.Title {
+		t.Errorf("Expected an OpenAPI 3.0.3 document titled %q, got %q titled %q", apidoc.Title, doc.OpenAPI, doc.Info.Title)
+	}
+	for _, name := range []string{"Event", "Journal", "UserSummary", "SignupRequest", "UserProfile"} {
+		if doc.Components.Schemas[name] == nil {
+			t.Errorf("Expected a %s schema", name)
+		}
+	}
+}
+
+func TestBuild_Security(t *testing.T) {
+	doc := loadDocument(t)
+
+	scheme := doc.Components.SecuritySchemes["bearerAuth"]
+	if scheme == nil || scheme.Value.Type != "http" || scheme.Value.Scheme != "bearer" || scheme.Value.BearerFormat != "JWT" {
+		t.Fatalf("Expected a bearer JWT security scheme, got %+v", scheme)
+	}
+	if len(doc.Security) != 1 {
+		t.Errorf("Expected routes to require the bearer scheme by default, got %+v", doc.Security)
+	}
+
+	login := doc.Paths["/api/login"].Post
+	if login.Security == nil || len(*login.Security) != 0 {
+		t.Errorf("Expected login to be public, got security %+v", login.Security)
+	}
+	events := doc.Paths["/api/events/all"].Get
+	if events.Security != nil {
+		t.Errorf("Expected listing events to require the bearer scheme, got %+v", events.Security)
+	}
+	unauthorized := events.Responses.Get(http.StatusUnauthorized)
+	if unauthorized == nil {
+		t.Fatalf("Expected a protected route to document 401")
+	}
+	errorSchema := unauthorized.Value.Content.Get("application/json").Schema
+	if errorSchema.Ref != "#/components/schemas/ErrorResponse" || errorSchema.Value.Properties["message"] == nil {
+		t.Errorf("Expected errors to be documented as ErrorResponse, got %+v", errorSchema)
+	}
+
+	invalid := doc.Paths["/api/events/create"].Post.Responses.Get(http.StatusBadRequest)
+	if invalid == nil || len(invalid.Value.Content.Get("application/json").Schema.Value.OneOf) != 2 {
+		t.Errorf("Expected creating an event to document both 400 bodies, got %+v", invalid)
+	}
+}
+
+// route is a path and method registered with a router.
+type route struct {
+	path, method string
+}
+
+// registeredRoutes returns every route of router that is restricted to methods.
+func registeredRoutes(t *testing.T, router *mux.Router) []route {
+	t.Helper()
+	var routes []route
+	err := router.Walk(func(r *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := r.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := r.GetMethods()
+		if err != nil {
+			return nil // Catch-all routes such as PathPrefix("/") have no methods.
+		}
+		for _, method := range methods {
+			routes = append(routes, route{path, method})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk the routes: %v", err)
+	}
+	return routes
+}
+
+func TestBuild_CoversAllRoutes(t *testing.T) {
+	doc := loadDocument(t)
+
+	// The routes are only walked, never served, so the handlers can be nil.
+	passThrough := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	limit := func(next http.Handler) http.Handler { return next }
+	m := server.Middleware{
+		JWTAuth: passThrough, WebSocketAuth: passThrough, AdminOnly: passThrough,
+		SignupLimit: limit, LoginLimit: limit, OTPLimit: limit, ExportLimit: limit, InviteLimit: limit,
+		Instrument: limit, JSONBody: limit, ImportBody: limit,
+	}
+	api := server.NewAPIRouter(server.Handlers{}, m, true, false)
+	root := server.NewRootRouter(server.Handlers{}, m, api)
+	routes := append(registeredRoutes(t, api), registeredRoutes(t, root)...)
+
+	registered := map[route]bool{}
+	for _, r := range routes {
+		registered[r] = true
+		pathItem := doc.Paths[r.path]
+		if pathItem == nil || pathItem.GetOperation(r.method) == nil {
+			t.Errorf("Route %s %s is not described in the OpenAPI document; add it to internal/apidoc/routes.go", r.method, r.path)
+		}
+	}
+
+	var documented []string
+	for path, pathItem := range doc.Paths {
+		for method := range pathItem.Operations() {
+			if !registered[route{path, method}] {
+				documented = append(documented, method+" "+path)
+			}
+		}
+	}
+	sort.Strings(documented)
+	for _, op := range documented {
+		t.Errorf("Operation %s is documented but not registered", op)
+	}
+}
@@ -0,0 +1,220 @@
+/**
+ *  Config Tests validate that Load reads the settings from the environment, applies defaults and
+ *  reports every missing or invalid variable in one error.
+ *
+ *  @file       config_test.go
+ *  @package    config_test
+ *
+ *  @test_cases
+ *  - TestLoad_Valid          - Tests the parsed values, the defaults of optional variables and the GOOGLE_CLOUD_PROJECT fallback.
+ *  - TestLoad_MissingAll     - Tests that all missing required variables are reported at once.
+ *  - TestLoad_MemoryBackend  - Tests that FIRESTORE_PROJECT_ID is only required when something is kept in Firestore.
+ *  - TestLoad_InvalidValues  - Tests that invalid SMTP_PORT, DIGEST_INTERVAL, MAX_BODY_SIZE, JWT_EXPIRY, RATE_LIMIT_*, OTP_*,
+ *                              STORAGE_BACKEND and ENCRYPTION_MASTER_KEY values are reported together, without the key itself.
+ *  - TestLoad_AllowedOrigins - Tests parsing the ALLOWED_ORIGINS list, its development fallback and rejected entries.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package config_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/config"
+)
+
+// requiredVars lists the variables Load refuses to start without.
+var requiredVars = []string{"JWT_SECRET_KEY", "NEWS_API_KEY", "SMTP_HOST", "SMTP_PORT", "EMAIL_USER", "EMAIL_PASS", "FIRESTORE_PROJECT_ID"}
+
+// setValidEnv sets every required variable to a valid value and clears the optional ones.
+func setValidEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("JWT_SECRET_KEY", "secret")
+	t.Setenv("NEWS_API_KEY", "news-key")
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_PORT", "587")
+	t.Setenv("EMAIL_USER", "noreply@example.com")
+	t.Setenv("EMAIL_PASS", "password")
+	t.Setenv("FIRESTORE_PROJECT_ID", "dailyverse-test")
+	for _, name := range []string{"PORT", "GCS_BUCKET", "DIGEST_INTERVAL", "ENABLE_ADMIN_ROUTES", "MAX_BODY_SIZE", "MAX_IMPORT_BODY_SIZE", "ALLOWED_ORIGINS",
+		"JWT_OLD_SECRET_KEYS", "JWT_EXPIRY", "JWT_ISSUER", "GOOGLE_CLOUD_PROJECT", "APP_URL",
+		"RATE_LIMIT_STORE", "RATE_LIMIT_FLUSH_INTERVAL", "ENCRYPTION_MASTER_KEY", "OTP_LENGTH", "OTP_TTL", "STORAGE_BACKEND", "LOG_LEVEL"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestLoad_Valid(t *testing.T) {
+	setValidEnv(t)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Port != config.DefaultPort || cfg.DigestInterval != 0 || cfg.EnableAdminRoutes || cfg.GCSBucket != "" ||
+		cfg.MaxBodySize != config.DefaultMaxBodySize || cfg.MaxImportBodySize != config.DefaultMaxImportBodySize ||
+		cfg.AppURL != config.DefaultAppURL || cfg.RateLimitStore != config.RateLimitStoreMemory ||
+		cfg.RateLimitFlushInterval != config.DefaultRateLimitFlushInterval || cfg.EncryptionMasterKey != nil ||
+		cfg.OTPLength != 0 || cfg.OTPTTL != 0 || cfg.StorageBackend != config.StorageBackendFirestore || cfg.Debug() {
+		t.Errorf("Expected the defaults of the optional settings, got %+v", cfg)
+	}
+	if cfg.JWTSecret != "secret" || cfg.NewsAPIKey != "news-key" || cfg.FirestoreProjectID != "dailyverse-test" {
+		t.Errorf("Expected the secrets from the environment, got %+v", cfg)
+	}
+	if cfg.JWTOldSecrets != nil || cfg.JWTExpiry != 0 || cfg.JWTIssuer != "" {
+		t.Errorf("Expected no JWT rotation keys and the default expiry and issuer, got %+v", cfg)
+	}
+	want := config.SMTPConfig{Host: "smtp.example.com", Port: 587, User: "noreply@example.com", Password: "password"}
+	if cfg.SMTP != want {
+		t.Errorf("Expected SMTP settings %+v, got %+v", want, cfg.SMTP)
+	}
+
+	t.Setenv("PORT", "9090")
+	t.Setenv("GCS_BUCKET", "pictures")
+	t.Setenv("DIGEST_INTERVAL", "30m")
+	t.Setenv("ENABLE_ADMIN_ROUTES", "true")
+	t.Setenv("MAX_BODY_SIZE", "2048")
+	t.Setenv("MAX_IMPORT_BODY_SIZE", "4096")
+	t.Setenv("JWT_OLD_SECRET_KEYS", "older, old,")
+	t.Setenv("JWT_EXPIRY", "12h")
+	t.Setenv("JWT_ISSUER", "dailyverse-staging")
+	t.Setenv("RATE_LIMIT_STORE", "firestore")
+	t.Setenv("RATE_LIMIT_FLUSH_INTERVAL", "1m")
+	t.Setenv("ENCRYPTION_MASTER_KEY", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	t.Setenv("OTP_LENGTH", "8")
+	t.Setenv("OTP_TTL", "10m")
+	t.Setenv("STORAGE_BACKEND", "memory")
+	t.Setenv("LOG_LEVEL", "debug")
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Port != "9090" || cfg.GCSBucket != "pictures" || cfg.DigestInterval != 30*time.Minute || !cfg.EnableAdminRoutes ||
+		cfg.MaxBodySize != 2048 || cfg.MaxImportBodySize != 4096 ||
+		cfg.RateLimitStore != config.RateLimitStoreFirestore || cfg.RateLimitFlushInterval != time.Minute ||
+		string(cfg.EncryptionMasterKey) != strings.Repeat("k", 32) || cfg.OTPLength != 8 || cfg.OTPTTL != 10*time.Minute ||
+		cfg.StorageBackend != config.StorageBackendMemory || !cfg.Debug() {
+		t.Errorf("Expected the optional settings from the environment, got %+v", cfg)
+	}
+	if fmt.Sprint(cfg.JWTOldSecrets) != "[older old]" || cfg.JWTExpiry != 12*time.Hour || cfg.JWTIssuer != "dailyverse-staging" {
+		t.Errorf("Expected the JWT settings from the environment, got %+v", cfg)
+	}
+
+	// GOOGLE_CLOUD_PROJECT names the Firestore project when FIRESTORE_PROJECT_ID is not set.
+	t.Setenv("FIRESTORE_PROJECT_ID", "")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "dailyverse-cloud")
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.FirestoreProjectID != "dailyverse-cloud" {
+		t.Errorf("Expected the project ID from GOOGLE_CLOUD_PROJECT, got %q", cfg.FirestoreProjectID)
+	}
+}
+
+func TestLoad_MissingAll(t *testing.T) {
+	setValidEnv(t)
+	for _, name := range requiredVars {
+		t.Setenv(name, "")
+	}
+
+	cfg, err := config.Load()
+	if err == nil {
+		t.Fatalf("Expected an error, got config %+v", cfg)
+	}
+	for _, name := range requiredVars {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected the error to name %s, got %q", name, err)
+		}
+	}
+}
+
+func TestLoad_MemoryBackend(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("FIRESTORE_PROJECT_ID", "")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	t.Setenv("STORAGE_BACKEND", "memory")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Expected the memory backend to need no Firestore project, got %v", err)
+	}
+	if cfg.UsesFirestore() {
+		t.Errorf("Expected nothing to be kept in Firestore, got %+v", cfg)
+	}
+
+	// Rate limit buckets kept in Firestore still need the project.
+	t.Setenv("RATE_LIMIT_STORE", "firestore")
+	if _, err := config.Load(); err == nil || !strings.Contains(err.Error(), "FIRESTORE_PROJECT_ID") {
+		t.Errorf("Expected FIRESTORE_PROJECT_ID to be required for the Firestore rate limit store, got %v", err)
+	}
+}
+
+func TestLoad_InvalidValues(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("SMTP_PORT", "smtp")
+	t.Setenv("DIGEST_INTERVAL", "-1h")
+	t.Setenv("EMAIL_PASS", "")
+	t.Setenv("MAX_BODY_SIZE", "1MB")
+	t.Setenv("JWT_EXPIRY", "forever")
+	t.Setenv("RATE_LIMIT_STORE", "redis")
+	t.Setenv("RATE_LIMIT_FLUSH_INTERVAL", "0s")
+	shortKey := base64.StdEncoding.EncodeToString([]byte("too-short"))
+	t.Setenv("ENCRYPTION_MASTER_KEY", shortKey)
+	t.Setenv("OTP_LENGTH", "3")
+	t.Setenv("OTP_TTL", "soon")
+	t.Setenv("STORAGE_BACKEND", "sqlite")
+	t.Setenv("LOG_LEVEL", "verbose")
+
+	_, err := config.Load()
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	for _, want := range []string{"EMAIL_PASS", `SMTP_PORT "smtp"`, `DIGEST_INTERVAL "-1h"`, `MAX_BODY_SIZE "1MB"`, `JWT_EXPIRY "forever"`,
+		`RATE_LIMIT_STORE "redis"`, `RATE_LIMIT_FLUSH_INTERVAL "0s"`, "ENCRYPTION_MASTER_KEY", `OTP_LENGTH "3"`, `OTP_TTL "soon"`,
+		`STORAGE_BACKEND "sqlite"`, `LOG_LEVEL "verbose"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %s, got %q", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), shortKey) {
+		t.Errorf("Expected the error not to contain the master key, got %q", err)
+	}
+}
+
+func TestLoad_AllowedOrigins(t *testing.T) {
+	setValidEnv(t)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if fmt.Sprint(cfg.AllowedOrigins) != fmt.Sprint(config.DefaultAllowedOrigins) {
+		t.Errorf("Expected the development origins without ALLOWED_ORIGINS, got %v", cfg.AllowedOrigins)
+	}
+
+	t.Setenv("ALLOWED_ORIGINS", " https://dailyverse.app/ ,https://www.dailyverse.app,, http://localhost:3000")
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []string{"https://dailyverse.app", "https://www.dailyverse.app", "http://localhost:3000"}
+	if fmt.Sprint(cfg.AllowedOrigins) != fmt.Sprint(want) {
+		t.Errorf("Expected origins %v, got %v", want, cfg.AllowedOrigins)
+	}
+
+	for _, origins := range []string{"*", "dailyverse.app", "https://dailyverse.app/app", "ftp://dailyverse.app", ","} {
+		t.Setenv("ALLOWED_ORIGINS", origins)
+		if _, err := config.Load(); err == nil || !strings.Contains(err.Error(), "ALLOWED_ORIGINS") {
+			t.Errorf("Expected ALLOWED_ORIGINS %q to be rejected, got %v", origins, err)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+/**
+ *  Dates Tests check the parsing of dates and date ranges, the limits on event and journal dates,
+ *  and which date it is in a time zone around midnight.
+ *
+ *  @file       dates_test.go
+ *  @package    dates_test
+ *
+ *  @test_cases
+ *  - TestParse             - Tests valid dates and the error for malformed or impossible ones.
+ *  - TestParseRange        - Tests optional bounds, malformed bounds and an inverted range.
+ *  - TestParseMonth        - Tests a month parses to its first day and malformed months are rejected.
+ *  - TestParseEventDate    - Tests the MaxEventYears window on both sides of today.
+ *  - TestParseJournalDate  - Tests journal dates up to and including today, but not after.
+ *  - TestToday_TimeZones   - Tests the date around midnight in Auckland, Los Angeles and EarliestZone.
+ *  - TestAsError           - Tests the error message and that validate.AsErrors reports the field.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package dates_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"proh2052-group6/pkg/dates"
+	"proh2052-group6/pkg/validate"
+)
+
+// day returns the date YYYY-MM-DD as midnight UTC.
+func day(t *testing.T, value string) time.Time {
+	t.Helper()
+	date, err := time.Parse(dates.Layout, value)
+	if err != nil {
+		t.Fatalf("Invalid test date %q: %v", value, err)
+	}
+	return date
+}
+
+// loadLocation loads an IANA time zone, failing the test if it is unknown.
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("Failed to load time zone %s: %v", name, err)
+	}
+	return location
+}
+
+// reason returns the field and reason of a date error, or empty strings if err is nil.
+func reason(t *testing.T, err error) (string, string) {
+	t.Helper()
+	if err == nil {
+		return "", ""
+	}
+	dateErr, ok := dates.AsError(err)
+	if !ok {
+		t.Fatalf("Expected a *dates.Error, got %T: %v", err, err)
+	}
+	return dateErr.Field, dateErr.Reason
+}
+
+func TestParse(t *testing.T) {
+	date, err := dates.Parse("date", "2024-02-29")
+	if err != nil || !date.Equal(day(t, "2024-02-29")) || date.Location() != time.UTC {
+		t.Errorf("Expected 2024-02-29 at midnight UTC, got %v, %v", date, err)
+	}
+
+	for _, value := range []string{"", "01/03/2024", "2024-1-5", "2023-02-29", "2024-13-01", "2024-05-01T10:00:00Z"} {
+		_, err := dates.Parse("date", value)
+		if field, why := reason(t, err); field != "date" || why != dates.ReasonFormat {
+			t.Errorf("%q: expected date %q, got %s %q", value, dates.ReasonFormat, field, why)
+		}
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	month, err := dates.ParseMonth("month", "2024-02")
+	if err != nil || !month.Equal(day(t, "2024-02-01")) {
+		t.Errorf("Expected 2024-02-01 at midnight UTC, got %v, %v", month, err)
+	}
+
+	for _, value := range []string{"", "2024-2", "2024-13", "2024-02-01", "02/2024"} {
+		_, err := dates.ParseMonth("month", value)
+		if field, why := reason(t, err); field != "month" || why != dates.ReasonMonth {
+			t.Errorf("%q: expected month %q, got %s %q", value, dates.ReasonMonth, field, why)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	from, to, err := dates.ParseRange("2024-03-01", "")
+	if err != nil || !from.Equal(day(t, "2024-03-01")) || !to.IsZero() {
+		t.Errorf("Expected from 2024-03-01 and no to bound, got %v, %v, %v", from, to, err)
+	}
+	if _, _, err := dates.ParseRange("2024-03-01", "2024-03-01"); err != nil {
+		t.Errorf("Expected a single-day range to be valid, got %v", err)
+	}
+	if _, _, err := dates.ParseRange("", ""); err != nil {
+		t.Errorf("Expected an open range to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		from, to, wantField, wantReason string
+	}{
+		{"03/01/2024", "", "from", dates.ReasonFormat},
+		{"2024-03-01", "tomorrow", "to", dates.ReasonFormat},
+		{"2024-04-01", "2024-03-01", "from", dates.ReasonAfterTo},
+	}
+	for _, tt := range tests {
+		_, _, err := dates.ParseRange(tt.from, tt.to)
+		if field, why := reason(t, err); field != tt.wantField || why != tt.wantReason {
+			t.Errorf("%q..%q: expected %s %q, got %s %q", tt.from, tt.to, tt.wantField, tt.wantReason, field, why)
+		}
+	}
+}
+
+func TestParseEventDate(t *testing.T) {
+	today := day(t, "2024-06-15")
+	tests := map[string]string{
+		"2014-06-15": "",
+		"2034-06-15": "",
+		"2024-06-15": "",
+		"2014-06-14": dates.ReasonEventYear,
+		"2034-06-16": dates.ReasonEventYear,
+		"2024-06-31": dates.ReasonFormat,
+	}
+	for value, want := range tests {
+		_, err := dates.ParseEventDate("date", value, today)
+		if _, why := reason(t, err); why != want {
+			t.Errorf("%s: expected %q, got %q", value, want, why)
+		}
+	}
+	if dates.ReasonEventYear != fmt.Sprintf("must be within %d years of today", dates.MaxEventYears) {
+		t.Errorf("Expected ReasonEventYear to name MaxEventYears, got %q", dates.ReasonEventYear)
+	}
+}
+
+func TestParseJournalDate(t *testing.T) {
+	today := day(t, "2024-06-15")
+	tests := map[string]string{
+		"2024-06-14": "",
+		"2024-06-15": "",
+		"1999-01-01": "",
+		"2024-06-16": dates.ReasonFuture,
+		"15.06.2024": dates.ReasonFormat,
+	}
+	for value, want := range tests {
+		date, err := dates.ParseJournalDate("date", value, today)
+		if _, why := reason(t, err); why != want {
+			t.Errorf("%s: expected %q, got %q", value, want, why)
+		}
+		if want == "" && date.Format(dates.Layout) != value {
+			t.Errorf("%s: expected the date back, got %v", value, date)
+		}
+	}
+}
+
+func TestToday_TimeZones(t *testing.T) {
+	auckland := loadLocation(t, "Pacific/Auckland")
+	losAngeles := loadLocation(t, "America/Los_Angeles")
+
+	tests := []struct {
+		name string
+		now  time.Time
+		loc  *time.Location
+		want string
+	}{
+		// 21:30 on 1 June in Auckland (NZST, UTC+12) is still 1 June in UTC.
+		{"Auckland evening", time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC), auckland, "2024-06-01"},
+		// 00:30 on 2 June in Auckland, while UTC is on 1 June.
+		{"Auckland after midnight", time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC), auckland, "2024-06-02"},
+		// 23:59 on 1 January in Auckland during daylight saving time (NZDT, UTC+13).
+		{"Auckland summer", time.Date(2024, 1, 1, 10, 59, 0, 0, time.UTC), auckland, "2024-01-01"},
+		{"Auckland summer midnight", time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), auckland, "2024-01-02"},
+		// 20:00 on 1 June in Los Angeles (PDT, UTC-7), while UTC is already on 2 June.
+		{"Los Angeles evening", time.Date(2024, 6, 2, 3, 0, 0, 0, time.UTC), losAngeles, "2024-06-01"},
+		{"UTC", time.Date(2024, 6, 1, 23, 59, 0, 0, time.UTC), time.UTC, "2024-06-01"},
+		// UTC+14 reaches 2 June at 10:00 UTC on 1 June.
+		{"Earliest zone before midnight", time.Date(2024, 6, 1, 9, 59, 0, 0, time.UTC), dates.EarliestZone, "2024-06-01"},
+		{"Earliest zone after midnight", time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), dates.EarliestZone, "2024-06-02"},
+	}
+	for _, tt := range tests {
+		got := dates.Today(tt.now, tt.loc)
+		if got.Format(dates.Layout) != tt.want || got.Location() != time.UTC || !got.Equal(day(t, tt.want)) {
+			t.Errorf("%s: expected %s at midnight UTC, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestAsError(t *testing.T) {
+	_, err := dates.ParseJournalDate("date", "2024-06-16", day(t, "2024-06-15"))
+	if err == nil || err.Error() != "Invalid input: date must not be in the future" {
+		t.Errorf("Unexpected message: %v", err)
+	}
+
+	wrapped := fmt.Errorf("Failed to save journal: %w", err)
+	fieldErrors, ok := validate.AsErrors(wrapped)
+	if !ok || !reflect.DeepEqual(map[string]string(fieldErrors), map[string]string{"date": dates.ReasonFuture}) {
+		t.Errorf("Expected validate.AsErrors to report the date field, got %v, %v", fieldErrors, ok)
+	}
+
+	if _, ok := dates.AsError(fmt.Errorf("other")); ok {
+		t.Errorf("Expected AsError to ignore other errors")
+	}
+}
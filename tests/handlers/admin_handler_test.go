@@ -0,0 +1,201 @@
+/**
+ *  AdminHandler Tests validate the moderation endpoints behind the JWT and AdminOnly middleware:
+ *  that only administrators reach them and that a disabled account is locked out.
+ *
+ *  @file       admin_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestAdminHandler_RequiresAdmin      - Tests 401 without a token, 403 for a regular user and 200 for an admin.
+ *  - TestAdminHandler_DisableUser        - Tests that a disabled user cannot log in or use their token until re-enabled.
+ *  - TestAdminHandler_DisableUser_Errors - Tests the 400 for the admin's own account and the 404 for an unknown one.
+ *  - TestAdminHandler_VerifyUser         - Tests manual verification and the 409 for a verified account.
+ *  - TestAdminHandler_NormalizeEmails    - Tests the migration report and logging in to a migrated account in any casing.
+ *
+ *  @dependencies
+ *  - mocks.NewMockUserRepository: Holds the admin and the regular user.
+ *  - mocks.NewMockAuditRepository: Stores the audit log in memory.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+	"proh2052-group6/tests/mocks"
+)
+
+// adminTestServer wires the admin endpoints and login like the router does.
+type adminTestServer struct {
+	userRepo    *mocks.MockUserRepository
+	adminOnly   func(http.HandlerFunc) http.HandlerFunc
+	jwt         func(http.HandlerFunc) http.HandlerFunc
+	admin       *handlers.AdminHandler
+	userHandler *handlers.UserHandler
+}
+
+func newAdminTestServer(t *testing.T) *adminTestServer {
+	t.Helper()
+	adminPassword, _ := utils.HashPassword("Password123!")
+	userPassword, _ := utils.HashPassword("Password123!")
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"admin@example.com": {Email: "admin@example.com", Username: "admin", Password: adminPassword, Role: models.RoleAdmin, IsVerified: true},
+		"user@example.com":  {Email: "user@example.com", Username: "user", Password: userPassword, Role: models.RoleUser, IsVerified: true},
+	})
+	auditService := services.NewAuditService(mocks.NewMockAuditRepository())
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+	return &adminTestServer{
+		userRepo:    userRepo,
+		adminOnly:   middleware.NewAdminOnlyMiddleware(userRepo),
+		jwt:         middleware.NewJwtAuthMiddleware(userRepo, testJWT),
+		admin:       handlers.NewAdminHandler(services.NewAdminService(userRepo, auditService)),
+		userHandler: handlers.NewUserHandler(userService),
+	}
+}
+
+// serve sends a request with the token of email, or none if email is empty, to handler behind the middleware.
+func (s *adminTestServer) serve(t *testing.T, handler http.HandlerFunc, method, target, body, email string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	if email != "" {
+		token, err := testJWT.GenerateJWT(email, 0)
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	s.jwt(s.adminOnly(handler)).ServeHTTP(rr, req)
+	return rr
+}
+
+// login posts the regular user's credentials to the login handler.
+func (s *adminTestServer) login() *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/login", bytes.NewBufferString(`{"email":"user@example.com","password":"Password123!"}`))
+	rr := httptest.NewRecorder()
+	s.userHandler.Login(rr, req)
+	return rr
+}
+
+func TestAdminHandler_RequiresAdmin(t *testing.T) {
+	s := newAdminTestServer(t)
+
+	if rr := s.serve(t, s.admin.SearchUsers, "GET", "/api/admin/users", "", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a token, got %d", rr.Code)
+	}
+	endpoints := map[string]http.HandlerFunc{
+		"SearchUsers":     s.admin.SearchUsers,
+		"VerifyUser":      s.admin.VerifyUser,
+		"DisableUser":     s.admin.DisableUser,
+		"NormalizeEmails": s.admin.NormalizeEmails,
+	}
+	for name, handler := range endpoints {
+		rr := s.serve(t, handler, "POST", "/api/admin/users", `{"email":"admin@example.com"}`, "user@example.com")
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("%s: expected status 403 for a regular user, got %d", name, rr.Code)
+		}
+	}
+
+	rr := s.serve(t, s.admin.SearchUsers, "GET", "/api/admin/users?query=user@example.com", "", "admin@example.com")
+	if rr.Code != http.StatusOK || !bytes.Contains(rr.Body.Bytes(), []byte(`"email":"user@example.com"`)) {
+		t.Errorf("Expected status 200 with the user, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAdminHandler_DisableUser(t *testing.T) {
+	s := newAdminTestServer(t)
+	me := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	rr := s.serve(t, s.admin.DisableUser, "POST", "/api/admin/users/disable", `{"email":"user@example.com"}`, "admin@example.com")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := s.login(); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 logging in to a disabled account, got %d: %s", rr.Code, rr.Body.String())
+	}
+	token, _ := testJWT.GenerateJWT("user@example.com", 0)
+	req := httptest.NewRequest("GET", "/api/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr = httptest.NewRecorder()
+	s.jwt(me).ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for the token of a disabled account, got %d", rr.Code)
+	}
+
+	rr = s.serve(t, s.admin.DisableUser, "POST", "/api/admin/users/disable", `{"email":"user@example.com","disabled":false}`, "admin@example.com")
+	if rr.Code != http.StatusOK || s.userRepo.Users["user@example.com"].Disabled {
+		t.Fatalf("Expected the account to be re-enabled, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := s.login(); rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200 logging in to a re-enabled account, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAdminHandler_DisableUser_Errors(t *testing.T) {
+	s := newAdminTestServer(t)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"OwnAccount", `{"email":"admin@example.com"}`, http.StatusBadRequest},
+		{"UnknownAccount", `{"email":"nobody@example.com"}`, http.StatusNotFound},
+		{"MissingEmail", `{}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := s.serve(t, s.admin.DisableUser, "POST", "/api/admin/users/disable", tt.body, "admin@example.com")
+		if rr.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.code, rr.Code, rr.Body.String())
+		}
+	}
+	if s.userRepo.Users["admin@example.com"].Disabled {
+		t.Error("Expected the admin's own account to stay enabled")
+	}
+}
+
+func TestAdminHandler_VerifyUser(t *testing.T) {
+	s := newAdminTestServer(t)
+	s.userRepo.Users["user@example.com"].IsVerified = false
+
+	rr := s.serve(t, s.admin.VerifyUser, "POST", "/api/admin/users/verify", `{"email":"user@example.com"}`, "admin@example.com")
+	if rr.Code != http.StatusOK || !s.userRepo.Users["user@example.com"].IsVerified {
+		t.Fatalf("Expected the account to be verified, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = s.serve(t, s.admin.VerifyUser, "POST", "/api/admin/users/verify", `{"email":"user@example.com"}`, "admin@example.com")
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a verified account, got %d", rr.Code)
+	}
+}
+
+func TestAdminHandler_NormalizeEmails(t *testing.T) {
+	s := newAdminTestServer(t)
+	password, _ := utils.HashPassword("Password123!")
+	s.userRepo.Users["Jane@Example.com"] = &models.User{Email: "Jane@Example.com", Username: "jane", Password: password, IsVerified: true}
+
+	rr := s.serve(t, s.admin.NormalizeEmails, "POST", "/api/admin/users/normalize-emails", "", "admin@example.com")
+	if rr.Code != http.StatusOK || rr.Body.String() != `{"migrated":["Jane@Example.com"],"conflicts":[],"failed":[]}`+"\n" {
+		t.Fatalf("Expected jane to be migrated, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	req := httptest.NewRequest("POST", "/api/login", bytes.NewBufferString(`{"email":"JANE@example.com","password":"Password123!"}`))
+	rr = httptest.NewRecorder()
+	s.userHandler.Login(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected jane to log in after the migration, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
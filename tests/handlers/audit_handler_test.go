@@ -0,0 +1,126 @@
+/**
+ *  AuditHandler Tests validate the security log endpoint and that the handlers of audited actions
+ *  pass the client's IP and User-Agent on to the audit log.
+ *
+ *  @file       audit_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestAuditHandler_GetSecurityLog      - Tests that only the user's own entries are returned, newest first.
+ *  - TestAuditHandler_DatabaseUnavailable - Tests the 503 when the audit log cannot be read.
+ *  - TestUserHandler_Login_AuditsClient   - Tests that a login is recorded with the forwarded IP and User-Agent.
+ *
+ *  @dependencies
+ *  - mocks.NewMockAuditRepository: Stores the audit log in memory.
+ *  - mocks.NewMockUserRepository: Holds the user logging in.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+	"proh2052-group6/tests/mocks"
+)
+
+// getSecurityLog requests /api/me/security-log as userEmail.
+func getSecurityLog(auditRepo *mocks.MockAuditRepository, userEmail string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/me/security-log", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+	rr := httptest.NewRecorder()
+	handlers.NewAuditHandler(services.NewAuditService(auditRepo)).GetSecurityLog(rr, req)
+	return rr
+}
+
+func TestAuditHandler_GetSecurityLog(t *testing.T) {
+	auditRepo := mocks.NewMockAuditRepository()
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	auditRepo.Entries["alice@example.com"] = []models.AuditEntry{
+		{Email: "alice@example.com", Action: services.AuditLoginSucceeded, IP: "203.0.113.7", UserAgent: "Firefox/124.0", Timestamp: start},
+		{Email: "alice@example.com", Action: services.AuditPasswordChanged, IP: "203.0.113.7", UserAgent: "Firefox/124.0", Timestamp: start.Add(time.Hour)},
+	}
+	auditRepo.Entries["bob@example.com"] = []models.AuditEntry{{Email: "bob@example.com", Action: services.AuditLoginFailed, Timestamp: start}}
+
+	rr := getSecurityLog(auditRepo, "alice@example.com")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var entries []map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&entries); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(entries) != 2 || entries[0]["action"] != services.AuditPasswordChanged || entries[1]["action"] != services.AuditLoginSucceeded {
+		t.Fatalf("Expected alice's 2 entries newest first, got %v", entries)
+	}
+	if entries[0]["ip"] != "203.0.113.7" || entries[0]["userAgent"] != "Firefox/124.0" || entries[0]["timestamp"] == nil {
+		t.Errorf("Expected the client and time of each entry, got %v", entries[0])
+	}
+	if _, ok := entries[0]["email"]; ok {
+		t.Errorf("Expected the email to be left out, got %v", entries[0])
+	}
+
+	// A user without entries gets an empty list.
+	rr = getSecurityLog(auditRepo, "carol@example.com")
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+		t.Errorf("Expected an empty list, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAuditHandler_DatabaseUnavailable(t *testing.T) {
+	auditRepo := mocks.NewMockAuditRepository()
+	auditRepo.Err = repositories.ErrUnavailable
+
+	rr := getSecurityLog(auditRepo, "alice@example.com")
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
+
+func TestUserHandler_Login_AuditsClient(t *testing.T) {
+	hashedPassword, err := utils.HashPassword("Password123!")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "alice", Password: hashedPassword, IsVerified: true},
+	})
+	auditRepo := mocks.NewMockAuditRepository()
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+	userService.(*services.UserService).Audit = services.NewAuditService(auditRepo)
+	handler := handlers.NewUserHandler(userService)
+
+	req := httptest.NewRequest("POST", "/api/login", strings.NewReader(`{"email": "alice@example.com", "password": "Password123!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	req.Header.Set("User-Agent", "Firefox/124.0")
+	rr := httptest.NewRecorder()
+	handler.Login(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	entries := auditRepo.Entries["alice@example.com"]
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %d", len(entries))
+	}
+	if entries[0].Action != services.AuditLoginSucceeded || entries[0].IP != "203.0.113.7" || entries[0].UserAgent != "Firefox/124.0" {
+		t.Errorf("Expected a login_succeeded entry from 203.0.113.7 with Firefox, got %+v", entries[0])
+	}
+}
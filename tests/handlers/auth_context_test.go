@@ -0,0 +1,269 @@
+/**
+ *  Auth Context Tests validate that every protected handler rejects requests whose context
+ *  carries no authenticated user email, and that the JWT middleware rejects requests without
+ *  a valid token or with a revoked one. Both must answer with a JSON 401 instead of panicking.
+ *
+ *  @file       auth_context_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestProtectedHandlers_MissingUserEmail    - Tests that each protected handler returns a JSON 401.
+ *  - TestJwtAuthMiddleware_MissingToken        - Tests that the middleware returns a JSON 401 without a token.
+ *  - TestJwtAuthMiddleware_PasswordReset       - Tests that tokens issued before a password reset are rejected.
+ *  - TestJwtAuthMiddleware_DatabaseUnavailable - Tests that a failed user lookup is a 503, not an invalid token.
+ *  - TestUserEmailFromContext                  - Tests the context helpers round-trip the email.
+ *
+ *  @dependencies
+ *  - middleware.NewJwtAuthMiddleware, middleware.WithUserEmail, middleware.UserEmailFromContext
+ *  - mocks: Mock services injected into the handlers.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// assertJSONUnauthorized checks that the recorder holds a 401 with a JSON message body.
+func assertJSONUnauthorized(t *testing.T, name string, rr *httptest.ResponseRecorder) {
+	t.Helper()
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("%s: expected status %d, got %d", name, http.StatusUnauthorized, rr.Code)
+		return
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("%s: expected JSON content type, got %q", name, contentType)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil || body["message"] == "" {
+		t.Errorf("%s: expected JSON error message, got %q (err: %v)", name, rr.Body.String(), err)
+	}
+}
+
+func TestProtectedHandlers_MissingUserEmail(t *testing.T) {
+	eventHandler := handlers.NewEventHandler(mocks.NewMockEventService())
+	friendHandler := handlers.NewFriendHandler(&mocks.MockFriendService{})
+	journalHandler := handlers.NewJournalHandler(mocks.NewMockJournalService())
+	promptHandler := handlers.NewPromptHandler(nil)
+	newsHandler := handlers.NewNewsHandler(nil)
+	holidayHandler := handlers.NewHolidayHandler(nil, nil)
+	profileHandler := handlers.NewProfileHandler(mocks.NewMockProfileService())
+	timetableHandler := handlers.NewTimetableHandler(nil)
+	userHandler := handlers.NewUserHandler(&mocks.MockUserService{})
+	hub := services.NewNotificationHub()
+	notificationHandler := handlers.NewNotificationHandler(services.NewNotificationService(mocks.NewMockNotificationRepository(), hub), hub, nil)
+	exportHandler := handlers.NewExportHandler(nil)
+	digestHandler := handlers.NewDigestHandler(nil)
+	statsHandler := handlers.NewStatsHandler(nil)
+	adminHandler := handlers.NewAdminHandler(nil)
+
+	protected := map[string]http.HandlerFunc{
+		"CreateEvent":              eventHandler.CreateEvent,
+		"GetEvent":                 eventHandler.GetEvent,
+		"UpdateEvent":              eventHandler.UpdateEvent,
+		"PatchEvent":               eventHandler.PatchEvent,
+		"DeleteEvent":              eventHandler.DeleteEvent,
+		"GetAllEvents":             eventHandler.GetAllEvents,
+		"InviteToEvent":            eventHandler.InviteToEvent,
+		"RespondToInvitation":      eventHandler.RespondToInvitation,
+		"GetInvitations":           eventHandler.GetInvitations,
+		"GetEventTags":             eventHandler.GetEventTags,
+		"SearchEvents":             eventHandler.SearchEvents,
+		"GetEventMonth":            eventHandler.GetEventMonth,
+		"ShareEvent":               eventHandler.ShareEvent,
+		"RevokeEventShare":         eventHandler.RevokeEventShare,
+		"GetFriendEvents":          eventHandler.GetFriendEvents,
+		"GetFriendsEventFeed":      eventHandler.GetFriendsEventFeed,
+		"DuplicateEvent":           eventHandler.DuplicateEvent,
+		"SendFriendRequest":        friendHandler.SendFriendRequest,
+		"AcceptFriendRequest":      friendHandler.AcceptFriendRequest,
+		"GetFriendsList":           friendHandler.GetFriendsList,
+		"RemoveFriend":             friendHandler.RemoveFriend,
+		"GetPendingFriendRequests": friendHandler.GetPendingFriendRequests,
+		"DeclineFriendRequest":     friendHandler.DeclineFriendRequest,
+		"CancelFriendRequest":      friendHandler.CancelFriendRequest,
+		"BlockUser":                friendHandler.BlockUser,
+		"UnblockUser":              friendHandler.UnblockUser,
+		"GetBlockedUsers":          friendHandler.GetBlockedUsers,
+		"GetFriendSuggestions":     friendHandler.GetFriendSuggestions,
+		"GetMutualFriends":         friendHandler.GetMutualFriends,
+		"InviteFriend":             friendHandler.InviteFriend,
+		"GetRelationshipStatuses":  friendHandler.GetRelationshipStatuses,
+		"CreateJournal":            journalHandler.CreateJournal,
+		"GetJournal":               journalHandler.GetJournal,
+		"UpdateJournal":            journalHandler.UpdateJournal,
+		"DeleteJournal":            journalHandler.DeleteJournal,
+		"GetJournalTrash":          journalHandler.GetJournalTrash,
+		"RestoreJournal":           journalHandler.RestoreJournal,
+		"GetAllJournals":           journalHandler.GetAllJournals,
+		"SearchJournals":           journalHandler.SearchJournals,
+		"ExportJournals":           journalHandler.ExportJournals,
+		"ImportJournals":           journalHandler.ImportJournals,
+		"GetJournalStats":          journalHandler.GetJournalStats,
+		"GetPrompt":                promptHandler.GetPrompt,
+		"SkipPrompt":               promptHandler.SkipPrompt,
+		"FetchNews":                newsHandler.FetchNews,
+		"GetHolidays":              holidayHandler.GetHolidays,
+		"GetProfile":               profileHandler.GetProfile,
+		"UpdateProfile":            profileHandler.UpdateProfile,
+		"ChangeEmail":              profileHandler.ChangeEmail,
+		"ConfirmEmail":             profileHandler.ConfirmEmail,
+		"UploadAvatar":             profileHandler.UploadAvatar,
+		"DeleteAvatar":             profileHandler.DeleteAvatar,
+		"ImportTimetable":          timetableHandler.ImportTimetable,
+		"ExportTimetable":          timetableHandler.ExportTimetable,
+		"CreateFeedToken":          timetableHandler.CreateFeedToken,
+		"GetFeedToken":             timetableHandler.GetFeedToken,
+		"RevokeFeedToken":          timetableHandler.RevokeFeedToken,
+		"GetUserInfo":              userHandler.GetUserInfo,
+		"SearchUsersByUsername":    userHandler.SearchUsersByUsername,
+		"ServeWS":                  notificationHandler.ServeWS,
+		"ListNotifications":        notificationHandler.ListNotifications,
+		"MarkNotificationsRead":    notificationHandler.MarkRead,
+		"ExportData":               exportHandler.ExportData,
+		"RunDigests":               digestHandler.RunDigests,
+		"GetUserStats":             statsHandler.GetUserStats,
+		"AdminSearchUsers":         adminHandler.SearchUsers,
+		"AdminVerifyUser":          adminHandler.VerifyUser,
+		"AdminDisableUser":         adminHandler.DisableUser,
+		"AdminNormalizeEmails":     adminHandler.NormalizeEmails,
+	}
+
+	for name, handler := range protected {
+		req, _ := http.NewRequest("GET", "/api/protected", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assertJSONUnauthorized(t, name, rr)
+	}
+}
+
+func TestJwtAuthMiddleware_MissingToken(t *testing.T) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+	handler := middleware.NewJwtAuthMiddleware(mocks.NewMockUserRepository(map[string]*models.User{}), testJWT)(next)
+
+	for _, header := range []string{"", "Bearer", "Bearer not-a-token"} {
+		req, _ := http.NewRequest("GET", "/api/protected", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assertJSONUnauthorized(t, "Authorization "+header, rr)
+	}
+	if called {
+		t.Errorf("Expected next handler not to be called without a valid token")
+	}
+}
+
+func TestJwtAuthMiddleware_PasswordReset(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user@example.com": {Email: "user@example.com", Username: "user", IsVerified: true, OTP: testJWT.HashOTP("123456"), OTPExpiresAt: time.Now().Add(5 * time.Minute)},
+	})
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+
+	var gotEmail string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEmail, _ = middleware.UserEmailFromContext(r.Context())
+	})
+	handler := middleware.NewJwtAuthMiddleware(userRepo, testJWT)(next)
+	serve := func(token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	oldToken, err := testJWT.GenerateJWT("user@example.com", 0)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if rr := serve(oldToken); rr.Code != http.StatusOK || gotEmail != "user@example.com" {
+		t.Fatalf("Expected the token to be accepted before the reset, got %d", rr.Code)
+	}
+
+	if err := userService.ResetPassword(context.Background(), "user@example.com", "123456", "NewPassword123!"); err != nil {
+		t.Fatalf("Failed to reset password: %v", err)
+	}
+
+	gotEmail = ""
+	assertJSONUnauthorized(t, "token issued before the reset", serve(oldToken))
+	if gotEmail != "" {
+		t.Errorf("Expected next handler not to be called with a revoked token")
+	}
+
+	newToken, err := testJWT.GenerateJWT("user@example.com", userRepo.Users["user@example.com"].TokenVersion)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if rr := serve(newToken); rr.Code != http.StatusOK || gotEmail != "user@example.com" {
+		t.Errorf("Expected a token issued after the reset to be accepted, got %d", rr.Code)
+	}
+
+	// Tokens of users that no longer exist are rejected too.
+	goneToken, _ := testJWT.GenerateJWT("gone@example.com", 0)
+	assertJSONUnauthorized(t, "token of a deleted user", serve(goneToken))
+}
+
+func TestJwtAuthMiddleware_DatabaseUnavailable(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user@example.com": {Email: "user@example.com", Username: "user", IsVerified: true},
+	})
+	userRepo.Err = fmt.Errorf("Failed to get user: %w", repositories.ErrUnavailable)
+	reached := false
+	handler := middleware.NewJwtAuthMiddleware(userRepo, testJWT)(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	})
+
+	token, _ := testJWT.GenerateJWT("user@example.com", 0)
+	req := httptest.NewRequest("GET", "/api/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil || body["message"] != "Service unavailable" {
+		t.Errorf("Expected a JSON 'Service unavailable' message, got %q (err: %v)", rr.Body.String(), err)
+	}
+	if reached {
+		t.Errorf("Expected next handler not to be called without the user")
+	}
+}
+
+func TestUserEmailFromContext(t *testing.T) {
+	if _, ok := middleware.UserEmailFromContext(context.Background()); ok {
+		t.Errorf("Expected no user email in an empty context")
+	}
+	// A plain string key must not be mistaken for the typed key.
+	if _, ok := middleware.UserEmailFromContext(context.WithValue(context.Background(), "userEmail", "user@example.com")); ok {
+		t.Errorf("Expected untyped context key to be ignored")
+	}
+
+	email, ok := middleware.UserEmailFromContext(middleware.WithUserEmail(context.Background(), "user@example.com"))
+	if !ok || email != "user@example.com" {
+		t.Errorf("Expected user@example.com, got %q (ok: %v)", email, ok)
+	}
+}
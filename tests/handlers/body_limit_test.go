@@ -0,0 +1,213 @@
+/**
+ *  Body Limit Tests validate the JSON body middleware created by NewJSONBody and the strict decoding
+ *  of request bodies by the handlers: the 415 for non-JSON bodies, the 413 for oversized bodies,
+ *  whether or not their size is known up front, and the 400 naming an unknown field.
+ *
+ *  @file       body_limit_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestJSONBody_ContentType       - Tests which requests need a JSON Content-Type.
+ *  - TestJSONBody_Oversized         - Tests the 413 for bodies over the limit, with and without a Content-Length.
+ *  - TestDecodeJSON_UnknownField    - Tests that a misspelled field of a request body is rejected.
+ *  - TestDecodeJSON_ServerFields    - Tests that fields set by the server are rejected in a signup or profile update, and
+ *    ignored in an event or journal sent back as it was received.
+ *
+ *  @dependencies
+ *  - middleware.NewJSONBody: The middleware under test.
+ *  - mocks.NewMockUserRepository, mocks.NewMockEventService, mocks.NewMockJournalService,
+ *    mocks.NewMockProfileService: Back the handlers the bodies are decoded by.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// errorMessage returns the message of a JSON error response.
+func errorMessage(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body map[string]string
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("Expected a JSON error, got %q (err: %v)", rr.Body.String(), err)
+	}
+	return body["message"]
+}
+
+// newSignupHandler returns the signup handler behind a JSON body limit of maxBytes.
+func newSignupHandler(maxBytes int64) http.Handler {
+	userService := services.NewUserService(mocks.NewMockUserRepository(map[string]*models.User{}), &mocks.MockEmailService{}, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+	return middleware.NewJSONBody(maxBytes)(http.HandlerFunc(handlers.NewUserHandler(userService).Signup))
+}
+
+func TestJSONBody_ContentType(t *testing.T) {
+	handler := middleware.NewJSONBody(1024)(okHandler)
+
+	tests := []struct {
+		name, method, contentType, body string
+		want                            int
+	}{
+		{"json", "POST", "application/json", "{}", http.StatusOK},
+		{"json with charset", "PUT", "application/json; charset=utf-8", "{}", http.StatusOK},
+		{"plain text", "POST", "text/plain", "{}", http.StatusUnsupportedMediaType},
+		{"form", "PUT", "application/x-www-form-urlencoded", "a=b", http.StatusUnsupportedMediaType},
+		{"missing", "POST", "", "{}", http.StatusUnsupportedMediaType},
+		{"empty body", "POST", "", "", http.StatusOK},
+		{"get", "GET", "text/plain", "ignored", http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/api/journal/save", strings.NewReader(test.body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != test.want {
+				t.Fatalf("Expected status %d, got %d", test.want, rr.Code)
+			}
+			if test.want == http.StatusUnsupportedMediaType && errorMessage(t, rr) != "Content-Type must be application/json" {
+				t.Errorf("Expected the Content-Type error, got %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestJSONBody_Oversized(t *testing.T) {
+	body := `{"email": "carol@example.com", "username": "carol", "password": "Password123!", "country": "Norway", "city": "` +
+		strings.Repeat("Oslo", 100) + `"}`
+	handler := newSignupHandler(128)
+
+	// A Content-Length over the limit is rejected before the handler runs.
+	req := httptest.NewRequest("POST", "/api/signup", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge || errorMessage(t, rr) != middleware.ErrRequestBodyTooLarge.Error() {
+		t.Fatalf("Expected a 413 for a large Content-Length, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// A chunked body has no Content-Length, so the handler stops reading at the limit.
+	req = httptest.NewRequest("POST", "/api/signup", io.MultiReader(strings.NewReader(body)))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge || errorMessage(t, rr) != middleware.ErrRequestBodyTooLarge.Error() {
+		t.Fatalf("Expected a 413 for a large chunked body, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// The same body is accepted under a larger limit.
+	req = httptest.NewRequest("POST", "/api/signup", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	newSignupHandler(1024).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected the body to fit a larger limit, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestDecodeJSON_UnknownField(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/signup", strings.NewReader(
+		`{"email": "carol@example.com", "usrname": "carol", "password": "Password123!", "country": "Norway", "city": "Oslo"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	newSignupHandler(1024).ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if message := errorMessage(t, rr); message != `Unknown field "usrname"` {
+		t.Errorf("Expected the unknown field to be named, got %q", message)
+	}
+
+	// Events and journals are decoded as strictly.
+	req = jsonRequest("POST", "/api/events/create", `{"title": "Lunch", "date": "2024-03-01", "colour": "blue"}`)
+	rr = httptest.NewRecorder()
+	handlers.NewEventHandler(mocks.NewMockEventService()).CreateEvent(rr, req)
+	if rr.Code != http.StatusBadRequest || errorMessage(t, rr) != `Unknown field "colour"` {
+		t.Errorf("Expected the unknown event field to be rejected, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+// jsonRequest returns a JSON request with body, sent by alice@example.com.
+func jsonRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req.WithContext(middleware.WithUserEmail(req.Context(), "alice@example.com"))
+}
+
+func TestDecodeJSON_ServerFields(t *testing.T) {
+	// A signup cannot verify the account or set the lowercase username used for lookups.
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{})
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+	for _, field := range []string{`"isVerified": true`, `"usernameLower": "admin"`, `"otp": "123456"`} {
+		req := jsonRequest("POST", "/api/signup",
+			`{"email": "carol@example.com", "username": "carol", "password": "Password123!", "country": "Norway", "city": "Oslo", `+field+`}`)
+		rr := httptest.NewRecorder()
+		handlers.NewUserHandler(userService).Signup(rr, req)
+		if rr.Code != http.StatusBadRequest || !strings.HasPrefix(errorMessage(t, rr), "Unknown field ") {
+			t.Errorf("Expected a signup with %s to be rejected, got %d: %s", field, rr.Code, rr.Body.String())
+		}
+	}
+	if user, _ := userRepo.GetUserByEmail(context.Background(), "carol@example.com"); user != nil {
+		t.Errorf("Expected no account to be created, got %+v", user)
+	}
+
+	// A profile update cannot change the role.
+	profileService := mocks.NewMockProfileService()
+	profileService.Profiles["alice@example.com"] = map[string]interface{}{"Email": "alice@example.com", "Password": "secret"}
+	rr := httptest.NewRecorder()
+	handlers.NewProfileHandler(profileService).UpdateProfile(rr, jsonRequest("PUT", "/api/profile", `{"CurrentPassword": "secret", "Role": "admin"}`))
+	if rr.Code != http.StatusBadRequest || profileService.Profiles["alice@example.com"]["Role"] != nil {
+		t.Errorf("Expected a profile update with a role to be rejected, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// An event sent back as it was received keeps only the fields clients may set.
+	eventService := mocks.NewMockEventService()
+	rr = httptest.NewRecorder()
+	handlers.NewEventHandler(eventService).CreateEvent(rr, jsonRequest("POST", "/api/events/create",
+		`{"eventID": "old", "email": "mallory@example.com", "title": "Lunch", "date": "2024-03-01", "startTime": "12:00",
+		  "reminderSent": true, "importBatchID": "batch1", "externalID": "uid-1@ntnu", "timeZone": "Asia/Tokyo"}`))
+	event := eventService.Events[""]
+	if rr.Code != http.StatusOK || event == nil {
+		t.Fatalf("Expected the event to be created, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if event.Email != "alice@example.com" || event.Title != "Lunch" || event.StartTime != "12:00" ||
+		event.ReminderSent || event.ImportBatchID != "" || event.ExternalID != "" || event.TimeZone != "" {
+		t.Errorf("Expected the fields set by the server to be ignored, got %+v", event)
+	}
+
+	// So does a journal.
+	journalService := mocks.NewMockJournalService()
+	rr = httptest.NewRecorder()
+	handlers.NewJournalHandler(journalService).CreateJournal(rr, jsonRequest("POST", "/api/journal/save",
+		`{"journalID": "", "date": "2024-03-01", "content": "Sunny", "attachments": [{"id": "a1", "url": "https://example.com/a1.png"}]}`))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected the journal to be created, got %d: %s", rr.Code, rr.Body.String())
+	}
+	for _, journal := range journalService.Journals {
+		if journal.Email != "alice@example.com" || journal.Content != "Sunny" || len(journal.Attachments) != 0 {
+			t.Errorf("Expected the attachments to be ignored, got %+v", journal)
+		}
+	}
+}
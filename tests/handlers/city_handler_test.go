@@ -5,6 +5,7 @@
  *  - Correctly fetches cities when a valid 'country' parameter is provided.
  *  - Returns an error when the 'country' parameter is missing.
  *  - Handles errors from the CityService gracefully and returns appropriate status codes.
+ *  - Returns 504 Gateway Timeout when the cities API times out.
  *
  *  @dependencies
  *  - mocks.MockCityService: Mock implementation of the CityService for testing.
@@ -19,6 +20,7 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -26,6 +28,7 @@ import (
 	"testing"
 
 	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/services"
 	"proh2052-group6/tests/mocks"
 
 	"github.com/stretchr/testify/assert"
@@ -36,7 +39,7 @@ func TestCityHandler_GetCities_WithCountryParam(t *testing.T) {
 
 	// Setup mock CityService with expected behavior.
 	mockCityService := &mocks.MockCityService{
-		GetCitiesByCountryFunc: func(country string) ([]string, error) {
+		GetCitiesByCountryFunc: func(ctx context.Context, country string) ([]string, error) {
 			if country == "TestCountry" {
 				return []string{"City1", "City2", "City3"}, nil
 			}
@@ -112,7 +115,7 @@ func TestCityHandler_GetCities_ExternalAPIError(t *testing.T) {
 
 	// Setup mock CityService to return an error.
 	mockCityService := &mocks.MockCityService{
-		GetCitiesByCountryFunc: func(country string) ([]string, error) {
+		GetCitiesByCountryFunc: func(ctx context.Context, country string) ([]string, error) {
 			return nil, fmt.Errorf("error fetching cities: country not found")
 		},
 	}
@@ -140,3 +143,22 @@ func TestCityHandler_GetCities_ExternalAPIError(t *testing.T) {
 	expectedError := "Error fetching cities\n"
 	assert.Equal(t, expectedError, rr.Body.String(), "Error message should match")
 }
+
+func TestCityHandler_GetCities_Timeout(t *testing.T) {
+	// Test Case: Return 504 when the cities API does not respond in time.
+
+	// Setup mock CityService to report a timeout.
+	mockCityService := &mocks.MockCityService{
+		GetCitiesByCountryFunc: func(ctx context.Context, country string) ([]string, error) {
+			return nil, fmt.Errorf("%w: context deadline exceeded", services.ErrCityLookupTimeout)
+		},
+	}
+	cityHandler := handlers.NewCityHandler(mockCityService, &mocks.MockUserService{})
+
+	req, err := http.NewRequest("GET", "/api/cities?country=Norway", nil)
+	assert.NoError(t, err, "Failed to create request")
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(cityHandler.GetCities).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code, "Handler should return status 504 Gateway Timeout")
+}
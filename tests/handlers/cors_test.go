@@ -0,0 +1,113 @@
+/**
+ *  CORS Tests validate the middleware created by NewCORS: the headers of preflight and simple
+ *  requests from allowed and disallowed origins, so the allow-list does not rely on manual
+ *  browser testing.
+ *
+ *  @file       cors_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestCORS_Preflight     - Tests preflight answers for allowed and disallowed origins, methods and headers.
+ *  - TestCORS_SimpleRequest - Tests that only allowed origins get CORS headers on ordinary requests.
+ *
+ *  @dependencies
+ *  - middleware.NewCORS: The middleware under test.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"proh2052-group6/internal/middleware"
+)
+
+// corsOrigins is the allow-list the tests run the middleware with.
+var corsOrigins = []string{"https://dailyverse.app", "http://localhost:3000"}
+
+func TestCORS_Preflight(t *testing.T) {
+	reached := false
+	handler := middleware.NewCORS(corsOrigins)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+
+	tests := []struct {
+		name, origin, method, headers string
+		allowed                       bool
+	}{
+		{"allowed origin", "https://dailyverse.app", "POST", "Content-Type, Authorization", true},
+		{"allowed patch", "http://localhost:3000", "PATCH", "Idempotency-Key", true},
+		{"disallowed origin", "https://evil.example", "POST", "Content-Type", false},
+		{"other port", "http://localhost:4000", "GET", "", false},
+		{"disallowed method", "https://dailyverse.app", "TRACE", "", false},
+		{"disallowed header", "https://dailyverse.app", "POST", "X-Custom", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/api/events/create", nil)
+			req.Header.Set("Origin", test.origin)
+			req.Header.Set("Access-Control-Request-Method", test.method)
+			if test.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", test.headers)
+			}
+			rr := httptest.NewRecorder()
+			reached = false
+			handler.ServeHTTP(rr, req)
+
+			if reached {
+				t.Error("Expected the preflight to be answered by the middleware")
+			}
+			allowOrigin := rr.Header().Get("Access-Control-Allow-Origin")
+			if !test.allowed {
+				if allowOrigin != "" {
+					t.Errorf("Expected no Access-Control-Allow-Origin, got %q", allowOrigin)
+				}
+				return
+			}
+			if allowOrigin != test.origin {
+				t.Errorf("Expected Access-Control-Allow-Origin %q, got %q", test.origin, allowOrigin)
+			}
+			if rr.Header().Get("Access-Control-Allow-Credentials") != "true" {
+				t.Error("Expected credentials to be allowed")
+			}
+			if got := rr.Header().Get("Access-Control-Allow-Methods"); !strings.EqualFold(got, test.method) {
+				t.Errorf("Expected Access-Control-Allow-Methods %q, got %q", test.method, got)
+			}
+			if got, want := rr.Header().Get("Access-Control-Max-Age"), strconv.Itoa(int(middleware.CORSMaxAge.Seconds())); got != want {
+				t.Errorf("Expected Access-Control-Max-Age %s, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestCORS_SimpleRequest(t *testing.T) {
+	handler := middleware.NewCORS(corsOrigins)(okHandler)
+
+	for origin, allowed := range map[string]bool{"https://dailyverse.app": true, "https://evil.example": false} {
+		req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+		req.Header.Set("Origin", origin)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s: expected the request to be served, got %d", origin, rr.Code)
+		}
+		got := rr.Header().Get("Access-Control-Allow-Origin")
+		if allowed && (got != origin || rr.Header().Get("Access-Control-Expose-Headers") != "Idempotent-Replayed, Etag") {
+			t.Errorf("%s: expected the CORS headers, got %v", origin, rr.Header())
+		}
+		if !allowed && got != "" {
+			t.Errorf("%s: expected no Access-Control-Allow-Origin, got %q", origin, got)
+		}
+	}
+}
@@ -10,6 +10,7 @@
  *  - TestCountryHandler_GetCountries: Verifies the handler retrieves and filters country data correctly.
  *  - TestCountryHandler_GetCountries_ShortSearch: Ensures the handler properly handles short search queries.
  *  - TestCountryHandler_GetCountries_ExternalAPIError: Validates the handler's behavior when the external API fails.
+ *  - TestCountryHandler_GetCountries_Local: Verifies name and country code searches against the local country list.
  *
  *  @dependencies
  *  - httptest.Server: Used to mock the external API's behavior during testing.
@@ -94,7 +95,7 @@ func TestCountryHandler_GetCountries(t *testing.T) {
 	defer services.SetCountriesAPIURL(originalCountriesAPIURL)
 
 	// Create the handler
-	countryHandler := handlers.NewCountryHandler()
+	countryHandler := handlers.NewCountryHandler(services.NewCountryService(services.CountrySourceExternal))
 
 	// Create a test request with a search query
 	req, err := http.NewRequest("GET", "/api/countries?search=cam", nil)
@@ -145,10 +146,10 @@ func equalCountries(a, b []services.Country) bool {
 
 func TestCountryHandler_GetCountries_ShortSearch(t *testing.T) {
 	// Create the handler
-	countryHandler := handlers.NewCountryHandler()
+	countryHandler := handlers.NewCountryHandler(services.NewCountryService(services.CountrySourceLocal))
 
 	// Create a test request with a short search query
-	req, err := http.NewRequest("GET", "/api/countries?search=ca", nil)
+	req, err := http.NewRequest("GET", "/api/countries?search=c", nil)
 	if err != nil {
 		t.Fatalf("Failed to create request: %v", err)
 	}
@@ -190,7 +191,7 @@ func TestCountryHandler_GetCountries_ExternalAPIError(t *testing.T) {
 	defer services.SetCountriesAPIURL(originalCountriesAPIURL)
 
 	// Create the handler
-	countryHandler := handlers.NewCountryHandler()
+	countryHandler := handlers.NewCountryHandler(services.NewCountryService(services.CountrySourceExternal))
 
 	// Create a test request with a valid search query
 	req, err := http.NewRequest("GET", "/api/countries?search=can", nil)
@@ -216,3 +217,32 @@ func TestCountryHandler_GetCountries_ExternalAPIError(t *testing.T) {
 		t.Errorf("Expected error message '%s', got '%s'", expectedError, rr.Body.String())
 	}
 }
+
+func TestCountryHandler_GetCountries_Local(t *testing.T) {
+	countryHandler := handlers.NewCountryHandler(services.NewCountryService(services.CountrySourceLocal))
+
+	tests := []struct {
+		search string
+		want   []services.Country
+	}{
+		{"NO", []services.Country{{Name: "Norway", Code: "NO"}}},
+		{"norw", []services.Country{{Name: "Norway", Code: "NO"}}},
+		{"zz", []services.Country{}},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(countryHandler.GetCountries).ServeHTTP(rr, httptest.NewRequest("GET", "/api/countries?search="+tt.search, nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", tt.search, http.StatusOK, rr.Code)
+		}
+		var countries []services.Country
+		if err := json.Unmarshal(rr.Body.Bytes(), &countries); err != nil {
+			t.Fatalf("%s: failed to decode response body: %v", tt.search, err)
+		}
+		if !equalCountries(countries, tt.want) {
+			t.Errorf("%s: expected countries %v, got %v", tt.search, tt.want, countries)
+		}
+	}
+}
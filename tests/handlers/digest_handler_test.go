@@ -0,0 +1,61 @@
+/**
+ *  DigestHandler Tests validate the manual weekly digest trigger, which sends the digest to every
+ *  user who enabled it and reports how many were sent.
+ *
+ *  @file       digest_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestDigestHandler_RunDigests - Tests that only subscribers are emailed and the count is returned.
+ *
+ *  @dependencies
+ *  - services.NewDigestService: The service under the handler, over mock repositories.
+ *  - mocks.MockEmailService: Captures the digests.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+func TestDigestHandler_RunDigests(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "alice", Country: "Norway", IsVerified: true, DigestEnabled: true},
+		"bob@example.com":   {Email: "bob@example.com", Username: "bob", Country: "Norway", IsVerified: true},
+	})
+	emails := &mocks.MockEmailService{}
+	digestService := services.NewDigestService(userRepo, mocks.NewMockEventRepository(), mocks.NewMockJournalRepository(), emails)
+	digestHandler := handlers.NewDigestHandler(digestService)
+
+	req := httptest.NewRequest("POST", "/api/admin/digest/run", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "bob@example.com"))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(digestHandler.RunDigests).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var response map[string]int
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil || response["sent"] != 1 {
+		t.Errorf("Expected 1 digest to be reported, got %s (err: %v)", rr.Body.String(), err)
+	}
+	if len(emails.SentEmails) != 1 || emails.SentEmails[0].To != "alice@example.com" {
+		t.Errorf("Expected a digest to alice only, got %+v", emails.SentEmails)
+	}
+}
@@ -0,0 +1,62 @@
+/**
+ *  DocsHandler Tests validate that the OpenAPI document and the Swagger UI page are served.
+ *
+ *  @file       docs_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestDocsHandler_GetSpec - Tests that the document is returned unchanged as JSON.
+ *  - TestDocsHandler_GetDocs - Tests that the HTML page points Swagger UI at /api/openapi.json.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"proh2052-group6/internal/handlers"
+)
+
+func TestDocsHandler_GetSpec(t *testing.T) {
+	spec := `{"openapi":"3.0.3"}`
+	docsHandler := handlers.NewDocsHandler([]byte(spec))
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(docsHandler.GetSpec).ServeHTTP(rr, httptest.NewRequest("GET", "/api/openapi.json", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected Content-Type application/json, got %q", ct)
+	}
+	if rr.Body.String() != spec {
+		t.Errorf("Expected the document %s, got %s", spec, rr.Body.String())
+	}
+}
+
+func TestDocsHandler_GetDocs(t *testing.T) {
+	docsHandler := handlers.NewDocsHandler(nil)
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(docsHandler.GetDocs).ServeHTTP(rr, httptest.NewRequest("GET", "/api/docs", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Expected an HTML page, got %q", rr.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rr.Body.String(), `url: "/api/openapi.json"`) {
+		t.Errorf("Expected the page to load /api/openapi.json, got %s", rr.Body.String())
+	}
+}
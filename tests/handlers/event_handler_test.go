@@ -11,11 +11,30 @@
  *  - TestEventHandler_UpdateEvent      - Tests updating an existing event.
  *  - TestEventHandler_DeleteEvent      - Tests deleting an event.
  *  - TestEventHandler_GetAllEvents     - Tests retrieving all events for a user.
+ *  - TestEventHandler_GetAllEvents_Paginated - Tests the paginated response shape for date-filtered requests.
+ *  - TestEventHandler_GetAllEvents_ETag - Tests 304 for unchanged events, a new ETag after an update, and HEAD.
+ *  - TestEventHandler_OccurrenceScope  - Tests the scope and date parameters for updating and deleting occurrences.
+ *  - TestEventHandler_GetAllEvents_TagFilter - Tests filtering the listing with the tag parameter.
+ *  - TestEventHandler_GetEventTags     - Tests listing the user's tags with counts.
+ *  - TestEventHandler_ValidationErrors - Tests the field-level 400 payload for invalid events on create and update.
+ *  - TestEventHandler_CreateEvent_IdempotencyKey - Tests the Idempotent-Replayed header on retries and 422 on key reuse.
+ *  - TestEventHandler_RepositoryErrors - Tests 404 for getting or deleting a missing event and 503 while the database is unavailable.
+ *  - TestEventHandler_CreateEvent_Geocoding - Tests the geocoded hint with a mock geocoder, including a failed lookup.
+ *  - TestEventHandler_GetNearbyEvents  - Tests the nearby listing and its parameter validation.
+ *  - TestEventHandler_SearchEvents     - Tests searching across fields, an empty result and the 400 responses.
+ *  - TestEventHandler_BulkEvents       - Tests the partial-failure response of bulk create and delete, and the 100-event cap.
+ *  - TestEventHandler_PatchEvent       - Tests partial updates with PATCH and that PUT cannot change an event's email or ID.
+ *  - TestEventHandler_GetEventMonth    - Tests the month summary, invalid months, and rejected colors and all-day times.
+ *  - TestEventHandler_SharedEvents     - Tests creating, reading and revoking a shared link, its redacted body and the 404 and 410 responses.
+ *  - TestEventHandler_FriendEvents     - Tests that friends get public events only, and the 403, 404 and 400 responses.
+ *  - TestEventHandler_DuplicateEvent   - Tests weekly copies and the 400 and 404 responses for invalid requests and other users' events.
  *
  *  @dependencies
  *  - mocks.NewMockEventService: Mock implementation of EventService for testing.
+ *  - services.NewEventService: The real service with mock repositories, where its validation is tested.
+ *  - mocks.NewMockGeocodingService: Mock geocoder for event addresses.
  *  - httptest: Provides utilities for testing HTTP handlers.
- *  - context.WithValue: Adds user-specific context values for testing purposes.
+ *  - middleware.WithUserEmail: Adds the authenticated user email to the request context.
  *  - encoding/json: Handles JSON marshalling and unmarshalling.
  *
  *  @behaviors
@@ -36,11 +55,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
 
 	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
 	"proh2052-group6/pkg/models"
 	"proh2052-group6/tests/mocks"
 )
@@ -67,7 +94,7 @@ func TestEventHandler_CreateEvent(t *testing.T) {
 
 	// Inject userEmail into context
 	userEmail := "test@example.com"
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create ResponseRecorder to capture response
@@ -130,7 +157,7 @@ func TestEventHandler_GetEvent(t *testing.T) {
 	}
 
 	// Inject userEmail into context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create ResponseRecorder
@@ -192,7 +219,7 @@ func TestEventHandler_UpdateEvent(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	// Set the userEmail in the context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create a ResponseRecorder to record the response
@@ -243,7 +270,7 @@ func TestEventHandler_DeleteEvent(t *testing.T) {
 	}
 
 	// Set the userEmail in the context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create a ResponseRecorder to record the response
@@ -297,7 +324,7 @@ func TestEventHandler_GetAllEvents(t *testing.T) {
 	}
 
 	// Set the userEmail in the context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create a ResponseRecorder to record the response
@@ -324,3 +351,885 @@ func TestEventHandler_GetAllEvents(t *testing.T) {
 		t.Errorf("Expected 2 events, got %d", len(response))
 	}
 }
+
+func TestEventHandler_GetAllEvents_Paginated(t *testing.T) {
+	mockEventService := mocks.NewMockEventService()
+	eventHandler := handlers.NewEventHandler(mockEventService)
+
+	userEmail := "test@example.com"
+	for _, event := range []*models.Event{
+		{EventID: "event1", Email: userEmail, Title: "Meeting", Date: "2023-10-15"},
+		{EventID: "event2", Email: userEmail, Title: "Conference", Date: "2023-11-20"},
+		{EventID: "event3", Email: userEmail, Title: "Workshop", Date: "2023-11-25"},
+	} {
+		mockEventService.Events[event.EventID] = event
+	}
+
+	req, err := http.NewRequest("GET", "/api/events/all?from=2023-11-01&limit=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(eventHandler.GetAllEvents).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.EventPage
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if len(response.Items) != 1 || response.Items[0].EventID != "event2" || response.NextPageToken != "event2" {
+		t.Errorf("Expected first page with event2 and a next page token, got %+v", response)
+	}
+
+	// An invalid limit is rejected.
+	req, _ = http.NewRequest("GET", "/api/events/all?limit=abc", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(eventHandler.GetAllEvents).ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid limit, got %v", rr.Code)
+	}
+}
+
+func TestEventHandler_GetAllEvents_ETag(t *testing.T) {
+	mockEventService := mocks.NewMockEventService()
+	eventHandler := handlers.NewEventHandler(mockEventService)
+	mockEventService.Events["event1"] = &models.Event{EventID: "event1", Email: "test@example.com", Title: "Meeting", Date: "2024-05-01"}
+
+	list := func(method, url, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		eventHandler.GetAllEvents(rr, req)
+		return rr
+	}
+
+	for _, url := range []string{"/api/events/all", "/api/events/all?limit=10"} {
+		first := list("GET", url, "")
+		etag := first.Header().Get("ETag")
+		if first.Code != http.StatusOK || etag == "" {
+			t.Fatalf("%s: expected 200 with an ETag, got %d with %q", url, first.Code, etag)
+		}
+		if rr := list("GET", url, etag); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+			t.Errorf("%s: expected 304 without a body, got %d with %q", url, rr.Code, rr.Body.String())
+		}
+		if rr := list("HEAD", url, ""); rr.Code != http.StatusOK || rr.Body.Len() != 0 || rr.Header().Get("ETag") != etag {
+			t.Errorf("%s: expected the headers only for HEAD, got %d with %q", url, rr.Code, rr.Body.String())
+		}
+
+		mockEventService.Events["event1"].Title += " (moved)"
+		if rr := list("GET", url, etag); rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+			t.Errorf("%s: expected an update to change the ETag, got %d with %q", url, rr.Code, rr.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestEventHandler_OccurrenceScope(t *testing.T) {
+	mockEventService := mocks.NewMockEventService()
+	eventHandler := handlers.NewEventHandler(mockEventService)
+
+	userEmail := "test@example.com"
+	mockEventService.Events["series1"] = &models.Event{
+		EventID:    "series1",
+		Email:      userEmail,
+		Title:      "Weekly meeting",
+		Date:       "2024-01-01",
+		Recurrence: &models.Recurrence{Frequency: "weekly", Interval: 1},
+	}
+
+	tests := []struct {
+		method string
+		url    string
+		body   string
+		status int
+	}{
+		{"DELETE", "/api/events/delete?eventID=series1&scope=all", "", http.StatusBadRequest},
+		{"DELETE", "/api/events/delete?eventID=series1&scope=occurrence", "", http.StatusBadRequest},
+		{"DELETE", "/api/events/delete?eventID=series1&scope=occurrence&date=2024-01-08", "", http.StatusOK},
+		{"PUT", "/api/events/update?eventID=series1&scope=occurrence&date=2024-01-15", `{"title":"Moved meeting","date":"2024-01-16"}`, http.StatusOK},
+		{"PUT", "/api/events/update?eventID=missing&scope=occurrence&date=2024-01-15", `{"title":"Moved meeting"}`, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		req, _ := http.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+
+		rr := httptest.NewRecorder()
+		if tc.method == "DELETE" {
+			http.HandlerFunc(eventHandler.DeleteEvent).ServeHTTP(rr, req)
+		} else {
+			http.HandlerFunc(eventHandler.UpdateEvent).ServeHTTP(rr, req)
+		}
+		if rr.Code != tc.status {
+			t.Errorf("%s %s: expected status %d, got %d (%s)", tc.method, tc.url, tc.status, rr.Code, rr.Body.String())
+		}
+	}
+
+	// Only the targeted occurrences were touched; the series itself still exists.
+	series := mockEventService.Events["series1"]
+	if series == nil || len(series.ExceptionDates) != 2 {
+		t.Fatalf("Expected the series to record 2 exceptions, got %+v", series)
+	}
+	if moved := mockEventService.Events["series1_2024-01-15"]; moved == nil || moved.SeriesID != "series1" {
+		t.Errorf("Expected the moved occurrence to be linked to the series, got %+v", moved)
+	}
+}
+
+func TestEventHandler_GetAllEvents_TagFilter(t *testing.T) {
+	mockEventService := mocks.NewMockEventService()
+	eventHandler := handlers.NewEventHandler(mockEventService)
+
+	userEmail := "test@example.com"
+	for _, event := range []*models.Event{
+		{EventID: "event1", Email: userEmail, Title: "Standup", Date: "2023-10-15", Tags: []string{"work"}},
+		{EventID: "event2", Email: userEmail, Title: "Gym", Date: "2023-10-16", Tags: []string{"gym"}},
+		{EventID: "event3", Email: userEmail, Title: "Planning", Date: "2023-10-17", Tags: []string{"gym", "work"}},
+	} {
+		mockEventService.Events[event.EventID] = event
+	}
+
+	req, _ := http.NewRequest("GET", "/api/events/all?tag=work", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(eventHandler.GetAllEvents).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var response models.EventPage
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if len(response.Items) != 2 || response.Items[0].EventID != "event1" || response.Items[1].EventID != "event3" {
+		t.Errorf("Expected event1 and event3 tagged work, got %+v", response.Items)
+	}
+}
+
+func TestEventHandler_GetEventTags(t *testing.T) {
+	mockEventService := mocks.NewMockEventService()
+	eventHandler := handlers.NewEventHandler(mockEventService)
+
+	userEmail := "test@example.com"
+	mockEventService.Events["event1"] = &models.Event{EventID: "event1", Email: userEmail, Tags: []string{"work"}}
+	mockEventService.Events["event2"] = &models.Event{EventID: "event2", Email: userEmail, Tags: []string{"gym", "work"}}
+	mockEventService.Events["event3"] = &models.Event{EventID: "event3", Email: "other@example.com", Tags: []string{"school"}}
+
+	req, _ := http.NewRequest("GET", "/api/events/tags", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(eventHandler.GetEventTags).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var tags []models.TagCount
+	if err := json.Unmarshal(rr.Body.Bytes(), &tags); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != (models.TagCount{Tag: "gym", Count: 1}) || tags[1] != (models.TagCount{Tag: "work", Count: 2}) {
+		t.Errorf("Unexpected tags: %+v", tags)
+	}
+}
+
+// decodeValidationErrors decodes the field errors of a 400 validation response.
+func decodeValidationErrors(t *testing.T, rr *httptest.ResponseRecorder) map[string]string {
+	t.Helper()
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+	}
+	var response struct {
+		Message string            `json:"message"`
+		Errors  map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if response.Message != "Invalid input" {
+		t.Errorf("Expected message 'Invalid input', got %q", response.Message)
+	}
+	return response.Errors
+}
+
+func TestEventHandler_ValidationErrors(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	eventService := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	eventHandler := handlers.NewEventHandler(eventService)
+
+	send := func(method, url string, event models.Event) *httptest.ResponseRecorder {
+		requestBody, _ := json.Marshal(event)
+		req := httptest.NewRequest(method, url, bytes.NewBuffer(requestBody))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		if method == "POST" {
+			http.HandlerFunc(eventHandler.CreateEvent).ServeHTTP(rr, req)
+		} else {
+			http.HandlerFunc(eventHandler.UpdateEvent).ServeHTTP(rr, req)
+		}
+		return rr
+	}
+
+	invalid := models.Event{Date: "2024-05-01", EventTypeID: "private", StartTime: "14:00", EndTime: "13:00", PostalNumber: "7O34"}
+	fieldErrors := decodeValidationErrors(t, send("POST", "/api/events/create", invalid))
+	expected := map[string]string{"title": "required", "endTime": "must be after startTime", "postalNumber": "must contain only digits"}
+	if len(fieldErrors) != len(expected) {
+		t.Errorf("Expected errors %v, got %v", expected, fieldErrors)
+	}
+	for field, message := range expected {
+		if fieldErrors[field] != message {
+			t.Errorf("Expected %s to be %q, got %q", field, message, fieldErrors[field])
+		}
+	}
+	if len(eventRepo.Events) != 0 {
+		t.Errorf("Expected the invalid event not to be stored")
+	}
+
+	valid := models.Event{Title: "Meeting", Date: "2024-05-01", EventTypeID: "private", StartTime: "13:00", EndTime: "14:00", PostalNumber: "7034"}
+	rr := send("POST", "/api/events/create", valid)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d for a valid event, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var created map[string]string
+	json.Unmarshal(rr.Body.Bytes(), &created)
+
+	valid.Title = "   "
+	fieldErrors = decodeValidationErrors(t, send("PUT", "/api/events/update?eventID="+created["eventID"], valid))
+	if fieldErrors["title"] != "required" {
+		t.Errorf("Expected a blank title to be rejected on update, got %v", fieldErrors)
+	}
+}
+
+func TestEventHandler_CreateEvent_IdempotencyKey(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	eventService := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, mocks.NewMockIdempotencyRepository(), nil)
+	eventHandler := handlers.NewEventHandler(eventService)
+
+	send := func(title string) *httptest.ResponseRecorder {
+		requestBody, _ := json.Marshal(models.Event{Title: title, Date: "2024-05-10", EventTypeID: "private"})
+		req := httptest.NewRequest("POST", "/api/events/create", bytes.NewBuffer(requestBody))
+		req.Header.Set("Idempotency-Key", "retry-1")
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(eventHandler.CreateEvent).ServeHTTP(rr, req)
+		return rr
+	}
+	eventID := func(rr *httptest.ResponseRecorder) string {
+		var response map[string]string
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return response["eventID"]
+	}
+
+	first := send("Dinner")
+	if first.Code != http.StatusOK || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("Expected a fresh 200 response, got %d with Idempotent-Replayed %q", first.Code, first.Header().Get("Idempotent-Replayed"))
+	}
+
+	retry := send("Dinner")
+	if retry.Code != http.StatusOK || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("Expected a replayed 200 response, got %d with Idempotent-Replayed %q", retry.Code, retry.Header().Get("Idempotent-Replayed"))
+	}
+	if eventID(retry) != eventID(first) {
+		t.Errorf("Expected replayed eventID %q, got %q", eventID(first), eventID(retry))
+	}
+	if len(eventRepo.Events) != 1 {
+		t.Errorf("Expected 1 event, got %d", len(eventRepo.Events))
+	}
+
+	if rr := send("Lunch"); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a reused key, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+}
+
+func TestEventHandler_RepositoryErrors(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	eventService := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	eventHandler := handlers.NewEventHandler(eventService)
+
+	send := func(handler http.HandlerFunc, url string) int {
+		req := httptest.NewRequest("GET", url, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if status := send(eventHandler.GetEvent, "/api/events/get?eventID=missing"); status != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing event, got %d", http.StatusNotFound, status)
+	}
+
+	// Deleting a missing event, or another user's, is not a silent success.
+	other := &models.Event{Email: "other@example.com", Title: "Dentist", Date: "2024-05-01", EventTypeID: "private"}
+	if err := eventService.CreateEvent(context.Background(), other); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	for _, eventID := range []string{"missing", other.EventID} {
+		if status := send(eventHandler.DeleteEvent, "/api/events/delete?eventID="+eventID); status != http.StatusNotFound {
+			t.Errorf("Expected status %d for deleting %s, got %d", http.StatusNotFound, eventID, status)
+		}
+	}
+	if _, exists := eventRepo.Events[other.EventID]; !exists {
+		t.Error("Expected the other user's event to be kept")
+	}
+
+	eventRepo.Err = repositories.ErrUnavailable
+	if status := send(eventHandler.GetEvent, "/api/events/get?eventID=missing"); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d for GetEvent during an outage, got %d", http.StatusServiceUnavailable, status)
+	}
+	if status := send(eventHandler.GetAllEvents, "/api/events"); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d for GetAllEvents during an outage, got %d", http.StatusServiceUnavailable, status)
+	}
+}
+
+func TestEventHandler_CreateEvent_Geocoding(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	geocoder := mocks.NewMockGeocodingService(map[string]services.Coordinates{
+		"Kongens gate 1, 7011": {Latitude: 63.4305, Longitude: 10.3951},
+	})
+	eventService := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, geocoder)
+	eventHandler := handlers.NewEventHandler(eventService)
+
+	create := func(street, postal string) handlers.EventSavedResponse {
+		t.Helper()
+		requestBody, _ := json.Marshal(models.Event{Title: "Meeting", Date: "2024-05-01", EventTypeID: "private", StreetAddress: street, PostalNumber: postal})
+		req := httptest.NewRequest("POST", "/api/events/create", bytes.NewBuffer(requestBody))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(eventHandler.CreateEvent).ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var response handlers.EventSavedResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response body: %v", err)
+		}
+		return response
+	}
+
+	found := create("Kongens gate 1", "7011")
+	if found.Geocoded == nil || !*found.Geocoded {
+		t.Errorf("Expected geocoded to be true, got %v", found.Geocoded)
+	}
+	stored := eventRepo.Events[found.EventID]
+	if stored.Latitude == nil || *stored.Latitude != 63.4305 || *stored.Longitude != 10.3951 {
+		t.Errorf("Expected the coordinates to be stored, got %v, %v", stored.Latitude, stored.Longitude)
+	}
+
+	notFound := create("Nowhere 1", "9999")
+	if notFound.Geocoded == nil || *notFound.Geocoded {
+		t.Errorf("Expected geocoded to be false for an unknown address, got %v", notFound.Geocoded)
+	}
+	if eventRepo.Events[notFound.EventID].Latitude != nil {
+		t.Errorf("Expected no coordinates to be stored for an unknown address")
+	}
+
+	if noAddress := create("", ""); noAddress.Geocoded != nil {
+		t.Errorf("Expected no geocoded hint without an address, got %v", *noAddress.Geocoded)
+	}
+}
+
+func TestEventHandler_GetNearbyEvents(t *testing.T) {
+	mockEventService := mocks.NewMockEventService()
+	eventHandler := handlers.NewEventHandler(mockEventService)
+
+	userEmail := "test@example.com"
+	position := func(latitude, longitude float64) (*float64, *float64) { return &latitude, &longitude }
+	torget := &models.Event{EventID: "torget", Email: userEmail, Title: "Torget"}
+	torget.Latitude, torget.Longitude = position(63.4305, 10.3951)
+	oslo := &models.Event{EventID: "oslo", Email: userEmail, Title: "Oslo"}
+	oslo.Latitude, oslo.Longitude = position(59.9139, 10.7522)
+	mockEventService.Events["torget"] = torget
+	mockEventService.Events["oslo"] = oslo
+	mockEventService.Events["unplaced"] = &models.Event{EventID: "unplaced", Email: userEmail, Title: "Unplaced"}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/events/nearby"+query, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(eventHandler.GetNearbyEvents).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("?lat=63.43&lng=10.40")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var nearby []models.NearbyEvent
+	if err := json.Unmarshal(rr.Body.Bytes(), &nearby); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if len(nearby) != 1 || nearby[0].EventID != "torget" || nearby[0].DistanceKm > 1 {
+		t.Errorf("Expected only Torget within the default radius, got %+v", nearby)
+	}
+
+	json.Unmarshal(get("?lat=63.43&lng=10.40&radiusKm=500").Body.Bytes(), &nearby)
+	if len(nearby) != 2 || nearby[0].EventID != "torget" || nearby[1].EventID != "oslo" {
+		t.Errorf("Expected Torget and then Oslo within 500 km, got %+v", nearby)
+	}
+
+	for _, query := range []string{"", "?lat=63.43", "?lat=north&lng=10.40", "?lat=63.43&lng=10.40&radiusKm=far"} {
+		if rr := get(query); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %q, got %d", http.StatusBadRequest, query, rr.Code)
+		}
+	}
+}
+
+func TestEventHandler_SearchEvents(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	eventService := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	eventHandler := handlers.NewEventHandler(eventService)
+
+	userEmail := "test@example.com"
+	for _, event := range []*models.Event{
+		{Title: "Dentist", Description: "Bring the dentist forms", Date: "2024-03-14", StartTime: "14:00"},
+		{Title: "Checkup", StreetAddress: "Dentistveien 4", Date: "2024-03-14", StartTime: "08:00"},
+		{Title: "Gym", Date: "2024-03-15", StartTime: "18:00"},
+	} {
+		event.Email, event.EventTypeID = userEmail, "private"
+		if err := eventService.CreateEvent(context.Background(), event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+
+	search := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/events/search"+query, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(eventHandler.SearchEvents).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := search("?q=dentist&from=2024-03-01&to=2024-03-31")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var results []models.EventSearchResult
+	if err := json.Unmarshal(rr.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if len(results) != 2 || results[0].Title != "Checkup" || results[0].MatchedField != "streetAddress" ||
+		results[1].Title != "Dentist" || results[1].MatchedField != "title" {
+		t.Errorf("Expected Checkup by address and then Dentist by title, got %+v", results)
+	}
+
+	rr = search("?q=orthodontist&from=2024-03-01&to=2024-03-31")
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+		t.Errorf("Expected an empty array for a query that matches nothing, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	for _, query := range []string{
+		"?from=2024-03-01&to=2024-03-31",
+		"?q=dentist",
+		"?q=dentist&from=March&to=2024-03-31",
+		"?q=dentist&from=2024-03-31&to=2024-03-01",
+		"?q=dentist&from=2024-01-01&to=2025-06-01",
+	} {
+		if rr := search(query); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %q, got %d", http.StatusBadRequest, query, rr.Code)
+		}
+	}
+}
+
+func TestEventHandler_BulkEvents(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	eventService := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	eventHandler := handlers.NewEventHandler(eventService)
+
+	send := func(handler http.HandlerFunc, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		requestBody, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", "/api/events/bulk", bytes.NewBuffer(requestBody))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	decode := func(rr *httptest.ResponseRecorder) map[string]interface{} {
+		t.Helper()
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var response map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response body: %v", err)
+		}
+		return response
+	}
+
+	created := decode(send(eventHandler.BulkCreateEvents, []models.Event{
+		{Title: "Meeting", Date: "2024-05-01", EventTypeID: "private"},
+		{Title: "Party", Date: "2024-05-02", EventTypeID: "birthday"},
+	}))
+	succeeded, _ := created["succeeded"].([]interface{})
+	failed, _ := created["failed"].([]interface{})
+	if len(succeeded) != 1 || len(failed) != 1 {
+		t.Fatalf("Expected one created and one failed event, got %v", created)
+	}
+	if failure := failed[0].(map[string]interface{}); failure["index"] != 1.0 || failure["error"] != "Invalid event type" {
+		t.Errorf("Expected the second event to fail with its index, got %v", failure)
+	}
+
+	other := &models.Event{Email: "other@example.com", Title: "Theirs", Date: "2024-05-01", EventTypeID: "private"}
+	eventRepo.CreateEvent(context.Background(), other)
+	deleted := decode(send(eventHandler.BulkDeleteEvents, handlers.BulkDeleteEventsRequest{EventIDs: []string{succeeded[0].(string), other.EventID}}))
+	if succeeded, _ := deleted["succeeded"].([]interface{}); len(succeeded) != 1 || succeeded[0] != created["succeeded"].([]interface{})[0] {
+		t.Errorf("Expected the user's event to be deleted, got %v", deleted)
+	}
+	failed, _ = deleted["failed"].([]interface{})
+	if len(failed) != 1 || failed[0].(map[string]interface{})["eventID"] != other.EventID || failed[0].(map[string]interface{})["error"] != "Event not found" {
+		t.Errorf("Expected the other user's event to fail as not found, got %v", deleted)
+	}
+	if eventRepo.Events[other.EventID] == nil {
+		t.Errorf("Expected the other user's event not to be deleted")
+	}
+
+	tooMany := make([]string, services.MaxBulkEvents+1)
+	for i := range tooMany {
+		tooMany[i] = "event"
+	}
+	for name, rr := range map[string]*httptest.ResponseRecorder{
+		"bulk create over the cap": send(eventHandler.BulkCreateEvents, make([]models.Event, services.MaxBulkEvents+1)),
+		"bulk delete over the cap": send(eventHandler.BulkDeleteEvents, handlers.BulkDeleteEventsRequest{EventIDs: tooMany}),
+		"empty bulk delete":        send(eventHandler.BulkDeleteEvents, handlers.BulkDeleteEventsRequest{}),
+		"bulk create of an object": send(eventHandler.BulkCreateEvents, models.Event{Title: "Meeting"}),
+	} {
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, name, rr.Code)
+		}
+	}
+
+	eventRepo.Err = repositories.ErrUnavailable
+	if rr := send(eventHandler.BulkDeleteEvents, handlers.BulkDeleteEventsRequest{EventIDs: []string{other.EventID}}); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d during an outage, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
+
+func TestEventHandler_PatchEvent(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	eventService := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	eventHandler := handlers.NewEventHandler(eventService)
+	event := &models.Event{Email: "test@example.com", Title: "Meeting", Description: "Team meeting", Date: "2024-05-01", StartTime: "13:00", EventTypeID: "private"}
+	if err := eventService.CreateEvent(context.Background(), event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	send := func(method, url, body, userEmail string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+		rr := httptest.NewRecorder()
+		if method == "PATCH" {
+			http.HandlerFunc(eventHandler.PatchEvent).ServeHTTP(rr, req)
+		} else {
+			http.HandlerFunc(eventHandler.UpdateEvent).ServeHTTP(rr, req)
+		}
+		return rr
+	}
+
+	url := "/api/events/update?eventID=" + event.EventID
+	rr := send("PATCH", url, `{"title": "Planning"}`, "test@example.com")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var patched models.Event
+	if err := json.Unmarshal(rr.Body.Bytes(), &patched); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if patched.Title != "Planning" || patched.Description != "Team meeting" || patched.StartTime != "13:00" {
+		t.Errorf("Expected the updated event with its other fields, got %+v", patched)
+	}
+
+	tests := []struct {
+		name, method, url, body, user string
+		status                        int
+	}{
+		{"other user's event", "PATCH", url, `{"title": "Mine"}`, "other@example.com", http.StatusNotFound},
+		{"change email", "PATCH", url, `{"email": "other@example.com"}`, "test@example.com", http.StatusBadRequest},
+		{"unknown field", "PATCH", url, `{"colour": "red"}`, "test@example.com", http.StatusBadRequest},
+		{"not an object", "PATCH", url, `null`, "test@example.com", http.StatusBadRequest},
+		{"occurrence scope", "PATCH", url + "&scope=occurrence&date=2024-05-01", `{"title": "Once"}`, "test@example.com", http.StatusBadRequest},
+		{"missing event ID", "PATCH", "/api/events/update", `{"title": "Planning"}`, "test@example.com", http.StatusBadRequest},
+		{"PUT changing email", "PUT", url, `{"email": "other@example.com", "title": "Planning", "date": "2024-05-01"}`, "test@example.com", http.StatusBadRequest},
+		{"PUT changing ID", "PUT", url, `{"eventID": "event99", "title": "Planning", "date": "2024-05-01"}`, "test@example.com", http.StatusBadRequest},
+		{"PUT missing event", "PUT", "/api/events/update?eventID=missing", `{"title": "Ghost", "date": "2024-05-01"}`, "test@example.com", http.StatusNotFound},
+		{"PUT echoing email and ID", "PUT", url, fmt.Sprintf(`{"email": "test@example.com", "eventID": %q, "title": "Planning", "date": "2024-05-01"}`, event.EventID), "test@example.com", http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if rr := send(test.method, test.url, test.body, test.user); rr.Code != test.status {
+				t.Errorf("Expected status %d, got %d: %s", test.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+	if len(eventRepo.Events) != 1 || eventRepo.Events[event.EventID].Email != "test@example.com" {
+		t.Errorf("Expected only the original event to be stored, got %v", eventRepo.Events)
+	}
+}
+
+func TestEventHandler_GetEventMonth(t *testing.T) {
+	eventService := services.NewEventService(mocks.NewMockEventRepository(), mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	eventHandler := handlers.NewEventHandler(eventService)
+
+	userEmail := "test@example.com"
+	for _, event := range []*models.Event{
+		{Title: "Holiday", Date: "2024-05-17", AllDay: true, Color: "red"},
+		{Title: "Parade", Date: "2024-05-17", StartTime: "10:00", Color: "#00FF00"},
+	} {
+		event.Email, event.EventTypeID = userEmail, "private"
+		if err := eventService.CreateEvent(context.Background(), event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/events/month"+query, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(eventHandler.GetEventMonth).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("?month=2024-05")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var month models.EventMonth
+	if err := json.Unmarshal(rr.Body.Bytes(), &month); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if month.From != "2024-04-29" || month.To != "2024-06-02" || month.Weeks != 5 || len(month.Days) != 1 {
+		t.Fatalf("Unexpected month summary: %+v", month)
+	}
+	if day := month.Days[0]; day.Date != "2024-05-17" || day.Count != 2 || day.FirstTitle != "Holiday" ||
+		strings.Join(day.Colors, ",") != "red,#00ff00" {
+		t.Errorf("Unexpected day summary: %+v", day)
+	}
+
+	for _, query := range []string{"", "?month=May", "?month=2024-5", "?month=2024-05-01"} {
+		rr := get(query)
+		if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), `"month"`) {
+			t.Errorf("Expected a 400 naming month for %q, got %d %s", query, rr.Code, rr.Body.String())
+		}
+	}
+
+	// Invalid colors and all-day events with times are rejected on create.
+	for _, body := range []string{
+		`{"title": "Party", "date": "2024-05-18", "eventTypeID": "private", "color": "#12345"}`,
+		`{"title": "Party", "date": "2024-05-18", "eventTypeID": "private", "allDay": true, "startTime": "20:00"}`,
+	} {
+		req := httptest.NewRequest("POST", "/api/events/create", strings.NewReader(body))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(eventHandler.CreateEvent).ServeHTTP(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d: %s", http.StatusBadRequest, body, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestEventHandler_SharedEvents(t *testing.T) {
+	mockEventService := mocks.NewMockEventService()
+	eventHandler := handlers.NewEventHandler(mockEventService)
+	mockEventService.Events["party"] = &models.Event{
+		EventID: "party", Email: "owner@example.com", Title: "Party", Date: "2024-06-01", StartTime: "20:00",
+		StreetAddress: "Main Street 1", Description: "Bring snacks",
+	}
+
+	send := func(handler http.HandlerFunc, method, target, userEmail string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if userEmail != "" {
+			req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	getShared := func(token string) *httptest.ResponseRecorder {
+		req := mux.SetURLVars(httptest.NewRequest("GET", "/api/shared/events/"+token, nil), map[string]string{"token": token})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(eventHandler.GetSharedEvent).ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send(eventHandler.ShareEvent, "POST", "/api/events/share", "owner@example.com"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without an eventID, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if rr := send(eventHandler.ShareEvent, "POST", "/api/events/share?eventID=party", "other@example.com"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for another user's event, got %d", http.StatusNotFound, rr.Code)
+	}
+	rr := send(eventHandler.ShareEvent, "POST", "/api/events/share?eventID=party", "owner@example.com")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var link models.EventShareLink
+	if err := json.Unmarshal(rr.Body.Bytes(), &link); err != nil || link.Token == "" || !strings.HasSuffix(link.URL, "/shared/events/"+link.Token) {
+		t.Fatalf("Expected a token and its URL, got %s (err: %v)", rr.Body.String(), err)
+	}
+
+	// The shared event is read without authentication and without the owner's email.
+	rr = getShared(link.Token)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var shared map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &shared); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if shared["title"] != "Party" || shared["startTime"] != "20:00" || shared["streetAddress"] != "Main Street 1" || shared["description"] != "Bring snacks" {
+		t.Errorf("Expected the title, time, address and description of the event, got %v", shared)
+	}
+	if _, ok := shared["email"]; ok || strings.Contains(rr.Body.String(), "owner@example.com") {
+		t.Errorf("Expected the owner's email to be left out, got %s", rr.Body.String())
+	}
+
+	if rr := getShared("unknown"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown token, got %d", http.StatusNotFound, rr.Code)
+	}
+	mockEventService.Shares[link.Token].ExpiresAt = time.Now().Add(-time.Minute)
+	if rr := getShared(link.Token); rr.Code != http.StatusGone {
+		t.Errorf("Expected status %d for an expired link, got %d", http.StatusGone, rr.Code)
+	}
+
+	rr = send(eventHandler.ShareEvent, "POST", "/api/events/share?eventID=party", "owner@example.com")
+	if err := json.Unmarshal(rr.Body.Bytes(), &link); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if rr := send(eventHandler.RevokeEventShare, "DELETE", "/api/events/share?eventID=party", "other@example.com"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d when another user revokes the link, got %d", http.StatusNotFound, rr.Code)
+	}
+	if rr := send(eventHandler.RevokeEventShare, "DELETE", "/api/events/share?eventID=party", "owner@example.com"); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if rr := getShared(link.Token); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a revoked link, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestEventHandler_FriendEvents(t *testing.T) {
+	users := map[string]*models.User{
+		"alice@example.com":   {Email: "alice@example.com", Username: "alice"},
+		"bob@example.com":     {Email: "bob@example.com", Username: "bob"},
+		"mallory@example.com": {Email: "mallory@example.com", Username: "mallory"},
+	}
+	friends := map[string]*models.Friend{
+		"alice@example.com_bob@example.com": {Email: "alice@example.com", FriendEmail: "bob@example.com", Status: "accepted"},
+	}
+	eventService := services.NewEventService(mocks.NewMockEventRepository(), mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(users), mocks.NewMockFriendRepository(friends), nil, nil, nil)
+	eventHandler := handlers.NewEventHandler(eventService)
+
+	ctx := context.Background()
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	for _, event := range []*models.Event{
+		{Email: "bob@example.com", Title: "Party", Date: tomorrow, StartTime: "20:00", EventTypeID: "public"},
+		{Email: "bob@example.com", Title: "Doctor", Date: tomorrow, StartTime: "09:00", EventTypeID: "private"},
+	} {
+		if err := eventService.CreateEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+
+	send := func(handler http.HandlerFunc, target, userEmail string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for name, rr := range map[string]*httptest.ResponseRecorder{
+		"/api/friends/events": send(eventHandler.GetFriendEvents, "/api/friends/events?username=bob", "alice@example.com"),
+		"/api/events/feed":    send(eventHandler.GetFriendsEventFeed, "/api/events/feed", "alice@example.com"),
+	} {
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d: %s", name, http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var events []map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &events); err != nil {
+			t.Fatalf("%s: failed to parse response body: %v", name, err)
+		}
+		if len(events) != 1 || events[0]["title"] != "Party" || events[0]["username"] != "bob" {
+			t.Errorf("%s: expected bob's public party only, got %s", name, rr.Body.String())
+		}
+		if strings.Contains(rr.Body.String(), "Doctor") || strings.Contains(rr.Body.String(), "bob@example.com") {
+			t.Errorf("%s: expected the private event and bob's email to be left out, got %s", name, rr.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name      string
+		target    string
+		userEmail string
+		want      int
+	}{
+		{"NotFriends", "/api/friends/events?username=bob", "mallory@example.com", http.StatusForbidden},
+		{"Self", "/api/friends/events?username=bob", "bob@example.com", http.StatusForbidden},
+		{"UnknownUser", "/api/friends/events?username=nobody", "alice@example.com", http.StatusNotFound},
+		{"MissingUsername", "/api/friends/events", "alice@example.com", http.StatusBadRequest},
+		{"InvalidDate", "/api/friends/events?username=bob&from=tomorrow", "alice@example.com", http.StatusBadRequest},
+		{"RangeTooLong", "/api/friends/events?username=bob&from=2024-01-01&to=2025-06-01", "alice@example.com", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := send(eventHandler.GetFriendEvents, tt.target, tt.userEmail)
+		if rr.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.want, rr.Code, rr.Body.String())
+		}
+		if tt.want == http.StatusForbidden && strings.Contains(rr.Body.String(), "Party") {
+			t.Errorf("%s: expected no events in the error, got %s", tt.name, rr.Body.String())
+		}
+	}
+
+	if rr := send(eventHandler.GetFriendsEventFeed, "/api/events/feed", "mallory@example.com"); rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+		t.Errorf("Expected an empty feed without friends, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestEventHandler_DuplicateEvent(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	eventService := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	eventHandler := handlers.NewEventHandler(eventService)
+
+	source := &models.Event{Email: "test@example.com", Title: "Standup", Date: "2024-05-01", EventTypeID: "private"}
+	other := &models.Event{Email: "other@example.com", Title: "Theirs", Date: "2024-05-01", EventTypeID: "private"}
+	eventRepo.CreateEvent(context.Background(), source)
+	eventRepo.CreateEvent(context.Background(), other)
+
+	send := func(body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		requestBody, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", "/api/events/duplicate", bytes.NewBuffer(requestBody))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		eventHandler.DuplicateEvent(rr, req)
+		return rr
+	}
+
+	rr := send(handlers.DuplicateEventRequest{EventID: source.EventID, Date: "2024-06-03", Count: 2})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var response handlers.DuplicateEventResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if len(response.EventIDs) != 2 || eventRepo.Events[response.EventIDs[1]] == nil || eventRepo.Events[response.EventIDs[1]].Date != "2024-06-10" {
+		t.Errorf("Expected two copies a week apart, got %+v", response)
+	}
+
+	for name, tc := range map[string]struct {
+		body   interface{}
+		status int
+	}{
+		"missing eventID":      {handlers.DuplicateEventRequest{Date: "2024-06-03"}, http.StatusBadRequest},
+		"count over the cap":   {handlers.DuplicateEventRequest{EventID: source.EventID, Date: "2024-06-03", Count: services.MaxEventCopies + 1}, http.StatusBadRequest},
+		"invalid date":         {handlers.DuplicateEventRequest{EventID: source.EventID, Date: "June 3rd"}, http.StatusBadRequest},
+		"another user's event": {handlers.DuplicateEventRequest{EventID: other.EventID, Date: "2024-06-03"}, http.StatusNotFound},
+	} {
+		if rr := send(tc.body); rr.Code != tc.status {
+			t.Errorf("Expected status %d for %s, got %d: %s", tc.status, name, rr.Code, rr.Body.String())
+		}
+	}
+}
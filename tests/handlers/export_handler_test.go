@@ -0,0 +1,213 @@
+/**
+ *  ExportHandler Tests validate the data export download. They use the real ExportService over
+ *  mock repositories and unzip the recorded response to check its contents.
+ *
+ *  @file       export_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestExportHandler_ExportData         - Tests that each JSON file in the archive parses and holds the user's data.
+ *  - TestExportHandler_ExportData_Unknown - Tests the JSON 404 for a user who no longer exists.
+ *  - TestExportHandler_ExportData_Unavailable - Tests the 503 when the database cannot be reached.
+ *  - TestExportHandler_RateLimited        - Tests that exports are limited per user, not per IP.
+ *
+ *  @dependencies
+ *  - services.NewExportService: The real service with mock repositories.
+ *  - mocks.NewMockUserRepository, mocks.NewMockEventRepository, mocks.NewMockJournalRepository,
+ *    mocks.NewMockFriendRepository: Mock repositories holding the exported data.
+ *  - middleware.NewUserRateLimiter: Limits exports per user.
+ *  - archive/zip: Reads the recorded archive.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newExportHandler creates an ExportHandler over mock repositories holding a profile, two events,
+// a journal entry, a friend and a block for user@example.com, and an event of another user.
+func newExportHandler(t *testing.T) *handlers.ExportHandler {
+	t.Helper()
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user@example.com": {Email: "user@example.com", Username: "user", City: "Oslo", Password: "hashed-password", OTP: "hashed-otp"},
+	})
+	eventRepo := mocks.NewMockEventRepository()
+	for _, event := range []*models.Event{
+		{Email: "user@example.com", Title: "Dinner", Date: "2024-05-01"},
+		{Email: "user@example.com", Title: "Gym", Date: "2024-05-02"},
+		{Email: "other@example.com", Title: "Not mine", Date: "2024-05-03"},
+	} {
+		eventRepo.CreateEvent(ctx, event)
+	}
+	journalRepo := mocks.NewMockJournalRepository()
+	journalRepo.CreateJournal(ctx, &models.Journal{Email: "user@example.com", Date: "2024-05-01", Content: "A good day"})
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		"user@example.com_friend@example.com": {Email: "user@example.com", FriendEmail: "friend@example.com", Status: "accepted"},
+	})
+	friendRepo.CreateBlock(ctx, &models.Block{BlockerEmail: "user@example.com", BlockedEmail: "troll@example.com"})
+
+	return handlers.NewExportHandler(services.NewExportService(userRepo, eventRepo, journalRepo, friendRepo))
+}
+
+// requestExport sends an export request for email through handler.
+func requestExport(handler http.Handler, email string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/me/export", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), email))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+// readZipFile returns the contents of a file in the archive, failing the test if it is missing.
+func readZipFile(t *testing.T, archive *zip.Reader, name string) []byte {
+	t.Helper()
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", name, err)
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return data
+	}
+	t.Fatalf("Expected %s in the archive", name)
+	return nil
+}
+
+func TestExportHandler_ExportData(t *testing.T) {
+	rr := requestExport(http.HandlerFunc(newExportHandler(t).ExportData), "user@example.com")
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/zip" {
+		t.Errorf("Expected application/zip, got %q", contentType)
+	}
+	if disposition := rr.Header().Get("Content-Disposition"); !strings.Contains(disposition, "attachment") || !strings.Contains(disposition, ".zip") {
+		t.Errorf("Expected a ZIP attachment, got Content-Disposition %q", disposition)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(rr.Body.Bytes()), int64(rr.Body.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read the archive: %v", err)
+	}
+
+	profileData := readZipFile(t, archive, "profile.json")
+	var profile map[string]interface{}
+	if err := json.Unmarshal(profileData, &profile); err != nil {
+		t.Fatalf("Failed to parse profile.json: %v", err)
+	}
+	if profile["email"] != "user@example.com" || profile["city"] != "Oslo" {
+		t.Errorf("Expected the user's profile, got %v", profile)
+	}
+	for _, secret := range []string{"hashed-password", "hashed-otp"} {
+		if bytes.Contains(profileData, []byte(secret)) {
+			t.Errorf("Expected profile.json not to contain %q", secret)
+		}
+	}
+
+	var events []models.Event
+	if err := json.Unmarshal(readZipFile(t, archive, "events.json"), &events); err != nil {
+		t.Fatalf("Failed to parse events.json: %v", err)
+	}
+	if len(events) != 2 || events[0].Title != "Dinner" || events[1].Title != "Gym" {
+		t.Errorf("Expected the user's 2 events, got %+v", events)
+	}
+
+	var journals []models.Journal
+	if err := json.Unmarshal(readZipFile(t, archive, "journals.json"), &journals); err != nil {
+		t.Fatalf("Failed to parse journals.json: %v", err)
+	}
+	if len(journals) != 1 || journals[0].Content != "A good day" {
+		t.Errorf("Expected the user's journal entry, got %+v", journals)
+	}
+
+	var friends struct {
+		Friends          []models.Friend `json:"friends"`
+		IncomingRequests []models.Friend `json:"incomingRequests"`
+		Blocked          []models.Block  `json:"blocked"`
+	}
+	if err := json.Unmarshal(readZipFile(t, archive, "friends.json"), &friends); err != nil {
+		t.Fatalf("Failed to parse friends.json: %v", err)
+	}
+	if len(friends.Friends) != 1 || friends.Friends[0].FriendEmail != "friend@example.com" {
+		t.Errorf("Expected the friendship with friend@example.com, got %+v", friends.Friends)
+	}
+	if friends.IncomingRequests == nil || len(friends.IncomingRequests) != 0 {
+		t.Errorf("Expected an empty list of incoming requests, got %v", friends.IncomingRequests)
+	}
+	if len(friends.Blocked) != 1 || friends.Blocked[0].BlockedEmail != "troll@example.com" {
+		t.Errorf("Expected the block of troll@example.com, got %+v", friends.Blocked)
+	}
+}
+
+func TestExportHandler_ExportData_Unknown(t *testing.T) {
+	rr := requestExport(http.HandlerFunc(newExportHandler(t).ExportData), "gone@example.com")
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("Expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected a JSON error, got %q", contentType)
+	}
+}
+
+func TestExportHandler_ExportData_Unavailable(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{})
+	userRepo.Err = repositories.ErrUnavailable
+	exportService := services.NewExportService(userRepo, mocks.NewMockEventRepository(), mocks.NewMockJournalRepository(), mocks.NewMockFriendRepository(map[string]*models.Friend{}))
+	rr := requestExport(http.HandlerFunc(handlers.NewExportHandler(exportService).ExportData), "user@example.com")
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
+
+func TestExportHandler_RateLimited(t *testing.T) {
+	limit := middleware.NewUserRateLimiter(rate.Every(12*time.Hour), 2)
+	handler := limit(http.HandlerFunc(newExportHandler(t).ExportData))
+
+	for i := 1; i <= 2; i++ {
+		if rr := requestExport(handler, "user@example.com"); rr.Code != http.StatusOK {
+			t.Fatalf("Export %d: expected status %d, got %d", i, http.StatusOK, rr.Code)
+		}
+	}
+	if rr := requestExport(handler, "user@example.com"); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the third export to be rejected with %d, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+	// Another user on the same IP has their own limit.
+	if rr := requestExport(handler, "gone@example.com"); rr.Code == http.StatusTooManyRequests {
+		t.Errorf("Expected another user not to be limited")
+	}
+}
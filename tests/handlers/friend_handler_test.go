@@ -16,12 +16,20 @@
  *
  *  @testcases
  *  - TestSendFriendRequestHandler: Validates the ability to send a friend request.
+ *  - TestSendFriendRequestHandler_CrossingRequest: Checks that a request to a user who already sent one accepts theirs.
+ *  - TestSendFriendRequestHandler_DeclineCooldown: Checks that sending again right after a decline returns 429.
  *  - TestAcceptFriendRequestHandler: Verifies that pending friend requests can be accepted.
  *  - TestGetFriendsListHandler: Checks the retrieval of a user's accepted friend list.
+ *  - TestGetFriendsListHandler_NoSensitiveFields: Checks that friends are returned as summaries with friendsSince only.
+ *  - TestGetFriendSuggestionsHandler: Checks suggestions of friends of friends and limit validation.
+ *  - TestGetMutualFriendsHandler: Checks the mutual friends endpoint and its error status codes.
  *  - TestRemoveFriendHandler: Ensures a user can remove an existing friend.
  *  - TestGetPendingFriendRequestsHandler: Validates retrieval of pending friend requests.
  *  - TestDeclineFriendRequestHandler: Confirms that a user can decline a pending friend request.
  *  - TestCancelFriendRequestHandler: Tests the ability to cancel a sent friend request.
+ *  - TestFriendHandler_DatabaseUnavailable: Checks that failing friend lookups return 503 instead of "not found" errors.
+ *  - TestInviteFriendHandler: Checks friend requests and invitations by email and their error status codes.
+ *  - TestGetRelationshipStatusesHandler: Checks the batch status lookup and its error status codes.
  *
  *  @behaviors
  *  - Uses mock repositories to simulate user and friend data for isolated testing.
@@ -39,11 +47,11 @@
  *  userRepo := mocks.NewMockUserRepository(mockUsers)
  *  friendRepo := mocks.NewMockFriendRepository(make(map[string]*models.Friend))
  *
- *  friendService := services.NewFriendService(userRepo, friendRepo)
+ *  friendService := services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil)
  *  friendHandler := handlers.NewFriendHandler(friendService)
  *
  *  req, _ := http.NewRequest("POST", "/api/friends/add", bytes.NewReader(body))
- *  ctx := context.WithValue(req.Context(), "userEmail", "user1@example.com")
+ *  ctx := middleware.WithUserEmail(req.Context(), "user1@example.com")
  *  req = req.WithContext(ctx)
  *
  *  rr := httptest.NewRecorder()
@@ -59,13 +67,16 @@ package handlers_test
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
 	"proh2052-group6/internal/services"
 	"proh2052-group6/pkg/models"
 	"proh2052-group6/tests/mocks"
@@ -79,7 +90,7 @@ func TestSendFriendRequestHandler(t *testing.T) {
 	userRepo := mocks.NewMockUserRepository(mockUsers)
 	friendRepo := mocks.NewMockFriendRepository(make(map[string]*models.Friend))
 
-	friendService := services.NewFriendService(userRepo, friendRepo)
+	friendService := services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil)
 	friendHandler := handlers.NewFriendHandler(friendService)
 
 	requestData := map[string]string{
@@ -92,7 +103,7 @@ func TestSendFriendRequestHandler(t *testing.T) {
 	}
 
 	// Mock authentication context
-	ctx := context.WithValue(req.Context(), "userEmail", "user1@example.com")
+	ctx := middleware.WithUserEmail(req.Context(), "user1@example.com")
 	req = req.WithContext(ctx)
 
 	rr := httptest.NewRecorder()
@@ -134,7 +145,7 @@ func TestAcceptFriendRequestHandler(t *testing.T) {
 		},
 	})
 
-	friendService := services.NewFriendService(userRepo, friendRepo)
+	friendService := services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil)
 	friendHandler := handlers.NewFriendHandler(friendService)
 
 	requestData := map[string]string{
@@ -147,7 +158,7 @@ func TestAcceptFriendRequestHandler(t *testing.T) {
 	}
 
 	// Mock authentication context
-	ctx := context.WithValue(req.Context(), "userEmail", "user1@example.com")
+	ctx := middleware.WithUserEmail(req.Context(), "user1@example.com")
 	req = req.WithContext(ctx)
 
 	rr := httptest.NewRecorder()
@@ -197,7 +208,7 @@ func TestGetFriendsListHandler(t *testing.T) {
 			Status:      "accepted",
 		},
 	})
-	friendService := services.NewFriendService(userRepo, friendRepo)
+	friendService := services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil)
 	friendHandler := handlers.NewFriendHandler(friendService)
 
 	req, err := http.NewRequest("GET", "/api/friends/list", nil)
@@ -206,7 +217,7 @@ func TestGetFriendsListHandler(t *testing.T) {
 	}
 
 	// Mock authentication context
-	ctx := context.WithValue(req.Context(), "userEmail", "user1@example.com")
+	ctx := middleware.WithUserEmail(req.Context(), "user1@example.com")
 	req = req.WithContext(ctx)
 
 	rr := httptest.NewRecorder()
@@ -218,7 +229,7 @@ func TestGetFriendsListHandler(t *testing.T) {
 	}
 
 	// Verify response body
-	var friends []models.User
+	var friends []models.UserSummary
 	err = json.Unmarshal(rr.Body.Bytes(), &friends)
 	if err != nil {
 		t.Errorf("Failed to parse response body")
@@ -247,6 +258,48 @@ func TestGetFriendsListHandler(t *testing.T) {
 	}
 }
 
+func TestGetFriendsListHandler_NoSensitiveFields(t *testing.T) {
+	friendsSince := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user1@example.com": {Email: "user1@example.com", Username: "user1"},
+		"user2@example.com": {Email: "user2@example.com", Username: "user2", Password: "hashed", OTP: "123456", IsVerified: true, FailedLoginCount: 2},
+	})
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		"user1@example.com_user2@example.com": {
+			Email:       "user1@example.com",
+			FriendEmail: "user2@example.com",
+			Status:      "accepted",
+			CreatedAt:   friendsSince,
+		},
+	})
+	friendHandler := handlers.NewFriendHandler(services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil))
+
+	req := httptest.NewRequest("GET", "/api/friends/list", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "user1@example.com"))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(friendHandler.GetFriendsList).ServeHTTP(rr, req)
+
+	var friends []map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &friends); err != nil || len(friends) != 1 {
+		t.Fatalf("Expected 1 friend, got %s (err: %v)", rr.Body.String(), err)
+	}
+
+	allowed := map[string]bool{"username": true, "email": true, "country": true, "city": true, "friendsSince": true}
+	for key := range friends[0] {
+		if !allowed[key] {
+			t.Errorf("Unexpected key %q in friends list response", key)
+		}
+	}
+	for _, sensitive := range []string{"isVerified", "password", "otp", "otpExpiresAt", "usernameLower"} {
+		if strings.Contains(rr.Body.String(), sensitive) {
+			t.Errorf("Friends list response contains %q: %s", sensitive, rr.Body.String())
+		}
+	}
+	if friends[0]["friendsSince"] != "2024-11-01T12:00:00Z" {
+		t.Errorf("Expected friendsSince 2024-11-01T12:00:00Z, got %v", friends[0]["friendsSince"])
+	}
+}
+
 func TestRemoveFriendHandler(t *testing.T) {
 	mockUsers := map[string]*models.User{
 		"user1@example.com": {Email: "user1@example.com", Username: "user1"},
@@ -260,7 +313,7 @@ func TestRemoveFriendHandler(t *testing.T) {
 			Status:      "accepted",
 		},
 	})
-	friendService := services.NewFriendService(userRepo, friendRepo)
+	friendService := services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil)
 	friendHandler := handlers.NewFriendHandler(friendService)
 
 	requestData := map[string]string{
@@ -273,7 +326,7 @@ func TestRemoveFriendHandler(t *testing.T) {
 	}
 
 	// Mock authentication context
-	ctx := context.WithValue(req.Context(), "userEmail", "user1@example.com")
+	ctx := middleware.WithUserEmail(req.Context(), "user1@example.com")
 	req = req.WithContext(ctx)
 
 	rr := httptest.NewRecorder()
@@ -324,7 +377,7 @@ func TestGetPendingFriendRequestsHandler(t *testing.T) {
 			Status:      "pending",
 		},
 	})
-	friendService := services.NewFriendService(userRepo, friendRepo)
+	friendService := services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil)
 	friendHandler := handlers.NewFriendHandler(friendService)
 
 	req, err := http.NewRequest("GET", "/api/friends/requests", nil)
@@ -333,7 +386,7 @@ func TestGetPendingFriendRequestsHandler(t *testing.T) {
 	}
 
 	// Mock authentication context
-	ctx := context.WithValue(req.Context(), "userEmail", "user1@example.com")
+	ctx := middleware.WithUserEmail(req.Context(), "user1@example.com")
 	req = req.WithContext(ctx)
 
 	rr := httptest.NewRecorder()
@@ -387,7 +440,7 @@ func TestDeclineFriendRequestHandler(t *testing.T) {
 			Status:      "pending",
 		},
 	})
-	friendService := services.NewFriendService(userRepo, friendRepo)
+	friendService := services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil)
 	friendHandler := handlers.NewFriendHandler(friendService)
 
 	requestData := map[string]string{
@@ -400,7 +453,7 @@ func TestDeclineFriendRequestHandler(t *testing.T) {
 	}
 
 	// Mock authentication context
-	ctx := context.WithValue(req.Context(), "userEmail", "user1@example.com")
+	ctx := middleware.WithUserEmail(req.Context(), "user1@example.com")
 	req = req.WithContext(ctx)
 
 	rr := httptest.NewRecorder()
@@ -421,10 +474,10 @@ func TestDeclineFriendRequestHandler(t *testing.T) {
 		t.Errorf("Unexpected response message: %s", response["message"])
 	}
 
-	// Verify that the friend request has been removed
+	// Verify that the friend request is kept as declined for the resend cooldown
 	friendKey := "user2@example.com_user1@example.com"
-	if _, exists := friendRepo.Friends[friendKey]; exists {
-		t.Errorf("Friend request not removed from mock repository")
+	if friend, exists := friendRepo.Friends[friendKey]; !exists || friend.Status != "declined" || friend.DeclinedAt == nil {
+		t.Errorf("Friend request not marked as declined in mock repository")
 	}
 }
 
@@ -441,7 +494,7 @@ func TestCancelFriendRequestHandler(t *testing.T) {
 			Status:      "pending",
 		},
 	})
-	friendService := services.NewFriendService(userRepo, friendRepo)
+	friendService := services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil)
 	friendHandler := handlers.NewFriendHandler(friendService)
 
 	requestData := map[string]string{
@@ -454,7 +507,7 @@ func TestCancelFriendRequestHandler(t *testing.T) {
 	}
 
 	// Mock authentication context
-	ctx := context.WithValue(req.Context(), "userEmail", "user1@example.com")
+	ctx := middleware.WithUserEmail(req.Context(), "user1@example.com")
 	req = req.WithContext(ctx)
 
 	rr := httptest.NewRecorder()
@@ -481,3 +534,249 @@ func TestCancelFriendRequestHandler(t *testing.T) {
 		t.Errorf("Friend request not removed from mock repository")
 	}
 }
+
+// newSuggestionsFriendHandler creates a FriendHandler where user1 and user3 share the friend user2,
+// and user4 is an unrelated user.
+func newSuggestionsFriendHandler() *handlers.FriendHandler {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user1@example.com": {Email: "user1@example.com", Username: "user1"},
+		"user2@example.com": {Email: "user2@example.com", Username: "user2"},
+		"user3@example.com": {Email: "user3@example.com", Username: "user3"},
+		"user4@example.com": {Email: "user4@example.com", Username: "user4"},
+	})
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		"user1@example.com_user2@example.com": {Email: "user1@example.com", FriendEmail: "user2@example.com", Status: "accepted"},
+		"user2@example.com_user3@example.com": {Email: "user2@example.com", FriendEmail: "user3@example.com", Status: "accepted"},
+	})
+	return handlers.NewFriendHandler(services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil))
+}
+
+func TestGetFriendSuggestionsHandler(t *testing.T) {
+	friendHandler := newSuggestionsFriendHandler()
+
+	tests := []struct {
+		url        string
+		wantStatus int
+		wantUsers  []string
+	}{
+		{"/api/friends/suggestions", http.StatusOK, []string{"user3"}},
+		{"/api/friends/suggestions?limit=5", http.StatusOK, []string{"user3"}},
+		{"/api/friends/suggestions?limit=abc", http.StatusBadRequest, nil},
+		{"/api/friends/suggestions?limit=0", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user1@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(friendHandler.GetFriendSuggestions).ServeHTTP(rr, req)
+
+		if rr.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.url, tt.wantStatus, rr.Code)
+			continue
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+
+		var suggestions []models.UserSummary
+		if err := json.Unmarshal(rr.Body.Bytes(), &suggestions); err != nil {
+			t.Fatalf("%s: failed to parse response body: %v", tt.url, err)
+		}
+		if len(suggestions) != len(tt.wantUsers) || suggestions[0].Username != tt.wantUsers[0] || suggestions[0].MutualFriends != 1 {
+			t.Errorf("%s: expected %v with 1 mutual friend, got %+v", tt.url, tt.wantUsers, suggestions)
+		}
+	}
+}
+
+func TestGetMutualFriendsHandler(t *testing.T) {
+	friendHandler := newSuggestionsFriendHandler()
+
+	tests := []struct {
+		url        string
+		wantStatus int
+		wantCount  int
+	}{
+		{"/api/friends/mutual?username=user3", http.StatusOK, 1},
+		{"/api/friends/mutual?username=user4", http.StatusOK, 0},
+		{"/api/friends/mutual", http.StatusBadRequest, 0},
+		{"/api/friends/mutual?username=user1", http.StatusBadRequest, 0},
+		{"/api/friends/mutual?username=nobody", http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user1@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(friendHandler.GetMutualFriends).ServeHTTP(rr, req)
+
+		if rr.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.url, tt.wantStatus, rr.Code)
+			continue
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+
+		var mutualFriends []models.UserSummary
+		if err := json.Unmarshal(rr.Body.Bytes(), &mutualFriends); err != nil {
+			t.Fatalf("%s: failed to parse response body: %v", tt.url, err)
+		}
+		if len(mutualFriends) != tt.wantCount {
+			t.Errorf("%s: expected %d mutual friends, got %+v", tt.url, tt.wantCount, mutualFriends)
+		}
+	}
+}
+
+func TestSendFriendRequestHandler_CrossingRequest(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user1@example.com": {Email: "user1@example.com", Username: "user1"},
+		"user2@example.com": {Email: "user2@example.com", Username: "user2"},
+	})
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{})
+	friendHandler := handlers.NewFriendHandler(services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil))
+
+	send := func(userEmail, target string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"usernameOrEmail": target})
+		req := httptest.NewRequest("POST", "/api/friends/add", bytes.NewReader(body))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(friendHandler.SendFriendRequest).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("user1@example.com", "user2")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Friend request sent") {
+		t.Fatalf("Expected 'Friend request sent', got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = send("user2@example.com", "user1")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Friend request accepted") {
+		t.Fatalf("Expected 'Friend request accepted', got %d %s", rr.Code, rr.Body.String())
+	}
+	if friend := friendRepo.Friends["user1@example.com_user2@example.com"]; friend == nil || friend.Status != "accepted" {
+		t.Errorf("Expected the original request to be accepted, got %+v", friend)
+	}
+	if _, exists := friendRepo.Friends["user2@example.com_user1@example.com"]; exists {
+		t.Errorf("Expected no crossing request to be created")
+	}
+}
+
+func TestSendFriendRequestHandler_DeclineCooldown(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user1@example.com": {Email: "user1@example.com", Username: "user1"},
+		"user2@example.com": {Email: "user2@example.com", Username: "user2"},
+	})
+	declinedAt := time.Now().Add(-time.Hour)
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		"user1@example.com_user2@example.com": {
+			Email:       "user1@example.com",
+			FriendEmail: "user2@example.com",
+			Status:      "declined",
+			DeclinedAt:  &declinedAt,
+		},
+	})
+	friendHandler := handlers.NewFriendHandler(services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil))
+
+	body, _ := json.Marshal(map[string]string{"usernameOrEmail": "user2"})
+	req := httptest.NewRequest("POST", "/api/friends/add", bytes.NewReader(body))
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "user1@example.com"))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(friendHandler.SendFriendRequest).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusTooManyRequests || !strings.Contains(rr.Body.String(), "Request recently declined, try again later") {
+		t.Errorf("Expected 429 'Request recently declined, try again later', got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestFriendHandler_DatabaseUnavailable(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user1@example.com": {Email: "user1@example.com", Username: "user1"},
+		"user2@example.com": {Email: "user2@example.com", Username: "user2"},
+	})
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{})
+	friendRepo.Err = repositories.ErrUnavailable
+	friendHandler := handlers.NewFriendHandler(services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil))
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		body    string
+	}{
+		{"GetFriendsList", friendHandler.GetFriendsList, ""},
+		{"SendFriendRequest", friendHandler.SendFriendRequest, `{"usernameOrEmail":"user2"}`},
+		{"RemoveFriend", friendHandler.RemoveFriend, `{"username":"user2"}`},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/api/friends", strings.NewReader(tt.body))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user1@example.com"))
+		rr := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected status %d, got %d", tt.name, http.StatusServiceUnavailable, rr.Code)
+		}
+	}
+}
+
+func TestInviteFriendHandler(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user1@example.com": {Email: "user1@example.com", Username: "user1"},
+		"user2@example.com": {Email: "user2@example.com", Username: "user2"},
+	})
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{})
+	friendService := services.NewFriendService(userRepo, friendRepo, &mocks.MockEmailService{}, nil)
+	friendService.(*services.FriendService).InvitationRepo = mocks.NewMockFriendInvitationRepository()
+	friendHandler := handlers.NewFriendHandler(friendService)
+
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantMessage string
+	}{
+		{"registered user", `{"email":"user2@example.com"}`, http.StatusOK, "Friend request sent"},
+		{"new address", `{"email":"new@example.com"}`, http.StatusOK, "Invitation sent"},
+		{"already invited", `{"email":"new@example.com"}`, http.StatusConflict, "You have already invited this email address"},
+		{"not an email", `{"email":"user2"}`, http.StatusBadRequest, "Invalid email address"},
+		{"missing email", `{}`, http.StatusBadRequest, "Email is required"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/api/friends/invite", strings.NewReader(tt.body))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user1@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(friendHandler.InviteFriend).ServeHTTP(rr, req)
+
+		if rr.Code != tt.wantStatus || !strings.Contains(rr.Body.String(), tt.wantMessage) {
+			t.Errorf("%s: expected %d %q, got %d %s", tt.name, tt.wantStatus, tt.wantMessage, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestGetRelationshipStatusesHandler(t *testing.T) {
+	friendHandler := newSuggestionsFriendHandler()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"mixed", `{"emails":["user2@example.com","user3@example.com","nobody@example.com"]}`, http.StatusOK,
+			`{"nobody@example.com":"none","user2@example.com":"friends","user3@example.com":"none"}`},
+		{"empty", `{"emails":[]}`, http.StatusOK, `{}`},
+		{"not an email", `{"emails":["user2"]}`, http.StatusBadRequest, "Invalid email address"},
+		{"too many", `{"emails":[` + strings.Repeat(`"a@example.com",`, services.MaxRelationshipStatusEmails) + `"b@example.com"]}`,
+			http.StatusBadRequest, "At most 50 emails"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/api/friends/status", strings.NewReader(tt.body))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user1@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(friendHandler.GetRelationshipStatuses).ServeHTTP(rr, req)
+
+		if rr.Code != tt.wantStatus || !strings.Contains(rr.Body.String(), tt.wantBody) {
+			t.Errorf("%s: expected %d %s, got %d %s", tt.name, tt.wantStatus, tt.wantBody, rr.Code, rr.Body.String())
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/**
+ *  HealthHandler Tests validate the liveness and readiness probe responses.
+ *  They use a HealthService with injected checkers to simulate a failing Firestore.
+ *
+ *  @file       health_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestHealthHandler_Liveness  - Tests that /healthz always returns 200.
+ *  - TestHealthHandler_Readiness - Tests 200 with per-dependency statuses, and 503 when Firestore fails.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/services"
+)
+
+func TestHealthHandler_Liveness(t *testing.T) {
+	failing := func(ctx context.Context) error { return fmt.Errorf("unavailable") }
+	healthHandler := handlers.NewHealthHandler(services.NewHealthService(map[string]services.HealthChecker{"firestore": failing}))
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(healthHandler.Liveness).ServeHTTP(rr, httptest.NewRequest("GET", "/healthz", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d even with a failing dependency, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestHealthHandler_Readiness(t *testing.T) {
+	tests := []struct {
+		name          string
+		firestoreErr  error
+		wantStatus    int
+		wantFirestore string
+	}{
+		{"ready", nil, http.StatusOK, "ok"},
+		{"firestore down", fmt.Errorf("rpc error: code = Unavailable desc = connection refused"), http.StatusServiceUnavailable, "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthHandler := handlers.NewHealthHandler(services.NewHealthService(map[string]services.HealthChecker{
+				"firestore": func(ctx context.Context) error { return tt.firestoreErr },
+				"smtp":      services.SkippedHealthChecker(),
+			}))
+
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(healthHandler.Readiness).ServeHTTP(rr, httptest.NewRequest("GET", "/readyz", nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			var statuses map[string]string
+			if err := json.Unmarshal(rr.Body.Bytes(), &statuses); err != nil {
+				t.Fatalf("Failed to parse response body: %v", err)
+			}
+			if statuses["firestore"] != tt.wantFirestore || statuses["smtp"] != "skipped" {
+				t.Errorf("Unexpected statuses: %v", statuses)
+			}
+		})
+	}
+}
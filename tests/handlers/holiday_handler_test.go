@@ -0,0 +1,136 @@
+/**
+ *  HolidayHandler Tests validate the holidays of the user's country, using a fake holidays API behind
+ *  the real HolidayService and a mock user service.
+ *
+ *  @file       holiday_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestHolidayHandler_GetHolidays               - Tests the holidays of the user's country and the year sent upstream.
+ *  - TestHolidayHandler_GetHolidays_UpstreamError - Tests 502 when the API fails, and the embedded holidays for Norway.
+ *  - TestHolidayHandler_GetHolidays_Errors        - Tests invalid years and a missing or unknown country.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newHolidayHandler returns a HolidayHandler for a user in country, with a HolidayService calling a
+// fake holidays API that answers with status and body and records the requested paths in paths.
+func newHolidayHandler(t *testing.T, country string, paths *[]string, status int, body string) *handlers.HolidayHandler {
+	t.Helper()
+	holidaysAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*paths = append(*paths, r.URL.Path)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(holidaysAPI.Close)
+
+	holidayService := &services.HolidayService{
+		HTTPClient:  holidaysAPI.Client(),
+		HolidaysURL: holidaysAPI.URL,
+		CacheTTL:    time.Hour,
+	}
+	userService := &mocks.MockUserService{
+		GetUserInfoFunc: func(ctx context.Context, userEmail string) (*models.UserProfile, error) {
+			return &models.UserProfile{Email: userEmail, City: "Oslo", Country: country}, nil
+		},
+	}
+	return handlers.NewHolidayHandler(holidayService, userService)
+}
+
+// getHolidays sends GET /api/holidays with the given query as test@example.com.
+func getHolidays(handler *handlers.HolidayHandler, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/holidays"+query, nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(handler.GetHolidays).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestHolidayHandler_GetHolidays(t *testing.T) {
+	var paths []string
+	handler := newHolidayHandler(t, "Sweden", &paths, http.StatusOK,
+		`[{"date":"2025-06-06","localName":"Sveriges nationaldag","name":"National Day","global":true}]`)
+
+	rr := getHolidays(handler, "?year=2025")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var holidays []services.Holiday
+	if err := json.NewDecoder(rr.Body).Decode(&holidays); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(holidays) != 1 || holidays[0] != (services.Holiday{Date: "2025-06-06", LocalName: "Sveriges nationaldag", Name: "National Day"}) {
+		t.Errorf("Unexpected holidays %+v", holidays)
+	}
+
+	// Without a year, the current year is requested.
+	getHolidays(handler, "")
+	if want := []string{"/2025/SE", "/" + strconv.Itoa(time.Now().Year()) + "/SE"}; len(paths) != 2 || paths[0] != want[0] || paths[1] != want[1] {
+		t.Errorf("Expected requests for %v, got %v", want, paths)
+	}
+}
+
+func TestHolidayHandler_GetHolidays_UpstreamError(t *testing.T) {
+	var paths []string
+	handler := newHolidayHandler(t, "Sweden", &paths, http.StatusInternalServerError, `{"message":"oops"}`)
+	rr := getHolidays(handler, "?year=2025")
+	if rr.Code != http.StatusBadGateway {
+		t.Errorf("Expected status %d, got %d", http.StatusBadGateway, rr.Code)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil || body["message"] == "" {
+		t.Errorf("Expected a JSON error message, got %v (err: %v)", body, err)
+	}
+
+	handler = newHolidayHandler(t, "Norway", &paths, http.StatusInternalServerError, `{"message":"oops"}`)
+	rr = getHolidays(handler, "?year=2025")
+	var holidays []services.Holiday
+	if rr.Code != http.StatusOK || json.NewDecoder(rr.Body).Decode(&holidays) != nil || len(holidays) != len(services.NorwegianHolidays(2025)) {
+		t.Errorf("Expected the embedded Norwegian holidays, got %d with %+v", rr.Code, holidays)
+	}
+}
+
+func TestHolidayHandler_GetHolidays_Errors(t *testing.T) {
+	var paths []string
+	tests := []struct {
+		name    string
+		country string
+		query   string
+	}{
+		{"YearNotANumber", "Norway", "?year=next"},
+		{"YearTooFarAhead", "Norway", "?year=" + strconv.Itoa(time.Now().Year()+11)},
+		{"NoCountry", "", "?year=2025"},
+		{"UnknownCountry", "Atlantis", "?year=2025"},
+	}
+	for _, tt := range tests {
+		rr := getHolidays(newHolidayHandler(t, tt.country, &paths, http.StatusOK, `[]`), tt.query)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", tt.name, http.StatusBadRequest, rr.Code)
+		}
+	}
+	if len(paths) != 0 {
+		t.Errorf("Expected no upstream requests for invalid input, got %v", paths)
+	}
+}
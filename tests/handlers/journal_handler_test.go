@@ -11,11 +11,26 @@
  *  - TestJournalHandler_UpdateJournal      - Tests updating an existing journal entry.
  *  - TestJournalHandler_DeleteJournal      - Tests deleting a journal entry.
  *  - TestJournalHandler_GetAllJournals     - Tests retrieving all journal entries for a user.
+ *  - TestJournalHandler_GetAllJournals_ETag - Tests 304 for an unchanged list, a new ETag after a write, and HEAD.
+ *  - TestJournalHandler_SearchJournals     - Tests searching journal entries by text and date range.
+ *  - TestJournalHandler_CreateJournal_Conflict - Tests that a second journal for the same date returns 409.
+ *  - TestJournalHandler_CreateJournal_Upsert   - Tests that ?upsert=true overwrites the journal for that date.
+ *  - TestJournalHandler_ExportJournals         - Tests download headers per format and JSON errors for bad input.
+ *  - TestJournalHandler_InvalidMood            - Tests that an unknown mood is rejected with 400.
+ *  - TestJournalHandler_GetJournalStats        - Tests the monthly statistics and the rejection of a malformed month.
+ *  - TestJournalHandler_ValidationErrors       - Tests the field-level 400 payload for empty or overly long content.
+ *  - TestJournalHandler_DateErrors             - Tests the field-level 400 payload for future or malformed dates and date ranges.
+ *  - TestJournalHandler_RepositoryErrors       - Tests 404 for getting, updating or deleting a missing journal and 503 while the database is unavailable.
+ *  - TestJournalHandler_TrashAndRestore        - Tests a deleted journal moves from the list to the trash and back on restore, and 409 for a live journal.
+ *  - TestJournalHandler_Attachments            - Tests image uploads, type and size rejection, ownership checks, listing,
+ *                                                downloading and deleting attachments, and that images are stored privately.
+ *  - TestJournalHandler_ImportJournals         - Tests JSON and CSV imports with merge, and 400, 413 and 415 for bad files, too many entries, large bodies and other types.
  *
  *  @dependencies
  *  - mocks.NewMockJournalService: Mock implementation of JournalService for testing.
+ *  - services.NewJournalService, mocks.NewMockJournalRepository: The real service, where its validation is tested.
  *  - httptest: Provides utilities for testing HTTP handlers.
- *  - context.WithValue: Adds user-specific context values for testing purposes.
+ *  - middleware.WithUserEmail: Adds the authenticated user email to the request context.
  *  - encoding/json: Handles JSON marshalling and unmarshalling.
  *
  *  @behaviors
@@ -44,11 +59,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
 	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
 	"proh2052-group6/pkg/models"
 	"proh2052-group6/tests/mocks"
 )
@@ -74,7 +95,7 @@ func TestJournalHandler_CreateJournal(t *testing.T) {
 
 	// Inject userEmail into context
 	userEmail := "test@example.com"
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create ResponseRecorder to capture response
@@ -137,7 +158,7 @@ func TestJournalHandler_GetJournal(t *testing.T) {
 	}
 
 	// Inject userEmail into context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create ResponseRecorder
@@ -197,7 +218,7 @@ func TestJournalHandler_UpdateJournal(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	// Set the userEmail in the context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create a ResponseRecorder to record the response
@@ -247,7 +268,7 @@ func TestJournalHandler_DeleteJournal(t *testing.T) {
 	}
 
 	// Set the userEmail in the context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create a ResponseRecorder to record the response
@@ -298,7 +319,7 @@ func TestJournalHandler_GetAllJournals(t *testing.T) {
 		t.Fatal(err)
 	}
 	// Set the userEmail in the context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create a ResponseRecorder to record the response
@@ -323,3 +344,639 @@ func TestJournalHandler_GetAllJournals(t *testing.T) {
 		t.Errorf("Expected 2 journals, got %d", len(response))
 	}
 }
+
+func TestJournalHandler_GetAllJournals_ETag(t *testing.T) {
+	journalHandler := handlers.NewJournalHandler(services.NewJournalService(mocks.NewMockJournalRepository(), nil))
+	postJournal(t, journalHandler, "/api/journal/save", "test@example.com", models.Journal{Date: "2024-05-01", Content: "Dear diary"})
+
+	list := func(method, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/journals", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		journalHandler.GetAllJournals(rr, req)
+		return rr
+	}
+
+	first := list("GET", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d with %q", first.Code, etag)
+	}
+	if rr := list("GET", etag); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 || rr.Header().Get("ETag") != etag {
+		t.Errorf("Expected 304 without a body for an unchanged list, got %d with %q", rr.Code, rr.Body.String())
+	}
+	if rr := list("GET", `"other", W/`+etag); rr.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for a weak ETag in a list, got %d", rr.Code)
+	}
+
+	head := list("HEAD", "")
+	if head.Code != http.StatusOK || head.Body.Len() != 0 || head.Header().Get("ETag") != etag ||
+		head.Header().Get("Content-Length") != strconv.Itoa(first.Body.Len()) {
+		t.Errorf("Expected the headers of the GET response only, got %d %v with %q", head.Code, head.Header(), head.Body.String())
+	}
+
+	postJournal(t, journalHandler, "/api/journal/save", "test@example.com", models.Journal{Date: "2024-05-02", Content: "Another day"})
+	rr := list("GET", etag)
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+		t.Errorf("Expected a write to change the ETag, got %d with %q", rr.Code, rr.Header().Get("ETag"))
+	}
+	var journals []models.Journal
+	if err := json.NewDecoder(rr.Body).Decode(&journals); err != nil || len(journals) != 2 {
+		t.Errorf("Expected both journals after the write, got %+v (err: %v)", journals, err)
+	}
+}
+
+func TestJournalHandler_SearchJournals(t *testing.T) {
+	mockJournalService := mocks.NewMockJournalService()
+	journalHandler := handlers.NewJournalHandler(mockJournalService)
+
+	userEmail := "test@example.com"
+	mockJournalService.Journals["journal1"] = &models.Journal{JournalID: "journal1", Email: userEmail, Date: "2023-10-01", Content: "Went hiking"}
+	mockJournalService.Journals["journal2"] = &models.Journal{JournalID: "journal2", Email: userEmail, Date: "2023-10-05", Content: "Rainy day"}
+	mockJournalService.Journals["journal3"] = &models.Journal{JournalID: "journal3", Email: userEmail, Date: "2023-10-09", Content: "More hiking"}
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{"text match", "/api/journals/search?q=HIKING", http.StatusOK, []string{"journal3", "journal1"}},
+		{"dates only", "/api/journals/search?from=2023-10-02&to=2023-10-09", http.StatusOK, []string{"journal3", "journal2"}},
+		{"limit", "/api/journals/search?limit=1", http.StatusOK, []string{"journal3"}},
+		{"invalid date", "/api/journals/search?from=10/01/2023", http.StatusBadRequest, nil},
+		{"invalid limit", "/api/journals/search?limit=zero", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(journalHandler.SearchJournals).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var journals []models.Journal
+			if err := json.Unmarshal(rr.Body.Bytes(), &journals); err != nil {
+				t.Fatalf("Failed to parse response body: %v", err)
+			}
+			if len(journals) != len(tt.wantIDs) {
+				t.Fatalf("Expected %d journals, got %d", len(tt.wantIDs), len(journals))
+			}
+			for i, id := range tt.wantIDs {
+				if journals[i].JournalID != id {
+					t.Errorf("Expected journal %d to be %s, got %s", i, id, journals[i].JournalID)
+				}
+			}
+		})
+	}
+}
+
+// postJournal sends a journal to the CreateJournal handler for the given user.
+func postJournal(t *testing.T, journalHandler *handlers.JournalHandler, url, userEmail string, journal models.Journal) *httptest.ResponseRecorder {
+	t.Helper()
+	requestBody, _ := json.Marshal(journal)
+	req := httptest.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(journalHandler.CreateJournal).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestJournalHandler_CreateJournal_Conflict(t *testing.T) {
+	mockJournalService := mocks.NewMockJournalService()
+	journalHandler := handlers.NewJournalHandler(mockJournalService)
+	userEmail := "test@example.com"
+
+	rr := postJournal(t, journalHandler, "/api/journal/save", userEmail, models.Journal{Date: "2023-10-15", Content: "First entry"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("First journal: got status %v want %v", rr.Code, http.StatusOK)
+	}
+
+	rr = postJournal(t, journalHandler, "/api/journal/save", userEmail, models.Journal{Date: "2023-10-15", Content: "Second entry"})
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Second journal: got status %v want %v", rr.Code, http.StatusConflict)
+	}
+	if len(mockJournalService.Journals) != 1 {
+		t.Errorf("Expected 1 journal to be stored, got %d", len(mockJournalService.Journals))
+	}
+}
+
+func TestJournalHandler_CreateJournal_Upsert(t *testing.T) {
+	mockJournalService := mocks.NewMockJournalService()
+	journalHandler := handlers.NewJournalHandler(mockJournalService)
+	userEmail := "test@example.com"
+
+	rr := postJournal(t, journalHandler, "/api/journal/save", userEmail, models.Journal{Date: "2023-10-15", Content: "First entry"})
+	var created map[string]string
+	json.Unmarshal(rr.Body.Bytes(), &created)
+
+	rr = postJournal(t, journalHandler, "/api/journal/save?upsert=true", userEmail, models.Journal{Date: "2023-10-15", Content: "Rewritten entry"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Upsert: got status %v want %v", rr.Code, http.StatusOK)
+	}
+
+	var response map[string]string
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if response["journalID"] != created["journalID"] {
+		t.Errorf("Expected upsert to keep journalID %q, got %q", created["journalID"], response["journalID"])
+	}
+	if len(mockJournalService.Journals) != 1 {
+		t.Fatalf("Expected 1 journal to be stored, got %d", len(mockJournalService.Journals))
+	}
+	if journal := mockJournalService.Journals[created["journalID"]]; journal.Content != "Rewritten entry" {
+		t.Errorf("Expected content to be overwritten, got %q", journal.Content)
+	}
+}
+
+func TestJournalHandler_ExportJournals(t *testing.T) {
+	mockJournalService := mocks.NewMockJournalService()
+	journalHandler := handlers.NewJournalHandler(mockJournalService)
+
+	userEmail := "test@example.com"
+	mockJournalService.Journals["journal1"] = &models.Journal{JournalID: "journal1", Email: userEmail, Date: "2023-10-05", Content: "Rainy day"}
+	mockJournalService.Journals["journal2"] = &models.Journal{JournalID: "journal2", Email: userEmail, Date: "2023-10-01", Content: "Went hiking"}
+
+	tests := []struct {
+		name            string
+		url             string
+		wantStatus      int
+		wantType        string
+		wantDisposition string
+	}{
+		{"default json", "/api/journals/export", http.StatusOK, "application/json", `attachment; filename="journals.json"`},
+		{"markdown", "/api/journals/export?format=markdown&from=2023-10-01", http.StatusOK, "text/markdown; charset=utf-8", `attachment; filename="journals.md"`},
+		{"unknown format", "/api/journals/export?format=pdf", http.StatusBadRequest, "application/json", ""},
+		{"invalid date", "/api/journals/export?to=2023/10/01", http.StatusBadRequest, "application/json", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(journalHandler.ExportJournals).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, tt.wantStatus)
+			}
+			if got := rr.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Expected Content-Type %q, got %q", tt.wantType, got)
+			}
+			if got := rr.Header().Get("Content-Disposition"); got != tt.wantDisposition {
+				t.Errorf("Expected Content-Disposition %q, got %q", tt.wantDisposition, got)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/api/journals/export?format=json", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(journalHandler.ExportJournals).ServeHTTP(rr, req)
+
+	var journals []models.Journal
+	if err := json.Unmarshal(rr.Body.Bytes(), &journals); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if len(journals) != 2 || journals[0].JournalID != "journal2" || journals[1].JournalID != "journal1" {
+		t.Errorf("Expected journals oldest first, got %+v", journals)
+	}
+}
+
+func TestJournalHandler_InvalidMood(t *testing.T) {
+	journalHandler := handlers.NewJournalHandler(services.NewJournalService(mocks.NewMockJournalRepository(), nil))
+
+	rr := postJournal(t, journalHandler, "/api/journal/save", "test@example.com", models.Journal{Date: "2024-05-01", Content: "Entry", Mood: "ecstatic"})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Unknown mood: got status %v want %v", rr.Code, http.StatusBadRequest)
+	}
+
+	rr = postJournal(t, journalHandler, "/api/journal/save", "test@example.com", models.Journal{Date: "2024-05-01", Content: "Entry", Mood: "Good"})
+	if rr.Code != http.StatusOK {
+		t.Errorf("Valid mood: got status %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
+
+func TestJournalHandler_GetJournalStats(t *testing.T) {
+	journalHandler := handlers.NewJournalHandler(services.NewJournalService(mocks.NewMockJournalRepository(), nil))
+	for _, journal := range []models.Journal{
+		{Date: "2024-05-01", Content: "Entry", Mood: "good"},
+		{Date: "2024-05-02", Content: "Entry", Mood: "good"},
+		{Date: "2024-05-04", Content: "Entry", Mood: "bad"},
+	} {
+		postJournal(t, journalHandler, "/api/journal/save", "test@example.com", journal)
+	}
+
+	getStats := func(month string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/journals/stats?month="+month, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(journalHandler.GetJournalStats).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := getStats("2024-05")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Got status %v want %v", rr.Code, http.StatusOK)
+	}
+	var stats models.JournalStats
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if stats.EntryCount != 3 || stats.MoodDistribution["good"] != 2 || stats.MoodDistribution["bad"] != 1 || stats.LongestStreak != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	if rr := getStats("May"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Invalid month: got status %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestJournalHandler_ValidationErrors(t *testing.T) {
+	journalHandler := handlers.NewJournalHandler(services.NewJournalService(mocks.NewMockJournalRepository(), nil))
+
+	tests := []struct {
+		name    string
+		url     string
+		content string
+		message string
+	}{
+		{"empty content", "/api/journal/save", " \n ", "required"},
+		{"too long content", "/api/journal/save", strings.Repeat("a", 20001), "must be at most 20000 characters"},
+		{"empty content on upsert", "/api/journal/save?upsert=true", "", "required"},
+	}
+	for _, tt := range tests {
+		rr := postJournal(t, journalHandler, tt.url, "test@example.com", models.Journal{Date: "2024-05-01", Content: tt.content})
+		fieldErrors := decodeValidationErrors(t, rr)
+		if len(fieldErrors) != 1 || fieldErrors["content"] != tt.message {
+			t.Errorf("%s: expected content %q, got %v", tt.name, tt.message, fieldErrors)
+		}
+	}
+}
+
+func TestJournalHandler_DateErrors(t *testing.T) {
+	journalHandler := handlers.NewJournalHandler(services.NewJournalService(mocks.NewMockJournalRepository(), nil))
+
+	for date, reason := range map[string]string{"2999-01-01": "must not be in the future", "01.05.2024": "must be a date in YYYY-MM-DD format"} {
+		rr := postJournal(t, journalHandler, "/api/journal/save", "test@example.com", models.Journal{Date: date, Content: "Entry"})
+		if fieldErrors := decodeValidationErrors(t, rr); len(fieldErrors) != 1 || fieldErrors["date"] != reason {
+			t.Errorf("%s: expected date %q, got %v", date, reason, fieldErrors)
+		}
+	}
+
+	// Query parameters are reported by name, on search and export alike.
+	for _, tt := range []struct {
+		handler http.HandlerFunc
+		url     string
+		field   string
+		reason  string
+	}{
+		{journalHandler.SearchJournals, "/api/journals/search?from=yesterday", "from", "must be a date in YYYY-MM-DD format"},
+		{journalHandler.SearchJournals, "/api/journals/search?from=2024-05-02&to=2024-05-01", "from", "must not be after to"},
+		{journalHandler.ExportJournals, "/api/journals/export?to=2024-5-1", "to", "must be a date in YYYY-MM-DD format"},
+	} {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rr, req)
+		if fieldErrors := decodeValidationErrors(t, rr); len(fieldErrors) != 1 || fieldErrors[tt.field] != tt.reason {
+			t.Errorf("%s: expected %s %q, got %v", tt.url, tt.field, tt.reason, fieldErrors)
+		}
+	}
+}
+
+func TestJournalHandler_RepositoryErrors(t *testing.T) {
+	journalRepo := mocks.NewMockJournalRepository()
+	journalHandler := handlers.NewJournalHandler(services.NewJournalService(journalRepo, nil))
+
+	send := func(handler http.HandlerFunc, url string) int {
+		req := httptest.NewRequest("GET", url, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if status := send(journalHandler.GetJournal, "/api/journals/get?journalID=missing"); status != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing journal, got %d", http.StatusNotFound, status)
+	}
+
+	// Updating or deleting a missing journal, or another user's, is not a silent success.
+	other := &models.Journal{Email: "other@example.com", Date: "2024-05-01", Content: "Private thoughts"}
+	if err := journalRepo.CreateJournal(context.Background(), other); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	for _, journalID := range []string{"missing", other.JournalID} {
+		if status := send(journalHandler.DeleteJournal, "/api/journals?journalID="+journalID); status != http.StatusNotFound {
+			t.Errorf("Expected status %d for deleting %s, got %d", http.StatusNotFound, journalID, status)
+		}
+
+		req := httptest.NewRequest("PUT", "/api/journals?journalID="+journalID, strings.NewReader(`{"date": "2024-05-01", "content": "Overwritten"}`))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		journalHandler.UpdateJournal(rr, req)
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d for updating %s, got %d", http.StatusNotFound, journalID, rr.Code)
+		}
+	}
+	if stored := journalRepo.Journals[other.JournalID]; stored == nil || stored.Content != "Private thoughts" || len(journalRepo.Journals) != 1 {
+		t.Errorf("Expected only the other user's journal to be stored, unchanged, got %v", journalRepo.Journals)
+	}
+
+	journalRepo.Err = repositories.ErrUnavailable
+	for name, handler := range map[string]http.HandlerFunc{
+		"GetJournal":      journalHandler.GetJournal,
+		"GetAllJournals":  journalHandler.GetAllJournals,
+		"GetJournalStats": journalHandler.GetJournalStats,
+		"GetJournalTrash": journalHandler.GetJournalTrash,
+	} {
+		if status := send(handler, "/api/journals?journalID=missing"); status != http.StatusServiceUnavailable {
+			t.Errorf("Expected status %d for %s during an outage, got %d", http.StatusServiceUnavailable, name, status)
+		}
+	}
+}
+
+func TestJournalHandler_TrashAndRestore(t *testing.T) {
+	journalHandler := handlers.NewJournalHandler(services.NewJournalService(mocks.NewMockJournalRepository(), nil))
+
+	send := func(handler http.HandlerFunc, method, url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	listed := func(handler http.HandlerFunc, url string) []models.Journal {
+		rr := send(handler, "GET", url)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status %d for %s, got %d", http.StatusOK, url, rr.Code)
+		}
+		var journals []models.Journal
+		if err := json.NewDecoder(rr.Body).Decode(&journals); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return journals
+	}
+
+	rr := postJournal(t, journalHandler, "/api/journal/save", "test@example.com", models.Journal{Date: "2024-05-01", Content: "Dear diary"})
+	var saved handlers.JournalSavedResponse
+	json.NewDecoder(rr.Body).Decode(&saved)
+
+	if rr := send(journalHandler.DeleteJournal, "DELETE", "/api/journal/delete?journalID="+saved.JournalID); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d deleting the journal, got %d", http.StatusOK, rr.Code)
+	}
+	if journals := listed(journalHandler.GetAllJournals, "/api/journals"); len(journals) != 0 {
+		t.Errorf("Expected the deleted journal to leave the list, got %+v", journals)
+	}
+	if trash := listed(journalHandler.GetJournalTrash, "/api/journal/trash"); len(trash) != 1 || trash[0].JournalID != saved.JournalID || trash[0].DeletedAt == nil {
+		t.Errorf("Expected the deleted journal in the trash, got %+v", trash)
+	}
+
+	if rr := send(journalHandler.RestoreJournal, "POST", "/api/journal/restore"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without a journalID, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if rr := send(journalHandler.RestoreJournal, "POST", "/api/journal/restore?journalID=missing"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d restoring a missing journal, got %d", http.StatusNotFound, rr.Code)
+	}
+	rr = send(journalHandler.RestoreJournal, "POST", "/api/journal/restore?journalID="+saved.JournalID)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d restoring the journal, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var restored models.Journal
+	if err := json.NewDecoder(rr.Body).Decode(&restored); err != nil || restored.Content != "Dear diary" || restored.DeletedAt != nil {
+		t.Errorf("Expected the restored journal in the response, got %+v (%v)", restored, err)
+	}
+	if journals := listed(journalHandler.GetAllJournals, "/api/journals"); len(journals) != 1 {
+		t.Errorf("Expected the restored journal back in the list, got %+v", journals)
+	}
+	if trash := listed(journalHandler.GetJournalTrash, "/api/journal/trash"); len(trash) != 0 {
+		t.Errorf("Expected an empty trash after restore, got %+v", trash)
+	}
+	if rr := send(journalHandler.RestoreJournal, "POST", "/api/journal/restore?journalID="+saved.JournalID); rr.Code != http.StatusConflict {
+		t.Errorf("Expected status %d restoring a journal not in the trash, got %d", http.StatusConflict, rr.Code)
+	}
+}
+
+// newAttachmentRequest builds a multipart POST to /api/journal/attachments uploading content to journalID.
+func newAttachmentRequest(t *testing.T, userEmail, journalID string, content []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("attachment", "photo")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/journal/attachments?journalID="+journalID, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+}
+
+func TestJournalHandler_Attachments(t *testing.T) {
+	journalRepo := mocks.NewMockJournalRepository()
+	storage := mocks.NewMockStorageService()
+	journalService := services.NewJournalService(journalRepo, nil)
+	journalService.(*services.JournalService).Storage = storage
+	journalHandler := handlers.NewJournalHandler(journalService)
+
+	journal := &models.Journal{Email: "user@example.com", Date: "2024-05-01", Content: "A day at the beach"}
+	if err := journalService.CreateJournal(context.Background(), journal); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	png := []byte("\x89PNG\r\n\x1a\n" + "image data")
+	jpeg := []byte("\xFF\xD8\xFF" + "image data")
+	webp := []byte("RIFF\x00\x00\x00\x00WEBPVP8 " + "image data")
+
+	tests := []struct {
+		name       string
+		userEmail  string
+		journalID  string
+		content    []byte
+		statusCode int
+	}{
+		{"png", "user@example.com", journal.JournalID, png, http.StatusOK},
+		{"jpeg", "user@example.com", journal.JournalID, jpeg, http.StatusOK},
+		{"webp", "user@example.com", journal.JournalID, webp, http.StatusOK},
+		{"text", "user@example.com", journal.JournalID, []byte("just some text"), http.StatusUnsupportedMediaType},
+		{"gif", "user@example.com", journal.JournalID, []byte("GIF89a" + "image data"), http.StatusUnsupportedMediaType},
+		{"too large", "user@example.com", journal.JournalID, append(png, make([]byte, services.MaxAttachmentSize)...), http.StatusRequestEntityTooLarge},
+		{"another user's journal", "other@example.com", journal.JournalID, png, http.StatusNotFound},
+		{"missing journal", "user@example.com", "missing", png, http.StatusNotFound},
+		{"missing journalID", "user@example.com", "", png, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(journalHandler.UploadAttachment).ServeHTTP(rr, newAttachmentRequest(t, tt.userEmail, tt.journalID, tt.content))
+		if rr.Code != tt.statusCode {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.statusCode, rr.Code, rr.Body.String())
+		}
+	}
+	if len(storage.Objects) != 3 {
+		t.Fatalf("Expected only the 3 accepted images to be stored, got %d", len(storage.Objects))
+	}
+
+	// The journal lists the attachments with their metadata.
+	req := httptest.NewRequest("GET", "/api/journal?journalID="+journal.JournalID, nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(journalHandler.GetJournal).ServeHTTP(rr, req)
+	var got models.Journal
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode journal: %v", err)
+	}
+	wantTypes := []string{"image/png", "image/jpeg", "image/webp"}
+	if len(got.Attachments) != len(wantTypes) {
+		t.Fatalf("Expected %d attachments, got %+v", len(wantTypes), got.Attachments)
+	}
+	for i, attachment := range got.Attachments {
+		if attachment.ContentType != wantTypes[i] || attachment.ID == "" || attachment.Size == 0 {
+			t.Errorf("Unexpected attachment %d: %+v", i, attachment)
+		}
+		if attachment.URL != services.AttachmentURL(journal.JournalID, attachment.ID) {
+			t.Errorf("Expected attachment %s to be served by the API, got %s", attachment.ID, attachment.URL)
+		}
+	}
+	if strings.Contains(rr.Body.String(), mocks.MockStorageBaseURL) {
+		t.Errorf("Expected no storage URL in the journal, got %s", rr.Body.String())
+	}
+	for objectURL, object := range storage.Objects {
+		if !object.Private {
+			t.Errorf("Expected %s to be stored privately", objectURL)
+		}
+	}
+
+	// Only the owner can download an attachment, from its URL.
+	downloadAttachment := func(userEmail, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(journalHandler.GetAttachment).ServeHTTP(rr, req)
+		return rr
+	}
+	downloaded := downloadAttachment("user@example.com", got.Attachments[1].URL)
+	if downloaded.Code != http.StatusOK || !bytes.Equal(downloaded.Body.Bytes(), jpeg) {
+		t.Errorf("Expected the JPEG image, got status %d: %q", downloaded.Code, downloaded.Body.String())
+	}
+	if contentType, cache := downloaded.Header().Get("Content-Type"), downloaded.Header().Get("Cache-Control"); contentType != "image/jpeg" || !strings.HasPrefix(cache, "private") {
+		t.Errorf("Expected a privately cacheable image/jpeg, got %q and %q", contentType, cache)
+	}
+	if rr := downloadAttachment("other@example.com", got.Attachments[1].URL); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 downloading another user's attachment, got %d", rr.Code)
+	}
+	if rr := downloadAttachment("user@example.com", "/api/journal/attachments?journalID="+journal.JournalID); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without an attachmentID, got %d", rr.Code)
+	}
+
+	// Only the owner can delete an attachment, and the image is deleted from storage.
+	removed := got.Attachments[0]
+	removedObject := journalRepo.Journals[journal.JournalID].Attachments[0].Object
+	if _, stored := storage.Objects[removedObject]; !stored {
+		t.Fatalf("Expected the journal to keep the storage URL of attachment %s, got %q", removed.ID, removedObject)
+	}
+	deleteAttachment := func(userEmail, attachmentID string) int {
+		req := httptest.NewRequest("DELETE", "/api/journal/attachments?journalID="+journal.JournalID+"&attachmentID="+attachmentID, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(journalHandler.DeleteAttachment).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if status := deleteAttachment("other@example.com", removed.ID); status != http.StatusNotFound {
+		t.Errorf("Expected 404 deleting another user's attachment, got %d", status)
+	}
+	if status := deleteAttachment("user@example.com", removed.ID); status != http.StatusOK {
+		t.Fatalf("Expected 200 deleting the attachment, got %d", status)
+	}
+	if status := deleteAttachment("user@example.com", removed.ID); status != http.StatusNotFound {
+		t.Errorf("Expected 404 deleting the attachment twice, got %d", status)
+	}
+	if _, stored := storage.Objects[removedObject]; stored || len(storage.Objects) != 2 {
+		t.Errorf("Expected the deleted image to be removed from storage, got %+v", storage.Objects)
+	}
+	if attachments := journalRepo.Journals[journal.JournalID].Attachments; len(attachments) != 2 {
+		t.Errorf("Expected 2 attachments left, got %+v", attachments)
+	}
+}
+
+func TestJournalHandler_ImportJournals(t *testing.T) {
+	journalRepo := mocks.NewMockJournalRepository()
+	journalHandler := handlers.NewJournalHandler(services.NewJournalService(journalRepo, nil))
+	postJournal(t, journalHandler, "/api/journal/save", "user@example.com", models.Journal{Date: "2024-05-01", Content: "Existing"})
+
+	importJournals := func(target, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(journalHandler.ImportJournals).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := importJournals("/api/journals/import", "application/json; charset=utf-8",
+		`[{"date": "2024-05-01", "content": "Duplicate"}, {"date": "2024-05-02", "content": "New"}, {"date": "soon", "content": "Bad date"}]`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var result models.JournalImportResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil || result.Imported != 1 || result.Skipped != 2 || len(result.Rows) != 3 {
+		t.Errorf("Expected 1 imported and 2 skipped rows, got %+v (err: %v)", result, err)
+	}
+
+	rr = importJournals("/api/journals/import?merge=true", "text/csv", "date,content\n2024-05-01,Appended\n2024-05-03,From CSV\n")
+	result = models.JournalImportResult{}
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil || rr.Code != http.StatusOK || result.Merged != 1 || result.Imported != 1 {
+		t.Errorf("Expected 1 merged and 1 imported CSV row, got %d %+v (err: %v)", rr.Code, result, err)
+	}
+	if journal, _ := journalRepo.GetJournalByDate(context.Background(), "user@example.com", "2024-05-01"); journal == nil || journal.Content != "Existing\n\nAppended" {
+		t.Errorf("Expected the CSV row merged into the existing journal, got %+v", journal)
+	}
+
+	if rr := importJournals("/api/journals/import", "text/plain", "2024-05-04 Plain text"); rr.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415 for a plain text body, got %d", rr.Code)
+	}
+	if rr := importJournals("/api/journals/import", "application/json", `{"date": "2024-05-04"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a JSON object instead of an array, got %d", rr.Code)
+	}
+
+	var tooMany strings.Builder
+	tooMany.WriteString("date,content\n")
+	for i := 0; i <= services.MaxJournalImportEntries; i++ {
+		tooMany.WriteString("2024-05-04,Again\n")
+	}
+	rr = importJournals("/api/journals/import", "text/csv", tooMany.String())
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), services.ErrTooManyImportEntries.Error()) {
+		t.Errorf("Expected 400 for too many entries, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// A body over the limit is rejected whether or not its length is announced.
+	tooLarge := `[{"date": "2024-05-04", "content": "` + strings.Repeat("x", services.MaxJournalImportSize) + `"}]`
+	req := httptest.NewRequest("POST", "/api/journals/import", strings.NewReader(tooLarge))
+	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = -1
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(journalHandler.ImportJournals).ServeHTTP(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a body read past the limit, got %d", rr.Code)
+	}
+	if rr := importJournals("/api/journals/import", "application/json", tooLarge); rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a body announced over the limit, got %d", rr.Code)
+	}
+	if len(journalRepo.Journals) != 3 {
+		t.Errorf("Expected only the 3 imported journals stored, got %d", len(journalRepo.Journals))
+	}
+}
@@ -0,0 +1,165 @@
+/**
+ *  Locale Tests validate the middleware created by NewLocale and the translated responses of the
+ *  handlers: the language is taken from the lang query parameter, then Accept-Language, then the
+ *  country of the authenticated user, and English is the fallback.
+ *
+ *  @file       locale_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestLocale_SuccessMessage     - Tests that a friend request is answered in the requested language.
+ *  - TestLocale_UserErrors         - Tests that UserService errors are translated, but wrapped errors are not.
+ *  - TestLocale_UserCountry        - Tests the fallback to the language of the authenticated user's country.
+ *
+ *  @dependencies
+ *  - middleware.NewLocale, middleware.NewJwtAuthMiddleware, middleware.NewUserLocale: The middleware under test.
+ *  - mocks: Mock repositories and services behind the handlers.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/i18n"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// responseMessage returns the message of a JSON response.
+func responseMessage(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+	var response map[string]string
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response body %q: %v", rr.Body.String(), err)
+	}
+	return response["message"]
+}
+
+func TestLocale_SuccessMessage(t *testing.T) {
+	tests := []struct {
+		name, target, acceptLanguage, want string
+	}{
+		{"Accept-Language nb", "/api/friends/add", "nb", "Venneforespørsel sendt"},
+		{"Accept-Language with regions and q-values", "/api/friends/add", "nb-NO,nb;q=0.9,en;q=0.5", "Venneforespørsel sendt"},
+		{"Norwegian alias", "/api/friends/add", "no", "Venneforespørsel sendt"},
+		{"English", "/api/friends/add", "en-US", "Friend request sent"},
+		{"unsupported language", "/api/friends/add", "de-DE", "Friend request sent"},
+		{"no language", "/api/friends/add", "", "Friend request sent"},
+		{"lang parameter wins", "/api/friends/add?lang=nb", "en", "Venneforespørsel sendt"},
+		{"unsupported lang parameter", "/api/friends/add?lang=xx", "nb", "Venneforespørsel sendt"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+				"user1@example.com": {Email: "user1@example.com", Username: "user1"},
+				"user2@example.com": {Email: "user2@example.com", Username: "user2"},
+			})
+			friendService := services.NewFriendService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), &mocks.MockEmailService{}, nil)
+			handler := middleware.NewLocale()(http.HandlerFunc(handlers.NewFriendHandler(friendService).SendFriendRequest))
+
+			req := httptest.NewRequest("POST", test.target, strings.NewReader(`{"usernameOrEmail":"user2"}`))
+			if test.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", test.acceptLanguage)
+			}
+			req = req.WithContext(middleware.WithUserEmail(req.Context(), "user1@example.com"))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if message := responseMessage(t, rr); message != test.want {
+				t.Errorf("Expected message %q, got %q", test.want, message)
+			}
+			if vary := rr.Header().Get("Vary"); vary != "Accept-Language" {
+				t.Errorf("Expected Vary: Accept-Language, got %q", vary)
+			}
+		})
+	}
+}
+
+func TestLocale_UserErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		serviceErr error
+		want       string
+	}{
+		{"email taken", services.ErrEmailTaken, "E-postadressen er allerede registrert"},
+		{"account expired", services.ErrAccountExpired, "Kontoen ble ikke bekreftet i tide. Vennligst registrer deg på nytt"},
+		{"wrapped", fmt.Errorf("signup: %w", services.ErrEmailTaken), "signup: Email already registered"},
+		{"internal", fmt.Errorf("Failed to create user: boom"), "Intern serverfeil"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userHandler := handlers.NewUserHandler(&mocks.MockUserService{
+				SignupFunc: func(ctx context.Context, user *models.User) error { return test.serviceErr },
+			})
+			handler := middleware.NewLocale()(http.HandlerFunc(userHandler.Signup))
+
+			req := httptest.NewRequest("POST", "/api/signup", strings.NewReader(`{"email":"test@example.com"}`))
+			req.Header.Set("Accept-Language", "nb")
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if message := responseMessage(t, rr); message != test.want {
+				t.Errorf("Expected message %q, got %q", test.want, message)
+			}
+		})
+	}
+}
+
+func TestLocale_UserCountry(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"ola@example.com":  {Email: "ola@example.com", Username: "ola", Country: "Norway", IsVerified: true},
+		"hans@example.com": {Email: "hans@example.com", Username: "hans", Country: "Germany", IsVerified: true},
+	})
+	var gotLocale string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotLocale = i18n.Locale(r.Context())
+	})
+	handler := middleware.NewLocale()(middleware.NewJwtAuthMiddleware(userRepo, testJWT)(middleware.NewUserLocale(userRepo, services.UserLocale)(next)))
+
+	tests := []struct {
+		name, email, acceptLanguage, want string
+	}{
+		{"Norwegian user", "ola@example.com", "", "nb"},
+		{"Norwegian user asking for English", "ola@example.com", "en", "en"},
+		{"user from a country without a catalog", "hans@example.com", "", "en"},
+		{"user from a country without a catalog asking for Norwegian", "hans@example.com", "nb", "nb"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token, err := testJWT.GenerateJWT(test.email, 0)
+			if err != nil {
+				t.Fatalf("Failed to generate token: %v", err)
+			}
+			req := httptest.NewRequest("GET", "/api/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			if test.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", test.acceptLanguage)
+			}
+			gotLocale = ""
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if gotLocale != test.want {
+				t.Errorf("Expected locale %q, got %q", test.want, gotLocale)
+			}
+		})
+	}
+}
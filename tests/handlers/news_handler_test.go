@@ -16,12 +16,17 @@
  *  - Validates the HTTP status code (expected: 200 OK).
  *  - Ensures the response body contains the correct news data.
  *  - Simulates a real-world scenario using a mock external news API and user data.
+ *  - TestNewsHandler_FetchNews_Refresh: Cached responses are reused until refresh=true is given.
+ *  - TestNewsHandler_FetchNews_UpstreamErrors: Upstream 429 maps to 429, malformed JSON to 502.
+ *  - TestNewsHandler_FetchNews_Pagination: The page parameter is passed upstream and nextPage is returned.
+ *  - TestNewsHandler_FetchNews_Language: The language parameter picks one of the country's languages,
+ *    falling back to its primary language.
  *
  *  @example
  *  ```
  *  // Simulate fetching local news for a user
  *  req, _ := http.NewRequest("GET", "/api/news?mode=local", nil)
- *  ctx := context.WithValue(req.Context(), "userEmail", "test@example.com")
+ *  ctx := middleware.WithUserEmail(req.Context(), "test@example.com")
  *  req = req.WithContext(ctx)
  *
  *  rr := httptest.NewRecorder()
@@ -32,13 +37,15 @@
 package handlers_test
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
 	"proh2052-group6/internal/services"
 	"proh2052-group6/pkg/models"
 	"proh2052-group6/tests/mocks"
@@ -82,9 +89,9 @@ func TestNewsHandler_FetchNews(t *testing.T) {
 		UserRepo:   mockUserRepo,
 		HTTPClient: testServer.Client(),
 		NewsAPIURL: testServer.URL,
-		GetCountryAndLanguageCode: func(countryName string) (string, string, error) {
+		GetCountryLanguages: func(countryName string) (string, []string, error) {
 			// Mock implementation to return a hardcoded country and language code
-			return "testcountrycode", "en", nil
+			return "testcountrycode", []string{"en"}, nil
 		},
 	}
 
@@ -99,7 +106,7 @@ func TestNewsHandler_FetchNews(t *testing.T) {
 
 	// Set the userEmail in the request context to simulate authentication
 	userEmail := "test@example.com"
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Step 6: Create a ResponseRecorder to capture the handler's response
@@ -115,15 +122,16 @@ func TestNewsHandler_FetchNews(t *testing.T) {
 	}
 
 	// Step 9: Parse and validate the response body
-	var response []map[string]interface{}
-	err = json.NewDecoder(rr.Body).Decode(&response)
+	var page services.NewsPage
+	err = json.NewDecoder(rr.Body).Decode(&page)
 	if err != nil {
 		t.Errorf("Failed to decode response body: %v", err)
 	}
+	response := page.Items
 
 	// Verify the number of news items
 	if len(response) != 1 {
-		t.Errorf("Expected 1 news item, got %d", len(response))
+		t.Fatalf("Expected 1 news item, got %d", len(response))
 	}
 
 	// Validate the content of the news item
@@ -131,3 +139,158 @@ func TestNewsHandler_FetchNews(t *testing.T) {
 		t.Errorf("Expected news title 'Test News Title', got '%s'", response[0]["title"])
 	}
 }
+
+func TestNewsHandler_FetchNews_Refresh(t *testing.T) {
+	var calls int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "results": []map[string]interface{}{{"title": "News"}}})
+	}))
+	defer testServer.Close()
+
+	newsService := &services.NewsService{
+		UserRepo:   mocks.NewMockUserRepository(map[string]*models.User{}),
+		HTTPClient: testServer.Client(),
+		NewsAPIURL: testServer.URL,
+		CacheTTL:   time.Minute,
+	}
+	newsHandler := handlers.NewNewsHandler(newsService)
+
+	for _, url := range []string{"/api/news?q=ai", "/api/news?q=ai", "/api/news?q=ai&refresh=true"} {
+		req := httptest.NewRequest("GET", url, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(newsHandler.FetchNews).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", url, http.StatusOK, rr.Code)
+		}
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected 2 upstream calls (initial and refresh), got %d", got)
+	}
+}
+
+// serveNews runs the FetchNews handler for an authenticated request to url against a news API at apiURL.
+func serveNews(t *testing.T, apiURL, url string) *httptest.ResponseRecorder {
+	t.Helper()
+	newsHandler := handlers.NewNewsHandler(&services.NewsService{
+		UserRepo:   mocks.NewMockUserRepository(map[string]*models.User{}),
+		HTTPClient: http.DefaultClient,
+		NewsAPIURL: apiURL,
+	})
+
+	req := httptest.NewRequest("GET", url, nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(newsHandler.FetchNews).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestNewsHandler_FetchNews_UpstreamErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus int
+	}{
+		{"upstream 429", http.StatusTooManyRequests, `{"status":"error","results":{"message":"API rate limit exceeded","code":"RateLimitExceeded"}}`, http.StatusTooManyRequests},
+		{"malformed json", http.StatusOK, `{"status":"success","results":[`, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer testServer.Close()
+
+			rr := serveNews(t, testServer.URL, "/api/news")
+			if rr.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil || body["message"] == "" {
+				t.Errorf("Expected a JSON error message, got %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewsHandler_FetchNews_Pagination(t *testing.T) {
+	var gotPage string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPage = r.URL.Query().Get("page")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":   "success",
+			"results":  []map[string]interface{}{{"title": "News"}},
+			"nextPage": "token3",
+		})
+	}))
+	defer testServer.Close()
+
+	rr := serveNews(t, testServer.URL, "/api/news?page=token2")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if gotPage != "token2" {
+		t.Errorf("Expected page %q to be passed upstream, got %q", "token2", gotPage)
+	}
+
+	var page services.NewsPage
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if page.NextPage != "token3" || len(page.Items) != 1 {
+		t.Errorf("Expected 1 item and nextPage %q, got %+v", "token3", page)
+	}
+}
+
+func TestNewsHandler_FetchNews_Language(t *testing.T) {
+	var gotCountry, gotLanguage string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCountry, gotLanguage = r.URL.Query().Get("country"), r.URL.Query().Get("language")
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "results": []map[string]interface{}{}})
+	}))
+	defer testServer.Close()
+
+	newsService := &services.NewsService{
+		UserRepo: mocks.NewMockUserRepository(map[string]*models.User{
+			"test@example.com": {Email: "test@example.com", Country: "BE"},
+		}),
+		HTTPClient:          http.DefaultClient,
+		NewsAPIURL:          testServer.URL,
+		GetCountryLanguages: services.GetCountryLanguages,
+	}
+	newsHandler := handlers.NewNewsHandler(newsService)
+
+	tests := []struct {
+		url          string
+		wantCountry  string
+		wantLanguage string
+	}{
+		{"/api/news?mode=local", "be", "nl"},
+		{"/api/news?mode=local&language=fr", "be", "fr"},
+		{"/api/news?mode=local&language=DE", "be", "de"},
+		{"/api/news?mode=local&language=it", "be", "nl"},
+		{"/api/news?mode=local&country=Switzerland&language=it", "ch", "it"},
+		{"/api/news?mode=local&country=ca&language=fr", "ca", "fr"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(newsHandler.FetchNews).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.url, http.StatusOK, rr.Code, rr.Body.String())
+			continue
+		}
+		if gotCountry != tt.wantCountry || gotLanguage != tt.wantLanguage {
+			t.Errorf("%s: expected %s/%s upstream, got %s/%s", tt.url, tt.wantCountry, tt.wantLanguage, gotCountry, gotLanguage)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+/**
+ *  NotificationHandler Tests validate the WebSocket endpoint: authentication with the token
+ *  query parameter and the push of notifications published by FriendService to every open
+ *  connection of the recipient. They run a real HTTP server with httptest and dial it with
+ *  the gorilla/websocket client. They also cover listing and marking the notification inbox.
+ *
+ *  @file       notification_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestNotificationHandler_FriendRequestPushed - Tests that a friend request reaches all of the recipient's connections.
+ *  - TestNotificationHandler_Unauthorized        - Tests that the handshake is rejected without a valid token.
+ *  - TestNotificationHandler_Origin              - Tests that browsers may only connect from the allowed origins.
+ *  - TestNotificationHandler_ListNotifications   - Tests newest-first listing, the unread filter and the limit.
+ *  - TestNotificationHandler_MarkRead            - Tests marking one notification or all of them as read.
+ *  - TestNotificationHandler_DatabaseUnavailable - Tests the 503 for inbox requests when the database cannot be reached.
+ *
+ *  @dependencies
+ *  - services.NotificationHub: The hub the handler subscribes to and FriendService publishes to.
+ *  - middleware.NewWebSocketAuthMiddleware: Authenticates the WebSocket handshake.
+ *  - mocks: Mock repositories and email service for FriendService and the notification inbox.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+
+	"github.com/gorilla/websocket"
+)
+
+// subscribeSignallingHub reports each Subscribe, so tests can wait until a connection is registered.
+type subscribeSignallingHub struct {
+	services.NotificationHubInterface
+	subscribed chan string
+}
+
+func (h *subscribeSignallingHub) Subscribe(userEmail string) *services.NotificationSubscription {
+	subscription := h.NotificationHubInterface.Subscribe(userEmail)
+	h.subscribed <- userEmail
+	return subscription
+}
+
+// wsTestOrigin is the web app origin newNotificationServer accepts WebSocket connections from.
+const wsTestOrigin = "https://app.example.com"
+
+// newNotificationServer starts a server for /api/ws and returns it with a FriendService publishing to the same hub.
+func newNotificationServer(t *testing.T) (*httptest.Server, *subscribeSignallingHub, services.FriendServiceInterface) {
+	t.Helper()
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user1@example.com": {Email: "user1@example.com", Username: "user1"},
+		"user2@example.com": {Email: "user2@example.com", Username: "user2"},
+	})
+	hub := &subscribeSignallingHub{services.NewNotificationHub(), make(chan string, 10)}
+	notificationService := services.NewNotificationService(mocks.NewMockNotificationRepository(), hub)
+	friendService := services.NewFriendService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), &mocks.MockEmailService{}, notificationService)
+
+	wsAuth := middleware.NewWebSocketAuthMiddleware(userRepo, testJWT)
+	server := httptest.NewServer(wsAuth(handlers.NewNotificationHandler(notificationService, hub, []string{wsTestOrigin}).ServeWS))
+	t.Cleanup(server.Close)
+	return server, hub, friendService
+}
+
+// dialNotifications opens a WebSocket connection to server authenticated as email with the token
+// query parameter, and waits until the connection is subscribed to the hub.
+func dialNotifications(t *testing.T, server *httptest.Server, hub *subscribeSignallingHub, email string) *websocket.Conn {
+	t.Helper()
+	token, err := testJWT.GenerateJWT(email, 0)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws?token=" + token
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	select {
+	case <-hub.subscribed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Connection of %s was not subscribed to the hub", email)
+	}
+	return conn
+}
+
+func TestNotificationHandler_FriendRequestPushed(t *testing.T) {
+	server, hub, friendService := newNotificationServer(t)
+	connections := []*websocket.Conn{
+		dialNotifications(t, server, hub, "user2@example.com"),
+		dialNotifications(t, server, hub, "user2@example.com"),
+	}
+	sender := dialNotifications(t, server, hub, "user1@example.com")
+
+	if _, err := friendService.SendFriendRequest(context.Background(), "user1@example.com", "user2"); err != nil {
+		t.Fatalf("Failed to send friend request: %v", err)
+	}
+
+	for i, conn := range connections {
+		var received models.Notification
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if err := conn.ReadJSON(&received); err != nil {
+			t.Fatalf("Connection %d: expected a notification, got %v", i, err)
+		}
+		if received.Type != services.NotificationFriendRequest || received.Payload["username"] != "user1" {
+			t.Errorf("Connection %d: expected a friend_request notification from user1, got %+v", i, received)
+		}
+	}
+
+	var received models.Notification
+	sender.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if err := sender.ReadJSON(&received); err == nil {
+		t.Errorf("Expected no notification for the sender, got %+v", received)
+	}
+}
+
+func TestNotificationHandler_Unauthorized(t *testing.T) {
+	server, _, _ := newNotificationServer(t)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws"
+
+	for _, query := range []string{"", "?token=invalid"} {
+		_, resp, err := websocket.DefaultDialer.Dial(url+query, nil)
+		if err == nil {
+			t.Fatalf("%q: expected the handshake to fail", query)
+		}
+		if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%q: expected status %d, got %+v", query, http.StatusUnauthorized, resp)
+		}
+	}
+}
+
+func TestNotificationHandler_Origin(t *testing.T) {
+	server, _, _ := newNotificationServer(t)
+	token, err := testJWT.GenerateJWT("user1@example.com", 0)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws?token=" + token
+
+	tests := []struct {
+		origin     string
+		statusCode int
+	}{
+		{wsTestOrigin, http.StatusSwitchingProtocols},
+		{"HTTPS://APP.EXAMPLE.COM", http.StatusSwitchingProtocols},
+		{"https://evil.example.com", http.StatusForbidden},
+		{"http://app.example.com", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		conn, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {tt.origin}})
+		if conn != nil {
+			conn.Close()
+		}
+		if resp == nil || resp.StatusCode != tt.statusCode {
+			t.Errorf("%s: expected status %d, got %+v (%v)", tt.origin, tt.statusCode, resp, err)
+		}
+	}
+}
+
+// newInboxHandler returns a NotificationHandler whose inbox for user@example.com holds an
+// older read notification and two newer unread ones.
+func newInboxHandler(t *testing.T) (*handlers.NotificationHandler, *mocks.MockNotificationRepository) {
+	t.Helper()
+	repo := mocks.NewMockNotificationRepository()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, notification := range []models.Notification{
+		{Type: services.NotificationFriendRequest, Read: true},
+		{Type: services.NotificationFriendAccepted},
+		{Type: services.NotificationEventInvitation},
+	} {
+		notification.Email = "user@example.com"
+		notification.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		repo.CreateNotification(context.Background(), &notification)
+	}
+	hub := services.NewNotificationHub()
+	return handlers.NewNotificationHandler(services.NewNotificationService(repo, hub), hub, nil), repo
+}
+
+func TestNotificationHandler_ListNotifications(t *testing.T) {
+	handler, _ := newInboxHandler(t)
+
+	tests := []struct {
+		query string
+		types []string
+	}{
+		{"", []string{services.NotificationEventInvitation, services.NotificationFriendAccepted, services.NotificationFriendRequest}},
+		{"?unread=true", []string{services.NotificationEventInvitation, services.NotificationFriendAccepted}},
+		{"?limit=1", []string{services.NotificationEventInvitation}},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", "/api/notifications"+tt.query, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+		rr := httptest.NewRecorder()
+		handler.ListNotifications(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%q: expected status 200, got %d", tt.query, rr.Code)
+		}
+		var notifications []models.Notification
+		if err := json.NewDecoder(rr.Body).Decode(&notifications); err != nil {
+			t.Fatalf("%q: failed to decode response: %v", tt.query, err)
+		}
+		if len(notifications) != len(tt.types) {
+			t.Fatalf("%q: expected %d notifications, got %d", tt.query, len(tt.types), len(notifications))
+		}
+		for i, notification := range notifications {
+			if notification.Type != tt.types[i] {
+				t.Errorf("%q: expected notification %d to be %s, got %s", tt.query, i, tt.types[i], notification.Type)
+			}
+		}
+	}
+
+	for _, query := range []string{"?unread=maybe", "?limit=0", "?limit=abc"} {
+		req, _ := http.NewRequest("GET", "/api/notifications"+query, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+		rr := httptest.NewRecorder()
+		handler.ListNotifications(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d", query, rr.Code)
+		}
+	}
+}
+
+func TestNotificationHandler_DatabaseUnavailable(t *testing.T) {
+	handler, repo := newInboxHandler(t)
+	repo.Err = repositories.ErrUnavailable
+
+	tests := []struct {
+		name  string
+		serve http.HandlerFunc
+		body  string
+	}{
+		{"ListNotifications", handler.ListNotifications, ""},
+		{"MarkRead", handler.MarkRead, `{"id":"1"}`},
+		{"MarkAllRead", handler.MarkRead, `{"all":true}`},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", "/api/notifications", strings.NewReader(tt.body))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+		rr := httptest.NewRecorder()
+		tt.serve(rr, req)
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected status %d, got %d", tt.name, http.StatusServiceUnavailable, rr.Code)
+		}
+	}
+}
+
+func TestNotificationHandler_MarkRead(t *testing.T) {
+	handler, repo := newInboxHandler(t)
+	unread := repo.Notifications["user@example.com"][1]
+
+	tests := []struct {
+		body       string
+		statusCode int
+	}{
+		{`{"id":"` + unread.ID + `"}`, http.StatusOK},
+		{`{"id":"missing"}`, http.StatusNotFound},
+		{`{}`, http.StatusBadRequest},
+		{`not json`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", "/api/notifications/read", strings.NewReader(tt.body))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+		rr := httptest.NewRecorder()
+		handler.MarkRead(rr, req)
+		if rr.Code != tt.statusCode {
+			t.Errorf("%s: expected status %d, got %d", tt.body, tt.statusCode, rr.Code)
+		}
+	}
+	if !unread.Read {
+		t.Errorf("Expected notification %s to be marked as read", unread.ID)
+	}
+	if repo.Notifications["user@example.com"][2].Read {
+		t.Errorf("Expected other notifications to stay unread")
+	}
+
+	req, _ := http.NewRequest("POST", "/api/notifications/read", strings.NewReader(`{"all":true}`))
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+	rr := httptest.NewRecorder()
+	handler.MarkRead(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rr.Code)
+	}
+	for _, notification := range repo.Notifications["user@example.com"] {
+		if !notification.Read {
+			t.Errorf("Expected notification %s to be marked as read", notification.ID)
+		}
+	}
+}
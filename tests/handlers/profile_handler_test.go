@@ -10,9 +10,14 @@
  *  - TestProfileHandler_UpdateProfile: Tests successful updates to user profile data.
  *  - TestProfileHandler_UpdateProfile_InvalidCurrentPassword: Ensures proper handling of incorrect current passwords during updates.
  *  - TestProfileHandler_ProfileHandler_MethodNotAllowed: Validates the response for unsupported HTTP methods.
+ *  - TestProfileHandler_UpdateProfile_UsernameTaken: Ensures a username taken in another case returns 409 and UsernameLower stays in sync.
+ *  - TestProfileHandler_GetProfile_RepositoryErrors: Verifies 404 for a deleted account and 503 while the database is unavailable.
+ *  - TestProfileHandler_ChangeEmail: Verifies the status codes of the email change and confirmation endpoints.
+ *  - TestProfileHandler_Avatar: Verifies multipart uploads, size and type rejection, replacement cleanup and removal.
  *
  *  @dependencies
  *  - mocks.NewMockProfileService: A mock implementation of the ProfileServiceInterface for isolated testing.
+ *  - mocks.NewMockStorageService: An in-memory StorageService for profile picture uploads.
  *  - httptest: Used to simulate HTTP requests and responses.
  *  - handlers.NewProfileHandler: The handler under test.
  *
@@ -37,12 +42,17 @@ package handlers_test
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
 	"proh2052-group6/tests/mocks"
+	"strings"
 	"testing"
 )
 
@@ -67,7 +77,7 @@ func TestProfileHandler_GetProfile(t *testing.T) {
 	}
 
 	// Set the userEmail in the context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create a ResponseRecorder to record the response
@@ -90,17 +100,17 @@ func TestProfileHandler_GetProfile(t *testing.T) {
 	}
 
 	// Verify the response data
-	if response["Email"] != userEmail {
-		t.Errorf("Expected Email '%s', got '%s'", userEmail, response["Email"])
+	if response["email"] != userEmail {
+		t.Errorf("Expected Email '%s', got '%s'", userEmail, response["email"])
 	}
-	if response["Username"] != "testuser" {
-		t.Errorf("Expected Username 'testuser', got '%s'", response["Username"])
+	if response["username"] != "testuser" {
+		t.Errorf("Expected Username 'testuser', got '%s'", response["username"])
 	}
-	if response["Country"] != "TestCountry" {
-		t.Errorf("Expected Country 'TestCountry', got '%s'", response["Country"])
+	if response["country"] != "TestCountry" {
+		t.Errorf("Expected Country 'TestCountry', got '%s'", response["country"])
 	}
-	if response["City"] != "TestCity" {
-		t.Errorf("Expected City 'TestCity', got '%s'", response["City"])
+	if response["city"] != "TestCity" {
+		t.Errorf("Expected City 'TestCity', got '%s'", response["city"])
 	}
 }
 
@@ -136,7 +146,7 @@ func TestProfileHandler_UpdateProfile(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	// Set the userEmail in the context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create a ResponseRecorder to record the response
@@ -203,7 +213,7 @@ func TestProfileHandler_UpdateProfile_InvalidCurrentPassword(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	// Set the userEmail in the context
-	ctx := context.WithValue(req.Context(), "userEmail", userEmail)
+	ctx := middleware.WithUserEmail(req.Context(), userEmail)
 	req = req.WithContext(ctx)
 
 	// Create a ResponseRecorder to record the response
@@ -258,3 +268,176 @@ func TestProfileHandler_ProfileHandler_MethodNotAllowed(t *testing.T) {
 			status, http.StatusMethodNotAllowed)
 	}
 }
+
+func TestProfileHandler_UpdateProfile_UsernameTaken(t *testing.T) {
+	hashedPassword := mustHashPassword(t, "Password123!")
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "Alice", UsernameLower: "alice", Password: hashedPassword},
+		"bob@example.com":   {Email: "bob@example.com", Username: "bob", UsernameLower: "bob", Password: hashedPassword},
+	})
+	profileHandler := handlers.NewProfileHandler(services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT))
+
+	updateUsername := func(username string) *httptest.ResponseRecorder {
+		requestBody, _ := json.Marshal(map[string]interface{}{"Username": username, "CurrentPassword": "Password123!"})
+		req := httptest.NewRequest("PUT", "/api/profile", bytes.NewBuffer(requestBody))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "bob@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(profileHandler.ProfileHandler).ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := updateUsername("ALICE"); rr.Code != http.StatusConflict {
+		t.Errorf("Expected status %d for a taken username, got %d", http.StatusConflict, rr.Code)
+	}
+
+	if rr := updateUsername("Bobby"); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d for a free username, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if bob := userRepo.Users["bob@example.com"]; bob.Username != "Bobby" || bob.UsernameLower != "bobby" {
+		t.Errorf("Expected Bobby/bobby, got %q/%q", bob.Username, bob.UsernameLower)
+	}
+}
+
+func TestProfileHandler_GetProfile_RepositoryErrors(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{})
+	profileHandler := handlers.NewProfileHandler(services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT))
+
+	getProfile := func() int {
+		req := httptest.NewRequest("GET", "/api/profile", nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "gone@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(profileHandler.ProfileHandler).ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if status := getProfile(); status != http.StatusNotFound {
+		t.Errorf("Expected status %d for a deleted account, got %d", http.StatusNotFound, status)
+	}
+	userRepo.Err = repositories.ErrUnavailable
+	if status := getProfile(); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d during an outage, got %d", http.StatusServiceUnavailable, status)
+	}
+}
+
+func TestProfileHandler_ChangeEmail(t *testing.T) {
+	mockProfileService := mocks.NewMockProfileService()
+	mockProfileService.Profiles["alice@example.com"] = map[string]interface{}{"Email": "alice@example.com", "Password": "Password123!"}
+	mockProfileService.Profiles["bob@example.com"] = map[string]interface{}{"Email": "bob@example.com", "Password": "Password123!"}
+	profileHandler := handlers.NewProfileHandler(mockProfileService)
+
+	post := func(handler http.HandlerFunc, path string, body map[string]string) *httptest.ResponseRecorder {
+		requestBody, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", path, bytes.NewBuffer(requestBody))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "alice@example.com"))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	changeEmail := func(newEmail, password string) *httptest.ResponseRecorder {
+		return post(profileHandler.ChangeEmail, "/api/profile/change-email", map[string]string{"newEmail": newEmail, "currentPassword": password})
+	}
+	confirmEmail := func(otp string) *httptest.ResponseRecorder {
+		return post(profileHandler.ConfirmEmail, "/api/profile/confirm-email", map[string]string{"otp": otp})
+	}
+
+	if rr := confirmEmail(mocks.MockEmailChangeOTP); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without a pending change, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if rr := changeEmail("bob@example.com", "Password123!"); rr.Code != http.StatusConflict {
+		t.Errorf("Expected status %d for a taken email, got %d", http.StatusConflict, rr.Code)
+	}
+	if rr := changeEmail("alice@new.example.com", "Wrong123!"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for a wrong password, got %d", http.StatusUnauthorized, rr.Code)
+	}
+	if rr := changeEmail("alice@new.example.com", "Password123!"); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if rr := confirmEmail("000000"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a wrong OTP, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr := confirmEmail(mocks.MockEmailChangeOTP)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var response map[string]string
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if response["token"] != "token-for-alice@new.example.com" {
+		t.Errorf("Expected a token for the new email, got %v", response)
+	}
+	if _, moved := mockProfileService.Profiles["alice@new.example.com"]; !moved {
+		t.Errorf("Expected the profile to be moved to the new email")
+	}
+}
+
+// newAvatarRequest builds a multipart POST to /api/profile/avatar uploading content as fileName.
+func newAvatarRequest(t *testing.T, userEmail, fileName string, content []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("avatar", fileName)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/profile/avatar", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+}
+
+func TestProfileHandler_Avatar(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "alice"},
+	})
+	storage := mocks.NewMockStorageService()
+	profileHandler := handlers.NewProfileHandler(services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, storage, testJWT))
+	png := []byte("\x89PNG\r\n\x1a\n" + "image data")
+
+	tests := []struct {
+		name       string
+		fileName   string
+		content    []byte
+		statusCode int
+	}{
+		{"text named .png", "avatar.png", []byte("just some text"), http.StatusUnsupportedMediaType},
+		{"too large", "avatar.png", append(png, make([]byte, services.MaxAvatarSize)...), http.StatusRequestEntityTooLarge},
+		{"png", "avatar.png", png, http.StatusOK},
+		{"png without extension", "avatar", png, http.StatusOK},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(profileHandler.UploadAvatar).ServeHTTP(rr, newAvatarRequest(t, "alice@example.com", tt.fileName, tt.content))
+		if rr.Code != tt.statusCode {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.statusCode, rr.Code, rr.Body.String())
+		}
+	}
+
+	imageURL := userRepo.Users["alice@example.com"].ImageURL
+	if _, stored := storage.Objects[imageURL]; !stored || len(storage.Objects) != 1 {
+		t.Fatalf("Expected only the latest picture %q to be stored, got %+v", imageURL, storage.Objects)
+	}
+
+	req := httptest.NewRequest("POST", "/api/profile/avatar", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "alice@example.com"))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(profileHandler.UploadAvatar).ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without a file, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	req = httptest.NewRequest("DELETE", "/api/profile/avatar", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "alice@example.com"))
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(profileHandler.DeleteAvatar).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if len(storage.Objects) != 0 || userRepo.Users["alice@example.com"].ImageURL != "" {
+		t.Errorf("Expected the profile picture to be removed, got %+v", storage.Objects)
+	}
+}
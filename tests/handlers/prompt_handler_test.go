@@ -0,0 +1,87 @@
+/**
+ *  PromptHandler Tests validate the journal prompt endpoints, using the real PromptService with its
+ *  embedded prompts and a mock prompt repository.
+ *
+ *  @file       prompt_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestPromptHandler_GetAndSkip       - Tests that the prompt stays until it is skipped and the skip returns the new prompt.
+ *  - TestPromptHandler_RepositoryErrors - Tests the 503 responses while the database is unavailable.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// servePrompt sends a request to handler as test@example.com and decodes the prompt it returns.
+func servePrompt(t *testing.T, handler http.HandlerFunc, method, target string) (*httptest.ResponseRecorder, models.JournalPrompt) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var prompt models.JournalPrompt
+	if rr.Code == http.StatusOK {
+		if err := json.Unmarshal(rr.Body.Bytes(), &prompt); err != nil {
+			t.Fatalf("Failed to parse response body: %v", err)
+		}
+	}
+	return rr, prompt
+}
+
+func TestPromptHandler_GetAndSkip(t *testing.T) {
+	promptRepo := mocks.NewMockPromptRepository()
+	promptHandler := handlers.NewPromptHandler(services.NewPromptService(promptRepo, nil))
+
+	rr, first := servePrompt(t, promptHandler.GetPrompt, "GET", "/api/journal/prompt")
+	if rr.Code != http.StatusOK || first.ID == "" || first.Text == "" {
+		t.Fatalf("Expected a prompt, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if _, again := servePrompt(t, promptHandler.GetPrompt, "GET", "/api/journal/prompt"); again != first {
+		t.Errorf("Expected the same prompt until it is skipped, got %+v and %+v", first, again)
+	}
+
+	rr, next := servePrompt(t, promptHandler.SkipPrompt, "POST", "/api/journal/prompt/skip")
+	if rr.Code != http.StatusOK || next.ID == "" || next.ID == first.ID {
+		t.Fatalf("Expected another prompt after skipping, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if _, current := servePrompt(t, promptHandler.GetPrompt, "GET", "/api/journal/prompt"); current != next {
+		t.Errorf("Expected the skipped-to prompt %+v, got %+v", next, current)
+	}
+	if promptRepo.Offsets["test@example.com"].Offset != 1 {
+		t.Errorf("Expected one skip to be recorded, got %+v", promptRepo.Offsets["test@example.com"])
+	}
+}
+
+func TestPromptHandler_RepositoryErrors(t *testing.T) {
+	promptRepo := mocks.NewMockPromptRepository()
+	promptRepo.Err = repositories.ErrUnavailable
+	promptHandler := handlers.NewPromptHandler(services.NewPromptService(promptRepo, nil))
+
+	if rr, _ := servePrompt(t, promptHandler.GetPrompt, "GET", "/api/journal/prompt"); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+	if rr, _ := servePrompt(t, promptHandler.SkipPrompt, "POST", "/api/journal/prompt/skip"); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
@@ -0,0 +1,134 @@
+/**
+ *  QuoteHandler Tests validate the daily verse and favourite endpoints, using the real QuoteService
+ *  with its embedded dataset, a mock favourite repository and a mock user service.
+ *
+ *  @file       quote_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestQuoteHandler_GetDailyVerse  - Tests that the quote follows the language of the user's country, or the language parameter.
+ *  - TestQuoteHandler_Favorites      - Tests saving a favourite and listing it, and the 400, 404 and 503 responses.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newQuoteHandler returns a QuoteHandler for a user in country, storing favourites in favoriteRepo.
+func newQuoteHandler(country string, favoriteRepo *mocks.MockFavoriteRepository) *handlers.QuoteHandler {
+	userService := &mocks.MockUserService{
+		GetUserInfoFunc: func(ctx context.Context, userEmail string) (*models.UserProfile, error) {
+			return &models.UserProfile{Email: userEmail, Country: country}, nil
+		},
+	}
+	return handlers.NewQuoteHandler(services.NewQuoteService(favoriteRepo), userService)
+}
+
+// serveQuote sends a request to handler as test@example.com.
+func serveQuote(handler http.HandlerFunc, method, target string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestQuoteHandler_GetDailyVerse(t *testing.T) {
+	tests := []struct {
+		name     string
+		country  string
+		query    string
+		language string
+	}{
+		{"Language of the user's country", "Norway", "?date=2024-05-17", "no"},
+		{"Country without quotes", "Sweden", "?date=2024-05-17", "en"},
+		{"No country", "", "?date=2024-05-17", "en"},
+		{"Language parameter", "Norway", "?date=2024-05-17&language=en", "en"},
+		{"Today", "Norway", "", "no"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newQuoteHandler(tt.country, mocks.NewMockFavoriteRepository())
+			rr := serveQuote(handler.GetDailyVerse, "GET", "/api/daily-verse"+tt.query, nil)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+			}
+			var quote models.Quote
+			if err := json.Unmarshal(rr.Body.Bytes(), &quote); err != nil {
+				t.Fatalf("Failed to parse response body: %v", err)
+			}
+			if quote.Language != tt.language || quote.ID == "" || quote.Text == "" {
+				t.Errorf("Expected a quote in %q, got %+v", tt.language, quote)
+			}
+		})
+	}
+
+	handler := newQuoteHandler("Norway", mocks.NewMockFavoriteRepository())
+	if rr := serveQuote(handler.GetDailyVerse, "GET", "/api/daily-verse?date=17.05.2024", nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid date, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestQuoteHandler_Favorites(t *testing.T) {
+	favoriteRepo := mocks.NewMockFavoriteRepository()
+	handler := newQuoteHandler("Norway", favoriteRepo)
+
+	rr := serveQuote(handler.SaveFavorite, "POST", "/api/daily-verse/favorite", []byte(`{"quoteId":"no-003"}`))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	rr = serveQuote(handler.GetFavorites, "GET", "/api/daily-verse/favorites", nil)
+	var favorites []models.FavoriteQuote
+	if err := json.Unmarshal(rr.Body.Bytes(), &favorites); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if len(favorites) != 1 || favorites[0].ID != "no-003" || favorites[0].Text == "" || favorites[0].SavedAt.IsZero() {
+		t.Errorf("Expected the saved quote, got %+v", favorites)
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{"Invalid body", `{"quoteId":`, http.StatusBadRequest},
+		{"Missing quoteId", `{}`, http.StatusBadRequest},
+		{"Unknown quote", `{"quoteId":"xx-999"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rr := serveQuote(handler.SaveFavorite, "POST", "/api/daily-verse/favorite", []byte(tt.body)); rr.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedCode, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	favoriteRepo.Err = repositories.ErrUnavailable
+	if rr := serveQuote(handler.SaveFavorite, "POST", "/api/daily-verse/favorite", []byte(`{"quoteId":"en-001"}`)); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d while the database is unavailable, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+	if rr := serveQuote(handler.GetFavorites, "GET", "/api/daily-verse/favorites", nil); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d while the database is unavailable, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
@@ -0,0 +1,338 @@
+/**
+ *  Rate Limit Tests validate the per-IP rate limiting middleware created by NewRateLimiter,
+ *  including independent buckets per limiter, the 429 response, and client IP parsing, as well as
+ *  the token bucket Limiter and the stores its buckets are kept in.
+ *
+ *  @file       rate_limit_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestRateLimiter_RejectsWithRetryAfter - Tests the JSON 429 response and its Retry-After header.
+ *  - TestRateLimiter_IndependentBuckets    - Tests that limiters and clients do not share a bucket.
+ *  - TestClientIP                          - Tests X-Forwarded-For and RemoteAddr parsing edge cases.
+ *  - TestLimiter_Refill                    - Tests refilling and retry delays with a fake clock.
+ *  - TestLimiter_SlowLoad                  - Tests that a bucket being loaded only holds up requests of the same client.
+ *  - TestMemoryLimiterStore_Prune          - Tests that only refilled buckets are forgotten.
+ *  - TestRateLimiters_SharedStore          - Tests that named limiters sharing a store keep separate buckets.
+ *  - TestPersistentLimiterStore_Restart    - Tests that flushed buckets are loaded by a new store, as after a restart.
+ *  - TestPersistentLimiterStore_FailsOpen  - Tests that repository failures allow requests and keep unsaved buckets.
+ *
+ *  @dependencies
+ *  - middleware.NewRateLimiter, middleware.ClientIP
+ *  - middleware.Limiter, middleware.MemoryLimiterStore, middleware.PersistentLimiterStore
+ *  - mocks.MockRateLimitRepository: Stands in for Firestore.
+ *  - golang.org/x/time/rate: Defines the limits under test.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// okHandler answers every request with 200 OK.
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+// sendFrom sends a request through the handler as if it came from remoteAddr.
+func sendFrom(handler http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/login", nil)
+	req.RemoteAddr = remoteAddr
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestRateLimiter_RejectsWithRetryAfter(t *testing.T) {
+	handler := middleware.NewRateLimiter(rate.Every(time.Minute), 2)(okHandler)
+
+	for i := 1; i <= 2; i++ {
+		if rr := sendFrom(handler, "10.0.0.1:1234"); rr.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected status %d, got %d", i, http.StatusOK, rr.Code)
+		}
+	}
+
+	rr := sendFrom(handler, "10.0.0.1:1234")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+	retryAfter, err := strconv.Atoi(rr.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Errorf("Expected Retry-After between 1 and 60 seconds, got %q", rr.Header().Get("Retry-After"))
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil || body["message"] == "" {
+		t.Errorf("Expected JSON error message, got %q (err: %v)", rr.Body.String(), err)
+	}
+}
+
+func TestRateLimiter_IndependentBuckets(t *testing.T) {
+	signup := middleware.NewRateLimiter(rate.Every(time.Hour), 1)(okHandler)
+	login := middleware.NewRateLimiter(rate.Every(time.Hour), 1)(okHandler)
+
+	if rr := sendFrom(signup, "10.0.0.1:1234"); rr.Code != http.StatusOK {
+		t.Fatalf("Expected the first signup to pass, got %d", rr.Code)
+	}
+	if rr := sendFrom(signup, "10.0.0.1:1234"); rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected the second signup to be limited, got %d", rr.Code)
+	}
+
+	// Another limiter has its own buckets.
+	if rr := sendFrom(login, "10.0.0.1:1234"); rr.Code != http.StatusOK {
+		t.Errorf("Expected login to pass after signups, got %d", rr.Code)
+	}
+	// Another client has its own bucket, while another port of the same client does not.
+	if rr := sendFrom(signup, "10.0.0.2:1234"); rr.Code != http.StatusOK {
+		t.Errorf("Expected another client to pass, got %d", rr.Code)
+	}
+	if rr := sendFrom(signup, "10.0.0.1:5678"); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected a new port of the same client to be limited, got %d", rr.Code)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		forwardedFor string
+		remoteAddr   string
+		wantClientIP string
+	}{
+		{"remote addr with port", "", "192.0.2.1:54321", "192.0.2.1"},
+		{"ipv6 remote addr", "", "[2001:db8::1]:443", "2001:db8::1"},
+		{"remote addr without port", "", "192.0.2.1", "192.0.2.1"},
+		{"single forwarded", "203.0.113.7", "10.0.0.1:80", "203.0.113.7"},
+		{"forwarded chain", "203.0.113.7, 198.51.100.2, 10.0.0.1", "10.0.0.1:80", "203.0.113.7"},
+		{"forwarded with spaces", "  203.0.113.7  ,198.51.100.2", "10.0.0.1:80", "203.0.113.7"},
+		{"empty forwarded entry", " , 198.51.100.2", "10.0.0.1:80", "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			if got := middleware.ClientIP(req); got != tt.wantClientIP {
+				t.Errorf("Expected %q, got %q", tt.wantClientIP, got)
+			}
+		})
+	}
+}
+
+// fakeClock is a settable clock for limiters and stores.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestLimiter_Refill(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := middleware.NewLimiter(rate.Every(time.Minute), 2, middleware.NewMemoryLimiterStore())
+	limiter.Now = clock.Now
+
+	for i := 1; i <= 2; i++ {
+		if _, ok := limiter.Reserve("client"); !ok {
+			t.Fatalf("Request %d: expected a token", i)
+		}
+	}
+	if delay, ok := limiter.Reserve("client"); ok || delay != time.Minute {
+		t.Fatalf("Expected a rejection with a delay of 1m, got %v (ok: %v)", delay, ok)
+	}
+
+	// Half a token has refilled; the rejection did not take anything.
+	clock.now = clock.now.Add(30 * time.Second)
+	if delay, ok := limiter.Reserve("client"); ok || delay != 30*time.Second {
+		t.Fatalf("Expected a rejection with a delay of 30s, got %v (ok: %v)", delay, ok)
+	}
+	clock.now = clock.now.Add(30 * time.Second)
+	if _, ok := limiter.Reserve("client"); !ok {
+		t.Fatalf("Expected a token after a minute")
+	}
+
+	// A bucket never holds more than the burst, however long the client was away.
+	clock.now = clock.now.Add(24 * time.Hour)
+	for i := 1; i <= 2; i++ {
+		if _, ok := limiter.Reserve("client"); !ok {
+			t.Fatalf("Request %d after a day: expected a token", i)
+		}
+	}
+	if _, ok := limiter.Reserve("client"); ok {
+		t.Errorf("Expected the third request after a day to be rejected")
+	}
+}
+
+// slowStore is a MemoryLimiterStore whose Get of slowKey waits until release is closed, like a
+// bucket loaded from a slow database.
+type slowStore struct {
+	*middleware.MemoryLimiterStore
+	slowKey string
+	loading chan struct{} // Closed once the first Get of slowKey has started.
+	release chan struct{}
+	once    sync.Once
+}
+
+func (s *slowStore) Get(key string) (models.RateLimitBucket, bool) {
+	if key == s.slowKey {
+		s.once.Do(func() { close(s.loading) })
+		<-s.release
+	}
+	return s.MemoryLimiterStore.Get(key)
+}
+
+func TestLimiter_SlowLoad(t *testing.T) {
+	store := &slowStore{MemoryLimiterStore: middleware.NewMemoryLimiterStore(), slowKey: "slow", loading: make(chan struct{}), release: make(chan struct{})}
+	limiter := middleware.NewLimiter(rate.Every(time.Minute), 1, store)
+
+	slowDone := make(chan bool)
+	go func() {
+		_, ok := limiter.Reserve("slow")
+		slowDone <- ok
+	}()
+	<-store.loading
+
+	fastDone := make(chan bool)
+	go func() {
+		_, ok := limiter.Reserve("fast")
+		fastDone <- ok
+	}()
+	select {
+	case ok := <-fastDone:
+		if !ok {
+			t.Errorf("Expected the other client to get a token")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected another client not to wait for the bucket being loaded")
+	}
+
+	close(store.release)
+	if ok := <-slowDone; !ok {
+		t.Errorf("Expected the slow client to get a token once its bucket loaded")
+	}
+	if _, ok := limiter.Reserve("slow"); ok {
+		t.Errorf("Expected the slow client's bucket to be empty")
+	}
+}
+
+func TestMemoryLimiterStore_Prune(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	store := middleware.NewMemoryLimiterStore()
+	limiter := middleware.NewLimiter(rate.Every(time.Hour), 1, store)
+	limiter.Now = clock.Now
+
+	limiter.Reserve("client")
+	// The bucket is empty for an hour, so pruning must not give the client a new token.
+	if pruned := store.Prune(clock.now.Add(30 * time.Minute)); pruned != 0 {
+		t.Fatalf("Expected no bucket to be pruned before it refilled, got %d", pruned)
+	}
+	clock.now = clock.now.Add(30 * time.Minute)
+	if _, ok := limiter.Reserve("client"); ok {
+		t.Fatalf("Expected the client to still be limited after pruning")
+	}
+
+	if pruned := store.Prune(clock.now.Add(time.Hour)); pruned != 1 {
+		t.Fatalf("Expected the refilled bucket to be pruned, got %d", pruned)
+	}
+	if _, ok := store.Get("client"); ok {
+		t.Errorf("Expected the pruned bucket to be gone")
+	}
+}
+
+func TestRateLimiters_SharedStore(t *testing.T) {
+	limiters := middleware.NewRateLimiters(middleware.NewMemoryLimiterStore())
+	signup := limiters.PerIP("signup", rate.Every(time.Hour), 1)(okHandler)
+	login := limiters.PerIP("login", rate.Every(time.Hour), 1)(okHandler)
+
+	if rr := sendFrom(signup, "10.0.0.1:1234"); rr.Code != http.StatusOK {
+		t.Fatalf("Expected the first signup to pass, got %d", rr.Code)
+	}
+	if rr := sendFrom(signup, "10.0.0.1:1234"); rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected the second signup to be limited, got %d", rr.Code)
+	}
+	if rr := sendFrom(login, "10.0.0.1:1234"); rr.Code != http.StatusOK {
+		t.Errorf("Expected login to pass after signups, got %d", rr.Code)
+	}
+}
+
+func TestPersistentLimiterStore_Restart(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	repo := mocks.NewMockRateLimitRepository()
+	newLimiter := func(store *middleware.PersistentLimiterStore) *middleware.Limiter {
+		store.Now = clock.Now
+		limiter := middleware.NewLimiter(rate.Every(time.Hour), 2, store)
+		limiter.Now = clock.Now
+		return limiter
+	}
+
+	store := middleware.NewPersistentLimiterStore(repo)
+	limiter := newLimiter(store)
+	limiter.Reserve("login:10.0.0.1")
+	limiter.Reserve("login:10.0.0.1")
+	if len(repo.Buckets) != 0 {
+		t.Fatalf("Expected buckets to be saved by Flush only, got %v", repo.Buckets)
+	}
+	if err := store.Flush(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	saved := repo.Buckets["login:10.0.0.1"]
+	if saved.Tokens != 0 || !saved.LastSeen.Equal(clock.now) || !saved.FullAt.Equal(clock.now.Add(2*time.Hour)) {
+		t.Fatalf("Expected an empty bucket full in 2h, got %+v", saved)
+	}
+	// Nothing changed, so nothing is saved again.
+	if err := store.Flush(context.Background()); err != nil || repo.Saves != 1 {
+		t.Fatalf("Expected no second save, got %d saves (err: %v)", repo.Saves, err)
+	}
+
+	// After a restart the bucket is loaded from the repository and is still empty.
+	clock.now = clock.now.Add(time.Minute)
+	limiter = newLimiter(middleware.NewPersistentLimiterStore(repo))
+	if _, ok := limiter.Reserve("login:10.0.0.1"); ok {
+		t.Errorf("Expected the client to still be limited after a restart")
+	}
+	if _, ok := limiter.Reserve("login:10.0.0.2"); !ok {
+		t.Errorf("Expected an unknown client to get a full bucket")
+	}
+}
+
+func TestPersistentLimiterStore_FailsOpen(t *testing.T) {
+	repo := mocks.NewMockRateLimitRepository()
+	repo.Err = repositories.ErrUnavailable
+	store := middleware.NewPersistentLimiterStore(repo)
+	limiter := middleware.NewLimiter(rate.Every(time.Hour), 1, store)
+
+	if _, ok := limiter.Reserve("otp:10.0.0.1"); !ok {
+		t.Fatalf("Expected a request to pass when buckets cannot be loaded")
+	}
+	// The bucket is kept in memory, so the client is still limited.
+	if _, ok := limiter.Reserve("otp:10.0.0.1"); ok {
+		t.Fatalf("Expected the second request to be limited")
+	}
+
+	if err := store.Flush(context.Background()); err == nil {
+		t.Fatalf("Expected the failed save to be reported")
+	}
+	repo.Err = nil
+	if err := store.Flush(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := repo.Buckets["otp:10.0.0.1"]; !ok {
+		t.Errorf("Expected the bucket to be saved by the next flush, got %v", repo.Buckets)
+	}
+}
@@ -0,0 +1,137 @@
+/**
+ *  Recover Tests validate the middleware created by NewRecover: a panicking handler fails only its
+ *  own request with a 500, the server keeps serving, and the panic is logged with the request ID.
+ *
+ *  @file       recover_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestRecover_Panic              - Tests the 500 JSON error, the logged stack trace and that later requests are served.
+ *  - TestRecover_PanicAfterHeaders  - Tests that a panic after the response was started keeps its status and is logged.
+ *  - TestRecover_RequestID          - Tests that a client's X-Request-ID is echoed and a missing one is generated.
+ *
+ *  @dependencies
+ *  - middleware.NewRecover: The middleware under test.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"proh2052-group6/internal/middleware"
+)
+
+// newPanickingServer serves /panic, which panics before writing, /partial, which panics after
+// writing its headers, and /ok behind the recover middleware.
+func newPanickingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		var counts map[string]int
+		counts["requests"]++ // Assignment to a nil map.
+	})
+	mux.HandleFunc("/partial", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("partial"))
+		panic("failed halfway")
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	server := httptest.NewServer(middleware.NewRecover()(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// captureLog redirects the standard logger to a buffer until the test ends.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(previous) })
+	return &buf
+}
+
+func TestRecover_Panic(t *testing.T) {
+	server := newPanickingServer(t)
+	logs := captureLog(t)
+
+	req, _ := http.NewRequest("GET", server.URL+"/panic", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-123")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected a response, got %v", err)
+	}
+	var body map[string]string
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || body["message"] != "Internal server error" {
+		t.Errorf("Expected a 500 JSON error, got %d %+v", resp.StatusCode, body)
+	}
+	if out := logs.String(); !strings.Contains(out, "req-123") || !strings.Contains(out, "assignment to entry in nil map") || !strings.Contains(out, "goroutine") {
+		t.Errorf("Expected the panic to be logged with its request ID and stack trace, got %q", out)
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := http.Get(server.URL + "/ok")
+		if err != nil {
+			t.Fatalf("Expected the server to keep serving, got %v", err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(data) != "ok" {
+			t.Errorf("Expected 200 ok after the panic, got %d %q", resp.StatusCode, data)
+		}
+	}
+}
+
+func TestRecover_PanicAfterHeaders(t *testing.T) {
+	server := newPanickingServer(t)
+	logs := captureLog(t)
+
+	resp, err := http.Get(server.URL + "/partial")
+	if err != nil {
+		t.Fatalf("Expected a response, got %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(data) != "partial" {
+		t.Errorf("Expected the started response to be kept, got %d %q", resp.StatusCode, data)
+	}
+	if !strings.Contains(logs.String(), "failed halfway") {
+		t.Errorf("Expected the panic to be logged, got %q", logs.String())
+	}
+}
+
+func TestRecover_RequestID(t *testing.T) {
+	handler := middleware.NewRecover()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-456")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if got := rr.Header().Get(middleware.RequestIDHeader); got != "req-456" {
+		t.Errorf("Expected the client's request ID, got %q", got)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	if got := rr.Header().Get(middleware.RequestIDHeader); len(got) != 32 {
+		t.Errorf("Expected a generated 32-character request ID, got %q", got)
+	}
+}
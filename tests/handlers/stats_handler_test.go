@@ -0,0 +1,93 @@
+/**
+ *  StatsHandler Tests validate the activity statistics endpoint of the dashboard.
+ *
+ *  @file       stats_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestStatsHandler_GetUserStats        - Tests the JSON shape and the counts of the user's own data.
+ *  - TestStatsHandler_DatabaseUnavailable - Tests the 503 when the counts cannot be read.
+ *
+ *  @dependencies
+ *  - mocks.NewMockEventRepository, NewMockJournalRepository, NewMockFriendRepository: Hold the counted data.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// getUserStats requests /api/me/stats as userEmail.
+func getUserStats(statsService services.StatsServiceInterface, userEmail string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/me/stats", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), userEmail))
+	rr := httptest.NewRecorder()
+	handlers.NewStatsHandler(statsService).GetUserStats(rr, req)
+	return rr
+}
+
+func TestStatsHandler_GetUserStats(t *testing.T) {
+	ctx := context.Background()
+	eventRepo := mocks.NewMockEventRepository()
+	journalRepo := mocks.NewMockJournalRepository()
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		"alice@example.com_bob@example.com":   {Email: "alice@example.com", FriendEmail: "bob@example.com", Status: "accepted"},
+		"carol@example.com_alice@example.com": {Email: "carol@example.com", FriendEmail: "alice@example.com", Status: "accepted"},
+		"alice@example.com_dave@example.com":  {Email: "alice@example.com", FriendEmail: "dave@example.com", Status: "pending"},
+	})
+	eventRepo.CreateEvent(ctx, &models.Event{Email: "alice@example.com", Title: "Lecture", Date: "2024-05-02"})
+	eventRepo.CreateEvent(ctx, &models.Event{Email: "bob@example.com", Title: "Gym", Date: "2024-05-02"})
+	for _, date := range []string{"2024-04-30", "2024-05-01", "2024-05-03"} {
+		journalRepo.CreateJournal(ctx, &models.Journal{Email: "alice@example.com", Date: date, Content: "Entry"})
+	}
+
+	statsService := services.NewStatsService(eventRepo, journalRepo, friendRepo).(*services.StatsService)
+	statsService.Now = func() time.Time { return time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC) }
+
+	rr := getUserStats(statsService, "alice@example.com")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var stats map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	want := map[string]float64{"events": 1, "journals": 3, "friends": 2, "activeDays": 2}
+	if len(stats) != len(want) {
+		t.Errorf("Expected exactly the fields %v, got %v", want, stats)
+	}
+	for field, count := range want {
+		if stats[field] != count {
+			t.Errorf("Expected %s to be %v, got %v", field, count, stats[field])
+		}
+	}
+}
+
+func TestStatsHandler_DatabaseUnavailable(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	eventRepo.Err = repositories.ErrUnavailable
+	statsService := services.NewStatsService(eventRepo, mocks.NewMockJournalRepository(), mocks.NewMockFriendRepository(map[string]*models.Friend{}))
+
+	if rr := getUserStats(statsService, "alice@example.com"); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
@@ -0,0 +1,244 @@
+/**
+ *  TimetableHandler Tests validate the HTTP layer of timetable import and export.
+ *  They use the real TimetableService backed by a mock EventRepository.
+ *
+ *  @file       timetable_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestTimetableHandler_ExportTimetable          - Tests the ICS download headers and body.
+ *  - TestTimetableHandler_ExportTimetable_BadRange - Tests that an invalid range returns a JSON 400.
+ *  - TestTimetableHandler_UndoImport               - Tests listing import batches and undoing an import, leaving other events.
+ *  - TestTimetableHandler_Feed                     - Tests the calendar feed, and that rotating or revoking the token disables the old URL.
+ *
+ *  @dependencies
+ *  - mocks.NewMockEventRepository: Mock implementation of EventRepository for testing.
+ *  - mocks.NewMockUserRepository: Mock implementation of UserRepository storing the feed tokens.
+ *  - services.NewTimetableService: Timetable service under test.
+ *  - middleware.WithUserEmail: Adds the authenticated user email to the request context.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newTestTimetableHandler creates a TimetableHandler with one event for user@example.com.
+func newTestTimetableHandler() *handlers.TimetableHandler {
+	mockEventRepo := mocks.NewMockEventRepository()
+	mockEventRepo.CreateEvent(context.Background(), &models.Event{
+		Email:     "user@example.com",
+		Title:     "Exam",
+		Date:      "2024-05-21",
+		StartTime: "09:00",
+		EndTime:   "13:00",
+	})
+	return handlers.NewTimetableHandler(services.NewTimetableService(mockEventRepo))
+}
+
+func TestTimetableHandler_ExportTimetable(t *testing.T) {
+	timetableHandler := newTestTimetableHandler()
+
+	req, _ := http.NewRequest("GET", "/api/events/export.ics?from=2024-05-01&to=2024-05-31", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(timetableHandler.ExportTimetable).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if contentType := rr.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/calendar") {
+		t.Errorf("Expected text/calendar content type, got %q", contentType)
+	}
+	if disposition := rr.Header().Get("Content-Disposition"); !strings.Contains(disposition, `filename="dailyverse-events.ics"`) {
+		t.Errorf("Expected an .ics filename, got %q", disposition)
+	}
+	body := rr.Body.String()
+	if !strings.HasPrefix(body, "BEGIN:VCALENDAR") || !strings.Contains(body, "SUMMARY:Exam") {
+		t.Errorf("Expected a calendar containing the exam, got:\n%s", body)
+	}
+}
+
+func TestTimetableHandler_ExportTimetable_BadRange(t *testing.T) {
+	timetableHandler := newTestTimetableHandler()
+
+	req, _ := http.NewRequest("GET", "/api/events/export.ics?from=21-05-2024", nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(timetableHandler.ExportTimetable).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected a JSON error, got content type %q", contentType)
+	}
+}
+
+func TestTimetableHandler_UndoImport(t *testing.T) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	mockEventRepo.CreateEvent(context.Background(), &models.Event{Email: "user@example.com", Title: "Exam", Date: "2024-05-21"})
+	timetableHandler := handlers.NewTimetableHandler(services.NewTimetableService(mockEventRepo))
+
+	send := func(handler http.HandlerFunc, method, url string, body interface{}) *httptest.ResponseRecorder {
+		requestBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(requestBody))
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	icsContent := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:idatg2204-1@tp.uio.no",
+		"DTSTART:20240115T081500Z",
+		"DTEND:20240115T100000Z",
+		"SUMMARY:IDATG2204 Forelesning",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:idatg2204-2@tp.uio.no",
+		"DTSTART:20240122T081500Z",
+		"DTEND:20240122T100000Z",
+		"SUMMARY:IDATG2204 Forelesning",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	var imported models.ImportResult
+	json.Unmarshal(send(timetableHandler.ImportTimetable, "POST", "/api/import-ntnu-timetable", handlers.ImportTimetableRequest{ICSContent: icsContent}).Body.Bytes(), &imported)
+	if imported.Imported != 2 || imported.BatchID == "" {
+		t.Fatalf("Expected 2 imported events and a batch ID, got %+v", imported)
+	}
+
+	var batches []models.ImportBatch
+	rr := send(timetableHandler.GetImportBatches, "GET", "/api/import-ntnu-timetable/batches", nil)
+	if err := json.Unmarshal(rr.Body.Bytes(), &batches); err != nil || len(batches) != 1 || batches[0].BatchID != imported.BatchID || batches[0].EventCount != 2 {
+		t.Fatalf("Expected the import to be listed with 2 events, got %s", rr.Body.String())
+	}
+
+	rr = send(timetableHandler.UndoImport, "DELETE", "/api/import-ntnu-timetable?batchID="+imported.BatchID, nil)
+	var undone handlers.UndoImportResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &undone); err != nil || rr.Code != http.StatusOK || undone.Deleted != 2 {
+		t.Fatalf("Expected 2 events to be deleted, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(mockEventRepo.Events) != 1 {
+		t.Errorf("Expected only the manually created event to remain, got %d events", len(mockEventRepo.Events))
+	}
+
+	if rr := send(timetableHandler.UndoImport, "DELETE", "/api/import-ntnu-timetable?batchID="+imported.BatchID, nil); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d when undoing twice, got %d", http.StatusNotFound, rr.Code)
+	}
+	if rr := send(timetableHandler.UndoImport, "DELETE", "/api/import-ntnu-timetable", nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without a batchID, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestTimetableHandler_Feed(t *testing.T) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	mockEventRepo.CreateEvent(context.Background(), &models.Event{Email: "user@example.com", Title: "Exam", Date: "2024-05-21", StartTime: "09:00", EndTime: "13:00"})
+	timetableService := services.NewTimetableService(mockEventRepo).(*services.TimetableService)
+	timetableService.UserRepo = mocks.NewMockUserRepository(map[string]*models.User{
+		"user@example.com": {Email: "user@example.com", Username: "user", Country: "Norway"},
+	})
+	timetableService.Now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	timetableHandler := handlers.NewTimetableHandler(timetableService)
+
+	send := func(handler http.HandlerFunc, method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/events/feed-token", nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "user@example.com"))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	createToken := func() models.CalendarFeed {
+		t.Helper()
+		var feed models.CalendarFeed
+		rr := send(timetableHandler.CreateFeedToken, "POST")
+		if err := json.Unmarshal(rr.Body.Bytes(), &feed); err != nil || rr.Code != http.StatusOK || len(feed.Token) != 64 {
+			t.Fatalf("Expected a new feed token, got %d: %s", rr.Code, rr.Body.String())
+		}
+		return feed
+	}
+	getFeed := func(token string) *httptest.ResponseRecorder {
+		req := mux.SetURLVars(httptest.NewRequest("GET", "/api/feeds/"+token+".ics", nil), map[string]string{"token": token})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(timetableHandler.GetFeed).ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send(timetableHandler.GetFeedToken, "GET"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d before a feed is created, got %d", http.StatusNotFound, rr.Code)
+	}
+
+	feed := createToken()
+	if feed.URL != "http://example.com/api/feeds/"+feed.Token+".ics" {
+		t.Errorf("Unexpected feed URL %q", feed.URL)
+	}
+	var got models.CalendarFeed
+	if rr := send(timetableHandler.GetFeedToken, "GET"); json.Unmarshal(rr.Body.Bytes(), &got) != nil || got != feed {
+		t.Errorf("Expected the feed %+v, got %s", feed, rr.Body.String())
+	}
+
+	rr := getFeed(feed.Token)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if contentType := rr.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/calendar") {
+		t.Errorf("Expected text/calendar content type, got %q", contentType)
+	}
+	if cacheControl := rr.Header().Get("Cache-Control"); cacheControl != "private, max-age=900" {
+		t.Errorf("Expected the feed to be cacheable, got Cache-Control %q", cacheControl)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, "SUMMARY:Exam") || strings.Contains(body, "user@example.com") {
+		t.Errorf("Expected a calendar with the exam and without the user's email, got:\n%s", body)
+	}
+
+	// Rotating the token disables the old URL.
+	rotated := createToken()
+	if rotated.Token == feed.Token {
+		t.Fatal("Expected a different token after rotating")
+	}
+	if rr := getFeed(feed.Token); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for the old token, got %d", http.StatusNotFound, rr.Code)
+	}
+	if rr := getFeed(rotated.Token); rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d for the new token, got %d", http.StatusOK, rr.Code)
+	}
+
+	// Revoking the token disables the feed.
+	if rr := send(timetableHandler.RevokeFeedToken, "DELETE"); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d when revoking, got %d", http.StatusOK, rr.Code)
+	}
+	for _, token := range []string{rotated.Token, ""} {
+		if rr := getFeed(token); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d for %q after revoking, got %d", http.StatusNotFound, token, rr.Code)
+		}
+	}
+	if rr := send(timetableHandler.GetFeedToken, "GET"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d after revoking, got %d", http.StatusNotFound, rr.Code)
+	}
+}
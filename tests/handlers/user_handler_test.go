@@ -8,16 +8,21 @@
  *
  *  @test_cases
  *  - TestUserHandler_Signup        - Tests user signup functionality.
+ *  - TestUserHandler_Signup_CountryAndCity - Tests the field errors for an unknown country and the warning for an unlisted city.
  *  - TestUserHandler_Login         - Tests user login functionality.
+ *  - TestUserHandler_Login_LegacyPasswordHash - Tests login and hash upgrade for legacy SHA-256 users.
  *  - TestUserHandler_ResendOTP     - Tests resending OTP functionality.
  *  - TestUserHandler_VerifyEmail   - Tests email verification functionality.
  *  - TestUserHandler_GetUserInfo   - Tests retrieving user information.
+ *  - TestUserHandler_ErrorStatusCodes - Tests that service errors map to 400/401/403/404/409/423/429, that an
+ *    unreachable database returns 503 and that unexpected errors return a generic 500 without leaking the internal message.
+ *  - TestUserHandler_SearchUsers_Pagination - Tests that limit, offset and excludeRelated are passed to the service and validated.
  *
  *  @dependencies
  *  - mocks.NewMockUserRepository: Mock implementation of UserRepository for testing.
  *  - mocks.MockEmailService: Mock implementation of EmailService for testing.
  *  - httptest: Utilities for testing HTTP handlers.
- *  - context.WithValue: Adds user-specific context values for testing purposes.
+ *  - middleware.WithUserEmail: Adds the authenticated user email to the request context.
  *  - encoding/json: Handles JSON marshalling and unmarshalling.
  *
  *  @behaviors
@@ -49,34 +54,53 @@ package handlers_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
 	"proh2052-group6/internal/services"
 	"proh2052-group6/pkg/models"
 	"proh2052-group6/pkg/utils"
 	"proh2052-group6/tests/mocks"
 )
 
+// testJWT signs tokens and hashes OTPs for the services and middleware under test.
+var testJWT = utils.NewJWTManager("test-secret", nil, 0, "")
+
+// mustHashPassword hashes a password for test fixtures, failing the test on error.
+func mustHashPassword(t *testing.T, password string) string {
+	t.Helper()
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	return hashedPassword
+}
+
 func TestUserHandler_Signup(t *testing.T) {
 	// Test case: Verify user signup with valid input
 	// Arrange
 	mockUserRepo := mocks.NewMockUserRepository(make(map[string]*models.User))
 	mockEmailService := &mocks.MockEmailService{}
-	userService := services.NewUserService(mockUserRepo, mockEmailService)
+	userService := services.NewUserService(mockUserRepo, mockEmailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
 	userHandler := handlers.NewUserHandler(userService)
 
 	// Act
-	user := models.User{
+	user := handlers.SignupRequest{
 		Email:    "test@example.com",
 		Username: "testuser",
 		Password: "Password123!",
-		Country:  "TestCountry",
-		City:     "TestCity",
+		Country:  "Norway",
+		City:     "Oslo",
 	}
 	requestBody, _ := json.Marshal(user)
 	req, err := http.NewRequest("POST", "/api/signup", bytes.NewBuffer(requestBody))
@@ -116,19 +140,58 @@ func TestUserHandler_Signup(t *testing.T) {
 	}
 }
 
+func TestUserHandler_Signup_CountryAndCity(t *testing.T) {
+	userService := services.NewUserService(mocks.NewMockUserRepository(make(map[string]*models.User)), &mocks.MockEmailService{}, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+	userService.(*services.UserService).Cities = &mocks.MockCityService{
+		GetCitiesByCountryFunc: func(ctx context.Context, country string) ([]string, error) {
+			return []string{"Bergen", "Oslo"}, nil
+		},
+	}
+	userHandler := handlers.NewUserHandler(userService)
+	signup := func(email, country, city string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		requestBody, _ := json.Marshal(handlers.SignupRequest{Email: email, Username: strings.Split(email, "@")[0], Password: "Password123!", Country: country, City: city})
+		rr := httptest.NewRecorder()
+		userHandler.Signup(rr, httptest.NewRequest("POST", "/api/signup", bytes.NewBuffer(requestBody)))
+		var body map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &body)
+		return rr, body
+	}
+
+	rr, body := signup("carol@example.com", "Norwayy", "Oslo")
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown country, got %d", http.StatusBadRequest, rr.Code)
+	}
+	want := map[string]interface{}{"country": "is not a known country; did you mean Norway?"}
+	if fieldErrors, _ := body["errors"].(map[string]interface{}); body["message"] != "Invalid input" || fmt.Sprint(fieldErrors) != fmt.Sprint(want) {
+		t.Errorf("Expected the country field error, got %v", body)
+	}
+
+	rr, body = signup("dave@example.com", "norway", "Asdf")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d for an unlisted city, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if warnings, _ := body["warnings"].(map[string]interface{}); warnings["city"] != "is not in our list of cities in Norway; check the spelling" {
+		t.Errorf("Expected a warning for the city, got %v", body)
+	}
+
+	if _, body = signup("erin@example.com", "Norway", "oslo"); body["warnings"] != nil {
+		t.Errorf("Expected no warnings for a listed city, got %v", body["warnings"])
+	}
+}
+
 func TestUserHandler_Login(t *testing.T) {
 	// Test case: Verify user login with valid credentials
 	// Arrange
 	mockUserRepo := mocks.NewMockUserRepository(make(map[string]*models.User))
 	mockEmailService := &mocks.MockEmailService{}
-	userService := services.NewUserService(mockUserRepo, mockEmailService)
+	userService := services.NewUserService(mockUserRepo, mockEmailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
 	userHandler := handlers.NewUserHandler(userService)
 
 	// Add a verified user
 	user := &models.User{
 		Email:      "test@example.com",
 		Username:   "testuser",
-		Password:   utils.HashPassword("Password123!"),
+		Password:   mustHashPassword(t, "Password123!"),
 		Country:    "TestCountry",
 		City:       "TestCity",
 		IsVerified: true,
@@ -166,22 +229,89 @@ func TestUserHandler_Login(t *testing.T) {
 	}
 }
 
+func TestUserHandler_Login_LegacyPasswordHash(t *testing.T) {
+	// Test case: A user stored with a legacy SHA-256 hash can log in and is upgraded to bcrypt
+	// Arrange
+	mockUserRepo := mocks.NewMockUserRepository(make(map[string]*models.User))
+	mockEmailService := &mocks.MockEmailService{}
+	userService := services.NewUserService(mockUserRepo, mockEmailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+	userHandler := handlers.NewUserHandler(userService)
+
+	legacyHash := sha256.Sum256([]byte("Password123!"))
+	user := &models.User{
+		Email:      "legacy@example.com",
+		Username:   "legacyuser",
+		Password:   hex.EncodeToString(legacyHash[:]),
+		Country:    "TestCountry",
+		City:       "TestCity",
+		IsVerified: true,
+	}
+	mockUserRepo.CreateUser(context.Background(), user)
+
+	// Act
+	loginData := models.LoginRequest{
+		Email:    "legacy@example.com",
+		Password: "Password123!",
+	}
+	requestBody, _ := json.Marshal(loginData)
+	req, err := http.NewRequest("POST", "/api/login", bytes.NewBuffer(requestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(userHandler.Login)
+	handler.ServeHTTP(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response map[string]string
+	err = json.Unmarshal(rr.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Failed to parse response body: %v", err)
+	}
+	if response["token"] == "" {
+		t.Errorf("Expected a token in response")
+	}
+
+	// Validate the stored hash was upgraded to bcrypt
+	savedUser, _ := mockUserRepo.GetUserByEmail(context.Background(), user.Email)
+	if utils.IsLegacyPasswordHash(savedUser.Password) {
+		t.Errorf("Expected legacy password hash to be upgraded")
+	}
+	if !utils.CheckPasswordHash("Password123!", savedUser.Password) {
+		t.Errorf("Expected upgraded hash to verify with bcrypt")
+	}
+
+	// Validate the user can still log in with the upgraded hash
+	req, _ = http.NewRequest("POST", "/api/login", bytes.NewBuffer(requestBody))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("second login returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+}
+
 func TestUserHandler_ResendOTP(t *testing.T) {
 	// Test case: Verify OTP resend functionality for unverified users
 	// Arrange
 	mockUserRepo := mocks.NewMockUserRepository(make(map[string]*models.User))
 	mockEmailService := &mocks.MockEmailService{}
-	userService := services.NewUserService(mockUserRepo, mockEmailService)
+	userService := services.NewUserService(mockUserRepo, mockEmailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
 	userHandler := handlers.NewUserHandler(userService)
 
 	// Add an unverified user
 	user := &models.User{
 		Email:      "test@example.com",
 		Username:   "testuser",
-		Password:   utils.HashPassword("Password123!"),
+		Password:   mustHashPassword(t, "Password123!"),
 		Country:    "TestCountry",
 		City:       "TestCity",
 		IsVerified: false,
+		CreatedAt:  time.Now(),
 	}
 	mockUserRepo.CreateUser(context.Background(), user)
 
@@ -223,19 +353,20 @@ func TestUserHandler_VerifyEmail(t *testing.T) {
 	// Create mocks
 	mockUserRepo := mocks.NewMockUserRepository(make(map[string]*models.User))
 	mockEmailService := &mocks.MockEmailService{}
-	userService := services.NewUserService(mockUserRepo, mockEmailService)
+	userService := services.NewUserService(mockUserRepo, mockEmailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
 	userHandler := handlers.NewUserHandler(userService)
 
 	// Add an unverified user with an OTP
 	user := &models.User{
 		Email:        "test@example.com",
 		Username:     "testuser",
-		Password:     utils.HashPassword("Password123!"),
+		Password:     mustHashPassword(t, "Password123!"),
 		Country:      "TestCountry",
 		City:         "TestCity",
 		IsVerified:   false,
-		OTP:          "123456",
+		OTP:          testJWT.HashOTP("123456"), // Only the hash of the OTP is stored.
 		OTPExpiresAt: time.Now().Add(5 * time.Minute),
+		CreatedAt:    time.Now(),
 	}
 	mockUserRepo.CreateUser(context.Background(), user)
 
@@ -285,14 +416,14 @@ func TestUserHandler_GetUserInfo(t *testing.T) {
 	// Create mocks
 	mockUserRepo := mocks.NewMockUserRepository(make(map[string]*models.User))
 	mockEmailService := &mocks.MockEmailService{}
-	userService := services.NewUserService(mockUserRepo, mockEmailService)
+	userService := services.NewUserService(mockUserRepo, mockEmailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
 	userHandler := handlers.NewUserHandler(userService)
 
 	// Add a verified user to the mock repository
 	user := &models.User{
 		Email:      "test@example.com",
 		Username:   "testuser",
-		Password:   utils.HashPassword("Password123!"),
+		Password:   mustHashPassword(t, "Password123!"),
 		Country:    "TestCountry",
 		City:       "TestCity",
 		IsVerified: true,
@@ -306,7 +437,7 @@ func TestUserHandler_GetUserInfo(t *testing.T) {
 	}
 
 	// Add the userEmail to the request context
-	ctx := context.WithValue(req.Context(), "userEmail", user.Email)
+	ctx := middleware.WithUserEmail(req.Context(), user.Email)
 	req = req.WithContext(ctx)
 
 	// Create a ResponseRecorder to record the response
@@ -323,7 +454,7 @@ func TestUserHandler_GetUserInfo(t *testing.T) {
 	}
 
 	// Check the response body
-	var response map[string]string
+	var response map[string]interface{}
 	err = json.Unmarshal(rr.Body.Bytes(), &response)
 	if err != nil {
 		t.Errorf("Failed to parse response body: %v", err)
@@ -335,4 +466,110 @@ func TestUserHandler_GetUserInfo(t *testing.T) {
 	if response["username"] != user.Username {
 		t.Errorf("Expected username '%s', got '%s'", user.Username, response["username"])
 	}
+	if response["isVerified"] != true {
+		t.Errorf("Expected isVerified true, got %v", response["isVerified"])
+	}
+	// The user was created without a CreatedAt, like accounts from before it was recorded.
+	if _, ok := response["createdAt"]; ok {
+		t.Errorf("Expected no createdAt for an account without one, got %v", response["createdAt"])
+	}
+}
+
+func TestUserHandler_ErrorStatusCodes(t *testing.T) {
+	internalErr := fmt.Errorf("Failed to create user: rpc error: code = Unavailable desc = firestore.googleapis.com")
+
+	tests := []struct {
+		name        string
+		serviceErr  error
+		wantStatus  int
+		wantMessage string
+	}{
+		{"missing fields", services.ErrMissingFields, http.StatusBadRequest, services.ErrMissingFields.Error()},
+		{"weak password", services.ErrWeakPassword, http.StatusBadRequest, services.ErrWeakPassword.Error()},
+		{"invalid credentials", services.ErrInvalidCredentials, http.StatusUnauthorized, services.ErrInvalidCredentials.Error()},
+		{"not verified", services.ErrNotVerified, http.StatusForbidden, services.ErrNotVerified.Error()},
+		{"not registered", services.ErrEmailNotRegistered, http.StatusNotFound, services.ErrEmailNotRegistered.Error()},
+		{"email taken", services.ErrEmailTaken, http.StatusConflict, services.ErrEmailTaken.Error()},
+		{"username taken", services.ErrUsernameTaken, http.StatusConflict, services.ErrUsernameTaken.Error()},
+		{"already verified", services.ErrAlreadyVerified, http.StatusConflict, services.ErrAlreadyVerified.Error()},
+		{"wrapped", fmt.Errorf("signup: %w", services.ErrEmailTaken), http.StatusConflict, "signup: Email already registered"},
+		{"account locked", services.ErrAccountLocked, http.StatusLocked, services.ErrAccountLocked.Error()},
+		{"too many OTP attempts", services.ErrTooManyOTPAttempts, http.StatusTooManyRequests, services.ErrTooManyOTPAttempts.Error()},
+		{"account expired", services.ErrAccountExpired, http.StatusGone, services.ErrAccountExpired.Error()},
+		{"internal", internalErr, http.StatusInternalServerError, "Internal server error"},
+		{"database unavailable", fmt.Errorf("Failed to create user: %w", repositories.ErrUnavailable), http.StatusServiceUnavailable, "Service unavailable"},
+	}
+
+	for _, tt := range tests {
+		mockUserService := &mocks.MockUserService{
+			SignupFunc:         func(ctx context.Context, user *models.User) error { return tt.serviceErr },
+			LoginFunc:          func(ctx context.Context, loginData *models.LoginRequest) (string, error) { return "", tt.serviceErr },
+			ResendOTPFunc:      func(ctx context.Context, email string) error { return tt.serviceErr },
+			ForgotPasswordFunc: func(ctx context.Context, email string) error { return tt.serviceErr },
+		}
+		userHandler := handlers.NewUserHandler(mockUserService)
+
+		endpoints := map[string]http.HandlerFunc{
+			"Signup":         userHandler.Signup,
+			"Login":          userHandler.Login,
+			"ResendOTP":      userHandler.ResendOTP,
+			"ForgotPassword": userHandler.ForgotPassword,
+		}
+		for endpoint, handler := range endpoints {
+			t.Run(tt.name+"/"+endpoint, func(t *testing.T) {
+				req := httptest.NewRequest("POST", "/api/user", bytes.NewBufferString(`{"email":"test@example.com"}`))
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+
+				if rr.Code != tt.wantStatus {
+					t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tt.wantStatus)
+				}
+				var response map[string]string
+				if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+					t.Fatalf("Failed to parse response body: %v", err)
+				}
+				if response["message"] != tt.wantMessage {
+					t.Errorf("Expected message %q, got %q", tt.wantMessage, response["message"])
+				}
+			})
+		}
+	}
+}
+
+func TestUserHandler_SearchUsers_Pagination(t *testing.T) {
+	var gotLimit, gotOffset int
+	var gotExcludeRelated bool
+	mockUserService := &mocks.MockUserService{
+		SearchUsersByUsernameFunc: func(ctx context.Context, userEmail, query string, excludeBlocked, excludeRelated bool, limit, offset int) ([]map[string]string, error) {
+			gotLimit, gotOffset, gotExcludeRelated = limit, offset, excludeRelated
+			return []map[string]string{{"username": "johndoe", "friendshipStatus": services.FriendshipNone}}, nil
+		},
+	}
+	userHandler := handlers.NewUserHandler(mockUserService)
+
+	search := func(url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", url, nil)
+		req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(userHandler.SearchUsersByUsername).ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := search("/api/users/search?query=doe"); rr.Code != http.StatusOK || gotLimit != 0 || gotOffset != 0 {
+		t.Errorf("Expected 200 with the default limit and offset, got %d with limit %d and offset %d", rr.Code, gotLimit, gotOffset)
+	}
+	if rr := search("/api/users/search?query=doe&limit=5&offset=10"); rr.Code != http.StatusOK || gotLimit != 5 || gotOffset != 10 {
+		t.Errorf("Expected 200 with limit 5 and offset 10, got %d with limit %d and offset %d", rr.Code, gotLimit, gotOffset)
+	}
+	if rr := search("/api/users/search?query=doe&excludeRelated=true"); rr.Code != http.StatusOK || !gotExcludeRelated {
+		t.Errorf("Expected excludeRelated to be passed to the service, got %d with %v", rr.Code, gotExcludeRelated)
+	}
+	if search("/api/users/search?query=doe"); gotExcludeRelated {
+		t.Errorf("Expected excludeRelated to be false by default")
+	}
+	for _, url := range []string{"/api/users/search?query=doe&limit=0", "/api/users/search?query=doe&limit=x", "/api/users/search?query=doe&offset=-1"} {
+		if rr := search(url); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", url, rr.Code)
+		}
+	}
 }
@@ -0,0 +1,137 @@
+/**
+ *  WeatherHandler Tests validate the forecast for the user's city, using a fake forecast API behind
+ *  the real WeatherService, a mock geocoder and a mock user service.
+ *
+ *  @file       weather_handler_test.go
+ *  @package    handlers_test
+ *
+ *  @test_cases
+ *  - TestWeatherHandler_GetWeather               - Tests the forecast response and that repeated requests are served from the cache.
+ *  - TestWeatherHandler_GetWeather_UpstreamError - Tests that a failing forecast API returns 502 with a JSON error.
+ *  - TestWeatherHandler_GetWeather_Errors        - Tests a missing city, an unknown city and invalid dates.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newWeatherHandler returns a WeatherHandler for a user in city, with a WeatherService calling a
+// fake forecast API that answers with status and body and counts its requests in calls.
+func newWeatherHandler(t *testing.T, city string, calls *int32, status int, body string) *handlers.WeatherHandler {
+	t.Helper()
+	forecastAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(forecastAPI.Close)
+
+	weatherService := &services.WeatherService{
+		HTTPClient:  forecastAPI.Client(),
+		ForecastURL: forecastAPI.URL,
+		CacheTTL:    time.Hour,
+		Now:         func() time.Time { return time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC) },
+	}
+	userService := &mocks.MockUserService{
+		GetUserInfoFunc: func(ctx context.Context, userEmail string) (*models.UserProfile, error) {
+			return &models.UserProfile{Email: userEmail, City: city, Country: "Norway"}, nil
+		},
+	}
+	geocoder := mocks.NewMockGeocodingService(map[string]services.Coordinates{
+		"Oslo, Norway": {Latitude: 59.9139, Longitude: 10.7522},
+	})
+	return handlers.NewWeatherHandler(weatherService, userService, geocoder)
+}
+
+// getWeather sends GET /api/weather with the given query as test@example.com.
+func getWeather(handler *handlers.WeatherHandler, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/weather"+query, nil)
+	req = req.WithContext(middleware.WithUserEmail(req.Context(), "test@example.com"))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(handler.GetWeather).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestWeatherHandler_GetWeather(t *testing.T) {
+	var calls int32
+	handler := newWeatherHandler(t, "Oslo", &calls, http.StatusOK, `{"daily":{"time":["2024-05-01"],`+
+		`"temperature_2m_max":[14.2],"temperature_2m_min":[5.1],"precipitation_sum":[0.4],"weather_code":[61]}}`)
+
+	for i := 0; i < 2; i++ {
+		rr := getWeather(handler, "?date=2024-05-01")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var response handlers.WeatherResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response body: %v", err)
+		}
+		if response.City != "Oslo" || response.Date != "2024-05-01" || response.TemperatureMax != 14.2 ||
+			response.PrecipitationMm != 0.4 || response.Summary != "Light rain" {
+			t.Errorf("Unexpected forecast %+v", response)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected the second request to be served from the cache, got %d upstream calls", got)
+	}
+}
+
+func TestWeatherHandler_GetWeather_UpstreamError(t *testing.T) {
+	var calls int32
+	handler := newWeatherHandler(t, "Oslo", &calls, http.StatusInternalServerError, `{"error":true,"reason":"Internal error"}`)
+
+	rr := getWeather(handler, "?date=2024-05-01")
+	if rr.Code != http.StatusBadGateway {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusBadGateway, rr.Code, rr.Body.String())
+	}
+	var response handlers.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil || response.Message == "" {
+		t.Errorf("Expected a JSON error, got %q", rr.Body.String())
+	}
+}
+
+func TestWeatherHandler_GetWeather_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		city         string
+		query        string
+		expectedCode int
+	}{
+		{"No city in profile", "", "?date=2024-05-01", http.StatusBadRequest},
+		{"Unknown city", "Atlantis", "?date=2024-05-01", http.StatusNotFound},
+		{"Invalid date", "Oslo", "?date=01.05.2024", http.StatusBadRequest},
+		{"Date in the past", "Oslo", "?date=2024-04-30", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			handler := newWeatherHandler(t, tt.city, &calls, http.StatusOK, `{}`)
+			if rr := getWeather(handler, tt.query); rr.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedCode, rr.Code, rr.Body.String())
+			}
+			if got := atomic.LoadInt32(&calls); got != 0 {
+				t.Errorf("Expected no upstream call, got %d", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+/**
+ *  HTTPClient Tests check the timeouts of the outbound client and its retry policy: which requests
+ *  and failures are retried, how often, and that other methods are sent once.
+ *
+ *  @file       httpclient_test.go
+ *  @package    httpclient_test
+ *
+ *  @test_cases
+ *  - TestNew_Timeout                  - Tests that a call to an upstream sleeping past the timeout fails in time.
+ *  - TestNew_RetriesServerErrors      - Tests that a GET failing twice with 5xx succeeds on the third attempt.
+ *  - TestNew_RetriesExhausted         - Tests that the last 5xx response is returned after every retry failed.
+ *  - TestNew_RetriesNetworkErrors     - Tests that network errors from the transport are retried.
+ *  - TestNew_DoesNotRetry             - Tests that POSTs, 4xx responses and clients without retries are sent once.
+ *  - TestNew_CancelledContext         - Tests that a cancelled request context stops the retries.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"proh2052-group6/pkg/httpclient"
+)
+
+// testConfig returns the default config with a backoff short enough for tests.
+func testConfig() httpclient.Config {
+	cfg := httpclient.DefaultConfig()
+	cfg.RetryBackoff = time.Millisecond
+	return cfg
+}
+
+// failingServer answers the first failures requests with status and the rest with 200 OK.
+// The returned counter holds the number of requests received.
+func failingServer(t *testing.T, failures int32, status int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestNew_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := testConfig()
+	cfg.Timeout = 100 * time.Millisecond
+	start := time.Now()
+	_, err := httpclient.New(cfg).Get(server.URL)
+	if err == nil {
+		t.Fatal("Expected a timeout error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the call to give up after about 100ms, it took %v", elapsed)
+	}
+}
+
+func TestNew_RetriesServerErrors(t *testing.T) {
+	server, calls := failingServer(t, 2, http.StatusBadGateway)
+
+	resp, err := httpclient.New(testConfig()).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the third attempt to succeed, got %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || *calls != 3 {
+		t.Errorf("Expected status 200 after 3 attempts, got %d after %d", resp.StatusCode, *calls)
+	}
+}
+
+func TestNew_RetriesExhausted(t *testing.T) {
+	server, calls := failingServer(t, 10, http.StatusServiceUnavailable)
+
+	resp, err := httpclient.New(testConfig()).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the last response, got %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || *calls != 1+httpclient.DefaultRetries {
+		t.Errorf("Expected status 503 after %d attempts, got %d after %d", 1+httpclient.DefaultRetries, resp.StatusCode, *calls)
+	}
+}
+
+func TestNew_RetriesNetworkErrors(t *testing.T) {
+	server, _ := failingServer(t, 0, http.StatusOK)
+	var calls int32
+	cfg := testConfig()
+	cfg.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			return nil, errors.New("connection reset by peer")
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	resp, err := httpclient.New(cfg).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the third attempt to succeed, got %v", err)
+	}
+	resp.Body.Close()
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestNew_DoesNotRetry(t *testing.T) {
+	noRetries := testConfig()
+	noRetries.Retries = 0
+
+	tests := []struct {
+		name   string
+		cfg    httpclient.Config
+		status int
+		method string
+	}{
+		{"Post", testConfig(), http.StatusInternalServerError, http.MethodPost},
+		{"ClientError", testConfig(), http.StatusNotFound, http.MethodGet},
+		{"RetriesDisabled", noRetries, http.StatusInternalServerError, http.MethodGet},
+	}
+	for _, tt := range tests {
+		server, calls := failingServer(t, 10, tt.status)
+		req, _ := http.NewRequest(tt.method, server.URL, nil)
+		if tt.method == http.MethodPost {
+			req, _ = http.NewRequest(tt.method, server.URL, strings.NewReader(`{"country":"Norway"}`))
+		}
+		resp, err := httpclient.New(tt.cfg).Do(req)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status || *calls != 1 {
+			t.Errorf("%s: expected status %d after 1 attempt, got %d after %d", tt.name, tt.status, resp.StatusCode, *calls)
+		}
+	}
+}
+
+func TestNew_CancelledContext(t *testing.T) {
+	server, calls := failingServer(t, 10, http.StatusInternalServerError)
+	cfg := testConfig()
+	cfg.RetryBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	start := time.Now()
+	if _, err := httpclient.New(cfg).Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || *calls != 1 {
+		t.Errorf("Expected the backoff to end with the context after 1 attempt, took %v and %d attempts", elapsed, *calls)
+	}
+}
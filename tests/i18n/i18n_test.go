@@ -0,0 +1,170 @@
+/**
+ *  I18n Tests check that every message catalog translates every English message with the same
+ *  arguments, that missing translations fall back to English, and how locales are matched from
+ *  language tags and Accept-Language headers.
+ *
+ *  @file       i18n_test.go
+ *  @package    i18n_test
+ *
+ *  @test_cases
+ *  - TestCatalogs_NoMissingKeys   - Tests that each catalog has exactly the keys of the English one.
+ *  - TestCatalogs_FormatVerbs     - Tests that each translation takes the same fmt verbs as the English message.
+ *  - TestTranslate_Fallback       - Tests the fallback to English for unsupported locales and to the key for unknown keys.
+ *  - TestMatch                    - Tests matching language tags, regions and the Norwegian aliases.
+ *  - TestParseAcceptLanguage      - Tests ranking Accept-Language entries by q-value and position.
+ *  - TestLocale_Context           - Tests storing a locale in a context and the default without one.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package i18n_test
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"proh2052-group6/pkg/i18n"
+)
+
+func TestCatalogs_NoMissingKeys(t *testing.T) {
+	english := i18n.Keys(i18n.DefaultLocale)
+	if len(english) == 0 {
+		t.Fatalf("Expected the English catalog to have messages")
+	}
+	if locales := i18n.Locales(); !reflect.DeepEqual(locales, []string{"en", "nb"}) {
+		t.Errorf("Expected the locales en and nb, got %v", locales)
+	}
+
+	for _, locale := range i18n.Locales() {
+		keys := make(map[string]bool)
+		for _, key := range i18n.Keys(locale) {
+			keys[key] = true
+		}
+		for _, key := range english {
+			if !keys[key] {
+				t.Errorf("%s: missing translation of %q", locale, key)
+			}
+			delete(keys, key)
+		}
+		for key := range keys {
+			t.Errorf("%s: %q is not in the English catalog", locale, key)
+		}
+	}
+}
+
+// formatVerb matches the fmt verbs of a message.
+var formatVerb = regexp.MustCompile(`%[a-z]`)
+
+func TestCatalogs_FormatVerbs(t *testing.T) {
+	for _, locale := range i18n.Locales() {
+		for _, key := range i18n.Keys(i18n.DefaultLocale) {
+			want := formatVerb.FindAllString(i18n.Translate(i18n.DefaultLocale, key), -1)
+			got := formatVerb.FindAllString(i18n.Translate(locale, key), -1)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %q takes %v, but English takes %v", locale, key, got, want)
+			}
+		}
+	}
+}
+
+func TestTranslate_Fallback(t *testing.T) {
+	tests := []struct {
+		name, locale, key string
+		args              []interface{}
+		want              string
+	}{
+		{"English", "en", "friend.request_sent", nil, "Friend request sent"},
+		{"Norwegian", "nb", "friend.request_sent", nil, "Venneforespørsel sendt"},
+		{"arguments", "nb", "email.friend_request.body", []interface{}{"alice"}, "alice har sendt deg en venneforespørsel på DailyVerse."},
+		{"unsupported locale", "de", "friend.request_sent", nil, "Friend request sent"},
+		{"empty locale", "", "friend.request_sent", nil, "Friend request sent"},
+		{"unknown key", "nb", "no.such.key", nil, "no.such.key"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := i18n.Translate(test.locale, test.key, test.args...); got != test.want {
+				t.Errorf("Expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+		ok       bool
+	}{
+		{"en", "en", true},
+		{"EN-us", "en", true},
+		{"nb", "nb", true},
+		{"nb_NO", "nb", true},
+		{"no", "nb", true},
+		{"nn-NO", "nb", true},
+		{" nb ", "nb", true},
+		{"de", "", false},
+		{"", "", false},
+		{"*", "", false},
+	}
+	for _, test := range tests {
+		locale, ok := i18n.Match(test.language)
+		if ok != test.ok || ok && locale != test.want {
+			t.Errorf("Match(%q): expected %q/%v, got %q/%v", test.language, test.want, test.ok, locale, ok)
+		}
+	}
+
+	if locale, ok := i18n.MatchLanguages([]string{"se", "no"}); !ok || locale != "nb" {
+		t.Errorf("Expected the first supported language of a country to match, got %q/%v", locale, ok)
+	}
+	if _, ok := i18n.MatchLanguages([]string{"de", "fr"}); ok {
+		t.Errorf("Expected no match for unsupported languages")
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{"nb", "nb", true},
+		{"nb-NO,nb;q=0.9,en;q=0.5", "nb", true},
+		{"en;q=0.5, nb-NO", "nb", true},
+		{"de-DE, en;q=0.8, nb;q=0.7", "en", true},
+		{"nb;q=0.8, en;q=0.8", "nb", true},
+		{"nb;q=0, en;q=0.1", "en", true},
+		{"de, fr;q=0.9", "", false},
+		{"*", "", false},
+		{"", "", false},
+	}
+	for _, test := range tests {
+		locale, ok := i18n.ParseAcceptLanguage(test.header)
+		if ok != test.ok || locale != test.want {
+			t.Errorf("ParseAcceptLanguage(%q): expected %q/%v, got %q/%v", test.header, test.want, test.ok, locale, ok)
+		}
+	}
+}
+
+func TestLocale_Context(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := i18n.FromContext(ctx); ok {
+		t.Errorf("Expected no locale in an empty context")
+	}
+	if locale := i18n.Locale(ctx); locale != i18n.DefaultLocale {
+		t.Errorf("Expected the default locale, got %q", locale)
+	}
+
+	ctx = i18n.WithLocale(ctx, "nb")
+	if locale, ok := i18n.FromContext(ctx); !ok || locale != "nb" {
+		t.Errorf("Expected the stored locale, got %q/%v", locale, ok)
+	}
+	if message := i18n.T(ctx, "friend.request_sent"); message != "Venneforespørsel sendt" {
+		t.Errorf("Expected the Norwegian message, got %q", message)
+	}
+}
@@ -0,0 +1,213 @@
+/**
+ *  FirestoreEventRepository Integration Tests run the event repository against the Firestore emulator:
+ *  the CRUD operations on a user's events, date-filtered and tag-filtered paging, the collection group
+ *  query used by the reminder scheduler, and the lookups by external ID and recurrence.
+ *
+ *  @file       event_repository_test.go
+ *  @package    integration_test
+ *
+ *  @test_cases
+ *  - TestFirestoreEventRepository_CRUD               - Tests create, get, update and delete, and that IDs are assigned.
+ *  - TestFirestoreEventRepository_GetAllEvents       - Tests date ranges, tags, ordering and page tokens.
+ *  - TestFirestoreEventRepository_GetEventsBetween   - Tests the time range query across all users.
+ *  - TestFirestoreEventRepository_ExternalAndRecurring - Tests the external ID lookup and the recurring event query.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package integration_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// createEvents stores events, failing the test on the first error.
+func createEvents(t *testing.T, repo repositories.EventRepository, events ...*models.Event) {
+	t.Helper()
+	for _, event := range events {
+		if err := repo.CreateEvent(testContext(t), event); err != nil {
+			t.Fatalf("Failed to create event %q: %v", event.Title, err)
+		}
+	}
+}
+
+// eventTitles returns the titles of events, in order, joined by commas.
+func eventTitles(events []models.Event) string {
+	titles := make([]string, len(events))
+	for i, event := range events {
+		titles[i] = event.Title
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestFirestoreEventRepository_CRUD(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreEventRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	event := &models.Event{Email: "alice@example.com", Title: "Dinner", Date: "2024-05-01", StartTime: "18:00", Tags: []string{"food"}}
+	createEvents(t, repo, event)
+	if event.EventID == "" {
+		t.Fatalf("Expected CreateEvent to assign an EventID")
+	}
+
+	got, err := repo.GetEvent(ctx, "alice@example.com", event.EventID)
+	if err != nil || got.EventID != event.EventID || got.Title != "Dinner" || len(got.Tags) != 1 {
+		t.Fatalf("Expected the stored event, got %+v (err: %v)", got, err)
+	}
+	if _, err := repo.GetEvent(ctx, "bob@example.com", event.EventID); err == nil {
+		t.Errorf("Expected another user's lookup of the event to fail")
+	}
+
+	got.Title = "Late dinner"
+	got.StartTime = "20:00"
+	if err := repo.UpdateEvent(ctx, got); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	updated, _ := repo.GetEvent(ctx, "alice@example.com", event.EventID)
+	if updated.Title != "Late dinner" || updated.StartTime != "20:00" || updated.Date != "2024-05-01" {
+		t.Errorf("Expected the updated event, got %+v", updated)
+	}
+
+	if err := repo.DeleteEvent(ctx, "alice@example.com", event.EventID); err != nil {
+		t.Fatalf("Failed to delete event: %v", err)
+	}
+	if _, err := repo.GetEvent(ctx, "alice@example.com", event.EventID); err == nil {
+		t.Errorf("Expected the deleted event to be gone")
+	}
+}
+
+func TestFirestoreEventRepository_GetAllEvents(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreEventRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	createEvents(t, repo,
+		&models.Event{Email: "alice@example.com", Title: "May 3", Date: "2024-05-03", Tags: []string{"work"}},
+		&models.Event{Email: "alice@example.com", Title: "May 1", Date: "2024-05-01"},
+		&models.Event{Email: "alice@example.com", Title: "June 1", Date: "2024-06-01", Tags: []string{"work"}},
+		&models.Event{Email: "alice@example.com", Title: "May 2", Date: "2024-05-02", Tags: []string{"gym"}},
+		&models.Event{Email: "bob@example.com", Title: "Not alice's", Date: "2024-05-02"},
+	)
+
+	page, err := repo.GetAllEvents(ctx, "alice@example.com", models.EventQuery{})
+	if err != nil || eventTitles(page.Items) != "May 1,May 2,May 3,June 1" || page.NextPageToken != "" {
+		t.Errorf("Expected all of alice's events by date, got %q (err: %v)", eventTitles(page.Items), err)
+	}
+	for _, event := range page.Items {
+		if event.EventID == "" {
+			t.Errorf("Expected every listed event to carry its ID, got %+v", event)
+		}
+	}
+
+	page, _ = repo.GetAllEvents(ctx, "alice@example.com", models.EventQuery{From: "2024-05-02", To: "2024-05-31"})
+	if eventTitles(page.Items) != "May 2,May 3" {
+		t.Errorf("Expected the events in the date range, got %q", eventTitles(page.Items))
+	}
+
+	page, _ = repo.GetAllEvents(ctx, "alice@example.com", models.EventQuery{Tag: "work"})
+	if eventTitles(page.Items) != "May 3,June 1" {
+		t.Errorf("Expected the events tagged work, got %q", eventTitles(page.Items))
+	}
+
+	// Paging two at a time walks through all events without repeating any.
+	var titles []string
+	query := models.EventQuery{Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("Expected paging to end, got %q so far", strings.Join(titles, ","))
+		}
+		page, err := repo.GetAllEvents(ctx, "alice@example.com", query)
+		if err != nil {
+			t.Fatalf("Failed to fetch page: %v", err)
+		}
+		if len(page.Items) > 2 {
+			t.Errorf("Expected at most 2 events per page, got %d", len(page.Items))
+		}
+		for _, event := range page.Items {
+			titles = append(titles, event.Title)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		query.PageToken = page.NextPageToken
+	}
+	if got := strings.Join(titles, ","); got != "May 1,May 2,May 3,June 1" {
+		t.Errorf("Expected the pages to hold every event once, got %q", got)
+	}
+
+	if _, err := repo.GetAllEvents(ctx, "alice@example.com", models.EventQuery{PageToken: "no-such-event"}); err == nil {
+		t.Errorf("Expected an invalid page token to be rejected")
+	}
+}
+
+func TestFirestoreEventRepository_GetEventsBetween(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreEventRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	createEvents(t, repo,
+		&models.Event{Email: "alice@example.com", Title: "Alice's", StartAt: start.Add(2 * time.Hour)},
+		&models.Event{Email: "bob@example.com", Title: "Bob's", StartAt: start.Add(23 * time.Hour)},
+		&models.Event{Email: "bob@example.com", Title: "Too late", StartAt: start.Add(25 * time.Hour)},
+		&models.Event{Email: "carol@example.com", Title: "Too early", StartAt: start.Add(-time.Minute)},
+	)
+
+	events, err := repo.GetEventsBetween(ctx, start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to query events: %v", err)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].StartAt.Before(events[j].StartAt) })
+	if got := eventTitles(events); got != "Alice's,Bob's" {
+		t.Errorf("Expected the events of all users in the range, got %q", got)
+	}
+	if len(events) == 2 && (events[0].Email != "alice@example.com" || events[1].Email != "bob@example.com" || events[0].EventID == "") {
+		t.Errorf("Expected the events to keep their owners and IDs, got %+v", events)
+	}
+}
+
+func TestFirestoreEventRepository_ExternalAndRecurring(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreEventRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	imported := &models.Event{Email: "alice@example.com", Title: "Lecture", Date: "2024-05-01", ExternalID: "uid-1@ntnu"}
+	createEvents(t, repo,
+		imported,
+		&models.Event{Email: "alice@example.com", Title: "Daily", Date: "2024-05-01", Recurrence: &models.Recurrence{Frequency: "daily", Interval: 1}},
+		&models.Event{Email: "alice@example.com", Title: "Weekly", Date: "2024-05-02", Recurrence: &models.Recurrence{Frequency: "weekly", Interval: 2, Count: 5}},
+		&models.Event{Email: "alice@example.com", Title: "Once", Date: "2024-05-03"},
+		&models.Event{Email: "bob@example.com", Title: "Bob's daily", Date: "2024-05-01", Recurrence: &models.Recurrence{Frequency: "daily"}},
+	)
+
+	got, err := repo.GetEventByExternalID(ctx, "alice@example.com", "uid-1@ntnu")
+	if err != nil || got == nil || got.EventID != imported.EventID {
+		t.Errorf("Expected the imported event, got %+v (err: %v)", got, err)
+	}
+	if got, err := repo.GetEventByExternalID(ctx, "bob@example.com", "uid-1@ntnu"); err != nil || got != nil {
+		t.Errorf("Expected nil for another user's external ID, got %+v (err: %v)", got, err)
+	}
+
+	recurring, err := repo.GetRecurringEvents(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("Failed to query recurring events: %v", err)
+	}
+	sort.Slice(recurring, func(i, j int) bool { return recurring[i].Date < recurring[j].Date })
+	if got := eventTitles(recurring); got != "Daily,Weekly" {
+		t.Errorf("Expected alice's recurring events, got %q", got)
+	}
+	if len(recurring) == 2 && (recurring[1].Recurrence == nil || recurring[1].Recurrence.Interval != 2 || recurring[1].Recurrence.Count != 5) {
+		t.Errorf("Expected the recurrence rule to round-trip, got %+v", recurring[1].Recurrence)
+	}
+}
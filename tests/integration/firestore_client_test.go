@@ -0,0 +1,42 @@
+/**
+ *  Firestore Client Integration Tests check that NewFirestoreClient connects to the emulator and
+ *  passes its connectivity check.
+ *
+ *  @file       firestore_client_test.go
+ *  @package    integration_test
+ *
+ *  @test_cases
+ *  - TestNewFirestoreClient_Emulator - Tests the client connects and the readiness probe passes.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package integration_test
+
+import (
+	"os"
+	"testing"
+
+	"proh2052-group6/internal/services"
+)
+
+func TestNewFirestoreClient_Emulator(t *testing.T) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST not set; skipping Firestore integration test")
+	}
+	ctx := testContext(t)
+
+	client, err := services.NewFirestoreClient(ctx, "test-firestore-client")
+	if err != nil {
+		t.Fatalf("Expected to connect to the emulator, got %v", err)
+	}
+	defer client.Close()
+
+	if err := services.FirestoreHealthChecker(client)(ctx); err != nil {
+		t.Errorf("Expected the readiness probe to pass, got %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+/**
+ *  FirestoreFriendRepository Integration Tests run the friend repository against the Firestore emulator:
+ *  friend requests stored under `<sender>_<recipient>` composite document IDs, accepting them in a transaction,
+ *  the friend and request listings in both directions, blocks, and moving them to a new email.
+ *
+ *  @file       friend_repository_test.go
+ *  @package    integration_test
+ *
+ *  @test_cases
+ *  - TestFirestoreFriendRepository_FriendRequests     - Tests create, get, update and delete under the composite document ID.
+ *  - TestFirestoreFriendRepository_AcceptFriendRequest - Tests that only existing pending requests can be accepted.
+ *  - TestFirestoreFriendRepository_Listings           - Tests friends, pending and sent requests, and the batched friends query.
+ *  - TestFirestoreFriendRepository_Blocks             - Tests create, get, list and delete of blocks.
+ *  - TestFirestoreFriendRepository_MigrateFriendEmail - Tests re-keying requests and blocks after an email change.
+ *  - TestFirestoreFriendRepository_EscapedEmailIDs    - Tests requests between emails with "_" and requests stored under legacy raw IDs.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package integration_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// createFriendRequests stores friend requests, failing the test on the first error.
+func createFriendRequests(t *testing.T, repo repositories.FriendRepository, friends ...*models.Friend) {
+	t.Helper()
+	for _, friend := range friends {
+		if err := repo.CreateFriendRequest(testContext(t), friend); err != nil {
+			t.Fatalf("Failed to create friend request %s_%s: %v", friend.Email, friend.FriendEmail, err)
+		}
+	}
+}
+
+// friendPairs returns "sender>recipient" for each friend request, sorted and joined by commas.
+func friendPairs(friends []models.Friend) string {
+	pairs := make([]string, len(friends))
+	for i, friend := range friends {
+		pairs[i] = fmt.Sprintf("%s>%s", friend.Email, friend.FriendEmail)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func TestFirestoreFriendRepository_FriendRequests(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+	repo := repositories.NewFirestoreFriendRepository(client)
+	ctx := testContext(t)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	createFriendRequests(t, repo, &models.Friend{Email: "alice@example.com", FriendEmail: "bob@example.com", Status: "pending", CreatedAt: createdAt})
+
+	// The document ID is composed of the sender and the recipient, in that order.
+	if _, err := client.Collection("friends").Doc("alice@example.com_bob@example.com").Get(ctx); err != nil {
+		t.Errorf("Expected the request under alice@example.com_bob@example.com: %v", err)
+	}
+	got, err := repo.GetFriendRequest(ctx, "alice@example.com", "bob@example.com")
+	if err != nil || got == nil || got.Status != "pending" || !got.CreatedAt.Equal(createdAt) {
+		t.Fatalf("Expected the pending request, got %+v (err: %v)", got, err)
+	}
+	if got, err := repo.GetFriendRequest(ctx, "bob@example.com", "alice@example.com"); err != nil || got != nil {
+		t.Errorf("Expected nil for the reverse direction, got %+v (err: %v)", got, err)
+	}
+
+	if err := repo.UpdateFriendRequest(ctx, "alice@example.com", "bob@example.com", map[string]interface{}{"Status": "accepted"}); err != nil {
+		t.Fatalf("Failed to update friend request: %v", err)
+	}
+	got, _ = repo.GetFriendRequest(ctx, "alice@example.com", "bob@example.com")
+	if got == nil || got.Status != "accepted" || got.Email != "alice@example.com" {
+		t.Errorf("Expected the accepted request with its other fields kept, got %+v", got)
+	}
+
+	// Updating a missing request fails instead of creating a partial document.
+	if err := repo.UpdateFriendRequest(ctx, "carol@example.com", "bob@example.com", map[string]interface{}{"Status": "accepted"}); err == nil {
+		t.Errorf("Expected updating a missing request to fail")
+	}
+	if got, _ := repo.GetFriendRequest(ctx, "carol@example.com", "bob@example.com"); got != nil {
+		t.Errorf("Expected the update not to create the request, got %+v", got)
+	}
+
+	if err := repo.DeleteFriendRequest(ctx, "alice@example.com", "bob@example.com"); err != nil {
+		t.Fatalf("Failed to delete friend request: %v", err)
+	}
+	if got, err := repo.GetFriendRequest(ctx, "alice@example.com", "bob@example.com"); err != nil || got != nil {
+		t.Errorf("Expected the deleted request to be gone, got %+v (err: %v)", got, err)
+	}
+}
+
+func TestFirestoreFriendRepository_AcceptFriendRequest(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreFriendRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	createFriendRequests(t, repo, &models.Friend{Email: "alice@example.com", FriendEmail: "bob@example.com", Status: "pending"})
+
+	if err := repo.AcceptFriendRequest(ctx, "alice@example.com", "bob@example.com"); err != nil {
+		t.Fatalf("Failed to accept friend request: %v", err)
+	}
+	if got, _ := repo.GetFriendRequest(ctx, "alice@example.com", "bob@example.com"); got == nil || got.Status != "accepted" {
+		t.Errorf("Expected the request to be accepted, got %+v", got)
+	}
+
+	if err := repo.AcceptFriendRequest(ctx, "alice@example.com", "bob@example.com"); !errors.Is(err, repositories.ErrFriendRequestNotPending) {
+		t.Errorf("Expected ErrFriendRequestNotPending for an accepted request, got %v", err)
+	}
+	if err := repo.AcceptFriendRequest(ctx, "carol@example.com", "bob@example.com"); !errors.Is(err, repositories.ErrFriendRequestNotPending) {
+		t.Errorf("Expected ErrFriendRequestNotPending for a missing request, got %v", err)
+	}
+	if got, _ := repo.GetFriendRequest(ctx, "carol@example.com", "bob@example.com"); got != nil {
+		t.Errorf("Expected accepting a missing request not to create it, got %+v", got)
+	}
+}
+
+func TestFirestoreFriendRepository_Listings(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreFriendRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	createFriendRequests(t, repo,
+		&models.Friend{Email: "alice@example.com", FriendEmail: "bob@example.com", Status: "accepted"},
+		&models.Friend{Email: "carol@example.com", FriendEmail: "alice@example.com", Status: "accepted"},
+		&models.Friend{Email: "dave@example.com", FriendEmail: "alice@example.com", Status: "pending"},
+		&models.Friend{Email: "alice@example.com", FriendEmail: "erin@example.com", Status: "pending"},
+		&models.Friend{Email: "bob@example.com", FriendEmail: "carol@example.com", Status: "accepted"},
+	)
+
+	tests := []struct {
+		name string
+		list func() ([]models.Friend, error)
+		want string
+	}{
+		{"friends as sender and recipient", func() ([]models.Friend, error) { return repo.GetFriends(ctx, "alice@example.com") },
+			"alice@example.com>bob@example.com,carol@example.com>alice@example.com"},
+		{"pending requests received", func() ([]models.Friend, error) { return repo.GetPendingFriendRequests(ctx, "alice@example.com") },
+			"dave@example.com>alice@example.com"},
+		{"pending requests sent", func() ([]models.Friend, error) { return repo.GetSentFriendRequests(ctx, "alice@example.com") },
+			"alice@example.com>erin@example.com"},
+		{"friends of several users, each friendship once", func() ([]models.Friend, error) {
+			return repo.GetFriendsOfUsers(ctx, []string{"alice@example.com", "bob@example.com"})
+		}, "alice@example.com>bob@example.com,bob@example.com>carol@example.com,carol@example.com>alice@example.com"},
+		{"friends of no users", func() ([]models.Friend, error) { return repo.GetFriendsOfUsers(ctx, nil) }, ""},
+	}
+	for _, tt := range tests {
+		friends, err := tt.list()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := friendPairs(friends); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	// More users than fit in one "in" query are split into batches.
+	emails := make([]string, 25)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("user%02d@example.com", i)
+	}
+	emails[24] = "erin@example.com"
+	createFriendRequests(t, repo, &models.Friend{Email: "dave@example.com", FriendEmail: "erin@example.com", Status: "accepted"})
+	friends, err := repo.GetFriendsOfUsers(ctx, emails)
+	if err != nil || friendPairs(friends) != "dave@example.com>erin@example.com" {
+		t.Errorf("Expected erin's friendship from the last batch, got %q (err: %v)", friendPairs(friends), err)
+	}
+}
+
+func TestFirestoreFriendRepository_Blocks(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreFriendRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	for _, block := range []*models.Block{
+		{BlockerEmail: "alice@example.com", BlockedEmail: "bob@example.com", CreatedAt: time.Now()},
+		{BlockerEmail: "alice@example.com", BlockedEmail: "carol@example.com", CreatedAt: time.Now()},
+		{BlockerEmail: "bob@example.com", BlockedEmail: "alice@example.com", CreatedAt: time.Now()},
+	} {
+		if err := repo.CreateBlock(ctx, block); err != nil {
+			t.Fatalf("Failed to create block: %v", err)
+		}
+	}
+
+	if got, err := repo.GetBlock(ctx, "alice@example.com", "bob@example.com"); err != nil || got == nil || got.BlockedEmail != "bob@example.com" {
+		t.Errorf("Expected alice's block of bob, got %+v (err: %v)", got, err)
+	}
+	if got, err := repo.GetBlock(ctx, "carol@example.com", "alice@example.com"); err != nil || got != nil {
+		t.Errorf("Expected nil for a missing block, got %+v (err: %v)", got, err)
+	}
+
+	blocks, err := repo.GetBlockedUsers(ctx, "alice@example.com")
+	if err != nil || len(blocks) != 2 {
+		t.Errorf("Expected alice's 2 blocks, got %+v (err: %v)", blocks, err)
+	}
+
+	if err := repo.DeleteBlock(ctx, "alice@example.com", "bob@example.com"); err != nil {
+		t.Fatalf("Failed to delete block: %v", err)
+	}
+	if got, _ := repo.GetBlock(ctx, "alice@example.com", "bob@example.com"); got != nil {
+		t.Errorf("Expected the deleted block to be gone, got %+v", got)
+	}
+	if got, _ := repo.GetBlock(ctx, "bob@example.com", "alice@example.com"); got == nil {
+		t.Errorf("Expected bob's block of alice to be kept")
+	}
+}
+
+func TestFirestoreFriendRepository_MigrateFriendEmail(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreFriendRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	createFriendRequests(t, repo,
+		&models.Friend{Email: "alice@old.example.com", FriendEmail: "bob@example.com", Status: "accepted"},
+		&models.Friend{Email: "carol@example.com", FriendEmail: "alice@old.example.com", Status: "pending"},
+	)
+	if err := repo.CreateBlock(ctx, &models.Block{BlockerEmail: "dave@example.com", BlockedEmail: "alice@old.example.com"}); err != nil {
+		t.Fatalf("Failed to create block: %v", err)
+	}
+
+	if err := repo.MigrateFriendEmail(ctx, "alice@old.example.com", "alice@new.example.com"); err != nil {
+		t.Fatalf("Failed to migrate friend email: %v", err)
+	}
+
+	for _, pair := range [][2]string{{"alice@old.example.com", "bob@example.com"}, {"carol@example.com", "alice@old.example.com"}} {
+		if got, _ := repo.GetFriendRequest(ctx, pair[0], pair[1]); got != nil {
+			t.Errorf("Expected no request left under %s_%s, got %+v", pair[0], pair[1], got)
+		}
+	}
+	if got, _ := repo.GetFriendRequest(ctx, "alice@new.example.com", "bob@example.com"); got == nil || got.Email != "alice@new.example.com" || got.Status != "accepted" {
+		t.Errorf("Expected the sent request under the new email, got %+v", got)
+	}
+	if got, _ := repo.GetFriendRequest(ctx, "carol@example.com", "alice@new.example.com"); got == nil || got.FriendEmail != "alice@new.example.com" || got.Status != "pending" {
+		t.Errorf("Expected the received request under the new email, got %+v", got)
+	}
+	if got, _ := repo.GetBlock(ctx, "dave@example.com", "alice@old.example.com"); got != nil {
+		t.Errorf("Expected no block left under the old email, got %+v", got)
+	}
+	if got, _ := repo.GetBlock(ctx, "dave@example.com", "alice@new.example.com"); got == nil || got.BlockedEmail != "alice@new.example.com" {
+		t.Errorf("Expected the block under the new email, got %+v", got)
+	}
+}
+
+func TestFirestoreFriendRepository_EscapedEmailIDs(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+	repo := repositories.NewFirestoreFriendRepository(client)
+	ctx := testContext(t)
+
+	// Sending, accepting and listing work for emails containing the separator.
+	createFriendRequests(t, repo, &models.Friend{Email: "john_doe@example.com", FriendEmail: "doe_jane@example.com", Status: "pending"})
+	if _, err := client.Collection("friends").Doc("john%5Fdoe@example.com_doe%5Fjane@example.com").Get(ctx); err != nil {
+		t.Errorf("Expected the request under its escaped composite ID: %v", err)
+	}
+	if err := repo.AcceptFriendRequest(ctx, "john_doe@example.com", "doe_jane@example.com"); err != nil {
+		t.Fatalf("Failed to accept friend request: %v", err)
+	}
+	friends, err := repo.GetFriends(ctx, "doe_jane@example.com")
+	if err != nil || friendPairs(friends) != "john_doe@example.com>doe_jane@example.com" {
+		t.Errorf("Expected john_doe as jane's friend, got %q (err: %v)", friendPairs(friends), err)
+	}
+
+	// A request stored under the raw emails before escaping is still found and answered in place.
+	legacyID := "old_user@example.com_bob@example.com"
+	legacy := &models.Friend{Email: "old_user@example.com", FriendEmail: "bob@example.com", Status: "pending"}
+	if _, err := client.Collection("friends").Doc(legacyID).Set(ctx, legacy); err != nil {
+		t.Fatalf("Failed to store legacy request: %v", err)
+	}
+	if got, err := repo.GetFriendRequest(ctx, "old_user@example.com", "bob@example.com"); err != nil || got == nil || got.Status != "pending" {
+		t.Fatalf("Expected the legacy request, got %+v (err: %v)", got, err)
+	}
+	if err := repo.AcceptFriendRequest(ctx, "old_user@example.com", "bob@example.com"); err != nil {
+		t.Fatalf("Failed to accept legacy request: %v", err)
+	}
+	if doc, err := client.Collection("friends").Doc(legacyID).Get(ctx); err != nil || doc.Data()["Status"] != "accepted" {
+		t.Errorf("Expected the legacy request to be accepted in place (err: %v)", err)
+	}
+	if _, err := client.Collection("friends").Doc(repositories.CompositeID("old_user@example.com", "bob@example.com")).Get(ctx); err == nil {
+		t.Errorf("Expected no escaped copy of the legacy request")
+	}
+	if err := repo.DeleteFriendRequest(ctx, "old_user@example.com", "bob@example.com"); err != nil {
+		t.Fatalf("Failed to delete legacy request: %v", err)
+	}
+	if got, _ := repo.GetFriendRequest(ctx, "old_user@example.com", "bob@example.com"); got != nil {
+		t.Errorf("Expected the legacy request to be deleted, got %+v", got)
+	}
+}
@@ -0,0 +1,179 @@
+/**
+ *  FirestoreJournalRepository Integration Tests run the journal repository against the Firestore emulator:
+ *  the CRUD operations on a user's journals, the lookup by date, the filtered search and streaming
+ *  over a date range.
+ *
+ *  @file       journal_repository_test.go
+ *  @package    integration_test
+ *
+ *  @test_cases
+ *  - TestFirestoreJournalRepository_CRUD            - Tests create, get, get by date, update, list and delete.
+ *  - TestFirestoreJournalRepository_SearchJournals  - Tests the text and date filters, newest-first order and limit.
+ *  - TestFirestoreJournalRepository_StreamJournals  - Tests oldest-first streaming in a date range and stopping on an error.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package integration_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// createJournals stores a journal for alice on each of dates, with contents taken from the same index.
+func createJournals(t *testing.T, repo repositories.JournalRepository, dates, contents []string) {
+	t.Helper()
+	for i, date := range dates {
+		journal := &models.Journal{Email: "alice@example.com", Date: date, Content: contents[i]}
+		if err := repo.CreateJournal(testContext(t), journal); err != nil {
+			t.Fatalf("Failed to create journal for %s: %v", date, err)
+		}
+	}
+}
+
+// journalDates returns the dates of journals, in order, joined by commas.
+func journalDates(journals []models.Journal) string {
+	dates := make([]string, len(journals))
+	for i, journal := range journals {
+		dates[i] = journal.Date
+	}
+	return strings.Join(dates, ",")
+}
+
+func TestFirestoreJournalRepository_CRUD(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreJournalRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	journal := &models.Journal{Email: "alice@example.com", Date: "2024-05-01", Content: "A good day", Mood: "good", Tags: []string{"work"}}
+	if err := repo.CreateJournal(ctx, journal); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	if journal.JournalID == "" {
+		t.Fatalf("Expected CreateJournal to assign a JournalID")
+	}
+
+	got, err := repo.GetJournal(ctx, "alice@example.com", journal.JournalID)
+	if err != nil || got.JournalID != journal.JournalID || got.Content != "A good day" || got.Mood != "good" || len(got.Tags) != 1 {
+		t.Fatalf("Expected the stored journal, got %+v (err: %v)", got, err)
+	}
+	if _, err := repo.GetJournal(ctx, "bob@example.com", journal.JournalID); err == nil {
+		t.Errorf("Expected another user's lookup of the journal to fail")
+	}
+
+	byDate, err := repo.GetJournalByDate(ctx, "alice@example.com", "2024-05-01")
+	if err != nil || byDate == nil || byDate.JournalID != journal.JournalID {
+		t.Errorf("Expected the journal for 2024-05-01, got %+v (err: %v)", byDate, err)
+	}
+	if byDate, err := repo.GetJournalByDate(ctx, "alice@example.com", "2024-05-02"); err != nil || byDate != nil {
+		t.Errorf("Expected nil for a date without a journal, got %+v (err: %v)", byDate, err)
+	}
+
+	got.Content = "A great day"
+	got.Mood = "great"
+	if err := repo.UpdateJournal(ctx, got); err != nil {
+		t.Fatalf("Failed to update journal: %v", err)
+	}
+	updated, _ := repo.GetJournal(ctx, "alice@example.com", journal.JournalID)
+	if updated.Content != "A great day" || updated.Mood != "great" || updated.Date != "2024-05-01" {
+		t.Errorf("Expected the updated journal, got %+v", updated)
+	}
+
+	createJournals(t, repo, []string{"2024-05-02"}, []string{"Another day"})
+	journals, err := repo.GetAllJournals(ctx, "alice@example.com")
+	if err != nil || len(journals) != 2 {
+		t.Errorf("Expected alice's 2 journals, got %+v (err: %v)", journals, err)
+	}
+	if journals, _ := repo.GetAllJournals(ctx, "bob@example.com"); len(journals) != 0 {
+		t.Errorf("Expected no journals for bob, got %+v", journals)
+	}
+
+	if err := repo.DeleteJournal(ctx, "alice@example.com", journal.JournalID); err != nil {
+		t.Fatalf("Failed to delete journal: %v", err)
+	}
+	if _, err := repo.GetJournal(ctx, "alice@example.com", journal.JournalID); err == nil {
+		t.Errorf("Expected the deleted journal to be gone")
+	}
+}
+
+func TestFirestoreJournalRepository_SearchJournals(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreJournalRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	createJournals(t, repo,
+		[]string{"2024-05-03", "2024-05-01", "2024-05-04", "2024-05-02"},
+		[]string{"Went RUNNING", "Running in the rain", "Quiet day", "More running"},
+	)
+
+	tests := []struct {
+		query, from, to string
+		limit           int
+		want            string
+	}{
+		{"", "", "", 0, "2024-05-04,2024-05-03,2024-05-02,2024-05-01"},
+		{"running", "", "", 0, "2024-05-03,2024-05-02,2024-05-01"},
+		{"running", "", "", 2, "2024-05-03,2024-05-02"},
+		{"", "", "", 1, "2024-05-04"},
+		{"running", "2024-05-02", "2024-05-03", 0, "2024-05-03,2024-05-02"},
+		{"", "2024-05-04", "", 0, "2024-05-04"},
+		{"", "", "2024-05-01", 0, "2024-05-01"},
+		{"swimming", "", "", 0, ""},
+	}
+	for _, tt := range tests {
+		journals, err := repo.SearchJournals(ctx, "alice@example.com", tt.query, tt.from, tt.to, tt.limit)
+		if err != nil {
+			t.Fatalf("Failed to search for %q: %v", tt.query, err)
+		}
+		if got := journalDates(journals); got != tt.want {
+			t.Errorf("Search %q from %q to %q (limit %d): expected %q, got %q", tt.query, tt.from, tt.to, tt.limit, tt.want, got)
+		}
+		for _, journal := range journals {
+			if journal.JournalID == "" {
+				t.Errorf("Expected every found journal to carry its ID, got %+v", journal)
+			}
+		}
+	}
+}
+
+func TestFirestoreJournalRepository_StreamJournals(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreJournalRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	createJournals(t, repo,
+		[]string{"2024-05-03", "2024-05-01", "2024-05-04", "2024-05-02"},
+		[]string{"Third", "First", "Fourth", "Second"},
+	)
+
+	var streamed []models.Journal
+	err := repo.StreamJournals(ctx, "alice@example.com", "2024-05-02", "2024-05-04", func(journal models.Journal) error {
+		streamed = append(streamed, journal)
+		return nil
+	})
+	if err != nil || journalDates(streamed) != "2024-05-02,2024-05-03,2024-05-04" {
+		t.Errorf("Expected the journals in the range oldest first, got %q (err: %v)", journalDates(streamed), err)
+	}
+
+	errStop := errors.New("stop")
+	streamed = nil
+	err = repo.StreamJournals(ctx, "alice@example.com", "", "", func(journal models.Journal) error {
+		streamed = append(streamed, journal)
+		if len(streamed) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || journalDates(streamed) != "2024-05-01,2024-05-02" {
+		t.Errorf("Expected streaming to stop with the callback's error after 2 journals, got %q (err: %v)", journalDates(streamed), err)
+	}
+}
@@ -0,0 +1,138 @@
+/**
+ *  Memory Backend Server Integration Tests build and start the server with STORAGE_BACKEND=memory and
+ *  no Firestore settings, to check that local development needs no Google Cloud project.
+ *
+ *  @file       memory_server_test.go
+ *  @package    integration_test
+ *
+ *  @test_cases
+ *  - TestServer_MemoryBackendWithoutFirestore - Tests the server becomes ready without FIRESTORE_PROJECT_ID,
+ *                                               checks no Firestore readiness, and shuts down on SIGINT.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package integration_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// firestoreEnvVars are the variables that would point the server at a Firestore project.
+var firestoreEnvVars = []string{"FIRESTORE_PROJECT_ID", "GOOGLE_CLOUD_PROJECT", "FIRESTORE_EMULATOR_HOST", "GOOGLE_APPLICATION_CREDENTIALS"}
+
+// memoryServerEnv returns the environment of the test process without the Firestore variables,
+// plus the required settings of a server using the memory backend on port.
+func memoryServerEnv(port string) []string {
+	var env []string
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		keep := true
+		for _, firestoreVar := range firestoreEnvVars {
+			keep = keep && name != firestoreVar
+		}
+		if keep {
+			env = append(env, variable)
+		}
+	}
+	return append(env,
+		"STORAGE_BACKEND=memory", "RATE_LIMIT_STORE=memory", "PORT="+port,
+		"JWT_SECRET_KEY=secret", "NEWS_API_KEY=news-key", "SMTP_HOST=smtp.example.com", "SMTP_PORT=587",
+		"EMAIL_USER=noreply@example.com", "EMAIL_PASS=password",
+	)
+}
+
+// freePort returns a port nothing is listening on.
+func freePort(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	defer listener.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return port
+}
+
+func TestServer_MemoryBackendWithoutFirestore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping server build in short mode")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "server")
+	build := exec.Command("go", "build", "-o", binary, "../../cmd")
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build the server: %v\n%s", err, output)
+	}
+
+	port := freePort(t)
+	var logs bytes.Buffer
+	server := exec.Command(binary)
+	server.Dir = dir // No .env file is loaded from here.
+	server.Env = memoryServerEnv(port)
+	server.Stdout, server.Stderr = &logs, &logs
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start the server: %v", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- server.Wait() }()
+	stopped := false
+	defer func() {
+		if !stopped {
+			server.Process.Kill()
+			<-exited
+		}
+	}()
+
+	// Poll the readiness probe until the server is up, or fail if it exits first.
+	var body []byte
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		resp, err := http.Get("http://127.0.0.1:" + port + "/readyz")
+		if err == nil {
+			body, _ = io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				break
+			}
+		}
+		select {
+		case err := <-exited:
+			stopped = true
+			t.Fatalf("Expected the server to start, it exited with %v:\n%s", err, logs.String())
+		case <-time.After(100 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the server to become ready, last response %q:\n%s", body, logs.String())
+		}
+	}
+	if strings.Contains(string(body), "firestore") {
+		t.Errorf("Expected no Firestore readiness check in memory mode, got %s", body)
+	}
+
+	if err := server.Process.Signal(os.Interrupt); err != nil {
+		t.Fatalf("Failed to interrupt the server: %v", err)
+	}
+	select {
+	case err := <-exited:
+		stopped = true
+		if err != nil {
+			t.Errorf("Expected a clean shutdown, got %v:\n%s", err, logs.String())
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatalf("Expected the server to shut down:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,284 @@
+/**
+ *  FirestoreUserRepository Integration Tests run the user repository against the Firestore emulator:
+ *  creating, reading and updating users, the username lookup, the prefix search over usernames and
+ *  names, digest subscribers, and moving a user with their data to a new email.
+ *
+ *  @file       user_repository_test.go
+ *  @package    integration_test
+ *
+ *  @test_cases
+ *  - TestFirestoreUserRepository_CRUD              - Tests create, get by email and username, and partial updates.
+ *  - TestFirestoreUserRepository_UpdateMissingUser - Tests that updating a missing user fails instead of creating it.
+ *  - TestFirestoreUserRepository_SearchUsers       - Tests the case-insensitive prefix search, its order and limit.
+ *  - TestFirestoreUserRepository_GetDigestSubscribers - Tests that only users with the digest enabled are returned.
+ *  - TestFirestoreUserRepository_MigrateUserEmail  - Tests moving a user, their events and journals to a new email.
+ *  - TestFirestoreUserRepository_EscapedEmailIDs   - Tests escaped IDs for new users and the fallback to users stored under the raw email.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package integration_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+)
+
+// testUser returns a user with the lowercase search fields filled in, like Signup stores them.
+func testUser(email, username, firstName, lastName string) *models.User {
+	return &models.User{
+		Email:          email,
+		Username:       username,
+		UsernameLower:  strings.ToLower(username),
+		FirstName:      firstName,
+		FirstNameLower: strings.ToLower(firstName),
+		LastName:       lastName,
+		LastNameLower:  strings.ToLower(lastName),
+		Country:        "Norway",
+		City:           "Oslo",
+	}
+}
+
+// createUsers stores users, failing the test on the first error.
+func createUsers(t *testing.T, repo repositories.UserRepository, users ...*models.User) {
+	t.Helper()
+	for _, user := range users {
+		if err := repo.CreateUser(testContext(t), user); err != nil {
+			t.Fatalf("Failed to create user %s: %v", user.Email, err)
+		}
+	}
+}
+
+// userEmails returns the emails of users, in order, joined by commas.
+func userEmails(users []*models.User) string {
+	emails := make([]string, len(users))
+	for i, user := range users {
+		emails[i] = user.Email
+	}
+	return strings.Join(emails, ",")
+}
+
+func TestFirestoreUserRepository_CRUD(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreUserRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	user := testUser("alice@example.com", "Alice", "Alice", "Anderson")
+	user.Password = "hashed-password"
+	createUsers(t, repo, user)
+
+	got, err := repo.GetUserByEmail(ctx, "alice@example.com")
+	if err != nil || got.Username != "Alice" || got.Password != "hashed-password" || got.IsVerified {
+		t.Fatalf("Expected the stored user, got %+v (err: %v)", got, err)
+	}
+	if _, err := repo.GetUserByEmail(ctx, "nobody@example.com"); err == nil {
+		t.Errorf("Expected an error for a missing user")
+	}
+
+	// The username lookup ignores case.
+	got, err = repo.GetUserByUsername(ctx, "ALICE")
+	if err != nil || got.Email != "alice@example.com" {
+		t.Errorf("Expected alice by username, got %+v (err: %v)", got, err)
+	}
+	if _, err := repo.GetUserByUsername(ctx, "bob"); err == nil {
+		t.Errorf("Expected an error for a missing username")
+	}
+
+	// Updates change only the given fields, including clearing one with nil.
+	expiresAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	err = repo.UpdateUser(ctx, "alice@example.com", map[string]interface{}{
+		"IsVerified":   true,
+		"OTP":          "hashed-otp",
+		"OTPExpiresAt": expiresAt,
+		"City":         nil,
+	})
+	if err != nil {
+		t.Fatalf("Failed to update user: %v", err)
+	}
+	got, _ = repo.GetUserByEmail(ctx, "alice@example.com")
+	if !got.IsVerified || got.OTP != "hashed-otp" || !got.OTPExpiresAt.Equal(expiresAt) || got.City != "" {
+		t.Errorf("Expected the updated fields, got %+v", got)
+	}
+	if got.Password != "hashed-password" || got.Username != "Alice" {
+		t.Errorf("Expected the other fields to be kept, got %+v", got)
+	}
+}
+
+func TestFirestoreUserRepository_UpdateMissingUser(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreUserRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	if err := repo.UpdateUser(ctx, "ghost@example.com", map[string]interface{}{"IsVerified": true}); err == nil {
+		t.Errorf("Expected updating a missing user to fail")
+	}
+	if _, err := repo.GetUserByEmail(ctx, "ghost@example.com"); err == nil {
+		t.Errorf("Expected the update not to create the user")
+	}
+}
+
+func TestFirestoreUserRepository_SearchUsers(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreUserRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	createUsers(t, repo,
+		testUser("john@example.com", "JohnDoe", "John", "Doe"),
+		testUser("jane@example.com", "jsmith", "Jane", "Smith"),
+		testUser("doe@example.com", "doelover", "Mary", "Jones"),
+		testUser("bob@example.com", "bob", "Bob", "Builder"),
+	)
+
+	tests := []struct {
+		query string
+		limit int
+		want  string
+	}{
+		// Username matches come first, then first name and last name matches, each user once.
+		{"j", 10, "john@example.com,jane@example.com,doe@example.com"},
+		{"DOE", 10, "doe@example.com,john@example.com"},
+		{"jo", 10, "john@example.com,doe@example.com"},
+		{"smi", 10, "jane@example.com"},
+		{"x", 10, ""},
+		// The limit applies to each field.
+		{"j", 1, "john@example.com,jane@example.com,doe@example.com"},
+	}
+	for _, tt := range tests {
+		users, err := repo.SearchUsers(ctx, tt.query, tt.limit)
+		if err != nil {
+			t.Fatalf("Failed to search for %q: %v", tt.query, err)
+		}
+		if got := userEmails(users); got != tt.want {
+			t.Errorf("Search for %q (limit %d): expected %q, got %q", tt.query, tt.limit, tt.want, got)
+		}
+	}
+}
+
+func TestFirestoreUserRepository_GetDigestSubscribers(t *testing.T) {
+	t.Parallel()
+	repo := repositories.NewFirestoreUserRepository(newTestClient(t))
+	ctx := testContext(t)
+
+	subscriber := testUser("alice@example.com", "alice", "", "")
+	subscriber.DigestEnabled = true
+	createUsers(t, repo, subscriber, testUser("bob@example.com", "bob", "", ""))
+
+	users, err := repo.GetDigestSubscribers(ctx)
+	if err != nil || userEmails(users) != "alice@example.com" {
+		t.Errorf("Expected only alice, got %q (err: %v)", userEmails(users), err)
+	}
+
+	// Turning the digest on through an update makes the user a subscriber.
+	if err := repo.UpdateUser(ctx, "bob@example.com", map[string]interface{}{"DigestEnabled": true}); err != nil {
+		t.Fatalf("Failed to update user: %v", err)
+	}
+	users, _ = repo.GetDigestSubscribers(ctx)
+	if len(users) != 2 {
+		t.Errorf("Expected 2 subscribers, got %q", userEmails(users))
+	}
+}
+
+func TestFirestoreUserRepository_MigrateUserEmail(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+	repo := repositories.NewFirestoreUserRepository(client)
+	eventRepo := repositories.NewFirestoreEventRepository(client)
+	journalRepo := repositories.NewFirestoreJournalRepository(client)
+	ctx := testContext(t)
+
+	createUsers(t, repo, testUser("alice@old.example.com", "alice", "", ""), testUser("taken@example.com", "taken", "", ""))
+	event := &models.Event{Email: "alice@old.example.com", Title: "Dinner", Date: "2024-05-01"}
+	if err := eventRepo.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	journal := &models.Journal{Email: "alice@old.example.com", Date: "2024-05-01", Content: "A good day"}
+	if err := journalRepo.CreateJournal(ctx, journal); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+
+	// An email that is already registered is refused and nothing moves.
+	if err := repo.MigrateUserEmail(ctx, "alice@old.example.com", "taken@example.com"); err == nil {
+		t.Errorf("Expected moving to a registered email to fail")
+	}
+	if got, err := repo.GetUserByEmail(ctx, "taken@example.com"); err != nil || got.Username != "taken" {
+		t.Errorf("Expected the registered user to be untouched, got %+v (err: %v)", got, err)
+	}
+
+	if err := repo.MigrateUserEmail(ctx, "alice@old.example.com", "alice@new.example.com"); err != nil {
+		t.Fatalf("Failed to move user: %v", err)
+	}
+	if _, err := repo.GetUserByEmail(ctx, "alice@old.example.com"); err == nil {
+		t.Errorf("Expected the old user document to be deleted")
+	}
+	if got, err := repo.GetUserByEmail(ctx, "alice@new.example.com"); err != nil || got.Email != "alice@new.example.com" || got.Username != "alice" {
+		t.Errorf("Expected the user under the new email, got %+v (err: %v)", got, err)
+	}
+
+	movedEvent, err := eventRepo.GetEvent(ctx, "alice@new.example.com", event.EventID)
+	if err != nil || movedEvent.Email != "alice@new.example.com" || movedEvent.Title != "Dinner" {
+		t.Errorf("Expected the event under the new email with the same ID, got %+v (err: %v)", movedEvent, err)
+	}
+	movedJournal, err := journalRepo.GetJournal(ctx, "alice@new.example.com", journal.JournalID)
+	if err != nil || movedJournal.Email != "alice@new.example.com" {
+		t.Errorf("Expected the journal under the new email with the same ID, got %+v (err: %v)", movedJournal, err)
+	}
+	if journals, _ := journalRepo.GetAllJournals(ctx, "alice@old.example.com"); len(journals) != 0 {
+		t.Errorf("Expected no journals left under the old email, got %d", len(journals))
+	}
+}
+
+func TestFirestoreUserRepository_EscapedEmailIDs(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+	repo := repositories.NewFirestoreUserRepository(client)
+	eventRepo := repositories.NewFirestoreEventRepository(client)
+	ctx := testContext(t)
+
+	// New users are stored under the escaped email, and their events under that document.
+	createUsers(t, repo, testUser("john_doe@example.com", "john", "", ""))
+	if _, err := client.Collection("users").Doc("john%5Fdoe@example.com").Get(ctx); err != nil {
+		t.Errorf("Expected the user under john%%5Fdoe@example.com: %v", err)
+	}
+	if err := eventRepo.CreateEvent(ctx, &models.Event{Email: "john_doe@example.com", Title: "Lunch", Date: "2024-05-01"}); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if events, err := client.Collection("users").Doc("john%5Fdoe@example.com").Collection("events").Documents(ctx).GetAll(); err != nil || len(events) != 1 {
+		t.Errorf("Expected the event under the escaped user document, got %d (err: %v)", len(events), err)
+	}
+
+	// A user created before escaping keeps their raw ID for reads, updates and subcollections.
+	legacy := testUser("jane_doe@example.com", "jane", "", "")
+	if _, err := client.Collection("users").Doc("jane_doe@example.com").Set(ctx, legacy); err != nil {
+		t.Fatalf("Failed to store legacy user: %v", err)
+	}
+	if got, err := repo.GetUserByEmail(ctx, "jane_doe@example.com"); err != nil || got.Username != "jane" {
+		t.Fatalf("Expected the legacy user, got %+v (err: %v)", got, err)
+	}
+	if err := repo.UpdateUser(ctx, "jane_doe@example.com", map[string]interface{}{"City": "Bergen"}); err != nil {
+		t.Fatalf("Failed to update legacy user: %v", err)
+	}
+	if _, err := client.Collection("users").Doc("jane%5Fdoe@example.com").Get(ctx); err == nil {
+		t.Errorf("Expected the update not to create an escaped user document")
+	}
+	if got, _ := repo.GetUserByEmail(ctx, "jane_doe@example.com"); got == nil || got.City != "Bergen" {
+		t.Errorf("Expected the legacy user to be updated in place, got %+v", got)
+	}
+	event := &models.Event{Email: "jane_doe@example.com", Title: "Dinner", Date: "2024-05-01"}
+	if err := eventRepo.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if _, err := client.Collection("users").Doc("jane_doe@example.com").Collection("events").Doc(event.EventID).Get(ctx); err != nil {
+		t.Errorf("Expected the event under the legacy user document: %v", err)
+	}
+	if got, err := eventRepo.GetEvent(ctx, "jane_doe@example.com", event.EventID); err != nil || got.Title != "Dinner" {
+		t.Errorf("Expected the event of the legacy user, got %+v (err: %v)", got, err)
+	}
+}
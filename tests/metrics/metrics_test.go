@@ -0,0 +1,181 @@
+/**
+ *  Metrics Tests validate that requests, upstream calls and rate limit rejections are recorded,
+ *  by scraping each test's own registry through the /metrics handler.
+ *
+ *  @file       metrics_test.go
+ *  @package    metrics_test
+ *
+ *  @test_cases
+ *  - TestInstrument_CountsByRouteTemplate   - Tests that requests are counted by route template, method and status.
+ *  - TestHandler_Token                      - Tests that a configured token is required to scrape.
+ *  - TestTransport_RecordsNewsAPILatency    - Tests that news API calls are timed with their status class.
+ *  - TestObserveOperation_TimedRepository   - Tests that repository calls are timed with their outcome.
+ *  - TestCountRejections                    - Tests that only the requests a rate limiter rejects are counted.
+ *
+ *  @dependencies
+ *  - metrics.New, metrics.Handler: Record the metrics in, and serve them from, a test registry.
+ *  - mocks.MockUserRepository: Stands in for Firestore behind a timed repository.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+
+	"proh2052-group6/internal/handlers"
+	"proh2052-group6/internal/metrics"
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// scrape returns the metrics served for registry by the /metrics handler.
+func scrape(t *testing.T, registry *prometheus.Registry) string {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	metrics.Handler(registry, "").ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected the scrape to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+	return rr.Body.String()
+}
+
+// assertMetric fails the test unless the scraped metrics contain the given sample line.
+func assertMetric(t *testing.T, scraped, sample string) {
+	t.Helper()
+	if !strings.Contains(scraped, sample+"\n") {
+		t.Errorf("Expected the sample %q, got:\n%s", sample, scraped)
+	}
+}
+
+func TestInstrument_CountsByRouteTemplate(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	appMetrics := metrics.New(registry)
+	healthHandler := handlers.NewHealthHandler(services.NewHealthService(nil))
+
+	router := mux.NewRouter()
+	router.Use(appMetrics.Instrument)
+	router.HandleFunc("/healthz", healthHandler.Liveness).Methods("GET")
+	router.HandleFunc("/api/events/get", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Event not found", http.StatusNotFound)
+	}).Methods("GET")
+
+	for _, target := range []string{"/healthz", "/healthz", "/api/events/get?eventID=1", "/api/events/get?eventID=2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+	}
+
+	scraped := scrape(t, registry)
+	assertMetric(t, scraped, `dailyverse_http_requests_total{method="GET",route="/healthz",status="200"} 2`)
+	assertMetric(t, scraped, `dailyverse_http_requests_total{method="GET",route="/api/events/get",status="404"} 2`)
+	assertMetric(t, scraped, `dailyverse_http_request_duration_seconds_count{method="GET",route="/healthz",status="200"} 2`)
+	if strings.Contains(scraped, "eventID") {
+		t.Errorf("Expected query strings to be left out of the route label, got:\n%s", scraped)
+	}
+}
+
+func TestHandler_Token(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics.New(registry)
+	handler := metrics.Handler(registry, "scrape-secret")
+
+	tests := []struct {
+		name          string
+		authorization string
+		expectedCode  int
+	}{
+		{"Missing token", "", http.StatusUnauthorized},
+		{"Wrong token", "Bearer wrong", http.StatusUnauthorized},
+		{"Valid token", "Bearer scrape-secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, rr.Code)
+			}
+		})
+	}
+}
+
+func TestTransport_RecordsNewsAPILatency(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	appMetrics := metrics.New(registry)
+	newsAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status":"error","results":{"message":"Down for maintenance","code":"Unavailable"}}`))
+	}))
+	defer newsAPI.Close()
+
+	newsService := &services.NewsService{
+		HTTPClient: &http.Client{Transport: appMetrics.Transport("news", nil)},
+		NewsAPIURL: newsAPI.URL,
+	}
+	if _, err := newsService.FetchNews(context.Background(), "", "general", "", "", "", "", false); err == nil {
+		t.Fatal("Expected the news API error to be returned")
+	}
+
+	assertMetric(t, scrape(t, registry), `dailyverse_upstream_request_duration_seconds_count{operation="GET",outcome="5xx",upstream="news"} 1`)
+}
+
+func TestObserveOperation_TimedRepository(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	appMetrics := metrics.New(registry)
+	userRepo := repositories.NewTimedUserRepository(mocks.NewMockUserRepository(map[string]*models.User{
+		"test@example.com": {Email: "test@example.com"},
+	}), appMetrics)
+
+	if _, err := userRepo.GetUserByEmail(context.Background(), "test@example.com"); err != nil {
+		t.Fatalf("Expected the user to be found, got %v", err)
+	}
+	if _, err := userRepo.GetUserByEmail(context.Background(), "missing@example.com"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound to be passed through, got %v", err)
+	}
+
+	scraped := scrape(t, registry)
+	assertMetric(t, scraped, `dailyverse_upstream_request_duration_seconds_count{operation="UserRepository.GetUserByEmail",outcome="ok",upstream="firestore"} 1`)
+	assertMetric(t, scraped, `dailyverse_upstream_request_duration_seconds_count{operation="UserRepository.GetUserByEmail",outcome="not_found",upstream="firestore"} 1`)
+}
+
+func TestCountRejections(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	appMetrics := metrics.New(registry)
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	login := appMetrics.CountRejections("login", middleware.NewRateLimiter(rate.Every(time.Hour), 2))(okHandler)
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		rr := httptest.NewRecorder()
+		login.ServeHTTP(rr, httptest.NewRequest("POST", "/api/login", nil))
+		codes = append(codes, rr.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("Expected two allowed requests and one rejection, got %v", codes)
+	}
+
+	assertMetric(t, scrape(t, registry), `dailyverse_rate_limit_rejections_total{limiter="login"} 1`)
+}
@@ -0,0 +1,32 @@
+/**
+ *  MockAuditRepository is the in-memory AuditRepository of the memory package, used for testing
+ *  the account audit log without relying on a database.
+ *
+ *  @file       mock_audit_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockAuditRepository() - Creates an empty memory.AuditRepository.
+ *
+ *  @behaviors
+ *  - Tests seed and inspect the repository through its Entries map, keyed by user email.
+ *  - Setting Err makes every method fail with it, e.g. repositories.ErrUnavailable to simulate an outage.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import "proh2052-group6/internal/repositories/memory"
+
+// MockAuditRepository is the in-memory AuditRepository.
+type MockAuditRepository = memory.AuditRepository
+
+// NewMockAuditRepository creates an empty in-memory AuditRepository.
+func NewMockAuditRepository() *MockAuditRepository {
+	return memory.NewAuditRepository()
+}
@@ -11,14 +11,14 @@
  *    `GetCitiesByCountry` for specific test cases.
  *
  *  @methods
- *  - GetCitiesByCountry(country) ([]string, error): Calls the mock function to simulate fetching cities
+ *  - GetCitiesByCountry(ctx, country) ([]string, error): Calls the mock function to simulate fetching cities
  *    by country. If the mock function is not defined, it returns a default error.
  *
  *  @example
  *  ```
  *  // Define mock behavior
  *  mockCityService := &MockCityService{
- *      GetCitiesByCountryFunc: func(country string) ([]string, error) {
+ *      GetCitiesByCountryFunc: func(ctx context.Context, country string) ([]string, error) {
  *          if country == "TestCountry" {
  *              return []string{"City1", "City2"}, nil
  *          }
@@ -27,7 +27,7 @@
  *  }
  *
  *  // Call the mocked method
- *  cities, err := mockCityService.GetCitiesByCountry("TestCountry")
+ *  cities, err := mockCityService.GetCitiesByCountry(ctx, "TestCountry")
  *  fmt.Println(cities) // Output: [City1 City2]
  *  ```
  *
@@ -39,20 +39,21 @@
 package mocks
 
 import (
+	"context"
 	"fmt"
 )
 
 // MockCityService is a mock implementation of the CityServiceInterface.
 // It allows you to define custom behavior for the GetCitiesByCountry method.
 type MockCityService struct {
-	GetCitiesByCountryFunc func(country string) ([]string, error)
+	GetCitiesByCountryFunc func(ctx context.Context, country string) ([]string, error)
 }
 
 // GetCitiesByCountry calls the mocked GetCitiesByCountryFunc if it's set.
 // Otherwise, it returns nil or a default error.
-func (m *MockCityService) GetCitiesByCountry(country string) ([]string, error) {
+func (m *MockCityService) GetCitiesByCountry(ctx context.Context, country string) ([]string, error) {
 	if m.GetCitiesByCountryFunc != nil {
-		return m.GetCitiesByCountryFunc(country)
+		return m.GetCitiesByCountryFunc(ctx, country)
 	}
 	return nil, fmt.Errorf("GetCitiesByCountryFunc not implemented")
 }
@@ -8,14 +8,25 @@
  *
  *  @fields
  *  - SentEmails ([]Email): A slice to store the details of emails sent during the test.
+ *  - Err (error): When set, SendEmail returns this error instead of capturing the email.
+ *  - FailFor (map[string]error): Errors SendBulk reports for the given recipients instead of capturing their emails.
+ *  - BulkSends ([][]Email): The emails of each SendBulk call, so tests can assert batching happened.
  *
  *  @struct   Email
  *  - To (string): The recipient's email address.
  *  - Subject (string): The email subject.
- *  - Body (string): The email body content.
+ *  - Body (string): The email body content; the plaintext part of multipart emails.
+ *  - HTML (string): The HTML part of multipart emails.
+ *  - Async (bool): Whether the email was sent with SendEmailAsync or SendMultipartEmailAsync.
+ *  - Bulk (bool): Whether the email was sent with SendBulk.
  *
  *  @methods
  *  - SendEmail(toEmail, subject, body) (error): Captures the email details and appends them to the SentEmails slice.
+ *  - SendEmailAsync(ctx, toEmail, subject, body) (error): Captures the email like SendEmail, marked as Async.
+ *  - SendMultipartEmail(toEmail, msg) (error): Captures a rendered email with its plaintext and HTML parts.
+ *  - SendMultipartEmailAsync(ctx, toEmail, msg) (error): Captures a rendered email, marked as Async.
+ *  - SendBulk(ctx, messages) (*BulkResult, error): Captures the delivered emails in SentEmails and the batch in BulkSends.
+ *  - LastOTP() (string): Returns the 6-digit OTP from the most recent email, since only its hash is stored.
  *
  *  @example
  *  ```
@@ -42,17 +53,37 @@
 
 package mocks
 
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"proh2052-group6/internal/services"
+)
+
 // MockEmailService is a mock implementation of the EmailServiceInterface.
 type MockEmailService struct {
 	// SentEmails stores the details of all emails sent during testing.
 	SentEmails []Email
+
+	// Err, when set, is returned by SendEmail to simulate a delivery failure.
+	Err error
+
+	// FailFor maps recipients to the error SendBulk reports for their emails.
+	FailFor map[string]error
+
+	// BulkSends stores the delivered emails of each SendBulk call.
+	BulkSends [][]Email
 }
 
 // Email represents the details of an email sent using the mock service.
 type Email struct {
 	To      string // Recipient's email address
 	Subject string // Email subject
-	Body    string // Email body content
+	Body    string // Email body content; the plaintext part of multipart emails
+	HTML    string // HTML part of multipart emails
+	Async   bool   // Whether the email was queued with SendEmailAsync or SendMultipartEmailAsync
+	Bulk    bool   // Whether the email was sent with SendBulk
 }
 
 // SendEmail simulates sending an email by capturing its details.
@@ -62,9 +93,79 @@ type Email struct {
 // - body (string): Body content of the email.
 //
 // Returns:
-// - error: Always returns nil, as this is a simulation.
+// - error: Err if set, otherwise nil, as this is a simulation.
 func (mes *MockEmailService) SendEmail(toEmail, subject, body string) error {
+	if mes.Err != nil {
+		return mes.Err
+	}
 	// Append the email details to the SentEmails slice.
 	mes.SentEmails = append(mes.SentEmails, Email{To: toEmail, Subject: subject, Body: body})
 	return nil
 }
+
+// SendEmailAsync simulates queueing an email. The email is captured immediately, so tests
+// can read it without waiting for a queue.
+func (mes *MockEmailService) SendEmailAsync(ctx context.Context, toEmail, subject, body string) error {
+	if mes.Err != nil {
+		return mes.Err
+	}
+	mes.SentEmails = append(mes.SentEmails, Email{To: toEmail, Subject: subject, Body: body, Async: true})
+	return nil
+}
+
+// SendMultipartEmail simulates sending a rendered email by capturing its subject and both parts.
+func (mes *MockEmailService) SendMultipartEmail(toEmail string, msg services.EmailMessage) error {
+	if mes.Err != nil {
+		return mes.Err
+	}
+	mes.SentEmails = append(mes.SentEmails, Email{To: toEmail, Subject: msg.Subject, Body: msg.Text, HTML: msg.HTML})
+	return nil
+}
+
+// SendMultipartEmailAsync simulates queueing a rendered email, capturing it immediately.
+func (mes *MockEmailService) SendMultipartEmailAsync(ctx context.Context, toEmail string, msg services.EmailMessage) error {
+	if mes.Err != nil {
+		return mes.Err
+	}
+	mes.SentEmails = append(mes.SentEmails, Email{To: toEmail, Subject: msg.Subject, Body: msg.Text, HTML: msg.HTML, Async: true})
+	return nil
+}
+
+// SendBulk simulates sending rendered emails in one batch. Emails to recipients in FailFor, or all
+// emails when Err is set, fail; the others are captured in SentEmails and, together, in BulkSends.
+func (mes *MockEmailService) SendBulk(ctx context.Context, messages []services.EmailMessage) (*services.BulkResult, error) {
+	result := &services.BulkResult{}
+	var batch []Email
+	for _, msg := range messages {
+		err := mes.Err
+		if err == nil {
+			err = mes.FailFor[msg.To]
+		}
+		result.Recipients = append(result.Recipients, services.BulkRecipientResult{To: msg.To, Err: err})
+		if err == nil {
+			batch = append(batch, Email{To: msg.To, Subject: msg.Subject, Body: msg.Text, HTML: msg.HTML, Bulk: true})
+		}
+	}
+	mes.SentEmails = append(mes.SentEmails, batch...)
+	mes.BulkSends = append(mes.BulkSends, batch)
+
+	if failed := len(result.Failed()); failed > 0 {
+		return result, fmt.Errorf("Failed to send %d of %d emails", failed, len(messages))
+	}
+	return result, nil
+}
+
+// otpRegex matches the 6-digit OTP in an email body.
+var otpRegex = regexp.MustCompile(`\b\d{6}\b`)
+
+// LastOTP returns the OTP contained in the most recently sent email.
+// Services store only a hash of the OTP, so tests read the raw value from the email instead.
+//
+// Returns:
+// - string: The 6-digit OTP, or an empty string if no email containing one was sent.
+func (mes *MockEmailService) LastOTP() string {
+	if len(mes.SentEmails) == 0 {
+		return ""
+	}
+	return otpRegex.FindString(mes.SentEmails[len(mes.SentEmails)-1].Body)
+}
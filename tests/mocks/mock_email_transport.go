@@ -0,0 +1,93 @@
+/**
+ *  MockEmailTransport is a fake EmailTransport for testing email delivery without an SMTP server.
+ *  It records every delivery attempt and can be told to fail, so tests can check retries.
+ *
+ *  @struct   MockEmailTransport
+ *  @inherits EmailTransport
+ *
+ *  @fields
+ *  - FailFirst (int): Number of attempts that fail before deliveries succeed.
+ *  - Err (error): The error returned by failing attempts; a generic error if nil.
+ *  - Delay (time.Duration): How long each attempt takes, unless its context ends first.
+ *
+ *  @methods
+ *  - Send(ctx, toEmail, msg) (error): Records the attempt and fails or delivers it.
+ *  - Attempts() (int): Returns the number of delivery attempts so far.
+ *  - Delivered() ([]string): Returns the recipients of the successful deliveries, in order.
+ *  - Messages() ([][]byte): Returns the messages of the successful deliveries, in order.
+ *
+ *  @file      mock_email_transport.go
+ *  @project   DailyVerse
+ *  @framework Go Testing with Mock Services
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// MockEmailTransport is a fake EmailTransport that records delivery attempts.
+type MockEmailTransport struct {
+	FailFirst int           // Number of attempts that fail before deliveries succeed.
+	Err       error         // Error returned by failing attempts; a generic error if nil.
+	Delay     time.Duration // Duration of each attempt.
+
+	mutex     sync.Mutex
+	attempts  int
+	delivered []string
+	messages  [][]byte
+}
+
+// Send records a delivery attempt. The first FailFirst attempts fail; later ones deliver the email.
+func (mt *MockEmailTransport) Send(ctx context.Context, toEmail string, msg []byte) error {
+	if mt.Delay > 0 {
+		select {
+		case <-time.After(mt.Delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	mt.mutex.Lock()
+	defer mt.mutex.Unlock()
+	mt.attempts++
+	if mt.attempts <= mt.FailFirst {
+		if mt.Err != nil {
+			return mt.Err
+		}
+		return errors.New("smtp: temporary failure")
+	}
+	mt.delivered = append(mt.delivered, toEmail)
+	mt.messages = append(mt.messages, msg)
+	return nil
+}
+
+// Attempts returns the number of delivery attempts so far.
+func (mt *MockEmailTransport) Attempts() int {
+	mt.mutex.Lock()
+	defer mt.mutex.Unlock()
+	return mt.attempts
+}
+
+// Delivered returns the recipients of the successful deliveries, in order.
+func (mt *MockEmailTransport) Delivered() []string {
+	mt.mutex.Lock()
+	defer mt.mutex.Unlock()
+	return append([]string(nil), mt.delivered...)
+}
+
+// Messages returns the messages of the successful deliveries, in order.
+func (mt *MockEmailTransport) Messages() [][]byte {
+	mt.mutex.Lock()
+	defer mt.mutex.Unlock()
+	return append([][]byte(nil), mt.messages...)
+}
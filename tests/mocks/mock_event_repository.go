@@ -0,0 +1,76 @@
+/**
+ *  MockEventRepository wraps the in-memory EventRepository of the memory package with partial
+ *  failures of bulk writes, for testing event-related services without relying on a database.
+ *
+ *  @file       mock_event_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockEventRepository()               - Creates a MockEventRepository over an empty memory.EventRepository.
+ *  - CreateEvents(ctx, events)              - Creates several events, failing those in FailEventTitles.
+ *  - DeleteEvents(ctx, userEmail, eventIDs) - Deletes several events, failing those in FailEventIDs.
+ *
+ *  @behaviors
+ *  - Every other method is the memory.EventRepository's. Tests seed and inspect the repository
+ *    through its Events map, keyed by EventID, and setting Err makes every method fail with it.
+ *  - Bulk writes fail per event for the titles in FailEventTitles and the IDs in FailEventIDs, to simulate partial failures.
+ *
+ *  @example
+ *  ```
+ *  repo := NewMockEventRepository()
+ *  err := repo.CreateEvent(ctx, &models.Event{Email: "user@example.com", Title: "Meeting"})
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import (
+	"context"
+	"proh2052-group6/internal/repositories/memory"
+	"proh2052-group6/pkg/models"
+)
+
+// MockEventRepository is the in-memory EventRepository with simulated partial failures.
+type MockEventRepository struct {
+	*memory.EventRepository
+
+	FailEventTitles map[string]error // Errors returned by CreateEvents for events with these titles.
+	FailEventIDs    map[string]error // Errors returned by DeleteEvents for these event IDs.
+}
+
+// NewMockEventRepository initializes a new MockEventRepository instance.
+func NewMockEventRepository() *MockEventRepository {
+	return &MockEventRepository{EventRepository: memory.NewEventRepository()}
+}
+
+// CreateEvents creates several events, failing those whose title is in FailEventTitles.
+func (mer *MockEventRepository) CreateEvents(ctx context.Context, events []*models.Event) []error {
+	errs := make([]error, len(events))
+	for i, event := range events {
+		if err := mer.FailEventTitles[event.Title]; err != nil {
+			errs[i] = err
+			continue
+		}
+		errs[i] = mer.CreateEvent(ctx, event)
+	}
+	return errs
+}
+
+// DeleteEvents deletes several events, failing those whose ID is in FailEventIDs.
+func (mer *MockEventRepository) DeleteEvents(ctx context.Context, userEmail string, eventIDs []string) []error {
+	errs := make([]error, len(eventIDs))
+	for i, eventID := range eventIDs {
+		if err := mer.FailEventIDs[eventID]; err != nil {
+			errs[i] = err
+			continue
+		}
+		errs[i] = mer.DeleteEvent(ctx, userEmail, eventID)
+	}
+	return errs
+}
@@ -13,10 +13,30 @@
  *  @methods
  *  - NewMockEventService: Initializes a new instance of MockEventService.
  *  - CreateEvent(ctx, event): Simulates creating a new event.
+ *  - CreateEventIdempotent(ctx, event, key): Simulates creating a new event; the key is ignored.
  *  - GetEvent(ctx, userEmail, eventID): Simulates retrieving an event by ID and user email.
  *  - UpdateEvent(ctx, event): Simulates updating an event.
+ *  - PatchEvent(ctx, userEmail, eventID, patch): Simulates updating some fields of an event.
+ *  - UpdateOccurrence(ctx, event, date): Simulates replacing one occurrence of a recurring event.
  *  - DeleteEvent(ctx, userEmail, eventID): Simulates deleting an event.
- *  - GetAllEvents(ctx, userEmail): Simulates retrieving all events for a user.
+ *  - DeleteOccurrence(ctx, userEmail, eventID, date): Simulates removing one occurrence of a recurring event.
+ *  - GetAllEvents(ctx, userEmail, query): Simulates retrieving a page of events for a user.
+ *  - InviteToEvent(ctx, ownerEmail, eventID, identifier): Simulates inviting a user to an event.
+ *  - RespondToInvitation(ctx, userEmail, eventID, response): Simulates responding to an invitation.
+ *  - GetInvitations(ctx, userEmail): Simulates retrieving a user's invitations.
+ *  - GetEventTags(ctx, userEmail): Simulates counting the tags on a user's events.
+ *  - GetNearbyEvents(ctx, userEmail, lat, lng, radiusKm): Simulates listing a user's events near a position.
+ *  - SearchEvents(ctx, userEmail, query, from, to): Simulates searching a user's events by title, description and address.
+ *  - BulkCreateEvents(ctx, userEmail, events): Simulates creating several events, failing those without a title.
+ *  - BulkDeleteEvents(ctx, userEmail, eventIDs): Simulates deleting several events, failing those the user does not own.
+ *  - CancelEvent(ctx, userEmail, eventID): Simulates cancelling an event.
+ *  - GetEventMonth(ctx, userEmail, month): Simulates summarizing a user's events per day of a month.
+ *  - ShareEvent(ctx, userEmail, eventID): Simulates creating a read-only link to an event, replacing earlier links.
+ *  - RevokeEventShare(ctx, userEmail, eventID): Simulates deleting the links to an event.
+ *  - GetSharedEvent(ctx, token): Simulates reading a shared event, rejecting unknown and expired links.
+ *  - GetFriendEvents(ctx, userEmail, username, from, to): Simulates listing the public events of a friend.
+ *  - GetFriendsEventFeed(ctx, userEmail): Simulates listing the public events of all of a user's friends.
+ *  - DuplicateEvent(ctx, userEmail, eventID, date, count): Simulates copying an event to a date and the following weeks.
  *
  *  @example
  *  ```
@@ -51,19 +71,37 @@ package mocks
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/repositories/memory"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/dates"
 	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/validate"
+	"sort"
+	"strings"
+	"time"
 )
 
 // MockEventService simulates an event service for testing.
 type MockEventService struct {
-	Events map[string]*models.Event // In-memory store for events.
+	Events      map[string]*models.Event           // In-memory store for events.
+	Invitations map[string]*models.EventInvitation // In-memory store for invitations keyed by eventID_inviteeEmail.
+	Shares      map[string]*models.EventShare      // In-memory store for shared event links keyed by token.
+	Usernames   map[string]string                  // Emails of the users keyed by username, for friend events.
+	Friends     map[string][]string                // Emails of the accepted friends of each user.
+	shareCount  int                                // Number of links created, to number their tokens.
 }
 
 // NewMockEventService initializes a new instance of MockEventService.
 func NewMockEventService() *MockEventService {
 	return &MockEventService{
-		Events: make(map[string]*models.Event),
+		Events:      make(map[string]*models.Event),
+		Invitations: make(map[string]*models.EventInvitation),
+		Shares:      make(map[string]*models.EventShare),
+		Usernames:   make(map[string]string),
+		Friends:     make(map[string][]string),
 	}
 }
 
@@ -76,11 +114,16 @@ func (mes *MockEventService) CreateEvent(ctx context.Context, event *models.Even
 	return nil
 }
 
+// CreateEventIdempotent simulates creating a new event. The key is ignored, so nothing is replayed.
+func (mes *MockEventService) CreateEventIdempotent(ctx context.Context, event *models.Event, key string) (bool, error) {
+	return false, mes.CreateEvent(ctx, event)
+}
+
 // GetEvent simulates retrieving an event by ID and user email.
 func (mes *MockEventService) GetEvent(ctx context.Context, userEmail, eventID string) (*models.Event, error) {
 	event, exists := mes.Events[eventID]
 	if !exists || event.Email != userEmail {
-		return nil, fmt.Errorf("event not found")
+		return nil, fmt.Errorf("event %w", repositories.ErrNotFound)
 	}
 	return event, nil
 }
@@ -89,29 +132,411 @@ func (mes *MockEventService) GetEvent(ctx context.Context, userEmail, eventID st
 func (mes *MockEventService) UpdateEvent(ctx context.Context, event *models.Event) error {
 	existingEvent, exists := mes.Events[event.EventID]
 	if !exists || existingEvent.Email != event.Email {
-		return fmt.Errorf("event not found")
+		return fmt.Errorf("event %w", repositories.ErrNotFound)
 	}
 	mes.Events[event.EventID] = event
 	return nil
 }
 
+// PatchEvent simulates updating the fields of an existing event that are present in patch.
+func (mes *MockEventService) PatchEvent(ctx context.Context, userEmail, eventID string, patch map[string]json.RawMessage) (*models.Event, error) {
+	event, exists := mes.Events[eventID]
+	if !exists || event.Email != userEmail {
+		return nil, fmt.Errorf("Event %w", repositories.ErrNotFound)
+	}
+	encoded, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	patched := *event
+	if err := json.Unmarshal(encoded, &patched); err != nil {
+		return nil, err
+	}
+	mes.Events[eventID] = &patched
+	return &patched, nil
+}
+
+// UpdateOccurrence simulates replacing one occurrence of a recurring event with a new event.
+func (mes *MockEventService) UpdateOccurrence(ctx context.Context, event *models.Event, occurrenceDate string) error {
+	series, exists := mes.Events[event.EventID]
+	if !exists || series.Email != event.Email {
+		return fmt.Errorf("Event %w", repositories.ErrNotFound)
+	}
+	if series.Recurrence == nil {
+		return fmt.Errorf("Event is not recurring")
+	}
+	series.ExceptionDates = append(series.ExceptionDates, occurrenceDate)
+
+	replacement := *event
+	replacement.EventID = fmt.Sprintf("%s_%s", series.EventID, occurrenceDate)
+	replacement.SeriesID = series.EventID
+	replacement.Recurrence = nil
+	mes.Events[replacement.EventID] = &replacement
+	event.EventID = replacement.EventID
+	return nil
+}
+
 // DeleteEvent simulates deleting an event by ID and user email.
 func (mes *MockEventService) DeleteEvent(ctx context.Context, userEmail, eventID string) error {
 	event, exists := mes.Events[eventID]
 	if !exists || event.Email != userEmail {
-		return fmt.Errorf("event not found")
+		return fmt.Errorf("event %w", repositories.ErrNotFound)
 	}
 	delete(mes.Events, eventID)
 	return nil
 }
 
-// GetAllEvents simulates retrieving all events for a specific user.
-func (mes *MockEventService) GetAllEvents(ctx context.Context, userEmail string) ([]models.Event, error) {
-	var events []models.Event
+// DeleteOccurrence simulates removing one occurrence of a recurring event.
+func (mes *MockEventService) DeleteOccurrence(ctx context.Context, userEmail, eventID, occurrenceDate string) error {
+	series, exists := mes.Events[eventID]
+	if !exists || series.Email != userEmail {
+		return fmt.Errorf("Event %w", repositories.ErrNotFound)
+	}
+	if series.Recurrence == nil {
+		return fmt.Errorf("Event is not recurring")
+	}
+	series.ExceptionDates = append(series.ExceptionDates, occurrenceDate)
+	return nil
+}
+
+// GetAllEvents simulates retrieving a page of events for a specific user, paged like the event repository.
+func (mes *MockEventService) GetAllEvents(ctx context.Context, userEmail string, query models.EventQuery) (*models.EventPage, error) {
+	events := memory.NewEventRepository()
+	events.Events = mes.Events
+	return events.GetAllEvents(ctx, userEmail, query)
+}
+
+// InviteToEvent simulates inviting a user to an event owned by ownerEmail.
+func (mes *MockEventService) InviteToEvent(ctx context.Context, ownerEmail, eventID, identifier string) error {
+	event, exists := mes.Events[eventID]
+	if !exists || event.Email != ownerEmail {
+		return fmt.Errorf("Event %w", repositories.ErrNotFound)
+	}
+	docID := eventID + "_" + identifier
+	if _, exists := mes.Invitations[docID]; !exists {
+		mes.Invitations[docID] = &models.EventInvitation{
+			EventID:      eventID,
+			OwnerEmail:   ownerEmail,
+			InviteeEmail: identifier,
+			Status:       "pending",
+		}
+	}
+	return nil
+}
+
+// RespondToInvitation simulates accepting or declining an invitation.
+func (mes *MockEventService) RespondToInvitation(ctx context.Context, userEmail, eventID, response string) error {
+	invitation, exists := mes.Invitations[eventID+"_"+userEmail]
+	if !exists {
+		return fmt.Errorf("Invitation %w", repositories.ErrNotFound)
+	}
+	switch response {
+	case "accept":
+		invitation.Status = "accepted"
+	case "decline":
+		invitation.Status = "declined"
+	default:
+		return fmt.Errorf("Response must be 'accept' or 'decline'")
+	}
+	return nil
+}
+
+// GetInvitations simulates retrieving all invitations received by a user.
+func (mes *MockEventService) GetInvitations(ctx context.Context, userEmail string) ([]models.EventInvitation, error) {
+	var invitations []models.EventInvitation
+	for _, invitation := range mes.Invitations {
+		if invitation.InviteeEmail == userEmail {
+			invitations = append(invitations, *invitation)
+		}
+	}
+	return invitations, nil
+}
+
+// GetEventTags simulates counting the tags on a user's events, ordered by tag.
+func (mes *MockEventService) GetEventTags(ctx context.Context, userEmail string) ([]models.TagCount, error) {
+	counts := make(map[string]int)
+	for _, event := range mes.Events {
+		if event.Email != userEmail {
+			continue
+		}
+		for _, tag := range event.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := []models.TagCount{}
+	for tag, count := range counts {
+		tags = append(tags, models.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+	return tags, nil
+}
+
+// GetNearbyEvents simulates listing a user's events with coordinates within radiusKm, nearest first.
+func (mes *MockEventService) GetNearbyEvents(ctx context.Context, userEmail string, latitude, longitude, radiusKm float64) ([]models.NearbyEvent, error) {
+	nearby := []models.NearbyEvent{}
 	for _, event := range mes.Events {
-		if event.Email == userEmail {
-			events = append(events, *event)
+		if event.Email != userEmail || event.Latitude == nil || event.Longitude == nil {
+			continue
+		}
+		if distance := services.HaversineKm(latitude, longitude, *event.Latitude, *event.Longitude); distance <= radiusKm {
+			nearby = append(nearby, models.NearbyEvent{Event: *event, DistanceKm: distance})
 		}
 	}
-	return events, nil
+	sort.Slice(nearby, func(i, j int) bool { return nearby[i].DistanceKm < nearby[j].DistanceKm })
+	return nearby, nil
+}
+
+// SearchEvents simulates searching the user's events between from and to for query, ignoring case.
+// Dates are not validated.
+func (mes *MockEventService) SearchEvents(ctx context.Context, userEmail, query, from, to string) ([]models.EventSearchResult, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	results := []models.EventSearchResult{}
+	for _, event := range mes.Events {
+		if event.Email != userEmail || event.Date < from || event.Date > to {
+			continue
+		}
+		fields := [][2]string{{"title", event.Title}, {"description", event.Description}, {"streetAddress", event.StreetAddress}}
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field[1]), query) {
+				results = append(results, models.EventSearchResult{Event: *event, MatchedField: field[0]})
+				break
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Date != results[j].Date {
+			return results[i].Date < results[j].Date
+		}
+		return results[i].StartTime < results[j].StartTime
+	})
+	return results, nil
+}
+
+// BulkCreateEvents simulates creating up to services.MaxBulkEvents events; events without a title fail.
+func (mes *MockEventService) BulkCreateEvents(ctx context.Context, userEmail string, events []models.Event) (*models.BulkEventResult, error) {
+	if len(events) == 0 {
+		return nil, services.ErrNoBulkEvents
+	}
+	if len(events) > services.MaxBulkEvents {
+		return nil, services.ErrTooManyBulkEvents
+	}
+
+	result := &models.BulkEventResult{Succeeded: []string{}, Failed: []models.BulkEventFailure{}}
+	for i := range events {
+		if events[i].Title == "" {
+			result.Failed = append(result.Failed, models.BulkEventFailure{Index: i, Error: "title required"})
+			continue
+		}
+		event := events[i]
+		event.Email = userEmail
+		event.EventID = fmt.Sprintf("bulk%d", len(mes.Events)+1)
+		mes.Events[event.EventID] = &event
+		result.Succeeded = append(result.Succeeded, event.EventID)
+	}
+	return result, nil
+}
+
+// BulkDeleteEvents simulates deleting up to services.MaxBulkEvents events; IDs the user does not own fail.
+func (mes *MockEventService) BulkDeleteEvents(ctx context.Context, userEmail string, eventIDs []string) (*models.BulkEventResult, error) {
+	if len(eventIDs) == 0 {
+		return nil, services.ErrNoBulkEvents
+	}
+	if len(eventIDs) > services.MaxBulkEvents {
+		return nil, services.ErrTooManyBulkEvents
+	}
+
+	result := &models.BulkEventResult{Succeeded: []string{}, Failed: []models.BulkEventFailure{}}
+	for i, eventID := range eventIDs {
+		event, exists := mes.Events[eventID]
+		if !exists || event.Email != userEmail {
+			result.Failed = append(result.Failed, models.BulkEventFailure{Index: i, EventID: eventID, Error: "Event not found"})
+			continue
+		}
+		delete(mes.Events, eventID)
+		result.Succeeded = append(result.Succeeded, eventID)
+	}
+	return result, nil
+}
+
+// CancelEvent simulates cancelling an event; cancelled events cannot be cancelled again.
+func (mes *MockEventService) CancelEvent(ctx context.Context, userEmail, eventID string) error {
+	event, exists := mes.Events[eventID]
+	if !exists || event.Email != userEmail {
+		return fmt.Errorf("Event not found")
+	}
+	if event.Status == services.EventStatusCancelled {
+		return services.ErrEventCancelled
+	}
+	cancelledAt := time.Now()
+	event.Status = services.EventStatusCancelled
+	event.CancelledAt = &cancelledAt
+	return nil
+}
+
+// GetEventMonth simulates summarizing a user's events per day of a month. Only the days of the month itself
+// are counted, in date order, and the month is not validated.
+func (mes *MockEventService) GetEventMonth(ctx context.Context, userEmail, month string) (*models.EventMonth, error) {
+	counts := make(map[string]*models.EventDaySummary)
+	for _, event := range mes.Events {
+		if event.Email != userEmail || !strings.HasPrefix(event.Date, month+"-") {
+			continue
+		}
+		if counts[event.Date] == nil {
+			counts[event.Date] = &models.EventDaySummary{Date: event.Date, FirstTitle: event.Title}
+		}
+		counts[event.Date].Count++
+	}
+
+	summary := &models.EventMonth{Month: month, Days: []models.EventDaySummary{}}
+	for _, day := range counts {
+		summary.Days = append(summary.Days, *day)
+	}
+	sort.Slice(summary.Days, func(i, j int) bool { return summary.Days[i].Date < summary.Days[j].Date })
+	return summary, nil
+}
+
+// ShareEvent simulates creating a link to an event that works for services.EventShareLifetime.
+// Tokens are numbered, "share-1", "share-2" and so on, and earlier links to the event are deleted.
+func (mes *MockEventService) ShareEvent(ctx context.Context, userEmail, eventID string) (*models.EventShareLink, error) {
+	if err := mes.RevokeEventShare(ctx, userEmail, eventID); err != nil {
+		return nil, err
+	}
+	mes.shareCount++
+	token := fmt.Sprintf("share-%d", mes.shareCount)
+	expiresAt := time.Now().Add(services.EventShareLifetime)
+	mes.Shares[token] = &models.EventShare{Email: userEmail, EventID: eventID, CreatedAt: time.Now(), ExpiresAt: expiresAt}
+	return &models.EventShareLink{Token: token, URL: "http://localhost:5173/shared/events/" + token, ExpiresAt: expiresAt}, nil
+}
+
+// RevokeEventShare simulates deleting the links to an event.
+func (mes *MockEventService) RevokeEventShare(ctx context.Context, userEmail, eventID string) error {
+	event, exists := mes.Events[eventID]
+	if !exists || event.Email != userEmail {
+		return fmt.Errorf("Event not found")
+	}
+	for token, share := range mes.Shares {
+		if share.Email == userEmail && share.EventID == eventID {
+			delete(mes.Shares, token)
+		}
+	}
+	return nil
+}
+
+// GetSharedEvent simulates reading the event a link points to, without the owner's email.
+func (mes *MockEventService) GetSharedEvent(ctx context.Context, token string) (*models.SharedEvent, error) {
+	share, exists := mes.Shares[token]
+	if !exists {
+		return nil, services.ErrEventShareNotFound
+	}
+	if !time.Now().Before(share.ExpiresAt) {
+		return nil, services.ErrEventShareExpired
+	}
+	event, exists := mes.Events[share.EventID]
+	if !exists || event.Email != share.Email {
+		return nil, services.ErrEventShareNotFound
+	}
+	return &models.SharedEvent{
+		Title:         event.Title,
+		Description:   event.Description,
+		Date:          event.Date,
+		StartTime:     event.StartTime,
+		EndTime:       event.EndTime,
+		AllDay:        event.AllDay,
+		StreetAddress: event.StreetAddress,
+		PostalNumber:  event.PostalNumber,
+		Status:        event.Status,
+	}, nil
+}
+
+// GetFriendEvents simulates listing the public, uncancelled events of the friend with username
+// between from and to. The dates are not validated and an empty from or to is unbounded.
+func (mes *MockEventService) GetFriendEvents(ctx context.Context, userEmail, username, from, to string) ([]models.FriendEvent, error) {
+	ownerEmail, exists := mes.Usernames[username]
+	if !exists {
+		return nil, fmt.Errorf("User not found")
+	}
+	if !mes.isFriend(userEmail, ownerEmail) {
+		return nil, services.ErrNotFriends
+	}
+	return mes.publicEvents(map[string]string{ownerEmail: username}, from, to), nil
+}
+
+// GetFriendsEventFeed simulates listing the public, uncancelled events of all friends of a user, on any date.
+func (mes *MockEventService) GetFriendsEventFeed(ctx context.Context, userEmail string) ([]models.FriendEvent, error) {
+	owners := make(map[string]string)
+	for username, email := range mes.Usernames {
+		if mes.isFriend(userEmail, email) {
+			owners[email] = username
+		}
+	}
+	return mes.publicEvents(owners, "", ""), nil
+}
+
+// DuplicateEvent simulates copying one of the user's events to date and the count-1 following weeks.
+func (mes *MockEventService) DuplicateEvent(ctx context.Context, userEmail, eventID, date string, count int) ([]string, error) {
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 || count > services.MaxEventCopies {
+		return nil, validate.Errors{"count": fmt.Sprintf("must be between 1 and %d", services.MaxEventCopies)}
+	}
+	source, exists := mes.Events[eventID]
+	if !exists || source.Email != userEmail {
+		return nil, fmt.Errorf("Event not found")
+	}
+	first, err := dates.Parse("date", date)
+	if err != nil {
+		return nil, err
+	}
+
+	eventIDs := make([]string, count)
+	for i := range eventIDs {
+		event := *source
+		event.EventID = fmt.Sprintf("copy%d", len(mes.Events)+1)
+		event.Date = first.AddDate(0, 0, 7*i).Format(dates.Layout)
+		event.ReminderSent = false
+		mes.Events[event.EventID] = &event
+		eventIDs[i] = event.EventID
+	}
+	return eventIDs, nil
+}
+
+// isFriend reports whether Friends lists otherEmail as a friend of userEmail.
+func (mes *MockEventService) isFriend(userEmail, otherEmail string) bool {
+	for _, friend := range mes.Friends[userEmail] {
+		if friend == otherEmail {
+			return true
+		}
+	}
+	return false
+}
+
+// publicEvents returns the public, uncancelled events of the owners, keyed by email to their
+// usernames, between from and to, in date order.
+func (mes *MockEventService) publicEvents(owners map[string]string, from, to string) []models.FriendEvent {
+	events := []models.FriendEvent{}
+	for _, event := range mes.Events {
+		username, exists := owners[event.Email]
+		if !exists || event.EventTypeID != "public" || event.Status == "cancelled" ||
+			(from != "" && event.Date < from) || (to != "" && event.Date > to) {
+			continue
+		}
+		events = append(events, models.FriendEvent{
+			EventID:   event.EventID,
+			Username:  username,
+			Title:     event.Title,
+			Date:      event.Date,
+			StartTime: event.StartTime,
+			EndTime:   event.EndTime,
+			Status:    event.Status,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Date != events[j].Date {
+			return events[i].Date < events[j].Date
+		}
+		return events[i].EventID < events[j].EventID
+	})
+	return events
 }
@@ -0,0 +1,32 @@
+/**
+ *  MockFavoriteRepository is the in-memory FavoriteRepository of the memory package, used for testing
+ *  favourite quotes without relying on a database.
+ *
+ *  @file       mock_favorite_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockFavoriteRepository() - Creates an empty memory.FavoriteRepository.
+ *
+ *  @behaviors
+ *  - Tests seed and inspect the repository through its Favorites map, keyed by user email and quote ID.
+ *  - Setting Err makes every method fail with it, e.g. repositories.ErrUnavailable to simulate an outage.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import "proh2052-group6/internal/repositories/memory"
+
+// MockFavoriteRepository is the in-memory FavoriteRepository.
+type MockFavoriteRepository = memory.FavoriteRepository
+
+// NewMockFavoriteRepository creates an empty in-memory FavoriteRepository.
+func NewMockFavoriteRepository() *MockFavoriteRepository {
+	return memory.NewFavoriteRepository()
+}
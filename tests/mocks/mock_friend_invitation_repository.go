@@ -0,0 +1,32 @@
+/**
+ *  MockFriendInvitationRepository is the in-memory FriendInvitationRepository of the memory package, used for testing
+ *  invitations to join as a friend without relying on a database.
+ *
+ *  @file       mock_friend_invitation_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockFriendInvitationRepository() - Creates an empty memory.FriendInvitationRepository.
+ *
+ *  @behaviors
+ *  - Tests seed and inspect the repository through its Invitations map, keyed by CompositeID(invitee, inviter).
+ *  - Setting Err makes every method fail with it, e.g. repositories.ErrUnavailable to simulate an outage.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import "proh2052-group6/internal/repositories/memory"
+
+// MockFriendInvitationRepository is the in-memory FriendInvitationRepository.
+type MockFriendInvitationRepository = memory.FriendInvitationRepository
+
+// NewMockFriendInvitationRepository creates an empty in-memory FriendInvitationRepository.
+func NewMockFriendInvitationRepository() *MockFriendInvitationRepository {
+	return memory.NewFriendInvitationRepository()
+}
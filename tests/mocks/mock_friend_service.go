@@ -7,13 +7,20 @@
  *  @inherits FriendServiceInterface
  *
  *  @methods
- *  - SendFriendRequest(ctx, userEmail, username) (error): Simulates sending a friend request.
+ *  - SendFriendRequest(ctx, userEmail, username) (bool, error): Simulates sending a friend request.
  *  - AcceptFriendRequest(ctx, userEmail, username) (error): Simulates accepting a friend request.
- *  - GetFriendsList(ctx, userEmail) ([]models.User, error): Simulates retrieving the user's friends list.
+ *  - GetFriendsList(ctx, userEmail) ([]models.UserSummary, error): Simulates retrieving the user's friends list.
  *  - RemoveFriend(ctx, userEmail, username) (error): Simulates removing a friend.
  *  - GetPendingFriendRequests(ctx, userEmail) ([]models.User, error): Simulates retrieving pending friend requests.
  *  - DeclineFriendRequest(ctx, userEmail, username) (error): Simulates declining a friend request.
  *  - CancelFriendRequest(ctx, userEmail, username) (error): Simulates canceling a friend request.
+ *  - BlockUser(ctx, userEmail, identifier) (error): Simulates blocking a user.
+ *  - UnblockUser(ctx, userEmail, identifier) (error): Simulates unblocking a user.
+ *  - GetBlockedUsers(ctx, userEmail) ([]models.UserSummary, error): Simulates retrieving blocked users.
+ *  - ComputeSuggestions(ctx, userEmail, limit) ([]models.UserSummary, error): Simulates suggesting friends.
+ *  - GetMutualFriends(ctx, userEmail, identifier) ([]models.UserSummary, error): Simulates retrieving mutual friends.
+ *  - InviteFriend(ctx, userEmail, email) (string, error): Simulates inviting a friend by email.
+ *  - GetRelationshipStatuses(ctx, userEmail, emails) (map[string]string, error): Simulates looking up relationship statuses.
  *
  *  @example
  *  ```
@@ -21,7 +28,7 @@
  *  mockFriendService := &MockFriendService{}
  *
  *  // Simulate sending a friend request
- *  err := mockFriendService.SendFriendRequest(context.Background(), "user1@example.com", "user2")
+ *  _, err := mockFriendService.SendFriendRequest(context.Background(), "user1@example.com", "user2")
  *  if err != nil {
  *      t.Errorf("Expected no error, got %v", err)
  *  }
@@ -43,6 +50,7 @@ package mocks
 
 import (
 	"context"
+	"proh2052-group6/internal/services"
 	"proh2052-group6/pkg/models"
 )
 
@@ -58,10 +66,11 @@ type MockFriendService struct {
 // - username (string): The username of the user to whom the request is being sent.
 //
 // Returns:
+// - bool: Always false in this mock, as no request is ever accepted automatically.
 // - error: Always returns nil in this mock, simulating successful request sending.
-func (mfs *MockFriendService) SendFriendRequest(ctx context.Context, userEmail, username string) error {
+func (mfs *MockFriendService) SendFriendRequest(ctx context.Context, userEmail, username string) (bool, error) {
 	// Simulate sending friend request
-	return nil
+	return false, nil
 }
 
 // AcceptFriendRequest simulates accepting a friend request.
@@ -83,11 +92,11 @@ func (mfs *MockFriendService) AcceptFriendRequest(ctx context.Context, userEmail
 // - userEmail (string): The email of the user whose friends list is being requested.
 //
 // Returns:
-// - []models.User: A slice of users representing the friends list.
+// - []models.UserSummary: A slice of user summaries representing the friends list.
 // - error: Always returns nil in this mock.
-func (mfs *MockFriendService) GetFriendsList(ctx context.Context, userEmail string) ([]models.User, error) {
+func (mfs *MockFriendService) GetFriendsList(ctx context.Context, userEmail string) ([]models.UserSummary, error) {
 	// Simulate retrieving friends list
-	return []models.User{}, nil
+	return []models.UserSummary{}, nil
 }
 
 // RemoveFriend simulates removing a friend.
@@ -109,11 +118,11 @@ func (mfs *MockFriendService) RemoveFriend(ctx context.Context, userEmail, usern
 // - userEmail (string): The email of the user whose pending friend requests are being retrieved.
 //
 // Returns:
-// - []models.User: A slice of users representing the pending friend requests.
+// - []models.UserSummary: A slice of users representing the pending friend requests.
 // - error: Always returns nil in this mock.
-func (mfs *MockFriendService) GetPendingFriendRequests(ctx context.Context, userEmail string) ([]models.User, error) {
+func (mfs *MockFriendService) GetPendingFriendRequests(ctx context.Context, userEmail string) ([]models.UserSummary, error) {
 	// Simulate getting pending friend requests
-	return []models.User{}, nil
+	return []models.UserSummary{}, nil
 }
 
 // DeclineFriendRequest simulates declining a friend request.
@@ -141,3 +150,61 @@ func (mfs *MockFriendService) CancelFriendRequest(ctx context.Context, userEmail
 	// Simulate canceling friend request
 	return nil
 }
+
+// BlockUser simulates blocking a user.
+// Returns:
+// - error: Always returns nil in this mock, simulating a successful block.
+func (mfs *MockFriendService) BlockUser(ctx context.Context, userEmail, identifier string) error {
+	return nil
+}
+
+// UnblockUser simulates unblocking a user.
+// Returns:
+// - error: Always returns nil in this mock, simulating a successful unblock.
+func (mfs *MockFriendService) UnblockUser(ctx context.Context, userEmail, identifier string) error {
+	return nil
+}
+
+// GetBlockedUsers simulates retrieving the users blocked by a user.
+// Returns:
+// - []models.UserSummary: Always empty in this mock.
+// - error: Always returns nil in this mock.
+func (mfs *MockFriendService) GetBlockedUsers(ctx context.Context, userEmail string) ([]models.UserSummary, error) {
+	return []models.UserSummary{}, nil
+}
+
+// ComputeSuggestions simulates suggesting friends of friends.
+// Returns:
+// - []models.UserSummary: Always empty in this mock.
+// - error: Always returns nil in this mock.
+func (mfs *MockFriendService) ComputeSuggestions(ctx context.Context, userEmail string, limit int) ([]models.UserSummary, error) {
+	return []models.UserSummary{}, nil
+}
+
+// GetMutualFriends simulates retrieving the friends shared with another user.
+// Returns:
+// - []models.UserSummary: Always empty in this mock.
+// - error: Always returns nil in this mock.
+func (mfs *MockFriendService) GetMutualFriends(ctx context.Context, userEmail, identifier string) ([]models.UserSummary, error) {
+	return []models.UserSummary{}, nil
+}
+
+// InviteFriend simulates inviting a friend by email.
+// Returns:
+// - string: Always services.InviteRequestSent in this mock.
+// - error: Always returns nil in this mock.
+func (mfs *MockFriendService) InviteFriend(ctx context.Context, userEmail, email string) (string, error) {
+	return services.InviteRequestSent, nil
+}
+
+// GetRelationshipStatuses simulates looking up the relationship with several users.
+// Returns:
+// - map[string]string: services.FriendshipNone for every email in this mock.
+// - error: Always returns nil in this mock.
+func (mfs *MockFriendService) GetRelationshipStatuses(ctx context.Context, userEmail string, emails []string) (map[string]string, error) {
+	statuses := make(map[string]string, len(emails))
+	for _, email := range emails {
+		statuses[email] = services.FriendshipNone
+	}
+	return statuses, nil
+}
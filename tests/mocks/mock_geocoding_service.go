@@ -0,0 +1,74 @@
+/**
+ *  MockGeocodingService simulates a GeocodingService with a fixed set of known addresses,
+ *  so event tests can run without calling Nominatim.
+ *
+ *  @file       mock_geocoding_service.go
+ *  @package    mocks
+ *
+ *  @structs
+ *  - MockGeocodingService: Looks up addresses in an in-memory map.
+ *
+ *  @methods
+ *  - NewMockGeocodingService(addresses): Initializes a mock that knows the given addresses.
+ *  - Geocode(ctx, address): Returns the coordinates of a known address.
+ *
+ *  @behaviors
+ *  - Addresses are matched after services.NormalizeAddress, like the cache of the real geocoder.
+ *  - Unknown addresses return services.ErrAddressNotFound; setting Err makes every lookup fail with it.
+ *  - Every looked up address is recorded in Calls.
+ *
+ *  @example
+ *  ```
+ *  geocoder := mocks.NewMockGeocodingService(map[string]services.Coordinates{
+ *      "kongens gate 1, 7011": {Latitude: 63.4305, Longitude: 10.3951},
+ *  })
+ *  ```
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"proh2052-group6/internal/services"
+)
+
+// MockGeocodingService simulates a GeocodingService.
+type MockGeocodingService struct {
+	Addresses map[string]services.Coordinates // Known addresses, keyed by normalized address.
+	Err       error                           // If set, returned by every lookup.
+	Calls     []string                        // Addresses looked up, in order.
+
+	mutex sync.Mutex
+}
+
+// NewMockGeocodingService initializes a MockGeocodingService that knows the given addresses.
+func NewMockGeocodingService(addresses map[string]services.Coordinates) *MockGeocodingService {
+	normalized := make(map[string]services.Coordinates, len(addresses))
+	for address, coordinates := range addresses {
+		normalized[services.NormalizeAddress(address)] = coordinates
+	}
+	return &MockGeocodingService{Addresses: normalized}
+}
+
+// Geocode returns the coordinates of a known address.
+func (mgs *MockGeocodingService) Geocode(ctx context.Context, address string) (*services.Coordinates, error) {
+	mgs.mutex.Lock()
+	defer mgs.mutex.Unlock()
+	mgs.Calls = append(mgs.Calls, address)
+	if mgs.Err != nil {
+		return nil, mgs.Err
+	}
+	coordinates, ok := mgs.Addresses[services.NormalizeAddress(address)]
+	if !ok {
+		return nil, services.ErrAddressNotFound
+	}
+	return &coordinates, nil
+}
@@ -0,0 +1,32 @@
+/**
+ *  MockIdempotencyRepository is the in-memory IdempotencyRepository of the memory package, used for testing
+ *  idempotent event creation without relying on a database.
+ *
+ *  @file       mock_idempotency_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockIdempotencyRepository() - Creates an empty memory.IdempotencyRepository.
+ *
+ *  @behaviors
+ *  - Tests seed and inspect the repository through its Records map, keyed by "{email}_{key}".
+ *  - Setting Err makes every method fail with it, e.g. repositories.ErrUnavailable to simulate an outage.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import "proh2052-group6/internal/repositories/memory"
+
+// MockIdempotencyRepository is the in-memory IdempotencyRepository.
+type MockIdempotencyRepository = memory.IdempotencyRepository
+
+// NewMockIdempotencyRepository creates an empty in-memory IdempotencyRepository.
+func NewMockIdempotencyRepository() *MockIdempotencyRepository {
+	return memory.NewIdempotencyRepository()
+}
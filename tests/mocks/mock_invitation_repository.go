@@ -0,0 +1,32 @@
+/**
+ *  MockInvitationRepository is the in-memory InvitationRepository of the memory package, used for testing
+ *  event invitations without relying on a database.
+ *
+ *  @file       mock_invitation_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockInvitationRepository() - Creates an empty memory.InvitationRepository.
+ *
+ *  @behaviors
+ *  - Tests seed and inspect the repository through its Invitations map, keyed by "{eventID}_{inviteeEmail}".
+ *  - Setting Err makes every method fail with it, e.g. repositories.ErrUnavailable to simulate an outage.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import "proh2052-group6/internal/repositories/memory"
+
+// MockInvitationRepository is the in-memory InvitationRepository.
+type MockInvitationRepository = memory.InvitationRepository
+
+// NewMockInvitationRepository creates an empty in-memory InvitationRepository.
+func NewMockInvitationRepository() *MockInvitationRepository {
+	return memory.NewInvitationRepository()
+}
@@ -0,0 +1,32 @@
+/**
+ *  MockJournalRepository is the in-memory JournalRepository of the memory package, used for testing
+ *  journal-related services without relying on a database.
+ *
+ *  @file       mock_journal_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockJournalRepository() - Creates an empty memory.JournalRepository.
+ *
+ *  @behaviors
+ *  - Tests seed and inspect the repository through its Journals map, keyed by JournalID.
+ *  - Setting Err makes every method fail with it, e.g. repositories.ErrUnavailable to simulate an outage.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import "proh2052-group6/internal/repositories/memory"
+
+// MockJournalRepository is the in-memory JournalRepository.
+type MockJournalRepository = memory.JournalRepository
+
+// NewMockJournalRepository creates an empty in-memory JournalRepository.
+func NewMockJournalRepository() *MockJournalRepository {
+	return memory.NewJournalRepository()
+}
@@ -3,8 +3,16 @@ package mocks
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/dates"
 	"proh2052-group6/pkg/models"
+	"sort"
+	"strings"
+	"time"
 )
 
 type MockJournalService struct {
@@ -21,22 +29,47 @@ func (mjs *MockJournalService) CreateJournal(ctx context.Context, journal *model
 	if _, exists := mjs.Journals[journal.JournalID]; exists {
 		return fmt.Errorf("journal already exists")
 	}
+	if mjs.findByDate(journal.Email, journal.Date) != nil {
+		return fmt.Errorf("A journal already exists for this date")
+	}
+	if journal.JournalID == "" {
+		journal.JournalID = fmt.Sprintf("journal%d", len(mjs.Journals)+1)
+	}
 	mjs.Journals[journal.JournalID] = journal
 	return nil
 }
 
+func (mjs *MockJournalService) UpsertJournal(ctx context.Context, journal *models.Journal) error {
+	if existing := mjs.findByDate(journal.Email, journal.Date); existing != nil {
+		journal.JournalID = existing.JournalID
+		mjs.Journals[journal.JournalID] = journal
+		return nil
+	}
+	return mjs.CreateJournal(ctx, journal)
+}
+
+// findByDate returns the user's journal for the given date, or nil.
+func (mjs *MockJournalService) findByDate(userEmail, date string) *models.Journal {
+	for _, journal := range mjs.Journals {
+		if journal.Email == userEmail && journal.Date == date && journal.DeletedAt == nil {
+			return journal
+		}
+	}
+	return nil
+}
+
 func (mjs *MockJournalService) GetJournal(ctx context.Context, userEmail, journalID string) (*models.Journal, error) {
 	journal, exists := mjs.Journals[journalID]
-	if !exists || journal.Email != userEmail {
-		return nil, fmt.Errorf("journal not found")
+	if !exists || journal.Email != userEmail || journal.DeletedAt != nil {
+		return nil, fmt.Errorf("journal %w", repositories.ErrNotFound)
 	}
 	return journal, nil
 }
 
 func (mjs *MockJournalService) UpdateJournal(ctx context.Context, journal *models.Journal) error {
 	existingJournal, exists := mjs.Journals[journal.JournalID]
-	if !exists || existingJournal.Email != journal.Email {
-		return fmt.Errorf("journal not found")
+	if !exists || existingJournal.Email != journal.Email || existingJournal.DeletedAt != nil {
+		return fmt.Errorf("journal %w", repositories.ErrNotFound)
 	}
 	mjs.Journals[journal.JournalID] = journal
 	return nil
@@ -44,19 +77,188 @@ func (mjs *MockJournalService) UpdateJournal(ctx context.Context, journal *model
 
 func (mjs *MockJournalService) DeleteJournal(ctx context.Context, userEmail, journalID string) error {
 	journal, exists := mjs.Journals[journalID]
-	if !exists || journal.Email != userEmail {
-		return fmt.Errorf("journal not found")
+	if !exists || journal.Email != userEmail || journal.DeletedAt != nil {
+		return fmt.Errorf("journal %w", repositories.ErrNotFound)
 	}
-	delete(mjs.Journals, journalID)
+	deletedAt := time.Now()
+	journal.DeletedAt = &deletedAt
 	return nil
 }
 
+func (mjs *MockJournalService) GetJournalTrash(ctx context.Context, userEmail string) ([]models.Journal, error) {
+	journals := []models.Journal{}
+	for _, journal := range mjs.Journals {
+		if journal.Email == userEmail && journal.DeletedAt != nil {
+			journals = append(journals, *journal)
+		}
+	}
+	return journals, nil
+}
+
+func (mjs *MockJournalService) RestoreJournal(ctx context.Context, userEmail, journalID string) (*models.Journal, error) {
+	journal, exists := mjs.Journals[journalID]
+	if !exists || journal.Email != userEmail {
+		return nil, fmt.Errorf("journal %w", repositories.ErrNotFound)
+	}
+	if journal.DeletedAt == nil {
+		return nil, fmt.Errorf("Journal is not in the trash")
+	}
+	if mjs.findByDate(userEmail, journal.Date) != nil {
+		return nil, fmt.Errorf("A journal already exists for this date")
+	}
+	journal.DeletedAt = nil
+	return journal, nil
+}
+
 func (mjs *MockJournalService) GetAllJournals(ctx context.Context, userEmail string) ([]models.Journal, error) {
 	var journals []models.Journal
 	for _, journal := range mjs.Journals {
-		if journal.Email == userEmail {
+		if journal.Email == userEmail && journal.DeletedAt == nil {
 			journals = append(journals, *journal)
 		}
 	}
 	return journals, nil
 }
+
+func (mjs *MockJournalService) SearchJournals(ctx context.Context, userEmail, query, from, to string, limit int) ([]models.Journal, error) {
+	if _, _, err := dates.ParseRange(from, to); err != nil {
+		return nil, err
+	}
+	return searchJournals(mjs.Journals, userEmail, query, from, to, limit), nil
+}
+
+// searchJournals filters journals in memory by owner, content substring and date range, newest first.
+func searchJournals(store map[string]*models.Journal, userEmail, query, from, to string, limit int) []models.Journal {
+	needle := strings.ToLower(query)
+	journals := []models.Journal{}
+	for _, journal := range store {
+		if journal.Email != userEmail || journal.DeletedAt != nil {
+			continue
+		}
+		if (from != "" && journal.Date < from) || (to != "" && journal.Date > to) {
+			continue
+		}
+		if needle != "" && !strings.Contains(strings.ToLower(journal.Content), needle) {
+			continue
+		}
+		journals = append(journals, *journal)
+	}
+
+	sort.Slice(journals, func(i, j int) bool {
+		if journals[i].Date != journals[j].Date {
+			return journals[i].Date > journals[j].Date
+		}
+		return journals[i].JournalID < journals[j].JournalID
+	})
+
+	if limit > 0 && len(journals) > limit {
+		journals = journals[:limit]
+	}
+	return journals
+}
+
+// ExportJournals simulates exporting a user's journals, oldest first. Markdown output has one section per date.
+func (mjs *MockJournalService) ExportJournals(ctx context.Context, userEmail, format, from, to string, w io.Writer) error {
+	if format != "json" && format != "markdown" {
+		return fmt.Errorf("format must be 'json' or 'markdown'")
+	}
+	if _, _, err := dates.ParseRange(from, to); err != nil {
+		return err
+	}
+
+	journals := searchJournals(mjs.Journals, userEmail, "", from, to, 0)
+	sort.Slice(journals, func(i, j int) bool { return journals[i].Date < journals[j].Date })
+	if format == "json" {
+		return json.NewEncoder(w).Encode(journals)
+	}
+
+	fmt.Fprint(w, "# Journal\n")
+	for _, journal := range journals {
+		fmt.Fprintf(w, "\n## %s\n\n%s\n", journal.Date, journal.Content)
+	}
+	return nil
+}
+
+// ImportJournals simulates importing a JSON array of journals. Rows whose date already has a journal
+// are skipped; nothing is validated or merged.
+func (mjs *MockJournalService) ImportJournals(ctx context.Context, userEmail, format string, r io.Reader, merge bool) (*models.JournalImportResult, error) {
+	if format != "json" {
+		return nil, fmt.Errorf("Import must be JSON or CSV")
+	}
+	var rows []models.Journal
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("Invalid import file")
+	}
+
+	result := &models.JournalImportResult{Rows: []models.JournalImportRowResult{}}
+	for i, row := range rows {
+		outcome := models.JournalImportRowResult{Row: i + 1, Date: row.Date, Status: "imported"}
+		journal := &models.Journal{Email: userEmail, Date: row.Date, Content: row.Content}
+		if err := mjs.CreateJournal(ctx, journal); err != nil {
+			outcome.Status, outcome.Reason = "skipped", err.Error()
+			result.Skipped++
+		} else {
+			result.Imported++
+		}
+		result.Rows = append(result.Rows, outcome)
+	}
+	return result, nil
+}
+
+// GetJournalStats simulates summarising a month of a user's journals. Streaks are not computed.
+func (mjs *MockJournalService) GetJournalStats(ctx context.Context, userEmail, month string) (*models.JournalStats, error) {
+	if _, err := time.Parse("2006-01", month); err != nil {
+		return nil, fmt.Errorf("Invalid month format. Please use YYYY-MM.")
+	}
+
+	stats := &models.JournalStats{Month: month, MoodDistribution: map[string]int{}}
+	for _, journal := range searchJournals(mjs.Journals, userEmail, "", month+"-01", month+"-31", 0) {
+		stats.EntryCount++
+		if journal.Mood != "" {
+			stats.MoodDistribution[journal.Mood]++
+		}
+	}
+	return stats, nil
+}
+
+// AddAttachment simulates attaching an image to a journal. The image is not checked or stored.
+func (mjs *MockJournalService) AddAttachment(ctx context.Context, userEmail, journalID string, data []byte) (*models.Attachment, error) {
+	journal, err := mjs.GetJournal(ctx, userEmail, journalID)
+	if err != nil {
+		return nil, err
+	}
+	id := fmt.Sprintf("attachment%d", len(journal.Attachments)+1)
+	attachment := models.Attachment{ID: id, URL: services.AttachmentURL(journalID, id), ContentType: "image/png", Size: len(data)}
+	journal.Attachments = append(journal.Attachments, attachment)
+	return &attachment, nil
+}
+
+// GetAttachment simulates downloading an image attached to a journal. Since images are not stored,
+// its content is empty.
+func (mjs *MockJournalService) GetAttachment(ctx context.Context, userEmail, journalID, attachmentID string) (*models.Attachment, []byte, error) {
+	journal, err := mjs.GetJournal(ctx, userEmail, journalID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, attachment := range journal.Attachments {
+		if attachment.ID == attachmentID {
+			return &attachment, []byte{}, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("attachment %w", repositories.ErrNotFound)
+}
+
+// DeleteAttachment simulates removing an image from a journal.
+func (mjs *MockJournalService) DeleteAttachment(ctx context.Context, userEmail, journalID, attachmentID string) error {
+	journal, err := mjs.GetJournal(ctx, userEmail, journalID)
+	if err != nil {
+		return err
+	}
+	for i, attachment := range journal.Attachments {
+		if attachment.ID == attachmentID {
+			journal.Attachments = append(journal.Attachments[:i:i], journal.Attachments[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("attachment %w", repositories.ErrNotFound)
+}
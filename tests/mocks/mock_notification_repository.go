@@ -0,0 +1,32 @@
+/**
+ *  MockNotificationRepository is the in-memory NotificationRepository of the memory package, used for testing
+ *  the notification inbox without relying on a database.
+ *
+ *  @file       mock_notification_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockNotificationRepository() - Creates an empty memory.NotificationRepository.
+ *
+ *  @behaviors
+ *  - Tests seed and inspect the repository through its Notifications map, keyed by user email.
+ *  - Setting Err makes every method fail with it, e.g. repositories.ErrUnavailable to simulate an outage.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import "proh2052-group6/internal/repositories/memory"
+
+// MockNotificationRepository is the in-memory NotificationRepository.
+type MockNotificationRepository = memory.NotificationRepository
+
+// NewMockNotificationRepository creates an empty in-memory NotificationRepository.
+func NewMockNotificationRepository() *MockNotificationRepository {
+	return memory.NewNotificationRepository()
+}
@@ -0,0 +1,32 @@
+/**
+ *  MockPendingDeliveryRepository is the in-memory PendingDeliveryRepository of the memory package, used for testing
+ *  quiet hours without relying on a database.
+ *
+ *  @file       mock_pending_delivery_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockPendingDeliveryRepository() - Creates an empty memory.PendingDeliveryRepository.
+ *
+ *  @behaviors
+ *  - Tests seed and inspect the repository through its Deliveries map, keyed by generated ID.
+ *  - Setting Err makes every method fail with it, e.g. repositories.ErrUnavailable to simulate an outage.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import "proh2052-group6/internal/repositories/memory"
+
+// MockPendingDeliveryRepository is the in-memory PendingDeliveryRepository.
+type MockPendingDeliveryRepository = memory.PendingDeliveryRepository
+
+// NewMockPendingDeliveryRepository creates an empty in-memory PendingDeliveryRepository.
+func NewMockPendingDeliveryRepository() *MockPendingDeliveryRepository {
+	return memory.NewPendingDeliveryRepository()
+}
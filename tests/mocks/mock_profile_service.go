@@ -12,8 +12,12 @@
  *
  *  @methods
  *  - NewMockProfileService: Initializes a new instance of MockProfileService.
- *  - GetProfile(ctx, userEmail): Simulates retrieving a user profile by email.
+ *  - GetProfile(ctx, userEmail): Simulates retrieving a user profile by email, built from the string fields of Profiles.
  *  - UpdateProfile(ctx, userEmail, updatedData): Simulates updating a user's profile.
+ *  - RequestEmailChange(ctx, userEmail, newEmail, currentPassword): Simulates starting an email change.
+ *  - ConfirmEmailChange(ctx, userEmail, otp): Simulates completing an email change with MockEmailChangeOTP.
+ *  - UpdateAvatar(ctx, userEmail, data): Simulates setting a profile picture.
+ *  - DeleteAvatar(ctx, userEmail): Simulates removing a profile picture.
  *
  *  @example
  *  ```
@@ -58,36 +62,59 @@ package mocks
 import (
 	"context"
 	"errors"
+	"fmt"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
 )
 
+// MockEmailChangeOTP is the only OTP MockProfileService accepts when confirming an email change.
+const MockEmailChangeOTP = "123456"
+
 // MockProfileService simulates a profile service for testing.
 type MockProfileService struct {
-	Profiles map[string]map[string]interface{} // In-memory store for profiles.
-	Users    map[string]map[string]interface{} // In-memory store for users.
+	Profiles      map[string]map[string]interface{} // In-memory store for profiles.
+	Users         map[string]map[string]interface{} // In-memory store for users.
+	PendingEmails map[string]string                 // Requested new emails keyed by current email.
 }
 
 // NewMockProfileService initializes a new instance of MockProfileService.
 func NewMockProfileService() *MockProfileService {
 	return &MockProfileService{
-		Profiles: make(map[string]map[string]interface{}),
-		Users:    make(map[string]map[string]interface{}),
+		Profiles:      make(map[string]map[string]interface{}),
+		Users:         make(map[string]map[string]interface{}),
+		PendingEmails: make(map[string]string),
 	}
 }
 
 // GetProfile simulates retrieving a user profile by email.
-func (mps *MockProfileService) GetProfile(ctx context.Context, userEmail string) (map[string]interface{}, error) {
+func (mps *MockProfileService) GetProfile(ctx context.Context, userEmail string) (*models.UserProfile, error) {
 	profile, exists := mps.Profiles[userEmail]
 	if !exists {
-		return nil, errors.New("profile not found")
+		return nil, fmt.Errorf("profile %w", repositories.ErrNotFound)
 	}
-	return profile, nil
+	field := func(key string) string {
+		value, _ := profile[key].(string)
+		return value
+	}
+	return &models.UserProfile{
+		Email:     field("Email"),
+		Username:  field("Username"),
+		FirstName: field("FirstName"),
+		LastName:  field("LastName"),
+		Country:   field("Country"),
+		City:      field("City"),
+		ImageURL:  field("ImageURL"),
+		TimeZone:  field("TimeZone"),
+	}, nil
 }
 
 // UpdateProfile simulates updating a user's profile.
 func (mps *MockProfileService) UpdateProfile(ctx context.Context, userEmail string, updatedData map[string]interface{}) error {
 	profile, exists := mps.Profiles[userEmail]
 	if !exists {
-		return errors.New("profile not found")
+		return fmt.Errorf("profile %w", repositories.ErrNotFound)
 	}
 
 	// Simulate password validation.
@@ -114,3 +141,58 @@ func (mps *MockProfileService) UpdateProfile(ctx context.Context, userEmail stri
 
 	return nil
 }
+
+// RequestEmailChange simulates starting an email change after checking the current password.
+func (mps *MockProfileService) RequestEmailChange(ctx context.Context, userEmail, newEmail, currentPassword string) error {
+	profile, exists := mps.Profiles[userEmail]
+	if !exists {
+		return fmt.Errorf("profile %w", repositories.ErrNotFound)
+	}
+	if currentPassword != profile["Password"] {
+		return errors.New("Invalid current password")
+	}
+	if _, taken := mps.Profiles[newEmail]; taken {
+		return services.ErrEmailTaken
+	}
+	mps.PendingEmails[userEmail] = newEmail
+	return nil
+}
+
+// ConfirmEmailChange simulates completing an email change, moving the profile to the pending email.
+func (mps *MockProfileService) ConfirmEmailChange(ctx context.Context, userEmail, otp string) (string, error) {
+	newEmail, pending := mps.PendingEmails[userEmail]
+	if !pending {
+		return "", services.ErrNoPendingEmailChange
+	}
+	if otp != MockEmailChangeOTP {
+		return "", services.ErrInvalidOTP
+	}
+
+	profile := mps.Profiles[userEmail]
+	profile["Email"] = newEmail
+	mps.Profiles[newEmail] = profile
+	delete(mps.Profiles, userEmail)
+	delete(mps.PendingEmails, userEmail)
+	return "token-for-" + newEmail, nil
+}
+
+// UpdateAvatar simulates setting a profile picture without validating or storing it.
+func (mps *MockProfileService) UpdateAvatar(ctx context.Context, userEmail string, data []byte) (string, error) {
+	profile, exists := mps.Profiles[userEmail]
+	if !exists {
+		return "", fmt.Errorf("profile %w", repositories.ErrNotFound)
+	}
+	imageURL := MockStorageBaseURL + "avatars/" + userEmail
+	profile["ImageURL"] = imageURL
+	return imageURL, nil
+}
+
+// DeleteAvatar simulates removing a profile picture.
+func (mps *MockProfileService) DeleteAvatar(ctx context.Context, userEmail string) error {
+	profile, exists := mps.Profiles[userEmail]
+	if !exists {
+		return fmt.Errorf("profile %w", repositories.ErrNotFound)
+	}
+	delete(profile, "ImageURL")
+	return nil
+}
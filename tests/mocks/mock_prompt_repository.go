@@ -0,0 +1,32 @@
+/**
+ *  MockPromptRepository is the in-memory PromptRepository of the memory package, used for testing
+ *  journal prompts without relying on a database.
+ *
+ *  @file       mock_prompt_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockPromptRepository() - Creates an empty memory.PromptRepository.
+ *
+ *  @behaviors
+ *  - Tests seed and inspect the repository through its Offsets map, keyed by user email.
+ *  - Setting Err makes every method fail with it, e.g. repositories.ErrUnavailable to simulate an outage.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import "proh2052-group6/internal/repositories/memory"
+
+// MockPromptRepository is the in-memory PromptRepository.
+type MockPromptRepository = memory.PromptRepository
+
+// NewMockPromptRepository creates an empty in-memory PromptRepository.
+func NewMockPromptRepository() *MockPromptRepository {
+	return memory.NewPromptRepository()
+}
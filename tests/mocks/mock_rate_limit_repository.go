@@ -0,0 +1,73 @@
+/**
+ *  MockRateLimitRepository is a mock implementation of the RateLimitRepository interface.
+ *  It is used for testing persisted rate limits without relying on a database.
+ *
+ *  @file       mock_rate_limit_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockRateLimitRepository()          - Creates a new instance of MockRateLimitRepository.
+ *  - GetRateLimitBucket(ctx, key)          - Simulates loading a bucket.
+ *  - SaveRateLimitBuckets(ctx, buckets)    - Simulates storing buckets.
+ *
+ *  @behaviors
+ *  - Buckets are stored in memory, keyed by bucket key; setting Err makes every method fail with it.
+ *  - Safe for concurrent use, since buckets are loaded by requests while they are flushed.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import (
+	"context"
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/pkg/models"
+	"sync"
+)
+
+// MockRateLimitRepository provides an in-memory implementation of the RateLimitRepository interface.
+type MockRateLimitRepository struct {
+	mu      sync.Mutex
+	Buckets map[string]models.RateLimitBucket // In-memory buckets keyed by bucket key.
+	Saves   int                               // Number of SaveRateLimitBuckets calls that succeeded.
+
+	Err error // When set, every method returns Err, e.g. repositories.ErrUnavailable to simulate an outage.
+}
+
+// NewMockRateLimitRepository initializes a new MockRateLimitRepository instance.
+func NewMockRateLimitRepository() *MockRateLimitRepository {
+	return &MockRateLimitRepository{Buckets: make(map[string]models.RateLimitBucket)}
+}
+
+// GetRateLimitBucket simulates loading the bucket stored under key.
+func (mrr *MockRateLimitRepository) GetRateLimitBucket(ctx context.Context, key string) (*models.RateLimitBucket, error) {
+	mrr.mu.Lock()
+	defer mrr.mu.Unlock()
+	if mrr.Err != nil {
+		return nil, mrr.Err
+	}
+	bucket, ok := mrr.Buckets[key]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &bucket, nil
+}
+
+// SaveRateLimitBuckets simulates storing buckets, replacing the buckets stored under their keys.
+func (mrr *MockRateLimitRepository) SaveRateLimitBuckets(ctx context.Context, buckets []models.RateLimitBucket) error {
+	mrr.mu.Lock()
+	defer mrr.mu.Unlock()
+	if mrr.Err != nil {
+		return mrr.Err
+	}
+	for _, bucket := range buckets {
+		mrr.Buckets[bucket.Key] = bucket
+	}
+	mrr.Saves++
+	return nil
+}
@@ -0,0 +1,32 @@
+/**
+ *  MockShareRepository is the in-memory ShareRepository of the memory package, used for testing
+ *  shared event links without relying on a database.
+ *
+ *  @file       mock_share_repository.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockShareRepository() - Creates an empty memory.ShareRepository.
+ *
+ *  @behaviors
+ *  - Tests seed and inspect the repository through its Shares map, keyed by token.
+ *  - Setting Err makes every method fail with it, e.g. repositories.ErrUnavailable to simulate an outage.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import "proh2052-group6/internal/repositories/memory"
+
+// MockShareRepository is the in-memory ShareRepository.
+type MockShareRepository = memory.ShareRepository
+
+// NewMockShareRepository creates an empty in-memory ShareRepository.
+func NewMockShareRepository() *MockShareRepository {
+	return memory.NewShareRepository()
+}
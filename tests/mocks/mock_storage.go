@@ -0,0 +1,83 @@
+/**
+ *  MockStorageService is an in-memory implementation of the StorageServiceInterface.
+ *  It is used for testing file uploads such as profile pictures without Cloud Storage.
+ *
+ *  @file       mock_storage.go
+ *  @package    mocks
+ *
+ *  @methods
+ *  - NewMockStorageService()                     - Creates a new instance of MockStorageService.
+ *  - Upload(ctx, objectName, contentType, data)        - Simulates storing a public object and returns its URL.
+ *  - UploadPrivate(ctx, objectName, contentType, data) - Simulates storing a private object and returns its URL.
+ *  - Download(ctx, objectURL)                          - Simulates reading an object.
+ *  - Delete(ctx, objectURL)                            - Simulates deleting an object.
+ *
+ *  @behaviors
+ *  - Objects are stored in memory keyed by their URL, MockStorageBaseURL followed by the object name.
+ *  - Downloading an unknown URL returns repositories.ErrNotFound; deleting one is not an error, like
+ *    the Cloud Storage implementation.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package mocks
+
+import (
+	"context"
+	"fmt"
+
+	"proh2052-group6/internal/repositories"
+)
+
+// MockStorageBaseURL is the prefix of the URLs returned by MockStorageService.Upload.
+const MockStorageBaseURL = "https://storage.example.com/"
+
+// MockStoredObject is an object stored by MockStorageService.
+type MockStoredObject struct {
+	ContentType string
+	Data        []byte
+	Private     bool // Whether the object was stored with UploadPrivate.
+}
+
+// MockStorageService provides an in-memory implementation of the StorageServiceInterface.
+type MockStorageService struct {
+	Objects map[string]MockStoredObject // Stored objects keyed by URL.
+}
+
+// NewMockStorageService initializes a new MockStorageService instance.
+func NewMockStorageService() *MockStorageService {
+	return &MockStorageService{Objects: make(map[string]MockStoredObject)}
+}
+
+// Upload simulates storing an object and returns its URL.
+func (mss *MockStorageService) Upload(ctx context.Context, objectName, contentType string, data []byte) (string, error) {
+	objectURL := MockStorageBaseURL + objectName
+	mss.Objects[objectURL] = MockStoredObject{ContentType: contentType, Data: data}
+	return objectURL, nil
+}
+
+// UploadPrivate simulates storing an object that is not publicly readable and returns its URL.
+func (mss *MockStorageService) UploadPrivate(ctx context.Context, objectName, contentType string, data []byte) (string, error) {
+	objectURL := MockStorageBaseURL + objectName
+	mss.Objects[objectURL] = MockStoredObject{ContentType: contentType, Data: data, Private: true}
+	return objectURL, nil
+}
+
+// Download simulates reading the object behind objectURL.
+func (mss *MockStorageService) Download(ctx context.Context, objectURL string) ([]byte, error) {
+	object, exists := mss.Objects[objectURL]
+	if !exists {
+		return nil, fmt.Errorf("Stored object %w", repositories.ErrNotFound)
+	}
+	return object.Data, nil
+}
+
+// Delete simulates deleting the object behind objectURL.
+func (mss *MockStorageService) Delete(ctx context.Context, objectURL string) error {
+	delete(mss.Objects, objectURL)
+	return nil
+}
@@ -61,8 +61,8 @@ type MockUserService struct {
 	VerifyEmailFunc           func(ctx context.Context, email, otp string) (string, error)
 	ForgotPasswordFunc        func(ctx context.Context, email string) error
 	ResetPasswordFunc         func(ctx context.Context, email, otp, newPassword string) error
-	GetUserInfoFunc           func(ctx context.Context, userEmail string) (map[string]string, error)
-	SearchUsersByUsernameFunc func(ctx context.Context, userEmail, query string) ([]map[string]string, error)
+	GetUserInfoFunc           func(ctx context.Context, userEmail string) (*models.UserProfile, error)
+	SearchUsersByUsernameFunc func(ctx context.Context, userEmail, query string, excludeBlocked, excludeRelated bool, limit, offset int) ([]map[string]string, error)
 }
 
 // Signup mocks the Signup method of the UserServiceInterface.
@@ -114,7 +114,7 @@ func (m *MockUserService) ResetPassword(ctx context.Context, email, otp, newPass
 }
 
 // GetUserInfo mocks retrieving basic user information like email, username, country, etc.
-func (m *MockUserService) GetUserInfo(ctx context.Context, userEmail string) (map[string]string, error) {
+func (m *MockUserService) GetUserInfo(ctx context.Context, userEmail string) (*models.UserProfile, error) {
 	if m.GetUserInfoFunc != nil {
 		return m.GetUserInfoFunc(ctx, userEmail)
 	}
@@ -122,9 +122,9 @@ func (m *MockUserService) GetUserInfo(ctx context.Context, userEmail string) (ma
 }
 
 // SearchUsersByUsername mocks searching for users by a query substring.
-func (m *MockUserService) SearchUsersByUsername(ctx context.Context, userEmail, query string) ([]map[string]string, error) {
+func (m *MockUserService) SearchUsersByUsername(ctx context.Context, userEmail, query string, excludeBlocked, excludeRelated bool, limit, offset int) ([]map[string]string, error) {
 	if m.SearchUsersByUsernameFunc != nil {
-		return m.SearchUsersByUsernameFunc(ctx, userEmail, query)
+		return m.SearchUsersByUsernameFunc(ctx, userEmail, query, excludeBlocked, excludeRelated, limit, offset)
 	}
 	return nil, fmt.Errorf("SearchUsersByUsernameFunc not implemented")
 }
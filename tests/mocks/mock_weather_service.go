@@ -0,0 +1,51 @@
+/**
+ *  MockWeatherService provides a mock implementation of the WeatherServiceInterface for testing
+ *  purposes, so handlers can be tested without calling the weather API.
+ *
+ *  @struct   MockWeatherService
+ *  @inherits WeatherServiceInterface
+ *
+ *  @fields
+ *  - GetForecastFunc (func): A customizable function that simulates the behavior of `GetForecast`.
+ *
+ *  @methods
+ *  - GetForecast(ctx, latitude, longitude, date) (*services.Forecast, error): Calls the mock function
+ *    to simulate fetching a forecast. If the mock function is not defined, it returns a default error.
+ *
+ *  @example
+ *  ```
+ *  mockWeatherService := &MockWeatherService{
+ *      GetForecastFunc: func(ctx context.Context, latitude, longitude float64, date string) (*services.Forecast, error) {
+ *          return &services.Forecast{Date: date, TemperatureMax: 12, Summary: "Overcast"}, nil
+ *      },
+ *  }
+ *  ```
+ *
+ *  @file      mock_weather_service.go
+ *  @project   DailyVerse
+ *  @framework Go Testing with Mock Services
+ */
+
+package mocks
+
+import (
+	"context"
+	"fmt"
+
+	"proh2052-group6/internal/services"
+)
+
+// MockWeatherService is a mock implementation of the WeatherServiceInterface.
+// It allows you to define custom behavior for the GetForecast method.
+type MockWeatherService struct {
+	GetForecastFunc func(ctx context.Context, latitude, longitude float64, date string) (*services.Forecast, error)
+}
+
+// GetForecast calls the mocked GetForecastFunc if it's set.
+// Otherwise, it returns a default error.
+func (m *MockWeatherService) GetForecast(ctx context.Context, latitude, longitude float64, date string) (*services.Forecast, error) {
+	if m.GetForecastFunc != nil {
+		return m.GetForecastFunc(ctx, latitude, longitude, date)
+	}
+	return nil, fmt.Errorf("GetForecastFunc not implemented")
+}
@@ -0,0 +1,85 @@
+/**
+ *  IDCodec Tests check how emails are turned into Firestore document IDs: which characters are
+ *  escaped, that composite IDs stay unambiguous, and that every ID decodes back to its parts.
+ *
+ *  @file       idcodec_test.go
+ *  @package    repositories_test
+ *
+ *  @test_cases
+ *  - TestEncodeID                - Tests that plain emails keep their ID and "_", "/" and other bytes are escaped.
+ *  - TestDecodeID                - Tests the round trip of EncodeID and the errors for IDs it cannot produce.
+ *  - TestCompositeID_Unambiguous - Tests that emails with "_" make distinct composite IDs that split back.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"proh2052-group6/internal/repositories"
+)
+
+func TestEncodeID(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"alice@example.com", "alice@example.com"},
+		{"Alice.Smith+news@example-mail.com", "Alice.Smith+news@example-mail.com"},
+		{"john_doe@example.com", "john%5Fdoe@example.com"},
+		{`"a/b"@example.com`, "%22a%2Fb%22@example.com"},
+		{"100%@example.com", "100%25@example.com"},
+		{"ølav@example.com", "%C3%B8lav@example.com"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := repositories.EncodeID(tt.key); got != tt.want {
+			t.Errorf("EncodeID(%q): expected %q, got %q", tt.key, tt.want, got)
+		}
+	}
+}
+
+func TestDecodeID(t *testing.T) {
+	for _, key := range []string{"alice@example.com", "john_doe@example.com", `"a/b"@example.com`, "100%@example.com", "ølav@example.com"} {
+		if got, err := repositories.DecodeID(repositories.EncodeID(key)); err != nil || got != key {
+			t.Errorf("Expected %q to survive the round trip, got %q (err: %v)", key, got, err)
+		}
+	}
+	for _, id := range []string{"john_doe@example.com", "100%", "100%2", "a%5fb", "a%ZZb"} {
+		if _, err := repositories.DecodeID(id); err == nil {
+			t.Errorf("Expected DecodeID(%q) to fail", id)
+		}
+	}
+}
+
+func TestCompositeID_Unambiguous(t *testing.T) {
+	pairs := [][]string{
+		{"john_doe@example.com", "jane@example.com"},
+		{"john", "doe@example.com_jane@example.com"},
+		{"john_doe@example.com_jane", "example.com"},
+	}
+	seen := map[string][]string{}
+	for _, pair := range pairs {
+		id := repositories.CompositeID(pair...)
+		if strings.Count(id, "_") != 1 {
+			t.Errorf("Expected exactly one separator in %q", id)
+		}
+		if other, exists := seen[id]; exists {
+			t.Errorf("Expected distinct IDs, %q and %q both map to %q", other, pair, id)
+		}
+		seen[id] = pair
+
+		parts, err := repositories.SplitCompositeID(id)
+		if err != nil || !reflect.DeepEqual(parts, pair) {
+			t.Errorf("Expected %q to split into %q, got %q (err: %v)", id, pair, parts, err)
+		}
+	}
+}
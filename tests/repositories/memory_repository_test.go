@@ -0,0 +1,211 @@
+/**
+ *  Memory Repository Tests check the in-memory repositories used with STORAGE_BACKEND=memory:
+ *  that they can be shared by concurrent requests and keep the semantics of the Firestore ones.
+ *  Run them with -race to have the race detector check the locking.
+ *
+ *  @file       memory_repository_test.go
+ *  @package    repositories_test
+ *
+ *  @test_cases
+ *  - TestMemoryFriendRepository_Concurrent     - Tests sending, accepting, listing and deleting requests from many goroutines.
+ *  - TestMemoryUserRepository_MigrateUserEmail - Tests that a user's events and journals move with their email, like subcollections.
+ *  - TestMemoryUserRepository_Copies           - Tests that returned users are copies and updates are keyed by field name.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/repositories/memory"
+	"proh2052-group6/pkg/models"
+)
+
+func TestMemoryFriendRepository_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewFriendRepository()
+	const users = 20
+	email := func(i int) string { return fmt.Sprintf("user%d@example.com", i) }
+
+	// Every user sends a request to every other user with a higher number, while others read.
+	var wg sync.WaitGroup
+	for i := 0; i < users; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := i + 1; j < users; j++ {
+				if err := repo.CreateFriendRequest(ctx, &models.Friend{Email: email(i), FriendEmail: email(j), Status: "pending"}); err != nil {
+					t.Errorf("Failed to send request: %v", err)
+				}
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < users; j++ {
+				if _, err := repo.GetPendingFriendRequests(ctx, email(i)); err != nil {
+					t.Errorf("Failed to list requests: %v", err)
+				}
+				if _, err := repo.GetFriendsOfUsers(ctx, []string{email(i), email(j)}); err != nil {
+					t.Errorf("Failed to list friends: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Two goroutines race to accept each request; exactly one of them wins.
+	var mu sync.Mutex
+	accepted := 0
+	for i := 0; i < users; i++ {
+		for j := i + 1; j < users; j++ {
+			for attempt := 0; attempt < 2; attempt++ {
+				wg.Add(1)
+				go func(i, j int) {
+					defer wg.Done()
+					err := repo.AcceptFriendRequest(ctx, email(i), email(j))
+					if errors.Is(err, repositories.ErrFriendRequestNotPending) {
+						return
+					}
+					if err != nil {
+						t.Errorf("Failed to accept request: %v", err)
+						return
+					}
+					mu.Lock()
+					accepted++
+					mu.Unlock()
+				}(i, j)
+			}
+		}
+	}
+	// Meanwhile the users block and unblock each other and count their friends.
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			blocked := email((i + 1) % users)
+			_ = repo.CreateBlock(ctx, &models.Block{BlockerEmail: email(i), BlockedEmail: blocked})
+			_, _ = repo.GetBlock(ctx, email(i), blocked)
+			_ = repo.DeleteBlock(ctx, email(i), blocked)
+			_, _ = repo.CountFriends(ctx, email(i), 0)
+		}(i)
+	}
+	wg.Wait()
+
+	if want := users * (users - 1) / 2; accepted != want {
+		t.Errorf("Expected %d accepted requests, got %d", want, accepted)
+	}
+	for i := 0; i < users; i++ {
+		if count, err := repo.CountFriends(ctx, email(i), 0); err != nil || count != users-1 {
+			t.Errorf("Expected %s to have %d friends, got %d (%v)", email(i), users-1, count, err)
+		}
+		if blocks, _ := repo.GetBlockedUsers(ctx, email(i)); len(blocks) != 0 {
+			t.Errorf("Expected the blocks of %s to be removed, got %v", email(i), blocks)
+		}
+	}
+
+	// Deleting concurrently leaves no friendships behind.
+	for i := 0; i < users; i++ {
+		for j := i + 1; j < users; j++ {
+			wg.Add(1)
+			go func(i, j int) {
+				defer wg.Done()
+				if err := repo.DeleteFriendRequest(ctx, email(i), email(j)); err != nil {
+					t.Errorf("Failed to delete request: %v", err)
+				}
+			}(i, j)
+		}
+	}
+	wg.Wait()
+	if friends, _ := repo.GetFriendsOfUsers(ctx, []string{email(0)}); len(friends) != 0 {
+		t.Errorf("Expected no friendships after deleting them, got %d", len(friends))
+	}
+}
+
+func TestMemoryUserRepository_MigrateUserEmail(t *testing.T) {
+	ctx := context.Background()
+	users, events, journals := memory.NewUserRepository(), memory.NewEventRepository(), memory.NewJournalRepository()
+	users.Events, users.Journals = events, journals
+
+	for _, email := range []string{"old@example.com", "bob@example.com"} {
+		if err := users.CreateUser(ctx, &models.User{Email: email, Username: email[:3]}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+	event := &models.Event{Email: "old@example.com", Title: "Lecture", Date: "2024-09-02"}
+	bobEvent := &models.Event{Email: "bob@example.com", Title: "Gym", Date: "2024-09-02"}
+	journal := &models.Journal{Email: "old@example.com", Date: "2024-09-02", Content: "Dear diary"}
+	for _, err := range []error{events.CreateEvent(ctx, event), events.CreateEvent(ctx, bobEvent), journals.CreateJournal(ctx, journal)} {
+		if err != nil {
+			t.Fatalf("Failed to seed: %v", err)
+		}
+	}
+
+	// Like a subcollection, another user cannot reach or delete the event.
+	if _, err := events.GetEvent(ctx, "bob@example.com", event.EventID); err == nil {
+		t.Error("Expected another user's event to be not found")
+	}
+	if err := events.DeleteEvent(ctx, "bob@example.com", event.EventID); err != nil {
+		t.Errorf("Expected deleting another user's event to succeed, got %v", err)
+	}
+
+	if err := users.MigrateUserEmail(ctx, "old@example.com", "bob@example.com"); err == nil {
+		t.Error("Expected moving onto an existing user to fail")
+	}
+	if err := users.MigrateUserEmail(ctx, "old@example.com", "new@example.com"); err != nil {
+		t.Fatalf("Failed to migrate user: %v", err)
+	}
+	if _, err := users.GetUserByEmail(ctx, "old@example.com"); err == nil {
+		t.Error("Expected the old email to be gone")
+	}
+	if moved, err := events.GetEvent(ctx, "new@example.com", event.EventID); err != nil || moved.Title != "Lecture" {
+		t.Errorf("Expected the event to move to the new email, got %+v (%v)", moved, err)
+	}
+	if moved, err := journals.GetJournalByDate(ctx, "new@example.com", "2024-09-02"); err != nil || moved == nil {
+		t.Errorf("Expected the journal to move to the new email, got %+v (%v)", moved, err)
+	}
+	if count, _ := events.CountEvents(ctx, "bob@example.com", 0); count != 1 {
+		t.Errorf("Expected the other user's events to stay, got %d", count)
+	}
+}
+
+func TestMemoryUserRepository_Copies(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepository()
+	if err := users.CreateUser(ctx, &models.User{Email: "alice@example.com", Username: "Alice", UsernameLower: "alice"}); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	alice, _ := users.GetUserByUsername(ctx, "ALICE")
+	alice.Country = "Norway"
+	if stored, _ := users.GetUserByEmail(ctx, "alice@example.com"); stored.Country != "" {
+		t.Error("Expected changing a returned user not to change the repository")
+	}
+
+	if err := users.UpdateUser(ctx, "alice@example.com", map[string]interface{}{"Country": "Norway", "NotificationsEnabled": false}); err != nil {
+		t.Fatalf("Failed to update user: %v", err)
+	}
+	stored, _ := users.GetUserByEmail(ctx, "alice@example.com")
+	if stored.Country != "Norway" || stored.NotificationsEnabled == nil || *stored.NotificationsEnabled {
+		t.Errorf("Expected the updated fields, got %+v", stored)
+	}
+	if err := users.UpdateUser(ctx, "alice@example.com", map[string]interface{}{"Country": "Sweden", "Nickname": "Al"}); err == nil {
+		t.Error("Expected an unknown field to be rejected")
+	}
+	if stored, _ := users.GetUserByEmail(ctx, "alice@example.com"); stored.Country != "Norway" {
+		t.Errorf("Expected a rejected update to change nothing, got %q", stored.Country)
+	}
+	if err := users.UpdateUser(ctx, "nobody@example.com", map[string]interface{}{"Country": "Norway"}); err == nil {
+		t.Error("Expected updating a missing user to fail")
+	}
+}
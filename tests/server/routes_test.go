@@ -0,0 +1,172 @@
+/**
+ *  Routes Tests validate the answers to requests no route serves: a JSON 404 for unknown paths and a
+ *  JSON 405 with an Allow header for known paths requested with the wrong method. The routers are
+ *  wrapped like main does, so the CORS headers are checked on these answers too.
+ *
+ *  @file       routes_test.go
+ *  @package    server_test
+ *
+ *  @test_cases
+ *  - TestRoutes_NotFound           - Tests the JSON 404 for unknown paths, inside and outside /api.
+ *  - TestRoutes_NotFoundDebugLog   - Tests that unknown /api paths are logged only with debug logging.
+ *  - TestRoutes_MethodNotAllowed   - Tests the JSON 405 and the methods listed in its Allow header.
+ *  - TestRoutes_DigestRunAdminOnly - Tests that the development digest route rejects users who are not administrators.
+ *
+ *  @dependencies
+ *  - server.NewAPIRouter, server.NewRootRouter: The routes the server registers.
+ *  - middleware.NewCORS: Adds the CORS headers the web app needs to read the answers.
+ *  - middleware.NewJwtAuthMiddleware, middleware.NewAdminOnlyMiddleware: Guard the administrator routes.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/server"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+	"proh2052-group6/tests/mocks"
+)
+
+// testOrigin is the web app origin allowed by newTestHandler.
+const testOrigin = "https://app.example.com"
+
+// newTestHandler wraps the routers like main does, with middleware that passes every request through.
+// debug is passed to NewAPIRouter.
+func newTestHandler(debug bool) http.Handler {
+	passThrough := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	wrap := func(next http.Handler) http.Handler { return next }
+	m := server.Middleware{
+		JWTAuth: passThrough, WebSocketAuth: passThrough, AdminOnly: passThrough,
+		SignupLimit: wrap, LoginLimit: wrap, OTPLimit: wrap, ExportLimit: wrap, InviteLimit: wrap,
+		Instrument: wrap, JSONBody: wrap, ImportBody: wrap,
+	}
+	api := server.NewAPIRouter(server.Handlers{}, m, false, debug)
+	return server.NewRootRouter(server.Handlers{}, m, middleware.NewCORS([]string{testOrigin})(api))
+}
+
+// serveFromOrigin sends a request from the web app's origin to handler.
+func serveFromOrigin(handler http.Handler, method, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Origin", testOrigin)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+// checkJSONError checks that rr is a JSON error with the status code and message, readable by the web app.
+func checkJSONError(t *testing.T, rr *httptest.ResponseRecorder, code int, message string) {
+	t.Helper()
+	if rr.Code != code {
+		t.Errorf("Expected status %d, got %d: %s", code, rr.Code, rr.Body.String())
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected a JSON response, got Content-Type %q", contentType)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil || body["message"] != message {
+		t.Errorf("Expected message %q, got %v, %v", message, body, err)
+	}
+	if origin := rr.Header().Get("Access-Control-Allow-Origin"); origin != testOrigin {
+		t.Errorf("Expected the CORS header for %s, got %q", testOrigin, origin)
+	}
+}
+
+func TestRoutes_NotFound(t *testing.T) {
+	handler := newTestHandler(false)
+
+	for _, target := range []string{"/api/bogus", "/api/events/creat", "/favicon.ico"} {
+		rr := serveFromOrigin(handler, http.MethodGet, target)
+		checkJSONError(t, rr, http.StatusNotFound, "Not found")
+		if allow := rr.Header().Get("Allow"); allow != "" {
+			t.Errorf("%s: expected no Allow header on a 404, got %q", target, allow)
+		}
+	}
+}
+
+func TestRoutes_NotFoundDebugLog(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	serveFromOrigin(newTestHandler(false), http.MethodGet, "/api/bogus")
+	if logs.Len() != 0 {
+		t.Errorf("Expected no log without debug logging, got %q", logs.String())
+	}
+
+	handler := newTestHandler(true)
+	serveFromOrigin(handler, http.MethodGet, "/favicon.ico")
+	if logs.Len() != 0 {
+		t.Errorf("Expected no log for a path outside /api, got %q", logs.String())
+	}
+	rr := serveFromOrigin(handler, http.MethodGet, "/api/bogus")
+	checkJSONError(t, rr, http.StatusNotFound, "Not found")
+	if !strings.Contains(logs.String(), `debug unmatched_route method=GET path="/api/bogus"`) {
+		t.Errorf("Expected the unknown path to be logged, got %q", logs.String())
+	}
+}
+
+func TestRoutes_MethodNotAllowed(t *testing.T) {
+	handler := newTestHandler(false)
+
+	tests := []struct {
+		method string
+		target string
+		allow  string
+	}{
+		{http.MethodGet, "/api/login", "POST"},
+		{http.MethodPost, "/api/events/update", "PATCH, PUT"},
+		{http.MethodDelete, "/api/events/all", "GET, HEAD"},
+	}
+	for _, test := range tests {
+		rr := serveFromOrigin(handler, test.method, test.target)
+		checkJSONError(t, rr, http.StatusMethodNotAllowed, "Method not allowed")
+		if allow := rr.Header().Get("Allow"); allow != test.allow {
+			t.Errorf("%s %s: expected Allow %q, got %q", test.method, test.target, test.allow, allow)
+		}
+	}
+}
+
+func TestRoutes_DigestRunAdminOnly(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user@example.com": {Email: "user@example.com", Username: "user", Role: models.RoleUser, IsVerified: true},
+	})
+	jwtManager := utils.NewJWTManager("test-secret", nil, 0, "")
+	wrap := func(next http.Handler) http.Handler { return next }
+	m := server.Middleware{
+		JWTAuth:       middleware.NewJwtAuthMiddleware(userRepo, jwtManager),
+		WebSocketAuth: middleware.NewJwtAuthMiddleware(userRepo, jwtManager),
+		AdminOnly:     middleware.NewAdminOnlyMiddleware(userRepo),
+		SignupLimit:   wrap, LoginLimit: wrap, OTPLimit: wrap, ExportLimit: wrap, InviteLimit: wrap,
+		Instrument: wrap, JSONBody: wrap, ImportBody: wrap,
+	}
+	handler := server.NewAPIRouter(server.Handlers{}, m, true, false)
+
+	token, err := jwtManager.GenerateJWT("user@example.com", 0)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/digest/run", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusForbidden, rr.Code, rr.Body.String())
+	}
+}
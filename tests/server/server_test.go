@@ -0,0 +1,142 @@
+/**
+ *  Server Tests validate graceful shutdown of the HTTP server and the per-request timeout middleware.
+ *  The server is started on a random local port and stopped with a real SIGTERM.
+ *
+ *  @file       server_test.go
+ *  @package    server_test
+ *
+ *  @test_cases
+ *  - TestServe_GracefulShutdown          - Tests that an in-flight request completes after SIGTERM.
+ *  - TestServe_ShutdownTimeout           - Tests that shutdown gives up after the timeout.
+ *  - TestRequestTimeout_CancelsContext   - Tests that handlers see their context cancelled after the timeout.
+ *
+ *  @dependencies
+ *  - server.Serve: Runs the server until its context is cancelled.
+ *  - middleware.NewRequestTimeout: Attaches the per-request deadline.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/middleware"
+	"proh2052-group6/internal/server"
+)
+
+// startServer serves handler on a random local port until ctx is cancelled.
+// It returns the server's base URL and a channel receiving the result of server.Serve.
+func startServer(t *testing.T, ctx context.Context, handler http.Handler, shutdownTimeout time.Duration) (string, <-chan error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx, &http.Server{Handler: handler}, listener, shutdownTimeout)
+	}()
+	return "http://" + listener.Addr().String(), done
+}
+
+func TestServe_GracefulShutdown(t *testing.T) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
+
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(w, "done")
+	})
+	baseURL, done := startServer(t, ctx, handler, 5*time.Second)
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(baseURL + "/slow")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("Failed to send SIGTERM: %v", err)
+	}
+
+	res := <-response
+	if res.err != nil || res.body != "done" {
+		t.Fatalf("Expected the in-flight request to complete, got %q (err: %v)", res.body, res.err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Expected a graceful shutdown, got %v", err)
+	}
+
+	// The server no longer accepts requests.
+	if _, err := http.Get(baseURL + "/slow"); err == nil {
+		t.Errorf("Expected requests after shutdown to fail")
+	}
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	baseURL, done := startServer(t, ctx, handler, 50*time.Millisecond)
+
+	go http.Get(baseURL + "/stuck")
+	<-started
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected shutdown to time out, got %v", err)
+	}
+}
+
+func TestRequestTimeout_CancelsContext(t *testing.T) {
+	var ctxErr error
+	handler := middleware.NewRequestTimeout(20 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			ctxErr = r.Context().Err()
+		case <-time.After(time.Second):
+		}
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+	if !errors.Is(ctxErr, context.DeadlineExceeded) {
+		t.Errorf("Expected the request context to hit its deadline, got %v", ctxErr)
+	}
+}
@@ -0,0 +1,264 @@
+/**
+ *  AdminService Tests validate the administrator actions: searching every account, verifying an
+ *  account by hand and disabling it, moving mixed-case accounts to their normalized email, and that
+ *  users cannot give themselves the role or flag.
+ *
+ *  @file       admin_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestAdminService_SearchUsers           - Tests exact email lookups, prefix searches over every account and paging.
+ *  - TestAdminService_VerifyUser            - Tests manual verification, its audit entry and the already verified and unknown cases.
+ *  - TestAdminService_SetUserDisabled       - Tests disabling and re-enabling, their audit entries, login and self-disabling.
+ *  - TestAdminService_RoleNotSelfAssignable - Tests that signup and profile updates cannot set the role or disabled flag.
+ *  - TestAdminService_NormalizeUserEmails   - Tests moving mixed-case accounts and their friends, conflicts, and running it twice.
+ *  - TestAdminService_NormalizeUserEmails_Resume - Tests that an account whose friends failed to move is finished by the next run.
+ *
+ *  @dependencies
+ *  - mocks.NewMockUserRepository: Holds the accounts.
+ *  - mocks.NewMockAuditRepository: Stores the audit log in memory.
+ *  - mocks.NewMockFriendRepository, mocks.NewMockInvitationRepository: Hold the friend requests and invitations moved with an account.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newAdminService creates an AdminService over the accounts of newUsernameTestRepo and an admin.
+func newAdminService(t *testing.T) (*services.AdminService, *mocks.MockUserRepository, *mocks.MockAuditRepository) {
+	t.Helper()
+	userRepo := newUsernameTestRepo(t)
+	userRepo.Users["admin@example.com"] = &models.User{Email: "admin@example.com", Username: "admin", Role: models.RoleAdmin, IsVerified: true}
+	auditService, auditRepo := newAuditService()
+	return services.NewAdminService(userRepo, auditService).(*services.AdminService), userRepo, auditRepo
+}
+
+func TestAdminService_SearchUsers(t *testing.T) {
+	adminService, userRepo, _ := newAdminService(t)
+	ctx := context.Background()
+	userRepo.Users["alice@example.com"].Disabled = true
+
+	users, err := adminService.SearchUsers(ctx, "admin@example.com", "alice@example.com", 0, 0)
+	if err != nil || len(users) != 1 || users[0].Username != "Alice" || !users[0].Disabled || users[0].Role != models.RoleUser {
+		t.Errorf("Expected alice by email, disabled and with the user role, got %+v, %v", users, err)
+	}
+	users, err = adminService.SearchUsers(ctx, "admin@example.com", "nobody@example.com", 0, 0)
+	if err != nil || len(users) != 0 {
+		t.Errorf("Expected no account for an unknown email, got %+v, %v", users, err)
+	}
+
+	// Without a query every account is listed, including the admin themselves.
+	var usernames []string
+	for offset := 0; offset < 3; offset++ {
+		page, err := adminService.SearchUsers(ctx, "admin@example.com", "", 1, offset)
+		if err != nil || len(page) != 1 {
+			t.Fatalf("Expected 1 account at offset %d, got %+v, %v", offset, page, err)
+		}
+		usernames = append(usernames, page[0].Username)
+	}
+	if fmt.Sprint(usernames) != "[admin Alice bob]" {
+		t.Errorf("Expected every account once, in username order, got %v", usernames)
+	}
+
+	userRepo.Err = repositories.ErrUnavailable
+	if _, err := adminService.SearchUsers(ctx, "admin@example.com", "al", 0, 0); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestAdminService_VerifyUser(t *testing.T) {
+	adminService, userRepo, auditRepo := newAdminService(t)
+	ctx := context.Background()
+	userRepo.Users["alice@example.com"].OTP = "hash"
+
+	if err := adminService.VerifyUser(ctx, "admin@example.com", "alice@example.com"); err != nil {
+		t.Fatalf("Failed to verify user: %v", err)
+	}
+	alice := userRepo.Users["alice@example.com"]
+	if !alice.IsVerified || alice.OTP != "" {
+		t.Errorf("Expected alice to be verified with no pending OTP, got %+v", alice)
+	}
+	if got := fmt.Sprint(auditRepo.Actions("alice@example.com")); got != fmt.Sprint([]string{services.AuditEmailVerifiedByAdmin}) {
+		t.Errorf("Expected the verification in alice's audit log, got %s", got)
+	}
+
+	if err := adminService.VerifyUser(ctx, "admin@example.com", "alice@example.com"); !errors.Is(err, services.ErrAlreadyVerified) {
+		t.Errorf("Expected ErrAlreadyVerified, got %v", err)
+	}
+	if err := adminService.VerifyUser(ctx, "admin@example.com", "nobody@example.com"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAdminService_SetUserDisabled(t *testing.T) {
+	adminService, userRepo, auditRepo := newAdminService(t)
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+	ctx := context.Background()
+	userRepo.Users["alice@example.com"].IsVerified = true
+	login := &models.LoginRequest{Email: "alice@example.com", Password: "Password123!"}
+
+	if err := adminService.SetUserDisabled(ctx, "admin@example.com", "alice@example.com", true); err != nil {
+		t.Fatalf("Failed to disable user: %v", err)
+	}
+	if _, err := userService.Login(ctx, login); !errors.Is(err, services.ErrAccountDisabled) {
+		t.Errorf("Expected ErrAccountDisabled, got %v", err)
+	}
+	// The disabled account is only revealed to someone who knows the password.
+	if _, err := userService.Login(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "Wrong123!"}); !errors.Is(err, services.ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for a wrong password, got %v", err)
+	}
+
+	if err := adminService.SetUserDisabled(ctx, "admin@example.com", "alice@example.com", false); err != nil {
+		t.Fatalf("Failed to enable user: %v", err)
+	}
+	if _, err := userService.Login(ctx, login); err != nil {
+		t.Errorf("Expected the re-enabled user to log in, got %v", err)
+	}
+	want := []string{services.AuditAccountDisabled, services.AuditAccountEnabled}
+	if got := auditRepo.Actions("alice@example.com"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected audit actions %v, got %v", want, got)
+	}
+
+	if err := adminService.SetUserDisabled(ctx, "admin@example.com", "ADMIN@example.com", true); !errors.Is(err, services.ErrDisableSelf) {
+		t.Errorf("Expected ErrDisableSelf, got %v", err)
+	}
+	if err := adminService.SetUserDisabled(ctx, "admin@example.com", "nobody@example.com", true); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAdminService_RoleNotSelfAssignable(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	ctx := context.Background()
+
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+	user := &models.User{Email: "carol@example.com", Username: "carol", Password: "Password123!", Country: "Norway", City: "Oslo",
+		Role: models.RoleAdmin, Disabled: true}
+	if err := userService.Signup(ctx, user); err != nil {
+		t.Fatalf("Failed to sign up: %v", err)
+	}
+	if carol := userRepo.Users["carol@example.com"]; carol.Role != models.RoleUser || carol.Disabled {
+		t.Errorf("Expected a new account to be an enabled user, got role %q, disabled %v", carol.Role, carol.Disabled)
+	}
+
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+	err := profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{"City": "Oslo", "Role": models.RoleAdmin, "Disabled": true, "CurrentPassword": "Password123!"})
+	if err != nil {
+		t.Fatalf("Failed to update profile: %v", err)
+	}
+	if alice := userRepo.Users["alice@example.com"]; alice.Role != "" || alice.Disabled || alice.City != "Oslo" {
+		t.Errorf("Expected the profile update to ignore the role and disabled flag, got %+v", alice)
+	}
+}
+
+func TestAdminService_NormalizeUserEmails(t *testing.T) {
+	adminService, userRepo, _ := newAdminService(t)
+	ctx := context.Background()
+	// Accounts created before emails were normalized; BOB@example.com collides with bob@example.com.
+	userRepo.Users["Carol@Example.com"] = &models.User{Email: "Carol@Example.com", Username: "carol", IsVerified: true}
+	userRepo.Users["BOB@example.com"] = &models.User{Email: "BOB@example.com", Username: "bob2"}
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		repositories.CompositeID("alice@example.com", "Carol@Example.com"): {Email: "alice@example.com", FriendEmail: "Carol@Example.com", Status: "accepted"},
+	})
+	adminService.FriendRepo = friendRepo
+
+	report, err := adminService.NormalizeUserEmails(ctx, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Failed to normalize emails: %v", err)
+	}
+	if fmt.Sprint(report.Migrated, report.Conflicts, report.Failed) != "[Carol@Example.com] [BOB@example.com] []" {
+		t.Errorf("Expected carol migrated and BOB in conflict, got %+v", report)
+	}
+	if carol := userRepo.Users["carol@example.com"]; carol == nil || carol.Email != "carol@example.com" || carol.Username != "carol" {
+		t.Errorf("Expected carol to be stored under carol@example.com, got %+v", carol)
+	}
+	if _, stale := userRepo.Users["Carol@Example.com"]; stale {
+		t.Error("Expected the mixed-case account to be removed")
+	}
+	if userRepo.Users["bob@example.com"].Username != "bob" || userRepo.Users["BOB@example.com"] == nil {
+		t.Error("Expected both bob accounts to be left alone")
+	}
+	if friend := friendRepo.Friends[repositories.CompositeID("alice@example.com", "carol@example.com")]; friend == nil || friend.FriendEmail != "carol@example.com" {
+		t.Errorf("Expected the friendship with carol to follow her, got %+v", friendRepo.Friends)
+	}
+	if users, err := adminService.SearchUsers(ctx, "admin@example.com", "CAROL@example.com", 0, 0); err != nil || len(users) != 1 {
+		t.Errorf("Expected carol to be found by email, got %+v, %v", users, err)
+	}
+
+	// Running it again leaves only the conflict.
+	report, err = adminService.NormalizeUserEmails(ctx, "admin@example.com")
+	if err != nil || len(report.Migrated) != 0 || fmt.Sprint(report.Conflicts) != "[BOB@example.com]" {
+		t.Errorf("Expected only the conflict on a second run, got %+v, %v", report, err)
+	}
+
+	userRepo.Err = repositories.ErrUnavailable
+	if _, err := adminService.NormalizeUserEmails(ctx, "admin@example.com"); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestAdminService_NormalizeUserEmails_Resume(t *testing.T) {
+	adminService, userRepo, _ := newAdminService(t)
+	ctx := context.Background()
+	userRepo.Users["Carol@Example.com"] = &models.User{Email: "Carol@Example.com", Username: "carol", IsVerified: true}
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		repositories.CompositeID("alice@example.com", "Carol@Example.com"): {Email: "alice@example.com", FriendEmail: "Carol@Example.com", Status: "accepted"},
+	})
+	invitationRepo := mocks.NewMockInvitationRepository()
+	invitationRepo.CreateInvitation(ctx, &models.EventInvitation{EventID: "event1", OwnerEmail: "alice@example.com", InviteeEmail: "Carol@Example.com", Status: "pending"})
+	adminService.FriendRepo = friendRepo
+	adminService.InvitationRepo = invitationRepo
+
+	// The friend requests cannot be moved, so carol stays under her old email.
+	friendRepo.Err = repositories.ErrUnavailable
+	report, err := adminService.NormalizeUserEmails(ctx, "admin@example.com")
+	if err != nil || fmt.Sprint(report.Migrated, report.Conflicts, report.Failed) != "[] [] [Carol@Example.com]" {
+		t.Fatalf("Expected carol to fail, got %+v, %v", report, err)
+	}
+	if userRepo.Users["Carol@Example.com"] == nil || userRepo.Users["carol@example.com"] != nil {
+		t.Fatalf("Expected carol to stay under her old email until everything can be moved")
+	}
+
+	// The next run picks carol up again and moves her friends and invitations with her.
+	friendRepo.Err = nil
+	report, err = adminService.NormalizeUserEmails(ctx, "admin@example.com")
+	if err != nil || fmt.Sprint(report.Migrated, report.Conflicts, report.Failed) != "[Carol@Example.com] [] []" {
+		t.Fatalf("Expected carol to be migrated on the second run, got %+v, %v", report, err)
+	}
+	if carol := userRepo.Users["carol@example.com"]; carol == nil || userRepo.Users["Carol@Example.com"] != nil {
+		t.Errorf("Expected carol to be stored under carol@example.com only")
+	}
+	if friend := friendRepo.Friends[repositories.CompositeID("alice@example.com", "carol@example.com")]; friend == nil || friend.FriendEmail != "carol@example.com" {
+		t.Errorf("Expected the friendship with carol to follow her, got %+v", friendRepo.Friends)
+	}
+	if invitation, _ := invitationRepo.GetInvitation(ctx, "event1", "carol@example.com"); invitation == nil {
+		t.Errorf("Expected the invitation to carol to follow her")
+	}
+
+	// A copy left under the normalized email by a move that failed halfway is resumed, not a conflict.
+	userRepo.Users["Dave@example.com"] = &models.User{Email: "Dave@example.com", Username: "dave"}
+	userRepo.Users["dave@example.com"] = &models.User{Email: "dave@example.com", Username: "dave", MovedFrom: "Dave@example.com"}
+	report, err = adminService.NormalizeUserEmails(ctx, "admin@example.com")
+	if err != nil || fmt.Sprint(report.Migrated, report.Conflicts, report.Failed) != "[Dave@example.com] [] []" {
+		t.Fatalf("Expected dave's move to be resumed, got %+v, %v", report, err)
+	}
+	if dave := userRepo.Users["dave@example.com"]; dave == nil || dave.MovedFrom != "" || userRepo.Users["Dave@example.com"] != nil {
+		t.Errorf("Expected dave's move to be finished, got %+v", dave)
+	}
+}
@@ -0,0 +1,220 @@
+/**
+ *  AuditService Tests validate the account audit log: the entries written by AuditService, and the
+ *  actions recorded by UserService and ProfileService.
+ *
+ *  @file       audit_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestAuditService_Record               - Tests that entries carry the client info of the context and the current time.
+ *  - TestAuditService_Record_BestEffort    - Tests that a failed write neither fails nor blocks the login it records.
+ *  - TestAuditService_Record_Concurrent    - Tests recording from several goroutines, after the request context is cancelled.
+ *  - TestAuditService_ListEntries          - Tests that the latest 50 entries are listed newest first.
+ *  - TestUserService_Audit                 - Tests the actions recorded for logins, email verification and password resets.
+ *  - TestProfileService_Audit              - Tests that only a password change is recorded for a profile update.
+ *
+ *  @dependencies
+ *  - mocks.NewMockAuditRepository: Stores the audit log in memory.
+ *  - mocks.NewMockUserRepository: Holds the users whose actions are audited.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newAuditService creates an AuditService over a mock repository with a fixed clock.
+func newAuditService() (*services.AuditService, *mocks.MockAuditRepository) {
+	auditRepo := mocks.NewMockAuditRepository()
+	auditService := services.NewAuditService(auditRepo).(*services.AuditService)
+	auditService.Now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return auditService, auditRepo
+}
+
+func TestAuditService_Record(t *testing.T) {
+	auditService, auditRepo := newAuditService()
+
+	ctx := services.WithClientInfo(context.Background(), "203.0.113.7", "Firefox/124.0")
+	auditService.Record(ctx, "alice@example.com", services.AuditLoginSucceeded)
+	auditService.Record(context.Background(), "alice@example.com", services.AuditLoginFailed)
+
+	entries := auditRepo.Entries["alice@example.com"]
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	want := models.AuditEntry{
+		Email:     "alice@example.com",
+		Action:    services.AuditLoginSucceeded,
+		IP:        "203.0.113.7",
+		UserAgent: "Firefox/124.0",
+		Timestamp: auditService.Now(),
+	}
+	if entries[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, entries[0])
+	}
+	// Without client info the entry is still recorded, with the client unknown.
+	if entries[1].Action != services.AuditLoginFailed || entries[1].IP != "" || entries[1].UserAgent != "" {
+		t.Errorf("Expected a login_failed entry without client info, got %+v", entries[1])
+	}
+}
+
+func TestAuditService_Record_BestEffort(t *testing.T) {
+	auditService, auditRepo := newAuditService()
+	auditRepo.Err = repositories.ErrUnavailable
+
+	userRepo := newUsernameTestRepo(t)
+	userRepo.Users["alice@example.com"].IsVerified = true
+	userService, _ := newLimitedUserService(userRepo)
+	userService.Audit = auditService
+
+	token, err := userService.Login(context.Background(), &models.LoginRequest{Email: "alice@example.com", Password: "Password123!"})
+	if err != nil || token == "" {
+		t.Fatalf("Expected the login to succeed despite the audit log failing, got %v", err)
+	}
+	if _, err := auditService.ListEntries(context.Background(), "alice@example.com"); err == nil {
+		t.Error("Expected listing to report the failure")
+	}
+}
+
+func TestAuditService_Record_Concurrent(t *testing.T) {
+	auditService, auditRepo := newAuditService()
+
+	// The request may have ended before an entry recorded in the background is written.
+	ctx, cancel := context.WithCancel(services.WithClientInfo(context.Background(), "203.0.113.7", "curl/8.5"))
+	cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			auditService.Record(ctx, "alice@example.com", services.AuditLoginFailed)
+		}()
+	}
+	wg.Wait()
+
+	if got := len(auditRepo.Actions("alice@example.com")); got != 20 {
+		t.Errorf("Expected 20 entries, got %d", got)
+	}
+}
+
+func TestAuditService_ListEntries(t *testing.T) {
+	auditService, auditRepo := newAuditService()
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 60; i++ {
+		auditRepo.Entries["alice@example.com"] = append(auditRepo.Entries["alice@example.com"], models.AuditEntry{
+			Email:     "alice@example.com",
+			Action:    fmt.Sprintf("action%d", i),
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	auditRepo.Entries["bob@example.com"] = []models.AuditEntry{{Email: "bob@example.com", Action: "login_succeeded", Timestamp: start}}
+
+	entries, err := auditService.ListEntries(context.Background(), "alice@example.com")
+	if err != nil {
+		t.Fatalf("Failed to list entries: %v", err)
+	}
+	if len(entries) != services.MaxAuditEntries {
+		t.Fatalf("Expected %d entries, got %d", services.MaxAuditEntries, len(entries))
+	}
+	if entries[0].Action != "action59" || entries[len(entries)-1].Action != "action10" {
+		t.Errorf("Expected action59 to action10, newest first, got %s to %s", entries[0].Action, entries[len(entries)-1].Action)
+	}
+	for _, entry := range entries {
+		if entry.Email != "alice@example.com" {
+			t.Errorf("Expected only alice's entries, got one of %s", entry.Email)
+		}
+	}
+}
+
+func TestUserService_Audit(t *testing.T) {
+	auditService, auditRepo := newAuditService()
+	userRepo := newUsernameTestRepo(t)
+	userService, _ := newLimitedUserService(userRepo)
+	userService.Audit = auditService
+	ctx := services.WithClientInfo(context.Background(), "203.0.113.7", "Firefox/124.0")
+	alice := userRepo.Users["alice@example.com"]
+
+	// Verify the email, log in with a wrong and then the right password, and reset the password.
+	withOTP(alice)
+	if _, err := userService.VerifyEmail(ctx, "alice@example.com", "123456"); err != nil {
+		t.Fatalf("Failed to verify email: %v", err)
+	}
+	if _, err := userService.Login(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "wrong"}); err == nil {
+		t.Fatal("Expected the wrong password to be rejected")
+	}
+	if _, err := userService.Login(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "Password123!"}); err != nil {
+		t.Fatalf("Failed to log in: %v", err)
+	}
+	if err := userService.ForgotPassword(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("Failed to request a password reset: %v", err)
+	}
+	withOTP(alice)
+	if err := userService.ResetPassword(ctx, "alice@example.com", "123456", "NewPassword123!"); err != nil {
+		t.Fatalf("Failed to reset password: %v", err)
+	}
+
+	want := fmt.Sprint([]string{
+		services.AuditEmailVerified,
+		services.AuditLoginFailed,
+		services.AuditLoginSucceeded,
+		services.AuditPasswordResetRequested,
+		services.AuditPasswordResetCompleted,
+	})
+	if got := fmt.Sprint(auditRepo.Actions("alice@example.com")); got != want {
+		t.Errorf("Expected actions %s, got %s", want, got)
+	}
+	for _, entry := range auditRepo.Entries["alice@example.com"] {
+		if entry.IP != "203.0.113.7" || entry.UserAgent != "Firefox/124.0" {
+			t.Errorf("Expected the client info on %s, got %q and %q", entry.Action, entry.IP, entry.UserAgent)
+		}
+	}
+
+	// Attempts on unknown emails have no account to be recorded for.
+	_, _ = userService.Login(ctx, &models.LoginRequest{Email: "nobody@example.com", Password: "Password123!"})
+	_ = userService.ForgotPassword(ctx, "nobody@example.com")
+	if len(auditRepo.Entries) != 1 {
+		t.Errorf("Expected only alice's audit log, got %d logs", len(auditRepo.Entries))
+	}
+}
+
+func TestProfileService_Audit(t *testing.T) {
+	auditService, auditRepo := newAuditService()
+	userRepo := newUsernameTestRepo(t)
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT).(*services.ProfileService)
+	profileService.Audit = auditService
+	ctx := context.Background()
+
+	err := profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{"City": "Oslo", "CurrentPassword": "Password123!"})
+	if err != nil {
+		t.Fatalf("Failed to update profile: %v", err)
+	}
+	err = profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{"NewPassword": "weak", "CurrentPassword": "Password123!"})
+	if err == nil {
+		t.Fatal("Expected the weak password to be rejected")
+	}
+	err = profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{"NewPassword": "NewPassword123!", "CurrentPassword": "Password123!"})
+	if err != nil {
+		t.Fatalf("Failed to change password: %v", err)
+	}
+
+	if got := fmt.Sprint(auditRepo.Actions("alice@example.com")); got != fmt.Sprint([]string{services.AuditPasswordChanged}) {
+		t.Errorf("Expected only the password change to be recorded, got %s", got)
+	}
+}
@@ -0,0 +1,86 @@
+/**
+ *  CityService Tests validate the city list cache and context handling against a counting
+ *  fake cities API, so no request reaches countriesnow.space.
+ *
+ *  @file       city_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestCityService_GetCitiesByCountry_Cache   - Tests that a country is fetched once within the TTL, case-insensitively, and again after expiry.
+ *  - TestCityService_GetCitiesByCountry_Timeout - Tests that a slow API is cut off by ctx and reported as ErrCityLookupTimeout.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+)
+
+func TestCityService_GetCitiesByCountry_Cache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": false, "data": []string{"Oslo", "Bergen"}})
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	cityService := &services.CityService{
+		HTTPClient:   server.Client(),
+		CitiesAPIURL: server.URL,
+		CacheTTL:     time.Hour,
+		Now:          func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	for _, country := range []string{"Norway", "norway", " NORWAY "} {
+		cities, err := cityService.GetCitiesByCountry(ctx, country)
+		if err != nil || len(cities) != 2 {
+			t.Fatalf("Expected 2 cities for %q, got %v (err: %v)", country, cities, err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call within the TTL, got %d", got)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := cityService.GetCitiesByCountry(ctx, "Norway"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected another upstream call after expiry, got %d calls", got)
+	}
+}
+
+func TestCityService_GetCitiesByCountry_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cityService := &services.CityService{HTTPClient: server.Client(), CitiesAPIURL: server.URL}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := cityService.GetCitiesByCountry(ctx, "Norway")
+	if !errors.Is(err, services.ErrCityLookupTimeout) {
+		t.Errorf("Expected ErrCityLookupTimeout, got %v", err)
+	}
+}
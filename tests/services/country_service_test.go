@@ -0,0 +1,184 @@
+/**
+ *  CountryService Tests validate country search against the local CountryLanguageMap and the
+ *  external API, and the case-insensitive country name lookup used for local news.
+ *
+ *  @file       country_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestCountryService_SearchCountries_Local    - Tests prefix, substring, code and short searches without network access.
+ *  - TestCountryService_SearchCountries_External - Tests that the external mode searches the API's country list.
+ *  - TestGetCountryAndLanguageCode               - Tests multi-word and differently cased country names, aliases and codes.
+ *  - TestLookupByCode                            - Tests country names and languages looked up by ISO code.
+ *  - TestGetCountryLanguages                     - Tests that multilingual countries list every language, primary first.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"proh2052-group6/internal/config"
+	"proh2052-group6/internal/services"
+)
+
+func TestCountryService_SearchCountries_Local(t *testing.T) {
+	// Point the external API at an unreachable address to prove local mode never calls it.
+	originalCountriesAPIURL := config.CountriesAPIURL
+	services.SetCountriesAPIURL("http://127.0.0.1:0")
+	defer services.SetCountriesAPIURL(originalCountriesAPIURL)
+
+	countryService := services.NewCountryService(services.CountrySourceLocal)
+
+	tests := []struct {
+		name   string
+		search string
+		want   []services.Country
+	}{
+		{"prefix, sorted by name", "nor", []services.Country{{Name: "North Korea", Code: "KP"}, {Name: "North Macedonia", Code: "MK"}, {Name: "Norway", Code: "NO"}}},
+		{"prefix before substring", "guinea", []services.Country{{Name: "Guinea", Code: "GN"}, {Name: "Guinea-Bissau", Code: "GW"}, {Name: "Equatorial Guinea", Code: "GQ"}, {Name: "Papua New Guinea", Code: "PG"}}},
+		{"case-insensitive", "UNITED STATES", []services.Country{{Name: "United States", Code: "US"}}},
+		{"two-letter code", "NO", []services.Country{{Name: "Norway", Code: "NO"}}},
+		{"lowercase code", "us", []services.Country{{Name: "United States", Code: "US"}}},
+		{"unknown code", "ZZ", []services.Country{}},
+		{"too short", "n", []services.Country{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			countries, err := countryService.SearchCountries(context.Background(), tt.search)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(countries, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, countries)
+			}
+		})
+	}
+}
+
+func TestCountryService_SearchCountries_External(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name":{"common":"Norway"},"cca2":"NO"},{"name":{"common":"Svalbard and Jan Mayen"},"cca2":"SJ"}]`))
+	}))
+	defer testServer.Close()
+
+	originalCountriesAPIURL := config.CountriesAPIURL
+	services.SetCountriesAPIURL(testServer.URL)
+	defer services.SetCountriesAPIURL(originalCountriesAPIURL)
+
+	countryService := services.NewCountryService(services.CountrySourceExternal)
+
+	// Svalbard is not in the local list, so a match proves the API was used.
+	countries, err := countryService.SearchCountries(context.Background(), "svalbard")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []services.Country{{Name: "Svalbard and Jan Mayen", Code: "SJ"}}
+	if !reflect.DeepEqual(countries, want) {
+		t.Errorf("Expected %v, got %v", want, countries)
+	}
+
+	body, _ := json.Marshal(countries)
+	if string(body) != `[{"name":"Svalbard and Jan Mayen","code":"SJ"}]` {
+		t.Errorf("Unexpected JSON: %s", body)
+	}
+}
+
+func TestGetCountryAndLanguageCode(t *testing.T) {
+	tests := []struct {
+		country      string
+		wantCountry  string
+		wantLanguage string
+		wantErr      bool
+	}{
+		{"United States", "us", "en", false},
+		{"united states", "us", "en", false},
+		{"  United   Kingdom ", "gb", "en", false},
+		{"Bosnia and Herzegovina", "ba", "bs", false},
+		{"saint vincent and the grenadines", "vc", "en", false},
+		{"USA", "us", "en", false},
+		{"uk", "gb", "en", false},
+		{"South Korea", "kr", "ko", false},
+		{"Korea, Republic of", "kr", "ko", false},
+		{"NO", "no", "no", false},
+		{"be", "be", "nl", false},
+		{"Atlantis", "", "", true},
+		{"XX", "", "", true},
+	}
+
+	for _, tt := range tests {
+		countryCode, languageCode, err := services.GetCountryAndLanguageCode(tt.country)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tt.country, tt.wantErr, err)
+		}
+		if countryCode != tt.wantCountry || languageCode != tt.wantLanguage {
+			t.Errorf("%q: expected %s/%s, got %s/%s", tt.country, tt.wantCountry, tt.wantLanguage, countryCode, languageCode)
+		}
+	}
+}
+
+func TestLookupByCode(t *testing.T) {
+	tests := []struct {
+		code          string
+		wantName      string
+		wantLanguages []string
+		wantErr       bool
+	}{
+		{"BE", "Belgium", []string{"nl", "fr", "de"}, false},
+		{"ca", "Canada", []string{"en", "fr"}, false},
+		{" ch ", "Switzerland", []string{"de", "fr", "it", "rm"}, false},
+		{"NO", "Norway", []string{"no"}, false},
+		{"GB", "United Kingdom", []string{"en"}, false},
+		{"XX", "", nil, true},
+		{"", "", nil, true},
+		{"Norway", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		name, languages, err := services.LookupByCode(tt.code)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tt.code, tt.wantErr, err)
+		}
+		if name != tt.wantName || !reflect.DeepEqual(languages, tt.wantLanguages) {
+			t.Errorf("%q: expected %s %v, got %s %v", tt.code, tt.wantName, tt.wantLanguages, name, languages)
+		}
+	}
+}
+
+func TestGetCountryLanguages(t *testing.T) {
+	tests := []struct {
+		country       string
+		wantCountry   string
+		wantLanguages []string
+		wantErr       bool
+	}{
+		{"Belgium", "be", []string{"nl", "fr", "de"}, false},
+		{"canada", "ca", []string{"en", "fr"}, false},
+		{"CH", "ch", []string{"de", "fr", "it", "rm"}, false},
+		{"United States of America", "us", []string{"en"}, false},
+		{"Holland", "nl", []string{"nl"}, false},
+		{"Atlantis", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		countryCode, languages, err := services.GetCountryLanguages(tt.country)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tt.country, tt.wantErr, err)
+		}
+		if countryCode != tt.wantCountry || !reflect.DeepEqual(languages, tt.wantLanguages) {
+			t.Errorf("%q: expected %s %v, got %s %v", tt.country, tt.wantCountry, tt.wantLanguages, countryCode, languages)
+		}
+	}
+}
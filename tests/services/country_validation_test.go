@@ -0,0 +1,176 @@
+/**
+ *  Country Validation Tests validate normalizing the country and city given at signup and in profile
+ *  updates, and the suggestions for misspelled countries.
+ *
+ *  @file       country_validation_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestNormalizeCountry                   - Tests names, aliases and ISO codes, and the error for unknown countries.
+ *  - TestSuggestCountries                   - Tests the closest countries suggested for typos, and none for nonsense.
+ *  - TestNormalizeCity                      - Tests the listed spelling of a city, unlisted cities and unavailable lists.
+ *  - TestUserService_Signup_CountryAndCity  - Tests that signup stores the canonical country and flags unlisted cities.
+ *  - TestProfileService_UpdateProfile_Country - Tests that profile updates reject unknown countries and store canonical names.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/validate"
+	"proh2052-group6/tests/mocks"
+)
+
+// norwegianCities is a city service listing a few Norwegian cities and nothing for other countries.
+var norwegianCities = &mocks.MockCityService{
+	GetCitiesByCountryFunc: func(ctx context.Context, country string) ([]string, error) {
+		if country == "Norway" {
+			return []string{"Bergen", "Oslo", "Trondheim"}, nil
+		}
+		return nil, errors.New("country not found")
+	},
+}
+
+func TestNormalizeCountry(t *testing.T) {
+	for input, want := range map[string]string{
+		"Norway":          "Norway",
+		"  norway ":       "Norway",
+		"USA":             "United States",
+		"us":              "United States",
+		"NO":              "Norway",
+		"united  kingdom": "United Kingdom",
+	} {
+		if got, err := services.NormalizeCountry(input); err != nil || got != want {
+			t.Errorf("NormalizeCountry(%q) = %q, %v; expected %q", input, got, err, want)
+		}
+	}
+
+	_, err := services.NormalizeCountry("Norwayy")
+	fieldErrors, ok := validate.AsErrors(err)
+	if !ok || fieldErrors["country"] != "is not a known country; did you mean Norway?" {
+		t.Errorf("Expected a country error suggesting Norway, got %v", err)
+	}
+	_, err = services.NormalizeCountry(" ")
+	if fieldErrors, ok := validate.AsErrors(err); !ok || fieldErrors["country"] != "required" {
+		t.Errorf("Expected the country to be required, got %v", err)
+	}
+}
+
+func TestSuggestCountries(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"Norwayy", []string{"Norway"}},
+		{"swedn", []string{"Sweden"}},
+		{"Untied Kingdom", []string{"United Kingdom"}},
+		{"Marocco", []string{"Morocco"}},
+		{"Iram", []string{"Iran", "Iraq"}},
+		{"asdf", nil},
+	}
+	for _, tt := range tests {
+		got := services.SuggestCountries(tt.input, services.MaxCountrySuggestions)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got[:min(len(got), len(tt.want))], tt.want) {
+			t.Errorf("SuggestCountries(%q) = %v; expected it to start with %v", tt.input, got, tt.want)
+		}
+		if len(got) > services.MaxCountrySuggestions {
+			t.Errorf("SuggestCountries(%q) returned %d suggestions", tt.input, len(got))
+		}
+	}
+}
+
+func TestNormalizeCity(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name       string
+		cities     services.CityServiceInterface
+		country    string
+		city       string
+		wantCity   string
+		wantListed bool
+	}{
+		{"Listed", norwegianCities, "Norway", " oslo ", "Oslo", true},
+		{"Unlisted", norwegianCities, "Norway", "Asdf", "Asdf", false},
+		{"LookupFails", norwegianCities, "Sweden", "Malmö", "Malmö", true},
+		{"NoCityService", nil, "Norway", "Asdf", "Asdf", true},
+	}
+	for _, tt := range tests {
+		city, listed := services.NormalizeCity(ctx, tt.cities, tt.country, tt.city)
+		if city != tt.wantCity || listed != tt.wantListed {
+			t.Errorf("%s: got %q/%v, expected %q/%v", tt.name, city, listed, tt.wantCity, tt.wantListed)
+		}
+	}
+}
+
+func TestUserService_Signup_CountryAndCity(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newUsernameTestRepo(t)
+	userService, _ := newLimitedUserService(userRepo)
+	userService.Cities = norwegianCities
+
+	carol := &models.User{Email: "carol@example.com", Username: "Carol", Password: "Password123!", Country: "no", City: "trondheim"}
+	if err := userService.Signup(ctx, carol); err != nil {
+		t.Fatalf("Failed to sign up: %v", err)
+	}
+	stored := userRepo.Users["carol@example.com"]
+	if stored.Country != "Norway" || stored.City != "Trondheim" || carol.CityUnlisted {
+		t.Errorf("Expected Norway/Trondheim without a warning, got %q/%q/%v", stored.Country, stored.City, carol.CityUnlisted)
+	}
+
+	dave := &models.User{Email: "dave@example.com", Username: "Dave", Password: "Password123!", Country: "Norway", City: "Asdf"}
+	if err := userService.Signup(ctx, dave); err != nil {
+		t.Fatalf("Expected an unlisted city to be accepted, got %v", err)
+	}
+	if !dave.CityUnlisted || userRepo.Users["dave@example.com"].City != "Asdf" {
+		t.Errorf("Expected the unlisted city to be kept and flagged, got %+v", dave)
+	}
+
+	erin := &models.User{Email: "erin@example.com", Username: "Erin", Password: "Password123!", Country: "Norwayy", City: "Oslo"}
+	if _, ok := validate.AsErrors(userService.Signup(ctx, erin)); !ok {
+		t.Error("Expected a field error for an unknown country")
+	}
+	if _, created := userRepo.Users["erin@example.com"]; created {
+		t.Error("Expected no user to be created for an unknown country")
+	}
+}
+
+func TestProfileService_UpdateProfile_Country(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+	ctx := context.Background()
+	update := func(fields map[string]interface{}) error {
+		fields["CurrentPassword"] = "Password123!"
+		return profileService.UpdateProfile(ctx, "alice@example.com", fields)
+	}
+
+	if err := update(map[string]interface{}{"Country": "usa", "City": " Boston "}); err != nil {
+		t.Fatalf("Failed to update the country: %v", err)
+	}
+	if alice := userRepo.Users["alice@example.com"]; alice.Country != "United States" || alice.City != "Boston" {
+		t.Errorf("Expected United States/Boston, got %q/%q", alice.Country, alice.City)
+	}
+
+	for _, fields := range []map[string]interface{}{{"Country": "Norwayy"}, {"Country": ""}, {"City": " "}} {
+		if _, ok := validate.AsErrors(update(fields)); !ok {
+			t.Errorf("Expected a field error for %v", fields)
+		}
+	}
+	if alice := userRepo.Users["alice@example.com"]; alice.Country != "United States" || alice.City != "Boston" {
+		t.Errorf("Expected rejected updates to leave the location, got %q/%q", alice.Country, alice.City)
+	}
+}
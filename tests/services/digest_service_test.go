@@ -0,0 +1,298 @@
+/**
+ *  DigestService Tests validate the weekly digest scheduler: the Monday 08:00 send time in each
+ *  user's time zone, sending each week's digest once, the contents of the digest, and that users
+ *  who did not enable the digest never receive one. They use mock repositories, a mock EmailService
+ *  and a fake clock.
+ *
+ *  @file       digest_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestDigestSendTime                        - Tests the local Monday 08:00, across time zones and daylight saving time.
+ *  - TestDigestService_SendDueDigests_Schedule - Tests that digests are sent once per week at each user's local time.
+ *  - TestDigestService_SendDueDigests_CatchUp  - Tests that a digest missed by more than CatchUp is skipped.
+ *  - TestDigestService_SendDueDigests_Bulk     - Tests that due digests are sent in one batch and failed ones are retried.
+ *  - TestDigestService_SendDueDigests_Content  - Tests the events and journal count in the digest.
+ *  - TestDigestService_SendAllDigests          - Tests that manual runs skip disabled users and do not record the week.
+ *  - TestDigestService_Run                     - Tests that the scheduler sends due digests on each tick.
+ *  - TestProfileService_UpdateProfile_DigestEnabled - Tests that the digest can be turned on through the profile.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+	"proh2052-group6/tests/mocks"
+)
+
+// digestFixture bundles a DigestService with the mocks backing it and its fake clock.
+type digestFixture struct {
+	service     *services.DigestService
+	userRepo    *mocks.MockUserRepository
+	eventRepo   *mocks.MockEventRepository
+	journalRepo *mocks.MockJournalRepository
+	emails      *mocks.MockEmailService
+	now         *time.Time
+}
+
+// newDigestFixture creates alice (Norway) and carol (United States), who enabled the digest,
+// bob (Norway), who did not, and dave (Norway), who enabled it but never verified his email.
+func newDigestFixture() *digestFixture {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "alice", Country: "Norway", IsVerified: true, DigestEnabled: true},
+		"bob@example.com":   {Email: "bob@example.com", Username: "bob", Country: "Norway", IsVerified: true},
+		"carol@example.com": {Email: "carol@example.com", Username: "carol", Country: "United States", IsVerified: true, DigestEnabled: true},
+		"dave@example.com":  {Email: "dave@example.com", Username: "dave", Country: "Norway", DigestEnabled: true},
+	})
+	eventRepo := mocks.NewMockEventRepository()
+	journalRepo := mocks.NewMockJournalRepository()
+	emails := &mocks.MockEmailService{}
+
+	now := time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC)
+	service := services.NewDigestService(userRepo, eventRepo, journalRepo, emails).(*services.DigestService)
+	service.Now = func() time.Time { return now }
+	return &digestFixture{service, userRepo, eventRepo, journalRepo, emails, &now}
+}
+
+// recipients returns the sorted recipients of the emails sent so far, and forgets the emails.
+func (f *digestFixture) recipients() string {
+	var to []string
+	for _, email := range f.emails.SentEmails {
+		to = append(to, email.To)
+	}
+	f.emails.SentEmails = nil
+	sort.Strings(to)
+	return strings.Join(to, ",")
+}
+
+// sendDueAt moves the clock to now and sends the due digests.
+func (f *digestFixture) sendDueAt(t *testing.T, now time.Time) string {
+	t.Helper()
+	*f.now = now
+	if _, err := f.service.SendDueDigests(context.Background()); err != nil {
+		t.Fatalf("Failed to send digests at %v: %v", now, err)
+	}
+	return f.recipients()
+}
+
+func TestDigestSendTime(t *testing.T) {
+	oslo := services.LocationForCountry("norway")
+	tokyo := services.LocationForCountry("Japan")
+	tests := []struct {
+		name     string
+		now      time.Time
+		location *time.Location
+		want     time.Time
+	}{
+		{"Midweek", time.Date(2024, 12, 4, 12, 0, 0, 0, time.UTC), oslo, time.Date(2024, 12, 2, 7, 0, 0, 0, time.UTC)},
+		{"Early Monday", time.Date(2024, 12, 2, 6, 30, 0, 0, time.UTC), oslo, time.Date(2024, 12, 2, 7, 0, 0, 0, time.UTC)},
+		{"Sunday night", time.Date(2024, 12, 1, 22, 59, 0, 0, time.UTC), oslo, time.Date(2024, 11, 25, 7, 0, 0, 0, time.UTC)},
+		{"Monday in Tokyo while Sunday in UTC", time.Date(2024, 12, 1, 23, 30, 0, 0, time.UTC), tokyo, time.Date(2024, 12, 1, 23, 0, 0, 0, time.UTC)},
+		{"Summer time", time.Date(2024, 7, 3, 12, 0, 0, 0, time.UTC), oslo, time.Date(2024, 7, 1, 6, 0, 0, 0, time.UTC)},
+		{"Unknown country", time.Date(2024, 12, 4, 12, 0, 0, 0, time.UTC), services.LocationForCountry("Atlantis"), time.Date(2024, 12, 2, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := services.DigestSendTime(tt.now, tt.location); !got.Equal(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got.UTC())
+		}
+	}
+}
+
+func TestDigestService_SendDueDigests_Schedule(t *testing.T) {
+	f := newDigestFixture()
+
+	// Monday 08:00 in Oslo is 07:00 UTC; in New York it is 13:00 UTC.
+	steps := []struct {
+		now  time.Time
+		want string
+	}{
+		{time.Date(2024, 12, 2, 6, 59, 0, 0, time.UTC), ""},
+		{time.Date(2024, 12, 2, 7, 0, 0, 0, time.UTC), "alice@example.com"},
+		{time.Date(2024, 12, 2, 7, 15, 0, 0, time.UTC), ""},
+		{time.Date(2024, 12, 2, 13, 0, 0, 0, time.UTC), "carol@example.com"},
+		{time.Date(2024, 12, 2, 18, 0, 0, 0, time.UTC), ""},
+		{time.Date(2024, 12, 9, 7, 30, 0, 0, time.UTC), "alice@example.com"},
+		{time.Date(2024, 12, 9, 13, 30, 0, 0, time.UTC), "carol@example.com"},
+	}
+	for _, step := range steps {
+		if got := f.sendDueAt(t, step.now); got != step.want {
+			t.Errorf("At %v: expected digests to %q, got %q", step.now, step.want, got)
+		}
+	}
+
+	if sentFor := f.userRepo.Users["alice@example.com"].DigestSentFor; sentFor != "2024-12-09" {
+		t.Errorf("Expected alice's last digest to be recorded for 2024-12-09, got %q", sentFor)
+	}
+	for _, email := range []string{"bob@example.com", "dave@example.com"} {
+		if sentFor := f.userRepo.Users[email].DigestSentFor; sentFor != "" {
+			t.Errorf("Expected no digest for %s, got one for %s", email, sentFor)
+		}
+	}
+}
+
+func TestDigestService_SendDueDigests_CatchUp(t *testing.T) {
+	f := newDigestFixture()
+
+	// Within CatchUp of Monday 08:00 a missed digest is still sent, later it waits for next week.
+	if got := f.sendDueAt(t, time.Date(2024, 12, 2, 18, 59, 0, 0, time.UTC)); got != "alice@example.com,carol@example.com" {
+		t.Errorf("Expected the missed digests to be caught up, got %q", got)
+	}
+	f.userRepo.Users["eve@example.com"] = &models.User{Email: "eve@example.com", Username: "eve", Country: "Norway", IsVerified: true, DigestEnabled: true}
+	if got := f.sendDueAt(t, time.Date(2024, 12, 4, 9, 0, 0, 0, time.UTC)); got != "" {
+		t.Errorf("Expected no digest on Wednesday, got %q", got)
+	}
+}
+
+func TestDigestService_SendDueDigests_Bulk(t *testing.T) {
+	f := newDigestFixture()
+	f.emails.FailFor = map[string]error{"carol@example.com": errors.New("550 mailbox unavailable")}
+
+	*f.now = time.Date(2024, 12, 2, 18, 0, 0, 0, time.UTC)
+	sent, err := f.service.SendDueDigests(context.Background())
+	if err != nil || sent != 1 {
+		t.Fatalf("Expected 1 digest to be sent, got %d (err: %v)", sent, err)
+	}
+	if len(f.emails.BulkSends) != 1 || len(f.emails.BulkSends[0]) != 1 || f.emails.BulkSends[0][0].To != "alice@example.com" {
+		t.Errorf("Expected alice's digest in a single batch, got %+v", f.emails.BulkSends)
+	}
+	if sentFor := f.userRepo.Users["carol@example.com"].DigestSentFor; sentFor != "" {
+		t.Errorf("Expected carol's failed digest not to be recorded, got %q", sentFor)
+	}
+
+	// The failed digest is sent on the next run, within CatchUp.
+	f.emails.FailFor = nil
+	f.recipients()
+	if got := f.sendDueAt(t, time.Date(2024, 12, 2, 18, 15, 0, 0, time.UTC)); got != "carol@example.com" {
+		t.Errorf("Expected carol's digest to be retried, got %q", got)
+	}
+}
+
+func TestDigestService_SendDueDigests_Content(t *testing.T) {
+	f := newDigestFixture()
+	ctx := context.Background()
+
+	for _, event := range []*models.Event{
+		{Email: "alice@example.com", Title: "Brunch", Date: "2024-12-08", StartTime: "11:00"},
+		{Email: "alice@example.com", Title: "Standup", Date: "2024-12-02", StartTime: "10:00"},
+		{Email: "alice@example.com", Title: "Yesterday", Date: "2024-12-01", StartTime: "10:00"},
+		{Email: "alice@example.com", Title: "Next week", Date: "2024-12-09", StartTime: "10:00"},
+		{Email: "bob@example.com", Title: "Not alice's", Date: "2024-12-03", StartTime: "10:00"},
+	} {
+		f.eventRepo.CreateEvent(ctx, event)
+	}
+	for _, date := range []string{"2024-11-24", "2024-11-26", "2024-11-30", "2024-12-01", "2024-12-02"} {
+		f.journalRepo.CreateJournal(ctx, &models.Journal{Email: "alice@example.com", Date: date, Content: "Entry"})
+	}
+
+	*f.now = time.Date(2024, 12, 2, 7, 30, 0, 0, time.UTC)
+	if _, err := f.service.SendDueDigests(ctx); err != nil {
+		t.Fatalf("Failed to send digests: %v", err)
+	}
+	if len(f.emails.SentEmails) != 1 {
+		t.Fatalf("Expected 1 digest, got %d", len(f.emails.SentEmails))
+	}
+
+	email := f.emails.SentEmails[0]
+	for _, part := range []string{email.Body, email.HTML} {
+		standup, brunch := strings.Index(part, "Standup"), strings.Index(part, "Brunch")
+		if standup < 0 || brunch < standup {
+			t.Errorf("Expected Standup and then Brunch in the digest, got %s", part)
+		}
+		for _, excluded := range []string{"Yesterday", "Next week", "Not alice"} {
+			if strings.Contains(part, excluded) {
+				t.Errorf("Expected %q not to be in the digest", excluded)
+			}
+		}
+		// Entries from Nov 25 to Dec 1 count; Nov 24 and today do not. Alice lives in Norway, so her
+		// digest is in Norwegian.
+		if !strings.Contains(part, "Du skrev 3 av de siste 7 dagene") {
+			t.Errorf("Expected a journal count of 3 in the digest, got %s", part)
+		}
+	}
+}
+
+func TestDigestService_SendAllDigests(t *testing.T) {
+	f := newDigestFixture()
+	ctx := context.Background()
+
+	*f.now = time.Date(2024, 12, 4, 15, 0, 0, 0, time.UTC)
+	sent, err := f.service.SendAllDigests(ctx)
+	if err != nil || sent != 2 {
+		t.Fatalf("Expected 2 digests, got %d (err: %v)", sent, err)
+	}
+	if got := f.recipients(); got != "alice@example.com,carol@example.com" {
+		t.Errorf("Expected digests to alice and carol only, got %q", got)
+	}
+
+	// A manual run does not stop the scheduled digest.
+	if got := f.sendDueAt(t, time.Date(2024, 12, 9, 7, 0, 0, 0, time.UTC)); got != "alice@example.com" {
+		t.Errorf("Expected the scheduled digest after a manual run, got %q", got)
+	}
+}
+
+func TestDigestService_Run(t *testing.T) {
+	f := newDigestFixture()
+	*f.now = time.Date(2024, 12, 2, 7, 30, 0, 0, time.UTC)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		f.service.Run(ctx, ticks)
+		close(done)
+	}()
+
+	ticks <- *f.now
+	ticks <- *f.now // The second tick is only received once the first one has been handled.
+	cancel()
+	<-done
+
+	if got := f.recipients(); got != "alice@example.com" {
+		t.Errorf("Expected one digest to alice, got %q", got)
+	}
+}
+
+func TestProfileService_UpdateProfile_DigestEnabled(t *testing.T) {
+	hashed, _ := utils.HashPassword("Password123!")
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"bob@example.com": {Email: "bob@example.com", Username: "bob", Password: hashed},
+	})
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+	ctx := context.Background()
+
+	err := profileService.UpdateProfile(ctx, "bob@example.com", map[string]interface{}{"CurrentPassword": "Password123!", "DigestEnabled": "yes"})
+	if err == nil {
+		t.Errorf("Expected a non-boolean DigestEnabled to be rejected")
+	}
+
+	err = profileService.UpdateProfile(ctx, "bob@example.com", map[string]interface{}{
+		"CurrentPassword": "Password123!", "DigestEnabled": true, "DigestSentFor": "2099-01-05",
+	})
+	if err != nil {
+		t.Fatalf("Failed to enable the digest: %v", err)
+	}
+	user := userRepo.Users["bob@example.com"]
+	if !user.DigestEnabled || user.DigestSentFor != "" {
+		t.Errorf("Expected the digest to be enabled without changing DigestSentFor, got %+v", user)
+	}
+	profile, _ := profileService.GetProfile(ctx, "bob@example.com")
+	if !profile.DigestEnabled {
+		t.Errorf("Expected the profile to report the digest as enabled, got %v", profile.DigestEnabled)
+	}
+}
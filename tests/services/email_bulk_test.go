@@ -0,0 +1,200 @@
+/**
+ *  Bulk Email Tests validate SendBulk against an in-process fake SMTP server: all messages are sent in
+ *  one session, a rejected recipient fails alone, and a failed connection fails every message. They
+ *  also check the fallback for transports that cannot send batches.
+ *
+ *  @file       email_bulk_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestSMTPEmailService_SendBulk_PartialFailure    - Tests one session, a rejected recipient and the delivered messages.
+ *  - TestSMTPEmailService_SendBulk_ConnectionFailure - Tests that every message fails when the server cannot be reached.
+ *  - TestSMTPEmailService_SendBulk_SingleTransport   - Tests one Send per message on a transport without batches.
+ *
+ *  @dependencies
+ *  - fakeSMTPServer: Minimal SMTP server recording sessions and delivered messages.
+ *  - mocks.MockEmailTransport: Fake transport without batch support.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/tests/mocks"
+)
+
+// fakeSMTPServer is a minimal SMTP server without extensions that rejects the recipients in reject.
+type fakeSMTPServer struct {
+	listener net.Listener
+	reject   map[string]bool
+
+	mutex     sync.Mutex
+	sessions  int
+	delivered []string // Recipients of the accepted messages, in order.
+}
+
+// startFakeSMTPServer starts a fakeSMTPServer on a local port, stopped when the test ends.
+func startFakeSMTPServer(t *testing.T, reject ...string) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener, reject: make(map[string]bool)}
+	for _, address := range reject {
+		server.reject[address] = true
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+// transport returns an SMTPTransport sending to the server.
+func (s *fakeSMTPServer) transport() *services.SMTPTransport {
+	return &services.SMTPTransport{
+		Host: "127.0.0.1",
+		Port: s.listener.Addr().(*net.TCPAddr).Port,
+		From: "noreply@example.com",
+	}
+}
+
+// serve answers the commands of one SMTP session.
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	s.mutex.Lock()
+	s.sessions++
+	s.mutex.Unlock()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake ESMTP")
+	var recipients []string
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 fake")
+		case "MAIL", "RSET":
+			recipients = nil
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			address := strings.Trim(strings.TrimPrefix(strings.ToUpper(arg), "TO:"), "<>")
+			if s.reject[strings.ToLower(address)] {
+				tp.PrintfLine("550 5.1.1 Mailbox unavailable")
+				continue
+			}
+			recipients = append(recipients, strings.ToLower(address))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			if len(recipients) == 0 {
+				tp.PrintfLine("503 5.5.1 No valid recipients")
+				continue
+			}
+			tp.PrintfLine("354 Go ahead")
+			if _, err := tp.ReadDotBytes(); err != nil {
+				return
+			}
+			s.mutex.Lock()
+			s.delivered = append(s.delivered, recipients...)
+			s.mutex.Unlock()
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 5.5.2 Command not implemented")
+		}
+	}
+}
+
+// stats returns the number of sessions and the recipients of the delivered messages.
+func (s *fakeSMTPServer) stats() (int, []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.sessions, append([]string(nil), s.delivered...)
+}
+
+// bulkMessages returns a message to each recipient.
+func bulkMessages(recipients ...string) []services.EmailMessage {
+	var messages []services.EmailMessage
+	for _, to := range recipients {
+		messages = append(messages, services.EmailMessage{To: to, Subject: "Weekly digest", Text: "Hello", HTML: "<p>Hello</p>"})
+	}
+	return messages
+}
+
+func TestSMTPEmailService_SendBulk_PartialFailure(t *testing.T) {
+	server := startFakeSMTPServer(t, "gone@example.com")
+	emailService := services.NewSMTPEmailService(server.transport(), nil)
+
+	result, err := emailService.SendBulk(context.Background(), bulkMessages("alice@example.com", "gone@example.com", "carol@example.com"))
+	if err == nil {
+		t.Errorf("Expected an error for the rejected recipient")
+	}
+	if result.Sent() != 2 {
+		t.Errorf("Expected 2 delivered messages, got %d", result.Sent())
+	}
+	failed := result.Failed()
+	if len(failed) != 1 || failed[0].To != "gone@example.com" || !strings.Contains(failed[0].Err.Error(), "Mailbox unavailable") {
+		t.Errorf("Expected only gone@example.com to fail, got %+v", failed)
+	}
+
+	sessions, delivered := server.stats()
+	if sessions != 1 {
+		t.Errorf("Expected the batch to use 1 session, got %d", sessions)
+	}
+	if want := []string{"alice@example.com", "carol@example.com"}; !reflect.DeepEqual(delivered, want) {
+		t.Errorf("Expected deliveries to %v, got %v", want, delivered)
+	}
+}
+
+func TestSMTPEmailService_SendBulk_ConnectionFailure(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	transport := server.transport()
+	server.listener.Close()
+	emailService := services.NewSMTPEmailService(transport, nil)
+
+	result, err := emailService.SendBulk(context.Background(), bulkMessages("alice@example.com", "carol@example.com"))
+	if err == nil || result.Sent() != 0 || len(result.Failed()) != 2 {
+		t.Errorf("Expected both messages to fail, got %+v (err: %v)", result.Recipients, err)
+	}
+}
+
+func TestSMTPEmailService_SendBulk_SingleTransport(t *testing.T) {
+	transport := &mocks.MockEmailTransport{FailFirst: 1}
+	emailService := services.NewSMTPEmailService(transport, nil)
+
+	result, err := emailService.SendBulk(context.Background(), bulkMessages("alice@example.com", "carol@example.com"))
+	if err == nil || len(result.Failed()) != 1 || result.Failed()[0].To != "alice@example.com" {
+		t.Errorf("Expected only the first message to fail, got %+v (err: %v)", result.Recipients, err)
+	}
+	if transport.Attempts() != 2 || !reflect.DeepEqual(transport.Delivered(), []string{"carol@example.com"}) {
+		t.Errorf("Expected one attempt per message, got %d delivering %v", transport.Attempts(), transport.Delivered())
+	}
+}
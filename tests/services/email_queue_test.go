@@ -0,0 +1,181 @@
+/**
+ *  EmailQueue Tests validate background email delivery: retries with backoff, giving up after
+ *  MaxAttempts, draining on shutdown, and the asynchronous path of the SMTP email service.
+ *  They use a fake transport, so no SMTP server is needed.
+ *
+ *  @file       email_queue_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestEmailQueue_RetriesTransientFailures - Tests that an email failing twice is delivered on the third attempt.
+ *  - TestEmailQueue_GivesUpAfterMaxAttempts  - Tests that an email is attempted MaxAttempts times and then dropped.
+ *  - TestEmailQueue_ShutdownDrains           - Tests that queued emails are delivered before Shutdown returns.
+ *  - TestEmailQueue_ShutdownTimeout          - Tests that Shutdown stops waiting for retries when its context ends.
+ *  - TestEmailQueue_EnqueueWaitsForRoom      - Tests that a full queue makes Enqueue wait until its context ends.
+ *  - TestSMTPEmailService_SendEmailAsync     - Tests that async emails are queued, and sent directly without a queue.
+ *  - TestUserService_Signup_QueuesOTPEmail   - Tests that signup hands the OTP email to the queue.
+ *
+ *  @dependencies
+ *  - mocks.MockEmailTransport: Fake transport recording delivery attempts.
+ *  - mocks.MockEmailService, mocks.NewMockUserRepository: Used for the signup test.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newTestEmailQueue creates a started EmailQueue over transport with a short backoff.
+func newTestEmailQueue(transport services.EmailTransport, size int) *services.EmailQueue {
+	queue := services.NewEmailQueue(transport, size)
+	queue.Backoff = time.Millisecond
+	queue.Start()
+	return queue
+}
+
+// shutdownQueue drains the queue, failing the test if that takes longer than a second.
+func shutdownQueue(t *testing.T, queue *services.EmailQueue) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := queue.Shutdown(ctx); err != nil {
+		t.Fatalf("Failed to drain the queue: %v", err)
+	}
+}
+
+func TestEmailQueue_RetriesTransientFailures(t *testing.T) {
+	transport := &mocks.MockEmailTransport{FailFirst: 2}
+	queue := newTestEmailQueue(transport, 10)
+
+	if err := queue.Enqueue(context.Background(), "user@example.com", []byte("hello")); err != nil {
+		t.Fatalf("Failed to queue email: %v", err)
+	}
+	shutdownQueue(t, queue)
+
+	if transport.Attempts() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", transport.Attempts())
+	}
+	if delivered := transport.Delivered(); len(delivered) != 1 || delivered[0] != "user@example.com" {
+		t.Errorf("Expected delivery to user@example.com, got %v", delivered)
+	}
+}
+
+func TestEmailQueue_GivesUpAfterMaxAttempts(t *testing.T) {
+	transport := &mocks.MockEmailTransport{FailFirst: 10}
+	queue := newTestEmailQueue(transport, 10)
+
+	queue.Enqueue(context.Background(), "user@example.com", []byte("hello"))
+	shutdownQueue(t, queue)
+
+	if transport.Attempts() != services.DefaultEmailMaxAttempts {
+		t.Errorf("Expected %d attempts, got %d", services.DefaultEmailMaxAttempts, transport.Attempts())
+	}
+	if delivered := transport.Delivered(); len(delivered) != 0 {
+		t.Errorf("Expected no delivery, got %v", delivered)
+	}
+}
+
+func TestEmailQueue_ShutdownDrains(t *testing.T) {
+	transport := &mocks.MockEmailTransport{Delay: 5 * time.Millisecond}
+	queue := newTestEmailQueue(transport, 10)
+
+	for i := 0; i < 5; i++ {
+		if err := queue.Enqueue(context.Background(), "user@example.com", []byte("hello")); err != nil {
+			t.Fatalf("Failed to queue email %d: %v", i, err)
+		}
+	}
+	shutdownQueue(t, queue)
+
+	if delivered := transport.Delivered(); len(delivered) != 5 {
+		t.Errorf("Expected all 5 queued emails to be delivered, got %d", len(delivered))
+	}
+	if err := queue.Enqueue(context.Background(), "user@example.com", []byte("late")); !errors.Is(err, services.ErrEmailQueueClosed) {
+		t.Errorf("Expected ErrEmailQueueClosed after shutdown, got %v", err)
+	}
+}
+
+func TestEmailQueue_ShutdownTimeout(t *testing.T) {
+	transport := &mocks.MockEmailTransport{FailFirst: 10}
+	queue := services.NewEmailQueue(transport, 10)
+	queue.Backoff = time.Hour
+	queue.Start()
+
+	queue.Enqueue(context.Background(), "user@example.com", []byte("hello"))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := queue.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the shutdown to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Shutdown to return when its context ended, took %v", elapsed)
+	}
+}
+
+func TestEmailQueue_EnqueueWaitsForRoom(t *testing.T) {
+	transport := &mocks.MockEmailTransport{}
+	// The queue is not started, so nothing is taken out of it.
+	queue := services.NewEmailQueue(transport, 1)
+
+	if err := queue.Enqueue(context.Background(), "first@example.com", []byte("hello")); err != nil {
+		t.Fatalf("Failed to queue email: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := queue.Enqueue(ctx, "second@example.com", []byte("hello")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a full queue to wait until the context ended, got %v", err)
+	}
+}
+
+func TestSMTPEmailService_SendEmailAsync(t *testing.T) {
+	transport := &mocks.MockEmailTransport{FailFirst: 1}
+	queue := newTestEmailQueue(transport, 10)
+	emailService := services.NewSMTPEmailService(transport, queue)
+
+	// A transient failure is retried in the background instead of being returned.
+	if err := emailService.SendEmailAsync(context.Background(), "user@example.com", "Subject", "Body"); err != nil {
+		t.Fatalf("Expected the email to be queued, got %v", err)
+	}
+	shutdownQueue(t, queue)
+	if delivered := transport.Delivered(); len(delivered) != 1 {
+		t.Errorf("Expected the queued email to be delivered after a retry, got %v", delivered)
+	}
+
+	// Without a queue the email is sent directly, and failures are returned.
+	direct := &mocks.MockEmailTransport{FailFirst: 1}
+	emailService = services.NewSMTPEmailService(direct, nil)
+	if err := emailService.SendEmailAsync(context.Background(), "user@example.com", "Subject", "Body"); err == nil {
+		t.Error("Expected the direct send to fail")
+	}
+	if direct.Attempts() != 1 {
+		t.Errorf("Expected 1 direct attempt, got %d", direct.Attempts())
+	}
+}
+
+func TestUserService_Signup_QueuesOTPEmail(t *testing.T) {
+	mockEmailService := &mocks.MockEmailService{}
+	userService := services.NewUserService(mocks.NewMockUserRepository(map[string]*models.User{}), mockEmailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+
+	user := &models.User{Email: "new@example.com", Username: "newuser", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(context.Background(), user); err != nil {
+		t.Fatalf("Failed to sign up: %v", err)
+	}
+	if len(mockEmailService.SentEmails) != 1 || !mockEmailService.SentEmails[0].Async {
+		t.Errorf("Expected the verification email to be queued, got %+v", mockEmailService.SentEmails)
+	}
+}
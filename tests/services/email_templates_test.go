@@ -0,0 +1,260 @@
+/**
+ *  EmailTemplateRenderer Tests render every email template with sample data and check that the
+ *  key content appears in both the HTML and the plaintext part, that user-supplied values are
+ *  escaped in the HTML part, and that multipart emails are valid multipart/alternative messages.
+ *
+ *  @file       email_templates_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestEmailTemplateRenderer_Templates             - Tests each template's subject and key content in both parts.
+ *  - TestEmailTemplateRenderer_EscapesHTML           - Tests that a malicious username cannot inject markup into the HTML part.
+ *  - TestEmailTemplateRenderer_Locale                - Tests that emails are rendered in Norwegian, and in English for unsupported locales.
+ *  - TestUserService_Signup_OTPEmailLocale           - Tests that the OTP email is in the requested language, else in the user's country's.
+ *  - TestSMTPEmailService_SendMultipartEmail         - Tests that the sent message has a plaintext and an HTML part.
+ *  - TestSMTPEmailService_SendMultipartEmail_Subject - Tests that a subject with a line break cannot add headers.
+ *  - TestUserService_Signup_RendersOTPEmail          - Tests that the signup email contains the OTP in both parts.
+ *
+ *  @dependencies
+ *  - mocks.MockEmailTransport: Fake transport capturing the sent messages.
+ *  - mocks.MockEmailService, mocks.NewMockUserRepository: Used for the signup test.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/i18n"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+func TestEmailTemplateRenderer_Templates(t *testing.T) {
+	renderer := services.NewEmailTemplateRenderer()
+	event := &models.Event{Title: "Team meeting", Date: "2024-12-01", StartTime: "10:00"}
+
+	tests := []struct {
+		name    string
+		render  func() (services.EmailMessage, error)
+		subject string
+		content []string
+	}{
+		{"VerificationOTP", func() (services.EmailMessage, error) { return renderer.VerificationOTP("en", "123456", 5*time.Minute) },
+			"Your Verification Code", []string{"123456", "5 minutes"}},
+		{"PasswordResetOTP", func() (services.EmailMessage, error) { return renderer.PasswordResetOTP("en", "654321", 5*time.Minute) },
+			"Password Reset Request", []string{"654321", "reset your DailyVerse password"}},
+		{"FriendRequest", func() (services.EmailMessage, error) { return renderer.FriendRequest("en", "alice") },
+			"New friend request on DailyVerse", []string{"alice", "sent you a friend request"}},
+		{"FriendAccepted", func() (services.EmailMessage, error) { return renderer.FriendAccepted("en", "bob") },
+			"Friend request accepted", []string{"bob", "accepted your friend request"}},
+		{"EventReminder", func() (services.EmailMessage, error) { return renderer.EventReminder("en", event) },
+			"Reminder: Team meeting", []string{"Team meeting", "2024-12-01", "10:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := tt.render()
+			if err != nil {
+				t.Fatalf("Failed to render: %v", err)
+			}
+			if msg.Subject != tt.subject {
+				t.Errorf("Expected subject %q, got %q", tt.subject, msg.Subject)
+			}
+			if !strings.Contains(msg.HTML, "<html") || strings.Contains(msg.Text, "<") {
+				t.Errorf("Expected an HTML document and a plaintext body, got %q and %q", msg.HTML, msg.Text)
+			}
+			for _, content := range tt.content {
+				if !strings.Contains(msg.HTML, content) {
+					t.Errorf("Expected the HTML part to contain %q", content)
+				}
+				if !strings.Contains(msg.Text, content) {
+					t.Errorf("Expected the plaintext part to contain %q", content)
+				}
+			}
+		})
+	}
+}
+
+func TestEmailTemplateRenderer_EscapesHTML(t *testing.T) {
+	renderer := services.NewEmailTemplateRenderer()
+	username := `<script>alert("x")</script>{{.OTP}}`
+
+	msg, err := renderer.FriendRequest("en", username)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if strings.Contains(msg.HTML, "<script>") {
+		t.Errorf("Expected the username to be escaped in the HTML part, got %s", msg.HTML)
+	}
+	if !strings.Contains(msg.HTML, "&lt;script&gt;") {
+		t.Errorf("Expected the escaped username in the HTML part, got %s", msg.HTML)
+	}
+	// Template syntax in a value is printed as text, never executed.
+	if !strings.Contains(msg.Text, username) {
+		t.Errorf("Expected the plaintext part to contain the username as-is, got %s", msg.Text)
+	}
+}
+
+func TestEmailTemplateRenderer_Locale(t *testing.T) {
+	renderer := services.NewEmailTemplateRenderer()
+
+	msg, err := renderer.FriendRequest("nb", "alice")
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if msg.Subject != "Ny venneforespørsel på DailyVerse" {
+		t.Errorf("Expected the Norwegian subject, got %q", msg.Subject)
+	}
+	for _, part := range []string{msg.HTML, msg.Text} {
+		if !strings.Contains(part, "alice har sendt deg en venneforespørsel") {
+			t.Errorf("Expected the Norwegian body, got %s", part)
+		}
+	}
+	if !strings.Contains(msg.HTML, `<html lang="nb">`) || !strings.Contains(msg.HTML, "Du mottar denne e-posten") {
+		t.Errorf("Expected the layout in Norwegian, got %s", msg.HTML)
+	}
+
+	msg, err = renderer.VerificationOTP("de", "123456", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if msg.Subject != "Your Verification Code" || !strings.Contains(msg.Text, "expires in 5 minutes") {
+		t.Errorf("Expected an unsupported locale to fall back to English, got %q: %s", msg.Subject, msg.Text)
+	}
+}
+
+// readMultipartEmail parses a message sent through the SMTP email service into its headers and parts,
+// keyed by content type.
+func readMultipartEmail(t *testing.T, data []byte) (*mail.Message, map[string]string) {
+	t.Helper()
+	message, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse the message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected a multipart/alternative message, got %q (err: %v)", message.Header.Get("Content-Type"), err)
+	}
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read a part: %v", err)
+		}
+		// NextPart decodes quoted-printable parts; their lines end in CRLF on the wire.
+		content, _ := io.ReadAll(part)
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[partType] = strings.ReplaceAll(string(content), "\r\n", "\n")
+	}
+	return message, parts
+}
+
+func TestSMTPEmailService_SendMultipartEmail(t *testing.T) {
+	transport := &mocks.MockEmailTransport{}
+	emailService := services.NewSMTPEmailService(transport, nil)
+
+	msg, _ := services.NewEmailTemplateRenderer().VerificationOTP("en", "123456", 5*time.Minute)
+	if err := emailService.SendMultipartEmail("user@example.com", msg); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	messages := transport.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(messages))
+	}
+
+	message, parts := readMultipartEmail(t, messages[0])
+	if message.Header.Get("To") != "user@example.com" || message.Header.Get("Subject") != "Your Verification Code" {
+		t.Errorf("Unexpected headers: %v", message.Header)
+	}
+	if parts["text/plain"] != msg.Text || parts["text/html"] != msg.HTML {
+		t.Errorf("Expected the plaintext and HTML bodies, got %v", parts)
+	}
+}
+
+func TestSMTPEmailService_SendMultipartEmail_Subject(t *testing.T) {
+	transport := &mocks.MockEmailTransport{}
+	emailService := services.NewSMTPEmailService(transport, nil)
+
+	msg, _ := services.NewEmailTemplateRenderer().EventReminder("en", &models.Event{Title: "Party\r\nBcc: victim@example.com"})
+	if err := emailService.SendMultipartEmail("user@example.com", msg); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	message, _ := readMultipartEmail(t, transport.Messages()[0])
+	if message.Header.Get("Bcc") != "" {
+		t.Errorf("Expected the subject not to add a Bcc header, got %q", message.Header.Get("Bcc"))
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Expected the subject %q, got %q (err: %v)", msg.Subject, subject, err)
+	}
+}
+
+func TestUserService_Signup_RendersOTPEmail(t *testing.T) {
+	mockEmailService := &mocks.MockEmailService{}
+	userService := services.NewUserService(mocks.NewMockUserRepository(map[string]*models.User{}), mockEmailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+
+	user := &models.User{Email: "new@example.com", Username: "newuser", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(context.Background(), user); err != nil {
+		t.Fatalf("Failed to sign up: %v", err)
+	}
+	if len(mockEmailService.SentEmails) != 1 {
+		t.Fatalf("Expected 1 email, got %d", len(mockEmailService.SentEmails))
+	}
+	email := mockEmailService.SentEmails[0]
+	otp := mockEmailService.LastOTP()
+	if otp == "" || !strings.Contains(email.HTML, otp) {
+		t.Errorf("Expected the OTP in both parts, got %q and %q", email.Body, email.HTML)
+	}
+}
+
+func TestUserService_Signup_OTPEmailLocale(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		country string
+		subject string
+	}{
+		{"user's country", context.Background(), "Norway", "Din bekreftelseskode"},
+		{"requested language", i18n.WithLocale(context.Background(), "en"), "Norway", "Your Verification Code"},
+		{"country without a catalog", context.Background(), "Germany", "Your Verification Code"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockEmailService := &mocks.MockEmailService{}
+			userService := services.NewUserService(mocks.NewMockUserRepository(map[string]*models.User{}), mockEmailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+
+			user := &models.User{Email: "new@example.com", Username: "newuser", Password: "Password123!", Country: test.country, City: "Oslo"}
+			if err := userService.Signup(test.ctx, user); err != nil {
+				t.Fatalf("Failed to sign up: %v", err)
+			}
+			if len(mockEmailService.SentEmails) != 1 {
+				t.Fatalf("Expected 1 email, got %d", len(mockEmailService.SentEmails))
+			}
+			if subject := mockEmailService.SentEmails[0].Subject; subject != test.subject {
+				t.Errorf("Expected subject %q, got %q", test.subject, subject)
+			}
+		})
+	}
+}
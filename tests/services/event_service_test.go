@@ -0,0 +1,2088 @@
+/**
+ *  EventService Tests validate the business logic of EventService, in particular event invitations.
+ *  They use mock repositories to isolate the service from Firestore.
+ *
+ *  @file       event_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestEventService_InviteToEvent_NonFriend   - Tests that inviting a non-friend is rejected.
+ *  - TestEventService_InviteToEvent_Idempotent  - Tests that inviting the same friend twice is a no-op and notifies once.
+ *  - TestEventService_RespondToInvitation_Decline - Tests declining an invitation hides the event.
+ *  - TestEventService_GetAllEvents_Pagination     - Tests page boundaries when paging through 60 events.
+ *  - TestEventService_GetAllEvents_DateRange      - Tests filtering events by a from/to date range.
+ *  - TestEventService_GetAllEvents_InvalidQuery   - Tests rejection of malformed date ranges.
+ *  - TestEventService_Recurrence_MonthBoundaries  - Tests expansion of series across month ends and leap days.
+ *  - TestEventService_Recurrence_UntilIsInclusive - Tests that the until date is the last possible occurrence.
+ *  - TestEventService_Recurrence_Count            - Tests count-limited series, including removed occurrences.
+ *  - TestEventService_Recurrence_Validation       - Tests rejection of invalid recurrence rules.
+ *  - TestEventService_Recurrence_UpdateOccurrence - Tests changing one occurrence versus the entire series.
+ *  - TestEventService_Recurrence_Pagination       - Tests paging through expanded occurrences.
+ *  - TestEventService_Tags_Validation             - Tests normalization and rejection of invalid tags.
+ *  - TestEventService_GetAllEvents_TagFilter      - Tests filtering stored events and occurrences by tag.
+ *  - TestEventService_GetEventTags                - Tests counting the tags on a user's events.
+ *  - TestEventService_CreateEventIdempotent       - Tests replays, key reuse, expiry and failed creations with an Idempotency-Key.
+ *  - TestEventService_CreateEventIdempotent_Concurrent - Tests that concurrent requests with one key create a single event.
+ *  - TestEventService_CreateEventIdempotent_Recovery   - Tests retries after a failed completion and after a request died holding the key.
+ *  - TestHaversineKm                              - Tests the great-circle distance against known distances.
+ *  - TestEventService_Geocoding                   - Tests that addresses are geocoded, client coordinates kept and failures ignored.
+ *  - TestEventService_GetNearbyEvents             - Tests the radius filter, distance ordering and parameter validation.
+ *  - TestEventService_BulkCreateEvents            - Tests per-event validation, partial write failures and the 100-event cap.
+ *  - TestEventService_BulkDeleteEvents            - Tests per-event authorization, partial failures and deleting a series with its occurrences.
+ *  - TestEventService_ImportBatchID               - Tests that clients cannot set or clear the import batch or external ID of an event.
+ *  - TestEventService_TimeZones_DST               - Tests reading event times in the user's zone on the days the clocks change.
+ *  - TestEventService_TimeZones_Timestamps        - Tests creating events from startAt and endAt instead of date and times.
+ *  - TestEventService_TimeZones_Rendering         - Tests showing events in the viewer's current zone, and legacy events as stored.
+ *  - TestEventService_TimeZones_RecurrenceAcrossDST - Tests that a weekly series keeps its local time when the clocks change.
+ *  - TestEventService_Status_Validation           - Tests the default, allowed and rejected statuses of new and updated events.
+ *  - TestEventService_CancelEvent                 - Tests cancelling, the notification of accepted invitees and that cancelled events are final.
+ *  - TestEventService_GetAllEvents_Cancelled      - Tests that cancelled events and series are only listed with IncludeCancelled.
+ *  - TestEventService_PatchEvent                  - Tests that a partial update keeps the fields it leaves out and rejects invalid patches.
+ *  - TestEventService_UpdateEvent_Ownership       - Tests that events of other users and missing events cannot be replaced or patched.
+ *  - TestEventService_SearchEvents                - Tests matching across fields and occurrences, result order and the one-year window.
+ *  - TestEventService_DateWindow                  - Tests event dates must be within 10 years of today on create and update, unlike listing ranges.
+ *  - TestEventService_CalendarFields_Validation   - Tests accepted and rejected colors and that all-day events have no times.
+ *  - TestEventService_GetEventMonth               - Tests the per-day summary of a month and grids of 4 to 6 weeks.
+ *  - TestEventService_ShareEvent                  - Tests shared links: redaction, replacing and revoking links, expiry and deleted events.
+ *  - TestEventService_GetFriendEvents             - Tests that friends see only public events and everyone else is refused.
+ *  - TestEventService_GetFriendsEventFeed         - Tests the feed of all friends' public events in the next 14 days, in date order.
+ *  - TestEventService_DuplicateEvent              - Tests weekly copies without per-instance fields, ownership and the copy cap.
+ *
+ *  @dependencies
+ *  - mocks.NewMockEventRepository, mocks.NewMockInvitationRepository,
+ *    mocks.NewMockUserRepository, mocks.NewMockFriendRepository,
+ *    mocks.NewMockIdempotencyRepository: Mock repositories for testing.
+ *  - mocks.NewMockGeocodingService: Mock geocoder for event addresses.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/dates"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/validate"
+	"proh2052-group6/tests/mocks"
+)
+
+// eventServiceFixture bundles an EventService with the mock repositories backing it.
+type eventServiceFixture struct {
+	service        services.EventServiceInterface
+	invitationRepo *mocks.MockInvitationRepository
+	hub            services.NotificationHubInterface
+	eventID        string
+}
+
+// newEventServiceFixture creates an owner with one event, a friend, and a non-friend.
+func newEventServiceFixture(t *testing.T) *eventServiceFixture {
+	t.Helper()
+	users := map[string]*models.User{
+		"owner@example.com":    {Email: "owner@example.com", Username: "owner"},
+		"friend@example.com":   {Email: "friend@example.com", Username: "friend"},
+		"stranger@example.com": {Email: "stranger@example.com", Username: "stranger"},
+	}
+	friends := map[string]*models.Friend{
+		"owner@example.com_friend@example.com": {
+			Email:       "owner@example.com",
+			FriendEmail: "friend@example.com",
+			Status:      "accepted",
+		},
+	}
+
+	invitationRepo := mocks.NewMockInvitationRepository()
+	hub := services.NewNotificationHub()
+	service := services.NewEventService(mocks.NewMockEventRepository(), invitationRepo, mocks.NewMockUserRepository(users), mocks.NewMockFriendRepository(friends), services.NewNotificationService(mocks.NewMockNotificationRepository(), hub), nil, nil)
+
+	event := &models.Event{
+		Email:       "owner@example.com",
+		Title:       "Dinner",
+		Date:        "2024-12-01",
+		EventTypeID: "private",
+	}
+	if err := service.CreateEvent(context.Background(), event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	return &eventServiceFixture{
+		service:        service,
+		invitationRepo: invitationRepo,
+		hub:            hub,
+		eventID:        event.EventID,
+	}
+}
+
+func TestEventService_InviteToEvent_NonFriend(t *testing.T) {
+	f := newEventServiceFixture(t)
+
+	err := f.service.InviteToEvent(context.Background(), "owner@example.com", f.eventID, "stranger")
+	if err == nil || err.Error() != "You can only invite friends to an event" {
+		t.Errorf("Expected non-friend invite to be rejected, got %v", err)
+	}
+	if len(f.invitationRepo.Invitations) != 0 {
+		t.Errorf("Expected no invitations to be stored, got %d", len(f.invitationRepo.Invitations))
+	}
+}
+
+func TestEventService_InviteToEvent_Idempotent(t *testing.T) {
+	f := newEventServiceFixture(t)
+	subscription := f.hub.Subscribe("friend@example.com")
+	defer f.hub.Unsubscribe(subscription)
+
+	for i := 0; i < 2; i++ {
+		if err := f.service.InviteToEvent(context.Background(), "owner@example.com", f.eventID, "friend"); err != nil {
+			t.Fatalf("Invite %d failed: %v", i+1, err)
+		}
+	}
+	if len(f.invitationRepo.Invitations) != 1 {
+		t.Errorf("Expected exactly 1 invitation, got %d", len(f.invitationRepo.Invitations))
+	}
+
+	invitations, err := f.service.GetInvitations(context.Background(), "friend@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get invitations: %v", err)
+	}
+	if len(invitations) != 1 || invitations[0].Status != "pending" || invitations[0].Event == nil {
+		t.Errorf("Expected 1 pending invitation with event details, got %+v", invitations)
+	}
+
+	if len(subscription.Notifications) != 1 {
+		t.Fatalf("Expected 1 invitation notification, got %d", len(subscription.Notifications))
+	}
+	notification := <-subscription.Notifications
+	if notification.Type != services.NotificationEventInvitation || notification.Payload["eventID"] != f.eventID || notification.Payload["username"] != "owner" {
+		t.Errorf("Expected an event_invitation notification from owner, got %+v", notification)
+	}
+}
+
+func TestEventService_RespondToInvitation_Decline(t *testing.T) {
+	f := newEventServiceFixture(t)
+
+	if err := f.service.InviteToEvent(context.Background(), "owner@example.com", f.eventID, "friend@example.com"); err != nil {
+		t.Fatalf("Invite failed: %v", err)
+	}
+	if err := f.service.RespondToInvitation(context.Background(), "friend@example.com", f.eventID, "decline"); err != nil {
+		t.Fatalf("Decline failed: %v", err)
+	}
+
+	invitation, _ := f.invitationRepo.GetInvitation(context.Background(), f.eventID, "friend@example.com")
+	if invitation == nil || invitation.Status != "declined" {
+		t.Errorf("Expected invitation to be declined, got %+v", invitation)
+	}
+
+	page, err := f.service.GetAllEvents(context.Background(), "friend@example.com", models.EventQuery{})
+	if err != nil {
+		t.Fatalf("Failed to get events: %v", err)
+	}
+	if len(page.Items) != 0 {
+		t.Errorf("Expected declined event to be excluded, got %d events", len(page.Items))
+	}
+
+	// Accepting afterwards makes the event show up in the invitee's calendar.
+	if err := f.service.RespondToInvitation(context.Background(), "friend@example.com", f.eventID, "accept"); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	page, _ = f.service.GetAllEvents(context.Background(), "friend@example.com", models.EventQuery{})
+	if len(page.Items) != 1 || page.Items[0].EventID != f.eventID {
+		t.Errorf("Expected accepted event to be included, got %+v", page.Items)
+	}
+}
+
+// newPaginationService creates an EventService whose user owns 60 events, one per day from 2024-01-01.
+func newPaginationService(t *testing.T) services.EventServiceInterface {
+	t.Helper()
+	service := services.NewEventService(mocks.NewMockEventRepository(), mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 60; i++ {
+		event := &models.Event{
+			Email:       "user@example.com",
+			Title:       fmt.Sprintf("Lecture %d", i+1),
+			Date:        first.AddDate(0, 0, i).Format("2006-01-02"),
+			EventTypeID: "private",
+		}
+		if err := service.CreateEvent(context.Background(), event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+	return service
+}
+
+func TestEventService_GetAllEvents_Pagination(t *testing.T) {
+	service := newPaginationService(t)
+
+	var pages [][]models.Event
+	query := models.EventQuery{Limit: 25}
+	for {
+		page, err := service.GetAllEvents(context.Background(), "user@example.com", query)
+		if err != nil {
+			t.Fatalf("Failed to get events: %v", err)
+		}
+		pages = append(pages, page.Items)
+		if page.NextPageToken == "" {
+			break
+		}
+		query.PageToken = page.NextPageToken
+	}
+
+	if len(pages) != 3 || len(pages[0]) != 25 || len(pages[1]) != 25 || len(pages[2]) != 10 {
+		t.Fatalf("Expected pages of 25, 25 and 10 events, got %d pages", len(pages))
+	}
+
+	// Pages must continue exactly where the previous one stopped, in date order.
+	var all []models.Event
+	for _, page := range pages {
+		all = append(all, page...)
+	}
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, event := range all {
+		if want := first.AddDate(0, 0, i).Format("2006-01-02"); event.Date != want {
+			t.Fatalf("Event %d: expected date %s, got %s", i, want, event.Date)
+		}
+	}
+
+	// Without a limit, every event is returned in a single page.
+	page, err := service.GetAllEvents(context.Background(), "user@example.com", models.EventQuery{})
+	if err != nil {
+		t.Fatalf("Failed to get events: %v", err)
+	}
+	if len(page.Items) != 60 || page.NextPageToken != "" {
+		t.Errorf("Expected all 60 events without a next page, got %d (token %q)", len(page.Items), page.NextPageToken)
+	}
+}
+
+func TestEventService_GetAllEvents_DateRange(t *testing.T) {
+	service := newPaginationService(t)
+
+	query := models.EventQuery{From: "2024-01-10", To: "2024-01-31", Limit: 20}
+	page, err := service.GetAllEvents(context.Background(), "user@example.com", query)
+	if err != nil {
+		t.Fatalf("Failed to get events: %v", err)
+	}
+	if len(page.Items) != 20 || page.Items[0].Date != "2024-01-10" || page.NextPageToken == "" {
+		t.Fatalf("Expected a full first page starting at 2024-01-10, got %d events", len(page.Items))
+	}
+
+	query.PageToken = page.NextPageToken
+	page, err = service.GetAllEvents(context.Background(), "user@example.com", query)
+	if err != nil {
+		t.Fatalf("Failed to get events: %v", err)
+	}
+	if len(page.Items) != 2 || page.Items[1].Date != "2024-01-31" || page.NextPageToken != "" {
+		t.Errorf("Expected a last page of 2 events ending at 2024-01-31, got %+v", page)
+	}
+}
+
+func TestEventService_GetAllEvents_InvalidQuery(t *testing.T) {
+	service := newPaginationService(t)
+
+	queries := []models.EventQuery{
+		{From: "01-10-2024"},
+		{From: "2024-02-01", To: "2024-01-01"},
+		{Limit: -1},
+	}
+	for _, query := range queries {
+		if _, err := service.GetAllEvents(context.Background(), "user@example.com", query); err == nil {
+			t.Errorf("Expected query %+v to be rejected", query)
+		}
+	}
+}
+
+// newRecurrenceService creates an EventService with no events for user@example.com.
+func newRecurrenceService() services.EventServiceInterface {
+	return services.NewEventService(mocks.NewMockEventRepository(), mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+}
+
+// createSeries creates a recurring event for user@example.com starting on date.
+func createSeries(t *testing.T, service services.EventServiceInterface, date string, rule models.Recurrence) string {
+	t.Helper()
+	event := &models.Event{
+		Email:       "user@example.com",
+		Title:       "Weekly lecture",
+		Date:        date,
+		StartTime:   "10:15",
+		EventTypeID: "private",
+		Recurrence:  &rule,
+	}
+	if err := service.CreateEvent(context.Background(), event); err != nil {
+		t.Fatalf("Failed to create recurring event: %v", err)
+	}
+	return event.EventID
+}
+
+// listDates returns the dates of all events of user@example.com within [from, to].
+func listDates(t *testing.T, service services.EventServiceInterface, from, to string) []string {
+	t.Helper()
+	page, err := service.GetAllEvents(context.Background(), "user@example.com", models.EventQuery{From: from, To: to})
+	if err != nil {
+		t.Fatalf("Failed to get events: %v", err)
+	}
+	dates := []string{}
+	for _, event := range page.Items {
+		dates = append(dates, event.Date)
+	}
+	return dates
+}
+
+// assertDates fails the test if got and want differ.
+func assertDates(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected dates %v, got %v", want, got)
+	}
+}
+
+func TestEventService_Recurrence_MonthBoundaries(t *testing.T) {
+	service := newRecurrenceService()
+	createSeries(t, service, "2024-01-31", models.Recurrence{Frequency: "weekly"})
+
+	// A series starting before the window still yields the occurrences inside it.
+	assertDates(t, listDates(t, service, "2024-02-01", "2024-03-06"),
+		"2024-02-07", "2024-02-14", "2024-02-21", "2024-02-28", "2024-03-06")
+
+	// Every other day across the end of February in a leap year.
+	service = newRecurrenceService()
+	createSeries(t, service, "2024-02-27", models.Recurrence{Frequency: "DAILY", Interval: 2})
+	assertDates(t, listDates(t, service, "2024-02-01", "2024-03-04"),
+		"2024-02-27", "2024-02-29", "2024-03-02", "2024-03-04")
+
+	// Occurrences keep their start time.
+	page, _ := service.GetAllEvents(context.Background(), "user@example.com", models.EventQuery{From: "2024-03-02", To: "2024-03-02"})
+	if len(page.Items) != 1 || page.Items[0].StartAt.Format("2006-01-02 15:04") != "2024-03-02 10:15" {
+		t.Errorf("Expected a single occurrence starting 2024-03-02 10:15, got %+v", page.Items)
+	}
+}
+
+func TestEventService_Recurrence_UntilIsInclusive(t *testing.T) {
+	service := newRecurrenceService()
+	createSeries(t, service, "2024-01-01", models.Recurrence{Frequency: "weekly", Until: "2024-01-29"})
+	assertDates(t, listDates(t, service, "2024-01-01", "2024-12-31"),
+		"2024-01-01", "2024-01-08", "2024-01-15", "2024-01-22", "2024-01-29")
+
+	service = newRecurrenceService()
+	createSeries(t, service, "2024-01-01", models.Recurrence{Frequency: "weekly", Until: "2024-01-28"})
+	assertDates(t, listDates(t, service, "2024-01-01", "2024-12-31"),
+		"2024-01-01", "2024-01-08", "2024-01-15", "2024-01-22")
+
+	// A series ending on its first day has exactly one occurrence.
+	service = newRecurrenceService()
+	createSeries(t, service, "2024-01-01", models.Recurrence{Frequency: "daily", Until: "2024-01-01"})
+	assertDates(t, listDates(t, service, "2023-12-01", "2024-12-31"), "2024-01-01")
+}
+
+func TestEventService_Recurrence_Count(t *testing.T) {
+	service := newRecurrenceService()
+	eventID := createSeries(t, service, "2024-01-30", models.Recurrence{Frequency: "daily", Count: 3})
+	assertDates(t, listDates(t, service, "2024-01-01", "2024-12-31"), "2024-01-30", "2024-01-31", "2024-02-01")
+
+	// Removing an occurrence does not extend the series.
+	if err := service.DeleteOccurrence(context.Background(), "user@example.com", eventID, "2024-01-31"); err != nil {
+		t.Fatalf("Failed to delete occurrence: %v", err)
+	}
+	assertDates(t, listDates(t, service, "2024-01-01", "2024-12-31"), "2024-01-30", "2024-02-01")
+}
+
+func TestEventService_Recurrence_Validation(t *testing.T) {
+	service := newRecurrenceService()
+
+	rules := map[string]models.Recurrence{
+		"Recurrence frequency must be 'daily' or 'weekly'":                    {Frequency: "monthly"},
+		"Recurrence interval must be a positive number":                       {Frequency: "daily", Interval: -1},
+		"Recurrence cannot have both until and count":                         {Frequency: "daily", Until: "2024-02-01", Count: 3},
+		"Recurrence count must be a positive number":                          {Frequency: "daily", Count: -2},
+		"Invalid input: recurrence.until must be a date in YYYY-MM-DD format": {Frequency: "daily", Until: "01-02-2024"},
+		"Recurrence until date must not be before the event date":             {Frequency: "daily", Until: "2023-12-31"},
+	}
+	for expected, rule := range rules {
+		rule := rule
+		event := &models.Event{Email: "user@example.com", Title: "Standup", Date: "2024-01-01", EventTypeID: "private", Recurrence: &rule}
+		if err := service.CreateEvent(context.Background(), event); err == nil || err.Error() != expected {
+			t.Errorf("Expected %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestEventService_Recurrence_UpdateOccurrence(t *testing.T) {
+	service := newRecurrenceService()
+	eventID := createSeries(t, service, "2024-01-01", models.Recurrence{Frequency: "weekly", Count: 4})
+
+	// Move the lecture of 2024-01-15 to the next day.
+	moved := &models.Event{Email: "user@example.com", EventID: eventID, Title: "Moved lecture", Date: "2024-01-16", StartTime: "12:15"}
+	if err := service.UpdateOccurrence(context.Background(), moved, "2024-01-15"); err != nil {
+		t.Fatalf("Failed to update occurrence: %v", err)
+	}
+	if moved.EventID == eventID {
+		t.Errorf("Expected the moved occurrence to get its own ID")
+	}
+	assertDates(t, listDates(t, service, "2024-01-01", "2024-01-31"), "2024-01-01", "2024-01-08", "2024-01-16", "2024-01-22")
+
+	// The same occurrence cannot be changed twice, and dates outside the series are rejected.
+	for _, date := range []string{"2024-01-15", "2024-01-02", "2024-01-29"} {
+		err := service.UpdateOccurrence(context.Background(), &models.Event{Email: "user@example.com", EventID: eventID}, date)
+		if err == nil || err.Error() != "Date is not an occurrence of this event" {
+			t.Errorf("Expected %s to be rejected, got %v", date, err)
+		}
+	}
+
+	// Updating the entire series keeps the moved occurrence.
+	series, _ := service.GetEvent(context.Background(), "user@example.com", eventID)
+	series.Title = "Renamed lecture"
+	if err := service.UpdateEvent(context.Background(), series); err != nil {
+		t.Fatalf("Failed to update series: %v", err)
+	}
+	assertDates(t, listDates(t, service, "2024-01-01", "2024-01-31"), "2024-01-01", "2024-01-08", "2024-01-16", "2024-01-22")
+
+	// Deleting the series removes the moved occurrence as well.
+	if err := service.DeleteEvent(context.Background(), "user@example.com", eventID); err != nil {
+		t.Fatalf("Failed to delete series: %v", err)
+	}
+	assertDates(t, listDates(t, service, "2024-01-01", "2024-01-31"))
+}
+
+func TestEventService_Recurrence_Pagination(t *testing.T) {
+	service := newRecurrenceService()
+	createSeries(t, service, "2024-01-20", models.Recurrence{Frequency: "daily", Count: 25})
+	single := &models.Event{Email: "user@example.com", Title: "One-off", Date: "2024-02-01", EventTypeID: "private"}
+	if err := service.CreateEvent(context.Background(), single); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	var sizes []int
+	var all []models.Event
+	query := models.EventQuery{From: "2024-01-01", To: "2024-02-29", Limit: 10}
+	for {
+		page, err := service.GetAllEvents(context.Background(), "user@example.com", query)
+		if err != nil {
+			t.Fatalf("Failed to get events: %v", err)
+		}
+		sizes = append(sizes, len(page.Items))
+		all = append(all, page.Items...)
+		if page.NextPageToken == "" {
+			break
+		}
+		query.PageToken = page.NextPageToken
+	}
+
+	if fmt.Sprint(sizes) != "[10 10 6]" {
+		t.Fatalf("Expected pages of 10, 10 and 6 events, got %v", sizes)
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Date < all[i-1].Date {
+			t.Fatalf("Events out of order at %d: %s after %s", i, all[i].Date, all[i-1].Date)
+		}
+	}
+
+	// Without a window, the series is listed once as stored.
+	page, _ := service.GetAllEvents(context.Background(), "user@example.com", models.EventQuery{})
+	if len(page.Items) != 2 {
+		t.Errorf("Expected the series and the one-off event, got %d events", len(page.Items))
+	}
+}
+
+func TestEventService_Tags_Validation(t *testing.T) {
+	service := newRecurrenceService()
+
+	event := &models.Event{Email: "user@example.com", Title: "Run", Date: "2024-03-01", EventTypeID: "private", Tags: []string{" Gym ", "Health"}}
+	if err := service.CreateEvent(context.Background(), event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if fmt.Sprint(event.Tags) != "[gym health]" {
+		t.Errorf("Expected tags to be trimmed and lowercased, got %v", event.Tags)
+	}
+
+	tooMany := make([]string, 11)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag%d", i)
+	}
+	tests := []struct {
+		name    string
+		tags    []string
+		wantErr string
+	}{
+		{"too many tags", tooMany, "An event can have at most 10 tags"},
+		{"too long", []string{strings.Repeat("a", 31)}, "Tags must be between 1 and 30 characters"},
+		{"empty", []string{"work", "  "}, "Tags must be between 1 and 30 characters"},
+		{"duplicate after lowercasing", []string{"Work", "work"}, "Duplicate tags are not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &models.Event{Email: "user@example.com", Title: "Run", Date: "2024-03-01", EventTypeID: "private", Tags: tt.tags}
+			if err := service.CreateEvent(context.Background(), event); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected error %q on create, got %v", tt.wantErr, err)
+			}
+
+			event = &models.Event{EventID: "event1", Email: "user@example.com", Title: "Run", Date: "2024-03-01", EventTypeID: "private", Tags: tt.tags}
+			if err := service.UpdateEvent(context.Background(), event); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected error %q on update, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestEventService_GetAllEvents_TagFilter(t *testing.T) {
+	service := newRecurrenceService()
+	for _, event := range []*models.Event{
+		{Title: "Standup", Date: "2024-03-04", Tags: []string{"work"}},
+		{Title: "Gym", Date: "2024-03-05", Tags: []string{"gym", "health"}},
+		{Title: "Untagged", Date: "2024-03-06"},
+		{Title: "Weekly review", Date: "2024-03-01", Tags: []string{"work"}, Recurrence: &models.Recurrence{Frequency: "weekly"}},
+	} {
+		event.Email = "user@example.com"
+		event.EventTypeID = "private"
+		if err := service.CreateEvent(context.Background(), event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+
+	titles := func(query models.EventQuery) []string {
+		t.Helper()
+		page, err := service.GetAllEvents(context.Background(), "user@example.com", query)
+		if err != nil {
+			t.Fatalf("Failed to get events: %v", err)
+		}
+		titles := []string{}
+		for _, event := range page.Items {
+			titles = append(titles, event.Title+" "+event.Date)
+		}
+		return titles
+	}
+
+	// Without a window the series is listed once; the tag filter is case-insensitive.
+	if got := fmt.Sprint(titles(models.EventQuery{Tag: "Work"})); got != "[Weekly review 2024-03-01 Standup 2024-03-04]" {
+		t.Errorf("Unexpected events tagged work: %s", got)
+	}
+	// Within a window, only occurrences of tagged series are expanded.
+	if got := fmt.Sprint(titles(models.EventQuery{From: "2024-03-05", To: "2024-03-15", Tag: "work"})); got != "[Weekly review 2024-03-08 Weekly review 2024-03-15]" {
+		t.Errorf("Unexpected events tagged work in window: %s", got)
+	}
+	if got := fmt.Sprint(titles(models.EventQuery{From: "2024-03-01", To: "2024-03-07", Tag: "gym"})); got != "[Gym 2024-03-05]" {
+		t.Errorf("Unexpected events tagged gym in window: %s", got)
+	}
+	if got := titles(models.EventQuery{Tag: "school"}); len(got) != 0 {
+		t.Errorf("Expected no events tagged school, got %v", got)
+	}
+}
+
+func TestEventService_GetEventTags(t *testing.T) {
+	service := newRecurrenceService()
+	for _, tags := range [][]string{{"work"}, {"gym", "work"}, nil, {"Gym"}, {"school"}} {
+		event := &models.Event{Email: "user@example.com", Title: "Event", Date: "2024-03-01", EventTypeID: "private", Tags: tags}
+		if err := service.CreateEvent(context.Background(), event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+
+	tags, err := service.GetEventTags(context.Background(), "user@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get tags: %v", err)
+	}
+	if got := fmt.Sprint(tags); got != "[{gym 2} {work 2} {school 1}]" {
+		t.Errorf("Expected tags ordered by count and name, got %s", got)
+	}
+}
+
+// newIdempotentEventService creates an EventService with Idempotency-Key support and a settable clock.
+func newIdempotentEventService() (*services.EventService, *mocks.MockEventRepository) {
+	eventRepo := mocks.NewMockEventRepository()
+	service := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, mocks.NewMockIdempotencyRepository(), nil).(*services.EventService)
+	return service, eventRepo
+}
+
+func TestEventService_CreateEventIdempotent(t *testing.T) {
+	service, eventRepo := newIdempotentEventService()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service.Now = func() time.Time { return now }
+	ctx := context.Background()
+	newEvent := func(title string) *models.Event {
+		return &models.Event{Email: "user@example.com", Title: title, Date: "2024-05-10", EventTypeID: "private"}
+	}
+
+	first := newEvent("Dinner")
+	replayed, err := service.CreateEventIdempotent(ctx, first, "key-1")
+	if err != nil || replayed {
+		t.Fatalf("Expected the first request to create an event, got replayed=%v, err=%v", replayed, err)
+	}
+
+	// A retry returns the original event without creating another one.
+	retry := newEvent("Dinner")
+	replayed, err = service.CreateEventIdempotent(ctx, retry, "key-1")
+	if err != nil || !replayed {
+		t.Fatalf("Expected the retry to be replayed, got replayed=%v, err=%v", replayed, err)
+	}
+	if retry.EventID != first.EventID {
+		t.Errorf("Expected replayed event %s, got %s", first.EventID, retry.EventID)
+	}
+	if len(eventRepo.Events) != 1 {
+		t.Errorf("Expected 1 event after a retry, got %d", len(eventRepo.Events))
+	}
+
+	// The same key with a different body is rejected.
+	if _, err := service.CreateEventIdempotent(ctx, newEvent("Lunch"), "key-1"); err != services.ErrIdempotencyKeyReused {
+		t.Errorf("Expected ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	// Keys are scoped to the user.
+	other := newEvent("Dinner")
+	other.Email = "other@example.com"
+	if replayed, err := service.CreateEventIdempotent(ctx, other, "key-1"); err != nil || replayed {
+		t.Errorf("Expected another user's key to create an event, got replayed=%v, err=%v", replayed, err)
+	}
+
+	// After the key expires, the same request creates a new event.
+	now = now.Add(25 * time.Hour)
+	expired := newEvent("Dinner")
+	if replayed, err := service.CreateEventIdempotent(ctx, expired, "key-1"); err != nil || replayed {
+		t.Fatalf("Expected an expired key to create an event, got replayed=%v, err=%v", replayed, err)
+	}
+	if expired.EventID == first.EventID {
+		t.Errorf("Expected a new event after the key expired, got %s again", expired.EventID)
+	}
+
+	// A failed creation releases the key, so the corrected request can use it.
+	invalid := newEvent("Dinner")
+	invalid.EventTypeID = "secret"
+	if _, err := service.CreateEventIdempotent(ctx, invalid, "key-2"); err == nil {
+		t.Fatal("Expected an invalid event to be rejected")
+	}
+	if replayed, err := service.CreateEventIdempotent(ctx, newEvent("Dinner"), "key-2"); err != nil || replayed {
+		t.Errorf("Expected the key of a failed request to be reusable, got replayed=%v, err=%v", replayed, err)
+	}
+}
+
+func TestEventService_CreateEventIdempotent_Concurrent(t *testing.T) {
+	service, eventRepo := newIdempotentEventService()
+
+	const requests = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			event := &models.Event{Email: "user@example.com", Title: "Dinner", Date: "2024-05-10", EventTypeID: "private"}
+			_, err := service.CreateEventIdempotent(context.Background(), event, "key-1")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil && err != services.ErrIdempotencyRequestInProgress {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if len(eventRepo.Events) != 1 {
+		t.Errorf("Expected concurrent requests to create 1 event, got %d", len(eventRepo.Events))
+	}
+}
+
+// flakyIdempotencyRepo is an in-memory IdempotencyRepository whose completions and deletions can fail,
+// as if the request died before it finished with its key.
+type flakyIdempotencyRepo struct {
+	*mocks.MockIdempotencyRepository
+	failCompletion bool // Fail storing a completed record.
+	failDelete     bool // Fail releasing a key.
+}
+
+func (r *flakyIdempotencyRepo) CompleteRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if r.failCompletion && record.Status == "completed" {
+		return repositories.ErrUnavailable
+	}
+	return r.MockIdempotencyRepository.CompleteRecord(ctx, record)
+}
+
+func (r *flakyIdempotencyRepo) DeleteRecord(ctx context.Context, userEmail, key string) error {
+	if r.failDelete {
+		return repositories.ErrUnavailable
+	}
+	return r.MockIdempotencyRepository.DeleteRecord(ctx, userEmail, key)
+}
+
+func TestEventService_CreateEventIdempotent_Recovery(t *testing.T) {
+	service, eventRepo := newIdempotentEventService()
+	idempotencyRepo := &flakyIdempotencyRepo{MockIdempotencyRepository: mocks.NewMockIdempotencyRepository()}
+	service.IdempotencyRepo = idempotencyRepo
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service.Now = func() time.Time { return now }
+	ctx := context.Background()
+	newEvent := func() *models.Event {
+		return &models.Event{Email: "user@example.com", Title: "Dinner", Date: "2024-05-10", EventTypeID: "private"}
+	}
+
+	// The event is created but the record cannot be completed; the retry finishes it and replays the event.
+	idempotencyRepo.failCompletion = true
+	first := newEvent()
+	if _, err := service.CreateEventIdempotent(ctx, first, "key-1"); err == nil {
+		t.Fatal("Expected the failed completion to be reported")
+	}
+	idempotencyRepo.failCompletion = false
+	retry := newEvent()
+	if replayed, err := service.CreateEventIdempotent(ctx, retry, "key-1"); err != nil || !replayed {
+		t.Fatalf("Expected the retry to replay the created event, got replayed=%v, err=%v", replayed, err)
+	}
+	if retry.EventID != first.EventID || len(eventRepo.Events) != 1 {
+		t.Errorf("Expected event %s to be replayed and no other created, got %s and %d events", first.EventID, retry.EventID, len(eventRepo.Events))
+	}
+	if record, _ := idempotencyRepo.GetRecord(ctx, "user@example.com", "key-1"); record == nil || record.Status != "completed" {
+		t.Errorf("Expected the retry to complete the record, got %+v", record)
+	}
+
+	// The request dies before creating the event, without releasing its key.
+	idempotencyRepo.failDelete = true
+	eventRepo.Err = repositories.ErrUnavailable
+	if _, err := service.CreateEventIdempotent(ctx, newEvent(), "key-2"); err == nil {
+		t.Fatal("Expected the failed creation to be reported")
+	}
+	eventRepo.Err = nil
+	if _, err := service.CreateEventIdempotent(ctx, newEvent(), "key-2"); !errors.Is(err, services.ErrIdempotencyRequestInProgress) {
+		t.Errorf("Expected the key to be held during its lease, got %v", err)
+	}
+	now = now.Add(time.Minute)
+	takeover := newEvent()
+	if replayed, err := service.CreateEventIdempotent(ctx, takeover, "key-2"); err != nil || replayed {
+		t.Fatalf("Expected a retry after the lease to create the event, got replayed=%v, err=%v", replayed, err)
+	}
+	replay := newEvent()
+	if replayed, err := service.CreateEventIdempotent(ctx, replay, "key-2"); err != nil || !replayed || replay.EventID != takeover.EventID {
+		t.Errorf("Expected the next retry to replay event %s, got %s (replayed=%v, err=%v)", takeover.EventID, replay.EventID, replayed, err)
+	}
+}
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		expected, tolerance    float64
+	}{
+		{"same position", 63.4305, 10.3951, 63.4305, 10.3951, 0, 1e-9},
+		{"Trondheim to Oslo", 63.4305, 10.3951, 59.9139, 10.7522, 391.5, 1},
+		{"one degree of longitude at the equator", 0, 0, 0, 1, 111.19, 0.01},
+		{"across the antimeridian", 0, 179.5, 0, -179.5, 111.19, 0.01},
+		{"antipodes", 0, 0, 0, 180, math.Pi * 6371, 1e-6},
+	}
+	for _, tt := range tests {
+		got := services.HaversineKm(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+		if math.Abs(got-tt.expected) > tt.tolerance {
+			t.Errorf("%s: expected %.2f km, got %.2f km", tt.name, tt.expected, got)
+		}
+		if back := services.HaversineKm(tt.lat2, tt.lng2, tt.lat1, tt.lng1); math.Abs(back-got) > 1e-9 {
+			t.Errorf("%s: expected the distance to be symmetric, got %f and %f", tt.name, got, back)
+		}
+	}
+}
+
+// newGeocodingEventService creates an EventService whose geocoder knows a single address in Trondheim.
+func newGeocodingEventService() (services.EventServiceInterface, *mocks.MockGeocodingService) {
+	geocoder := mocks.NewMockGeocodingService(map[string]services.Coordinates{
+		"Kongens gate 1, 7011": {Latitude: 63.4305, Longitude: 10.3951},
+	})
+	service := services.NewEventService(mocks.NewMockEventRepository(), mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, geocoder)
+	return service, geocoder
+}
+
+func TestEventService_Geocoding(t *testing.T) {
+	service, geocoder := newGeocodingEventService()
+	ctx := context.Background()
+	newEvent := func(street, postal string) *models.Event {
+		return &models.Event{Email: "user@example.com", Title: "Event", Date: "2024-03-01", EventTypeID: "private", StreetAddress: street, PostalNumber: postal}
+	}
+
+	found := newEvent("Kongens gate 1", "7011")
+	if err := service.CreateEvent(ctx, found); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if found.Latitude == nil || *found.Latitude != 63.4305 || *found.Longitude != 10.3951 {
+		t.Errorf("Expected the address to be geocoded, got %v, %v", found.Latitude, found.Longitude)
+	}
+
+	unknown := newEvent("Nowhere 1", "9999")
+	if err := service.CreateEvent(ctx, unknown); err != nil {
+		t.Fatalf("Expected an unknown address not to fail creation, got %v", err)
+	}
+	if unknown.Latitude != nil || unknown.Longitude != nil {
+		t.Errorf("Expected no coordinates for an unknown address, got %v, %v", unknown.Latitude, unknown.Longitude)
+	}
+
+	geocoder.Err = errors.New("Geocoding API responded with status 503")
+	failed := newEvent("Kongens gate 1", "7011")
+	if err := service.CreateEvent(ctx, failed); err != nil {
+		t.Fatalf("Expected a geocoding failure not to fail creation, got %v", err)
+	}
+	if failed.Latitude != nil {
+		t.Errorf("Expected no coordinates when geocoding fails, got %v", *failed.Latitude)
+	}
+	geocoder.Err = nil
+
+	calls := len(geocoder.Calls)
+	latitude, longitude := 59.9139, 10.7522
+	given := newEvent("Kongens gate 1", "7011")
+	given.Latitude, given.Longitude = &latitude, &longitude
+	if err := service.CreateEvent(ctx, given); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if *given.Latitude != 59.9139 || len(geocoder.Calls) != calls {
+		t.Errorf("Expected coordinates sent by the client to be kept without a lookup, got %v after %d lookups", *given.Latitude, len(geocoder.Calls)-calls)
+	}
+
+	if err := service.CreateEvent(ctx, newEvent("", "")); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if len(geocoder.Calls) != calls {
+		t.Errorf("Expected events without an address not to be geocoded, got %v", geocoder.Calls[calls:])
+	}
+
+	// Updating an event whose address was not found retries the lookup.
+	unknown.StreetAddress = "Kongens gate 1"
+	unknown.PostalNumber = "7011"
+	if err := service.UpdateEvent(ctx, unknown); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if unknown.Latitude == nil || *unknown.Latitude != 63.4305 {
+		t.Errorf("Expected the updated address to be geocoded, got %v", unknown.Latitude)
+	}
+}
+
+func TestEventService_GetNearbyEvents(t *testing.T) {
+	service, _ := newGeocodingEventService()
+	ctx := context.Background()
+	positions := map[string][2]float64{
+		"Solsiden":  {63.4346, 10.4130}, // About 1 km from Kongens gate 1.
+		"Heimdal":   {63.3520, 10.3570}, // About 9 km away.
+		"Oslo":      {59.9139, 10.7522}, // About 390 km away.
+		"Torget":    {63.4305, 10.3951}, // At Kongens gate 1.
+		"No coords": {math.NaN(), math.NaN()},
+	}
+	for title, position := range positions {
+		event := &models.Event{Email: "user@example.com", Title: title, Date: "2024-03-01", EventTypeID: "private"}
+		if latitude, longitude := position[0], position[1]; !math.IsNaN(latitude) {
+			event.Latitude, event.Longitude = &latitude, &longitude
+		}
+		if err := service.CreateEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+
+	nearby, err := service.GetNearbyEvents(ctx, "user@example.com", 63.4305, 10.3951, 10)
+	if err != nil {
+		t.Fatalf("Failed to get nearby events: %v", err)
+	}
+	var titles []string
+	for _, event := range nearby {
+		titles = append(titles, event.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Torget,Solsiden,Heimdal" {
+		t.Errorf("Expected the events within 10 km, nearest first, got %s", got)
+	}
+	if len(nearby) == 3 && (nearby[0].DistanceKm != 0 || nearby[1].DistanceKm < 0.5 || nearby[1].DistanceKm > 1.5) {
+		t.Errorf("Unexpected distances %v and %v", nearby[0].DistanceKm, nearby[1].DistanceKm)
+	}
+
+	if nearby, err := service.GetNearbyEvents(ctx, "other@example.com", 63.4305, 10.3951, 10); err != nil || len(nearby) != 0 {
+		t.Errorf("Expected no events of other users, got %v (err: %v)", nearby, err)
+	}
+
+	for _, params := range [][3]float64{{91, 0, 10}, {0, -181, 10}, {0, 0, 0}, {0, 0, 501}} {
+		if _, err := service.GetNearbyEvents(ctx, "user@example.com", params[0], params[1], params[2]); err == nil {
+			t.Errorf("Expected lat %v, lng %v, radiusKm %v to be rejected", params[0], params[1], params[2])
+		}
+	}
+}
+
+func TestEventService_BulkCreateEvents(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	eventRepo.FailEventTitles = map[string]error{"Unlucky": fmt.Errorf("Failed to create event: %w", repositories.ErrUnavailable)}
+	service := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	ctx := context.Background()
+
+	result, err := service.BulkCreateEvents(ctx, "user@example.com", []models.Event{
+		{Title: "Lecture", Date: "2024-05-01", StartTime: "10:15", EventTypeID: "Private", Email: "someone@example.com"},
+		{Title: "Bad date", Date: "01.05.2024", EventTypeID: "private"},
+		{Title: "Unlucky", Date: "2024-05-02", EventTypeID: "private"},
+		{Title: "Lab", Date: "2024-05-03", EventTypeID: "public"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Succeeded) != 2 {
+		t.Fatalf("Expected 2 created events, got %+v", result)
+	}
+	for _, eventID := range result.Succeeded {
+		stored := eventRepo.Events[eventID]
+		if stored == nil || stored.Email != "user@example.com" || stored.EventTypeID == "Private" {
+			t.Errorf("Expected event %s to be created for the user with a normalized type, got %+v", eventID, stored)
+		}
+	}
+	if len(result.Failed) != 2 ||
+		result.Failed[0].Index != 1 || result.Failed[0].Error != "Invalid input: date must be a date in YYYY-MM-DD format" ||
+		result.Failed[1].Index != 2 || !strings.Contains(result.Failed[1].Error, "Failed to create event") {
+		t.Errorf("Expected the invalid date and the failed write to be reported by index, got %+v", result.Failed)
+	}
+
+	if _, err := service.BulkCreateEvents(ctx, "user@example.com", nil); !errors.Is(err, services.ErrNoBulkEvents) {
+		t.Errorf("Expected ErrNoBulkEvents for an empty request, got %v", err)
+	}
+	tooMany := make([]models.Event, services.MaxBulkEvents+1)
+	for i := range tooMany {
+		tooMany[i] = models.Event{Title: "Event", Date: "2024-05-01", EventTypeID: "private"}
+	}
+	before := len(eventRepo.Events)
+	if _, err := service.BulkCreateEvents(ctx, "user@example.com", tooMany); !errors.Is(err, services.ErrTooManyBulkEvents) {
+		t.Errorf("Expected ErrTooManyBulkEvents for %d events, got %v", len(tooMany), err)
+	}
+	if len(eventRepo.Events) != before {
+		t.Errorf("Expected nothing to be created for a request over the cap")
+	}
+	if result, err := service.BulkCreateEvents(ctx, "user@example.com", tooMany[:services.MaxBulkEvents]); err != nil || len(result.Succeeded) != services.MaxBulkEvents {
+		t.Errorf("Expected %d events to be created at the cap, got %v (err: %v)", services.MaxBulkEvents, result, err)
+	}
+}
+
+func TestEventService_BulkDeleteEvents(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	service := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	ctx := context.Background()
+
+	create := func(email, title string) string {
+		t.Helper()
+		event := &models.Event{Email: email, Title: title, Date: "2024-05-01", EventTypeID: "private"}
+		if err := service.CreateEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+		return event.EventID
+	}
+	mine, stuck, other := create("user@example.com", "Mine"), create("user@example.com", "Stuck"), create("other@example.com", "Theirs")
+	series := createSeries(t, service, "2024-05-06", models.Recurrence{Frequency: "weekly"})
+	changed := &models.Event{Email: "user@example.com", EventID: series, Title: "Moved lecture", StartTime: "12:15"}
+	if err := service.UpdateOccurrence(ctx, changed, "2024-05-13"); err != nil {
+		t.Fatalf("Failed to change an occurrence: %v", err)
+	}
+	eventRepo.FailEventIDs = map[string]error{stuck: fmt.Errorf("Failed to delete event: %w", repositories.ErrUnavailable)}
+
+	result, err := service.BulkDeleteEvents(ctx, "user@example.com", []string{mine, other, "missing", mine, stuck, series})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Succeeded) != 2 || result.Succeeded[0] != mine || result.Succeeded[1] != series {
+		t.Errorf("Expected %s and %s to be deleted, got %v", mine, series, result.Succeeded)
+	}
+	want := []models.BulkEventFailure{
+		{Index: 1, EventID: other, Error: "Event not found"},
+		{Index: 2, EventID: "missing", Error: "Event not found"},
+		{Index: 3, EventID: mine, Error: "Duplicate eventID"},
+		{Index: 4, EventID: stuck, Error: "Failed to delete event: database unavailable"},
+	}
+	if len(result.Failed) != len(want) {
+		t.Fatalf("Expected failures %+v, got %+v", want, result.Failed)
+	}
+	for i := range want {
+		if result.Failed[i] != want[i] {
+			t.Errorf("Expected failure %+v, got %+v", want[i], result.Failed[i])
+		}
+	}
+
+	if eventRepo.Events[other] == nil {
+		t.Errorf("Expected the other user's event not to be deleted")
+	}
+	if eventRepo.Events[changed.EventID] != nil {
+		t.Errorf("Expected the changed occurrence to be deleted with its series")
+	}
+
+	if _, err := service.BulkDeleteEvents(ctx, "user@example.com", make([]string, services.MaxBulkEvents+1)); !errors.Is(err, services.ErrTooManyBulkEvents) {
+		t.Errorf("Expected ErrTooManyBulkEvents, got %v", err)
+	}
+	eventRepo.Err = repositories.ErrUnavailable
+	if _, err := service.BulkDeleteEvents(ctx, "user@example.com", []string{other}); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected the lookup to fail with ErrUnavailable, got %v", err)
+	}
+}
+
+func TestEventService_ImportBatchID(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	service := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	ctx := context.Background()
+
+	manual := &models.Event{Email: "user@example.com", Title: "Dentist", Date: "2024-05-01", EventTypeID: "private", ImportBatchID: "batch1", ExternalID: "uid-1@ntnu"}
+	if err := service.CreateEvent(ctx, manual); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if stored := eventRepo.Events[manual.EventID]; stored.ImportBatchID != "" || stored.ExternalID != "" {
+		t.Errorf("Expected a manually created event not to join an import batch or calendar, got %+v", stored)
+	}
+
+	importedAt := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	imported := &models.Event{Email: "user@example.com", Title: "Lecture", Date: "2024-05-02", EventTypeID: "private", ImportBatchID: "batch1", ImportedAt: &importedAt, ExternalID: "uid-2@ntnu"}
+	eventRepo.CreateEvent(ctx, imported)
+	update := &models.Event{Email: "user@example.com", EventID: imported.EventID, Title: "Moved lecture", Date: "2024-05-03", EventTypeID: "private"}
+	if err := service.UpdateEvent(ctx, update); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if stored := eventRepo.Events[imported.EventID]; stored.ImportBatchID != "batch1" || stored.ImportedAt == nil || stored.ExternalID != "uid-2@ntnu" {
+		t.Errorf("Expected an updated imported event to stay in its batch and calendar, got %+v", stored)
+	}
+}
+
+// newTimeZoneService creates an EventService for oslo@example.com, whose time zone comes from their
+// country, and ny@example.com, who set their time zone in their profile.
+func newTimeZoneService() (services.EventServiceInterface, *mocks.MockEventRepository, *mocks.MockUserRepository) {
+	eventRepo := mocks.NewMockEventRepository()
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"oslo@example.com": {Email: "oslo@example.com", Username: "oslo", Country: "Norway"},
+		"ny@example.com":   {Email: "ny@example.com", Username: "ny", Country: "Norway", TimeZone: "America/New_York"},
+	})
+	service := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	return service, eventRepo, userRepo
+}
+
+func TestEventService_TimeZones_DST(t *testing.T) {
+	service, eventRepo, _ := newTimeZoneService()
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		date      string
+		startTime string
+		endTime   string
+		startAt   string // Expected StartAt in UTC.
+		endAt     string // Expected EndAt in UTC.
+	}{
+		{"winter time", "2024-03-30", "09:00", "10:00", "2024-03-30T08:00:00Z", "2024-03-30T09:00:00Z"},
+		{"before the clocks go forward", "2024-03-31", "01:00", "01:59", "2024-03-31T00:00:00Z", "2024-03-31T00:59:00Z"},
+		{"after the clocks go forward", "2024-03-31", "03:00", "04:00", "2024-03-31T01:00:00Z", "2024-03-31T02:00:00Z"},
+		{"across the clocks going forward", "2024-03-31", "01:30", "03:30", "2024-03-31T00:30:00Z", "2024-03-31T01:30:00Z"},
+		{"summer time", "2024-07-01", "09:00", "10:00", "2024-07-01T07:00:00Z", "2024-07-01T08:00:00Z"},
+		{"the repeated hour is the first one", "2024-10-27", "02:30", "03:30", "2024-10-27T00:30:00Z", "2024-10-27T02:30:00Z"},
+		{"after the clocks go back", "2024-10-27", "03:00", "04:00", "2024-10-27T02:00:00Z", "2024-10-27T03:00:00Z"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event := &models.Event{Email: "oslo@example.com", Title: "Meeting", Date: test.date, StartTime: test.startTime, EndTime: test.endTime, EventTypeID: "private"}
+			if err := service.CreateEvent(ctx, event); err != nil {
+				t.Fatalf("Failed to create event: %v", err)
+			}
+			stored := eventRepo.Events[event.EventID]
+			if stored.TimeZone != "Europe/Oslo" || stored.StartTime != test.startTime || stored.EndTime != test.endTime {
+				t.Errorf("Expected %s-%s in Europe/Oslo, got %s-%s in %q", test.startTime, test.endTime, stored.StartTime, stored.EndTime, stored.TimeZone)
+			}
+			if got := stored.StartAt.UTC().Format(time.RFC3339); got != test.startAt {
+				t.Errorf("Expected startAt %s, got %s", test.startAt, got)
+			}
+			if stored.EndAt == nil || stored.EndAt.UTC().Format(time.RFC3339) != test.endAt {
+				t.Errorf("Expected endAt %s, got %v", test.endAt, stored.EndAt)
+			}
+		})
+	}
+
+	// Times skipped when the clocks go forward do not exist.
+	for _, times := range [][2]string{{"02:30", "03:30"}, {"01:00", "02:15"}} {
+		event := &models.Event{Email: "oslo@example.com", Title: "Meeting", Date: "2024-03-31", StartTime: times[0], EndTime: times[1], EventTypeID: "private"}
+		if err := service.CreateEvent(ctx, event); !errors.Is(err, services.ErrNonexistentLocalTime) {
+			t.Errorf("Expected ErrNonexistentLocalTime for %s-%s, got %v", times[0], times[1], err)
+		}
+	}
+
+	// New York changes its clocks on other days; the skipped hour in Oslo is an ordinary time there.
+	event := &models.Event{Email: "ny@example.com", Title: "Meeting", Date: "2024-03-31", StartTime: "02:30", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if got := eventRepo.Events[event.EventID].StartAt.UTC().Format(time.RFC3339); got != "2024-03-31T06:30:00Z" {
+		t.Errorf("Expected 02:30 in New York to be 06:30 UTC, got %s", got)
+	}
+	event = &models.Event{Email: "ny@example.com", Title: "Meeting", Date: "2024-03-10", StartTime: "02:30", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, event); !errors.Is(err, services.ErrNonexistentLocalTime) {
+		t.Errorf("Expected ErrNonexistentLocalTime on the day New York changes its clocks, got %v", err)
+	}
+}
+
+func TestEventService_TimeZones_Timestamps(t *testing.T) {
+	service, eventRepo, _ := newTimeZoneService()
+	ctx := context.Background()
+
+	startAt := time.Date(2024, 3, 31, 0, 30, 0, 0, time.UTC)
+	endAt := time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC)
+	event := &models.Event{Email: "oslo@example.com", Title: "Meeting", StartAt: startAt, EndAt: &endAt, EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event from timestamps: %v", err)
+	}
+	stored := eventRepo.Events[event.EventID]
+	if stored.Date != "2024-03-31" || stored.StartTime != "01:30" || stored.EndTime != "03:30" || stored.TimeZone != "Europe/Oslo" {
+		t.Errorf("Expected 2024-03-31 01:30-03:30 in Europe/Oslo, got %s %s-%s in %q", stored.Date, stored.StartTime, stored.EndTime, stored.TimeZone)
+	}
+	if !stored.StartAt.Equal(startAt) || stored.EndAt == nil || !stored.EndAt.Equal(endAt) {
+		t.Errorf("Expected the timestamps to be kept, got %v-%v", stored.StartAt, stored.EndAt)
+	}
+
+	// The date and times win over the timestamps when both are given.
+	event = &models.Event{Email: "oslo@example.com", Title: "Meeting", Date: "2024-07-01", StartTime: "09:00", StartAt: startAt, EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if got := eventRepo.Events[event.EventID].StartAt.UTC().Format(time.RFC3339); got != "2024-07-01T07:00:00Z" {
+		t.Errorf("Expected startAt from the date and start time, got %s", got)
+	}
+
+	endAt = startAt.Add(24 * time.Hour)
+	event = &models.Event{Email: "oslo@example.com", Title: "Trip", StartAt: startAt, EndAt: &endAt, EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, event); !errors.Is(err, services.ErrEventSpansDays) {
+		t.Errorf("Expected ErrEventSpansDays, got %v", err)
+	}
+}
+
+func TestEventService_TimeZones_Rendering(t *testing.T) {
+	service, eventRepo, userRepo := newTimeZoneService()
+	ctx := context.Background()
+
+	event := &models.Event{Email: "oslo@example.com", Title: "Meeting", Date: "2024-01-15", StartTime: "09:00", EndTime: "10:00", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	allDay := &models.Event{Email: "oslo@example.com", Title: "Holiday", Date: "2024-01-16", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, allDay); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	// Events stored before time zones were recorded have no TimeZone and are shown as stored.
+	legacy := &models.Event{Email: "oslo@example.com", Title: "Old meeting", Date: "2024-01-17", StartTime: "09:00", EndTime: "10:00", StartAt: time.Date(2024, 1, 17, 9, 0, 0, 0, time.UTC), EventTypeID: "private"}
+	eventRepo.CreateEvent(ctx, legacy)
+
+	// The user moves to New York after creating the events.
+	userRepo.Users["oslo@example.com"].TimeZone = "America/New_York"
+
+	rendered, err := service.GetEvent(ctx, "oslo@example.com", event.EventID)
+	if err != nil {
+		t.Fatalf("Failed to get event: %v", err)
+	}
+	if rendered.Date != "2024-01-15" || rendered.StartTime != "03:00" || rendered.EndTime != "04:00" || rendered.TimeZone != "America/New_York" {
+		t.Errorf("Expected 2024-01-15 03:00-04:00 in America/New_York, got %s %s-%s in %q", rendered.Date, rendered.StartTime, rendered.EndTime, rendered.TimeZone)
+	}
+	if stored := eventRepo.Events[event.EventID]; stored.StartTime != "09:00" || stored.TimeZone != "Europe/Oslo" {
+		t.Errorf("Expected the stored event to be unchanged, got %s in %q", stored.StartTime, stored.TimeZone)
+	}
+
+	for _, query := range []models.EventQuery{{}, {From: "2024-01-01", To: "2024-01-31"}} {
+		page, err := service.GetAllEvents(ctx, "oslo@example.com", query)
+		if err != nil {
+			t.Fatalf("Failed to get events: %v", err)
+		}
+		got := map[string]string{}
+		for _, item := range page.Items {
+			got[item.Title] = item.Date + " " + item.StartTime + "-" + item.EndTime
+		}
+		want := map[string]string{"Meeting": "2024-01-15 03:00-04:00", "Holiday": "2024-01-16 -", "Old meeting": "2024-01-17 09:00-10:00"}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Query %+v: expected %v, got %v", query, want, got)
+		}
+	}
+
+	// Saving the event as shown keeps the same moment in time.
+	if err := service.UpdateEvent(ctx, rendered); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if stored := eventRepo.Events[event.EventID]; !stored.StartAt.Equal(event.StartAt) || stored.TimeZone != "America/New_York" {
+		t.Errorf("Expected the updated event to start at %v in America/New_York, got %v in %q", event.StartAt, stored.StartAt, stored.TimeZone)
+	}
+
+	// An evening event moves to the next day for viewers further east.
+	late := &models.Event{Email: "ny@example.com", Title: "Call", Date: "2024-01-15", StartTime: "20:00", EndTime: "21:00", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, late); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	userRepo.Users["ny@example.com"].TimeZone = ""
+	if rendered, err := service.GetEvent(ctx, "ny@example.com", late.EventID); err != nil || rendered.Date != "2024-01-16" || rendered.StartTime != "02:00" {
+		t.Errorf("Expected the call on 2024-01-16 at 02:00 in Oslo, got %+v (err: %v)", rendered, err)
+	}
+}
+
+func TestEventService_TimeZones_RecurrenceAcrossDST(t *testing.T) {
+	service, _, userRepo := newTimeZoneService()
+	ctx := context.Background()
+
+	series := &models.Event{
+		Email: "oslo@example.com", Title: "Lecture", Date: "2024-03-24", StartTime: "10:15", EndTime: "12:00",
+		EventTypeID: "private", Recurrence: &models.Recurrence{Frequency: "weekly", Count: 3},
+	}
+	if err := service.CreateEvent(ctx, series); err != nil {
+		t.Fatalf("Failed to create series: %v", err)
+	}
+
+	listTimes := func() []string {
+		t.Helper()
+		page, err := service.GetAllEvents(ctx, "oslo@example.com", models.EventQuery{From: "2024-03-01", To: "2024-04-30"})
+		if err != nil {
+			t.Fatalf("Failed to get events: %v", err)
+		}
+		times := []string{}
+		for _, occurrence := range page.Items {
+			times = append(times, fmt.Sprintf("%s %s-%s %s", occurrence.Date, occurrence.StartTime, occurrence.EndTime, occurrence.StartAt.UTC().Format("15:04Z")))
+		}
+		return times
+	}
+
+	// Occurrences stay at 10:15 in Oslo, so they start an hour earlier in UTC after the clocks go forward.
+	assertDates(t, listTimes(),
+		"2024-03-24 10:15-12:00 09:15Z",
+		"2024-03-31 10:15-12:00 08:15Z",
+		"2024-04-07 10:15-12:00 08:15Z",
+	)
+
+	// New York changed its clocks two weeks earlier, so there the lecture moves an hour earlier.
+	userRepo.Users["oslo@example.com"].TimeZone = "America/New_York"
+	assertDates(t, listTimes(),
+		"2024-03-24 05:15-07:00 09:15Z",
+		"2024-03-31 04:15-06:00 08:15Z",
+		"2024-04-07 04:15-06:00 08:15Z",
+	)
+}
+
+func TestEventService_Status_Validation(t *testing.T) {
+	service := newRecurrenceService()
+	ctx := context.Background()
+
+	tests := []struct {
+		status, want string
+		wantErr      error
+	}{
+		{"", services.EventStatusConfirmed, nil},
+		{"Tentative", services.EventStatusTentative, nil},
+		{"confirmed", services.EventStatusConfirmed, nil},
+		{"cancelled", "", services.ErrInvalidEventStatus},
+		{"maybe", "", services.ErrInvalidEventStatus},
+	}
+	for _, test := range tests {
+		event := &models.Event{Email: "user@example.com", Title: "Lunch", Date: "2024-03-01", EventTypeID: "private", Status: test.status}
+		err := service.CreateEvent(ctx, event)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("Status %q: expected error %v, got %v", test.status, test.wantErr, err)
+			continue
+		}
+		if err == nil && event.Status != test.want {
+			t.Errorf("Status %q: expected %q, got %q", test.status, test.want, event.Status)
+		}
+	}
+
+	// An update without a status keeps the stored one.
+	event := &models.Event{Email: "user@example.com", Title: "Lunch", Date: "2024-03-01", EventTypeID: "private", Status: "tentative"}
+	if err := service.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	update := *event
+	update.Status = ""
+	update.Title = "Long lunch"
+	if err := service.UpdateEvent(ctx, &update); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if update.Status != services.EventStatusTentative {
+		t.Errorf("Expected the update to stay tentative, got %q", update.Status)
+	}
+}
+
+func TestEventService_CancelEvent(t *testing.T) {
+	f := newEventServiceFixture(t)
+	ctx := context.Background()
+
+	if err := f.service.InviteToEvent(ctx, "owner@example.com", f.eventID, "friend"); err != nil {
+		t.Fatalf("Invite failed: %v", err)
+	}
+	if err := f.service.RespondToInvitation(ctx, "friend@example.com", f.eventID, "accept"); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	f.invitationRepo.Invitations[f.eventID+"_stranger@example.com"] = &models.EventInvitation{
+		EventID: f.eventID, OwnerEmail: "owner@example.com", InviteeEmail: "stranger@example.com", Status: "pending",
+	}
+	accepted := f.hub.Subscribe("friend@example.com")
+	defer f.hub.Unsubscribe(accepted)
+	pending := f.hub.Subscribe("stranger@example.com")
+	defer f.hub.Unsubscribe(pending)
+
+	if err := f.service.CancelEvent(ctx, "friend@example.com", f.eventID); err == nil || err.Error() != "Event not found" {
+		t.Errorf("Expected an invitee to be unable to cancel the event, got %v", err)
+	}
+	if err := f.service.CancelEvent(ctx, "owner@example.com", f.eventID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+
+	event, err := f.service.GetEvent(ctx, "owner@example.com", f.eventID)
+	if err != nil {
+		t.Fatalf("Expected the cancelled event to be kept, got %v", err)
+	}
+	if event.Status != services.EventStatusCancelled || event.CancelledAt == nil {
+		t.Errorf("Expected the event to be cancelled with a time, got status %q and %v", event.Status, event.CancelledAt)
+	}
+
+	if len(accepted.Notifications) != 1 {
+		t.Fatalf("Expected 1 cancellation notification for the accepted invitee, got %d", len(accepted.Notifications))
+	}
+	notification := <-accepted.Notifications
+	if notification.Type != services.NotificationEventCancelled || notification.Payload["eventID"] != f.eventID || notification.Payload["username"] != "owner" {
+		t.Errorf("Expected an event_cancelled notification from owner, got %+v", notification)
+	}
+	if len(pending.Notifications) != 0 {
+		t.Errorf("Expected no notification for the pending invitee, got %d", len(pending.Notifications))
+	}
+
+	// A cancelled event is final.
+	event.Status = services.EventStatusConfirmed
+	if err := f.service.UpdateEvent(ctx, event); !errors.Is(err, services.ErrEventCancelled) {
+		t.Errorf("Expected cancelled -> confirmed to be rejected, got %v", err)
+	}
+	if err := f.service.CancelEvent(ctx, "owner@example.com", f.eventID); !errors.Is(err, services.ErrEventCancelled) {
+		t.Errorf("Expected a second cancel to be rejected, got %v", err)
+	}
+	if err := f.service.InviteToEvent(ctx, "owner@example.com", f.eventID, "friend"); !errors.Is(err, services.ErrEventCancelled) {
+		t.Errorf("Expected inviting to a cancelled event to be rejected, got %v", err)
+	}
+}
+
+func TestEventService_GetAllEvents_Cancelled(t *testing.T) {
+	f := newEventServiceFixture(t)
+	ctx := context.Background()
+
+	if err := f.service.InviteToEvent(ctx, "owner@example.com", f.eventID, "friend"); err != nil {
+		t.Fatalf("Invite failed: %v", err)
+	}
+	if err := f.service.RespondToInvitation(ctx, "friend@example.com", f.eventID, "accept"); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	series := &models.Event{
+		Email: "owner@example.com", Title: "Standup", Date: "2024-12-02", EventTypeID: "private",
+		Recurrence: &models.Recurrence{Frequency: "daily", Count: 3},
+	}
+	if err := f.service.CreateEvent(ctx, series); err != nil {
+		t.Fatalf("Failed to create series: %v", err)
+	}
+	for _, eventID := range []string{f.eventID, series.EventID} {
+		if err := f.service.CancelEvent(ctx, "owner@example.com", eventID); err != nil {
+			t.Fatalf("Cancel failed: %v", err)
+		}
+	}
+
+	window := models.EventQuery{From: "2024-12-01", To: "2024-12-31"}
+	tests := []struct {
+		name, user string
+		query      models.EventQuery
+		want       int
+	}{
+		{"owner", "owner@example.com", models.EventQuery{}, 0},
+		{"owner including cancelled", "owner@example.com", models.EventQuery{IncludeCancelled: true}, 2},
+		{"owner window", "owner@example.com", window, 0},
+		{"owner window including cancelled", "owner@example.com", models.EventQuery{From: window.From, To: window.To, IncludeCancelled: true}, 4},
+		{"invitee", "friend@example.com", models.EventQuery{}, 0},
+		{"invitee including cancelled", "friend@example.com", models.EventQuery{IncludeCancelled: true}, 1},
+	}
+	for _, test := range tests {
+		page, err := f.service.GetAllEvents(ctx, test.user, test.query)
+		if err != nil {
+			t.Fatalf("%s: failed to get events: %v", test.name, err)
+		}
+		if len(page.Items) != test.want {
+			t.Errorf("%s: expected %d events, got %d", test.name, test.want, len(page.Items))
+		}
+		for _, event := range page.Items {
+			if event.Status != services.EventStatusCancelled {
+				t.Errorf("%s: expected only cancelled events, got %q", test.name, event.Status)
+			}
+		}
+	}
+}
+
+// newPatchService creates an EventService over a mock event repository, which is returned for inspection.
+func newPatchService() (services.EventServiceInterface, *mocks.MockEventRepository) {
+	eventRepo := mocks.NewMockEventRepository()
+	service := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	return service, eventRepo
+}
+
+func TestEventService_PatchEvent(t *testing.T) {
+	service, eventRepo := newPatchService()
+	ctx := context.Background()
+	event := &models.Event{
+		Email: "user@example.com", Title: "Standup", Description: "Daily sync", EventTypeID: "private",
+		Date: "2024-05-06", StartTime: "09:00", EndTime: "09:15", Tags: []string{"work"}, ReminderMinutesBefore: 10,
+	}
+	if err := service.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	// Only the title is sent, as by a client that does not echo the other fields.
+	patched, err := service.PatchEvent(ctx, "user@example.com", event.EventID, map[string]json.RawMessage{"title": json.RawMessage(`"Retro"`)})
+	if err != nil {
+		t.Fatalf("Failed to patch event: %v", err)
+	}
+	stored := eventRepo.Events[event.EventID]
+	if patched.Title != "Retro" || stored.Title != "Retro" {
+		t.Errorf("Expected the title to be updated, got %q and stored %q", patched.Title, stored.Title)
+	}
+	if stored.Description != "Daily sync" || stored.StartTime != "09:00" || stored.EndTime != "09:15" ||
+		fmt.Sprint(stored.Tags) != "[work]" || stored.ReminderMinutesBefore != 10 || !stored.StartAt.Equal(event.StartAt) {
+		t.Errorf("Expected the fields left out of the patch to survive, got %+v", stored)
+	}
+
+	// Moving the start keeps the end time, and null clears a field.
+	_, err = service.PatchEvent(ctx, "user@example.com", event.EventID, map[string]json.RawMessage{
+		"startTime":   json.RawMessage(`"08:45"`),
+		"description": json.RawMessage(`null`),
+	})
+	if err != nil {
+		t.Fatalf("Failed to patch event: %v", err)
+	}
+	stored = eventRepo.Events[event.EventID]
+	if stored.StartTime != "08:45" || !stored.StartAt.Equal(event.StartAt.Add(-15*time.Minute)) || stored.EndTime != "09:15" || stored.Description != "" {
+		t.Errorf("Expected the start to move to 08:45 and the description to be cleared, got %+v", stored)
+	}
+
+	// Sending the current email and ID is allowed.
+	_, err = service.PatchEvent(ctx, "user@example.com", event.EventID, map[string]json.RawMessage{
+		"email":   json.RawMessage(`"user@example.com"`),
+		"eventID": json.RawMessage(fmt.Sprintf("%q", event.EventID)),
+	})
+	if err != nil {
+		t.Errorf("Expected the current email and ID to be accepted, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr error
+	}{
+		{"change email", `{"email": "other@example.com"}`, services.ErrImmutableEventField},
+		{"change ID", `{"eventID": "event99", "title": "Moved"}`, services.ErrImmutableEventField},
+		{"unknown field", `{"colour": "red"}`, services.ErrUnknownEventField},
+		{"wrong type", `{"title": 5}`, services.ErrInvalidEventField},
+		{"cleared title", `{"title": null}`, nil},
+		{"end before start", `{"endTime": "08:00"}`, nil},
+		{"invalid status", `{"status": "cancelled"}`, services.ErrInvalidEventStatus},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(test.patch), &patch); err != nil {
+				t.Fatalf("Invalid test patch: %v", err)
+			}
+			_, err := service.PatchEvent(ctx, "user@example.com", event.EventID, patch)
+			if err == nil || (test.wantErr != nil && !errors.Is(err, test.wantErr)) {
+				t.Errorf("Expected error %v, got %v", test.wantErr, err)
+			}
+			if eventRepo.Events[event.EventID].Title != "Retro" {
+				t.Errorf("Expected the rejected patch not to be stored, got %+v", eventRepo.Events[event.EventID])
+			}
+		})
+	}
+}
+
+func TestEventService_UpdateEvent_Ownership(t *testing.T) {
+	service, eventRepo := newPatchService()
+	ctx := context.Background()
+	event := &models.Event{Email: "alice@example.com", Title: "Dentist", Date: "2024-05-06", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	// Bob can neither replace nor patch alice's event.
+	err := service.UpdateEvent(ctx, &models.Event{Email: "bob@example.com", EventID: event.EventID, Title: "Mine now", Date: "2024-05-06", EventTypeID: "private"})
+	if err == nil || err.Error() != "Event not found" {
+		t.Errorf("Expected replacing another user's event to fail with 'Event not found', got %v", err)
+	}
+	_, err = service.PatchEvent(ctx, "bob@example.com", event.EventID, map[string]json.RawMessage{"title": json.RawMessage(`"Mine now"`)})
+	if err == nil || err.Error() != "Event not found" {
+		t.Errorf("Expected patching another user's event to fail with 'Event not found', got %v", err)
+	}
+	if stored := eventRepo.Events[event.EventID]; stored.Email != "alice@example.com" || stored.Title != "Dentist" {
+		t.Errorf("Expected alice's event to be unchanged, got %+v", stored)
+	}
+
+	// A full update does not create an event that does not exist.
+	err = service.UpdateEvent(ctx, &models.Event{Email: "alice@example.com", EventID: "missing", Title: "Ghost", Date: "2024-05-06", EventTypeID: "private"})
+	if err == nil || err.Error() != "Event not found" {
+		t.Errorf("Expected updating a missing event to fail with 'Event not found', got %v", err)
+	}
+	if len(eventRepo.Events) != 1 {
+		t.Errorf("Expected no event to be created, got %d events", len(eventRepo.Events))
+	}
+
+	// An outage is not reported as a missing event.
+	eventRepo.Err = repositories.ErrUnavailable
+	_, err = service.PatchEvent(ctx, "alice@example.com", event.EventID, map[string]json.RawMessage{"title": json.RawMessage(`"Orthodontist"`)})
+	if !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestEventService_SearchEvents(t *testing.T) {
+	service := newRecurrenceService()
+	ctx := context.Background()
+	events := []*models.Event{
+		{Title: "Dentist", Date: "2024-03-14", StartTime: "14:00"},
+		{Title: "Lunch", Description: "Before the dentist", Date: "2024-03-14", StartTime: "12:00"},
+		{Title: "Checkup", StreetAddress: "Dentistveien 4", Date: "2024-02-01", StartTime: "08:00"},
+		{Title: "Dentist appointment", Description: "Ask the dentist about X-rays", Date: "2024-05-02", StartTime: "09:00"},
+		{Title: "Dentist", Date: "2025-06-01", StartTime: "09:00"},
+		{Title: "Gym", Date: "2024-03-15", StartTime: "18:00"},
+	}
+	for _, event := range events {
+		event.Email, event.EventTypeID = "user@example.com", "private"
+		if err := service.CreateEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+	createSeries(t, service, "2024-03-04", models.Recurrence{Frequency: "weekly", Count: 3})
+	other := &models.Event{Email: "other@example.com", Title: "Dentist", Date: "2024-03-14", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, other); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	results, err := service.SearchEvents(ctx, "user@example.com", "  DENTIST ", "2024-01-01", "2024-12-31")
+	if err != nil {
+		t.Fatalf("Failed to search events: %v", err)
+	}
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Date+" "+result.StartTime+" "+result.MatchedField)
+	}
+	want := []string{
+		"2024-02-01 08:00 streetAddress",
+		"2024-03-14 12:00 description",
+		"2024-03-14 14:00 title",
+		"2024-05-02 09:00 title", // Matches the title and the description.
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Occurrences of recurring events are searched one by one.
+	results, err = service.SearchEvents(ctx, "user@example.com", "lecture", "2024-03-01", "2024-03-12")
+	if err != nil || len(results) != 2 || results[0].Date != "2024-03-04" || results[1].Date != "2024-03-11" {
+		t.Errorf("Expected the two occurrences within the window, got %+v (err: %v)", results, err)
+	}
+
+	results, err = service.SearchEvents(ctx, "user@example.com", "orthodontist", "2024-01-01", "2024-12-31")
+	if err != nil || results == nil || len(results) != 0 {
+		t.Errorf("Expected an empty list for a query that matches nothing, got %v (err: %v)", results, err)
+	}
+
+	invalid := [][3]string{
+		{"", "2024-01-01", "2024-12-31"},
+		{"dentist", "", "2024-12-31"},
+		{"dentist", "2024-01-01", ""},
+		{"dentist", "01/01/2024", "2024-12-31"},
+		{"dentist", "2024-12-31", "2024-01-01"},
+		{"dentist", "2024-01-01", "2025-01-02"},
+	}
+	for _, params := range invalid {
+		if _, err := service.SearchEvents(ctx, "user@example.com", params[0], params[1], params[2]); err == nil {
+			t.Errorf("Expected q %q, from %q and to %q to be rejected", params[0], params[1], params[2])
+		}
+	}
+	if _, err := service.SearchEvents(ctx, "user@example.com", "dentist", "2024-01-01", "2025-01-01"); err != nil {
+		t.Errorf("Expected a window of exactly one year to be accepted, got %v", err)
+	}
+}
+
+func TestEventService_DateWindow(t *testing.T) {
+	ctx := context.Background()
+	service := newRecurrenceService().(*services.EventService)
+	service.Now = func() time.Time { return time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC) }
+
+	tests := map[string]string{
+		"2014-06-15": "",
+		"2034-06-15": "",
+		"2014-06-14": "Invalid input: date must be within 10 years of today",
+		"2034-06-16": "Invalid input: date must be within 10 years of today",
+		"2024/06/15": "Invalid input: date must be a date in YYYY-MM-DD format",
+	}
+	for date, wantErr := range tests {
+		event := &models.Event{Email: "user@example.com", Title: "Reunion", Date: date, EventTypeID: "private"}
+		err := service.CreateEvent(ctx, event)
+		if (err == nil && wantErr != "") || (err != nil && err.Error() != wantErr) {
+			t.Errorf("%s: expected error %q, got %v", date, wantErr, err)
+		}
+	}
+
+	// Updates are held to the same window.
+	event := &models.Event{Email: "user@example.com", Title: "Reunion", Date: "2024-07-01", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	event.Date = "2099-07-01"
+	if err := service.UpdateEvent(ctx, event); err == nil || err.Error() != "Invalid input: date must be within 10 years of today" {
+		t.Errorf("Expected the update to be rejected, got %v", err)
+	}
+
+	// Query ranges are only checked for their format and order, not the window.
+	if _, err := service.GetAllEvents(ctx, "user@example.com", models.EventQuery{From: "2000-01-01", To: "2099-12-31"}); err != nil {
+		t.Errorf("Expected a wide listing range to be accepted, got %v", err)
+	}
+	_, err := service.GetAllEvents(ctx, "user@example.com", models.EventQuery{From: "2024-07-01", To: "2024-06-01"})
+	if dateErr, ok := dates.AsError(err); !ok || dateErr.Field != "from" || dateErr.Reason != dates.ReasonAfterTo {
+		t.Errorf("Expected an inverted range to be rejected on from, got %v", err)
+	}
+}
+
+func TestEventService_CalendarFields_Validation(t *testing.T) {
+	ctx := context.Background()
+	service := newRecurrenceService()
+
+	tests := []struct {
+		name      string
+		color     string
+		allDay    bool
+		startTime string
+		endTime   string
+		wantColor string
+		wantErr   error
+	}{
+		{"no color", "", false, "10:00", "", "", nil},
+		{"hex color", "#1a2B3c", false, "10:00", "11:00", "#1a2b3c", nil},
+		{"palette color", " Blue ", false, "10:00", "", "blue", nil},
+		{"short hex", "#abc", false, "10:00", "", "", services.ErrInvalidEventColor},
+		{"hex without hash", "1a2b3c", false, "10:00", "", "", services.ErrInvalidEventColor},
+		{"non-hex digits", "#12345g", false, "10:00", "", "", services.ErrInvalidEventColor},
+		{"unknown name", "magenta", false, "10:00", "", "", services.ErrInvalidEventColor},
+		{"all-day", "green", true, "", "", "green", nil},
+		{"all-day with start time", "", true, "10:00", "", "", services.ErrAllDayEventTimes},
+		{"all-day with end time", "", true, "", "11:00", "", services.ErrAllDayEventTimes},
+		{"all-day with both times", "", true, "10:00", "11:00", "", services.ErrAllDayEventTimes},
+	}
+	for _, tt := range tests {
+		event := &models.Event{Email: "user@example.com", Title: "Planning", Date: "2024-03-14", EventTypeID: "private",
+			Color: tt.color, AllDay: tt.allDay, StartTime: tt.startTime, EndTime: tt.endTime}
+		err := service.CreateEvent(ctx, event)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+			continue
+		}
+		if err == nil && event.Color != tt.wantColor {
+			t.Errorf("%s: expected color %q, got %q", tt.name, tt.wantColor, event.Color)
+		}
+	}
+
+	// Updates are validated the same way.
+	event := &models.Event{Email: "user@example.com", Title: "Planning", Date: "2024-03-14", StartTime: "10:00", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	event.AllDay = true
+	if err := service.UpdateEvent(ctx, event); !errors.Is(err, services.ErrAllDayEventTimes) {
+		t.Errorf("Expected a timed update to all-day to be rejected, got %v", err)
+	}
+	event.StartTime, event.Color = "", "#FF8800"
+	if err := service.UpdateEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to update the event to all-day: %v", err)
+	}
+	saved, _ := service.GetEvent(ctx, "user@example.com", event.EventID)
+	if !saved.AllDay || saved.StartTime != "" || saved.Color != "#ff8800" {
+		t.Errorf("Expected an all-day event colored #ff8800, got %+v", saved)
+	}
+	if _, err := service.PatchEvent(ctx, "user@example.com", event.EventID, map[string]json.RawMessage{"color": json.RawMessage(`"plaid"`)}); !errors.Is(err, services.ErrInvalidEventColor) {
+		t.Errorf("Expected an invalid color patch to be rejected, got %v", err)
+	}
+}
+
+func TestEventService_GetEventMonth(t *testing.T) {
+	ctx := context.Background()
+	service := newRecurrenceService()
+	for _, event := range []*models.Event{
+		{Title: "Dentist", Date: "2024-03-14", StartTime: "14:00", Color: "red"},
+		{Title: "Breakfast", Date: "2024-03-14", StartTime: "08:00", Color: "blue"},
+		{Title: "Holiday", Date: "2024-03-14", AllDay: true, Color: "blue"},
+		{Title: "Gym", Date: "2024-03-15", StartTime: "18:00"},
+		{Title: "Before the grid", Date: "2024-02-25", StartTime: "12:00"},
+		{Title: "In the grid", Date: "2024-03-31", StartTime: "12:00", Color: "#00ff00"},
+		{Title: "After the grid", Date: "2024-04-01", StartTime: "12:00"},
+	} {
+		event.Email, event.EventTypeID = "user@example.com", "private"
+		if err := service.CreateEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to create event %q: %v", event.Title, err)
+		}
+	}
+	// The series on Mondays from 2024-02-26 shows up in each week of the March grid.
+	createSeries(t, service, "2024-02-26", models.Recurrence{Frequency: "weekly"})
+	cancelled := &models.Event{Email: "user@example.com", Title: "Cancelled", Date: "2024-03-15", StartTime: "07:00", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, cancelled); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if err := service.CancelEvent(ctx, "user@example.com", cancelled.EventID); err != nil {
+		t.Fatalf("Failed to cancel event: %v", err)
+	}
+	other := &models.Event{Email: "other@example.com", Title: "Not mine", Date: "2024-03-14", EventTypeID: "private"}
+	if err := service.CreateEvent(ctx, other); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	month, err := service.GetEventMonth(ctx, "user@example.com", "2024-03")
+	if err != nil {
+		t.Fatalf("Failed to summarize month: %v", err)
+	}
+	// March 2024 starts on a Friday and ends on a Sunday: 2024-02-26 to 2024-03-31.
+	if month.Month != "2024-03" || month.From != "2024-02-26" || month.To != "2024-03-31" || month.Weeks != 5 {
+		t.Errorf("Expected the grid 2024-02-26 to 2024-03-31 of 5 weeks, got %s to %s of %d", month.From, month.To, month.Weeks)
+	}
+	var got []string
+	for _, day := range month.Days {
+		got = append(got, fmt.Sprintf("%s %d %s %v", day.Date, day.Count, day.FirstTitle, day.Colors))
+	}
+	want := []string{
+		"2024-02-26 1 Weekly lecture []",
+		"2024-03-04 1 Weekly lecture []",
+		"2024-03-11 1 Weekly lecture []",
+		"2024-03-14 3 Holiday [blue red]",
+		"2024-03-15 1 Gym []",
+		"2024-03-18 1 Weekly lecture []",
+		"2024-03-25 1 Weekly lecture []",
+		"2024-03-31 1 In the grid [#00ff00]",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected days:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	// Grids are 4 to 6 weeks long, depending on the weekdays of the first and last day.
+	grids := []struct {
+		month, from, to string
+		weeks           int
+	}{
+		{"2021-02", "2021-02-01", "2021-02-28", 4}, // Starts on a Monday and has exactly 28 days.
+		{"2024-02", "2024-01-29", "2024-03-03", 5}, // A leap month from Thursday to Thursday.
+		{"2024-09", "2024-08-26", "2024-10-06", 6}, // Starts on a Sunday and ends on a Monday.
+		{"2023-12", "2023-11-27", "2023-12-31", 5}, // Ends on a Sunday, across the new year.
+	}
+	for _, grid := range grids {
+		month, err := service.GetEventMonth(ctx, "user@example.com", grid.month)
+		if err != nil {
+			t.Fatalf("%s: failed to summarize month: %v", grid.month, err)
+		}
+		if month.From != grid.from || month.To != grid.to || month.Weeks != grid.weeks {
+			t.Errorf("%s: expected %s to %s of %d weeks, got %s to %s of %d", grid.month, grid.from, grid.to, grid.weeks, month.From, month.To, month.Weeks)
+		}
+	}
+
+	empty, err := service.GetEventMonth(ctx, "user@example.com", "2021-02")
+	if err != nil || empty.Days == nil || len(empty.Days) != 0 {
+		t.Errorf("Expected an empty list of days, got %+v (err: %v)", empty, err)
+	}
+	for _, invalid := range []string{"", "2024-3", "2024-13", "2024-03-01", "March"} {
+		_, err := service.GetEventMonth(ctx, "user@example.com", invalid)
+		if dateErr, ok := dates.AsError(err); !ok || dateErr.Field != "month" || dateErr.Reason != dates.ReasonMonth {
+			t.Errorf("%q: expected an invalid month error, got %v", invalid, err)
+		}
+	}
+}
+
+func TestEventService_ShareEvent(t *testing.T) {
+	f := newEventServiceFixture(t)
+	ctx := context.Background()
+	service := f.service.(*services.EventService)
+
+	if _, err := service.ShareEvent(ctx, "owner@example.com", f.eventID); !errors.Is(err, services.ErrEventSharingDisabled) {
+		t.Fatalf("Expected sharing to be disabled without a repository, got %v", err)
+	}
+
+	shareRepo := mocks.NewMockShareRepository()
+	service.ShareRepo = shareRepo
+	service.AppURL = "https://dailyverse.app"
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	service.Now = func() time.Time { return now }
+
+	if _, err := service.ShareEvent(ctx, "friend@example.com", f.eventID); err == nil || err.Error() != "Event not found" {
+		t.Errorf("Expected another user to be unable to share the event, got %v", err)
+	}
+	link, err := service.ShareEvent(ctx, "owner@example.com", f.eventID)
+	if err != nil {
+		t.Fatalf("Share failed: %v", err)
+	}
+	if len(link.Token) != 64 || link.URL != "https://dailyverse.app/shared/events/"+link.Token || !link.ExpiresAt.Equal(now.Add(services.EventShareLifetime)) {
+		t.Errorf("Expected a 64-character token, its URL and an expiry in 30 days, got %+v", link)
+	}
+
+	shared, err := service.GetSharedEvent(ctx, link.Token)
+	if err != nil {
+		t.Fatalf("Expected the shared event, got %v", err)
+	}
+	if shared.Title != "Dinner" || shared.Date != "2024-12-01" {
+		t.Errorf("Expected the title and date of the event, got %+v", shared)
+	}
+	body, _ := json.Marshal(shared)
+	if strings.Contains(string(body), "owner@example.com") || strings.Contains(string(body), f.eventID) {
+		t.Errorf("Expected the shared event to leave out the owner's email and the event ID, got %s", body)
+	}
+
+	// Sharing again replaces the link.
+	second, err := service.ShareEvent(ctx, "owner@example.com", f.eventID)
+	if err != nil || second.Token == link.Token {
+		t.Fatalf("Expected a new token, got %+v (err: %v)", second, err)
+	}
+	if _, err := service.GetSharedEvent(ctx, link.Token); !errors.Is(err, services.ErrEventShareNotFound) {
+		t.Errorf("Expected the replaced link to stop working, got %v", err)
+	}
+	if _, err := service.GetSharedEvent(ctx, "unknown"); !errors.Is(err, services.ErrEventShareNotFound) {
+		t.Errorf("Expected an unknown token to be not found, got %v", err)
+	}
+
+	// The link works until it expires.
+	now = now.Add(services.EventShareLifetime - time.Second)
+	if _, err := service.GetSharedEvent(ctx, second.Token); err != nil {
+		t.Errorf("Expected the link to work until it expires, got %v", err)
+	}
+	now = now.Add(time.Second)
+	if _, err := service.GetSharedEvent(ctx, second.Token); !errors.Is(err, services.ErrEventShareExpired) {
+		t.Errorf("Expected the link to have expired, got %v", err)
+	}
+
+	// Revoking deletes the link; only the owner can revoke it.
+	third, err := service.ShareEvent(ctx, "owner@example.com", f.eventID)
+	if err != nil {
+		t.Fatalf("Share failed: %v", err)
+	}
+	if err := service.RevokeEventShare(ctx, "friend@example.com", f.eventID); err == nil || err.Error() != "Event not found" {
+		t.Errorf("Expected another user to be unable to revoke the link, got %v", err)
+	}
+	if err := service.RevokeEventShare(ctx, "owner@example.com", f.eventID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, err := service.GetSharedEvent(ctx, third.Token); !errors.Is(err, services.ErrEventShareNotFound) {
+		t.Errorf("Expected the revoked link to stop working, got %v", err)
+	}
+
+	// Links to a deleted event are unknown, and repository failures are passed on.
+	fourth, _ := service.ShareEvent(ctx, "owner@example.com", f.eventID)
+	if err := service.DeleteEvent(ctx, "owner@example.com", f.eventID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := service.GetSharedEvent(ctx, fourth.Token); !errors.Is(err, services.ErrEventShareNotFound) {
+		t.Errorf("Expected a link to a deleted event to be not found, got %v", err)
+	}
+	shareRepo.Err = repositories.ErrUnavailable
+	if _, err := service.GetSharedEvent(ctx, fourth.Token); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected the repository failure, got %v", err)
+	}
+}
+
+// newFriendEventsService creates a service where alice is friends with bob and carol, but not with
+// mallory. Bob has a private, a public, a cancelled public and a weekly public event, and carol a
+// public event. The clock is set to 2024-11-28 12:00 UTC.
+func newFriendEventsService(t *testing.T) (*services.EventService, *mocks.MockEventRepository, *mocks.MockFriendRepository) {
+	t.Helper()
+	users := map[string]*models.User{
+		"alice@example.com":   {Email: "alice@example.com", Username: "alice"},
+		"bob@example.com":     {Email: "bob@example.com", Username: "bob"},
+		"carol@example.com":   {Email: "carol@example.com", Username: "carol"},
+		"mallory@example.com": {Email: "mallory@example.com", Username: "mallory"},
+	}
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		repositories.CompositeID("alice@example.com", "bob@example.com"):   {Email: "alice@example.com", FriendEmail: "bob@example.com", Status: "accepted"},
+		repositories.CompositeID("carol@example.com", "alice@example.com"): {Email: "carol@example.com", FriendEmail: "alice@example.com", Status: "accepted"},
+		repositories.CompositeID("mallory@example.com", "bob@example.com"): {Email: "mallory@example.com", FriendEmail: "bob@example.com", Status: "pending"},
+	})
+	eventRepo := mocks.NewMockEventRepository()
+	service := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(users), friendRepo, nil, nil, nil).(*services.EventService)
+	service.Now = func() time.Time { return time.Date(2024, 11, 28, 12, 0, 0, 0, time.UTC) }
+
+	ctx := context.Background()
+	events := []*models.Event{
+		{Email: "bob@example.com", Title: "Doctor", Date: "2024-12-01", StartTime: "09:00", EventTypeID: "private"},
+		{Email: "bob@example.com", Title: "Party", Date: "2024-12-02", StartTime: "20:00", EventTypeID: "public"},
+		{Email: "bob@example.com", Title: "Concert", Date: "2024-12-03", EventTypeID: "public"},
+		{Email: "bob@example.com", Title: "Football", Date: "2024-11-20", StartTime: "18:00", EventTypeID: "public", Recurrence: &models.Recurrence{Frequency: "weekly"}},
+		{Email: "bob@example.com", Title: "Therapy", Date: "2024-11-21", EventTypeID: "private", Recurrence: &models.Recurrence{Frequency: "weekly"}},
+		{Email: "carol@example.com", Title: "Brunch", Date: "2024-11-30", StartTime: "11:00", EventTypeID: "public"},
+		{Email: "carol@example.com", Title: "Far away", Date: "2025-01-30", EventTypeID: "public"},
+	}
+	for _, event := range events {
+		if err := service.CreateEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to create %s: %v", event.Title, err)
+		}
+	}
+	if err := service.CancelEvent(ctx, "bob@example.com", events[2].EventID); err != nil {
+		t.Fatalf("Failed to cancel the concert: %v", err)
+	}
+	return service, eventRepo, friendRepo
+}
+
+// friendEventTitles returns "date title (username)" for each event.
+func friendEventTitles(events []models.FriendEvent) []string {
+	titles := []string{}
+	for _, event := range events {
+		titles = append(titles, fmt.Sprintf("%s %s (%s)", event.Date, event.Title, event.Username))
+	}
+	return titles
+}
+
+func TestEventService_GetFriendEvents(t *testing.T) {
+	service, eventRepo, _ := newFriendEventsService(t)
+	ctx := context.Background()
+
+	events, err := service.GetFriendEvents(ctx, "alice@example.com", "bob", "2024-11-25", "2024-12-10")
+	if err != nil {
+		t.Fatalf("Failed to list bob's events: %v", err)
+	}
+	want := []string{"2024-11-27 Football (bob)", "2024-12-02 Party (bob)", "2024-12-04 Football (bob)"}
+	if got := friendEventTitles(events); strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected only bob's public, uncancelled events %q, got %q", want, got)
+	}
+	body, _ := json.Marshal(events)
+	for _, leak := range []string{"bob@example.com", "Doctor", "Therapy", "Concert", "private"} {
+		if strings.Contains(string(body), leak) {
+			t.Errorf("Expected %q not to be shown to friends, got %s", leak, body)
+		}
+	}
+
+	// Without dates, the next FriendFeedDays days are listed.
+	events, err = service.GetFriendEvents(ctx, "alice@example.com", "carol", "", "")
+	if err != nil {
+		t.Fatalf("Failed to list carol's events: %v", err)
+	}
+	if got := friendEventTitles(events); len(got) != 1 || got[0] != "2024-11-30 Brunch (carol)" {
+		t.Errorf("Expected carol's brunch only, got %q", got)
+	}
+
+	tests := []struct {
+		name      string
+		userEmail string
+		username  string
+		from, to  string
+		wantErr   string
+	}{
+		{"NotFriends", "mallory@example.com", "bob", "", "", services.ErrNotFriends.Error()},
+		{"NotFriendsOfFriend", "carol@example.com", "bob", "", "", services.ErrNotFriends.Error()},
+		{"Self", "bob@example.com", "bob", "", "", services.ErrNotFriends.Error()},
+		{"UnknownUser", "alice@example.com", "nobody", "", "", "User not found"},
+		{"RangeTooLong", "alice@example.com", "bob", "2024-01-01", "2025-06-01", "Date range must not exceed one year"},
+	}
+	for _, tt := range tests {
+		if _, err := service.GetFriendEvents(ctx, tt.userEmail, tt.username, tt.from, tt.to); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+	if _, err := service.GetFriendEvents(ctx, "alice@example.com", "bob", "2024-12-10", "2024-12-01"); err == nil {
+		t.Errorf("Expected a reversed range to be rejected")
+	}
+
+	eventRepo.Err = repositories.ErrUnavailable
+	if _, err := service.GetFriendEvents(ctx, "alice@example.com", "bob", "", ""); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected the repository failure, got %v", err)
+	}
+}
+
+func TestEventService_GetFriendsEventFeed(t *testing.T) {
+	service, eventRepo, friendRepo := newFriendEventsService(t)
+	ctx := context.Background()
+
+	events, err := service.GetFriendsEventFeed(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("Failed to read the feed: %v", err)
+	}
+	want := []string{"2024-11-30 Brunch (carol)", "2024-12-02 Party (bob)", "2024-12-04 Football (bob)", "2024-12-11 Football (bob)"}
+	if got := friendEventTitles(events); strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if events, err := service.GetFriendsEventFeed(ctx, "mallory@example.com"); err != nil || len(events) != 0 {
+		t.Errorf("Expected an empty feed without friends, got %v (err: %v)", events, err)
+	}
+
+	// Many friends are all read, whatever order their events are read in.
+	userRepo := service.UserRepo.(*mocks.MockUserRepository)
+	for i := 0; i < 30; i++ {
+		email := fmt.Sprintf("friend%02d@example.com", i)
+		userRepo.Users[email] = &models.User{Email: email, Username: fmt.Sprintf("friend%02d", i)}
+		friendRepo.Friends[repositories.CompositeID(email, "mallory@example.com")] = &models.Friend{Email: email, FriendEmail: "mallory@example.com", Status: "accepted"}
+		event := &models.Event{Email: email, Title: "Meetup", Date: fmt.Sprintf("2024-12-%02d", 1+i%10), EventTypeID: "public"}
+		if err := service.CreateEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+	events, err = service.GetFriendsEventFeed(ctx, "mallory@example.com")
+	if err != nil || len(events) != 30 {
+		t.Fatalf("Expected the events of all 30 friends, got %d (err: %v)", len(events), err)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Date < events[i-1].Date {
+			t.Errorf("Expected the feed in date order, got %s after %s", events[i].Date, events[i-1].Date)
+		}
+	}
+
+	eventRepo.Err = repositories.ErrUnavailable
+	if _, err := service.GetFriendsEventFeed(ctx, "mallory@example.com"); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected a failed friend to fail the feed, got %v", err)
+	}
+	eventRepo.Err = nil
+	friendRepo.Err = repositories.ErrUnavailable
+	if _, err := service.GetFriendsEventFeed(ctx, "alice@example.com"); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected the friend repository failure, got %v", err)
+	}
+}
+
+func TestEventService_DuplicateEvent(t *testing.T) {
+	eventRepo := mocks.NewMockEventRepository()
+	service := services.NewEventService(eventRepo, mocks.NewMockInvitationRepository(), mocks.NewMockUserRepository(map[string]*models.User{}), mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	ctx := context.Background()
+
+	source := &models.Event{Email: "user@example.com", Title: "Standup", Date: "2024-05-01", StartTime: "09:00", EndTime: "09:15", EventTypeID: "private", Tags: []string{"work"}}
+	if err := service.CreateEvent(ctx, source); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	eventRepo.Events[source.EventID].ReminderSent = true
+
+	// Copies are made a week apart, with new IDs and without the sent reminder.
+	eventIDs, err := service.DuplicateEvent(ctx, "user@example.com", source.EventID, "2024-06-03", 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(eventIDs) != 3 {
+		t.Fatalf("Expected 3 copies, got %v", eventIDs)
+	}
+	for i, want := range []string{"2024-06-03", "2024-06-10", "2024-06-17"} {
+		stored := eventRepo.Events[eventIDs[i]]
+		if stored == nil || eventIDs[i] == source.EventID {
+			t.Fatalf("Expected copy %d to be stored under a new ID, got %s", i, eventIDs[i])
+		}
+		if stored.Date != want || stored.StartTime != "09:00" || stored.Title != "Standup" || stored.Email != "user@example.com" {
+			t.Errorf("Expected copy %d on %s at 09:00, got %+v", i, want, stored)
+		}
+		if stored.ReminderSent {
+			t.Errorf("Expected copy %d not to have a sent reminder", i)
+		}
+	}
+
+	// A count of 0 makes a single copy.
+	if eventIDs, err := service.DuplicateEvent(ctx, "user@example.com", source.EventID, "2024-07-01", 0); err != nil || len(eventIDs) != 1 {
+		t.Errorf("Expected one copy for a count of 0, got %v (err: %v)", eventIDs, err)
+	}
+
+	// Only the user's own events can be copied.
+	if _, err := service.DuplicateEvent(ctx, "other@example.com", source.EventID, "2024-06-03", 1); err == nil || err.Error() != "Event not found" {
+		t.Errorf("Expected another user's event to be not found, got %v", err)
+	}
+	if _, err := service.DuplicateEvent(ctx, "user@example.com", "missing", "2024-06-03", 1); err == nil || err.Error() != "Event not found" {
+		t.Errorf("Expected a missing event to be not found, got %v", err)
+	}
+
+	// Counts over the cap and invalid dates create nothing.
+	before := len(eventRepo.Events)
+	var fieldErrs validate.Errors
+	if _, err := service.DuplicateEvent(ctx, "user@example.com", source.EventID, "2024-06-03", services.MaxEventCopies+1); !errors.As(err, &fieldErrs) || fieldErrs["count"] == "" {
+		t.Errorf("Expected a count error above %d copies, got %v", services.MaxEventCopies, err)
+	}
+	if _, err := service.DuplicateEvent(ctx, "user@example.com", source.EventID, "03.06.2024", 2); err == nil {
+		t.Error("Expected an invalid date to be rejected")
+	}
+	if len(eventRepo.Events) != before {
+		t.Errorf("Expected rejected requests to create nothing, got %d new events", len(eventRepo.Events)-before)
+	}
+	if eventIDs, err := service.DuplicateEvent(ctx, "user@example.com", source.EventID, "2024-06-03", services.MaxEventCopies); err != nil || len(eventIDs) != services.MaxEventCopies {
+		t.Errorf("Expected %d copies at the cap, got %d (err: %v)", services.MaxEventCopies, len(eventIDs), err)
+	}
+}
@@ -0,0 +1,60 @@
+/**
+ *  Firestore Client Tests check the errors NewFirestoreClient returns when the database is not
+ *  configured or cannot be reached. The happy path runs against the emulator in tests/integration.
+ *
+ *  @file       firestore_client_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestNewFirestoreClient_MissingProjectID - Tests the error names the FIRESTORE_PROJECT_ID variable.
+ *  - TestNewFirestoreClient_Unreachable      - Tests the error names the project ID and the emulator being used.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+)
+
+func TestNewFirestoreClient_MissingProjectID(t *testing.T) {
+	client, err := services.NewFirestoreClient(context.Background(), "")
+	if client != nil {
+		t.Errorf("Expected no client without a project ID")
+	}
+	if !errors.Is(err, services.ErrFirestoreProjectIDMissing) {
+		t.Fatalf("Expected ErrFirestoreProjectIDMissing, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "FIRESTORE_PROJECT_ID") {
+		t.Errorf("Expected the error to name FIRESTORE_PROJECT_ID, got %q", err)
+	}
+}
+
+func TestNewFirestoreClient_Unreachable(t *testing.T) {
+	// Nothing listens on port 1, so the connectivity check fails.
+	t.Setenv("FIRESTORE_EMULATOR_HOST", "127.0.0.1:1")
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	client, err := services.NewFirestoreClient(ctx, "dailyverse-offline")
+	if err == nil {
+		client.Close()
+		t.Fatalf("Expected an error when the database cannot be reached")
+	}
+	for _, want := range []string{`"dailyverse-offline"`, "the emulator at 127.0.0.1:1"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %s, got %q", want, err)
+		}
+	}
+}
@@ -0,0 +1,958 @@
+/**
+ *  FriendService Tests validate the friend request notification emails sent by FriendService.
+ *  They use mock repositories and a mock EmailService so no emails are actually sent.
+ *
+ *  @file       friend_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestFriendService_SendFriendRequest_NotifiesRecipient - Tests that the recipient is emailed.
+ *  - TestFriendService_SendFriendRequest_EmailCasing       - Tests requests and statuses for upper-cased and padded emails.
+ *  - TestFriendService_AcceptFriendRequest_NotifiesSender  - Tests that the original sender is emailed.
+ *  - TestFriendService_AcceptFriendRequest_NotPending      - Tests that cancelled or answered requests cannot be accepted.
+ *  - TestFriendService_NotificationInbox                   - Tests that requests and acceptances are stored in the inbox.
+ *  - TestFriendService_SendFriendRequest_Crossing          - Tests that a request to a user who already sent one accepts theirs.
+ *  - TestFriendService_LegacyDuplicateRequests             - Tests the cleanup of legacy requests in both directions.
+ *  - TestFriendService_NotificationsDisabled               - Tests that disabled notifications suppress emails.
+ *  - TestFriendService_EmailFailureDoesNotFail             - Tests that email failures do not fail the request.
+ *  - TestFriendService_BlockUser_RejectsFriendRequests     - Tests that requests are rejected in both directions after a block.
+ *  - TestFriendService_BlockUser_RemovesFriendship         - Tests that blocking an existing friend removes the friendship.
+ *  - TestFriendService_UnblockUser_AllowsFriendRequests    - Tests that requests are allowed again after unblocking.
+ *  - TestFriendService_GetFriendsList_FriendsSince         - Tests that friends are summarised with the date the request was sent.
+ *  - TestFriendService_UnderscoreEmails                    - Tests sending, accepting and listing friends for emails containing "_".
+ *  - TestFriendService_ComputeSuggestions                  - Tests ranking by mutual friends and the exclusion rules on a small social graph.
+ *  - TestFriendService_GetMutualFriends                    - Tests the friends shared by two users, and that blocked users are not found.
+ *  - TestFriendService_GetRelationshipStatuses             - Tests every status on a mixed set of users without per-user lookups, and input validation.
+ *  - TestUserService_SearchUsersByUsername_ExcludeBlocked  - Tests that blocked users can be hidden from search.
+ *  - TestFriendService_SendFriendRequest_Branches          - Tests every outcome of sending a request and the stored requests after each.
+ *  - TestFriendService_AcceptFriendRequest_Branches        - Tests every outcome of accepting a request and the stored requests after each.
+ *  - TestFriendService_RemoveFriend                        - Tests removing friendships stored in either direction, and non-friends.
+ *  - TestFriendService_RequestExpiry                       - Tests that requests older than 30 days are hidden, cannot be accepted and can be sent again.
+ *  - TestFriendService_DeclineCooldown                     - Tests that a declined sender must wait a day before sending again.
+ *  - TestFriendService_PurgeExpiredFriendRequests          - Tests that the sweep deletes only expired and cooled-down requests.
+ *  - TestFriendService_InviteFriend                        - Tests friend requests to registered addresses and invitations to the rest.
+ *
+ *  @dependencies
+ *  - mocks.NewMockUserRepository, mocks.NewMockFriendRepository: Mock repositories for testing.
+ *  - mocks.MockEmailService: Mock implementation of EmailService for testing.
+ *  - mocks.NewMockNotificationRepository: Mock notification inbox for testing.
+ *  - mocks.NewMockFriendInvitationRepository: Mock invitations to addresses without an account.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newNotifyingFriendService creates a FriendService with two users, alice and bob.
+func newNotifyingFriendService() (services.FriendServiceInterface, map[string]*models.User, *mocks.MockEmailService) {
+	friendService, users, _, mockEmailService := newFriendServiceWithRepos()
+	return friendService, users, mockEmailService
+}
+
+// newFriendServiceWithRepos creates a FriendService with two users, alice and bob, and
+// also returns the mock friend repository backing it.
+func newFriendServiceWithRepos() (services.FriendServiceInterface, map[string]*models.User, *mocks.MockFriendRepository, *mocks.MockEmailService) {
+	users := map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "alice"},
+		"bob@example.com":   {Email: "bob@example.com", Username: "bob"},
+	}
+	mockFriendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{})
+	mockEmailService := &mocks.MockEmailService{}
+	friendService := services.NewFriendService(mocks.NewMockUserRepository(users), mockFriendRepo, mockEmailService, nil)
+	return friendService, users, mockFriendRepo, mockEmailService
+}
+
+func TestFriendService_SendFriendRequest_NotifiesRecipient(t *testing.T) {
+	friendService, _, mockEmailService := newNotifyingFriendService()
+
+	if _, err := friendService.SendFriendRequest(context.Background(), "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Failed to send friend request: %v", err)
+	}
+
+	if len(mockEmailService.SentEmails) != 1 {
+		t.Fatalf("Expected 1 email, got %d", len(mockEmailService.SentEmails))
+	}
+	email := mockEmailService.SentEmails[0]
+	if email.To != "bob@example.com" || !strings.Contains(email.Body, "alice") {
+		t.Errorf("Expected an email to bob mentioning alice, got %+v", email)
+	}
+}
+
+func TestFriendService_SendFriendRequest_EmailCasing(t *testing.T) {
+	ctx := context.Background()
+	friendService, _, friendRepo, mockEmailService := newFriendServiceWithRepos()
+
+	if _, err := friendService.SendFriendRequest(ctx, "alice@example.com", " BOB@EXAMPLE.COM "); err != nil {
+		t.Fatalf("Failed to send friend request to an upper-cased email: %v", err)
+	}
+	if request := friendRepo.Friends["alice@example.com_bob@example.com"]; request == nil || request.FriendEmail != "bob@example.com" || request.Status != "pending" {
+		t.Errorf("Expected a pending request from alice to bob@example.com, got %+v", friendRepo.Friends)
+	}
+	if len(mockEmailService.SentEmails) != 1 || mockEmailService.SentEmails[0].To != "bob@example.com" {
+		t.Errorf("Expected 1 email to bob@example.com, got %+v", mockEmailService.SentEmails)
+	}
+
+	// The emails are reported under the form they were asked about.
+	statuses, err := friendService.GetRelationshipStatuses(ctx, "alice@example.com", []string{"Bob@Example.com", "bob@example.com"})
+	if err != nil || statuses["Bob@Example.com"] != services.FriendshipPendingOutgoing || statuses["bob@example.com"] != services.FriendshipPendingOutgoing {
+		t.Errorf("Expected bob to be pending outgoing however his email is cased, got %v, %v", statuses, err)
+	}
+
+	if _, err := friendService.SendFriendRequest(ctx, "alice@example.com", "ALICE@example.com"); err == nil {
+		t.Error("Expected a request to the user's own upper-cased email to be rejected")
+	}
+}
+
+func TestFriendService_AcceptFriendRequest_NotifiesSender(t *testing.T) {
+	friendService, _, mockEmailService := newNotifyingFriendService()
+
+	friendService.SendFriendRequest(context.Background(), "alice@example.com", "bob")
+	if err := friendService.AcceptFriendRequest(context.Background(), "bob@example.com", "alice"); err != nil {
+		t.Fatalf("Failed to accept friend request: %v", err)
+	}
+
+	if len(mockEmailService.SentEmails) != 2 {
+		t.Fatalf("Expected 2 emails, got %d", len(mockEmailService.SentEmails))
+	}
+	email := mockEmailService.SentEmails[1]
+	if email.To != "alice@example.com" || !strings.Contains(email.Body, "bob") {
+		t.Errorf("Expected an email to alice mentioning bob, got %+v", email)
+	}
+}
+
+func TestFriendService_NotificationInbox(t *testing.T) {
+	users := map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "alice"},
+		"bob@example.com":   {Email: "bob@example.com", Username: "bob"},
+	}
+	notificationRepo := mocks.NewMockNotificationRepository()
+	notificationService := services.NewNotificationService(notificationRepo, services.NewNotificationHub())
+	friendService := services.NewFriendService(mocks.NewMockUserRepository(users), mocks.NewMockFriendRepository(map[string]*models.Friend{}), &mocks.MockEmailService{}, notificationService)
+
+	friendService.SendFriendRequest(context.Background(), "alice@example.com", "bob")
+	inbox, _ := notificationService.ListNotifications(context.Background(), "bob@example.com", true, 0)
+	if len(inbox) != 1 || inbox[0].Type != services.NotificationFriendRequest || inbox[0].Payload["username"] != "alice" || inbox[0].Read {
+		t.Fatalf("Expected an unread friend request from alice in bob's inbox, got %+v", inbox)
+	}
+
+	if err := friendService.AcceptFriendRequest(context.Background(), "bob@example.com", "alice"); err != nil {
+		t.Fatalf("Failed to accept friend request: %v", err)
+	}
+	inbox, _ = notificationService.ListNotifications(context.Background(), "alice@example.com", true, 0)
+	if len(inbox) != 1 || inbox[0].Type != services.NotificationFriendAccepted || inbox[0].Payload["username"] != "bob" {
+		t.Fatalf("Expected an acceptance from bob in alice's inbox, got %+v", inbox)
+	}
+}
+
+func TestFriendService_AcceptFriendRequest_NotPending(t *testing.T) {
+	friendService, _, mockFriendRepo, mockEmailService := newFriendServiceWithRepos()
+	ctx := context.Background()
+
+	// A request cancelled by its sender must not come back as a friendship.
+	friendService.SendFriendRequest(ctx, "alice@example.com", "bob")
+	if err := friendService.CancelFriendRequest(ctx, "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Failed to cancel friend request: %v", err)
+	}
+	if err := friendService.AcceptFriendRequest(ctx, "bob@example.com", "alice"); err == nil || err.Error() != "Friend request not found" {
+		t.Fatalf("Expected 'Friend request not found', got %v", err)
+	}
+	if _, exists := mockFriendRepo.Friends["alice@example.com_bob@example.com"]; exists {
+		t.Errorf("Expected no friendship to be created for a cancelled request")
+	}
+
+	// An already accepted request cannot be accepted again, and the sender is not notified twice.
+	friendService.SendFriendRequest(ctx, "alice@example.com", "bob")
+	if err := friendService.AcceptFriendRequest(ctx, "bob@example.com", "alice"); err != nil {
+		t.Fatalf("Failed to accept friend request: %v", err)
+	}
+	sent := len(mockEmailService.SentEmails)
+	if err := friendService.AcceptFriendRequest(ctx, "bob@example.com", "alice"); err == nil || err.Error() != "Friend request not found" {
+		t.Fatalf("Expected 'Friend request not found' for an accepted request, got %v", err)
+	}
+	if len(mockEmailService.SentEmails) != sent {
+		t.Errorf("Expected no email for a failed acceptance")
+	}
+}
+
+func TestFriendService_SendFriendRequest_Crossing(t *testing.T) {
+	friendService, _, mockFriendRepo, mockEmailService := newFriendServiceWithRepos()
+	ctx := context.Background()
+
+	if accepted, err := friendService.SendFriendRequest(ctx, "alice@example.com", "bob"); err != nil || accepted {
+		t.Fatalf("Expected a new pending request, got accepted=%v err=%v", accepted, err)
+	}
+	accepted, err := friendService.SendFriendRequest(ctx, "bob@example.com", "alice")
+	if err != nil || !accepted {
+		t.Fatalf("Expected alice's request to be accepted, got accepted=%v err=%v", accepted, err)
+	}
+
+	if friend := mockFriendRepo.Friends["alice@example.com_bob@example.com"]; friend == nil || friend.Status != "accepted" {
+		t.Errorf("Expected alice's request to be accepted, got %+v", friend)
+	}
+	if _, exists := mockFriendRepo.Friends["bob@example.com_alice@example.com"]; exists {
+		t.Errorf("Expected no crossing request from bob")
+	}
+	last := mockEmailService.SentEmails[len(mockEmailService.SentEmails)-1]
+	if last.To != "alice@example.com" || last.Subject != "Friend request accepted" {
+		t.Errorf("Expected alice to be told her request was accepted, got %+v", last)
+	}
+
+	// Now that they are friends, neither can send another request.
+	if _, err := friendService.SendFriendRequest(ctx, "bob@example.com", "alice"); err == nil {
+		t.Errorf("Expected an error when sending a request to a friend")
+	}
+}
+
+func TestFriendService_LegacyDuplicateRequests(t *testing.T) {
+	friendService, _, mockFriendRepo, _ := newFriendServiceWithRepos()
+	ctx := context.Background()
+	pending := func(sender, recipient string) *models.Friend {
+		return &models.Friend{Email: sender, FriendEmail: recipient, Status: "pending"}
+	}
+
+	// Accepting one of two crossing requests removes the other.
+	mockFriendRepo.Friends["alice@example.com_bob@example.com"] = pending("alice@example.com", "bob@example.com")
+	mockFriendRepo.Friends["bob@example.com_alice@example.com"] = pending("bob@example.com", "alice@example.com")
+	if err := friendService.AcceptFriendRequest(ctx, "bob@example.com", "alice"); err != nil {
+		t.Fatalf("Failed to accept friend request: %v", err)
+	}
+	if _, exists := mockFriendRepo.Friends["bob@example.com_alice@example.com"]; exists {
+		t.Errorf("Expected the crossing request to be removed")
+	}
+
+	// A request left pending next to an accepted one is hidden from the pending list and removed.
+	mockFriendRepo.Friends["bob@example.com_alice@example.com"] = pending("bob@example.com", "alice@example.com")
+	requests, err := friendService.GetPendingFriendRequests(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get pending friend requests: %v", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("Expected no pending requests from a friend, got %+v", requests)
+	}
+	if _, exists := mockFriendRepo.Friends["bob@example.com_alice@example.com"]; exists {
+		t.Errorf("Expected the stale request to be removed")
+	}
+
+	// Removing a friend clears both directions.
+	mockFriendRepo.Friends["bob@example.com_alice@example.com"] = pending("bob@example.com", "alice@example.com")
+	if err := friendService.RemoveFriend(ctx, "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Failed to remove friend: %v", err)
+	}
+	if len(mockFriendRepo.Friends) != 0 {
+		t.Errorf("Expected both directions to be removed, got %+v", mockFriendRepo.Friends)
+	}
+}
+
+func TestFriendService_NotificationsDisabled(t *testing.T) {
+	friendService, users, mockEmailService := newNotifyingFriendService()
+	disabled := false
+	users["bob@example.com"].NotificationsEnabled = &disabled
+
+	if _, err := friendService.SendFriendRequest(context.Background(), "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Failed to send friend request: %v", err)
+	}
+	if len(mockEmailService.SentEmails) != 0 {
+		t.Errorf("Expected no emails when notifications are disabled, got %d", len(mockEmailService.SentEmails))
+	}
+}
+
+func TestFriendService_EmailFailureDoesNotFail(t *testing.T) {
+	friendService, _, mockEmailService := newNotifyingFriendService()
+	mockEmailService.Err = fmt.Errorf("smtp unavailable")
+
+	if _, err := friendService.SendFriendRequest(context.Background(), "alice@example.com", "bob"); err != nil {
+		t.Errorf("Expected friend request to succeed despite email failure, got %v", err)
+	}
+	if err := friendService.AcceptFriendRequest(context.Background(), "bob@example.com", "alice"); err != nil {
+		t.Errorf("Expected acceptance to succeed despite email failure, got %v", err)
+	}
+}
+
+func TestFriendService_BlockUser_RejectsFriendRequests(t *testing.T) {
+	friendService, _, mockFriendRepo, _ := newFriendServiceWithRepos()
+
+	if err := friendService.BlockUser(context.Background(), "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Failed to block user: %v", err)
+	}
+
+	if _, err := friendService.SendFriendRequest(context.Background(), "bob@example.com", "alice"); err == nil {
+		t.Errorf("Expected friend request from blocked user to be rejected")
+	}
+	if _, err := friendService.SendFriendRequest(context.Background(), "alice@example.com", "bob"); err == nil {
+		t.Errorf("Expected friend request to blocked user to be rejected")
+	}
+	if len(mockFriendRepo.Friends) != 0 {
+		t.Errorf("Expected no friend requests to be stored, got %d", len(mockFriendRepo.Friends))
+	}
+
+	blocked, _ := friendService.GetBlockedUsers(context.Background(), "alice@example.com")
+	if len(blocked) != 1 || blocked[0].Username != "bob" {
+		t.Errorf("Expected bob in alice's blocked users, got %+v", blocked)
+	}
+}
+
+func TestFriendService_BlockUser_RemovesFriendship(t *testing.T) {
+	friendService, _, mockFriendRepo, _ := newFriendServiceWithRepos()
+
+	friendService.SendFriendRequest(context.Background(), "alice@example.com", "bob")
+	friendService.AcceptFriendRequest(context.Background(), "bob@example.com", "alice")
+
+	if err := friendService.BlockUser(context.Background(), "bob@example.com", "alice@example.com"); err != nil {
+		t.Fatalf("Failed to block user: %v", err)
+	}
+
+	friends, _ := friendService.GetFriendsList(context.Background(), "alice@example.com")
+	if len(friends) != 0 || len(mockFriendRepo.Friends) != 0 {
+		t.Errorf("Expected friendship to be removed, got %d friends", len(friends))
+	}
+}
+
+func TestFriendService_UnblockUser_AllowsFriendRequests(t *testing.T) {
+	friendService, _, _, _ := newFriendServiceWithRepos()
+
+	friendService.BlockUser(context.Background(), "alice@example.com", "bob")
+	if err := friendService.UnblockUser(context.Background(), "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Failed to unblock user: %v", err)
+	}
+
+	if _, err := friendService.SendFriendRequest(context.Background(), "bob@example.com", "alice"); err != nil {
+		t.Errorf("Expected friend request to be allowed after unblocking, got %v", err)
+	}
+
+	if err := friendService.UnblockUser(context.Background(), "alice@example.com", "bob"); err == nil {
+		t.Errorf("Expected unblocking a user who is not blocked to fail")
+	}
+}
+
+func TestFriendService_GetFriendsList_FriendsSince(t *testing.T) {
+	friendService, _, mockFriendRepo, _ := newFriendServiceWithRepos()
+
+	before := time.Now()
+	friendService.SendFriendRequest(context.Background(), "alice@example.com", "bob")
+	friendService.AcceptFriendRequest(context.Background(), "bob@example.com", "alice")
+
+	createdAt := mockFriendRepo.Friends["alice@example.com_bob@example.com"].CreatedAt
+	if createdAt.Before(before) {
+		t.Fatalf("Expected CreatedAt to be set when the request was sent, got %v", createdAt)
+	}
+
+	friends, err := friendService.GetFriendsList(context.Background(), "bob@example.com")
+	if err != nil || len(friends) != 1 {
+		t.Fatalf("Expected 1 friend, got %+v (err: %v)", friends, err)
+	}
+	if friends[0].Username != "alice" || friends[0].FriendsSince == nil || !friends[0].FriendsSince.Equal(createdAt) {
+		t.Errorf("Expected alice with friendsSince %v, got %+v", createdAt, friends[0])
+	}
+}
+
+func TestFriendService_UnderscoreEmails(t *testing.T) {
+	users := map[string]*models.User{
+		"john_doe@example.com": {Email: "john_doe@example.com", Username: "john"},
+		"doe_jane@example.com": {Email: "doe_jane@example.com", Username: "jane"},
+	}
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{})
+	friendService := services.NewFriendService(mocks.NewMockUserRepository(users), friendRepo, &mocks.MockEmailService{}, nil)
+	ctx := context.Background()
+
+	if _, err := friendService.SendFriendRequest(ctx, "john_doe@example.com", "jane"); err != nil {
+		t.Fatalf("Failed to send friend request: %v", err)
+	}
+	docID := repositories.CompositeID("john_doe@example.com", "doe_jane@example.com")
+	if docID != "john%5Fdoe@example.com_doe%5Fjane@example.com" || friendRepo.Friends[docID] == nil {
+		t.Fatalf("Expected the request under %s, got %v", docID, friendRepo.Friends)
+	}
+	if parts, err := repositories.SplitCompositeID(docID); err != nil || parts[0] != "john_doe@example.com" || parts[1] != "doe_jane@example.com" {
+		t.Errorf("Expected the document ID to split into sender and recipient, got %q (err: %v)", parts, err)
+	}
+
+	if err := friendService.AcceptFriendRequest(ctx, "doe_jane@example.com", "john"); err != nil {
+		t.Fatalf("Failed to accept friend request: %v", err)
+	}
+	for email, want := range map[string]string{"john_doe@example.com": "jane", "doe_jane@example.com": "john"} {
+		friends, err := friendService.GetFriendsList(ctx, email)
+		if err != nil || len(friends) != 1 || friends[0].Username != want {
+			t.Errorf("Expected %s to be friends with %s, got %+v (err: %v)", email, want, friends, err)
+		}
+	}
+}
+
+// newSocialGraphFriendService creates a FriendService over a small social graph around alice:
+//
+//	alice - bob, carol, dave
+//	bob   - carol, eve, frank
+//	carol - eve, grace, mallory
+//	dave  - eve, heidi, ivan, judy, mallory
+//
+// alice has blocked heidi, mallory has blocked alice, alice sent ivan a request and judy sent alice one.
+func newSocialGraphFriendService() (services.FriendServiceInterface, *mocks.MockFriendRepository) {
+	users := map[string]*models.User{}
+	for _, name := range []string{"alice", "bob", "carol", "dave", "eve", "frank", "grace", "heidi", "ivan", "judy", "mallory"} {
+		users[name+"@example.com"] = &models.User{Email: name + "@example.com", Username: name}
+	}
+
+	friends := map[string]*models.Friend{}
+	link := func(from, to, status string) {
+		friends[from+"@example.com_"+to+"@example.com"] = &models.Friend{Email: from + "@example.com", FriendEmail: to + "@example.com", Status: status}
+	}
+	link("alice", "bob", "accepted")
+	link("carol", "alice", "accepted")
+	link("alice", "dave", "accepted")
+	link("bob", "carol", "accepted")
+	link("bob", "eve", "accepted")
+	link("frank", "bob", "accepted")
+	link("carol", "eve", "accepted")
+	link("carol", "grace", "accepted")
+	link("carol", "mallory", "accepted")
+	link("eve", "dave", "accepted")
+	link("dave", "heidi", "accepted")
+	link("dave", "ivan", "accepted")
+	link("dave", "judy", "accepted")
+	link("dave", "mallory", "accepted")
+	link("alice", "ivan", "pending")
+	link("judy", "alice", "pending")
+
+	friendRepo := mocks.NewMockFriendRepository(friends)
+	friendRepo.Blocks["alice@example.com_heidi@example.com"] = &models.Block{BlockerEmail: "alice@example.com", BlockedEmail: "heidi@example.com"}
+	friendRepo.Blocks["mallory@example.com_alice@example.com"] = &models.Block{BlockerEmail: "mallory@example.com", BlockedEmail: "alice@example.com"}
+
+	return services.NewFriendService(mocks.NewMockUserRepository(users), friendRepo, &mocks.MockEmailService{}, nil), friendRepo
+}
+
+func TestFriendService_ComputeSuggestions(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{"ranked by mutual friends, then email", 10, []string{"eve:3", "frank:1", "grace:1"}},
+		{"limited", 2, []string{"eve:3", "frank:1"}},
+		{"default limit", 0, []string{"eve:3", "frank:1", "grace:1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			friendService, friendRepo := newSocialGraphFriendService()
+
+			suggestions, err := friendService.ComputeSuggestions(context.Background(), "alice@example.com", tt.limit)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, suggestion := range suggestions {
+				got = append(got, fmt.Sprintf("%s:%d", suggestion.Username, suggestion.MutualFriends))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected suggestions %v, got %v", tt.want, got)
+			}
+			if friendRepo.GetFriendsOfUsersCalls != 1 {
+				t.Errorf("Expected friends of friends to be loaded in 1 batched call, got %d", friendRepo.GetFriendsOfUsersCalls)
+			}
+		})
+	}
+}
+
+func TestFriendService_GetRelationshipStatuses(t *testing.T) {
+	ctx := context.Background()
+	friendService, friendRepo := newSocialGraphFriendService()
+
+	want := map[string]string{
+		"bob@example.com":     services.FriendshipFriends,         // Request sent by alice.
+		"carol@example.com":   services.FriendshipFriends,         // Request sent by carol.
+		"ivan@example.com":    services.FriendshipPendingOutgoing, // Pending request from alice.
+		"judy@example.com":    services.FriendshipPendingIncoming, // Pending request to alice.
+		"heidi@example.com":   services.FriendshipBlocked,         // Blocked by alice.
+		"mallory@example.com": services.FriendshipNone,            // Blocked alice, which is not revealed.
+		"eve@example.com":     services.FriendshipNone,            // Friend of friends only.
+		"alice@example.com":   services.FriendshipNone,            // The user themselves.
+		"nobody@example.com":  services.FriendshipNone,            // No account.
+	}
+	emails := make([]string, 0, len(want))
+	for email := range want {
+		emails = append(emails, email)
+	}
+
+	statuses, err := friendService.GetRelationshipStatuses(ctx, "alice@example.com", emails)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Errorf("Expected statuses %v, got %v", want, statuses)
+	}
+	if friendRepo.GetFriendRequestCalls != 0 {
+		t.Errorf("Expected no lookups per user, got %d GetFriendRequest calls", friendRepo.GetFriendRequestCalls)
+	}
+
+	// A leftover pending request does not hide an accepted friendship.
+	friendRepo.Friends["alice@example.com_carol@example.com"] = &models.Friend{Email: "alice@example.com", FriendEmail: "carol@example.com", Status: "pending"}
+	statuses, err = friendService.GetRelationshipStatuses(ctx, "alice@example.com", []string{"carol@example.com"})
+	if err != nil || statuses["carol@example.com"] != services.FriendshipFriends {
+		t.Errorf("Expected carol to stay a friend, got %v, %v", statuses, err)
+	}
+
+	if _, err := friendService.GetRelationshipStatuses(ctx, "alice@example.com", []string{"bob@example.com", "bob"}); !errors.Is(err, services.ErrInvalidEmail) {
+		t.Errorf("Expected ErrInvalidEmail, got %v", err)
+	}
+	tooMany := make([]string, services.MaxRelationshipStatusEmails+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("user%d@example.com", i)
+	}
+	if _, err := friendService.GetRelationshipStatuses(ctx, "alice@example.com", tooMany); !errors.Is(err, services.ErrTooManyStatusEmails) {
+		t.Errorf("Expected ErrTooManyStatusEmails, got %v", err)
+	}
+	if statuses, err := friendService.GetRelationshipStatuses(ctx, "alice@example.com", nil); err != nil || len(statuses) != 0 {
+		t.Errorf("Expected no statuses for no emails, got %v, %v", statuses, err)
+	}
+
+	friendRepo.Err = repositories.ErrUnavailable
+	if _, err := friendService.GetRelationshipStatuses(ctx, "alice@example.com", []string{"bob@example.com"}); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestFriendService_GetMutualFriends(t *testing.T) {
+	friendService, _ := newSocialGraphFriendService()
+
+	mutualFriends, err := friendService.GetMutualFriends(context.Background(), "alice@example.com", "eve")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var got []string
+	for _, friend := range mutualFriends {
+		got = append(got, friend.Username)
+	}
+	if strings.Join(got, ",") != "bob,carol,dave" {
+		t.Errorf("Expected mutual friends bob, carol and dave, got %v", got)
+	}
+
+	if _, err := friendService.GetMutualFriends(context.Background(), "alice@example.com", "mallory"); err == nil || err.Error() != "User not found" {
+		t.Errorf("Expected a user who blocked alice to be not found, got %v", err)
+	}
+}
+
+func TestUserService_SearchUsersByUsername_ExcludeBlocked(t *testing.T) {
+	users := map[string]*models.User{
+		"alice@example.com":  {Email: "alice@example.com", Username: "alice"},
+		"albert@example.com": {Email: "albert@example.com", Username: "albert"},
+		"alex@example.com":   {Email: "alex@example.com", Username: "alex"},
+	}
+	mockFriendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{})
+	mockFriendRepo.CreateBlock(context.Background(), &models.Block{BlockerEmail: "albert@example.com", BlockedEmail: "alice@example.com"})
+	userService := services.NewUserService(mocks.NewMockUserRepository(users), &mocks.MockEmailService{}, mockFriendRepo, testJWT)
+
+	results, _ := userService.SearchUsersByUsername(context.Background(), "alice@example.com", "al", false, false, 0, 0)
+	if len(results) != 2 {
+		t.Errorf("Expected 2 results without exclusion, got %d", len(results))
+	}
+
+	results, _ = userService.SearchUsersByUsername(context.Background(), "alice@example.com", "al", true, false, 0, 0)
+	if len(results) != 1 || results[0]["username"] != "alex" {
+		t.Errorf("Expected only alex when excluding blocked users, got %+v", results)
+	}
+}
+
+// friendCase describes a friend operation by alice on a repository prepared by setup, and the
+// requests stored afterwards as "sender_recipient" mapped to their status.
+type friendCase struct {
+	name       string
+	setup      func(repo *mocks.MockFriendRepository)
+	identifier string
+	wantErr    string
+	wantStored map[string]string
+}
+
+// pendingRequest stores a request from sender to recipient with the given status.
+func pendingRequest(sender, recipient, status string) func(repo *mocks.MockFriendRepository) {
+	return func(repo *mocks.MockFriendRepository) {
+		repo.Friends[sender+"_"+recipient] = &models.Friend{Email: sender, FriendEmail: recipient, Status: status}
+	}
+}
+
+// storedRequests returns the stored requests as "sender_recipient" mapped to their status.
+func storedRequests(repo *mocks.MockFriendRepository) map[string]string {
+	stored := map[string]string{}
+	for id, friend := range repo.Friends {
+		stored[id] = friend.Status
+	}
+	return stored
+}
+
+// checkFriendCase fails the test if err or the stored requests differ from the case's expectations.
+func checkFriendCase(t *testing.T, test friendCase, repo *mocks.MockFriendRepository, err error) {
+	t.Helper()
+	switch {
+	case test.wantErr == "" && err != nil:
+		t.Fatalf("Expected success, got %v", err)
+	case test.wantErr != "" && (err == nil || err.Error() != test.wantErr):
+		t.Fatalf("Expected %q, got %v", test.wantErr, err)
+	}
+	if test.wantStored == nil {
+		test.wantStored = map[string]string{}
+	}
+	if got := storedRequests(repo); fmt.Sprint(got) != fmt.Sprint(test.wantStored) {
+		t.Errorf("Expected stored requests %v, got %v", test.wantStored, got)
+	}
+}
+
+func TestFriendService_SendFriendRequest_Branches(t *testing.T) {
+	const aliceBob, bobAlice = "alice@example.com_bob@example.com", "bob@example.com_alice@example.com"
+	tests := []struct {
+		friendCase
+		wantAccepted bool
+	}{
+		{friendCase: friendCase{name: "by username", identifier: "bob", wantStored: map[string]string{aliceBob: "pending"}}},
+		{friendCase: friendCase{name: "by email", identifier: "bob@example.com", wantStored: map[string]string{aliceBob: "pending"}}},
+		{friendCase: friendCase{name: "unknown username", identifier: "nobody", wantErr: "User not found"}},
+		{friendCase: friendCase{name: "unknown email", identifier: "nobody@example.com", wantErr: "User not found"}},
+		{friendCase: friendCase{name: "to self by username", identifier: "alice", wantErr: "You cannot send a friend request to yourself"}},
+		{friendCase: friendCase{name: "to self by email", identifier: "alice@example.com", wantErr: "You cannot send a friend request to yourself"}},
+		{friendCase: friendCase{
+			name: "blocked by the recipient",
+			setup: func(repo *mocks.MockFriendRepository) {
+				repo.CreateBlock(context.Background(), &models.Block{BlockerEmail: "bob@example.com", BlockedEmail: "alice@example.com"})
+			},
+			identifier: "bob", wantErr: "You cannot send a friend request to this user",
+		}},
+		{friendCase: friendCase{
+			name: "already sent", setup: pendingRequest("alice@example.com", "bob@example.com", "pending"), identifier: "bob",
+			wantErr: "Friend request already exists or you are already friends", wantStored: map[string]string{aliceBob: "pending"},
+		}},
+		{friendCase: friendCase{
+			name: "already friends through their request", setup: pendingRequest("bob@example.com", "alice@example.com", "accepted"), identifier: "bob",
+			wantErr: "Friend request already exists or you are already friends", wantStored: map[string]string{bobAlice: "accepted"},
+		}},
+		{
+			friendCase: friendCase{
+				name: "accepts their pending request", setup: pendingRequest("bob@example.com", "alice@example.com", "pending"), identifier: "bob",
+				wantStored: map[string]string{bobAlice: "accepted"},
+			},
+			wantAccepted: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			friendService, _, repo, _ := newFriendServiceWithRepos()
+			if test.setup != nil {
+				test.setup(repo)
+			}
+			accepted, err := friendService.SendFriendRequest(context.Background(), "alice@example.com", test.identifier)
+			checkFriendCase(t, test.friendCase, repo, err)
+			if accepted != test.wantAccepted {
+				t.Errorf("Expected accepted %v, got %v", test.wantAccepted, accepted)
+			}
+		})
+	}
+
+	// Database failures are passed on rather than reported as a missing user or an existing request.
+	friendService, _, repo, _ := newFriendServiceWithRepos()
+	repo.Err = repositories.ErrUnavailable
+	if _, err := friendService.SendFriendRequest(context.Background(), "alice@example.com", "bob"); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestFriendService_AcceptFriendRequest_Branches(t *testing.T) {
+	const aliceBob, bobAlice = "alice@example.com_bob@example.com", "bob@example.com_alice@example.com"
+	tests := []friendCase{
+		{name: "by username", setup: pendingRequest("bob@example.com", "alice@example.com", "pending"), identifier: "bob", wantStored: map[string]string{bobAlice: "accepted"}},
+		{name: "by email", setup: pendingRequest("bob@example.com", "alice@example.com", "pending"), identifier: "bob@example.com", wantStored: map[string]string{bobAlice: "accepted"}},
+		{name: "unknown user", identifier: "nobody", wantErr: "User not found"},
+		{name: "no request", identifier: "bob", wantErr: "Friend request not found"},
+		{
+			name: "request sent by the user themselves", setup: pendingRequest("alice@example.com", "bob@example.com", "pending"), identifier: "bob",
+			wantErr: "Friend request not found", wantStored: map[string]string{aliceBob: "pending"},
+		},
+		{
+			name: "already accepted", setup: pendingRequest("bob@example.com", "alice@example.com", "accepted"), identifier: "bob",
+			wantErr: "Friend request not found", wantStored: map[string]string{bobAlice: "accepted"},
+		},
+		{
+			name: "legacy request in the other direction is dropped",
+			setup: func(repo *mocks.MockFriendRepository) {
+				pendingRequest("bob@example.com", "alice@example.com", "pending")(repo)
+				pendingRequest("alice@example.com", "bob@example.com", "pending")(repo)
+			},
+			identifier: "bob", wantStored: map[string]string{bobAlice: "accepted"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			friendService, _, repo, _ := newFriendServiceWithRepos()
+			if test.setup != nil {
+				test.setup(repo)
+			}
+			err := friendService.AcceptFriendRequest(context.Background(), "alice@example.com", test.identifier)
+			checkFriendCase(t, test, repo, err)
+		})
+	}
+}
+
+func TestFriendService_RemoveFriend(t *testing.T) {
+	const aliceBob, bobAlice = "alice@example.com_bob@example.com", "bob@example.com_alice@example.com"
+	tests := []friendCase{
+		{name: "friendship the user requested", setup: pendingRequest("alice@example.com", "bob@example.com", "accepted"), identifier: "bob"},
+		{name: "friendship the other user requested", setup: pendingRequest("bob@example.com", "alice@example.com", "accepted"), identifier: "bob"},
+		{
+			name: "legacy pair in both directions",
+			setup: func(repo *mocks.MockFriendRepository) {
+				pendingRequest("bob@example.com", "alice@example.com", "accepted")(repo)
+				pendingRequest("alice@example.com", "bob@example.com", "pending")(repo)
+			},
+			identifier: "bob",
+		},
+		{name: "unknown user", identifier: "nobody", wantErr: "User not found"},
+		{name: "not friends", identifier: "bob", wantErr: "Friend not found"},
+		{
+			name: "pending request only", setup: pendingRequest("bob@example.com", "alice@example.com", "pending"), identifier: "bob",
+			wantErr: "Friend not found", wantStored: map[string]string{bobAlice: "pending"},
+		},
+		{
+			name: "sent request only", setup: pendingRequest("alice@example.com", "bob@example.com", "pending"), identifier: "bob",
+			wantErr: "Friend not found", wantStored: map[string]string{aliceBob: "pending"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			friendService, _, repo, _ := newFriendServiceWithRepos()
+			if test.setup != nil {
+				test.setup(repo)
+			}
+			err := friendService.RemoveFriend(context.Background(), "alice@example.com", test.identifier)
+			checkFriendCase(t, test, repo, err)
+		})
+	}
+
+	friendService, _, repo, _ := newFriendServiceWithRepos()
+	pendingRequest("alice@example.com", "bob@example.com", "accepted")(repo)
+	repo.Err = repositories.ErrUnavailable
+	if err := friendService.RemoveFriend(context.Background(), "alice@example.com", "bob"); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+}
+
+// newClockedFriendService creates a FriendService with alice and bob whose clock reads *now.
+func newClockedFriendService(now *time.Time) (*services.FriendService, *mocks.MockFriendRepository) {
+	friendService, _, repo, _ := newFriendServiceWithRepos()
+	service := friendService.(*services.FriendService)
+	service.Now = func() time.Time { return *now }
+	return service, repo
+}
+
+func TestFriendService_RequestExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	friendService, repo := newClockedFriendService(&now)
+
+	if _, err := friendService.SendFriendRequest(ctx, "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Failed to send friend request: %v", err)
+	}
+
+	now = now.Add(services.FriendRequestExpiry - time.Hour)
+	pending, err := friendService.GetPendingFriendRequests(ctx, "bob@example.com")
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("Expected the request to be pending before it expires, got %v, %v", pending, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	pending, err = friendService.GetPendingFriendRequests(ctx, "bob@example.com")
+	if err != nil || len(pending) != 0 {
+		t.Errorf("Expected expired requests to be hidden, got %v, %v", pending, err)
+	}
+	if err := friendService.AcceptFriendRequest(ctx, "bob@example.com", "alice"); err == nil || err.Error() != "Friend request not found" {
+		t.Errorf("Expected an expired request not to be accepted, got %v", err)
+	}
+	if err := friendService.DeclineFriendRequest(ctx, "bob@example.com", "alice"); err == nil || err.Error() != "Friend request not found" {
+		t.Errorf("Expected an expired request not to be declined, got %v", err)
+	}
+
+	// Sending again replaces the expired request with a fresh one.
+	if _, err := friendService.SendFriendRequest(ctx, "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Expected an expired request to be sent again, got %v", err)
+	}
+	if request := repo.Friends["alice@example.com_bob@example.com"]; !request.CreatedAt.Equal(now) {
+		t.Errorf("Expected the request to be sent at %v, got %v", now, request.CreatedAt)
+	}
+
+	// Requests without a CreatedAt never expire.
+	pendingRequest("bob@example.com", "alice@example.com", "pending")(repo)
+	delete(repo.Friends, "alice@example.com_bob@example.com")
+	if pending, _ := friendService.GetPendingFriendRequests(ctx, "alice@example.com"); len(pending) != 1 {
+		t.Errorf("Expected a legacy request to stay pending, got %v", pending)
+	}
+}
+
+func TestFriendService_DeclineCooldown(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	friendService, repo := newClockedFriendService(&now)
+	const aliceBob = "alice@example.com_bob@example.com"
+
+	if _, err := friendService.SendFriendRequest(ctx, "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Failed to send friend request: %v", err)
+	}
+	if err := friendService.DeclineFriendRequest(ctx, "bob@example.com", "alice"); err != nil {
+		t.Fatalf("Failed to decline friend request: %v", err)
+	}
+	if request := repo.Friends[aliceBob]; request.Status != "declined" || request.DeclinedAt == nil || !request.DeclinedAt.Equal(now) {
+		t.Fatalf("Expected the request to be kept as declined, got %+v", request)
+	}
+	if pending, _ := friendService.GetPendingFriendRequests(ctx, "bob@example.com"); len(pending) != 0 {
+		t.Errorf("Expected declined requests to be hidden, got %v", pending)
+	}
+
+	// Cancelling does not remove the tombstone.
+	if err := friendService.CancelFriendRequest(ctx, "alice@example.com", "bob"); err != nil {
+		t.Errorf("Expected cancelling a declined request to succeed, got %v", err)
+	}
+	if _, exists := repo.Friends[aliceBob]; !exists {
+		t.Fatal("Expected cancelling not to remove the declined request")
+	}
+
+	now = now.Add(services.FriendRequestDeclineCooldown - time.Minute)
+	if _, err := friendService.SendFriendRequest(ctx, "alice@example.com", "bob"); !errors.Is(err, services.ErrFriendRequestCooldown) {
+		t.Errorf("Expected ErrFriendRequestCooldown, got %v", err)
+	}
+
+	// The recipient can still send a request the other way, which is not accepted automatically.
+	if accepted, err := friendService.SendFriendRequest(ctx, "bob@example.com", "alice"); err != nil || accepted {
+		t.Errorf("Expected the recipient to send a new request, got %v, %v", accepted, err)
+	}
+	delete(repo.Friends, "bob@example.com_alice@example.com")
+
+	now = now.Add(2 * time.Minute)
+	if _, err := friendService.SendFriendRequest(ctx, "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Expected a request after the cooldown to be sent, got %v", err)
+	}
+	if request := repo.Friends[aliceBob]; request.Status != "pending" || request.DeclinedAt != nil {
+		t.Errorf("Expected a fresh pending request, got %+v", request)
+	}
+}
+
+func TestFriendService_PurgeExpiredFriendRequests(t *testing.T) {
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	friendService, repo := newClockedFriendService(&now)
+	store := func(sender, recipient, status string, createdAt time.Time, declinedAt *time.Time) {
+		repo.Friends[sender+"_"+recipient] = &models.Friend{Email: sender, FriendEmail: recipient, Status: status, CreatedAt: createdAt, DeclinedAt: declinedAt}
+	}
+	recentlyDeclined, longDeclined := now.Add(-time.Hour), now.Add(-2*services.FriendRequestDeclineCooldown)
+	store("a@example.com", "b@example.com", "pending", now.Add(-services.FriendRequestExpiry-time.Hour), nil)
+	store("a@example.com", "c@example.com", "pending", now.Add(-time.Hour), nil)
+	store("a@example.com", "d@example.com", "pending", time.Time{}, nil)
+	store("a@example.com", "e@example.com", "declined", now.Add(-time.Hour), &recentlyDeclined)
+	store("a@example.com", "f@example.com", "declined", now.Add(-time.Hour), &longDeclined)
+	store("a@example.com", "g@example.com", "accepted", now.Add(-2*services.FriendRequestExpiry), nil)
+
+	deleted, err := friendService.PurgeExpiredFriendRequests(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to purge friend requests: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 requests to be deleted, got %d", deleted)
+	}
+	for _, kept := range []string{"c", "d", "e", "g"} {
+		if _, exists := repo.Friends["a@example.com_"+kept+"@example.com"]; !exists {
+			t.Errorf("Expected the request to %s to be kept", kept)
+		}
+	}
+
+	// The sweep purges on every tick until it is stopped.
+	store("a@example.com", "b@example.com", "pending", now.Add(-services.FriendRequestExpiry-time.Hour), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		friendService.RunExpirySweep(ctx, ticks)
+		close(done)
+	}()
+	ticks <- now
+	ticks <- now // Blocks until the first tick has been handled.
+	cancel()
+	<-done
+	if _, exists := repo.Friends["a@example.com_b@example.com"]; exists {
+		t.Error("Expected the sweep to delete the expired request")
+	}
+}
+
+func TestFriendService_InviteFriend(t *testing.T) {
+	ctx := context.Background()
+	friendService, _, friendRepo, mockEmailService := newFriendServiceWithRepos()
+	invitationRepo := mocks.NewMockFriendInvitationRepository()
+	friendService.(*services.FriendService).InvitationRepo = invitationRepo
+	friendService.(*services.FriendService).AppURL = "https://dailyverse.app"
+
+	// A registered address gets a friend request, exactly like SendFriendRequest.
+	outcome, err := friendService.InviteFriend(ctx, "alice@example.com", "bob@example.com")
+	if err != nil || outcome != services.InviteRequestSent {
+		t.Fatalf("Expected a friend request to bob, got %q, %v", outcome, err)
+	}
+	if request := friendRepo.Friends["alice@example.com_bob@example.com"]; request == nil || request.Status != "pending" {
+		t.Errorf("Expected a pending request from alice to bob, got %+v", request)
+	}
+	outcome, err = friendService.InviteFriend(ctx, "bob@example.com", "alice@example.com")
+	if err != nil || outcome != services.InviteRequestAccepted {
+		t.Errorf("Expected bob's invite to accept alice's request, got %q, %v", outcome, err)
+	}
+	if len(invitationRepo.Invitations) != 0 {
+		t.Errorf("Expected no invitations for registered users, got %d", len(invitationRepo.Invitations))
+	}
+
+	// An unregistered address is emailed an invitation with a signup link.
+	mockEmailService.SentEmails = nil
+	outcome, err = friendService.InviteFriend(ctx, "alice@example.com", " Carol@Example.com ")
+	if err != nil || outcome != services.InviteInvitationSent {
+		t.Fatalf("Expected an invitation to carol, got %q, %v", outcome, err)
+	}
+	invitation := invitationRepo.Invitations["carol@example.com_alice@example.com"]
+	if invitation == nil || invitation.ConsumedAt != nil || invitation.CreatedAt.IsZero() {
+		t.Fatalf("Expected a pending invitation keyed by the lowercased address, got %+v", invitationRepo.Invitations)
+	}
+	if len(mockEmailService.SentEmails) != 1 {
+		t.Fatalf("Expected 1 invitation email, got %d", len(mockEmailService.SentEmails))
+	}
+	email := mockEmailService.SentEmails[0]
+	if email.To != "carol@example.com" || !strings.Contains(email.Body, "alice") ||
+		!strings.Contains(email.Body, "https://dailyverse.app/signup?email=carol%40example.com") {
+		t.Errorf("Expected an email to carol from alice with a signup link, got %+v", email)
+	}
+
+	// Inviting the same address again is a conflict, but another inviter may invite it too.
+	if _, err := friendService.InviteFriend(ctx, "alice@example.com", "carol@example.com"); !errors.Is(err, services.ErrFriendInvitationExists) {
+		t.Errorf("Expected ErrFriendInvitationExists, got %v", err)
+	}
+	if outcome, err := friendService.InviteFriend(ctx, "bob@example.com", "carol@example.com"); err != nil || outcome != services.InviteInvitationSent {
+		t.Errorf("Expected bob to be able to invite carol too, got %q, %v", outcome, err)
+	}
+
+	if _, err := friendService.InviteFriend(ctx, "alice@example.com", "carol"); !errors.Is(err, services.ErrInvalidEmail) {
+		t.Errorf("Expected ErrInvalidEmail for a username, got %v", err)
+	}
+
+	// A failed email does not fail the invitation.
+	mockEmailService.Err = fmt.Errorf("smtp down")
+	if outcome, err := friendService.InviteFriend(ctx, "alice@example.com", "dave@example.com"); err != nil || outcome != services.InviteInvitationSent {
+		t.Errorf("Expected the invitation to be stored despite the email failure, got %q, %v", outcome, err)
+	}
+
+	invitationRepo.Err = repositories.ErrUnavailable
+	if _, err := friendService.InviteFriend(ctx, "alice@example.com", "erin@example.com"); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+/**
+ *  GeocodingService Tests validate the Nominatim geocoder's address cache against a counting fake
+ *  search API, so no request reaches nominatim.openstreetmap.org.
+ *
+ *  @file       geocoding_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestNormalizeAddress              - Tests that case, whitespace and commas are normalized.
+ *  - TestNominatimGeocoder_Cache       - Tests that an address is fetched once within the TTL, however it is typed, and again after expiry.
+ *  - TestNominatimGeocoder_NotFound    - Tests that unknown addresses return ErrAddressNotFound and are cached too.
+ *  - TestNominatimGeocoder_APIError    - Tests that failed lookups return an error and are not cached.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := map[string]string{
+		"Kongens gate 1, 7011":          "kongens gate 1, 7011",
+		"  KONGENS   gate 1 ,7011 ":     "kongens gate 1, 7011",
+		"Kongens gate 1,, 7011,":        "kongens gate 1, 7011",
+		"Kongens\tgate 1\n, 7011":       "kongens gate 1, 7011",
+		" , ":                           "",
+		"Olav Tryggvasons gate 40 7011": "olav tryggvasons gate 40 7011",
+	}
+	for address, expected := range tests {
+		if got := services.NormalizeAddress(address); got != expected {
+			t.Errorf("NormalizeAddress(%q): expected %q, got %q", address, expected, got)
+		}
+	}
+}
+
+// newGeocoder returns a NominatimGeocoder for a fake search API that answers with results,
+// counting the requests in calls.
+func newGeocoder(t *testing.T, calls *int32, status int, results string, now *time.Time) *services.NominatimGeocoder {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if r.Header.Get("User-Agent") != "DailyVerse test" {
+			t.Errorf("Expected the User-Agent to identify the application, got %q", r.Header.Get("User-Agent"))
+		}
+		if r.URL.Query().Get("q") != "kongens gate 1, 7011" || r.URL.Query().Get("format") != "jsonv2" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(results))
+	}))
+	t.Cleanup(server.Close)
+
+	return &services.NominatimGeocoder{
+		HTTPClient: server.Client(),
+		SearchURL:  server.URL,
+		UserAgent:  "DailyVerse test",
+		CacheTTL:   time.Hour,
+		Now:        func() time.Time { return *now },
+	}
+}
+
+func TestNominatimGeocoder_Cache(t *testing.T) {
+	var calls int32
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	geocoder := newGeocoder(t, &calls, http.StatusOK, `[{"lat":"63.4305","lon":"10.3951","display_name":"Kongens gate 1"}]`, &now)
+	ctx := context.Background()
+
+	for _, address := range []string{"Kongens gate 1, 7011", "kongens gate 1,7011", " KONGENS GATE 1 , 7011 "} {
+		coordinates, err := geocoder.Geocode(ctx, address)
+		if err != nil || coordinates.Latitude != 63.4305 || coordinates.Longitude != 10.3951 {
+			t.Fatalf("Expected the coordinates of %q, got %+v (err: %v)", address, coordinates, err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call within the TTL, got %d", got)
+	}
+
+	// Changing the returned coordinates must not change the cached ones.
+	coordinates, _ := geocoder.Geocode(ctx, "Kongens gate 1, 7011")
+	coordinates.Latitude = 0
+	if cached, _ := geocoder.Geocode(ctx, "Kongens gate 1, 7011"); cached.Latitude != 63.4305 {
+		t.Errorf("Expected the cached coordinates to be unchanged, got %+v", cached)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := geocoder.Geocode(ctx, "Kongens gate 1, 7011"); err != nil {
+		t.Fatalf("Unexpected error after expiry: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected the address to be fetched again after the TTL, got %d calls", got)
+	}
+}
+
+func TestNominatimGeocoder_NotFound(t *testing.T) {
+	var calls int32
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	geocoder := newGeocoder(t, &calls, http.StatusOK, `[]`, &now)
+
+	for i := 0; i < 2; i++ {
+		if _, err := geocoder.Geocode(context.Background(), "Kongens gate 1, 7011"); !errors.Is(err, services.ErrAddressNotFound) {
+			t.Fatalf("Expected ErrAddressNotFound, got %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected the miss to be cached, got %d calls", got)
+	}
+}
+
+func TestNominatimGeocoder_APIError(t *testing.T) {
+	var calls int32
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	geocoder := newGeocoder(t, &calls, http.StatusTooManyRequests, `Too many requests`, &now)
+
+	for i := 0; i < 2; i++ {
+		_, err := geocoder.Geocode(context.Background(), "Kongens gate 1, 7011")
+		if err == nil || errors.Is(err, services.ErrAddressNotFound) {
+			t.Fatalf("Expected an API error, got %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected failed lookups not to be cached, got %d calls", got)
+	}
+}
@@ -0,0 +1,90 @@
+/**
+ *  HealthService Tests validate the readiness checks with injected checkers, so no real
+ *  Firestore client or SMTP server is needed.
+ *
+ *  @file       health_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestHealthService_CheckReadiness        - Tests ok, skipped and failing dependencies.
+ *  - TestHealthService_CheckReadiness_Timeout - Tests that a hanging checker is cut off by the timeout.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+)
+
+func TestHealthService_CheckReadiness(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return fmt.Errorf("rpc error: code = Unavailable") }
+
+	tests := []struct {
+		name         string
+		checkers     map[string]services.HealthChecker
+		wantStatuses map[string]string
+		wantReady    bool
+	}{
+		{
+			name:         "all usable",
+			checkers:     map[string]services.HealthChecker{"firestore": ok, "smtp": services.SkippedHealthChecker()},
+			wantStatuses: map[string]string{"firestore": "ok", "smtp": "skipped"},
+			wantReady:    true,
+		},
+		{
+			name:         "firestore down",
+			checkers:     map[string]services.HealthChecker{"firestore": failing, "smtp": services.SkippedHealthChecker()},
+			wantStatuses: map[string]string{"firestore": "error", "smtp": "skipped"},
+			wantReady:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses, ready := services.NewHealthService(tt.checkers).CheckReadiness(context.Background())
+			if ready != tt.wantReady {
+				t.Errorf("Expected ready %v, got %v", tt.wantReady, ready)
+			}
+			if len(statuses) != len(tt.wantStatuses) {
+				t.Fatalf("Expected %d statuses, got %v", len(tt.wantStatuses), statuses)
+			}
+			for name, want := range tt.wantStatuses {
+				if statuses[name] != want {
+					t.Errorf("Expected %s to be %q, got %q", name, want, statuses[name])
+				}
+			}
+		})
+	}
+}
+
+func TestHealthService_CheckReadiness_Timeout(t *testing.T) {
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	healthService := &services.HealthService{
+		Checkers: map[string]services.HealthChecker{"firestore": hanging},
+		Timeout:  20 * time.Millisecond,
+	}
+
+	start := time.Now()
+	statuses, ready := healthService.CheckReadiness(context.Background())
+	if ready || statuses["firestore"] != "error" {
+		t.Errorf("Expected a hanging check to fail, got %v (ready: %v)", statuses, ready)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the check to be cut off by the timeout, took %v", elapsed)
+	}
+}
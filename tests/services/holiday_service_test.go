@@ -0,0 +1,189 @@
+/**
+ *  HolidayService Tests validate the holiday cache, the embedded Norwegian fallback and the input
+ *  validation against a counting fake holidays API, so no request reaches date.nager.at.
+ *
+ *  @file       holiday_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestHolidayService_Cache          - Tests that holidays are fetched once per country and year within the TTL, and again after expiry.
+ *  - TestHolidayService_APIError       - Tests the Norwegian fallback and the *HolidayAPIError of other countries when the API fails.
+ *  - TestHolidayService_InvalidInput   - Tests that unknown countries and years out of range are rejected without a request.
+ *  - TestNorwegianHolidays             - Tests the movable holidays computed from Easter across years.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+)
+
+// holidaysResponse is a holidays API response with a national and a regional holiday, out of order.
+const holidaysResponse = `[` +
+	`{"date":"2025-05-17","localName":"Syttende mai","name":"Constitution Day","countryCode":"NO","global":true},` +
+	`{"date":"2025-03-19","localName":"Sant Josep","name":"Saint Joseph's Day","countryCode":"NO","global":false},` +
+	`{"date":"2025-01-01","localName":"Første nyttårsdag","name":"New Year's Day","countryCode":"NO","global":true}]`
+
+// newHolidayService returns a HolidayService for a fake holidays API that answers with status and
+// body, counting the requests in calls.
+func newHolidayService(t *testing.T, calls *int32, status int, body string, now *time.Time) *services.HolidayService {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if r.Header.Get("User-Agent") != "DailyVerse test" {
+			t.Errorf("Expected the User-Agent to identify the application, got %q", r.Header.Get("User-Agent"))
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return &services.HolidayService{
+		HTTPClient:  server.Client(),
+		HolidaysURL: server.URL,
+		UserAgent:   "DailyVerse test",
+		CacheTTL:    24 * time.Hour,
+		Now:         func() time.Time { return *now },
+	}
+}
+
+func TestHolidayService_Cache(t *testing.T) {
+	var calls int32
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	holidayService := newHolidayService(t, &calls, http.StatusOK, holidaysResponse, &now)
+	ctx := context.Background()
+
+	expected := []services.Holiday{
+		{Date: "2025-01-01", LocalName: "Første nyttårsdag", Name: "New Year's Day"},
+		{Date: "2025-05-17", LocalName: "Syttende mai", Name: "Constitution Day"},
+	}
+	for _, code := range []string{"NO", "no"} {
+		holidays, err := holidayService.GetHolidays(ctx, code, 2025)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(holidays, expected) {
+			t.Errorf("Expected the national holidays by date, got %+v", holidays)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call for a cache hit, got %d", got)
+	}
+
+	// Another year or country is a cache miss.
+	holidayService.GetHolidays(ctx, "NO", 2026)
+	holidayService.GetHolidays(ctx, "SE", 2025)
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Expected 3 upstream calls, got %d", got)
+	}
+
+	// After the TTL the holidays are fetched again.
+	now = now.Add(24 * time.Hour)
+	holidayService.GetHolidays(ctx, "NO", 2025)
+	if got := atomic.LoadInt32(&calls); got != 4 {
+		t.Errorf("Expected a new upstream call after expiry, got %d calls", got)
+	}
+}
+
+func TestHolidayService_APIError(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"ServerError", http.StatusInternalServerError, `{"message":"oops"}`},
+		{"MalformedBody", http.StatusOK, `not json`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+			holidayService := newHolidayService(t, &calls, tt.status, tt.body, &now)
+			ctx := context.Background()
+
+			var apiErr *services.HolidayAPIError
+			if _, err := holidayService.GetHolidays(ctx, "SE", 2025); !errors.As(err, &apiErr) {
+				t.Errorf("Expected a *HolidayAPIError for Sweden, got %v", err)
+			}
+
+			// Norway falls back to the embedded holidays, which are not cached.
+			for i := 0; i < 2; i++ {
+				holidays, err := holidayService.GetHolidays(ctx, "NO", 2025)
+				if err != nil || !reflect.DeepEqual(holidays, services.NorwegianHolidays(2025)) {
+					t.Errorf("Expected the embedded Norwegian holidays, got %+v (err: %v)", holidays, err)
+				}
+			}
+			if got := atomic.LoadInt32(&calls); got != 3 {
+				t.Errorf("Expected every request to try the API, got %d calls", got)
+			}
+		})
+	}
+}
+
+func TestHolidayService_InvalidInput(t *testing.T) {
+	var calls int32
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	holidayService := newHolidayService(t, &calls, http.StatusOK, holidaysResponse, &now)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		code    string
+		year    int
+		wantErr error
+	}{
+		{"UnknownCountry", "XX", 2025, services.ErrUnknownCountryCode},
+		{"TooEarly", "NO", 2014, services.ErrHolidayYearOutOfRange},
+		{"TooLate", "NO", 2036, services.ErrHolidayYearOutOfRange},
+	}
+	for _, tt := range tests {
+		if _, err := holidayService.GetHolidays(ctx, tt.code, tt.year); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+	for _, year := range []int{2015, 2035} {
+		if _, err := holidayService.GetHolidays(ctx, "NO", year); err != nil {
+			t.Errorf("Expected %d to be within range, got %v", year, err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected only the valid requests to reach the API, got %d calls", got)
+	}
+}
+
+func TestNorwegianHolidays(t *testing.T) {
+	tests := []struct {
+		year       int
+		goodFriday string
+		ascension  string
+		whitMonday string
+	}{
+		{2024, "2024-03-29", "2024-05-09", "2024-05-20"},
+		{2025, "2025-04-18", "2025-05-29", "2025-06-09"},
+		{2038, "2038-04-23", "2038-06-03", "2038-06-14"},
+	}
+	for _, tt := range tests {
+		dates := map[string]string{}
+		for _, holiday := range services.NorwegianHolidays(tt.year) {
+			dates[holiday.Name] = holiday.Date
+		}
+		if len(dates) != 12 || dates["Good Friday"] != tt.goodFriday || dates["Ascension Day"] != tt.ascension ||
+			dates["Whit Monday"] != tt.whitMonday || dates["Constitution Day"] != time.Date(tt.year, 5, 17, 0, 0, 0, 0, time.UTC).Format("2006-01-02") {
+			t.Errorf("%d: unexpected holidays %v", tt.year, dates)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+/**
+ *  JournalReminderService Tests validate the daily journal reminder scheduler: the reminder time in
+ *  each user's time zone, sending each day's reminder once, skipping users who already wrote today,
+ *  and the reminder settings of the profile. They use mock repositories, a mock EmailService and a
+ *  fake clock.
+ *
+ *  @file       journal_reminder_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestJournalReminderService_SendDueReminders_Schedule - Tests that reminders are sent once per day at each user's local time.
+ *  - TestJournalReminderService_SendDueReminders_Written  - Tests that users with an entry for today are skipped, unless it is in the trash.
+ *  - TestJournalReminderService_SendDueReminders_Bulk     - Tests that due reminders are sent in one batch and failed ones are retried.
+ *  - TestJournalReminderService_Run                       - Tests that the scheduler sends due reminders on each tick.
+ *  - TestProfileService_UpdateProfile_JournalReminder     - Tests validating the reminder time and enabling the reminder.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+	"proh2052-group6/tests/mocks"
+)
+
+// journalReminderFixture bundles a JournalReminderService with the mocks backing it and its fake clock.
+type journalReminderFixture struct {
+	service     *services.JournalReminderService
+	userRepo    *mocks.MockUserRepository
+	journalRepo *mocks.MockJournalRepository
+	emails      *mocks.MockEmailService
+	now         *time.Time
+}
+
+// newJournalReminderFixture creates alice (Norway, 21:00) and carol (United States, 20:30), who enabled
+// the reminder, bob (Norway), who did not, and dave (Norway, 21:00), who never verified his email.
+func newJournalReminderFixture() *journalReminderFixture {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "alice", Country: "Norway", IsVerified: true, ReminderEnabled: true, ReminderTime: "21:00"},
+		"bob@example.com":   {Email: "bob@example.com", Username: "bob", Country: "Norway", IsVerified: true, ReminderTime: "21:00"},
+		"carol@example.com": {Email: "carol@example.com", Username: "carol", TimeZone: "America/New_York", IsVerified: true, ReminderEnabled: true, ReminderTime: "20:30"},
+		"dave@example.com":  {Email: "dave@example.com", Username: "dave", Country: "Norway", ReminderEnabled: true, ReminderTime: "21:00"},
+	})
+	journalRepo := mocks.NewMockJournalRepository()
+	emails := &mocks.MockEmailService{}
+
+	now := time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC)
+	service := services.NewJournalReminderService(userRepo, journalRepo, emails).(*services.JournalReminderService)
+	service.Now = func() time.Time { return now }
+	return &journalReminderFixture{service, userRepo, journalRepo, emails, &now}
+}
+
+// recipients returns the sorted recipients of the emails sent so far, and forgets the emails.
+func (f *journalReminderFixture) recipients() string {
+	var to []string
+	for _, email := range f.emails.SentEmails {
+		to = append(to, email.To)
+	}
+	f.emails.SentEmails = nil
+	sort.Strings(to)
+	return strings.Join(to, ",")
+}
+
+// sendDueAt moves the clock to now and sends the due reminders.
+func (f *journalReminderFixture) sendDueAt(t *testing.T, now time.Time) string {
+	t.Helper()
+	*f.now = now
+	if _, err := f.service.SendDueReminders(context.Background()); err != nil {
+		t.Fatalf("Failed to send reminders at %v: %v", now, err)
+	}
+	return f.recipients()
+}
+
+func TestJournalReminderService_SendDueReminders_Schedule(t *testing.T) {
+	f := newJournalReminderFixture()
+
+	// 21:00 in Oslo is 20:00 UTC; 20:30 in New York is 01:30 UTC the next day.
+	steps := []struct {
+		now  time.Time
+		want string
+	}{
+		{time.Date(2024, 12, 2, 19, 59, 0, 0, time.UTC), ""},
+		{time.Date(2024, 12, 2, 20, 0, 0, 0, time.UTC), "alice@example.com"},
+		{time.Date(2024, 12, 2, 20, 1, 0, 0, time.UTC), ""},
+		{time.Date(2024, 12, 2, 21, 0, 0, 0, time.UTC), ""}, // More than CatchUp after alice's time.
+		{time.Date(2024, 12, 3, 1, 30, 0, 0, time.UTC), "carol@example.com"},
+		{time.Date(2024, 12, 3, 1, 31, 0, 0, time.UTC), ""},
+		{time.Date(2024, 12, 3, 20, 30, 0, 0, time.UTC), "alice@example.com"},
+	}
+	for _, step := range steps {
+		if got := f.sendDueAt(t, step.now); got != step.want {
+			t.Errorf("At %v: expected reminders to %q, got %q", step.now, step.want, got)
+		}
+	}
+
+	if sentDate := f.userRepo.Users["alice@example.com"].LastReminderSentDate; sentDate != "2024-12-03" {
+		t.Errorf("Expected alice's last reminder to be recorded for 2024-12-03, got %q", sentDate)
+	}
+	if sentDate := f.userRepo.Users["carol@example.com"].LastReminderSentDate; sentDate != "2024-12-02" {
+		t.Errorf("Expected carol's reminder to be recorded for her local day 2024-12-02, got %q", sentDate)
+	}
+	for _, email := range []string{"bob@example.com", "dave@example.com"} {
+		if sentDate := f.userRepo.Users[email].LastReminderSentDate; sentDate != "" {
+			t.Errorf("Expected no reminder for %s, got one on %s", email, sentDate)
+		}
+	}
+}
+
+func TestJournalReminderService_SendDueReminders_Written(t *testing.T) {
+	f := newJournalReminderFixture()
+	ctx := context.Background()
+
+	// Alice wrote today; carol's entry for today is in the trash, and her entry for yesterday does not count.
+	deletedAt := time.Date(2024, 12, 2, 12, 0, 0, 0, time.UTC)
+	f.journalRepo.CreateJournal(ctx, &models.Journal{Email: "alice@example.com", Date: "2024-12-02", Content: "Entry"})
+	f.journalRepo.CreateJournal(ctx, &models.Journal{Email: "carol@example.com", Date: "2024-12-02", Content: "Entry", DeletedAt: &deletedAt})
+	f.journalRepo.CreateJournal(ctx, &models.Journal{Email: "carol@example.com", Date: "2024-12-01", Content: "Entry"})
+	f.userRepo.Users["carol@example.com"].ReminderTime = "15:00"
+
+	if got := f.sendDueAt(t, time.Date(2024, 12, 2, 20, 0, 0, 0, time.UTC)); got != "carol@example.com" {
+		t.Errorf("Expected only carol to be reminded, got %q", got)
+	}
+	if sentDate := f.userRepo.Users["alice@example.com"].LastReminderSentDate; sentDate != "" {
+		t.Errorf("Expected no reminder to be recorded for alice, got %q", sentDate)
+	}
+
+	// A repository failure skips the run without recording anything.
+	f.journalRepo.Err = errors.New("unavailable")
+	if got := f.sendDueAt(t, time.Date(2024, 12, 3, 20, 0, 0, 0, time.UTC)); got != "" {
+		t.Errorf("Expected no reminders while journals cannot be checked, got %q", got)
+	}
+}
+
+func TestJournalReminderService_SendDueReminders_Bulk(t *testing.T) {
+	f := newJournalReminderFixture()
+	f.userRepo.Users["carol@example.com"].TimeZone = "Europe/Oslo"
+	f.userRepo.Users["carol@example.com"].ReminderTime = "21:00"
+	f.emails.FailFor = map[string]error{"carol@example.com": errors.New("550 mailbox unavailable")}
+
+	*f.now = time.Date(2024, 12, 2, 20, 0, 0, 0, time.UTC)
+	sent, err := f.service.SendDueReminders(context.Background())
+	if err != nil || sent != 1 {
+		t.Fatalf("Expected 1 reminder to be sent, got %d (err: %v)", sent, err)
+	}
+	if len(f.emails.BulkSends) != 1 || len(f.emails.BulkSends[0]) != 1 || f.emails.BulkSends[0][0].To != "alice@example.com" {
+		t.Errorf("Expected alice's reminder in a single batch, got %+v", f.emails.BulkSends)
+	}
+	if sentDate := f.userRepo.Users["carol@example.com"].LastReminderSentDate; sentDate != "" {
+		t.Errorf("Expected carol's failed reminder not to be recorded, got %q", sentDate)
+	}
+
+	// The failed reminder is sent on the next run, within CatchUp.
+	f.emails.FailFor = nil
+	f.recipients()
+	if got := f.sendDueAt(t, time.Date(2024, 12, 2, 20, 1, 0, 0, time.UTC)); got != "carol@example.com" {
+		t.Errorf("Expected carol's reminder to be retried, got %q", got)
+	}
+}
+
+func TestJournalReminderService_Run(t *testing.T) {
+	f := newJournalReminderFixture()
+	*f.now = time.Date(2024, 12, 2, 20, 0, 0, 0, time.UTC)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		f.service.Run(ctx, ticks)
+		close(done)
+	}()
+
+	ticks <- *f.now
+	ticks <- *f.now // The second tick is only received once the first one has been handled.
+	cancel()
+	<-done
+
+	if got := f.recipients(); got != "alice@example.com" {
+		t.Errorf("Expected one reminder to alice, got %q", got)
+	}
+}
+
+func TestProfileService_UpdateProfile_JournalReminder(t *testing.T) {
+	hashed, _ := utils.HashPassword("Password123!")
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"bob@example.com": {Email: "bob@example.com", Username: "bob", Password: hashed},
+	})
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+	ctx := context.Background()
+	update := func(fields map[string]interface{}) error {
+		fields["CurrentPassword"] = "Password123!"
+		return profileService.UpdateProfile(ctx, "bob@example.com", fields)
+	}
+
+	for _, invalid := range []interface{}{"25:00", "9pm", "21:00:00", 21} {
+		if err := update(map[string]interface{}{"ReminderTime": invalid}); !errors.Is(err, services.ErrInvalidReminderTime) {
+			t.Errorf("Expected ErrInvalidReminderTime for %v, got %v", invalid, err)
+		}
+	}
+	if err := update(map[string]interface{}{"ReminderEnabled": true}); !errors.Is(err, services.ErrReminderTimeRequired) {
+		t.Errorf("Expected ErrReminderTimeRequired without a time, got %v", err)
+	}
+
+	err := update(map[string]interface{}{"ReminderEnabled": true, "ReminderTime": " 7:30 ", "LastReminderSentDate": "2099-01-05"})
+	if err != nil {
+		t.Fatalf("Failed to enable the reminder: %v", err)
+	}
+	user := userRepo.Users["bob@example.com"]
+	if !user.ReminderEnabled || user.ReminderTime != "07:30" || user.LastReminderSentDate != "" {
+		t.Errorf("Expected the reminder at 07:30 without changing LastReminderSentDate, got %+v", user)
+	}
+	if profile, _ := profileService.GetProfile(ctx, "bob@example.com"); !profile.ReminderEnabled || profile.ReminderTime != "07:30" {
+		t.Errorf("Expected the profile to report the reminder, got %+v", profile)
+	}
+
+	// The time cannot be cleared while the reminder is enabled, but can once it is disabled.
+	if err := update(map[string]interface{}{"ReminderTime": ""}); !errors.Is(err, services.ErrReminderTimeRequired) {
+		t.Errorf("Expected ErrReminderTimeRequired when clearing the time, got %v", err)
+	}
+	if err := update(map[string]interface{}{"ReminderEnabled": false, "ReminderTime": ""}); err != nil || userRepo.Users["bob@example.com"].ReminderEnabled {
+		t.Errorf("Expected the reminder to be disabled, got %v", err)
+	}
+}
@@ -0,0 +1,1071 @@
+/**
+ *  JournalService Tests validate the business logic of JournalService, in particular journal search
+ *  and the monthly mood and streak statistics.
+ *  They use a mock JournalRepository to isolate the service from Firestore.
+ *
+ *  @file       journal_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestJournalService_SearchJournals              - Tests text and date filtering with newest-first ordering.
+ *  - TestJournalService_SearchJournals_InvalidDates - Tests rejection of malformed or inverted date ranges.
+ *  - TestJournalService_CreateJournal_OnePerDate    - Tests duplicate rejection and upsert of same-date journals.
+ *  - TestJournalService_ExportJournals_JSON         - Tests the JSON export is an oldest-first array filtered by date.
+ *  - TestJournalService_ExportJournals_Markdown     - Tests dated Markdown sections and escaping of special characters.
+ *  - TestJournalService_ExportJournals_Invalid      - Tests rejection of unknown formats and malformed dates.
+ *  - TestJournalService_MoodAndTags                 - Tests normalization and rejection of moods and tags.
+ *  - TestJournalService_GetJournalStats             - Tests counts, mood distribution and streaks over a synthetic month.
+ *  - TestJournalService_TrashAndRestore             - Tests deleted entries leave every listing, appear in the trash and come back on restore.
+ *  - TestJournalService_RestoreJournal_Errors       - Tests restoring live, expired or date-conflicting entries fails.
+ *  - TestJournalService_PurgeDeletedJournals        - Tests only entries past the retention are purged, across users.
+ *  - TestJournalService_FutureDates_TimeZones       - Tests entries may be dated up to today in the user's time zone, or UTC+14 when it is unknown.
+ *  - TestJournalService_Attachments                 - Tests attachments survive saving and the trash, are capped, and are deleted from storage on purge.
+ *  - TestJournalService_AttachmentDownload          - Tests downloading attachments, including ones stored before they were kept private.
+ *  - TestJournalService_Encryption                  - Tests content round-trips encrypted, is never stored in plaintext, is searchable and exportable,
+ *                                                     and survives an email change.
+ *  - TestJournalService_Encryption_Legacy           - Tests legacy plaintext entries are read as they are and encrypted on their next update.
+ *  - TestJournalService_Encryption_WrongKey         - Tests entries sealed with another master key fail with ErrJournalDecryption.
+ *  - TestJournalService_ImportJournals_JSON         - Tests valid rows are imported and malformed, invalid and same-date rows skipped with a reason.
+ *  - TestJournalService_ImportJournals_CSV          - Tests header columns in any order and case, quoted content and short rows.
+ *  - TestJournalService_ImportJournals_Merge        - Tests merge appends rows to existing and earlier same-date entries, once and within the length limit.
+ *  - TestJournalService_ImportJournals_Cap          - Tests 1000 rows are imported, one more rejects the import, and endless files are not read to the end.
+ *  - TestJournalService_ImportJournals_InvalidFiles - Tests unparsable files and unknown formats fail without storing anything.
+ *  - TestJournalService_ImportJournals_Encrypted    - Tests imported and merged entries are stored encrypted.
+ *
+ *  @dependencies
+ *  - mocks.NewMockJournalRepository: Mock implementation of JournalRepository for testing.
+ *  - mocks.NewMockUserRepository: Provides the time zones of users.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/validate"
+	"proh2052-group6/tests/mocks"
+)
+
+// newSearchJournalService creates a JournalService with a few journals for two users.
+func newSearchJournalService(t *testing.T) services.JournalServiceInterface {
+	t.Helper()
+	journalService := services.NewJournalService(mocks.NewMockJournalRepository(), nil)
+
+	journals := []models.Journal{
+		{Email: "user@example.com", Date: "2024-03-01", Content: "Started a new book"},
+		{Email: "user@example.com", Date: "2024-03-15", Content: "Finished the Book club reading"},
+		{Email: "user@example.com", Date: "2024-04-02", Content: "Went skiing"},
+		{Email: "other@example.com", Date: "2024-03-10", Content: "My book"},
+	}
+	for i := range journals {
+		if err := journalService.CreateJournal(context.Background(), &journals[i]); err != nil {
+			t.Fatalf("Failed to create journal: %v", err)
+		}
+	}
+	return journalService
+}
+
+func TestJournalService_SearchJournals(t *testing.T) {
+	journalService := newSearchJournalService(t)
+
+	journals, err := journalService.SearchJournals(context.Background(), "user@example.com", "book", "", "", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(journals) != 2 || journals[0].Date != "2024-03-15" || journals[1].Date != "2024-03-01" {
+		t.Errorf("Expected the two 'book' entries newest first, got %+v", journals)
+	}
+
+	journals, err = journalService.SearchJournals(context.Background(), "user@example.com", "", "2024-03-10", "2024-04-30", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(journals) != 2 || journals[0].Date != "2024-04-02" {
+		t.Errorf("Expected 2 entries in the date range, got %+v", journals)
+	}
+
+	journals, _ = journalService.SearchJournals(context.Background(), "user@example.com", "", "", "", 1)
+	if len(journals) != 1 || journals[0].Date != "2024-04-02" {
+		t.Errorf("Expected only the newest entry, got %+v", journals)
+	}
+}
+
+func TestJournalService_SearchJournals_InvalidDates(t *testing.T) {
+	journalService := newSearchJournalService(t)
+
+	if _, err := journalService.SearchJournals(context.Background(), "user@example.com", "", "2024-13-01", "", 0); err == nil {
+		t.Errorf("Expected an error for an invalid from date")
+	}
+	if _, err := journalService.SearchJournals(context.Background(), "user@example.com", "", "2024-04-01", "2024-03-01", 0); err == nil {
+		t.Errorf("Expected an error when from is after to")
+	}
+}
+
+func TestJournalService_CreateJournal_OnePerDate(t *testing.T) {
+	mockJournalRepo := mocks.NewMockJournalRepository()
+	journalService := services.NewJournalService(mockJournalRepo, nil)
+
+	first := &models.Journal{Email: "user@example.com", Date: "2024-03-01", Content: "Morning"}
+	if err := journalService.CreateJournal(context.Background(), first); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+
+	duplicate := &models.Journal{Email: "user@example.com", Date: "2024-03-01", Content: "Evening"}
+	if err := journalService.CreateJournal(context.Background(), duplicate); err == nil || err.Error() != "A journal already exists for this date" {
+		t.Errorf("Expected duplicate journal to be rejected, got %v", err)
+	}
+
+	// Another user may still write a journal on the same date.
+	other := &models.Journal{Email: "other@example.com", Date: "2024-03-01", Content: "Hello"}
+	if err := journalService.CreateJournal(context.Background(), other); err != nil {
+		t.Errorf("Expected journal of another user to be created, got %v", err)
+	}
+
+	if err := journalService.UpsertJournal(context.Background(), duplicate); err != nil {
+		t.Fatalf("Failed to upsert journal: %v", err)
+	}
+	if duplicate.JournalID != first.JournalID {
+		t.Errorf("Expected upsert to reuse journal %s, got %s", first.JournalID, duplicate.JournalID)
+	}
+	stored, _ := mockJournalRepo.GetJournal(context.Background(), "user@example.com", first.JournalID)
+	if stored == nil || stored.Content != "Evening" {
+		t.Errorf("Expected stored journal to be overwritten, got %+v", stored)
+	}
+}
+
+func TestJournalService_ExportJournals_JSON(t *testing.T) {
+	journalService := newSearchJournalService(t)
+
+	tests := []struct {
+		name      string
+		from, to  string
+		wantDates []string
+	}{
+		{"all", "", "", []string{"2024-03-01", "2024-03-15", "2024-04-02"}},
+		{"date range", "2024-03-02", "2024-04-02", []string{"2024-03-15", "2024-04-02"}},
+		{"empty range", "2025-01-01", "", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := journalService.ExportJournals(context.Background(), "user@example.com", "json", tt.from, tt.to, &out); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var journals []models.Journal
+			if err := json.Unmarshal(out.Bytes(), &journals); err != nil {
+				t.Fatalf("Expected a JSON array, got %q: %v", out.String(), err)
+			}
+			if len(journals) != len(tt.wantDates) {
+				t.Fatalf("Expected %d journals, got %d", len(tt.wantDates), len(journals))
+			}
+			for i, date := range tt.wantDates {
+				if journals[i].Date != date || journals[i].Email != "user@example.com" || journals[i].JournalID == "" {
+					t.Errorf("Unexpected journal %d: %+v", i, journals[i])
+				}
+			}
+		})
+	}
+}
+
+func TestJournalService_ExportJournals_Markdown(t *testing.T) {
+	journalService := services.NewJournalService(mocks.NewMockJournalRepository(), nil)
+	journals := []models.Journal{
+		{Email: "user@example.com", Date: "2024-05-02", Content: "# Not a heading\n1. *bold* [link](x) <b>_x_</b> a\\b"},
+		{Email: "user@example.com", Date: "2024-05-01", Content: "Plain day"},
+		{Email: "user@example.com", Date: "2024-06-01", Content: "Outside the range"},
+	}
+	for i := range journals {
+		if err := journalService.CreateJournal(context.Background(), &journals[i]); err != nil {
+			t.Fatalf("Failed to create journal: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := journalService.ExportJournals(context.Background(), "user@example.com", "markdown", "2024-05-01", "2024-05-31", &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := "# Journal\n" +
+		"\n## 2024-05-01\n\nPlain day\n" +
+		"\n## 2024-05-02\n\n\\# Not a heading\n1\\. \\*bold\\* \\[link\\]\\(x\\) \\<b\\>\\_x\\_\\</b\\> a\\\\b\n"
+	if out.String() != want {
+		t.Errorf("Unexpected Markdown export:\ngot:  %q\nwant: %q", out.String(), want)
+	}
+	if strings.Contains(out.String(), "Outside the range") {
+		t.Errorf("Expected entries outside the date range to be left out")
+	}
+}
+
+func TestJournalService_ExportJournals_Invalid(t *testing.T) {
+	journalService := newSearchJournalService(t)
+
+	tests := []struct {
+		name     string
+		format   string
+		from, to string
+		wantErr  string
+	}{
+		{"unknown format", "csv", "", "", "format must be 'json' or 'markdown'"},
+		{"invalid date", "json", "01/03/2024", "", "Invalid input: from must be a date in YYYY-MM-DD format"},
+		{"inverted range", "markdown", "2024-04-01", "2024-03-01", "Invalid input: from must not be after to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := journalService.ExportJournals(context.Background(), "user@example.com", tt.format, tt.from, tt.to, &out)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Expected error %q, got %v", tt.wantErr, err)
+			}
+			if out.Len() != 0 {
+				t.Errorf("Expected nothing to be written, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestJournalService_MoodAndTags(t *testing.T) {
+	journalService := services.NewJournalService(mocks.NewMockJournalRepository(), nil)
+
+	journal := &models.Journal{Email: "user@example.com", Date: "2024-05-01", Content: "Entry", Mood: " Great ", Tags: []string{"Work", "gym "}}
+	if err := journalService.CreateJournal(context.Background(), journal); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	if journal.Mood != "great" || journal.Tags[0] != "work" || journal.Tags[1] != "gym" {
+		t.Errorf("Expected the mood and tags to be lowercased, got %q %v", journal.Mood, journal.Tags)
+	}
+
+	tests := []struct {
+		name    string
+		journal models.Journal
+		err     string
+	}{
+		{"unknown mood", models.Journal{Mood: "ecstatic"}, "Mood must be one of great, good, neutral, bad or awful"},
+		{"duplicate tags", models.Journal{Tags: []string{"work", "Work"}}, "Duplicate tags are not allowed"},
+		{"too many tags", models.Journal{Tags: strings.Split("a,b,c,d,e,f,g,h,i,j,k", ",")}, "A journal entry can have at most 10 tags"},
+	}
+	for _, tt := range tests {
+		tt.journal.Email, tt.journal.Date, tt.journal.Content = "user@example.com", "2024-05-02", "Entry"
+		if err := journalService.CreateJournal(context.Background(), &tt.journal); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: expected %q on create, got %v", tt.name, tt.err, err)
+		}
+		tt.journal.JournalID = journal.JournalID
+		if err := journalService.UpdateJournal(context.Background(), &tt.journal); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: expected %q on update, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestJournalService_GetJournalStats(t *testing.T) {
+	service := services.NewJournalService(mocks.NewMockJournalRepository(), nil).(*services.JournalService)
+	service.Now = func() time.Time { return time.Date(2024, 5, 20, 21, 0, 0, 0, time.UTC) }
+
+	// May 2024: a 4-day run (2-5), a 6-day run (10-15) and a run from the 17th up to yesterday,
+	// the 19th, while today's entry is not written yet. April 30 must not extend the May streaks.
+	moods := map[string]string{
+		"2024-04-30": services.MoodGood,
+		"2024-05-02": services.MoodGreat, "2024-05-03": services.MoodGood, "2024-05-04": "", "2024-05-05": services.MoodBad,
+		"2024-05-10": services.MoodGood, "2024-05-11": services.MoodGood, "2024-05-12": services.MoodNeutral,
+		"2024-05-13": services.MoodAwful, "2024-05-14": services.MoodGood, "2024-05-15": services.MoodGreat,
+		"2024-05-17": services.MoodNeutral, "2024-05-18": services.MoodGood, "2024-05-19": services.MoodGood,
+	}
+	for date, mood := range moods {
+		journal := &models.Journal{Email: "user@example.com", Date: date, Content: "Entry", Mood: mood}
+		if err := service.CreateJournal(context.Background(), journal); err != nil {
+			t.Fatalf("Failed to create journal: %v", err)
+		}
+	}
+	service.CreateJournal(context.Background(), &models.Journal{Email: "other@example.com", Date: "2024-05-20", Content: "Entry", Mood: services.MoodBad})
+
+	stats, err := service.GetJournalStats(context.Background(), "user@example.com", "2024-05")
+	if err != nil {
+		t.Fatalf("Failed to get journal stats: %v", err)
+	}
+	expectedMoods := map[string]int{services.MoodGreat: 2, services.MoodGood: 6, services.MoodNeutral: 2, services.MoodBad: 1, services.MoodAwful: 1}
+	if stats.Month != "2024-05" || stats.EntryCount != 13 {
+		t.Errorf("Expected 13 entries in 2024-05, got %d in %s", stats.EntryCount, stats.Month)
+	}
+	for mood, count := range expectedMoods {
+		if stats.MoodDistribution[mood] != count {
+			t.Errorf("Expected %d %s entries, got %d", count, mood, stats.MoodDistribution[mood])
+		}
+	}
+	if stats.LongestStreak != 6 || stats.CurrentStreak != 3 {
+		t.Errorf("Expected longest streak 6 and current streak 3, got %d and %d", stats.LongestStreak, stats.CurrentStreak)
+	}
+
+	// Writing today's entry extends the current streak.
+	service.CreateJournal(context.Background(), &models.Journal{Email: "user@example.com", Date: "2024-05-20", Content: "Entry", Mood: services.MoodGood})
+	stats, _ = service.GetJournalStats(context.Background(), "user@example.com", "")
+	if stats.Month != "2024-05" || stats.CurrentStreak != 4 {
+		t.Errorf("Expected the current month with a current streak of 4, got %s with %d", stats.Month, stats.CurrentStreak)
+	}
+
+	// A past month's current streak ends on its last day.
+	stats, _ = service.GetJournalStats(context.Background(), "user@example.com", "2024-04")
+	if stats.EntryCount != 1 || stats.CurrentStreak != 1 || stats.LongestStreak != 1 {
+		t.Errorf("Expected one entry and streaks of 1 in April, got %+v", stats)
+	}
+
+	if _, err := service.GetJournalStats(context.Background(), "user@example.com", "2024-5"); err == nil {
+		t.Errorf("Expected an error for an invalid month")
+	}
+}
+
+// newClockedJournalService creates a JournalService on a mock repository whose clock reads *now.
+func newClockedJournalService(now *time.Time) (*services.JournalService, *mocks.MockJournalRepository) {
+	journalRepo := mocks.NewMockJournalRepository()
+	service := services.NewJournalService(journalRepo, nil).(*services.JournalService)
+	service.Now = func() time.Time { return *now }
+	return service, journalRepo
+}
+
+func TestJournalService_TrashAndRestore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service, _ := newClockedJournalService(&now)
+
+	journal := &models.Journal{Email: "user@example.com", Date: "2024-05-30", Content: "A rainy walk"}
+	if err := service.CreateJournal(ctx, journal); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	if err := service.DeleteJournal(ctx, "user@example.com", journal.JournalID); err != nil {
+		t.Fatalf("Failed to delete journal: %v", err)
+	}
+
+	// The deleted entry is gone from every listing and can no longer be read, updated or deleted.
+	if journals, _ := service.GetAllJournals(ctx, "user@example.com"); len(journals) != 0 {
+		t.Errorf("Expected no journals after delete, got %+v", journals)
+	}
+	if journals, _ := service.SearchJournals(ctx, "user@example.com", "rainy", "", "", 0); len(journals) != 0 {
+		t.Errorf("Expected no search results after delete, got %+v", journals)
+	}
+	if _, err := service.GetJournal(ctx, "user@example.com", journal.JournalID); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound getting a deleted journal, got %v", err)
+	}
+	update := &models.Journal{JournalID: journal.JournalID, Email: "user@example.com", Date: "2024-05-30", Content: "Edited"}
+	if err := service.UpdateJournal(ctx, update); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a deleted journal, got %v", err)
+	}
+	if err := service.DeleteJournal(ctx, "user@example.com", journal.JournalID); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting a journal twice, got %v", err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	trash, err := service.GetJournalTrash(ctx, "user@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(trash) != 1 || trash[0].JournalID != journal.JournalID || trash[0].DeletedAt == nil {
+		t.Fatalf("Expected the deleted journal in the trash, got %+v", trash)
+	}
+	if trash, _ := service.GetJournalTrash(ctx, "other@example.com"); len(trash) != 0 {
+		t.Errorf("Expected another user's trash to be empty, got %+v", trash)
+	}
+
+	restored, err := service.RestoreJournal(ctx, "user@example.com", journal.JournalID)
+	if err != nil {
+		t.Fatalf("Failed to restore journal: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Content != "A rainy walk" {
+		t.Errorf("Expected the original entry back out of the trash, got %+v", restored)
+	}
+	if journals, _ := service.GetAllJournals(ctx, "user@example.com"); len(journals) != 1 {
+		t.Errorf("Expected the restored journal in the list, got %+v", journals)
+	}
+	if trash, _ := service.GetJournalTrash(ctx, "user@example.com"); len(trash) != 0 {
+		t.Errorf("Expected an empty trash after restore, got %+v", trash)
+	}
+}
+
+func TestJournalService_RestoreJournal_Errors(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service, _ := newClockedJournalService(&now)
+
+	deleted := &models.Journal{Email: "user@example.com", Date: "2024-05-30", Content: "First draft"}
+	service.CreateJournal(ctx, deleted)
+	service.DeleteJournal(ctx, "user@example.com", deleted.JournalID)
+
+	// A deleted entry frees its date, but cannot be restored over the entry written there since.
+	replacement := &models.Journal{Email: "user@example.com", Date: "2024-05-30", Content: "Second draft"}
+	if err := service.CreateJournal(ctx, replacement); err != nil {
+		t.Fatalf("Expected the date of a deleted journal to be free, got %v", err)
+	}
+	if _, err := service.RestoreJournal(ctx, "user@example.com", deleted.JournalID); err == nil || err.Error() != "A journal already exists for this date" {
+		t.Errorf("Expected a date conflict, got %v", err)
+	}
+	if _, err := service.RestoreJournal(ctx, "user@example.com", replacement.JournalID); err == nil || err.Error() != "Journal is not in the trash" {
+		t.Errorf("Expected an error restoring a live journal, got %v", err)
+	}
+	if _, err := service.RestoreJournal(ctx, "other@example.com", deleted.JournalID); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound restoring another user's journal, got %v", err)
+	}
+
+	// Past the retention the entry is treated as gone, even before the purge has run.
+	service.DeleteJournal(ctx, "user@example.com", replacement.JournalID)
+	now = now.Add(services.JournalTrashRetention)
+	if _, err := service.RestoreJournal(ctx, "user@example.com", replacement.JournalID); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound restoring an expired journal, got %v", err)
+	}
+	if trash, _ := service.GetJournalTrash(ctx, "user@example.com"); len(trash) != 0 {
+		t.Errorf("Expected expired journals to be left out of the trash, got %+v", trash)
+	}
+}
+
+func TestJournalService_PurgeDeletedJournals(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service, journalRepo := newClockedJournalService(&now)
+
+	create := func(email, date string) *models.Journal {
+		journal := &models.Journal{Email: email, Date: date, Content: "Entry"}
+		if err := service.CreateJournal(ctx, journal); err != nil {
+			t.Fatalf("Failed to create journal: %v", err)
+		}
+		return journal
+	}
+	oldest := create("user@example.com", "2024-05-01")
+	other := create("other@example.com", "2024-05-01")
+	recent := create("user@example.com", "2024-05-02")
+	live := create("user@example.com", "2024-05-03")
+
+	service.DeleteJournal(ctx, "user@example.com", oldest.JournalID)
+	service.DeleteJournal(ctx, "other@example.com", other.JournalID)
+	now = now.Add(10 * 24 * time.Hour)
+	service.DeleteJournal(ctx, "user@example.com", recent.JournalID)
+
+	// 30 days after the first deletions, only the two oldest have expired.
+	now = now.Add(services.JournalTrashRetention - 10*24*time.Hour + time.Minute)
+	purged, err := service.PurgeDeletedJournals(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("Expected 2 journals purged, got %d", purged)
+	}
+	for _, id := range []string{oldest.JournalID, other.JournalID} {
+		if _, exists := journalRepo.Journals[id]; exists {
+			t.Errorf("Expected journal %s to be purged", id)
+		}
+	}
+	for _, id := range []string{recent.JournalID, live.JournalID} {
+		if _, exists := journalRepo.Journals[id]; !exists {
+			t.Errorf("Expected journal %s to be kept", id)
+		}
+	}
+
+	// A purge tick runs the same purge in the background.
+	now = now.Add(10 * 24 * time.Hour)
+	ticks := make(chan time.Time)
+	purgeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		service.RunTrashPurge(purgeCtx, ticks)
+		close(done)
+	}()
+	ticks <- now
+	cancel()
+	<-done
+	if _, exists := journalRepo.Journals[recent.JournalID]; exists {
+		t.Errorf("Expected the purge tick to delete the expired journal")
+	}
+	if len(journalRepo.Journals) != 1 {
+		t.Errorf("Expected only the live journal to remain, got %d journals", len(journalRepo.Journals))
+	}
+}
+
+func TestJournalService_FutureDates_TimeZones(t *testing.T) {
+	ctx := context.Background()
+	// 12:30 UTC on 1 June is 00:30 on 2 June in Auckland and 05:30 on 1 June in Los Angeles.
+	now := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"kiri@example.com": {Email: "kiri@example.com", TimeZone: "Pacific/Auckland"},
+		"sam@example.com":  {Email: "sam@example.com", TimeZone: "America/Los_Angeles"},
+	})
+	service, _ := newClockedJournalService(&now)
+	service.UserRepo = userRepo
+
+	tests := []struct {
+		email, date, wantErr string
+	}{
+		{"kiri@example.com", "2024-06-02", ""},
+		{"kiri@example.com", "2024-06-03", "Invalid input: date must not be in the future"},
+		{"sam@example.com", "2024-06-01", ""},
+		{"sam@example.com", "2024-06-02", "Invalid input: date must not be in the future"},
+		// Users without a known time zone may write up to the date it is in UTC+14.
+		{"unknown@example.com", "2024-06-02", ""},
+		{"unknown@example.com", "2024-06-03", "Invalid input: date must not be in the future"},
+	}
+	for _, tt := range tests {
+		err := service.CreateJournal(ctx, &models.Journal{Email: tt.email, Date: tt.date, Content: "Evening thoughts"})
+		if (err == nil && tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+			t.Errorf("%s on %s: expected error %q, got %v", tt.email, tt.date, tt.wantErr, err)
+		}
+	}
+
+	// Moving an entry to a future date is rejected too.
+	journal := &models.Journal{Email: "sam@example.com", Date: "2024-05-31", Content: "Yesterday"}
+	if err := service.CreateJournal(ctx, journal); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	journal.Date = "2024-06-02"
+	if err := service.UpdateJournal(ctx, journal); err == nil || err.Error() != "Invalid input: date must not be in the future" {
+		t.Errorf("Expected moving the entry to tomorrow to be rejected, got %v", err)
+	}
+
+	// An unreachable user repository is reported as such, not as an invalid date.
+	userRepo.Err = repositories.ErrUnavailable
+	if err := service.CreateJournal(ctx, &models.Journal{Email: "sam@example.com", Date: "2024-05-30", Content: "Entry"}); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestJournalService_Attachments(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service, journalRepo := newClockedJournalService(&now)
+	png := []byte("\x89PNG\r\n\x1a\n" + "image data")
+
+	journal := &models.Journal{Email: "user@example.com", Date: "2024-05-30", Content: "A rainy walk"}
+	if err := service.CreateJournal(ctx, journal); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+
+	// Without a StorageService, uploads are disabled.
+	if _, err := service.AddAttachment(ctx, "user@example.com", journal.JournalID, png); !errors.Is(err, services.ErrAttachmentUploadsDisabled) {
+		t.Fatalf("Expected ErrAttachmentUploadsDisabled, got %v", err)
+	}
+
+	storage := mocks.NewMockStorageService()
+	service.Storage = storage
+	for i := 0; i < services.MaxJournalAttachments; i++ {
+		if _, err := service.AddAttachment(ctx, "user@example.com", journal.JournalID, png); err != nil {
+			t.Fatalf("Failed to add attachment %d: %v", i, err)
+		}
+	}
+	if _, err := service.AddAttachment(ctx, "user@example.com", journal.JournalID, png); !errors.Is(err, services.ErrTooManyAttachments) {
+		t.Errorf("Expected ErrTooManyAttachments, got %v", err)
+	}
+	if len(storage.Objects) != services.MaxJournalAttachments {
+		t.Fatalf("Expected %d stored images, got %d", services.MaxJournalAttachments, len(storage.Objects))
+	}
+
+	// Saving the entry cannot change or drop its attachments.
+	update := &models.Journal{Email: "user@example.com", JournalID: journal.JournalID, Date: "2024-05-30", Content: "A sunny walk",
+		Attachments: []models.Attachment{{ID: "forged", URL: "https://example.com/forged.png"}}}
+	if err := service.UpdateJournal(ctx, update); err != nil {
+		t.Fatalf("Failed to update journal: %v", err)
+	}
+	saved, _ := service.GetJournal(ctx, "user@example.com", journal.JournalID)
+	if len(saved.Attachments) != services.MaxJournalAttachments || saved.Attachments[0].ID == "forged" {
+		t.Errorf("Expected the update to keep the attachments, got %+v", saved.Attachments)
+	}
+
+	// Moving the entry to the trash keeps its images, so a restored entry still has them.
+	service.DeleteJournal(ctx, "user@example.com", journal.JournalID)
+	if len(storage.Objects) != services.MaxJournalAttachments {
+		t.Errorf("Expected the trash to keep the stored images, got %d", len(storage.Objects))
+	}
+	restored, err := service.RestoreJournal(ctx, "user@example.com", journal.JournalID)
+	if err != nil {
+		t.Fatalf("Failed to restore journal: %v", err)
+	}
+	if len(restored.Attachments) != services.MaxJournalAttachments {
+		t.Errorf("Expected the restored journal to keep its attachments, got %d", len(restored.Attachments))
+	}
+
+	// Purging the deleted entry deletes its images from storage.
+	service.DeleteJournal(ctx, "user@example.com", journal.JournalID)
+	now = now.Add(services.JournalTrashRetention + time.Minute)
+	if purged, err := service.PurgeDeletedJournals(ctx); err != nil || purged != 1 {
+		t.Fatalf("Expected 1 journal purged, got %d, %v", purged, err)
+	}
+	if _, exists := journalRepo.Journals[journal.JournalID]; exists {
+		t.Errorf("Expected the journal to be purged")
+	}
+	if len(storage.Objects) != 0 {
+		t.Errorf("Expected the purge to delete the stored images, got %d left", len(storage.Objects))
+	}
+}
+
+func TestJournalService_AttachmentDownload(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service, journalRepo := newClockedJournalService(&now)
+	storage := mocks.NewMockStorageService()
+	service.Storage = storage
+	png := []byte("\x89PNG\r\n\x1a\n" + "image data")
+
+	// An attachment stored with its public storage URL, before attachments were kept private.
+	legacyURL := mocks.MockStorageBaseURL + "journals/legacy.png"
+	storage.Objects[legacyURL] = mocks.MockStoredObject{ContentType: "image/png", Data: png}
+	journalRepo.Journals["journal1"] = &models.Journal{JournalID: "journal1", Email: "user@example.com", Date: "2024-05-30", Content: "A rainy walk",
+		Attachments: []models.Attachment{{ID: "legacy", URL: legacyURL, ContentType: "image/png", Size: len(png)}}}
+
+	journal, err := service.GetJournal(ctx, "user@example.com", "journal1")
+	if err != nil {
+		t.Fatalf("Failed to get journal: %v", err)
+	}
+	if url := journal.Attachments[0].URL; url != services.AttachmentURL("journal1", "legacy") {
+		t.Errorf("Expected the legacy attachment to be served by the API, got %s", url)
+	}
+	attachment, data, err := service.GetAttachment(ctx, "user@example.com", "journal1", "legacy")
+	if err != nil || attachment.ContentType != "image/png" || !bytes.Equal(data, png) {
+		t.Fatalf("Expected the legacy image, got %+v, %q, %v", attachment, data, err)
+	}
+
+	// A new attachment is stored privately; saving the entry keeps the storage URL of both.
+	added, err := service.AddAttachment(ctx, "user@example.com", "journal1", png)
+	if err != nil {
+		t.Fatalf("Failed to add attachment: %v", err)
+	}
+	if object := journalRepo.Journals["journal1"].Attachments[1].Object; !storage.Objects[object].Private {
+		t.Errorf("Expected the new image to be stored privately at %q", object)
+	}
+	if _, data, err := service.GetAttachment(ctx, "user@example.com", "journal1", added.ID); err != nil || !bytes.Equal(data, png) {
+		t.Errorf("Expected the new image, got %q, %v", data, err)
+	}
+	if _, _, err := service.GetAttachment(ctx, "user@example.com", "journal1", "legacy"); err != nil {
+		t.Errorf("Expected the legacy image to stay readable after the entry was saved, got %v", err)
+	}
+
+	// Other users and unknown attachments are not found.
+	if _, _, err := service.GetAttachment(ctx, "other@example.com", "journal1", "legacy"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another user, got %v", err)
+	}
+	if _, _, err := service.GetAttachment(ctx, "user@example.com", "journal1", "missing"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown attachment, got %v", err)
+	}
+
+	// Deleting the legacy attachment deletes its image from storage.
+	if err := service.DeleteAttachment(ctx, "user@example.com", "journal1", "legacy"); err != nil {
+		t.Fatalf("Failed to delete attachment: %v", err)
+	}
+	if _, stored := storage.Objects[legacyURL]; stored {
+		t.Errorf("Expected the legacy image to be deleted from storage")
+	}
+}
+
+// newEncryptedJournalService creates a JournalService encrypting with a master key of repeated key bytes.
+func newEncryptedJournalService(t *testing.T, journalRepo *mocks.MockJournalRepository, key byte) *services.JournalService {
+	t.Helper()
+	cipher, err := services.NewJournalCipher(bytes.Repeat([]byte{key}, 32))
+	if err != nil {
+		t.Fatalf("Failed to create cipher: %v", err)
+	}
+	service := services.NewJournalService(journalRepo, nil).(*services.JournalService)
+	service.Cipher = cipher
+	return service
+}
+
+// assertSealed fails unless every stored journal is encrypted and none contains plaintext.
+func assertSealed(t *testing.T, journalRepo *mocks.MockJournalRepository, plaintext ...string) {
+	t.Helper()
+	for id, journal := range journalRepo.Journals {
+		if journal.Encryption != services.JournalEncryptedV1 {
+			t.Errorf("Expected journal %s to be stored encrypted, got version %d", id, journal.Encryption)
+		}
+		for _, text := range plaintext {
+			if strings.Contains(journal.Content, text) {
+				t.Errorf("Expected journal %s not to store %q, got %q", id, text, journal.Content)
+			}
+		}
+	}
+}
+
+func TestJournalService_Encryption(t *testing.T) {
+	ctx := context.Background()
+	journalRepo := mocks.NewMockJournalRepository()
+	service := newEncryptedJournalService(t, journalRepo, 'k')
+
+	if _, err := services.NewJournalCipher([]byte("short")); err == nil {
+		t.Errorf("Expected a master key of the wrong size to be rejected")
+	}
+
+	journal := &models.Journal{Email: "user@example.com", Date: "2024-05-30", Content: "A secret walk"}
+	if err := service.CreateJournal(ctx, journal); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	if journal.Content != "A secret walk" || journal.Encryption != services.JournalPlaintext {
+		t.Errorf("Expected the caller's journal to stay in plaintext, got %+v", journal)
+	}
+	assertSealed(t, journalRepo, "secret")
+	if stored := journalRepo.Journals[journal.JournalID]; stored.KeyEmail != "user@example.com" {
+		t.Errorf("Expected the key email to be stored, got %q", stored.KeyEmail)
+	}
+
+	got, err := service.GetJournal(ctx, "user@example.com", journal.JournalID)
+	if err != nil || got.Content != "A secret walk" {
+		t.Fatalf("Expected the decrypted content, got %+v, %v", got, err)
+	}
+
+	// Updates, the trash and a second entry are all stored encrypted.
+	update := &models.Journal{Email: "user@example.com", JournalID: journal.JournalID, Date: "2024-05-30", Content: "A hidden walk"}
+	if err := service.UpdateJournal(ctx, update); err != nil {
+		t.Fatalf("Failed to update journal: %v", err)
+	}
+	if err := service.CreateJournal(ctx, &models.Journal{Email: "user@example.com", Date: "2024-05-31", Content: "Private thoughts"}); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	if err := service.DeleteJournal(ctx, "user@example.com", journal.JournalID); err != nil {
+		t.Fatalf("Failed to delete journal: %v", err)
+	}
+	assertSealed(t, journalRepo, "hidden", "Private")
+	trash, err := service.GetJournalTrash(ctx, "user@example.com")
+	if err != nil || len(trash) != 1 || trash[0].Content != "A hidden walk" {
+		t.Fatalf("Expected the decrypted entry in the trash, got %+v, %v", trash, err)
+	}
+	restored, err := service.RestoreJournal(ctx, "user@example.com", journal.JournalID)
+	if err != nil || restored.Content != "A hidden walk" {
+		t.Fatalf("Expected the restored entry to be decrypted, got %+v, %v", restored, err)
+	}
+	assertSealed(t, journalRepo, "hidden", "Private")
+
+	// Searching matches the decrypted content, case-insensitively, and applies the limit.
+	found, err := service.SearchJournals(ctx, "user@example.com", "HIDDEN", "", "", 10)
+	if err != nil || len(found) != 1 || found[0].Content != "A hidden walk" {
+		t.Errorf("Expected the search to find the decrypted entry, got %+v, %v", found, err)
+	}
+	found, err = service.SearchJournals(ctx, "user@example.com", "", "", "", 1)
+	if err != nil || len(found) != 1 || found[0].Content != "Private thoughts" {
+		t.Errorf("Expected the newest decrypted entry, got %+v, %v", found, err)
+	}
+	all, err := service.GetAllJournals(ctx, "user@example.com")
+	if err != nil || len(all) != 2 {
+		t.Fatalf("Expected 2 journals, got %d, %v", len(all), err)
+	}
+	for _, entry := range all {
+		if entry.Content != "A hidden walk" && entry.Content != "Private thoughts" {
+			t.Errorf("Expected decrypted content, got %q", entry.Content)
+		}
+	}
+
+	var exported bytes.Buffer
+	if err := service.ExportJournals(ctx, "user@example.com", "markdown", "", "", &exported); err != nil {
+		t.Fatalf("Failed to export journals: %v", err)
+	}
+	if !strings.Contains(exported.String(), "A hidden walk") || !strings.Contains(exported.String(), "Private thoughts") {
+		t.Errorf("Expected the export to contain the decrypted content, got %q", exported.String())
+	}
+
+	// An email change moves the entries without re-encrypting them; they are still read with the old key.
+	for _, stored := range journalRepo.Journals {
+		stored.Email = "renamed@example.com"
+	}
+	got, err = service.GetJournal(ctx, "renamed@example.com", journal.JournalID)
+	if err != nil || got.Content != "A hidden walk" {
+		t.Fatalf("Expected the moved entry to be decrypted, got %+v, %v", got, err)
+	}
+	got.Content = "A renamed walk"
+	if err := service.UpdateJournal(ctx, got); err != nil {
+		t.Fatalf("Failed to update journal: %v", err)
+	}
+	if stored := journalRepo.Journals[journal.JournalID]; stored.KeyEmail != "renamed@example.com" {
+		t.Errorf("Expected the entry to be re-encrypted with the new email, got %q", stored.KeyEmail)
+	}
+}
+
+func TestJournalService_Encryption_Legacy(t *testing.T) {
+	ctx := context.Background()
+	journalRepo := mocks.NewMockJournalRepository()
+	legacy := &models.Journal{Email: "user@example.com", Date: "2024-05-30", Content: "Written before encryption"}
+	if err := journalRepo.CreateJournal(ctx, legacy); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	service := newEncryptedJournalService(t, journalRepo, 'k')
+
+	got, err := service.GetJournal(ctx, "user@example.com", legacy.JournalID)
+	if err != nil || got.Content != "Written before encryption" {
+		t.Fatalf("Expected the legacy entry as it is, got %+v, %v", got, err)
+	}
+	found, err := service.SearchJournals(ctx, "user@example.com", "before", "", "", 0)
+	if err != nil || len(found) != 1 {
+		t.Errorf("Expected the search to find the legacy entry, got %+v, %v", found, err)
+	}
+
+	got.Content = "Updated after encryption"
+	if err := service.UpdateJournal(ctx, got); err != nil {
+		t.Fatalf("Failed to update journal: %v", err)
+	}
+	assertSealed(t, journalRepo, "Updated")
+	got, err = service.GetJournal(ctx, "user@example.com", legacy.JournalID)
+	if err != nil || got.Content != "Updated after encryption" {
+		t.Errorf("Expected the updated content, got %+v, %v", got, err)
+	}
+}
+
+func TestJournalService_Encryption_WrongKey(t *testing.T) {
+	ctx := context.Background()
+	journalRepo := mocks.NewMockJournalRepository()
+	journal := &models.Journal{Email: "user@example.com", Date: "2024-05-30", Content: "A secret walk"}
+	if err := newEncryptedJournalService(t, journalRepo, 'k').CreateJournal(ctx, journal); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+
+	service := newEncryptedJournalService(t, journalRepo, 'x')
+	if _, err := service.GetJournal(ctx, "user@example.com", journal.JournalID); !errors.Is(err, services.ErrJournalDecryption) {
+		t.Errorf("Expected ErrJournalDecryption, got %v", err)
+	}
+	if _, err := service.SearchJournals(ctx, "user@example.com", "secret", "", "", 0); !errors.Is(err, services.ErrJournalDecryption) {
+		t.Errorf("Expected ErrJournalDecryption from search, got %v", err)
+	}
+
+	// Without a cipher, encrypted entries cannot be read either.
+	plain := services.NewJournalService(journalRepo, nil)
+	if _, err := plain.GetJournal(ctx, "user@example.com", journal.JournalID); !errors.Is(err, services.ErrJournalDecryption) {
+		t.Errorf("Expected ErrJournalDecryption without a cipher, got %v", err)
+	}
+}
+
+// importStatuses returns the status of every row of an import result.
+func importStatuses(result *models.JournalImportResult) []string {
+	statuses := make([]string, len(result.Rows))
+	for i, row := range result.Rows {
+		statuses[i] = row.Status
+	}
+	return statuses
+}
+
+func TestJournalService_ImportJournals_JSON(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service, journalRepo := newClockedJournalService(&now)
+	existing := &models.Journal{Email: "user@example.com", Date: "2024-05-01", Content: "Existing"}
+	if err := service.CreateJournal(ctx, existing); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+
+	body := `[
+		{"date": "2024-05-02", "content": "First", "mood": "ignored"},
+		{"date": "2024-05-02", "content": "Same day again"},
+		{"date": "2024-05-01", "content": "Already written"},
+		{"date": "05/03/2024", "content": "Wrong date format"},
+		{"date": "2024-07-01", "content": "In the future"},
+		{"date": "2024-05-04", "content": "   "},
+		42,
+		{"date": 20240505, "content": "Numeric date"},
+		{"date": "2024-05-06", "content": "Last"}
+	]`
+	result, err := service.ImportJournals(ctx, "user@example.com", services.JournalImportJSON, strings.NewReader(body), false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{"imported", "skipped", "skipped", "skipped", "skipped", "skipped", "skipped", "skipped", "imported"}
+	if got := importStatuses(result); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected statuses %v, got %v", want, got)
+	}
+	if result.Imported != 2 || result.Skipped != 7 || result.Merged != 0 || result.Failed != 0 {
+		t.Errorf("Expected 2 imported and 7 skipped, got %+v", result)
+	}
+	for _, row := range result.Rows {
+		if row.Status == "skipped" && row.Reason == "" {
+			t.Errorf("Expected a reason for skipped row %d", row.Row)
+		}
+	}
+	if result.Rows[2].Reason != "A journal already exists for this date" {
+		t.Errorf("Expected row 3 to be skipped for its existing journal, got %q", result.Rows[2].Reason)
+	}
+
+	if len(journalRepo.Journals) != 3 {
+		t.Errorf("Expected 3 stored journals, got %d", len(journalRepo.Journals))
+	}
+	journal, err := journalRepo.GetJournalByDate(ctx, "user@example.com", "2024-05-02")
+	if err != nil || journal == nil || journal.Content != "First" || journal.Mood != "" || journal.JournalID == "" {
+		t.Errorf("Expected only the first entry of 2024-05-02 to be stored, got %+v, %v", journal, err)
+	}
+}
+
+func TestJournalService_ImportJournals_CSV(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service, journalRepo := newClockedJournalService(&now)
+
+	body := "\ufeffContent,Mood,DATE\n" +
+		"\"Two lines,\nwith a comma\",good,2024-05-01\n" +
+		"Too few columns\n" +
+		"Not a date,,yesterday\n" +
+		"Second, ,2024-05-02\n"
+	result, err := service.ImportJournals(ctx, "user@example.com", services.JournalImportCSV, strings.NewReader(body), false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{"imported", "skipped", "skipped", "imported"}
+	if got := importStatuses(result); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected statuses %v, got %v", want, got)
+	}
+	if result.Rows[0].Row != 1 || result.Rows[0].Date != "2024-05-01" {
+		t.Errorf("Expected rows to be numbered from 1 after the header, got %+v", result.Rows[0])
+	}
+	journal, _ := journalRepo.GetJournalByDate(ctx, "user@example.com", "2024-05-01")
+	if journal == nil || journal.Content != "Two lines,\nwith a comma" {
+		t.Errorf("Expected the quoted content to be imported as written, got %+v", journal)
+	}
+}
+
+func TestJournalService_ImportJournals_Merge(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service, journalRepo := newClockedJournalService(&now)
+	existing := &models.Journal{Email: "user@example.com", Date: "2024-05-01", Content: "Existing", Mood: "good", Tags: []string{"work"}}
+	if err := service.CreateJournal(ctx, existing); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+
+	rows := []map[string]string{
+		{"date": "2024-05-01", "content": "Imported"},
+		{"date": "2024-05-01", "content": "Imported"},
+		{"date": "2024-05-02", "content": "Morning"},
+		{"date": "2024-05-02", "content": "Evening"},
+		{"date": "2024-05-02", "content": strings.Repeat("x", validate.MaxJournalContentLength)},
+	}
+	body, _ := json.Marshal(rows)
+	result, err := service.ImportJournals(ctx, "user@example.com", services.JournalImportJSON, bytes.NewReader(body), true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{"merged", "skipped", "imported", "merged", "skipped"}
+	if got := importStatuses(result); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected statuses %v, got %v", want, got)
+	}
+	if result.Imported != 1 || result.Merged != 2 || result.Skipped != 2 {
+		t.Errorf("Expected 1 imported, 2 merged and 2 skipped, got %+v", result)
+	}
+
+	merged, err := service.GetJournal(ctx, "user@example.com", existing.JournalID)
+	if err != nil || merged.Content != "Existing\n\nImported" || merged.Mood != "good" || len(merged.Tags) != 1 {
+		t.Errorf("Expected the row appended to the existing journal, keeping its mood and tags, got %+v, %v", merged, err)
+	}
+	journal, _ := journalRepo.GetJournalByDate(ctx, "user@example.com", "2024-05-02")
+	if journal == nil || journal.Content != "Morning\n\nEvening" {
+		t.Errorf("Expected same-day rows merged into one journal, got %+v", journal)
+	}
+	if len(journalRepo.Journals) != 2 {
+		t.Errorf("Expected 2 stored journals, got %d", len(journalRepo.Journals))
+	}
+}
+
+// endlessReader repeats line forever, like an arbitrarily large upload.
+type endlessReader struct {
+	line string
+	pos  int
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.line[r.pos]
+		r.pos = (r.pos + 1) % len(r.line)
+	}
+	return len(p), nil
+}
+
+func TestJournalService_ImportJournals_Cap(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service, journalRepo := newClockedJournalService(&now)
+
+	// Exactly the limit is imported, one entry per day.
+	var csvBody strings.Builder
+	csvBody.WriteString("date,content\n")
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < services.MaxJournalImportEntries; i++ {
+		fmt.Fprintf(&csvBody, "%s,Entry %d\n", day.AddDate(0, 0, i).Format("2006-01-02"), i)
+	}
+	result, err := service.ImportJournals(ctx, "user@example.com", services.JournalImportCSV, strings.NewReader(csvBody.String()), false)
+	if err != nil || result.Imported != services.MaxJournalImportEntries {
+		t.Fatalf("Expected %d entries imported, got %+v, %v", services.MaxJournalImportEntries, result, err)
+	}
+
+	// One more row rejects the whole import.
+	service, journalRepo = newClockedJournalService(&now)
+	csvBody.WriteString("2024-01-01,One too many\n")
+	_, err = service.ImportJournals(ctx, "user@example.com", services.JournalImportCSV, strings.NewReader(csvBody.String()), false)
+	if !errors.Is(err, services.ErrTooManyImportEntries) {
+		t.Errorf("Expected ErrTooManyImportEntries, got %v", err)
+	}
+	if len(journalRepo.Journals) != 0 {
+		t.Errorf("Expected nothing stored for a rejected import, got %d journals", len(journalRepo.Journals))
+	}
+
+	// Files are read row by row, so an endless one is rejected instead of read into memory.
+	endlessCSV := io.MultiReader(strings.NewReader("date,content\n"), &endlessReader{line: "2024-01-01,Again\n"})
+	if _, err := service.ImportJournals(ctx, "user@example.com", services.JournalImportCSV, endlessCSV, false); !errors.Is(err, services.ErrTooManyImportEntries) {
+		t.Errorf("Expected ErrTooManyImportEntries for an endless CSV file, got %v", err)
+	}
+	endlessJSON := io.MultiReader(strings.NewReader("["), &endlessReader{line: `{"date":"2024-01-01","content":"Again"},`})
+	if _, err := service.ImportJournals(ctx, "user@example.com", services.JournalImportJSON, endlessJSON, false); !errors.Is(err, services.ErrTooManyImportEntries) {
+		t.Errorf("Expected ErrTooManyImportEntries for an endless JSON array, got %v", err)
+	}
+}
+
+func TestJournalService_ImportJournals_InvalidFiles(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service, journalRepo := newClockedJournalService(&now)
+
+	tests := []struct {
+		name   string
+		format string
+		body   string
+		want   error
+	}{
+		{"JSON object", services.JournalImportJSON, `{"date": "2024-05-01", "content": "Hi"}`, services.ErrInvalidImportFile},
+		{"truncated JSON", services.JournalImportJSON, `[{"date": "2024-05-01", "content": "Hi"}, {"date": `, services.ErrInvalidImportFile},
+		{"empty CSV", services.JournalImportCSV, ``, services.ErrInvalidImportFile},
+		{"CSV without content column", services.JournalImportCSV, "date,text\n2024-05-01,Hi\n", services.ErrInvalidImportFile},
+		{"CSV with a stray quote", services.JournalImportCSV, "date,content\n2024-05-01,Hi\n2024-05-02,\"Unterminated\n", services.ErrInvalidImportFile},
+		{"unknown format", "xml", `<journals/>`, services.ErrUnsupportedImportFormat},
+	}
+	for _, test := range tests {
+		if _, err := service.ImportJournals(ctx, "user@example.com", test.format, strings.NewReader(test.body), false); !errors.Is(err, test.want) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, err)
+		}
+	}
+	if len(journalRepo.Journals) != 0 {
+		t.Errorf("Expected nothing stored from invalid files, got %d journals", len(journalRepo.Journals))
+	}
+}
+
+func TestJournalService_ImportJournals_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	journalRepo := mocks.NewMockJournalRepository()
+	service := newEncryptedJournalService(t, journalRepo, 1)
+	existing := &models.Journal{Email: "user@example.com", Date: "2024-05-01", Content: "Sealed secret"}
+	if err := service.CreateJournal(ctx, existing); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+
+	body := `[{"date": "2024-05-01", "content": "Merged secret"}, {"date": "2024-05-02", "content": "New secret"}]`
+	result, err := service.ImportJournals(ctx, "user@example.com", services.JournalImportJSON, strings.NewReader(body), true)
+	if err != nil || result.Merged != 1 || result.Imported != 1 {
+		t.Fatalf("Expected 1 merged and 1 imported entry, got %+v, %v", result, err)
+	}
+	assertSealed(t, journalRepo, "secret")
+
+	merged, err := service.GetJournal(ctx, "user@example.com", existing.JournalID)
+	if err != nil || merged.Content != "Sealed secret\n\nMerged secret" {
+		t.Errorf("Expected the decrypted content merged, got %+v, %v", merged, err)
+	}
+}
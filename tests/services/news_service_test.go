@@ -0,0 +1,302 @@
+/**
+ *  NewsService Tests validate the in-memory cache in front of the news API. They use a counting
+ *  fake news API and an injectable clock, so expiry can be tested without waiting.
+ *
+ *  @file       news_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestNewsService_FetchNews_Cache         - Tests one upstream call within the TTL, another after expiry, and per-key entries.
+ *  - TestNewsService_FetchNews_Refresh       - Tests that refresh bypasses the cached response.
+ *  - TestNewsService_FetchNews_SingleFlight  - Tests that concurrent requests for the same key share one upstream call.
+ *  - TestNewsService_FetchNews_SingleFlightCancel - Tests that the caller starting a shared call can give up without failing the others.
+ *  - TestNewsService_FetchNews_ErrorNotCached - Tests that a failed upstream response is retried on the next request.
+ *  - TestNewsService_FetchNews_UpstreamErrors - Tests that upstream 429s, API errors and malformed JSON return a NewsAPIError.
+ *  - TestNewsService_FetchNews_Pagination     - Tests that the page token is passed upstream and nextPage is returned.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newCountingNewsAPI starts a fake news API that counts its requests. If release is non-nil,
+// each request blocks until it is closed.
+func newCountingNewsAPI(t *testing.T, calls *int32, release chan struct{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if release != nil {
+			<-release
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "success",
+			"totalResults": 1,
+			"results":      []map[string]interface{}{{"title": "News for " + r.URL.Query().Get("q")}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newCachedNewsService creates a NewsService against the fake news API with a 10-minute TTL
+// and a clock read from now.
+func newCachedNewsService(server *httptest.Server, now *time.Time) *services.NewsService {
+	return &services.NewsService{
+		UserRepo:   mocks.NewMockUserRepository(map[string]*models.User{}),
+		HTTPClient: server.Client(),
+		NewsAPIURL: server.URL,
+		GetCountryLanguages: func(country string) (string, []string, error) {
+			return "no", []string{"nb"}, nil
+		},
+		CacheTTL: 10 * time.Minute,
+		Now:      func() time.Time { return *now },
+	}
+}
+
+func TestNewsService_FetchNews_Cache(t *testing.T) {
+	var calls int32
+	server := newCountingNewsAPI(t, &calls, nil)
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	newsService := newCachedNewsService(server, &now)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		articles, err := newsService.FetchNews(ctx, "", "general", "", "", "tech", "", false)
+		if err != nil || articles == nil || len(articles.Items) != 1 {
+			t.Fatalf("Expected 1 article, got %v (err: %v)", articles, err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call within the TTL, got %d", got)
+	}
+
+	// A different query or country is a different cache entry.
+	newsService.FetchNews(ctx, "", "general", "", "", "sport", "", false)
+	newsService.FetchNews(ctx, "", "local", "Norway", "", "tech", "", false)
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Expected 3 upstream calls for 3 keys, got %d", got)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if _, err := newsService.FetchNews(ctx, "", "general", "", "", "tech", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 4 {
+		t.Errorf("Expected another upstream call after expiry, got %d calls", got)
+	}
+}
+
+func TestNewsService_FetchNews_Refresh(t *testing.T) {
+	var calls int32
+	server := newCountingNewsAPI(t, &calls, nil)
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	newsService := newCachedNewsService(server, &now)
+	ctx := context.Background()
+
+	newsService.FetchNews(ctx, "", "general", "", "", "tech", "", false)
+	newsService.FetchNews(ctx, "", "general", "", "", "tech", "", true)
+	newsService.FetchNews(ctx, "", "general", "", "", "tech", "", false)
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected 2 upstream calls (initial and refresh), got %d", got)
+	}
+}
+
+func TestNewsService_FetchNews_SingleFlight(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	server := newCountingNewsAPI(t, &calls, release)
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	newsService := newCachedNewsService(server, &now)
+
+	const requests = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := newsService.FetchNews(context.Background(), "", "general", "", "", "tech", "", false)
+			errs <- err
+		}()
+	}
+
+	// Give every goroutine time to join the in-flight call before the upstream responds.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call for %d concurrent requests, got %d", requests, got)
+	}
+}
+
+func TestNewsService_FetchNews_SingleFlightCancel(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	server := newCountingNewsAPI(t, &calls, release)
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	newsService := newCachedNewsService(server, &now)
+
+	// The first caller starts the upstream call, then its client disconnects.
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := newsService.FetchNews(firstCtx, "", "general", "", "", "tech", "", false)
+		firstErr <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	secondPage := make(chan *services.NewsPage, 1)
+	secondErr := make(chan error, 1)
+	go func() {
+		page, err := newsService.FetchNews(context.Background(), "", "general", "", "", "tech", "", false)
+		secondPage <- page
+		secondErr <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-firstErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the cancelled caller to get context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the cancelled caller to stop waiting")
+	}
+
+	close(release)
+	if page, err := <-secondPage, <-secondErr; err != nil || page == nil || len(page.Items) != 1 {
+		t.Fatalf("Expected the other caller to get 1 article, got %v (err: %v)", page, err)
+	}
+	if _, err := newsService.FetchNews(context.Background(), "", "general", "", "", "tech", "", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call, cached for later requests, got %d", got)
+	}
+}
+
+func TestNewsService_FetchNews_ErrorNotCached(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Write([]byte("not json"))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "results": []map[string]interface{}{{"title": "News"}}})
+	}))
+	defer server.Close()
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	newsService := newCachedNewsService(server, &now)
+
+	if _, err := newsService.FetchNews(context.Background(), "", "general", "", "", "", "", false); err == nil {
+		t.Fatal("Expected an error for an unparsable response")
+	}
+	articles, err := newsService.FetchNews(context.Background(), "", "general", "", "", "", "", false)
+	if err != nil || articles == nil || len(articles.Items) != 1 {
+		t.Errorf("Expected the failed response to be retried, got %v (err: %v)", articles, err)
+	}
+}
+
+func TestNewsService_FetchNews_UpstreamErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		wantRateLimited bool
+		wantMessage     string
+	}{
+		{"rate limited", http.StatusTooManyRequests, `{"status":"error","results":{"message":"API rate limit exceeded","code":"RateLimitExceeded"}}`, true, "API rate limit exceeded"},
+		{"api error", http.StatusUnauthorized, `{"status":"error","results":{"message":"API key invalid","code":"Unauthorized"}}`, false, "API key invalid"},
+		{"malformed json", http.StatusOK, `<html>Bad Gateway</html>`, false, "Failed to parse news data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+			newsService := newCachedNewsService(server, &now)
+
+			_, err := newsService.FetchNews(context.Background(), "", "general", "", "", "", "", false)
+			var apiErr *services.NewsAPIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected a NewsAPIError, got %v", err)
+			}
+			if apiErr.RateLimited() != tt.wantRateLimited {
+				t.Errorf("Expected RateLimited %v, got %v", tt.wantRateLimited, apiErr.RateLimited())
+			}
+			if apiErr.Message != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, apiErr.Message)
+			}
+		})
+	}
+}
+
+func TestNewsService_FetchNews_Pagination(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		page := r.URL.Query().Get("page")
+		nextPage := "page2"
+		if page == "page2" {
+			nextPage = ""
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":   "success",
+			"results":  []map[string]interface{}{{"title": "Article on " + page}},
+			"nextPage": nextPage,
+		})
+	}))
+	defer server.Close()
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	newsService := newCachedNewsService(server, &now)
+	ctx := context.Background()
+
+	first, err := newsService.FetchNews(ctx, "", "general", "", "", "", "", false)
+	if err != nil || first.NextPage != "page2" {
+		t.Fatalf("Expected nextPage %q, got %+v (err: %v)", "page2", first, err)
+	}
+
+	second, err := newsService.FetchNews(ctx, "", "general", "", "", "", first.NextPage, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if second.NextPage != "" || second.Items[0]["title"] != "Article on page2" {
+		t.Errorf("Expected the last page for page2, got %+v", second)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected each page to be fetched separately, got %d upstream calls", got)
+	}
+}
@@ -0,0 +1,297 @@
+/**
+ *  NotificationGate Tests validate quiet hours: the quiet window in each user's time zone, including
+ *  windows that wrap past midnight, holding notification emails back and sending them once the window
+ *  has ended, and the quiet hours settings of the profile. They use mock repositories, a mock
+ *  EmailService and a fake clock.
+ *
+ *  @file       notification_gate_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestQuietWindowEnd                                  - Tests windows within a day and wrapping past midnight, their bounds and invalid windows.
+ *  - TestQuietUntil_TimeZone                             - Tests that the window is in the user's time zone, or their country's.
+ *  - TestNotificationGate_Hold                           - Tests storing held emails, dropping expired ones, storage failures and a nil gate.
+ *  - TestNotificationGate_FriendRequestAfterQuietHours   - Tests that a friend request email sent at night is delivered when the window ends, while a password reset is not held.
+ *  - TestNotificationGate_EventReminder                  - Tests that a held event reminder is marked as sent, and dropped if the event starts first.
+ *  - TestProfileService_UpdateProfile_QuietHours         - Tests validating, normalizing and clearing the quiet hours.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+	"proh2052-group6/tests/mocks"
+)
+
+func TestQuietWindowEnd(t *testing.T) {
+	at := func(day, hour, minute int) time.Time { return time.Date(2024, 12, day, hour, minute, 0, 0, time.UTC) }
+	tests := []struct {
+		name       string
+		start, end string
+		now        time.Time
+		wantQuiet  bool
+		wantEnd    time.Time
+	}{
+		{"wrapping, before midnight", "22:00", "07:00", at(2, 23, 30), true, at(3, 7, 0)},
+		{"wrapping, at the start", "22:00", "07:00", at(2, 22, 0), true, at(3, 7, 0)},
+		{"wrapping, after midnight", "22:00", "07:00", at(3, 3, 0), true, at(3, 7, 0)},
+		{"wrapping, a minute before the end", "22:00", "07:00", at(3, 6, 59), true, at(3, 7, 0)},
+		{"wrapping, at the end", "22:00", "07:00", at(3, 7, 0), false, time.Time{}},
+		{"wrapping, before the start", "22:00", "07:00", at(2, 21, 59), false, time.Time{}},
+		{"wrapping, midday", "22:00", "07:00", at(2, 12, 0), false, time.Time{}},
+		{"within a day, inside", "13:00", "14:30", at(2, 13, 45), true, at(2, 14, 30)},
+		{"within a day, before", "13:00", "14:30", at(2, 12, 59), false, time.Time{}},
+		{"within a day, at the end", "13:00", "14:30", at(2, 14, 30), false, time.Time{}},
+		{"from midnight", "00:00", "06:00", at(2, 0, 0), true, at(2, 6, 0)},
+		{"until midnight", "20:00", "00:00", at(2, 23, 59), true, at(3, 0, 0)},
+		{"same start and end", "22:00", "22:00", at(2, 22, 0), false, time.Time{}},
+		{"invalid start", "10pm", "07:00", at(2, 23, 0), false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, quiet := services.QuietWindowEnd(tt.now, time.UTC, tt.start, tt.end)
+			if quiet != tt.wantQuiet || !end.Equal(tt.wantEnd) {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", tt.wantEnd, tt.wantQuiet, end, quiet)
+			}
+		})
+	}
+}
+
+func TestQuietUntil_TimeZone(t *testing.T) {
+	// 04:00 UTC is 05:00 in Oslo and 23:00 the day before in New York.
+	now := time.Date(2024, 12, 3, 4, 0, 0, 0, time.UTC)
+	oslo := &models.User{Country: "Norway", QuietStart: "22:00", QuietEnd: "07:00"}
+	end, quiet := services.QuietUntil(oslo, now)
+	if want := time.Date(2024, 12, 3, 6, 0, 0, 0, time.UTC); !quiet || !end.Equal(want) {
+		t.Errorf("Expected Oslo's quiet hours to end at %v, got (%v, %v)", want, end, quiet)
+	}
+
+	newYork := &models.User{TimeZone: "America/New_York", QuietStart: "22:00", QuietEnd: "07:00"}
+	end, quiet = services.QuietUntil(newYork, now)
+	if want := time.Date(2024, 12, 3, 12, 0, 0, 0, time.UTC); !quiet || !end.Equal(want) {
+		t.Errorf("Expected New York's quiet hours to end at %v, got (%v, %v)", want, end, quiet)
+	}
+
+	if _, quiet := services.QuietUntil(&models.User{Country: "Norway"}, now); quiet {
+		t.Error("Expected a user without quiet hours never to be quiet")
+	}
+}
+
+func TestNotificationGate_Hold(t *testing.T) {
+	pendingRepo := mocks.NewMockPendingDeliveryRepository()
+	gate := services.NewNotificationGate(pendingRepo, &mocks.MockEmailService{})
+	now := time.Date(2024, 12, 2, 23, 0, 0, 0, time.UTC)
+	gate.Now = func() time.Time { return now }
+	ctx := context.Background()
+	user := &models.User{Email: "bob@example.com", QuietStart: "22:00", QuietEnd: "07:00"}
+	msg := services.EmailMessage{Subject: "Hello", Text: "Hi", HTML: "<p>Hi</p>"}
+	windowEnd := time.Date(2024, 12, 3, 7, 0, 0, 0, time.UTC)
+
+	held, err := gate.Hold(ctx, user, msg, time.Time{})
+	if err != nil || !held {
+		t.Fatalf("Expected the email to be held during quiet hours, got (%v, %v)", held, err)
+	}
+	if len(pendingRepo.Deliveries) != 1 {
+		t.Fatalf("Expected one pending delivery, got %d", len(pendingRepo.Deliveries))
+	}
+	for _, delivery := range pendingRepo.Deliveries {
+		if delivery.Email != "bob@example.com" || delivery.Subject != "Hello" || delivery.HTML != "<p>Hi</p>" || !delivery.DeliverAt.Equal(windowEnd) || !delivery.CreatedAt.Equal(now) {
+			t.Errorf("Expected the email to be stored for delivery at %v, got %+v", windowEnd, delivery)
+		}
+	}
+
+	// An email that would be stale by the end of the window is dropped.
+	if held, err := gate.Hold(ctx, user, msg, windowEnd.Add(-time.Minute)); err != nil || !held || len(pendingRepo.Deliveries) != 1 {
+		t.Errorf("Expected an expiring email to be dropped, got (%v, %v) and %d deliveries", held, err, len(pendingRepo.Deliveries))
+	}
+	if held, err := gate.Hold(ctx, user, msg, windowEnd); err != nil || !held || len(pendingRepo.Deliveries) != 2 {
+		t.Errorf("Expected an email expiring at the end of the window to be held, got (%v, %v)", held, err)
+	}
+
+	// Outside quiet hours, and for users without them, emails are sent right away.
+	if held, err := gate.Hold(ctx, &models.User{Email: "alice@example.com"}, msg, time.Time{}); held || err != nil {
+		t.Errorf("Expected no hold without quiet hours, got (%v, %v)", held, err)
+	}
+	now = time.Date(2024, 12, 3, 7, 0, 0, 0, time.UTC)
+	if held, err := gate.Hold(ctx, user, msg, time.Time{}); held || err != nil {
+		t.Errorf("Expected no hold once the window has ended, got (%v, %v)", held, err)
+	}
+
+	// An email that could not be stored must not be sent either.
+	now = time.Date(2024, 12, 3, 1, 0, 0, 0, time.UTC)
+	pendingRepo.Err = repositories.ErrUnavailable
+	if held, err := gate.Hold(ctx, user, msg, time.Time{}); !held || !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected a storage failure to hold the email with an error, got (%v, %v)", held, err)
+	}
+
+	var noGate *services.NotificationGate
+	if held, err := noGate.Hold(ctx, user, msg, time.Time{}); held || err != nil {
+		t.Errorf("Expected a nil gate to hold nothing, got (%v, %v)", held, err)
+	}
+}
+
+func TestNotificationGate_FriendRequestAfterQuietHours(t *testing.T) {
+	hashed, _ := utils.HashPassword("Password123!")
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "alice"},
+		"bob@example.com":   {Email: "bob@example.com", Username: "bob", Password: hashed, IsVerified: true, Country: "Norway", QuietStart: "22:00", QuietEnd: "07:00"},
+	})
+	emails := &mocks.MockEmailService{}
+	pendingRepo := mocks.NewMockPendingDeliveryRepository()
+	gate := services.NewNotificationGate(pendingRepo, emails)
+	now := time.Date(2024, 12, 2, 22, 30, 0, 0, time.UTC) // 23:30 in Oslo.
+	gate.Now = func() time.Time { return now }
+	friendService := services.NewFriendService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), emails, nil).(*services.FriendService)
+	friendService.Gate = gate
+	friendService.Now = gate.Now
+	ctx := context.Background()
+
+	if _, err := friendService.SendFriendRequest(ctx, "alice@example.com", "bob"); err != nil {
+		t.Fatalf("Failed to send friend request: %v", err)
+	}
+	if len(emails.SentEmails) != 0 {
+		t.Fatalf("Expected no email during bob's quiet hours, got %+v", emails.SentEmails)
+	}
+
+	// A password reset code is sent at once, since the user is waiting for it.
+	userService := services.NewUserService(userRepo, emails, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+	if err := userService.ForgotPassword(ctx, "bob@example.com"); err != nil {
+		t.Fatalf("Failed to request a password reset: %v", err)
+	}
+	if len(emails.SentEmails) != 1 || emails.LastOTP() == "" {
+		t.Fatalf("Expected the password reset code to bypass quiet hours, got %+v", emails.SentEmails)
+	}
+	emails.SentEmails = nil
+
+	// Nothing is due until 07:00 in Oslo, which is 06:00 UTC.
+	now = time.Date(2024, 12, 3, 5, 59, 0, 0, time.UTC)
+	if sent, err := gate.FlushDue(ctx); err != nil || sent != 0 || len(emails.SentEmails) != 0 {
+		t.Fatalf("Expected nothing to be sent before the window ends, got %d (%v)", sent, err)
+	}
+
+	// A failed delivery stays pending and is retried on the next run.
+	now = time.Date(2024, 12, 3, 6, 0, 0, 0, time.UTC)
+	emails.FailFor = map[string]error{"bob@example.com": errors.New("mailbox unavailable")}
+	if sent, _ := gate.FlushDue(ctx); sent != 0 || len(pendingRepo.Deliveries) != 1 {
+		t.Fatalf("Expected a failed delivery to stay pending, got %d sent and %d pending", sent, len(pendingRepo.Deliveries))
+	}
+	emails.FailFor = nil
+
+	if sent, err := gate.FlushDue(ctx); err != nil || sent != 1 {
+		t.Fatalf("Expected the held email to be sent once the window ended, got %d (%v)", sent, err)
+	}
+	if len(emails.SentEmails) != 1 || emails.SentEmails[0].To != "bob@example.com" || emails.SentEmails[0].Subject == "" {
+		t.Errorf("Expected the friend request email to reach bob, got %+v", emails.SentEmails)
+	}
+	if len(pendingRepo.Deliveries) != 0 {
+		t.Errorf("Expected the delivered email to be removed, got %d pending", len(pendingRepo.Deliveries))
+	}
+	if sent, _ := gate.FlushDue(ctx); sent != 0 {
+		t.Errorf("Expected the email to be sent only once, got %d more", sent)
+	}
+}
+
+func TestNotificationGate_EventReminder(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"bob@example.com": {Email: "bob@example.com", Username: "bob", QuietStart: "22:00", QuietEnd: "07:00"},
+	})
+	eventRepo := mocks.NewMockEventRepository()
+	emails := &mocks.MockEmailService{}
+	pendingRepo := mocks.NewMockPendingDeliveryRepository()
+	now := time.Date(2024, 12, 2, 23, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	gate := services.NewNotificationGate(pendingRepo, emails)
+	gate.Now = clock
+	reminderService := services.NewReminderService(eventRepo, emails).(*services.ReminderService)
+	reminderService.UserRepo, reminderService.Gate, reminderService.Now = userRepo, gate, clock
+	ctx := context.Background()
+
+	// The morning event is reminded when the window ends; the one during the night is dropped.
+	morning := &models.Event{Email: "bob@example.com", Title: "Dentist", StartAt: time.Date(2024, 12, 3, 9, 0, 0, 0, time.UTC), ReminderMinutesBefore: 12 * 60}
+	night := &models.Event{Email: "bob@example.com", Title: "Flight", StartAt: time.Date(2024, 12, 3, 5, 0, 0, 0, time.UTC), ReminderMinutesBefore: 60 * 6}
+	for _, event := range []*models.Event{morning, night} {
+		eventRepo.CreateEvent(ctx, event)
+	}
+
+	if sent, err := reminderService.SendDueReminders(ctx); err != nil || sent != 0 || len(emails.SentEmails) != 0 {
+		t.Fatalf("Expected no reminders during quiet hours, got %d (%v)", sent, err)
+	}
+	for _, event := range []*models.Event{morning, night} {
+		if !eventRepo.Events[event.EventID].ReminderSent {
+			t.Errorf("Expected the reminder of %s to be marked as sent", event.Title)
+		}
+	}
+	if len(pendingRepo.Deliveries) != 1 {
+		t.Fatalf("Expected only the morning event's reminder to be held, got %d", len(pendingRepo.Deliveries))
+	}
+
+	now = time.Date(2024, 12, 3, 7, 0, 0, 0, time.UTC)
+	if sent, err := gate.FlushDue(ctx); err != nil || sent != 1 || emails.SentEmails[0].To != "bob@example.com" {
+		t.Errorf("Expected the morning reminder to be sent at 07:00, got %d (%v)", sent, err)
+	}
+}
+
+func TestProfileService_UpdateProfile_QuietHours(t *testing.T) {
+	hashed, _ := utils.HashPassword("Password123!")
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"bob@example.com": {Email: "bob@example.com", Username: "bob", Password: hashed},
+	})
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+	ctx := context.Background()
+	update := func(fields map[string]interface{}) error {
+		fields["CurrentPassword"] = "Password123!"
+		return profileService.UpdateProfile(ctx, "bob@example.com", fields)
+	}
+
+	for _, invalid := range []map[string]interface{}{
+		{"QuietStart": "25:00", "QuietEnd": "07:00"},
+		{"QuietStart": "22:00", "QuietEnd": "7am"},
+		{"QuietStart": "22:00", "QuietEnd": "22:00"},
+		{"QuietStart": 22, "QuietEnd": "07:00"},
+	} {
+		if err := update(invalid); !errors.Is(err, services.ErrInvalidQuietHours) {
+			t.Errorf("Expected ErrInvalidQuietHours for %v, got %v", invalid, err)
+		}
+	}
+	if err := update(map[string]interface{}{"QuietStart": "22:00"}); !errors.Is(err, services.ErrQuietHoursIncomplete) {
+		t.Errorf("Expected ErrQuietHoursIncomplete without an end, got %v", err)
+	}
+
+	if err := update(map[string]interface{}{"QuietStart": " 22:00 ", "QuietEnd": "7:00"}); err != nil {
+		t.Fatalf("Failed to set the quiet hours: %v", err)
+	}
+	if user := userRepo.Users["bob@example.com"]; user.QuietStart != "22:00" || user.QuietEnd != "07:00" {
+		t.Errorf("Expected quiet hours from 22:00 to 07:00, got %q to %q", user.QuietStart, user.QuietEnd)
+	}
+	if profile, _ := profileService.GetProfile(ctx, "bob@example.com"); profile.QuietStart != "22:00" || profile.QuietEnd != "07:00" {
+		t.Errorf("Expected the profile to report the quiet hours, got %+v", profile)
+	}
+
+	// One end can be moved on its own, but not cleared on its own.
+	if err := update(map[string]interface{}{"QuietEnd": "06:30"}); err != nil || userRepo.Users["bob@example.com"].QuietEnd != "06:30" {
+		t.Errorf("Expected the end to move to 06:30, got %v", err)
+	}
+	if err := update(map[string]interface{}{"QuietStart": ""}); !errors.Is(err, services.ErrQuietHoursIncomplete) {
+		t.Errorf("Expected ErrQuietHoursIncomplete when clearing only the start, got %v", err)
+	}
+	if err := update(map[string]interface{}{"QuietStart": "", "QuietEnd": ""}); err != nil {
+		t.Fatalf("Failed to clear the quiet hours: %v", err)
+	}
+	if user := userRepo.Users["bob@example.com"]; user.QuietStart != "" || user.QuietEnd != "" {
+		t.Errorf("Expected the quiet hours to be cleared, got %q to %q", user.QuietStart, user.QuietEnd)
+	}
+}
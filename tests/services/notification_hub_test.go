@@ -0,0 +1,93 @@
+/**
+ *  NotificationHub Tests validate the delivery rules of the hub that pushes notifications
+ *  to WebSocket connections: fan-out to every connection of a user, cleanup on unsubscribe,
+ *  and that publishing never blocks.
+ *
+ *  @file       notification_hub_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestNotificationHub_MultipleConnections - Tests that every connection of a user receives a notification.
+ *  - TestNotificationHub_Unsubscribe         - Tests that unsubscribing closes the channel and stops delivery.
+ *  - TestNotificationHub_DoesNotBlock        - Tests that offline users and full buffers do not block Publish.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+)
+
+func TestNotificationHub_MultipleConnections(t *testing.T) {
+	hub := services.NewNotificationHub()
+	first := hub.Subscribe("alice@example.com")
+	second := hub.Subscribe("alice@example.com")
+	other := hub.Subscribe("bob@example.com")
+
+	hub.Publish("alice@example.com", models.Notification{Type: services.NotificationFriendRequest, Message: "hi"})
+
+	for i, subscription := range []*services.NotificationSubscription{first, second} {
+		select {
+		case notification := <-subscription.Notifications:
+			if notification.Message != "hi" || notification.CreatedAt.IsZero() {
+				t.Errorf("Connection %d: expected the notification with a timestamp, got %+v", i, notification)
+			}
+		default:
+			t.Errorf("Connection %d: expected a notification", i)
+		}
+	}
+	if len(other.Notifications) != 0 {
+		t.Errorf("Expected no notification for another user")
+	}
+}
+
+func TestNotificationHub_Unsubscribe(t *testing.T) {
+	hub := services.NewNotificationHub()
+	subscription := hub.Subscribe("alice@example.com")
+	remaining := hub.Subscribe("alice@example.com")
+
+	hub.Unsubscribe(subscription)
+	hub.Unsubscribe(subscription) // A second call must not panic on the closed channel.
+	if _, open := <-subscription.Notifications; open {
+		t.Errorf("Expected the channel to be closed")
+	}
+
+	hub.Publish("alice@example.com", models.Notification{Message: "still here"})
+	if len(remaining.Notifications) != 1 {
+		t.Errorf("Expected the remaining connection to receive the notification")
+	}
+}
+
+func TestNotificationHub_DoesNotBlock(t *testing.T) {
+	hub := services.NewNotificationHub()
+	subscription := hub.Subscribe("alice@example.com")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		hub.Publish("offline@example.com", models.Notification{Message: "dropped"})
+		// Nobody reads the subscription, so its buffer fills up and later notifications are dropped.
+		for i := 0; i < 100; i++ {
+			hub.Publish("alice@example.com", models.Notification{Message: "flood"})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked")
+	}
+	if len(subscription.Notifications) != cap(subscription.Notifications) {
+		t.Errorf("Expected the buffer to be full, got %d of %d", len(subscription.Notifications), cap(subscription.Notifications))
+	}
+}
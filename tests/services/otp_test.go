@@ -0,0 +1,169 @@
+/**
+ *  OTPManager Tests validate the generation and validation of OTPs with the default and a configured
+ *  length and lifetime, the email delivery channel, and UserService sending its OTPs through a
+ *  replaced channel.
+ *
+ *  @file       otp_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestOTPManager_Generate            - Tests the digits, stored hash and expiry of default and configured OTPs.
+ *  - TestOTPManager_Validate            - Tests matching, wrong, missing, expired and rotated-secret OTPs.
+ *  - TestEmailOTPDelivery               - Tests the email sent for each purpose and the error for unknown ones.
+ *  - TestUserService_OTPDeliveryChannel - Tests that verification and reset OTPs use the configured channel and length.
+ *
+ *  @dependencies
+ *  - mocks.MockEmailService: Records the OTP emails.
+ *  - mocks.NewMockUserRepository: Holds the user whose OTPs are issued.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+	"proh2052-group6/tests/mocks"
+)
+
+// newTestOTPManager returns an OTPManager with a fixed clock.
+func newTestOTPManager(now time.Time) *services.OTPManager {
+	manager := services.NewOTPManager(testJWT)
+	manager.Now = func() time.Time { return now }
+	return manager
+}
+
+func TestOTPManager_Generate(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager := newTestOTPManager(now)
+
+	otp, hash, expiresAt := manager.Generate()
+	if !regexp.MustCompile(`^\d{6}$`).MatchString(otp) {
+		t.Errorf("Expected 6 digits by default, got %q", otp)
+	}
+	if hash == otp || !testJWT.CheckOTP(otp, hash) {
+		t.Errorf("Expected the hash of the OTP, got %q", hash)
+	}
+	if !expiresAt.Equal(now.Add(services.OTPValidity)) {
+		t.Errorf("Expected expiry after %v, got %v", services.OTPValidity, expiresAt)
+	}
+
+	manager.Length, manager.TTL = 8, 10*time.Minute
+	otp, _, expiresAt = manager.Generate()
+	if !regexp.MustCompile(`^\d{8}$`).MatchString(otp) || !expiresAt.Equal(now.Add(10*time.Minute)) {
+		t.Errorf("Expected 8 digits valid for 10 minutes, got %q until %v", otp, expiresAt)
+	}
+}
+
+func TestOTPManager_Validate(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager := newTestOTPManager(now)
+	hash := testJWT.HashOTP("123456")
+	rotated := utils.NewJWTManager("new-secret", []string{"test-secret"}, 0, "")
+
+	tests := []struct {
+		name      string
+		manager   *services.OTPManager
+		stored    string
+		provided  string
+		expiresAt time.Time
+		wantErr   error
+	}{
+		{"Valid", manager, hash, "123456", now.Add(time.Minute), nil},
+		{"Wrong", manager, hash, "654321", now.Add(time.Minute), services.ErrInvalidOTP},
+		{"NothingStored", manager, "", "123456", now.Add(time.Minute), services.ErrInvalidOTP},
+		{"NothingProvided", manager, hash, "", now.Add(time.Minute), services.ErrInvalidOTP},
+		{"Expired", manager, hash, "123456", now.Add(-time.Second), services.ErrOTPExpired},
+		{"WrongAndExpired", manager, hash, "654321", now.Add(-time.Second), services.ErrInvalidOTP},
+		{"OldSecret", &services.OTPManager{JWT: rotated, Now: manager.Now}, hash, "123456", now.Add(time.Minute), nil},
+	}
+	for _, tt := range tests {
+		if err := tt.manager.Validate(tt.stored, tt.provided, tt.expiresAt); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestEmailOTPDelivery(t *testing.T) {
+	emailService := &mocks.MockEmailService{}
+	delivery := services.NewEmailOTPDelivery(emailService, services.NewEmailTemplateRenderer())
+	ctx := context.Background()
+
+	for purpose, subject := range map[services.OTPPurpose]string{
+		services.OTPPurposeVerification:  "Your Verification Code",
+		services.OTPPurposePasswordReset: "Password Reset Request",
+	} {
+		if err := delivery.SendOTP(ctx, "alice@example.com", "12345678", purpose, 10*time.Minute); err != nil {
+			t.Fatalf("%s: failed to send OTP: %v", purpose, err)
+		}
+		sent := emailService.SentEmails[len(emailService.SentEmails)-1]
+		if sent.To != "alice@example.com" || sent.Subject != subject || !strings.Contains(sent.Body, "12345678") || !strings.Contains(sent.Body, "10 minutes") {
+			t.Errorf("%s: expected %q with the OTP and its lifetime, got %+v", purpose, subject, sent)
+		}
+	}
+
+	if err := delivery.SendOTP(ctx, "alice@example.com", "123456", "carrier_pigeon", time.Minute); err == nil {
+		t.Errorf("Expected an unknown purpose to fail")
+	}
+}
+
+// recordingOTPDelivery records the OTPs sent through it instead of delivering them.
+type recordingOTPDelivery struct {
+	otps     []string
+	purposes []services.OTPPurpose
+}
+
+func (rd *recordingOTPDelivery) SendOTP(ctx context.Context, email, otp string, purpose services.OTPPurpose, validFor time.Duration) error {
+	rd.otps = append(rd.otps, otp)
+	rd.purposes = append(rd.purposes, purpose)
+	return nil
+}
+
+func TestUserService_OTPDeliveryChannel(t *testing.T) {
+	password, _ := utils.HashPassword("Password123!")
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "alice", Password: password, CreatedAt: time.Now()},
+	})
+	emailService := &mocks.MockEmailService{}
+	userService := services.NewUserService(userRepo, emailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT).(*services.UserService)
+	delivery := &recordingOTPDelivery{}
+	userService.OTPDelivery = delivery
+	userService.OTP.Length = 8
+	ctx := context.Background()
+
+	if err := userService.ResendOTP(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("Failed to resend OTP: %v", err)
+	}
+	if _, err := userService.VerifyEmail(ctx, "alice@example.com", delivery.otps[0]); err != nil {
+		t.Fatalf("Expected the delivered OTP to verify the email, got %v", err)
+	}
+	if err := userService.ForgotPassword(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("Failed to request a password reset: %v", err)
+	}
+	if err := userService.ResetPassword(ctx, "alice@example.com", delivery.otps[1], "NewPassword1!"); err != nil {
+		t.Fatalf("Expected the delivered OTP to reset the password, got %v", err)
+	}
+
+	if len(delivery.otps) != 2 || len(delivery.otps[0]) != 8 || len(delivery.otps[1]) != 8 {
+		t.Errorf("Expected two 8-digit OTPs, got %q", delivery.otps)
+	}
+	if delivery.purposes[0] != services.OTPPurposeVerification || delivery.purposes[1] != services.OTPPurposePasswordReset {
+		t.Errorf("Expected a verification and a reset OTP, got %v", delivery.purposes)
+	}
+	if len(emailService.SentEmails) != 0 {
+		t.Errorf("Expected no emails with another channel, got %d", len(emailService.SentEmails))
+	}
+}
@@ -0,0 +1,340 @@
+/**
+ *  ProfileService Tests validate the email change flow: the OTP sent to the new address, the move of
+ *  the account and everything referring to it, and the rejection of invalid or conflicting changes.
+ *  They use mock repositories, a mock EmailService and an injectable clock. Profile picture uploads are
+ *  tested with a mock StorageService.
+ *
+ *  @file       profile_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestProfileService_EmailChange             - Tests a complete change, including friends, blocks, invitations and shared events.
+ *  - TestProfileService_RequestEmailChange_Rejected - Tests invalid, unchanged and taken emails and a wrong password.
+ *  - TestProfileService_ConfirmEmailChange_OTP  - Tests wrong, expired and exhausted OTPs.
+ *  - TestProfileService_EmailChange_OTPSettings - Tests that the OTP length and lifetime of the OTP manager are used.
+ *  - TestProfileService_ConfirmEmailChange_TakenMeanwhile - Tests an email registered after the change was requested.
+ *  - TestProfileService_ConfirmEmailChange_Resume - Tests confirming again after a change failed halfway.
+ *  - TestProfileService_UpdateAvatar            - Tests size and type rejection and that replaced pictures are deleted.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// emailChangeFixture bundles a ProfileService with the mocks backing it.
+type emailChangeFixture struct {
+	service        *services.ProfileService
+	userRepo       *mocks.MockUserRepository
+	friendRepo     *mocks.MockFriendRepository
+	invitationRepo *mocks.MockInvitationRepository
+	shareRepo      *mocks.MockShareRepository
+	emails         *mocks.MockEmailService
+	now            *time.Time
+}
+
+// newEmailChangeFixture creates alice and bob (password "Password123!") who are friends, where alice
+// has blocked carol and shared an event, and bob has invited alice to an event.
+func newEmailChangeFixture(t *testing.T) *emailChangeFixture {
+	t.Helper()
+	userRepo := newUsernameTestRepo(t)
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		"bob@example.com_alice@example.com": {Email: "bob@example.com", FriendEmail: "alice@example.com", Status: "accepted"},
+	})
+	friendRepo.Blocks["alice@example.com_carol@example.com"] = &models.Block{BlockerEmail: "alice@example.com", BlockedEmail: "carol@example.com"}
+	invitationRepo := mocks.NewMockInvitationRepository()
+	invitationRepo.CreateInvitation(context.Background(), &models.EventInvitation{
+		EventID: "event1", OwnerEmail: "bob@example.com", InviteeEmail: "alice@example.com", Status: "accepted",
+	})
+	shareRepo := mocks.NewMockShareRepository()
+	shareRepo.Shares["token1"] = models.EventShare{Email: "alice@example.com", EventID: "event2"}
+	emails := &mocks.MockEmailService{}
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := services.NewProfileService(userRepo, friendRepo, invitationRepo, emails, nil, testJWT).(*services.ProfileService)
+	service.ShareRepo = shareRepo
+	service.Now = func() time.Time { return now }
+	return &emailChangeFixture{service, userRepo, friendRepo, invitationRepo, shareRepo, emails, &now}
+}
+
+func TestProfileService_EmailChange(t *testing.T) {
+	f := newEmailChangeFixture(t)
+	ctx := context.Background()
+
+	if err := f.service.RequestEmailChange(ctx, "alice@example.com", " alice@new.example.com ", "Password123!"); err != nil {
+		t.Fatalf("Failed to request email change: %v", err)
+	}
+	alice := f.userRepo.Users["alice@example.com"]
+	if alice.PendingEmail != "alice@new.example.com" || alice.EmailChangeOTP == "" {
+		t.Fatalf("Expected a pending change to alice@new.example.com with an OTP, got %q", alice.PendingEmail)
+	}
+	if len(f.emails.SentEmails) != 1 || f.emails.SentEmails[0].To != "alice@new.example.com" {
+		t.Fatalf("Expected the OTP to be sent to the new address, got %+v", f.emails.SentEmails)
+	}
+	if alice.OTP != "" {
+		t.Errorf("Expected the email change not to set the password reset OTP")
+	}
+	otp := f.emails.LastOTP()
+	if otp == "" || alice.EmailChangeOTP == otp {
+		t.Errorf("Expected only a hash of the emailed OTP to be stored")
+	}
+
+	token, err := f.service.ConfirmEmailChange(ctx, "alice@example.com", otp)
+	if err != nil || token == "" {
+		t.Fatalf("Expected the change to be confirmed with a token, got %v", err)
+	}
+
+	if _, exists := f.userRepo.Users["alice@example.com"]; exists {
+		t.Errorf("Expected the old email to be free")
+	}
+	moved := f.userRepo.Users["alice@new.example.com"]
+	if moved == nil || moved.Email != "alice@new.example.com" || moved.Username != "Alice" {
+		t.Fatalf("Expected alice under the new email, got %+v", moved)
+	}
+	if moved.PendingEmail != "" || moved.EmailChangeOTP != "" {
+		t.Errorf("Expected the pending change to be cleared")
+	}
+
+	if friend, err := f.friendRepo.GetFriendRequest(ctx, "bob@example.com", "alice@new.example.com"); err != nil || friend.FriendEmail != "alice@new.example.com" {
+		t.Errorf("Expected the friendship to refer to the new email, got %v (err: %v)", friend, err)
+	}
+	if block, _ := f.friendRepo.GetBlock(ctx, "alice@new.example.com", "carol@example.com"); block == nil {
+		t.Errorf("Expected the block to refer to the new email")
+	}
+	if invitation, _ := f.invitationRepo.GetInvitation(ctx, "event1", "alice@new.example.com"); invitation == nil || invitation.Status != "accepted" {
+		t.Errorf("Expected the invitation to refer to the new email, got %+v", invitation)
+	}
+	if share, _ := f.shareRepo.GetEventShare(ctx, "token1"); share == nil || share.Email != "alice@new.example.com" {
+		t.Errorf("Expected the shared event link to refer to the new email, got %+v", share)
+	}
+}
+
+func TestProfileService_RequestEmailChange_Rejected(t *testing.T) {
+	f := newEmailChangeFixture(t)
+
+	tests := []struct {
+		name     string
+		newEmail string
+		password string
+		wantErr  error
+	}{
+		{"invalid email", "not-an-email", "Password123!", services.ErrInvalidEmail},
+		{"same email", "Alice@example.com", "Password123!", services.ErrSameEmail},
+		{"taken email", "bob@example.com", "Password123!", services.ErrEmailTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := f.service.RequestEmailChange(context.Background(), "alice@example.com", tt.newEmail, tt.password); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	err := f.service.RequestEmailChange(context.Background(), "alice@example.com", "alice@new.example.com", "Wrong123!")
+	if err == nil || err.Error() != "Invalid current password" {
+		t.Errorf("Expected 'Invalid current password', got %v", err)
+	}
+	if len(f.emails.SentEmails) != 0 || f.userRepo.Users["alice@example.com"].PendingEmail != "" {
+		t.Errorf("Expected rejected requests not to start a change")
+	}
+}
+
+func TestProfileService_ConfirmEmailChange_OTP(t *testing.T) {
+	f := newEmailChangeFixture(t)
+	ctx := context.Background()
+
+	if _, err := f.service.ConfirmEmailChange(ctx, "alice@example.com", "123456"); !errors.Is(err, services.ErrNoPendingEmailChange) {
+		t.Fatalf("Expected ErrNoPendingEmailChange, got %v", err)
+	}
+
+	if err := f.service.RequestEmailChange(ctx, "alice@example.com", "alice@new.example.com", "Password123!"); err != nil {
+		t.Fatalf("Failed to request email change: %v", err)
+	}
+	alice := f.userRepo.Users["alice@example.com"]
+	otp := f.emails.LastOTP()
+
+	// An expired OTP is rejected.
+	*f.now = f.now.Add(11 * time.Minute)
+	if _, err := f.service.ConfirmEmailChange(ctx, "alice@example.com", otp); !errors.Is(err, services.ErrOTPExpired) {
+		t.Fatalf("Expected ErrOTPExpired, got %v", err)
+	}
+	if err := f.service.RequestEmailChange(ctx, "alice@example.com", "alice@new.example.com", "Password123!"); err != nil {
+		t.Fatalf("Failed to request email change: %v", err)
+	}
+
+	// Too many wrong OTPs cancel the pending change.
+	for i := 1; i < services.DefaultMaxOTPAttempts; i++ {
+		if _, err := f.service.ConfirmEmailChange(ctx, "alice@example.com", "000000"); !errors.Is(err, services.ErrInvalidOTP) {
+			t.Fatalf("Attempt %d: expected ErrInvalidOTP, got %v", i, err)
+		}
+	}
+	if _, err := f.service.ConfirmEmailChange(ctx, "alice@example.com", "000000"); !errors.Is(err, services.ErrTooManyOTPAttempts) {
+		t.Fatalf("Expected ErrTooManyOTPAttempts, got %v", err)
+	}
+	if alice.PendingEmail != "" {
+		t.Errorf("Expected the pending change to be cancelled")
+	}
+	if _, exists := f.userRepo.Users["alice@new.example.com"]; exists {
+		t.Errorf("Expected the email not to have changed")
+	}
+}
+
+func TestProfileService_EmailChange_OTPSettings(t *testing.T) {
+	f := newEmailChangeFixture(t)
+	f.service.OTP.Length = 8
+	f.service.OTP.TTL = 3 * time.Minute
+	ctx := context.Background()
+
+	if err := f.service.RequestEmailChange(ctx, "alice@example.com", "alice@new.example.com", "Password123!"); err != nil {
+		t.Fatalf("Failed to request email change: %v", err)
+	}
+	otp := regexp.MustCompile(`\b\d{8}\b`).FindString(f.emails.SentEmails[len(f.emails.SentEmails)-1].Body)
+	if otp == "" {
+		t.Fatalf("Expected an 8-digit OTP in the email, got %q", f.emails.SentEmails[len(f.emails.SentEmails)-1].Body)
+	}
+	if expiresAt := f.userRepo.Users["alice@example.com"].EmailChangeOTPExpiresAt; !expiresAt.Equal(f.now.Add(3 * time.Minute)) {
+		t.Errorf("Expected the OTP to expire after 3 minutes, got %v", expiresAt)
+	}
+	if _, err := f.service.ConfirmEmailChange(ctx, "alice@example.com", otp); err != nil {
+		t.Fatalf("Expected the 8-digit OTP to confirm the change, got %v", err)
+	}
+}
+
+func TestProfileService_ConfirmEmailChange_TakenMeanwhile(t *testing.T) {
+	f := newEmailChangeFixture(t)
+	ctx := context.Background()
+
+	if err := f.service.RequestEmailChange(ctx, "alice@example.com", "alice@new.example.com", "Password123!"); err != nil {
+		t.Fatalf("Failed to request email change: %v", err)
+	}
+	f.userRepo.Users["alice@new.example.com"] = &models.User{Email: "alice@new.example.com", Username: "squatter"}
+
+	otp := f.emails.LastOTP()
+	if _, err := f.service.ConfirmEmailChange(ctx, "alice@example.com", otp); !errors.Is(err, services.ErrEmailTaken) {
+		t.Fatalf("Expected ErrEmailTaken, got %v", err)
+	}
+	if f.userRepo.Users["alice@new.example.com"].Username != "squatter" {
+		t.Errorf("Expected the existing account not to be overwritten")
+	}
+}
+
+func TestProfileService_ConfirmEmailChange_Resume(t *testing.T) {
+	f := newEmailChangeFixture(t)
+	ctx := context.Background()
+
+	if err := f.service.RequestEmailChange(ctx, "alice@example.com", "alice@new.example.com", "Password123!"); err != nil {
+		t.Fatalf("Failed to request email change: %v", err)
+	}
+	otp := f.emails.LastOTP()
+
+	// The invitations cannot be moved: the account and its pending change stay under the old email.
+	f.invitationRepo.Err = repositories.ErrUnavailable
+	if _, err := f.service.ConfirmEmailChange(ctx, "alice@example.com", otp); err == nil {
+		t.Fatalf("Expected the change to fail")
+	}
+	alice := f.userRepo.Users["alice@example.com"]
+	if alice == nil || alice.PendingEmail != "alice@new.example.com" {
+		t.Fatalf("Expected alice to keep the pending change under the old email, got %+v", alice)
+	}
+	if friend, _ := f.friendRepo.GetFriendRequest(ctx, "bob@example.com", "alice@new.example.com"); friend == nil {
+		t.Fatalf("Expected the friendship to have moved before the failure")
+	}
+
+	// A copy of alice under the new email, as left by a user move that failed halfway, is resumed.
+	copied := *alice
+	copied.Email, copied.MovedFrom = "alice@new.example.com", "alice@example.com"
+	f.userRepo.Users["alice@new.example.com"] = &copied
+
+	f.invitationRepo.Err = nil
+	token, err := f.service.ConfirmEmailChange(ctx, "alice@example.com", otp)
+	if err != nil || token == "" {
+		t.Fatalf("Expected confirming again to finish the change, got %v", err)
+	}
+	moved := f.userRepo.Users["alice@new.example.com"]
+	if _, exists := f.userRepo.Users["alice@example.com"]; exists || moved == nil {
+		t.Fatalf("Expected alice to have moved to the new email")
+	}
+	if moved.PendingEmail != "" || moved.EmailChangeOTP != "" || moved.MovedFrom != "" {
+		t.Errorf("Expected the pending change and the move to be cleared, got %+v", moved)
+	}
+	if friend, err := f.friendRepo.GetFriendRequest(ctx, "bob@example.com", "alice@new.example.com"); err != nil || friend.Status != "accepted" {
+		t.Errorf("Expected the friendship to be kept, got %v (err: %v)", friend, err)
+	}
+	if invitation, _ := f.invitationRepo.GetInvitation(ctx, "event1", "alice@new.example.com"); invitation == nil {
+		t.Errorf("Expected the invitation to refer to the new email")
+	}
+	if share, _ := f.shareRepo.GetEventShare(ctx, "token1"); share == nil || share.Email != "alice@new.example.com" {
+		t.Errorf("Expected the shared event link to refer to the new email, got %+v", share)
+	}
+}
+
+// pngHeader is the signature that makes content be detected as a PNG image.
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+func TestProfileService_UpdateAvatar(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	storage := mocks.NewMockStorageService()
+	service := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, storage, testJWT)
+	ctx := context.Background()
+
+	rejected := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"text", []byte("not an image"), services.ErrUnsupportedAvatarType},
+		{"gif", []byte("GIF89a" + strings.Repeat("x", 16)), services.ErrUnsupportedAvatarType},
+		{"too large", []byte(pngHeader + strings.Repeat("x", services.MaxAvatarSize)), services.ErrAvatarTooLarge},
+	}
+	for _, tt := range rejected {
+		if _, err := service.UpdateAvatar(ctx, "alice@example.com", tt.data); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+	if len(storage.Objects) != 0 || userRepo.Users["alice@example.com"].ImageURL != "" {
+		t.Fatalf("Expected rejected pictures not to be stored")
+	}
+
+	firstURL, err := service.UpdateAvatar(ctx, "alice@example.com", []byte(pngHeader+"first"))
+	if err != nil {
+		t.Fatalf("Failed to upload profile picture: %v", err)
+	}
+	if storage.Objects[firstURL].ContentType != "image/png" || userRepo.Users["alice@example.com"].ImageURL != firstURL {
+		t.Fatalf("Expected the PNG to be stored and set as the profile picture, got %+v", storage.Objects)
+	}
+
+	secondURL, err := service.UpdateAvatar(ctx, "alice@example.com", []byte("\xff\xd8\xff\xe0second"))
+	if err != nil {
+		t.Fatalf("Failed to replace profile picture: %v", err)
+	}
+	if secondURL == firstURL || !strings.HasSuffix(secondURL, ".jpg") {
+		t.Errorf("Expected a new JPEG object, got %s", secondURL)
+	}
+	if _, exists := storage.Objects[firstURL]; exists || len(storage.Objects) != 1 {
+		t.Errorf("Expected the replaced picture to be deleted, got %+v", storage.Objects)
+	}
+
+	if err := service.DeleteAvatar(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("Failed to delete profile picture: %v", err)
+	}
+	if len(storage.Objects) != 0 || userRepo.Users["alice@example.com"].ImageURL != "" {
+		t.Errorf("Expected the profile picture to be removed, got %+v", storage.Objects)
+	}
+}
@@ -0,0 +1,197 @@
+/**
+ *  PromptService Tests validate the daily rotation of journal prompts, skipping to the next prompt of
+ *  a user's sequence, and that journal entries record the prompt they answer.
+ *
+ *  @file       prompt_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestPromptService_DailyRotation   - Tests that a day has one prompt for every user who did not skip, and that it changes over the days.
+ *  - TestPromptService_SkipPrompt      - Tests that skips walk through every prompt once, only for the user who skipped, and carry over to later days.
+ *  - TestPromptService_TimeZones       - Tests that the prompt changes at midnight in the user's time zone.
+ *  - TestPromptService_RepositoryError - Tests that repository failures are returned.
+ *  - TestJournalService_PromptID       - Tests that entries keep the prompt they were created for and unknown prompts are rejected.
+ *
+ *  @dependencies
+ *  - mocks.NewMockPromptRepository: Stores the prompt offsets.
+ *  - mocks.NewMockUserRepository: Provides the time zones of users.
+ *  - mocks.NewMockJournalRepository: Stores the journal entries.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newPromptService returns a PromptService whose clock reads *now, for users in Oslo and Tokyo.
+func newPromptService(now *time.Time) (*services.PromptService, *mocks.MockPromptRepository) {
+	promptRepo := mocks.NewMockPromptRepository()
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", TimeZone: "Europe/Oslo"},
+		"bob@example.com":   {Email: "bob@example.com", TimeZone: "Europe/Oslo"},
+		"kenji@example.com": {Email: "kenji@example.com", TimeZone: "Asia/Tokyo"},
+	})
+	service := services.NewPromptService(promptRepo, userRepo).(*services.PromptService)
+	service.Now = func() time.Time { return *now }
+	return service, promptRepo
+}
+
+// mustPrompt returns the prompt of userEmail, failing the test on an error.
+func mustPrompt(t *testing.T, service *services.PromptService, userEmail string) models.JournalPrompt {
+	t.Helper()
+	prompt, err := service.GetPrompt(context.Background(), userEmail)
+	if err != nil {
+		t.Fatalf("Failed to get the prompt of %s: %v", userEmail, err)
+	}
+	return *prompt
+}
+
+func TestPromptService_DailyRotation(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service, _ := newPromptService(&now)
+
+	seen := map[string]bool{}
+	for day := 0; day < 30; day++ {
+		now = time.Date(2024, 3, 1+day, 9, 0, 0, 0, time.UTC)
+		morning := mustPrompt(t, service, "alice@example.com")
+		if morning.ID == "" || morning.Text == "" || !service.IsPrompt(morning.ID) {
+			t.Fatalf("Expected a prompt from the list, got %+v", morning)
+		}
+		if other := mustPrompt(t, service, "bob@example.com"); other != morning {
+			t.Errorf("Expected every user to get %+v on day %d, got %+v", morning, day, other)
+		}
+		now = now.Add(10 * time.Hour)
+		if evening := mustPrompt(t, service, "alice@example.com"); evening != morning {
+			t.Errorf("Expected the same prompt all day, got %+v and %+v", morning, evening)
+		}
+		seen[morning.ID] = true
+	}
+	if len(seen) < 10 {
+		t.Errorf("Expected the prompt to change over 30 days, got %d different prompts", len(seen))
+	}
+}
+
+func TestPromptService_SkipPrompt(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service, promptRepo := newPromptService(&now)
+	ctx := context.Background()
+
+	first := mustPrompt(t, service, "alice@example.com")
+	seen := map[string]bool{first.ID: true}
+	for {
+		next, err := service.SkipPrompt(ctx, "alice@example.com")
+		if err != nil {
+			t.Fatalf("Skip failed: %v", err)
+		}
+		if got := mustPrompt(t, service, "alice@example.com"); got != *next {
+			t.Fatalf("Expected the skipped-to prompt %+v to stay, got %+v", next, got)
+		}
+		if next.ID == first.ID {
+			break
+		}
+		if seen[next.ID] {
+			t.Fatalf("Expected every prompt once before the first comes back, got %s twice", next.ID)
+		}
+		seen[next.ID] = true
+	}
+	skips := len(seen)
+	if skips < 2 || promptRepo.Offsets["alice@example.com"].Offset != skips || !promptRepo.Offsets["alice@example.com"].UpdatedAt.Equal(now) {
+		t.Errorf("Expected %d skips recorded at %v, got %+v", skips, now, promptRepo.Offsets["alice@example.com"])
+	}
+	if got := mustPrompt(t, service, "bob@example.com"); got != first {
+		t.Errorf("Expected skips not to change the prompt of other users, got %+v", got)
+	}
+
+	// The next day, the user stays the same number of prompts ahead of the rotation.
+	service.SkipPrompt(ctx, "alice@example.com")
+	now = now.AddDate(0, 0, 1)
+	service.SkipPrompt(ctx, "bob@example.com")
+	if alice, bob := mustPrompt(t, service, "alice@example.com"), mustPrompt(t, service, "bob@example.com"); alice != bob {
+		t.Errorf("Expected users one skip ahead to get the same prompt, got %+v and %+v", alice, bob)
+	}
+}
+
+func TestPromptService_TimeZones(t *testing.T) {
+	// 2024-03-01 20:00 in Oslo is already 2024-03-02 in Tokyo.
+	now := time.Date(2024, 3, 1, 19, 0, 0, 0, time.UTC)
+	service, _ := newPromptService(&now)
+
+	kenjiToday := mustPrompt(t, service, "kenji@example.com")
+	now = time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	if alice := mustPrompt(t, service, "alice@example.com"); alice != kenjiToday {
+		t.Errorf("Expected Oslo to get Tokyo's prompt of 2024-03-02 on that date, got %+v and %+v", alice, kenjiToday)
+	}
+}
+
+func TestPromptService_RepositoryError(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service, promptRepo := newPromptService(&now)
+	promptRepo.Err = repositories.ErrUnavailable
+
+	if _, err := service.GetPrompt(context.Background(), "alice@example.com"); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected the repository failure, got %v", err)
+	}
+	if _, err := service.SkipPrompt(context.Background(), "alice@example.com"); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected the repository failure, got %v", err)
+	}
+}
+
+func TestJournalService_PromptID(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	promptService, _ := newPromptService(&now)
+	prompt := mustPrompt(t, promptService, "alice@example.com")
+
+	journalRepo := mocks.NewMockJournalRepository()
+	journalService := services.NewJournalService(journalRepo, nil).(*services.JournalService)
+	journalService.Prompts = promptService
+	ctx := context.Background()
+
+	journal := &models.Journal{Email: "alice@example.com", Date: "2024-03-01", Content: "A good day", PromptID: " " + prompt.ID + " "}
+	if err := journalService.CreateJournal(ctx, journal); err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	if stored, _ := journalService.GetJournal(ctx, "alice@example.com", journal.JournalID); stored == nil || stored.PromptID != prompt.ID {
+		t.Errorf("Expected the entry to record prompt %s, got %+v", prompt.ID, stored)
+	}
+
+	// Updates keep the prompt the entry was written for.
+	update := &models.Journal{Email: "alice@example.com", JournalID: journal.JournalID, Date: "2024-03-01", Content: "A great day", PromptID: "prompt-999"}
+	if err := journalService.UpdateJournal(ctx, update); err != nil {
+		t.Fatalf("Failed to update journal: %v", err)
+	}
+	upsert := &models.Journal{Email: "alice@example.com", Date: "2024-03-01", Content: "The best day"}
+	if err := journalService.UpsertJournal(ctx, upsert); err != nil {
+		t.Fatalf("Failed to upsert journal: %v", err)
+	}
+	if stored, _ := journalService.GetJournal(ctx, "alice@example.com", journal.JournalID); stored == nil || stored.PromptID != prompt.ID || stored.Content != "The best day" {
+		t.Errorf("Expected the prompt to be kept, got %+v", stored)
+	}
+
+	for _, save := range []func(*models.Journal) error{
+		func(j *models.Journal) error { return journalService.CreateJournal(ctx, j) },
+		func(j *models.Journal) error { return journalService.UpsertJournal(ctx, j) },
+	} {
+		unknown := &models.Journal{Email: "bob@example.com", Date: "2024-03-01", Content: "Hello", PromptID: "prompt-999"}
+		if err := save(unknown); !errors.Is(err, services.ErrUnknownPrompt) {
+			t.Errorf("Expected an unknown prompt to be rejected, got %v", err)
+		}
+	}
+	if len(journalRepo.Journals) != 1 {
+		t.Errorf("Expected only the first entry to be stored, got %d", len(journalRepo.Journals))
+	}
+}
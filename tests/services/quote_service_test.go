@@ -0,0 +1,137 @@
+/**
+ *  QuoteService Tests validate that the daily verse is picked from the embedded dataset in a stable
+ *  way, and that favourites are saved through a mock repository.
+ *
+ *  @file       quote_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestQuoteService_GetDailyQuote_Stable      - Tests that a date always gives the same quote, and that the quote changes over the days.
+ *  - TestQuoteService_GetDailyQuote_Language    - Tests that quotes are picked in the language asked for, falling back to English.
+ *  - TestQuoteService_GetDailyQuote_InvalidDate - Tests that dates not in the format YYYY-MM-DD are rejected.
+ *  - TestQuoteService_Favorites                 - Tests saving favourites, saving one twice, and unknown quotes.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/tests/mocks"
+)
+
+func TestQuoteService_GetDailyQuote_Stable(t *testing.T) {
+	ctx := context.Background()
+	first := services.NewQuoteService(mocks.NewMockFavoriteRepository())
+	second := services.NewQuoteService(mocks.NewMockFavoriteRepository())
+
+	seen := map[string]bool{}
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 365; i++ {
+		date := day.AddDate(0, 0, i).Format("2006-01-02")
+		quote, err := first.GetDailyQuote(ctx, date, "en")
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", date, err)
+		}
+		again, _ := second.GetDailyQuote(ctx, date, "en")
+		if again.ID != quote.ID {
+			t.Fatalf("Expected the same quote for %s, got %s and %s", date, quote.ID, again.ID)
+		}
+		if quote.Text == "" || quote.Author == "" {
+			t.Errorf("Expected quote %s to have a text and an author", quote.ID)
+		}
+		seen[quote.ID] = true
+	}
+	if len(seen) < 100 {
+		t.Errorf("Expected the quote to change over a year, got only %d different quotes", len(seen))
+	}
+}
+
+func TestQuoteService_GetDailyQuote_Language(t *testing.T) {
+	quoteService := services.NewQuoteService(mocks.NewMockFavoriteRepository())
+	ctx := context.Background()
+
+	tests := []struct {
+		language string
+		expected string
+	}{
+		{"en", "en"},
+		{"no", "no"},
+		{" NO ", "no"},
+		{"sv", services.DefaultQuoteLanguage},
+		{"", services.DefaultQuoteLanguage},
+	}
+	for _, tt := range tests {
+		quote, err := quoteService.GetDailyQuote(ctx, "2024-05-17", tt.language)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.language, err)
+		}
+		if quote.Language != tt.expected {
+			t.Errorf("Expected a quote in %q for %q, got %q", tt.expected, tt.language, quote.Language)
+		}
+	}
+}
+
+func TestQuoteService_GetDailyQuote_InvalidDate(t *testing.T) {
+	quoteService := services.NewQuoteService(mocks.NewMockFavoriteRepository())
+	for _, date := range []string{"", "17.05.2024", "2024-5-17", "2024-02-30"} {
+		if _, err := quoteService.GetDailyQuote(context.Background(), date, "en"); err == nil {
+			t.Errorf("Expected an error for %q", date)
+		}
+	}
+}
+
+func TestQuoteService_Favorites(t *testing.T) {
+	favoriteRepo := mocks.NewMockFavoriteRepository()
+	quoteService := services.NewQuoteService(favoriteRepo)
+	now := time.Date(2024, 5, 17, 8, 0, 0, 0, time.UTC)
+	quoteService.(*services.QuoteService).Now = func() time.Time { return now }
+	ctx := context.Background()
+	userEmail := "test@example.com"
+
+	quote, _ := quoteService.GetDailyQuote(ctx, "2024-05-17", "no")
+	favorite, err := quoteService.SaveFavorite(ctx, userEmail, quote.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if favorite.Text != quote.Text || favorite.Author != quote.Author || !favorite.SavedAt.Equal(now) {
+		t.Errorf("Expected the favourite to copy the quote, got %+v", favorite)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := quoteService.SaveFavorite(ctx, userEmail, "en-001"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := quoteService.SaveFavorite(ctx, userEmail, quote.ID); err != nil {
+		t.Fatalf("Unexpected error saving a favourite again: %v", err)
+	}
+
+	favorites, err := quoteService.GetFavorites(ctx, userEmail)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(favorites) != 2 || favorites[0].ID != quote.ID || favorites[1].ID != "en-001" {
+		t.Errorf("Expected each quote once, most recently saved first, got %+v", favorites)
+	}
+
+	if _, err := quoteService.SaveFavorite(ctx, userEmail, "xx-999"); !errors.Is(err, services.ErrQuoteNotFound) {
+		t.Errorf("Expected ErrQuoteNotFound, got %v", err)
+	}
+
+	favoriteRepo.Err = repositories.ErrUnavailable
+	if _, err := quoteService.SaveFavorite(ctx, userEmail, "en-001"); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable to be passed through, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+/**
+ *  ReminderService Tests validate that event reminders are sent at the right time and only once.
+ *  They use a mock EventRepository, a mock EmailService, and a fixed clock so no sleeping is needed.
+ *
+ *  @file       reminder_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestReminderService_SendDueReminders_SendsOnce   - Tests that a due reminder is sent exactly once.
+ *  - TestReminderService_SendDueReminders_NotYetDue   - Tests that reminders are not sent early.
+ *  - TestReminderService_Run_UsesInjectedTicks        - Tests that the scheduler runs on injected ticks.
+ *
+ *  @dependencies
+ *  - mocks.NewMockEventRepository: Mock implementation of EventRepository for testing.
+ *  - mocks.MockEmailService: Mock implementation of EmailService for testing.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newTestReminderService creates a ReminderService with a fixed clock.
+func newTestReminderService(now time.Time) (*services.ReminderService, *mocks.MockEventRepository, *mocks.MockEmailService) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	mockEmailService := &mocks.MockEmailService{}
+	reminderService := services.NewReminderService(mockEventRepo, mockEmailService).(*services.ReminderService)
+	reminderService.Now = func() time.Time { return now }
+	return reminderService, mockEventRepo, mockEmailService
+}
+
+func TestReminderService_SendDueReminders_SendsOnce(t *testing.T) {
+	now := time.Date(2024, 12, 1, 9, 50, 0, 0, time.UTC)
+	reminderService, mockEventRepo, mockEmailService := newTestReminderService(now)
+
+	mockEventRepo.CreateEvent(context.Background(), &models.Event{
+		Email:                 "user@example.com",
+		Title:                 "Meeting",
+		Date:                  "2024-12-01",
+		StartTime:             "10:00",
+		StartAt:               time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC),
+		ReminderMinutesBefore: 15,
+	})
+
+	sent, err := reminderService.SendDueReminders(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("Expected 1 reminder to be sent, got %d", sent)
+	}
+	if len(mockEmailService.SentEmails) != 1 || mockEmailService.SentEmails[0].To != "user@example.com" {
+		t.Errorf("Expected a reminder email to user@example.com, got %+v", mockEmailService.SentEmails)
+	}
+
+	// A second pass must not resend the reminder.
+	sent, _ = reminderService.SendDueReminders(context.Background())
+	if sent != 0 {
+		t.Errorf("Expected reminder to be sent only once, got %d more", sent)
+	}
+	if len(mockEmailService.SentEmails) != 1 {
+		t.Errorf("Expected 1 email in total, got %d", len(mockEmailService.SentEmails))
+	}
+}
+
+func TestReminderService_SendDueReminders_NotYetDue(t *testing.T) {
+	now := time.Date(2024, 12, 1, 9, 0, 0, 0, time.UTC)
+	reminderService, mockEventRepo, mockEmailService := newTestReminderService(now)
+
+	mockEventRepo.CreateEvent(context.Background(), &models.Event{
+		Email:                 "user@example.com",
+		Title:                 "Meeting",
+		StartAt:               time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC),
+		ReminderMinutesBefore: 15,
+	})
+	mockEventRepo.CreateEvent(context.Background(), &models.Event{
+		Email:   "user@example.com",
+		Title:   "No reminder",
+		StartAt: time.Date(2024, 12, 1, 9, 5, 0, 0, time.UTC),
+	})
+
+	sent, err := reminderService.SendDueReminders(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sent != 0 || len(mockEmailService.SentEmails) != 0 {
+		t.Errorf("Expected no reminders to be sent, got %d", len(mockEmailService.SentEmails))
+	}
+}
+
+func TestReminderService_Run_UsesInjectedTicks(t *testing.T) {
+	now := time.Date(2024, 12, 1, 9, 50, 0, 0, time.UTC)
+	reminderService, mockEventRepo, mockEmailService := newTestReminderService(now)
+
+	mockEventRepo.CreateEvent(context.Background(), &models.Event{
+		Email:                 "user@example.com",
+		Title:                 "Meeting",
+		StartAt:               time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC),
+		ReminderMinutesBefore: 30,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		reminderService.Run(ctx, ticks)
+		close(done)
+	}()
+
+	// Unbuffered sends return only once Run has received each tick.
+	ticks <- now
+	ticks <- now
+	cancel()
+	<-done
+
+	if len(mockEmailService.SentEmails) != 1 {
+		t.Errorf("Expected 1 reminder email, got %d", len(mockEmailService.SentEmails))
+	}
+}
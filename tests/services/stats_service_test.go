@@ -0,0 +1,113 @@
+/**
+ *  StatsService Tests validate the activity statistics of the dashboard and their per-user cache.
+ *  They use mock repositories to isolate the service from Firestore.
+ *
+ *  @file       stats_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestStatsService_GetUserStats - Tests the counts, the trash exclusion, the cap and the active days of the current month.
+ *  - TestStatsService_Cache        - Tests statistics are cached per user for CacheTTL and failures are not cached.
+ *
+ *  @dependencies
+ *  - mocks.NewMockEventRepository, NewMockJournalRepository, NewMockFriendRepository: Hold the counted data.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// newClockedStatsService creates a StatsService on mock repositories whose clock reads *now.
+func newClockedStatsService(now *time.Time) (*services.StatsService, *mocks.MockEventRepository, *mocks.MockJournalRepository) {
+	eventRepo := mocks.NewMockEventRepository()
+	journalRepo := mocks.NewMockJournalRepository()
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		"user@example.com_friend@example.com":  {Email: "user@example.com", FriendEmail: "friend@example.com", Status: "accepted"},
+		"user@example.com_pending@example.com": {Email: "user@example.com", FriendEmail: "pending@example.com", Status: "pending"},
+	})
+	service := services.NewStatsService(eventRepo, journalRepo, friendRepo).(*services.StatsService)
+	service.Now = func() time.Time { return *now }
+	return service, eventRepo, journalRepo
+}
+
+func TestStatsService_GetUserStats(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 20, 18, 0, 0, 0, time.UTC)
+	service, eventRepo, journalRepo := newClockedStatsService(&now)
+
+	for i := 0; i < services.MaxStatsCount+5; i++ {
+		eventRepo.CreateEvent(ctx, &models.Event{Email: "user@example.com", Title: fmt.Sprintf("Event %d", i), Date: "2024-05-01"})
+	}
+	// Entries from last month, in the trash or after today do not make an active day this month.
+	for _, date := range []string{"2024-04-29", "2024-05-01", "2024-05-02", "2024-05-19", "2024-05-21"} {
+		journalRepo.CreateJournal(ctx, &models.Journal{Email: "user@example.com", Date: date, Content: "Entry"})
+	}
+	deletedAt := now.Add(-time.Hour)
+	trashed := &models.Journal{Email: "user@example.com", Date: "2024-05-05", Content: "Deleted", DeletedAt: &deletedAt}
+	journalRepo.CreateJournal(ctx, trashed)
+
+	stats, err := service.GetUserStats(ctx, "user@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := models.UserStats{Events: services.MaxStatsCount, Journals: 5, Friends: 1, ActiveDays: 3}
+	if *stats != want {
+		t.Errorf("Expected %+v, got %+v", want, *stats)
+	}
+
+	stats, err = service.GetUserStats(ctx, "nobody@example.com")
+	if err != nil || *stats != (models.UserStats{}) {
+		t.Errorf("Expected zero statistics for a new user, got %+v (%v)", stats, err)
+	}
+}
+
+func TestStatsService_Cache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 20, 18, 0, 0, 0, time.UTC)
+	service, eventRepo, journalRepo := newClockedStatsService(&now)
+	eventRepo.CreateEvent(ctx, &models.Event{Email: "user@example.com", Title: "Lecture", Date: "2024-05-20"})
+
+	if stats, _ := service.GetUserStats(ctx, "user@example.com"); stats.Events != 1 {
+		t.Fatalf("Expected 1 event, got %+v", stats)
+	}
+
+	// Within the TTL the cached statistics are returned, even if the data changed or reads fail.
+	eventRepo.CreateEvent(ctx, &models.Event{Email: "user@example.com", Title: "Seminar", Date: "2024-05-21"})
+	journalRepo.Err = repositories.ErrUnavailable
+	now = now.Add(services.DefaultStatsCacheTTL - time.Second)
+	if stats, err := service.GetUserStats(ctx, "user@example.com"); err != nil || stats.Events != 1 {
+		t.Errorf("Expected the cached statistics, got %+v (%v)", stats, err)
+	}
+
+	// Once expired, they are read again, and a failed read is not cached.
+	now = now.Add(time.Second)
+	if _, err := service.GetUserStats(ctx, "user@example.com"); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable after the cache expired, got %v", err)
+	}
+	journalRepo.Err = nil
+	if stats, err := service.GetUserStats(ctx, "user@example.com"); err != nil || stats.Events != 2 {
+		t.Errorf("Expected fresh statistics after the failure, got %+v (%v)", stats, err)
+	}
+
+	// Each user has their own entry.
+	if stats, _ := service.GetUserStats(ctx, "friend@example.com"); stats.Events != 0 || stats.Friends != 1 {
+		t.Errorf("Expected the friend's own statistics, got %+v", stats)
+	}
+}
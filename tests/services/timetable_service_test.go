@@ -0,0 +1,530 @@
+/**
+ *  TimetableService Tests validate importing NTNU ICS timetables, including the timestamp
+ *  formats NTNU emits, per-event import results and dry runs, and exporting events back to ICS.
+ *
+ *  @file       timetable_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestTimetableService_ImportTimetable_NTNUFormats         - Tests parsing of UTC, TZID and floating timestamps.
+ *  - TestTimetableService_ImportTimetable_DryRun              - Tests that a dry run validates without saving.
+ *  - TestTimetableService_ImportTimetable_ContinuesOnFailure  - Tests that one failing event does not abort the import.
+ *  - TestTimetableService_ImportTimetable_InvalidICS          - Tests rejection of content that is not ICS.
+ *  - TestTimetableService_ImportTimetable_Twice               - Tests that re-importing does not duplicate events.
+ *  - TestTimetableService_ImportTimetable_TwiceWithoutUID     - Tests duplicate detection for events without a UID.
+ *  - TestTimetableService_UndoImport                          - Tests that undoing an import deletes only the events it created.
+ *  - TestTimetableService_ExportTimetable_RoundTrip           - Tests that exported events re-import unchanged.
+ *  - TestTimetableService_ExportTimetable_DateRange           - Tests limiting the export to a date range.
+ *  - TestTimetableService_ExportTimetable_Recurring           - Tests RRULE and EXDATE output for recurring events.
+ *  - TestTimetableService_ExportTimetable_AllDay              - Tests that all-day events are exported with DATE-valued DTSTART and DTEND.
+ *  - TestTimetableService_TimeZones                           - Tests TZID parameters in summer time, stored time zones and exporting events of other zones.
+ *  - TestTimetableService_ExportFeed                          - Tests the date range of calendar feeds and the feeds of disabled accounts.
+ *
+ *  @dependencies
+ *  - mocks.NewMockEventRepository: Mock implementation of EventRepository for testing.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/tests/mocks"
+)
+
+// ntnuTimetableICS mimics an export from NTNU's timetable service: UTC timestamps,
+// TZID-qualified local timestamps, a floating timestamp and one event with an unusable start.
+var ntnuTimetableICS = strings.Join([]string{
+	"BEGIN:VCALENDAR",
+	"VERSION:2.0",
+	"PRODID:-//NTNU//TP//NO",
+	"CALSCALE:GREGORIAN",
+	"X-WR-CALNAME:Timeplan",
+	"X-WR-TIMEZONE:Europe/Oslo",
+	"BEGIN:VEVENT",
+	"UID:idatg2204-1@tp.uio.no",
+	"DTSTAMP:20240105T120000Z",
+	"DTSTART:20240115T081500Z",
+	"DTEND:20240115T100000Z",
+	"SUMMARY:IDATG2204 Datamodellering og databasesystemer Forelesning",
+	"LOCATION:A-blokk A155",
+	"DESCRIPTION:Forelesning",
+	"END:VEVENT",
+	"BEGIN:VEVENT",
+	"UID:prog2005-1@tp.uio.no",
+	"DTSTAMP:20240105T120000Z",
+	"DTSTART;TZID=Europe/Oslo:20240116T101500",
+	"DTEND;TZID=Europe/Oslo:20240116T120000",
+	"SUMMARY:PROG2005 Cloud Technologies Lab",
+	"LOCATION:S-blokk S206",
+	"END:VEVENT",
+	"BEGIN:VEVENT",
+	"UID:prog2052-1@tp.uio.no",
+	"DTSTAMP:20240105T120000Z",
+	"DTSTART:20240117T141500",
+	"DTEND:20240117T160000",
+	"SUMMARY:PROG2052 Integrasjonsprosjekt Veiledning",
+	"END:VEVENT",
+	"BEGIN:VEVENT",
+	"UID:idatg2204-2@tp.uio.no",
+	"DTSTAMP:20240105T120000Z",
+	"DTSTART:TBA",
+	"DTEND:20240118T100000Z",
+	"SUMMARY:IDATG2204 Eksamen",
+	"END:VEVENT",
+	"END:VCALENDAR",
+}, "\r\n")
+
+func TestTimetableService_ImportTimetable_NTNUFormats(t *testing.T) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	timetableService := services.NewTimetableService(mockEventRepo)
+
+	result, err := timetableService.ImportTimetable(context.Background(), "user@example.com", ntnuTimetableICS, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Imported != 3 || result.Skipped != 1 || result.Failed != 0 || len(result.Events) != 4 {
+		t.Fatalf("Expected 3 imported and 1 skipped, got %+v", result)
+	}
+	if len(mockEventRepo.Events) != 3 {
+		t.Errorf("Expected 3 stored events, got %d", len(mockEventRepo.Events))
+	}
+
+	// Times are shown in Oslo time (UTC+1 in January).
+	expected := []models.ImportEventResult{
+		{Date: "2024-01-15", StartTime: "09:15", EndTime: "11:00", Status: "imported"},
+		{Date: "2024-01-16", StartTime: "10:15", EndTime: "12:00", Status: "imported"},
+		{Date: "2024-01-17", StartTime: "14:15", EndTime: "16:00", Status: "imported"},
+	}
+	for i, want := range expected {
+		got := result.Events[i]
+		if got.Date != want.Date || got.StartTime != want.StartTime || got.EndTime != want.EndTime || got.Status != want.Status {
+			t.Errorf("Event %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	skipped := result.Events[3]
+	if skipped.Status != "skipped" || skipped.Title != "IDATG2204 Eksamen" || !strings.Contains(skipped.Reason, "start time") {
+		t.Errorf("Expected exam to be skipped with a reason, got %+v", skipped)
+	}
+}
+
+func TestTimetableService_ImportTimetable_DryRun(t *testing.T) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	timetableService := services.NewTimetableService(mockEventRepo)
+
+	result, err := timetableService.ImportTimetable(context.Background(), "user@example.com", ntnuTimetableICS, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.DryRun || result.Imported != 3 || result.Skipped != 1 {
+		t.Errorf("Expected a dry run previewing 3 events, got %+v", result)
+	}
+	if len(mockEventRepo.Events) != 0 {
+		t.Errorf("Expected no events to be stored in a dry run, got %d", len(mockEventRepo.Events))
+	}
+}
+
+// failingEventRepository fails to create events with a given title.
+type failingEventRepository struct {
+	*mocks.MockEventRepository
+	failTitle string
+}
+
+// CreateEvent fails for events titled failTitle and stores all others.
+func (r *failingEventRepository) CreateEvent(ctx context.Context, event *models.Event) error {
+	if event.Title == r.failTitle {
+		return fmt.Errorf("firestore unavailable")
+	}
+	return r.MockEventRepository.CreateEvent(ctx, event)
+}
+
+func TestTimetableService_ImportTimetable_ContinuesOnFailure(t *testing.T) {
+	repo := &failingEventRepository{
+		MockEventRepository: mocks.NewMockEventRepository(),
+		failTitle:           "PROG2005 Cloud Technologies Lab",
+	}
+	timetableService := services.NewTimetableService(repo)
+
+	result, err := timetableService.ImportTimetable(context.Background(), "user@example.com", ntnuTimetableICS, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Imported != 2 || result.Failed != 1 || result.Skipped != 1 {
+		t.Errorf("Expected 2 imported, 1 failed and 1 skipped, got %+v", result)
+	}
+	if failed := result.Events[1]; failed.Status != "failed" || !strings.Contains(failed.Reason, "firestore unavailable") {
+		t.Errorf("Expected lab to be reported as failed, got %+v", failed)
+	}
+	if len(repo.Events) != 2 {
+		t.Errorf("Expected the events after the failure to be stored, got %d", len(repo.Events))
+	}
+}
+
+func TestTimetableService_ImportTimetable_InvalidICS(t *testing.T) {
+	timetableService := services.NewTimetableService(mocks.NewMockEventRepository())
+
+	if _, err := timetableService.ImportTimetable(context.Background(), "user@example.com", "not a calendar", false); err == nil {
+		t.Errorf("Expected an error for invalid ICS content")
+	}
+}
+
+func TestTimetableService_ImportTimetable_Twice(t *testing.T) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	timetableService := services.NewTimetableService(mockEventRepo)
+
+	if _, err := timetableService.ImportTimetable(context.Background(), "user@example.com", ntnuTimetableICS, false); err != nil {
+		t.Fatalf("First import failed: %v", err)
+	}
+
+	// A user's reminder on an imported lecture must survive a re-import.
+	for _, event := range mockEventRepo.Events {
+		event.ReminderMinutesBefore = 15
+	}
+
+	result, err := timetableService.ImportTimetable(context.Background(), "user@example.com", ntnuTimetableICS, false)
+	if err != nil {
+		t.Fatalf("Second import failed: %v", err)
+	}
+	if result.Imported != 0 || result.Existing != 3 || result.Skipped != 1 {
+		t.Errorf("Expected all 3 events to already exist, got %+v", result)
+	}
+	if len(mockEventRepo.Events) != 3 {
+		t.Errorf("Expected the event count to stay at 3, got %d", len(mockEventRepo.Events))
+	}
+	for _, event := range mockEventRepo.Events {
+		if event.ReminderMinutesBefore != 15 {
+			t.Errorf("Expected reminder of %q to be kept, got %d", event.Title, event.ReminderMinutesBefore)
+		}
+	}
+
+	// Another user importing the same timetable gets their own copies.
+	result, _ = timetableService.ImportTimetable(context.Background(), "other@example.com", ntnuTimetableICS, true)
+	if result.Imported != 3 || result.Existing != 0 {
+		t.Errorf("Expected a dry run for another user to create 3 events, got %+v", result)
+	}
+}
+
+func TestTimetableService_ImportTimetable_TwiceWithoutUID(t *testing.T) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	timetableService := services.NewTimetableService(mockEventRepo)
+
+	icsContent := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"DTSTART:20240115T081500Z",
+		"DTEND:20240115T100000Z",
+		"SUMMARY:IDATG2204 Forelesning",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	for i := 0; i < 2; i++ {
+		if _, err := timetableService.ImportTimetable(context.Background(), "user@example.com", icsContent, false); err != nil {
+			t.Fatalf("Import %d failed: %v", i+1, err)
+		}
+	}
+	if len(mockEventRepo.Events) != 1 {
+		t.Errorf("Expected 1 event after importing twice, got %d", len(mockEventRepo.Events))
+	}
+}
+
+func TestTimetableService_UndoImport(t *testing.T) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	timetableService := services.NewTimetableService(mockEventRepo)
+	timetableService.(*services.TimetableService).Now = func() time.Time { return time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	manual := &models.Event{Email: "user@example.com", Title: "Dentist", Date: "2024-01-15", EventTypeID: "private"}
+	mockEventRepo.CreateEvent(ctx, manual)
+
+	// The second import creates one new event and updates the three from the first.
+	first, err := timetableService.ImportTimetable(ctx, "user@example.com", ntnuTimetableICS, false)
+	if err != nil || first.BatchID == "" {
+		t.Fatalf("Expected the import to return a batch ID, got %+v (err: %v)", first, err)
+	}
+	timetableService.(*services.TimetableService).Now = func() time.Time { return time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC) }
+	extra := strings.Replace(ntnuTimetableICS, "END:VCALENDAR", strings.Join([]string{
+		"BEGIN:VEVENT",
+		"UID:prog2052-2@tp.uio.no",
+		"DTSTART:20240124T131500Z",
+		"DTEND:20240124T150000Z",
+		"SUMMARY:PROG2052 Integrasjonsprosjekt Veiledning",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n"), 1)
+	second, err := timetableService.ImportTimetable(ctx, "user@example.com", extra, false)
+	if err != nil || second.BatchID == "" || second.BatchID == first.BatchID || second.Imported != 1 || second.Existing != 3 {
+		t.Fatalf("Expected a new batch with 1 imported and 3 existing events, got %+v (err: %v)", second, err)
+	}
+
+	batches, err := timetableService.GetImportBatches(ctx, "user@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(batches) != 2 || batches[0].BatchID != second.BatchID || batches[0].EventCount != 1 ||
+		batches[1].BatchID != first.BatchID || batches[1].EventCount != 3 || !batches[1].ImportedAt.Equal(time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the second and then the first import, got %+v", batches)
+	}
+
+	deleted, err := timetableService.UndoImport(ctx, "user@example.com", first.BatchID)
+	if err != nil || deleted != 3 {
+		t.Fatalf("Expected 3 events to be deleted, got %d (err: %v)", deleted, err)
+	}
+	if len(mockEventRepo.Events) != 2 || mockEventRepo.Events[manual.EventID] == nil {
+		t.Errorf("Expected the manual event and the second import's event to remain, got %d events", len(mockEventRepo.Events))
+	}
+	for _, event := range mockEventRepo.Events {
+		if event.ImportBatchID != "" && event.ImportBatchID != second.BatchID {
+			t.Errorf("Expected no event of the first import to remain, got %+v", event)
+		}
+	}
+
+	if _, err := timetableService.UndoImport(ctx, "user@example.com", first.BatchID); !errors.Is(err, services.ErrImportBatchNotFound) {
+		t.Errorf("Expected ErrImportBatchNotFound when undoing twice, got %v", err)
+	}
+	if _, err := timetableService.UndoImport(ctx, "other@example.com", second.BatchID); !errors.Is(err, services.ErrImportBatchNotFound) {
+		t.Errorf("Expected another user not to undo the import, got %v", err)
+	}
+
+	dryRun, _ := timetableService.ImportTimetable(ctx, "user@example.com", ntnuTimetableICS, true)
+	if dryRun.BatchID != "" {
+		t.Errorf("Expected no batch ID for a dry run, got %q", dryRun.BatchID)
+	}
+}
+
+// exportedEvents are created by the owner and exported in the round-trip tests.
+var exportedEvents = []models.Event{
+	{Title: "Team meeting", Description: "Weekly sync, bring notes", StreetAddress: "Teknologiveien 22, Gjøvik", Date: "2024-03-30", StartTime: "09:00", EndTime: "10:30"},
+	{Title: "Summer lecture", Description: "Daylight saving time", StreetAddress: "A-blokk A155", Date: "2024-06-15", StartTime: "14:15", EndTime: "16:00"},
+	{Title: "Late shift", Date: "2024-07-01", StartTime: "22:00", EndTime: "23:45"},
+}
+
+// newExportService creates a TimetableService whose repository holds exportedEvents for owner@example.com.
+func newExportService(t *testing.T) (services.TimetableServiceInterface, *mocks.MockEventRepository) {
+	t.Helper()
+	mockEventRepo := mocks.NewMockEventRepository()
+	for _, event := range exportedEvents {
+		event.Email = "owner@example.com"
+		event.EventTypeID = "private"
+		mockEventRepo.CreateEvent(context.Background(), &event)
+	}
+	return services.NewTimetableService(mockEventRepo), mockEventRepo
+}
+
+func TestTimetableService_ExportTimetable_RoundTrip(t *testing.T) {
+	timetableService, mockEventRepo := newExportService(t)
+
+	var calendar strings.Builder
+	if err := timetableService.ExportTimetable(context.Background(), "owner@example.com", "", "", &calendar); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(calendar.String(), "DTSTART;TZID=Europe/Oslo:20240330T090000") {
+		t.Errorf("Expected DTSTART in Oslo time, got:\n%s", calendar.String())
+	}
+
+	// Re-import the calendar for another user and compare the events field by field.
+	result, err := timetableService.ImportTimetable(context.Background(), "copy@example.com", calendar.String(), false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Imported != len(exportedEvents) || result.Skipped != 0 {
+		t.Fatalf("Expected %d imported events, got %+v", len(exportedEvents), result)
+	}
+
+	imported := map[string]models.Event{}
+	for _, event := range mockEventRepo.Events {
+		if event.Email == "copy@example.com" {
+			imported[event.Title] = *event
+		}
+	}
+	for _, want := range exportedEvents {
+		got, ok := imported[want.Title]
+		if !ok {
+			t.Errorf("Event %q was not imported", want.Title)
+			continue
+		}
+		if got.Description != want.Description || got.StreetAddress != want.StreetAddress ||
+			got.Date != want.Date || got.StartTime != want.StartTime || got.EndTime != want.EndTime {
+			t.Errorf("Event %q changed in the round trip: want %+v, got %+v", want.Title, want, got)
+		}
+	}
+}
+
+func TestTimetableService_ExportTimetable_DateRange(t *testing.T) {
+	timetableService, _ := newExportService(t)
+
+	var calendar strings.Builder
+	if err := timetableService.ExportTimetable(context.Background(), "owner@example.com", "2024-06-01", "2024-06-30", &calendar); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if count := strings.Count(calendar.String(), "BEGIN:VEVENT"); count != 1 || !strings.Contains(calendar.String(), "SUMMARY:Summer lecture") {
+		t.Errorf("Expected only the June event, got %d events:\n%s", count, calendar.String())
+	}
+
+	if err := timetableService.ExportTimetable(context.Background(), "owner@example.com", "2024-07-01", "2024-06-01", &calendar); err == nil {
+		t.Errorf("Expected an inverted range to be rejected")
+	}
+}
+
+func TestTimetableService_ExportTimetable_Recurring(t *testing.T) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	mockEventRepo.CreateEvent(context.Background(), &models.Event{
+		Email:          "owner@example.com",
+		Title:          "Weekly lecture",
+		Date:           "2024-01-08",
+		StartTime:      "10:15",
+		EndTime:        "12:00",
+		Recurrence:     &models.Recurrence{Frequency: "weekly", Interval: 1, Until: "2024-02-26"},
+		ExceptionDates: []string{"2024-01-22"},
+	})
+	timetableService := services.NewTimetableService(mockEventRepo)
+
+	// The series starts before the range but has occurrences within it.
+	var calendar strings.Builder
+	if err := timetableService.ExportTimetable(context.Background(), "owner@example.com", "2024-02-01", "2024-02-29", &calendar); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	for _, line := range []string{
+		"RRULE:FREQ=WEEKLY;UNTIL=20240226T091500Z",
+		"EXDATE;TZID=Europe/Oslo:20240122T101500",
+	} {
+		if !strings.Contains(calendar.String(), line) {
+			t.Errorf("Expected %q in the export, got:\n%s", line, calendar.String())
+		}
+	}
+}
+
+func TestTimetableService_ExportTimetable_AllDay(t *testing.T) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	mockEventRepo.CreateEvent(context.Background(), &models.Event{
+		Email:    "owner@example.com",
+		Title:    "Constitution Day",
+		Date:     "2024-05-17",
+		AllDay:   true,
+		TimeZone: "Europe/Oslo",
+	})
+	timetableService := services.NewTimetableService(mockEventRepo)
+
+	var calendar strings.Builder
+	if err := timetableService.ExportTimetable(context.Background(), "owner@example.com", "", "", &calendar); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	for _, line := range []string{"DTSTART;VALUE=DATE:20240517", "DTEND;VALUE=DATE:20240518"} {
+		if !strings.Contains(calendar.String(), line) {
+			t.Errorf("Expected %q in the export, got:\n%s", line, calendar.String())
+		}
+	}
+}
+
+func TestTimetableService_TimeZones(t *testing.T) {
+	mockEventRepo := mocks.NewMockEventRepository()
+	timetableService := services.NewTimetableService(mockEventRepo)
+	ctx := context.Background()
+
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Example//Conference//EN",
+		"BEGIN:VEVENT",
+		"UID:keynote@example.com",
+		"DTSTAMP:20240105T120000Z",
+		"DTSTART;TZID=America/New_York:20240701T090000",
+		"DTEND;TZID=America/New_York:20240701T100000",
+		"SUMMARY:Keynote",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	if _, err := timetableService.ImportTimetable(ctx, "user@example.com", calendar, false); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	// 09:00 in New York summer time (UTC-4) is 15:00 in Oslo summer time (UTC+2).
+	var imported *models.Event
+	for _, event := range mockEventRepo.Events {
+		imported = event
+	}
+	if imported.Date != "2024-07-01" || imported.StartTime != "15:00" || imported.EndTime != "16:00" || imported.TimeZone != "Europe/Oslo" {
+		t.Errorf("Expected 2024-07-01 15:00-16:00 in Europe/Oslo, got %s %s-%s in %q", imported.Date, imported.StartTime, imported.EndTime, imported.TimeZone)
+	}
+	if imported.StartAt.UTC().Format(time.RFC3339) != "2024-07-01T13:00:00Z" || imported.EndAt == nil || imported.EndAt.UTC().Format(time.RFC3339) != "2024-07-01T14:00:00Z" {
+		t.Errorf("Expected the keynote from 13:00 to 14:00 UTC, got %v-%v", imported.StartAt, imported.EndAt)
+	}
+
+	// A user in New York sees the keynote at the time in the invitation.
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"user@example.com": {Email: "user@example.com", TimeZone: "America/New_York"},
+	})
+	eventService := services.NewEventService(mockEventRepo, mocks.NewMockInvitationRepository(), userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), nil, nil, nil)
+	rendered, err := eventService.GetEvent(ctx, "user@example.com", imported.EventID)
+	if err != nil || rendered.StartTime != "09:00" || rendered.EndTime != "10:00" {
+		t.Errorf("Expected 09:00-10:00 in New York, got %+v (err: %v)", rendered, err)
+	}
+
+	// Events stored in another zone are exported in Oslo time.
+	mockEventRepo.CreateEvent(ctx, &models.Event{
+		Email: "owner@example.com", Title: "Stand-up", Date: "2024-01-15", StartTime: "09:00", EndTime: "09:15", TimeZone: "America/New_York",
+	})
+	var export strings.Builder
+	if err := timetableService.ExportTimetable(ctx, "owner@example.com", "", "", &export); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	for _, line := range []string{"DTSTART;TZID=Europe/Oslo:20240115T150000", "DTEND;TZID=Europe/Oslo:20240115T151500"} {
+		if !strings.Contains(export.String(), line) {
+			t.Errorf("Expected %q in the export, got:\n%s", line, export.String())
+		}
+	}
+}
+
+func TestTimetableService_ExportFeed(t *testing.T) {
+	ctx := context.Background()
+	mockEventRepo := mocks.NewMockEventRepository()
+	for _, event := range []models.Event{
+		{Title: "Too old", Date: "2024-03-31"},
+		{Title: "Last month", Date: "2024-04-01"},
+		{Title: "Next year", Date: "2025-05-01"},
+		{Title: "Too far ahead", Date: "2025-05-02"},
+	} {
+		event.Email = "owner@example.com"
+		mockEventRepo.CreateEvent(ctx, &event)
+	}
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"owner@example.com": {Email: "owner@example.com", Country: "Norway", FeedToken: "feed-token"},
+	})
+	timetableService := services.NewTimetableService(mockEventRepo).(*services.TimetableService)
+	timetableService.UserRepo = userRepo
+	// 23:30 UTC is already May 1 in Oslo.
+	timetableService.Now = func() time.Time { return time.Date(2024, 4, 30, 23, 30, 0, 0, time.UTC) }
+
+	var calendar strings.Builder
+	if err := timetableService.ExportFeed(ctx, "feed-token", &calendar); err != nil {
+		t.Fatalf("Failed to export the feed: %v", err)
+	}
+	for title, want := range map[string]bool{"Too old": false, "Last month": true, "Next year": true, "Too far ahead": false} {
+		if got := strings.Contains(calendar.String(), "SUMMARY:"+title); got != want {
+			t.Errorf("Expected %q in the feed to be %v", title, want)
+		}
+	}
+
+	userRepo.Users["owner@example.com"].Disabled = true
+	if err := timetableService.ExportFeed(ctx, "feed-token", &calendar); !errors.Is(err, services.ErrFeedNotFound) {
+		t.Errorf("Expected ErrFeedNotFound for a disabled account, got %v", err)
+	}
+	if err := services.NewTimetableService(mockEventRepo).ExportFeed(ctx, "feed-token", &calendar); !errors.Is(err, services.ErrFeedsDisabled) {
+		t.Errorf("Expected ErrFeedsDisabled without a UserRepo, got %v", err)
+	}
+}
@@ -0,0 +1,1116 @@
+/**
+ *  UserService and ProfileService Tests validate username uniqueness at signup and profile update,
+ *  and the brute-force limits on logins and OTP submissions using an injectable clock.
+ *  They use a mock UserRepository and a mock EmailService so nothing touches Firestore or sends email.
+ *
+ *  @file       user_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestUserService_Signup_UsernameTaken          - Tests that a username differing only in case or surrounding spaces is rejected.
+ *  - TestProfileService_UpdateProfile_UsernameTaken - Tests that renaming to another user's username is rejected.
+ *  - TestProfileService_UpdateProfile_UsernameLower - Tests that UsernameLower follows a username change.
+ *  - TestUserService_Login_Lockout                  - Tests locking after repeated wrong passwords and expiry of the lock.
+ *  - TestUserService_Login_SuccessResetsCount       - Tests that a successful login resets the failure count.
+ *  - TestUserService_VerifyEmail_OTPAttempts        - Tests that an OTP is invalidated after too many wrong attempts.
+ *  - TestUserService_ConcurrentAttempts             - Tests that wrong passwords and OTPs sent concurrently are all counted.
+ *  - TestUserService_OTPStoredHashed                - Tests that only a hash of the emailed OTP is stored.
+ *  - TestUserService_ResetPassword_BumpsTokenVersion - Tests that a password reset revokes existing tokens.
+ *  - TestProfileService_UpdateProfile_TokenVersion  - Tests that only a password change bumps the token version.
+ *  - TestProfileService_TimeZone                    - Tests validating the time zone setting and falling back to the country's zone.
+ *  - TestUserService_GetUserInfo_MatchesProfile     - Tests that /api/me and /api/profile describe a new and an older account alike.
+ *  - TestUserService_SearchUsersByUsername_Names    - Tests merged username, first name and last name matches, kept in sync on signup and update.
+ *  - TestUserService_SearchUsersByUsername_FriendshipStatus - Tests the friendship status attached to each result.
+ *  - TestUserService_SearchUsersByUsername_Pagination - Tests limit and offset, including pages shortened by excluded users.
+ *  - TestUserService_SearchUsersByUsername_ExcludeRelated - Tests that friends, pending requests either way and blocks are hidden.
+ *  - TestUserService_Signup_Branches                - Tests every signup outcome and the stored user after each.
+ *  - TestUserService_Login_Branches                 - Tests every login outcome, including that an unverified account needs the password.
+ *  - TestUserService_VerifyEmail_Branches           - Tests every verification outcome and the stored OTP after each.
+ *  - TestUserService_ResetPassword_Branches         - Tests every password reset outcome and the stored password after each.
+ *  - TestUserService_Signup_FriendInvitations       - Tests that pending invitations become friend requests and are consumed on signup.
+ *  - TestUserService_EmailCasing                    - Tests that signup, login, verification and password resets ignore the case and padding of emails.
+ *  - TestUserService_UnverifiedAccountExpiry       - Tests the 7-day boundary after which an unverified account is refused and its OTP cleared.
+ *  - TestUserService_Signup_ReplacesExpiredAccount  - Tests that signing up again replaces an expired unverified account, but not a live one.
+ *
+ *  @dependencies
+ *  - mocks.NewMockUserRepository: Mock implementation of UserRepository for testing.
+ *  - mocks.NewMockFriendRepository: Mock friendships and blocks for the search tests.
+ *  - mocks.MockEmailService: Mock implementation of EmailService for testing.
+ *  - mocks.NewMockFriendInvitationRepository: Mock invitations converted to friend requests on signup.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/repositories"
+	"proh2052-group6/internal/services"
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/utils"
+	"proh2052-group6/tests/mocks"
+)
+
+// testJWT signs tokens and hashes OTPs for the services under test.
+var testJWT = utils.NewJWTManager("test-secret", nil, 0, "")
+
+// newUsernameTestRepo creates a mock user repository holding alice and bob, both with the password "Password123!".
+func newUsernameTestRepo(t *testing.T) *mocks.MockUserRepository {
+	t.Helper()
+	hashedPassword, err := utils.HashPassword("Password123!")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	// The accounts are unverified, so they are created now to keep them from expiring under either clock.
+	createdAt := time.Now()
+	return mocks.NewMockUserRepository(map[string]*models.User{
+		"alice@example.com": {Email: "alice@example.com", Username: "Alice", UsernameLower: "alice", Password: hashedPassword, CreatedAt: createdAt},
+		"bob@example.com":   {Email: "bob@example.com", Username: "bob", UsernameLower: "bob", Password: hashedPassword, CreatedAt: createdAt},
+	})
+}
+
+func TestUserService_Signup_UsernameTaken(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	mockEmailService := &mocks.MockEmailService{}
+	userService := services.NewUserService(userRepo, mockEmailService, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+
+	for _, username := range []string{"ALICE", "  alice ", "\tAlice\n"} {
+		user := &models.User{Email: "carol@example.com", Username: username, Password: "Password123!", Country: "Norway", City: "Oslo"}
+		if err := userService.Signup(context.Background(), user); !errors.Is(err, services.ErrUsernameTaken) {
+			t.Fatalf("%q: expected ErrUsernameTaken, got %v", username, err)
+		}
+	}
+	user := &models.User{Email: "carol@example.com", Username: "   ", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(context.Background(), user); !errors.Is(err, services.ErrMissingFields) {
+		t.Fatalf("Expected ErrMissingFields for a blank username, got %v", err)
+	}
+	if _, exists := userRepo.Users["carol@example.com"]; exists {
+		t.Errorf("Expected the user not to be created")
+	}
+	if len(mockEmailService.SentEmails) != 0 {
+		t.Errorf("Expected no verification email, got %d", len(mockEmailService.SentEmails))
+	}
+
+	user = &models.User{Email: "carol@example.com", Username: " Carol ", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(context.Background(), user); err != nil {
+		t.Fatalf("Expected signup with a free username to succeed, got %v", err)
+	}
+	if stored := userRepo.Users["carol@example.com"]; stored.Username != "Carol" || stored.UsernameLower != "carol" {
+		t.Errorf("Expected the trimmed username 'Carol'/'carol', got %q/%q", stored.Username, stored.UsernameLower)
+	}
+}
+
+func TestProfileService_UpdateProfile_UsernameTaken(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+
+	err := profileService.UpdateProfile(context.Background(), "bob@example.com", map[string]interface{}{
+		"Username":        "aLiCe",
+		"CurrentPassword": "Password123!",
+	})
+	if !errors.Is(err, services.ErrUsernameTaken) {
+		t.Fatalf("Expected ErrUsernameTaken, got %v", err)
+	}
+	if bob := userRepo.Users["bob@example.com"]; bob.Username != "bob" || bob.UsernameLower != "bob" {
+		t.Errorf("Expected bob's username to be unchanged, got %q/%q", bob.Username, bob.UsernameLower)
+	}
+}
+
+func TestProfileService_UpdateProfile_UsernameLower(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+	ctx := context.Background()
+
+	// Changing only the case of one's own username is allowed.
+	err := profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{
+		"Username":        "ALICE",
+		"CurrentPassword": "Password123!",
+	})
+	if err != nil {
+		t.Fatalf("Expected case-only rename to succeed, got %v", err)
+	}
+
+	err = profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{
+		"Username":        "AliceInOslo",
+		"UsernameLower":   "bob",
+		"CurrentPassword": "Password123!",
+	})
+	if err != nil {
+		t.Fatalf("Expected rename to succeed, got %v", err)
+	}
+
+	alice := userRepo.Users["alice@example.com"]
+	if alice.Username != "AliceInOslo" || alice.UsernameLower != "aliceinoslo" {
+		t.Errorf("Expected AliceInOslo/aliceinoslo, got %q/%q", alice.Username, alice.UsernameLower)
+	}
+	if user, err := userRepo.GetUserByUsername(ctx, "ALICEINOSLO"); err != nil || user.Email != "alice@example.com" {
+		t.Errorf("Expected lookup by the new username to find alice, got %v (err: %v)", user, err)
+	}
+	if _, err := userRepo.GetUserByUsername(ctx, "alice"); err == nil {
+		t.Errorf("Expected the old username to be free")
+	}
+}
+
+// newLimitedUserService creates a UserService over the given repository with a fake clock that
+// the returned pointer controls.
+func newLimitedUserService(userRepo *mocks.MockUserRepository) (*services.UserService, *time.Time) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT).(*services.UserService)
+	userService.Now = func() time.Time { return now }
+	return userService, &now
+}
+
+func TestUserService_Login_Lockout(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	userRepo.Users["alice@example.com"].IsVerified = true
+	userService, now := newLimitedUserService(userRepo)
+	userService.MaxLoginAttempts = 3
+	ctx := context.Background()
+
+	wrong := &models.LoginRequest{Email: "alice@example.com", Password: "Wrong123!"}
+	right := &models.LoginRequest{Email: "alice@example.com", Password: "Password123!"}
+
+	for i := 1; i < 3; i++ {
+		if _, err := userService.Login(ctx, wrong); !errors.Is(err, services.ErrInvalidCredentials) {
+			t.Fatalf("Attempt %d: expected ErrInvalidCredentials, got %v", i, err)
+		}
+	}
+	if _, err := userService.Login(ctx, wrong); !errors.Is(err, services.ErrAccountLocked) {
+		t.Fatalf("Expected the third wrong password to lock the account, got %v", err)
+	}
+	if lockedUntil := userRepo.Users["alice@example.com"].LockedUntil; !lockedUntil.Equal(now.Add(services.DefaultLockoutDuration)) {
+		t.Errorf("Expected LockedUntil %v, got %v", now.Add(services.DefaultLockoutDuration), lockedUntil)
+	}
+
+	// While locked, even the right password is refused.
+	*now = now.Add(14 * time.Minute)
+	if _, err := userService.Login(ctx, right); !errors.Is(err, services.ErrAccountLocked) {
+		t.Fatalf("Expected ErrAccountLocked during the lockout, got %v", err)
+	}
+
+	*now = now.Add(time.Minute)
+	if token, err := userService.Login(ctx, right); err != nil || token == "" {
+		t.Fatalf("Expected login after the lockout expired, got %v", err)
+	}
+}
+
+func TestUserService_Login_SuccessResetsCount(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	userRepo.Users["alice@example.com"].IsVerified = true
+	userService, _ := newLimitedUserService(userRepo)
+	ctx := context.Background()
+
+	for i := 0; i < services.DefaultMaxLoginAttempts-1; i++ {
+		userService.Login(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "Wrong123!"})
+	}
+	if _, err := userService.Login(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "Password123!"}); err != nil {
+		t.Fatalf("Expected login to succeed, got %v", err)
+	}
+	if count := userRepo.Users["alice@example.com"].FailedLoginCount; count != 0 {
+		t.Errorf("Expected FailedLoginCount to be reset, got %d", count)
+	}
+
+	// A single wrong password after a successful login must not lock the account.
+	if _, err := userService.Login(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "Wrong123!"}); !errors.Is(err, services.ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+	}
+}
+
+func TestUserService_VerifyEmail_OTPAttempts(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	userService, now := newLimitedUserService(userRepo)
+	ctx := context.Background()
+
+	alice := userRepo.Users["alice@example.com"]
+	alice.OTP = testJWT.HashOTP("123456")
+	alice.OTPExpiresAt = now.Add(5 * time.Minute)
+
+	for i := 1; i < services.DefaultMaxOTPAttempts; i++ {
+		if _, err := userService.VerifyEmail(ctx, "alice@example.com", "000000"); err == nil || err.Error() != "Invalid OTP" {
+			t.Fatalf("Attempt %d: expected 'Invalid OTP', got %v", i, err)
+		}
+	}
+	if _, err := userService.VerifyEmail(ctx, "alice@example.com", "000000"); !errors.Is(err, services.ErrTooManyOTPAttempts) {
+		t.Fatalf("Expected ErrTooManyOTPAttempts, got %v", err)
+	}
+	if alice.OTP != "" {
+		t.Errorf("Expected the OTP to be invalidated, got %q", alice.OTP)
+	}
+
+	// The right OTP no longer works until a new one is requested.
+	if _, err := userService.VerifyEmail(ctx, "alice@example.com", "123456"); !errors.Is(err, services.ErrTooManyOTPAttempts) {
+		t.Fatalf("Expected ErrTooManyOTPAttempts for the old OTP, got %v", err)
+	}
+
+	if err := userService.ResendOTP(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("Failed to resend OTP: %v", err)
+	}
+	if alice.OTPAttempts != 0 {
+		t.Errorf("Expected OTPAttempts to be reset, got %d", alice.OTPAttempts)
+	}
+	otp := userService.Email.(*mocks.MockEmailService).LastOTP()
+	if _, err := userService.VerifyEmail(ctx, "alice@example.com", otp); err != nil {
+		t.Fatalf("Expected the new OTP to verify the email, got %v", err)
+	}
+	if !alice.IsVerified {
+		t.Errorf("Expected alice to be verified")
+	}
+}
+
+func TestUserService_ConcurrentAttempts(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	alice := userRepo.Users["alice@example.com"]
+	alice.IsVerified = true
+	bob := userRepo.Users["bob@example.com"]
+	userService, now := newLimitedUserService(userRepo)
+	userService.MaxLoginAttempts = 3
+	bob.OTP = testJWT.HashOTP("123456")
+	bob.OTPExpiresAt = now.Add(5 * time.Minute)
+	ctx := context.Background()
+
+	// Every guess reads the user before any has been counted, so only the stored counters can stop them.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			userService.Login(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "Wrong123!"})
+		}()
+		go func(i int) {
+			defer wg.Done()
+			userService.VerifyEmail(ctx, "bob@example.com", fmt.Sprintf("%06d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	if !alice.LockedUntil.After(*now) {
+		t.Errorf("Expected concurrent wrong passwords to lock the account")
+	}
+	if bob.OTP != "" || bob.OTPAttempts < services.DefaultMaxOTPAttempts {
+		t.Errorf("Expected concurrent wrong OTPs to invalidate the OTP, got %d attempts and OTP %q", bob.OTPAttempts, bob.OTP)
+	}
+	if _, err := userService.VerifyEmail(ctx, "bob@example.com", "123456"); !errors.Is(err, services.ErrTooManyOTPAttempts) {
+		t.Errorf("Expected ErrTooManyOTPAttempts for the right OTP, got %v", err)
+	}
+}
+
+func TestUserService_OTPStoredHashed(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	userService, _ := newLimitedUserService(userRepo)
+	mockEmailService := userService.Email.(*mocks.MockEmailService)
+	ctx := context.Background()
+
+	user := &models.User{Email: "carol@example.com", Username: "Carol", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(ctx, user); err != nil {
+		t.Fatalf("Failed to sign up: %v", err)
+	}
+	otp := mockEmailService.LastOTP()
+	carol := userRepo.Users["carol@example.com"]
+	if otp == "" || carol.OTP == otp || carol.OTP != testJWT.HashOTP(otp) {
+		t.Fatalf("Expected the stored OTP to be the hash of the emailed OTP %q, got %q", otp, carol.OTP)
+	}
+	if _, err := userService.VerifyEmail(ctx, "carol@example.com", carol.OTP); err == nil {
+		t.Fatalf("Expected the stored hash not to be accepted as an OTP")
+	}
+	if _, err := userService.VerifyEmail(ctx, "carol@example.com", otp); err != nil {
+		t.Fatalf("Expected the emailed OTP to verify the email, got %v", err)
+	}
+
+	if err := userService.ForgotPassword(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("Failed to request a password reset: %v", err)
+	}
+	otp = mockEmailService.LastOTP()
+	if userRepo.Users["alice@example.com"].OTP != testJWT.HashOTP(otp) {
+		t.Fatalf("Expected the password reset OTP to be stored hashed")
+	}
+	if err := userService.ResetPassword(ctx, "alice@example.com", otp, "NewPassword123!"); err != nil {
+		t.Fatalf("Expected the emailed OTP to reset the password, got %v", err)
+	}
+}
+
+func TestUserService_ResetPassword_BumpsTokenVersion(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	userService, now := newLimitedUserService(userRepo)
+	ctx := context.Background()
+
+	alice := userRepo.Users["alice@example.com"]
+	alice.TokenVersion = 2
+	alice.OTP = testJWT.HashOTP("123456")
+	alice.OTPExpiresAt = now.Add(5 * time.Minute)
+
+	if err := userService.ResetPassword(ctx, "alice@example.com", "123456", "NewPassword123!"); err != nil {
+		t.Fatalf("Failed to reset password: %v", err)
+	}
+	if alice.TokenVersion != 3 {
+		t.Errorf("Expected TokenVersion 3 after the reset, got %d", alice.TokenVersion)
+	}
+}
+
+func TestProfileService_UpdateProfile_TokenVersion(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+	ctx := context.Background()
+	alice := userRepo.Users["alice@example.com"]
+
+	// Other profile changes, and a client-supplied version, leave the version alone.
+	err := profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{
+		"City":            "Oslo",
+		"TokenVersion":    5,
+		"CurrentPassword": "Password123!",
+	})
+	if err != nil {
+		t.Fatalf("Failed to update profile: %v", err)
+	}
+	if alice.TokenVersion != 0 {
+		t.Errorf("Expected TokenVersion to stay 0, got %d", alice.TokenVersion)
+	}
+
+	err = profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{
+		"NewPassword":     "NewPassword123!",
+		"CurrentPassword": "Password123!",
+	})
+	if err != nil {
+		t.Fatalf("Failed to change password: %v", err)
+	}
+	if alice.TokenVersion != 1 {
+		t.Errorf("Expected TokenVersion 1 after the password change, got %d", alice.TokenVersion)
+	}
+}
+
+// searchUsernames returns the usernames in search results, in order.
+func searchUsernames(results []map[string]string) string {
+	usernames := make([]string, len(results))
+	for i, result := range results {
+		usernames[i] = result["username"]
+	}
+	return strings.Join(usernames, ",")
+}
+
+func TestProfileService_TimeZone(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	userRepo.Users["alice@example.com"].Country = "Norway"
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+	ctx := context.Background()
+
+	profile, err := profileService.GetProfile(ctx, "alice@example.com")
+	if err != nil || profile.TimeZone != "Europe/Oslo" {
+		t.Fatalf("Expected the time zone of Norway by default, got %+v (err: %v)", profile, err)
+	}
+
+	for _, invalid := range []interface{}{"Mars/Olympus_Mons", "Local", 2} {
+		err := profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{"CurrentPassword": "Password123!", "TimeZone": invalid})
+		if !errors.Is(err, services.ErrInvalidTimeZone) {
+			t.Errorf("Expected ErrInvalidTimeZone for %v, got %v", invalid, err)
+		}
+	}
+
+	err = profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{"CurrentPassword": "Password123!", "TimeZone": " America/New_York "})
+	if err != nil {
+		t.Fatalf("Failed to set the time zone: %v", err)
+	}
+	if profile, _ := profileService.GetProfile(ctx, "alice@example.com"); profile.TimeZone != "America/New_York" {
+		t.Errorf("Expected America/New_York, got %v", profile.TimeZone)
+	}
+
+	// Clearing the setting falls back to the country again; users without either have no time zone.
+	err = profileService.UpdateProfile(ctx, "alice@example.com", map[string]interface{}{"CurrentPassword": "Password123!", "TimeZone": ""})
+	if err != nil {
+		t.Fatalf("Failed to clear the time zone: %v", err)
+	}
+	if profile, _ := profileService.GetProfile(ctx, "alice@example.com"); profile.TimeZone != "Europe/Oslo" {
+		t.Errorf("Expected Europe/Oslo after clearing the setting, got %v", profile.TimeZone)
+	}
+	if profile, _ := profileService.GetProfile(ctx, "bob@example.com"); profile.TimeZone != "" {
+		t.Errorf("Expected no time zone without a setting or country, got %v", profile.TimeZone)
+	}
+}
+
+func TestUserService_GetUserInfo_MatchesProfile(t *testing.T) {
+	userRepo := newUsernameTestRepo(t)
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, mocks.NewMockFriendRepository(map[string]*models.Friend{}), testJWT)
+	createdAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	userService.(*services.UserService).Now = func() time.Time { return createdAt }
+	profileService := services.NewProfileService(userRepo, mocks.NewMockFriendRepository(map[string]*models.Friend{}), mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+	ctx := context.Background()
+
+	user := &models.User{Email: "carol@example.com", Username: "carol", FirstName: "Carol", LastName: "Smith", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(ctx, user); err != nil {
+		t.Fatalf("Failed to sign up: %v", err)
+	}
+	info, err := userService.GetUserInfo(ctx, "carol@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get user info: %v", err)
+	}
+	if info.FirstName != "Carol" || info.LastName != "Smith" || info.IsVerified || info.TimeZone != "Europe/Oslo" ||
+		info.CreatedAt == nil || !info.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected the new account's names, verification, time zone and creation time, got %+v", info)
+	}
+	if profile, err := profileService.GetProfile(ctx, "carol@example.com"); err != nil || !reflect.DeepEqual(profile, info) {
+		t.Errorf("Expected GetProfile to match GetUserInfo, got %+v (err: %v)", profile, err)
+	}
+
+	// Accounts created before CreatedAt was recorded leave it out.
+	userRepo.Users["alice@example.com"].CreatedAt = time.Time{}
+	info, err = userService.GetUserInfo(ctx, "alice@example.com")
+	if err != nil || info.CreatedAt != nil {
+		t.Errorf("Expected no creation time for an older account, got %+v (err: %v)", info, err)
+	}
+}
+
+func TestUserService_SearchUsersByUsername_Names(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"me@example.com": {Email: "me@example.com", Username: "me"},
+	})
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{})
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, friendRepo, testJWT)
+	profileService := services.NewProfileService(userRepo, friendRepo, mocks.NewMockInvitationRepository(), &mocks.MockEmailService{}, nil, testJWT)
+	ctx := context.Background()
+
+	for _, user := range []*models.User{
+		{Email: "john@example.com", Username: "JohnDoe", FirstName: "John", LastName: "Doe"},
+		{Email: "jane@example.com", Username: "jsmith", FirstName: "Jane", LastName: "Doe"},
+		{Email: "doe@example.com", Username: "doelover", FirstName: "Dora", LastName: "Doerr"},
+		{Email: "sam@example.com", Username: "sam", FirstName: "Sam", LastName: "Smith"},
+	} {
+		user.Country, user.City, user.Password = "Norway", "Oslo", "Password123!"
+		if err := userService.Signup(ctx, user); err != nil {
+			t.Fatalf("Failed to sign up %s: %v", user.Username, err)
+		}
+	}
+
+	// "doe" finds the username match first, then last name matches, each user once. JohnDoe is found by
+	// last name only, since username matches are prefix matches.
+	results, err := userService.SearchUsersByUsername(ctx, "me@example.com", "DOE", false, false, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to search users: %v", err)
+	}
+	if got := searchUsernames(results); got != "doelover,jsmith,JohnDoe" {
+		t.Errorf("Expected doelover,jsmith,JohnDoe, got %s", got)
+	}
+	if results[2]["firstName"] != "John" || results[2]["lastName"] != "Doe" {
+		t.Errorf("Expected the names of JohnDoe in the result, got %+v", results[2])
+	}
+
+	results, _ = userService.SearchUsersByUsername(ctx, "me@example.com", "ja", false, false, 0, 0)
+	if got := searchUsernames(results); got != "jsmith" {
+		t.Errorf("Expected a first name match on jsmith, got %s", got)
+	}
+
+	// Renaming keeps the lowercase names in sync, and they cannot be set directly.
+	err = profileService.UpdateProfile(ctx, "sam@example.com", map[string]interface{}{
+		"FirstName":       " Samantha ",
+		"LastNameLower":   "doe",
+		"CurrentPassword": "Password123!",
+	})
+	if err != nil {
+		t.Fatalf("Failed to update profile: %v", err)
+	}
+	if sam := userRepo.Users["sam@example.com"]; sam.FirstName != "Samantha" || sam.FirstNameLower != "samantha" || sam.LastNameLower != "smith" {
+		t.Errorf("Expected Samantha/samantha/smith, got %q/%q/%q", sam.FirstName, sam.FirstNameLower, sam.LastNameLower)
+	}
+	results, _ = userService.SearchUsersByUsername(ctx, "me@example.com", "samanth", false, false, 0, 0)
+	if got := searchUsernames(results); got != "sam" {
+		t.Errorf("Expected the renamed user to be found by first name, got %s", got)
+	}
+	results, _ = userService.SearchUsersByUsername(ctx, "me@example.com", "doe", false, false, 0, 0)
+	if got := searchUsernames(results); got != "doelover,jsmith,JohnDoe" {
+		t.Errorf("Expected LastNameLower to be ignored as an update, got %s", got)
+	}
+}
+
+func TestUserService_SearchUsersByUsername_FriendshipStatus(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"me@example.com":       {Email: "me@example.com", Username: "me"},
+		"friend@example.com":   {Email: "friend@example.com", Username: "ann_friend"},
+		"outgoing@example.com": {Email: "outgoing@example.com", Username: "ann_outgoing"},
+		"incoming@example.com": {Email: "incoming@example.com", Username: "ann_incoming"},
+		"stranger@example.com": {Email: "stranger@example.com", Username: "ann_stranger"},
+	})
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		"friend@example.com_me@example.com":   {Email: "friend@example.com", FriendEmail: "me@example.com", Status: "accepted"},
+		"me@example.com_outgoing@example.com": {Email: "me@example.com", FriendEmail: "outgoing@example.com", Status: "pending"},
+		"incoming@example.com_me@example.com": {Email: "incoming@example.com", FriendEmail: "me@example.com", Status: "pending"},
+	})
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, friendRepo, testJWT)
+
+	results, err := userService.SearchUsersByUsername(context.Background(), "me@example.com", "ann", false, false, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to search users: %v", err)
+	}
+	expected := map[string]string{
+		"ann_friend":   services.FriendshipFriends,
+		"ann_outgoing": services.FriendshipPendingOutgoing,
+		"ann_incoming": services.FriendshipPendingIncoming,
+		"ann_stranger": services.FriendshipNone,
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+	for _, result := range results {
+		if result["friendshipStatus"] != expected[result["username"]] {
+			t.Errorf("Expected status %q for %s, got %q", expected[result["username"]], result["username"], result["friendshipStatus"])
+		}
+	}
+}
+
+func TestUserService_SearchUsersByUsername_ExcludeRelated(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(map[string]*models.User{
+		"me@example.com":       {Email: "me@example.com", Username: "ann_me"},
+		"friend@example.com":   {Email: "friend@example.com", Username: "ann_friend"},
+		"mine@example.com":     {Email: "mine@example.com", Username: "ann_mine"},
+		"outgoing@example.com": {Email: "outgoing@example.com", Username: "ann_outgoing"},
+		"incoming@example.com": {Email: "incoming@example.com", Username: "ann_incoming"},
+		"declined@example.com": {Email: "declined@example.com", Username: "ann_declined"},
+		"blocked@example.com":  {Email: "blocked@example.com", Username: "ann_blocked"},
+		"blocker@example.com":  {Email: "blocker@example.com", Username: "ann_blocker"},
+		"stranger@example.com": {Email: "stranger@example.com", Username: "ann_stranger"},
+	})
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{
+		// Friendships are stored once, under whoever sent the request.
+		"friend@example.com_me@example.com":   {Email: "friend@example.com", FriendEmail: "me@example.com", Status: "accepted"},
+		"me@example.com_mine@example.com":     {Email: "me@example.com", FriendEmail: "mine@example.com", Status: "accepted"},
+		"me@example.com_outgoing@example.com": {Email: "me@example.com", FriendEmail: "outgoing@example.com", Status: "pending"},
+		"incoming@example.com_me@example.com": {Email: "incoming@example.com", FriendEmail: "me@example.com", Status: "pending"},
+		"declined@example.com_me@example.com": {Email: "declined@example.com", FriendEmail: "me@example.com", Status: "declined"},
+	})
+	ctx := context.Background()
+	friendRepo.CreateBlock(ctx, &models.Block{BlockerEmail: "me@example.com", BlockedEmail: "blocked@example.com"})
+	friendRepo.CreateBlock(ctx, &models.Block{BlockerEmail: "blocker@example.com", BlockedEmail: "me@example.com"})
+	userService := services.NewUserService(userRepo, &mocks.MockEmailService{}, friendRepo, testJWT)
+
+	// Without the filter, everyone but the user is found.
+	results, err := userService.SearchUsersByUsername(ctx, "me@example.com", "ann", false, false, 0, 0)
+	if err != nil || len(results) != 8 {
+		t.Fatalf("Expected 8 results without excludeRelated, got %s (err: %v)", searchUsernames(results), err)
+	}
+
+	// With it, only users without any relationship are left: a declined request is no longer pending.
+	results, err = userService.SearchUsersByUsername(ctx, "me@example.com", "ann", false, true, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to search users: %v", err)
+	}
+	if got := searchUsernames(results); got != "ann_declined,ann_stranger" {
+		t.Errorf("Expected ann_declined,ann_stranger, got %s", got)
+	}
+	for _, result := range results {
+		if result["friendshipStatus"] != services.FriendshipNone {
+			t.Errorf("Expected no friendship with %s, got %q", result["username"], result["friendshipStatus"])
+		}
+	}
+
+	// Excluded users do not count towards the page.
+	results, _ = userService.SearchUsersByUsername(ctx, "me@example.com", "ann", false, true, 1, 1)
+	if got := searchUsernames(results); got != "ann_stranger" {
+		t.Errorf("Expected the second page to be ann_stranger, got %s", got)
+	}
+
+	friendRepo.Err = repositories.ErrUnavailable
+	if _, err := userService.SearchUsersByUsername(ctx, "me@example.com", "ann", false, true, 0, 0); !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Expected the repository failure to be returned, got %v", err)
+	}
+}
+
+func TestUserService_SearchUsersByUsername_Pagination(t *testing.T) {
+	users := map[string]*models.User{
+		"me@example.com": {Email: "me@example.com", Username: "user00"},
+	}
+	for i := 1; i <= 30; i++ {
+		email := fmt.Sprintf("user%02d@example.com", i)
+		users[email] = &models.User{Email: email, Username: fmt.Sprintf("user%02d", i)}
+	}
+	friendRepo := mocks.NewMockFriendRepository(map[string]*models.Friend{})
+	// Blocks in both directions hide user02 to user05, ahead of the first page boundary.
+	for i := 2; i <= 5; i++ {
+		other := fmt.Sprintf("user%02d@example.com", i)
+		if i%2 == 0 {
+			friendRepo.CreateBlock(context.Background(), &models.Block{BlockerEmail: "me@example.com", BlockedEmail: other})
+		} else {
+			friendRepo.CreateBlock(context.Background(), &models.Block{BlockerEmail: other, BlockedEmail: "me@example.com"})
+		}
+	}
+	userService := services.NewUserService(mocks.NewMockUserRepository(users), &mocks.MockEmailService{}, friendRepo, testJWT)
+	ctx := context.Background()
+
+	results, _ := userService.SearchUsersByUsername(ctx, "me@example.com", "user", false, false, 0, 0)
+	if len(results) != services.DefaultUserSearchLimit || results[0]["username"] != "user01" {
+		t.Errorf("Expected the default page of %d starting at user01, got %s", services.DefaultUserSearchLimit, searchUsernames(results))
+	}
+
+	results, _ = userService.SearchUsersByUsername(ctx, "me@example.com", "user", false, false, 5, 10)
+	if got := searchUsernames(results); got != "user11,user12,user13,user14,user15" {
+		t.Errorf("Expected user11 to user15, got %s", got)
+	}
+
+	results, _ = userService.SearchUsersByUsername(ctx, "me@example.com", "user", true, false, 5, 0)
+	if got := searchUsernames(results); got != "user01,user06,user07,user08,user09" {
+		t.Errorf("Expected a full first page without blocked users, got %s", got)
+	}
+
+	results, _ = userService.SearchUsersByUsername(ctx, "me@example.com", "user", true, false, 10, 20)
+	if got := searchUsernames(results); got != "user25,user26,user27,user28,user29,user30" {
+		t.Errorf("Expected the last partial page, got %s", got)
+	}
+
+	results, err := userService.SearchUsersByUsername(ctx, "me@example.com", "user", false, false, 10, 100)
+	if err != nil || len(results) != 0 {
+		t.Errorf("Expected an empty page past the end, got %v (err: %v)", results, err)
+	}
+}
+
+// checkUserErr fails the test unless err is want, matched with errors.Is or by message, or both are nil.
+func checkUserErr(t *testing.T, err, want error) {
+	t.Helper()
+	switch {
+	case want == nil && err != nil:
+		t.Fatalf("Expected success, got %v", err)
+	case want != nil && (err == nil || !errors.Is(err, want) && err.Error() != want.Error()):
+		t.Fatalf("Expected %q, got %v", want, err)
+	}
+}
+
+// withOTP gives alice the OTP "123456", valid until five minutes after the fake clock's start.
+func withOTP(alice *models.User) {
+	alice.OTP = testJWT.HashOTP("123456")
+	alice.OTPExpiresAt = time.Date(2024, 3, 1, 12, 5, 0, 0, time.UTC)
+}
+
+func TestUserService_Signup_Branches(t *testing.T) {
+	carol := func(change func(user *models.User)) *models.User {
+		user := &models.User{Email: "carol@example.com", Username: "Carol", Password: "Password123!", Country: "Norway", City: "Oslo"}
+		if change != nil {
+			change(user)
+		}
+		return user
+	}
+	tests := []struct {
+		name    string
+		user    *models.User
+		repoErr error
+		wantErr error
+	}{
+		{name: "success", user: carol(nil)},
+		{name: "missing country", user: carol(func(u *models.User) { u.Country = "" }), wantErr: services.ErrMissingFields},
+		{name: "missing city", user: carol(func(u *models.User) { u.City = "" }), wantErr: services.ErrMissingFields},
+		{name: "missing email", user: carol(func(u *models.User) { u.Email = "" }), wantErr: services.ErrMissingFields},
+		{name: "missing username", user: carol(func(u *models.User) { u.Username = "" }), wantErr: services.ErrMissingFields},
+		{name: "missing password", user: carol(func(u *models.User) { u.Password = "" }), wantErr: services.ErrMissingFields},
+		{name: "email taken", user: carol(func(u *models.User) { u.Email = "bob@example.com" }), wantErr: services.ErrEmailTaken},
+		{name: "username taken", user: carol(func(u *models.User) { u.Username = "BOB" }), wantErr: services.ErrUsernameTaken},
+		{name: "weak password", user: carol(func(u *models.User) { u.Password = "password" }), wantErr: services.ErrWeakPassword},
+		{name: "database unavailable", user: carol(nil), repoErr: repositories.ErrUnavailable, wantErr: repositories.ErrUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userRepo := newUsernameTestRepo(t)
+			userService, now := newLimitedUserService(userRepo)
+			mockEmailService := userService.Email.(*mocks.MockEmailService)
+			userRepo.Err = test.repoErr
+
+			err := userService.Signup(context.Background(), test.user)
+			checkUserErr(t, err, test.wantErr)
+
+			stored, created := userRepo.Users["carol@example.com"]
+			if test.wantErr != nil {
+				if created || len(userRepo.Users) != 2 {
+					t.Errorf("Expected no user to be created, got %d users", len(userRepo.Users))
+				}
+				if len(mockEmailService.SentEmails) != 0 {
+					t.Errorf("Expected no verification email, got %d", len(mockEmailService.SentEmails))
+				}
+				return
+			}
+			if !created {
+				t.Fatalf("Expected carol to be stored")
+			}
+			if !utils.CheckPasswordHash("Password123!", stored.Password) {
+				t.Errorf("Expected a hash of the password to be stored, got %q", stored.Password)
+			}
+			if stored.IsVerified || stored.UsernameLower != "carol" {
+				t.Errorf("Expected an unverified user with UsernameLower 'carol', got %v/%q", stored.IsVerified, stored.UsernameLower)
+			}
+			if otp := mockEmailService.LastOTP(); otp == "" || stored.OTP != testJWT.HashOTP(otp) {
+				t.Errorf("Expected the hash of the emailed OTP %q to be stored, got %q", otp, stored.OTP)
+			}
+			if !stored.OTPExpiresAt.Equal(now.Add(services.OTPValidity)) {
+				t.Errorf("Expected the OTP to expire at %v, got %v", now.Add(services.OTPValidity), stored.OTPExpiresAt)
+			}
+		})
+	}
+}
+
+func TestUserService_Login_Branches(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(alice *models.User)
+		email        string
+		password     string
+		repoErr      error
+		wantErr      error
+		wantFailures int
+	}{
+		{name: "success", setup: func(a *models.User) { a.IsVerified = true; a.FailedLoginCount = 2 }, password: "Password123!"},
+		{name: "unknown email", email: "nobody@example.com", password: "Password123!", wantErr: services.ErrInvalidCredentials},
+		{name: "wrong password", setup: func(a *models.User) { a.IsVerified = true }, password: "Wrong123!", wantErr: services.ErrInvalidCredentials, wantFailures: 1},
+		{name: "unverified", password: "Password123!", wantErr: services.ErrNotVerified},
+		{name: "unverified with a wrong password", password: "Wrong123!", wantErr: services.ErrInvalidCredentials, wantFailures: 1},
+		{
+			name: "locked",
+			setup: func(a *models.User) {
+				a.IsVerified = true
+				a.LockedUntil = time.Date(2024, 3, 1, 12, 1, 0, 0, time.UTC)
+			},
+			password: "Password123!", wantErr: services.ErrAccountLocked,
+		},
+		{
+			name: "lock expired",
+			setup: func(a *models.User) {
+				a.IsVerified = true
+				a.LockedUntil = time.Date(2024, 3, 1, 11, 59, 0, 0, time.UTC)
+			},
+			password: "Password123!",
+		},
+		{name: "database unavailable", password: "Password123!", repoErr: repositories.ErrUnavailable, wantErr: repositories.ErrUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userRepo := newUsernameTestRepo(t)
+			alice := userRepo.Users["alice@example.com"]
+			if test.setup != nil {
+				test.setup(alice)
+			}
+			userService, _ := newLimitedUserService(userRepo)
+			userRepo.Err = test.repoErr
+			email := test.email
+			if email == "" {
+				email = "alice@example.com"
+			}
+
+			token, err := userService.Login(context.Background(), &models.LoginRequest{Email: email, Password: test.password})
+			checkUserErr(t, err, test.wantErr)
+			if (token != "") != (test.wantErr == nil) {
+				t.Errorf("Expected a token only on success, got %q", token)
+			}
+			if alice.FailedLoginCount != test.wantFailures {
+				t.Errorf("Expected %d failed logins to be stored, got %d", test.wantFailures, alice.FailedLoginCount)
+			}
+		})
+	}
+}
+
+func TestUserService_VerifyEmail_Branches(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(alice *models.User)
+		email        string
+		otp          string
+		advance      time.Duration
+		repoErr      error
+		wantErr      error
+		wantVerified bool
+		wantOTP      bool
+	}{
+		{name: "success", setup: withOTP, otp: "123456", wantVerified: true},
+		{name: "unknown email", email: "nobody@example.com", otp: "123456", wantErr: errors.New("Invalid email or OTP")},
+		{name: "already verified", setup: func(a *models.User) { withOTP(a); a.IsVerified = true }, otp: "123456", wantErr: services.ErrAlreadyVerified, wantVerified: true, wantOTP: true},
+		{name: "wrong OTP", setup: withOTP, otp: "654321", wantErr: errors.New("Invalid OTP"), wantOTP: true},
+		{name: "expired OTP", setup: withOTP, otp: "123456", advance: services.OTPValidity + time.Second, wantErr: errors.New("OTP has expired"), wantOTP: true},
+		{name: "no OTP requested", otp: "123456", wantErr: errors.New("Invalid OTP")},
+		{name: "database unavailable", setup: withOTP, otp: "123456", repoErr: repositories.ErrUnavailable, wantErr: repositories.ErrUnavailable, wantOTP: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userRepo := newUsernameTestRepo(t)
+			alice := userRepo.Users["alice@example.com"]
+			if test.setup != nil {
+				test.setup(alice)
+			}
+			userService, now := newLimitedUserService(userRepo)
+			*now = now.Add(test.advance)
+			userRepo.Err = test.repoErr
+			email := test.email
+			if email == "" {
+				email = "alice@example.com"
+			}
+
+			token, err := userService.VerifyEmail(context.Background(), email, test.otp)
+			checkUserErr(t, err, test.wantErr)
+			if (token != "") != (test.wantErr == nil) {
+				t.Errorf("Expected a token only on success, got %q", token)
+			}
+			if alice.IsVerified != test.wantVerified {
+				t.Errorf("Expected IsVerified %v, got %v", test.wantVerified, alice.IsVerified)
+			}
+			if (alice.OTP != "") != test.wantOTP {
+				t.Errorf("Expected an OTP to be stored: %v, got %q", test.wantOTP, alice.OTP)
+			}
+		})
+	}
+}
+
+func TestUserService_ResetPassword_Branches(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(alice *models.User)
+		email        string
+		otp          string
+		newPassword  string
+		advance      time.Duration
+		repoErr      error
+		wantErr      error
+		wantPassword string
+	}{
+		{name: "success", setup: withOTP, otp: "123456", newPassword: "NewPassword1!", wantPassword: "NewPassword1!"},
+		{name: "unknown email", email: "nobody@example.com", otp: "123456", newPassword: "NewPassword1!", wantErr: errors.New("Invalid email or OTP"), wantPassword: "Password123!"},
+		{name: "wrong OTP", setup: withOTP, otp: "654321", newPassword: "NewPassword1!", wantErr: errors.New("Invalid OTP"), wantPassword: "Password123!"},
+		{
+			name: "expired OTP", setup: withOTP, otp: "123456", newPassword: "NewPassword1!", advance: services.OTPValidity + time.Second,
+			wantErr: errors.New("OTP has expired"), wantPassword: "Password123!",
+		},
+		{name: "weak password", setup: withOTP, otp: "123456", newPassword: "password", wantErr: services.ErrWeakPassword, wantPassword: "Password123!"},
+		{
+			name: "database unavailable", setup: withOTP, otp: "123456", newPassword: "NewPassword1!", repoErr: repositories.ErrUnavailable,
+			wantErr: repositories.ErrUnavailable, wantPassword: "Password123!",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userRepo := newUsernameTestRepo(t)
+			alice := userRepo.Users["alice@example.com"]
+			if test.setup != nil {
+				test.setup(alice)
+			}
+			userService, now := newLimitedUserService(userRepo)
+			*now = now.Add(test.advance)
+			userRepo.Err = test.repoErr
+			email := test.email
+			if email == "" {
+				email = "alice@example.com"
+			}
+
+			err := userService.ResetPassword(context.Background(), email, test.otp, test.newPassword)
+			checkUserErr(t, err, test.wantErr)
+			if !utils.CheckPasswordHash(test.wantPassword, alice.Password) {
+				t.Errorf("Expected the stored password to be %q", test.wantPassword)
+			}
+			if test.wantErr == nil && (alice.OTP != "" || alice.TokenVersion != 1) {
+				t.Errorf("Expected the OTP to be cleared and the token version bumped, got %q/%d", alice.OTP, alice.TokenVersion)
+			}
+		})
+	}
+}
+
+func TestUserService_EmailCasing(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newUsernameTestRepo(t)
+	userRepo.Users["alice@example.com"].IsVerified = true
+	userService, _ := newLimitedUserService(userRepo)
+	mockEmailService := userService.Email.(*mocks.MockEmailService)
+
+	for _, email := range []string{"alice@example.com", "ALICE@EXAMPLE.COM", "Alice@Example.com", "  alice@example.com\t"} {
+		token, err := userService.Login(ctx, &models.LoginRequest{Email: email, Password: "Password123!"})
+		if err != nil {
+			t.Errorf("Expected login as %q to succeed, got %v", email, err)
+			continue
+		}
+		if claims, err := testJWT.ParseAndValidate(token); err != nil || claims.Email != "alice@example.com" {
+			t.Errorf("Expected a token for alice@example.com after logging in as %q, got %+v, %v", email, claims, err)
+		}
+	}
+	if _, err := userService.Login(ctx, &models.LoginRequest{Email: "ALICE@example.com", Password: "Wrong123!"}); !errors.Is(err, services.ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+	}
+	if count := userRepo.Users["alice@example.com"].FailedLoginCount; count != 1 {
+		t.Errorf("Expected the wrong password to count against alice, got %d failures", count)
+	}
+
+	// Accounts are stored under the normalized email, and differently cased addresses are taken.
+	bob := &models.User{Email: "BOB@Example.com", Username: "Bobby", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(ctx, bob); !errors.Is(err, services.ErrEmailTaken) {
+		t.Errorf("Expected ErrEmailTaken for a differently cased email, got %v", err)
+	}
+	carol := &models.User{Email: " Carol@Example.COM ", Username: "Carol", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(ctx, carol); err != nil {
+		t.Fatalf("Failed to sign up: %v", err)
+	}
+	if stored := userRepo.Users["carol@example.com"]; stored == nil || stored.Email != "carol@example.com" {
+		t.Fatalf("Expected carol to be stored under carol@example.com, got %+v", stored)
+	}
+	if _, err := userService.VerifyEmail(ctx, "CAROL@example.com", mockEmailService.LastOTP()); err != nil {
+		t.Errorf("Expected verification with a differently cased email to succeed, got %v", err)
+	}
+
+	if err := userService.ForgotPassword(ctx, "ALICE@EXAMPLE.COM"); err != nil {
+		t.Fatalf("Failed to request a password reset: %v", err)
+	}
+	if err := userService.ResetPassword(ctx, " Alice@example.com", mockEmailService.LastOTP(), "NewPassword1!"); err != nil {
+		t.Fatalf("Expected the password reset to succeed, got %v", err)
+	}
+	if !utils.CheckPasswordHash("NewPassword1!", userRepo.Users["alice@example.com"].Password) {
+		t.Error("Expected alice's password to be reset")
+	}
+}
+
+func TestUserService_Signup_FriendInvitations(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newUsernameTestRepo(t)
+	userService, now := newLimitedUserService(userRepo)
+	friendRepo := userService.FriendRepo.(*mocks.MockFriendRepository)
+	invitationRepo := mocks.NewMockFriendInvitationRepository()
+	userService.FriendInvitationRepo = invitationRepo
+
+	invite := func(inviter, invitee string, age time.Duration) {
+		invitationRepo.Invitations[invitee+"_"+inviter] = &models.FriendInvitation{InviterEmail: inviter, InviteeEmail: invitee, CreatedAt: now.Add(-age)}
+	}
+	invite("alice@example.com", "carol@example.com", time.Hour)
+	invite("bob@example.com", "carol@example.com", 2*time.Hour)
+	invite("gone@example.com", "carol@example.com", 3*time.Hour) // The inviter has since deleted their account.
+	invite("alice@example.com", "dave@example.com", time.Hour)
+
+	carol := &models.User{Email: "Carol@example.com", Username: "Carol", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(ctx, carol); err != nil {
+		t.Fatalf("Failed to sign up: %v", err)
+	}
+
+	for _, inviter := range []string{"alice@example.com", "bob@example.com"} {
+		request := friendRepo.Friends[inviter+"_carol@example.com"]
+		if request == nil || request.Status != "pending" || !request.CreatedAt.Equal(*now) {
+			t.Errorf("Expected a pending request from %s to carol, got %+v", inviter, request)
+		}
+	}
+	if len(friendRepo.Friends) != 2 {
+		t.Errorf("Expected 2 friend requests, got %d", len(friendRepo.Friends))
+	}
+	for _, inviter := range []string{"alice@example.com", "bob@example.com", "gone@example.com"} {
+		if invitation := invitationRepo.Invitations["carol@example.com_"+inviter]; invitation.ConsumedAt == nil {
+			t.Errorf("Expected the invitation from %s to be consumed", inviter)
+		}
+	}
+	if invitationRepo.Invitations["dave@example.com_alice@example.com"].ConsumedAt != nil {
+		t.Error("Expected the invitation to dave to stay pending")
+	}
+
+	// Signing up still succeeds when the invitations cannot be read.
+	invitationRepo.Err = repositories.ErrUnavailable
+	erin := &models.User{Email: "erin@example.com", Username: "Erin", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(ctx, erin); err != nil {
+		t.Errorf("Expected signup to succeed without invitations, got %v", err)
+	}
+}
+
+func TestUserService_UnverifiedAccountExpiry(t *testing.T) {
+	tests := []struct {
+		name        string
+		age         time.Duration
+		verified    bool
+		noCreatedAt bool
+		wantExpired bool
+	}{
+		{name: "just before the boundary", age: services.UnverifiedAccountExpiry - time.Second},
+		{name: "at the boundary", age: services.UnverifiedAccountExpiry, wantExpired: true},
+		{name: "after the boundary", age: services.UnverifiedAccountExpiry + time.Hour, wantExpired: true},
+		{name: "verified", age: 30 * 24 * time.Hour, verified: true},
+		{name: "no CreatedAt", noCreatedAt: true, wantExpired: true},
+		{name: "verified without CreatedAt", verified: true, noCreatedAt: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userRepo := newUsernameTestRepo(t)
+			userService, now := newLimitedUserService(userRepo)
+			alice := userRepo.Users["alice@example.com"]
+			alice.IsVerified = test.verified
+			alice.CreatedAt = now.Add(-test.age)
+			if test.noCreatedAt {
+				alice.CreatedAt = time.Time{}
+			}
+			withOTP(alice)
+			ctx := context.Background()
+
+			_, err := userService.VerifyEmail(ctx, "alice@example.com", "123456")
+			if expired := errors.Is(err, services.ErrAccountExpired); expired != test.wantExpired {
+				t.Fatalf("Expected ErrAccountExpired: %v, got %v", test.wantExpired, err)
+			}
+			if !test.wantExpired {
+				return
+			}
+			if err.Error() != "Account was not verified in time. Please sign up again" {
+				t.Errorf("Expected the error to ask to sign up again, got %q", err)
+			}
+			if alice.OTP != "" || !alice.OTPExpiresAt.IsZero() {
+				t.Errorf("Expected the OTP of the expired account to be cleared, got %q/%v", alice.OTP, alice.OTPExpiresAt)
+			}
+			if err := userService.ResendOTP(ctx, "alice@example.com"); !errors.Is(err, services.ErrAccountExpired) {
+				t.Errorf("Expected ResendOTP to fail with ErrAccountExpired, got %v", err)
+			}
+			if err := userService.ResetPassword(ctx, "alice@example.com", "123456", "NewPassword1!"); !errors.Is(err, services.ErrAccountExpired) {
+				t.Errorf("Expected ResetPassword to fail with ErrAccountExpired, got %v", err)
+			}
+			login := &models.LoginRequest{Email: "alice@example.com", Password: "Password123!"}
+			if _, err := userService.Login(ctx, login); !errors.Is(err, services.ErrAccountExpired) {
+				t.Errorf("Expected Login to fail with ErrAccountExpired, got %v", err)
+			}
+			if len(userService.Email.(*mocks.MockEmailService).SentEmails) != 0 {
+				t.Errorf("Expected no email to be sent to an expired account")
+			}
+		})
+	}
+}
+
+func TestUserService_Signup_ReplacesExpiredAccount(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newUsernameTestRepo(t)
+	userService, now := newLimitedUserService(userRepo)
+	mockEmailService := userService.Email.(*mocks.MockEmailService)
+	alice := userRepo.Users["alice@example.com"]
+	alice.CreatedAt = now.Add(-services.UnverifiedAccountExpiry + time.Minute)
+	alice.FirstName = "Old"
+	withOTP(alice)
+
+	signup := func() *models.User {
+		return &models.User{Email: "ALICE@example.com", Username: "alice", Password: "NewPassword1!", Country: "Norway", City: "Oslo"}
+	}
+	if err := userService.Signup(ctx, signup()); !errors.Is(err, services.ErrEmailTaken) {
+		t.Fatalf("Expected an account younger than the expiry to keep its email, got %v", err)
+	}
+
+	*now = now.Add(time.Minute)
+	if err := userService.Signup(ctx, signup()); err != nil {
+		t.Fatalf("Expected signup to replace the expired account, got %v", err)
+	}
+	stored := userRepo.Users["alice@example.com"]
+	if len(userRepo.Users) != 2 {
+		t.Errorf("Expected the account to be replaced, got %d users", len(userRepo.Users))
+	}
+	if !utils.CheckPasswordHash("NewPassword1!", stored.Password) || stored.FirstName != "" {
+		t.Errorf("Expected the new password and no old fields, got first name %q", stored.FirstName)
+	}
+	if !stored.CreatedAt.Equal(*now) {
+		t.Errorf("Expected CreatedAt %v, got %v", *now, stored.CreatedAt)
+	}
+	otp := mockEmailService.LastOTP()
+	if otp == "" || stored.OTP != testJWT.HashOTP(otp) {
+		t.Fatalf("Expected a fresh OTP to be emailed and stored, got %q", stored.OTP)
+	}
+	if _, err := userService.VerifyEmail(ctx, "alice@example.com", otp); err != nil {
+		t.Fatalf("Expected the new OTP to verify the replaced account, got %v", err)
+	}
+
+	// Another account's username stays taken even when the email is free.
+	userRepo.Users["bob@example.com"].CreatedAt = now.Add(-services.UnverifiedAccountExpiry)
+	taken := &models.User{Email: "bob@example.com", Username: "Alice", Password: "Password123!", Country: "Norway", City: "Oslo"}
+	if err := userService.Signup(ctx, taken); !errors.Is(err, services.ErrUsernameTaken) {
+		t.Errorf("Expected ErrUsernameTaken, got %v", err)
+	}
+}
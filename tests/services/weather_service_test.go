@@ -0,0 +1,158 @@
+/**
+ *  WeatherService Tests validate the forecast cache and error handling against a counting fake
+ *  forecast API, so no request reaches api.open-meteo.com.
+ *
+ *  @file       weather_service_test.go
+ *  @package    services_test
+ *
+ *  @test_cases
+ *  - TestWeatherService_Cache      - Tests that a forecast is fetched once per position and date within the TTL, and again after expiry.
+ *  - TestWeatherService_APIError   - Tests that failed requests return a *WeatherAPIError and are not cached.
+ *  - TestWeatherService_DateRange  - Tests that invalid dates and dates outside the forecast range are rejected without a request.
+ *  - TestWeatherSummary            - Tests the descriptions of WMO weather codes.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"proh2052-group6/internal/services"
+)
+
+// forecastResponse is a forecast API response for 2024-05-01 with light rain.
+const forecastResponse = `{"latitude":59.91,"longitude":10.75,"daily":{"time":["2024-05-01"],` +
+	`"temperature_2m_max":[14.2],"temperature_2m_min":[5.1],"precipitation_sum":[0.4],"weather_code":[61]}}`
+
+// newWeatherService returns a WeatherService for a fake forecast API that answers with status and
+// body, counting the requests in calls.
+func newWeatherService(t *testing.T, calls *int32, status int, body string, now *time.Time) *services.WeatherService {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if r.Header.Get("User-Agent") != "DailyVerse test" {
+			t.Errorf("Expected the User-Agent to identify the application, got %q", r.Header.Get("User-Agent"))
+		}
+		query := r.URL.Query()
+		if query.Get("start_date") != query.Get("end_date") || query.Get("daily") == "" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return &services.WeatherService{
+		HTTPClient:  server.Client(),
+		ForecastURL: server.URL,
+		UserAgent:   "DailyVerse test",
+		CacheTTL:    time.Hour,
+		Now:         func() time.Time { return *now },
+	}
+}
+
+func TestWeatherService_Cache(t *testing.T) {
+	var calls int32
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	weatherService := newWeatherService(t, &calls, http.StatusOK, forecastResponse, &now)
+	ctx := context.Background()
+
+	// Positions within the same city share a cache entry.
+	for _, position := range [][2]float64{{59.9139, 10.7522}, {59.9141, 10.7518}} {
+		forecast, err := weatherService.GetForecast(ctx, position[0], position[1], "2024-05-01")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := services.Forecast{Date: "2024-05-01", TemperatureMax: 14.2, TemperatureMin: 5.1, PrecipitationMm: 0.4, Summary: "Light rain"}
+		if *forecast != expected {
+			t.Errorf("Expected %+v, got %+v", expected, *forecast)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 upstream call for a cache hit, got %d", got)
+	}
+
+	// Another date or city is a cache miss.
+	weatherService.GetForecast(ctx, 59.9139, 10.7522, "2024-05-02")
+	weatherService.GetForecast(ctx, 63.4305, 10.3951, "2024-05-01")
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Expected an upstream call per date and city, got %d calls", got)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := weatherService.GetForecast(ctx, 59.9139, 10.7522, "2024-05-01"); err != nil {
+		t.Fatalf("Unexpected error after expiry: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 4 {
+		t.Errorf("Expected the forecast to be fetched again after the TTL, got %d calls", got)
+	}
+}
+
+func TestWeatherService_APIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"Rejected request", http.StatusBadRequest, `{"error":true,"reason":"Latitude must be in range of -90 to 90°."}`},
+		{"Unavailable", http.StatusServiceUnavailable, `<html>Service Unavailable</html>`},
+		{"Missing forecast", http.StatusOK, `{"daily":{"time":[]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+			weatherService := newWeatherService(t, &calls, tt.status, tt.body, &now)
+
+			for i := 0; i < 2; i++ {
+				_, err := weatherService.GetForecast(context.Background(), 59.9139, 10.7522, "2024-05-01")
+				var apiErr *services.WeatherAPIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("Expected a *WeatherAPIError, got %v", err)
+				}
+			}
+			if got := atomic.LoadInt32(&calls); got != 2 {
+				t.Errorf("Expected failed requests not to be cached, got %d calls", got)
+			}
+		})
+	}
+}
+
+func TestWeatherService_DateRange(t *testing.T) {
+	var calls int32
+	now := time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC)
+	weatherService := newWeatherService(t, &calls, http.StatusOK, forecastResponse, &now)
+
+	for _, date := range []string{"2024-04-30", "2024-05-17", "01.05.2024"} {
+		if _, err := weatherService.GetForecast(context.Background(), 59.9139, 10.7522, date); err == nil {
+			t.Errorf("Expected an error for %s", date)
+		}
+	}
+	if _, err := weatherService.GetForecast(context.Background(), 59.9139, 10.7522, "2024-05-16"); err != nil {
+		t.Errorf("Expected 15 days ahead to be in range, got %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected only the valid date to reach the API, got %d calls", got)
+	}
+}
+
+func TestWeatherSummary(t *testing.T) {
+	tests := map[int]string{0: "Clear sky", 3: "Overcast", 45: "Fog", 53: "Drizzle", 61: "Light rain", 63: "Rain", 73: "Snow", 95: "Thunderstorm", 42: "Unknown"}
+	for code, expected := range tests {
+		if got := services.WeatherSummary(code); got != expected {
+			t.Errorf("WeatherSummary(%d): expected %q, got %q", code, expected, got)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+/**
+ *  JWTManager Tests check that tokens are signed with the primary key and validated on expiry,
+ *  issuer, audience and signing method, and that old keys keep working during a key rotation.
+ *
+ *  @file       jwt_test.go
+ *  @package    utils_test
+ *
+ *  @test_cases
+ *  - TestJWTManager_RoundTrip        - Tests the claims of a generated token, including issuer, audience and times.
+ *  - TestJWTManager_Expiry           - Tests a token is accepted until its expiry and rejected after it.
+ *  - TestJWTManager_WrongIssuer      - Tests tokens from another issuer or for another audience are rejected.
+ *  - TestJWTManager_RotatedKey       - Tests tokens and OTPs from an old key are accepted only while it is listed.
+ *  - TestJWTManager_RejectsAlgNone   - Tests unsigned and non-HS256 tokens are rejected.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"proh2052-group6/pkg/utils"
+)
+
+// newClockedJWTManager creates a JWTManager whose clock reads *now.
+func newClockedJWTManager(now *time.Time, primaryKey string, oldKeys []string, issuer string) *utils.JWTManager {
+	manager := utils.NewJWTManager(primaryKey, oldKeys, time.Hour, issuer)
+	manager.Now = func() time.Time { return *now }
+	return manager
+}
+
+func TestJWTManager_RoundTrip(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	manager := newClockedJWTManager(&now, "secret", nil, "")
+
+	token, err := manager.GenerateJWT("user@example.com", 3)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	claims, err := manager.ParseAndValidate(token)
+	if err != nil {
+		t.Fatalf("Expected the token to be valid, got %v", err)
+	}
+	if claims.Email != "user@example.com" || claims.TokenVersion != 3 {
+		t.Errorf("Expected the email and token version back, got %+v", claims)
+	}
+	if claims.Issuer != utils.DefaultJWTIssuer || len(claims.Audience) != 1 || claims.Audience[0] != utils.JWTAudience {
+		t.Errorf("Expected the default issuer and the API audience, got %q and %v", claims.Issuer, claims.Audience)
+	}
+	if !claims.IssuedAt.Time.Equal(now) || !claims.ExpiresAt.Time.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected iat %v and exp an hour later, got %v and %v", now, claims.IssuedAt, claims.ExpiresAt)
+	}
+}
+
+func TestJWTManager_Expiry(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	manager := newClockedJWTManager(&now, "secret", nil, "")
+	token, _ := manager.GenerateJWT("user@example.com", 0)
+
+	now = now.Add(59 * time.Minute)
+	if _, err := manager.ParseAndValidate(token); err != nil {
+		t.Errorf("Expected the token to be valid before its expiry, got %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := manager.ParseAndValidate(token); err == nil {
+		t.Errorf("Expected the token to be rejected after its expiry")
+	}
+
+	// A token issued in the future is rejected too.
+	future, _ := manager.GenerateJWT("user@example.com", 0)
+	now = now.Add(-2 * time.Hour)
+	if _, err := manager.ParseAndValidate(future); err == nil {
+		t.Errorf("Expected a token issued in the future to be rejected")
+	}
+}
+
+func TestJWTManager_WrongIssuer(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	manager := newClockedJWTManager(&now, "secret", nil, "dailyverse")
+	other := newClockedJWTManager(&now, "secret", nil, "someone-else")
+
+	token, _ := other.GenerateJWT("user@example.com", 0)
+	if _, err := manager.ParseAndValidate(token); err == nil {
+		t.Errorf("Expected a token from another issuer to be rejected")
+	}
+
+	// A token signed with the right key but for another audience is rejected.
+	claims := utils.Claims{
+		Email: "user@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "dailyverse",
+			Audience:  jwt.ClaimStrings{"another-api"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+	token, _ = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if _, err := manager.ParseAndValidate(token); err == nil {
+		t.Errorf("Expected a token for another audience to be rejected")
+	}
+
+	// So is a token without an expiry.
+	claims.Audience = jwt.ClaimStrings{utils.JWTAudience}
+	claims.ExpiresAt = nil
+	token, _ = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if _, err := manager.ParseAndValidate(token); err == nil {
+		t.Errorf("Expected a token without an expiry to be rejected")
+	}
+}
+
+func TestJWTManager_RotatedKey(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	before := newClockedJWTManager(&now, "old-secret", nil, "")
+	oldToken, _ := before.GenerateJWT("user@example.com", 0)
+	oldOTP := before.HashOTP("123456")
+
+	rotating := newClockedJWTManager(&now, "new-secret", []string{"old-secret"}, "")
+	if _, err := rotating.ParseAndValidate(oldToken); err != nil {
+		t.Errorf("Expected a token signed with an old key to be accepted, got %v", err)
+	}
+	if !rotating.CheckOTP("123456", oldOTP) {
+		t.Errorf("Expected an OTP hashed with an old key to match")
+	}
+
+	// New tokens are signed with the primary key only.
+	newToken, _ := rotating.GenerateJWT("user@example.com", 0)
+	if _, err := before.ParseAndValidate(newToken); err == nil {
+		t.Errorf("Expected a new token not to validate with the old key alone")
+	}
+
+	rotated := newClockedJWTManager(&now, "new-secret", nil, "")
+	if _, err := rotated.ParseAndValidate(oldToken); err == nil {
+		t.Errorf("Expected a token signed with a dropped key to be rejected")
+	}
+	if rotated.CheckOTP("123456", oldOTP) {
+		t.Errorf("Expected an OTP hashed with a dropped key not to match")
+	}
+	if _, err := rotated.ParseAndValidate(newToken); err != nil {
+		t.Errorf("Expected a token signed with the primary key to be accepted, got %v", err)
+	}
+}
+
+func TestJWTManager_RejectsAlgNone(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	manager := newClockedJWTManager(&now, "secret", nil, "")
+	claims := utils.Claims{
+		Email: "user@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    utils.DefaultJWTIssuer,
+			Audience:  jwt.ClaimStrings{utils.JWTAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("Failed to build an unsigned token: %v", err)
+	}
+	if _, err := manager.ParseAndValidate(unsigned); err == nil {
+		t.Errorf("Expected an alg=none token to be rejected")
+	}
+
+	hs512, _ := jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString([]byte("secret"))
+	if _, err := manager.ParseAndValidate(hs512); err == nil {
+		t.Errorf("Expected a token signed with another algorithm to be rejected")
+	}
+}
@@ -0,0 +1,120 @@
+/**
+ *  Validate Tests check the field-level validation of events and journal entries, including
+ *  the error map returned for invalid fields and its message.
+ *
+ *  @file       validate_test.go
+ *  @package    validate_test
+ *
+ *  @test_cases
+ *  - TestEvent          - Tests required title, length limits, HH:MM times, their order, numeric postal numbers and coordinates.
+ *  - TestJournal        - Tests required content and its length limit.
+ *  - TestErrors_Message - Tests that the error message lists the invalid fields in order and AsErrors unwraps it.
+ *
+ *  @authors
+ *      - Aayush
+ *      - Tung
+ *      - Boss
+ *      - Majd
+ */
+
+package validate_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"proh2052-group6/pkg/models"
+	"proh2052-group6/pkg/validate"
+)
+
+// fieldErrors returns the field errors of err, or nil if err is nil.
+func fieldErrors(t *testing.T, err error) map[string]string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	errs, ok := validate.AsErrors(err)
+	if !ok {
+		t.Fatalf("Expected validate.Errors, got %T: %v", err, err)
+	}
+	return errs
+}
+
+// degrees returns a pointer to a coordinate.
+func degrees(value float64) *float64 {
+	return &value
+}
+
+func TestEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    models.Event
+		expected map[string]string
+	}{
+		{"valid", models.Event{Title: "Meeting", Description: "Weekly sync", StartTime: "09:00", EndTime: "10:30", PostalNumber: "7034"}, nil},
+		{"only a title", models.Event{Title: "Meeting"}, nil},
+		{"missing title", models.Event{}, map[string]string{"title": "required"}},
+		{"blank title", models.Event{Title: " \t "}, map[string]string{"title": "required"}},
+		{"title at limit", models.Event{Title: strings.Repeat("é", 200)}, nil},
+		{"title too long", models.Event{Title: strings.Repeat("a", 201)}, map[string]string{"title": "must be at most 200 characters"}},
+		{"description too long", models.Event{Title: "Meeting", Description: strings.Repeat("a", 5001)}, map[string]string{"description": "must be at most 5000 characters"}},
+		{"malformed start time", models.Event{Title: "Meeting", StartTime: "9am"}, map[string]string{"startTime": "must be a time in HH:MM format"}},
+		{"malformed end time", models.Event{Title: "Meeting", StartTime: "09:00", EndTime: "25:00"}, map[string]string{"endTime": "must be a time in HH:MM format"}},
+		{"end before start", models.Event{Title: "Meeting", StartTime: "10:00", EndTime: "09:59"}, map[string]string{"endTime": "must be after startTime"}},
+		{"end equal to start", models.Event{Title: "Meeting", StartTime: "10:00", EndTime: "10:00"}, map[string]string{"endTime": "must be after startTime"}},
+		{"end without start", models.Event{Title: "Meeting", EndTime: "10:00"}, nil},
+		{"non-numeric postal number", models.Event{Title: "Meeting", PostalNumber: "N-7034"}, map[string]string{"postalNumber": "must contain only digits"}},
+		{"coordinates", models.Event{Title: "Meeting", Latitude: degrees(63.4305), Longitude: degrees(-10.3951)}, nil},
+		{"latitude without longitude", models.Event{Title: "Meeting", Latitude: degrees(63.4305)}, map[string]string{"longitude": "required with latitude"}},
+		{"longitude without latitude", models.Event{Title: "Meeting", Longitude: degrees(10.3951)}, map[string]string{"latitude": "required with longitude"}},
+		{"coordinates out of range", models.Event{Title: "Meeting", Latitude: degrees(90.5), Longitude: degrees(-180.5)}, map[string]string{
+			"latitude": "must be between -90 and 90", "longitude": "must be between -180 and 180",
+		}},
+		{"several fields", models.Event{StartTime: "x", PostalNumber: "abc"}, map[string]string{
+			"title": "required", "startTime": "must be a time in HH:MM format", "postalNumber": "must contain only digits",
+		}},
+	}
+	for _, tt := range tests {
+		got := fieldErrors(t, validate.Event(&tt.event))
+		if len(got) != len(tt.expected) || (len(got) > 0 && !reflect.DeepEqual(got, tt.expected)) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestJournal(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string]string
+	}{
+		{"valid", "Today was a good day.", nil},
+		{"empty", "", map[string]string{"content": "required"}},
+		{"whitespace", "\n  \n", map[string]string{"content": "required"}},
+		{"at limit", strings.Repeat("a", 20000), nil},
+		{"too long", strings.Repeat("a", 20001), map[string]string{"content": "must be at most 20000 characters"}},
+	}
+	for _, tt := range tests {
+		got := fieldErrors(t, validate.Journal(&models.Journal{Content: tt.content}))
+		if len(got) != len(tt.expected) || (len(got) > 0 && !reflect.DeepEqual(got, tt.expected)) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestErrors_Message(t *testing.T) {
+	err := validate.Event(&models.Event{PostalNumber: "abc"})
+	if err == nil || err.Error() != "Invalid input: postalNumber must contain only digits, title required" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+
+	wrapped := fmt.Errorf("creating event: %w", err)
+	if errs, ok := validate.AsErrors(wrapped); !ok || errs["title"] != "required" {
+		t.Errorf("Expected AsErrors to unwrap the field errors, got %v", errs)
+	}
+	if _, ok := validate.AsErrors(fmt.Errorf("other")); ok {
+		t.Errorf("Expected AsErrors to reject other errors")
+	}
+}